@@ -93,6 +93,42 @@ func main() {
 	}
 	rootCmd.AddCommand(lspCmd)
 
+	analyzeSchemaConfig := new(cmd.AnalyzeSchemaConfig)
+	analyzeSchemaCmd := cmd.NewAnalyzeSchemaCommand(rootCmd.Use, analyzeSchemaConfig)
+	cmd.RegisterAnalyzeSchemaFlags(analyzeSchemaCmd, analyzeSchemaConfig)
+	rootCmd.AddCommand(analyzeSchemaCmd)
+
+	schemaCmd := cmd.NewSchemaCommand(rootCmd.Use)
+	rootCmd.AddCommand(schemaCmd)
+
+	perfConfig := new(cmd.PerfConfig)
+	perfCmd := cmd.NewPerfCommand(rootCmd.Use, perfConfig)
+	cmd.RegisterPerfFlags(perfCmd, perfConfig)
+	rootCmd.AddCommand(perfCmd)
+
+	importConfig := new(cmd.ImportConfig)
+	importCmd := cmd.NewImportCommand(rootCmd.Use, importConfig)
+	cmd.RegisterImportFlags(importCmd, importConfig)
+	rootCmd.AddCommand(importCmd)
+
+	backupCmd := cmd.NewBackupCommand(rootCmd.Use)
+	rootCmd.AddCommand(backupCmd)
+
+	replayConfig := new(cmd.ReplayConfig)
+	replayCmd := cmd.NewReplayCommand(rootCmd.Use, replayConfig)
+	cmd.RegisterReplayFlags(replayCmd, replayConfig)
+	rootCmd.AddCommand(replayCmd)
+
+	k8sAuthzWebhookConfig := new(cmd.K8sAuthzWebhookConfig)
+	k8sAuthzWebhookCmd := cmd.NewK8sAuthzWebhookCommand(rootCmd.Use, k8sAuthzWebhookConfig)
+	cmd.RegisterK8sAuthzWebhookFlags(k8sAuthzWebhookCmd, k8sAuthzWebhookConfig)
+	rootCmd.AddCommand(k8sAuthzWebhookCmd)
+
+	ldapSyncConfig := new(cmd.LDAPSyncConfig)
+	ldapSyncCmd := cmd.NewLDAPSyncCommand(rootCmd.Use, ldapSyncConfig)
+	cmd.RegisterLDAPSyncFlags(ldapSyncCmd, ldapSyncConfig)
+	rootCmd.AddCommand(ldapSyncCmd)
+
 	var testServerConfig testserver.Config
 	testingCmd := cmd.NewTestingCommand(rootCmd.Use, &testServerConfig)
 	cmd.RegisterTestingFlags(testingCmd, &testServerConfig)
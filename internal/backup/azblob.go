@@ -0,0 +1,59 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+)
+
+// azureStorageConnectionStringEnv is the environment variable holding the connection string of
+// the Azure storage account.
+const azureStorageConnectionStringEnv = "AZURE_STORAGE_CONNECTION_STRING"
+
+// azureBlobSink stores objects as block blobs in an Azure Blob Storage container, uploading them
+// in blocks.
+type azureBlobSink struct {
+	client    *azblob.Client
+	container string
+	prefix    string
+}
+
+func newAzureBlobSink(container, prefix string) (*azureBlobSink, error) {
+	connectionString := os.Getenv(azureStorageConnectionStringEnv)
+	if connectionString == "" {
+		return nil, errors.New(azureStorageConnectionStringEnv + " must be set to back up to Azure Blob Storage")
+	}
+
+	client, err := azblob.NewClientFromConnectionString(connectionString, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Azure Blob Storage client: %w", err)
+	}
+	return &azureBlobSink{client: client, container: container, prefix: prefix}, nil
+}
+
+func (s *azureBlobSink) Write(ctx context.Context, name string, body io.Reader) error {
+	key := objectKey(s.prefix, name)
+	if _, err := s.client.UploadStream(ctx, s.container, key, body, &azblob.UploadStreamOptions{
+		BlockSize: uploadPartSize,
+	}); err != nil {
+		return fmt.Errorf("failed to upload azblob://%s/%s: %w", s.container, key, err)
+	}
+	return nil
+}
+
+func (s *azureBlobSink) Read(ctx context.Context, name string) (io.ReadCloser, error) {
+	key := objectKey(s.prefix, name)
+	resp, err := s.client.DownloadStream(ctx, s.container, key, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download azblob://%s/%s: %w", s.container, key, err)
+	}
+	return resp.Body, nil
+}
+
+func (s *azureBlobSink) Close() error {
+	return nil
+}
@@ -0,0 +1,294 @@
+// Package backup writes backups of the schema and relationships of a SpiceDB instance to a sink,
+// such as a bucket in object storage, and restores them.
+//
+// A backup is made of compressed objects, one for the schema and one per chunk of relationships,
+// and of a manifest describing them, which is written last: a backup without a manifest is
+// incomplete. The objects of an encrypted backup are encrypted with a data key, which is stored in
+// the manifest wrapped by a key held in a KMS, and its manifest is authenticated with the data key.
+// The objects of a backup which is not encrypted are only verified against the checksums in its
+// manifest, which detect corruption but not tampering by anyone able to write to the sink.
+package backup
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/klauspost/compress/zstd"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	log "github.com/authzed/spicedb/internal/logging"
+)
+
+const (
+	// manifestName is the name of the manifest of a backup.
+	manifestName = "manifest.json"
+
+	// manifestVersion is the version of the manifest format written by this package.
+	manifestVersion = 1
+
+	// compressionZstd is the compression of the objects of a backup.
+	compressionZstd = "zstd"
+
+	schemaObjectName = "schema.zed.zst"
+)
+
+// Config is the configuration of a backup.
+type Config struct {
+	// ChunkSize is the number of relationships written to each chunk of the backup.
+	ChunkSize uint
+
+	// EncryptionKey, if not empty, is the URI of the KMS key wrapping the key with which the
+	// backup is encrypted; see openKeyWrapper.
+	EncryptionKey string
+}
+
+func (c Config) validate() error {
+	if c.ChunkSize == 0 {
+		return errors.New("chunk size must be positive")
+	}
+	return nil
+}
+
+// Manifest describes a backup.
+type Manifest struct {
+	// Version is the version of the format of the backup.
+	Version int `json:"version"`
+
+	// Revision is the ZedToken of the revision at which the backup was read.
+	Revision string `json:"revision"`
+
+	// CreatedAt is the time at which the backup was started.
+	CreatedAt time.Time `json:"createdAt"`
+
+	// Compression is the compression of the objects of the backup.
+	Compression string `json:"compression"`
+
+	// Encryption, if not nil, describes the encryption of the objects of the backup.
+	Encryption *Encryption `json:"encryption,omitempty"`
+
+	// Schema is the object holding the schema.
+	Schema Object `json:"schema"`
+
+	// Chunks are the objects holding the relationships, in order.
+	Chunks []Chunk `json:"chunks"`
+
+	// Relationships is the number of relationships in the backup.
+	Relationships uint64 `json:"relationships"`
+
+	// MAC, if the backup is encrypted, is the HMAC-SHA256 of the rest of the manifest, keyed by
+	// the data key; see authenticate.
+	MAC []byte `json:"mac,omitempty"`
+
+	// dataKey is the data key of an encrypted backup, once unwrapped.
+	dataKey []byte
+}
+
+// Object describes an object of a backup as stored in its sink.
+type Object struct {
+	// Name is the name of the object.
+	Name string `json:"name"`
+
+	// Size is the size of the object, in bytes.
+	Size int64 `json:"size"`
+
+	// SHA256 is the hex-encoded SHA-256 checksum of the object.
+	SHA256 string `json:"sha256"`
+}
+
+// Chunk describes an object of a backup holding relationships, one JSON-encoded relationship per
+// line.
+type Chunk struct {
+	Object
+
+	// Relationships is the number of relationships in the chunk.
+	Relationships uint64 `json:"relationships"`
+}
+
+// Create writes a backup of the schema and relationships of the SpiceDB instance behind the
+// connection to the sink. The relationships are exported at the revision at which the schema was
+// read, so the backup is a consistent snapshot.
+func Create(ctx context.Context, conn grpc.ClientConnInterface, sink Sink, config Config) (*Manifest, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+
+	schema, err := v1.NewSchemaServiceClient(conn).ReadSchema(ctx, &v1.ReadSchemaRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema: %w", err)
+	}
+
+	manifest := &Manifest{
+		Version:     manifestVersion,
+		Revision:    schema.ReadAt.Token,
+		CreatedAt:   time.Now().UTC(),
+		Compression: compressionZstd,
+	}
+
+	if config.EncryptionKey != "" {
+		manifest.dataKey, manifest.Encryption, err = newEncryption(ctx, config.EncryptionKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	manifest.Schema, err = writeObject(ctx, sink, schemaObjectName, []byte(schema.SchemaText), manifest.dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := v1.NewPermissionsServiceClient(conn).ExportBulkRelationships(ctx, &v1.ExportBulkRelationshipsRequest{
+		Consistency: &v1.Consistency{
+			Requirement: &v1.Consistency_AtExactSnapshot{AtExactSnapshot: schema.ReadAt},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to export relationships: %w", err)
+	}
+
+	var chunk bytes.Buffer
+	var inChunk uint64
+	flush := func() error {
+		if inChunk == 0 {
+			return nil
+		}
+
+		name := fmt.Sprintf("relationships-%06d.ndjson.zst", len(manifest.Chunks))
+		object, err := writeObject(ctx, sink, name, chunk.Bytes(), manifest.dataKey)
+		if err != nil {
+			return err
+		}
+
+		manifest.Chunks = append(manifest.Chunks, Chunk{Object: object, Relationships: inChunk})
+		manifest.Relationships += inChunk
+		log.Ctx(ctx).Info().Str("chunk", name).Uint64("relationships", manifest.Relationships).Msg("wrote backup chunk")
+
+		chunk.Reset()
+		inChunk = 0
+		return nil
+	}
+
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to export relationships after %d: %w", manifest.Relationships+inChunk, err)
+		}
+
+		for _, rel := range resp.Relationships {
+			encoded, err := protojson.Marshal(rel)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode relationship: %w", err)
+			}
+			chunk.Write(encoded)
+			chunk.WriteByte('\n')
+
+			inChunk++
+			if inChunk == uint64(config.ChunkSize) {
+				if err := flush(); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	if err := writeManifest(ctx, sink, manifest); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// RewrapKey wraps the data key of the encrypted backup in the sink with the KMS key with the URI,
+// replacing the key recorded in its manifest, such as to retire that key. The data key, and so the
+// objects of the backup, are unchanged.
+func RewrapKey(ctx context.Context, sink Sink, keyURI string) (*Manifest, error) {
+	manifest, err := ReadManifest(ctx, sink, "")
+	if err != nil {
+		return nil, err
+	}
+
+	if manifest.Encryption == nil {
+		return nil, errors.New("backup is not encrypted")
+	}
+
+	wrapper, err := openKeyWrapper(ctx, keyURI)
+	if err != nil {
+		return nil, err
+	}
+	defer wrapper.close()
+
+	wrapped, err := wrapper.wrap(ctx, manifest.dataKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to wrap data key with %s: %w", keyURI, err)
+	}
+
+	manifest.Encryption.KeyURI = keyURI
+	manifest.Encryption.WrappedKey = wrapped
+	if err := writeManifest(ctx, sink, manifest); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// writeManifest writes the manifest to the sink, authenticated with its data key if encrypted.
+func writeManifest(ctx context.Context, sink Sink, manifest *Manifest) error {
+	manifest.MAC = nil
+	if manifest.dataKey != nil {
+		mac, err := authenticate(manifest.dataKey, manifest)
+		if err != nil {
+			return err
+		}
+		manifest.MAC = mac
+	}
+
+	encoded, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := sink.Write(ctx, manifestName, bytes.NewReader(encoded)); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return nil
+}
+
+// writeObject compresses the contents, encrypts them with the data key if not nil, and writes
+// them to the sink as the object with the name.
+func writeObject(ctx context.Context, sink Sink, name string, contents []byte, dataKey []byte) (Object, error) {
+	encoder, err := zstd.NewWriter(nil)
+	if err != nil {
+		return Object{}, err
+	}
+	defer encoder.Close()
+
+	stored := encoder.EncodeAll(contents, nil)
+	if dataKey != nil {
+		stored, err = seal(dataKey, name, stored)
+		if err != nil {
+			return Object{}, fmt.Errorf("failed to encrypt %s: %w", name, err)
+		}
+	}
+	checksum := sha256.Sum256(stored)
+	if err := sink.Write(ctx, name, bytes.NewReader(stored)); err != nil {
+		return Object{}, err
+	}
+
+	return Object{
+		Name:   name,
+		Size:   int64(len(stored)),
+		SHA256: hex.EncodeToString(checksum[:]),
+	}, nil
+}
@@ -0,0 +1,136 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/internal/testserver"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+func TestOpenSink(t *testing.T) {
+	dir := t.TempDir()
+
+	sink, err := OpenSink(context.Background(), "file://"+filepath.Join(dir, "nested"))
+	require.NoError(t, err)
+	require.Equal(t, &fileSink{dir: filepath.Join(dir, "nested")}, sink)
+
+	sink, err = OpenSink(context.Background(), dir)
+	require.NoError(t, err)
+	require.Equal(t, &fileSink{dir: dir}, sink)
+
+	_, err = OpenSink(context.Background(), "s3:///prefix")
+	require.ErrorContains(t, err, "missing bucket")
+
+	_, err = OpenSink(context.Background(), "ftp://bucket/prefix")
+	require.ErrorContains(t, err, "unknown scheme `ftp`")
+
+	t.Setenv(azureStorageConnectionStringEnv, "")
+	_, err = OpenSink(context.Background(), "azblob://container/prefix")
+	require.ErrorContains(t, err, azureStorageConnectionStringEnv)
+}
+
+func TestObjectKey(t *testing.T) {
+	require.Equal(t, "manifest.json", objectKey("", "manifest.json"))
+	require.Equal(t, "backups/daily/manifest.json", objectKey("backups/daily/", "manifest.json"))
+}
+
+func TestCreateAndRestore(t *testing.T) {
+	source, cleanup, _, _ := testserver.NewTestServer(require.New(t), 0, time.Hour, true, testfixtures.StandardDatastoreWithCaveatedData)
+	t.Cleanup(cleanup)
+
+	sink, err := OpenSink(context.Background(), t.TempDir())
+	require.NoError(t, err)
+
+	manifest, err := Create(context.Background(), source, sink, Config{ChunkSize: 7})
+	require.NoError(t, err)
+
+	expected := exportRelationships(t, source)
+	require.Equal(t, uint64(len(expected)), manifest.Relationships)
+	require.Len(t, manifest.Chunks, (len(expected)+6)/7)
+	require.NotEmpty(t, manifest.Revision)
+
+	stored, err := ReadManifest(context.Background(), sink, "")
+	require.NoError(t, err)
+	require.Equal(t, manifest.Chunks, stored.Chunks)
+
+	target, cleanup, _, _ := testserver.NewTestServer(require.New(t), 0, time.Hour, true, testfixtures.EmptyDatastore)
+	t.Cleanup(cleanup)
+
+	summary, err := Restore(context.Background(), target, sink, RestoreConfig{})
+	require.NoError(t, err)
+	require.Equal(t, manifest.Relationships, summary.Relationships)
+	require.Equal(t, manifest.Revision, summary.Revision)
+
+	require.ElementsMatch(t, expected, exportRelationships(t, target))
+}
+
+func TestRestoreVerifiesChecksums(t *testing.T) {
+	source, cleanup, _, _ := testserver.NewTestServer(require.New(t), 0, time.Hour, true, testfixtures.StandardDatastoreWithData)
+	t.Cleanup(cleanup)
+
+	dir := t.TempDir()
+	sink, err := OpenSink(context.Background(), dir)
+	require.NoError(t, err)
+
+	manifest, err := Create(context.Background(), source, sink, Config{ChunkSize: 1_000})
+	require.NoError(t, err)
+	require.Len(t, manifest.Chunks, 1)
+
+	path := filepath.Join(dir, manifest.Chunks[0].Name)
+	contents, err := os.ReadFile(path)
+	require.NoError(t, err)
+	contents[len(contents)-1] ^= 0xff
+	require.NoError(t, os.WriteFile(path, contents, 0o600))
+
+	target, cleanup, _, _ := testserver.NewTestServer(require.New(t), 0, time.Hour, true, testfixtures.EmptyDatastore)
+	t.Cleanup(cleanup)
+
+	_, err = Restore(context.Background(), target, sink, RestoreConfig{})
+	require.ErrorContains(t, err, "does not match its checksum")
+	require.Empty(t, exportRelationships(t, target))
+}
+
+func TestRestoreRequiresManifest(t *testing.T) {
+	sink, err := OpenSink(context.Background(), t.TempDir())
+	require.NoError(t, err)
+
+	_, err = Restore(context.Background(), nil, sink, RestoreConfig{})
+	require.ErrorContains(t, err, "the backup may be incomplete")
+}
+
+func TestCreateValidatesConfig(t *testing.T) {
+	_, err := Create(context.Background(), nil, nil, Config{})
+	require.ErrorContains(t, err, "chunk size must be positive")
+}
+
+func exportRelationships(t *testing.T, conn grpc.ClientConnInterface) []string {
+	stream, err := v1.NewPermissionsServiceClient(conn).ExportBulkRelationships(context.Background(), &v1.ExportBulkRelationshipsRequest{
+		Consistency: &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}},
+	})
+	require.NoError(t, err)
+
+	var rels []string
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+
+		for _, rel := range resp.Relationships {
+			rels = append(rels, tuple.MustV1RelString(rel))
+		}
+	}
+	return rels
+}
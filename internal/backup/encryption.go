@@ -0,0 +1,256 @@
+package backup
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	kms "cloud.google.com/go/kms/apiv1"
+	"cloud.google.com/go/kms/apiv1/kmspb"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	awskms "github.com/aws/aws-sdk-go-v2/service/kms"
+)
+
+const (
+	// encryptionAES256GCM is the encryption of the objects of an encrypted backup.
+	encryptionAES256GCM = "AES-256-GCM"
+
+	dataKeySize = 32
+)
+
+// Encryption describes the encryption of a backup. Each object of the backup is encrypted with a
+// data key, which is stored wrapped by a key held in a KMS.
+type Encryption struct {
+	// Algorithm is the algorithm with which the objects are encrypted.
+	Algorithm string `json:"algorithm"`
+
+	// KeyURI is the URI of the KMS key which wrapped the data key.
+	KeyURI string `json:"keyUri"`
+
+	// WrappedKey is the data key, wrapped by the KMS key.
+	WrappedKey []byte `json:"wrappedKey"`
+}
+
+// keyWrapper wraps and unwraps data keys with a key held in a KMS.
+type keyWrapper interface {
+	wrap(ctx context.Context, dataKey []byte) ([]byte, error)
+	unwrap(ctx context.Context, wrapped []byte) ([]byte, error)
+	close() error
+}
+
+// keyWrappers are the constructors of the key wrappers, by the scheme of their key URIs.
+var keyWrappers = map[string]func(ctx context.Context, key string) (keyWrapper, error){
+	"awskms": newAWSKeyWrapper,
+	"gcpkms": newGCPKeyWrapper,
+}
+
+// openKeyWrapper returns the wrapper of the key with the URI, written `awskms://<key ID, ARN or
+// alias>` or `gcpkms://projects/<project>/locations/<location>/keyRings/<key ring>/cryptoKeys/<key>`.
+func openKeyWrapper(ctx context.Context, uri string) (keyWrapper, error) {
+	scheme, key, ok := strings.Cut(uri, "://")
+	newWrapper, known := keyWrappers[scheme]
+	if !ok || !known || key == "" {
+		return nil, fmt.Errorf("invalid encryption key `%s`: must be awskms://<key> or gcpkms://<key>", uri)
+	}
+	return newWrapper(ctx, key)
+}
+
+// newEncryption returns a new data key and the description of the encryption with it, the data
+// key being wrapped by the KMS key with the URI.
+func newEncryption(ctx context.Context, keyURI string) ([]byte, *Encryption, error) {
+	wrapper, err := openKeyWrapper(ctx, keyURI)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer wrapper.close()
+
+	dataKey := make([]byte, dataKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, nil, err
+	}
+
+	wrapped, err := wrapper.wrap(ctx, dataKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to wrap data key with %s: %w", keyURI, err)
+	}
+
+	return dataKey, &Encryption{
+		Algorithm:  encryptionAES256GCM,
+		KeyURI:     keyURI,
+		WrappedKey: wrapped,
+	}, nil
+}
+
+// dataKey unwraps the data key of the encryption, with the KMS key recorded in it or, if not
+// empty, with the KMS key with the URI.
+func (e *Encryption) dataKey(ctx context.Context, keyURI string) ([]byte, error) {
+	if e.Algorithm != encryptionAES256GCM {
+		return nil, fmt.Errorf("unsupported backup encryption `%s`", e.Algorithm)
+	}
+
+	if keyURI == "" {
+		keyURI = e.KeyURI
+	}
+
+	wrapper, err := openKeyWrapper(ctx, keyURI)
+	if err != nil {
+		return nil, err
+	}
+	defer wrapper.close()
+
+	dataKey, err := wrapper.unwrap(ctx, e.WrappedKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key with %s: %w", keyURI, err)
+	}
+	if len(dataKey) != dataKeySize {
+		return nil, fmt.Errorf("unwrapped data key has %d bytes, expected %d", len(dataKey), dataKeySize)
+	}
+	return dataKey, nil
+}
+
+// authenticate returns the HMAC-SHA256 of the manifest, without its MAC, keyed by a key derived
+// from the data key so that the data key is not used by two algorithms.
+func authenticate(dataKey []byte, manifest *Manifest) ([]byte, error) {
+	unauthenticated := *manifest
+	unauthenticated.MAC = nil
+	encoded, err := json.Marshal(unauthenticated)
+	if err != nil {
+		return nil, err
+	}
+
+	derivation := hmac.New(sha256.New, dataKey)
+	derivation.Write([]byte(manifestName))
+
+	mac := hmac.New(sha256.New, derivation.Sum(nil))
+	mac.Write(encoded)
+	return mac.Sum(nil), nil
+}
+
+// seal encrypts the contents of the object with the name, which is authenticated along with
+// them so that objects cannot be swapped, and prefixes them with the nonce.
+func seal(dataKey []byte, name string, contents []byte) ([]byte, error) {
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(contents)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, contents, []byte(name)), nil
+}
+
+// open decrypts the contents sealed for the object with the name.
+func open(dataKey []byte, name string, sealed []byte) ([]byte, error) {
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("encrypted object is too short")
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, []byte(name))
+}
+
+func newAEAD(dataKey []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// awsKeyWrapper wraps data keys with an AWS KMS key.
+type awsKeyWrapper struct {
+	client *awskms.Client
+	keyID  string
+}
+
+func newAWSKeyWrapper(ctx context.Context, keyID string) (keyWrapper, error) {
+	cfg, err := awsconfig.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS configuration: %w", err)
+	}
+	return &awsKeyWrapper{client: awskms.NewFromConfig(cfg), keyID: keyID}, nil
+}
+
+func (w *awsKeyWrapper) wrap(ctx context.Context, dataKey []byte) ([]byte, error) {
+	out, err := w.client.Encrypt(ctx, &awskms.EncryptInput{
+		KeyId:     aws.String(w.keyID),
+		Plaintext: dataKey,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out.CiphertextBlob, nil
+}
+
+func (w *awsKeyWrapper) unwrap(ctx context.Context, wrapped []byte) ([]byte, error) {
+	// The wrapped key identifies the symmetric key and its version which wrapped it, so it is
+	// unwrapped whether the key has since been rotated or an alias now points to another key.
+	out, err := w.client.Decrypt(ctx, &awskms.DecryptInput{
+		CiphertextBlob: wrapped,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out.Plaintext, nil
+}
+
+func (w *awsKeyWrapper) close() error {
+	return nil
+}
+
+// gcpKeyWrapper wraps data keys with a Google Cloud KMS key.
+type gcpKeyWrapper struct {
+	client *kms.KeyManagementClient
+	name   string
+}
+
+func newGCPKeyWrapper(ctx context.Context, name string) (keyWrapper, error) {
+	client, err := kms.NewKeyManagementClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Cloud KMS client: %w", err)
+	}
+	return &gcpKeyWrapper{client: client, name: name}, nil
+}
+
+func (w *gcpKeyWrapper) wrap(ctx context.Context, dataKey []byte) ([]byte, error) {
+	resp, err := w.client.Encrypt(ctx, &kmspb.EncryptRequest{
+		Name:      w.name,
+		Plaintext: dataKey,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Ciphertext, nil
+}
+
+func (w *gcpKeyWrapper) unwrap(ctx context.Context, wrapped []byte) ([]byte, error) {
+	// The wrapped key identifies the version of the key which wrapped it, so it is unwrapped after
+	// the key has been rotated, as long as that version is enabled.
+	resp, err := w.client.Decrypt(ctx, &kmspb.DecryptRequest{
+		Name:       w.name,
+		Ciphertext: wrapped,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Plaintext, nil
+}
+
+func (w *gcpKeyWrapper) close() error {
+	return w.client.Close()
+}
@@ -0,0 +1,223 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/internal/testserver"
+)
+
+// testKMS is a KMS holding keys in memory, registered as the testkms scheme.
+type testKMS struct {
+	keys map[string][]byte
+}
+
+func newTestKMS(t *testing.T, keyNames ...string) *testKMS {
+	kms := &testKMS{keys: make(map[string][]byte)}
+	for _, name := range keyNames {
+		key := make([]byte, dataKeySize)
+		_, err := rand.Read(key)
+		require.NoError(t, err)
+		kms.keys[name] = key
+	}
+
+	keyWrappers["testkms"] = func(_ context.Context, name string) (keyWrapper, error) {
+		return &testKeyWrapper{kms: kms, name: name}, nil
+	}
+	t.Cleanup(func() {
+		delete(keyWrappers, "testkms")
+	})
+	return kms
+}
+
+type testKeyWrapper struct {
+	kms  *testKMS
+	name string
+}
+
+func (w *testKeyWrapper) key() ([]byte, error) {
+	key, ok := w.kms.keys[w.name]
+	if !ok {
+		return nil, fmt.Errorf("key %s is not enabled", w.name)
+	}
+	return key, nil
+}
+
+func (w *testKeyWrapper) wrap(_ context.Context, dataKey []byte) ([]byte, error) {
+	key, err := w.key()
+	if err != nil {
+		return nil, err
+	}
+	return seal(key, w.name, dataKey)
+}
+
+func (w *testKeyWrapper) unwrap(_ context.Context, wrapped []byte) ([]byte, error) {
+	key, err := w.key()
+	if err != nil {
+		return nil, err
+	}
+	return open(key, w.name, wrapped)
+}
+
+func (w *testKeyWrapper) close() error {
+	return nil
+}
+
+func TestEncryptedBackup(t *testing.T) {
+	kms := newTestKMS(t, "first", "second")
+
+	source, cleanup, _, _ := testserver.NewTestServer(require.New(t), 0, time.Hour, true, testfixtures.StandardDatastoreWithCaveatedData)
+	t.Cleanup(cleanup)
+
+	dir := t.TempDir()
+	sink, err := OpenSink(context.Background(), dir)
+	require.NoError(t, err)
+
+	manifest, err := Create(context.Background(), source, sink, Config{ChunkSize: 5, EncryptionKey: "testkms://first"})
+	require.NoError(t, err)
+	require.Equal(t, encryptionAES256GCM, manifest.Encryption.Algorithm)
+	require.Equal(t, "testkms://first", manifest.Encryption.KeyURI)
+
+	// The objects cannot be read without the data key.
+	stored, err := os.ReadFile(filepath.Join(dir, manifest.Schema.Name))
+	require.NoError(t, err)
+	_, err = readObject(context.Background(), sink, manifest.Schema, nil)
+	require.ErrorContains(t, err, "failed to decompress")
+	require.False(t, bytes.Contains(stored, []byte("definition")))
+
+	expected := exportRelationships(t, source)
+
+	t.Run("restore with the recorded key", func(t *testing.T) {
+		target, cleanup, _, _ := testserver.NewTestServer(require.New(t), 0, time.Hour, true, testfixtures.EmptyDatastore)
+		t.Cleanup(cleanup)
+
+		summary, err := Restore(context.Background(), target, sink, RestoreConfig{})
+		require.NoError(t, err)
+		require.Equal(t, manifest.Relationships, summary.Relationships)
+		require.ElementsMatch(t, expected, exportRelationships(t, target))
+	})
+
+	t.Run("restore with another key", func(t *testing.T) {
+		target, cleanup, _, _ := testserver.NewTestServer(require.New(t), 0, time.Hour, true, testfixtures.EmptyDatastore)
+		t.Cleanup(cleanup)
+
+		_, err := Restore(context.Background(), target, sink, RestoreConfig{EncryptionKey: "testkms://second"})
+		require.ErrorContains(t, err, "failed to unwrap data key with testkms://second")
+		require.Empty(t, exportRelationships(t, target))
+	})
+
+	t.Run("restore after the key is rewrapped and retired", func(t *testing.T) {
+		rewrapped, err := RewrapKey(context.Background(), sink, "testkms://second")
+		require.NoError(t, err)
+		require.Equal(t, "testkms://second", rewrapped.Encryption.KeyURI)
+		require.Equal(t, manifest.Chunks, rewrapped.Chunks)
+
+		delete(kms.keys, "first")
+
+		target, cleanup, _, _ := testserver.NewTestServer(require.New(t), 0, time.Hour, true, testfixtures.EmptyDatastore)
+		t.Cleanup(cleanup)
+
+		summary, err := Restore(context.Background(), target, sink, RestoreConfig{})
+		require.NoError(t, err)
+		require.Equal(t, manifest.Relationships, summary.Relationships)
+		require.ElementsMatch(t, expected, exportRelationships(t, target))
+	})
+}
+
+func TestEncryptedBackupManifestIsAuthenticated(t *testing.T) {
+	newTestKMS(t, "first")
+
+	source, cleanup, _, _ := testserver.NewTestServer(require.New(t), 0, time.Hour, true, testfixtures.StandardDatastoreWithData)
+	t.Cleanup(cleanup)
+
+	dir := t.TempDir()
+	sink, err := OpenSink(context.Background(), dir)
+	require.NoError(t, err)
+
+	manifest, err := Create(context.Background(), source, sink, Config{ChunkSize: 1_000, EncryptionKey: "testkms://first"})
+	require.NoError(t, err)
+	require.NotEmpty(t, manifest.MAC)
+
+	stored, err := ReadManifest(context.Background(), sink, "")
+	require.NoError(t, err)
+	require.Equal(t, manifest.Chunks, stored.Chunks)
+
+	writeManifestForTesting := func(t *testing.T, modify func(manifest *Manifest)) {
+		var tampered Manifest
+		contents, err := os.ReadFile(filepath.Join(dir, manifestName))
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(contents, &tampered))
+
+		modify(&tampered)
+		contents, err = json.Marshal(tampered)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, manifestName), contents, 0o600))
+	}
+
+	t.Run("tampered manifest", func(t *testing.T) {
+		writeManifestForTesting(t, func(manifest *Manifest) {
+			manifest.Chunks = manifest.Chunks[:0]
+			manifest.Relationships = 0
+		})
+
+		_, err := ReadManifest(context.Background(), sink, "")
+		require.ErrorContains(t, err, "does not match its MAC")
+	})
+
+	t.Run("manifest stripped of its encryption", func(t *testing.T) {
+		writeManifestForTesting(t, func(manifest *Manifest) {
+			manifest.Encryption = nil
+			manifest.MAC = nil
+		})
+
+		_, err := ReadManifest(context.Background(), sink, "testkms://first")
+		require.ErrorContains(t, err, "backup is not encrypted")
+	})
+}
+
+func TestRewrapKeyRequiresEncryptedBackup(t *testing.T) {
+	source, cleanup, _, _ := testserver.NewTestServer(require.New(t), 0, time.Hour, true, testfixtures.StandardDatastoreWithData)
+	t.Cleanup(cleanup)
+
+	sink, err := OpenSink(context.Background(), t.TempDir())
+	require.NoError(t, err)
+
+	_, err = Create(context.Background(), source, sink, Config{ChunkSize: 1_000})
+	require.NoError(t, err)
+
+	_, err = RewrapKey(context.Background(), sink, "awskms://alias/backups")
+	require.ErrorContains(t, err, "backup is not encrypted")
+}
+
+func TestSealedObjectsAreBoundToTheirNames(t *testing.T) {
+	dataKey := make([]byte, dataKeySize)
+	_, err := rand.Read(dataKey)
+	require.NoError(t, err)
+
+	sealed, err := seal(dataKey, "relationships-000000.ndjson.zst", []byte("contents"))
+	require.NoError(t, err)
+
+	opened, err := open(dataKey, "relationships-000000.ndjson.zst", sealed)
+	require.NoError(t, err)
+	require.Equal(t, []byte("contents"), opened)
+
+	_, err = open(dataKey, "relationships-000001.ndjson.zst", sealed)
+	require.Error(t, err)
+}
+
+func TestOpenKeyWrapper(t *testing.T) {
+	for _, uri := range []string{"", "awskms://", "vault://transit/backups", "alias/backups"} {
+		_, err := openKeyWrapper(context.Background(), uri)
+		require.ErrorContains(t, err, "invalid encryption key", uri)
+	}
+}
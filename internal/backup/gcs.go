@@ -0,0 +1,58 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"cloud.google.com/go/storage"
+)
+
+// gcsSink stores objects in a Google Cloud Storage bucket, uploading them in resumable chunks.
+type gcsSink struct {
+	client *storage.Client
+	bucket string
+	prefix string
+}
+
+func newGCSSink(ctx context.Context, bucket, prefix string) (*gcsSink, error) {
+	client, err := storage.NewClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCS client: %w", err)
+	}
+	return &gcsSink{client: client, bucket: bucket, prefix: prefix}, nil
+}
+
+func (s *gcsSink) Write(ctx context.Context, name string, body io.Reader) error {
+	key := objectKey(s.prefix, name)
+
+	// Canceling the context of the writer abandons the upload, so that a failed copy does not
+	// leave a partial object.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	writer := s.client.Bucket(s.bucket).Object(key).NewWriter(ctx)
+	writer.ChunkSize = uploadPartSize
+	if _, err := io.Copy(writer, body); err != nil {
+		cancel()
+		_ = writer.Close()
+		return fmt.Errorf("failed to upload gs://%s/%s: %w", s.bucket, key, err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to upload gs://%s/%s: %w", s.bucket, key, err)
+	}
+	return nil
+}
+
+func (s *gcsSink) Read(ctx context.Context, name string) (io.ReadCloser, error) {
+	key := objectKey(s.prefix, name)
+	reader, err := s.client.Bucket(s.bucket).Object(key).NewReader(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download gs://%s/%s: %w", s.bucket, key, err)
+	}
+	return reader, nil
+}
+
+func (s *gcsSink) Close() error {
+	return s.client.Close()
+}
@@ -0,0 +1,220 @@
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/klauspost/compress/zstd"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	log "github.com/authzed/spicedb/internal/logging"
+)
+
+// importBatchSize is the number of relationships sent in each message when importing a chunk.
+const importBatchSize = 1_000
+
+// RestoreConfig is the configuration of a restore.
+type RestoreConfig struct {
+	// EncryptionKey, if not empty, is the URI of the KMS key unwrapping the data key of an
+	// encrypted backup, instead of the key recorded in its manifest. The backup must then be
+	// encrypted.
+	EncryptionKey string
+}
+
+// RestoreSummary is the result of a restore.
+type RestoreSummary struct {
+	// Revision is the ZedToken of the revision at which the backup was read.
+	Revision string `json:"revision"`
+
+	// Relationships is the number of relationships restored.
+	Relationships uint64 `json:"relationships"`
+
+	// DurationSeconds is the duration of the restore, in seconds.
+	DurationSeconds float64 `json:"durationSeconds"`
+}
+
+// ReadManifest returns the manifest of the backup in the sink. The data key of an encrypted backup
+// is unwrapped by the KMS key recorded in its manifest or, if not empty, by the KMS key with the
+// URI encryptionKey, and the manifest is verified against its MAC. If encryptionKey is not empty,
+// the backup must be encrypted, so that a manifest stripped of its encryption is rejected.
+func ReadManifest(ctx context.Context, sink Sink, encryptionKey string) (*Manifest, error) {
+	reader, err := sink.Read(ctx, manifestName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest, the backup may be incomplete: %w", err)
+	}
+	defer reader.Close()
+
+	var manifest Manifest
+	if err := json.NewDecoder(reader).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+
+	if manifest.Version != manifestVersion {
+		return nil, fmt.Errorf("unsupported backup version %d", manifest.Version)
+	}
+	if manifest.Compression != compressionZstd {
+		return nil, fmt.Errorf("unsupported backup compression `%s`", manifest.Compression)
+	}
+
+	if manifest.Encryption == nil {
+		if encryptionKey != "" {
+			return nil, errors.New("backup is not encrypted")
+		}
+		return &manifest, nil
+	}
+
+	manifest.dataKey, err = manifest.Encryption.dataKey(ctx, encryptionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	mac, err := authenticate(manifest.dataKey, &manifest)
+	if err != nil {
+		return nil, err
+	}
+	if !hmac.Equal(mac, manifest.MAC) {
+		return nil, errors.New("manifest does not match its MAC, the backup may have been tampered with")
+	}
+	return &manifest, nil
+}
+
+// Restore writes the schema and relationships of the backup in the sink to the SpiceDB instance
+// behind the connection, which should not have any relationships. Each chunk is verified against
+// the checksum in the manifest before it is imported, in a transaction of its own; a restore
+// which fails leaves the chunks imported before the failure.
+//
+// The data key of an encrypted backup is unwrapped by the KMS key recorded in its manifest, unless
+// another is configured, as when the backup was wrapped by a key since retired.
+func Restore(ctx context.Context, conn grpc.ClientConnInterface, sink Sink, config RestoreConfig) (*RestoreSummary, error) {
+	manifest, err := ReadManifest(ctx, sink, config.EncryptionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	summary := &RestoreSummary{Revision: manifest.Revision}
+
+	schema, err := readObject(ctx, sink, manifest.Schema, manifest.dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := v1.NewSchemaServiceClient(conn).WriteSchema(ctx, &v1.WriteSchemaRequest{Schema: string(schema)}); err != nil {
+		return nil, fmt.Errorf("failed to write schema: %w", err)
+	}
+
+	client := v1.NewPermissionsServiceClient(conn)
+	for _, chunk := range manifest.Chunks {
+		contents, err := readObject(ctx, sink, chunk.Object, manifest.dataKey)
+		if err != nil {
+			return summary, err
+		}
+
+		rels, err := decodeChunk(contents)
+		if err != nil {
+			return summary, fmt.Errorf("invalid chunk %s: %w", chunk.Name, err)
+		}
+		if uint64(len(rels)) != chunk.Relationships {
+			return summary, fmt.Errorf("chunk %s holds %d relationships, expected %d", chunk.Name, len(rels), chunk.Relationships)
+		}
+
+		if err := importChunk(ctx, client, rels); err != nil {
+			return summary, fmt.Errorf("failed to import chunk %s: %w", chunk.Name, err)
+		}
+
+		summary.Relationships += chunk.Relationships
+		log.Ctx(ctx).Info().Str("chunk", chunk.Name).Uint64("relationships", summary.Relationships).Msg("restored backup chunk")
+	}
+
+	summary.DurationSeconds = time.Since(start).Seconds()
+	return summary, nil
+}
+
+// readObject reads the object from the sink, verifies it against its size and checksum, and
+// returns its contents, decrypted with the data key if not nil and decompressed.
+func readObject(ctx context.Context, sink Sink, object Object, dataKey []byte) ([]byte, error) {
+	reader, err := sink.Read(ctx, object.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", object.Name, err)
+	}
+	defer reader.Close()
+
+	stored, err := io.ReadAll(io.LimitReader(reader, object.Size+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", object.Name, err)
+	}
+
+	if int64(len(stored)) != object.Size {
+		return nil, fmt.Errorf("object %s has a size of %d bytes, expected %d", object.Name, len(stored), object.Size)
+	}
+
+	checksum := sha256.Sum256(stored)
+	if hex.EncodeToString(checksum[:]) != object.SHA256 {
+		return nil, fmt.Errorf("object %s does not match its checksum", object.Name)
+	}
+
+	if dataKey != nil {
+		stored, err = open(dataKey, object.Name, stored)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt %s: %w", object.Name, err)
+		}
+	}
+
+	decoder, err := zstd.NewReader(nil)
+	if err != nil {
+		return nil, err
+	}
+	defer decoder.Close()
+
+	contents, err := decoder.DecodeAll(stored, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress %s: %w", object.Name, err)
+	}
+	return contents, nil
+}
+
+func decodeChunk(contents []byte) ([]*v1.Relationship, error) {
+	var rels []*v1.Relationship
+	scanner := bufio.NewScanner(bytes.NewReader(contents))
+	scanner.Buffer(nil, max(len(contents)+1, bufio.MaxScanTokenSize))
+	for scanner.Scan() {
+		rel := &v1.Relationship{}
+		if err := protojson.Unmarshal(scanner.Bytes(), rel); err != nil {
+			return nil, fmt.Errorf("invalid relationship on line %d: %w", len(rels)+1, err)
+		}
+		rels = append(rels, rel)
+	}
+	return rels, scanner.Err()
+}
+
+func importChunk(ctx context.Context, client v1.PermissionsServiceClient, rels []*v1.Relationship) error {
+	stream, err := client.ImportBulkRelationships(ctx)
+	if err != nil {
+		return err
+	}
+
+	for start := 0; start < len(rels); start += importBatchSize {
+		end := min(start+importBatchSize, len(rels))
+		if err := stream.Send(&v1.ImportBulkRelationshipsRequest{Relationships: rels[start:end]}); err != nil {
+			// The error of a stream closed by the server is returned by CloseAndRecv.
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return err
+		}
+	}
+
+	_, err = stream.CloseAndRecv()
+	return err
+}
@@ -0,0 +1,65 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// s3Sink stores objects in an S3 bucket, uploading them in parts.
+type s3Sink struct {
+	client   *s3.Client
+	uploader *manager.Uploader
+	bucket   string
+	prefix   string
+}
+
+func newS3Sink(ctx context.Context, bucket, prefix string) (*s3Sink, error) {
+	cfg, err := awsconfig.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS configuration: %w", err)
+	}
+
+	client := s3.NewFromConfig(cfg)
+	return &s3Sink{
+		client: client,
+		uploader: manager.NewUploader(client, func(u *manager.Uploader) {
+			u.PartSize = uploadPartSize
+		}),
+		bucket: bucket,
+		prefix: prefix,
+	}, nil
+}
+
+func (s *s3Sink) Write(ctx context.Context, name string, body io.Reader) error {
+	key := objectKey(s.prefix, name)
+	if _, err := s.uploader.Upload(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+		Body:   body,
+	}); err != nil {
+		return fmt.Errorf("failed to upload s3://%s/%s: %w", s.bucket, key, err)
+	}
+	return nil
+}
+
+func (s *s3Sink) Read(ctx context.Context, name string) (io.ReadCloser, error) {
+	key := objectKey(s.prefix, name)
+	out, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to download s3://%s/%s: %w", s.bucket, key, err)
+	}
+	return out.Body, nil
+}
+
+func (s *s3Sink) Close() error {
+	return nil
+}
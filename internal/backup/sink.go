@@ -0,0 +1,116 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// uploadPartSize is the size of the parts in which objects are uploaded to object storage.
+const uploadPartSize = 16 * 1024 * 1024
+
+// Sink is the storage to which backups are written and from which they are restored.
+type Sink interface {
+	// Write stores the object with the name, replacing any existing one. The object is only
+	// visible once it has been completely written.
+	Write(ctx context.Context, name string, body io.Reader) error
+
+	// Read returns the contents of the object with the name.
+	Read(ctx context.Context, name string) (io.ReadCloser, error)
+
+	// Close releases the resources of the sink.
+	Close() error
+}
+
+// OpenSink returns the sink of the location, which is either a local directory or the prefix of
+// objects in a bucket, written `s3://bucket/prefix`, `gs://bucket/prefix` or
+// `azblob://container/prefix`.
+//
+// S3 and GCS are accessed with the default credentials of their SDKs. Azure Blob Storage is
+// accessed with the connection string in the AZURE_STORAGE_CONNECTION_STRING environment
+// variable.
+func OpenSink(ctx context.Context, location string) (Sink, error) {
+	scheme, rest, ok := strings.Cut(location, "://")
+	if !ok {
+		return newFileSink(location)
+	}
+
+	if scheme == "file" {
+		return newFileSink(rest)
+	}
+
+	bucket, prefix, _ := strings.Cut(rest, "/")
+	if bucket == "" {
+		return nil, fmt.Errorf("missing bucket in backup location `%s`", location)
+	}
+
+	switch scheme {
+	case "s3":
+		return newS3Sink(ctx, bucket, prefix)
+	case "gs":
+		return newGCSSink(ctx, bucket, prefix)
+	case "azblob":
+		return newAzureBlobSink(bucket, prefix)
+	default:
+		return nil, fmt.Errorf("unknown scheme `%s` in backup location `%s`: must be one of s3, gs, azblob or file", scheme, location)
+	}
+}
+
+// objectKey returns the key of the object with the name under the prefix.
+func objectKey(prefix, name string) string {
+	prefix = strings.Trim(prefix, "/")
+	if prefix == "" {
+		return name
+	}
+	return prefix + "/" + name
+}
+
+// fileSink stores objects as the files of a local directory.
+type fileSink struct {
+	dir string
+}
+
+func newFileSink(dir string) (*fileSink, error) {
+	if dir == "" {
+		return nil, errors.New("missing directory in backup location")
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create backup directory: %w", err)
+	}
+	return &fileSink{dir: dir}, nil
+}
+
+func (s *fileSink) Write(_ context.Context, name string, body io.Reader) error {
+	path := filepath.Join(s.dir, name)
+	temp, err := os.CreateTemp(s.dir, name+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	defer os.Remove(temp.Name())
+
+	if _, err := io.Copy(temp, body); err != nil {
+		temp.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := temp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	if err := os.Rename(temp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+func (s *fileSink) Read(_ context.Context, name string) (io.ReadCloser, error) {
+	return os.Open(filepath.Join(s.dir, name))
+}
+
+func (s *fileSink) Close() error {
+	return nil
+}
@@ -0,0 +1,62 @@
+package caveats
+
+import (
+	"context"
+	"slices"
+
+	"github.com/authzed/spicedb/pkg/caveats"
+	caveattypes "github.com/authzed/spicedb/pkg/caveats/types"
+	"github.com/authzed/spicedb/pkg/datastore"
+)
+
+// FalseCaveatNames returns the sorted names of the caveats which evaluate to false under the
+// given context alone, without any context written on relationships.
+//
+// Relationships with one of these caveats and no written context can never satisfy a check
+// made with the context, and so need not be loaded. Caveats whose evaluation is partial or
+// fails under the context are never returned, leaving their evaluation to the check itself.
+func FalseCaveatNames(ctx context.Context, reader datastore.CaveatReader, caveatContext map[string]any) ([]string, error) {
+	if len(caveatContext) == 0 {
+		return nil, nil
+	}
+
+	ctx, span := tracer.Start(ctx, "FalseCaveatNames")
+	defer span.End()
+
+	caveatDefs, err := reader.ListAllCaveats(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, caveatDef := range caveatDefs {
+		parameterTypes, err := caveattypes.DecodeParameterTypes(caveatDef.Definition.ParameterTypes)
+		if err != nil {
+			continue
+		}
+
+		compiled, err := caveats.DeserializeCaveat(caveatDef.Definition.SerializedExpression, parameterTypes)
+		if err != nil {
+			continue
+		}
+
+		typedParameters, err := caveats.ConvertContextToParameters(
+			caveatContext,
+			caveatDef.Definition.ParameterTypes,
+			caveats.SkipUnknownParameters,
+		)
+		if err != nil {
+			continue
+		}
+
+		result, err := caveats.EvaluateCaveat(compiled, typedParameters)
+		if err != nil || result.IsPartial() || result.Value() {
+			continue
+		}
+
+		names = append(names, caveatDef.Definition.Name)
+	}
+
+	slices.Sort(names)
+	return names, nil
+}
@@ -629,3 +629,53 @@ func (f noCaveatsReader) LookupCaveatsWithNames(ctx context.Context, names []str
 func (f noCaveatsReader) ListAllCaveats(ctx context.Context) ([]datastore.RevisionedCaveat, error) {
 	return nil, errors.New("should not be called")
 }
+
+func TestFalseCaveatNames(t *testing.T) {
+	req := require.New(t)
+
+	rawDS, err := dsfortesting.NewMemDBDatastoreForTesting(0, 0, memdb.DisableGC)
+	req.NoError(err)
+
+	ds, _ := testfixtures.DatastoreFromSchemaAndTestRelationships(rawDS, `
+				caveat is_weekday(day string) {
+					day != "saturday" && day != "sunday"
+				}
+
+				caveat has_quota(remaining int) {
+					remaining > 0
+				}
+
+				caveat in_region(region string, allowed list<string>) {
+					region in allowed
+				}
+
+				caveat is_open(day string) {
+					day == "monday"
+				}
+				`, nil, req)
+
+	headRevision, err := ds.HeadRevision(context.Background())
+	req.NoError(err)
+
+	reader := ds.SnapshotReader(headRevision)
+
+	tcs := []struct {
+		name          string
+		context       map[string]any
+		expectedNames []string
+	}{
+		{"no context", nil, nil},
+		{"all caveats false", map[string]any{"day": "sunday", "remaining": float64(0)}, []string{"has_quota", "is_open", "is_weekday"}},
+		{"some caveats true", map[string]any{"day": "monday", "remaining": float64(0)}, []string{"has_quota"}},
+		{"partial caveat", map[string]any{"region": "eu"}, nil},
+		{"invalid parameter type", map[string]any{"remaining": "lots", "day": "tuesday"}, []string{"is_open"}},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			names, err := caveats.FalseCaveatNames(context.Background(), reader, tc.context)
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedNames, names)
+		})
+	}
+}
@@ -0,0 +1,231 @@
+// Package compression provides the zstd and brotli compressors which can be enabled on the gRPC
+// server and the HTTP gateway, to reduce the bandwidth used by large responses.
+package compression
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// Zstd is the name of the zstd compression algorithm.
+	Zstd = "zstd"
+
+	// Brotli is the name of the brotli compression algorithm.
+	Brotli = "br"
+
+	// Gzip is the name of the gzip compression algorithm.
+	Gzip = "gzip"
+)
+
+// GRPCCompressors are the names of the compressors which can be enabled for gRPC.
+var GRPCCompressors = []string{Zstd, Brotli}
+
+// The compressors are registered at init time, as encoding.RegisterCompressor is not safe to call
+// concurrently with the use of the registry. Servers reject the messages compressed with those
+// which are not enabled, with the interceptors below.
+func init() {
+	encoding.RegisterCompressor(&zstdCompressor{})
+	encoding.RegisterCompressor(&brotliCompressor{})
+}
+
+// ValidateGRPCCompressors returns an error if any of the named compressors is unknown.
+func ValidateGRPCCompressors(names []string) error {
+	for _, name := range names {
+		if !slices.Contains(GRPCCompressors, name) {
+			return fmt.Errorf("unknown gRPC compressor %q, must be one of %v", name, GRPCCompressors)
+		}
+	}
+	return nil
+}
+
+// recvCompressStream is implemented by the transport streams of gRPC servers, returning the
+// compressor with which the messages of the client are compressed.
+type recvCompressStream interface {
+	RecvCompress() string
+}
+
+// checkCompressor returns an Unimplemented error, as gRPC does for unregistered compressors, if
+// the request is compressed with one of GRPCCompressors which is not enabled. gRPC compresses
+// responses with the compressor of the request, so this also keeps it from sending them.
+func checkCompressor(ctx context.Context, enabled []string) error {
+	stream, ok := grpc.ServerTransportStreamFromContext(ctx).(recvCompressStream)
+	if !ok {
+		return nil
+	}
+
+	name := stream.RecvCompress()
+	if slices.Contains(GRPCCompressors, name) && !slices.Contains(enabled, name) {
+		return status.Errorf(codes.Unimplemented, "grpc: compressor is not enabled for grpc-encoding %q", name)
+	}
+	return nil
+}
+
+// UnaryServerInterceptor returns a new unary server interceptor rejecting the requests compressed
+// with one of GRPCCompressors which is not enabled.
+func UnaryServerInterceptor(enabled []string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := checkCompressor(ctx, enabled); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns a new stream server interceptor rejecting the requests
+// compressed with one of GRPCCompressors which is not enabled.
+func StreamServerInterceptor(enabled []string) grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := checkCompressor(stream.Context(), enabled); err != nil {
+			return err
+		}
+		return handler(srv, stream)
+	}
+}
+
+// zstdCompressor is a gRPC compressor using zstd, pooling encoders and decoders.
+type zstdCompressor struct {
+	encoders sync.Pool
+	decoders sync.Pool
+}
+
+func (c *zstdCompressor) Name() string {
+	return Zstd
+}
+
+func (c *zstdCompressor) Compress(w io.Writer) (io.WriteCloser, error) {
+	encoder, ok := c.encoders.Get().(*zstd.Encoder)
+	if !ok {
+		var err error
+		encoder, err = zstd.NewWriter(w, zstd.WithEncoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		encoder.Reset(w)
+	}
+	return &zstdWriteCloser{encoder, c}, nil
+}
+
+type zstdWriteCloser struct {
+	*zstd.Encoder
+	compressor *zstdCompressor
+}
+
+func (w *zstdWriteCloser) Close() error {
+	err := w.Encoder.Close()
+	w.compressor.encoders.Put(w.Encoder)
+	return err
+}
+
+func (c *zstdCompressor) Decompress(r io.Reader) (io.Reader, error) {
+	decoder, ok := c.decoders.Get().(*zstd.Decoder)
+	if !ok {
+		var err error
+		decoder, err = zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+	} else if err := decoder.Reset(r); err != nil {
+		return nil, err
+	}
+	return &zstdReader{decoder, c}, nil
+}
+
+// zstdReader returns its decoder to the pool once the message is fully read.
+type zstdReader struct {
+	decoder    *zstd.Decoder
+	compressor *zstdCompressor
+}
+
+func (r *zstdReader) Read(p []byte) (int, error) {
+	if r.decoder == nil {
+		return 0, io.EOF
+	}
+
+	n, err := r.decoder.Read(p)
+	if err == io.EOF {
+		r.compressor.decoders.Put(r.decoder)
+		r.decoder = nil
+	}
+	return n, err
+}
+
+// brotliCompressor is a gRPC compressor using brotli, pooling writers and readers.
+type brotliCompressor struct {
+	writers sync.Pool
+	readers sync.Pool
+}
+
+func (c *brotliCompressor) Name() string {
+	return Brotli
+}
+
+func (c *brotliCompressor) Compress(w io.Writer) (io.WriteCloser, error) {
+	writer, ok := c.writers.Get().(*brotli.Writer)
+	if !ok {
+		writer = brotli.NewWriterLevel(w, brotli.DefaultCompression)
+	} else {
+		writer.Reset(w)
+	}
+	return &brotliWriteCloser{writer, c}, nil
+}
+
+type brotliWriteCloser struct {
+	*brotli.Writer
+	compressor *brotliCompressor
+}
+
+func (w *brotliWriteCloser) Close() error {
+	err := w.Writer.Close()
+	w.compressor.writers.Put(w.Writer)
+	return err
+}
+
+func (c *brotliCompressor) Decompress(r io.Reader) (io.Reader, error) {
+	reader, ok := c.readers.Get().(*brotli.Reader)
+	if !ok {
+		reader = brotli.NewReader(r)
+	} else if err := reader.Reset(r); err != nil {
+		return nil, err
+	}
+	return &brotliReader{reader, c}, nil
+}
+
+// brotliReader returns its reader to the pool once the message is fully read.
+type brotliReader struct {
+	reader     *brotli.Reader
+	compressor *brotliCompressor
+}
+
+func (r *brotliReader) Read(p []byte) (int, error) {
+	if r.reader == nil {
+		return 0, io.EOF
+	}
+
+	n, err := r.reader.Read(p)
+	if err == io.EOF {
+		r.compressor.readers.Put(r.reader)
+		r.reader = nil
+	}
+	return n, err
+}
+
+func validateEncodings(encodings []string, allowed []string) error {
+	for _, encoding := range encodings {
+		if !slices.Contains(allowed, encoding) {
+			return fmt.Errorf("unknown compression %q, must be one of %v", encoding, allowed)
+		}
+	}
+	return nil
+}
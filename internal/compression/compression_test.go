@@ -0,0 +1,192 @@
+package compression
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestGRPCCompressors(t *testing.T) {
+	require.NoError(t, ValidateGRPCCompressors(GRPCCompressors))
+	require.ErrorContains(t, ValidateGRPCCompressors([]string{"lzma"}), "unknown gRPC compressor")
+
+	message := []byte(strings.Repeat("document:somedocument#viewer@user:someuser\n", 100))
+	for _, name := range GRPCCompressors {
+		t.Run(name, func(t *testing.T) {
+			compressor := encoding.GetCompressor(name)
+			require.NotNil(t, compressor)
+
+			// Compressors are pooled, so round trip more than once.
+			for range 3 {
+				var compressed bytes.Buffer
+				w, err := compressor.Compress(&compressed)
+				require.NoError(t, err)
+				_, err = w.Write(message)
+				require.NoError(t, err)
+				require.NoError(t, w.Close())
+				require.Less(t, compressed.Len(), len(message))
+
+				r, err := compressor.Decompress(&compressed)
+				require.NoError(t, err)
+				decompressed, err := io.ReadAll(r)
+				require.NoError(t, err)
+				require.Equal(t, message, decompressed)
+			}
+		})
+	}
+}
+
+func TestServerInterceptorsRejectCompressorsNotEnabled(t *testing.T) {
+	for _, enabled := range [][]string{nil, {Zstd}} {
+		t.Run(strings.Join(enabled, ","), func(t *testing.T) {
+			listener := bufconn.Listen(1024 * 1024)
+			server := grpc.NewServer(
+				grpc.ChainUnaryInterceptor(UnaryServerInterceptor(enabled)),
+				grpc.ChainStreamInterceptor(StreamServerInterceptor(enabled)),
+			)
+			healthpb.RegisterHealthServer(server, health.NewServer())
+			go func() {
+				_ = server.Serve(listener)
+			}()
+			t.Cleanup(server.Stop)
+
+			conn, err := grpc.NewClient("passthrough:///bufnet",
+				grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+					return listener.DialContext(ctx)
+				}),
+				grpc.WithTransportCredentials(insecure.NewCredentials()),
+			)
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = conn.Close() })
+
+			client := healthpb.NewHealthClient(conn)
+			for _, name := range GRPCCompressors {
+				_, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{}, grpc.UseCompressor(name))
+				if slices.Contains(enabled, name) {
+					require.NoError(t, err)
+				} else {
+					require.Equal(t, codes.Unimplemented, status.Code(err))
+				}
+			}
+
+			// Uncompressed requests are always served.
+			_, err = client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestNegotiateEncoding(t *testing.T) {
+	tcs := []struct {
+		acceptEncoding string
+		supported      []string
+		expected       string
+	}{
+		{"", HTTPEncodings, ""},
+		{"gzip", HTTPEncodings, "gzip"},
+		{"gzip, deflate, br, zstd", HTTPEncodings, "zstd"},
+		{"gzip, deflate, br, zstd", []string{Brotli, Gzip}, "br"},
+		{"zstd;q=0, gzip;q=0.5", HTTPEncodings, "gzip"},
+		{"identity", HTTPEncodings, ""},
+		{"*", []string{Gzip}, "gzip"},
+		{"*, gzip;q=0", []string{Gzip, Brotli}, "br"},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.acceptEncoding, func(t *testing.T) {
+			require.Equal(t, tc.expected, negotiateEncoding(tc.acceptEncoding, tc.supported))
+		})
+	}
+}
+
+func decode(t *testing.T, encoding string, body io.Reader) string {
+	var r io.Reader
+	switch encoding {
+	case Zstd:
+		decoder, err := zstd.NewReader(body)
+		require.NoError(t, err)
+		defer decoder.Close()
+		r = decoder
+	case Brotli:
+		r = brotli.NewReader(body)
+	case Gzip:
+		decoder, err := gzip.NewReader(body)
+		require.NoError(t, err)
+		r = decoder
+	default:
+		r = body
+	}
+
+	decoded, err := io.ReadAll(r)
+	require.NoError(t, err)
+	return string(decoded)
+}
+
+func TestHTTPHandler(t *testing.T) {
+	_, err := NewHTTPHandler(http.NotFoundHandler(), []string{"deflate"})
+	require.ErrorContains(t, err, "unknown compression")
+
+	body := strings.Repeat(`{"result":{"resourceObjectId":"somedocument"}}`+"\n", 50)
+	delegate := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		half := len(body) / 2
+		_, _ = w.Write([]byte(body[:half]))
+		w.(http.Flusher).Flush()
+		_, _ = w.Write([]byte(body[half:]))
+	})
+
+	handler, err := NewHTTPHandler(delegate, HTTPEncodings)
+	require.NoError(t, err)
+
+	for _, acceptEncoding := range []string{"", Zstd, Brotli, Gzip} {
+		t.Run(acceptEncoding, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/v1/permissions/resources", nil)
+			if acceptEncoding != "" {
+				req.Header.Set("Accept-Encoding", acceptEncoding)
+			}
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			require.Equal(t, http.StatusOK, recorder.Code)
+			require.Equal(t, "Accept-Encoding", recorder.Header().Get("Vary"))
+			require.Equal(t, acceptEncoding, recorder.Header().Get("Content-Encoding"))
+			require.True(t, recorder.Flushed)
+			if acceptEncoding != "" {
+				require.Less(t, recorder.Body.Len(), len(body))
+			}
+			require.Equal(t, body, decode(t, acceptEncoding, recorder.Body))
+		})
+	}
+}
+
+func TestHTTPHandlerDisabled(t *testing.T) {
+	delegate := http.NotFoundHandler()
+	handler, err := NewHTTPHandler(delegate, nil)
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	handler.ServeHTTP(recorder, req)
+	require.Empty(t, recorder.Header().Get("Content-Encoding"))
+}
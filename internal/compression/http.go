@@ -0,0 +1,164 @@
+package compression
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+)
+
+// HTTPEncodings are the content encodings which can be enabled for HTTP responses.
+var HTTPEncodings = []string{Zstd, Brotli, Gzip}
+
+type flushWriteCloser interface {
+	io.WriteCloser
+	Flush() error
+}
+
+func newEncoder(encoding string, w io.Writer) (flushWriteCloser, error) {
+	switch encoding {
+	case Zstd:
+		return zstd.NewWriter(w, zstd.WithEncoderConcurrency(1))
+	case Brotli:
+		return brotli.NewWriterLevel(w, brotli.DefaultCompression), nil
+	default:
+		return gzip.NewWriter(w), nil
+	}
+}
+
+// NewHTTPHandler returns a handler which compresses the responses of the delegate with the first
+// of the given encodings accepted by the client, in the order of preference given. Responses
+// already encoded by the delegate are left unchanged. Flushes, such as those of streamed
+// responses, flush the compressed data written so far.
+func NewHTTPHandler(delegate http.Handler, encodings []string) (http.Handler, error) {
+	if err := validateEncodings(encodings, HTTPEncodings); err != nil {
+		return nil, err
+	}
+
+	if len(encodings) == 0 {
+		return delegate, nil
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+
+		encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"), encodings)
+		if encoding == "" || r.Method == http.MethodHead {
+			delegate.ServeHTTP(w, r)
+			return
+		}
+
+		cw := &compressingResponseWriter{ResponseWriter: w, encoding: encoding}
+		defer cw.close()
+		delegate.ServeHTTP(cw, r)
+	}), nil
+}
+
+// negotiateEncoding returns the first of the supported encodings accepted, with a non-zero
+// quality value, in the Accept-Encoding header, or the empty string if none is.
+func negotiateEncoding(acceptEncoding string, supported []string) string {
+	if acceptEncoding == "" {
+		return ""
+	}
+
+	accepted := make(map[string]bool)
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+
+		quality := 1.0
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(q, 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+		accepted[name] = quality > 0
+	}
+
+	for _, encoding := range supported {
+		if accepted, ok := accepted[encoding]; ok {
+			if accepted {
+				return encoding
+			}
+			continue
+		}
+
+		if accepted["*"] {
+			return encoding
+		}
+	}
+	return ""
+}
+
+// compressingResponseWriter compresses the response body, unless the delegate already set a
+// Content-Encoding.
+type compressingResponseWriter struct {
+	http.ResponseWriter
+
+	encoding    string
+	encoder     flushWriteCloser
+	wroteHeader bool
+	passthrough bool
+}
+
+func (cw *compressingResponseWriter) WriteHeader(statusCode int) {
+	if cw.wroteHeader {
+		return
+	}
+	cw.wroteHeader = true
+
+	header := cw.Header()
+	if header.Get("Content-Encoding") != "" || statusCode == http.StatusNoContent || statusCode == http.StatusNotModified {
+		cw.passthrough = true
+	} else {
+		header.Set("Content-Encoding", cw.encoding)
+		header.Del("Content-Length")
+	}
+
+	cw.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (cw *compressingResponseWriter) Write(p []byte) (int, error) {
+	if !cw.wroteHeader {
+		cw.WriteHeader(http.StatusOK)
+	}
+
+	if cw.passthrough {
+		return cw.ResponseWriter.Write(p)
+	}
+
+	if cw.encoder == nil {
+		encoder, err := newEncoder(cw.encoding, cw.ResponseWriter)
+		if err != nil {
+			return 0, err
+		}
+		cw.encoder = encoder
+	}
+	return cw.encoder.Write(p)
+}
+
+func (cw *compressingResponseWriter) Flush() {
+	if cw.encoder != nil {
+		_ = cw.encoder.Flush()
+	}
+
+	if flusher, ok := cw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (cw *compressingResponseWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+func (cw *compressingResponseWriter) close() {
+	if cw.encoder != nil {
+		_ = cw.encoder.Close()
+	}
+}
@@ -340,3 +340,35 @@ func (ch *Changes[R, K]) removeAllChangesBefore(boundRev R) {
 		}
 	}
 }
+
+// SchemaSnapshotChanges returns the full schema at the revision, all of its namespaces and caveats,
+// as the changed definitions of a single change at the revision, for watches which replay the schema
+// before streaming live changes. Returns nil if the schema is empty.
+func SchemaSnapshotChanges(ctx context.Context, reader datastore.Reader, revision datastore.Revision) (*datastore.RevisionChanges, error) {
+	namespaces, err := reader.ListAllNamespaces(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	caveats, err := reader.ListAllCaveats(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(namespaces) == 0 && len(caveats) == 0 {
+		return nil, nil
+	}
+
+	definitions := make([]datastore.SchemaDefinition, 0, len(namespaces)+len(caveats))
+	for _, namespace := range namespaces {
+		definitions = append(definitions, namespace.Definition)
+	}
+	for _, caveat := range caveats {
+		definitions = append(definitions, caveat.Definition)
+	}
+
+	return &datastore.RevisionChanges{
+		Revision:           revision,
+		ChangedDefinitions: definitions,
+	}, nil
+}
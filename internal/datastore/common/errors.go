@@ -34,6 +34,11 @@ func (err SerializationError) GRPCStatus() *status.Status {
 	)
 }
 
+// IsContentionError implements datastore.ErrContention.
+func (err SerializationError) IsContentionError() bool {
+	return true
+}
+
 func (err SerializationError) Unwrap() error {
 	return err.error
 }
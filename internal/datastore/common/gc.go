@@ -36,6 +36,13 @@ var (
 		Help:      "The number of expired relationships deleted by the datastore garbage collection.",
 	})
 
+	gcExpiredRecordsCounter = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "spicedb",
+		Subsystem: "datastore",
+		Name:      "gc_expired_records_total",
+		Help:      "The number of expired internal records deleted by the datastore garbage collection.",
+	})
+
 	gcTransactionsCounter = prometheus.NewCounter(prometheus.CounterOpts{
 		Namespace: "spicedb",
 		Subsystem: "datastore",
@@ -67,6 +74,7 @@ func RegisterGCMetrics() error {
 		gcRelationshipsCounter,
 		gcTransactionsCounter,
 		gcNamespacesCounter,
+		gcExpiredRecordsCounter,
 		gcFailureCounter,
 	} {
 		if err := prometheus.Register(metric); err != nil {
@@ -108,11 +116,45 @@ type GarbageCollector interface {
 	// TxIDBefore returns the highest transaction ID before the provided time.
 	TxIDBefore(context.Context, time.Time) (datastore.Revision, error)
 
-	// DeleteBeforeTx deletes all data before the provided transaction ID.
-	DeleteBeforeTx(ctx context.Context, txID datastore.Revision) (DeletionCounts, error)
+	// DeleteBeforeTx deletes the data of each table before its watermark.
+	DeleteBeforeTx(ctx context.Context, watermarks Watermarks) (DeletionCounts, error)
 
 	// DeleteExpiredRels deletes all relationships that have expired.
 	DeleteExpiredRels(ctx context.Context) (int64, error)
+
+	// DeleteExpiredRecords deletes all internal records that have expired.
+	DeleteExpiredRecords(ctx context.Context) (int64, error)
+
+	// TableRetention returns how long the history of each table is retained.
+	TableRetention() TableRetention
+
+	// PinnedRevisions returns the revisions pinned by unexpired pins, whose history must not be
+	// collected.
+	PinnedRevisions(ctx context.Context) ([]datastore.Revision, error)
+}
+
+// TableRetention is how long the history of each table is retained before being garbage
+// collected. A retention shorter than the GC window, including zero, retains the history of the
+// table for the GC window.
+type TableRetention struct {
+	Relationships time.Duration
+	Namespaces    time.Duration
+}
+
+// Watermarks are the revisions before which the history of each table is garbage collected. The
+// transactions are collected before the oldest watermark, so that the revisions of all the
+// history retained remain known.
+type Watermarks struct {
+	Relationships datastore.Revision
+	Namespaces    datastore.Revision
+	Transactions  datastore.Revision
+}
+
+func (w Watermarks) MarshalZerologObject(e *zerolog.Event) {
+	e.
+		Stringer("relationships", w.Relationships).
+		Stringer("namespaces", w.Namespaces).
+		Stringer("transactions", w.Transactions)
 }
 
 // DeletionCounts tracks the amount of deletions that occurred when calling
@@ -138,6 +180,21 @@ func StartGarbageCollector(ctx context.Context, gc GarbageCollector, interval, w
 	return startGarbageCollectorWithMaxElapsedTime(ctx, gc, interval, window, 0, timeout, gcFailureCounter)
 }
 
+// StartLeaderGarbageCollector loops forever until the context is canceled and
+// performs garbage collection on the provided interval, whenever the node holds
+// the garbage collection lease, so that a single node of the cluster collects
+// garbage. A lease duration of zero disables the election, collecting garbage on
+// every node.
+func StartLeaderGarbageCollector(ctx context.Context, gc GarbageCollector, lm LeaseManager, leaseDuration, interval, window, timeout time.Duration) error {
+	if leaseDuration <= 0 {
+		return StartGarbageCollector(ctx, gc, interval, window, timeout)
+	}
+
+	return RunAsLeader(ctx, lm, GarbageCollectionLease, leaseDuration, func(ctx context.Context) error {
+		return StartGarbageCollector(ctx, gc, interval, window, timeout)
+	})
+}
+
 func startGarbageCollectorWithMaxElapsedTime(ctx context.Context, gc GarbageCollector, interval, window, maxElapsedTime, timeout time.Duration, failureCounter prometheus.Counter) error {
 	backoffInterval := backoff.NewExponentialBackOff()
 	backoffInterval.InitialInterval = interval
@@ -230,21 +287,24 @@ func RunGarbageCollection(gc GarbageCollector, window, timeout time.Duration) er
 		return fmt.Errorf("error retrieving now: %w", err)
 	}
 
-	watermark, err := gc.TxIDBefore(ctx, now.Add(-1*window))
+	watermarks, err := computeWatermarks(ctx, gc, now, window)
 	if err != nil {
 		return fmt.Errorf("error retrieving watermark: %w", err)
 	}
 
-	collected, err := gc.DeleteBeforeTx(ctx, watermark)
+	collected, err := gc.DeleteBeforeTx(ctx, watermarks)
 
 	expiredRelationshipsCount, eerr := gc.DeleteExpiredRels(ctx)
 
+	expiredRecordsCount, rerr := gc.DeleteExpiredRecords(ctx)
+
 	// even if an error happened, garbage would have been collected. This makes sure these are reflected even if the
 	// worker eventually fails or times out.
 	gcRelationshipsCounter.Add(float64(collected.Relationships))
 	gcTransactionsCounter.Add(float64(collected.Transactions))
 	gcNamespacesCounter.Add(float64(collected.Namespaces))
 	gcExpiredRelationshipsCounter.Add(float64(expiredRelationshipsCount))
+	gcExpiredRecordsCounter.Add(float64(expiredRecordsCount))
 	collectionDuration := time.Since(startTime)
 	gcDurationHistogram.Observe(collectionDuration.Seconds())
 
@@ -256,14 +316,74 @@ func RunGarbageCollection(gc GarbageCollector, window, timeout time.Duration) er
 		return fmt.Errorf("error deleting expired relationships in gc: %w", eerr)
 	}
 
+	if rerr != nil {
+		return fmt.Errorf("error deleting expired records in gc: %w", rerr)
+	}
+
 	log.Ctx(ctx).Info().
-		Stringer("highestTxID", watermark).
+		Object("watermarks", watermarks).
 		Dur("duration", collectionDuration).
 		Time("nowTime", now).
 		Interface("collected", collected).
 		Int64("expiredRelationships", expiredRelationshipsCount).
+		Int64("expiredRecords", expiredRecordsCount).
 		Msg("datastore garbage collection completed successfully")
 
 	gc.MarkGCCompleted()
 	return nil
 }
+
+// computeWatermarks returns the watermarks of the tables, holding each one back to the oldest
+// pinned revision.
+func computeWatermarks(ctx context.Context, gc GarbageCollector, now time.Time, window time.Duration) (Watermarks, error) {
+	retention := gc.TableRetention()
+
+	// Retentions of the same duration share their watermark.
+	byRetention := make(map[time.Duration]datastore.Revision, 3)
+	watermarkFor := func(retention time.Duration) (datastore.Revision, error) {
+		retention = max(retention, window)
+		if watermark, ok := byRetention[retention]; ok {
+			return watermark, nil
+		}
+
+		watermark, err := gc.TxIDBefore(ctx, now.Add(-1*retention))
+		if err != nil {
+			return nil, err
+		}
+		byRetention[retention] = watermark
+		return watermark, nil
+	}
+
+	var watermarks Watermarks
+	var err error
+	if watermarks.Relationships, err = watermarkFor(retention.Relationships); err != nil {
+		return Watermarks{}, err
+	}
+	if watermarks.Namespaces, err = watermarkFor(retention.Namespaces); err != nil {
+		return Watermarks{}, err
+	}
+	if watermarks.Transactions, err = watermarkFor(max(retention.Relationships, retention.Namespaces)); err != nil {
+		return Watermarks{}, err
+	}
+
+	pinned, err := gc.PinnedRevisions(ctx)
+	if err != nil {
+		return Watermarks{}, fmt.Errorf("error retrieving pinned revisions: %w", err)
+	}
+
+	for _, revision := range pinned {
+		watermarks.Relationships = pinnedBefore(watermarks.Relationships, revision)
+		watermarks.Namespaces = pinnedBefore(watermarks.Namespaces, revision)
+		watermarks.Transactions = pinnedBefore(watermarks.Transactions, revision)
+	}
+	return watermarks, nil
+}
+
+// pinnedBefore returns the watermark held back to the pinned revision, if older, so that the
+// history it reads is not collected.
+func pinnedBefore(watermark datastore.Revision, pinned datastore.Revision) datastore.Revision {
+	if watermark == datastore.NoRevision || !pinned.LessThan(watermark) {
+		return watermark
+	}
+	return pinned
+}
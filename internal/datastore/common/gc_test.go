@@ -25,6 +25,8 @@ type fakeGC struct {
 	lock         sync.RWMutex
 	wasLocked    bool
 	wasUnlocked  bool
+	retention    TableRetention
+	pinned       []datastore.Revision
 }
 
 type gcMetrics struct {
@@ -79,13 +81,13 @@ func (gc *fakeGC) TxIDBefore(_ context.Context, _ time.Time) (datastore.Revision
 	return rev, nil
 }
 
-func (gc *fakeGC) DeleteBeforeTx(_ context.Context, rev datastore.Revision) (DeletionCounts, error) {
+func (gc *fakeGC) DeleteBeforeTx(_ context.Context, watermarks Watermarks) (DeletionCounts, error) {
 	gc.lock.Lock()
 	defer gc.lock.Unlock()
 
 	gc.metrics.deleteBeforeTxCount++
 
-	revInt := rev.(revisions.TransactionIDRevision).TransactionID()
+	revInt := watermarks.Relationships.(revisions.TransactionIDRevision).TransactionID()
 
 	return gc.deleter.DeleteBeforeTx(revInt)
 }
@@ -99,6 +101,21 @@ func (gc *fakeGC) DeleteExpiredRels(_ context.Context) (int64, error) {
 	return gc.deleter.DeleteExpiredRels()
 }
 
+func (gc *fakeGC) DeleteExpiredRecords(_ context.Context) (int64, error) {
+	return 0, nil
+}
+
+func (gc *fakeGC) TableRetention() TableRetention {
+	return gc.retention
+}
+
+func (gc *fakeGC) PinnedRevisions(_ context.Context) ([]datastore.Revision, error) {
+	gc.lock.Lock()
+	defer gc.lock.Unlock()
+
+	return gc.pinned, nil
+}
+
 func (gc *fakeGC) HasGCRun() bool {
 	gc.lock.Lock()
 	defer gc.lock.Unlock()
@@ -261,3 +278,37 @@ func TestGCUnlockOnTimeout(t *testing.T) {
 	require.True(t, gc.wasLocked, "GC should have been locked")
 	require.True(t, gc.wasUnlocked, "GC should have been unlocked")
 }
+
+func TestGCWatermarks(t *testing.T) {
+	window := 1 * time.Hour
+
+	// Each distinct retention retrieves a new revision from the fake.
+	gc := newFakeGC(revisionErrorDeleter{})
+	gc.retention = TableRetention{Relationships: 2 * time.Hour}
+
+	watermarks, err := computeWatermarks(context.Background(), &gc, time.Now(), window)
+	require.NoError(t, err)
+	require.Equal(t, revisions.NewForTransactionID(1), watermarks.Relationships)
+	require.Equal(t, revisions.NewForTransactionID(2), watermarks.Namespaces)
+	require.Equal(t, revisions.NewForTransactionID(1), watermarks.Transactions, "transactions must be retained for the longest retention")
+
+	// Pinned revisions hold back the watermarks after them.
+	gc = newFakeGC(revisionErrorDeleter{})
+	gc.lastRevision = 10
+	gc.retention = TableRetention{Namespaces: 2 * time.Hour}
+	gc.pinned = []datastore.Revision{revisions.NewForTransactionID(11), revisions.NewForTransactionID(5)}
+
+	watermarks, err = computeWatermarks(context.Background(), &gc, time.Now(), window)
+	require.NoError(t, err)
+	require.Equal(t, revisions.NewForTransactionID(5), watermarks.Relationships)
+	require.Equal(t, revisions.NewForTransactionID(5), watermarks.Namespaces)
+	require.Equal(t, revisions.NewForTransactionID(5), watermarks.Transactions)
+
+	// Pinned revisions newer than the watermarks do not move them.
+	gc.pinned = []datastore.Revision{revisions.NewForTransactionID(14)}
+	watermarks, err = computeWatermarks(context.Background(), &gc, time.Now(), window)
+	require.NoError(t, err)
+	require.Equal(t, revisions.NewForTransactionID(13), watermarks.Relationships)
+	require.Equal(t, revisions.NewForTransactionID(14), watermarks.Namespaces)
+	require.Equal(t, revisions.NewForTransactionID(14), watermarks.Transactions)
+}
@@ -41,7 +41,7 @@ func ContextualizedCaveatFrom(name string, context map[string]any) (*core.Contex
 			return nil, fmt.Errorf("malformed caveat context: %w", err)
 		}
 		caveat = &core.ContextualizedCaveat{
-			CaveatName: name,
+			CaveatName: tuple.InternName(name),
 			Context:    strct,
 		}
 	}
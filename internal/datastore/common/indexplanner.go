@@ -0,0 +1,148 @@
+package common
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	log "github.com/authzed/spicedb/internal/logging"
+)
+
+// RelationshipIndex is an index of the relationships table which can be chosen by an IndexPlanner.
+type RelationshipIndex struct {
+	// Name is the name of the index in the datastore.
+	Name string
+
+	// Columns are the key columns of the index, in order.
+	Columns []string
+}
+
+// IndexStatistics are the statistics of the relationships table used to estimate the number of
+// rows read through each index.
+type IndexStatistics struct {
+	// RowCount is the estimated number of rows of the relationships table.
+	RowCount uint64
+
+	// DistinctCounts are the estimated numbers of distinct values of the prefixes of the columns
+	// of each index, by index name: the count at position i is that of the first i+1 columns,
+	// or zero if unknown. Indexes missing from the datastore are missing from the map.
+	DistinctCounts map[string][]uint64
+}
+
+// IndexStatisticsLoader loads the statistics of the given indexes from the datastore catalog.
+type IndexStatisticsLoader func(ctx context.Context, indexes []RelationshipIndex) (IndexStatistics, error)
+
+// IndexPlanner chooses the index of the relationships table through which a query reads the
+// fewest rows, based on the selectivity of the columns the query filters on in the statistics
+// of the datastore. The statistics are reloaded in the background once they are older than the
+// refresh interval, so planning never waits on the datastore.
+type IndexPlanner struct {
+	indexes         []RelationshipIndex
+	load            IndexStatisticsLoader
+	refreshInterval time.Duration
+
+	mu       sync.RWMutex
+	stats    IndexStatistics
+	loadedAt time.Time
+	loading  atomic.Bool
+}
+
+// NewIndexPlanner creates a planner choosing between the indexes, preferring those listed first
+// when their estimates are equal.
+func NewIndexPlanner(load IndexStatisticsLoader, refreshInterval time.Duration, indexes ...RelationshipIndex) *IndexPlanner {
+	return &IndexPlanner{
+		indexes:         indexes,
+		load:            load,
+		refreshInterval: refreshInterval,
+	}
+}
+
+// ChooseIndex returns the name of the index estimated to read the fewest rows for the query, or
+// an empty string if the statistics are not loaded yet, in which case the choice is left to the
+// datastore.
+func (ip *IndexPlanner) ChooseIndex(ctx context.Context, query SchemaQueryFilterer) string {
+	ip.maybeRefresh(ctx)
+
+	ip.mu.RLock()
+	defer ip.mu.RUnlock()
+	return ip.stats.chooseIndex(ip.indexes, query.filteringColumnTracker)
+}
+
+// Refresh loads the statistics of the indexes.
+func (ip *IndexPlanner) Refresh(ctx context.Context) error {
+	stats, err := ip.load(ctx, ip.indexes)
+	if err != nil {
+		return err
+	}
+
+	ip.mu.Lock()
+	defer ip.mu.Unlock()
+	ip.stats = stats
+	ip.loadedAt = time.Now()
+	return nil
+}
+
+func (ip *IndexPlanner) maybeRefresh(ctx context.Context) {
+	ip.mu.RLock()
+	stale := time.Since(ip.loadedAt) >= ip.refreshInterval
+	ip.mu.RUnlock()
+
+	if !stale || !ip.loading.CompareAndSwap(false, true) {
+		return
+	}
+
+	// The statistics are loaded outside of the query, which must not be slowed down or canceled
+	// with it.
+	refreshCtx := context.WithoutCancel(ctx)
+	go func() {
+		defer ip.loading.Store(false)
+		if err := ip.Refresh(refreshCtx); err != nil {
+			log.Ctx(refreshCtx).Warn().Err(err).Msg("failed to load the statistics of the relationship indexes")
+
+			// Retry after the interval rather than on every query.
+			ip.mu.Lock()
+			ip.loadedAt = time.Now()
+			ip.mu.Unlock()
+		}
+	}()
+}
+
+func (stats IndexStatistics) chooseIndex(indexes []RelationshipIndex, filtering columnTrackerMap) string {
+	if stats.RowCount == 0 {
+		return ""
+	}
+
+	chosen := ""
+	var chosenEstimate float64
+	for _, index := range indexes {
+		distinctCounts, ok := stats.DistinctCounts[index.Name]
+		if !ok {
+			continue
+		}
+
+		estimate := stats.estimateRows(index, distinctCounts, filtering)
+		if chosen == "" || estimate < chosenEstimate {
+			chosen = index.Name
+			chosenEstimate = estimate
+		}
+	}
+	return chosen
+}
+
+// estimateRows estimates the number of rows read through the index, from the longest prefix of
+// its columns filtered to known values with known statistics.
+func (stats IndexStatistics) estimateRows(index RelationshipIndex, distinctCounts []uint64, filtering columnTrackerMap) float64 {
+	estimate := float64(stats.RowCount)
+	values := 1.0
+	for i, column := range index.Columns {
+		tracker, ok := filtering[column]
+		if !ok || tracker.valueCount == 0 || tracker.nonEquality || i >= len(distinctCounts) || distinctCounts[i] == 0 {
+			break
+		}
+
+		values *= float64(tracker.valueCount)
+		estimate = min(float64(stats.RowCount), float64(stats.RowCount)*values/float64(distinctCounts[i]))
+	}
+	return estimate
+}
@@ -0,0 +1,174 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/pkg/datastore"
+)
+
+var (
+	testSubjectIndex = RelationshipIndex{
+		Name:    "ix_by_subject",
+		Columns: []string{"subject_ns", "subject_object_id", "subject_relation", "ns", "relation"},
+	}
+	testResourceIndex = RelationshipIndex{
+		Name:    "ix_by_resource",
+		Columns: []string{"ns", "object_id", "relation", "subject_ns", "subject_object_id", "subject_relation"},
+	}
+)
+
+func newIndexPlannerTestFilterer() SchemaQueryFilterer {
+	schema := NewSchemaInformationWithOptions(
+		WithRelationshipTableName("relationtuples"),
+		WithColNamespace("ns"),
+		WithColObjectID("object_id"),
+		WithColRelation("relation"),
+		WithColUsersetNamespace("subject_ns"),
+		WithColUsersetObjectID("subject_object_id"),
+		WithColUsersetRelation("subject_relation"),
+		WithColCaveatName("caveat"),
+		WithColCaveatContext("caveat_context"),
+		WithColExpiration("expiration"),
+		WithPlaceholderFormat(sq.Question),
+		WithPaginationFilterType(TupleComparison),
+		WithColumnOptimization(ColumnOptimizationOptionStaticValues),
+		WithNowFunction("NOW"),
+	)
+	return NewSchemaQueryFiltererForRelationshipsSelect(*schema, 100)
+}
+
+func TestIndexPlannerChooseIndex(t *testing.T) {
+	stats := IndexStatistics{
+		RowCount: 1_000_000,
+		DistinctCounts: map[string][]uint64{
+			// Most relationships have users as subjects, of which there are 1000.
+			testSubjectIndex.Name: {3, 1_000, 1_010, 1_500, 2_000},
+			// Resources are of 10 types, with 100 000 distinct resources.
+			testResourceIndex.Name: {10, 100_000, 200_000, 400_000, 600_000, 1_000_000},
+		},
+	}
+
+	tcs := []struct {
+		name          string
+		stats         *IndexStatistics
+		run           func(filterer SchemaQueryFilterer) (SchemaQueryFilterer, error)
+		expectedIndex string
+	}{
+		{
+			name: "subject",
+			run: func(filterer SchemaQueryFilterer) (SchemaQueryFilterer, error) {
+				return filterer.FilterWithSubjectsSelectors(datastore.SubjectsSelector{OptionalSubjectType: "user", OptionalSubjectIds: []string{"tom"}})
+			},
+			expectedIndex: testSubjectIndex.Name,
+		},
+		{
+			name: "subject type only, with resource type",
+			run: func(filterer SchemaQueryFilterer) (SchemaQueryFilterer, error) {
+				filterer, err := filterer.FilterWithSubjectsSelectors(datastore.SubjectsSelector{OptionalSubjectType: "user"})
+				return filterer.FilterToResourceType("document"), err
+			},
+			expectedIndex: testResourceIndex.Name,
+		},
+		{
+			name: "many subject IDs, with resource type and ID",
+			run: func(filterer SchemaQueryFilterer) (SchemaQueryFilterer, error) {
+				filterer, err := filterer.FilterWithSubjectsSelectors(datastore.SubjectsSelector{OptionalSubjectType: "user", OptionalSubjectIds: []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k"}})
+				return filterer.FilterToResourceType("document").FilterToResourceID("readme"), err
+			},
+			expectedIndex: testResourceIndex.Name,
+		},
+		{
+			name: "selectors not all filtering on subject IDs",
+			run: func(filterer SchemaQueryFilterer) (SchemaQueryFilterer, error) {
+				filterer, err := filterer.FilterWithSubjectsSelectors(
+					datastore.SubjectsSelector{OptionalSubjectType: "user", OptionalSubjectIds: []string{"tom"}},
+					datastore.SubjectsSelector{OptionalSubjectType: "user"},
+				)
+				return filterer.FilterToResourceType("document"), err
+			},
+			expectedIndex: testResourceIndex.Name,
+		},
+		{
+			name: "equal estimates",
+			run: func(filterer SchemaQueryFilterer) (SchemaQueryFilterer, error) {
+				return filterer, nil
+			},
+			expectedIndex: testSubjectIndex.Name,
+		},
+		{
+			name: "missing index",
+			stats: &IndexStatistics{
+				RowCount:       1_000_000,
+				DistinctCounts: map[string][]uint64{testResourceIndex.Name: stats.DistinctCounts[testResourceIndex.Name]},
+			},
+			run: func(filterer SchemaQueryFilterer) (SchemaQueryFilterer, error) {
+				return filterer.FilterWithSubjectsSelectors(datastore.SubjectsSelector{OptionalSubjectType: "user", OptionalSubjectIds: []string{"tom"}})
+			},
+			expectedIndex: testResourceIndex.Name,
+		},
+		{
+			name:  "no statistics",
+			stats: &IndexStatistics{},
+			run: func(filterer SchemaQueryFilterer) (SchemaQueryFilterer, error) {
+				return filterer.FilterWithSubjectsSelectors(datastore.SubjectsSelector{OptionalSubjectType: "user", OptionalSubjectIds: []string{"tom"}})
+			},
+			expectedIndex: "",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			tcStats := stats
+			if tc.stats != nil {
+				tcStats = *tc.stats
+			}
+
+			planner := NewIndexPlanner(func(context.Context, []RelationshipIndex) (IndexStatistics, error) {
+				return tcStats, nil
+			}, time.Hour, testSubjectIndex, testResourceIndex)
+			require.NoError(t, planner.Refresh(context.Background()))
+
+			filterer, err := tc.run(newIndexPlannerTestFilterer())
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedIndex, planner.ChooseIndex(context.Background(), filterer))
+		})
+	}
+}
+
+func TestIndexPlannerRefreshesInBackground(t *testing.T) {
+	loaded := make(chan struct{}, 1)
+	fail := true
+	planner := NewIndexPlanner(func(context.Context, []RelationshipIndex) (IndexStatistics, error) {
+		defer func() {
+			select {
+			case loaded <- struct{}{}:
+			default:
+			}
+		}()
+		if fail {
+			return IndexStatistics{}, errors.New("statistics unavailable")
+		}
+		return IndexStatistics{RowCount: 10, DistinctCounts: map[string][]uint64{testSubjectIndex.Name: {1}}}, nil
+	}, 0, testSubjectIndex, testResourceIndex)
+
+	filterer := newIndexPlannerTestFilterer()
+
+	// The first query is planned without statistics while they are loaded.
+	require.Equal(t, "", planner.ChooseIndex(context.Background(), filterer))
+	<-loaded
+	require.Eventually(t, func() bool { return !planner.loading.Load() }, time.Second, time.Millisecond)
+
+	// Failures are retried at the next refresh.
+	fail = false
+	planner.ChooseIndex(context.Background(), filterer)
+	<-loaded
+	require.Eventually(t, func() bool {
+		return planner.ChooseIndex(context.Background(), filterer) == testSubjectIndex.Name
+	}, time.Second, time.Millisecond)
+}
@@ -0,0 +1,152 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/pkg/middleware/nodeid"
+)
+
+// GarbageCollectionLease is the name of the lease held by the node running garbage collection.
+const GarbageCollectionLease = "garbage_collection"
+
+var leaseHeldGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: "spicedb",
+	Subsystem: "datastore",
+	Name:      "lease_held",
+	Help:      "Whether the node, identified by the holder label, holds the lease of a singleton background job.",
+}, []string{"lease", "holder"})
+
+// RegisterLeaseMetrics registers leader election metrics to the default
+// registry.
+func RegisterLeaseMetrics() error {
+	return prometheus.Register(leaseHeldGauge)
+}
+
+// LeaseManager represents any datastore that supports leases, through which a
+// single node of the cluster is elected to run each singleton background job.
+type LeaseManager interface {
+	// TryAcquireLease attempts to acquire the named lease for the holder, until
+	// the given duration from now as measured by the datastore. The lease is
+	// acquired if it is unheld, expired, or already held by the holder, in which
+	// case it is renewed.
+	TryAcquireLease(ctx context.Context, name, holder string, duration time.Duration) (bool, error)
+
+	// ReleaseLease releases the named lease, if it is held by the holder.
+	ReleaseLease(ctx context.Context, name, holder string) error
+}
+
+// leaseHolderID returns the ID with which the process holds leases. The node ID
+// is suffixed with a random ID, so that processes sharing a hostname are not
+// mistaken for one another.
+var leaseHolderID = sync.OnceValue(func() string {
+	nodeID, err := nodeid.FromContext(context.Background())
+	if err != nil {
+		log.Warn().Err(err).Msg("unable to determine node ID for leases")
+		nodeID = "spicedb"
+	}
+	return fmt.Sprintf("%s-%s", nodeID, uuid.NewString()[:8])
+})
+
+// RunAsLeader runs the job while the node holds the named lease, until the
+// context is canceled. The lease is renewed every third of its duration; should
+// a renewal fail, the context of the job is canceled ahead of the lease expiring
+// and the node campaigns for the lease again once the job has returned. A job
+// returning on its own ends the election with the error of the job.
+func RunAsLeader(ctx context.Context, lm LeaseManager, name string, duration time.Duration, job func(context.Context) error) error {
+	return runAsLeader(ctx, lm, name, leaseHolderID(), duration, job)
+}
+
+func runAsLeader(ctx context.Context, lm LeaseManager, name, holder string, duration time.Duration, job func(context.Context) error) error {
+	held := leaseHeldGauge.WithLabelValues(name, holder)
+	held.Set(0)
+
+	var cancelJob context.CancelFunc
+	var jobDone chan error
+
+	// stopLeading cancels the running job, if any, waits for it to return and
+	// releases the lease.
+	stopLeading := func() error {
+		if jobDone == nil {
+			return nil
+		}
+
+		cancelJob()
+		err := <-jobDone
+		cancelJob, jobDone = nil, nil
+		held.Set(0)
+
+		// The lease is released with a fresh context, as the context of the
+		// election is usually canceled by now.
+		releaseCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), duration)
+		defer cancel()
+		if rerr := lm.ReleaseLease(releaseCtx, name, holder); rerr != nil {
+			log.Ctx(ctx).Warn().Err(rerr).Str("lease", name).Msg("error releasing lease")
+		}
+
+		if errors.Is(err, context.Canceled) {
+			return nil
+		}
+		return err
+	}
+
+	log.Ctx(ctx).Info().
+		Str("lease", name).
+		Str("holder", holder).
+		Dur("duration", duration).
+		Msg("campaigning for lease")
+
+	for {
+		acquired, err := lm.TryAcquireLease(ctx, name, holder, duration)
+		if err != nil && ctx.Err() == nil {
+			log.Ctx(ctx).Warn().Err(err).Str("lease", name).Msg("error attempting to acquire lease")
+		}
+
+		switch {
+		case acquired && jobDone == nil:
+			log.Ctx(ctx).Info().Str("lease", name).Str("holder", holder).Msg("acquired lease; running singleton job")
+
+			cancelJob, jobDone = startJob(ctx, job)
+			held.Set(1)
+
+		case !acquired && jobDone != nil:
+			log.Ctx(ctx).Warn().Str("lease", name).Str("holder", holder).Msg("lost lease; stopping singleton job")
+			if err := stopLeading(); err != nil {
+				return err
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			if err := stopLeading(); err != nil {
+				return err
+			}
+			return ctx.Err()
+
+		case err := <-jobDone:
+			// Hand the job back so that stopLeading can collect it.
+			jobDone <- err
+			return stopLeading()
+
+		case <-time.After(duration / 3):
+		}
+	}
+}
+
+// startJob runs the job in the background, returning the function canceling it
+// and the channel receiving its result.
+func startJob(ctx context.Context, job func(context.Context) error) (context.CancelFunc, chan error) {
+	jobCtx, cancel := context.WithCancel(ctx)
+	done := make(chan error, 1)
+	go func() {
+		done <- job(jobCtx)
+	}()
+	return cancel, done
+}
@@ -0,0 +1,165 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+// Fake lease manager holding its leases in memory.
+type fakeLeaseManager struct {
+	lock    sync.Mutex
+	holders map[string]string
+	expires map[string]time.Time
+}
+
+func newFakeLeaseManager() *fakeLeaseManager {
+	return &fakeLeaseManager{
+		holders: map[string]string{},
+		expires: map[string]time.Time{},
+	}
+}
+
+func (lm *fakeLeaseManager) TryAcquireLease(_ context.Context, name, holder string, duration time.Duration) (bool, error) {
+	lm.lock.Lock()
+	defer lm.lock.Unlock()
+
+	now := time.Now()
+	if current, ok := lm.holders[name]; ok && current != holder && lm.expires[name].After(now) {
+		return false, nil
+	}
+
+	lm.holders[name] = holder
+	lm.expires[name] = now.Add(duration)
+	return true, nil
+}
+
+func (lm *fakeLeaseManager) ReleaseLease(_ context.Context, name, holder string) error {
+	lm.lock.Lock()
+	defer lm.lock.Unlock()
+
+	if lm.holders[name] == holder {
+		delete(lm.holders, name)
+		delete(lm.expires, name)
+	}
+	return nil
+}
+
+func (lm *fakeLeaseManager) holder(name string) string {
+	lm.lock.Lock()
+	defer lm.lock.Unlock()
+
+	return lm.holders[name]
+}
+
+// steal hands the lease to another holder.
+func (lm *fakeLeaseManager) steal(name, holder string) {
+	lm.lock.Lock()
+	defer lm.lock.Unlock()
+
+	lm.holders[name] = holder
+	lm.expires[name] = time.Now().Add(time.Hour)
+}
+
+func TestRunAsLeaderSingleLeader(t *testing.T) {
+	lm := newFakeLeaseManager()
+	duration := 30 * time.Millisecond
+
+	var running, maxRunning atomic.Int32
+	ranBy := make(chan string, 10)
+	jobFor := func(holder string) func(context.Context) error {
+		return func(ctx context.Context) error {
+			current := running.Add(1)
+			defer running.Add(-1)
+			if current > maxRunning.Load() {
+				maxRunning.Store(current)
+			}
+
+			ranBy <- holder
+			<-ctx.Done()
+			return ctx.Err()
+		}
+	}
+
+	firstCtx, cancelFirst := context.WithCancel(context.Background())
+	firstDone := make(chan error, 1)
+	go func() {
+		firstDone <- runAsLeader(firstCtx, lm, "job", "first", duration, jobFor("first"))
+	}()
+	require.Equal(t, "first", <-ranBy)
+	require.Equal(t, 1.0, testutil.ToFloat64(leaseHeldGauge.WithLabelValues("job", "first")))
+
+	secondCtx, cancelSecond := context.WithCancel(context.Background())
+	secondDone := make(chan error, 1)
+	go func() {
+		secondDone <- runAsLeader(secondCtx, lm, "job", "second", duration, jobFor("second"))
+	}()
+
+	// The second node does not run the job while the first one holds the lease.
+	time.Sleep(3 * duration)
+	require.Empty(t, ranBy)
+	require.Equal(t, 0.0, testutil.ToFloat64(leaseHeldGauge.WithLabelValues("job", "second")))
+
+	// Once the first node stops, it releases the lease to the second node.
+	cancelFirst()
+	require.ErrorIs(t, <-firstDone, context.Canceled)
+	require.Equal(t, 0.0, testutil.ToFloat64(leaseHeldGauge.WithLabelValues("job", "first")))
+	require.Equal(t, "second", <-ranBy)
+	require.Equal(t, 1.0, testutil.ToFloat64(leaseHeldGauge.WithLabelValues("job", "second")))
+
+	cancelSecond()
+	require.ErrorIs(t, <-secondDone, context.Canceled)
+	require.Equal(t, int32(1), maxRunning.Load())
+}
+
+func TestRunAsLeaderStopsJobOnLostLease(t *testing.T) {
+	lm := newFakeLeaseManager()
+	duration := 30 * time.Millisecond
+
+	started := make(chan struct{})
+	stopped := make(chan struct{})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- runAsLeader(ctx, lm, "job", "first", duration, func(ctx context.Context) error {
+			close(started)
+			<-ctx.Done()
+			close(stopped)
+			return ctx.Err()
+		})
+	}()
+	<-started
+
+	lm.steal("job", "other")
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		require.Fail(t, "job was not stopped after losing its lease")
+	}
+
+	// The lease of the other holder is left untouched.
+	require.Equal(t, "other", lm.holder("job"))
+
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+}
+
+func TestRunAsLeaderEndsWithJob(t *testing.T) {
+	lm := newFakeLeaseManager()
+
+	err := runAsLeader(context.Background(), lm, "job", "first", time.Second, func(context.Context) error {
+		return errTestJob
+	})
+	require.ErrorIs(t, err, errTestJob)
+	require.Empty(t, lm.holder("job"), "the lease should be released")
+}
+
+var errTestJob = errors.New("job failed")
@@ -0,0 +1,202 @@
+package common
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// PoolStats is a snapshot of the statistics of a SQL connection pool.
+type PoolStats struct {
+	// MaxConns is the maximum number of connections in the pool.
+	MaxConns int64
+
+	// InUseConns is the number of connections currently in use.
+	InUseConns int64
+
+	// IdleConns is the number of idle connections in the pool.
+	IdleConns int64
+
+	// WaitCount is the cumulative number of acquisitions which waited for a connection.
+	WaitCount int64
+
+	// WaitDuration is the cumulative time spent waiting for connections.
+	WaitDuration time.Duration
+
+	// MaxLifetimeClosed is the cumulative number of connections closed for exceeding their
+	// maximum lifetime.
+	MaxLifetimeClosed int64
+
+	// MaxIdleClosed is the cumulative number of connections closed for exceeding their maximum
+	// idle time.
+	MaxIdleClosed int64
+
+	// ErrorPruned is the cumulative number of connections closed for exceeding the maximum number
+	// of consecutive errors.
+	ErrorPruned int64
+}
+
+var poolLabels = []string{"db_name", "pool_usage"}
+
+var (
+	poolMaxConnsDesc = prometheus.NewDesc(
+		"spicedb_datastore_pool_max_connections",
+		"maximum number of connections in the datastore connection pool",
+		poolLabels, nil,
+	)
+	poolInUseConnsDesc = prometheus.NewDesc(
+		"spicedb_datastore_pool_in_use_connections",
+		"number of datastore connections currently in use",
+		poolLabels, nil,
+	)
+	poolIdleConnsDesc = prometheus.NewDesc(
+		"spicedb_datastore_pool_idle_connections",
+		"number of idle datastore connections in the pool",
+		poolLabels, nil,
+	)
+	poolWaitCountDesc = prometheus.NewDesc(
+		"spicedb_datastore_pool_wait_count_total",
+		"total number of datastore connection acquisitions which waited for a connection",
+		poolLabels, nil,
+	)
+	poolWaitDurationDesc = prometheus.NewDesc(
+		"spicedb_datastore_pool_wait_duration_seconds_total",
+		"total time in seconds spent waiting for datastore connections",
+		poolLabels, nil,
+	)
+	poolMaxLifetimeClosedDesc = prometheus.NewDesc(
+		"spicedb_datastore_pool_max_lifetime_closed_total",
+		"total number of datastore connections closed for exceeding their maximum lifetime",
+		poolLabels, nil,
+	)
+	poolMaxIdleClosedDesc = prometheus.NewDesc(
+		"spicedb_datastore_pool_max_idle_closed_total",
+		"total number of datastore connections closed for exceeding their maximum idle time",
+		poolLabels, nil,
+	)
+	poolErrorPrunedDesc = prometheus.NewDesc(
+		"spicedb_datastore_pool_error_pruned_total",
+		"total number of datastore connections closed for exceeding the maximum number of consecutive errors",
+		poolLabels, nil,
+	)
+)
+
+type poolStatsCollector struct {
+	dbName    string
+	poolUsage string
+	stats     func() PoolStats
+}
+
+// NewPoolStatsCollector returns a Prometheus collector exporting the statistics of a connection
+// pool under the common `spicedb_datastore_pool_` prefix, labeled with the database name and the
+// usage of the pool (e.g. `read` or `write`). This allows pools of all SQL drivers to be monitored
+// with the same queries.
+func NewPoolStatsCollector(dbName, poolUsage string, stats func() PoolStats) prometheus.Collector {
+	return &poolStatsCollector{
+		dbName:    dbName,
+		poolUsage: poolUsage,
+		stats:     stats,
+	}
+}
+
+func (c *poolStatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(c, ch)
+}
+
+func (c *poolStatsCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.stats()
+	for _, metric := range []struct {
+		desc      *prometheus.Desc
+		valueType prometheus.ValueType
+		value     float64
+	}{
+		{poolMaxConnsDesc, prometheus.GaugeValue, float64(stats.MaxConns)},
+		{poolInUseConnsDesc, prometheus.GaugeValue, float64(stats.InUseConns)},
+		{poolIdleConnsDesc, prometheus.GaugeValue, float64(stats.IdleConns)},
+		{poolWaitCountDesc, prometheus.CounterValue, float64(stats.WaitCount)},
+		{poolWaitDurationDesc, prometheus.CounterValue, stats.WaitDuration.Seconds()},
+		{poolMaxLifetimeClosedDesc, prometheus.CounterValue, float64(stats.MaxLifetimeClosed)},
+		{poolMaxIdleClosedDesc, prometheus.CounterValue, float64(stats.MaxIdleClosed)},
+		{poolErrorPrunedDesc, prometheus.CounterValue, float64(stats.ErrorPruned)},
+	} {
+		ch <- prometheus.MustNewConstMetric(metric.desc, metric.valueType, metric.value, c.dbName, c.poolUsage)
+	}
+}
+
+// ConnErrorTracker tracks the number of consecutive errors returned by each connection of a pool,
+// so that connections which repeatedly fail are pruned from the pool when they are released,
+// rather than being handed out again. A nil ConnErrorTracker prunes nothing.
+type ConnErrorTracker[C comparable] struct {
+	maxErrors int
+
+	lock   sync.Mutex
+	errors map[C]int
+
+	pruned atomic.Int64
+}
+
+// NewConnErrorTracker returns a tracker pruning connections which return more than maxErrors
+// consecutive errors, or nil if maxErrors is not positive.
+func NewConnErrorTracker[C comparable](maxErrors int) *ConnErrorTracker[C] {
+	if maxErrors <= 0 {
+		return nil
+	}
+	return &ConnErrorTracker[C]{maxErrors: maxErrors, errors: make(map[C]int)}
+}
+
+// RecordResult records the outcome of an operation on the connection. A nil error resets the
+// count of consecutive errors of the connection.
+func (t *ConnErrorTracker[C]) RecordResult(conn C, err error) {
+	if t == nil {
+		return
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if err == nil {
+		delete(t.errors, conn)
+		return
+	}
+	t.errors[conn]++
+}
+
+// ShouldPrune returns whether the connection exceeded the maximum number of consecutive errors
+// and must be closed. The connection is forgotten if it must be closed.
+func (t *ConnErrorTracker[C]) ShouldPrune(conn C) bool {
+	if t == nil {
+		return false
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.errors[conn] <= t.maxErrors {
+		return false
+	}
+
+	delete(t.errors, conn)
+	t.pruned.Add(1)
+	return true
+}
+
+// Forget removes the connection from the tracker, once it has been closed.
+func (t *ConnErrorTracker[C]) Forget(conn C) {
+	if t == nil {
+		return
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	delete(t.errors, conn)
+}
+
+// PrunedCount returns the number of connections pruned so far.
+func (t *ConnErrorTracker[C]) PrunedCount() int64 {
+	if t == nil {
+		return 0
+	}
+	return t.pruned.Load()
+}
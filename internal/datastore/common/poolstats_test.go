@@ -0,0 +1,81 @@
+package common
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnErrorTracker(t *testing.T) {
+	require.Nil(t, NewConnErrorTracker[int](0))
+
+	var disabled *ConnErrorTracker[int]
+	disabled.RecordResult(1, errors.New("failed"))
+	require.False(t, disabled.ShouldPrune(1))
+	require.Zero(t, disabled.PrunedCount())
+
+	tracker := NewConnErrorTracker[int](2)
+	failed := errors.New("failed")
+
+	// Errors must be consecutive.
+	tracker.RecordResult(1, failed)
+	tracker.RecordResult(1, failed)
+	tracker.RecordResult(1, nil)
+	tracker.RecordResult(1, failed)
+	require.False(t, tracker.ShouldPrune(1))
+
+	tracker.RecordResult(1, failed)
+	require.False(t, tracker.ShouldPrune(1))
+
+	tracker.RecordResult(1, failed)
+	require.True(t, tracker.ShouldPrune(1))
+	require.Equal(t, int64(1), tracker.PrunedCount())
+
+	// Pruned connections are forgotten.
+	require.False(t, tracker.ShouldPrune(1))
+
+	// Closed connections are forgotten.
+	for range 3 {
+		tracker.RecordResult(2, failed)
+	}
+	tracker.Forget(2)
+	require.False(t, tracker.ShouldPrune(2))
+	require.Equal(t, int64(1), tracker.PrunedCount())
+}
+
+func TestPoolStatsCollector(t *testing.T) {
+	collector := NewPoolStatsCollector("spicedb", "read", func() PoolStats {
+		return PoolStats{
+			MaxConns:          20,
+			InUseConns:        5,
+			IdleConns:         15,
+			WaitCount:         3,
+			WaitDuration:      1500 * time.Millisecond,
+			MaxLifetimeClosed: 7,
+			MaxIdleClosed:     2,
+			ErrorPruned:       1,
+		}
+	})
+
+	expected := `
+# HELP spicedb_datastore_pool_in_use_connections number of datastore connections currently in use
+# TYPE spicedb_datastore_pool_in_use_connections gauge
+spicedb_datastore_pool_in_use_connections{db_name="spicedb",pool_usage="read"} 5
+# HELP spicedb_datastore_pool_wait_duration_seconds_total total time in seconds spent waiting for datastore connections
+# TYPE spicedb_datastore_pool_wait_duration_seconds_total counter
+spicedb_datastore_pool_wait_duration_seconds_total{db_name="spicedb",pool_usage="read"} 1.5
+# HELP spicedb_datastore_pool_error_pruned_total total number of datastore connections closed for exceeding the maximum number of consecutive errors
+# TYPE spicedb_datastore_pool_error_pruned_total counter
+spicedb_datastore_pool_error_pruned_total{db_name="spicedb",pool_usage="read"} 1
+`
+	require.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(expected),
+		"spicedb_datastore_pool_in_use_connections",
+		"spicedb_datastore_pool_wait_duration_seconds_total",
+		"spicedb_datastore_pool_error_pruned_total",
+	))
+	require.Equal(t, 8, testutil.CollectAndCount(collector))
+}
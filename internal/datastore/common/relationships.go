@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"sync"
 	"time"
 
 	"go.opentelemetry.io/otel/attribute"
@@ -41,36 +42,49 @@ type closeRows interface {
 
 // QueryRelationships queries relationships for the given query and transaction.
 func QueryRelationships[R Rows, C ~map[string]any](ctx context.Context, builder RelationshipsQueryBuilder, tx Querier[R]) (datastore.RelationshipIterator, error) {
-	span := trace.SpanFromContext(ctx)
-	sqlString, args, err := builder.SelectSQL()
+	tagged, err := QueryTaggedRelationships[R, C](ctx, builder, tx)
 	if err != nil {
-		return nil, fmt.Errorf(errUnableToQueryRels, err)
+		return nil, err
 	}
 
-	var resourceObjectType string
-	var resourceObjectID string
-	var resourceRelation string
-	var subjectObjectType string
-	var subjectObjectID string
-	var subjectRelation string
-	var caveatName sql.NullString
-	var caveatCtx C
-	var expiration *time.Time
-
-	var integrityKeyID string
-	var integrityHash []byte
-	var timestamp time.Time
-
-	span.AddEvent("Selecting columns")
-	colsToSelect, err := ColumnsToSelect(builder, &resourceObjectType, &resourceObjectID, &resourceRelation, &subjectObjectType, &subjectObjectID, &subjectRelation, &caveatName, &caveatCtx, &expiration, &integrityKeyID, &integrityHash, &timestamp)
+	return func(yield func(tuple.Relationship, error) bool) {
+		for t, err := range tagged {
+			if !yield(t.Relationship, err) {
+				return
+			}
+		}
+	}, nil
+}
+
+// QueryTaggedRelationships queries relationships for the given query and transaction, tagging
+// each with the index of the query of the batch it matched, if the builder combines a batch.
+func QueryTaggedRelationships[R Rows, C ~map[string]any](ctx context.Context, builder RelationshipsQueryBuilder, tx Querier[R]) (datastore.TaggedRelationshipIterator, error) {
+	span := trace.SpanFromContext(ctx)
+	sqlString, args, err := builder.SelectSQL()
 	if err != nil {
 		return nil, fmt.Errorf(errUnableToQueryRels, err)
 	}
 
-	span.AddEvent("Returning iterator", trace.WithAttributes(attribute.Int("column-count", len(colsToSelect))))
-	return func(yield func(tuple.Relationship, error) bool) {
-		span.AddEvent("Issuing query to database")
-		err := tx.QueryFunc(ctx, func(ctx context.Context, rows R) error {
+	span.AddEvent("Returning iterator")
+	return func(yield func(datastore.TaggedRelationship, error) bool) {
+		// The row is acquired for each iteration, so that it is never shared by two iterations
+		// of the same iterator.
+		row := acquireRelationshipRow()
+		defer releaseRelationshipRow(row)
+
+		var caveatCtx C
+
+		span.AddEvent("Selecting columns")
+		colsToSelect, err := appendColumnsToSelect(row.colsToSelect, builder, &row.resourceObjectType, &row.resourceObjectID, &row.resourceRelation, &row.subjectObjectType, &row.subjectObjectID, &row.subjectRelation, &row.caveatName, &caveatCtx, &row.expiration, &row.integrityKeyID, &row.integrityHash, &row.timestamp, &row.writeRevision)
+		if err != nil {
+			yield(datastore.TaggedRelationship{}, fmt.Errorf(errUnableToQueryRels, err))
+			return
+		}
+		colsToSelect = builder.WithFilterIndexColumn(colsToSelect, &row.filterIndex)
+		row.colsToSelect = colsToSelect
+
+		span.AddEvent("Issuing query to database", trace.WithAttributes(attribute.Int("column-count", len(colsToSelect))))
+		err = tx.QueryFunc(ctx, func(ctx context.Context, rows R) error {
 			span.AddEvent("Query issued to database")
 
 			var r Rows = rows
@@ -96,9 +110,9 @@ func QueryRelationships[R Rows, C ~map[string]any](ctx context.Context, builder
 
 				var caveat *corev1.ContextualizedCaveat
 				if !builder.SkipCaveats || builder.Schema.ColumnOptimization == ColumnOptimizationOptionNone {
-					if caveatName.Valid {
+					if row.caveatName.Valid {
 						var err error
-						caveat, err = ContextualizedCaveatFrom(caveatName.String, caveatCtx)
+						caveat, err = ContextualizedCaveatFrom(row.caveatName.String, caveatCtx)
 						if err != nil {
 							return fmt.Errorf(errUnableToQueryRels, fmt.Errorf("unable to fetch caveat context: %w", err))
 						}
@@ -106,39 +120,49 @@ func QueryRelationships[R Rows, C ~map[string]any](ctx context.Context, builder
 				}
 
 				var integrity *corev1.RelationshipIntegrity
-				if integrityKeyID != "" {
+				if row.integrityKeyID != "" {
 					integrity = &corev1.RelationshipIntegrity{
-						KeyId:    integrityKeyID,
-						Hash:     integrityHash,
-						HashedAt: timestamppb.New(timestamp),
+						KeyId:    row.integrityKeyID,
+						Hash:     row.integrityHash,
+						HashedAt: timestamppb.New(row.timestamp),
 					}
 				}
 
-				if expiration != nil {
+				var expiration *time.Time
+				if row.expiration != nil {
 					// Ensure the expiration is always read in UTC, since some datastores (like CRDB)
 					// will normalize to local time.
-					t := expiration.UTC()
+					t := row.expiration.UTC()
 					expiration = &t
 				}
 
+				var writeRevision string
+				if builder.withWriteRevisions() {
+					writeRevision = row.writeRevision
+				}
+
 				relCount++
-				if !yield(tuple.Relationship{
+
+				// The names are scanned into new strings for every row, so they are interned to
+				// keep the relationships held by callers from each referencing copies of them.
+				if !yield(datastore.TaggedRelationship{FilterIndex: int(row.filterIndex), Relationship: tuple.Relationship{
 					RelationshipReference: tuple.RelationshipReference{
 						Resource: tuple.ObjectAndRelation{
-							ObjectType: resourceObjectType,
-							ObjectID:   resourceObjectID,
-							Relation:   resourceRelation,
+							ObjectType: tuple.InternName(row.resourceObjectType),
+							ObjectID:   row.resourceObjectID,
+							Relation:   tuple.InternName(row.resourceRelation),
 						},
 						Subject: tuple.ObjectAndRelation{
-							ObjectType: subjectObjectType,
-							ObjectID:   subjectObjectID,
-							Relation:   subjectRelation,
+							ObjectType: tuple.InternName(row.subjectObjectType),
+							ObjectID:   row.subjectObjectID,
+							Relation:   tuple.InternName(row.subjectRelation),
 						},
 					},
-					OptionalCaveat:     caveat,
-					OptionalExpiration: expiration,
-					OptionalIntegrity:  integrity,
-				}, nil) {
+					OptionalCaveat:        caveat,
+					OptionalExpiration:    expiration,
+					OptionalIntegrity:     integrity,
+					OptionalWriteRevision: writeRevision,
+				}}, nil) {
 					return nil
 				}
 			}
@@ -151,9 +175,50 @@ func QueryRelationships[R Rows, C ~map[string]any](ctx context.Context, builder
 			return nil
 		}, sqlString, args...)
 		if err != nil {
-			if !yield(tuple.Relationship{}, err) {
+			if !yield(datastore.TaggedRelationship{}, err) {
 				return
 			}
 		}
 	}, nil
 }
+
+// relationshipRow holds the destinations of the columns of the rows read by a relationships
+// query. As queries are issued for every dispatched check, rows are pooled across queries.
+type relationshipRow struct {
+	resourceObjectType string
+	resourceObjectID   string
+	resourceRelation   string
+	subjectObjectType  string
+	subjectObjectID    string
+	subjectRelation    string
+	caveatName         sql.NullString
+	expiration         *time.Time
+
+	integrityKeyID string
+	integrityHash  []byte
+	timestamp      time.Time
+	writeRevision  string
+	filterIndex    int64
+
+	colsToSelect []any
+}
+
+var relationshipRowPool = sync.Pool{
+	New: func() any {
+		return &relationshipRow{
+			colsToSelect: make([]any, 0, relationshipStandardColumnCount+relationshipCaveatColumnCount+relationshipExpirationColumnCount+relationshipIntegrityColumnCount+1),
+		}
+	},
+}
+
+func acquireRelationshipRow() *relationshipRow {
+	return relationshipRowPool.Get().(*relationshipRow)
+}
+
+// releaseRelationshipRow returns the row to the pool, dropping the references it holds so that
+// the values of its last query are not kept alive.
+func releaseRelationshipRow(row *relationshipRow) {
+	clear(row.colsToSelect)
+	*row = relationshipRow{colsToSelect: row.colsToSelect[:0]}
+	relationshipRowPool.Put(row)
+}
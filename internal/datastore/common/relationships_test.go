@@ -0,0 +1,147 @@
+package common
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+	"unsafe"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// fakeRow is a row of the relationships table, in the order of the selected columns.
+type fakeRow struct {
+	resourceType, resourceID, relation, subjectType, subjectID, subjectRelation string
+	caveatName                                                                  sql.NullString
+	caveatContext                                                               map[string]any
+	expiration                                                                  *time.Time
+}
+
+type fakeRows struct {
+	rows  []fakeRow
+	index int
+}
+
+func (fr *fakeRows) Next() bool {
+	fr.index++
+	return fr.index <= len(fr.rows)
+}
+
+func (fr *fakeRows) Scan(dest ...any) error {
+	row := fr.rows[fr.index-1]
+	values := []any{row.resourceType, row.resourceID, row.relation, row.subjectType, row.subjectID, row.subjectRelation, row.caveatName, row.caveatContext, row.expiration}
+	if len(dest) != len(values) {
+		return fmt.Errorf("expected %d destinations, got %d", len(values), len(dest))
+	}
+
+	for i, value := range values {
+		switch d := dest[i].(type) {
+		case *string:
+			// Scanned strings have their own backing memory, as with the drivers.
+			*d = strings.Clone(value.(string))
+		case *sql.NullString:
+			*d = value.(sql.NullString)
+		case *map[string]any:
+			*d = value.(map[string]any)
+		case **time.Time:
+			*d = value.(*time.Time)
+		default:
+			return fmt.Errorf("unexpected destination %T", d)
+		}
+	}
+	return nil
+}
+
+func (fr *fakeRows) Err() error {
+	return nil
+}
+
+type fakeQuerier struct {
+	rows []fakeRow
+}
+
+func (fq fakeQuerier) QueryFunc(ctx context.Context, f func(context.Context, *fakeRows) error, sql string, args ...any) error {
+	return f(ctx, &fakeRows{rows: fq.rows})
+}
+
+func newRelationshipsTestBuilder() RelationshipsQueryBuilder {
+	schema := NewSchemaInformationWithOptions(
+		WithRelationshipTableName("relationtuples"),
+		WithColNamespace("ns"),
+		WithColObjectID("object_id"),
+		WithColRelation("relation"),
+		WithColUsersetNamespace("subject_ns"),
+		WithColUsersetObjectID("subject_object_id"),
+		WithColUsersetRelation("subject_relation"),
+		WithColCaveatName("caveat"),
+		WithColCaveatContext("caveat_context"),
+		WithColExpiration("expiration"),
+		WithPlaceholderFormat(sq.Dollar),
+		WithPaginationFilterType(TupleComparison),
+		WithColumnOptimization(ColumnOptimizationOptionNone),
+		WithNowFunction("NOW"),
+	)
+	query := NewSchemaQueryFiltererForRelationshipsSelect(*schema, 100)
+	return RelationshipsQueryBuilder{
+		Schema:           *schema,
+		filteringValues:  query.filteringColumnTracker,
+		baseQueryBuilder: query,
+	}
+}
+
+func TestQueryRelationshipsReusesRows(t *testing.T) {
+	expiration := time.Date(2030, 1, 1, 0, 0, 0, 0, time.FixedZone("offset", 3600))
+	querier := fakeQuerier{rows: []fakeRow{
+		{"document", "first", "viewer", "user", "tom", "...", sql.NullString{String: "somecaveat", Valid: true}, map[string]any{"key": "value"}, &expiration},
+		{"document", "second", "viewer", "group", "eng", "member", sql.NullString{}, nil, nil},
+	}}
+
+	it, err := QueryRelationships[*fakeRows, map[string]any](context.Background(), newRelationshipsTestBuilder(), querier)
+	require.NoError(t, err)
+
+	// Iterating twice reads the rows afresh, with no value carried over from the pooled rows.
+	for range 2 {
+		var rels []tuple.Relationship
+		for rel, err := range it {
+			require.NoError(t, err)
+			rels = append(rels, rel)
+		}
+
+		require.Len(t, rels, 2)
+		require.Equal(t, "document:first#viewer@user:tom[somecaveat:{\"key\":\"value\"}][expiration:2029-12-31T23:00:00Z]", tuple.MustString(rels[0]))
+		require.Equal(t, time.UTC, rels[0].OptionalExpiration.Location())
+		require.Equal(t, "document:second#viewer@group:eng#member", tuple.MustString(rels[1]))
+
+		// The names are interned, rather than referencing the scanned strings.
+		require.Same(t, unsafe.StringData(rels[0].Resource.ObjectType), unsafe.StringData(rels[1].Resource.ObjectType))
+		require.Same(t, unsafe.StringData(rels[0].Resource.Relation), unsafe.StringData(rels[1].Resource.Relation))
+		require.Same(t, unsafe.StringData(tuple.InternName("somecaveat")), unsafe.StringData(rels[0].OptionalCaveat.CaveatName))
+	}
+}
+
+func BenchmarkQueryRelationships(b *testing.B) {
+	querier := fakeQuerier{rows: []fakeRow{
+		{"document", "first", "viewer", "user", "tom", "...", sql.NullString{}, nil, nil},
+	}}
+	builder := newRelationshipsTestBuilder()
+
+	b.ReportAllocs()
+	for range b.N {
+		it, err := QueryRelationships[*fakeRows, map[string]any](context.Background(), builder, querier)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		for _, err := range it {
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
@@ -11,6 +11,7 @@ const (
 	relationshipCaveatColumnCount     = 2 // ColCaveatName, ColCaveatContext
 	relationshipExpirationColumnCount = 1 // ColExpiration
 	relationshipIntegrityColumnCount  = 3 // ColIntegrityKeyID, ColIntegrityHash, ColIntegrityTimestamp
+	relationshipWriteRevisionCount    = 1 // ColWriteRevision
 )
 
 // SchemaInformation holds the schema information from the SQL datastore implementation.
@@ -35,6 +36,11 @@ type SchemaInformation struct {
 	ColIntegrityHash      string `debugmap:"visible"`
 	ColIntegrityTimestamp string `debugmap:"visible"`
 
+	// ColWriteRevision is the column, or the expression casting it to a string, identifying the
+	// transaction which last wrote a relationship. If empty, relationships are read without their
+	// write revision.
+	ColWriteRevision string `debugmap:"visible"`
+
 	// PaginationFilterType is the type of pagination filter to use for this schema.
 	PaginationFilterType PaginationFilterType `debugmap:"visible"`
 
@@ -44,6 +50,11 @@ type SchemaInformation struct {
 	// NowFunction is the function to use to get the current time in the datastore.
 	NowFunction string `debugmap:"visible"`
 
+	// EmptyCaveatContextCondition is the condition, with the caveat context column substituted
+	// for each `%[1]s`, that holds for relationships without a written caveat context. If empty,
+	// relationships are not filtered by excluded caveat names.
+	EmptyCaveatContextCondition string `debugmap:"visible"`
+
 	// ColumnOptimization is the optimization to use for columns in the schema, if any.
 	ColumnOptimization ColumnOptimizationOption `debugmap:"visible"`
 
@@ -0,0 +1,100 @@
+package common
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	queryShapeCacheHit  = "hit"
+	queryShapeCacheMiss = "miss"
+)
+
+var queryShapeCacheCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "spicedb",
+	Subsystem: "datastore",
+	Name:      "query_shape_cache_total",
+	Help:      "number of relationship queries whose SQL was looked up in the cache of query shapes, by whether it was found.",
+}, []string{"driver", "result"})
+
+func init() {
+	prometheus.MustRegister(queryShapeCacheCounter)
+}
+
+// QueryShapeCache caches the SQL generated for relationship queries, keyed by the shape of the
+// query: its selected columns, the SQL of its predicates with placeholders for their values, and
+// its FROM, ORDER BY and LIMIT clauses. As dispatch issues many queries of the same shape which
+// differ only in their values, the SQL of most queries is then neither built nor rendered.
+//
+// The cache holds two generations of entries: once the current generation is full, it replaces
+// the previous one, whose entries are promoted back to the current generation when used. This
+// bounds the cache without tracking recency on every hit, while shapes that are never used
+// again, such as those of past revisions in the FROM clause of CRDB queries, are dropped.
+type QueryShapeCache struct {
+	generationCapacity int
+	hits               prometheus.Counter
+	misses             prometheus.Counter
+
+	mu       sync.Mutex
+	current  map[string]string
+	previous map[string]string
+}
+
+// NewQueryShapeCache creates a cache holding the SQL of up to capacity query shapes, recording
+// its hits and misses under the given driver name. It returns nil, which disables caching, if
+// the capacity is not positive.
+func NewQueryShapeCache(driver string, capacity int) *QueryShapeCache {
+	if capacity <= 0 {
+		return nil
+	}
+
+	return &QueryShapeCache{
+		generationCapacity: max(capacity/2, 1),
+		hits:               queryShapeCacheCounter.WithLabelValues(driver, queryShapeCacheHit),
+		misses:             queryShapeCacheCounter.WithLabelValues(driver, queryShapeCacheMiss),
+		current:            make(map[string]string),
+	}
+}
+
+func (c *QueryShapeCache) get(key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	sql, ok := c.current[key]
+	if !ok {
+		sql, ok = c.previous[key]
+		if ok {
+			delete(c.previous, key)
+			c.addLocked(key, sql)
+		}
+	}
+
+	if ok {
+		c.hits.Inc()
+	} else {
+		c.misses.Inc()
+	}
+	return sql, ok
+}
+
+func (c *QueryShapeCache) set(key string, sql string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.addLocked(key, sql)
+}
+
+func (c *QueryShapeCache) addLocked(key string, sql string) {
+	if len(c.current) >= c.generationCapacity {
+		c.previous = c.current
+		c.current = make(map[string]string, c.generationCapacity)
+	}
+	c.current[key] = sql
+}
+
+// len returns the number of cached shapes.
+func (c *QueryShapeCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.current) + len(c.previous)
+}
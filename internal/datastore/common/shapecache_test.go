@@ -0,0 +1,116 @@
+package common
+
+import (
+	"context"
+	"testing"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/options"
+)
+
+func TestQueryShapeCacheGenerations(t *testing.T) {
+	require.Nil(t, NewQueryShapeCache("test", 0))
+
+	cache := NewQueryShapeCache("test", 4)
+	cache.set("a", "SELECT a")
+	cache.set("b", "SELECT b")
+
+	// Filling the current generation moves it to the previous one.
+	cache.set("c", "SELECT c")
+	require.Equal(t, 3, cache.len())
+
+	// Using an entry of the previous generation promotes it.
+	sql, ok := cache.get("a")
+	require.True(t, ok)
+	require.Equal(t, "SELECT a", sql)
+
+	// The unused entry is dropped once the current generation is full again.
+	cache.set("d", "SELECT d")
+	_, ok = cache.get("b")
+	require.False(t, ok)
+	require.LessOrEqual(t, cache.len(), 4)
+
+	for _, key := range []string{"a", "c", "d"} {
+		_, ok := cache.get(key)
+		require.True(t, ok, key)
+	}
+}
+
+func TestQueryShapeCacheSharedByShape(t *testing.T) {
+	schema := NewSchemaInformationWithOptions(
+		WithRelationshipTableName("relationtuples"),
+		WithColNamespace("ns"),
+		WithColObjectID("object_id"),
+		WithColRelation("relation"),
+		WithColUsersetNamespace("subject_ns"),
+		WithColUsersetObjectID("subject_object_id"),
+		WithColUsersetRelation("subject_relation"),
+		WithColCaveatName("caveat"),
+		WithColCaveatContext("caveat_context"),
+		WithColExpiration("expiration"),
+		WithPlaceholderFormat(sq.Dollar),
+		WithPaginationFilterType(TupleComparison),
+		WithColumnOptimization(ColumnOptimizationOptionStaticValues),
+		WithNowFunction("NOW"),
+	)
+	alive := Predicate{SQL: "deleted_xid = ?", Args: []any{uint64(0)}}
+
+	newQuery := func(resourceType string, resourceIDs ...string) SchemaQueryFilterer {
+		query, err := NewSchemaQueryFiltererForRelationshipsSelect(*schema, 100).
+			WithAdditionalPredicate(alive).
+			FilterToResourceType(resourceType).
+			FilterToResourceIDs(resourceIDs)
+		require.NoError(t, err)
+		return query
+	}
+
+	var lastSQL string
+	var lastArgs []any
+	exc := QueryRelationshipsExecutor{
+		Executor: func(ctx context.Context, builder RelationshipsQueryBuilder) (datastore.RelationshipIterator, error) {
+			var err error
+			lastSQL, lastArgs, err = builder.SelectSQL()
+			return nil, err
+		},
+		BatchExecutor: func(ctx context.Context, builder RelationshipsQueryBuilder) (datastore.TaggedRelationshipIterator, error) {
+			var err error
+			lastSQL, lastArgs, err = builder.SelectSQL()
+			return nil, err
+		},
+		ShapeCache: NewQueryShapeCache("test", 16),
+	}
+
+	// Queries differing only in their values share a shape.
+	_, err := exc.ExecuteQuery(context.Background(), newQuery("document", "first"))
+	require.NoError(t, err)
+	_, err = exc.ExecuteQuery(context.Background(), newQuery("folder", "second"))
+	require.NoError(t, err)
+	require.Equal(t, "SELECT relation, subject_ns, subject_object_id, subject_relation, caveat, caveat_context, expiration FROM relationtuples WHERE deleted_xid = $1 AND ns = $2 AND object_id IN ($3) AND (expiration IS NULL OR expiration > NOW())", lastSQL)
+	require.Equal(t, []any{uint64(0), "folder", "second"}, lastArgs)
+	require.Equal(t, 1, exc.ShapeCache.len())
+
+	// Filtering on more values, or with other clauses, is another shape.
+	_, err = exc.ExecuteQuery(context.Background(), newQuery("document", "first", "second"))
+	require.NoError(t, err)
+	require.Equal(t, []any{uint64(0), "document", "first", "second"}, lastArgs)
+	limit := uint64(10)
+	_, err = exc.ExecuteQuery(context.Background(), newQuery("document", "first"), options.WithLimit(&limit))
+	require.NoError(t, err)
+	require.Equal(t, 3, exc.ShapeCache.len())
+
+	// Batches are cached by the shapes of their queries.
+	for _, resourceType := range []string{"document", "folder"} {
+		_, err = exc.ExecuteBatchQuery(context.Background(), []SchemaQueryFilterer{newQuery(resourceType, "first"), newQuery("user", "second")})
+		require.NoError(t, err)
+	}
+	require.Equal(t, []any{uint64(0), "folder", "first", uint64(0), "user", "second"}, lastArgs)
+	require.Equal(t, 4, exc.ShapeCache.len())
+
+	// Queries with filters whose SQL is unknown are never cached.
+	_, err = exc.ExecuteQuery(context.Background(), newQuery("document", "first").WithAdditionalFilter(alive.Filter))
+	require.NoError(t, err)
+	require.Equal(t, 4, exc.ShapeCache.len())
+}
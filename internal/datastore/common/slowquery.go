@@ -0,0 +1,113 @@
+package common
+
+import (
+	"context"
+	"iter"
+	"regexp"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc"
+
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+var slowQueriesCount = promauto.NewCounter(prometheus.CounterOpts{
+	Namespace: "spicedb",
+	Subsystem: "datastore",
+	Name:      "slow_queries_total",
+	Help:      "total number of relationship queries which exceeded the slow query threshold",
+})
+
+var (
+	stringLiteralRegex  = regexp.MustCompile(`'(?:[^']|'')*'`)
+	numericLiteralRegex = regexp.MustCompile(`(^|[^\w$@.])\d+(?:\.\d+)?\b`)
+)
+
+// StripLiterals returns the SQL statement with all string and numeric literals replaced by `?`,
+// leaving only the shape of the statement. Placeholders, such as `$1` or `@p1`, are left as-is.
+func StripLiterals(sql string) string {
+	stripped := stringLiteralRegex.ReplaceAllString(sql, "?")
+	return numericLiteralRegex.ReplaceAllString(stripped, "${1}?")
+}
+
+// SlowQueryLogger logs relationship queries whose execution exceeds a threshold. A nil
+// SlowQueryLogger logs nothing.
+type SlowQueryLogger struct {
+	threshold time.Duration
+}
+
+// NewSlowQueryLogger returns a logger for queries exceeding the given threshold, or nil if the
+// threshold is not positive.
+func NewSlowQueryLogger(threshold time.Duration) *SlowQueryLogger {
+	if threshold <= 0 {
+		return nil
+	}
+	return &SlowQueryLogger{threshold: threshold}
+}
+
+// wrapIterator returns an iterator which yields the results of the given iterator, logging the
+// query if the time spent producing the results exceeds the threshold. The time spent by the
+// caller processing each result is not included.
+func (s *SlowQueryLogger) wrapIterator(
+	ctx context.Context,
+	builder RelationshipsQueryBuilder,
+	revision datastore.Revision,
+	it datastore.RelationshipIterator,
+) datastore.RelationshipIterator {
+	return datastore.RelationshipIterator(wrapSeq(s, ctx, builder, revision, iter.Seq2[tuple.Relationship, error](it)))
+}
+
+// wrapSeq implements wrapIterator for any sequence of results.
+func wrapSeq[T any](
+	s *SlowQueryLogger,
+	ctx context.Context,
+	builder RelationshipsQueryBuilder,
+	revision datastore.Revision,
+	seq iter.Seq2[T, error],
+) iter.Seq2[T, error] {
+	if s == nil {
+		return seq
+	}
+
+	return func(yield func(T, error) bool) {
+		start := time.Now()
+		var callerTime time.Duration
+		for result, err := range seq {
+			yieldStart := time.Now()
+			more := yield(result, err)
+			callerTime += time.Since(yieldStart)
+			if !more {
+				break
+			}
+		}
+
+		s.logIfSlow(ctx, time.Since(start)-callerTime, builder, revision)
+	}
+}
+
+func (s *SlowQueryLogger) logIfSlow(ctx context.Context, duration time.Duration, builder RelationshipsQueryBuilder, revision datastore.Revision) {
+	if duration < s.threshold {
+		return
+	}
+
+	slowQueriesCount.Inc()
+
+	event := log.Ctx(ctx).Warn().Dur("duration", duration).Dur("threshold", s.threshold)
+	if statement, _, err := builder.SelectSQL(); err == nil {
+		event = event.Str("statement", StripLiterals(statement))
+	}
+
+	if method, ok := grpc.Method(ctx); ok {
+		event = event.Str("method", method)
+	}
+
+	if revision != nil && revision != datastore.NoRevision {
+		event = event.Stringer("revision", revision)
+	}
+
+	event.Msg("slow datastore query")
+}
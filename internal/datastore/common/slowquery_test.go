@@ -0,0 +1,105 @@
+package common
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/revisions"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+func TestStripLiterals(t *testing.T) {
+	tcs := []struct {
+		sql      string
+		expected string
+	}{
+		{"SELECT ns FROM relationtuples WHERE ns = $1", "SELECT ns FROM relationtuples WHERE ns = $1"},
+		{"SELECT ns FROM relationtuples WHERE ns = @p1 LIMIT 100", "SELECT ns FROM relationtuples WHERE ns = @p1 LIMIT ?"},
+		{"SELECT ns FROM relationtuples WHERE ns = 'document' AND object_id = 'it''s'", "SELECT ns FROM relationtuples WHERE ns = ? AND object_id = ?"},
+		{"SELECT col1, t2.col FROM table2 t2 WHERE x > 1.5", "SELECT col1, t2.col FROM table2 t2 WHERE x > ?"},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.sql, func(t *testing.T) {
+			require.Equal(t, tc.expected, StripLiterals(tc.sql))
+		})
+	}
+}
+
+func TestSlowQueryLogger(t *testing.T) {
+	require.Nil(t, NewSlowQueryLogger(0))
+
+	schema := NewSchemaInformationWithOptions(
+		WithRelationshipTableName("relationtuples"),
+		WithColNamespace("ns"),
+		WithColObjectID("object_id"),
+		WithColRelation("relation"),
+		WithColUsersetNamespace("subject_ns"),
+		WithColUsersetObjectID("subject_object_id"),
+		WithColUsersetRelation("subject_relation"),
+		WithColCaveatName("caveat"),
+		WithColCaveatContext("caveat_context"),
+		WithColExpiration("expiration"),
+		WithPlaceholderFormat(sq.Dollar),
+		WithPaginationFilterType(TupleComparison),
+		WithColumnOptimization(ColumnOptimizationOptionNone),
+		WithNowFunction("NOW"),
+	)
+
+	tcs := []struct {
+		name        string
+		queryTime   time.Duration
+		callerTime  time.Duration
+		expectedLog bool
+	}{
+		{"fast query", 0, 0, false},
+		{"slow query", 50 * time.Millisecond, 0, true},
+		{"slow caller", 0, 50 * time.Millisecond, false},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			ctx := zerolog.New(&buf).WithContext(context.Background())
+
+			executor := QueryRelationshipsExecutor{
+				Executor: func(ctx context.Context, builder RelationshipsQueryBuilder) (datastore.RelationshipIterator, error) {
+					return func(yield func(tuple.Relationship, error) bool) {
+						time.Sleep(tc.queryTime)
+						yield(tuple.MustParse("document:first#viewer@user:tom"), nil)
+					}, nil
+				},
+				SlowQueryLogger: NewSlowQueryLogger(25 * time.Millisecond),
+				Revision:        revisions.NewForTransactionID(42),
+			}
+
+			filterer := NewSchemaQueryFiltererForRelationshipsSelect(*schema, 100).FilterToResourceType("document")
+			iter, err := executor.ExecuteQuery(ctx, filterer)
+			require.NoError(t, err)
+
+			count := 0
+			for _, err := range iter {
+				require.NoError(t, err)
+				time.Sleep(tc.callerTime)
+				count++
+			}
+			require.Equal(t, 1, count)
+
+			if !tc.expectedLog {
+				require.Empty(t, buf.String())
+				return
+			}
+
+			require.Contains(t, buf.String(), "slow datastore query")
+			require.Contains(t, buf.String(), `"statement":"SELECT ns, object_id, relation, subject_ns, subject_object_id, subject_relation, caveat, caveat_context, expiration FROM relationtuples WHERE ns = $1`)
+			require.Contains(t, buf.String(), `"revision":"42"`)
+		})
+	}
+}
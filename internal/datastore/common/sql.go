@@ -2,8 +2,12 @@ package common
 
 import (
 	"context"
+	"fmt"
+	"iter"
 	"maps"
 	"math"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -81,6 +85,13 @@ const (
 
 type columnTracker struct {
 	SingleValue *string
+
+	// valueCount is the number of values the column is compared to for equality, which may
+	// overcount repeated values.
+	valueCount int
+
+	// nonEquality is true if the column is also filtered by other comparisons than equality.
+	nonEquality bool
 }
 
 type columnTrackerMap map[string]columnTracker
@@ -102,6 +113,29 @@ type SchemaQueryFilterer struct {
 	isCustomQuery          bool
 	extraFields            []string
 	fromSuffix             string
+	indexHint              string
+
+	// predicates are the SQL of the predicates of the query, with `?` placeholders, and args
+	// their arguments in order. With the FROM, ORDER BY and LIMIT clauses of shapeClauses, they
+	// make up the shape under which the SQL of the query is cached.
+	predicates   []string
+	args         []any
+	shapeClauses string
+
+	// opaque is true if a filter whose SQL is not recorded was applied, in which case the SQL
+	// of the query is never cached.
+	opaque bool
+}
+
+// Predicate is a SQL predicate with `?` placeholders, and its arguments.
+type Predicate struct {
+	SQL  string
+	Args []any
+}
+
+// Filter adds the predicate to a select query.
+func (p Predicate) Filter(original sq.SelectBuilder) sq.SelectBuilder {
+	return original.Where(p.SQL, p.Args...)
 }
 
 // NewSchemaQueryFiltererForRelationshipsSelect creates a new SchemaQueryFilterer object for selecting
@@ -148,8 +182,27 @@ func NewSchemaQueryFiltererWithStartingQuery(schema SchemaInformation, startingQ
 }
 
 // WithAdditionalFilter returns the SchemaQueryFilterer with an additional filter applied to the query.
+// As the SQL of the filter is unknown until rendered, the SQL of the query is never cached: prefer
+// WithAdditionalPredicate where possible.
 func (sqf SchemaQueryFilterer) WithAdditionalFilter(filter func(original sq.SelectBuilder) sq.SelectBuilder) SchemaQueryFilterer {
 	sqf.queryBuilder = filter(sqf.queryBuilder)
+	sqf.opaque = true
+	return sqf
+}
+
+// WithAdditionalPredicate returns the SchemaQueryFilterer with an additional predicate applied to
+// the query.
+func (sqf SchemaQueryFilterer) WithAdditionalPredicate(predicate Predicate) SchemaQueryFilterer {
+	return sqf.where(predicate.SQL, predicate.Args...)
+}
+
+// where adds a predicate to the query, recording its SQL and arguments as they are rendered.
+// The slices are copied on append, as they may be shared with the filterer this one was
+// derived from.
+func (sqf SchemaQueryFilterer) where(predicate string, args ...any) SchemaQueryFilterer {
+	sqf.queryBuilder = sqf.queryBuilder.Where(predicate, args...)
+	sqf.predicates = append(slices.Clip(sqf.predicates), predicate)
+	sqf.args = append(slices.Clip(sqf.args), args...)
 	return sqf
 }
 
@@ -159,6 +212,13 @@ func (sqf SchemaQueryFilterer) WithFromSuffix(fromSuffix string) SchemaQueryFilt
 	return sqf
 }
 
+// WithIndexHint returns the SchemaQueryFilterer with an index hint added right after the table
+// name in the FROM clause, such as `@index` or ` FORCE INDEX (index)`.
+func (sqf SchemaQueryFilterer) WithIndexHint(indexHint string) SchemaQueryFilterer {
+	sqf.indexHint = indexHint
+	return sqf
+}
+
 func (sqf SchemaQueryFilterer) UnderlyingQueryBuilder() sq.SelectBuilder {
 	spiceerrors.DebugAssert(func() bool {
 		return sqf.isCustomQuery
@@ -174,36 +234,50 @@ func (sqf SchemaQueryFilterer) queryBuilderWithMaybeExpirationFilter(skipExpirat
 	}
 
 	// Filter out any expired relationships.
-	return sqf.queryBuilder.Where(sq.Or{
-		sq.Eq{sqf.schema.ColExpiration: nil},
-		sq.Expr(sqf.schema.ColExpiration + " > " + sqf.schema.NowFunction + "()"),
-	})
+	return sqf.queryBuilder.Where(sqf.expirationPredicate())
+}
+
+// expirationPredicate returns the predicate filtering out expired relationships, which has no
+// arguments.
+func (sqf SchemaQueryFilterer) expirationPredicate() string {
+	return "(" + sqf.schema.ColExpiration + " IS NULL OR " + sqf.schema.ColExpiration + " > " + sqf.schema.NowFunction + "())"
 }
 
 func (sqf SchemaQueryFilterer) TupleOrder(order options.SortOrder) SchemaQueryFilterer {
+	if columns := sqf.schema.orderColumns(order); len(columns) > 0 {
+		sqf.queryBuilder = sqf.queryBuilder.OrderBy(columns...)
+		sqf.shapeClauses += " ORDER BY " + strings.Join(columns, ", ")
+	}
+
+	return sqf
+}
+
+// orderColumns returns the columns by which relationships are ordered for the given sort order.
+func (si SchemaInformation) orderColumns(order options.SortOrder) []string {
 	switch order {
 	case options.ByResource:
-		sqf.queryBuilder = sqf.queryBuilder.OrderBy(
-			sqf.schema.ColNamespace,
-			sqf.schema.ColObjectID,
-			sqf.schema.ColRelation,
-			sqf.schema.ColUsersetNamespace,
-			sqf.schema.ColUsersetObjectID,
-			sqf.schema.ColUsersetRelation,
-		)
+		return []string{
+			si.ColNamespace,
+			si.ColObjectID,
+			si.ColRelation,
+			si.ColUsersetNamespace,
+			si.ColUsersetObjectID,
+			si.ColUsersetRelation,
+		}
 
 	case options.BySubject:
-		sqf.queryBuilder = sqf.queryBuilder.OrderBy(
-			sqf.schema.ColUsersetNamespace,
-			sqf.schema.ColUsersetObjectID,
-			sqf.schema.ColUsersetRelation,
-			sqf.schema.ColNamespace,
-			sqf.schema.ColObjectID,
-			sqf.schema.ColRelation,
-		)
-	}
+		return []string{
+			si.ColUsersetNamespace,
+			si.ColUsersetObjectID,
+			si.ColUsersetRelation,
+			si.ColNamespace,
+			si.ColObjectID,
+			si.ColRelation,
+		}
 
-	return sqf
+	default:
+		return nil
+	}
 }
 
 type nameAndValue struct {
@@ -236,6 +310,8 @@ func (sqf SchemaQueryFilterer) After(cursor options.Cursor, order options.SortOr
 				sqf.schema.ColUsersetRelation, cursor.Subject.Relation,
 			},
 		},
+		// The columns must be compared in the same order as that of TupleOrder, or relationships
+		// of the same subject object with differing subject relations are skipped.
 		options.BySubject: {
 			{
 				sqf.schema.ColUsersetNamespace, cursor.Subject.ObjectType,
@@ -243,6 +319,9 @@ func (sqf SchemaQueryFilterer) After(cursor options.Cursor, order options.SortOr
 			{
 				sqf.schema.ColUsersetObjectID, cursor.Subject.ObjectID,
 			},
+			{
+				sqf.schema.ColUsersetRelation, cursor.Subject.Relation,
+			},
 			{
 				sqf.schema.ColNamespace, cursor.Resource.ObjectType,
 			},
@@ -252,9 +331,6 @@ func (sqf SchemaQueryFilterer) After(cursor options.Cursor, order options.SortOr
 			{
 				sqf.schema.ColRelation, cursor.Resource.Relation,
 			},
-			{
-				sqf.schema.ColUsersetRelation, cursor.Subject.Relation,
-			},
 		},
 	}[order]
 
@@ -275,32 +351,32 @@ func (sqf SchemaQueryFilterer) After(cursor options.Cursor, order options.SortOr
 
 		if comparisonSlotCount > 0 {
 			comparisonTuple := "(" + strings.Join(columnNames, ",") + ") > (" + strings.Repeat(",?", comparisonSlotCount)[1:] + ")"
-			sqf.queryBuilder = sqf.queryBuilder.Where(
-				comparisonTuple,
-				valueSlots...,
-			)
+			sqf = sqf.where(comparisonTuple, valueSlots...)
 		}
 
 	case ExpandedLogicComparison:
 		// For performance reasons, remove any column names that have static values in the query.
-		orClause := sq.Or{}
+		orClause := make([]string, 0, len(columnsAndValues))
+		var args []any
 
 		for index, cav := range columnsAndValues {
 			if !sqf.filteringColumnTracker.hasStaticValue(cav.name) {
-				andClause := sq.And{}
+				andClause := make([]string, 0, index+1)
 				for _, previous := range columnsAndValues[0:index] {
 					if !sqf.filteringColumnTracker.hasStaticValue(previous.name) {
-						andClause = append(andClause, sq.Eq{previous.name: previous.value})
+						andClause = append(andClause, previous.name+" = ?")
+						args = append(args, previous.value)
 					}
 				}
 
-				andClause = append(andClause, sq.Gt{cav.name: cav.value})
-				orClause = append(orClause, andClause)
+				andClause = append(andClause, cav.name+" > ?")
+				args = append(args, cav.value)
+				orClause = append(orClause, "("+strings.Join(andClause, " AND ")+")")
 			}
 		}
 
 		if len(orClause) > 0 {
-			sqf.queryBuilder = sqf.queryBuilder.Where(orClause)
+			sqf = sqf.where("("+strings.Join(orClause, " OR ")+")", args...)
 		}
 	}
 
@@ -310,30 +386,42 @@ func (sqf SchemaQueryFilterer) After(cursor options.Cursor, order options.SortOr
 // FilterToResourceType returns a new SchemaQueryFilterer that is limited to resources of the
 // specified type.
 func (sqf SchemaQueryFilterer) FilterToResourceType(resourceType string) SchemaQueryFilterer {
-	sqf.queryBuilder = sqf.queryBuilder.Where(sq.Eq{sqf.schema.ColNamespace: resourceType})
+	sqf = sqf.where(sqf.schema.ColNamespace+" = ?", resourceType)
 	sqf.recordColumnValue(sqf.schema.ColNamespace, resourceType)
 	return sqf
 }
 
 func (sqf SchemaQueryFilterer) recordColumnValue(colName string, colValue string) {
 	existing, ok := sqf.filteringColumnTracker[colName]
-	if ok {
-		if existing.SingleValue != nil && *existing.SingleValue != colValue {
-			sqf.filteringColumnTracker[colName] = columnTracker{SingleValue: nil}
-		}
-	} else {
-		sqf.filteringColumnTracker[colName] = columnTracker{SingleValue: &colValue}
+	switch {
+	case !ok:
+		existing = columnTracker{SingleValue: &colValue, valueCount: 1}
+	case existing.SingleValue != nil && *existing.SingleValue == colValue:
+		return
+	default:
+		existing.SingleValue = nil
+		existing.valueCount++
 	}
+	sqf.filteringColumnTracker[colName] = existing
 }
 
 func (sqf SchemaQueryFilterer) recordVaryingColumnValue(colName string) {
-	sqf.filteringColumnTracker[colName] = columnTracker{SingleValue: nil}
+	existing := sqf.filteringColumnTracker[colName]
+	existing.SingleValue = nil
+	sqf.filteringColumnTracker[colName] = existing
+}
+
+func (sqf SchemaQueryFilterer) recordNonEqualityColumnFilter(colName string) {
+	existing := sqf.filteringColumnTracker[colName]
+	existing.SingleValue = nil
+	existing.nonEquality = true
+	sqf.filteringColumnTracker[colName] = existing
 }
 
 // FilterToResourceID returns a new SchemaQueryFilterer that is limited to resources with the
 // specified ID.
 func (sqf SchemaQueryFilterer) FilterToResourceID(objectID string) SchemaQueryFilterer {
-	sqf.queryBuilder = sqf.queryBuilder.Where(sq.Eq{sqf.schema.ColObjectID: objectID})
+	sqf = sqf.where(sqf.schema.ColObjectID+" = ?", objectID)
 	sqf.recordColumnValue(sqf.schema.ColObjectID, objectID)
 	return sqf
 }
@@ -359,7 +447,7 @@ func (sqf SchemaQueryFilterer) FilterWithResourceIDPrefix(prefix string) (Schema
 	prefix = strings.ReplaceAll(prefix, `\`, `\\`)
 	prefix = strings.ReplaceAll(prefix, "_", `\_`)
 
-	sqf.queryBuilder = sqf.queryBuilder.Where(sq.Like{sqf.schema.ColObjectID: prefix + "%"})
+	sqf = sqf.where(sqf.schema.ColObjectID+" LIKE ?", prefix+"%")
 
 	// NOTE: we do *not* record the use of the resource ID column here, because it is not used
 	// statically and thus is necessary for sorting operations.
@@ -401,14 +489,13 @@ func (sqf SchemaQueryFilterer) FilterToResourceIDs(resourceIds []string) (Schema
 	}
 	builder.WriteString(")")
 
-	sqf.queryBuilder = sqf.queryBuilder.Where(builder.String(), args...)
-	return sqf, nil
+	return sqf.where(builder.String(), args...), nil
 }
 
 // FilterToRelation returns a new SchemaQueryFilterer that is limited to resources with the
 // specified relation.
 func (sqf SchemaQueryFilterer) FilterToRelation(relation string) SchemaQueryFilterer {
-	sqf.queryBuilder = sqf.queryBuilder.Where(sq.Eq{sqf.schema.ColRelation: relation})
+	sqf = sqf.where(sqf.schema.ColRelation+" = ?", relation)
 	sqf.recordColumnValue(sqf.schema.ColRelation, relation)
 	return sqf
 }
@@ -466,11 +553,15 @@ func (sqf SchemaQueryFilterer) FilterWithRelationshipsFilter(filter datastore.Re
 		csqf = csqf.FilterWithCaveatName(filter.OptionalCaveatName)
 	}
 
+	if len(filter.OptionalExcludedCaveatNames) > 0 {
+		csqf = csqf.FilterWithExcludedCaveatNames(filter.OptionalExcludedCaveatNames)
+	}
+
 	if filter.OptionalExpirationOption == datastore.ExpirationFilterOptionHasExpiration {
-		csqf.queryBuilder = csqf.queryBuilder.Where(sq.NotEq{csqf.schema.ColExpiration: nil})
+		csqf = csqf.where(csqf.schema.ColExpiration + " IS NOT NULL")
 		spiceerrors.DebugAssert(func() bool { return !sqf.schema.ExpirationDisabled }, "expiration filter requested but schema does not support expiration")
 	} else if filter.OptionalExpirationOption == datastore.ExpirationFilterOptionNoExpiration {
-		csqf.queryBuilder = csqf.queryBuilder.Where(sq.Eq{csqf.schema.ColExpiration: nil})
+		csqf = csqf.where(csqf.schema.ColExpiration + " IS NULL")
 	}
 
 	return csqf, nil
@@ -489,7 +580,8 @@ func (sqf SchemaQueryFilterer) MustFilterWithSubjectsSelectors(selectors ...data
 // FilterWithSubjectsSelectors returns a new SchemaQueryFilterer that is limited to resources with
 // subjects that match the specified selector(s).
 func (sqf SchemaQueryFilterer) FilterWithSubjectsSelectors(selectors ...datastore.SubjectsSelector) (SchemaQueryFilterer, error) {
-	selectorsOrClause := sq.Or{}
+	selectorsOrClause := make([]string, 0, len(selectors))
+	var selectorsArgs []any
 
 	// If there is more than a single filter, record all the subjects as varying, as the subjects returned
 	// can differ for each branch.
@@ -498,13 +590,27 @@ func (sqf SchemaQueryFilterer) FilterWithSubjectsSelectors(selectors ...datastor
 		sqf.recordVaryingColumnValue(sqf.schema.ColUsersetNamespace)
 		sqf.recordVaryingColumnValue(sqf.schema.ColUsersetObjectID)
 		sqf.recordVaryingColumnValue(sqf.schema.ColUsersetRelation)
+
+		// Columns not filtered by every selector are not restricted to the values of the others.
+		for _, selector := range selectors {
+			if len(selector.OptionalSubjectType) == 0 {
+				sqf.recordNonEqualityColumnFilter(sqf.schema.ColUsersetNamespace)
+			}
+			if len(selector.OptionalSubjectIds) == 0 {
+				sqf.recordNonEqualityColumnFilter(sqf.schema.ColUsersetObjectID)
+			}
+			if selector.RelationFilter.IsEmpty() {
+				sqf.recordNonEqualityColumnFilter(sqf.schema.ColUsersetRelation)
+			}
+		}
 	}
 
 	for _, selector := range selectors {
-		selectorClause := sq.And{}
+		selectorClause := make([]string, 0, 3)
 
 		if len(selector.OptionalSubjectType) > 0 {
-			selectorClause = append(selectorClause, sq.Eq{sqf.schema.ColUsersetNamespace: selector.OptionalSubjectType})
+			selectorClause = append(selectorClause, sqf.schema.ColUsersetNamespace+" = ?")
+			selectorsArgs = append(selectorsArgs, selector.OptionalSubjectType)
 			sqf.recordColumnValue(sqf.schema.ColUsersetNamespace, selector.OptionalSubjectType)
 		}
 
@@ -533,13 +639,15 @@ func (sqf SchemaQueryFilterer) FilterWithSubjectsSelectors(selectors ...datastor
 			}
 
 			builder.WriteString(")")
-			selectorClause = append(selectorClause, sq.Expr(builder.String(), args...))
+			selectorClause = append(selectorClause, builder.String())
+			selectorsArgs = append(selectorsArgs, args...)
 		}
 
 		if !selector.RelationFilter.IsEmpty() {
 			if selector.RelationFilter.OnlyNonEllipsisRelations {
-				selectorClause = append(selectorClause, sq.NotEq{sqf.schema.ColUsersetRelation: datastore.Ellipsis})
-				sqf.recordVaryingColumnValue(sqf.schema.ColUsersetRelation)
+				selectorClause = append(selectorClause, sqf.schema.ColUsersetRelation+" <> ?")
+				selectorsArgs = append(selectorsArgs, datastore.Ellipsis)
+				sqf.recordNonEqualityColumnFilter(sqf.schema.ColUsersetRelation)
 			} else {
 				relations := make([]string, 0, 2)
 				if selector.RelationFilter.IncludeEllipsisRelation {
@@ -552,43 +660,52 @@ func (sqf SchemaQueryFilterer) FilterWithSubjectsSelectors(selectors ...datastor
 
 				if len(relations) == 1 {
 					relName := relations[0]
-					selectorClause = append(selectorClause, sq.Eq{sqf.schema.ColUsersetRelation: relName})
+					selectorClause = append(selectorClause, sqf.schema.ColUsersetRelation+" = ?")
+					selectorsArgs = append(selectorsArgs, relName)
 					sqf.recordColumnValue(sqf.schema.ColUsersetRelation, relName)
 				} else {
-					orClause := sq.Or{}
+					orClause := make([]string, 0, len(relations))
 					for _, relationName := range relations {
 						dsRelationName := stringz.DefaultEmpty(relationName, datastore.Ellipsis)
-						orClause = append(orClause, sq.Eq{sqf.schema.ColUsersetRelation: dsRelationName})
+						orClause = append(orClause, sqf.schema.ColUsersetRelation+" = ?")
+						selectorsArgs = append(selectorsArgs, dsRelationName)
 						sqf.recordColumnValue(sqf.schema.ColUsersetRelation, dsRelationName)
 					}
 
-					selectorClause = append(selectorClause, orClause)
+					selectorClause = append(selectorClause, "("+strings.Join(orClause, " OR ")+")")
 				}
 			}
 		}
 
-		selectorsOrClause = append(selectorsOrClause, selectorClause)
+		// The predicates are rendered as squirrel renders its conjunctions, empty ones included.
+		if len(selectorClause) == 0 {
+			selectorsOrClause = append(selectorsOrClause, "(1=1)")
+		} else {
+			selectorsOrClause = append(selectorsOrClause, "("+strings.Join(selectorClause, " AND ")+")")
+		}
 	}
 
-	sqf.queryBuilder = sqf.queryBuilder.Where(selectorsOrClause)
-	return sqf, nil
+	if len(selectorsOrClause) == 0 {
+		return sqf.where("(1=0)"), nil
+	}
+	return sqf.where("("+strings.Join(selectorsOrClause, " OR ")+")", selectorsArgs...), nil
 }
 
 // FilterToSubjectFilter returns a new SchemaQueryFilterer that is limited to resources with
 // subjects that match the specified filter.
 func (sqf SchemaQueryFilterer) FilterToSubjectFilter(filter *v1.SubjectFilter) SchemaQueryFilterer {
-	sqf.queryBuilder = sqf.queryBuilder.Where(sq.Eq{sqf.schema.ColUsersetNamespace: filter.SubjectType})
+	sqf = sqf.where(sqf.schema.ColUsersetNamespace+" = ?", filter.SubjectType)
 	sqf.recordColumnValue(sqf.schema.ColUsersetNamespace, filter.SubjectType)
 
 	if filter.OptionalSubjectId != "" {
-		sqf.queryBuilder = sqf.queryBuilder.Where(sq.Eq{sqf.schema.ColUsersetObjectID: filter.OptionalSubjectId})
+		sqf = sqf.where(sqf.schema.ColUsersetObjectID+" = ?", filter.OptionalSubjectId)
 		sqf.recordColumnValue(sqf.schema.ColUsersetObjectID, filter.OptionalSubjectId)
 	}
 
 	if filter.OptionalRelation != nil {
 		dsRelationName := stringz.DefaultEmpty(filter.OptionalRelation.Relation, datastore.Ellipsis)
 
-		sqf.queryBuilder = sqf.queryBuilder.Where(sq.Eq{sqf.schema.ColUsersetRelation: dsRelationName})
+		sqf = sqf.where(sqf.schema.ColUsersetRelation+" = ?", dsRelationName)
 		sqf.recordColumnValue(sqf.schema.ColUsersetRelation, datastore.Ellipsis)
 	}
 
@@ -596,36 +713,177 @@ func (sqf SchemaQueryFilterer) FilterToSubjectFilter(filter *v1.SubjectFilter) S
 }
 
 func (sqf SchemaQueryFilterer) FilterWithCaveatName(caveatName string) SchemaQueryFilterer {
-	sqf.queryBuilder = sqf.queryBuilder.Where(sq.Eq{sqf.schema.ColCaveatName: caveatName})
+	sqf = sqf.where(sqf.schema.ColCaveatName+" = ?", caveatName)
 	sqf.recordColumnValue(sqf.schema.ColCaveatName, caveatName)
 	return sqf
 }
 
+// FilterWithExcludedCaveatNames returns a new SchemaQueryFilterer that excludes relationships
+// with one of the given caveats and without a written caveat context. If the schema cannot
+// determine whether a caveat context is empty, no relationships are excluded.
+func (sqf SchemaQueryFilterer) FilterWithExcludedCaveatNames(caveatNames []string) SchemaQueryFilterer {
+	if sqf.schema.EmptyCaveatContextCondition == "" {
+		return sqf
+	}
+
+	emptyContext := fmt.Sprintf(sqf.schema.EmptyCaveatContextCondition, sqf.schema.ColCaveatContext)
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(caveatNames)), ",")
+	args := make([]any, 0, len(caveatNames))
+	for _, caveatName := range caveatNames {
+		args = append(args, caveatName)
+	}
+
+	return sqf.where(fmt.Sprintf("NOT (COALESCE(%s, '') IN (%s) AND %s)", sqf.schema.ColCaveatName, placeholders, emptyContext), args...)
+}
+
 // Limit returns a new SchemaQueryFilterer which is limited to the specified number of results.
 func (sqf SchemaQueryFilterer) limit(limit uint64) SchemaQueryFilterer {
 	sqf.queryBuilder = sqf.queryBuilder.Limit(limit)
+	sqf.shapeClauses += " LIMIT " + strconv.FormatUint(limit, 10)
 	return sqf
 }
 
 // QueryRelationshipsExecutor is a relationships query runner shared by SQL implementations of the datastore.
 type QueryRelationshipsExecutor struct {
 	Executor ExecuteReadRelsQueryFunc
+
+	// BatchExecutor, if non-nil, executes batched queries as a single rendered SQL query. If nil,
+	// the queries of a batch are executed one after the other by Executor.
+	BatchExecutor ExecuteBatchReadRelsQueryFunc
+
+	// SlowQueryLogger, if non-nil, logs queries whose execution exceeds its threshold.
+	SlowQueryLogger *SlowQueryLogger
+
+	// Revision is the revision at which queries are executed, if known, logged for slow queries.
+	Revision datastore.Revision
+
+	// ShapeCache, if non-nil, caches the SQL of the queries by their shape.
+	ShapeCache *QueryShapeCache
 }
 
 // ExecuteReadRelsQueryFunc is a function that can be used to execute a single rendered SQL query.
 type ExecuteReadRelsQueryFunc func(ctx context.Context, builder RelationshipsQueryBuilder) (datastore.RelationshipIterator, error)
 
+// ExecuteBatchReadRelsQueryFunc is a function that can be used to execute a single rendered SQL
+// query combining the queries of a batch, whose rows are tagged with the index of their query.
+type ExecuteBatchReadRelsQueryFunc func(ctx context.Context, builder RelationshipsQueryBuilder) (datastore.TaggedRelationshipIterator, error)
+
 // ExecuteQuery executes the query.
 func (exc QueryRelationshipsExecutor) ExecuteQuery(
 	ctx context.Context,
 	query SchemaQueryFilterer,
 	opts ...options.QueryOptionsOption,
 ) (datastore.RelationshipIterator, error) {
-	if query.isCustomQuery {
-		return nil, spiceerrors.MustBugf("ExecuteQuery should not be called on custom queries")
+	queryOpts := options.NewQueryOptionsWithOptions(opts...)
+
+	query, err := prepareQuery(query, queryOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	builder := RelationshipsQueryBuilder{
+		Schema:                query.schema,
+		SkipCaveats:           queryOpts.SkipCaveats,
+		SkipExpiration:        queryOpts.SkipExpiration,
+		IncludeWriteRevisions: queryOpts.IncludeWriteRevisions,
+		sqlAssertion:          queryOpts.SQLAssertion,
+		filteringValues:       query.filteringColumnTracker,
+		baseQueryBuilder:      query,
+		shapeCache:            exc.ShapeCache,
 	}
 
+	iter, err := exc.Executor(ctx, builder)
+	if err != nil {
+		return nil, err
+	}
+
+	return exc.SlowQueryLogger.wrapIterator(ctx, builder, exc.Revision, iter), nil
+}
+
+// ExecuteBatchQuery executes the queries, combined into a single SQL query with UNION ALL if the
+// executor supports it. The options apply to each query; cursors are not supported.
+func (exc QueryRelationshipsExecutor) ExecuteBatchQuery(
+	ctx context.Context,
+	queries []SchemaQueryFilterer,
+	opts ...options.QueryOptionsOption,
+) (datastore.TaggedRelationshipIterator, error) {
 	queryOpts := options.NewQueryOptionsWithOptions(opts...)
+	if queryOpts.After != nil {
+		return nil, datastore.ErrCursorsInBatch
+	}
+
+	if len(queries) == 0 {
+		return func(yield func(datastore.TaggedRelationship, error) bool) {}, nil
+	}
+
+	if exc.BatchExecutor == nil {
+		return exc.executeBatchSequentially(ctx, queries, opts...), nil
+	}
+
+	prepared := make([]SchemaQueryFilterer, 0, len(queries))
+	for _, query := range queries {
+		query, err := prepareQuery(query, queryOpts)
+		if err != nil {
+			return nil, err
+		}
+		prepared = append(prepared, query)
+	}
+
+	// Columns are never elided from batched queries, as their static values differ between the
+	// queries of the batch.
+	builder := RelationshipsQueryBuilder{
+		Schema:                prepared[0].schema,
+		SkipCaveats:           queryOpts.SkipCaveats,
+		SkipExpiration:        queryOpts.SkipExpiration,
+		IncludeWriteRevisions: queryOpts.IncludeWriteRevisions,
+		sqlAssertion:          queryOpts.SQLAssertion,
+		filteringValues:       columnTrackerMap{},
+		baseQueryBuilder:      prepared[0],
+		batchQueries:          prepared,
+		batchSort:             queryOpts.Sort,
+		shapeCache:            exc.ShapeCache,
+	}
+
+	it, err := exc.BatchExecutor(ctx, builder)
+	if err != nil {
+		return nil, err
+	}
+
+	return datastore.TaggedRelationshipIterator(wrapSeq(exc.SlowQueryLogger, ctx, builder, exc.Revision, iter.Seq2[datastore.TaggedRelationship, error](it))), nil
+}
+
+func (exc QueryRelationshipsExecutor) executeBatchSequentially(
+	ctx context.Context,
+	queries []SchemaQueryFilterer,
+	opts ...options.QueryOptionsOption,
+) datastore.TaggedRelationshipIterator {
+	return func(yield func(datastore.TaggedRelationship, error) bool) {
+		for index, query := range queries {
+			iter, err := exc.ExecuteQuery(ctx, query, opts...)
+			if err != nil {
+				yield(datastore.TaggedRelationship{}, err)
+				return
+			}
+
+			for rel, err := range iter {
+				if err != nil {
+					yield(datastore.TaggedRelationship{}, err)
+					return
+				}
+
+				if !yield(datastore.TaggedRelationship{FilterIndex: index, Relationship: rel}, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// prepareQuery adds the sort order, cursor, limit and FROM clause of the options to the query.
+func prepareQuery(query SchemaQueryFilterer, queryOpts *options.QueryOptions) (SchemaQueryFilterer, error) {
+	if query.isCustomQuery {
+		return query, spiceerrors.MustBugf("ExecuteQuery should not be called on custom queries")
+	}
 
 	// Add sort order.
 	query = query.TupleOrder(queryOpts.Sort)
@@ -633,7 +891,7 @@ func (exc QueryRelationshipsExecutor) ExecuteQuery(
 	// Add cursor.
 	if queryOpts.After != nil {
 		if queryOpts.Sort == options.Unsorted {
-			return nil, datastore.ErrCursorsWithoutSorting
+			return query, datastore.ErrCursorsWithoutSorting
 		}
 
 		query = query.After(queryOpts.After, queryOpts.Sort)
@@ -655,35 +913,50 @@ func (exc QueryRelationshipsExecutor) ExecuteQuery(
 	}
 
 	// Add FROM clause.
-	from := query.schema.RelationshipTableName
+	from := query.schema.RelationshipTableName + query.indexHint
 	if query.fromSuffix != "" {
 		from += " " + query.fromSuffix
 	}
 
 	query.queryBuilder = query.queryBuilder.From(from)
-
-	builder := RelationshipsQueryBuilder{
-		Schema:           query.schema,
-		SkipCaveats:      queryOpts.SkipCaveats,
-		SkipExpiration:   queryOpts.SkipExpiration,
-		sqlAssertion:     queryOpts.SQLAssertion,
-		filteringValues:  query.filteringColumnTracker,
-		baseQueryBuilder: query,
-	}
-
-	return exc.Executor(ctx, builder)
+	query.shapeClauses = " FROM " + from + query.shapeClauses
+	return query, nil
 }
 
 // RelationshipsQueryBuilder is a builder for producing the SQL and arguments necessary for reading
 // relationships.
 type RelationshipsQueryBuilder struct {
-	Schema         SchemaInformation
-	SkipCaveats    bool
-	SkipExpiration bool
+	Schema                SchemaInformation
+	SkipCaveats           bool
+	SkipExpiration        bool
+	IncludeWriteRevisions bool
 
 	filteringValues  columnTrackerMap
 	baseQueryBuilder SchemaQueryFilterer
 	sqlAssertion     options.Assertion
+
+	// batchQueries are the queries combined by the builder, if it builds a batched query.
+	batchQueries []SchemaQueryFilterer
+	batchSort    options.SortOrder
+
+	shapeCache *QueryShapeCache
+}
+
+// batchFilterIndexColumn is the column holding the index of the query of a batch matched by a row.
+const batchFilterIndexColumn = "batch_filter_index"
+
+// IsBatch returns true if the builder combines the queries of a batch.
+func (b RelationshipsQueryBuilder) IsBatch() bool {
+	return len(b.batchQueries) > 0
+}
+
+// WithFilterIndexColumn adds the slot for the index of the query of a batch to the columns to
+// select, if the builder combines the queries of a batch. It must be called after ColumnsToSelect.
+func (b RelationshipsQueryBuilder) WithFilterIndexColumn(colsToSelect []any, filterIndex *int64) []any {
+	if !b.IsBatch() {
+		return colsToSelect
+	}
+	return append(colsToSelect, filterIndex)
 }
 
 // withCaveats returns true if caveats should be included in the query.
@@ -701,6 +974,11 @@ func (b RelationshipsQueryBuilder) integrityEnabled() bool {
 	return b.Schema.IntegrityEnabled
 }
 
+// withWriteRevisions returns true if the write revision column should be included in the query.
+func (b RelationshipsQueryBuilder) withWriteRevisions() bool {
+	return b.IncludeWriteRevisions && b.Schema.ColWriteRevision != ""
+}
+
 // columnCount returns the number of columns that will be selected in the query.
 func (b RelationshipsQueryBuilder) columnCount() int {
 	columnCount := relationshipStandardColumnCount
@@ -713,11 +991,147 @@ func (b RelationshipsQueryBuilder) columnCount() int {
 	if b.integrityEnabled() {
 		columnCount += relationshipIntegrityColumnCount
 	}
+	if b.withWriteRevisions() {
+		columnCount += relationshipWriteRevisionCount
+	}
 	return columnCount
 }
 
 // SelectSQL returns the SQL and arguments necessary for reading relationships.
 func (b RelationshipsQueryBuilder) SelectSQL() (string, []any, error) {
+	if b.IsBatch() {
+		return b.batchSelectSQL()
+	}
+
+	columnNames := b.columnNamesToSelect()
+	key, cacheable := b.shapeKey(columnNames)
+	if cacheable {
+		if sql, ok := b.shapeCache.get(key); ok {
+			if b.sqlAssertion != nil {
+				b.sqlAssertion(sql)
+			}
+			return sql, slices.Clip(b.baseQueryBuilder.args), nil
+		}
+	}
+
+	sqlBuilder := b.baseQueryBuilder.queryBuilderWithMaybeExpirationFilter(b.SkipExpiration)
+	sqlBuilder = sqlBuilder.Columns(columnNames...)
+
+	sql, args, err := sqlBuilder.ToSql()
+	if err != nil {
+		return "", nil, err
+	}
+
+	if cacheable {
+		b.shapeCache.set(key, sql)
+	}
+
+	if b.sqlAssertion != nil {
+		b.sqlAssertion(sql)
+	}
+
+	return sql, args, nil
+}
+
+// shapeKey returns the key under which the SQL of the query is cached, or false if it is not
+// cached. The arguments of a query are always those of its recorded predicates, in order: the
+// expiration predicate has none, and LIMIT is rendered inline.
+func (b RelationshipsQueryBuilder) shapeKey(columnNames []string) (string, bool) {
+	if b.shapeCache == nil {
+		return "", false
+	}
+
+	queries := b.batchQueries
+	if !b.IsBatch() {
+		queries = []SchemaQueryFilterer{b.baseQueryBuilder}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(strings.Join(columnNames, ","))
+	if b.withExpiration() {
+		sb.WriteString("\x00expiring")
+	}
+	if b.IsBatch() {
+		sb.WriteString("\x00batch:" + strconv.Itoa(int(b.batchSort)))
+	}
+
+	for _, query := range queries {
+		if query.opaque {
+			return "", false
+		}
+
+		sb.WriteString("\x01")
+		sb.WriteString(query.shapeClauses)
+		for _, predicate := range query.predicates {
+			sb.WriteString("\x00")
+			sb.WriteString(predicate)
+		}
+	}
+	return sb.String(), true
+}
+
+// batchSelectSQL returns the SQL and arguments reading the relationships of each query of the
+// batch, combined with UNION ALL and tagged with the index of the query.
+func (b RelationshipsQueryBuilder) batchSelectSQL() (string, []any, error) {
+	columnNames := b.columnNamesToSelect()
+	key, cacheable := b.shapeKey(columnNames)
+	if cacheable {
+		if sql, ok := b.shapeCache.get(key); ok {
+			if b.sqlAssertion != nil {
+				b.sqlAssertion(sql)
+			}
+
+			var args []any
+			for _, query := range b.batchQueries {
+				args = append(args, query.args...)
+			}
+			return sql, args, nil
+		}
+	}
+
+	var sb strings.Builder
+	var args []any
+	for index, query := range b.batchQueries {
+		// The placeholders of each query are rendered once the queries are combined.
+		sqlBuilder := query.queryBuilderWithMaybeExpirationFilter(b.SkipExpiration).
+			PlaceholderFormat(sq.Question).
+			Columns(columnNames...).
+			Column(fmt.Sprintf("%d AS %s", index, batchFilterIndexColumn))
+
+		sql, queryArgs, err := sqlBuilder.ToSql()
+		if err != nil {
+			return "", nil, err
+		}
+
+		if index > 0 {
+			sb.WriteString(" UNION ALL ")
+		}
+		sb.WriteString("(" + sql + ")")
+		args = append(args, queryArgs...)
+	}
+
+	if orderColumns := b.Schema.orderColumns(b.batchSort); len(orderColumns) > 0 {
+		sb.WriteString(" ORDER BY " + batchFilterIndexColumn + ", " + strings.Join(orderColumns, ", "))
+	}
+
+	sql, err := b.Schema.PlaceholderFormat.ReplacePlaceholders(sb.String())
+	if err != nil {
+		return "", nil, err
+	}
+
+	if cacheable {
+		b.shapeCache.set(key, sql)
+	}
+
+	if b.sqlAssertion != nil {
+		b.sqlAssertion(sql)
+	}
+
+	return sql, args, nil
+}
+
+// columnNamesToSelect returns the names of the columns selected when reading relationships.
+func (b RelationshipsQueryBuilder) columnNamesToSelect() []string {
 	// Set the column names to select.
 	columnNamesToSelect := make([]string, 0, b.columnCount())
 
@@ -740,23 +1154,15 @@ func (b RelationshipsQueryBuilder) SelectSQL() (string, []any, error) {
 		columnNamesToSelect = append(columnNamesToSelect, b.Schema.ColIntegrityKeyID, b.Schema.ColIntegrityHash, b.Schema.ColIntegrityTimestamp)
 	}
 
-	if len(columnNamesToSelect) == 0 {
-		columnNamesToSelect = append(columnNamesToSelect, "1")
-	}
-
-	sqlBuilder := b.baseQueryBuilder.queryBuilderWithMaybeExpirationFilter(b.SkipExpiration)
-	sqlBuilder = sqlBuilder.Columns(columnNamesToSelect...)
-
-	sql, args, err := sqlBuilder.ToSql()
-	if err != nil {
-		return "", nil, err
+	if b.withWriteRevisions() {
+		columnNamesToSelect = append(columnNamesToSelect, b.Schema.ColWriteRevision)
 	}
 
-	if b.sqlAssertion != nil {
-		b.sqlAssertion(sql)
+	if len(columnNamesToSelect) == 0 {
+		columnNamesToSelect = append(columnNamesToSelect, "1")
 	}
 
-	return sql, args, nil
+	return columnNamesToSelect
 }
 
 // FilteringValuesForTesting returns the filtering values. For test use only.
@@ -811,9 +1217,31 @@ func ColumnsToSelect[CN any, CC any, EC any](
 	integrityKeyID *string,
 	integrityHash *[]byte,
 	timestamp *time.Time,
+	writeRevision *string,
 ) ([]any, error) {
-	colsToSelect := make([]any, 0, b.columnCount())
+	return appendColumnsToSelect(make([]any, 0, b.columnCount()), b, resourceObjectType, resourceObjectID, resourceRelation, subjectObjectType, subjectObjectID, subjectRelation, caveatName, caveatCtx, expiration, integrityKeyID, integrityHash, timestamp, writeRevision)
+}
 
+// appendColumnsToSelect appends the columns to select for a given query to colsToSelect, as
+// returned by ColumnsToSelect.
+func appendColumnsToSelect[CN any, CC any, EC any](
+	colsToSelect []any,
+	b RelationshipsQueryBuilder,
+	resourceObjectType *string,
+	resourceObjectID *string,
+	resourceRelation *string,
+	subjectObjectType *string,
+	subjectObjectID *string,
+	subjectRelation *string,
+	caveatName *CN,
+	caveatCtx *CC,
+	expiration EC,
+
+	integrityKeyID *string,
+	integrityHash *[]byte,
+	timestamp *time.Time,
+	writeRevision *string,
+) ([]any, error) {
 	colsToSelect = b.staticValueOrAddColumnForSelect(colsToSelect, b.Schema.ColNamespace, resourceObjectType)
 	colsToSelect = b.staticValueOrAddColumnForSelect(colsToSelect, b.Schema.ColObjectID, resourceObjectID)
 	colsToSelect = b.staticValueOrAddColumnForSelect(colsToSelect, b.Schema.ColRelation, resourceRelation)
@@ -833,6 +1261,10 @@ func ColumnsToSelect[CN any, CC any, EC any](
 		colsToSelect = append(colsToSelect, integrityKeyID, integrityHash, timestamp)
 	}
 
+	if b.withWriteRevisions() {
+		colsToSelect = append(colsToSelect, writeRevision)
+	}
+
 	if len(colsToSelect) == 0 {
 		var unused int
 		colsToSelect = append(colsToSelect, &unused)
@@ -840,3 +1272,12 @@ func ColumnsToSelect[CN any, CC any, EC any](
 
 	return colsToSelect, nil
 }
+
+// likePrefixEscaper escapes the wildcards of LIKE patterns.
+var likePrefixEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// LikePrefixPattern returns a LIKE pattern matching the strings starting with the prefix, with
+// the wildcards of the prefix escaped so that it matches only itself.
+func LikePrefixPattern(prefix string) string {
+	return likePrefixEscaper.Replace(prefix) + "%"
+}
@@ -56,6 +56,20 @@ func TestSchemaQueryFilterer(t *testing.T) {
 				staticCols: []string{"relation"},
 			},
 		},
+		{
+			name: "excluded caveat names filter",
+			run: func(filterer SchemaQueryFilterer) SchemaQueryFilterer {
+				return filterer.MustFilterWithRelationshipsFilter(datastore.RelationshipsFilter{
+					OptionalResourceRelation:    "somerelation",
+					OptionalExcludedCaveatNames: []string{"firstcaveat", "secondcaveat"},
+				})
+			},
+			expectedForTuple: expected{
+				sql:        "SELECT * WHERE relation = ? AND NOT (COALESCE(caveat, '') IN (?,?) AND (caveat_context IS NULL OR caveat_context = '{}')) AND (expiration IS NULL OR expiration > NOW())",
+				args:       []any{"somerelation", "firstcaveat", "secondcaveat"},
+				staticCols: []string{"relation"},
+			},
+		},
 		{
 			name: "resource ID filter",
 			run: func(filterer SchemaQueryFilterer) SchemaQueryFilterer {
@@ -696,13 +710,13 @@ func TestSchemaQueryFilterer(t *testing.T) {
 				}).After(toCursor(tuple.MustParse("someresourcetype:foo#viewer@user:bar")), options.BySubject)
 			},
 			expectedForTuple: expected{
-				sql:        "SELECT * WHERE ((subject_ns = ?)) AND (subject_object_id,ns,object_id,relation,subject_relation) > (?,?,?,?,?) AND (expiration IS NULL OR expiration > NOW())",
-				args:       []any{"somesubjectype", "bar", "someresourcetype", "foo", "viewer", "..."},
+				sql:        "SELECT * WHERE ((subject_ns = ?)) AND (subject_object_id,subject_relation,ns,object_id,relation) > (?,?,?,?,?) AND (expiration IS NULL OR expiration > NOW())",
+				args:       []any{"somesubjectype", "bar", "...", "someresourcetype", "foo", "viewer"},
 				staticCols: []string{"subject_ns"},
 			},
 			expectedForExpanded: expected{
-				sql:        "SELECT * WHERE ((subject_ns = ?)) AND ((subject_object_id > ?) OR (subject_object_id = ? AND ns > ?) OR (subject_object_id = ? AND ns = ? AND object_id > ?) OR (subject_object_id = ? AND ns = ? AND object_id = ? AND relation > ?) OR (subject_object_id = ? AND ns = ? AND object_id = ? AND relation = ? AND subject_relation > ?)) AND (expiration IS NULL OR expiration > NOW())",
-				args:       []any{"somesubjectype", "bar", "bar", "someresourcetype", "bar", "someresourcetype", "foo", "bar", "someresourcetype", "foo", "viewer", "bar", "someresourcetype", "foo", "viewer", "..."},
+				sql:        "SELECT * WHERE ((subject_ns = ?)) AND ((subject_object_id > ?) OR (subject_object_id = ? AND subject_relation > ?) OR (subject_object_id = ? AND subject_relation = ? AND ns > ?) OR (subject_object_id = ? AND subject_relation = ? AND ns = ? AND object_id > ?) OR (subject_object_id = ? AND subject_relation = ? AND ns = ? AND object_id = ? AND relation > ?)) AND (expiration IS NULL OR expiration > NOW())",
+				args:       []any{"somesubjectype", "bar", "bar", "...", "bar", "...", "someresourcetype", "bar", "...", "someresourcetype", "foo", "bar", "...", "someresourcetype", "foo", "viewer"},
 				staticCols: []string{"subject_ns"},
 			},
 		},
@@ -715,13 +729,13 @@ func TestSchemaQueryFilterer(t *testing.T) {
 				}).After(toCursor(tuple.MustParse("someresourcetype:someresource#viewer@user:bar")), options.BySubject)
 			},
 			expectedForTuple: expected{
-				sql:        "SELECT * WHERE ((subject_ns = ? AND subject_object_id IN (?))) AND (ns,object_id,relation,subject_relation) > (?,?,?,?) AND (expiration IS NULL OR expiration > NOW())",
-				args:       []any{"somesubjectype", "foo", "someresourcetype", "someresource", "viewer", "..."},
+				sql:        "SELECT * WHERE ((subject_ns = ? AND subject_object_id IN (?))) AND (subject_relation,ns,object_id,relation) > (?,?,?,?) AND (expiration IS NULL OR expiration > NOW())",
+				args:       []any{"somesubjectype", "foo", "...", "someresourcetype", "someresource", "viewer"},
 				staticCols: []string{"subject_ns", "subject_object_id"},
 			},
 			expectedForExpanded: expected{
-				sql:        "SELECT * WHERE ((subject_ns = ? AND subject_object_id IN (?))) AND ((ns > ?) OR (ns = ? AND object_id > ?) OR (ns = ? AND object_id = ? AND relation > ?) OR (ns = ? AND object_id = ? AND relation = ? AND subject_relation > ?)) AND (expiration IS NULL OR expiration > NOW())",
-				args:       []any{"somesubjectype", "foo", "someresourcetype", "someresourcetype", "someresource", "someresourcetype", "someresource", "viewer", "someresourcetype", "someresource", "viewer", "..."},
+				sql:        "SELECT * WHERE ((subject_ns = ? AND subject_object_id IN (?))) AND ((subject_relation > ?) OR (subject_relation = ? AND ns > ?) OR (subject_relation = ? AND ns = ? AND object_id > ?) OR (subject_relation = ? AND ns = ? AND object_id = ? AND relation > ?)) AND (expiration IS NULL OR expiration > NOW())",
+				args:       []any{"somesubjectype", "foo", "...", "...", "someresourcetype", "...", "someresourcetype", "someresource", "...", "someresourcetype", "someresource", "viewer"},
 				staticCols: []string{"subject_ns", "subject_object_id"},
 			},
 		},
@@ -734,13 +748,13 @@ func TestSchemaQueryFilterer(t *testing.T) {
 				}).After(toCursor(tuple.MustParse("someresourcetype:someresource#viewer@user:next")), options.BySubject)
 			},
 			expectedForTuple: expected{
-				sql:        "SELECT * WHERE ((subject_ns = ? AND subject_object_id IN (?,?))) AND (subject_object_id,ns,object_id,relation,subject_relation) > (?,?,?,?,?) AND (expiration IS NULL OR expiration > NOW())",
-				args:       []any{"somesubjectype", "foo", "bar", "next", "someresourcetype", "someresource", "viewer", "..."},
+				sql:        "SELECT * WHERE ((subject_ns = ? AND subject_object_id IN (?,?))) AND (subject_object_id,subject_relation,ns,object_id,relation) > (?,?,?,?,?) AND (expiration IS NULL OR expiration > NOW())",
+				args:       []any{"somesubjectype", "foo", "bar", "next", "...", "someresourcetype", "someresource", "viewer"},
 				staticCols: []string{"subject_ns"},
 			},
 			expectedForExpanded: expected{
-				sql:        "SELECT * WHERE ((subject_ns = ? AND subject_object_id IN (?,?))) AND ((subject_object_id > ?) OR (subject_object_id = ? AND ns > ?) OR (subject_object_id = ? AND ns = ? AND object_id > ?) OR (subject_object_id = ? AND ns = ? AND object_id = ? AND relation > ?) OR (subject_object_id = ? AND ns = ? AND object_id = ? AND relation = ? AND subject_relation > ?)) AND (expiration IS NULL OR expiration > NOW())",
-				args:       []any{"somesubjectype", "foo", "bar", "next", "next", "someresourcetype", "next", "someresourcetype", "someresource", "next", "someresourcetype", "someresource", "viewer", "next", "someresourcetype", "someresource", "viewer", "..."},
+				sql:        "SELECT * WHERE ((subject_ns = ? AND subject_object_id IN (?,?))) AND ((subject_object_id > ?) OR (subject_object_id = ? AND subject_relation > ?) OR (subject_object_id = ? AND subject_relation = ? AND ns > ?) OR (subject_object_id = ? AND subject_relation = ? AND ns = ? AND object_id > ?) OR (subject_object_id = ? AND subject_relation = ? AND ns = ? AND object_id = ? AND relation > ?)) AND (expiration IS NULL OR expiration > NOW())",
+				args:       []any{"somesubjectype", "foo", "bar", "next", "next", "...", "next", "...", "someresourcetype", "next", "...", "someresourcetype", "someresource", "next", "...", "someresourcetype", "someresource", "viewer"},
 				staticCols: []string{"subject_ns"},
 			},
 		},
@@ -754,13 +768,13 @@ func TestSchemaQueryFilterer(t *testing.T) {
 			},
 			withExpirationDisabled: true,
 			expectedForTuple: expected{
-				sql:        "SELECT * WHERE ((subject_ns = ? AND subject_object_id IN (?,?))) AND (subject_object_id,ns,object_id,relation,subject_relation) > (?,?,?,?,?)",
-				args:       []any{"somesubjectype", "foo", "bar", "next", "someresourcetype", "someresource", "viewer", "..."},
+				sql:        "SELECT * WHERE ((subject_ns = ? AND subject_object_id IN (?,?))) AND (subject_object_id,subject_relation,ns,object_id,relation) > (?,?,?,?,?)",
+				args:       []any{"somesubjectype", "foo", "bar", "next", "...", "someresourcetype", "someresource", "viewer"},
 				staticCols: []string{"subject_ns"},
 			},
 			expectedForExpanded: expected{
-				sql:        "SELECT * WHERE ((subject_ns = ? AND subject_object_id IN (?,?))) AND ((subject_object_id > ?) OR (subject_object_id = ? AND ns > ?) OR (subject_object_id = ? AND ns = ? AND object_id > ?) OR (subject_object_id = ? AND ns = ? AND object_id = ? AND relation > ?) OR (subject_object_id = ? AND ns = ? AND object_id = ? AND relation = ? AND subject_relation > ?))",
-				args:       []any{"somesubjectype", "foo", "bar", "next", "next", "someresourcetype", "next", "someresourcetype", "someresource", "next", "someresourcetype", "someresource", "viewer", "next", "someresourcetype", "someresource", "viewer", "..."},
+				sql:        "SELECT * WHERE ((subject_ns = ? AND subject_object_id IN (?,?))) AND ((subject_object_id > ?) OR (subject_object_id = ? AND subject_relation > ?) OR (subject_object_id = ? AND subject_relation = ? AND ns > ?) OR (subject_object_id = ? AND subject_relation = ? AND ns = ? AND object_id > ?) OR (subject_object_id = ? AND subject_relation = ? AND ns = ? AND object_id = ? AND relation > ?))",
+				args:       []any{"somesubjectype", "foo", "bar", "next", "next", "...", "next", "...", "someresourcetype", "next", "...", "someresourcetype", "someresource", "next", "...", "someresourcetype", "someresource", "viewer"},
 				staticCols: []string{"subject_ns"},
 			},
 		},
@@ -786,6 +800,7 @@ func TestSchemaQueryFilterer(t *testing.T) {
 						WithPaginationFilterType(filterType),
 						WithColumnOptimization(ColumnOptimizationOptionStaticValues),
 						WithNowFunction("NOW"),
+						WithEmptyCaveatContextCondition("(%[1]s IS NULL OR %[1]s = '{}')"),
 					)
 					filterer := NewSchemaQueryFiltererForRelationshipsSelect(*schema, 100)
 
@@ -1107,6 +1122,17 @@ func TestExecuteQuery(t *testing.T) {
 			fromSuffix:             "as of tomorrow",
 			expectedStaticColCount: 1,
 		},
+		{
+			name: "with index hint and from suffix",
+			run: func(filterer SchemaQueryFilterer) SchemaQueryFilterer {
+				return filterer.FilterToResourceType("sometype").WithIndexHint("@ix_by_resource")
+			},
+			expectedSQL:            "SELECT object_id, relation, subject_ns, subject_object_id, subject_relation, caveat, caveat_context FROM relationtuples@ix_by_resource as of tomorrow WHERE ns = ?",
+			expectedArgs:           []any{"sometype"},
+			withExpirationDisabled: true,
+			fromSuffix:             "as of tomorrow",
+			expectedStaticColCount: 1,
+		},
 		{
 			name: "with limit",
 			run: func(filterer SchemaQueryFilterer) SchemaQueryFilterer {
@@ -1352,17 +1378,25 @@ func TestExecuteQuery(t *testing.T) {
 							var integrityKeyID string
 							var integrityHash []byte
 							var timestamp time.Time
+							var writeRevision string
 
-							colsToSelect, err := ColumnsToSelect(builder, &resourceObjectType, &resourceObjectID, &resourceRelation, &subjectObjectType, &subjectObjectID, &subjectRelation, &caveatName, &caveatCtx, &expiration, &integrityKeyID, &integrityHash, &timestamp)
+							colsToSelect, err := ColumnsToSelect(builder, &resourceObjectType, &resourceObjectID, &resourceRelation, &subjectObjectType, &subjectObjectID, &subjectRelation, &caveatName, &caveatCtx, &expiration, &integrityKeyID, &integrityHash, &timestamp, &writeRevision)
 							require.NoError(t, err)
 							require.Equal(t, expectedColCount, len(colsToSelect))
 
 							return nil, nil
 						},
+						ShapeCache: NewQueryShapeCache("test", 16),
 					}
-					_, err := fake.ExecuteQuery(context.Background(), ran, tc.options...)
-					require.NoError(t, err)
-					require.True(t, wasRun)
+
+					// The second execution reads the SQL from the cache of query shapes.
+					for range 2 {
+						wasRun = false
+						_, err := fake.ExecuteQuery(context.Background(), ran, tc.options...)
+						require.NoError(t, err)
+						require.True(t, wasRun)
+					}
+					require.Equal(t, 1, fake.ShapeCache.len())
 				})
 			}
 		})
@@ -1404,3 +1438,78 @@ func TestNewSchemaQueryFiltererWithStartingQuery(t *testing.T) {
 	require.Equal(t, expectedSQL, sqlQuery)
 	require.Equal(t, expectedArgs, args)
 }
+
+func TestExecuteBatchQuery(t *testing.T) {
+	schema := NewSchemaInformationWithOptions(
+		WithRelationshipTableName("relationtuples"),
+		WithColNamespace("ns"),
+		WithColObjectID("object_id"),
+		WithColRelation("relation"),
+		WithColUsersetNamespace("subject_ns"),
+		WithColUsersetObjectID("subject_object_id"),
+		WithColUsersetRelation("subject_relation"),
+		WithColCaveatName("caveat"),
+		WithColCaveatContext("caveat_context"),
+		WithColExpiration("expiration"),
+		WithPlaceholderFormat(sq.Dollar),
+		WithPaginationFilterType(TupleComparison),
+		WithColumnOptimization(ColumnOptimizationOptionStaticValues),
+		WithNowFunction("NOW"),
+	)
+
+	first := NewSchemaQueryFiltererForRelationshipsSelect(*schema, 100).FilterToResourceType("document")
+	second := NewSchemaQueryFiltererForRelationshipsSelect(*schema, 100).FilterToResourceType("folder").FilterToRelation("viewer")
+	limit := uint64(2)
+
+	t.Run("combined", func(t *testing.T) {
+		var wasRun bool
+		fake := QueryRelationshipsExecutor{
+			Executor: func(ctx context.Context, builder RelationshipsQueryBuilder) (datastore.RelationshipIterator, error) {
+				require.Fail(t, "expected the batch executor to be used")
+				return nil, nil
+			},
+			BatchExecutor: func(ctx context.Context, builder RelationshipsQueryBuilder) (datastore.TaggedRelationshipIterator, error) {
+				wasRun = true
+				require.True(t, builder.IsBatch())
+
+				sql, args, err := builder.SelectSQL()
+				require.NoError(t, err)
+				require.Equal(t, "(SELECT ns, object_id, relation, subject_ns, subject_object_id, subject_relation, caveat, caveat_context, expiration, 0 AS batch_filter_index FROM relationtuples WHERE ns = $1 AND (expiration IS NULL OR expiration > NOW()) ORDER BY ns, object_id, relation, subject_ns, subject_object_id, subject_relation LIMIT 2) UNION ALL (SELECT ns, object_id, relation, subject_ns, subject_object_id, subject_relation, caveat, caveat_context, expiration, 1 AS batch_filter_index FROM relationtuples WHERE ns = $2 AND relation = $3 AND (expiration IS NULL OR expiration > NOW()) ORDER BY ns, object_id, relation, subject_ns, subject_object_id, subject_relation LIMIT 2) ORDER BY batch_filter_index, ns, object_id, relation, subject_ns, subject_object_id, subject_relation", sql)
+				require.Equal(t, []any{"document", "folder", "viewer"}, args)
+
+				var filterIndex int64
+				var rt, rid, rel, st, sid, srel string
+				var caveatName *string
+				var caveatCtx map[string]any
+				var expiration *time.Time
+				var integrityKeyID string
+				var integrityHash []byte
+				var timestamp time.Time
+				var writeRevision string
+
+				colsToSelect, err := ColumnsToSelect(builder, &rt, &rid, &rel, &st, &sid, &srel, &caveatName, &caveatCtx, &expiration, &integrityKeyID, &integrityHash, &timestamp, &writeRevision)
+				require.NoError(t, err)
+				require.Len(t, colsToSelect, 9)
+				require.Len(t, builder.WithFilterIndexColumn(colsToSelect, &filterIndex), 10)
+
+				return func(yield func(datastore.TaggedRelationship, error) bool) {}, nil
+			},
+		}
+
+		_, err := fake.ExecuteBatchQuery(context.Background(), []SchemaQueryFilterer{first, second}, options.WithSort(options.ByResource), options.WithLimit(&limit))
+		require.NoError(t, err)
+		require.True(t, wasRun)
+	})
+
+	t.Run("cursors are rejected", func(t *testing.T) {
+		fake := QueryRelationshipsExecutor{}
+		cursor := options.ToCursor(tuple.MustParse("document:foo#viewer@user:tom"))
+		_, err := fake.ExecuteBatchQuery(context.Background(), []SchemaQueryFilterer{first, second}, options.WithSort(options.ByResource), options.WithAfter(cursor))
+		require.ErrorIs(t, err, datastore.ErrCursorsInBatch)
+	})
+}
+
+func TestLikePrefixPattern(t *testing.T) {
+	require.Equal(t, "journal/%", LikePrefixPattern("journal/"))
+	require.Equal(t, `a\%b\_c\\d%`, LikePrefixPattern(`a%b_c\d`))
+}
@@ -44,9 +44,11 @@ func (s *SchemaInformation) ToOption() SchemaInformationOption {
 		to.ColIntegrityKeyID = s.ColIntegrityKeyID
 		to.ColIntegrityHash = s.ColIntegrityHash
 		to.ColIntegrityTimestamp = s.ColIntegrityTimestamp
+		to.ColWriteRevision = s.ColWriteRevision
 		to.PaginationFilterType = s.PaginationFilterType
 		to.PlaceholderFormat = s.PlaceholderFormat
 		to.NowFunction = s.NowFunction
+		to.EmptyCaveatContextCondition = s.EmptyCaveatContextCondition
 		to.ColumnOptimization = s.ColumnOptimization
 		to.IntegrityEnabled = s.IntegrityEnabled
 		to.ExpirationDisabled = s.ExpirationDisabled
@@ -69,9 +71,11 @@ func (s SchemaInformation) DebugMap() map[string]any {
 	debugMap["ColIntegrityKeyID"] = helpers.DebugValue(s.ColIntegrityKeyID, false)
 	debugMap["ColIntegrityHash"] = helpers.DebugValue(s.ColIntegrityHash, false)
 	debugMap["ColIntegrityTimestamp"] = helpers.DebugValue(s.ColIntegrityTimestamp, false)
+	debugMap["ColWriteRevision"] = helpers.DebugValue(s.ColWriteRevision, false)
 	debugMap["PaginationFilterType"] = helpers.DebugValue(s.PaginationFilterType, false)
 	debugMap["PlaceholderFormat"] = helpers.DebugValue(s.PlaceholderFormat, false)
 	debugMap["NowFunction"] = helpers.DebugValue(s.NowFunction, false)
+	debugMap["EmptyCaveatContextCondition"] = helpers.DebugValue(s.EmptyCaveatContextCondition, false)
 	debugMap["ColumnOptimization"] = helpers.DebugValue(s.ColumnOptimization, false)
 	debugMap["IntegrityEnabled"] = helpers.DebugValue(s.IntegrityEnabled, false)
 	debugMap["ExpirationDisabled"] = helpers.DebugValue(s.ExpirationDisabled, false)
@@ -185,6 +189,13 @@ func WithColIntegrityTimestamp(colIntegrityTimestamp string) SchemaInformationOp
 	}
 }
 
+// WithColWriteRevision returns an option that can set ColWriteRevision on a SchemaInformation
+func WithColWriteRevision(colWriteRevision string) SchemaInformationOption {
+	return func(s *SchemaInformation) {
+		s.ColWriteRevision = colWriteRevision
+	}
+}
+
 // WithPaginationFilterType returns an option that can set PaginationFilterType on a SchemaInformation
 func WithPaginationFilterType(paginationFilterType PaginationFilterType) SchemaInformationOption {
 	return func(s *SchemaInformation) {
@@ -206,6 +217,13 @@ func WithNowFunction(nowFunction string) SchemaInformationOption {
 	}
 }
 
+// WithEmptyCaveatContextCondition returns an option that can set EmptyCaveatContextCondition on a SchemaInformation
+func WithEmptyCaveatContextCondition(emptyCaveatContextCondition string) SchemaInformationOption {
+	return func(s *SchemaInformation) {
+		s.EmptyCaveatContextCondition = emptyCaveatContextCondition
+	}
+}
+
 // WithColumnOptimization returns an option that can set ColumnOptimization on a SchemaInformation
 func WithColumnOptimization(columnOptimization ColumnOptimizationOption) SchemaInformationOption {
 	return func(s *SchemaInformation) {
@@ -151,6 +151,10 @@ func (r *ctxReader) QueryRelationships(ctx context.Context, filter datastore.Rel
 	return r.delegate.QueryRelationships(SeparateContextWithTracing(ctx), filter, options...)
 }
 
+func (r *ctxReader) QueryRelationshipsBatch(ctx context.Context, filters []datastore.RelationshipsFilter, options ...options.QueryOptionsOption) (datastore.TaggedRelationshipIterator, error) {
+	return r.delegate.QueryRelationshipsBatch(SeparateContextWithTracing(ctx), filters, options...)
+}
+
 func (r *ctxReader) ReverseQueryRelationships(ctx context.Context, subjectsFilter datastore.SubjectsFilter, options ...options.ReverseQueryOptionsOption) (datastore.RelationshipIterator, error) {
 	return r.delegate.ReverseQueryRelationships(SeparateContextWithTracing(ctx), subjectsFilter, options...)
 }
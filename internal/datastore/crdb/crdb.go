@@ -29,6 +29,7 @@ import (
 	log "github.com/authzed/spicedb/internal/logging"
 	"github.com/authzed/spicedb/pkg/datastore"
 	"github.com/authzed/spicedb/pkg/datastore/options"
+	"github.com/authzed/spicedb/pkg/middleware/priority"
 	"github.com/authzed/spicedb/pkg/spiceerrors"
 )
 
@@ -55,6 +56,7 @@ const (
 	tableCaveat              = "caveat"
 	tableRelationshipCounter = "relationship_counter"
 	tableTransactionMetadata = "transaction_metadata"
+	tableInternalRecord      = "internal_record"
 
 	colNamespace      = "namespace"
 	colConfig         = "serialized_config"
@@ -82,6 +84,8 @@ const (
 	colCounterCurrentCount     = "current_count"
 	colCounterUpdatedAt        = "updated_at_timestamp"
 	colExpiresAt               = "expires_at"
+	colRecordKey               = "record_key"
+	colRecordValue             = "record_value"
 	colMetadata                = "metadata"
 
 	errUnableToInstantiate = "unable to instantiate datastore"
@@ -91,6 +95,7 @@ const (
 	queryTransactionNowPreV23 = querySelectNow
 	queryTransactionNow       = "SHOW COMMIT TIMESTAMP"
 	queryShowZoneConfig       = "SHOW ZONE CONFIGURATION FOR RANGE default;"
+	queryBeginLowPriority     = "BEGIN TRANSACTION PRIORITY LOW"
 
 	spicedbTransactionKey = "$spicedb_transaction_key"
 )
@@ -112,6 +117,9 @@ func newCRDBDatastore(ctx context.Context, url string, options ...Option) (datas
 	if err != nil {
 		return nil, common.RedactAndLogSensitiveConnString(ctx, errUnableToInstantiate, err, url)
 	}
+	if config.preparedStatementCacheCapacity > 0 {
+		pgxcommon.ConfigureStatementCache(readPoolConfig, "read", config.preparedStatementCacheCapacity)
+	}
 
 	writePoolConfig, err := pgxpool.ParseConfig(url)
 	if err != nil {
@@ -121,6 +129,9 @@ func newCRDBDatastore(ctx context.Context, url string, options ...Option) (datas
 	if err != nil {
 		return nil, common.RedactAndLogSensitiveConnString(ctx, errUnableToInstantiate, err, url)
 	}
+	if config.preparedStatementCacheCapacity > 0 {
+		pgxcommon.ConfigureStatementCache(writePoolConfig, "write", config.preparedStatementCacheCapacity)
+	}
 
 	initCtx, initCancel := context.WithTimeout(context.Background(), 5*time.Minute)
 	defer initCancel()
@@ -150,6 +161,9 @@ func newCRDBDatastore(ctx context.Context, url string, options ...Option) (datas
 	if version.Major < 22 {
 		log.Info().Object("version", version).Msg("using changefeed query for CRDB version < 22")
 		changefeedQuery = queryChangefeedPreV22
+	} else if version.Major < 23 {
+		log.Info().Object("version", version).Msg("using experimental changefeed query for CRDB version < 23")
+		changefeedQuery = queryChangefeedPreV23
 	}
 
 	transactionNowQuery := queryTransactionNow
@@ -219,9 +233,11 @@ func newCRDBDatastore(ctx context.Context, url string, options ...Option) (datas
 		common.WithColIntegrityKeyID(colIntegrityKeyID),
 		common.WithColIntegrityHash(colIntegrityHash),
 		common.WithColIntegrityTimestamp(colTimestamp),
+		common.WithColWriteRevision("crdb_internal_mvcc_timestamp::STRING"),
 		common.WithPaginationFilterType(common.ExpandedLogicComparison),
 		common.WithPlaceholderFormat(sq.Dollar),
 		common.WithNowFunction("NOW"),
+		common.WithEmptyCaveatContextCondition("(%[1]s IS NULL OR %[1]s = '{}'::JSONB)"),
 		common.WithColumnOptimization(config.columnOptimizationOption),
 		common.WithIntegrityEnabled(config.withIntegrity),
 		common.WithExpirationDisabled(config.expirationDisabled),
@@ -246,11 +262,25 @@ func newCRDBDatastore(ctx context.Context, url string, options ...Option) (datas
 		transactionNowQuery:     transactionNowQuery,
 		analyzeBeforeStatistics: config.analyzeBeforeStatistics,
 		filterMaximumIDCount:    config.filterMaximumIDCount,
+		slowQueryLogger:         common.NewSlowQueryLogger(config.slowQueryThreshold),
+		shapeCache:              common.NewQueryShapeCache(Engine, config.queryShapeCacheCapacity),
 		supportsIntegrity:       config.withIntegrity,
+		statementCacheEnabled:   config.preparedStatementCacheCapacity > 0,
 		gcWindow:                config.gcWindow,
+		maxStalenessPercent:     config.maxRevisionStalenessPercent,
 		schema:                  *schema,
 	}
 	ds.RemoteClockRevisions.SetNowFunc(ds.headRevisionInternal)
+	ds.RemoteClockRevisions.SetWindowJitter(config.revisionQuantizationJitter)
+
+	if config.indexPlannerRefreshInterval > 0 {
+		ds.indexPlanner = common.NewIndexPlanner(ds.loadIndexStatistics, config.indexPlannerRefreshInterval, reverseQueryIndexes...)
+	}
+
+	writeConnErrors := common.NewConnErrorTracker[*pgx.Conn](config.connMaxErrors)
+	pgxcommon.ConfigureConnErrorPruning(writePoolConfig, writeConnErrors)
+	readConnErrors := common.NewConnErrorTracker[*pgx.Conn](config.connMaxErrors)
+	pgxcommon.ConfigureConnErrorPruning(readPoolConfig, readConnErrors)
 
 	// this ctx and cancel is tied to the lifetime of the datastore
 	ds.ctx, ds.cancel = context.WithCancel(context.Background())
@@ -274,6 +304,11 @@ func newCRDBDatastore(ctx context.Context, url string, options ...Option) (datas
 			return nil, err
 		}
 
+		if err := prometheus.Register(common.NewPoolStatsCollector("spicedb", "write", pgxcommon.PoolStatsFunc(ds.writePool, writeConnErrors))); err != nil {
+			ds.cancel()
+			return nil, err
+		}
+
 		if err := prometheus.Register(pgxpoolprometheus.NewCollector(ds.readPool, map[string]string{
 			"db_name":    "spicedb",
 			"pool_usage": "read",
@@ -281,6 +316,11 @@ func newCRDBDatastore(ctx context.Context, url string, options ...Option) (datas
 			ds.cancel()
 			return nil, err
 		}
+
+		if err := prometheus.Register(common.NewPoolStatsCollector("spicedb", "read", pgxcommon.PoolStatsFunc(ds.readPool, readConnErrors))); err != nil {
+			ds.cancel()
+			return nil, err
+		}
 	}
 
 	// TODO: this (and the GC startup that it's based on for mysql/pg) should
@@ -333,6 +373,7 @@ type crdbDatastore struct {
 	overlapKeyInit          func(ctx context.Context) keySet
 	analyzeBeforeStatistics bool
 	gcWindow                time.Duration
+	maxStalenessPercent     float64
 	schema                  common.SchemaInformation
 
 	beginChangefeedQuery string
@@ -344,12 +385,22 @@ type crdbDatastore struct {
 	ctx                  context.Context
 	cancel               context.CancelFunc
 	filterMaximumIDCount uint16
+	slowQueryLogger      *common.SlowQueryLogger
+	shapeCache           *common.QueryShapeCache
 	supportsIntegrity    bool
+	indexPlanner         *common.IndexPlanner
+
+	statementCacheEnabled bool
 }
 
 func (cds *crdbDatastore) SnapshotReader(rev datastore.Revision) datastore.Reader {
+	querier := cds.relationshipsQuerier(cds.readPool)
 	executor := common.QueryRelationshipsExecutor{
-		Executor: pgxcommon.NewPGXQueryRelationshipsExecutor(cds.readPool),
+		Executor:        pgxcommon.NewPGXQueryRelationshipsExecutor(querier),
+		BatchExecutor:   pgxcommon.NewPGXQueryRelationshipsBatchExecutor(querier),
+		SlowQueryLogger: cds.slowQueryLogger,
+		ShapeCache:      cds.shapeCache,
+		Revision:        rev,
 	}
 	return &crdbReader{
 		schema:               cds.schema,
@@ -360,9 +411,19 @@ func (cds *crdbDatastore) SnapshotReader(rev datastore.Revision) datastore.Reade
 		filterMaximumIDCount: cds.filterMaximumIDCount,
 		withIntegrity:        cds.supportsIntegrity,
 		atSpecificRevision:   rev.String(),
+		indexPlanner:         cds.indexPlanner,
 	}
 }
 
+// relationshipsQuerier returns the querier with which relationships are queried, which caches
+// prepared statements if enabled.
+func (cds *crdbDatastore) relationshipsQuerier(querier pgxcommon.DBFuncQuerier) pgxcommon.DBFuncQuerier {
+	if cds.statementCacheEnabled {
+		return pgxcommon.WithStatementCache(querier)
+	}
+	return querier
+}
+
 func (cds *crdbDatastore) ReadWriteTx(
 	ctx context.Context,
 	f datastore.TxUserFunc,
@@ -375,10 +436,14 @@ func (cds *crdbDatastore) ReadWriteTx(
 		ctx = context.WithValue(ctx, pool.CtxDisableRetries, true)
 	}
 
-	err := cds.writePool.BeginFunc(ctx, func(tx pgx.Tx) error {
+	err := cds.writePool.BeginTxFunc(ctx, transactionOptions(ctx), func(tx pgx.Tx) error {
 		querier := pgxcommon.QuerierFuncsFor(tx)
+		relationshipsQuerier := cds.relationshipsQuerier(querier)
 		executor := common.QueryRelationshipsExecutor{
-			Executor: pgxcommon.NewPGXQueryRelationshipsExecutor(querier),
+			Executor:        pgxcommon.NewPGXQueryRelationshipsExecutor(relationshipsQuerier),
+			BatchExecutor:   pgxcommon.NewPGXQueryRelationshipsBatchExecutor(relationshipsQuerier),
+			SlowQueryLogger: cds.slowQueryLogger,
+			ShapeCache:      cds.shapeCache,
 		}
 
 		// Write metadata onto the transaction.
@@ -411,6 +476,7 @@ func (cds *crdbDatastore) ReadWriteTx(
 			filterMaximumIDCount: cds.filterMaximumIDCount,
 			withIntegrity:        cds.supportsIntegrity,
 			atSpecificRevision:   "", // No AS OF SYSTEM TIME for writes
+			indexPlanner:         cds.indexPlanner,
 		}
 
 		rwt := &crdbReadWriteTXN{
@@ -445,12 +511,27 @@ func (cds *crdbDatastore) ReadWriteTx(
 	return commitTimestamp, nil
 }
 
+// transactionOptions returns the options of a write transaction for the request of the context.
+// Batch and background requests run their writes at a low priority, so that the transactions of
+// interactive requests win when they contend. Reads are not prioritized, as they read at a past
+// revision and do not contend with writes.
+func transactionOptions(ctx context.Context) pgx.TxOptions {
+	if priority.FromContext(ctx) == priority.Interactive {
+		return pgx.TxOptions{}
+	}
+	return pgx.TxOptions{BeginQuery: queryBeginLowPriority}
+}
+
 func wrapError(err error) error {
 	// If a unique constraint violation is returned, then its likely that the cause
 	// was an existing relationship.
 	if cerr := pgxcommon.ConvertToWriteConstraintError(livingTupleConstraints, err); cerr != nil {
 		return cerr
 	}
+
+	if pgxcommon.IsReadOnlyTransactionError(err) {
+		return common.NewReadOnlyTransactionError(err)
+	}
 	return err
 }
 
@@ -512,6 +593,20 @@ func (cds *crdbDatastore) HeadRevision(ctx context.Context) (datastore.Revision,
 	return cds.headRevisionInternal(ctx)
 }
 
+func (cds *crdbDatastore) RevisionAtTime(_ context.Context, at time.Time) (datastore.Revision, error) {
+	return revisions.NewHLCForTime(at), nil
+}
+
+func (cds *crdbDatastore) SetRevisionQuantization(quantization time.Duration) error {
+	if quantization >= cds.gcWindow {
+		return fmt.Errorf(errQuantizationTooLarge, quantization, cds.gcWindow)
+	}
+
+	maxRevisionStaleness := time.Duration(float64(quantization.Nanoseconds())*cds.maxStalenessPercent) * time.Nanosecond
+	cds.SetQuantization(quantization, maxRevisionStaleness)
+	return nil
+}
+
 func (cds *crdbDatastore) headRevisionInternal(ctx context.Context) (datastore.Revision, error) {
 	var hlcNow datastore.Revision
 
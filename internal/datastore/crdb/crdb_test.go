@@ -180,7 +180,7 @@ func TestCRDBDatastoreWithIntegrity(t *testing.T) {
 			)
 			require.NoError(t, err)
 
-			wrapped, err := proxy.NewRelationshipIntegrityProxy(ds, defaultKeyForTesting, nil)
+			wrapped, err := proxy.NewRelationshipIntegrityProxy(ds, defaultKeyForTesting, nil, proxy.RejectIntegrityViolations)
 			require.NoError(t, err)
 			return wrapped
 		})
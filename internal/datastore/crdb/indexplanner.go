@@ -0,0 +1,98 @@
+package crdb
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/authzed/spicedb/internal/datastore/common"
+)
+
+const (
+	indexRelationTupleBySubjectCovering = "ix_relation_tuple_by_subject_covering"
+	indexRelationTuplePrimaryKey        = "pk_relation_tuple"
+)
+
+// reverseQueryIndexes are the indexes through which reverse queries can read relationships, the
+// subject index being preferred. Both relationship tables have the same indexes.
+var reverseQueryIndexes = []common.RelationshipIndex{
+	{
+		Name:    indexRelationTupleBySubjectCovering,
+		Columns: []string{colUsersetNamespace, colUsersetObjectID, colUsersetRelation, colNamespace, colRelation},
+	},
+	{
+		Name:    indexRelationTuplePrimaryKey,
+		Columns: []string{colNamespace, colObjectID, colRelation, colUsersetNamespace, colUsersetObjectID, colUsersetRelation},
+	},
+}
+
+// loadIndexStatistics loads the latest table statistics collected for the prefixes of the indexes
+// of the relationships table which exist.
+func (cds *crdbDatastore) loadIndexStatistics(ctx context.Context, indexes []common.RelationshipIndex) (common.IndexStatistics, error) {
+	var existing []string
+	if err := cds.readPool.QueryFunc(ctx, func(ctx context.Context, rows pgx.Rows) error {
+		for rows.Next() {
+			var indexName string
+			if err := rows.Scan(&indexName); err != nil {
+				return err
+			}
+			existing = append(existing, indexName)
+		}
+		return rows.Err()
+	}, "SELECT DISTINCT index_name FROM [SHOW INDEXES FROM "+cds.schema.RelationshipTableName+"]"); err != nil {
+		return common.IndexStatistics{}, fmt.Errorf("unable to load relationship indexes: %w", err)
+	}
+
+	stats := common.IndexStatistics{DistinctCounts: make(map[string][]uint64, len(indexes))}
+	for _, index := range indexes {
+		if slices.Contains(existing, index.Name) {
+			stats.DistinctCounts[index.Name] = make([]uint64, len(index.Columns))
+		}
+	}
+
+	if err := cds.readPool.QueryFunc(ctx, func(ctx context.Context, rows pgx.Rows) error {
+		// The statistics are ordered from the latest, which are kept for each set of columns.
+		seen := make(map[string]struct{})
+		for rows.Next() {
+			var columnNames []string
+			var rowCount, distinctCount int64
+			if err := rows.Scan(&columnNames, &rowCount, &distinctCount); err != nil {
+				return err
+			}
+			if len(columnNames) == 0 || rowCount < 0 || distinctCount < 0 {
+				continue
+			}
+
+			slices.Sort(columnNames)
+			key := fmt.Sprint(columnNames)
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+
+			if uint64(rowCount) > stats.RowCount {
+				stats.RowCount = uint64(rowCount)
+			}
+
+			for _, index := range indexes {
+				counts, ok := stats.DistinctCounts[index.Name]
+				if !ok || len(columnNames) > len(index.Columns) {
+					continue
+				}
+
+				prefix := slices.Clone(index.Columns[:len(columnNames)])
+				slices.Sort(prefix)
+				if slices.Equal(prefix, columnNames) {
+					counts[len(columnNames)-1] = uint64(distinctCount)
+				}
+			}
+		}
+		return rows.Err()
+	}, "SELECT column_names, row_count, distinct_count FROM [SHOW STATISTICS FOR TABLE "+cds.schema.RelationshipTableName+"] ORDER BY created DESC"); err != nil {
+		return common.IndexStatistics{}, fmt.Errorf("unable to load relationship statistics: %w", err)
+	}
+
+	return stats, nil
+}
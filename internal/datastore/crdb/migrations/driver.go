@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
@@ -25,6 +26,10 @@ const (
 // datastore.
 type CRDBDriver struct {
 	db *pgx.Conn
+
+	// printer is set in dry runs, in which the statements modifying the database
+	// are printed rather than executed.
+	printer pgxcommon.QueryInterceptor
 }
 
 // NewCRDBDriver creates a new driver with active connections to the database
@@ -42,7 +47,7 @@ func NewCRDBDriver(url string) (*CRDBDriver, error) {
 		return nil, fmt.Errorf(errUnableToInstantiate, err)
 	}
 
-	return &CRDBDriver{db}, nil
+	return &CRDBDriver{db: db}, nil
 }
 
 // Version returns the version of the schema to which the connected database
@@ -61,12 +66,26 @@ func (apd *CRDBDriver) Version(ctx context.Context) (string, error) {
 	return loaded, nil
 }
 
-// Conn returns the underlying pgx.Conn instance for this driver
-func (apd *CRDBDriver) Conn() *pgx.Conn {
+// Conn returns the underlying pgx.Conn instance for this driver, which prints
+// the statements it executes in dry runs.
+func (apd *CRDBDriver) Conn() pgxcommon.Querier {
+	if apd.printer != nil {
+		return pgxcommon.NewInterceptorQuerier(apd.db, apd.printer)
+	}
 	return apd.db
 }
 
 func (apd *CRDBDriver) RunTx(ctx context.Context, f migrate.TxMigrationFunc[pgx.Tx]) error {
+	if apd.printer != nil {
+		tx, err := apd.db.Begin(ctx)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = tx.Rollback(ctx) }()
+
+		return f(ctx, pgxcommon.NewInterceptorTx(tx, apd.printer))
+	}
+
 	return pgx.BeginFunc(ctx, apd.db, func(tx pgx.Tx) error {
 		return f(ctx, tx)
 	})
@@ -83,12 +102,21 @@ func (apd *CRDBDriver) WriteVersion(ctx context.Context, tx pgx.Tx, version, rep
 		return fmt.Errorf("unable to update version row: %w", err)
 	}
 
+	// Statements are not executed in dry runs.
 	updatedCount := result.RowsAffected()
-	if updatedCount != 1 {
+	if apd.printer == nil && updatedCount != 1 {
 		return fmt.Errorf("writing version update affected %d rows, should be 1", updatedCount)
 	}
 
 	return nil
 }
 
-var _ migrate.Driver[*pgx.Conn, pgx.Tx] = &CRDBDriver{}
+// DryRun returns a driver printing the statements of migrations to out.
+func (apd *CRDBDriver) DryRun(out io.Writer) migrate.Driver[pgxcommon.Querier, pgx.Tx] {
+	return &CRDBDriver{db: apd.db, printer: pgxcommon.NewStatementPrinter(out)}
+}
+
+var (
+	_ migrate.Driver[pgxcommon.Querier, pgx.Tx]       = &CRDBDriver{}
+	_ migrate.DryRunDriver[pgxcommon.Querier, pgx.Tx] = &CRDBDriver{}
+)
@@ -3,13 +3,14 @@ package migrations
 import (
 	"github.com/jackc/pgx/v5"
 
+	pgxcommon "github.com/authzed/spicedb/internal/datastore/postgres/common"
 	"github.com/authzed/spicedb/pkg/migrate"
 )
 
 var (
-	noNonAtomicMigration migrate.MigrationFunc[*pgx.Conn]
+	noNonAtomicMigration migrate.MigrationFunc[pgxcommon.Querier]
 	noAtomicMigration    migrate.TxMigrationFunc[pgx.Tx]
 )
 
 // CRDBMigrations implements a migration manager for the CRDBDriver.
-var CRDBMigrations = migrate.NewManager[*CRDBDriver, *pgx.Conn, pgx.Tx]()
+var CRDBMigrations = migrate.NewManager[*CRDBDriver, pgxcommon.Querier, pgx.Tx]()
@@ -0,0 +1,32 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/Masterminds/semver"
+
+	pgxcommon "github.com/authzed/spicedb/internal/datastore/postgres/common"
+)
+
+// serverVersion returns the version of the CockroachDB server.
+func serverVersion(ctx context.Context, conn pgxcommon.Querier) (*semver.Version, error) {
+	row := conn.QueryRow(ctx, "select version()")
+	var fullVersionString string
+	if err := row.Scan(&fullVersionString); err != nil {
+		return nil, err
+	}
+
+	re, err := regexp.Compile(semver.SemVerRegex)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile regex: %w", err)
+	}
+
+	version := re.FindString(fullVersionString)
+	v, err := semver.NewVersion(version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse version %q: %w", version, err)
+	}
+	return v, nil
+}
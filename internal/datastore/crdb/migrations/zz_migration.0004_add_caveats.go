@@ -3,7 +3,7 @@ package migrations
 import (
 	"context"
 
-	"github.com/jackc/pgx/v5"
+	pgxcommon "github.com/authzed/spicedb/internal/datastore/postgres/common"
 )
 
 const (
@@ -26,7 +26,7 @@ func init() {
 	}
 }
 
-func addCaveatFunc(ctx context.Context, conn *pgx.Conn) error {
+func addCaveatFunc(ctx context.Context, conn pgxcommon.Querier) error {
 	if _, err := conn.Exec(ctx, createCaveatTable); err != nil {
 		return err
 	}
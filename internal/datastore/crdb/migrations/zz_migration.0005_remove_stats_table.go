@@ -3,7 +3,7 @@ package migrations
 import (
 	"context"
 
-	"github.com/jackc/pgx/v5"
+	pgxcommon "github.com/authzed/spicedb/internal/datastore/postgres/common"
 )
 
 const (
@@ -17,7 +17,7 @@ func init() {
 	}
 }
 
-func removeStatsTable(ctx context.Context, conn *pgx.Conn) error {
+func removeStatsTable(ctx context.Context, conn pgxcommon.Querier) error {
 	if _, err := conn.Exec(ctx, dropStatsTable); err != nil {
 		return err
 	}
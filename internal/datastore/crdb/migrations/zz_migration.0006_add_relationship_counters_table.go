@@ -3,7 +3,7 @@ package migrations
 import (
 	"context"
 
-	"github.com/jackc/pgx/v5"
+	pgxcommon "github.com/authzed/spicedb/internal/datastore/postgres/common"
 )
 
 const (
@@ -24,7 +24,7 @@ func init() {
 	}
 }
 
-func addRelationshipCountersTable(ctx context.Context, conn *pgx.Conn) error {
+func addRelationshipCountersTable(ctx context.Context, conn pgxcommon.Querier) error {
 	if _, err := conn.Exec(ctx, addRelationshipCountersTableQuery); err != nil {
 		return err
 	}
@@ -4,7 +4,7 @@ import (
 	"context"
 	"fmt"
 
-	"github.com/jackc/pgx/v5"
+	pgxcommon "github.com/authzed/spicedb/internal/datastore/postgres/common"
 )
 
 const (
@@ -33,7 +33,7 @@ func init() {
 	}
 }
 
-func addIntegrityColumns(ctx context.Context, conn *pgx.Conn) error {
+func addIntegrityColumns(ctx context.Context, conn pgxcommon.Querier) error {
 	if _, err := conn.Exec(ctx, createRelationTupleWithIntegrityTable); err != nil {
 		return fmt.Errorf("failed to create relation_tuple_with_integrity table: %w", err)
 	}
@@ -6,7 +6,8 @@ import (
 	"regexp"
 
 	"github.com/Masterminds/semver"
-	"github.com/jackc/pgx/v5"
+
+	pgxcommon "github.com/authzed/spicedb/internal/datastore/postgres/common"
 )
 
 const (
@@ -48,7 +49,7 @@ func init() {
 	}
 }
 
-func addTransactionMetadataTable(ctx context.Context, conn *pgx.Conn) error {
+func addTransactionMetadataTable(ctx context.Context, conn pgxcommon.Querier) error {
 	row := conn.QueryRow(ctx, "select version()")
 	var fullVersionString string
 	if err := row.Scan(&fullVersionString); err != nil {
@@ -6,7 +6,8 @@ import (
 	"regexp"
 
 	"github.com/Masterminds/semver"
-	"github.com/jackc/pgx/v5"
+
+	pgxcommon "github.com/authzed/spicedb/internal/datastore/postgres/common"
 )
 
 const (
@@ -33,7 +34,7 @@ func init() {
 	}
 }
 
-func addExpirationSupport(ctx context.Context, conn *pgx.Conn) error {
+func addExpirationSupport(ctx context.Context, conn pgxcommon.Querier) error {
 	// Add the expires_at column to relation_tuple.
 	_, err := conn.Exec(ctx, addExpirationColumnToRelationTuple)
 	if err != nil {
@@ -0,0 +1,57 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+
+	pgxcommon "github.com/authzed/spicedb/internal/datastore/postgres/common"
+)
+
+const (
+	// The primary key columns are implicitly part of every index, so the resource is covered.
+	createRelationTupleBySubjectCoveringIndex = `CREATE INDEX IF NOT EXISTS ix_relation_tuple_by_subject_covering
+		ON relation_tuple (userset_namespace, userset_object_id, userset_relation, namespace, relation)
+		STORING (caveat_name, caveat_context, expires_at, timestamp);`
+
+	createRelationTupleWithIntegrityBySubjectCoveringIndex = `CREATE INDEX IF NOT EXISTS ix_relation_tuple_by_subject_covering
+		ON relation_tuple_with_integrity (userset_namespace, userset_object_id, userset_relation, namespace, relation)
+		STORING (caveat_name, caveat_context, expires_at, timestamp, integrity_key_id, integrity_hash);`
+
+	dropRelationTupleBySubjectCoveringIndex              = `DROP INDEX IF EXISTS relation_tuple@ix_relation_tuple_by_subject_covering;`
+	dropRelationTupleWithIntegrityBySubjectCoveringIndex = `DROP INDEX IF EXISTS relation_tuple_with_integrity@ix_relation_tuple_by_subject_covering;`
+)
+
+func init() {
+	err := CRDBMigrations.Register("add-subject-covering-index", "add-expiration-support", addSubjectCoveringIndex, noAtomicMigration)
+	if err != nil {
+		panic("failed to register migration: " + err.Error())
+	}
+
+	if err := CRDBMigrations.RegisterDown("add-subject-covering-index", dropSubjectCoveringIndex, noAtomicMigration); err != nil {
+		panic("failed to register down migration: " + err.Error())
+	}
+}
+
+func addSubjectCoveringIndex(ctx context.Context, conn pgxcommon.Querier) error {
+	if _, err := conn.Exec(ctx, createRelationTupleBySubjectCoveringIndex); err != nil {
+		return fmt.Errorf("failed to create covering index for relationships by subject: %w", err)
+	}
+
+	if _, err := conn.Exec(ctx, createRelationTupleWithIntegrityBySubjectCoveringIndex); err != nil {
+		return fmt.Errorf("failed to create covering index for relationships with integrity by subject: %w", err)
+	}
+
+	return nil
+}
+
+func dropSubjectCoveringIndex(ctx context.Context, conn pgxcommon.Querier) error {
+	if _, err := conn.Exec(ctx, dropRelationTupleBySubjectCoveringIndex); err != nil {
+		return fmt.Errorf("failed to drop covering index for relationships by subject: %w", err)
+	}
+
+	if _, err := conn.Exec(ctx, dropRelationTupleWithIntegrityBySubjectCoveringIndex); err != nil {
+		return fmt.Errorf("failed to drop covering index for relationships with integrity by subject: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,67 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+
+	pgxcommon "github.com/authzed/spicedb/internal/datastore/postgres/common"
+)
+
+const (
+	// The records are deleted by row-level TTL once expired. Records without an expiration never
+	// expire.
+	addInternalRecordTableQuery = `
+		CREATE TABLE IF NOT EXISTS internal_record (
+			record_key STRING PRIMARY KEY,
+			record_value BYTES NOT NULL,
+			expires_at TIMESTAMPTZ
+		) WITH (ttl_expiration_expression = 'expires_at', ttl_job_cron = '@hourly');
+	`
+
+	// v22.1 doesn't support `ttl_expiration_expression`, so expired records remain until they
+	// are overwritten or deleted.
+	addInternalRecordTableQueryWithoutTTL = `
+		CREATE TABLE IF NOT EXISTS internal_record (
+			record_key STRING PRIMARY KEY,
+			record_value BYTES NOT NULL,
+			expires_at TIMESTAMPTZ
+		);
+	`
+
+	dropInternalRecordTableQuery = `DROP TABLE IF EXISTS internal_record;`
+)
+
+func init() {
+	err := CRDBMigrations.Register("add-internal-record-table", "add-subject-covering-index", addInternalRecordTable, noAtomicMigration)
+	if err != nil {
+		panic("failed to register migration: " + err.Error())
+	}
+
+	if err := CRDBMigrations.RegisterDown("add-internal-record-table", dropInternalRecordTable, noAtomicMigration); err != nil {
+		panic("failed to register down migration: " + err.Error())
+	}
+}
+
+func addInternalRecordTable(ctx context.Context, conn pgxcommon.Querier) error {
+	v, err := serverVersion(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	query := addInternalRecordTableQuery
+	if v.Major() == 22 && v.Minor() == 1 {
+		query = addInternalRecordTableQueryWithoutTTL
+	}
+
+	if _, err := conn.Exec(ctx, query); err != nil {
+		return fmt.Errorf("failed to create internal record table: %w", err)
+	}
+	return nil
+}
+
+func dropInternalRecordTable(ctx context.Context, conn pgxcommon.Querier) error {
+	if _, err := conn.Exec(ctx, dropInternalRecordTableQuery); err != nil {
+		return fmt.Errorf("failed to drop internal record table: %w", err)
+	}
+	return nil
+}
@@ -19,6 +19,7 @@ type crdbOptions struct {
 	revisionQuantization           time.Duration
 	followerReadDelay              time.Duration
 	maxRevisionStalenessPercent    float64
+	revisionQuantizationJitter     time.Duration
 	gcWindow                       time.Duration
 	maxRetries                     uint8
 	overlapStrategy                string
@@ -26,12 +27,17 @@ type crdbOptions struct {
 	enableConnectionBalancing      bool
 	analyzeBeforeStatistics        bool
 	filterMaximumIDCount           uint16
+	slowQueryThreshold             time.Duration
+	queryShapeCacheCapacity        int
+	connMaxErrors                  int
 	enablePrometheusStats          bool
 	withIntegrity                  bool
 	allowedMigrations              []string
 	columnOptimizationOption       common.ColumnOptimizationOption
 	includeQueryParametersInTraces bool
 	expirationDisabled             bool
+	preparedStatementCacheCapacity int
+	indexPlannerRefreshInterval    time.Duration
 }
 
 const (
@@ -58,10 +64,13 @@ const (
 	defaultEnableConnectionBalancing      = true
 	defaultConnectRate                    = 100 * time.Millisecond
 	defaultFilterMaximumIDCount           = 100
+	defaultQueryShapeCacheCapacity        = 1024
 	defaultWithIntegrity                  = false
 	defaultColumnOptimizationOption       = common.ColumnOptimizationOptionNone
 	defaultIncludeQueryParametersInTraces = false
 	defaultExpirationDisabled             = false
+	defaultPreparedStatementCacheCapacity = 0
+	defaultIndexPlannerRefreshInterval    = 5 * time.Minute
 )
 
 // Option provides the facility to configure how clients within the CRDB
@@ -84,10 +93,13 @@ func generateConfig(options []Option) (crdbOptions, error) {
 		enableConnectionBalancing:      defaultEnableConnectionBalancing,
 		connectRate:                    defaultConnectRate,
 		filterMaximumIDCount:           defaultFilterMaximumIDCount,
+		queryShapeCacheCapacity:        defaultQueryShapeCacheCapacity,
 		withIntegrity:                  defaultWithIntegrity,
 		columnOptimizationOption:       defaultColumnOptimizationOption,
 		includeQueryParametersInTraces: defaultIncludeQueryParametersInTraces,
 		expirationDisabled:             defaultExpirationDisabled,
+		preparedStatementCacheCapacity: defaultPreparedStatementCacheCapacity,
+		indexPlannerRefreshInterval:    defaultIndexPlannerRefreshInterval,
 	}
 
 	for _, option := range options {
@@ -281,6 +293,16 @@ func MaxRevisionStalenessPercent(stalenessPercent float64) Option {
 	return func(po *crdbOptions) { po.maxRevisionStalenessPercent = stalenessPercent }
 }
 
+// RevisionQuantizationJitter is the maximum random offset by which the boundaries of the
+// revision quantization windows of this instance are shifted, such that the optimized revisions
+// cached by the nodes of a fleet do not all expire at the same time. It adds up to the jitter to
+// the staleness of optimized revisions.
+//
+// This value defaults to 0 (disabled).
+func RevisionQuantizationJitter(jitter time.Duration) Option {
+	return func(po *crdbOptions) { po.revisionQuantizationJitter = jitter }
+}
+
 // GCWindow is the maximum age of a passed revision that will be considered
 // valid.
 //
@@ -345,6 +367,40 @@ func FilterMaximumIDCount(filterMaximumIDCount uint16) Option {
 	return func(po *crdbOptions) { po.filterMaximumIDCount = filterMaximumIDCount }
 }
 
+// SlowQueryThreshold is the duration over which relationship queries are logged as slow, along with
+// the shape of the statement, the API method which triggered them and the revision used.
+//
+// Disabled (zero) by default.
+func SlowQueryThreshold(threshold time.Duration) Option {
+	return func(po *crdbOptions) { po.slowQueryThreshold = threshold }
+}
+
+// QueryShapeCacheCapacity is the maximum number of query shapes whose generated SQL is cached, so
+// that relationship queries differing only in their values are not rebuilt. Zero disables the cache.
+//
+// This value defaults to 1024.
+func QueryShapeCacheCapacity(capacity int) Option {
+	return func(po *crdbOptions) { po.queryShapeCacheCapacity = capacity }
+}
+
+// IndexPlannerRefreshInterval is the interval at which the statistics of the relationships table
+// are reloaded, to choose between the subject and resource indexes for reverse queries. Zero
+// disables the planner, leaving the choice of index to CockroachDB.
+//
+// This value defaults to 5 minutes.
+func IndexPlannerRefreshInterval(interval time.Duration) Option {
+	return func(po *crdbOptions) { po.indexPlannerRefreshInterval = interval }
+}
+
+// ConnMaxErrors is the maximum number of consecutive connection errors, such as network failures,
+// tolerated on a pooled connection. Connections exceeding it are closed when released to the pool
+// instead of being reused.
+//
+// Disabled (zero) by default.
+func ConnMaxErrors(maxErrors int) Option {
+	return func(po *crdbOptions) { po.connMaxErrors = maxErrors }
+}
+
 // WithIntegrity marks whether the datastore should store and return integrity information.
 func WithIntegrity(withIntegrity bool) Option {
 	return func(po *crdbOptions) { po.withIntegrity = withIntegrity }
@@ -361,6 +417,18 @@ func IncludeQueryParametersInTraces(includeQueryParametersInTraces bool) Option
 	return func(po *crdbOptions) { po.includeQueryParametersInTraces = includeQueryParametersInTraces }
 }
 
+// PreparedStatementCacheCapacity is the number of prepared statements cached on each connection
+// for the relationship queries, which are keyed by the shape of the query and reused across
+// queries of the same shape. Hits and misses of the cache are exported as metrics.
+//
+// As snapshot reads include their revision in the query, their statements are only reused for
+// queries at the same quantized revision.
+//
+// Disabled (zero) by default, in which case the connections keep the default cache of the driver.
+func PreparedStatementCacheCapacity(capacity int) Option {
+	return func(po *crdbOptions) { po.preparedStatementCacheCapacity = capacity }
+}
+
 // WithColumnOptimization configures the column optimization option for the datastore.
 func WithColumnOptimization(isEnabled bool) Option {
 	return func(po *crdbOptions) {
@@ -71,6 +71,9 @@ type RetryableError struct {
 func (e *RetryableError) Error() string { return "retryable error" + ": " + e.Err.Error() }
 func (e *RetryableError) Unwrap() error { return e.Err }
 
+// IsContentionError implements datastore.ErrContention.
+func (e *RetryableError) IsContentionError() bool { return true }
+
 func (e *RetryableError) GRPCStatus() *status.Status {
 	return spiceerrors.WithCodeAndDetails(
 		e.Unwrap(),
@@ -48,6 +48,7 @@ type crdbReader struct {
 	filterMaximumIDCount uint16
 	withIntegrity        bool
 	atSpecificRevision   string
+	indexPlanner         *common.IndexPlanner
 }
 
 const asOfSystemTime = "AS OF SYSTEM TIME"
@@ -232,6 +233,27 @@ func (cr *crdbReader) QueryRelationships(
 	return cr.executor.ExecuteQuery(ctx, qBuilder, opts...)
 }
 
+func (cr *crdbReader) QueryRelationshipsBatch(
+	ctx context.Context,
+	filters []datastore.RelationshipsFilter,
+	opts ...options.QueryOptionsOption,
+) (datastore.TaggedRelationshipIterator, error) {
+	qBuilders := make([]common.SchemaQueryFilterer, 0, len(filters))
+	for _, filter := range filters {
+		qBuilder, err := common.NewSchemaQueryFiltererForRelationshipsSelect(cr.schema, cr.filterMaximumIDCount).WithFromSuffix(cr.fromSuffix()).FilterWithRelationshipsFilter(filter)
+		if err != nil {
+			return nil, err
+		}
+		qBuilders = append(qBuilders, qBuilder)
+	}
+
+	if spiceerrors.DebugAssertionsEnabled {
+		opts = append(opts, options.WithSQLAssertion(cr.assertHasExpectedAsOfSystemTime))
+	}
+
+	return cr.executor.ExecuteBatchQuery(ctx, qBuilders, opts...)
+}
+
 func (cr *crdbReader) ReverseQueryRelationships(
 	ctx context.Context,
 	subjectsFilter datastore.SubjectsFilter,
@@ -251,6 +273,12 @@ func (cr *crdbReader) ReverseQueryRelationships(
 			FilterToRelation(queryOpts.ResRelation.Relation)
 	}
 
+	if cr.indexPlanner != nil {
+		if index := cr.indexPlanner.ChooseIndex(ctx, qBuilder); index != "" {
+			qBuilder = qBuilder.WithIndexHint("@" + index)
+		}
+	}
+
 	eopts := []options.QueryOptionsOption{
 		options.WithLimit(queryOpts.LimitForReverse),
 		options.WithAfter(queryOpts.AfterForReverse),
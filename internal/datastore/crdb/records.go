@@ -0,0 +1,94 @@
+package crdb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"github.com/authzed/spicedb/internal/datastore/common"
+	"github.com/authzed/spicedb/pkg/datastore"
+)
+
+var upsertRecordSuffix = fmt.Sprintf(
+	"ON CONFLICT (%[1]s) DO UPDATE SET %[2]s = excluded.%[2]s, %[3]s = excluded.%[3]s",
+	colRecordKey,
+	colRecordValue,
+	colExpiresAt,
+)
+
+func (rwt *crdbReadWriteTXN) ReadRecords(ctx context.Context, keyPrefix string) ([]datastore.Record, error) {
+	sql, args, err := psql.Select(colRecordKey, colRecordValue, colExpiresAt).
+		From(tableInternalRecord).
+		Where(sq.Like{colRecordKey: common.LikePrefixPattern(keyPrefix)}).
+		OrderBy(colRecordKey).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := rwt.tx.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read records: %w", err)
+	}
+	defer rows.Close()
+
+	var records []datastore.Record
+	for rows.Next() {
+		var record datastore.Record
+		var expiresAt *time.Time
+		if err := rows.Scan(&record.Key, &record.Value, &expiresAt); err != nil {
+			return nil, fmt.Errorf("failed to read records: %w", err)
+		}
+		if expiresAt != nil {
+			record.ExpiresAt = *expiresAt
+		}
+		records = append(records, record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read records: %w", err)
+	}
+	return records, nil
+}
+
+func (rwt *crdbReadWriteTXN) WriteRecords(ctx context.Context, records ...datastore.Record) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	builder := psql.Insert(tableInternalRecord).Columns(colRecordKey, colRecordValue, colExpiresAt)
+	for _, record := range records {
+		var expiresAt *time.Time
+		if !record.ExpiresAt.IsZero() {
+			expiresAt = &record.ExpiresAt
+		}
+		builder = builder.Values(record.Key, record.Value, expiresAt)
+	}
+
+	sql, args, err := builder.Suffix(upsertRecordSuffix).ToSql()
+	if err != nil {
+		return err
+	}
+
+	if _, err := rwt.tx.Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("failed to write records: %w", err)
+	}
+	return nil
+}
+
+func (rwt *crdbReadWriteTXN) DeleteRecords(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	sql, args, err := psql.Delete(tableInternalRecord).Where(sq.Eq{colRecordKey: keys}).ToSql()
+	if err != nil {
+		return err
+	}
+
+	if _, err := rwt.tx.Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("failed to delete records: %w", err)
+	}
+	return nil
+}
@@ -10,7 +10,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/jackc/pgx/v5"
 	"github.com/prometheus/client_golang/prometheus"
 	"google.golang.org/protobuf/types/known/structpb"
 	"google.golang.org/protobuf/types/known/timestamppb"
@@ -26,7 +25,8 @@ import (
 )
 
 const (
-	queryChangefeed       = "EXPERIMENTAL CHANGEFEED FOR %s WITH updated, cursor = '%s', resolved = '%s', min_checkpoint_frequency = '0';"
+	queryChangefeed       = "CREATE CHANGEFEED FOR %s WITH updated, cursor = '%s', resolved = '%s', min_checkpoint_frequency = '0';"
+	queryChangefeedPreV23 = "EXPERIMENTAL CHANGEFEED FOR %s WITH updated, cursor = '%s', resolved = '%s', min_checkpoint_frequency = '0';"
 	queryChangefeedPreV22 = "EXPERIMENTAL CHANGEFEED FOR %s WITH updated, cursor = '%s', resolved = '%s';"
 )
 
@@ -113,33 +113,6 @@ func (cds *crdbDatastore) watch(
 		watchConnectTimeout = cds.watchConnectTimeout
 	}
 
-	// get non-pooled connection for watch
-	// "applications should explicitly create dedicated connections to consume
-	// changefeed data, instead of using a connection pool as most client
-	// drivers do by default."
-	// see: https://www.cockroachlabs.com/docs/v22.2/changefeed-for#considerations
-	conn, err := pgxcommon.ConnectWithInstrumentationAndTimeout(ctx, cds.dburl, watchConnectTimeout)
-	if err != nil {
-		errs <- err
-		return
-	}
-	defer func() { _ = conn.Close(ctx) }()
-
-	tableNames := make([]string, 0, 4)
-	tableNames = append(tableNames, tableTransactionMetadata)
-	if opts.Content&datastore.WatchRelationships == datastore.WatchRelationships {
-		tableNames = append(tableNames, cds.schema.RelationshipTableName)
-	}
-	if opts.Content&datastore.WatchSchema == datastore.WatchSchema {
-		tableNames = append(tableNames, tableNamespace)
-		tableNames = append(tableNames, tableCaveat)
-	}
-
-	if len(tableNames) == 0 {
-		errs <- fmt.Errorf("at least relationships or schema must be specified")
-		return
-	}
-
 	if opts.CheckpointInterval < 0 {
 		errs <- fmt.Errorf("invalid checkpoint interval given")
 		return
@@ -152,7 +125,6 @@ func (cds *crdbDatastore) watch(
 	}
 
 	resolvedDurationString := strconv.FormatInt(resolvedDuration.Milliseconds(), 10) + "ms"
-	interpolated := fmt.Sprintf(cds.beginChangefeedQuery, strings.Join(tableNames, ","), afterRevision, resolvedDurationString)
 
 	sendError := func(err error) {
 		if errors.Is(ctx.Err(), context.Canceled) {
@@ -194,17 +166,144 @@ func (cds *crdbDatastore) watch(
 		}
 	}
 
-	changes, err := conn.Query(ctx, interpolated)
+	if opts.IncludeSchemaSnapshot && opts.Content&datastore.WatchSchema == datastore.WatchSchema {
+		snapshot, err := common.SchemaSnapshotChanges(ctx, cds.SnapshotReader(afterRevision), afterRevision)
+		if err != nil {
+			sendError(err)
+			return
+		}
+
+		if snapshot != nil {
+			if err := sendChange(snapshot); err != nil {
+				sendError(err)
+				return
+			}
+		}
+	}
+
+	// The changefeeds are read until the watch returns, which cancels them.
+	feedCtx, cancelFeeds := context.WithCancel(ctx)
+	defer cancelFeeds()
+
+	feedTables := changefeedTables(opts.Content, cds.schema.RelationshipTableName)
+	rows := make(chan changefeedRow)
+	feedErrs := make(chan error, len(feedTables))
+	for feed, tableNames := range feedTables {
+		query := fmt.Sprintf(cds.beginChangefeedQuery, strings.Join(tableNames, ","), afterRevision, resolvedDurationString)
+		go func() {
+			feedErrs <- cds.readChangefeed(feedCtx, feed, query, watchConnectTimeout, rows)
+		}()
+	}
+
+	cds.processChanges(ctx, rows, feedErrs, len(feedTables), sendError, sendChange, opts, opts.EmissionStrategy == datastore.EmitImmediatelyStrategy)
+}
+
+// changefeedTables returns the tables read by each of the changefeeds of a watch for the given
+// content. Relationships and schema definitions are read from separate changefeeds, so that the
+// resolved timestamps of the schema tables are not held back by the volume of relationship changes
+// and the schema tables can be scanned without the relationships table. The transaction metadata
+// is read by the first changefeed.
+func changefeedTables(content datastore.WatchContent, relationshipTableName string) [][]string {
+	feedTables := [][]string{{tableTransactionMetadata}}
+	if content&datastore.WatchRelationships == datastore.WatchRelationships {
+		feedTables[0] = append(feedTables[0], relationshipTableName)
+	}
+
+	if content&datastore.WatchSchema == datastore.WatchSchema {
+		schemaTables := []string{tableNamespace, tableCaveat}
+		if len(feedTables[0]) > 1 {
+			feedTables = append(feedTables, schemaTables)
+		} else {
+			feedTables[0] = append(feedTables[0], schemaTables...)
+		}
+	}
+
+	return feedTables
+}
+
+// changefeedRow is a row read from one of the changefeeds of a watch.
+type changefeedRow struct {
+	feed           int
+	tableName      []byte
+	primaryKeyJSON []byte
+	changeJSON     []byte
+}
+
+// readChangefeed runs the changefeed query on a dedicated connection and sends its rows until it
+// fails or the context is canceled.
+func (cds *crdbDatastore) readChangefeed(ctx context.Context, feed int, query string, connectTimeout time.Duration, rows chan<- changefeedRow) error {
+	// get non-pooled connection for watch
+	// "applications should explicitly create dedicated connections to consume
+	// changefeed data, instead of using a connection pool as most client
+	// drivers do by default."
+	// see: https://www.cockroachlabs.com/docs/v22.2/changefeed-for#considerations
+	conn, err := pgxcommon.ConnectWithInstrumentationAndTimeout(ctx, cds.dburl, connectTimeout)
 	if err != nil {
-		sendError(err)
-		return
+		return err
+	}
+	defer func() { _ = conn.Close(ctx) }()
+
+	changes, err := conn.Query(ctx, query)
+	if err != nil {
+		return err
 	}
 
 	// We call Close async here because it can be slow and blocks closing the channels. There is
 	// no return value so we're not really losing anything.
 	defer func() { go changes.Close() }()
 
-	cds.processChanges(ctx, changes, sendError, sendChange, opts, opts.EmissionStrategy == datastore.EmitImmediatelyStrategy)
+	for changes.Next() {
+		row := changefeedRow{feed: feed}
+
+		// Pull in the table name, the primary key(s) and change information.
+		if err := changes.Scan(&row.tableName, &row.primaryKeyJSON, &row.changeJSON); err != nil {
+			return err
+		}
+
+		select {
+		case rows <- row:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return changes.Err()
+}
+
+// feedCheckpoints tracks the resolved timestamps of the changefeeds of a watch. Changes are only
+// complete up to the earliest of these, as each changefeed resolves its own tables independently.
+type feedCheckpoints struct {
+	resolved []*revisions.HLCRevision
+	last     *revisions.HLCRevision
+}
+
+func newFeedCheckpoints(feedCount int) *feedCheckpoints {
+	return &feedCheckpoints{resolved: make([]*revisions.HLCRevision, feedCount)}
+}
+
+// resolve records the resolved timestamp received from a changefeed, and returns the revision up
+// to which all changefeeds are now resolved, if it advanced.
+func (fc *feedCheckpoints) resolve(feed int, rev revisions.HLCRevision) (revisions.HLCRevision, bool) {
+	if current := fc.resolved[feed]; current == nil || rev.GreaterThan(*current) {
+		fc.resolved[feed] = &rev
+	}
+
+	checkpoint := fc.resolved[0]
+	for _, resolved := range fc.resolved {
+		if resolved == nil {
+			return revisions.HLCRevision{}, false
+		}
+		if resolved.LessThan(*checkpoint) {
+			checkpoint = resolved
+		}
+	}
+
+	if fc.last != nil && !checkpoint.GreaterThan(*fc.last) {
+		return revisions.HLCRevision{}, false
+	}
+
+	fc.last = checkpoint
+	return *checkpoint, true
 }
 
 // changeTracker takes care of accumulating received from CockroachDB until a checkpoint is emitted
@@ -317,7 +416,9 @@ type (
 	sendErrorFunc  func(err error)
 )
 
-func (cds *crdbDatastore) processChanges(ctx context.Context, changes pgx.Rows, sendError sendErrorFunc, sendChange sendChangeFunc, opts datastore.WatchOptions, streaming bool) {
+// processChanges processes the rows read from the changefeeds of a watch, until one of them fails or
+// ends.
+func (cds *crdbDatastore) processChanges(ctx context.Context, rows <-chan changefeedRow, feedErrs <-chan error, feedCount int, sendError sendErrorFunc, sendChange sendChangeFunc, opts datastore.WatchOptions, streaming bool) {
 	var tracked changeTracker[revisions.HLCRevision, revisions.HLCRevision]
 	if streaming {
 		tracked = &streamingChangeProvider{
@@ -329,19 +430,20 @@ func (cds *crdbDatastore) processChanges(ctx context.Context, changes pgx.Rows,
 		tracked = common.NewChanges(revisions.HLCKeyFunc, opts.Content, opts.MaximumBufferedChangesByteSize)
 	}
 
-	for changes.Next() {
-		var tableNameBytes []byte
-		var changeJSON []byte
-		var primaryKeyValuesJSON []byte
-
-		// Pull in the table name, the primary key(s) and change information.
-		if err := changes.Scan(&tableNameBytes, &primaryKeyValuesJSON, &changeJSON); err != nil {
-			sendError(err)
+	checkpoints := newFeedCheckpoints(feedCount)
+	for {
+		var row changefeedRow
+		select {
+		case row = <-rows:
+		case err := <-feedErrs:
+			if err != nil {
+				sendError(err)
+			}
 			return
 		}
 
 		var details changeDetails
-		if err := json.Unmarshal(changeJSON, &details); err != nil {
+		if err := json.Unmarshal(row.changeJSON, &details); err != nil {
 			sendError(err)
 			return
 		}
@@ -349,12 +451,17 @@ func (cds *crdbDatastore) processChanges(ctx context.Context, changes pgx.Rows,
 		// Resolved indicates that the specified revision is "complete"; no additional updates can come in before or at it.
 		// Therefore, at this point, we issue tracked updates from before that time, and the checkpoint update.
 		if details.Resolved != "" {
-			rev, err := revisions.HLCRevisionFromString(details.Resolved)
+			resolved, err := revisions.HLCRevisionFromString(details.Resolved)
 			if err != nil {
 				sendError(fmt.Errorf("malformed resolved timestamp: %w", err))
 				return
 			}
 
+			rev, ok := checkpoints.resolve(row.feed, resolved)
+			if !ok {
+				continue
+			}
+
 			filtered, err := tracked.FilterAndRemoveRevisionChanges(revisions.HLCKeyLessThanFunc, rev)
 			if err != nil {
 				sendError(err)
@@ -424,10 +531,10 @@ func (cds *crdbDatastore) processChanges(ctx context.Context, changes pgx.Rows,
 		}
 
 		// Otherwise, this a notification of a row change.
-		tableName := string(tableNameBytes)
+		tableName := string(row.tableName)
 
 		var pkValues []string
-		if err := json.Unmarshal(primaryKeyValuesJSON, &pkValues); err != nil {
+		if err := json.Unmarshal(row.primaryKeyJSON, &pkValues); err != nil {
 			sendError(err)
 			return
 		}
@@ -512,7 +619,7 @@ func (cds *crdbDatastore) processChanges(ctx context.Context, changes pgx.Rows,
 
 		case tableNamespace:
 			if len(pkValues) != 1 {
-				sendError(spiceerrors.MustBugf("expected a single definition name for the primary key in change feed. found: %s", string(primaryKeyValuesJSON)))
+				sendError(spiceerrors.MustBugf("expected a single definition name for the primary key in change feed. found: %s", string(row.primaryKeyJSON)))
 				return
 			}
 
@@ -551,7 +658,7 @@ func (cds *crdbDatastore) processChanges(ctx context.Context, changes pgx.Rows,
 
 		case tableCaveat:
 			if len(pkValues) != 1 {
-				sendError(spiceerrors.MustBugf("expected a single definition name for the primary key in change feed. found: %s", string(primaryKeyValuesJSON)))
+				sendError(spiceerrors.MustBugf("expected a single definition name for the primary key in change feed. found: %s", string(row.primaryKeyJSON)))
 				return
 			}
 
@@ -610,9 +717,4 @@ func (cds *crdbDatastore) processChanges(ctx context.Context, changes pgx.Rows,
 			return
 		}
 	}
-
-	if changes.Err() != nil {
-		sendError(changes.Err())
-		return
-	}
 }
@@ -0,0 +1,115 @@
+package crdb
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/common"
+	"github.com/authzed/spicedb/internal/datastore/revisions"
+	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+func TestChangefeedTables(t *testing.T) {
+	tcs := []struct {
+		content  datastore.WatchContent
+		expected [][]string
+	}{
+		{datastore.WatchRelationships, [][]string{{tableTransactionMetadata, "relation_tuple"}}},
+		{datastore.WatchSchema, [][]string{{tableTransactionMetadata, tableNamespace, tableCaveat}}},
+		{datastore.WatchRelationships | datastore.WatchSchema | datastore.WatchCheckpoints, [][]string{
+			{tableTransactionMetadata, "relation_tuple"},
+			{tableNamespace, tableCaveat},
+		}},
+	}
+
+	for _, tc := range tcs {
+		require.Equal(t, tc.expected, changefeedTables(tc.content, "relation_tuple"))
+	}
+}
+
+func TestFeedCheckpoints(t *testing.T) {
+	rev := func(timestamp int64) revisions.HLCRevision {
+		parsed, err := revisions.HLCRevisionFromString(fmt.Sprint(timestamp))
+		require.NoError(t, err)
+		return parsed
+	}
+
+	checkpoints := newFeedCheckpoints(2)
+
+	// Nothing is resolved until every feed is.
+	_, ok := checkpoints.resolve(0, rev(10))
+	require.False(t, ok)
+
+	checkpoint, ok := checkpoints.resolve(1, rev(5))
+	require.True(t, ok)
+	require.True(t, checkpoint.Equal(rev(5)))
+
+	// The checkpoint advances with the slowest feed.
+	_, ok = checkpoints.resolve(0, rev(20))
+	require.False(t, ok)
+
+	checkpoint, ok = checkpoints.resolve(1, rev(15))
+	require.True(t, ok)
+	require.True(t, checkpoint.Equal(rev(15)))
+
+	// Resolved timestamps never move a feed backwards.
+	_, ok = checkpoints.resolve(1, rev(12))
+	require.False(t, ok)
+
+	checkpoint, ok = checkpoints.resolve(1, rev(30))
+	require.True(t, ok)
+	require.True(t, checkpoint.Equal(rev(20)))
+}
+
+func TestProcessChangesMergesFeeds(t *testing.T) {
+	cds := &crdbDatastore{schema: *common.NewSchemaInformationWithOptions(common.WithRelationshipTableName("relation_tuple"))}
+
+	namespaceBytes, err := (&core.NamespaceDefinition{Name: "document"}).MarshalVT()
+	require.NoError(t, err)
+
+	rows := make(chan changefeedRow)
+	feedErrs := make(chan error, 1)
+	go func() {
+		for _, row := range []changefeedRow{
+			{feed: 0, tableName: []byte("relation_tuple"), primaryKeyJSON: []byte(`["document","foo","viewer","user","tom","..."]`), changeJSON: []byte(`{"updated":"10.0000000000","after":{}}`)},
+			{feed: 1, tableName: []byte(tableNamespace), primaryKeyJSON: []byte(`["document"]`), changeJSON: []byte(fmt.Sprintf(`{"updated":"10.0000000000","after":{"serialized_config":"\\x%s"}}`, hex.EncodeToString(namespaceBytes)))},
+			{feed: 0, tableName: []byte(tableTransactionMetadata), primaryKeyJSON: []byte(`["someid"]`), changeJSON: []byte(fmt.Sprintf(`{"updated":"10.0000000000","after":{"metadata":{"%s":"somekey"}}}`, spicedbTransactionKey))},
+			{feed: 0, changeJSON: []byte(`{"resolved":"20.0000000000"}`)},
+			{feed: 1, changeJSON: []byte(`{"resolved":"15.0000000000"}`)},
+		} {
+			rows <- row
+		}
+		feedErrs <- errors.New("feed ended")
+	}()
+
+	var changes []*datastore.RevisionChanges
+	var sentErr error
+	cds.processChanges(context.Background(), rows, feedErrs, 2,
+		func(err error) { sentErr = err },
+		func(change *datastore.RevisionChanges) error {
+			changes = append(changes, change)
+			return nil
+		},
+		datastore.WatchOptions{Content: datastore.WatchRelationships | datastore.WatchSchema | datastore.WatchCheckpoints},
+		false,
+	)
+	require.EqualError(t, sentErr, "feed ended")
+
+	// The changes of both feeds at the same revision are emitted together, once both have resolved
+	// past it, followed by a checkpoint at the earliest resolved timestamp.
+	require.Len(t, changes, 2)
+	require.Equal(t, "10.0000000000", changes[0].Revision.String())
+	require.Equal(t, []tuple.RelationshipUpdate{tuple.Touch(tuple.MustParse("document:foo#viewer@user:tom"))}, changes[0].RelationshipChanges)
+	require.Len(t, changes[0].ChangedDefinitions, 1)
+	require.Equal(t, "document", changes[0].ChangedDefinitions[0].GetName())
+
+	require.True(t, changes[1].IsCheckpoint)
+	require.Equal(t, "15.0000000000", changes[1].Revision.String())
+}
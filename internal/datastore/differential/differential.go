@@ -0,0 +1,389 @@
+// Package differential runs the same randomized sequences of operations against a datastore and
+// against memdb, as an oracle, and flags any divergence between their results.
+package differential
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"slices"
+	"testing"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+const (
+	waitForChangesTimeout = 10 * time.Second
+	watchBufferLength     = 128
+)
+
+var (
+	resourceIDs = []string{"doc0", "doc1", "doc2", "doc3"}
+	relations   = []string{"viewer", "editor", "caveated_viewer"}
+	subjectIDs  = []string{"user0", "user1", "user2", "user3"}
+	secrets     = []string{"1234", "5678"}
+
+	// farFutureExpiration is the expiration of expiring relationships, far enough in the future
+	// for them never to expire during a test.
+	farFutureExpiration = time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	sentinel = tuple.MustParse("document:sentinel#viewer@user:sentinel")
+)
+
+// Options are the options of a differential run.
+type Options struct {
+	// Seed is the seed of the first sequence of operations; each sequence uses the next seed.
+	Seed int64
+
+	// Sequences is the number of sequences of operations run, each against new datastores.
+	Sequences int
+
+	// OperationsPerSequence is the number of operations of each sequence.
+	OperationsPerSequence int
+}
+
+// DefaultOptions are the default options of a differential run.
+var DefaultOptions = Options{
+	Seed:                  1,
+	Sequences:             10,
+	OperationsPerSequence: 50,
+}
+
+// Run runs randomized sequences of operations against the datastores created by newDatastore and
+// against memdb, failing the test at the first divergence between their results. The operations
+// are writes, deletes by filter, and reads at the revisions of previous operations; the changes
+// watched over each sequence are compared at its end.
+//
+// The datastores must not garbage collect the revisions of a sequence while it runs.
+func Run(t *testing.T, newDatastore func(t *testing.T) datastore.Datastore, opts Options) {
+	for sequence := 0; sequence < opts.Sequences; sequence++ {
+		seed := opts.Seed + int64(sequence)
+		t.Run(fmt.Sprintf("seed-%d", seed), func(t *testing.T) {
+			oracle, err := memdb.NewMemdbDatastore(watchBufferLength, 0, memdb.DisableGC)
+			require.NoError(t, err)
+			t.Cleanup(func() { oracle.Close() })
+
+			runSequence(t, oracle, newDatastore(t), seed, opts.OperationsPerSequence)
+		})
+	}
+}
+
+// target is a datastore against which a sequence runs, with the revisions of its writes.
+type target struct {
+	name      string
+	ds        datastore.Datastore
+	revisions []datastore.Revision
+}
+
+func runSequence(t *testing.T, oracleDS datastore.Datastore, subjectDS datastore.Datastore, seed int64, operations int) {
+	ctx := context.Background()
+
+	oracleDS, _ = testfixtures.StandardDatastoreWithSchema(oracleDS, require.New(t))
+	subjectDS, _ = testfixtures.StandardDatastoreWithSchema(subjectDS, require.New(t))
+
+	oracle := &target{name: "memdb", ds: oracleDS}
+	subject := &target{name: "datastore", ds: subjectDS}
+
+	watchCtx, cancel := context.WithCancel(ctx)
+	t.Cleanup(cancel)
+
+	oracleChanges := oracle.startWatch(watchCtx, t)
+	subjectChanges := subject.startWatch(watchCtx, t)
+
+	rnd := rand.New(rand.NewSource(seed)) // nolint:gosec
+	for index := 0; index < operations; index++ {
+		op := randomOperation(rnd, len(oracle.revisions))
+		oracleResult := op.apply(ctx, oracle)
+		subjectResult := op.apply(ctx, subject)
+		require.Equal(t, oracleResult, subjectResult, "divergence at operation %d (%s) of the sequence with seed %d", index, op, seed)
+	}
+
+	// The sentinel write ensures that the watches end with a change.
+	sentinelWrite := writeOperation{updates: []tuple.RelationshipUpdate{tuple.Touch(sentinel)}}
+	require.Empty(t, sentinelWrite.apply(ctx, oracle))
+	require.Empty(t, sentinelWrite.apply(ctx, subject))
+
+	require.Equal(t,
+		oracle.watchedChanges(t, oracleChanges),
+		subject.watchedChanges(t, subjectChanges),
+		"divergence of the changes watched over the sequence with seed %d", seed,
+	)
+}
+
+// operation is an operation of a sequence, applied identically to each target.
+type operation interface {
+	fmt.Stringer
+
+	// apply applies the operation to the target and returns its result, which must be the same
+	// for every target.
+	apply(ctx context.Context, target *target) string
+}
+
+func randomOperation(rnd *rand.Rand, writeCount int) operation {
+	switch choice := rnd.Intn(10); {
+	case choice < 5 || writeCount == 0:
+		return randomWrite(rnd)
+	case choice < 6:
+		return randomDelete(rnd)
+	default:
+		return randomRead(rnd, writeCount)
+	}
+}
+
+func randomRelationship(rnd *rand.Rand) tuple.Relationship {
+	rel := tuple.Relationship{
+		RelationshipReference: tuple.RelationshipReference{
+			Resource: tuple.ONR("document", resourceIDs[rnd.Intn(len(resourceIDs))], relations[rnd.Intn(len(relations))]),
+			Subject:  tuple.ONR("user", subjectIDs[rnd.Intn(len(subjectIDs))], tuple.Ellipsis),
+		},
+	}
+
+	if rel.Resource.Relation == "caveated_viewer" {
+		rel.OptionalCaveat = &core.ContextualizedCaveat{
+			CaveatName: "test",
+			Context:    mustStruct(map[string]any{"expectedSecret": secrets[rnd.Intn(len(secrets))]}),
+		}
+	}
+
+	if rnd.Intn(4) == 0 {
+		rel.OptionalExpiration = &farFutureExpiration
+	}
+
+	return rel
+}
+
+func mustStruct(fields map[string]any) *structpb.Struct {
+	s, err := structpb.NewStruct(fields)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// updateString returns the update with the caveat and expiration of its relationship, which
+// differ between updates of the same relationship.
+func updateString(update tuple.RelationshipUpdate) string {
+	return fmt.Sprintf("%s(%s)", update.OperationString(), tuple.MustString(update.Relationship))
+}
+
+// writeOperation writes relationship updates in a single transaction.
+type writeOperation struct {
+	updates []tuple.RelationshipUpdate
+}
+
+func randomWrite(rnd *rand.Rand) writeOperation {
+	count := 1 + rnd.Intn(4)
+	seen := make(map[string]struct{}, count)
+
+	var updates []tuple.RelationshipUpdate
+	for i := 0; i < count; i++ {
+		rel := randomRelationship(rnd)
+
+		// Datastores reject updates of the same relationship in a single write.
+		key := tuple.StringWithoutCaveatOrExpiration(rel)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+
+		switch rnd.Intn(3) {
+		case 0:
+			updates = append(updates, tuple.Create(rel))
+		case 1:
+			updates = append(updates, tuple.Touch(rel))
+		default:
+			updates = append(updates, tuple.Delete(rel))
+		}
+	}
+	return writeOperation{updates: updates}
+}
+
+func (op writeOperation) String() string {
+	descriptions := make([]string, 0, len(op.updates))
+	for _, update := range op.updates {
+		descriptions = append(descriptions, updateString(update))
+	}
+	return fmt.Sprintf("write %v", descriptions)
+}
+
+func (op writeOperation) apply(ctx context.Context, target *target) string {
+	revision, err := target.ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		return rwt.WriteRelationships(ctx, op.updates)
+	})
+	return target.recordResult(revision, err)
+}
+
+// deleteOperation deletes the relationships matching a filter.
+type deleteOperation struct {
+	filter *v1.RelationshipFilter
+}
+
+func randomDelete(rnd *rand.Rand) deleteOperation {
+	filter := &v1.RelationshipFilter{ResourceType: "document"}
+	if rnd.Intn(2) == 0 {
+		filter.OptionalResourceId = resourceIDs[rnd.Intn(len(resourceIDs))]
+	}
+	if rnd.Intn(2) == 0 {
+		filter.OptionalRelation = relations[rnd.Intn(len(relations))]
+	}
+	if rnd.Intn(2) == 0 {
+		filter.OptionalSubjectFilter = &v1.SubjectFilter{
+			SubjectType:       "user",
+			OptionalSubjectId: subjectIDs[rnd.Intn(len(subjectIDs))],
+		}
+	}
+	return deleteOperation{filter: filter}
+}
+
+func (op deleteOperation) String() string {
+	return fmt.Sprintf("delete matching %s", op.filter.String())
+}
+
+func (op deleteOperation) apply(ctx context.Context, target *target) string {
+	revision, err := target.ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		_, err := rwt.DeleteRelationships(ctx, op.filter)
+		return err
+	})
+	return target.recordResult(revision, err)
+}
+
+// readOperation reads relationships at the revision of a previous write, forward or by subject.
+type readOperation struct {
+	writeIndex     int
+	filter         datastore.RelationshipsFilter
+	subjectsFilter *datastore.SubjectsFilter
+}
+
+func randomRead(rnd *rand.Rand, writeCount int) readOperation {
+	op := readOperation{writeIndex: rnd.Intn(writeCount)}
+	if rnd.Intn(2) == 0 {
+		op.subjectsFilter = &datastore.SubjectsFilter{
+			SubjectType:        "user",
+			OptionalSubjectIds: []string{subjectIDs[rnd.Intn(len(subjectIDs))]},
+		}
+		return op
+	}
+
+	op.filter = datastore.RelationshipsFilter{OptionalResourceType: "document"}
+	if rnd.Intn(2) == 0 {
+		op.filter.OptionalResourceIds = []string{resourceIDs[rnd.Intn(len(resourceIDs))]}
+	}
+	if rnd.Intn(2) == 0 {
+		op.filter.OptionalResourceRelation = relations[rnd.Intn(len(relations))]
+	}
+	return op
+}
+
+func (op readOperation) String() string {
+	if op.subjectsFilter != nil {
+		return fmt.Sprintf("read by subject %v at the revision of write %d", op.subjectsFilter.OptionalSubjectIds, op.writeIndex)
+	}
+	return fmt.Sprintf("read %v#%s at the revision of write %d", op.filter.OptionalResourceIds, op.filter.OptionalResourceRelation, op.writeIndex)
+}
+
+func (op readOperation) apply(ctx context.Context, target *target) string {
+	reader := target.ds.SnapshotReader(target.revisions[op.writeIndex])
+
+	var it datastore.RelationshipIterator
+	var err error
+	if op.subjectsFilter != nil {
+		it, err = reader.ReverseQueryRelationships(ctx, *op.subjectsFilter)
+	} else {
+		it, err = reader.QueryRelationships(ctx, op.filter)
+	}
+	if err != nil {
+		return fmt.Sprintf("error: %s", err)
+	}
+
+	rels, err := datastore.IteratorToSlice(it)
+	if err != nil {
+		return fmt.Sprintf("error: %s", err)
+	}
+
+	found := make([]string, 0, len(rels))
+	for _, rel := range rels {
+		found = append(found, tuple.MustString(rel))
+	}
+	slices.Sort(found)
+	return fmt.Sprintf("%v", found)
+}
+
+// recordResult records the revision of a successful write, and returns the result of the write.
+// The errors of the datastores differ, so only whether the write failed is compared.
+func (target *target) recordResult(revision datastore.Revision, err error) string {
+	if err != nil {
+		return "failed"
+	}
+
+	target.revisions = append(target.revisions, revision)
+	return ""
+}
+
+func (target *target) startWatch(ctx context.Context, t *testing.T) *watch {
+	startRevision, err := target.ds.HeadRevision(ctx)
+	require.NoError(t, err)
+
+	changes, errs := target.ds.Watch(ctx, startRevision, datastore.WatchOptions{
+		Content: datastore.WatchRelationships,
+	})
+	return &watch{changes: changes, errs: errs}
+}
+
+type watch struct {
+	changes <-chan *datastore.RevisionChanges
+	errs    <-chan error
+}
+
+// watchedChanges returns the relationship changes watched, as the sorted and deduplicated changes
+// by index of the write which made them, up to the last write of the target.
+func (target *target) watchedChanges(t *testing.T, watch *watch) map[int][]string {
+	lastRevision := target.revisions[len(target.revisions)-1]
+
+	changesByWrite := make(map[int]map[string]struct{})
+	for {
+		select {
+		case changes, ok := <-watch.changes:
+			require.True(t, ok, "watch of %s closed before its last write", target.name)
+
+			if len(changes.RelationshipChanges) > 0 {
+				writeIndex := slices.IndexFunc(target.revisions, changes.Revision.Equal)
+				require.GreaterOrEqual(t, writeIndex, 0, "%s watched changes at revision %s, which is not the revision of a write", target.name, changes.Revision)
+
+				if changesByWrite[writeIndex] == nil {
+					changesByWrite[writeIndex] = make(map[string]struct{})
+				}
+				for _, change := range changes.RelationshipChanges {
+					changesByWrite[writeIndex][updateString(change)] = struct{}{}
+				}
+			}
+
+			if changes.Revision.Equal(lastRevision) || changes.Revision.GreaterThan(lastRevision) {
+				watched := make(map[int][]string, len(changesByWrite))
+				for writeIndex, changes := range changesByWrite {
+					sorted := make([]string, 0, len(changes))
+					for change := range changes {
+						sorted = append(sorted, change)
+					}
+					slices.Sort(sorted)
+					watched[writeIndex] = sorted
+				}
+				return watched
+			}
+
+		case err := <-watch.errs:
+			require.NoError(t, err, "watch of %s failed", target.name)
+
+		case <-time.After(waitForChangesTimeout):
+			require.FailNow(t, "timed out waiting for watched changes", "of %s", target.name)
+		}
+	}
+}
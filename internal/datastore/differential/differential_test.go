@@ -0,0 +1,19 @@
+package differential
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/pkg/datastore"
+)
+
+func TestMemdbAgainstOracle(t *testing.T) {
+	Run(t, func(t *testing.T) datastore.Datastore {
+		ds, err := memdb.NewMemdbDatastore(watchBufferLength, 0, memdb.DisableGC)
+		require.NoError(t, err)
+		t.Cleanup(func() { ds.Close() })
+		return ds
+	}, DefaultOptions)
+}
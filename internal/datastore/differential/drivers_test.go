@@ -0,0 +1,63 @@
+//go:build ci && docker
+// +build ci,docker
+
+package differential
+
+import (
+	"testing"
+	"time"
+
+	"github.com/authzed/spicedb/internal/datastore/crdb"
+	"github.com/authzed/spicedb/internal/datastore/mysql"
+	"github.com/authzed/spicedb/internal/datastore/postgres"
+	"github.com/authzed/spicedb/internal/datastore/postgres/version"
+	"github.com/authzed/spicedb/internal/datastore/spanner"
+	testdatastore "github.com/authzed/spicedb/internal/testserver/datastore"
+	"github.com/authzed/spicedb/internal/testserver/datastore/config"
+	dsconfig "github.com/authzed/spicedb/pkg/cmd/datastore"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/migrate"
+)
+
+const (
+	gcWindow   = 2 * time.Hour
+	gcInterval = 1 * time.Hour
+)
+
+var drivers = []struct {
+	name        string
+	suffix      string
+	extraConfig []dsconfig.ConfigOption
+	pgbouncer   bool
+}{
+	{postgres.Engine, "", nil, false},
+	{postgres.Engine, "-pgbouncer", nil, true},
+	{crdb.Engine, "-overlap-static", []dsconfig.ConfigOption{dsconfig.WithOverlapStrategy("static")}, false},
+	{crdb.Engine, "-overlap-insecure", []dsconfig.ConfigOption{dsconfig.WithOverlapStrategy("insecure")}, false},
+	{mysql.Engine, "", nil, false},
+	{spanner.Engine, "", nil, false},
+}
+
+func TestDriversAgainstOracle(t *testing.T) {
+	for _, driver := range drivers {
+		t.Run(driver.name+driver.suffix, func(t *testing.T) {
+			var engine testdatastore.RunningEngineForTest
+			if driver.pgbouncer {
+				engine = testdatastore.RunPostgresForTesting(t, "", migrate.Head, version.MinimumSupportedPostgresVersion, true)
+			} else {
+				engine = testdatastore.RunDatastoreEngine(t, driver.name)
+			}
+
+			Run(t, func(t *testing.T) datastore.Datastore {
+				return engine.NewDatastore(t, config.DatastoreConfigInitFunc(
+					t,
+					append(driver.extraConfig,
+						dsconfig.WithRevisionQuantization(0),
+						dsconfig.WithGCWindow(gcWindow),
+						dsconfig.WithGCInterval(gcInterval),
+						dsconfig.WithWatchBufferLength(watchBufferLength))...,
+				))
+			}, DefaultOptions)
+		})
+	}
+}
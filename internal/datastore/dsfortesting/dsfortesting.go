@@ -44,6 +44,16 @@ type validatingReader struct {
 	datastore.Reader
 }
 
+// QueryRelationshipsBatch runs each filter through QueryRelationships, so that every query of the
+// batch is validated.
+func (vr validatingReader) QueryRelationshipsBatch(
+	ctx context.Context,
+	filters []datastore.RelationshipsFilter,
+	options ...options.QueryOptionsOption,
+) (datastore.TaggedRelationshipIterator, error) {
+	return datastore.QueryRelationshipsBatchSequentially(ctx, vr, filters, options...)
+}
+
 func (vr validatingReader) QueryRelationships(
 	ctx context.Context,
 	filter datastore.RelationshipsFilter,
@@ -82,7 +92,9 @@ func (vr validatingReader) QueryRelationships(
 		},
 	}
 
-	_, _ = executor.ExecuteQuery(ctx, qBuilder, options...)
+	if _, err := executor.ExecuteQuery(ctx, qBuilder, options...); err != nil {
+		return nil, err
+	}
 	if builder == nil {
 		return nil, fmt.Errorf("no builder returned")
 	}
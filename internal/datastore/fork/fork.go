@@ -0,0 +1,250 @@
+// Package fork implements lightweight copy-on-write forks of a datastore at a revision, so that
+// ephemeral environments, such as preview environments, can freely mutate a copy of the
+// permissions of a production datastore and then be discarded, without ever writing to it.
+//
+// A fork reads the base datastore at the revision it was forked at, overlaid with the
+// relationships and schema written to the fork, which are held in an in-memory overlay. Forking
+// therefore only copies the schema, and the relationships of the base datastore are only copied
+// once overwritten in the fork.
+package fork
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/options"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// overlayGCWindow is the window within which the past revisions of the overlay of a fork can be
+// read.
+const overlayGCWindow = time.Hour
+
+// New creates a fork of the base datastore at the given revision, with the given ID. The base
+// datastore must not garbage collect the revision while the fork is in use.
+func New(ctx context.Context, id string, base datastore.Datastore, baseRevision datastore.Revision) (datastore.Datastore, error) {
+	if strings.Contains(id, ":") {
+		return nil, fmt.Errorf("invalid fork ID `%s`", id)
+	}
+
+	if err := base.CheckRevision(ctx, baseRevision); err != nil {
+		return nil, err
+	}
+
+	overlay, err := memdb.NewMemdbDatastore(0, 0, overlayGCWindow)
+	if err != nil {
+		return nil, err
+	}
+
+	baseReader := base.SnapshotReader(baseRevision)
+	namespaces, err := baseReader.ListAllNamespaces(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	caveats, err := baseReader.ListAllCaveats(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := overlay.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		definitions := make([]*core.NamespaceDefinition, 0, len(namespaces))
+		for _, ns := range namespaces {
+			definitions = append(definitions, ns.Definition)
+		}
+		if err := rwt.WriteNamespaces(ctx, definitions...); err != nil {
+			return err
+		}
+
+		caveatDefinitions := make([]*core.CaveatDefinition, 0, len(caveats))
+		for _, caveat := range caveats {
+			caveatDefinitions = append(caveatDefinitions, caveat.Definition)
+		}
+		return rwt.WriteCaveats(ctx, caveatDefinitions)
+	}); err != nil {
+		return nil, fmt.Errorf("unable to copy the schema into the fork: %w", err)
+	}
+
+	return &forkDatastore{
+		id:           id,
+		overlay:      overlay,
+		base:         base,
+		baseRevision: baseRevision,
+	}, nil
+}
+
+type forkDatastore struct {
+	id           string
+	overlay      datastore.Datastore
+	base         datastore.Datastore
+	baseRevision datastore.Revision
+}
+
+func (fds *forkDatastore) SnapshotReader(rev datastore.Revision) datastore.Reader {
+	overlayRev, err := overlayRevision(fds.id, rev)
+	if err != nil {
+		return &forkReader{initErr: err}
+	}
+
+	return &forkReader{
+		forkID:  fds.id,
+		overlay: fds.overlay.SnapshotReader(overlayRev),
+		base:    fds.base.SnapshotReader(fds.baseRevision),
+	}
+}
+
+func (fds *forkDatastore) ReadWriteTx(ctx context.Context, f datastore.TxUserFunc, opts ...options.RWTOptionsOption) (datastore.Revision, error) {
+	overlayRev, err := fds.overlay.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		return f(ctx, &forkReadWriteTx{
+			forkReader: &forkReader{
+				forkID:  fds.id,
+				overlay: rwt,
+				base:    fds.base.SnapshotReader(fds.baseRevision),
+			},
+			overlay: rwt,
+		})
+	}, opts...)
+	if err != nil {
+		return datastore.NoRevision, err
+	}
+	return forkRevision{fds.id, overlayRev}, nil
+}
+
+func (fds *forkDatastore) OptimizedRevision(ctx context.Context) (datastore.Revision, error) {
+	rev, err := fds.overlay.OptimizedRevision(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return forkRevision{fds.id, rev}, nil
+}
+
+func (fds *forkDatastore) HeadRevision(ctx context.Context) (datastore.Revision, error) {
+	rev, err := fds.overlay.HeadRevision(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return forkRevision{fds.id, rev}, nil
+}
+
+// CheckRevision checks the revision of the overlay, as well as the revision of the base
+// datastore, which may have been garbage collected.
+func (fds *forkDatastore) CheckRevision(ctx context.Context, rev datastore.Revision) error {
+	overlayRev, err := overlayRevision(fds.id, rev)
+	if err != nil {
+		return datastore.NewInvalidRevisionErr(rev, datastore.CouldNotDetermineRevision)
+	}
+
+	if err := fds.overlay.CheckRevision(ctx, overlayRev); err != nil {
+		return err
+	}
+	return fds.base.CheckRevision(ctx, fds.baseRevision)
+}
+
+func (fds *forkDatastore) RevisionFromString(serialized string) (datastore.Revision, error) {
+	prefix := revisionPrefix + fds.id + ":"
+	if !strings.HasPrefix(serialized, prefix) {
+		return nil, fmt.Errorf("revision `%s` is not a revision of fork `%s`", serialized, fds.id)
+	}
+
+	rev, err := fds.overlay.RevisionFromString(strings.TrimPrefix(serialized, prefix))
+	if err != nil {
+		return nil, err
+	}
+	return forkRevision{fds.id, rev}, nil
+}
+
+// Watch watches the changes written to the fork. Relationships of the base datastore deleted in
+// the fork are reported as deleted, without their caveat or expiration.
+func (fds *forkDatastore) Watch(ctx context.Context, afterRevision datastore.Revision, watchOptions datastore.WatchOptions) (<-chan *datastore.RevisionChanges, <-chan error) {
+	errs := make(chan error, 1)
+	overlayRev, err := overlayRevision(fds.id, afterRevision)
+	if err != nil {
+		errs <- err
+		return nil, errs
+	}
+
+	overlayChanges, overlayErrs := fds.overlay.Watch(ctx, overlayRev, watchOptions)
+	changes := make(chan *datastore.RevisionChanges)
+	go func() {
+		defer close(changes)
+		for {
+			select {
+			case change, ok := <-overlayChanges:
+				if !ok {
+					return
+				}
+
+				change.Revision = forkRevision{fds.id, change.Revision}
+				change.RelationshipChanges = withoutShadows(change.RelationshipChanges)
+				select {
+				case changes <- change:
+				case <-ctx.Done():
+					return
+				}
+
+			case err := <-overlayErrs:
+				errs <- err
+				return
+
+			case <-ctx.Done():
+				errs <- ctx.Err()
+				return
+			}
+		}
+	}()
+
+	return changes, errs
+}
+
+// withoutShadows replaces the shadow rows written in a change by the deletion of the relationships
+// of the base datastore they shadow, unless the relationships were also written in the change.
+func withoutShadows(updates []tuple.RelationshipUpdate) []tuple.RelationshipUpdate {
+	written := make(map[tuple.RelationshipReference]struct{}, len(updates))
+	for _, update := range updates {
+		if !isShadow(update.Relationship) {
+			written[update.Relationship.RelationshipReference] = struct{}{}
+		}
+	}
+
+	filtered := make([]tuple.RelationshipUpdate, 0, len(updates))
+	for _, update := range updates {
+		if !isShadow(update.Relationship) {
+			filtered = append(filtered, update)
+			continue
+		}
+
+		rel := shadowed(update.Relationship)
+		if _, ok := written[rel.RelationshipReference]; !ok && update.Operation != tuple.UpdateOperationDelete {
+			filtered = append(filtered, tuple.Delete(rel))
+		}
+	}
+	return filtered
+}
+
+func (fds *forkDatastore) ReadyState(ctx context.Context) (datastore.ReadyState, error) {
+	return fds.base.ReadyState(ctx)
+}
+
+func (fds *forkDatastore) Features(ctx context.Context) (*datastore.Features, error) {
+	return fds.overlay.Features(ctx)
+}
+
+func (fds *forkDatastore) OfflineFeatures() (*datastore.Features, error) {
+	return fds.overlay.OfflineFeatures()
+}
+
+// Statistics returns the statistics of the base datastore, as those of the fork would only differ
+// by the few relationships written to it.
+func (fds *forkDatastore) Statistics(ctx context.Context) (datastore.Stats, error) {
+	return fds.base.Statistics(ctx)
+}
+
+// Close discards the overlay of the fork. The base datastore is left open.
+func (fds *forkDatastore) Close() error {
+	return fds.overlay.Close()
+}
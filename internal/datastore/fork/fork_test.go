@@ -0,0 +1,210 @@
+package fork
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/dsfortesting"
+	"github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/options"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+const testSchema = `
+	definition user {}
+
+	definition document {
+		relation viewer: user
+		relation editor: user
+	}
+`
+
+func newForkForTesting(t *testing.T, rels ...string) (base datastore.Datastore, fork datastore.Datastore) {
+	require := require.New(t)
+
+	ds, err := dsfortesting.NewMemDBDatastoreForTesting(0, 0, time.Hour)
+	require.NoError(err)
+
+	relationships := make([]tuple.Relationship, 0, len(rels))
+	for _, rel := range rels {
+		relationships = append(relationships, tuple.MustParse(rel))
+	}
+
+	base, revision := testfixtures.DatastoreFromSchemaAndTestRelationships(ds, testSchema, relationships, require)
+	fork, err = New(context.Background(), "testfork", base, revision)
+	require.NoError(err)
+	return base, fork
+}
+
+func writeForTesting(t *testing.T, ds datastore.Datastore, updates ...tuple.RelationshipUpdate) datastore.Revision {
+	rev, err := ds.ReadWriteTx(context.Background(), func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		return rwt.WriteRelationships(ctx, updates)
+	})
+	require.NoError(t, err)
+	return rev
+}
+
+func readForTesting(t *testing.T, ds datastore.Datastore, rev datastore.Revision, opts ...options.QueryOptionsOption) []string {
+	it, err := ds.SnapshotReader(rev).QueryRelationships(context.Background(), datastore.RelationshipsFilter{OptionalResourceType: "document"}, opts...)
+	require.NoError(t, err)
+
+	rels, err := datastore.IteratorToSlice(it)
+	require.NoError(t, err)
+
+	strs := make([]string, 0, len(rels))
+	for _, rel := range rels {
+		strs = append(strs, tuple.MustString(rel))
+	}
+	return strs
+}
+
+func headForTesting(t *testing.T, ds datastore.Datastore) datastore.Revision {
+	rev, err := ds.HeadRevision(context.Background())
+	require.NoError(t, err)
+	return rev
+}
+
+func TestForkIsolatesWrites(t *testing.T) {
+	base, fork := newForkForTesting(t,
+		"document:firstdoc#viewer@user:tom",
+		"document:seconddoc#viewer@user:fred",
+	)
+
+	forkRev := writeForTesting(t, fork,
+		tuple.Delete(tuple.MustParse("document:firstdoc#viewer@user:tom")),
+		tuple.Create(tuple.MustParse("document:thirddoc#editor@user:sarah")),
+	)
+	baseRev := writeForTesting(t, base, tuple.Create(tuple.MustParse("document:fourthdoc#viewer@user:jill")))
+
+	require.Equal(t, []string{
+		"document:seconddoc#viewer@user:fred",
+		"document:thirddoc#editor@user:sarah",
+	}, readForTesting(t, fork, forkRev, options.WithSort(options.ByResource)))
+
+	// The base datastore is never written by the fork, nor are its later writes visible to it.
+	require.Equal(t, []string{
+		"document:firstdoc#viewer@user:tom",
+		"document:fourthdoc#viewer@user:jill",
+		"document:seconddoc#viewer@user:fred",
+	}, readForTesting(t, base, baseRev, options.WithSort(options.ByResource)))
+}
+
+func TestForkCreateExisting(t *testing.T) {
+	_, fork := newForkForTesting(t, "document:firstdoc#viewer@user:tom")
+
+	_, err := fork.ReadWriteTx(context.Background(), func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		return rwt.WriteRelationships(ctx, []tuple.RelationshipUpdate{tuple.Create(tuple.MustParse("document:firstdoc#viewer@user:tom"))})
+	})
+	require.ErrorContains(t, err, "could not CREATE relationship `document:firstdoc#viewer@user:tom`")
+
+	writeForTesting(t, fork, tuple.Delete(tuple.MustParse("document:firstdoc#viewer@user:tom")))
+	rev := writeForTesting(t, fork, tuple.Create(tuple.MustParse("document:firstdoc#viewer@user:tom[expiration:2000-01-01T00:00:00Z]")))
+
+	// The base relationship remains shadowed once its overwrite in the fork has expired.
+	require.Empty(t, readForTesting(t, fork, rev))
+}
+
+func TestForkSortedPages(t *testing.T) {
+	_, fork := newForkForTesting(t,
+		"document:a#viewer@user:tom",
+		"document:c#viewer@user:tom",
+		"document:e#viewer@user:tom",
+	)
+
+	rev := writeForTesting(t, fork,
+		tuple.Touch(tuple.MustParse("document:b#viewer@user:tom")),
+		tuple.Touch(tuple.MustParse("document:d#viewer@user:tom")),
+		tuple.Delete(tuple.MustParse("document:c#viewer@user:tom")),
+	)
+
+	var pages [][]string
+	var cursor options.Cursor
+	limit := uint64(2)
+	for {
+		page := readForTesting(t, fork, rev, options.WithSort(options.ByResource), options.WithLimit(&limit), options.WithAfter(cursor))
+		if len(page) == 0 {
+			break
+		}
+		pages = append(pages, page)
+		cursor = options.ToCursor(tuple.MustParse(page[len(page)-1]))
+	}
+
+	require.Equal(t, [][]string{
+		{"document:a#viewer@user:tom", "document:b#viewer@user:tom"},
+		{"document:d#viewer@user:tom", "document:e#viewer@user:tom"},
+	}, pages)
+}
+
+func TestForkDeleteRelationships(t *testing.T) {
+	_, fork := newForkForTesting(t,
+		"document:firstdoc#viewer@user:tom",
+		"document:firstdoc#viewer@user:fred",
+		"document:seconddoc#viewer@user:fred",
+	)
+	writeForTesting(t, fork, tuple.Touch(tuple.MustParse("document:firstdoc#editor@user:sarah")))
+
+	rev, err := fork.ReadWriteTx(context.Background(), func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		_, err := rwt.DeleteRelationships(ctx, &v1.RelationshipFilter{ResourceType: "document", OptionalResourceId: "firstdoc"})
+		return err
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"document:seconddoc#viewer@user:fred"}, readForTesting(t, fork, rev))
+}
+
+func TestForkRevisions(t *testing.T) {
+	base, fork := newForkForTesting(t)
+
+	rev := headForTesting(t, fork)
+	require.True(t, IsForkRevision(rev.String()))
+
+	parsed, err := fork.RevisionFromString(rev.String())
+	require.NoError(t, err)
+	require.True(t, rev.Equal(parsed))
+
+	baseRev := headForTesting(t, base)
+	require.False(t, IsForkRevision(baseRev.String()))
+
+	_, err = fork.RevisionFromString(baseRev.String())
+	require.ErrorContains(t, err, "is not a revision of fork `testfork`")
+
+	_, err = fork.SnapshotReader(baseRev).ListAllNamespaces(context.Background())
+	require.ErrorContains(t, err, "is not a revision of fork `testfork`")
+
+	// The schema of the base datastore is copied into the fork.
+	namespaces, err := fork.SnapshotReader(rev).ListAllNamespaces(context.Background())
+	require.NoError(t, err)
+	require.Len(t, namespaces, 2)
+	require.True(t, IsForkRevision(namespaces[0].LastWrittenRevision.String()))
+}
+
+func TestForkWatch(t *testing.T) {
+	_, fork := newForkForTesting(t, "document:firstdoc#viewer@user:tom")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changes, errs := fork.Watch(ctx, headForTesting(t, fork), datastore.WatchJustRelationships())
+	writeForTesting(t, fork,
+		tuple.Delete(tuple.MustParse("document:firstdoc#viewer@user:tom")),
+		tuple.Touch(tuple.MustParse("document:seconddoc#viewer@user:fred")),
+	)
+
+	select {
+	case change := <-changes:
+		require.True(t, IsForkRevision(change.Revision.String()))
+		require.ElementsMatch(t, []tuple.RelationshipUpdate{
+			tuple.Delete(tuple.MustParse("document:firstdoc#viewer@user:tom")),
+			tuple.Touch(tuple.MustParse("document:seconddoc#viewer@user:fred")),
+		}, change.RelationshipChanges)
+	case err := <-errs:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "timed out waiting for the change")
+	}
+}
@@ -0,0 +1,356 @@
+package fork
+
+import (
+	"cmp"
+	"context"
+	"iter"
+	"strings"
+
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/options"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// shadowTypePrefix prefixes the object types of the shadow rows of the overlay. A shadow row is
+// written for every relationship of the base datastore overwritten or deleted in the fork, and
+// hides it from the reads of the fork even once its overwrite in the overlay expires.
+const shadowTypePrefix = "__fork_shadow__/"
+
+func isShadow(rel tuple.Relationship) bool {
+	return strings.HasPrefix(rel.Resource.ObjectType, shadowTypePrefix)
+}
+
+// shadowOf returns the shadow row of the relationship.
+func shadowOf(rel tuple.Relationship) tuple.Relationship {
+	return tuple.Relationship{
+		RelationshipReference: tuple.RelationshipReference{
+			Resource: tuple.ObjectAndRelation{
+				ObjectType: shadowTypePrefix + rel.Resource.ObjectType,
+				ObjectID:   rel.Resource.ObjectID,
+				Relation:   rel.Resource.Relation,
+			},
+			Subject: rel.Subject,
+		},
+	}
+}
+
+// shadowed returns the relationship hidden by the shadow row.
+func shadowed(shadow tuple.Relationship) tuple.Relationship {
+	rel := tuple.Relationship{RelationshipReference: shadow.RelationshipReference}
+	rel.Resource.ObjectType = strings.TrimPrefix(rel.Resource.ObjectType, shadowTypePrefix)
+	return rel
+}
+
+// exactFilter returns the filter matching exactly the relationship.
+func exactFilter(rel tuple.Relationship) datastore.RelationshipsFilter {
+	return datastore.RelationshipsFilter{
+		OptionalResourceType:     rel.Resource.ObjectType,
+		OptionalResourceIds:      []string{rel.Resource.ObjectID},
+		OptionalResourceRelation: rel.Resource.Relation,
+		OptionalSubjectsSelectors: []datastore.SubjectsSelector{{
+			OptionalSubjectType: rel.Subject.ObjectType,
+			OptionalSubjectIds:  []string{rel.Subject.ObjectID},
+			RelationFilter:      datastore.SubjectRelationFilter{}.WithRelation(rel.Subject.Relation),
+		}},
+	}
+}
+
+// forkReader reads the relationships of a fork, merging those written to its overlay with those
+// of its base datastore which are not shadowed. The schema and counters of a fork are read from
+// its overlay alone, since its schema is copied into the overlay when it is created.
+type forkReader struct {
+	forkID  string
+	overlay datastore.Reader
+	base    datastore.Reader
+	initErr error
+}
+
+// QueryRelationships reads the relationships matching the filter from the overlay and the base
+// datastore, merging them in the requested order. The limit is applied to the merged results.
+func (r *forkReader) QueryRelationships(ctx context.Context, filter datastore.RelationshipsFilter, opts ...options.QueryOptionsOption) (datastore.RelationshipIterator, error) {
+	if r.initErr != nil {
+		return nil, r.initErr
+	}
+
+	queryOpts := options.NewQueryOptionsWithOptions(opts...)
+	unlimited := []options.QueryOptionsOption{
+		options.WithSort(queryOpts.Sort),
+		options.WithAfter(queryOpts.After),
+		options.WithSkipCaveats(queryOpts.SkipCaveats),
+		options.WithSkipExpiration(queryOpts.SkipExpiration),
+		options.WithIncludeWriteRevisions(queryOpts.IncludeWriteRevisions),
+	}
+
+	overlayIt, err := r.overlay.QueryRelationships(ctx, filter, unlimited...)
+	if err != nil {
+		return nil, err
+	}
+
+	baseIt, err := r.base.QueryRelationships(ctx, filter, unlimited...)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.merge(ctx, overlayIt, baseIt, queryOpts.Sort, queryOpts.Limit), nil
+}
+
+// QueryRelationshipsBatch reads the relationships matching each filter in turn.
+func (r *forkReader) QueryRelationshipsBatch(ctx context.Context, filters []datastore.RelationshipsFilter, opts ...options.QueryOptionsOption) (datastore.TaggedRelationshipIterator, error) {
+	if r.initErr != nil {
+		return nil, r.initErr
+	}
+
+	return datastore.QueryRelationshipsBatchSequentially(ctx, r, filters, opts...)
+}
+
+// ReverseQueryRelationships reads the relationships of the subjects from the overlay and the base
+// datastore, merging them in the requested order. The limit is applied to the merged results.
+func (r *forkReader) ReverseQueryRelationships(ctx context.Context, subjectsFilter datastore.SubjectsFilter, opts ...options.ReverseQueryOptionsOption) (datastore.RelationshipIterator, error) {
+	if r.initErr != nil {
+		return nil, r.initErr
+	}
+
+	queryOpts := options.NewReverseQueryOptionsWithOptions(opts...)
+	unlimited := []options.ReverseQueryOptionsOption{
+		options.WithResRelation(queryOpts.ResRelation),
+		options.WithSortForReverse(queryOpts.SortForReverse),
+		options.WithAfterForReverse(queryOpts.AfterForReverse),
+	}
+
+	overlayIt, err := r.overlay.ReverseQueryRelationships(ctx, subjectsFilter, unlimited...)
+	if err != nil {
+		return nil, err
+	}
+
+	baseIt, err := r.base.ReverseQueryRelationships(ctx, subjectsFilter, unlimited...)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.merge(ctx, overlayIt, baseIt, queryOpts.SortForReverse, queryOpts.LimitForReverse), nil
+}
+
+// isShadowed returns whether the relationship of the base datastore is shadowed in the overlay.
+func (r *forkReader) isShadowed(ctx context.Context, rel tuple.Relationship) (bool, error) {
+	it, err := r.overlay.QueryRelationships(ctx, exactFilter(shadowOf(rel)), options.WithLimit(options.LimitOne))
+	if err != nil {
+		return false, err
+	}
+
+	_, found, err := datastore.FirstRelationshipIn(it)
+	return found, err
+}
+
+// existsInBase returns whether the relationship exists in the base datastore, whether shadowed or
+// not.
+func (r *forkReader) existsInBase(ctx context.Context, rel tuple.Relationship) (bool, error) {
+	it, err := r.base.QueryRelationships(ctx, exactFilter(rel), options.WithLimit(options.LimitOne))
+	if err != nil {
+		return false, err
+	}
+
+	_, found, err := datastore.FirstRelationshipIn(it)
+	return found, err
+}
+
+// merge merges the relationships of the overlay, without its shadow rows, with the relationships
+// of the base datastore which are not shadowed, in the given order and up to the limit.
+func (r *forkReader) merge(ctx context.Context, overlayIt, baseIt datastore.RelationshipIterator, order options.SortOrder, limit *uint64) datastore.RelationshipIterator {
+	overlaySeq := func(yield func(tuple.Relationship, error) bool) {
+		for rel, err := range overlayIt {
+			if err == nil && isShadow(rel) {
+				continue
+			}
+			if !yield(rel, err) {
+				return
+			}
+		}
+	}
+
+	baseSeq := func(yield func(tuple.Relationship, error) bool) {
+		for rel, err := range baseIt {
+			if err == nil {
+				shadowed, serr := r.isShadowed(ctx, rel)
+				if serr != nil {
+					yield(rel, serr)
+					return
+				}
+				if shadowed {
+					continue
+				}
+			}
+			if !yield(rel, err) {
+				return
+			}
+		}
+	}
+
+	return func(yield func(tuple.Relationship, error) bool) {
+		var count uint64
+		emit := func(rel tuple.Relationship) bool {
+			if limit != nil && count >= *limit {
+				return false
+			}
+			count++
+			return yield(rel, nil)
+		}
+
+		if order == options.Unsorted {
+			for _, seq := range []iter.Seq2[tuple.Relationship, error]{overlaySeq, baseSeq} {
+				for rel, err := range seq {
+					if err != nil {
+						yield(rel, err)
+						return
+					}
+					if !emit(rel) {
+						return
+					}
+				}
+			}
+			return
+		}
+
+		nextOverlay, stopOverlay := iter.Pull2(iter.Seq2[tuple.Relationship, error](overlaySeq))
+		defer stopOverlay()
+		nextBase, stopBase := iter.Pull2(iter.Seq2[tuple.Relationship, error](baseSeq))
+		defer stopBase()
+
+		overlayRel, overlayErr, overlayOK := nextOverlay()
+		baseRel, baseErr, baseOK := nextBase()
+		for overlayOK || baseOK {
+			if overlayOK && overlayErr != nil {
+				yield(overlayRel, overlayErr)
+				return
+			}
+			if baseOK && baseErr != nil {
+				yield(baseRel, baseErr)
+				return
+			}
+
+			if !baseOK || (overlayOK && compareRelationships(overlayRel, baseRel, order) <= 0) {
+				if !emit(overlayRel) {
+					return
+				}
+				overlayRel, overlayErr, overlayOK = nextOverlay()
+				continue
+			}
+
+			if !emit(baseRel) {
+				return
+			}
+			baseRel, baseErr, baseOK = nextBase()
+		}
+	}
+}
+
+// compareRelationships compares the relationships by all six of their components, in the given
+// sort order.
+func compareRelationships(lhs, rhs tuple.Relationship, order options.SortOrder) int {
+	compareONR := func(lhs, rhs tuple.ObjectAndRelation) int {
+		return cmp.Or(
+			cmp.Compare(lhs.ObjectType, rhs.ObjectType),
+			cmp.Compare(lhs.ObjectID, rhs.ObjectID),
+			cmp.Compare(lhs.Relation, rhs.Relation),
+		)
+	}
+
+	if order == options.BySubject {
+		return cmp.Or(compareONR(lhs.Subject, rhs.Subject), compareONR(lhs.Resource, rhs.Resource))
+	}
+	return cmp.Or(compareONR(lhs.Resource, rhs.Resource), compareONR(lhs.Subject, rhs.Subject))
+}
+
+func (r *forkReader) ReadNamespaceByName(ctx context.Context, nsName string) (*core.NamespaceDefinition, datastore.Revision, error) {
+	if r.initErr != nil {
+		return nil, nil, r.initErr
+	}
+
+	ns, lastWritten, err := r.overlay.ReadNamespaceByName(ctx, nsName)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ns, forkRevision{r.forkID, lastWritten}, nil
+}
+
+func (r *forkReader) ListAllNamespaces(ctx context.Context) ([]datastore.RevisionedNamespace, error) {
+	if r.initErr != nil {
+		return nil, r.initErr
+	}
+
+	namespaces, err := r.overlay.ListAllNamespaces(ctx)
+	return r.wrapDefinitionRevisions(namespaces), err
+}
+
+func (r *forkReader) LookupNamespacesWithNames(ctx context.Context, nsNames []string) ([]datastore.RevisionedNamespace, error) {
+	if r.initErr != nil {
+		return nil, r.initErr
+	}
+
+	namespaces, err := r.overlay.LookupNamespacesWithNames(ctx, nsNames)
+	return r.wrapDefinitionRevisions(namespaces), err
+}
+
+func (r *forkReader) ReadCaveatByName(ctx context.Context, name string) (*core.CaveatDefinition, datastore.Revision, error) {
+	if r.initErr != nil {
+		return nil, nil, r.initErr
+	}
+
+	caveat, lastWritten, err := r.overlay.ReadCaveatByName(ctx, name)
+	if err != nil {
+		return nil, nil, err
+	}
+	return caveat, forkRevision{r.forkID, lastWritten}, nil
+}
+
+func (r *forkReader) ListAllCaveats(ctx context.Context) ([]datastore.RevisionedCaveat, error) {
+	if r.initErr != nil {
+		return nil, r.initErr
+	}
+
+	caveats, err := r.overlay.ListAllCaveats(ctx)
+	return wrapDefinitionRevisions(r.forkID, caveats), err
+}
+
+func (r *forkReader) LookupCaveatsWithNames(ctx context.Context, names []string) ([]datastore.RevisionedCaveat, error) {
+	if r.initErr != nil {
+		return nil, r.initErr
+	}
+
+	caveats, err := r.overlay.LookupCaveatsWithNames(ctx, names)
+	return wrapDefinitionRevisions(r.forkID, caveats), err
+}
+
+func (r *forkReader) wrapDefinitionRevisions(namespaces []datastore.RevisionedNamespace) []datastore.RevisionedNamespace {
+	return wrapDefinitionRevisions(r.forkID, namespaces)
+}
+
+// wrapDefinitionRevisions wraps the revisions at which the definitions were last written in the
+// overlay into revisions of the fork.
+func wrapDefinitionRevisions[T datastore.SchemaDefinition](forkID string, definitions []datastore.RevisionedDefinition[T]) []datastore.RevisionedDefinition[T] {
+	for i := range definitions {
+		definitions[i].LastWrittenRevision = forkRevision{forkID, definitions[i].LastWrittenRevision}
+	}
+	return definitions
+}
+
+func (r *forkReader) CountRelationships(ctx context.Context, name string) (int, error) {
+	if r.initErr != nil {
+		return 0, r.initErr
+	}
+	return r.overlay.CountRelationships(ctx, name)
+}
+
+func (r *forkReader) LookupCounters(ctx context.Context) ([]datastore.RelationshipCounter, error) {
+	if r.initErr != nil {
+		return nil, r.initErr
+	}
+
+	counters, err := r.overlay.LookupCounters(ctx)
+	for i := range counters {
+		if counters[i].ComputedAtRevision != datastore.NoRevision {
+			counters[i].ComputedAtRevision = forkRevision{r.forkID, counters[i].ComputedAtRevision}
+		}
+	}
+	return counters, err
+}
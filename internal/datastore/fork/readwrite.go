@@ -0,0 +1,188 @@
+package fork
+
+import (
+	"context"
+	"fmt"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+
+	"github.com/authzed/spicedb/internal/datastore/common"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/options"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/spiceerrors"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// forkReadWriteTx writes to the overlay of a fork. Relationships of the base datastore which are
+// overwritten or deleted are shadowed, so that the base datastore is never written.
+type forkReadWriteTx struct {
+	*forkReader
+	overlay datastore.ReadWriteTransaction
+}
+
+// WriteRelationships applies the mutations to the overlay, shadowing the relationships of the
+// base datastore they overwrite or delete.
+func (rwt *forkReadWriteTx) WriteRelationships(ctx context.Context, mutations []tuple.RelationshipUpdate) error {
+	updates := make([]tuple.RelationshipUpdate, 0, len(mutations))
+	for _, mutation := range mutations {
+		inBase, err := rwt.existsInBase(ctx, mutation.Relationship)
+		if err != nil {
+			return err
+		}
+
+		switch mutation.Operation {
+		case tuple.UpdateOperationCreate:
+			existing, found, err := rwt.find(ctx, mutation.Relationship)
+			if err != nil {
+				return err
+			}
+			if found {
+				return common.NewCreateRelationshipExistsError(&existing)
+			}
+			updates = append(updates, mutation)
+
+		case tuple.UpdateOperationTouch, tuple.UpdateOperationDelete:
+			updates = append(updates, mutation)
+
+		default:
+			return spiceerrors.MustBugf("unknown tuple mutation operation type: %v", mutation.Operation)
+		}
+
+		if inBase {
+			updates = append(updates, tuple.Touch(shadowOf(mutation.Relationship)))
+		}
+	}
+
+	return rwt.overlay.WriteRelationships(ctx, updates)
+}
+
+// find returns the relationship with the same resource and subject as the given relationship, if
+// it exists in the fork.
+func (rwt *forkReadWriteTx) find(ctx context.Context, rel tuple.Relationship) (tuple.Relationship, bool, error) {
+	it, err := rwt.QueryRelationships(ctx, exactFilter(rel), options.WithLimit(options.LimitOne))
+	if err != nil {
+		return tuple.Relationship{}, false, err
+	}
+	return datastore.FirstRelationshipIn(it)
+}
+
+// DeleteRelationships deletes the relationships of the fork matching the filter, up to the limit.
+func (rwt *forkReadWriteTx) DeleteRelationships(ctx context.Context, filter *v1.RelationshipFilter, opts ...options.DeleteOptionsOption) (bool, error) {
+	dsFilter, err := datastore.RelationshipsFilterFromPublicFilter(filter)
+	if err != nil {
+		return false, err
+	}
+
+	delOpts := options.NewDeleteOptionsWithOptionsAndDefaults(opts...)
+	var limit uint64
+	var queryOpts []options.QueryOptionsOption
+	if delOpts.DeleteLimit != nil && *delOpts.DeleteLimit > 0 {
+		limit = *delOpts.DeleteLimit
+		queryOpts = append(queryOpts, options.WithLimit(delOpts.DeleteLimit))
+	}
+
+	it, err := rwt.QueryRelationships(ctx, dsFilter, queryOpts...)
+	if err != nil {
+		return false, err
+	}
+
+	rels, err := datastore.IteratorToSlice(it)
+	if err != nil {
+		return false, err
+	}
+
+	mutations := make([]tuple.RelationshipUpdate, 0, len(rels))
+	for _, rel := range rels {
+		mutations = append(mutations, tuple.Delete(rel))
+	}
+
+	if err := rwt.WriteRelationships(ctx, mutations); err != nil {
+		return false, err
+	}
+	return limit > 0 && uint64(len(rels)) == limit, nil
+}
+
+// BulkLoad creates the relationships of the source one at a time, as the overlay is in memory.
+func (rwt *forkReadWriteTx) BulkLoad(ctx context.Context, iter datastore.BulkWriteRelationshipSource) (uint64, error) {
+	var loaded uint64
+	for {
+		rel, err := iter.Next(ctx)
+		if err != nil {
+			return 0, err
+		}
+		if rel == nil {
+			return loaded, nil
+		}
+
+		if err := rwt.WriteRelationships(ctx, []tuple.RelationshipUpdate{tuple.Create(*rel)}); err != nil {
+			return 0, err
+		}
+		loaded++
+	}
+}
+
+func (rwt *forkReadWriteTx) WriteNamespaces(ctx context.Context, newConfigs ...*core.NamespaceDefinition) error {
+	return rwt.overlay.WriteNamespaces(ctx, newConfigs...)
+}
+
+// DeleteNamespaces deletes the namespaces from the overlay along with their relationships, and
+// shadows the relationships of the namespaces in the base datastore.
+func (rwt *forkReadWriteTx) DeleteNamespaces(ctx context.Context, nsNames ...string) error {
+	for _, nsName := range nsNames {
+		it, err := rwt.base.QueryRelationships(ctx, datastore.RelationshipsFilter{OptionalResourceType: nsName})
+		if err != nil {
+			return err
+		}
+
+		var shadows []tuple.RelationshipUpdate
+		for rel, err := range it {
+			if err != nil {
+				return err
+			}
+			shadows = append(shadows, tuple.Touch(shadowOf(rel)))
+		}
+
+		if err := rwt.overlay.WriteRelationships(ctx, shadows); err != nil {
+			return fmt.Errorf("unable to shadow relationships of deleted namespace: %w", err)
+		}
+	}
+
+	return rwt.overlay.DeleteNamespaces(ctx, nsNames...)
+}
+
+func (rwt *forkReadWriteTx) WriteCaveats(ctx context.Context, caveats []*core.CaveatDefinition) error {
+	return rwt.overlay.WriteCaveats(ctx, caveats)
+}
+
+func (rwt *forkReadWriteTx) DeleteCaveats(ctx context.Context, names []string) error {
+	return rwt.overlay.DeleteCaveats(ctx, names)
+}
+
+func (rwt *forkReadWriteTx) RegisterCounter(ctx context.Context, name string, filter *core.RelationshipFilter) error {
+	return rwt.overlay.RegisterCounter(ctx, name, filter)
+}
+
+func (rwt *forkReadWriteTx) UnregisterCounter(ctx context.Context, name string) error {
+	return rwt.overlay.UnregisterCounter(ctx, name)
+}
+
+func (rwt *forkReadWriteTx) StoreCounterValue(ctx context.Context, name string, value int, computedAtRevision datastore.Revision) error {
+	overlayRev, err := overlayRevision(rwt.forkID, computedAtRevision)
+	if err != nil {
+		return err
+	}
+	return rwt.overlay.StoreCounterValue(ctx, name, value, overlayRev)
+}
+
+func (rwt *forkReadWriteTx) ReadRecords(ctx context.Context, keyPrefix string) ([]datastore.Record, error) {
+	return rwt.overlay.ReadRecords(ctx, keyPrefix)
+}
+
+func (rwt *forkReadWriteTx) WriteRecords(ctx context.Context, records ...datastore.Record) error {
+	return rwt.overlay.WriteRecords(ctx, records...)
+}
+
+func (rwt *forkReadWriteTx) DeleteRecords(ctx context.Context, keys ...string) error {
+	return rwt.overlay.DeleteRecords(ctx, keys...)
+}
@@ -0,0 +1,59 @@
+package fork
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/authzed/spicedb/pkg/datastore"
+)
+
+// revisionPrefix prefixes the serialized revisions of forks, which are followed by the ID of the
+// fork and the revision of its overlay.
+const revisionPrefix = "fork:"
+
+// IsForkRevision returns whether the serialized revision is a revision of a fork, which can only
+// be read on the node holding the fork.
+func IsForkRevision(serialized string) bool {
+	return strings.HasPrefix(serialized, revisionPrefix)
+}
+
+// forkRevision is a revision of a fork, wrapping the revision of its overlay. Its serialized form
+// includes the ID of the fork, so that the revisions, and ZedTokens, of forks and their base
+// datastore can never be confused.
+type forkRevision struct {
+	forkID  string
+	overlay datastore.Revision
+}
+
+func (fr forkRevision) String() string {
+	return revisionPrefix + fr.forkID + ":" + fr.overlay.String()
+}
+
+func (fr forkRevision) Equal(rhs datastore.Revision) bool {
+	other, ok := rhs.(forkRevision)
+	return ok && other.forkID == fr.forkID && fr.overlay.Equal(other.overlay)
+}
+
+func (fr forkRevision) GreaterThan(rhs datastore.Revision) bool {
+	other, ok := rhs.(forkRevision)
+	return ok && other.forkID == fr.forkID && fr.overlay.GreaterThan(other.overlay)
+}
+
+func (fr forkRevision) LessThan(rhs datastore.Revision) bool {
+	other, ok := rhs.(forkRevision)
+	return ok && other.forkID == fr.forkID && fr.overlay.LessThan(other.overlay)
+}
+
+func (fr forkRevision) ByteSortable() bool {
+	return false
+}
+
+// overlayRevision returns the revision of the overlay of the fork with the given ID wrapped by
+// the revision.
+func overlayRevision(forkID string, revision datastore.Revision) (datastore.Revision, error) {
+	fr, ok := revision.(forkRevision)
+	if !ok || fr.forkID != forkID {
+		return nil, fmt.Errorf("revision `%s` is not a revision of fork `%s`", revision, forkID)
+	}
+	return fr.overlay, nil
+}
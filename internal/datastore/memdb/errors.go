@@ -22,6 +22,11 @@ func NewSerializationMaxRetriesReachedErr(baseErr error) error {
 	}
 }
 
+// IsContentionError implements datastore.ErrContention.
+func (err SerializationMaxRetriesReachedError) IsContentionError() bool {
+	return true
+}
+
 // GRPCStatus implements retrieving the gRPC status for the error.
 func (err SerializationMaxRetriesReachedError) GRPCStatus() *status.Status {
 	return spiceerrors.WithCodeAndDetails(
@@ -105,6 +105,20 @@ func (r *memdbReader) LookupCounters(ctx context.Context) ([]datastore.Relations
 	return counters, nil
 }
 
+// QueryRelationshipsBatch reads relationships starting from the resource side, for each filter in
+// turn, as memdb has no round trips to save.
+func (r *memdbReader) QueryRelationshipsBatch(
+	ctx context.Context,
+	filters []datastore.RelationshipsFilter,
+	opts ...options.QueryOptionsOption,
+) (datastore.TaggedRelationshipIterator, error) {
+	if r.initErr != nil {
+		return nil, r.initErr
+	}
+
+	return datastore.QueryRelationshipsBatchSequentially(ctx, r, filters, opts...)
+}
+
 // QueryRelationships reads relationships starting from the resource side.
 func (r *memdbReader) QueryRelationships(
 	_ context.Context,
@@ -141,6 +155,7 @@ func (r *memdbReader) QueryRelationships(
 		filter.OptionalResourceRelation,
 		filter.OptionalSubjectsSelectors,
 		filter.OptionalCaveatName,
+		filter.OptionalExcludedCaveatNames,
 		filter.OptionalExpirationOption,
 		makeCursorFilterFn(queryOpts.After, queryOpts.Sort),
 	)
@@ -151,11 +166,11 @@ func (r *memdbReader) QueryRelationships(
 		fallthrough
 
 	case options.ByResource:
-		iter := newMemdbTupleIterator(r.now, filteredIterator, queryOpts.Limit, queryOpts.SkipCaveats, queryOpts.SkipExpiration)
+		iter := newMemdbTupleIterator(r.now, filteredIterator, queryOpts.Limit, queryOpts.SkipCaveats, queryOpts.SkipExpiration, queryOpts.IncludeWriteRevisions)
 		return iter, nil
 
 	case options.BySubject:
-		return newSubjectSortedIterator(r.now, filteredIterator, queryOpts.Limit, queryOpts.SkipCaveats, queryOpts.SkipExpiration)
+		return newSubjectSortedIterator(r.now, filteredIterator, queryOpts.Limit, queryOpts.SkipCaveats, queryOpts.SkipExpiration, queryOpts.IncludeWriteRevisions)
 
 	default:
 		return nil, spiceerrors.MustBugf("unsupported sort order: %v", queryOpts.Sort)
@@ -181,6 +196,9 @@ func (r *memdbReader) ReverseQueryRelationships(
 	}
 
 	queryOpts := options.NewReverseQueryOptionsWithOptions(opts...)
+	if queryOpts.AfterForReverse != nil && queryOpts.SortForReverse == options.Unsorted {
+		return nil, datastore.ErrCursorsWithoutSorting
+	}
 
 	iterator, err := tx.Get(
 		tableRelationship,
@@ -204,6 +222,7 @@ func (r *memdbReader) ReverseQueryRelationships(
 		filterRelation,
 		[]datastore.SubjectsSelector{subjectsFilter.AsSelector()},
 		"",
+		nil,
 		datastore.ExpirationFilterOptionNone,
 		makeCursorFilterFn(queryOpts.AfterForReverse, queryOpts.SortForReverse),
 	)
@@ -214,11 +233,11 @@ func (r *memdbReader) ReverseQueryRelationships(
 		fallthrough
 
 	case options.ByResource:
-		iter := newMemdbTupleIterator(r.now, filteredIterator, queryOpts.LimitForReverse, false, false)
+		iter := newMemdbTupleIterator(r.now, filteredIterator, queryOpts.LimitForReverse, false, false, false)
 		return iter, nil
 
 	case options.BySubject:
-		return newSubjectSortedIterator(r.now, filteredIterator, queryOpts.LimitForReverse, false, false)
+		return newSubjectSortedIterator(r.now, filteredIterator, queryOpts.LimitForReverse, false, false, false)
 
 	default:
 		return nil, spiceerrors.MustBugf("unsupported sort order: %v", queryOpts.SortForReverse)
@@ -389,6 +408,7 @@ func filterFuncForFilters(
 	optionalRelation string,
 	optionalSubjectsSelectors []datastore.SubjectsSelector,
 	optionalCaveatFilter string,
+	optionalExcludedCaveatNames []string,
 	optionalExpirationFilter datastore.ExpirationFilterOption,
 	cursorFilter func(*relationship) bool,
 ) memdb.FilterFunc {
@@ -406,6 +426,9 @@ func filterFuncForFilters(
 			return true
 		case optionalCaveatFilter != "" && (tuple.caveat == nil || tuple.caveat.caveatName != optionalCaveatFilter):
 			return true
+		case len(optionalExcludedCaveatNames) > 0 && tuple.caveat != nil && len(tuple.caveat.context) == 0 &&
+			slices.Contains(optionalExcludedCaveatNames, tuple.caveat.caveatName):
+			return true
 		case optionalExpirationFilter == datastore.ExpirationFilterOptionHasExpiration && tuple.expiration == nil:
 			return true
 		case optionalExpirationFilter == datastore.ExpirationFilterOptionNoExpiration && tuple.expiration != nil:
@@ -476,7 +499,7 @@ func makeCursorFilterFn(after options.Cursor, order options.SortOrder) func(tpl
 	return noopCursorFilter
 }
 
-func newSubjectSortedIterator(now time.Time, it memdb.ResultIterator, limit *uint64, skipCaveats bool, skipExpiration bool) (datastore.RelationshipIterator, error) {
+func newSubjectSortedIterator(now time.Time, it memdb.ResultIterator, limit *uint64, skipCaveats bool, skipExpiration bool, includeWriteRevisions bool) (datastore.RelationshipIterator, error) {
 	results := make([]tuple.Relationship, 0)
 
 	// Coalesce all of the results into memory
@@ -486,6 +509,10 @@ func newSubjectSortedIterator(now time.Time, it memdb.ResultIterator, limit *uin
 			return nil, err
 		}
 
+		if includeWriteRevisions {
+			rt.OptionalWriteRevision = foundRaw.(*relationship).writeRevision
+		}
+
 		if rt.OptionalExpiration != nil && rt.OptionalExpiration.Before(now) {
 			continue
 		}
@@ -534,7 +561,7 @@ func eq(lhsNamespace, lhsObjectID, lhsRelation string, rhs tuple.ObjectAndRelati
 	return lhsNamespace == rhs.ObjectType && lhsObjectID == rhs.ObjectID && lhsRelation == rhs.Relation
 }
 
-func newMemdbTupleIterator(now time.Time, it memdb.ResultIterator, limit *uint64, skipCaveats bool, skipExpiration bool) datastore.RelationshipIterator {
+func newMemdbTupleIterator(now time.Time, it memdb.ResultIterator, limit *uint64, skipCaveats bool, skipExpiration bool, includeWriteRevisions bool) datastore.RelationshipIterator {
 	var count uint64
 	return func(yield func(tuple.Relationship, error) bool) {
 		for {
@@ -555,6 +582,10 @@ func newMemdbTupleIterator(now time.Time, it memdb.ResultIterator, limit *uint64
 				continue
 			}
 
+			if includeWriteRevisions {
+				rt.OptionalWriteRevision = foundRaw.(*relationship).writeRevision
+			}
+
 			if skipCaveats && rt.OptionalCaveat != nil {
 				yield(rt, fmt.Errorf("unexpected caveat in result for relationship: %v", rt))
 				return
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
 	"github.com/hashicorp/go-memdb"
@@ -60,6 +61,7 @@ func (rwt *memdbReadWriteTx) write(tx *memdb.Txn, mutations ...tuple.Relationshi
 			rwt.toCaveatReference(mutation),
 			rwt.toIntegrity(mutation),
 			mutation.Relationship.OptionalExpiration,
+			rwt.newRevision.String(),
 		}
 
 		found, err := tx.First(
@@ -270,6 +272,92 @@ func (rwt *memdbReadWriteTx) StoreCounterValue(ctx context.Context, name string,
 	return tx.Insert(tableCounters, counter)
 }
 
+func (rwt *memdbReadWriteTx) ReadRecords(_ context.Context, keyPrefix string) ([]datastore.Record, error) {
+	rwt.mustLock()
+	defer rwt.Unlock()
+
+	tx, err := rwt.txSource()
+	if err != nil {
+		return nil, err
+	}
+
+	it, err := tx.Get(tableRecords, indexID+"_prefix", keyPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []datastore.Record
+	for foundRaw := it.Next(); foundRaw != nil; foundRaw = it.Next() {
+		found := foundRaw.(*record)
+		records = append(records, datastore.Record{
+			Key:       found.key,
+			Value:     found.value,
+			ExpiresAt: found.expiresAt,
+		})
+	}
+	return records, nil
+}
+
+func (rwt *memdbReadWriteTx) WriteRecords(_ context.Context, records ...datastore.Record) error {
+	rwt.mustLock()
+	defer rwt.Unlock()
+
+	tx, err := rwt.txSource()
+	if err != nil {
+		return err
+	}
+
+	// There is no garbage collection of the in-memory datastore, so expired records are removed
+	// as new ones are written.
+	it, err := tx.Get(tableRecords, indexID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var expired []*record
+	for foundRaw := it.Next(); foundRaw != nil; foundRaw = it.Next() {
+		found := foundRaw.(*record)
+		if !found.expiresAt.IsZero() && !now.Before(found.expiresAt) {
+			expired = append(expired, found)
+		}
+	}
+
+	for _, found := range expired {
+		if err := tx.Delete(tableRecords, found); err != nil {
+			return err
+		}
+	}
+
+	for _, r := range records {
+		if err := tx.Insert(tableRecords, &record{
+			key:       r.Key,
+			value:     r.Value,
+			expiresAt: r.ExpiresAt,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (rwt *memdbReadWriteTx) DeleteRecords(_ context.Context, keys ...string) error {
+	rwt.mustLock()
+	defer rwt.Unlock()
+
+	tx, err := rwt.txSource()
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		if _, err := tx.DeleteAll(tableRecords, indexID, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (rwt *memdbReadWriteTx) WriteNamespaces(_ context.Context, newConfigs ...*core.NamespaceDefinition) error {
 	rwt.mustLock()
 	defer rwt.Unlock()
@@ -2,7 +2,9 @@ package memdb
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/authzed/spicedb/internal/datastore/revisions"
@@ -73,6 +75,17 @@ func (mdb *memdbDatastore) OptimizedRevision(_ context.Context) (datastore.Revis
 	return revisions.NewForTimestamp(now.TimestampNanoSec() - now.TimestampNanoSec()%mdb.quantizationPeriod), nil
 }
 
+func (mdb *memdbDatastore) SetRevisionQuantization(quantization time.Duration) error {
+	if quantization.Nanoseconds() > -mdb.negativeGCWindow {
+		return errors.New("gc window must be larger than quantization interval")
+	}
+
+	mdb.Lock()
+	defer mdb.Unlock()
+	mdb.quantizationPeriod = max(quantization.Nanoseconds(), 1)
+	return nil
+}
+
 func (mdb *memdbDatastore) CheckRevision(_ context.Context, dr datastore.Revision) error {
 	mdb.RLock()
 	defer mdb.RUnlock()
@@ -117,3 +130,22 @@ func (mdb *memdbDatastore) revisionOutsideGCWindow(now revisions.TimestampRevisi
 	oldest := revisions.NewForTimestamp(now.TimestampNanoSec() + mdb.negativeGCWindow)
 	return revisionRaw.LessThan(oldest)
 }
+
+func (mdb *memdbDatastore) RevisionAtTime(_ context.Context, at time.Time) (datastore.Revision, error) {
+	mdb.RLock()
+	defer mdb.RUnlock()
+	if mdb.db == nil {
+		return nil, fmt.Errorf("datastore has been closed")
+	}
+
+	// Snapshots are read by the first revision at or after the one requested, so the revision
+	// returned must be that of the last snapshot at or before the time.
+	requested := revisions.NewForTime(at.UTC())
+	for _, snapshot := range slices.Backward(mdb.revisions) {
+		if !snapshot.revision.GreaterThan(requested) {
+			return snapshot.revision, nil
+		}
+	}
+
+	return datastore.NoRevision, datastore.NewInvalidRevisionErr(requested, datastore.RevisionStale)
+}
@@ -6,6 +6,8 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/pkg/datastore"
 )
 
 func TestHeadRevision(t *testing.T) {
@@ -26,6 +28,36 @@ func TestHeadRevision(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestRevisionAtTime(t *testing.T) {
+	ds, err := NewMemdbDatastore(0, 0, time.Hour)
+	require.NoError(t, err)
+	historical := ds.(datastore.HistoricalRevisionDatastore)
+
+	_, err = historical.RevisionAtTime(context.Background(), time.Now().Add(-time.Minute))
+	require.ErrorAs(t, err, &datastore.InvalidRevisionError{})
+
+	first, err := ds.ReadWriteTx(context.Background(), func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		return nil
+	})
+	require.NoError(t, err)
+
+	between := time.Now()
+	time.Sleep(1 * time.Millisecond)
+
+	second, err := ds.ReadWriteTx(context.Background(), func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		return nil
+	})
+	require.NoError(t, err)
+
+	atBetween, err := historical.RevisionAtTime(context.Background(), between)
+	require.NoError(t, err)
+	require.True(t, first.Equal(atBetween))
+
+	atNow, err := historical.RevisionAtTime(context.Background(), time.Now())
+	require.NoError(t, err)
+	require.True(t, second.Equal(atNow))
+}
+
 func (mdb *memdbDatastore) ExampleRetryableError() error {
 	return ErrSerialization
 }
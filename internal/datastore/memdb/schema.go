@@ -24,6 +24,8 @@ const (
 
 	tableCounters = "counters"
 
+	tableRecords = "records"
+
 	tableChangelog = "changelog"
 	indexRevision  = "id"
 )
@@ -45,6 +47,12 @@ type counter struct {
 	updated     datastore.Revision
 }
 
+type record struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
 type relationship struct {
 	namespace        string
 	resourceID       string
@@ -55,6 +63,7 @@ type relationship struct {
 	caveat           *contextualizedCaveat
 	integrity        *relationshipIntegrity
 	expiration       *time.Time
+	writeRevision    string
 }
 
 type relationshipIntegrity struct {
@@ -228,5 +237,15 @@ var schema = &memdb.DBSchema{
 				},
 			},
 		},
+		tableRecords: {
+			Name: tableRecords,
+			Indexes: map[string]*memdb.IndexSchema{
+				indexID: {
+					Name:    indexID,
+					Unique:  true,
+					Indexer: &memdb.StringFieldIndex{Field: "key"},
+				},
+			},
+		},
 	},
 }
@@ -8,6 +8,7 @@ import (
 
 	"github.com/hashicorp/go-memdb"
 
+	"github.com/authzed/spicedb/internal/datastore/common"
 	"github.com/authzed/spicedb/internal/datastore/revisions"
 	"github.com/authzed/spicedb/pkg/datastore"
 )
@@ -60,6 +61,18 @@ func (mdb *memdbDatastore) Watch(ctx context.Context, ar datastore.Revision, opt
 		defer close(updates)
 		defer close(errs)
 
+		if options.IncludeSchemaSnapshot && options.Content&datastore.WatchSchema == datastore.WatchSchema {
+			snapshot, err := common.SchemaSnapshotChanges(ctx, mdb.SnapshotReader(ar), ar)
+			if err != nil {
+				errs <- err
+				return
+			}
+
+			if snapshot != nil && !sendChange(snapshot) {
+				return
+			}
+		}
+
 		currentTxn := ar.(revisions.TimestampRevision).TimestampNanoSec()
 
 		for {
@@ -103,6 +116,10 @@ func (mdb *memdbDatastore) loadChanges(_ context.Context, currentTxn int64, opti
 	mdb.RLock()
 	defer mdb.RUnlock()
 
+	if mdb.db == nil {
+		return nil, 0, nil, fmt.Errorf("datastore has been closed")
+	}
+
 	loadNewTxn := mdb.db.Txn(false)
 	defer loadNewTxn.Abort()
 
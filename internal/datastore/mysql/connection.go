@@ -2,14 +2,20 @@ package mysql
 
 import (
 	"context"
+	"database/sql"
 	"database/sql/driver"
+	"errors"
 	"fmt"
+	"io"
+	"net"
 	"strconv"
 	"time"
 
-	log "github.com/authzed/spicedb/internal/logging"
-
+	"github.com/go-sql-driver/mysql"
 	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/authzed/spicedb/internal/datastore/common"
+	log "github.com/authzed/spicedb/internal/logging"
 )
 
 var (
@@ -122,3 +128,168 @@ func addSessionVariables(c driver.Connector, variables map[string]string) (drive
 		statements: statements,
 	}, nil
 }
+
+// errorTrackingConnector wraps a connector so that the connections it creates record the errors
+// returned by their operations, and are discarded by the pool once they exceed the maximum number
+// of consecutive connection errors.
+type errorTrackingConnector struct {
+	conn    driver.Connector
+	drv     driver.Driver
+	tracker *common.ConnErrorTracker[*errorTrackingConn]
+}
+
+func (c *errorTrackingConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.conn.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &errorTrackingConn{Conn: conn, tracker: c.tracker}, nil
+}
+
+func (c *errorTrackingConnector) Driver() driver.Driver {
+	return c.drv
+}
+
+func trackConnErrors(c driver.Connector, tracker *common.ConnErrorTracker[*errorTrackingConn]) driver.Connector {
+	return &errorTrackingConnector{
+		conn:    c,
+		drv:     c.Driver(),
+		tracker: tracker,
+	}
+}
+
+// isConnectionError returns whether the error indicates a problem with the connection on which it
+// was returned, rather than with the statement executed.
+func isConnectionError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return false
+	}
+
+	var netErr net.Error
+	return errors.Is(err, driver.ErrBadConn) || errors.Is(err, mysql.ErrInvalidConn) ||
+		errors.As(err, &netErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// errorTrackingConn is a connection recording the outcome of its operations in a
+// ConnErrorTracker. It reports itself as invalid to the pool once it must be pruned.
+type errorTrackingConn struct {
+	driver.Conn
+	tracker *common.ConnErrorTracker[*errorTrackingConn]
+}
+
+var (
+	_ driver.ConnBeginTx        = (*errorTrackingConn)(nil)
+	_ driver.ConnPrepareContext = (*errorTrackingConn)(nil)
+	_ driver.QueryerContext     = (*errorTrackingConn)(nil)
+	_ driver.ExecerContext      = (*errorTrackingConn)(nil)
+	_ driver.Pinger             = (*errorTrackingConn)(nil)
+	_ driver.SessionResetter    = (*errorTrackingConn)(nil)
+	_ driver.Validator          = (*errorTrackingConn)(nil)
+	_ driver.NamedValueChecker  = (*errorTrackingConn)(nil)
+	_ driver.Connector          = (*errorTrackingConnector)(nil)
+)
+
+func (c *errorTrackingConn) record(err error) error {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, driver.ErrSkip) {
+		return err
+	}
+
+	if isConnectionError(err) {
+		c.tracker.RecordResult(c, err)
+	} else {
+		c.tracker.RecordResult(c, nil)
+	}
+	return err
+}
+
+func (c *errorTrackingConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	tx, err := c.Conn.(driver.ConnBeginTx).BeginTx(ctx, opts)
+	return tx, c.record(err)
+}
+
+func (c *errorTrackingConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	stmt, err := c.Conn.(driver.ConnPrepareContext).PrepareContext(ctx, query)
+	return stmt, c.record(err)
+}
+
+func (c *errorTrackingConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	rows, err := queryer.QueryContext(ctx, query, args)
+	return rows, c.record(err)
+}
+
+func (c *errorTrackingConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	result, err := execer.ExecContext(ctx, query, args)
+	return result, c.record(err)
+}
+
+func (c *errorTrackingConn) Ping(ctx context.Context) error {
+	pinger, ok := c.Conn.(driver.Pinger)
+	if !ok {
+		return nil
+	}
+	return c.record(pinger.Ping(ctx))
+}
+
+func (c *errorTrackingConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *errorTrackingConn) IsValid() bool {
+	if c.tracker.ShouldPrune(c) {
+		return false
+	}
+
+	if validator, ok := c.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+func (c *errorTrackingConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+func (c *errorTrackingConn) Close() error {
+	c.tracker.Forget(c)
+	return c.Conn.Close()
+}
+
+// poolStatsFunc returns a function producing the statistics of the connection pool of the database
+// in the format common to all SQL drivers.
+func poolStatsFunc(db *sql.DB, tracker *common.ConnErrorTracker[*errorTrackingConn]) func() common.PoolStats {
+	return func() common.PoolStats {
+		stats := db.Stats()
+		return common.PoolStats{
+			MaxConns:          int64(stats.MaxOpenConnections),
+			InUseConns:        int64(stats.InUse),
+			IdleConns:         int64(stats.Idle),
+			WaitCount:         stats.WaitCount,
+			WaitDuration:      stats.WaitDuration,
+			MaxLifetimeClosed: stats.MaxLifetimeClosed,
+			MaxIdleClosed:     stats.MaxIdleTimeClosed,
+			ErrorPruned:       tracker.PrunedCount(),
+		}
+	}
+}
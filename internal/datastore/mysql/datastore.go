@@ -55,6 +55,18 @@ const (
 	colCounterCurrentCount      = "current_count"
 	colCounterUpdatedAtRevision = "count_updated_at_revision"
 
+	colPinID        = "pin_id"
+	colPinRevision  = "revision"
+	colPinExpiresAt = "expires_at"
+
+	colLeaseName      = "name"
+	colLeaseHolder    = "holder"
+	colLeaseExpiresAt = "expires_at"
+
+	colRecordKey       = "record_key"
+	colRecordValue     = "record_value"
+	colRecordExpiresAt = "expires_at"
+
 	errUnableToInstantiate = "unable to instantiate datastore: %w"
 	liveDeletedTxnID       = uint64(math.MaxInt64)
 	batchDeleteSize        = 1000
@@ -71,6 +83,15 @@ const (
 
 	// https://dev.mysql.com/doc/mysql-errors/8.0/en/server-error-reference.html#error_er_dup_entry
 	errMysqlDuplicateEntry = 1062
+
+	// https://dev.mysql.com/doc/mysql-errors/8.0/en/server-error-reference.html#error_er_option_prevents_statement
+	errMysqlOptionPreventsStatement = 1290
+
+	// https://dev.mysql.com/doc/mysql-errors/8.0/en/server-error-reference.html#error_er_cant_execute_in_read_only_transaction
+	errMysqlReadOnlyTransaction = 1792
+
+	// https://dev.mysql.com/doc/mysql-errors/8.0/en/server-error-reference.html#error_er_read_only_mode
+	errMysqlReadOnlyMode = 1836
 )
 
 var (
@@ -171,6 +192,11 @@ func newMySQLDatastore(ctx context.Context, uri string, replicaIndex int, option
 		}
 	}
 
+	connErrors := common.NewConnErrorTracker[*errorTrackingConn](config.connMaxErrors)
+	if connErrors != nil {
+		connector = trackConnErrors(connector, connErrors)
+	}
+
 	var db *sql.DB
 	if config.enablePrometheusStats {
 		connector, err = instrumentConnector(connector)
@@ -189,10 +215,21 @@ func newMySQLDatastore(ctx context.Context, uri string, replicaIndex int, option
 			return nil, fmt.Errorf(errUnableToInstantiate, err)
 		}
 
+		poolUsage := "read_write"
+		if !isPrimary {
+			poolUsage = "read"
+		}
+		if err := prometheus.Register(common.NewPoolStatsCollector(dbName, poolUsage, poolStatsFunc(db, connErrors))); err != nil {
+			return nil, fmt.Errorf(errUnableToInstantiate, err)
+		}
+
 		if isPrimary {
 			if err := common.RegisterGCMetrics(); err != nil {
 				return nil, fmt.Errorf(errUnableToInstantiate, err)
 			}
+			if err := common.RegisterLeaseMetrics(); err != nil {
+				return nil, fmt.Errorf(errUnableToInstantiate, err)
+			}
 		}
 	} else {
 		db = sql.OpenDB(connector)
@@ -222,18 +259,7 @@ func newMySQLDatastore(ctx context.Context, uri string, replicaIndex int, option
 	maxRevisionStaleness := time.Duration(float64(config.revisionQuantization.Nanoseconds())*
 		config.maxRevisionStalenessPercent) * time.Nanosecond
 
-	quantizationPeriodNanos := config.revisionQuantization.Nanoseconds()
-	if quantizationPeriodNanos < 1 {
-		quantizationPeriodNanos = 1
-	}
-
-	revisionQuery := fmt.Sprintf(
-		querySelectRevision,
-		colID,
-		driver.RelationTupleTransaction(),
-		colTimestamp,
-		quantizationPeriodNanos,
-	)
+	revisionQuery := selectRevisionQuery(driver.RelationTupleTransaction(), config.revisionQuantization)
 
 	validTransactionQuery := fmt.Sprintf(
 		queryValidTransaction,
@@ -254,9 +280,11 @@ func newMySQLDatastore(ctx context.Context, uri string, replicaIndex int, option
 		common.WithColCaveatName(colCaveatName),
 		common.WithColCaveatContext(colCaveatContext),
 		common.WithColExpiration(colExpiration),
+		common.WithColWriteRevision("CAST("+colCreatedTxn+" AS CHAR)"),
 		common.WithPaginationFilterType(common.ExpandedLogicComparison),
 		common.WithPlaceholderFormat(sq.Question),
 		common.WithNowFunction("NOW"),
+		common.WithEmptyCaveatContextCondition("(%[1]s IS NULL OR JSON_LENGTH(%[1]s) = 0)"),
 		common.WithColumnOptimization(config.columnOptimizationOption),
 		common.WithExpirationDisabled(config.expirationDisabled),
 	)
@@ -270,11 +298,13 @@ func newMySQLDatastore(ctx context.Context, uri string, replicaIndex int, option
 		gcWindow:                config.gcWindow,
 		gcInterval:              config.gcInterval,
 		gcTimeout:               config.gcMaxOperationTime,
+		gcTableRetention:        config.gcTableRetention,
+		gcLeaseDuration:         config.gcLeaseDuration,
 		gcCtx:                   gcCtx,
 		cancelGc:                cancelGc,
 		watchBufferLength:       config.watchBufferLength,
 		watchBufferWriteTimeout: config.watchBufferWriteTimeout,
-		optimizedRevisionQuery:  revisionQuery,
+		maxStalenessPercent:     config.maxRevisionStalenessPercent,
 		validTransactionQuery:   validTransactionQuery,
 		createTxn:               createTxn,
 		createBaseTxn:           createBaseTxn,
@@ -290,9 +320,17 @@ func newMySQLDatastore(ctx context.Context, uri string, replicaIndex int, option
 			Kind: revisions.TransactionID,
 		},
 		filterMaximumIDCount: config.filterMaximumIDCount,
+		slowQueryLogger:      common.NewSlowQueryLogger(config.slowQueryThreshold),
+		shapeCache:           common.NewQueryShapeCache(Engine, config.queryShapeCacheCapacity),
 	}
 
+	if config.indexPlannerRefreshInterval > 0 {
+		store.indexPlanner = common.NewIndexPlanner(store.loadIndexStatistics, config.indexPlannerRefreshInterval, reverseQueryIndexes...)
+	}
+
+	store.optimizedRevisionQuery.Store(&revisionQuery)
 	store.SetOptimizedRevisionFunc(store.optimizedRevisionFunc)
+	store.SetWindowJitter(config.revisionQuantizationJitter)
 
 	ctx, cancel := context.WithTimeout(context.Background(), seedingTimeout)
 	defer cancel()
@@ -306,9 +344,11 @@ func newMySQLDatastore(ctx context.Context, uri string, replicaIndex int, option
 		if store.gcInterval > 0*time.Minute && config.gcEnabled {
 			store.gcGroup, store.gcCtx = errgroup.WithContext(store.gcCtx)
 			store.gcGroup.Go(func() error {
-				return common.StartGarbageCollector(
+				return common.StartLeaderGarbageCollector(
 					store.gcCtx,
 					store,
+					store,
+					store.gcLeaseDuration,
 					store.gcInterval,
 					store.gcWindow,
 					store.gcTimeout,
@@ -333,16 +373,21 @@ func (mds *Datastore) SnapshotReader(rev datastore.Revision) datastore.Reader {
 	}
 
 	executor := common.QueryRelationshipsExecutor{
-		Executor: newMySQLExecutor(mds.db),
+		Executor:        newMySQLExecutor(mds.db),
+		BatchExecutor:   newMySQLBatchExecutor(mds.db),
+		SlowQueryLogger: mds.slowQueryLogger,
+		ShapeCache:      mds.shapeCache,
+		Revision:        rev,
 	}
 
 	return &mysqlReader{
 		mds.QueryBuilder,
 		createTxFunc,
 		executor,
-		buildLivingObjectFilterForRevision(rev),
+		buildLivingObjectPredicateForRevision(rev),
 		mds.filterMaximumIDCount,
 		mds.schema,
+		mds.indexPlanner,
 	}
 }
 
@@ -376,7 +421,10 @@ func (mds *Datastore) ReadWriteTx(
 			}
 
 			executor := common.QueryRelationshipsExecutor{
-				Executor: newMySQLExecutor(tx),
+				Executor:        newMySQLExecutor(tx),
+				BatchExecutor:   newMySQLBatchExecutor(tx),
+				SlowQueryLogger: mds.slowQueryLogger,
+				ShapeCache:      mds.shapeCache,
 			}
 
 			rwt := &mysqlReadWriteTXN{
@@ -387,6 +435,7 @@ func (mds *Datastore) ReadWriteTx(
 					currentlyLivingObjects,
 					mds.filterMaximumIDCount,
 					mds.schema,
+					mds.indexPlanner,
 				},
 				mds.driver.RelationTuple(),
 				tx,
@@ -418,9 +467,31 @@ func wrapError(err error) error {
 		return cerr
 	}
 
+	if isReadOnlyError(err) {
+		return common.NewReadOnlyTransactionError(err)
+	}
+
+	if isErrorRetryable(err) {
+		return common.NewSerializationError(err)
+	}
+
 	return err
 }
 
+// isReadOnlyError returns whether the error indicates that the server does not currently accept
+// writes, such as when it is running with `read_only` or `super_read_only` during a failover or
+// maintenance.
+func isReadOnlyError(err error) bool {
+	var mysqlerr *mysql.MySQLError
+	if !errors.As(err, &mysqlerr) {
+		return false
+	}
+
+	return mysqlerr.Number == errMysqlOptionPreventsStatement ||
+		mysqlerr.Number == errMysqlReadOnlyTransaction ||
+		mysqlerr.Number == errMysqlReadOnlyMode
+}
+
 func isErrorRetryable(err error) bool {
 	var mysqlerr *mysql.MySQLError
 	if !errors.As(err, &mysqlerr) {
@@ -473,6 +544,14 @@ func newMySQLExecutor(tx querier) common.ExecuteReadRelsQueryFunc {
 	}
 }
 
+// newMySQLBatchExecutor creates an executor for the batched queries combining multiple
+// relationship queries, with the same transaction semantics as newMySQLExecutor.
+func newMySQLBatchExecutor(tx querier) common.ExecuteBatchReadRelsQueryFunc {
+	return func(ctx context.Context, builder common.RelationshipsQueryBuilder) (datastore.TaggedRelationshipIterator, error) {
+		return common.QueryTaggedRelationships[common.Rows, structpbWrapper](ctx, builder, asQueryableTx{tx})
+	}
+}
+
 // Datastore is a MySQL-based implementation of the datastore.Datastore interface
 type Datastore struct {
 	*common.MigrationValidator
@@ -483,16 +562,22 @@ type Datastore struct {
 	analyzeBeforeStats bool
 
 	revisionQuantization    time.Duration
+	maxStalenessPercent     float64
 	gcWindow                time.Duration
 	gcInterval              time.Duration
 	gcTimeout               time.Duration
+	gcTableRetention        common.TableRetention
+	gcLeaseDuration         time.Duration
 	watchBufferLength       uint16
 	watchBufferWriteTimeout time.Duration
 	maxRetries              uint8
 	filterMaximumIDCount    uint16
+	slowQueryLogger         *common.SlowQueryLogger
+	shapeCache              *common.QueryShapeCache
 	schema                  common.SchemaInformation
+	indexPlanner            *common.IndexPlanner
 
-	optimizedRevisionQuery string
+	optimizedRevisionQuery atomic.Pointer[string]
 	validTransactionQuery  string
 
 	gcGroup  *errgroup.Group
@@ -656,16 +741,21 @@ func (mds *Datastore) seedDatabase(ctx context.Context) error {
 	})
 }
 
-func buildLivingObjectFilterForRevision(revision datastore.Revision) queryFilterer {
-	return func(original sq.SelectBuilder) sq.SelectBuilder {
-		return original.Where(sq.LtOrEq{colCreatedTxn: revision.(revisions.TransactionIDRevision).TransactionID()}).
-			Where(sq.Or{
-				sq.Eq{colDeletedTxn: liveDeletedTxnID},
-				sq.Gt{colDeletedTxn: revision},
-			})
+// buildLivingObjectPredicateForRevision returns the predicate matching the rows alive at the
+// revision. It is a predicate rather than a filter so that relationship queries can be cached
+// by their shape.
+func buildLivingObjectPredicateForRevision(revision datastore.Revision) common.Predicate {
+	return common.Predicate{
+		SQL: colCreatedTxn + " <= ? AND (" + colDeletedTxn + " = ? OR " + colDeletedTxn + " > ?)",
+		Args: []any{
+			revision.(revisions.TransactionIDRevision).TransactionID(),
+			liveDeletedTxnID,
+			revision,
+		},
 	}
 }
 
-func currentlyLivingObjects(original sq.SelectBuilder) sq.SelectBuilder {
-	return original.Where(sq.Eq{colDeletedTxn: liveDeletedTxnID})
+var currentlyLivingObjects = common.Predicate{
+	SQL:  colDeletedTxn + " = ?",
+	Args: []any{liveDeletedTxnID},
 }
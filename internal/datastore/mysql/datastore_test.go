@@ -147,6 +147,7 @@ func additionalMySQLTests(t *testing.T, b testdatastore.RunningEngineForTest) {
 	t.Run("ChunkedGarbageCollection", createDatastoreTest(b, ChunkedGarbageCollectionTest, defaultOptions...))
 	t.Run("EmptyGarbageCollection", createDatastoreTest(b, EmptyGarbageCollectionTest, defaultOptions...))
 	t.Run("NoRelationshipsGarbageCollection", createDatastoreTest(b, NoRelationshipsGarbageCollectionTest, defaultOptions...))
+	t.Run("Lease", createDatastoreTest(b, LeaseTest, defaultOptions...))
 	t.Run("QuantizedRevisions", func(t *testing.T) {
 		QuantizedRevisionTest(t, b)
 	})
@@ -251,7 +252,7 @@ func GarbageCollectionTest(t *testing.T, ds datastore.Datastore) {
 	// Run GC at the transaction and ensure no relationships are removed.
 	mds := ds.(*Datastore)
 
-	removed, err := mds.DeleteBeforeTx(ctx, writtenAt)
+	removed, err := mds.DeleteBeforeTx(ctx, watermarksAt(writtenAt))
 	req.NoError(err)
 	req.Zero(removed.Relationships)
 	req.Zero(removed.Namespaces)
@@ -271,7 +272,7 @@ func GarbageCollectionTest(t *testing.T, ds datastore.Datastore) {
 	req.NoError(err)
 
 	// Run GC to remove the old namespace
-	removed, err = mds.DeleteBeforeTx(ctx, writtenAt)
+	removed, err = mds.DeleteBeforeTx(ctx, watermarksAt(writtenAt))
 	req.NoError(err)
 	req.Zero(removed.Relationships)
 	req.Equal(int64(1), removed.Transactions)
@@ -283,14 +284,14 @@ func GarbageCollectionTest(t *testing.T, ds datastore.Datastore) {
 	req.NoError(err)
 
 	// Run GC at the transaction and ensure no relationships are removed, but 1 transaction (the previous write namespace) is.
-	removed, err = mds.DeleteBeforeTx(ctx, relWrittenAt)
+	removed, err = mds.DeleteBeforeTx(ctx, watermarksAt(relWrittenAt))
 	req.NoError(err)
 	req.Zero(removed.Relationships)
 	req.Equal(int64(1), removed.Transactions)
 	req.Zero(removed.Namespaces)
 
 	// Run GC again and ensure there are no changes.
-	removed, err = mds.DeleteBeforeTx(ctx, relWrittenAt)
+	removed, err = mds.DeleteBeforeTx(ctx, watermarksAt(relWrittenAt))
 	req.NoError(err)
 	req.Zero(removed.Relationships)
 	req.Zero(removed.Transactions)
@@ -306,14 +307,14 @@ func GarbageCollectionTest(t *testing.T, ds datastore.Datastore) {
 	req.NoError(err)
 
 	// Run GC at the transaction and ensure the (older copy of the) relationship is removed, as well as 1 transaction (the write).
-	removed, err = mds.DeleteBeforeTx(ctx, relOverwrittenAt)
+	removed, err = mds.DeleteBeforeTx(ctx, watermarksAt(relOverwrittenAt))
 	req.NoError(err)
 	req.Equal(int64(1), removed.Relationships)
 	req.Equal(int64(1), removed.Transactions)
 	req.Zero(removed.Namespaces)
 
 	// Run GC again and ensure there are no changes.
-	removed, err = mds.DeleteBeforeTx(ctx, relOverwrittenAt)
+	removed, err = mds.DeleteBeforeTx(ctx, watermarksAt(relOverwrittenAt))
 	req.NoError(err)
 	req.Zero(removed.Relationships)
 	req.Zero(removed.Transactions)
@@ -330,14 +331,14 @@ func GarbageCollectionTest(t *testing.T, ds datastore.Datastore) {
 	tRequire.NoRelationshipExists(ctx, crel, relDeletedAt)
 
 	// Run GC at the transaction and ensure the relationship is removed, as well as 1 transaction (the overwrite).
-	removed, err = mds.DeleteBeforeTx(ctx, relDeletedAt)
+	removed, err = mds.DeleteBeforeTx(ctx, watermarksAt(relDeletedAt))
 	req.NoError(err)
 	req.Equal(int64(1), removed.Relationships)
 	req.Equal(int64(1), removed.Transactions)
 	req.Zero(removed.Namespaces)
 
 	// Run GC again and ensure there are no changes.
-	removed, err = mds.DeleteBeforeTx(ctx, relDeletedAt)
+	removed, err = mds.DeleteBeforeTx(ctx, watermarksAt(relDeletedAt))
 	req.NoError(err)
 	req.Zero(removed.Relationships)
 	req.Zero(removed.Transactions)
@@ -358,7 +359,7 @@ func GarbageCollectionTest(t *testing.T, ds datastore.Datastore) {
 
 	// Run GC at the transaction and ensure the older copies of the relationships are removed,
 	// as well as the 2 older write transactions and the older delete transaction.
-	removed, err = mds.DeleteBeforeTx(ctx, relLastWriteAt)
+	removed, err = mds.DeleteBeforeTx(ctx, watermarksAt(relLastWriteAt))
 	req.NoError(err)
 	req.Equal(int64(2), removed.Relationships)
 	req.Equal(int64(3), removed.Transactions)
@@ -407,7 +408,7 @@ func GarbageCollectionByTimeTest(t *testing.T, ds datastore.Datastore) {
 	afterWriteTx, err := mds.TxIDBefore(ctx, afterWrite)
 	req.NoError(err)
 
-	removed, err := mds.DeleteBeforeTx(ctx, afterWriteTx)
+	removed, err := mds.DeleteBeforeTx(ctx, watermarksAt(afterWriteTx))
 	req.NoError(err)
 	req.Zero(removed.Relationships)
 	req.NotZero(removed.Transactions)
@@ -431,7 +432,7 @@ func GarbageCollectionByTimeTest(t *testing.T, ds datastore.Datastore) {
 	afterDeleteTx, err := mds.TxIDBefore(ctx, afterDelete)
 	req.NoError(err)
 
-	removed, err = mds.DeleteBeforeTx(ctx, afterDeleteTx)
+	removed, err = mds.DeleteBeforeTx(ctx, watermarksAt(afterDeleteTx))
 	req.NoError(err)
 	req.Equal(int64(1), removed.Relationships)
 	req.Equal(int64(1), removed.Transactions)
@@ -457,7 +458,7 @@ func EmptyGarbageCollectionTest(t *testing.T, ds datastore.Datastore) {
 	watermark, err := gc.TxIDBefore(ctx, now.Add(-1*time.Minute))
 	req.NoError(err)
 
-	collected, err := gc.DeleteBeforeTx(ctx, watermark)
+	collected, err := gc.DeleteBeforeTx(ctx, watermarksAt(watermark))
 	req.NoError(err)
 
 	req.Equal(int64(0), collected.Relationships)
@@ -494,7 +495,7 @@ func NoRelationshipsGarbageCollectionTest(t *testing.T, ds datastore.Datastore)
 	watermark, err := gc.TxIDBefore(ctx, now.Add(-1*time.Minute))
 	req.NoError(err)
 
-	collected, err := gc.DeleteBeforeTx(ctx, watermark)
+	collected, err := gc.DeleteBeforeTx(ctx, watermarksAt(watermark))
 	req.NoError(err)
 
 	req.Equal(int64(0), collected.Relationships)
@@ -502,6 +503,44 @@ func NoRelationshipsGarbageCollectionTest(t *testing.T, ds datastore.Datastore)
 	req.Equal(int64(0), collected.Namespaces)
 }
 
+func LeaseTest(t *testing.T, ds datastore.Datastore) {
+	require := require.New(t)
+
+	ctx := context.Background()
+	mds := ds.(*Datastore)
+
+	acquired, err := mds.TryAcquireLease(ctx, "somejob", "first", time.Hour)
+	require.NoError(err)
+	require.True(acquired)
+
+	// The holder renews the lease, while other holders cannot acquire it.
+	acquired, err = mds.TryAcquireLease(ctx, "somejob", "first", time.Hour)
+	require.NoError(err)
+	require.True(acquired)
+
+	acquired, err = mds.TryAcquireLease(ctx, "somejob", "second", time.Hour)
+	require.NoError(err)
+	require.False(acquired)
+
+	// Other holders cannot release the lease.
+	require.NoError(mds.ReleaseLease(ctx, "somejob", "second"))
+	acquired, err = mds.TryAcquireLease(ctx, "somejob", "second", time.Hour)
+	require.NoError(err)
+	require.False(acquired)
+
+	// Once released, the lease is acquired by another holder.
+	require.NoError(mds.ReleaseLease(ctx, "somejob", "first"))
+	acquired, err = mds.TryAcquireLease(ctx, "somejob", "second", 1*time.Millisecond)
+	require.NoError(err)
+	require.True(acquired)
+
+	// Once expired, the lease is taken over by another holder.
+	time.Sleep(10 * time.Millisecond)
+	acquired, err = mds.TryAcquireLease(ctx, "somejob", "first", time.Hour)
+	require.NoError(err)
+	require.True(acquired)
+}
+
 func ChunkedGarbageCollectionTest(t *testing.T, ds datastore.Datastore) {
 	req := require.New(t)
 
@@ -549,7 +588,7 @@ func ChunkedGarbageCollectionTest(t *testing.T, ds datastore.Datastore) {
 	afterWriteTx, err := mds.TxIDBefore(ctx, afterWrite)
 	req.NoError(err)
 
-	removed, err := mds.DeleteBeforeTx(ctx, afterWriteTx)
+	removed, err := mds.DeleteBeforeTx(ctx, watermarksAt(afterWriteTx))
 	req.NoError(err)
 	req.Zero(removed.Relationships)
 	req.NotZero(removed.Transactions)
@@ -577,7 +616,7 @@ func ChunkedGarbageCollectionTest(t *testing.T, ds datastore.Datastore) {
 	afterDeleteTx, err := mds.TxIDBefore(ctx, afterDelete)
 	req.NoError(err)
 
-	removed, err = mds.DeleteBeforeTx(ctx, afterDeleteTx)
+	removed, err = mds.DeleteBeforeTx(ctx, watermarksAt(afterDeleteTx))
 	req.NoError(err)
 	req.Equal(int64(chunkRelationshipCount), removed.Relationships)
 	req.Equal(int64(1), removed.Transactions)
@@ -805,3 +844,8 @@ func datastoreDB(t *testing.T, migrate bool) *sql.DB {
 	require.NoError(t, err)
 	return db
 }
+
+// watermarksAt returns the watermarks collecting the history of all tables before the revision.
+func watermarksAt(revision datastore.Revision) common.Watermarks {
+	return common.Watermarks{Relationships: revision, Namespaces: revision, Transactions: revision}
+}
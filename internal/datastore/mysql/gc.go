@@ -3,10 +3,12 @@ package mysql
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"time"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/ccoveille/go-safecast"
+	"github.com/google/uuid"
 
 	"github.com/authzed/spicedb/internal/datastore/common"
 	"github.com/authzed/spicedb/internal/datastore/revisions"
@@ -15,7 +17,10 @@ import (
 	"github.com/authzed/spicedb/pkg/spiceerrors"
 )
 
-var _ common.GarbageCollector = (*Datastore)(nil)
+var (
+	_ common.GarbageCollector  = (*Datastore)(nil)
+	_ datastore.RevisionPinner = (*Datastore)(nil)
+)
 
 func (mds *Datastore) HasGCRun() bool {
 	return mds.gcHasRun.Load()
@@ -85,10 +90,10 @@ func (mds *Datastore) TxIDBefore(ctx context.Context, before time.Time) (datasto
 // - implementation misses metrics
 func (mds *Datastore) DeleteBeforeTx(
 	ctx context.Context,
-	txID datastore.Revision,
+	watermarks common.Watermarks,
 ) (removed common.DeletionCounts, err error) {
 	// Delete any relationship rows with deleted_transaction <= the transaction ID.
-	removed.Relationships, err = mds.batchDelete(ctx, mds.driver.RelationTuple(), sq.LtOrEq{colDeletedTxn: txID})
+	removed.Relationships, err = mds.batchDelete(ctx, mds.driver.RelationTuple(), sq.LtOrEq{colDeletedTxn: watermarks.Relationships})
 	if err != nil {
 		return
 	}
@@ -97,16 +102,85 @@ func (mds *Datastore) DeleteBeforeTx(
 	//
 	// We don't delete the transaction itself to ensure there is always at least
 	// one transaction present.
-	removed.Transactions, err = mds.batchDelete(ctx, mds.driver.RelationTupleTransaction(), sq.Lt{colID: txID})
+	removed.Transactions, err = mds.batchDelete(ctx, mds.driver.RelationTupleTransaction(), sq.Lt{colID: watermarks.Transactions})
 	if err != nil {
 		return
 	}
 
 	// Delete any namespace rows with deleted_transaction <= the transaction ID.
-	removed.Namespaces, err = mds.batchDelete(ctx, mds.driver.Namespace(), sq.LtOrEq{colDeletedTxn: txID})
+	removed.Namespaces, err = mds.batchDelete(ctx, mds.driver.Namespace(), sq.LtOrEq{colDeletedTxn: watermarks.Namespaces})
 	return
 }
 
+func (mds *Datastore) TableRetention() common.TableRetention {
+	return mds.gcTableRetention
+}
+
+func (mds *Datastore) PinnedRevisions(ctx context.Context) ([]datastore.Revision, error) {
+	now, err := mds.Now(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query, args, err := sb.Select(colPinRevision).From(mds.driver.RevisionPins()).Where(sq.Gt{colPinExpiresAt: now}).ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := mds.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer common.LogOnError(ctx, rows.Close)
+
+	var pinned []datastore.Revision
+	for rows.Next() {
+		var revisionStr string
+		if err := rows.Scan(&revisionStr); err != nil {
+			return nil, err
+		}
+
+		revision, err := mds.RevisionFromString(revisionStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pinned revision: %w", err)
+		}
+		pinned = append(pinned, revision)
+	}
+	return pinned, rows.Err()
+}
+
+func (mds *Datastore) PinRevision(ctx context.Context, revision datastore.Revision, expiresAt time.Time) (string, error) {
+	if _, ok := revision.(revisions.TransactionIDRevision); !ok {
+		return "", fmt.Errorf("expected transaction ID revision, found %T", revision)
+	}
+
+	pinID := uuid.NewString()
+	query, args, err := sb.Insert(mds.driver.RevisionPins()).
+		Columns(colPinID, colPinRevision, colPinExpiresAt).
+		Values(pinID, revision.String(), expiresAt.UTC()).
+		ToSql()
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := mds.db.ExecContext(ctx, query, args...); err != nil {
+		return "", fmt.Errorf("failed to pin revision: %w", err)
+	}
+	return pinID, nil
+}
+
+func (mds *Datastore) UnpinRevision(ctx context.Context, pinID string) error {
+	query, args, err := sb.Delete(mds.driver.RevisionPins()).Where(sq.Eq{colPinID: pinID}).ToSql()
+	if err != nil {
+		return err
+	}
+
+	if _, err := mds.db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to unpin revision: %w", err)
+	}
+	return nil
+}
+
 func (mds *Datastore) DeleteExpiredRels(ctx context.Context) (int64, error) {
 	if mds.schema.ExpirationDisabled {
 		return 0, nil
@@ -124,6 +198,19 @@ func (mds *Datastore) DeleteExpiredRels(ctx context.Context) (int64, error) {
 	)
 }
 
+func (mds *Datastore) DeleteExpiredRecords(ctx context.Context) (int64, error) {
+	now, err := mds.Now(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return mds.batchDelete(
+		ctx,
+		mds.driver.InternalRecords(),
+		sq.LtOrEq{colRecordExpiresAt: now},
+	)
+}
+
 // - query was reworked to make it compatible with Vitess
 // - API differences with PSQL driver
 func (mds *Datastore) batchDelete(ctx context.Context, tableName string, filter sqlFilter) (int64, error) {
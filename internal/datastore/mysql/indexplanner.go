@@ -0,0 +1,79 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/authzed/spicedb/internal/datastore/common"
+)
+
+const (
+	indexRelationTupleBySubjectRevision = "ix_relation_tuple_by_subject_revision"
+	indexRelationTupleLiving            = "uq_relation_tuple_living"
+	indexPrimary                        = "PRIMARY"
+
+	// The cardinality of each column of an index is that of the prefix of the index ending with it.
+	queryIndexStatistics = `SELECT INDEX_NAME, SEQ_IN_INDEX, CARDINALITY
+		FROM INFORMATION_SCHEMA.STATISTICS
+		WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND CARDINALITY IS NOT NULL`
+)
+
+// reverseQueryIndexes are the indexes through which reverse queries can read relationships, the
+// subject index being preferred.
+var reverseQueryIndexes = []common.RelationshipIndex{
+	{
+		Name:    indexRelationTupleBySubjectRevision,
+		Columns: []string{colUsersetNamespace, colUsersetObjectID, colUsersetRelation, colNamespace, colRelation, colObjectID, colCreatedTxn, colDeletedTxn},
+	},
+	{
+		Name:    indexRelationTupleLiving,
+		Columns: []string{colNamespace, colObjectID, colRelation, colUsersetNamespace, colUsersetObjectID, colUsersetRelation, colDeletedTxn},
+	},
+}
+
+// loadIndexStatistics loads the cardinalities of the indexes of the relationships table as last
+// computed by InnoDB, the row count being the cardinality of the primary key.
+func (mds *Datastore) loadIndexStatistics(ctx context.Context, indexes []common.RelationshipIndex) (common.IndexStatistics, error) {
+	rows, err := mds.db.QueryContext(ctx, queryIndexStatistics, mds.driver.RelationTuple())
+	if err != nil {
+		return common.IndexStatistics{}, fmt.Errorf("unable to load index statistics: %w", err)
+	}
+	defer rows.Close()
+
+	stats := common.IndexStatistics{DistinctCounts: make(map[string][]uint64, len(indexes))}
+	for rows.Next() {
+		var indexName string
+		var seqInIndex int
+		var cardinality sql.NullInt64
+		if err := rows.Scan(&indexName, &seqInIndex, &cardinality); err != nil {
+			return common.IndexStatistics{}, fmt.Errorf("unable to load index statistics: %w", err)
+		}
+		if !cardinality.Valid || cardinality.Int64 < 0 || seqInIndex < 1 {
+			continue
+		}
+
+		if indexName == indexPrimary {
+			stats.RowCount = uint64(cardinality.Int64)
+			continue
+		}
+
+		for _, index := range indexes {
+			if index.Name != indexName || seqInIndex > len(index.Columns) {
+				continue
+			}
+
+			counts, ok := stats.DistinctCounts[indexName]
+			if !ok {
+				counts = make([]uint64, len(index.Columns))
+				stats.DistinctCounts[indexName] = counts
+			}
+			counts[seqInIndex-1] = uint64(cardinality.Int64)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return common.IndexStatistics{}, fmt.Errorf("unable to load index statistics: %w", err)
+	}
+
+	return stats, nil
+}
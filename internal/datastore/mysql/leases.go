@@ -0,0 +1,75 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"github.com/authzed/spicedb/internal/datastore/common"
+)
+
+var _ common.LeaseManager = (*Datastore)(nil)
+
+// acquireLeaseSuffix updates the lease on conflict only if it is already held by
+// the holder, or has expired by the time given as its parameter. The holder is
+// assigned first, so that the expiration is only updated once the lease belongs
+// to the holder.
+var acquireLeaseSuffix = fmt.Sprintf(
+	"ON DUPLICATE KEY UPDATE %[1]s = IF(%[1]s = VALUES(%[1]s) OR %[2]s <= ?, VALUES(%[1]s), %[1]s), %[2]s = IF(%[1]s = VALUES(%[1]s), VALUES(%[2]s), %[2]s)",
+	colLeaseHolder,
+	colLeaseExpiresAt,
+)
+
+func (mds *Datastore) TryAcquireLease(ctx context.Context, name, holder string, duration time.Duration) (bool, error) {
+	// The expiration of leases is measured by the database, so that the nodes
+	// agree on it regardless of the skew of their clocks.
+	now, err := mds.Now(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	query, args, err := sb.Insert(mds.driver.Leases()).
+		Columns(colLeaseName, colLeaseHolder, colLeaseExpiresAt).
+		Values(name, holder, now.Add(duration)).
+		Suffix(acquireLeaseSuffix, now).
+		ToSql()
+	if err != nil {
+		return false, err
+	}
+
+	if _, err := mds.db.ExecContext(ctx, query, args...); err != nil {
+		return false, fmt.Errorf("failed to acquire lease: %w", err)
+	}
+
+	// The lease is read back rather than relying on the affected rows, whose
+	// count for unchanged rows depends on the flags of the connection.
+	query, args, err = sb.Select(colLeaseHolder).
+		From(mds.driver.Leases()).
+		Where(sq.Eq{colLeaseName: name}).
+		ToSql()
+	if err != nil {
+		return false, err
+	}
+
+	var currentHolder string
+	if err := mds.db.QueryRowContext(ctx, query, args...).Scan(&currentHolder); err != nil {
+		return false, fmt.Errorf("failed to read lease: %w", err)
+	}
+	return currentHolder == holder, nil
+}
+
+func (mds *Datastore) ReleaseLease(ctx context.Context, name, holder string) error {
+	query, args, err := sb.Delete(mds.driver.Leases()).
+		Where(sq.Eq{colLeaseName: name, colLeaseHolder: holder}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	if _, err := mds.db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to release lease: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package migrations
+
+import (
+	"context"
+
+	"github.com/ccoveille/go-safecast"
+
+	"github.com/authzed/spicedb/pkg/migrate"
+)
+
+type templatedBatchStatement func(t *tables, batchSize uint64) string
+
+// batchedBackfill is a non-atomic migration running a statement updating at most a batch of
+// rows until it updates none. Unlike a single UPDATE, which locks every row of a huge table
+// until it commits, each batch commits on its own, such that the backfill does not block
+// writes and resumes where it stopped when interrupted. The statement must only select rows
+// which have yet to be backfilled, and limit them to the batch size.
+type batchedBackfill struct {
+	step      string
+	statement templatedBatchStatement
+}
+
+func newBatchedBackfill(step string, statement templatedBatchStatement) batchedBackfill {
+	return batchedBackfill{
+		step:      step,
+		statement: statement,
+	}
+}
+
+func (b batchedBackfill) execute(ctx context.Context, wrapper Wrapper) error {
+	_, err := migrate.Backfill(ctx, b.step, 0, func(ctx context.Context, batchSize uint64) (uint64, error) {
+		result, err := wrapper.db.ExecContext(ctx, b.statement(wrapper.tables, batchSize))
+		if err != nil {
+			return 0, err
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+		return safecast.ToUint64(rowsAffected)
+	})
+	return err
+}
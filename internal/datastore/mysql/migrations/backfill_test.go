@@ -0,0 +1,54 @@
+//go:build ci
+// +build ci
+
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/pkg/migrate"
+)
+
+// fakeBackfillDB updates at most a batch of its remaining rows per statement.
+type fakeBackfillDB struct {
+	remaining  int64
+	statements []string
+}
+
+func (f *fakeBackfillDB) ExecContext(_ context.Context, query string, _ ...any) (sql.Result, error) {
+	f.statements = append(f.statements, query)
+
+	var batchSize int64
+	if _, err := fmt.Sscanf(query[strings.LastIndex(query, "LIMIT"):], "LIMIT %d", &batchSize); err != nil {
+		return nil, err
+	}
+
+	updated := min(batchSize, f.remaining)
+	f.remaining -= updated
+	return driver.RowsAffected(updated), nil
+}
+
+func TestBatchedBackfill(t *testing.T) {
+	backfill := newBatchedBackfill("test backfill", func(t *tables, batchSize uint64) string {
+		return fmt.Sprintf("UPDATE %s SET expiration = NULL WHERE expiration IS NULL LIMIT %d", t.RelationTuple(), batchSize)
+	})
+
+	db := &fakeBackfillDB{remaining: 5}
+	ctx := context.WithValue(context.Background(), migrate.BackfillBatchSize, uint64(2))
+	require.NoError(t, backfill.execute(ctx, Wrapper{db: db, tables: newTables("")}))
+	require.Zero(t, db.remaining)
+	require.Len(t, db.statements, 4)
+	require.Equal(t, "UPDATE relation_tuple SET expiration = NULL WHERE expiration IS NULL LIMIT 2", db.statements[0])
+
+	// In dry runs, the statement is printed once.
+	var out strings.Builder
+	require.NoError(t, backfill.execute(ctx, Wrapper{db: statementPrinter{&out}, tables: newTables("")}))
+	require.Equal(t, "UPDATE relation_tuple SET expiration = NULL WHERE expiration IS NULL LIMIT 2;\n", out.String())
+}
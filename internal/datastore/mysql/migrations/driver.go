@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"io"
 	"strings"
 
 	mysqlCommon "github.com/authzed/spicedb/internal/datastore/mysql/common"
@@ -32,6 +33,10 @@ var sb = sq.StatementBuilder.PlaceholderFormat(sq.Question)
 type MySQLDriver struct {
 	db *sql.DB
 	*tables
+
+	// out is set in dry runs, in which the statements of migrations are written
+	// to it rather than executed.
+	out io.Writer
 }
 
 // NewMySQLDriverFromDSN creates a new migration driver with a connection pool to the database DSN specified.
@@ -61,7 +66,7 @@ func NewMySQLDriverFromDSN(url string, tablePrefix string, credentialsProvider d
 
 // NewMySQLDriverFromDB creates a new migration driver with a connection pool specified upfront.
 func NewMySQLDriverFromDB(db *sql.DB, tablePrefix string) *MySQLDriver {
-	return &MySQLDriver{db, newTables(tablePrefix)}
+	return &MySQLDriver{db: db, tables: newTables(tablePrefix)}
 }
 
 // revisionToColumnName generates the column name that will denote a given migration revision
@@ -110,10 +115,17 @@ func (driver *MySQLDriver) Version(ctx context.Context) (string, error) {
 }
 
 func (driver *MySQLDriver) Conn() Wrapper {
+	if driver.out != nil {
+		return Wrapper{db: statementPrinter{driver.out}, tables: driver.tables}
+	}
 	return Wrapper{db: driver.db, tables: driver.tables}
 }
 
 func (driver *MySQLDriver) RunTx(ctx context.Context, f migrate.TxMigrationFunc[TxWrapper]) error {
+	if driver.out != nil {
+		return f(ctx, TxWrapper{statementPrinter{driver.out}, driver.tables})
+	}
+
 	return BeginTxFunc(
 		ctx,
 		driver.db,
@@ -164,4 +176,12 @@ func (driver *MySQLDriver) Close(_ context.Context) error {
 	return driver.db.Close()
 }
 
-var _ migrate.Driver[Wrapper, TxWrapper] = &MySQLDriver{}
+// DryRun returns a driver printing the statements of migrations to out.
+func (driver *MySQLDriver) DryRun(out io.Writer) migrate.Driver[Wrapper, TxWrapper] {
+	return &MySQLDriver{db: driver.db, tables: driver.tables, out: out}
+}
+
+var (
+	_ migrate.Driver[Wrapper, TxWrapper]       = &MySQLDriver{}
+	_ migrate.DryRunDriver[Wrapper, TxWrapper] = &MySQLDriver{}
+)
@@ -1,9 +1,13 @@
 package migrations
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
 	"fmt"
+	"io"
 	"regexp"
+	"strings"
 
 	"github.com/authzed/spicedb/pkg/migrate"
 )
@@ -22,22 +26,56 @@ var (
 
 // Wrapper makes it possible to forward the table schema needed for MySQL MigrationFunc to run
 type Wrapper struct {
-	db     *sql.DB
+	db     executor
 	tables *tables
 }
 
 // TxWrapper makes it possible to forward the table schema to a transactional migration func.
 type TxWrapper struct {
-	tx     *sql.Tx
+	tx     executor
 	tables *tables
 }
 
+// executor executes the statements of migrations, which is either the database or a
+// transaction or, in dry runs, a statementPrinter.
+type executor interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// statementPrinter writes the statements of migrations to out rather than
+// executing them.
+type statementPrinter struct {
+	out io.Writer
+}
+
+func (sp statementPrinter) ExecContext(_ context.Context, query string, args ...any) (sql.Result, error) {
+	statement := strings.TrimSpace(query)
+	if !strings.HasSuffix(statement, ";") {
+		statement += ";"
+	}
+
+	if len(args) > 0 {
+		statement += fmt.Sprintf(" -- arguments: %v", args)
+	}
+
+	if _, err := fmt.Fprintln(sp.out, statement); err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(0), nil
+}
+
 func mustRegisterMigration(version, replaces string, up migrate.MigrationFunc[Wrapper], upTx migrate.TxMigrationFunc[TxWrapper]) {
 	if err := registerMigration(version, replaces, up, upTx); err != nil {
 		panic("failed to register migration  " + err.Error())
 	}
 }
 
+func mustRegisterDownMigration(version string, downTx migrate.TxMigrationFunc[TxWrapper]) {
+	if err := Manager.RegisterDown(version, noNonatomicMigration, downTx); err != nil {
+		panic("failed to register down migration " + err.Error())
+	}
+}
+
 func registerMigration(version, replaces string, up migrate.MigrationFunc[Wrapper], upTx migrate.TxMigrationFunc[TxWrapper]) error {
 	// validate migration names to ensure they are compatible with mysql column names
 	for _, v := range []string{version, replaces} {
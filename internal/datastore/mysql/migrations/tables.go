@@ -8,6 +8,9 @@ const (
 	tableMetadataDefault      = "mysql_metadata"
 	tableCaveatDefault        = "caveat"
 	tableRelationshipCounters = "relationship_counters"
+	tableRevisionPins         = "revision_pins"
+	tableLeases               = "leases"
+	tableInternalRecords      = "internal_records"
 )
 
 type tables struct {
@@ -18,6 +21,9 @@ type tables struct {
 	tableMetadata             string
 	tableCaveat               string
 	tableRelationshipCounters string
+	tableRevisionPins         string
+	tableLeases               string
+	tableInternalRecords      string
 }
 
 func newTables(prefix string) *tables {
@@ -29,6 +35,9 @@ func newTables(prefix string) *tables {
 		tableMetadata:             prefix + tableMetadataDefault,
 		tableCaveat:               prefix + tableCaveatDefault,
 		tableRelationshipCounters: prefix + tableRelationshipCounters,
+		tableRevisionPins:         prefix + tableRevisionPins,
+		tableLeases:               prefix + tableLeases,
+		tableInternalRecords:      prefix + tableInternalRecords,
 	}
 }
 
@@ -62,3 +71,18 @@ func (tn *tables) Caveat() string {
 func (tn *tables) RelationshipCounters() string {
 	return tn.tableRelationshipCounters
 }
+
+// RevisionPins returns the prefixed revision pins table name.
+func (tn *tables) RevisionPins() string {
+	return tn.tableRevisionPins
+}
+
+// Leases returns the prefixed leases table name.
+func (tn *tables) Leases() string {
+	return tn.tableLeases
+}
+
+// InternalRecords returns the prefixed internal records table name.
+func (tn *tables) InternalRecords() string {
+	return tn.tableInternalRecords
+}
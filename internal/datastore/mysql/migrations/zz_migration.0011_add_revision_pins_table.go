@@ -0,0 +1,22 @@
+package migrations
+
+import "fmt"
+
+// addRevisionPinsTable adds the table of the pins of revisions, whose history is not garbage
+// collected until they expire.
+func addRevisionPinsTable(t *tables) string {
+	return fmt.Sprintf(`CREATE TABLE %s (
+		pin_id VARCHAR(36) NOT NULL PRIMARY KEY,
+		revision VARCHAR(64) NOT NULL,
+		expires_at DATETIME(6) NOT NULL) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;`,
+		t.RevisionPins(),
+	)
+}
+
+func init() {
+	mustRegisterMigration("add_revision_pins_table", "add_expiration_to_relation_tuple", noNonatomicMigration,
+		newStatementBatch(
+			addRevisionPinsTable,
+		).execute,
+	)
+}
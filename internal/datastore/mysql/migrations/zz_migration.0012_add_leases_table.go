@@ -0,0 +1,31 @@
+package migrations
+
+import "fmt"
+
+// addLeasesTable adds the table of the leases held by the nodes elected to run
+// singleton background jobs, such as garbage collection.
+func addLeasesTable(t *tables) string {
+	return fmt.Sprintf(`CREATE TABLE %s (
+		name VARCHAR(64) NOT NULL PRIMARY KEY,
+		holder VARCHAR(128) NOT NULL,
+		expires_at DATETIME(6) NOT NULL) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;`,
+		t.Leases(),
+	)
+}
+
+func dropLeasesTable(t *tables) string {
+	return fmt.Sprintf(`DROP TABLE %s;`, t.Leases())
+}
+
+func init() {
+	mustRegisterMigration("add_leases_table", "add_revision_pins_table", noNonatomicMigration,
+		newStatementBatch(
+			addLeasesTable,
+		).execute,
+	)
+	mustRegisterDownMigration("add_leases_table",
+		newStatementBatch(
+			dropLeasesTable,
+		).execute,
+	)
+}
@@ -0,0 +1,33 @@
+package migrations
+
+import "fmt"
+
+// addInternalRecordsTable adds the table of the records stored by SpiceDB itself, such as the
+// journals of writes spanning several transactions. The keys are binary so that they are compared
+// bytewise when read by key prefix.
+func addInternalRecordsTable(t *tables) string {
+	return fmt.Sprintf(`CREATE TABLE %s (
+		record_key VARBINARY(512) NOT NULL PRIMARY KEY,
+		record_value LONGBLOB NOT NULL,
+		expires_at DATETIME(6) NULL,
+		INDEX ix_internal_records_expires_at (expires_at)) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;`,
+		t.InternalRecords(),
+	)
+}
+
+func dropInternalRecordsTable(t *tables) string {
+	return fmt.Sprintf(`DROP TABLE %s;`, t.InternalRecords())
+}
+
+func init() {
+	mustRegisterMigration("add_internal_records_table", "add_subject_revision_index", noNonatomicMigration,
+		newStatementBatch(
+			addInternalRecordsTable,
+		).execute,
+	)
+	mustRegisterDownMigration("add_internal_records_table",
+		newStatementBatch(
+			dropInternalRecordsTable,
+		).execute,
+	)
+}
@@ -14,6 +14,7 @@ const (
 	defaultGarbageCollectionWindow           = 24 * time.Hour
 	defaultGarbageCollectionInterval         = time.Minute * 3
 	defaultGarbageCollectionMaxOperationTime = time.Minute
+	defaultGarbageCollectionLeaseDuration    = 30 * time.Second
 	defaultMaxOpenConns                      = 20
 	defaultConnMaxIdleTime                   = 30 * time.Minute
 	defaultConnMaxLifetime                   = 30 * time.Minute
@@ -26,8 +27,10 @@ const (
 	defaultGCEnabled                         = true
 	defaultCredentialsProviderName           = ""
 	defaultFilterMaximumIDCount              = 100
+	defaultQueryShapeCacheCapacity           = 1024
 	defaultColumnOptimizationOption          = common.ColumnOptimizationOptionNone
 	defaultExpirationDisabled                = false
+	defaultIndexPlannerRefreshInterval       = 5 * time.Minute
 )
 
 type mysqlOptions struct {
@@ -35,7 +38,10 @@ type mysqlOptions struct {
 	gcWindow                    time.Duration
 	gcInterval                  time.Duration
 	gcMaxOperationTime          time.Duration
+	gcTableRetention            common.TableRetention
+	gcLeaseDuration             time.Duration
 	maxRevisionStalenessPercent float64
+	revisionQuantizationJitter  time.Duration
 	watchBufferLength           uint16
 	watchBufferWriteTimeout     time.Duration
 	tablePrefix                 string
@@ -49,9 +55,13 @@ type mysqlOptions struct {
 	gcEnabled                   bool
 	credentialsProviderName     string
 	filterMaximumIDCount        uint16
+	slowQueryThreshold          time.Duration
+	queryShapeCacheCapacity     int
+	connMaxErrors               int
 	allowedMigrations           []string
 	columnOptimizationOption    common.ColumnOptimizationOption
 	expirationDisabled          bool
+	indexPlannerRefreshInterval time.Duration
 }
 
 // Option provides the facility to configure how clients within the
@@ -63,6 +73,7 @@ func generateConfig(options []Option) (mysqlOptions, error) {
 		gcWindow:                    defaultGarbageCollectionWindow,
 		gcInterval:                  defaultGarbageCollectionInterval,
 		gcMaxOperationTime:          defaultGarbageCollectionMaxOperationTime,
+		gcLeaseDuration:             defaultGarbageCollectionLeaseDuration,
 		watchBufferLength:           defaultWatchBufferLength,
 		watchBufferWriteTimeout:     defaultWatchBufferWriteTimeout,
 		maxOpenConns:                defaultMaxOpenConns,
@@ -75,8 +86,10 @@ func generateConfig(options []Option) (mysqlOptions, error) {
 		gcEnabled:                   defaultGCEnabled,
 		credentialsProviderName:     defaultCredentialsProviderName,
 		filterMaximumIDCount:        defaultFilterMaximumIDCount,
+		queryShapeCacheCapacity:     defaultQueryShapeCacheCapacity,
 		columnOptimizationOption:    defaultColumnOptimizationOption,
 		expirationDisabled:          defaultExpirationDisabled,
+		indexPlannerRefreshInterval: defaultIndexPlannerRefreshInterval,
 	}
 
 	for _, option := range options {
@@ -137,6 +150,18 @@ func MaxRevisionStalenessPercent(stalenessPercent float64) Option {
 	}
 }
 
+// RevisionQuantizationJitter is the maximum random offset by which the boundaries of the
+// revision quantization windows of this instance are shifted, such that the optimized revisions
+// cached by the nodes of a fleet do not all expire at the same time. It adds up to the jitter to
+// the staleness of optimized revisions.
+//
+// This value defaults to 0 (disabled).
+func RevisionQuantizationJitter(jitter time.Duration) Option {
+	return func(mo *mysqlOptions) {
+		mo.revisionQuantizationJitter = jitter
+	}
+}
+
 // GCWindow is the maximum age of a passed revision that will be considered
 // valid.
 //
@@ -248,6 +273,28 @@ func GCEnabled(isGCEnabled bool) Option {
 	}
 }
 
+// RelationshipsGCRetention is how long the history of the relationships is
+// retained before being garbage collected. Retentions shorter than the GC window
+// retain the history for the GC window.
+//
+// This value defaults to the GC window.
+func RelationshipsGCRetention(retention time.Duration) Option {
+	return func(mo *mysqlOptions) {
+		mo.gcTableRetention.Relationships = retention
+	}
+}
+
+// NamespacesGCRetention is how long the history of the namespaces is retained
+// before being garbage collected. Retentions shorter than the GC window retain
+// the history for the GC window.
+//
+// This value defaults to the GC window.
+func NamespacesGCRetention(retention time.Duration) Option {
+	return func(mo *mysqlOptions) {
+		mo.gcTableRetention.Namespaces = retention
+	}
+}
+
 // GCMaxOperationTime is the maximum operation time of a garbage collection
 // pass before it times out.
 //
@@ -258,6 +305,18 @@ func GCMaxOperationTime(time time.Duration) Option {
 	}
 }
 
+// GCLeaseDuration is the duration of the lease held by the node elected to run
+// garbage collection, so that a single node of the cluster collects garbage.
+// Should the node stop renewing it, another node takes over once it expires. A
+// duration of zero disables the election, collecting garbage on every node.
+//
+// This value defaults to 30 seconds.
+func GCLeaseDuration(duration time.Duration) Option {
+	return func(mo *mysqlOptions) {
+		mo.gcLeaseDuration = duration
+	}
+}
+
 // CredentialsProviderName is the name of the CredentialsProvider implementation to use
 // for dynamically retrieving the datastore credentials at runtime
 //
@@ -271,6 +330,40 @@ func FilterMaximumIDCount(filterMaximumIDCount uint16) Option {
 	return func(mo *mysqlOptions) { mo.filterMaximumIDCount = filterMaximumIDCount }
 }
 
+// SlowQueryThreshold is the duration over which relationship queries are logged as slow, along with
+// the shape of the statement, the API method which triggered them and the revision used.
+//
+// Disabled (zero) by default.
+func SlowQueryThreshold(threshold time.Duration) Option {
+	return func(mo *mysqlOptions) { mo.slowQueryThreshold = threshold }
+}
+
+// QueryShapeCacheCapacity is the maximum number of query shapes whose generated SQL is cached, so
+// that relationship queries differing only in their values are not rebuilt. Zero disables the cache.
+//
+// This value defaults to 1024.
+func QueryShapeCacheCapacity(capacity int) Option {
+	return func(mo *mysqlOptions) { mo.queryShapeCacheCapacity = capacity }
+}
+
+// IndexPlannerRefreshInterval is the interval at which the index statistics of the relationships
+// table are reloaded, to choose between the subject and resource indexes for reverse queries.
+// Zero disables the planner, leaving the choice of index to MySQL.
+//
+// This value defaults to 5 minutes.
+func IndexPlannerRefreshInterval(interval time.Duration) Option {
+	return func(mo *mysqlOptions) { mo.indexPlannerRefreshInterval = interval }
+}
+
+// ConnMaxErrors is the maximum number of consecutive connection errors, such as network failures,
+// tolerated on a pooled connection. Connections exceeding it are closed when released to the pool
+// instead of being reused.
+//
+// Disabled (zero) by default.
+func ConnMaxErrors(maxErrors int) Option {
+	return func(mo *mysqlOptions) { mo.connMaxErrors = maxErrors }
+}
+
 // AllowedMigrations configures a set of additional migrations that will pass
 // the health check (head migration is always allowed).
 func AllowedMigrations(allowedMigrations []string) Option {
@@ -1,6 +1,8 @@
 package mysql
 
 import (
+	"fmt"
+
 	"github.com/authzed/spicedb/internal/datastore/mysql/migrations"
 
 	sq "github.com/Masterminds/squirrel"
@@ -22,6 +24,10 @@ type QueryBuilder struct {
 	DeleteCounterQuery sq.UpdateBuilder
 	UpdateCounterQuery sq.UpdateBuilder
 
+	ReadRecordsQuery   sq.SelectBuilder
+	WriteRecordsQuery  sq.InsertBuilder
+	DeleteRecordsQuery sq.DeleteBuilder
+
 	QueryRelsWithIdsQuery        sq.SelectBuilder
 	QueryRelsQuery               sq.SelectBuilder
 	DeleteRelsQuery              sq.UpdateBuilder
@@ -56,6 +62,11 @@ func NewQueryBuilder(driver *migrations.MySQLDriver) *QueryBuilder {
 	builder.DeleteCounterQuery = deleteCounter(driver.RelationshipCounters())
 	builder.UpdateCounterQuery = updateCounter(driver.RelationshipCounters())
 
+	// record builders
+	builder.ReadRecordsQuery = readRecords(driver.InternalRecords())
+	builder.WriteRecordsQuery = writeRecords(driver.InternalRecords())
+	builder.DeleteRecordsQuery = sb.Delete(driver.InternalRecords())
+
 	// tuple builders
 	builder.QueryRelsWithIdsQuery = queryRelationshipsWithIds(driver.RelationTuple())
 	builder.DeleteNamespaceRelationshipsQuery = deleteNamespaceRelationships(driver.RelationTuple())
@@ -130,6 +141,20 @@ func updateCounter(tableRelationshipCounters string) sq.UpdateBuilder {
 	return sb.Update(tableRelationshipCounters).Where(sq.Eq{colDeletedTxn: liveDeletedTxnID})
 }
 
+func readRecords(tableInternalRecords string) sq.SelectBuilder {
+	return sb.Select(colRecordKey, colRecordValue, colRecordExpiresAt).From(tableInternalRecords).OrderBy(colRecordKey)
+}
+
+func writeRecords(tableInternalRecords string) sq.InsertBuilder {
+	return sb.Insert(tableInternalRecords).
+		Columns(colRecordKey, colRecordValue, colRecordExpiresAt).
+		Suffix(fmt.Sprintf(
+			"ON DUPLICATE KEY UPDATE %[1]s = VALUES(%[1]s), %[2]s = VALUES(%[2]s)",
+			colRecordValue,
+			colRecordExpiresAt,
+		))
+}
+
 func writeNamespace(tableNamespace string) sq.InsertBuilder {
 	return sb.Insert(tableNamespace).Columns(
 		colNamespace,
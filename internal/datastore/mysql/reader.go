@@ -24,13 +24,19 @@ type mysqlReader struct {
 
 	txSource             txFactory
 	executor             common.QueryRelationshipsExecutor
-	aliveFilter          queryFilterer
+	alive                common.Predicate
 	filterMaximumIDCount uint16
 	schema               common.SchemaInformation
+	indexPlanner         *common.IndexPlanner
 }
 
 type queryFilterer func(original sq.SelectBuilder) sq.SelectBuilder
 
+// aliveFilter filters a query to the rows alive in the reader.
+func (mr *mysqlReader) aliveFilter(original sq.SelectBuilder) sq.SelectBuilder {
+	return mr.alive.Filter(original)
+}
+
 const (
 	errUnableToReadConfig        = "unable to read namespace config: %w"
 	errUnableToListNamespaces    = "unable to list namespaces: %w"
@@ -166,7 +172,7 @@ func (mr *mysqlReader) QueryRelationships(
 	opts ...options.QueryOptionsOption,
 ) (iter datastore.RelationshipIterator, err error) {
 	qBuilder, err := common.NewSchemaQueryFiltererForRelationshipsSelect(mr.schema, mr.filterMaximumIDCount).
-		WithAdditionalFilter(mr.aliveFilter).
+		WithAdditionalPredicate(mr.alive).
 		FilterWithRelationshipsFilter(filter)
 	if err != nil {
 		return nil, err
@@ -175,13 +181,32 @@ func (mr *mysqlReader) QueryRelationships(
 	return mr.executor.ExecuteQuery(ctx, qBuilder, opts...)
 }
 
+func (mr *mysqlReader) QueryRelationshipsBatch(
+	ctx context.Context,
+	filters []datastore.RelationshipsFilter,
+	opts ...options.QueryOptionsOption,
+) (datastore.TaggedRelationshipIterator, error) {
+	qBuilders := make([]common.SchemaQueryFilterer, 0, len(filters))
+	for _, filter := range filters {
+		qBuilder, err := common.NewSchemaQueryFiltererForRelationshipsSelect(mr.schema, mr.filterMaximumIDCount).
+			WithAdditionalPredicate(mr.alive).
+			FilterWithRelationshipsFilter(filter)
+		if err != nil {
+			return nil, err
+		}
+		qBuilders = append(qBuilders, qBuilder)
+	}
+
+	return mr.executor.ExecuteBatchQuery(ctx, qBuilders, opts...)
+}
+
 func (mr *mysqlReader) ReverseQueryRelationships(
 	ctx context.Context,
 	subjectsFilter datastore.SubjectsFilter,
 	opts ...options.ReverseQueryOptionsOption,
 ) (iter datastore.RelationshipIterator, err error) {
 	qBuilder, err := common.NewSchemaQueryFiltererForRelationshipsSelect(mr.schema, mr.filterMaximumIDCount).
-		WithAdditionalFilter(mr.aliveFilter).
+		WithAdditionalPredicate(mr.alive).
 		FilterWithSubjectsSelectors(subjectsFilter.AsSelector())
 	if err != nil {
 		return nil, err
@@ -195,6 +220,12 @@ func (mr *mysqlReader) ReverseQueryRelationships(
 			FilterToRelation(queryOpts.ResRelation.Relation)
 	}
 
+	if mr.indexPlanner != nil {
+		if index := mr.indexPlanner.ChooseIndex(ctx, qBuilder); index != "" {
+			qBuilder = qBuilder.WithIndexHint(" FORCE INDEX (" + index + ")")
+		}
+	}
+
 	return mr.executor.ExecuteQuery(
 		ctx,
 		qBuilder,
@@ -0,0 +1,85 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"github.com/authzed/spicedb/internal/datastore/common"
+	"github.com/authzed/spicedb/pkg/datastore"
+)
+
+func (rwt *mysqlReadWriteTXN) ReadRecords(ctx context.Context, keyPrefix string) ([]datastore.Record, error) {
+	query, args, err := rwt.ReadRecordsQuery.
+		Where(sq.Like{colRecordKey: common.LikePrefixPattern(keyPrefix)}).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := rwt.tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read records: %w", err)
+	}
+	defer common.LogOnError(ctx, rows.Close)
+
+	var records []datastore.Record
+	for rows.Next() {
+		var record datastore.Record
+		var expiresAt sql.NullTime
+		if err := rows.Scan(&record.Key, &record.Value, &expiresAt); err != nil {
+			return nil, fmt.Errorf("failed to read records: %w", err)
+		}
+		if expiresAt.Valid {
+			record.ExpiresAt = expiresAt.Time
+		}
+		records = append(records, record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read records: %w", err)
+	}
+	return records, nil
+}
+
+func (rwt *mysqlReadWriteTXN) WriteRecords(ctx context.Context, records ...datastore.Record) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	builder := rwt.WriteRecordsQuery
+	for _, record := range records {
+		var expiresAt sql.NullTime
+		if !record.ExpiresAt.IsZero() {
+			expiresAt = sql.NullTime{Time: record.ExpiresAt.UTC(), Valid: true}
+		}
+		builder = builder.Values(record.Key, record.Value, expiresAt)
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return err
+	}
+
+	if _, err := rwt.tx.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to write records: %w", err)
+	}
+	return nil
+}
+
+func (rwt *mysqlReadWriteTXN) DeleteRecords(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	query, args, err := rwt.DeleteRecordsQuery.Where(sq.Eq{colRecordKey: keys}).ToSql()
+	if err != nil {
+		return err
+	}
+
+	if _, err := rwt.tx.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to delete records: %w", err)
+	}
+	return nil
+}
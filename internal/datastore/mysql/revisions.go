@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	sq "github.com/Masterminds/squirrel"
 	"time"
 
 	"github.com/ccoveille/go-safecast"
@@ -66,10 +67,34 @@ const (
 		) as unknown;`
 )
 
+// selectRevisionQuery returns the query selecting the optimized revision for the quantization.
+func selectRevisionQuery(transactionTable string, quantization time.Duration) string {
+	quantizationPeriodNanos := max(quantization.Nanoseconds(), 1)
+	return fmt.Sprintf(
+		querySelectRevision,
+		colID,
+		transactionTable,
+		colTimestamp,
+		quantizationPeriodNanos,
+	)
+}
+
+// SetRevisionQuantization changes the quantization of the optimized revisions.
+func (mds *Datastore) SetRevisionQuantization(quantization time.Duration) error {
+	if quantization >= mds.gcWindow {
+		return fmt.Errorf(errQuantizationTooLarge, quantization, mds.gcWindow)
+	}
+
+	revisionQuery := selectRevisionQuery(mds.driver.RelationTupleTransaction(), quantization)
+	mds.optimizedRevisionQuery.Store(&revisionQuery)
+	mds.SetMaxRevisionStaleness(time.Duration(float64(quantization.Nanoseconds())*mds.maxStalenessPercent) * time.Nanosecond)
+	return nil
+}
+
 func (mds *Datastore) optimizedRevisionFunc(ctx context.Context) (datastore.Revision, time.Duration, error) {
 	var rev uint64
 	var validForNanos time.Duration
-	if err := mds.db.QueryRowContext(ctx, mds.optimizedRevisionQuery).
+	if err := mds.db.QueryRowContext(ctx, *mds.optimizedRevisionQuery.Load()).
 		Scan(&rev, &validForNanos); err != nil {
 		return datastore.NoRevision, 0, fmt.Errorf(errRevision, err)
 	}
@@ -193,3 +218,26 @@ func (mds *Datastore) createNewTransaction(ctx context.Context, tx *sql.Tx, meta
 
 	return uintLastInsertID, nil
 }
+
+func (mds *Datastore) RevisionAtTime(ctx context.Context, at time.Time) (datastore.Revision, error) {
+	query, args, err := mds.GetLastRevision.Where(sq.LtOrEq{colTimestamp: at.UTC()}).ToSql()
+	if err != nil {
+		return datastore.NoRevision, err
+	}
+
+	var value sql.NullInt64
+	if err := mds.db.QueryRowContext(ctx, query, args...).Scan(&value); err != nil {
+		return datastore.NoRevision, fmt.Errorf(errRevision, err)
+	}
+
+	if !value.Valid {
+		return datastore.NoRevision, datastore.NewInvalidRevisionErr(datastore.NoRevision, datastore.RevisionStale)
+	}
+
+	uintValue, err := safecast.ToUint64(value.Int64)
+	if err != nil {
+		return datastore.NoRevision, spiceerrors.MustBugf("value could not be cast to uint64: %v", err)
+	}
+
+	return revisions.NewForTransactionID(uintValue), nil
+}
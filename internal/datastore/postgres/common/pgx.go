@@ -27,6 +27,14 @@ func NewPGXQueryRelationshipsExecutor(querier DBFuncQuerier) common.ExecuteReadR
 	}
 }
 
+// NewPGXQueryRelationshipsBatchExecutor creates an executor that uses the pgx library to make the
+// batched queries combining multiple relationship queries.
+func NewPGXQueryRelationshipsBatchExecutor(querier DBFuncQuerier) common.ExecuteBatchReadRelsQueryFunc {
+	return func(ctx context.Context, builder common.RelationshipsQueryBuilder) (datastore.TaggedRelationshipIterator, error) {
+		return common.QueryTaggedRelationships[pgx.Rows, map[string]any](ctx, builder, querier)
+	}
+}
+
 // ParseConfigWithInstrumentation returns a pgx.ConnConfig that has been instrumented for observability
 func ParseConfigWithInstrumentation(url string) (*pgx.ConnConfig, error) {
 	connConfig, err := pgx.ParseConfig(url)
@@ -200,6 +208,24 @@ func (m *ComposedTracer) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data
 	}
 }
 
+func (m *ComposedTracer) TracePrepareStart(ctx context.Context, conn *pgx.Conn, data pgx.TracePrepareStartData) context.Context {
+	for _, t := range m.Tracers {
+		if pt, ok := t.(pgx.PrepareTracer); ok {
+			ctx = pt.TracePrepareStart(ctx, conn, data)
+		}
+	}
+
+	return ctx
+}
+
+func (m *ComposedTracer) TracePrepareEnd(ctx context.Context, conn *pgx.Conn, data pgx.TracePrepareEndData) {
+	for _, t := range m.Tracers {
+		if pt, ok := t.(pgx.PrepareTracer); ok {
+			pt.TracePrepareEnd(ctx, conn, data)
+		}
+	}
+}
+
 // DBFuncQuerier is satisfied by RetryPool and QuerierFuncs (which can wrap a pgxpool or transaction)
 type DBFuncQuerier interface {
 	ExecFunc(ctx context.Context, tagFunc func(ctx context.Context, tag pgconn.CommandTag, err error) error, sql string, arguments ...any) error
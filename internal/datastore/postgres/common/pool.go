@@ -0,0 +1,119 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/authzed/spicedb/internal/datastore/common"
+)
+
+// connErrorClasses are the SQLSTATE classes of errors which indicate a problem with the connection
+// or the server it is connected to, rather than with the statement executed.
+var connErrorClasses = map[string]struct{}{
+	"08": {}, // connection exception
+	"53": {}, // insufficient resources
+	"57": {}, // operator intervention
+	"58": {}, // system error
+	"XX": {}, // internal error
+}
+
+// IsConnectionError returns whether the error indicates a problem with the connection on which it
+// was returned, rather than with the statement executed.
+func IsConnectionError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var pgerr *pgconn.PgError
+	if errors.As(err, &pgerr) {
+		if len(pgerr.Code) < 2 {
+			return false
+		}
+		_, ok := connErrorClasses[pgerr.Code[:2]]
+		return ok
+	}
+
+	var connectErr *pgconn.ConnectError
+	var netErr net.Error
+	return errors.As(err, &connectErr) || errors.As(err, &netErr) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// connErrorTracer records the outcome of each query in a ConnErrorTracker.
+type connErrorTracer struct {
+	tracker *common.ConnErrorTracker[*pgx.Conn]
+}
+
+func (t *connErrorTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, _ pgx.TraceQueryStartData) context.Context {
+	return ctx
+}
+
+func (t *connErrorTracer) TraceQueryEnd(_ context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
+	if errors.Is(data.Err, context.Canceled) || errors.Is(data.Err, context.DeadlineExceeded) {
+		return
+	}
+
+	if IsConnectionError(data.Err) {
+		t.tracker.RecordResult(conn, data.Err)
+		return
+	}
+
+	t.tracker.RecordResult(conn, nil)
+}
+
+// ConfigureConnErrorPruning configures a pgx connection pool to close connections which returned
+// more consecutive connection errors than allowed by the tracker, when they are released back to
+// the pool. Does nothing if the tracker is nil.
+func ConfigureConnErrorPruning(pgxConfig *pgxpool.Config, tracker *common.ConnErrorTracker[*pgx.Conn]) {
+	if tracker == nil {
+		return
+	}
+
+	addTracer(pgxConfig.ConnConfig, &connErrorTracer{tracker})
+
+	afterRelease := pgxConfig.AfterRelease
+	pgxConfig.AfterRelease = func(conn *pgx.Conn) bool {
+		if afterRelease != nil && !afterRelease(conn) {
+			return false
+		}
+		return !tracker.ShouldPrune(conn)
+	}
+
+	beforeClose := pgxConfig.BeforeClose
+	pgxConfig.BeforeClose = func(conn *pgx.Conn) {
+		if beforeClose != nil {
+			beforeClose(conn)
+		}
+		tracker.Forget(conn)
+	}
+}
+
+// StatPool is satisfied by pgxpool.Pool and the CockroachDB RetryPool.
+type StatPool interface {
+	Stat() *pgxpool.Stat
+}
+
+// PoolStatsFunc returns a function producing the statistics of a pgx connection pool in the
+// format common to all SQL drivers. The wait duration reported is the total time spent acquiring
+// connections.
+func PoolStatsFunc(pool StatPool, tracker *common.ConnErrorTracker[*pgx.Conn]) func() common.PoolStats {
+	return func() common.PoolStats {
+		stat := pool.Stat()
+		return common.PoolStats{
+			MaxConns:          int64(stat.MaxConns()),
+			InUseConns:        int64(stat.AcquiredConns()),
+			IdleConns:         int64(stat.IdleConns()),
+			WaitCount:         stat.EmptyAcquireCount(),
+			WaitDuration:      stat.AcquireDuration(),
+			MaxLifetimeClosed: stat.MaxLifetimeDestroyCount(),
+			MaxIdleClosed:     stat.MaxIdleDestroyCount(),
+			ErrorPruned:       tracker.PrunedCount(),
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// NewStatementPrinter returns a QueryInterceptor writing the statements executed
+// through Exec to out instead of executing them, such that the statements of
+// migrations can be reviewed before being applied. Queries are still executed,
+// as they only read from the database.
+func NewStatementPrinter(out io.Writer) QueryInterceptor {
+	return statementPrinter{out: out}
+}
+
+type statementPrinter struct {
+	out io.Writer
+}
+
+func (sp statementPrinter) InterceptExec(_ context.Context, _ Querier, sql string, arguments ...any) (pgconn.CommandTag, error) {
+	statement := strings.TrimSpace(sql)
+	if !strings.HasSuffix(statement, ";") {
+		statement += ";"
+	}
+
+	if len(arguments) > 0 {
+		statement += fmt.Sprintf(" -- arguments: %v", arguments)
+	}
+
+	if _, err := fmt.Fprintln(sp.out, statement); err != nil {
+		return pgconn.CommandTag{}, err
+	}
+	return pgconn.CommandTag{}, nil
+}
+
+func (sp statementPrinter) InterceptQuery(ctx context.Context, delegate Querier, sql string, args ...any) (pgx.Rows, error) {
+	return delegate.Query(ctx, sql, args...)
+}
+
+func (sp statementPrinter) InterceptQueryRow(ctx context.Context, delegate Querier, sql string, optionsAndArgs ...any) pgx.Row {
+	return delegate.QueryRow(ctx, sql, optionsAndArgs...)
+}
+
+// NewInterceptorQuerier returns a Querier whose methods are intercepted by the
+// given interceptor.
+func NewInterceptorQuerier(delegate Querier, interceptor QueryInterceptor) Querier {
+	return newQuerierInterceptor(delegate, interceptor)
+}
+
+// NewInterceptorTx returns a transaction whose queries are intercepted by the
+// given interceptor.
+func NewInterceptorTx(tx pgx.Tx, interceptor QueryInterceptor) pgx.Tx {
+	return newTxInterceptor(interceptor)(tx)
+}
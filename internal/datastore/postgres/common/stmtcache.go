@@ -0,0 +1,141 @@
+package common
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	statementCacheHit  = "hit"
+	statementCacheMiss = "miss"
+)
+
+var preparedStatementCacheCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "spicedb",
+	Subsystem: "datastore",
+	Name:      "prepared_statement_cache_total",
+	Help:      "number of queries executed with a cached prepared statement, by whether the statement was already prepared on the connection.",
+}, []string{"pool", "result"})
+
+func init() {
+	prometheus.MustRegister(preparedStatementCacheCounter)
+}
+
+// ConfigureStatementCache enables the cache of prepared statements of each connection of the
+// pool, holding up to capacity statements, and records the hits and misses of the cache under
+// the given pool name.
+//
+// Statements are only prepared and cached for the queries made through a querier returned by
+// WithStatementCache: all other queries keep the default execution mode of the pool.
+func ConfigureStatementCache(pgxConfig *pgxpool.Config, poolName string, capacity int) {
+	pgxConfig.ConnConfig.StatementCacheCapacity = capacity
+	addTracer(pgxConfig.ConnConfig, &statementCacheTracer{
+		poolName:    poolName,
+		defaultMode: pgxConfig.ConnConfig.DefaultQueryExecMode,
+	})
+}
+
+// WithStatementCache returns a querier that executes its queries as prepared statements, which
+// are cached on each connection keyed by the SQL of the query. As the SQL holds placeholders
+// for all of the values of the query, queries of the same shape share a prepared statement.
+//
+// An execution mode given explicitly by a wrapping querier, such as the simple protocol of
+// strict read mode, takes precedence.
+func WithStatementCache(querier DBFuncQuerier) DBFuncQuerier {
+	return statementCachingQuerier{wrapped: querier}
+}
+
+type statementCachingQuerier struct {
+	wrapped DBFuncQuerier
+}
+
+func (scq statementCachingQuerier) ExecFunc(ctx context.Context, tagFunc func(ctx context.Context, tag pgconn.CommandTag, err error) error, sql string, arguments ...any) error {
+	return scq.wrapped.ExecFunc(ctx, tagFunc, sql, withCacheStatementMode(arguments)...)
+}
+
+func (scq statementCachingQuerier) QueryFunc(ctx context.Context, rowsFunc func(ctx context.Context, rows pgx.Rows) error, sql string, optionsAndArgs ...any) error {
+	return scq.wrapped.QueryFunc(ctx, rowsFunc, sql, withCacheStatementMode(optionsAndArgs)...)
+}
+
+func (scq statementCachingQuerier) QueryRowFunc(ctx context.Context, rowFunc func(ctx context.Context, row pgx.Row) error, sql string, optionsAndArgs ...any) error {
+	return scq.wrapped.QueryRowFunc(ctx, rowFunc, sql, withCacheStatementMode(optionsAndArgs)...)
+}
+
+// withCacheStatementMode prepends the cache statement execution mode to the arguments. pgx
+// applies the last execution mode given, so one already in the arguments takes precedence.
+func withCacheStatementMode(optionsAndArgs []any) []any {
+	return append([]any{pgx.QueryExecModeCacheStatement}, optionsAndArgs...)
+}
+
+type statementCacheLookupKey struct{}
+
+// statementCacheLookup records whether a query executed in the cache statement mode had to
+// prepare its statement.
+type statementCacheLookup struct {
+	prepared bool
+}
+
+// statementCacheTracer counts the hits and misses of the statement cache of the connections of a
+// pool. A query executed in the cache statement mode is a miss if pgx prepares a named statement
+// while executing it, and a hit otherwise.
+type statementCacheTracer struct {
+	poolName    string
+	defaultMode pgx.QueryExecMode
+}
+
+func (sct *statementCacheTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	if queryExecMode(sct.defaultMode, data.Args) != pgx.QueryExecModeCacheStatement {
+		return ctx
+	}
+
+	return context.WithValue(ctx, statementCacheLookupKey{}, &statementCacheLookup{})
+}
+
+func (sct *statementCacheTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	lookup, ok := ctx.Value(statementCacheLookupKey{}).(*statementCacheLookup)
+	if !ok {
+		return
+	}
+
+	result := statementCacheHit
+	if lookup.prepared {
+		result = statementCacheMiss
+	}
+	preparedStatementCacheCounter.WithLabelValues(sct.poolName, result).Inc()
+}
+
+func (sct *statementCacheTracer) TracePrepareStart(ctx context.Context, _ *pgx.Conn, data pgx.TracePrepareStartData) context.Context {
+	// Unnamed statements are prepared to describe queries, and are never cached.
+	if lookup, ok := ctx.Value(statementCacheLookupKey{}).(*statementCacheLookup); ok && data.Name != "" {
+		lookup.prepared = true
+	}
+	return ctx
+}
+
+func (sct *statementCacheTracer) TracePrepareEnd(context.Context, *pgx.Conn, pgx.TracePrepareEndData) {
+}
+
+// queryExecMode returns the execution mode of a query given its arguments, in which pgx
+// expects options to precede the arguments.
+func queryExecMode(defaultMode pgx.QueryExecMode, optionsAndArgs []any) pgx.QueryExecMode {
+	mode := defaultMode
+	for _, arg := range optionsAndArgs {
+		switch arg := arg.(type) {
+		case pgx.QueryExecMode:
+			mode = arg
+		case pgx.QueryResultFormats, pgx.QueryResultFormatsByOID, pgx.QueryRewriter:
+		default:
+			return mode
+		}
+	}
+	return mode
+}
+
+var (
+	_ pgx.QueryTracer   = (*statementCacheTracer)(nil)
+	_ pgx.PrepareTracer = (*statementCacheTracer)(nil)
+)
@@ -0,0 +1,100 @@
+package common
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryExecMode(t *testing.T) {
+	tcs := []struct {
+		name           string
+		optionsAndArgs []any
+		expected       pgx.QueryExecMode
+	}{
+		{"no arguments", nil, pgx.QueryExecModeExec},
+		{"only arguments", []any{"foo", 42}, pgx.QueryExecModeExec},
+		{"cache statement", withCacheStatementMode([]any{"foo"}), pgx.QueryExecModeCacheStatement},
+		{"explicit mode takes precedence", withCacheStatementMode([]any{pgx.QueryExecModeSimpleProtocol, "foo"}), pgx.QueryExecModeSimpleProtocol},
+		{"mode after other options", []any{pgx.QueryResultFormats{1}, pgx.QueryExecModeCacheStatement, "foo"}, pgx.QueryExecModeCacheStatement},
+		{"mode as an argument", []any{"foo", pgx.QueryExecModeCacheStatement}, pgx.QueryExecModeExec},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, queryExecMode(pgx.QueryExecModeExec, tc.optionsAndArgs))
+		})
+	}
+}
+
+func TestStatementCacheTracer(t *testing.T) {
+	tracer := &statementCacheTracer{poolName: "test", defaultMode: pgx.QueryExecModeExec}
+	hits := preparedStatementCacheCounter.WithLabelValues("test", statementCacheHit)
+	misses := preparedStatementCacheCounter.WithLabelValues("test", statementCacheMiss)
+
+	runQuery := func(args []any, prepareName string) {
+		ctx := tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: "SELECT 1", Args: args})
+		if prepareName != "" {
+			ctx = tracer.TracePrepareStart(ctx, nil, pgx.TracePrepareStartData{Name: prepareName, SQL: "SELECT 1"})
+			tracer.TracePrepareEnd(ctx, nil, pgx.TracePrepareEndData{})
+		}
+		tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{CommandTag: pgconn.NewCommandTag("SELECT 1")})
+	}
+
+	// Queries in other execution modes are not counted.
+	runQuery([]any{"foo"}, "")
+	runQuery([]any{pgx.QueryExecModeDescribeExec, "foo"}, "")
+	require.Equal(t, 0.0, testutil.ToFloat64(hits))
+	require.Equal(t, 0.0, testutil.ToFloat64(misses))
+
+	// A query preparing its named statement is a miss.
+	runQuery(withCacheStatementMode([]any{"foo"}), "stmtcache_1")
+	require.Equal(t, 0.0, testutil.ToFloat64(hits))
+	require.Equal(t, 1.0, testutil.ToFloat64(misses))
+
+	// A query reusing its statement is a hit.
+	runQuery(withCacheStatementMode([]any{"foo"}), "")
+	runQuery(withCacheStatementMode([]any{"bar"}), "")
+	require.Equal(t, 2.0, testutil.ToFloat64(hits))
+	require.Equal(t, 1.0, testutil.ToFloat64(misses))
+}
+
+func TestStatementCachingQuerier(t *testing.T) {
+	recording := &recordingQuerier{}
+	querier := WithStatementCache(recording)
+
+	err := querier.QueryFunc(context.Background(), nil, "SELECT 1", "foo")
+	require.NoError(t, err)
+	require.Equal(t, []any{pgx.QueryExecModeCacheStatement, "foo"}, recording.optionsAndArgs)
+
+	err = querier.QueryRowFunc(context.Background(), nil, "SELECT 1", "bar")
+	require.NoError(t, err)
+	require.Equal(t, []any{pgx.QueryExecModeCacheStatement, "bar"}, recording.optionsAndArgs)
+
+	err = querier.ExecFunc(context.Background(), nil, "SELECT 1")
+	require.NoError(t, err)
+	require.Equal(t, []any{pgx.QueryExecModeCacheStatement}, recording.optionsAndArgs)
+}
+
+type recordingQuerier struct {
+	optionsAndArgs []any
+}
+
+func (rq *recordingQuerier) ExecFunc(_ context.Context, _ func(ctx context.Context, tag pgconn.CommandTag, err error) error, _ string, arguments ...any) error {
+	rq.optionsAndArgs = arguments
+	return nil
+}
+
+func (rq *recordingQuerier) QueryFunc(_ context.Context, _ func(ctx context.Context, rows pgx.Rows) error, _ string, optionsAndArgs ...any) error {
+	rq.optionsAndArgs = optionsAndArgs
+	return nil
+}
+
+func (rq *recordingQuerier) QueryRowFunc(_ context.Context, _ func(ctx context.Context, row pgx.Row) error, _ string, optionsAndArgs ...any) error {
+	rq.optionsAndArgs = optionsAndArgs
+	return nil
+}
@@ -7,13 +7,15 @@ import (
 	"time"
 
 	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
 
 	"github.com/authzed/spicedb/internal/datastore/common"
 	"github.com/authzed/spicedb/pkg/datastore"
 )
 
 var (
-	_ common.GarbageCollector = (*pgDatastore)(nil)
+	_ common.GarbageCollector  = (*pgDatastore)(nil)
+	_ datastore.RevisionPinner = (*pgDatastore)(nil)
 
 	// we are using "tableoid" to globally identify the row through the "ctid" in partitioned environments
 	// as it's not guaranteed 2 rows in different partitions have different "ctid" values
@@ -94,10 +96,21 @@ func (pgd *pgDatastore) DeleteExpiredRels(ctx context.Context) (int64, error) {
 	)
 }
 
-func (pgd *pgDatastore) DeleteBeforeTx(ctx context.Context, txID datastore.Revision) (common.DeletionCounts, error) {
-	revision := txID.(postgresRevision)
+func (pgd *pgDatastore) DeleteExpiredRecords(ctx context.Context) (int64, error) {
+	now, err := pgd.Now(ctx)
+	if err != nil {
+		return 0, err
+	}
 
-	minTxAlive := newXid8(revision.snapshot.xmin)
+	return pgd.batchDelete(
+		ctx,
+		tableInternalRecord,
+		[]string{colRecordKey},
+		sq.LtOrEq{colRecordExpiresAt: now},
+	)
+}
+
+func (pgd *pgDatastore) DeleteBeforeTx(ctx context.Context, watermarks common.Watermarks) (common.DeletionCounts, error) {
 	removed := common.DeletionCounts{}
 	var err error
 	// Delete any relationship rows that were already dead when this transaction started
@@ -105,7 +118,7 @@ func (pgd *pgDatastore) DeleteBeforeTx(ctx context.Context, txID datastore.Revis
 		ctx,
 		tableTuple,
 		gcPKCols,
-		sq.Lt{colDeletedXid: minTxAlive},
+		sq.Lt{colDeletedXid: minTxAlive(watermarks.Relationships)},
 	)
 	if err != nil {
 		return removed, fmt.Errorf("failed to GC relationships table: %w", err)
@@ -119,7 +132,7 @@ func (pgd *pgDatastore) DeleteBeforeTx(ctx context.Context, txID datastore.Revis
 		ctx,
 		tableTransaction,
 		gcPKCols,
-		sq.Lt{colXID: minTxAlive},
+		sq.Lt{colXID: minTxAlive(watermarks.Transactions)},
 	)
 	if err != nil {
 		return removed, fmt.Errorf("failed to GC transactions table: %w", err)
@@ -130,7 +143,7 @@ func (pgd *pgDatastore) DeleteBeforeTx(ctx context.Context, txID datastore.Revis
 		ctx,
 		tableNamespace,
 		gcPKCols,
-		sq.Lt{colDeletedXid: minTxAlive},
+		sq.Lt{colDeletedXid: minTxAlive(watermarks.Namespaces)},
 	)
 	if err != nil {
 		return removed, fmt.Errorf("failed to GC namespaces table: %w", err)
@@ -139,6 +152,79 @@ func (pgd *pgDatastore) DeleteBeforeTx(ctx context.Context, txID datastore.Revis
 	return removed, err
 }
 
+func minTxAlive(watermark datastore.Revision) xid8 {
+	return newXid8(watermark.(postgresRevision).snapshot.xmin)
+}
+
+func (pgd *pgDatastore) TableRetention() common.TableRetention {
+	return pgd.gcTableRetention
+}
+
+func (pgd *pgDatastore) PinnedRevisions(ctx context.Context) ([]datastore.Revision, error) {
+	now, err := pgd.Now(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sql, args, err := psql.Select(colPinRevision).From(tableRevisionPin).Where(sq.Gt{colPinExpiresAt: now}).ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := pgd.readPool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pinned []datastore.Revision
+	for rows.Next() {
+		var revisionStr string
+		if err := rows.Scan(&revisionStr); err != nil {
+			return nil, err
+		}
+
+		revision, err := ParseRevisionString(revisionStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pinned revision: %w", err)
+		}
+		pinned = append(pinned, revision)
+	}
+	return pinned, rows.Err()
+}
+
+func (pgd *pgDatastore) PinRevision(ctx context.Context, revision datastore.Revision, expiresAt time.Time) (string, error) {
+	if _, ok := revision.(postgresRevision); !ok {
+		return "", fmt.Errorf("expected postgres revision, found %T", revision)
+	}
+
+	pinID := uuid.NewString()
+	sql, args, err := psql.Insert(tableRevisionPin).
+		Columns(colPinID, colPinRevision, colPinExpiresAt).
+		Values(pinID, revision.String(), expiresAt.UTC()).
+		ToSql()
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := pgd.writePool.Exec(ctx, sql, args...); err != nil {
+		return "", fmt.Errorf("failed to pin revision: %w", err)
+	}
+	return pinID, nil
+}
+
+func (pgd *pgDatastore) UnpinRevision(ctx context.Context, pinID string) error {
+	sql, args, err := psql.Delete(tableRevisionPin).Where(sq.Eq{colPinID: pinID}).ToSql()
+	if err != nil {
+		return err
+	}
+
+	if _, err := pgd.writePool.Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("failed to unpin revision: %w", err)
+	}
+	return nil
+}
+
 func (pgd *pgDatastore) batchDelete(
 	ctx context.Context,
 	tableName string,
@@ -0,0 +1,61 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"github.com/authzed/spicedb/internal/datastore/common"
+)
+
+var _ common.LeaseManager = (*pgDatastore)(nil)
+
+// acquireLeaseSuffix updates the lease on conflict only if it is already held by
+// the holder, or has expired by the time given as its parameter.
+var acquireLeaseSuffix = fmt.Sprintf(
+	"ON CONFLICT (%[1]s) DO UPDATE SET %[2]s = EXCLUDED.%[2]s, %[3]s = EXCLUDED.%[3]s WHERE %[4]s.%[2]s = EXCLUDED.%[2]s OR %[4]s.%[3]s <= ?",
+	colLeaseName,
+	colLeaseHolder,
+	colLeaseExpiresAt,
+	tableLease,
+)
+
+func (pgd *pgDatastore) TryAcquireLease(ctx context.Context, name, holder string, duration time.Duration) (bool, error) {
+	// The expiration of leases is measured by the database, so that the nodes
+	// agree on it regardless of the skew of their clocks.
+	now, err := pgd.Now(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	sql, args, err := psql.Insert(tableLease).
+		Columns(colLeaseName, colLeaseHolder, colLeaseExpiresAt).
+		Values(name, holder, now.Add(duration)).
+		Suffix(acquireLeaseSuffix, now).
+		ToSql()
+	if err != nil {
+		return false, err
+	}
+
+	result, err := pgd.writePool.Exec(ctx, sql, args...)
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire lease: %w", err)
+	}
+	return result.RowsAffected() == 1, nil
+}
+
+func (pgd *pgDatastore) ReleaseLease(ctx context.Context, name, holder string) error {
+	sql, args, err := psql.Delete(tableLease).
+		Where(sq.Eq{colLeaseName: name, colLeaseHolder: holder}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	if _, err := pgd.writePool.Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("failed to release lease: %w", err)
+	}
+	return nil
+}
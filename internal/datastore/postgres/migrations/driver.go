@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
@@ -26,6 +27,10 @@ var tracer = otel.Tracer("spicedb/internal/datastore/common")
 // It is compatible with the popular Python library, Alembic
 type AlembicPostgresDriver struct {
 	db *pgx.Conn
+
+	// printer is set in dry runs, in which the statements modifying the database
+	// are printed rather than executed.
+	printer pgxcommon.QueryInterceptor
 }
 
 // NewAlembicPostgresDriver creates a new driver with active connections to the database specified.
@@ -53,15 +58,29 @@ func NewAlembicPostgresDriver(ctx context.Context, url string, credentialsProvid
 		return nil, err
 	}
 
-	return &AlembicPostgresDriver{db}, nil
+	return &AlembicPostgresDriver{db: db}, nil
 }
 
-// Conn returns the underlying pgx.Conn instance for this driver
-func (apd *AlembicPostgresDriver) Conn() *pgx.Conn {
+// Conn returns the underlying pgx.Conn instance for this driver, which prints
+// the statements it executes in dry runs.
+func (apd *AlembicPostgresDriver) Conn() pgxcommon.Querier {
+	if apd.printer != nil {
+		return pgxcommon.NewInterceptorQuerier(apd.db, apd.printer)
+	}
 	return apd.db
 }
 
 func (apd *AlembicPostgresDriver) RunTx(ctx context.Context, f migrate.TxMigrationFunc[pgx.Tx]) error {
+	if apd.printer != nil {
+		tx, err := apd.db.Begin(ctx)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = tx.Rollback(ctx) }()
+
+		return f(ctx, pgxcommon.NewInterceptorTx(tx, apd.printer))
+	}
+
 	return pgx.BeginFunc(ctx, apd.db, func(tx pgx.Tx) error {
 		return f(ctx, tx)
 	})
@@ -99,12 +118,21 @@ func (apd *AlembicPostgresDriver) WriteVersion(ctx context.Context, tx pgx.Tx, v
 		return fmt.Errorf("unable to update version row: %w", err)
 	}
 
+	// Statements are not executed in dry runs.
 	updatedCount := result.RowsAffected()
-	if updatedCount != 1 {
+	if apd.printer == nil && updatedCount != 1 {
 		return fmt.Errorf("writing version update affected %d rows, should be 1", updatedCount)
 	}
 
 	return nil
 }
 
-var _ migrate.Driver[*pgx.Conn, pgx.Tx] = &AlembicPostgresDriver{}
+// DryRun returns a driver printing the statements of migrations to out.
+func (apd *AlembicPostgresDriver) DryRun(out io.Writer) migrate.Driver[pgxcommon.Querier, pgx.Tx] {
+	return &AlembicPostgresDriver{db: apd.db, printer: pgxcommon.NewStatementPrinter(out)}
+}
+
+var (
+	_ migrate.Driver[pgxcommon.Querier, pgx.Tx]       = &AlembicPostgresDriver{}
+	_ migrate.DryRunDriver[pgxcommon.Querier, pgx.Tx] = &AlembicPostgresDriver{}
+)
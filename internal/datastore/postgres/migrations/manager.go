@@ -3,13 +3,14 @@ package migrations
 import (
 	"github.com/jackc/pgx/v5"
 
+	pgxcommon "github.com/authzed/spicedb/internal/datastore/postgres/common"
 	"github.com/authzed/spicedb/pkg/migrate"
 )
 
 var (
-	noNonatomicMigration migrate.MigrationFunc[*pgx.Conn]
+	noNonatomicMigration migrate.MigrationFunc[pgxcommon.Querier]
 	noTxMigration        migrate.TxMigrationFunc[pgx.Tx]
 )
 
 // DatabaseMigrations implements a migration manager for the Postgres Driver.
-var DatabaseMigrations = migrate.NewManager[*AlembicPostgresDriver, *pgx.Conn, pgx.Tx]()
+var DatabaseMigrations = migrate.NewManager[*AlembicPostgresDriver, pgxcommon.Querier, pgx.Tx]()
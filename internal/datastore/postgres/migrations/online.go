@@ -0,0 +1,88 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ccoveille/go-safecast"
+	"github.com/jackc/pgx/v5"
+
+	pgxcommon "github.com/authzed/spicedb/internal/datastore/postgres/common"
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/pkg/migrate"
+)
+
+const (
+	// queryIndexValid returns whether the index is valid, and no row if it does not exist.
+	queryIndexValid = `SELECT indisvalid FROM pg_index WHERE indexrelid = to_regclass($1);`
+
+	// queryEstimatedRows returns the number of rows of a table estimated by its last
+	// vacuum or analyze, which is negative if it was never analyzed.
+	queryEstimatedRows = `SELECT GREATEST(reltuples, 0)::bigint FROM pg_class WHERE oid = to_regclass($1);`
+
+	dropInvalidIndex = `DROP INDEX CONCURRENTLY IF EXISTS %s;`
+)
+
+// createIndexConcurrently runs a CREATE INDEX CONCURRENTLY IF NOT EXISTS statement building
+// the named index on the table, which does not block writes to it, and reports its progress.
+//
+// A concurrent build which fails or is interrupted, e.g. by the migration timeout, leaves an
+// invalid index behind, which IF NOT EXISTS would then keep. Such an index is dropped first,
+// such that running the migration again resumes it by building the index again.
+func createIndexConcurrently(ctx context.Context, conn pgxcommon.Querier, table, index, createStmt string) error {
+	var valid bool
+	err := conn.QueryRow(ctx, queryIndexValid, index).Scan(&valid)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+	case err != nil:
+		return fmt.Errorf("unable to check for existing index %s: %w", index, err)
+	case !valid:
+		log.Ctx(ctx).Warn().Str("index", index).Msg("dropping invalid index left by an interrupted concurrent build")
+		if _, err := conn.Exec(ctx, fmt.Sprintf(dropInvalidIndex, index)); err != nil {
+			return fmt.Errorf("unable to drop invalid index %s: %w", index, err)
+		}
+	}
+
+	total, err := estimatedRows(ctx, conn, table)
+	if err != nil {
+		return err
+	}
+
+	step := fmt.Sprintf("build index %s on %s", index, table)
+	migrate.ReportProgress(ctx, migrate.Progress{Step: step, Total: total})
+	if _, err := conn.Exec(ctx, createStmt); err != nil {
+		return err
+	}
+	migrate.ReportProgress(ctx, migrate.Progress{Step: step, Done: total, Total: total, Completed: true})
+
+	return nil
+}
+
+// backfillInBatches runs a statement updating at most a batch of rows of the table, each in
+// their own implicit transaction, until it updates none, reporting the progress of the backfill.
+// The statement must have a %d verb for the batch size, and only select rows which have yet to
+// be backfilled for the backfill to resume where it stopped if interrupted.
+func backfillInBatches(ctx context.Context, conn pgxcommon.Querier, table, stmt string) error {
+	total, err := estimatedRows(ctx, conn, table)
+	if err != nil {
+		return err
+	}
+
+	_, err = migrate.Backfill(ctx, "backfill "+table, total, func(ctx context.Context, batchSize uint64) (uint64, error) {
+		result, err := conn.Exec(ctx, fmt.Sprintf(stmt, batchSize))
+		if err != nil {
+			return 0, err
+		}
+		return safecast.ToUint64(result.RowsAffected())
+	})
+	return err
+}
+
+func estimatedRows(ctx context.Context, conn pgxcommon.Querier, table string) (uint64, error) {
+	var estimated int64
+	if err := conn.QueryRow(ctx, queryEstimatedRows, table).Scan(&estimated); err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return 0, fmt.Errorf("unable to estimate the rows of %s: %w", table, err)
+	}
+	return safecast.ToUint64(estimated)
+}
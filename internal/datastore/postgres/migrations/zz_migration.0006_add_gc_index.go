@@ -3,7 +3,7 @@ package migrations
 import (
 	"context"
 
-	"github.com/jackc/pgx/v5"
+	pgxcommon "github.com/authzed/spicedb/internal/datastore/postgres/common"
 )
 
 const (
@@ -12,7 +12,7 @@ const (
 
 func init() {
 	if err := DatabaseMigrations.Register("add-gc-index", "change-transaction-timestamp-default",
-		func(ctx context.Context, conn *pgx.Conn) error {
+		func(ctx context.Context, conn pgxcommon.Querier) error {
 			// CREATE INDEX CONCURRENTLY cannot run inside a transaction block (SQLSTATE 25001)
 			_, err := conn.Exec(ctx, createDeletedTransactionIndex)
 			return err
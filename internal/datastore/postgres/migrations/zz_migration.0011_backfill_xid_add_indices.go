@@ -2,13 +2,8 @@ package migrations
 
 import (
 	"context"
-	"fmt"
 
-	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgconn"
-
-	log "github.com/authzed/spicedb/internal/logging"
-	"github.com/authzed/spicedb/pkg/migrate"
+	pgxcommon "github.com/authzed/spicedb/internal/datastore/postgres/common"
 )
 
 var (
@@ -39,16 +34,20 @@ var addBackfillIndices = []string{
 		ON caveat ( (created_xid IS NULL) )`,
 }
 
-var backfills = []string{
-	`UPDATE relation_tuple_transaction 
+// backfills are the statements backfilling a batch of the rows of each table.
+var backfills = []struct {
+	table string
+	stmt  string
+}{
+	{"relation_tuple_transaction", `UPDATE relation_tuple_transaction 
 		SET xid = id::text::xid8, snapshot = CONCAT(id, ':', id, ':')::pg_snapshot
 		WHERE id IN (
 			SELECT id FROM relation_tuple_transaction
 			WHERE snapshot IS NULL
 			LIMIT %d
 			FOR UPDATE
-		);`,
-	`UPDATE relation_tuple 
+		);`},
+	{"relation_tuple", `UPDATE relation_tuple 
 		SET deleted_xid = deleted_transaction::text::xid8,
 		created_xid = created_transaction::text::xid8
 		WHERE (namespace, object_id, relation, userset_namespace, userset_object_id,
@@ -60,8 +59,8 @@ var backfills = []string{
 			WHERE created_xid IS NULL
 			LIMIT %d
 			FOR UPDATE
-		);`,
-	`UPDATE namespace_config 
+		);`},
+	{"namespace_config", `UPDATE namespace_config 
 		SET deleted_xid = deleted_transaction::text::xid8,
 		created_xid = created_transaction::text::xid8
 		WHERE (namespace, created_transaction, deleted_transaction) IN (
@@ -70,8 +69,8 @@ var backfills = []string{
 			WHERE created_xid IS NULL
 			LIMIT %d
 			FOR UPDATE
-		);`,
-	`UPDATE caveat 
+		);`},
+	{"caveat", `UPDATE caveat 
 		SET deleted_xid = deleted_transaction::text::xid8,
 		created_xid = created_transaction::text::xid8
 		WHERE (name, created_transaction, deleted_transaction) IN (
@@ -80,7 +79,7 @@ var backfills = []string{
 			WHERE created_xid IS NULL
 			LIMIT %d
 			FOR UPDATE
-		);`,
+		);`},
 }
 
 var addXIDIndices = []string{
@@ -118,26 +117,15 @@ var dropBackfillIndices = []string{
 
 func init() {
 	if err := DatabaseMigrations.Register("backfill-xid-add-indices", "add-xid-columns",
-		func(ctx context.Context, conn *pgx.Conn) error {
+		func(ctx context.Context, conn pgxcommon.Querier) error {
 			for _, stmt := range addBackfillIndices {
 				if _, err := conn.Exec(ctx, stmt); err != nil {
 					return err
 				}
 			}
 
-			batchSize := ctx.Value(migrate.BackfillBatchSize).(uint64)
-			for _, stmt := range backfills {
-				concreteStmt := fmt.Sprintf(stmt, batchSize)
-
-				log.Ctx(ctx).Info().Str("statement", concreteStmt).Msg("starting backfill")
-
-				var r pgconn.CommandTag
-				var err error
-
-				for r, err = conn.Exec(ctx, concreteStmt); err == nil && r.RowsAffected() > 0; r, err = conn.Exec(ctx, concreteStmt) {
-					log.Ctx(ctx).Debug().Int64("count", r.RowsAffected()).Msg("updated rows")
-				}
-				if err != nil {
+			for _, backfill := range backfills {
+				if err := backfillInBatches(ctx, conn, backfill.table, backfill.stmt); err != nil {
 					return err
 				}
 			}
@@ -3,7 +3,7 @@ package migrations
 import (
 	"context"
 
-	"github.com/jackc/pgx/v5"
+	pgxcommon "github.com/authzed/spicedb/internal/datastore/postgres/common"
 )
 
 // See https://www.postgresql.org/docs/current/indexes-index-only-scans.html
@@ -14,8 +14,8 @@ const createRelationTupleDeletedCoveringIndex = `CREATE INDEX CONCURRENTLY
 
 func init() {
 	if err := DatabaseMigrations.Register("add-gc-covering-index", "drop-bigserial-ids",
-		func(ctx context.Context, conn *pgx.Conn) error {
-			if _, err := conn.Exec(ctx, createRelationTupleDeletedCoveringIndex); err != nil {
+		func(ctx context.Context, conn pgxcommon.Querier) error {
+			if err := createIndexConcurrently(ctx, conn, "relation_tuple", "ix_relation_tuple_by_deleted_xid", createRelationTupleDeletedCoveringIndex); err != nil {
 				return err
 			}
 			return nil
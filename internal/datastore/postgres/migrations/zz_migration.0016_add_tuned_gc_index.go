@@ -4,7 +4,7 @@ import (
 	"context"
 	"fmt"
 
-	"github.com/jackc/pgx/v5"
+	pgxcommon "github.com/authzed/spicedb/internal/datastore/postgres/common"
 )
 
 const createTunedGCIndex = `CREATE INDEX CONCURRENTLY 
@@ -16,8 +16,8 @@ const deleteSuboptimalGCIndex = `DROP INDEX CONCURRENTLY IF EXISTS ix_relation_t
 
 func init() {
 	if err := DatabaseMigrations.Register("add-tuned-gc-index", "add-gc-covering-index",
-		func(ctx context.Context, conn *pgx.Conn) error {
-			if _, err := conn.Exec(ctx, createTunedGCIndex); err != nil {
+		func(ctx context.Context, conn pgxcommon.Querier) error {
+			if err := createIndexConcurrently(ctx, conn, "relation_tuple", "ix_gc_index", createTunedGCIndex); err != nil {
 				return fmt.Errorf("failed to create new tuned GC Index: %w", err)
 			}
 			if _, err := conn.Exec(ctx, deleteSuboptimalGCIndex); err != nil {
@@ -4,7 +4,7 @@ import (
 	"context"
 	"fmt"
 
-	"github.com/jackc/pgx/v5"
+	pgxcommon "github.com/authzed/spicedb/internal/datastore/postgres/common"
 )
 
 const createAliveRelByResourceRelationSubjectIndex = `CREATE INDEX CONCURRENTLY 
@@ -15,8 +15,8 @@ const createAliveRelByResourceRelationSubjectIndex = `CREATE INDEX CONCURRENTLY
 
 func init() {
 	if err := DatabaseMigrations.Register("add-rel-by-alive-resource-relation-subject", "add-tuned-gc-index",
-		func(ctx context.Context, conn *pgx.Conn) error {
-			if _, err := conn.Exec(ctx, createAliveRelByResourceRelationSubjectIndex); err != nil {
+		func(ctx context.Context, conn pgxcommon.Querier) error {
+			if err := createIndexConcurrently(ctx, conn, "relation_tuple", "ix_relation_tuple_alive_by_resource_rel_subject_covering", createAliveRelByResourceRelationSubjectIndex); err != nil {
 				return fmt.Errorf("failed to create index for alive relationships by resource/relation/subject: %w", err)
 			}
 			return nil
@@ -4,7 +4,7 @@ import (
 	"context"
 	"fmt"
 
-	"github.com/jackc/pgx/v5"
+	pgxcommon "github.com/authzed/spicedb/internal/datastore/postgres/common"
 )
 
 const createRelationshipCountersTable = `CREATE TABLE relationship_counter (
@@ -20,7 +20,7 @@ const createRelationshipCountersTable = `CREATE TABLE relationship_counter (
 
 func init() {
 	if err := DatabaseMigrations.Register("create-relationships-counters-table", "add-rel-by-alive-resource-relation-subject",
-		func(ctx context.Context, conn *pgx.Conn) error {
+		func(ctx context.Context, conn pgxcommon.Querier) error {
 			if _, err := conn.Exec(ctx, createRelationshipCountersTable); err != nil {
 				return fmt.Errorf("failed to create relationships counters table: %w", err)
 			}
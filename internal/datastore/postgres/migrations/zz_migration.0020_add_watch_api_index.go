@@ -4,15 +4,15 @@ import (
 	"context"
 	"fmt"
 
-	"github.com/jackc/pgx/v5"
+	pgxcommon "github.com/authzed/spicedb/internal/datastore/postgres/common"
 )
 
 const addWatchAPIIndexToRelationTupleTable = `CREATE INDEX CONCURRENTLY IF NOT EXISTS ix_watch_index ON relation_tuple (created_xid);`
 
 func init() {
 	if err := DatabaseMigrations.Register("add-watch-api-index-to-relation-tuple-table", "add-metadata-to-transaction-table",
-		func(ctx context.Context, conn *pgx.Conn) error {
-			if _, err := conn.Exec(ctx, addWatchAPIIndexToRelationTupleTable); err != nil {
+		func(ctx context.Context, conn pgxcommon.Querier) error {
+			if err := createIndexConcurrently(ctx, conn, "relation_tuple", "ix_watch_index", addWatchAPIIndexToRelationTupleTable); err != nil {
 				return fmt.Errorf("failed to add watch API index to relation tuple table: %w", err)
 			}
 			return nil
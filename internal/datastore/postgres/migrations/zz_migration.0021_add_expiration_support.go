@@ -4,7 +4,7 @@ import (
 	"context"
 	"fmt"
 
-	"github.com/jackc/pgx/v5"
+	pgxcommon "github.com/authzed/spicedb/internal/datastore/postgres/common"
 )
 
 const addExpirationColumn = `
@@ -21,12 +21,12 @@ const addExpiredRelationshipsIndex = `CREATE INDEX CONCURRENTLY
 
 func init() {
 	if err := DatabaseMigrations.Register("add-expiration-support", "add-watch-api-index-to-relation-tuple-table",
-		func(ctx context.Context, conn *pgx.Conn) error {
+		func(ctx context.Context, conn pgxcommon.Querier) error {
 			if _, err := conn.Exec(ctx, addExpirationColumn); err != nil {
 				return fmt.Errorf("failed to add expiration column to relation tuple table: %w", err)
 			}
 
-			if _, err := conn.Exec(ctx, addExpiredRelationshipsIndex); err != nil {
+			if err := createIndexConcurrently(ctx, conn, "relation_tuple", "ix_relation_tuple_expired", addExpiredRelationshipsIndex); err != nil {
 				return fmt.Errorf("failed to add expiration column to relation tuple table: %w", err)
 			}
 
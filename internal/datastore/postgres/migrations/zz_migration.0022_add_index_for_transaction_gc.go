@@ -4,7 +4,7 @@ import (
 	"context"
 	"fmt"
 
-	"github.com/jackc/pgx/v5"
+	pgxcommon "github.com/authzed/spicedb/internal/datastore/postgres/common"
 )
 
 // addGCIndexForRelationTupleTransaction adds a missing index to relation_tuple_transaction table
@@ -27,8 +27,8 @@ const addGCIndexForRelationTupleTransaction = `CREATE INDEX CONCURRENTLY
 
 func init() {
 	if err := DatabaseMigrations.Register("add-index-for-transaction-gc", "add-expiration-support",
-		func(ctx context.Context, conn *pgx.Conn) error {
-			if _, err := conn.Exec(ctx, addGCIndexForRelationTupleTransaction); err != nil {
+		func(ctx context.Context, conn pgxcommon.Querier) error {
+			if err := createIndexConcurrently(ctx, conn, "relation_tuple_transaction", "ix_relation_tuple_transaction_xid_desc_timestamp", addGCIndexForRelationTupleTransaction); err != nil {
 				return fmt.Errorf("failed to add missing GC index: %w", err)
 			}
 			return nil
@@ -0,0 +1,30 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+
+	pgxcommon "github.com/authzed/spicedb/internal/datastore/postgres/common"
+)
+
+// createRevisionPinTable adds the table of the pins of revisions, whose history is not garbage
+// collected until they expire.
+const createRevisionPinTable = `CREATE TABLE revision_pin (
+	pin_id VARCHAR NOT NULL,
+	revision VARCHAR NOT NULL,
+	expires_at TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+	CONSTRAINT pk_revision_pin PRIMARY KEY (pin_id)
+);`
+
+func init() {
+	if err := DatabaseMigrations.Register("add-revision-pin-table", "add-index-for-transaction-gc",
+		func(ctx context.Context, conn pgxcommon.Querier) error {
+			if _, err := conn.Exec(ctx, createRevisionPinTable); err != nil {
+				return fmt.Errorf("failed to create revision pin table: %w", err)
+			}
+			return nil
+		},
+		noTxMigration); err != nil {
+		panic("failed to register migration: " + err.Error())
+	}
+}
@@ -0,0 +1,45 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	pgxcommon "github.com/authzed/spicedb/internal/datastore/postgres/common"
+)
+
+// createLeaseTable adds the table of the leases held by the nodes elected to run
+// singleton background jobs, such as garbage collection.
+const createLeaseTable = `CREATE TABLE lease (
+	name VARCHAR NOT NULL,
+	holder VARCHAR NOT NULL,
+	expires_at TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+	CONSTRAINT pk_lease PRIMARY KEY (name)
+);`
+
+const dropLeaseTable = `DROP TABLE IF EXISTS lease;`
+
+func init() {
+	if err := DatabaseMigrations.Register("add-lease-table", "add-revision-pin-table",
+		func(ctx context.Context, conn pgxcommon.Querier) error {
+			if _, err := conn.Exec(ctx, createLeaseTable); err != nil {
+				return fmt.Errorf("failed to create lease table: %w", err)
+			}
+			return nil
+		},
+		noTxMigration); err != nil {
+		panic("failed to register migration: " + err.Error())
+	}
+
+	if err := DatabaseMigrations.RegisterDown("add-lease-table",
+		noNonatomicMigration,
+		func(ctx context.Context, tx pgx.Tx) error {
+			if _, err := tx.Exec(ctx, dropLeaseTable); err != nil {
+				return fmt.Errorf("failed to drop lease table: %w", err)
+			}
+			return nil
+		}); err != nil {
+		panic("failed to register down migration: " + err.Error())
+	}
+}
@@ -0,0 +1,50 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// createInternalRecordTable adds the table of the records stored by SpiceDB itself, such as the
+// journals of writes spanning several transactions. The keys are compared bytewise so that
+// records are read by key prefix.
+const createInternalRecordTable = `CREATE TABLE internal_record (
+	record_key VARCHAR COLLATE "C" NOT NULL,
+	record_value BYTEA NOT NULL,
+	expires_at TIMESTAMP WITHOUT TIME ZONE,
+	CONSTRAINT pk_internal_record PRIMARY KEY (record_key)
+);`
+
+const createInternalRecordExpirationIndex = `CREATE INDEX ix_internal_record_expires_at
+	ON internal_record (expires_at)
+	WHERE expires_at IS NOT NULL;`
+
+const dropInternalRecordTable = `DROP TABLE IF EXISTS internal_record;`
+
+func init() {
+	if err := DatabaseMigrations.Register("add-internal-record-table", "add-subject-covering-index",
+		noNonatomicMigration,
+		func(ctx context.Context, tx pgx.Tx) error {
+			for _, stmt := range []string{createInternalRecordTable, createInternalRecordExpirationIndex} {
+				if _, err := tx.Exec(ctx, stmt); err != nil {
+					return fmt.Errorf("failed to create internal record table: %w", err)
+				}
+			}
+			return nil
+		}); err != nil {
+		panic("failed to register migration: " + err.Error())
+	}
+
+	if err := DatabaseMigrations.RegisterDown("add-internal-record-table",
+		noNonatomicMigration,
+		func(ctx context.Context, tx pgx.Tx) error {
+			if _, err := tx.Exec(ctx, dropInternalRecordTable); err != nil {
+				return fmt.Errorf("failed to drop internal record table: %w", err)
+			}
+			return nil
+		}); err != nil {
+		panic("failed to register down migration: " + err.Error())
+	}
+}
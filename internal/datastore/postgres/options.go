@@ -13,17 +13,24 @@ type postgresOptions struct {
 	readPoolOpts, writePoolOpts pgxcommon.PoolOptions
 
 	maxRevisionStalenessPercent float64
+	revisionQuantizationJitter  time.Duration
 
 	credentialsProviderName string
 
 	watchBufferLength       uint16
 	watchBufferWriteTimeout time.Duration
+	watchLogicalReplication bool
 	revisionQuantization    time.Duration
 	gcWindow                time.Duration
 	gcInterval              time.Duration
 	gcMaxOperationTime      time.Duration
+	gcTableRetention        common.TableRetention
+	gcLeaseDuration         time.Duration
 	maxRetries              uint8
 	filterMaximumIDCount    uint16
+	slowQueryThreshold      time.Duration
+	queryShapeCacheCapacity int
+	connMaxErrors           int
 
 	enablePrometheusStats          bool
 	analyzeBeforeStatistics        bool
@@ -32,6 +39,10 @@ type postgresOptions struct {
 	expirationDisabled             bool
 	columnOptimizationOption       common.ColumnOptimizationOption
 	includeQueryParametersInTraces bool
+	preparedStatementCacheCapacity int
+
+	lowPriorityReadConnsMaxOpen int
+	lowPriorityStatementTimeout time.Duration
 
 	migrationPhase    string
 	allowedMigrations []string
@@ -60,9 +71,11 @@ const (
 
 	defaultWatchBufferLength                 = 128
 	defaultWatchBufferWriteTimeout           = 1 * time.Second
+	defaultWatchLogicalReplication           = false
 	defaultGarbageCollectionWindow           = 24 * time.Hour
 	defaultGarbageCollectionInterval         = time.Minute * 3
 	defaultGarbageCollectionMaxOperationTime = time.Minute
+	defaultGarbageCollectionLeaseDuration    = 30 * time.Second
 	defaultQuantization                      = 5 * time.Second
 	defaultMaxRevisionStalenessPercent       = 0.1
 	defaultEnablePrometheusStats             = false
@@ -71,9 +84,11 @@ const (
 	defaultCredentialsProviderName           = ""
 	defaultReadStrictMode                    = false
 	defaultFilterMaximumIDCount              = 100
+	defaultQueryShapeCacheCapacity           = 1024
 	defaultColumnOptimizationOption          = common.ColumnOptimizationOptionNone
 	defaultIncludeQueryParametersInTraces    = false
 	defaultExpirationDisabled                = false
+	defaultPreparedStatementCacheCapacity    = 0
 )
 
 // Option provides the facility to configure how clients within the
@@ -85,8 +100,10 @@ func generateConfig(options []Option) (postgresOptions, error) {
 		gcWindow:                       defaultGarbageCollectionWindow,
 		gcInterval:                     defaultGarbageCollectionInterval,
 		gcMaxOperationTime:             defaultGarbageCollectionMaxOperationTime,
+		gcLeaseDuration:                defaultGarbageCollectionLeaseDuration,
 		watchBufferLength:              defaultWatchBufferLength,
 		watchBufferWriteTimeout:        defaultWatchBufferWriteTimeout,
+		watchLogicalReplication:        defaultWatchLogicalReplication,
 		revisionQuantization:           defaultQuantization,
 		maxRevisionStalenessPercent:    defaultMaxRevisionStalenessPercent,
 		enablePrometheusStats:          defaultEnablePrometheusStats,
@@ -96,9 +113,11 @@ func generateConfig(options []Option) (postgresOptions, error) {
 		readStrictMode:                 defaultReadStrictMode,
 		queryInterceptor:               nil,
 		filterMaximumIDCount:           defaultFilterMaximumIDCount,
+		queryShapeCacheCapacity:        defaultQueryShapeCacheCapacity,
 		columnOptimizationOption:       defaultColumnOptimizationOption,
 		includeQueryParametersInTraces: defaultIncludeQueryParametersInTraces,
 		expirationDisabled:             defaultExpirationDisabled,
+		preparedStatementCacheCapacity: defaultPreparedStatementCacheCapacity,
 	}
 
 	for _, option := range options {
@@ -273,6 +292,17 @@ func WatchBufferWriteTimeout(watchBufferWriteTimeout time.Duration) Option {
 	return func(po *postgresOptions) { po.watchBufferWriteTimeout = watchBufferWriteTimeout }
 }
 
+// WatchLogicalReplication enables streaming the changes of watches from temporary
+// logical replication slots decoded by wal2json, rather than polling the transactions
+// table. This requires Postgres to be run with wal_level=logical and the wal2json
+// plugin installed, and the user to have the REPLICATION attribute; otherwise,
+// watches fall back to polling.
+//
+// This value defaults to false.
+func WatchLogicalReplication(enabled bool) Option {
+	return func(po *postgresOptions) { po.watchLogicalReplication = enabled }
+}
+
 // RevisionQuantization is the time bucket size to which advertised
 // revisions will be rounded.
 //
@@ -290,6 +320,16 @@ func MaxRevisionStalenessPercent(stalenessPercent float64) Option {
 	return func(po *postgresOptions) { po.maxRevisionStalenessPercent = stalenessPercent }
 }
 
+// RevisionQuantizationJitter is the maximum random offset by which the boundaries of the
+// revision quantization windows of this instance are shifted, such that the optimized revisions
+// cached by the nodes of a fleet do not all expire at the same time. It adds up to the jitter to
+// the staleness of optimized revisions.
+//
+// This value defaults to 0 (disabled).
+func RevisionQuantizationJitter(jitter time.Duration) Option {
+	return func(po *postgresOptions) { po.revisionQuantizationJitter = jitter }
+}
+
 // GCWindow is the maximum age of a passed revision that will be considered
 // valid.
 //
@@ -305,6 +345,24 @@ func GCInterval(interval time.Duration) Option {
 	return func(po *postgresOptions) { po.gcInterval = interval }
 }
 
+// RelationshipsGCRetention is how long the history of the relationships is
+// retained before being garbage collected. Retentions shorter than the GC window
+// retain the history for the GC window.
+//
+// This value defaults to the GC window.
+func RelationshipsGCRetention(retention time.Duration) Option {
+	return func(po *postgresOptions) { po.gcTableRetention.Relationships = retention }
+}
+
+// NamespacesGCRetention is how long the history of the namespaces is retained
+// before being garbage collected. Retentions shorter than the GC window retain
+// the history for the GC window.
+//
+// This value defaults to the GC window.
+func NamespacesGCRetention(retention time.Duration) Option {
+	return func(po *postgresOptions) { po.gcTableRetention.Namespaces = retention }
+}
+
 // GCMaxOperationTime is the maximum operation time of a garbage collection
 // pass before it times out.
 //
@@ -313,6 +371,16 @@ func GCMaxOperationTime(time time.Duration) Option {
 	return func(po *postgresOptions) { po.gcMaxOperationTime = time }
 }
 
+// GCLeaseDuration is the duration of the lease held by the node elected to run
+// garbage collection, so that a single node of the cluster collects garbage.
+// Should the node stop renewing it, another node takes over once it expires. A
+// duration of zero disables the election, collecting garbage on every node.
+//
+// This value defaults to 30 seconds.
+func GCLeaseDuration(duration time.Duration) Option {
+	return func(po *postgresOptions) { po.gcLeaseDuration = duration }
+}
+
 // MaxRetries is the maximum number of times a retriable transaction will be
 // client-side retried.
 // Default: 10
@@ -388,11 +456,65 @@ func FilterMaximumIDCount(filterMaximumIDCount uint16) Option {
 	return func(po *postgresOptions) { po.filterMaximumIDCount = filterMaximumIDCount }
 }
 
+// SlowQueryThreshold is the duration over which relationship queries are logged as slow, along with
+// the shape of the statement, the API method which triggered them and the revision used.
+//
+// Disabled (zero) by default.
+func SlowQueryThreshold(threshold time.Duration) Option {
+	return func(po *postgresOptions) { po.slowQueryThreshold = threshold }
+}
+
+// QueryShapeCacheCapacity is the maximum number of query shapes whose generated SQL is cached, so
+// that relationship queries differing only in their values are not rebuilt. Zero disables the cache.
+//
+// This value defaults to 1024.
+func QueryShapeCacheCapacity(capacity int) Option {
+	return func(po *postgresOptions) { po.queryShapeCacheCapacity = capacity }
+}
+
+// ConnMaxErrors is the maximum number of consecutive connection errors, such as network failures,
+// tolerated on a pooled connection. Connections exceeding it are closed when released to the pool
+// instead of being reused.
+//
+// Disabled (zero) by default.
+func ConnMaxErrors(maxErrors int) Option {
+	return func(po *postgresOptions) { po.connMaxErrors = maxErrors }
+}
+
 // IncludeQueryParametersInTraces is a flag to set whether to include query parameters in OTEL traces
 func IncludeQueryParametersInTraces(includeQueryParametersInTraces bool) Option {
 	return func(po *postgresOptions) { po.includeQueryParametersInTraces = includeQueryParametersInTraces }
 }
 
+// PreparedStatementCacheCapacity is the number of prepared statements cached on each connection
+// for the relationship queries, which are keyed by the shape of the query and reused across
+// queries of the same shape. Hits and misses of the cache are exported as metrics.
+//
+// Disabled (zero) by default, in which case queries are executed without preparing statements.
+// Must remain disabled behind connection poolers that do not support prepared statements.
+func PreparedStatementCacheCapacity(capacity int) Option {
+	return func(po *postgresOptions) { po.preparedStatementCacheCapacity = capacity }
+}
+
+// LowPriorityReadConnsMaxOpen is the maximum size of a connection pool of its own with which the
+// reads of batch and background requests are served, so that they cannot take the connections
+// of the read pool from interactive requests.
+//
+// Disabled (zero) by default, in which case the requests of all priority classes share the read
+// pool.
+func LowPriorityReadConnsMaxOpen(conns int) Option {
+	return func(po *postgresOptions) { po.lowPriorityReadConnsMaxOpen = conns }
+}
+
+// LowPriorityStatementTimeout is the maximum duration of the statements of the write
+// transactions of batch and background requests, set with SET LOCAL statement_timeout, so that
+// they cannot hold the locks contended by the writes of interactive requests for long.
+//
+// Disabled (zero) by default.
+func LowPriorityStatementTimeout(timeout time.Duration) Option {
+	return func(po *postgresOptions) { po.lowPriorityStatementTimeout = timeout }
+}
+
 // WithColumnOptimization sets the column optimization option for the datastore.
 func WithColumnOptimization(isEnabled bool) Option {
 	return func(po *postgresOptions) {
@@ -13,6 +13,7 @@ import (
 
 	"github.com/IBM/pgxpoolprometheus"
 	sq "github.com/Masterminds/squirrel"
+	"github.com/ccoveille/go-safecast"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -46,6 +47,9 @@ const (
 	tableTuple               = "relation_tuple"
 	tableCaveat              = "caveat"
 	tableRelationshipCounter = "relationship_counter"
+	tableRevisionPin         = "revision_pin"
+	tableLease               = "lease"
+	tableInternalRecord      = "internal_record"
 
 	colXID               = "xid"
 	colTimestamp         = "timestamp"
@@ -71,6 +75,18 @@ const (
 	colCounterCurrentCount = "current_count"
 	colCounterSnapshot     = "updated_revision_snapshot"
 
+	colPinID        = "pin_id"
+	colPinRevision  = "revision"
+	colPinExpiresAt = "expires_at"
+
+	colLeaseName      = "name"
+	colLeaseHolder    = "holder"
+	colLeaseExpiresAt = "expires_at"
+
+	colRecordKey       = "record_key"
+	colRecordValue     = "record_value"
+	colRecordExpiresAt = "expires_at"
+
 	errUnableToInstantiate = "unable to instantiate datastore"
 
 	// The parameters to this format string are:
@@ -188,12 +204,20 @@ func newPostgresDatastore(
 		return nil, common.RedactAndLogSensitiveConnString(ctx, errUnableToInstantiate, err, pgURL)
 	}
 
+	if config.preparedStatementCacheCapacity > 0 {
+		pgxcommon.ConfigureStatementCache(readPoolConfig, "read", config.preparedStatementCacheCapacity)
+	}
+
 	readPoolConfig.AfterConnect = func(ctx context.Context, conn *pgx.Conn) error {
 		RegisterTypes(conn.TypeMap())
 		return nil
 	}
 
+	readConnErrors := common.NewConnErrorTracker[*pgx.Conn](config.connMaxErrors)
+	pgxcommon.ConfigureConnErrorPruning(readPoolConfig, readConnErrors)
+
 	var writePoolConfig *pgxpool.Config
+	var writeConnErrors *common.ConnErrorTracker[*pgx.Conn]
 	if isPrimary {
 		writePoolConfig = pgConfig.Copy()
 		err = config.writePoolOpts.ConfigurePgx(writePoolConfig, includeQueryParametersInTraces)
@@ -201,10 +225,17 @@ func newPostgresDatastore(
 			return nil, common.RedactAndLogSensitiveConnString(ctx, errUnableToInstantiate, err, pgURL)
 		}
 
+		if config.preparedStatementCacheCapacity > 0 {
+			pgxcommon.ConfigureStatementCache(writePoolConfig, "write", config.preparedStatementCacheCapacity)
+		}
+
 		writePoolConfig.AfterConnect = func(ctx context.Context, conn *pgx.Conn) error {
 			RegisterTypes(conn.TypeMap())
 			return nil
 		}
+
+		writeConnErrors = common.NewConnErrorTracker[*pgx.Conn](config.connMaxErrors)
+		pgxcommon.ConfigureConnErrorPruning(writePoolConfig, writeConnErrors)
 	}
 
 	if credentialsProvider != nil {
@@ -234,6 +265,21 @@ func newPostgresDatastore(
 		return nil, common.RedactAndLogSensitiveConnString(ctx, errUnableToInstantiate, err, pgURL)
 	}
 
+	var lowPriorityReadPool *pgxpool.Pool
+	if config.lowPriorityReadConnsMaxOpen > 0 {
+		lowPriorityReadPoolConfig := readPoolConfig.Copy()
+		lowPriorityReadPoolConfig.MaxConns, err = safecast.ToInt32(config.lowPriorityReadConnsMaxOpen)
+		if err != nil {
+			return nil, err
+		}
+		lowPriorityReadPoolConfig.MinConns = 0
+
+		lowPriorityReadPool, err = pgxpool.NewWithConfig(initializationContext, lowPriorityReadPoolConfig)
+		if err != nil {
+			return nil, common.RedactAndLogSensitiveConnString(ctx, errUnableToInstantiate, err, pgURL)
+		}
+	}
+
 	var writePool *pgxpool.Pool
 
 	if isPrimary {
@@ -257,6 +303,21 @@ func newPostgresDatastore(
 		log.Warn().Msg("watch API disabled, postgres must be run with track_commit_timestamp=on")
 	}
 
+	watchLogicalReplication := config.watchLogicalReplication
+	if watchEnabled && watchLogicalReplication {
+		var walLevel string
+		if err := readPool.
+			QueryRow(initializationContext, "SHOW wal_level;").
+			Scan(&walLevel); err != nil {
+			return nil, err
+		}
+
+		if walLevel != "logical" {
+			log.Warn().Str("wal_level", walLevel).Msg("watch falling back to polling, postgres must be run with wal_level=logical for logical replication")
+			watchLogicalReplication = false
+		}
+	}
+
 	if config.enablePrometheusStats {
 		replicaIndexStr := strconv.Itoa(replicaIndex)
 		dbname := "spicedb"
@@ -271,6 +332,19 @@ func newPostgresDatastore(
 			return nil, err
 		}
 
+		if err := prometheus.Register(common.NewPoolStatsCollector(dbname, "read", pgxcommon.PoolStatsFunc(readPool, readConnErrors))); err != nil {
+			return nil, err
+		}
+
+		if lowPriorityReadPool != nil {
+			if err := prometheus.Register(pgxpoolprometheus.NewCollector(lowPriorityReadPool, map[string]string{
+				"db_name":    dbname,
+				"pool_usage": "read_low_priority",
+			})); err != nil {
+				return nil, err
+			}
+		}
+
 		if isPrimary {
 			if err := prometheus.Register(pgxpoolprometheus.NewCollector(writePool, map[string]string{
 				"db_name":    "spicedb",
@@ -278,9 +352,15 @@ func newPostgresDatastore(
 			})); err != nil {
 				return nil, err
 			}
+			if err := prometheus.Register(common.NewPoolStatsCollector("spicedb", "write", pgxcommon.PoolStatsFunc(writePool, writeConnErrors))); err != nil {
+				return nil, err
+			}
 			if err := common.RegisterGCMetrics(); err != nil {
 				return nil, err
 			}
+			if err := common.RegisterLeaseMetrics(); err != nil {
+				return nil, err
+			}
 		}
 	}
 
@@ -291,18 +371,7 @@ func newPostgresDatastore(
 
 	gcCtx, cancelGc := context.WithCancel(context.Background())
 
-	quantizationPeriodNanos := config.revisionQuantization.Nanoseconds()
-	if quantizationPeriodNanos < 1 {
-		quantizationPeriodNanos = 1
-	}
-	revisionQuery := fmt.Sprintf(
-		querySelectRevision,
-		colXID,
-		tableTransaction,
-		colTimestamp,
-		quantizationPeriodNanos,
-		colSnapshot,
-	)
+	revisionQuery := selectRevisionQuery(config.revisionQuantization)
 
 	validTransactionQuery := fmt.Sprintf(
 		queryValidTransaction,
@@ -327,9 +396,11 @@ func newPostgresDatastore(
 		common.WithColCaveatName(colCaveatContextName),
 		common.WithColCaveatContext(colCaveatContext),
 		common.WithColExpiration(colExpiration),
+		common.WithColWriteRevision(colCreatedXid+"::text"),
 		common.WithPaginationFilterType(common.TupleComparison),
 		common.WithPlaceholderFormat(sq.Dollar),
 		common.WithNowFunction("NOW"),
+		common.WithEmptyCaveatContextCondition("(%[1]s IS NULL OR %[1]s = '{}'::JSONB)"),
 		common.WithColumnOptimization(config.columnOptimizationOption),
 		common.WithExpirationDisabled(config.expirationDisabled),
 	)
@@ -344,13 +415,16 @@ func newPostgresDatastore(
 		writePool:               nil, /* disabled by default */
 		watchBufferLength:       config.watchBufferLength,
 		watchBufferWriteTimeout: config.watchBufferWriteTimeout,
-		optimizedRevisionQuery:  revisionQuery,
 		validTransactionQuery:   validTransactionQuery,
 		gcWindow:                config.gcWindow,
+		maxStalenessPercent:     config.maxRevisionStalenessPercent,
 		gcInterval:              config.gcInterval,
 		gcTimeout:               config.gcMaxOperationTime,
+		gcTableRetention:        config.gcTableRetention,
+		gcLeaseDuration:         config.gcLeaseDuration,
 		analyzeBeforeStatistics: config.analyzeBeforeStatistics,
 		watchEnabled:            watchEnabled,
+		watchLogicalReplication: watchLogicalReplication,
 		gcCtx:                   gcCtx,
 		cancelGc:                cancelGc,
 		readTxOptions:           pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly},
@@ -359,7 +433,20 @@ func newPostgresDatastore(
 		isPrimary:               isPrimary,
 		inStrictReadMode:        config.readStrictMode,
 		filterMaximumIDCount:    config.filterMaximumIDCount,
+		slowQueryLogger:         common.NewSlowQueryLogger(config.slowQueryThreshold),
+		shapeCache:              common.NewQueryShapeCache(Engine, config.queryShapeCacheCapacity),
+		statementCacheEnabled:   config.preparedStatementCacheCapacity > 0,
 		schema:                  *schema,
+
+		lowPriorityStatementTimeout: config.lowPriorityStatementTimeout,
+	}
+	datastore.optimizedRevisionQuery.Store(&revisionQuery)
+
+	if lowPriorityReadPool != nil {
+		datastore.readPool = &priorityPooler{
+			interactive: datastore.readPool,
+			lowPriority: pgxcommon.MustNewInterceptorPooler(lowPriorityReadPool, config.queryInterceptor),
+		}
 	}
 
 	if isPrimary && config.readStrictMode {
@@ -371,15 +458,18 @@ func newPostgresDatastore(
 	}
 
 	datastore.SetOptimizedRevisionFunc(datastore.optimizedRevisionFunc)
+	datastore.SetWindowJitter(config.revisionQuantizationJitter)
 
 	// Start a goroutine for garbage collection.
 	if isPrimary {
 		if datastore.gcInterval > 0*time.Minute && config.gcEnabled {
 			datastore.gcGroup, datastore.gcCtx = errgroup.WithContext(datastore.gcCtx)
 			datastore.gcGroup.Go(func() error {
-				return common.StartGarbageCollector(
+				return common.StartLeaderGarbageCollector(
 					datastore.gcCtx,
 					datastore,
+					datastore,
+					datastore.gcLeaseDuration,
 					datastore.gcInterval,
 					datastore.gcWindow,
 					datastore.gcTimeout,
@@ -401,17 +491,22 @@ type pgDatastore struct {
 	readPool, writePool            pgxcommon.ConnPooler
 	watchBufferLength              uint16
 	watchBufferWriteTimeout        time.Duration
-	optimizedRevisionQuery         string
+	optimizedRevisionQuery         atomic.Pointer[string]
 	validTransactionQuery          string
 	gcWindow                       time.Duration
+	maxStalenessPercent            float64
 	gcInterval                     time.Duration
 	gcTimeout                      time.Duration
+	gcTableRetention               common.TableRetention
+	gcLeaseDuration                time.Duration
 	analyzeBeforeStatistics        bool
 	readTxOptions                  pgx.TxOptions
 	maxRetries                     uint8
 	watchEnabled                   bool
+	watchLogicalReplication        bool
 	isPrimary                      bool
 	inStrictReadMode               bool
+	statementCacheEnabled          bool
 	schema                         common.SchemaInformation
 	includeQueryParametersInTraces bool
 
@@ -422,6 +517,10 @@ type pgDatastore struct {
 	cancelGc             context.CancelFunc
 	gcHasRun             atomic.Bool
 	filterMaximumIDCount uint16
+	slowQueryLogger      *common.SlowQueryLogger
+	shapeCache           *common.QueryShapeCache
+
+	lowPriorityStatementTimeout time.Duration
 }
 
 func (pgd *pgDatastore) IsStrictReadModeEnabled() bool {
@@ -432,23 +531,38 @@ func (pgd *pgDatastore) SnapshotReader(revRaw datastore.Revision) datastore.Read
 	rev := revRaw.(postgresRevision)
 
 	queryFuncs := pgxcommon.QuerierFuncsFor(pgd.readPool)
+	relationshipsQueryFuncs := pgd.relationshipsQuerier(queryFuncs)
 	if pgd.inStrictReadMode {
 		queryFuncs = strictReaderQueryFuncs{wrapped: queryFuncs, revision: rev}
+		relationshipsQueryFuncs = strictReaderQueryFuncs{wrapped: relationshipsQueryFuncs, revision: rev}
 	}
 
 	executor := common.QueryRelationshipsExecutor{
-		Executor: pgxcommon.NewPGXQueryRelationshipsExecutor(queryFuncs),
+		Executor:        pgxcommon.NewPGXQueryRelationshipsExecutor(relationshipsQueryFuncs),
+		BatchExecutor:   pgxcommon.NewPGXQueryRelationshipsBatchExecutor(relationshipsQueryFuncs),
+		SlowQueryLogger: pgd.slowQueryLogger,
+		ShapeCache:      pgd.shapeCache,
+		Revision:        rev,
 	}
 
 	return &pgReader{
 		queryFuncs,
 		executor,
-		buildLivingObjectFilterForRevision(rev),
+		buildLivingObjectPredicateForRevision(rev),
 		pgd.filterMaximumIDCount,
 		pgd.schema,
 	}
 }
 
+// relationshipsQuerier returns the querier with which relationships are queried, which caches
+// prepared statements if enabled.
+func (pgd *pgDatastore) relationshipsQuerier(querier pgxcommon.DBFuncQuerier) pgxcommon.DBFuncQuerier {
+	if pgd.statementCacheEnabled {
+		return pgxcommon.WithStatementCache(querier)
+	}
+	return querier
+}
+
 // ReadWriteTx starts a read/write transaction, which will be committed if no error is
 // returned and rolled back if an error is returned.
 func (pgd *pgDatastore) ReadWriteTx(
@@ -473,14 +587,22 @@ func (pgd *pgDatastore) ReadWriteTx(
 				metadata = config.Metadata.AsMap()
 			}
 
+			if err := setLowPriorityStatementTimeout(ctx, tx, pgd.lowPriorityStatementTimeout); err != nil {
+				return err
+			}
+
 			newXID, newSnapshot, err = createNewTransaction(ctx, tx, metadata)
 			if err != nil {
 				return err
 			}
 
 			queryFuncs := pgxcommon.QuerierFuncsFor(pgd.readPool)
+			relationshipsQueryFuncs := pgd.relationshipsQuerier(queryFuncs)
 			executor := common.QueryRelationshipsExecutor{
-				Executor: pgxcommon.NewPGXQueryRelationshipsExecutor(queryFuncs),
+				Executor:        pgxcommon.NewPGXQueryRelationshipsExecutor(relationshipsQueryFuncs),
+				BatchExecutor:   pgxcommon.NewPGXQueryRelationshipsBatchExecutor(relationshipsQueryFuncs),
+				SlowQueryLogger: pgd.slowQueryLogger,
+				ShapeCache:      pgd.shapeCache,
 			}
 
 			rwt := &pgReadWriteTXN{
@@ -735,24 +857,22 @@ func (pgd *pgDatastore) OfflineFeatures() (*datastore.Features, error) {
 	}, nil
 }
 
-func buildLivingObjectFilterForRevision(revision postgresRevision) queryFilterer {
-	createdBeforeTXN := sq.Expr(fmt.Sprintf(
-		snapshotAlive,
-		colCreatedXid,
-	), revision.snapshot, true)
-
-	deletedAfterTXN := sq.Expr(fmt.Sprintf(
-		snapshotAlive,
-		colDeletedXid,
-	), revision.snapshot, false)
+// buildLivingObjectPredicateForRevision returns the predicate matching the rows alive at the
+// revision. It is a predicate rather than a filter so that relationship queries can be cached
+// by their shape.
+func buildLivingObjectPredicateForRevision(revision postgresRevision) common.Predicate {
+	createdBeforeTXN := fmt.Sprintf(snapshotAlive, colCreatedXid)
+	deletedAfterTXN := fmt.Sprintf(snapshotAlive, colDeletedXid)
 
-	return func(original sq.SelectBuilder) sq.SelectBuilder {
-		return original.Where(createdBeforeTXN).Where(deletedAfterTXN)
+	return common.Predicate{
+		SQL:  createdBeforeTXN + " AND " + deletedAfterTXN,
+		Args: []any{revision.snapshot, true, revision.snapshot, false},
 	}
 }
 
-func currentlyLivingObjects(original sq.SelectBuilder) sq.SelectBuilder {
-	return original.Where(sq.Eq{colDeletedXid: liveDeletedTxnID})
+var currentlyLivingObjects = common.Predicate{
+	SQL:  colDeletedXid + " = ?",
+	Args: []any{liveDeletedTxnID},
 }
 
 // DefaultQueryExecMode parses a Postgres URI and determines if a default_query_exec_mode
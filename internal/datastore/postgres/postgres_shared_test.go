@@ -31,6 +31,7 @@ import (
 	"github.com/authzed/spicedb/pkg/datastore"
 	"github.com/authzed/spicedb/pkg/datastore/options"
 	"github.com/authzed/spicedb/pkg/datastore/test"
+	"github.com/authzed/spicedb/pkg/middleware/priority"
 	"github.com/authzed/spicedb/pkg/migrate"
 	"github.com/authzed/spicedb/pkg/namespace"
 	"github.com/authzed/spicedb/pkg/tuple"
@@ -257,6 +258,18 @@ func testPostgresDatastore(t *testing.T, pc []postgresConfig) {
 					WatchBufferLength(50),
 					MigrationPhase(config.migrationPhase),
 				))
+
+				t.Run("TestLowPriority", createDatastoreTest(
+					b,
+					LowPriorityTest,
+					RevisionQuantization(0),
+					GCWindow(1000*time.Second),
+					GCInterval(veryLargeGCInterval),
+					WatchBufferLength(50),
+					MigrationPhase(config.migrationPhase),
+					LowPriorityReadConnsMaxOpen(1),
+					LowPriorityStatementTimeout(1500*time.Millisecond),
+				))
 			}
 
 			t.Run("OTelTracing", createDatastoreTest(
@@ -431,7 +444,7 @@ func GarbageCollectionTest(t *testing.T, ds datastore.Datastore) {
 	pds := ds.(*pgDatastore)
 
 	// Nothing to GC
-	removed, err := pds.DeleteBeforeTx(ctx, firstWrite)
+	removed, err := pds.DeleteBeforeTx(ctx, watermarksAt(firstWrite))
 	require.NoError(err)
 	require.Zero(removed.Relationships)
 	require.Zero(removed.Namespaces)
@@ -451,7 +464,7 @@ func GarbageCollectionTest(t *testing.T, ds datastore.Datastore) {
 	require.NoError(err)
 
 	// Run GC to remove the old transaction
-	removed, err = pds.DeleteBeforeTx(ctx, updateTwoNamespaces)
+	removed, err = pds.DeleteBeforeTx(ctx, watermarksAt(updateTwoNamespaces))
 	require.NoError(err)
 	require.Zero(removed.Relationships)
 	require.Equal(int64(1), removed.Transactions) // firstWrite
@@ -464,14 +477,14 @@ func GarbageCollectionTest(t *testing.T, ds datastore.Datastore) {
 	require.NoError(err)
 
 	// Run GC at the transaction and ensure no relationships are removed, but 1 transaction (the previous write namespace) is.
-	removed, err = pds.DeleteBeforeTx(ctx, wroteOneRelationship)
+	removed, err = pds.DeleteBeforeTx(ctx, watermarksAt(wroteOneRelationship))
 	require.NoError(err)
 	require.Zero(removed.Relationships)
 	require.Equal(int64(1), removed.Transactions) // updateTwoNamespaces
 	require.Zero(removed.Namespaces)
 
 	// Run GC again and ensure there are no changes.
-	removed, err = pds.DeleteBeforeTx(ctx, wroteOneRelationship)
+	removed, err = pds.DeleteBeforeTx(ctx, watermarksAt(wroteOneRelationship))
 	require.NoError(err)
 	require.Zero(removed.Relationships)
 	require.Zero(removed.Transactions)
@@ -487,14 +500,14 @@ func GarbageCollectionTest(t *testing.T, ds datastore.Datastore) {
 	require.NoError(err)
 
 	// Run GC, which won't clean anything because we're dropping the write transaction only
-	removed, err = pds.DeleteBeforeTx(ctx, relOverwrittenAt)
+	removed, err = pds.DeleteBeforeTx(ctx, watermarksAt(relOverwrittenAt))
 	require.NoError(err)
 	require.Equal(int64(1), removed.Relationships) // wroteOneRelationship
 	require.Equal(int64(1), removed.Transactions)  // wroteOneRelationship
 	require.Zero(removed.Namespaces)
 
 	// Run GC again and ensure there are no changes.
-	removed, err = pds.DeleteBeforeTx(ctx, relOverwrittenAt)
+	removed, err = pds.DeleteBeforeTx(ctx, watermarksAt(relOverwrittenAt))
 	require.NoError(err)
 	require.Zero(removed.Relationships)
 	require.Zero(removed.Transactions)
@@ -511,14 +524,14 @@ func GarbageCollectionTest(t *testing.T, ds datastore.Datastore) {
 	tRequire.NoRelationshipExists(ctx, rel, relDeletedAt)
 
 	// Run GC, which will now drop the overwrite transaction only and the first rel revision
-	removed, err = pds.DeleteBeforeTx(ctx, relDeletedAt)
+	removed, err = pds.DeleteBeforeTx(ctx, watermarksAt(relDeletedAt))
 	require.NoError(err)
 	require.Equal(int64(1), removed.Relationships)
 	require.Equal(int64(1), removed.Transactions) // relOverwrittenAt
 	require.Zero(removed.Namespaces)
 
 	// Run GC again and ensure there are no changes.
-	removed, err = pds.DeleteBeforeTx(ctx, relDeletedAt)
+	removed, err = pds.DeleteBeforeTx(ctx, watermarksAt(relDeletedAt))
 	require.NoError(err)
 	require.Zero(removed.Relationships)
 	require.Zero(removed.Transactions)
@@ -536,7 +549,7 @@ func GarbageCollectionTest(t *testing.T, ds datastore.Datastore) {
 
 	// Run GC at the transaction and ensure the older copies of the relationships are removed,
 	// as well as the 2 older write transactions and the older delete transaction.
-	removed, err = pds.DeleteBeforeTx(ctx, relLastWriteAt)
+	removed, err = pds.DeleteBeforeTx(ctx, watermarksAt(relLastWriteAt))
 	require.NoError(err)
 	require.Equal(int64(2), removed.Relationships) // delete, old1
 	require.Equal(int64(3), removed.Transactions)  // removed, write1, write2
@@ -552,13 +565,57 @@ func GarbageCollectionTest(t *testing.T, ds datastore.Datastore) {
 	require.NoError(err)
 
 	// Run GC to clean up the last write
-	removed, err = pds.DeleteBeforeTx(ctx, lastRev)
+	removed, err = pds.DeleteBeforeTx(ctx, watermarksAt(lastRev))
 	require.NoError(err)
 	require.Zero(removed.Relationships)           // write3
 	require.Equal(int64(1), removed.Transactions) // write3
 	require.Zero(removed.Namespaces)
 }
 
+func RevisionPinTest(t *testing.T, ds datastore.Datastore) {
+	require := require.New(t)
+
+	ctx := context.Background()
+	_, err := ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		return rwt.WriteNamespaces(ctx, namespace.Namespace(
+			"resource",
+			namespace.MustRelation("reader", nil),
+		), namespace.Namespace("user"))
+	})
+	require.NoError(err)
+
+	rel := tuple.MustParse("resource:someresource#reader@user:someuser#...")
+	wroteRelationship, err := common.WriteRelationships(ctx, ds, tuple.UpdateOperationCreate, rel)
+	require.NoError(err)
+
+	pds := ds.(*pgDatastore)
+	pinID, err := pds.PinRevision(ctx, wroteRelationship, time.Now().Add(time.Hour))
+	require.NoError(err)
+
+	_, err = common.WriteRelationships(ctx, ds, tuple.UpdateOperationDelete, rel)
+	require.NoError(err)
+
+	readAtPin := func() bool {
+		found, ok, err := datastore.FirstRelationshipIn(lo.Must(ds.SnapshotReader(wroteRelationship).QueryRelationships(ctx, datastore.RelationshipsFilter{
+			OptionalResourceType: "resource",
+		})))
+		require.NoError(err)
+		return ok && tuple.Equal(found, rel)
+	}
+
+	// The deleted relationship is retained while its revision is pinned.
+	time.Sleep(1 * time.Millisecond)
+	pds.ResetGCCompleted()
+	require.NoError(common.RunGarbageCollection(pds, 1*time.Millisecond, 10*time.Second))
+	require.True(pds.HasGCRun())
+	require.True(readAtPin())
+
+	// Once unpinned, it is collected.
+	require.NoError(pds.UnpinRevision(ctx, pinID))
+	require.NoError(common.RunGarbageCollection(pds, 1*time.Millisecond, 10*time.Second))
+	require.False(readAtPin())
+}
+
 func TransactionTimestampsTest(t *testing.T, ds datastore.Datastore) {
 	require := require.New(t)
 
@@ -630,7 +687,7 @@ func GarbageCollectionByTimeTest(t *testing.T, ds datastore.Datastore) {
 	afterWriteTx, err := pds.TxIDBefore(ctx, afterWrite)
 	require.NoError(err)
 
-	removed, err := pds.DeleteBeforeTx(ctx, afterWriteTx)
+	removed, err := pds.DeleteBeforeTx(ctx, watermarksAt(afterWriteTx))
 	require.NoError(err)
 	require.Zero(removed.Relationships)
 	require.True(removed.Transactions > 0)
@@ -660,7 +717,7 @@ func GarbageCollectionByTimeTest(t *testing.T, ds datastore.Datastore) {
 	afterDeleteTx, err := pds.TxIDBefore(ctx, afterDelete)
 	require.NoError(err)
 
-	removed, err = pds.DeleteBeforeTx(ctx, afterDeleteTx)
+	removed, err = pds.DeleteBeforeTx(ctx, watermarksAt(afterDeleteTx))
 	require.NoError(err)
 	require.Equal(int64(1), removed.Relationships)
 	require.Equal(int64(2), removed.Transactions) // relDeletedAt, injected
@@ -672,6 +729,44 @@ func GarbageCollectionByTimeTest(t *testing.T, ds datastore.Datastore) {
 
 const chunkRelationshipCount = 2000
 
+func LeaseTest(t *testing.T, ds datastore.Datastore) {
+	require := require.New(t)
+
+	ctx := context.Background()
+	pds := ds.(*pgDatastore)
+
+	acquired, err := pds.TryAcquireLease(ctx, "somejob", "first", time.Hour)
+	require.NoError(err)
+	require.True(acquired)
+
+	// The holder renews the lease, while other holders cannot acquire it.
+	acquired, err = pds.TryAcquireLease(ctx, "somejob", "first", time.Hour)
+	require.NoError(err)
+	require.True(acquired)
+
+	acquired, err = pds.TryAcquireLease(ctx, "somejob", "second", time.Hour)
+	require.NoError(err)
+	require.False(acquired)
+
+	// Other holders cannot release the lease.
+	require.NoError(pds.ReleaseLease(ctx, "somejob", "second"))
+	acquired, err = pds.TryAcquireLease(ctx, "somejob", "second", time.Hour)
+	require.NoError(err)
+	require.False(acquired)
+
+	// Once released, the lease is acquired by another holder.
+	require.NoError(pds.ReleaseLease(ctx, "somejob", "first"))
+	acquired, err = pds.TryAcquireLease(ctx, "somejob", "second", 1*time.Millisecond)
+	require.NoError(err)
+	require.True(acquired)
+
+	// Once expired, the lease is taken over by another holder.
+	time.Sleep(10 * time.Millisecond)
+	acquired, err = pds.TryAcquireLease(ctx, "somejob", "first", time.Hour)
+	require.NoError(err)
+	require.True(acquired)
+}
+
 func ChunkedGarbageCollectionTest(t *testing.T, ds datastore.Datastore) {
 	require := require.New(t)
 
@@ -714,7 +809,7 @@ func ChunkedGarbageCollectionTest(t *testing.T, ds datastore.Datastore) {
 	afterWriteTx, err := pds.TxIDBefore(ctx, afterWrite)
 	require.NoError(err)
 
-	removed, err := pds.DeleteBeforeTx(ctx, afterWriteTx)
+	removed, err := pds.DeleteBeforeTx(ctx, watermarksAt(afterWriteTx))
 	require.NoError(err)
 	require.Zero(removed.Relationships)
 	require.True(removed.Transactions > 0)
@@ -748,7 +843,7 @@ func ChunkedGarbageCollectionTest(t *testing.T, ds datastore.Datastore) {
 	afterDeleteTx, err := pds.TxIDBefore(ctx, afterDelete)
 	require.NoError(err)
 
-	removed, err = pds.DeleteBeforeTx(ctx, afterDeleteTx)
+	removed, err = pds.DeleteBeforeTx(ctx, watermarksAt(afterDeleteTx))
 	require.NoError(err)
 	require.Equal(int64(chunkRelationshipCount), removed.Relationships)
 	require.Equal(int64(2), removed.Transactions)
@@ -1485,7 +1580,7 @@ func GCQueriesServedByExpectedIndexes(t *testing.T, _ testdatastore.RunningEngin
 	casted := datastore.UnwrapAs[common.GarbageCollector](ds)
 	require.NotNil(casted)
 
-	_, err = casted.DeleteBeforeTx(context.Background(), revision)
+	_, err = casted.DeleteBeforeTx(context.Background(), watermarksAt(revision))
 	require.NoError(err)
 
 	require.NotEmpty(interceptor.explanations, "expected queries to be executed")
@@ -1579,6 +1674,31 @@ func LockingTest(t *testing.T, ds datastore.Datastore, ds2 datastore.Datastore)
 	require.NoError(t, err)
 }
 
+func LowPriorityTest(t *testing.T, ds datastore.Datastore) {
+	require := require.New(t)
+	pds := ds.(*pgDatastore)
+	require.IsType(&priorityPooler{}, pds.readPool)
+
+	statementTimeout := func(ctx context.Context) string {
+		var timeout string
+		_, err := ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+			return rwt.(*pgReadWriteTXN).tx.QueryRow(ctx, "SHOW statement_timeout").Scan(&timeout)
+		})
+		require.NoError(err)
+		return timeout
+	}
+
+	ctx := context.Background()
+	require.Equal("1500ms", statementTimeout(priority.ContextWithClass(ctx, priority.Background)))
+	require.Equal("1500ms", statementTimeout(priority.ContextWithClass(ctx, priority.Batch)))
+	require.NotEqual("1500ms", statementTimeout(ctx))
+
+	// The reads of low priority requests are served by their own pool.
+	headRevision, err := ds.HeadRevision(priority.ContextWithClass(ctx, priority.Background))
+	require.NoError(err)
+	require.NotNil(headRevision)
+}
+
 func StrictReadModeTest(t *testing.T, primaryDS datastore.Datastore, replicaDS datastore.Datastore) {
 	require := require.New(t)
 
@@ -1838,3 +1958,8 @@ func CheckpointsOnOutOfBandChangesTest(t *testing.T, ds datastore.Datastore) {
 }
 
 const waitForChangesTimeout = 30 * time.Second
+
+// watermarksAt returns the watermarks collecting the history of all tables before the revision.
+func watermarksAt(revision datastore.Revision) common.Watermarks {
+	return common.Watermarks{Relationships: revision, Namespaces: revision, Transactions: revision}
+}
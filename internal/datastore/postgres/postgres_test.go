@@ -54,6 +54,23 @@ func TestPostgresDatastoreGC(t *testing.T) {
 				MigrationPhase(config.migrationPhase),
 			))
 
+			t.Run("RevisionPin", createDatastoreTest(
+				b,
+				RevisionPinTest,
+				RevisionQuantization(0),
+				GCWindow(1*time.Millisecond),
+				GCInterval(veryLargeGCInterval),
+				WatchBufferLength(1),
+				MigrationPhase(config.migrationPhase),
+			))
+
+			t.Run("Lease", createDatastoreTest(
+				b,
+				LeaseTest,
+				WatchBufferLength(1),
+				MigrationPhase(config.migrationPhase),
+			))
+
 			t.Run("ChunkedGarbageCollection", createDatastoreTest(
 				b,
 				ChunkedGarbageCollectionTest,
@@ -0,0 +1,72 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	pgxcommon "github.com/authzed/spicedb/internal/datastore/postgres/common"
+	"github.com/authzed/spicedb/pkg/middleware/priority"
+)
+
+// priorityPooler serves the queries of batch and background requests from a pool of their own,
+// so that they cannot take the connections of the pool of interactive requests.
+type priorityPooler struct {
+	interactive pgxcommon.ConnPooler
+	lowPriority pgxcommon.ConnPooler
+}
+
+var _ pgxcommon.ConnPooler = (*priorityPooler)(nil)
+
+func (p *priorityPooler) poolFor(ctx context.Context) pgxcommon.ConnPooler {
+	if priority.FromContext(ctx) == priority.Interactive {
+		return p.interactive
+	}
+	return p.lowPriority
+}
+
+func (p *priorityPooler) Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error) {
+	return p.poolFor(ctx).Exec(ctx, sql, arguments...)
+}
+
+func (p *priorityPooler) Query(ctx context.Context, sql string, optionsAndArgs ...any) (pgx.Rows, error) {
+	return p.poolFor(ctx).Query(ctx, sql, optionsAndArgs...)
+}
+
+func (p *priorityPooler) QueryRow(ctx context.Context, sql string, optionsAndArgs ...any) pgx.Row {
+	return p.poolFor(ctx).QueryRow(ctx, sql, optionsAndArgs...)
+}
+
+func (p *priorityPooler) Begin(ctx context.Context) (pgx.Tx, error) {
+	return p.poolFor(ctx).Begin(ctx)
+}
+
+func (p *priorityPooler) BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error) {
+	return p.poolFor(ctx).BeginTx(ctx, txOptions)
+}
+
+func (p *priorityPooler) CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error) {
+	return p.poolFor(ctx).CopyFrom(ctx, tableName, columnNames, rowSrc)
+}
+
+func (p *priorityPooler) Close() {
+	p.interactive.Close()
+	p.lowPriority.Close()
+}
+
+// setLowPriorityStatementTimeout bounds the duration of the statements of the transaction if it
+// serves a batch or background request and a timeout is configured.
+func setLowPriorityStatementTimeout(ctx context.Context, tx pgx.Tx, timeout time.Duration) error {
+	if timeout <= 0 || priority.FromContext(ctx) == priority.Interactive {
+		return nil
+	}
+
+	// A timeout of zero disables it, so it is rounded up to at least a millisecond.
+	if _, err := tx.Exec(ctx, fmt.Sprintf("SET LOCAL statement_timeout = %d", max(timeout.Milliseconds(), 1))); err != nil {
+		return fmt.Errorf("failed to set the statement timeout of a low priority transaction: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+
+	pgxcommon "github.com/authzed/spicedb/internal/datastore/postgres/common"
+	"github.com/authzed/spicedb/pkg/middleware/priority"
+)
+
+// recordingPooler records the queries it is asked to run.
+type recordingPooler struct {
+	pgxcommon.ConnPooler
+
+	queries []string
+	closed  bool
+}
+
+func (p *recordingPooler) Query(_ context.Context, sql string, _ ...any) (pgx.Rows, error) {
+	p.queries = append(p.queries, sql)
+	return nil, nil
+}
+
+func (p *recordingPooler) Close() {
+	p.closed = true
+}
+
+func TestPriorityPooler(t *testing.T) {
+	interactive, lowPriority := &recordingPooler{}, &recordingPooler{}
+	pooler := &priorityPooler{interactive: interactive, lowPriority: lowPriority}
+
+	ctx := context.Background()
+	for _, tc := range []struct {
+		ctx   context.Context
+		query string
+	}{
+		{ctx, "interactive by default"},
+		{priority.ContextWithClass(ctx, priority.Interactive), "interactive"},
+		{priority.ContextWithClass(ctx, priority.Batch), "batch"},
+		{priority.ContextWithClass(ctx, priority.Background), "background"},
+	} {
+		_, err := pooler.Query(tc.ctx, tc.query)
+		require.NoError(t, err)
+	}
+
+	require.Equal(t, []string{"interactive by default", "interactive"}, interactive.queries)
+	require.Equal(t, []string{"batch", "background"}, lowPriority.queries)
+
+	pooler.Close()
+	require.True(t, interactive.closed)
+	require.True(t, lowPriority.closed)
+}
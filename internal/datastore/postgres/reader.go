@@ -18,13 +18,18 @@ import (
 type pgReader struct {
 	query                pgxcommon.DBFuncQuerier
 	executor             common.QueryRelationshipsExecutor
-	aliveFilter          queryFilterer
+	alive                common.Predicate
 	filterMaximumIDCount uint16
 	schema               common.SchemaInformation
 }
 
 type queryFilterer func(original sq.SelectBuilder) sq.SelectBuilder
 
+// aliveFilter filters a query to the rows alive in the reader.
+func (r *pgReader) aliveFilter(original sq.SelectBuilder) sq.SelectBuilder {
+	return r.alive.Filter(original)
+}
+
 var (
 	countRels = psql.Select("COUNT(*)").From(tableTuple)
 
@@ -150,7 +155,7 @@ func (r *pgReader) QueryRelationships(
 	opts ...options.QueryOptionsOption,
 ) (iter datastore.RelationshipIterator, err error) {
 	qBuilder, err := common.NewSchemaQueryFiltererForRelationshipsSelect(r.schema, r.filterMaximumIDCount).
-		WithAdditionalFilter(r.aliveFilter).
+		WithAdditionalPredicate(r.alive).
 		FilterWithRelationshipsFilter(filter)
 	if err != nil {
 		return nil, err
@@ -159,13 +164,35 @@ func (r *pgReader) QueryRelationships(
 	return r.executor.ExecuteQuery(ctx, qBuilder, opts...)
 }
 
+func (r *pgReader) QueryRelationshipsBatch(
+	ctx context.Context,
+	filters []datastore.RelationshipsFilter,
+	opts ...options.QueryOptionsOption,
+) (datastore.TaggedRelationshipIterator, error) {
+	qBuilders := make([]common.SchemaQueryFilterer, 0, len(filters))
+	for _, filter := range filters {
+		qBuilder, err := common.NewSchemaQueryFiltererForRelationshipsSelect(r.schema, r.filterMaximumIDCount).
+			WithAdditionalPredicate(r.alive).
+			FilterWithRelationshipsFilter(filter)
+		if err != nil {
+			return nil, err
+		}
+		qBuilders = append(qBuilders, qBuilder)
+	}
+
+	return r.executor.ExecuteBatchQuery(ctx, qBuilders, opts...)
+}
+
+// ReverseQueryRelationships reads the relationships of the subjects. Unlike the MySQL and CRDB
+// datastores, no index hint is given: Postgres has none, and its cost-based planner already
+// chooses between the subject covering index and the resource indexes from its own statistics.
 func (r *pgReader) ReverseQueryRelationships(
 	ctx context.Context,
 	subjectsFilter datastore.SubjectsFilter,
 	opts ...options.ReverseQueryOptionsOption,
 ) (iter datastore.RelationshipIterator, err error) {
 	qBuilder, err := common.NewSchemaQueryFiltererForRelationshipsSelect(r.schema, r.filterMaximumIDCount).
-		WithAdditionalFilter(r.aliveFilter).
+		WithAdditionalPredicate(r.alive).
 		FilterWithSubjectsSelectors(subjectsFilter.AsSelector())
 	if err != nil {
 		return nil, err
@@ -0,0 +1,343 @@
+package postgres
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgproto3"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/authzed/spicedb/pkg/datastore"
+)
+
+const (
+	// The parameters to this format string are:
+	// 1: the name of the slot
+	queryCreateReplicationSlot = "CREATE_REPLICATION_SLOT %s TEMPORARY LOGICAL wal2json NOEXPORT_SNAPSHOT"
+
+	// The parameters to this format string are:
+	// 1: the name of the slot
+	// 2: the position from which to stream
+	// 3: the tables to stream, as wal2json table patterns
+	queryStartReplication = `START_REPLICATION SLOT %s LOGICAL %s ("format-version" '2', "include-transaction" 'true', "add-tables" '%s')`
+
+	queryCurrentWALPosition = "SELECT pg_current_wal_lsn()::text"
+
+	replicationSlotPrefix = "spicedb_watch_"
+
+	xLogDataByteID            = 'w'
+	primaryKeepaliveByteID    = 'k'
+	standbyStatusUpdateByteID = 'r'
+)
+
+// postgresEpoch is the epoch of the timestamps of the replication protocol.
+var postgresEpoch = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// walPosition is a position in the write-ahead log of Postgres, also known as an LSN.
+type walPosition uint64
+
+func parseWALPosition(position string) (walPosition, error) {
+	high, low, ok := strings.Cut(position, "/")
+	if !ok {
+		return 0, fmt.Errorf("malformed WAL position: %s", position)
+	}
+
+	highValue, err := strconv.ParseUint(high, 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("malformed WAL position: %s", position)
+	}
+
+	lowValue, err := strconv.ParseUint(low, 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("malformed WAL position: %s", position)
+	}
+
+	return walPosition(highValue<<32 | lowValue), nil
+}
+
+func (p walPosition) String() string {
+	return fmt.Sprintf("%X/%X", uint32(p>>32), uint32(p))
+}
+
+// replicationConn is a replication connection streaming the changes of a temporary logical
+// replication slot, decoded by the wal2json output plugin. The slot is dropped by Postgres when
+// the connection is closed, so that no slot outlives the watch which created it.
+type replicationConn struct {
+	conn     *pgconn.PgConn
+	slotName string
+}
+
+func connectReplication(ctx context.Context, url string, credentialsProvider datastore.CredentialsProvider) (*replicationConn, error) {
+	config, err := pgconn.ParseConfig(url)
+	if err != nil {
+		return nil, err
+	}
+	config.RuntimeParams["replication"] = "database"
+	config.RuntimeParams["timezone"] = "UTC"
+
+	if credentialsProvider != nil {
+		config.User, config.Password, err = credentialsProvider.Get(ctx, fmt.Sprintf("%s:%d", config.Host, config.Port), config.User)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	conn, err := pgconn.ConnectConfig(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &replicationConn{
+		conn:     conn,
+		slotName: fmt.Sprintf("%s%016x", replicationSlotPrefix, rand.Uint64()),
+	}, nil
+}
+
+func (rc *replicationConn) Close(ctx context.Context) error {
+	return rc.conn.Close(ctx)
+}
+
+// createSlot creates the temporary replication slot of the connection, returning the position from
+// which it streams the transactions committed.
+func (rc *replicationConn) createSlot(ctx context.Context) (walPosition, error) {
+	results, err := rc.conn.Exec(ctx, fmt.Sprintf(queryCreateReplicationSlot, rc.slotName)).ReadAll()
+	if err != nil {
+		return 0, fmt.Errorf("unable to create replication slot: %w", err)
+	}
+
+	if len(results) != 1 || len(results[0].Rows) != 1 || len(results[0].Rows[0]) < 2 {
+		return 0, fmt.Errorf("unexpected result creating replication slot %s", rc.slotName)
+	}
+
+	return parseWALPosition(string(results[0].Rows[0][1]))
+}
+
+// startReplication starts streaming the changes of the given tables from the given position.
+func (rc *replicationConn) startReplication(ctx context.Context, start walPosition, tableNames []string) error {
+	patterns := make([]string, 0, len(tableNames))
+	for _, tableName := range tableNames {
+		// The tables are matched in any schema, as they are found through the search path.
+		patterns = append(patterns, "*."+tableName)
+	}
+
+	rc.conn.Frontend().Send(&pgproto3.Query{String: fmt.Sprintf(queryStartReplication, rc.slotName, start, strings.Join(patterns, ","))})
+	if err := rc.conn.Frontend().Flush(); err != nil {
+		return fmt.Errorf("unable to start replication: %w", err)
+	}
+
+	for {
+		msg, err := rc.conn.ReceiveMessage(ctx)
+		if err != nil {
+			return fmt.Errorf("unable to start replication: %w", err)
+		}
+
+		switch msg := msg.(type) {
+		case *pgproto3.CopyBothResponse:
+			return nil
+		case *pgproto3.ErrorResponse:
+			return fmt.Errorf("unable to start replication: %w", pgconn.ErrorResponseToPgError(msg))
+		case *pgproto3.NoticeResponse, *pgproto3.ParameterStatus:
+		default:
+			return fmt.Errorf("unexpected message starting replication: %T", msg)
+		}
+	}
+}
+
+// replicationMessage is a message of the replication stream, either WAL data or a keepalive.
+type replicationMessage struct {
+	keepalive      bool
+	replyRequested bool
+
+	walStart walPosition
+	walEnd   walPosition
+	data     []byte
+}
+
+// receive returns the next message of the replication stream.
+func (rc *replicationConn) receive(ctx context.Context) (replicationMessage, error) {
+	for {
+		msg, err := rc.conn.ReceiveMessage(ctx)
+		if err != nil {
+			return replicationMessage{}, err
+		}
+
+		switch msg := msg.(type) {
+		case *pgproto3.CopyData:
+			return parseReplicationMessage(msg.Data)
+		case *pgproto3.ErrorResponse:
+			return replicationMessage{}, pgconn.ErrorResponseToPgError(msg)
+		case *pgproto3.CopyDone:
+			return replicationMessage{}, io.EOF
+		case *pgproto3.NoticeResponse, *pgproto3.ParameterStatus:
+		default:
+			return replicationMessage{}, fmt.Errorf("unexpected message in replication stream: %T", msg)
+		}
+	}
+}
+
+func parseReplicationMessage(data []byte) (replicationMessage, error) {
+	if len(data) == 0 {
+		return replicationMessage{}, errors.New("empty replication message")
+	}
+
+	switch data[0] {
+	case xLogDataByteID:
+		if len(data) < 25 {
+			return replicationMessage{}, errors.New("truncated WAL data message")
+		}
+		return replicationMessage{
+			walStart: walPosition(binary.BigEndian.Uint64(data[1:])),
+			walEnd:   walPosition(binary.BigEndian.Uint64(data[9:])),
+			data:     data[25:],
+		}, nil
+
+	case primaryKeepaliveByteID:
+		if len(data) < 18 {
+			return replicationMessage{}, errors.New("truncated keepalive message")
+		}
+		return replicationMessage{
+			keepalive:      true,
+			walEnd:         walPosition(binary.BigEndian.Uint64(data[1:])),
+			replyRequested: data[17] != 0,
+		}, nil
+
+	default:
+		return replicationMessage{}, fmt.Errorf("unknown replication message type: %q", data[0])
+	}
+}
+
+// sendStandbyStatus reports the position up to which the stream was processed, allowing Postgres
+// to release the WAL before it. If replyRequested is set, Postgres replies with a keepalive
+// carrying the position it has streamed up to.
+func (rc *replicationConn) sendStandbyStatus(processed walPosition, now time.Time, replyRequested bool) error {
+	rc.conn.Frontend().Send(&pgproto3.CopyData{Data: encodeStandbyStatus(processed, now, replyRequested)})
+	return rc.conn.Frontend().Flush()
+}
+
+func encodeStandbyStatus(processed walPosition, now time.Time, replyRequested bool) []byte {
+	data := make([]byte, 0, 34)
+	data = append(data, standbyStatusUpdateByteID)
+	for range 3 {
+		// The positions written, flushed and applied.
+		data = binary.BigEndian.AppendUint64(data, uint64(processed))
+	}
+	data = binary.BigEndian.AppendUint64(data, uint64(now.Sub(postgresEpoch).Microseconds()))
+	if replyRequested {
+		return append(data, 1)
+	}
+	return append(data, 0)
+}
+
+// wal2jsonMessage is a change decoded by the wal2json output plugin, in its format version 2.
+type wal2jsonMessage struct {
+	// Action is B and C for the beginning and commit of transactions, and I, U and D for the
+	// insertion, update and deletion of rows.
+	Action  string           `json:"action"`
+	Table   string           `json:"table"`
+	Columns []wal2jsonColumn `json:"columns"`
+}
+
+type wal2jsonColumn struct {
+	Name  string          `json:"name"`
+	Value json.RawMessage `json:"value"`
+}
+
+// text returns the value of the column in the text format of Postgres, or false if the column is
+// absent or NULL. Columns whose value is unchanged and stored out of line are absent from updates.
+func (m wal2jsonMessage) text(name string) (string, bool, error) {
+	for _, column := range m.Columns {
+		if column.Name != name {
+			continue
+		}
+
+		if len(column.Value) == 0 || string(column.Value) == "null" {
+			return "", false, nil
+		}
+
+		// Numbers and booleans are given as JSON literals, all other values as strings.
+		if column.Value[0] != '"' {
+			return string(column.Value), true, nil
+		}
+
+		var value string
+		if err := json.Unmarshal(column.Value, &value); err != nil {
+			return "", false, fmt.Errorf("malformed value of column %s: %w", name, err)
+		}
+		return value, true, nil
+	}
+
+	return "", false, nil
+}
+
+func (m wal2jsonMessage) requiredText(name string) (string, error) {
+	value, ok, err := m.text(name)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", fmt.Errorf("missing column %s in change of table %s", name, m.Table)
+	}
+	return value, nil
+}
+
+func (m wal2jsonMessage) xid(name string) (xid8, error) {
+	value, err := m.requiredText(name)
+	if err != nil {
+		return xid8{}, err
+	}
+
+	parsed, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return xid8{}, fmt.Errorf("malformed transaction ID in column %s: %s", name, value)
+	}
+	return newXid8(parsed), nil
+}
+
+func (m wal2jsonMessage) bytes(name string) ([]byte, error) {
+	value, err := m.requiredText(name)
+	if err != nil {
+		return nil, err
+	}
+
+	encoded, ok := strings.CutPrefix(value, `\x`)
+	if !ok {
+		return nil, fmt.Errorf("malformed bytes in column %s", name)
+	}
+	return hex.DecodeString(encoded)
+}
+
+func (m wal2jsonMessage) jsonObject(name string) (map[string]any, error) {
+	value, ok, err := m.text(name)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	var object map[string]any
+	if err := json.Unmarshal([]byte(value), &object); err != nil {
+		return nil, fmt.Errorf("malformed JSON in column %s: %w", name, err)
+	}
+	return object, nil
+}
+
+func (m wal2jsonMessage) timestamp(typeMap *pgtype.Map, oid uint32, name string) (*time.Time, error) {
+	value, ok, err := m.text(name)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	var timestamp time.Time
+	if err := typeMap.Scan(oid, pgtype.TextFormatCode, []byte(value), &timestamp); err != nil {
+		return nil, fmt.Errorf("malformed timestamp in column %s: %w", name, err)
+	}
+	return &timestamp, nil
+}
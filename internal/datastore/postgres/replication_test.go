@@ -0,0 +1,157 @@
+package postgres
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+func TestWALPosition(t *testing.T) {
+	position, err := parseWALPosition("16/B374D848")
+	require.NoError(t, err)
+	require.Equal(t, walPosition(0x16_B374D848), position)
+	require.Equal(t, "16/B374D848", position.String())
+
+	for _, malformed := range []string{"", "16", "16/", "G/0", "1/100000000"} {
+		_, err := parseWALPosition(malformed)
+		require.Error(t, err, malformed)
+	}
+}
+
+func TestParseReplicationMessage(t *testing.T) {
+	xLogData := []byte{xLogDataByteID}
+	xLogData = binary.BigEndian.AppendUint64(xLogData, 100)
+	xLogData = binary.BigEndian.AppendUint64(xLogData, 200)
+	xLogData = binary.BigEndian.AppendUint64(xLogData, 0)
+	xLogData = append(xLogData, `{"action":"B"}`...)
+
+	msg, err := parseReplicationMessage(xLogData)
+	require.NoError(t, err)
+	require.Equal(t, replicationMessage{walStart: 100, walEnd: 200, data: []byte(`{"action":"B"}`)}, msg)
+
+	keepalive := []byte{primaryKeepaliveByteID}
+	keepalive = binary.BigEndian.AppendUint64(keepalive, 300)
+	keepalive = binary.BigEndian.AppendUint64(keepalive, 0)
+	keepalive = append(keepalive, 1)
+
+	msg, err = parseReplicationMessage(keepalive)
+	require.NoError(t, err)
+	require.Equal(t, replicationMessage{keepalive: true, replyRequested: true, walEnd: 300}, msg)
+
+	for _, malformed := range [][]byte{nil, xLogData[:24], keepalive[:17], {'x'}} {
+		_, err := parseReplicationMessage(malformed)
+		require.Error(t, err)
+	}
+}
+
+func TestEncodeStandbyStatus(t *testing.T) {
+	now := postgresEpoch.Add(5 * time.Second)
+	data := encodeStandbyStatus(42, now, true)
+	require.Len(t, data, 34)
+	require.Equal(t, byte(standbyStatusUpdateByteID), data[0])
+	for i := range 3 {
+		require.Equal(t, uint64(42), binary.BigEndian.Uint64(data[1+8*i:]))
+	}
+	require.Equal(t, uint64(5_000_000), binary.BigEndian.Uint64(data[25:]))
+	require.Equal(t, byte(1), data[33])
+
+	require.Equal(t, byte(0), encodeStandbyStatus(42, now, false)[33])
+}
+
+func wal2jsonChange(t *testing.T, change string) wal2jsonMessage {
+	var msg wal2jsonMessage
+	require.NoError(t, json.Unmarshal([]byte(change), &msg))
+	return msg
+}
+
+func TestReplicatedTransactionChanges(t *testing.T) {
+	w := &replicationWatch{
+		options: datastore.WatchOptions{Content: datastore.WatchRelationships | datastore.WatchSchema},
+		typeMap: pgtype.NewMap(),
+	}
+
+	revision, err := w.transactionRevision(wal2jsonChange(t, fmt.Sprintf(`{"action":"I","table":%q,"columns":[
+		{"name":%q,"value":12},
+		{"name":%q,"value":"11:13:11"},
+		{"name":%q,"value":"2024-05-06 07:08:09.123456"},
+		{"name":%q,"value":"{\"key\": \"value\"}"}
+	]}`, tableTransaction, colXID, colSnapshot, colTimestamp, colMetadata)))
+	require.NoError(t, err)
+	require.Equal(t, uint64(12), revision.optionalTxID.Uint64)
+	require.Equal(t, "11:13:11", revision.snapshot.String())
+	require.Equal(t, uint64(time.Date(2024, 5, 6, 7, 8, 9, 123456000, time.UTC).UnixNano()), revision.optionalNanosTimestamp)
+	require.Equal(t, map[string]any{"key": "value"}, revision.optionalMetadata)
+
+	namespaceBytes, err := (&core.NamespaceDefinition{Name: "document"}).MarshalVT()
+	require.NoError(t, err)
+
+	relationshipColumns := func(objectID string) string {
+		return fmt.Sprintf(`{"name":%q,"value":"document"},{"name":%q,"value":%q},{"name":%q,"value":"viewer"},
+			{"name":%q,"value":"user"},{"name":%q,"value":"tom"},{"name":%q,"value":"..."}`,
+			colNamespace, colObjectID, objectID, colRelation, colUsersetNamespace, colUsersetObjectID, colUsersetRelation)
+	}
+
+	txn := &replicatedTransaction{
+		revision: &revision,
+		changes: []wal2jsonMessage{
+			wal2jsonChange(t, fmt.Sprintf(`{"action":"I","table":%q,"columns":[%s,
+				{"name":%q,"value":"somecaveat"},{"name":%q,"value":"{\"answer\": 42}"},
+				{"name":%q,"value":"2030-01-02 03:04:05+00"},{"name":%q,"value":12},{"name":%q,"value":9223372036854775807}
+			]}`, tableTuple, relationshipColumns("foo"), colCaveatContextName, colCaveatContext, colExpiration, colCreatedXid, colDeletedXid)),
+			wal2jsonChange(t, fmt.Sprintf(`{"action":"U","table":%q,"columns":[%s,
+				{"name":%q,"value":null},{"name":%q,"value":null},{"name":%q,"value":7},{"name":%q,"value":12}
+			]}`, tableTuple, relationshipColumns("bar"), colCaveatContextName, colExpiration, colCreatedXid, colDeletedXid)),
+			wal2jsonChange(t, fmt.Sprintf(`{"action":"I","table":%q,"columns":[
+				{"name":%q,"value":"document"},{"name":%q,"value":"\\x%s"},{"name":%q,"value":12},{"name":%q,"value":9223372036854775807}
+			]}`, tableNamespace, colNamespace, colConfig, hex.EncodeToString(namespaceBytes), colCreatedXid, colDeletedXid)),
+			wal2jsonChange(t, fmt.Sprintf(`{"action":"U","table":%q,"columns":[
+				{"name":%q,"value":"somecaveat"},{"name":%q,"value":5},{"name":%q,"value":12}
+			]}`, tableCaveat, colCaveatName, colCreatedXid, colDeletedXid)),
+			// Rows of other transactions are ignored.
+			wal2jsonChange(t, fmt.Sprintf(`{"action":"U","table":%q,"columns":[%s,
+				{"name":%q,"value":null},{"name":%q,"value":null},{"name":%q,"value":7},{"name":%q,"value":13}
+			]}`, tableTuple, relationshipColumns("baz"), colCaveatContextName, colExpiration, colCreatedXid, colDeletedXid)),
+		},
+	}
+
+	changes, err := w.replicatedChanges(context.Background(), txn)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	require.Equal(t, revision, changes[0].Revision)
+	require.Equal(t, map[string]any{"key": "value"}, changes[0].Metadata.AsMap())
+
+	updates := make([]string, 0, len(changes[0].RelationshipChanges))
+	for _, update := range changes[0].RelationshipChanges {
+		updates = append(updates, update.OperationString()+" "+tuple.MustString(update.Relationship))
+	}
+	require.ElementsMatch(t, []string{
+		"TOUCH document:foo#viewer@user:tom[somecaveat:{\"answer\":42}][expiration:2030-01-02T03:04:05Z]",
+		"DELETE document:bar#viewer@user:tom",
+	}, updates)
+
+	require.Len(t, changes[0].ChangedDefinitions, 1)
+	require.Equal(t, "document", changes[0].ChangedDefinitions[0].GetName())
+	require.Equal(t, []string{"somecaveat"}, changes[0].DeletedCaveats)
+}
+
+func TestIsReplicationRetryableError(t *testing.T) {
+	require.True(t, isReplicationRetryableError(fmt.Errorf("receiving: %w", io.EOF)))
+	require.True(t, isReplicationRetryableError(&pgconn.PgError{Code: "57P01"}))
+	require.True(t, isReplicationRetryableError(&pgconn.PgError{Code: "08006"}))
+	require.False(t, isReplicationRetryableError(&pgconn.PgError{Code: "42704"}))
+	require.False(t, isReplicationRetryableError(errors.New("malformed change")))
+}
@@ -9,6 +9,7 @@ import (
 	"strings"
 	"time"
 
+	sq "github.com/Masterminds/squirrel"
 	"github.com/ccoveille/go-safecast"
 	"github.com/jackc/pgx/v5"
 
@@ -74,11 +75,35 @@ const (
 	queryLatestXID            = `SELECT max(xid)::text::integer FROM relation_tuple_transaction;`
 )
 
+// selectRevisionQuery returns the query selecting the optimized revision for the quantization.
+func selectRevisionQuery(quantization time.Duration) string {
+	quantizationPeriodNanos := max(quantization.Nanoseconds(), 1)
+	return fmt.Sprintf(
+		querySelectRevision,
+		colXID,
+		tableTransaction,
+		colTimestamp,
+		quantizationPeriodNanos,
+		colSnapshot,
+	)
+}
+
+func (pgd *pgDatastore) SetRevisionQuantization(quantization time.Duration) error {
+	if quantization >= pgd.gcWindow {
+		return fmt.Errorf(errQuantizationTooLarge, quantization, pgd.gcWindow)
+	}
+
+	revisionQuery := selectRevisionQuery(quantization)
+	pgd.optimizedRevisionQuery.Store(&revisionQuery)
+	pgd.SetMaxRevisionStaleness(time.Duration(float64(quantization.Nanoseconds())*pgd.maxStalenessPercent) * time.Nanosecond)
+	return nil
+}
+
 func (pgd *pgDatastore) optimizedRevisionFunc(ctx context.Context) (datastore.Revision, time.Duration, error) {
 	var revision xid8
 	var snapshot pgSnapshot
 	var validForNanos time.Duration
-	if err := pgd.readPool.QueryRow(ctx, pgd.optimizedRevisionQuery).
+	if err := pgd.readPool.QueryRow(ctx, *pgd.optimizedRevisionQuery.Load()).
 		Scan(&revision, &snapshot, &validForNanos); err != nil {
 		return datastore.NoRevision, 0, fmt.Errorf(errRevision, err)
 	}
@@ -387,3 +412,22 @@ var _ datastore.Revision = postgresRevision{}
 func revisionKeyFunc(rev postgresRevision) uint64 {
 	return rev.optionalTxID.Uint64
 }
+
+func (pgd *pgDatastore) RevisionAtTime(ctx context.Context, at time.Time) (datastore.Revision, error) {
+	sql, args, err := getRevision.Where(sq.LtOrEq{colTimestamp: at.UTC()}).ToSql()
+	if err != nil {
+		return datastore.NoRevision, err
+	}
+
+	var xid xid8
+	var snapshot pgSnapshot
+	if err := pgd.readPool.QueryRow(ctx, sql, args...).Scan(&xid, &snapshot); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return datastore.NoRevision, datastore.NewInvalidRevisionErr(datastore.NoRevision, datastore.RevisionStale)
+		}
+		return datastore.NoRevision, fmt.Errorf(errRevision, err)
+	}
+
+	// The revision reads the writes of the transaction itself.
+	return postgresRevision{snapshot: snapshot.markComplete(xid.Uint64), optionalTxID: xid}, nil
+}
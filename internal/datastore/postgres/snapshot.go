@@ -224,6 +224,39 @@ func (s pgSnapshot) markComplete(txid uint64) pgSnapshot {
 	return newSnapshot
 }
 
+// union will create a new snapshot in which the transactions visible in either this snapshot or
+// the specified one are visible. For example, this combines the snapshot of a transaction with
+// that of transactions known to have committed after it started.
+func (s pgSnapshot) union(rhs pgSnapshot) pgSnapshot {
+	var xipList []uint64
+
+	// The transactions in progress below the xmax of this snapshot are those in progress in both.
+	for _, txid := range s.xipList {
+		if !rhs.txVisible(txid) {
+			xipList = append(xipList, txid)
+		}
+	}
+
+	// Above it, the transactions in progress are those in progress in the specified snapshot.
+	for _, txid := range rhs.xipList {
+		if txid >= s.xmax {
+			xipList = append(xipList, txid)
+		}
+	}
+
+	newSnapshot := pgSnapshot{
+		xmax:    max(s.xmax, rhs.xmax),
+		xipList: xipList,
+	}
+	if len(xipList) > 0 {
+		newSnapshot.xmin = xipList[0]
+	} else {
+		newSnapshot.xmin = newSnapshot.xmax
+	}
+
+	return newSnapshot
+}
+
 // markInProgress will create a new snapshot where the specified transaction will be marked as
 // in-progress and therefore invisible. For example, if the specified xmin falls between two
 // values in the xip list, it will be inserted in order.
@@ -92,6 +92,34 @@ func TestMarkInProgress(t *testing.T) {
 	}
 }
 
+func TestUnion(t *testing.T) {
+	testCases := []struct {
+		snapshot pgSnapshot
+		other    pgSnapshot
+		expected pgSnapshot
+	}{
+		{snap(5, 5), snap(5, 5), snap(5, 5)},
+		{snap(3, 5, 3), snap(5, 5), snap(5, 5)},
+		{snap(3, 5, 3), snap(3, 8, 3, 6), snap(3, 8, 3, 6)},
+		{snap(3, 8, 3, 6), snap(4, 10, 4, 6, 9), snap(6, 10, 6, 9)},
+		{snap(4, 10, 4, 6, 9), snap(3, 8, 3, 6), snap(6, 10, 6, 9)},
+		{snap(10, 10), snap(3, 5, 3), snap(10, 10)},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(fmt.Sprintf("%s|%s=>%s", tc.snapshot, tc.other, tc.expected), func(t *testing.T) {
+			require := require.New(t)
+			union := tc.snapshot.union(tc.other)
+			require.Equal(tc.expected, union, "%s != %s", tc.expected, union)
+
+			for txid := range uint64(12) {
+				require.Equal(tc.snapshot.txVisible(txid) || tc.other.txVisible(txid), union.txVisible(txid), "visibility of %d", txid)
+			}
+		})
+	}
+}
+
 func snap(xmin, xmax uint64, xips ...uint64) pgSnapshot {
 	return pgSnapshot{
 		xmin, xmax, xips,
@@ -9,6 +9,7 @@ import (
 	sq "github.com/Masterminds/squirrel"
 	"github.com/ccoveille/go-safecast"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"google.golang.org/protobuf/types/known/structpb"
 
 	"github.com/authzed/spicedb/internal/datastore/common"
@@ -123,8 +124,42 @@ func (pgd *pgDatastore) Watch(
 		defer close(updates)
 		defer close(errs)
 
+		if options.IncludeSchemaSnapshot && options.Content&datastore.WatchSchema == datastore.WatchSchema {
+			snapshot, err := common.SchemaSnapshotChanges(ctx, pgd.SnapshotReader(afterRevision), afterRevision)
+			if err != nil {
+				errs <- err
+				return
+			}
+
+			if snapshot != nil && !sendChange(snapshot) {
+				return
+			}
+		}
+
 		currentTxn := afterRevision
 		requestedCheckpoints := options.Content&datastore.WatchCheckpoints == datastore.WatchCheckpoints
+		if pgd.watchLogicalReplication {
+			watch := &replicationWatch{
+				pgd:                  pgd,
+				options:              options,
+				checkpointInterval:   watchSleep,
+				requestedCheckpoints: requestedCheckpoints,
+				sendChange:           sendChange,
+				current:              currentTxn,
+				typeMap:              pgtype.NewMap(),
+			}
+
+			err := watch.run(ctx)
+			switch {
+			case errors.Is(err, errReplicationWatchStopped):
+			case errors.Is(ctx.Err(), context.Canceled), pgxcommon.IsCancellationError(err):
+				errs <- datastore.NewWatchCanceledErr()
+			case err != nil:
+				errs <- err
+			}
+			return
+		}
+
 		for {
 			newTxns, optionalHeadRevision, err := pgd.getNewRevisions(ctx, currentTxn, requestedCheckpoints)
 			if err != nil {
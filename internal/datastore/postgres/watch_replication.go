@@ -0,0 +1,579 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"github.com/ccoveille/go-safecast"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/authzed/spicedb/internal/datastore/common"
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/spiceerrors"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+const (
+	// standbyStatusInterval is the interval at which a replication watch reports its progress to
+	// Postgres, well within the default wal_sender_timeout of a minute.
+	standbyStatusInterval = 10 * time.Second
+
+	maxReplicationWatchRetries     = 5
+	replicationWatchInitialBackoff = 100 * time.Millisecond
+	replicationWatchMaxBackoff     = 5 * time.Second
+)
+
+// errReplicationWatchStopped is returned once a change could not be sent to the caller of a watch,
+// who was already told why.
+var errReplicationWatchStopped = errors.New("replication watch stopped")
+
+// replicationWatch is a watch reading the changes of transactions from a temporary logical
+// replication slot, rather than polling the transactions table. Transactions are streamed as they
+// commit, and in the order they commit.
+//
+// A slot only streams the transactions committed after its creation, so those committed since the
+// revision to watch from are first read from the transactions table, as when polling. If the
+// replication connection fails, such as when the primary fails over, the watch reconnects with a
+// new slot and resumes from the last revision it sent.
+type replicationWatch struct {
+	pgd                  *pgDatastore
+	options              datastore.WatchOptions
+	checkpointInterval   time.Duration
+	requestedCheckpoints bool
+	sendChange           func(*datastore.RevisionChanges) bool
+
+	// current is the revision up to which changes were sent.
+	current postgresRevision
+	typeMap *pgtype.Map
+}
+
+// replicatedTransaction accumulates the changes of a transaction streamed from a replication slot,
+// until its commit.
+type replicatedTransaction struct {
+	// revision is read from the row of the transactions table inserted by the transaction, and is
+	// nil for transactions not written by SpiceDB, such as those of garbage collection.
+	revision *postgresRevision
+	changes  []wal2jsonMessage
+}
+
+// headPosition is a head revision, along with the WAL position at which it was read. Once the
+// replication slot streamed past that position, all transactions in the revision were streamed.
+type headPosition struct {
+	revision postgresRevision
+	position walPosition
+}
+
+func (w *replicationWatch) run(ctx context.Context) error {
+	backoff := replicationWatchInitialBackoff
+	retries := 0
+	for {
+		streamed, err := w.session(ctx)
+		if ctx.Err() != nil || errors.Is(err, errReplicationWatchStopped) || !isReplicationRetryableError(err) {
+			return err
+		}
+
+		if streamed {
+			backoff = replicationWatchInitialBackoff
+			retries = 0
+		}
+
+		retries++
+		if retries > maxReplicationWatchRetries {
+			return fmt.Errorf("replication watch failed after %d retries: %w", maxReplicationWatchRetries, err)
+		}
+
+		log.Ctx(ctx).Warn().Err(err).Int("retries", retries).Dur("backoff", backoff).Msg("replication watch failed, reconnecting")
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		backoff = min(2*backoff, replicationWatchMaxBackoff)
+	}
+}
+
+// isReplicationRetryableError returns whether the error is one of the replication connection, after
+// which the watch can resume on a new connection.
+func isReplicationRetryableError(err error) bool {
+	var connectErr *pgconn.ConnectError
+	var netErr net.Error
+	var pgErr *pgconn.PgError
+	switch {
+	case errors.As(err, &connectErr), errors.As(err, &netErr), errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return true
+	case errors.As(err, &pgErr):
+		// Connection exceptions, and operator interventions such as the shutdown of the server.
+		return pgErr.Code[:2] == "08" || pgErr.Code[:2] == "57"
+	default:
+		return pgconn.SafeToRetry(err)
+	}
+}
+
+// session streams changes from a new replication slot until it fails, returning whether it
+// streamed at all.
+func (w *replicationWatch) session(ctx context.Context) (bool, error) {
+	conn, err := connectReplication(ctx, w.pgd.dburl, w.pgd.credentialsProvider)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = conn.Close(ctx) }()
+
+	start, err := conn.createSlot(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	if err := w.catchUp(ctx); err != nil {
+		return false, err
+	}
+
+	tableNames := []string{tableTransaction}
+	if w.options.Content&datastore.WatchRelationships == datastore.WatchRelationships {
+		tableNames = append(tableNames, tableTuple)
+	}
+	if w.options.Content&datastore.WatchSchema == datastore.WatchSchema {
+		tableNames = append(tableNames, tableNamespace, tableCaveat)
+	}
+
+	if err := conn.startReplication(ctx, start, tableNames); err != nil {
+		return false, err
+	}
+
+	processed := start
+	var txn *replicatedTransaction
+	var pendingHead *headPosition
+	nextStatus := time.Now().Add(standbyStatusInterval)
+	nextHead := time.Now()
+	for {
+		now := time.Now()
+
+		// Checkpoints at the head revision are sent once the slot streamed past it, as the head
+		// revision can move without any transaction being streamed.
+		if w.requestedCheckpoints && txn == nil && !now.Before(nextHead) {
+			if pendingHead == nil {
+				pendingHead, err = w.headPosition(ctx)
+				if err != nil {
+					return true, err
+				}
+			}
+
+			// Requesting a reply has Postgres send a keepalive with the position streamed so far.
+			if err := conn.sendStandbyStatus(processed, now, true); err != nil {
+				return true, err
+			}
+			nextStatus = now.Add(standbyStatusInterval)
+			nextHead = now.Add(w.checkpointInterval)
+		}
+
+		if !now.Before(nextStatus) {
+			if err := conn.sendStandbyStatus(processed, now, false); err != nil {
+				return true, err
+			}
+			nextStatus = now.Add(standbyStatusInterval)
+		}
+
+		deadline := nextStatus
+		if w.requestedCheckpoints && nextHead.Before(deadline) {
+			deadline = nextHead
+		}
+
+		receiveCtx, cancel := context.WithDeadline(ctx, deadline)
+		msg, err := conn.receive(receiveCtx)
+		cancel()
+		if err != nil {
+			if pgconn.Timeout(err) && ctx.Err() == nil {
+				continue
+			}
+			return true, err
+		}
+
+		if msg.keepalive {
+			if txn == nil {
+				processed = max(processed, msg.walEnd)
+				if pendingHead != nil && msg.walEnd >= pendingHead.position {
+					if pendingHead.revision.GreaterThan(w.current) {
+						w.current = pendingHead.revision
+						if !w.sendChange(&datastore.RevisionChanges{Revision: w.current, IsCheckpoint: true}) {
+							return true, errReplicationWatchStopped
+						}
+					}
+					pendingHead = nil
+				}
+			}
+
+			if msg.replyRequested {
+				if err := conn.sendStandbyStatus(processed, now, false); err != nil {
+					return true, err
+				}
+				nextStatus = now.Add(standbyStatusInterval)
+			}
+			continue
+		}
+
+		var change wal2jsonMessage
+		if err := json.Unmarshal(msg.data, &change); err != nil {
+			return true, fmt.Errorf("malformed change in replication stream: %w", err)
+		}
+
+		switch change.Action {
+		case "B":
+			txn = &replicatedTransaction{}
+
+		case "C":
+			if txn != nil {
+				if err := w.sendTransaction(ctx, txn); err != nil {
+					return true, err
+				}
+			}
+			txn = nil
+			processed = max(processed, msg.walStart+walPosition(len(msg.data)))
+
+		case "I", "U":
+			if txn == nil {
+				return true, spiceerrors.MustBugf("change streamed outside of a transaction")
+			}
+
+			if change.Table == tableTransaction {
+				if change.Action == "I" {
+					revision, err := w.transactionRevision(change)
+					if err != nil {
+						return true, err
+					}
+					txn.revision = &revision
+				}
+				continue
+			}
+			txn.changes = append(txn.changes, change)
+
+		default:
+			// Deletions are of rows garbage collected, and SpiceDB neither truncates tables nor
+			// emits logical decoding messages.
+		}
+	}
+}
+
+// catchUp sends the changes of the transactions committed after the current revision, read from
+// the transactions table.
+func (w *replicationWatch) catchUp(ctx context.Context) error {
+	newTxns, _, err := w.pgd.getNewRevisions(ctx, w.current, false)
+	if err != nil {
+		return err
+	}
+
+	if len(newTxns) == 0 {
+		return nil
+	}
+
+	changes, err := w.pgd.loadChanges(ctx, newTxns, w.options)
+	if err != nil {
+		return err
+	}
+
+	for i := range changes {
+		if !w.sendChange(&changes[i]) {
+			return errReplicationWatchStopped
+		}
+	}
+
+	last := newTxns[len(newTxns)-1]
+	snapshot := last.snapshot
+	for _, newTx := range newTxns {
+		snapshot = snapshot.markComplete(newTx.optionalTxID.Uint64)
+	}
+
+	return w.advance(postgresRevision{
+		snapshot:               snapshot,
+		optionalTxID:           last.optionalTxID,
+		optionalNanosTimestamp: last.optionalNanosTimestamp,
+	})
+}
+
+// sendTransaction sends the changes of a streamed transaction.
+func (w *replicationWatch) sendTransaction(ctx context.Context, txn *replicatedTransaction) error {
+	if txn.revision == nil {
+		return nil
+	}
+
+	// Transactions committed while catching up were already sent.
+	if w.current.snapshot.txVisible(txn.revision.optionalTxID.Uint64) {
+		return nil
+	}
+
+	changes, err := w.replicatedChanges(ctx, txn)
+	if err != nil {
+		return err
+	}
+
+	for i := range changes {
+		if !w.sendChange(&changes[i]) {
+			return errReplicationWatchStopped
+		}
+	}
+
+	return w.advance(*txn.revision)
+}
+
+// advance moves the current revision past the given one, sending a checkpoint if requested. The
+// snapshot of a transaction does not include the transactions which committed after it started,
+// so the snapshot of the new revision is combined with that of the current one.
+func (w *replicationWatch) advance(revision postgresRevision) error {
+	w.current = postgresRevision{
+		snapshot:               revision.snapshot.union(w.current.snapshot),
+		optionalTxID:           revision.optionalTxID,
+		optionalNanosTimestamp: revision.optionalNanosTimestamp,
+	}
+
+	if w.requestedCheckpoints && !w.sendChange(&datastore.RevisionChanges{Revision: w.current, IsCheckpoint: true}) {
+		return errReplicationWatchStopped
+	}
+	return nil
+}
+
+func (w *replicationWatch) headPosition(ctx context.Context) (*headPosition, error) {
+	var head *postgresRevision
+	var position walPosition
+	if err := pgx.BeginTxFunc(ctx, w.pgd.readPool, pgx.TxOptions{IsoLevel: pgx.RepeatableRead}, func(tx pgx.Tx) error {
+		var err error
+		head, err = w.pgd.getHeadRevision(ctx, tx)
+		if err != nil {
+			return fmt.Errorf("unable to get head revision: %w", err)
+		}
+
+		var positionText string
+		if err := tx.QueryRow(ctx, queryCurrentWALPosition).Scan(&positionText); err != nil {
+			return fmt.Errorf("unable to get WAL position: %w", err)
+		}
+
+		position, err = parseWALPosition(positionText)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	if head == nil {
+		return nil, spiceerrors.MustBugf("expected to have a head revision")
+	}
+
+	return &headPosition{revision: *head, position: position}, nil
+}
+
+// transactionRevision returns the revision of the transaction which inserted the row of the
+// transactions table.
+func (w *replicationWatch) transactionRevision(change wal2jsonMessage) (postgresRevision, error) {
+	xid, err := change.xid(colXID)
+	if err != nil {
+		return postgresRevision{}, err
+	}
+
+	snapshotText, err := change.requiredText(colSnapshot)
+	if err != nil {
+		return postgresRevision{}, err
+	}
+
+	var snapshot pgSnapshot
+	if err := snapshot.ScanText(pgtype.Text{String: snapshotText, Valid: true}); err != nil {
+		return postgresRevision{}, err
+	}
+
+	metadata, err := change.jsonObject(colMetadata)
+	if err != nil {
+		return postgresRevision{}, err
+	}
+
+	timestamp, err := change.timestamp(w.typeMap, pgtype.TimestampOID, colTimestamp)
+	if err != nil {
+		return postgresRevision{}, err
+	}
+	if timestamp == nil {
+		return postgresRevision{}, fmt.Errorf("missing column %s in change of table %s", colTimestamp, tableTransaction)
+	}
+
+	nanosTimestamp, err := safecast.ToUint64(timestamp.UnixNano())
+	if err != nil {
+		return postgresRevision{}, spiceerrors.MustBugf("could not cast timestamp to uint64")
+	}
+
+	return postgresRevision{
+		snapshot:               snapshot.markComplete(xid.Uint64),
+		optionalTxID:           xid,
+		optionalNanosTimestamp: nanosTimestamp,
+		optionalMetadata:       metadata,
+	}, nil
+}
+
+// replicatedChanges returns the changes of a streamed transaction. As when polling, rows created
+// by the transaction are touched and rows it marked deleted are deleted.
+func (w *replicationWatch) replicatedChanges(ctx context.Context, txn *replicatedTransaction) ([]datastore.RevisionChanges, error) {
+	revision := *txn.revision
+	xid := revision.optionalTxID.Uint64
+	tracked := common.NewChanges(revisionKeyFunc, w.options.Content, w.options.MaximumBufferedChangesByteSize)
+
+	if len(revision.optionalMetadata) > 0 {
+		if err := tracked.SetRevisionMetadata(ctx, revision, revision.optionalMetadata); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, change := range txn.changes {
+		createdXID, err := change.xid(colCreatedXid)
+		if err != nil {
+			return nil, err
+		}
+		created := change.Action == "I" && createdXID.Uint64 == xid
+
+		deletedXID, err := change.xid(colDeletedXid)
+		if err != nil {
+			return nil, err
+		}
+		deleted := deletedXID.Uint64 == xid
+
+		if !created && !deleted {
+			continue
+		}
+
+		switch change.Table {
+		case tableTuple:
+			relationship, err := w.replicatedRelationship(change)
+			if err != nil {
+				return nil, err
+			}
+
+			if created {
+				if err := tracked.AddRelationshipChange(ctx, revision, relationship, tuple.UpdateOperationTouch); err != nil {
+					return nil, err
+				}
+			}
+			if deleted {
+				if err := tracked.AddRelationshipChange(ctx, revision, relationship, tuple.UpdateOperationDelete); err != nil {
+					return nil, err
+				}
+			}
+
+		case tableNamespace:
+			if created {
+				config, err := change.bytes(colConfig)
+				if err != nil {
+					return nil, err
+				}
+
+				loaded := &core.NamespaceDefinition{}
+				if err := loaded.UnmarshalVT(config); err != nil {
+					return nil, fmt.Errorf(errUnableToReadConfig, err)
+				}
+
+				if err := tracked.AddChangedDefinition(ctx, revision, loaded); err != nil {
+					return nil, err
+				}
+			}
+			if deleted {
+				name, err := change.requiredText(colNamespace)
+				if err != nil {
+					return nil, err
+				}
+
+				if err := tracked.AddDeletedNamespace(ctx, revision, name); err != nil {
+					return nil, err
+				}
+			}
+
+		case tableCaveat:
+			if created {
+				definition, err := change.bytes(colCaveatDefinition)
+				if err != nil {
+					return nil, err
+				}
+
+				loaded := &core.CaveatDefinition{}
+				if err := loaded.UnmarshalVT(definition); err != nil {
+					return nil, fmt.Errorf(errUnableToReadConfig, err)
+				}
+
+				if err := tracked.AddChangedDefinition(ctx, revision, loaded); err != nil {
+					return nil, err
+				}
+			}
+			if deleted {
+				name, err := change.requiredText(colCaveatName)
+				if err != nil {
+					return nil, err
+				}
+
+				if err := tracked.AddDeletedCaveat(ctx, revision, name); err != nil {
+					return nil, err
+				}
+			}
+
+		default:
+			return nil, spiceerrors.MustBugf("unexpected table in replication stream: %s", change.Table)
+		}
+	}
+
+	return tracked.AsRevisionChanges(func(lhs, rhs uint64) bool { return false })
+}
+
+// replicatedRelationship returns the relationship of a row of the relationships table. The caveat
+// context of updated rows is absent if it is stored out of line, as it is then unchanged.
+func (w *replicationWatch) replicatedRelationship(change wal2jsonMessage) (tuple.Relationship, error) {
+	var values [6]string
+	for i, column := range []string{colNamespace, colObjectID, colRelation, colUsersetNamespace, colUsersetObjectID, colUsersetRelation} {
+		value, err := change.requiredText(column)
+		if err != nil {
+			return tuple.Relationship{}, err
+		}
+		values[i] = value
+	}
+
+	expiration, err := change.timestamp(w.typeMap, pgtype.TimestamptzOID, colExpiration)
+	if err != nil {
+		return tuple.Relationship{}, err
+	}
+
+	relationship := tuple.Relationship{
+		RelationshipReference: tuple.RelationshipReference{
+			Resource: tuple.ObjectAndRelation{
+				ObjectType: tuple.InternName(values[0]),
+				ObjectID:   values[1],
+				Relation:   tuple.InternName(values[2]),
+			},
+			Subject: tuple.ObjectAndRelation{
+				ObjectType: tuple.InternName(values[3]),
+				ObjectID:   values[4],
+				Relation:   tuple.InternName(values[5]),
+			},
+		},
+		OptionalExpiration: expiration,
+	}
+
+	caveatName, ok, err := change.text(colCaveatContextName)
+	if err != nil {
+		return tuple.Relationship{}, err
+	}
+
+	if ok && caveatName != "" {
+		caveatContext, err := change.jsonObject(colCaveatContext)
+		if err != nil {
+			return tuple.Relationship{}, err
+		}
+
+		contextStruct, err := structpb.NewStruct(caveatContext)
+		if err != nil {
+			return tuple.Relationship{}, fmt.Errorf("failed to read caveat context from update: %w", err)
+		}
+		relationship.OptionalCaveat = &core.ContextualizedCaveat{
+			CaveatName: tuple.InternName(caveatName),
+			Context:    contextStruct,
+		}
+	}
+
+	return relationship, nil
+}
@@ -0,0 +1,418 @@
+package proxy
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/options"
+	corev1 "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+const (
+	// encryptedContextField is the only field of an encrypted caveat context, holding the ID of
+	// the key and the ciphertext of the original context.
+	encryptedContextField = "__spicedb_encrypted_context"
+
+	encryptedContextKeyIDField      = "key_id"
+	encryptedContextCiphertextField = "ciphertext"
+
+	encryptionKeyLength = 32
+)
+
+// EncryptionKey is a key used to encrypt caveat contexts with AES-256-GCM.
+type EncryptionKey struct {
+	// ID is the unique identifier for the key, stored alongside the caveat contexts it encrypts.
+	// The bytes of a key must never change for a given ID.
+	ID string
+
+	// Bytes is the raw key material, which must be 32 bytes long.
+	Bytes []byte
+}
+
+// EncryptionKeyProvider provides the keys with which caveat contexts are encrypted and decrypted.
+type EncryptionKeyProvider interface {
+	// CurrentKey returns the key with which caveat contexts are encrypted when written.
+	CurrentKey(ctx context.Context) (EncryptionKey, error)
+
+	// KeyByID returns the key with the given ID, with which existing caveat contexts were
+	// encrypted.
+	KeyByID(ctx context.Context, id string) (EncryptionKey, error)
+}
+
+type staticEncryptionKeyProvider struct {
+	current  EncryptionKey
+	keysByID map[string]EncryptionKey
+}
+
+// NewStaticEncryptionKeyProvider creates a key provider with a fixed current key. The previous
+// keys are kept to decrypt the caveat contexts written before the current key was rotated in.
+func NewStaticEncryptionKeyProvider(current EncryptionKey, previous []EncryptionKey) (EncryptionKeyProvider, error) {
+	keysByID := make(map[string]EncryptionKey, len(previous)+1)
+	for _, key := range append([]EncryptionKey{current}, previous...) {
+		if len(key.ID) == 0 {
+			return nil, fmt.Errorf("encryption key ID cannot be empty")
+		}
+
+		if len(key.Bytes) != encryptionKeyLength {
+			return nil, fmt.Errorf("encryption key %s must be %d bytes long, found %d", key.ID, encryptionKeyLength, len(key.Bytes))
+		}
+
+		if _, ok := keysByID[key.ID]; ok {
+			return nil, fmt.Errorf("found duplicate encryption key ID: %s", key.ID)
+		}
+
+		keysByID[key.ID] = key
+	}
+
+	return &staticEncryptionKeyProvider{current: current, keysByID: keysByID}, nil
+}
+
+func (p *staticEncryptionKeyProvider) CurrentKey(context.Context) (EncryptionKey, error) {
+	return p.current, nil
+}
+
+func (p *staticEncryptionKeyProvider) KeyByID(_ context.Context, id string) (EncryptionKey, error) {
+	key, ok := p.keysByID[id]
+	if !ok {
+		return EncryptionKey{}, fmt.Errorf("encryption key not found: %s", id)
+	}
+	return key, nil
+}
+
+// NewCaveatContextEncryptionProxy creates a new datastore proxy which encrypts the caveat contexts
+// of relationships before they are written and decrypts them when read, so that the attributes
+// they often contain are not stored in plaintext. Empty contexts are left unencrypted, and
+// contexts written before encryption was enabled are returned as stored.
+//
+// Each context is encrypted with the current key of the provider, and bound to its relationship,
+// so that it cannot be moved to another relationship without failing to decrypt.
+func NewCaveatContextEncryptionProxy(ds datastore.Datastore, keys EncryptionKeyProvider) datastore.Datastore {
+	return &caveatContextEncryptionProxy{Datastore: ds, keys: keys}
+}
+
+type caveatContextEncryptionProxy struct {
+	datastore.Datastore
+
+	keys EncryptionKeyProvider
+
+	// ciphersByKeyID caches the AEAD ciphers of the keys, by their IDs.
+	ciphersByKeyID sync.Map
+}
+
+func (p *caveatContextEncryptionProxy) Unwrap() datastore.Datastore {
+	return p.Datastore
+}
+
+func (p *caveatContextEncryptionProxy) cipher(key EncryptionKey) (cipher.AEAD, error) {
+	if found, ok := p.ciphersByKeyID.Load(key.ID); ok {
+		return found.(cipher.AEAD), nil
+	}
+
+	block, err := aes.NewCipher(key.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption key %s: %w", key.ID, err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	p.ciphersByKeyID.Store(key.ID, aead)
+	return aead, nil
+}
+
+// additionalData returns the data to which the caveat context of the relationship is bound.
+func additionalData(rel tuple.Relationship) []byte {
+	return []byte(tuple.StringWithoutCaveatOrExpiration(rel) + "[" + rel.OptionalCaveat.CaveatName + "]")
+}
+
+func (p *caveatContextEncryptionProxy) encrypt(ctx context.Context, rel tuple.Relationship) (tuple.Relationship, error) {
+	if rel.OptionalCaveat == nil || len(rel.OptionalCaveat.Context.GetFields()) == 0 {
+		return rel, nil
+	}
+
+	key, err := p.keys.CurrentKey(ctx)
+	if err != nil {
+		return rel, fmt.Errorf("unable to load the current caveat context encryption key: %w", err)
+	}
+
+	aead, err := p.cipher(key)
+	if err != nil {
+		return rel, err
+	}
+
+	plaintext, err := proto.Marshal(rel.OptionalCaveat.Context)
+	if err != nil {
+		return rel, err
+	}
+
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return rel, err
+	}
+
+	// The nonce is prepended to the ciphertext, preceded by the version of the format.
+	sealed := aead.Seal(nonce, nonce, plaintext, additionalData(rel))
+	encoded := base64.StdEncoding.EncodeToString(append([]byte{versionByte}, sealed...))
+
+	// NOTE: Callers expect to be able to reuse the relationship, so the caveat is replaced
+	// rather than modified.
+	rel.OptionalCaveat = &corev1.ContextualizedCaveat{
+		CaveatName: rel.OptionalCaveat.CaveatName,
+		Context: &structpb.Struct{Fields: map[string]*structpb.Value{
+			encryptedContextField: structpb.NewStructValue(&structpb.Struct{Fields: map[string]*structpb.Value{
+				encryptedContextKeyIDField:      structpb.NewStringValue(key.ID),
+				encryptedContextCiphertextField: structpb.NewStringValue(encoded),
+			}}),
+		}},
+	}
+	return rel, nil
+}
+
+func (p *caveatContextEncryptionProxy) decrypt(ctx context.Context, rel tuple.Relationship) (tuple.Relationship, error) {
+	if rel.OptionalCaveat == nil || len(rel.OptionalCaveat.Context.GetFields()) != 1 {
+		return rel, nil
+	}
+
+	envelope, ok := rel.OptionalCaveat.Context.Fields[encryptedContextField]
+	if !ok {
+		return rel, nil
+	}
+
+	failed := func(err error) (tuple.Relationship, error) {
+		return rel, fmt.Errorf("unable to decrypt the caveat context of relationship %s: %w", tuple.StringWithoutCaveatOrExpiration(rel), err)
+	}
+
+	fields := envelope.GetStructValue().GetFields()
+	keyID := fields[encryptedContextKeyIDField].GetStringValue()
+	sealed, err := base64.StdEncoding.DecodeString(fields[encryptedContextCiphertextField].GetStringValue())
+	if err != nil {
+		return failed(err)
+	}
+
+	key, err := p.keys.KeyByID(ctx, keyID)
+	if err != nil {
+		return failed(err)
+	}
+
+	aead, err := p.cipher(key)
+	if err != nil {
+		return failed(err)
+	}
+
+	if len(sealed) < 1+aead.NonceSize() || sealed[0] != versionByte {
+		return failed(errors.New("invalid ciphertext"))
+	}
+
+	nonce, ciphertext := sealed[1:1+aead.NonceSize()], sealed[1+aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, additionalData(rel))
+	if err != nil {
+		return failed(err)
+	}
+
+	decrypted := &structpb.Struct{}
+	if err := proto.Unmarshal(plaintext, decrypted); err != nil {
+		return failed(err)
+	}
+
+	rel.OptionalCaveat = &corev1.ContextualizedCaveat{
+		CaveatName: rel.OptionalCaveat.CaveatName,
+		Context:    decrypted,
+	}
+	return rel, nil
+}
+
+func (p *caveatContextEncryptionProxy) SnapshotReader(rev datastore.Revision) datastore.Reader {
+	return caveatContextDecryptingReader{Reader: p.Datastore.SnapshotReader(rev), parent: p}
+}
+
+func (p *caveatContextEncryptionProxy) ReadWriteTx(ctx context.Context, f datastore.TxUserFunc, opts ...options.RWTOptionsOption) (datastore.Revision, error) {
+	return p.Datastore.ReadWriteTx(ctx, func(ctx context.Context, tx datastore.ReadWriteTransaction) error {
+		return f(ctx, &caveatContextEncryptionTx{
+			ReadWriteTransaction: tx,
+			reader:               caveatContextDecryptingReader{Reader: tx, parent: p},
+			parent:               p,
+		})
+	}, opts...)
+}
+
+func (p *caveatContextEncryptionProxy) Watch(ctx context.Context, afterRevision datastore.Revision, options datastore.WatchOptions) (<-chan *datastore.RevisionChanges, <-chan error) {
+	resultsChan, errChan := p.Datastore.Watch(ctx, afterRevision, options)
+	decryptedResultsChan := make(chan *datastore.RevisionChanges)
+	decryptedErrChan := make(chan error, 1)
+
+	go func() {
+		defer close(decryptedResultsChan)
+		defer close(decryptedErrChan)
+
+		for {
+			select {
+			case result, ok := <-resultsChan:
+				if !ok {
+					return
+				}
+
+				for i, change := range result.RelationshipChanges {
+					decrypted, err := p.decrypt(ctx, change.Relationship)
+					if err != nil {
+						decryptedErrChan <- err
+						return
+					}
+					result.RelationshipChanges[i].Relationship = decrypted
+				}
+
+				select {
+				case decryptedResultsChan <- result:
+				case <-ctx.Done():
+					return
+				}
+
+			case err, ok := <-errChan:
+				if ok {
+					decryptedErrChan <- err
+				}
+				return
+			}
+		}
+	}()
+
+	return decryptedResultsChan, decryptedErrChan
+}
+
+type caveatContextDecryptingReader struct {
+	datastore.Reader
+
+	parent *caveatContextEncryptionProxy
+}
+
+func (r caveatContextDecryptingReader) decryptAll(ctx context.Context, it datastore.RelationshipIterator) datastore.RelationshipIterator {
+	return func(yield func(tuple.Relationship, error) bool) {
+		for rel, err := range it {
+			if err != nil {
+				yield(rel, err)
+				return
+			}
+
+			decrypted, err := r.parent.decrypt(ctx, rel)
+			if err != nil {
+				yield(rel, err)
+				return
+			}
+
+			if !yield(decrypted, nil) {
+				return
+			}
+		}
+	}
+}
+
+func (r caveatContextDecryptingReader) QueryRelationships(ctx context.Context, filter datastore.RelationshipsFilter, options ...options.QueryOptionsOption) (datastore.RelationshipIterator, error) {
+	it, err := r.Reader.QueryRelationships(ctx, filter, options...)
+	if err != nil {
+		return nil, err
+	}
+	return r.decryptAll(ctx, it), nil
+}
+
+func (r caveatContextDecryptingReader) ReverseQueryRelationships(ctx context.Context, subjectsFilter datastore.SubjectsFilter, options ...options.ReverseQueryOptionsOption) (datastore.RelationshipIterator, error) {
+	it, err := r.Reader.ReverseQueryRelationships(ctx, subjectsFilter, options...)
+	if err != nil {
+		return nil, err
+	}
+	return r.decryptAll(ctx, it), nil
+}
+
+func (r caveatContextDecryptingReader) QueryRelationshipsBatch(ctx context.Context, filters []datastore.RelationshipsFilter, options ...options.QueryOptionsOption) (datastore.TaggedRelationshipIterator, error) {
+	it, err := r.Reader.QueryRelationshipsBatch(ctx, filters, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(yield func(datastore.TaggedRelationship, error) bool) {
+		for tagged, err := range it {
+			if err != nil {
+				yield(tagged, err)
+				return
+			}
+
+			tagged.Relationship, err = r.parent.decrypt(ctx, tagged.Relationship)
+			if err != nil {
+				yield(tagged, err)
+				return
+			}
+
+			if !yield(tagged, nil) {
+				return
+			}
+		}
+	}, nil
+}
+
+type caveatContextEncryptionTx struct {
+	datastore.ReadWriteTransaction
+
+	reader caveatContextDecryptingReader
+	parent *caveatContextEncryptionProxy
+}
+
+func (tx *caveatContextEncryptionTx) QueryRelationships(ctx context.Context, filter datastore.RelationshipsFilter, options ...options.QueryOptionsOption) (datastore.RelationshipIterator, error) {
+	return tx.reader.QueryRelationships(ctx, filter, options...)
+}
+
+func (tx *caveatContextEncryptionTx) ReverseQueryRelationships(ctx context.Context, subjectsFilter datastore.SubjectsFilter, options ...options.ReverseQueryOptionsOption) (datastore.RelationshipIterator, error) {
+	return tx.reader.ReverseQueryRelationships(ctx, subjectsFilter, options...)
+}
+
+func (tx *caveatContextEncryptionTx) QueryRelationshipsBatch(ctx context.Context, filters []datastore.RelationshipsFilter, options ...options.QueryOptionsOption) (datastore.TaggedRelationshipIterator, error) {
+	return tx.reader.QueryRelationshipsBatch(ctx, filters, options...)
+}
+
+func (tx *caveatContextEncryptionTx) WriteRelationships(ctx context.Context, mutations []tuple.RelationshipUpdate) error {
+	encrypted := make([]tuple.RelationshipUpdate, 0, len(mutations))
+	for _, mutation := range mutations {
+		if mutation.Operation != tuple.UpdateOperationDelete {
+			rel, err := tx.parent.encrypt(ctx, mutation.Relationship)
+			if err != nil {
+				return err
+			}
+			mutation.Relationship = rel
+		}
+		encrypted = append(encrypted, mutation)
+	}
+
+	return tx.ReadWriteTransaction.WriteRelationships(ctx, encrypted)
+}
+
+func (tx *caveatContextEncryptionTx) BulkLoad(ctx context.Context, iter datastore.BulkWriteRelationshipSource) (uint64, error) {
+	return tx.ReadWriteTransaction.BulkLoad(ctx, caveatContextEncryptingSource{iter, tx.parent})
+}
+
+type caveatContextEncryptingSource struct {
+	wrapped datastore.BulkWriteRelationshipSource
+	parent  *caveatContextEncryptionProxy
+}
+
+func (s caveatContextEncryptingSource) Next(ctx context.Context) (*tuple.Relationship, error) {
+	rel, err := s.wrapped.Next(ctx)
+	if err != nil || rel == nil {
+		return rel, err
+	}
+
+	encrypted, err := s.parent.encrypt(ctx, *rel)
+	if err != nil {
+		return nil, err
+	}
+	return &encrypted, nil
+}
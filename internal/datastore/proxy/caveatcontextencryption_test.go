@@ -0,0 +1,198 @@
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/dsfortesting"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+var (
+	currentEncryptionKeyForTesting  = EncryptionKey{ID: "current", Bytes: bytes.Repeat([]byte{0x01}, encryptionKeyLength)}
+	previousEncryptionKeyForTesting = EncryptionKey{ID: "previous", Bytes: bytes.Repeat([]byte{0x02}, encryptionKeyLength)}
+)
+
+func newEncryptionProxyForTesting(t *testing.T, ds datastore.Datastore, current EncryptionKey, previous ...EncryptionKey) datastore.Datastore {
+	keys, err := NewStaticEncryptionKeyProvider(current, previous)
+	require.NoError(t, err)
+	return NewCaveatContextEncryptionProxy(ds, keys)
+}
+
+func writeRelationshipsForTesting(t *testing.T, ds datastore.Datastore, rels ...string) {
+	_, err := ds.ReadWriteTx(context.Background(), func(ctx context.Context, tx datastore.ReadWriteTransaction) error {
+		updates := make([]tuple.RelationshipUpdate, 0, len(rels))
+		for _, rel := range rels {
+			updates = append(updates, tuple.Create(tuple.MustParse(rel)))
+		}
+		return tx.WriteRelationships(ctx, updates)
+	})
+	require.NoError(t, err)
+}
+
+func readRelationshipsForTesting(t *testing.T, ds datastore.Datastore) ([]string, error) {
+	headRev, err := ds.HeadRevision(context.Background())
+	require.NoError(t, err)
+
+	iter, err := ds.SnapshotReader(headRev).QueryRelationships(
+		context.Background(),
+		datastore.RelationshipsFilter{OptionalResourceType: "resource"},
+	)
+	require.NoError(t, err)
+
+	rels, err := datastore.IteratorToSlice(iter)
+	if err != nil {
+		return nil, err
+	}
+
+	strs := make([]string, 0, len(rels))
+	for _, rel := range rels {
+		strs = append(strs, tuple.MustString(rel))
+	}
+	return strs, nil
+}
+
+func TestCaveatContextEncryption(t *testing.T) {
+	ds, err := dsfortesting.NewMemDBDatastoreForTesting(0, 5*time.Second, 1*time.Hour)
+	require.NoError(t, err)
+
+	pds := newEncryptionProxyForTesting(t, ds, currentEncryptionKeyForTesting)
+	writeRelationshipsForTesting(t, pds,
+		`resource:foo#viewer@user:tom[somecaveat:{"region":"eu"}]`,
+		`resource:foo#viewer@user:fred[somecaveat]`,
+		`resource:foo#viewer@user:sarah`,
+	)
+
+	// Read them back through the proxy and ensure the contexts are decrypted.
+	rels, err := readRelationshipsForTesting(t, pds)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{
+		`resource:foo#viewer@user:tom[somecaveat:{"region":"eu"}]`,
+		`resource:foo#viewer@user:fred[somecaveat]`,
+		`resource:foo#viewer@user:sarah`,
+	}, rels)
+
+	// Read them back directly and ensure only the non-empty context is encrypted.
+	headRev, err := ds.HeadRevision(context.Background())
+	require.NoError(t, err)
+	iter, err := ds.SnapshotReader(headRev).QueryRelationships(context.Background(), datastore.RelationshipsFilter{OptionalResourceType: "resource"})
+	require.NoError(t, err)
+	stored, err := datastore.IteratorToSlice(iter)
+	require.NoError(t, err)
+	for _, rel := range stored {
+		switch rel.Subject.ObjectID {
+		case "tom":
+			require.Contains(t, rel.OptionalCaveat.Context.Fields, encryptedContextField)
+			require.NotContains(t, rel.OptionalCaveat.Context.Fields, "region")
+		case "fred":
+			require.Empty(t, rel.OptionalCaveat.Context.GetFields())
+		}
+	}
+}
+
+func TestCaveatContextEncryptionKeyRotation(t *testing.T) {
+	ds, err := dsfortesting.NewMemDBDatastoreForTesting(0, 5*time.Second, 1*time.Hour)
+	require.NoError(t, err)
+
+	writeRelationshipsForTesting(t, newEncryptionProxyForTesting(t, ds, previousEncryptionKeyForTesting),
+		`resource:foo#viewer@user:tom[somecaveat:{"region":"eu"}]`,
+	)
+
+	// A proxy with the previous key can decrypt the contexts written before the rotation.
+	pds := newEncryptionProxyForTesting(t, ds, currentEncryptionKeyForTesting, previousEncryptionKeyForTesting)
+	writeRelationshipsForTesting(t, pds,
+		`resource:foo#viewer@user:fred[somecaveat:{"region":"us"}]`,
+	)
+
+	rels, err := readRelationshipsForTesting(t, pds)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{
+		`resource:foo#viewer@user:tom[somecaveat:{"region":"eu"}]`,
+		`resource:foo#viewer@user:fred[somecaveat:{"region":"us"}]`,
+	}, rels)
+
+	// A proxy without the previous key cannot.
+	_, err = readRelationshipsForTesting(t, newEncryptionProxyForTesting(t, ds, currentEncryptionKeyForTesting))
+	require.ErrorContains(t, err, "encryption key not found: previous")
+}
+
+func TestCaveatContextEncryptionUnencryptedContexts(t *testing.T) {
+	ds, err := dsfortesting.NewMemDBDatastoreForTesting(0, 5*time.Second, 1*time.Hour)
+	require.NoError(t, err)
+
+	// Contexts written before encryption was enabled are returned as stored.
+	writeRelationshipsForTesting(t, ds, `resource:foo#viewer@user:tom[somecaveat:{"region":"eu"}]`)
+
+	rels, err := readRelationshipsForTesting(t, newEncryptionProxyForTesting(t, ds, currentEncryptionKeyForTesting))
+	require.NoError(t, err)
+	require.Equal(t, []string{`resource:foo#viewer@user:tom[somecaveat:{"region":"eu"}]`}, rels)
+}
+
+func TestCaveatContextEncryptionMovedContext(t *testing.T) {
+	ds, err := dsfortesting.NewMemDBDatastoreForTesting(0, 5*time.Second, 1*time.Hour)
+	require.NoError(t, err)
+
+	pds := newEncryptionProxyForTesting(t, ds, currentEncryptionKeyForTesting)
+	writeRelationshipsForTesting(t, pds, `resource:foo#viewer@user:tom[somecaveat:{"region":"eu"}]`)
+
+	headRev, err := ds.HeadRevision(context.Background())
+	require.NoError(t, err)
+	iter, err := ds.SnapshotReader(headRev).QueryRelationships(context.Background(), datastore.RelationshipsFilter{OptionalResourceType: "resource"})
+	require.NoError(t, err)
+	stored, err := datastore.IteratorToSlice(iter)
+	require.NoError(t, err)
+	require.Len(t, stored, 1)
+
+	// Copy the encrypted context onto another relationship by bypassing the proxy.
+	_, err = ds.ReadWriteTx(context.Background(), func(ctx context.Context, tx datastore.ReadWriteTransaction) error {
+		moved := tuple.MustParse("resource:foo#viewer@user:fred").WithCaveat(stored[0].OptionalCaveat)
+		return tx.WriteRelationships(ctx, []tuple.RelationshipUpdate{tuple.Create(moved)})
+	})
+	require.NoError(t, err)
+
+	_, err = readRelationshipsForTesting(t, pds)
+	require.ErrorContains(t, err, "unable to decrypt the caveat context of relationship resource:foo#viewer@user:fred")
+}
+
+func TestCaveatContextEncryptionWatch(t *testing.T) {
+	ds, err := dsfortesting.NewMemDBDatastoreForTesting(0, 5*time.Second, 1*time.Hour)
+	require.NoError(t, err)
+
+	pds := newEncryptionProxyForTesting(t, ds, currentEncryptionKeyForTesting)
+	headRev, err := pds.HeadRevision(context.Background())
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	watchEvents, errChan := pds.Watch(ctx, headRev, datastore.WatchJustRelationships())
+
+	writeRelationshipsForTesting(t, pds, `resource:foo#viewer@user:tom[somecaveat:{"region":"eu"}]`)
+
+	select {
+	case changes := <-watchEvents:
+		require.Len(t, changes.RelationshipChanges, 1)
+		require.Equal(t, "eu", changes.RelationshipChanges[0].Relationship.OptionalCaveat.Context.Fields["region"].GetStringValue())
+
+	case err := <-errChan:
+		require.NoError(t, err)
+
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "timeout waiting for watch event")
+	}
+}
+
+func TestNewStaticEncryptionKeyProvider(t *testing.T) {
+	_, err := NewStaticEncryptionKeyProvider(EncryptionKey{ID: "short", Bytes: []byte{0x01}}, nil)
+	require.ErrorContains(t, err, "must be 32 bytes long")
+
+	_, err = NewStaticEncryptionKeyProvider(EncryptionKey{Bytes: currentEncryptionKeyForTesting.Bytes}, nil)
+	require.ErrorContains(t, err, "encryption key ID cannot be empty")
+
+	_, err = NewStaticEncryptionKeyProvider(currentEncryptionKeyForTesting, []EncryptionKey{currentEncryptionKeyForTesting})
+	require.ErrorContains(t, err, "found duplicate encryption key ID: current")
+}
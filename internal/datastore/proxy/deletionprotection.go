@@ -0,0 +1,263 @@
+package proxy
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"sync"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/options"
+	"github.com/authzed/spicedb/pkg/errorcodes"
+	"github.com/authzed/spicedb/pkg/requestmeta"
+	"github.com/authzed/spicedb/pkg/spiceerrors"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// DeletionProtectionConfig defines the rules guarding against the deletion of critical
+// relationships, by any write.
+type DeletionProtectionConfig struct {
+	// MaxDeletedRelationships is the maximum number of relationships a single transaction may
+	// delete. Zero disables the rule.
+	MaxDeletedRelationships uint64
+
+	// ProtectedObjectTypes are the resource object types whose relationships cannot be deleted.
+	ProtectedObjectTypes []string
+
+	// AdminToken is the token with which the rules can be overridden, by setting it in the
+	// RequestOverrideDeletionProtection header. When empty, the rules cannot be overridden.
+	AdminToken string
+}
+
+// Enabled returns whether any deletion protection rule is configured.
+func (c DeletionProtectionConfig) Enabled() bool {
+	return c.MaxDeletedRelationships > 0 || len(c.ProtectedObjectTypes) > 0
+}
+
+// DeletionProtectedError occurs when a delete is denied by a deletion protection rule.
+type DeletionProtectedError struct {
+	error
+	metadata map[string]string
+}
+
+// NewDeletionProtectedErr constructs a new deletion protected error.
+func NewDeletionProtectedErr(reason string, metadata map[string]string) DeletionProtectedError {
+	return DeletionProtectedError{
+		error:    fmt.Errorf("delete denied by deletion protection: %s; the rules can be overridden with the %s header set to the admin token", reason, requestmeta.RequestOverrideDeletionProtection),
+		metadata: metadata,
+	}
+}
+
+// GRPCStatus implements retrieving the gRPC status for the error.
+func (err DeletionProtectedError) GRPCStatus() *status.Status {
+	return spiceerrors.WithCodeAndDetails(
+		err,
+		codes.FailedPrecondition,
+		spiceerrors.ForCode(errorcodes.DeletionProtected, maps.Clone(err.metadata)),
+	)
+}
+
+type withoutDeletionProtectionKey struct{}
+
+// WithoutDeletionProtection returns a context under which the transactions are not subject to
+// deletion protection, for internal writes restoring relationships to a previous state, such as
+// the rollback of a failed write.
+func WithoutDeletionProtection(ctx context.Context) context.Context {
+	return context.WithValue(ctx, withoutDeletionProtectionKey{}, true)
+}
+
+type deletionProtectionProxy struct {
+	datastore.Datastore
+
+	config DeletionProtectionConfig
+}
+
+// NewDeletionProtectionProxy creates a proxy which enforces the deletion protection rules on
+// every relationship deleted within its transactions: deleted by filter, such as by
+// DeleteRelationships, or by a DELETE update, such as by WriteRelationships,
+// ApplyRelationshipsSnapshot or a schema write deleting the relationships blocking it.
+//
+// The rules are overridden for the transactions whose context holds the
+// RequestOverrideDeletionProtection header set to the admin token; a deletion under the header
+// set to any other token fails with PermissionDenied.
+func NewDeletionProtectionProxy(delegate datastore.Datastore, config DeletionProtectionConfig) datastore.Datastore {
+	return &deletionProtectionProxy{Datastore: delegate, config: config}
+}
+
+func (p *deletionProtectionProxy) Unwrap() datastore.Datastore {
+	return p.Datastore
+}
+
+func (p *deletionProtectionProxy) ReadWriteTx(ctx context.Context, f datastore.TxUserFunc, opts ...options.RWTOptionsOption) (datastore.Revision, error) {
+	if bypass, _ := ctx.Value(withoutDeletionProtectionKey{}).(bool); bypass {
+		return p.Datastore.ReadWriteTx(ctx, f, opts...)
+	}
+
+	override := &deletionProtectionOverride{config: p.config}
+	return p.Datastore.ReadWriteTx(ctx, func(ctx context.Context, tx datastore.ReadWriteTransaction) error {
+		return f(ctx, &deletionProtectionTx{ReadWriteTransaction: tx, config: p.config, override: override})
+	}, opts...)
+}
+
+// deletionProtectionOverride records whether the rules are overridden for a ReadWriteTx call,
+// determined on its first deletion.
+type deletionProtectionOverride struct {
+	config DeletionProtectionConfig
+
+	once       sync.Once
+	overridden bool
+	err        error
+}
+
+// check returns whether the rules are overridden by the headers of the request, failing if the
+// override token is not the admin token.
+func (o *deletionProtectionOverride) check(ctx context.Context) (bool, error) {
+	o.once.Do(func() {
+		values := metadata.ValueFromIncomingContext(ctx, string(requestmeta.RequestOverrideDeletionProtection))
+		if len(values) == 0 {
+			return
+		}
+
+		if o.config.AdminToken == "" || subtle.ConstantTimeCompare([]byte(values[0]), []byte(o.config.AdminToken)) != 1 {
+			o.err = status.Error(codes.PermissionDenied, "invalid deletion protection override token")
+			return
+		}
+
+		o.overridden = true
+		log.Ctx(ctx).Warn().Msg("deletion protection overridden by request")
+	})
+	return o.overridden, o.err
+}
+
+type deletionProtectionTx struct {
+	datastore.ReadWriteTransaction
+
+	config   DeletionProtectionConfig
+	override *deletionProtectionOverride
+
+	// deleted is the number of relationships deleted by this attempt of the transaction, at most.
+	deleted uint64
+}
+
+func (t *deletionProtectionTx) WriteRelationships(ctx context.Context, mutations []tuple.RelationshipUpdate) error {
+	var deletes uint64
+	for _, mutation := range mutations {
+		if mutation.Operation != tuple.UpdateOperationDelete {
+			continue
+		}
+
+		if err := t.checkObjectType(ctx, mutation.Relationship.Resource.ObjectType); err != nil {
+			return err
+		}
+		deletes++
+	}
+
+	if deletes > 0 {
+		if err := t.checkDeletedCount(ctx, deletes, "the write deletes more than the maximum of relationships that can be deleted at once"); err != nil {
+			return err
+		}
+	}
+
+	return t.ReadWriteTransaction.WriteRelationships(ctx, mutations)
+}
+
+func (t *deletionProtectionTx) DeleteRelationships(ctx context.Context, filter *v1.RelationshipFilter, opts ...options.DeleteOptionsOption) (bool, error) {
+	if err := t.checkObjectType(ctx, filter.ResourceType); err != nil {
+		return false, err
+	}
+
+	if t.config.MaxDeletedRelationships > 0 {
+		matched, err := t.countMatching(ctx, filter, options.NewDeleteOptionsWithOptionsAndDefaults(opts...).DeleteLimit)
+		if err != nil {
+			return false, err
+		}
+
+		if err := t.checkDeletedCount(ctx, matched, "the filter matches more than the maximum of relationships that can be deleted at once"); err != nil {
+			return false, err
+		}
+	}
+
+	return t.ReadWriteTransaction.DeleteRelationships(ctx, filter, opts...)
+}
+
+// countMatching returns the number of relationships the filter deletes, up to the delete limit if
+// any, counting them only as far as needed to exceed the relationships the transaction may still
+// delete.
+func (t *deletionProtectionTx) countMatching(ctx context.Context, filter *v1.RelationshipFilter, deleteLimit *uint64) (uint64, error) {
+	remaining := t.config.MaxDeletedRelationships - min(t.deleted, t.config.MaxDeletedRelationships)
+	if deleteLimit != nil && *deleteLimit > 0 && *deleteLimit <= remaining {
+		return *deleteLimit, nil
+	}
+
+	dsFilter, err := datastore.RelationshipsFilterFromPublicFilter(filter)
+	if err != nil {
+		return 0, err
+	}
+
+	limitPlusOne := remaining + 1
+	it, err := t.QueryRelationships(ctx, dsFilter, options.WithLimit(&limitPlusOne))
+	if err != nil {
+		return 0, err
+	}
+
+	var matched uint64
+	for _, err := range it {
+		if err != nil {
+			return 0, err
+		}
+		matched++
+	}
+	return matched, nil
+}
+
+// checkObjectType fails if relationships of the object type, or of any object type if empty,
+// cannot be deleted.
+func (t *deletionProtectionTx) checkObjectType(ctx context.Context, objectType string) error {
+	if len(t.config.ProtectedObjectTypes) == 0 {
+		return nil
+	}
+	if objectType != "" && !slices.Contains(t.config.ProtectedObjectTypes, objectType) {
+		return nil
+	}
+
+	if overridden, err := t.override.check(ctx); overridden || err != nil {
+		return err
+	}
+
+	if objectType == "" {
+		return NewDeletionProtectedErr("the filter does not specify a resource type, and relationships of protected object types cannot be deleted", nil)
+	}
+	return NewDeletionProtectedErr("relationships of protected object type `"+objectType+"` cannot be deleted", map[string]string{
+		"object_type": objectType,
+	})
+}
+
+// checkDeletedCount adds the number of relationships deleted to those of the transaction, failing
+// with the reason if they exceed the maximum allowed.
+func (t *deletionProtectionTx) checkDeletedCount(ctx context.Context, deleted uint64, reason string) error {
+	if t.config.MaxDeletedRelationships == 0 {
+		return nil
+	}
+
+	t.deleted += deleted
+	if t.deleted <= t.config.MaxDeletedRelationships {
+		return nil
+	}
+
+	if overridden, err := t.override.check(ctx); overridden || err != nil {
+		return err
+	}
+
+	return NewDeletionProtectedErr(reason, map[string]string{
+		"maximum_deleted_relationships": strconv.FormatUint(t.config.MaxDeletedRelationships, 10),
+	})
+}
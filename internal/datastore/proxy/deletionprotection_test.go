@@ -0,0 +1,144 @@
+package proxy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/authzed/spicedb/internal/datastore/dsfortesting"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/options"
+	"github.com/authzed/spicedb/pkg/requestmeta"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+func newDeletionProtectionProxyForTesting(t *testing.T, config DeletionProtectionConfig) datastore.Datastore {
+	ds, err := dsfortesting.NewMemDBDatastoreForTesting(0, 5*time.Second, 1*time.Hour)
+	require.NoError(t, err)
+
+	require.NoError(t, writeRelationshipsWithErrorForTesting(ds,
+		"folder:company#viewer@user:tom",
+		"folder:company#viewer@user:fred",
+		"document:firstdoc#viewer@user:tom",
+		"document:firstdoc#viewer@user:fred",
+		"document:firstdoc#viewer@user:sarah",
+	))
+
+	return NewDeletionProtectionProxy(ds, config)
+}
+
+func withOverrideTokenForTesting(token string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs(string(requestmeta.RequestOverrideDeletionProtection), token))
+}
+
+func deleteUpdatesWithErrorForTesting(ctx context.Context, ds datastore.Datastore, rels ...string) error {
+	_, err := ds.ReadWriteTx(ctx, func(ctx context.Context, tx datastore.ReadWriteTransaction) error {
+		updates := make([]tuple.RelationshipUpdate, 0, len(rels))
+		for _, rel := range rels {
+			updates = append(updates, tuple.Delete(tuple.MustParse(rel)))
+		}
+		return tx.WriteRelationships(ctx, updates)
+	})
+	return err
+}
+
+func deleteByFilterWithErrorForTesting(ctx context.Context, ds datastore.Datastore, filter *v1.RelationshipFilter, opts ...options.DeleteOptionsOption) error {
+	_, err := ds.ReadWriteTx(ctx, func(ctx context.Context, tx datastore.ReadWriteTransaction) error {
+		_, err := tx.DeleteRelationships(ctx, filter, opts...)
+		return err
+	})
+	return err
+}
+
+func TestDeletionProtectionProtectedObjectTypes(t *testing.T) {
+	pds := newDeletionProtectionProxyForTesting(t, DeletionProtectionConfig{ProtectedObjectTypes: []string{"folder"}})
+	ctx := context.Background()
+
+	err := deleteUpdatesWithErrorForTesting(ctx, pds, "folder:company#viewer@user:tom")
+	require.ErrorAs(t, err, &DeletionProtectedError{})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+	require.ErrorContains(t, err, "relationships of protected object type `folder` cannot be deleted")
+
+	err = deleteByFilterWithErrorForTesting(ctx, pds, &v1.RelationshipFilter{ResourceType: "folder"})
+	require.ErrorAs(t, err, &DeletionProtectedError{})
+
+	err = deleteByFilterWithErrorForTesting(ctx, pds, &v1.RelationshipFilter{OptionalRelation: "viewer"})
+	require.ErrorAs(t, err, &DeletionProtectedError{})
+	require.ErrorContains(t, err, "the filter does not specify a resource type")
+
+	// Relationships of other object types can be deleted.
+	require.NoError(t, deleteUpdatesWithErrorForTesting(ctx, pds, "document:firstdoc#viewer@user:tom"))
+	require.NoError(t, deleteByFilterWithErrorForTesting(ctx, pds, &v1.RelationshipFilter{ResourceType: "document"}))
+}
+
+func TestDeletionProtectionMaxDeletedRelationships(t *testing.T) {
+	pds := newDeletionProtectionProxyForTesting(t, DeletionProtectionConfig{MaxDeletedRelationships: 2})
+	ctx := context.Background()
+
+	err := deleteByFilterWithErrorForTesting(ctx, pds, &v1.RelationshipFilter{ResourceType: "document"})
+	require.ErrorAs(t, err, &DeletionProtectedError{})
+	require.ErrorContains(t, err, "the filter matches more than the maximum of relationships that can be deleted at once")
+
+	err = deleteUpdatesWithErrorForTesting(ctx, pds,
+		"document:firstdoc#viewer@user:tom",
+		"document:firstdoc#viewer@user:fred",
+		"document:firstdoc#viewer@user:sarah",
+	)
+	require.ErrorAs(t, err, &DeletionProtectedError{})
+	require.ErrorContains(t, err, "the write deletes more than the maximum of relationships that can be deleted at once")
+
+	// The deletions of a transaction are counted together.
+	_, err = pds.ReadWriteTx(ctx, func(ctx context.Context, tx datastore.ReadWriteTransaction) error {
+		if err := tx.WriteRelationships(ctx, []tuple.RelationshipUpdate{
+			tuple.Delete(tuple.MustParse("document:firstdoc#viewer@user:tom")),
+		}); err != nil {
+			return err
+		}
+		_, err := tx.DeleteRelationships(ctx, &v1.RelationshipFilter{ResourceType: "folder"})
+		return err
+	})
+	require.ErrorAs(t, err, &DeletionProtectedError{})
+
+	// A delete limit within the maximum is allowed.
+	limit := uint64(2)
+	require.NoError(t, deleteByFilterWithErrorForTesting(ctx, pds, &v1.RelationshipFilter{ResourceType: "document"}, options.WithDeleteLimit(&limit)))
+	require.NoError(t, deleteByFilterWithErrorForTesting(ctx, pds, &v1.RelationshipFilter{ResourceType: "document"}))
+}
+
+func TestDeletionProtectionOverride(t *testing.T) {
+	pds := newDeletionProtectionProxyForTesting(t, DeletionProtectionConfig{
+		MaxDeletedRelationships: 1,
+		ProtectedObjectTypes:    []string{"folder"},
+		AdminToken:              "admintoken",
+	})
+
+	err := deleteByFilterWithErrorForTesting(withOverrideTokenForTesting("wrongtoken"), pds, &v1.RelationshipFilter{ResourceType: "folder"})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+	require.ErrorContains(t, err, "invalid deletion protection override token")
+
+	require.NoError(t, deleteByFilterWithErrorForTesting(withOverrideTokenForTesting("admintoken"), pds, &v1.RelationshipFilter{ResourceType: "folder"}))
+	require.NoError(t, deleteUpdatesWithErrorForTesting(withOverrideTokenForTesting("admintoken"), pds,
+		"document:firstdoc#viewer@user:tom",
+		"document:firstdoc#viewer@user:fred",
+	))
+}
+
+func TestDeletionProtectionWithoutDeletionProtection(t *testing.T) {
+	pds := newDeletionProtectionProxyForTesting(t, DeletionProtectionConfig{
+		MaxDeletedRelationships: 1,
+		ProtectedObjectTypes:    []string{"folder"},
+	})
+
+	ctx := WithoutDeletionProtection(context.Background())
+	require.NoError(t, deleteByFilterWithErrorForTesting(ctx, pds, &v1.RelationshipFilter{ResourceType: "folder"}))
+	require.NoError(t, deleteUpdatesWithErrorForTesting(ctx, pds,
+		"document:firstdoc#viewer@user:tom",
+		"document:firstdoc#viewer@user:fred",
+	))
+}
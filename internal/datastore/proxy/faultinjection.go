@@ -0,0 +1,352 @@
+package proxy
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/options"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+)
+
+var injectedFaultsCount = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "spicedb",
+	Subsystem: "datastore",
+	Name:      "injected_faults_total",
+	Help:      "total number of faults injected into datastore operations",
+}, []string{"kind"})
+
+var errInjectedFault = status.Error(codes.Unavailable, "injected datastore fault")
+
+// maxRetainedRevisions is the number of recently returned optimized revisions retained from which
+// stale revisions are returned.
+const maxRetainedRevisions = 16
+
+// FaultInjectionConfig is the configuration of the faults injected into datastore operations.
+// Percentages are in the range [0, 100].
+type FaultInjectionConfig struct {
+	// LatencyPercent is the percentage of operations delayed by Latency.
+	LatencyPercent float64
+
+	// Latency is the latency added to delayed operations.
+	Latency time.Duration
+
+	// ErrorPercent is the percentage of operations which fail with an Unavailable error.
+	ErrorPercent float64
+
+	// StaleRevisionPercent is the percentage of optimized revision requests which return the oldest
+	// of the recently returned revisions.
+	StaleRevisionPercent float64
+}
+
+type faultInjectionConfigJSON struct {
+	LatencyPercent       float64 `json:"latencyPercent"`
+	Latency              string  `json:"latency"`
+	ErrorPercent         float64 `json:"errorPercent"`
+	StaleRevisionPercent float64 `json:"staleRevisionPercent"`
+}
+
+func (c FaultInjectionConfig) MarshalJSON() ([]byte, error) {
+	return json.Marshal(faultInjectionConfigJSON{
+		LatencyPercent:       c.LatencyPercent,
+		Latency:              c.Latency.String(),
+		ErrorPercent:         c.ErrorPercent,
+		StaleRevisionPercent: c.StaleRevisionPercent,
+	})
+}
+
+func (c *FaultInjectionConfig) UnmarshalJSON(data []byte) error {
+	var decoded faultInjectionConfigJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	var latency time.Duration
+	if decoded.Latency != "" {
+		parsed, err := time.ParseDuration(decoded.Latency)
+		if err != nil {
+			return fmt.Errorf("invalid latency: %w", err)
+		}
+		latency = parsed
+	}
+
+	*c = FaultInjectionConfig{
+		LatencyPercent:       decoded.LatencyPercent,
+		Latency:              latency,
+		ErrorPercent:         decoded.ErrorPercent,
+		StaleRevisionPercent: decoded.StaleRevisionPercent,
+	}
+	return nil
+}
+
+func (c FaultInjectionConfig) validate() error {
+	for name, percent := range map[string]float64{
+		"latency":        c.LatencyPercent,
+		"error":          c.ErrorPercent,
+		"stale revision": c.StaleRevisionPercent,
+	} {
+		if percent < 0 || percent > 100 {
+			return fmt.Errorf("%s percentage must be in the range [0, 100], found %v", name, percent)
+		}
+	}
+
+	if c.Latency < 0 {
+		return fmt.Errorf("latency must not be negative")
+	}
+
+	return nil
+}
+
+// FaultInjector holds the fault injection configuration of a fault injection proxy, which can be
+// changed while the proxy is in use.
+//
+// FaultInjector is an http.Handler: GET requests return the current configuration as JSON, and PUT
+// or POST requests replace it with the configuration in the JSON request body, such as
+// `{"latencyPercent": 10, "latency": "250ms", "errorPercent": 1, "staleRevisionPercent": 5}`.
+type FaultInjector struct {
+	config atomic.Pointer[FaultInjectionConfig]
+}
+
+// NewFaultInjector creates a new fault injector, initially injecting no faults.
+func NewFaultInjector() *FaultInjector {
+	fi := &FaultInjector{}
+	fi.config.Store(&FaultInjectionConfig{})
+	return fi
+}
+
+// Config returns the current fault injection configuration.
+func (fi *FaultInjector) Config() FaultInjectionConfig {
+	return *fi.config.Load()
+}
+
+// SetConfig replaces the current fault injection configuration.
+func (fi *FaultInjector) SetConfig(config FaultInjectionConfig) error {
+	if err := config.validate(); err != nil {
+		return err
+	}
+
+	fi.config.Store(&config)
+	return nil
+}
+
+func (fi *FaultInjector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+
+	case http.MethodPut, http.MethodPost:
+		var config FaultInjectionConfig
+		if err := json.NewDecoder(r.Body).Decode(&config); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if err := fi.SetConfig(config); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		log.Ctx(r.Context()).Warn().Interface("config", config).Msg("updated datastore fault injection configuration")
+
+	default:
+		w.Header().Set("Allow", "GET, PUT, POST")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(fi.Config()); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+// AuthenticatedHandler returns a handler serving the fault injector only to the requests
+// authenticated with one of the preshared keys as a bearer token, so that faults cannot be
+// injected by anyone able to reach the server exposing it.
+func (fi *FaultInjector) AuthenticatedHandler(presharedKeys []string) (http.Handler, error) {
+	if len(presharedKeys) == 0 {
+		return nil, errors.New("datastore fault injection requires a preshared key")
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || !slices.ContainsFunc(presharedKeys, func(key string) bool {
+			return subtle.ConstantTimeCompare([]byte(key), []byte(token)) == 1
+		}) {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "invalid or missing preshared key", http.StatusUnauthorized)
+			return
+		}
+
+		fi.ServeHTTP(w, r)
+	}), nil
+}
+
+// inject delays the operation and returns an error, according to the current configuration.
+func (fi *FaultInjector) inject(ctx context.Context) error {
+	config := fi.config.Load()
+
+	if config.Latency > 0 && roll(config.LatencyPercent) {
+		injectedFaultsCount.WithLabelValues("latency").Inc()
+
+		timer := time.NewTimer(config.Latency)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	if roll(config.ErrorPercent) {
+		injectedFaultsCount.WithLabelValues("error").Inc()
+		return errInjectedFault
+	}
+
+	return nil
+}
+
+func roll(percent float64) bool {
+	return percent > 0 && rand.Float64()*100 < percent
+}
+
+// FaultInjectingDatastore is a datastore which injects faults into its operations.
+type FaultInjectingDatastore interface {
+	datastore.Datastore
+
+	// FaultInjector returns the fault injector controlling the faults injected.
+	FaultInjector() *FaultInjector
+}
+
+type faultInjectionProxy struct {
+	datastore.Datastore
+
+	injector *FaultInjector
+
+	revisionsLock sync.Mutex
+	revisions     []datastore.Revision
+}
+
+// NewFaultInjectionProxy creates a proxy which injects latency, errors and stale revisions into
+// a percentage of the operations of the delegate datastore, as configured by the injector. It is
+// intended only for validating the resilience of SpiceDB and its clients to datastore faults.
+func NewFaultInjectionProxy(delegate datastore.Datastore, injector *FaultInjector) FaultInjectingDatastore {
+	return &faultInjectionProxy{Datastore: delegate, injector: injector}
+}
+
+func (p *faultInjectionProxy) FaultInjector() *FaultInjector {
+	return p.injector
+}
+
+func (p *faultInjectionProxy) Unwrap() datastore.Datastore {
+	return p.Datastore
+}
+
+func (p *faultInjectionProxy) OptimizedRevision(ctx context.Context) (datastore.Revision, error) {
+	if err := p.injector.inject(ctx); err != nil {
+		return datastore.NoRevision, err
+	}
+
+	rev, err := p.Datastore.OptimizedRevision(ctx)
+	if err != nil {
+		return rev, err
+	}
+
+	stale := p.retainRevision(rev)
+	if roll(p.injector.config.Load().StaleRevisionPercent) {
+		injectedFaultsCount.WithLabelValues("stale_revision").Inc()
+		return stale, nil
+	}
+
+	return rev, nil
+}
+
+// retainRevision retains the revision, returning the oldest retained revision.
+func (p *faultInjectionProxy) retainRevision(rev datastore.Revision) datastore.Revision {
+	p.revisionsLock.Lock()
+	defer p.revisionsLock.Unlock()
+
+	if len(p.revisions) == 0 || !p.revisions[len(p.revisions)-1].Equal(rev) {
+		p.revisions = append(p.revisions, rev)
+		if len(p.revisions) > maxRetainedRevisions {
+			p.revisions = p.revisions[1:]
+		}
+	}
+
+	return p.revisions[0]
+}
+
+func (p *faultInjectionProxy) HeadRevision(ctx context.Context) (datastore.Revision, error) {
+	if err := p.injector.inject(ctx); err != nil {
+		return datastore.NoRevision, err
+	}
+	return p.Datastore.HeadRevision(ctx)
+}
+
+func (p *faultInjectionProxy) ReadWriteTx(ctx context.Context, f datastore.TxUserFunc, opts ...options.RWTOptionsOption) (datastore.Revision, error) {
+	if err := p.injector.inject(ctx); err != nil {
+		return datastore.NoRevision, err
+	}
+	return p.Datastore.ReadWriteTx(ctx, f, opts...)
+}
+
+func (p *faultInjectionProxy) SnapshotReader(rev datastore.Revision) datastore.Reader {
+	return &faultInjectionReader{p.Datastore.SnapshotReader(rev), p.injector}
+}
+
+type faultInjectionReader struct {
+	datastore.Reader
+
+	injector *FaultInjector
+}
+
+func (r *faultInjectionReader) ReadNamespaceByName(ctx context.Context, nsName string) (*core.NamespaceDefinition, datastore.Revision, error) {
+	if err := r.injector.inject(ctx); err != nil {
+		return nil, datastore.NoRevision, err
+	}
+	return r.Reader.ReadNamespaceByName(ctx, nsName)
+}
+
+func (r *faultInjectionReader) ReadCaveatByName(ctx context.Context, name string) (*core.CaveatDefinition, datastore.Revision, error) {
+	if err := r.injector.inject(ctx); err != nil {
+		return nil, datastore.NoRevision, err
+	}
+	return r.Reader.ReadCaveatByName(ctx, name)
+}
+
+func (r *faultInjectionReader) QueryRelationships(ctx context.Context, filter datastore.RelationshipsFilter, opts ...options.QueryOptionsOption) (datastore.RelationshipIterator, error) {
+	if err := r.injector.inject(ctx); err != nil {
+		return nil, err
+	}
+	return r.Reader.QueryRelationships(ctx, filter, opts...)
+}
+
+func (r *faultInjectionReader) QueryRelationshipsBatch(ctx context.Context, filters []datastore.RelationshipsFilter, opts ...options.QueryOptionsOption) (datastore.TaggedRelationshipIterator, error) {
+	if err := r.injector.inject(ctx); err != nil {
+		return nil, err
+	}
+	return r.Reader.QueryRelationshipsBatch(ctx, filters, opts...)
+}
+
+func (r *faultInjectionReader) ReverseQueryRelationships(ctx context.Context, subjectsFilter datastore.SubjectsFilter, opts ...options.ReverseQueryOptionsOption) (datastore.RelationshipIterator, error) {
+	if err := r.injector.inject(ctx); err != nil {
+		return nil, err
+	}
+	return r.Reader.ReverseQueryRelationships(ctx, subjectsFilter, opts...)
+}
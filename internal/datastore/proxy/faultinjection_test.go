@@ -0,0 +1,166 @@
+package proxy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/authzed/spicedb/internal/datastore/common"
+	"github.com/authzed/spicedb/internal/datastore/dsfortesting"
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+func newFaultInjectionTestDatastore(t *testing.T) FaultInjectingDatastore {
+	rawDS, err := dsfortesting.NewMemDBDatastoreForTesting(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+	return NewFaultInjectionProxy(rawDS, NewFaultInjector())
+}
+
+func TestFaultInjectionNoFaults(t *testing.T) {
+	ds := newFaultInjectionTestDatastore(t)
+	ctx := context.Background()
+
+	rev, err := common.WriteRelationships(ctx, ds, tuple.UpdateOperationCreate, tuple.MustParse("document:foo#viewer@user:tom"))
+	require.NoError(t, err)
+
+	it, err := ds.SnapshotReader(rev).QueryRelationships(ctx, datastore.RelationshipsFilter{OptionalResourceType: "document"})
+	require.NoError(t, err)
+
+	count := 0
+	for _, err := range it {
+		require.NoError(t, err)
+		count++
+	}
+	require.Equal(t, 1, count)
+}
+
+func TestFaultInjectionErrors(t *testing.T) {
+	ds := newFaultInjectionTestDatastore(t)
+	require.NoError(t, ds.FaultInjector().SetConfig(FaultInjectionConfig{ErrorPercent: 100}))
+
+	ctx := context.Background()
+	_, err := ds.OptimizedRevision(ctx)
+	require.Equal(t, codes.Unavailable, status.Code(err))
+
+	_, err = ds.HeadRevision(ctx)
+	require.Equal(t, codes.Unavailable, status.Code(err))
+
+	_, err = common.WriteRelationships(ctx, ds, tuple.UpdateOperationCreate, tuple.MustParse("document:foo#viewer@user:tom"))
+	require.Equal(t, codes.Unavailable, status.Code(err))
+
+	_, err = ds.SnapshotReader(datastore.NoRevision).QueryRelationships(ctx, datastore.RelationshipsFilter{OptionalResourceType: "document"})
+	require.Equal(t, codes.Unavailable, status.Code(err))
+}
+
+func TestFaultInjectionLatency(t *testing.T) {
+	ds := newFaultInjectionTestDatastore(t)
+	require.NoError(t, ds.FaultInjector().SetConfig(FaultInjectionConfig{LatencyPercent: 100, Latency: 50 * time.Millisecond}))
+
+	start := time.Now()
+	_, err := ds.HeadRevision(context.Background())
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+
+	// Injected latency respects cancellation.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = ds.HeadRevision(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestFaultInjectionStaleRevisions(t *testing.T) {
+	ds := newFaultInjectionTestDatastore(t)
+	ctx := context.Background()
+
+	first, err := ds.OptimizedRevision(ctx)
+	require.NoError(t, err)
+
+	_, err = common.WriteRelationships(ctx, ds, tuple.UpdateOperationCreate, tuple.MustParse("document:foo#viewer@user:tom"))
+	require.NoError(t, err)
+
+	second, err := ds.OptimizedRevision(ctx)
+	require.NoError(t, err)
+	require.True(t, second.GreaterThan(first))
+
+	require.NoError(t, ds.FaultInjector().SetConfig(FaultInjectionConfig{StaleRevisionPercent: 100}))
+
+	stale, err := ds.OptimizedRevision(ctx)
+	require.NoError(t, err)
+	require.True(t, stale.Equal(first))
+}
+
+func TestFaultInjectorHandler(t *testing.T) {
+	injector := NewFaultInjector()
+
+	recorder := httptest.NewRecorder()
+	injector.ServeHTTP(recorder, httptest.NewRequest(http.MethodPut, "/", strings.NewReader(`{"latencyPercent": 10, "latency": "250ms", "errorPercent": 1.5, "staleRevisionPercent": 5}`)))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, FaultInjectionConfig{
+		LatencyPercent:       10,
+		Latency:              250 * time.Millisecond,
+		ErrorPercent:         1.5,
+		StaleRevisionPercent: 5,
+	}, injector.Config())
+
+	recorder = httptest.NewRecorder()
+	injector.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.JSONEq(t, `{"latencyPercent": 10, "latency": "250ms", "errorPercent": 1.5, "staleRevisionPercent": 5}`, recorder.Body.String())
+
+	recorder = httptest.NewRecorder()
+	injector.ServeHTTP(recorder, httptest.NewRequest(http.MethodPut, "/", strings.NewReader(`{"errorPercent": 101}`)))
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+	require.Equal(t, 1.5, injector.Config().ErrorPercent)
+
+	recorder = httptest.NewRecorder()
+	injector.ServeHTTP(recorder, httptest.NewRequest(http.MethodDelete, "/", nil))
+	require.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+}
+
+func TestFaultInjectorAuthenticatedHandler(t *testing.T) {
+	injector := NewFaultInjector()
+
+	_, err := injector.AuthenticatedHandler(nil)
+	require.Error(t, err)
+
+	handler, err := injector.AuthenticatedHandler([]string{"somekey", "otherkey"})
+	require.NoError(t, err)
+
+	tcs := []struct {
+		name          string
+		authorization string
+		expectedCode  int
+	}{
+		{"missing key", "", http.StatusUnauthorized},
+		{"invalid key", "Bearer invalidkey", http.StatusUnauthorized},
+		{"valid key", "Bearer otherkey", http.StatusOK},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(`{"errorPercent": 50}`))
+			if tc.authorization != "" {
+				req.Header.Set("Authorization", tc.authorization)
+			}
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+			require.Equal(t, tc.expectedCode, recorder.Code)
+
+			if tc.expectedCode == http.StatusOK {
+				require.Equal(t, 50.0, injector.Config().ErrorPercent)
+			} else {
+				require.Zero(t, injector.Config().ErrorPercent)
+			}
+		})
+	}
+}
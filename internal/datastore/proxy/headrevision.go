@@ -0,0 +1,139 @@
+package proxy
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"resenje.org/singleflight"
+
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/options"
+)
+
+var headRevisionRequestsCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "spicedb",
+	Subsystem: "datastore",
+	Name:      "head_revision_requests_total",
+	Help:      "total number of head revision requests, by whether they were served from the polled head revision or queried from the datastore",
+}, []string{"source"})
+
+// NewHeadRevisionPollingProxy creates a proxy which serves head revision requests from a single
+// head revision, polled from the delegate datastore in the background, instead of querying it for
+// each request. The head revision served is at most maxStaleness old: it is refreshed at half that
+// interval, and requests arriving when it is older query the datastore, deduplicated with any
+// concurrent query.
+//
+// Revisions of read-write transactions committed through the proxy advance the head revision,
+// such that requests following a write to this node always observe it. Writes made through other
+// nodes are observed within maxStaleness.
+func NewHeadRevisionPollingProxy(delegate datastore.Datastore, maxStaleness time.Duration) datastore.Datastore {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &headRevisionPollingProxy{
+		Datastore:    delegate,
+		maxStaleness: maxStaleness,
+		cancel:       cancel,
+		done:         make(chan struct{}),
+	}
+	go p.poll(ctx)
+	return p
+}
+
+type headRevisionPollingProxy struct {
+	datastore.Datastore
+
+	maxStaleness time.Duration
+	group        singleflight.Group[string, datastore.Revision]
+	cancel       context.CancelFunc
+	done         chan struct{}
+
+	lock sync.RWMutex
+	head datastore.Revision
+	// headAsOf is the time at which the last query for the head revision started, which the head
+	// revision is at least as recent as.
+	headAsOf time.Time
+}
+
+func (p *headRevisionPollingProxy) HeadRevision(ctx context.Context) (datastore.Revision, error) {
+	p.lock.RLock()
+	head, headAsOf := p.head, p.headAsOf
+	p.lock.RUnlock()
+
+	if head != nil && time.Since(headAsOf) <= p.maxStaleness {
+		headRevisionRequestsCounter.WithLabelValues("polled").Inc()
+		return head, nil
+	}
+
+	headRevisionRequestsCounter.WithLabelValues("queried").Inc()
+	return p.refresh(ctx)
+}
+
+func (p *headRevisionPollingProxy) ReadWriteTx(ctx context.Context, f datastore.TxUserFunc, opts ...options.RWTOptionsOption) (datastore.Revision, error) {
+	rev, err := p.Datastore.ReadWriteTx(ctx, f, opts...)
+	if err == nil {
+		// A write does not make the head revision any more recent with respect to other writes, so
+		// it only advances the head revision.
+		p.recordHeadRevision(rev, time.Time{})
+	}
+	return rev, err
+}
+
+func (p *headRevisionPollingProxy) poll(ctx context.Context) {
+	defer close(p.done)
+
+	ticker := time.NewTicker(max(p.maxStaleness/2, time.Millisecond))
+	defer ticker.Stop()
+
+	for {
+		if _, err := p.refresh(ctx); err != nil && ctx.Err() == nil {
+			log.Ctx(ctx).Warn().Err(err).Msg("failed to poll the head revision")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *headRevisionPollingProxy) refresh(ctx context.Context) (datastore.Revision, error) {
+	rev, _, err := p.group.Do(ctx, "", func(ctx context.Context) (datastore.Revision, error) {
+		asOf := time.Now()
+		rev, err := p.Datastore.HeadRevision(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		return p.recordHeadRevision(rev, asOf), nil
+	})
+	return rev, err
+}
+
+// recordHeadRevision records a head revision, and returns the head revision, which is the most
+// recent revision recorded.
+func (p *headRevisionPollingProxy) recordHeadRevision(rev datastore.Revision, asOf time.Time) datastore.Revision {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.head == nil || rev.GreaterThan(p.head) {
+		p.head = rev
+	}
+	if asOf.After(p.headAsOf) {
+		p.headAsOf = asOf
+	}
+	return p.head
+}
+
+func (p *headRevisionPollingProxy) Close() error {
+	p.cancel()
+	<-p.done
+	return p.Datastore.Close()
+}
+
+func (p *headRevisionPollingProxy) Unwrap() datastore.Datastore {
+	return p.Datastore
+}
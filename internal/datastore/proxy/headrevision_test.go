@@ -0,0 +1,90 @@
+package proxy
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/common"
+	"github.com/authzed/spicedb/internal/datastore/dsfortesting"
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// headCountingDatastore counts the head revision queries it receives.
+type headCountingDatastore struct {
+	datastore.Datastore
+
+	headRevisionCalls atomic.Int32
+}
+
+func (ds *headCountingDatastore) HeadRevision(ctx context.Context) (datastore.Revision, error) {
+	ds.headRevisionCalls.Add(1)
+	return ds.Datastore.HeadRevision(ctx)
+}
+
+func TestHeadRevisionPolling(t *testing.T) {
+	rawDS, err := dsfortesting.NewMemDBDatastoreForTesting(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+
+	delegate := &headCountingDatastore{Datastore: rawDS}
+	ds := NewHeadRevisionPollingProxy(delegate, time.Minute)
+	t.Cleanup(func() { require.NoError(t, ds.Close()) })
+
+	ctx := context.Background()
+	initial, err := ds.HeadRevision(ctx)
+	require.NoError(t, err)
+
+	// Concurrent requests are served from the polled head revision.
+	var wg sync.WaitGroup
+	for range 100 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rev, err := ds.HeadRevision(ctx)
+			require.NoError(t, err)
+			require.True(t, rev.Equal(initial))
+		}()
+	}
+	wg.Wait()
+	require.LessOrEqual(t, delegate.headRevisionCalls.Load(), int32(2))
+
+	// Writes made elsewhere are not observed until the head revision is refreshed.
+	_, err = common.WriteRelationships(ctx, rawDS, tuple.UpdateOperationCreate, tuple.MustParse("document:foo#viewer@user:tom"))
+	require.NoError(t, err)
+
+	rev, err := ds.HeadRevision(ctx)
+	require.NoError(t, err)
+	require.True(t, rev.Equal(initial))
+
+	// Writes made through the proxy are observed immediately.
+	written, err := common.WriteRelationships(ctx, ds, tuple.UpdateOperationCreate, tuple.MustParse("document:foo#viewer@user:fred"))
+	require.NoError(t, err)
+
+	rev, err = ds.HeadRevision(ctx)
+	require.NoError(t, err)
+	require.True(t, rev.Equal(written))
+}
+
+func TestHeadRevisionPollingStaleness(t *testing.T) {
+	rawDS, err := dsfortesting.NewMemDBDatastoreForTesting(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+
+	ds := NewHeadRevisionPollingProxy(rawDS, 20*time.Millisecond)
+	t.Cleanup(func() { require.NoError(t, ds.Close()) })
+
+	ctx := context.Background()
+	written, err := common.WriteRelationships(ctx, rawDS, tuple.UpdateOperationCreate, tuple.MustParse("document:foo#viewer@user:tom"))
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		rev, err := ds.HeadRevision(ctx)
+		require.NoError(t, err)
+		return rev.Equal(written)
+	}, time.Second, 5*time.Millisecond)
+}
@@ -243,25 +243,38 @@ func (hp hedgingReader) QueryRelationships(
 	filter datastore.RelationshipsFilter,
 	options ...options.QueryOptionsOption,
 ) (iter datastore.RelationshipIterator, err error) {
-	return hp.executeQuery(ctx, func(c context.Context) (datastore.RelationshipIterator, error) {
+	return executeHedgedQuery(ctx, hp.p, func(c context.Context) (datastore.RelationshipIterator, error) {
 		return hp.Reader.QueryRelationships(ctx, filter, options...)
 	})
 }
 
+func (hp hedgingReader) QueryRelationshipsBatch(
+	ctx context.Context,
+	filters []datastore.RelationshipsFilter,
+	options ...options.QueryOptionsOption,
+) (iter datastore.TaggedRelationshipIterator, err error) {
+	return executeHedgedQuery(ctx, hp.p, func(c context.Context) (datastore.TaggedRelationshipIterator, error) {
+		return hp.Reader.QueryRelationshipsBatch(ctx, filters, options...)
+	})
+}
+
 func (hp hedgingReader) ReverseQueryRelationships(
 	ctx context.Context,
 	subjectsFilter datastore.SubjectsFilter,
 	opts ...options.ReverseQueryOptionsOption,
 ) (iter datastore.RelationshipIterator, err error) {
-	return hp.executeQuery(ctx, func(c context.Context) (datastore.RelationshipIterator, error) {
+	return executeHedgedQuery(ctx, hp.p, func(c context.Context) (datastore.RelationshipIterator, error) {
 		return hp.Reader.ReverseQueryRelationships(ctx, subjectsFilter, opts...)
 	})
 }
 
-func (hp hedgingReader) executeQuery(
+// executeHedgedQuery executes the query, hedged by the proxy, returning the iterator of the
+// fastest request.
+func executeHedgedQuery[I ~func(yield func(T, error) bool), T any](
 	ctx context.Context,
-	exec func(context.Context) (datastore.RelationshipIterator, error),
-) (delegateIterator datastore.RelationshipIterator, err error) {
+	p hedgingProxy,
+	exec func(context.Context) (I, error),
+) (delegateIterator I, err error) {
 	var once sync.Once
 	subreq := func(ctx context.Context, responseReady chan<- struct{}) {
 		tempIterator, tempErr := exec(ctx)
@@ -282,7 +295,7 @@ func (hp hedgingReader) executeQuery(
 		responseReady <- struct{}{}
 	}
 
-	hp.p.queryTuplesHedger(ctx, subreq)
+	p.queryTuplesHedger(ctx, subreq)
 
 	return
 }
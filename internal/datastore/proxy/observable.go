@@ -213,6 +213,29 @@ func (r *observableReader) ReadNamespaceByName(ctx context.Context, nsName strin
 	return r.delegate.ReadNamespaceByName(ctx, nsName)
 }
 
+func (r *observableReader) QueryRelationshipsBatch(ctx context.Context, filters []datastore.RelationshipsFilter, options ...options.QueryOptionsOption) (datastore.TaggedRelationshipIterator, error) {
+	ctx, closer := observe(ctx, "QueryRelationshipsBatch", trace.WithAttributes(
+		attribute.Int("filterCount", len(filters)),
+	))
+
+	iterator, err := r.delegate.QueryRelationshipsBatch(ctx, filters, options...)
+	if err != nil {
+		return iterator, err
+	}
+
+	return func(yield func(datastore.TaggedRelationship, error) bool) {
+		var count uint64
+		for tagged, err := range iterator {
+			count++
+			if !yield(tagged, err) {
+				break
+			}
+		}
+		loadedRelationshipCount.Observe(float64(count))
+		closer()
+	}, nil
+}
+
 func (r *observableReader) QueryRelationships(ctx context.Context, filter datastore.RelationshipsFilter, options ...options.QueryOptionsOption) (datastore.RelationshipIterator, error) {
 	ctx, closer := observe(ctx, "QueryRelationships", trace.WithAttributes(
 		attribute.String("resourceType", filter.OptionalResourceType),
@@ -295,6 +318,33 @@ func (rwt *observableRWT) StoreCounterValue(ctx context.Context, name string, va
 	return rwt.delegate.StoreCounterValue(ctx, name, value, computedAtRevision)
 }
 
+func (rwt *observableRWT) ReadRecords(ctx context.Context, keyPrefix string) ([]datastore.Record, error) {
+	ctx, closer := observe(ctx, "ReadRecords", trace.WithAttributes(
+		attribute.String("prefix", keyPrefix),
+	))
+	defer closer()
+
+	return rwt.delegate.ReadRecords(ctx, keyPrefix)
+}
+
+func (rwt *observableRWT) WriteRecords(ctx context.Context, records ...datastore.Record) error {
+	ctx, closer := observe(ctx, "WriteRecords", trace.WithAttributes(
+		attribute.Int("records", len(records)),
+	))
+	defer closer()
+
+	return rwt.delegate.WriteRecords(ctx, records...)
+}
+
+func (rwt *observableRWT) DeleteRecords(ctx context.Context, keys ...string) error {
+	ctx, closer := observe(ctx, "DeleteRecords", trace.WithAttributes(
+		attribute.Int("records", len(keys)),
+	))
+	defer closer()
+
+	return rwt.delegate.DeleteRecords(ctx, keys...)
+}
+
 func (rwt *observableRWT) WriteCaveats(ctx context.Context, caveats []*core.CaveatDefinition) error {
 	caveatNames := make([]string, 0, len(caveats))
 	for _, caveat := range caveats {
@@ -135,6 +135,26 @@ func (dm *MockReader) QueryRelationships(
 	return results, args.Error(1)
 }
 
+func (dm *MockReader) QueryRelationshipsBatch(
+	_ context.Context,
+	filters []datastore.RelationshipsFilter,
+	options ...options.QueryOptionsOption,
+) (datastore.TaggedRelationshipIterator, error) {
+	callArgs := make([]interface{}, 0, len(options)+1)
+	callArgs = append(callArgs, filters)
+	for _, option := range options {
+		callArgs = append(callArgs, option)
+	}
+
+	args := dm.Called(callArgs...)
+	var results datastore.TaggedRelationshipIterator
+	if args.Get(0) != nil {
+		results = args.Get(0).(datastore.TaggedRelationshipIterator)
+	}
+
+	return results, args.Error(1)
+}
+
 func (dm *MockReader) ReverseQueryRelationships(
 	_ context.Context,
 	subjectsFilter datastore.SubjectsFilter,
@@ -234,6 +254,26 @@ func (dm *MockReadWriteTransaction) QueryRelationships(
 	return results, args.Error(1)
 }
 
+func (dm *MockReadWriteTransaction) QueryRelationshipsBatch(
+	_ context.Context,
+	filters []datastore.RelationshipsFilter,
+	options ...options.QueryOptionsOption,
+) (datastore.TaggedRelationshipIterator, error) {
+	callArgs := make([]interface{}, 0, len(options)+1)
+	callArgs = append(callArgs, filters)
+	for _, option := range options {
+		callArgs = append(callArgs, option)
+	}
+
+	args := dm.Called(callArgs...)
+	var results datastore.TaggedRelationshipIterator
+	if args.Get(0) != nil {
+		results = args.Get(0).(datastore.TaggedRelationshipIterator)
+	}
+
+	return results, args.Error(1)
+}
+
 func (dm *MockReadWriteTransaction) ReverseQueryRelationships(
 	_ context.Context,
 	subjectsFilter datastore.SubjectsFilter,
@@ -341,6 +381,21 @@ func (dm *MockReadWriteTransaction) StoreCounterValue(ctx context.Context, name
 	return args.Error(0)
 }
 
+func (dm *MockReadWriteTransaction) ReadRecords(_ context.Context, keyPrefix string) ([]datastore.Record, error) {
+	args := dm.Called(keyPrefix)
+	return args.Get(0).([]datastore.Record), args.Error(1)
+}
+
+func (dm *MockReadWriteTransaction) WriteRecords(_ context.Context, records ...datastore.Record) error {
+	args := dm.Called(records)
+	return args.Error(0)
+}
+
+func (dm *MockReadWriteTransaction) DeleteRecords(_ context.Context, keys ...string) error {
+	args := dm.Called(keys)
+	return args.Error(0)
+}
+
 var (
 	_ datastore.Datastore            = &MockDatastore{}
 	_ datastore.Reader               = &MockReader{}
@@ -9,11 +9,14 @@ import (
 	"sync"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
 	log "github.com/authzed/spicedb/internal/logging"
 	"github.com/authzed/spicedb/pkg/datastore"
 	"github.com/authzed/spicedb/pkg/datastore/options"
+	"github.com/authzed/spicedb/pkg/fips"
 	corev1 "github.com/authzed/spicedb/pkg/proto/core/v1"
 	"github.com/authzed/spicedb/pkg/spiceerrors"
 	"github.com/authzed/spicedb/pkg/tuple"
@@ -31,6 +34,39 @@ type KeyConfig struct {
 	Bytes []byte
 }
 
+// IntegrityViolationMode is how relationships failing integrity verification are handled.
+type IntegrityViolationMode int
+
+const (
+	// RejectIntegrityViolations fails the reads and watches returning relationships which fail
+	// verification.
+	RejectIntegrityViolations IntegrityViolationMode = iota
+
+	// FlagIntegrityViolations logs and counts relationships which fail verification, but returns
+	// them, such as while enabling integrity on a datastore whose existing relationships are not
+	// yet signed.
+	FlagIntegrityViolations
+)
+
+// ParseIntegrityViolationMode returns the mode with the given name, either `reject` or `flag`.
+func ParseIntegrityViolationMode(name string) (IntegrityViolationMode, error) {
+	switch name {
+	case "reject":
+		return RejectIntegrityViolations, nil
+	case "flag":
+		return FlagIntegrityViolations, nil
+	default:
+		return RejectIntegrityViolations, fmt.Errorf("unknown relationship integrity mode `%s`: expected reject or flag", name)
+	}
+}
+
+var integrityViolationsCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "spicedb",
+	Subsystem: "datastore",
+	Name:      "relationship_integrity_violations_total",
+	Help:      "total number of relationships read which failed integrity verification, by whether they were rejected or flagged",
+}, []string{"action"})
+
 type hmacConfig struct {
 	keyID     string
 	expiredAt *time.Time
@@ -44,8 +80,9 @@ var (
 
 // NewRelationshipIntegrityProxy creates a new datastore proxy that ensures the integrity of
 // relationships by using HMACs to sign the data. The current key is used to sign new data,
-// and the expired keys are used to verify old data, if any.
-func NewRelationshipIntegrityProxy(ds datastore.Datastore, currentKey KeyConfig, expiredKeys []KeyConfig) (datastore.Datastore, error) {
+// and the expired keys are used to verify old data, if any. Relationships failing verification
+// are handled according to the violation mode.
+func NewRelationshipIntegrityProxy(ds datastore.Datastore, currentKey KeyConfig, expiredKeys []KeyConfig, violationMode IntegrityViolationMode) (datastore.Datastore, error) {
 	// Ensure the datastore supports integrity.
 	features, err := ds.OfflineFeatures()
 	if err != nil {
@@ -64,6 +101,10 @@ func NewRelationshipIntegrityProxy(ds datastore.Datastore, currentKey KeyConfig,
 		return nil, fmt.Errorf("current key ID cannot be empty")
 	}
 
+	if err := fips.CheckHMACKey(currentKey.Bytes); err != nil {
+		return nil, fmt.Errorf("invalid current key: %w", err)
+	}
+
 	currentKeyHMAC := &hmacConfig{
 		keyID:     currentKey.ID,
 		expiredAt: currentKey.ExpiredAt,
@@ -91,6 +132,10 @@ func NewRelationshipIntegrityProxy(ds datastore.Datastore, currentKey KeyConfig,
 			return nil, fmt.Errorf("expired key missing expiration time")
 		}
 
+		if err := fips.CheckHMACKey(key.Bytes); err != nil {
+			return nil, fmt.Errorf("invalid expired key %s: %w", key.ID, err)
+		}
+
 		if _, ok := keysByID[key.ID]; ok {
 			return nil, fmt.Errorf("found duplicate key ID: %s", key.ID)
 		}
@@ -107,12 +152,14 @@ func NewRelationshipIntegrityProxy(ds datastore.Datastore, currentKey KeyConfig,
 	log.Debug().
 		Str("current_key_id", currentKey.ID).
 		Strs("expired_key_ids", expiredKeyIDs).
+		Bool("flag_violations", violationMode == FlagIntegrityViolations).
 		Msgf("created relationship integrity proxy")
 
 	return &relationshipIntegrityProxy{
-		ds:         ds,
-		primaryKey: currentKeyHMAC,
-		keysByID:   keysByID,
+		ds:            ds,
+		primaryKey:    currentKeyHMAC,
+		keysByID:      keysByID,
+		violationMode: violationMode,
 	}, nil
 }
 
@@ -125,9 +172,10 @@ func poolForKey(key []byte) sync.Pool {
 }
 
 type relationshipIntegrityProxy struct {
-	ds         datastore.Datastore
-	primaryKey *hmacConfig
-	keysByID   map[string]*hmacConfig
+	ds            datastore.Datastore
+	primaryKey    *hmacConfig
+	keysByID      map[string]*hmacConfig
+	violationMode IntegrityViolationMode
 }
 
 func (r *relationshipIntegrityProxy) lookupKey(keyID string) (*hmacConfig, error) {
@@ -252,6 +300,24 @@ func (r *relationshipIntegrityProxy) validateRelationTuple(rel tuple.Relationshi
 	return nil
 }
 
+// checkRelationship validates the integrity of the relationship, returning the violation if
+// violations are rejected, or logging and counting it if they are flagged.
+func (r *relationshipIntegrityProxy) checkRelationship(ctx context.Context, rel tuple.Relationship) error {
+	err := r.validateRelationTuple(rel)
+	if err == nil {
+		return nil
+	}
+
+	if r.violationMode != FlagIntegrityViolations {
+		integrityViolationsCounter.WithLabelValues("rejected").Inc()
+		return err
+	}
+
+	integrityViolationsCounter.WithLabelValues("flagged").Inc()
+	log.Ctx(ctx).Warn().Err(err).Msg("relationship failed integrity verification")
+	return nil
+}
+
 func (r *relationshipIntegrityProxy) Watch(ctx context.Context, afterRevision datastore.Revision, options datastore.WatchOptions) (<-chan *datastore.RevisionChanges, <-chan error) {
 	resultsChan, errChan := r.ds.Watch(ctx, afterRevision, options)
 	checkedResultsChan := make(chan *datastore.RevisionChanges)
@@ -266,7 +332,7 @@ func (r *relationshipIntegrityProxy) Watch(ctx context.Context, afterRevision da
 			case result := <-resultsChan:
 				for _, rel := range result.RelationshipChanges {
 					if rel.Operation != tuple.UpdateOperationDelete {
-						err := r.validateRelationTuple(rel.Relationship)
+						err := r.checkRelationship(ctx, rel.Relationship)
 						if err != nil {
 							checkedErrChan <- err
 							return
@@ -307,7 +373,7 @@ func (r relationshipIntegrityReader) QueryRelationships(ctx context.Context, fil
 				return
 			}
 
-			if err := r.parent.validateRelationTuple(rel); err != nil {
+			if err := r.parent.checkRelationship(ctx, rel); err != nil {
 				yield(rel, err)
 				return
 			}
@@ -319,6 +385,32 @@ func (r relationshipIntegrityReader) QueryRelationships(ctx context.Context, fil
 	}, nil
 }
 
+func (r relationshipIntegrityReader) QueryRelationshipsBatch(ctx context.Context, filters []datastore.RelationshipsFilter, options ...options.QueryOptionsOption) (datastore.TaggedRelationshipIterator, error) {
+	it, err := r.wrapped.QueryRelationshipsBatch(ctx, filters, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(yield func(datastore.TaggedRelationship, error) bool) {
+		for tagged, err := range it {
+			if err != nil {
+				yield(tagged, err)
+				return
+			}
+
+			if err := r.parent.checkRelationship(ctx, tagged.Relationship); err != nil {
+				yield(tagged, err)
+				return
+			}
+
+			tagged.Relationship = tagged.Relationship.WithoutIntegrity()
+			if !yield(tagged, nil) {
+				return
+			}
+		}
+	}, nil
+}
+
 func (r relationshipIntegrityReader) ReverseQueryRelationships(ctx context.Context, subjectsFilter datastore.SubjectsFilter, options ...options.ReverseQueryOptionsOption) (datastore.RelationshipIterator, error) {
 	it, err := r.wrapped.ReverseQueryRelationships(ctx, subjectsFilter, options...)
 	if err != nil {
@@ -332,7 +424,7 @@ func (r relationshipIntegrityReader) ReverseQueryRelationships(ctx context.Conte
 				return
 			}
 
-			if err := r.parent.validateRelationTuple(rel); err != nil {
+			if err := r.parent.checkRelationship(ctx, rel); err != nil {
 				yield(rel, err)
 				return
 			}
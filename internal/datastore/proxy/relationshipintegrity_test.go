@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
@@ -61,7 +62,7 @@ func TestWriteWithPredefinedIntegrity(t *testing.T) {
 	ds, err := dsfortesting.NewMemDBDatastoreForTesting(0, 5*time.Second, 1*time.Hour)
 	require.NoError(t, err)
 
-	pds, err := NewRelationshipIntegrityProxy(ds, DefaultKeyForTesting, nil)
+	pds, err := NewRelationshipIntegrityProxy(ds, DefaultKeyForTesting, nil, RejectIntegrityViolations)
 	require.NoError(t, err)
 
 	require.Panics(t, func() {
@@ -89,7 +90,7 @@ func TestReadWithMissingIntegrity(t *testing.T) {
 	require.NoError(t, err)
 
 	// Attempt to read, which should return an error.
-	pds, err := NewRelationshipIntegrityProxy(ds, DefaultKeyForTesting, nil)
+	pds, err := NewRelationshipIntegrityProxy(ds, DefaultKeyForTesting, nil, RejectIntegrityViolations)
 	require.NoError(t, err)
 
 	headRev, err := pds.HeadRevision(context.Background())
@@ -111,7 +112,7 @@ func TestBasicIntegrityFailureDueToInvalidHashVersion(t *testing.T) {
 	ds, err := dsfortesting.NewMemDBDatastoreForTesting(0, 5*time.Second, 1*time.Hour)
 	require.NoError(t, err)
 
-	pds, err := NewRelationshipIntegrityProxy(ds, DefaultKeyForTesting, nil)
+	pds, err := NewRelationshipIntegrityProxy(ds, DefaultKeyForTesting, nil, RejectIntegrityViolations)
 	require.NoError(t, err)
 
 	// Write some relationships.
@@ -160,7 +161,7 @@ func TestBasicIntegrityFailureDueToInvalidHashSignature(t *testing.T) {
 	ds, err := dsfortesting.NewMemDBDatastoreForTesting(0, 5*time.Second, 1*time.Hour)
 	require.NoError(t, err)
 
-	pds, err := NewRelationshipIntegrityProxy(ds, DefaultKeyForTesting, nil)
+	pds, err := NewRelationshipIntegrityProxy(ds, DefaultKeyForTesting, nil, RejectIntegrityViolations)
 	require.NoError(t, err)
 
 	// Write some relationships.
@@ -210,7 +211,7 @@ func TestBasicIntegrityFailureDueToWriteWithExpiredKey(t *testing.T) {
 	require.NoError(t, err)
 
 	// Create a proxy with the to-be-expired key and write some relationships.
-	epds, err := NewRelationshipIntegrityProxy(ds, toBeExpiredKeyForTesting, nil)
+	epds, err := NewRelationshipIntegrityProxy(ds, toBeExpiredKeyForTesting, nil, RejectIntegrityViolations)
 	require.NoError(t, err)
 
 	// Write some relationships.
@@ -225,7 +226,7 @@ func TestBasicIntegrityFailureDueToWriteWithExpiredKey(t *testing.T) {
 
 	pds, err := NewRelationshipIntegrityProxy(ds, DefaultKeyForTesting, []KeyConfig{
 		expiredKeyForTesting,
-	})
+	}, RejectIntegrityViolations)
 	require.NoError(t, err)
 
 	// Read them back and ensure the read fails.
@@ -244,6 +245,66 @@ func TestBasicIntegrityFailureDueToWriteWithExpiredKey(t *testing.T) {
 	require.ErrorContains(t, err, "is signed by an expired key")
 }
 
+func TestFlagIntegrityViolations(t *testing.T) {
+	ds, err := dsfortesting.NewMemDBDatastoreForTesting(0, 5*time.Second, 1*time.Hour)
+	require.NoError(t, err)
+
+	pds, err := NewRelationshipIntegrityProxy(ds, DefaultKeyForTesting, nil, FlagIntegrityViolations)
+	require.NoError(t, err)
+
+	// Write some relationships.
+	_, err = pds.ReadWriteTx(context.Background(), func(ctx context.Context, tx datastore.ReadWriteTransaction) error {
+		return tx.WriteRelationships(context.Background(), []tuple.RelationshipUpdate{
+			tuple.Create(tuple.MustParse("resource:foo#viewer@user:tom")),
+			tuple.Create(tuple.MustParse("resource:foo#viewer@user:fred")),
+		})
+	})
+	require.NoError(t, err)
+
+	// Write a relationship without integrity data by bypassing the proxy.
+	_, err = ds.ReadWriteTx(context.Background(), func(ctx context.Context, tx datastore.ReadWriteTransaction) error {
+		return tx.WriteRelationships(context.Background(), []tuple.RelationshipUpdate{
+			tuple.Create(tuple.MustParse("resource:foo#viewer@user:jimmy")),
+		})
+	})
+	require.NoError(t, err)
+
+	flagged := integrityViolationsCounter.WithLabelValues("flagged")
+	before := promtestutil.ToFloat64(flagged)
+
+	// Read them back and ensure the unsigned relationship is returned and flagged.
+	headRev, err := pds.HeadRevision(context.Background())
+	require.NoError(t, err)
+
+	reader := pds.SnapshotReader(headRev)
+	iter, err := reader.QueryRelationships(
+		context.Background(),
+		datastore.RelationshipsFilter{OptionalResourceType: "resource"},
+	)
+	require.NoError(t, err)
+
+	rels, err := datastore.IteratorToSlice(iter)
+	require.NoError(t, err)
+	require.Len(t, rels, 3)
+	for _, rel := range rels {
+		require.Nil(t, rel.OptionalIntegrity)
+	}
+	require.Equal(t, before+1, promtestutil.ToFloat64(flagged))
+}
+
+func TestParseIntegrityViolationMode(t *testing.T) {
+	mode, err := ParseIntegrityViolationMode("reject")
+	require.NoError(t, err)
+	require.Equal(t, RejectIntegrityViolations, mode)
+
+	mode, err = ParseIntegrityViolationMode("flag")
+	require.NoError(t, err)
+	require.Equal(t, FlagIntegrityViolations, mode)
+
+	_, err = ParseIntegrityViolationMode("ignore")
+	require.ErrorContains(t, err, "unknown relationship integrity mode `ignore`")
+}
+
 func TestWatchIntegrityFailureDueToInvalidHashSignature(t *testing.T) {
 	ds, err := dsfortesting.NewMemDBDatastoreForTesting(0, 5*time.Second, 1*time.Hour)
 	require.NoError(t, err)
@@ -251,7 +312,7 @@ func TestWatchIntegrityFailureDueToInvalidHashSignature(t *testing.T) {
 	headRev, err := ds.HeadRevision(context.Background())
 	require.NoError(t, err)
 
-	pds, err := NewRelationshipIntegrityProxy(ds, DefaultKeyForTesting, nil)
+	pds, err := NewRelationshipIntegrityProxy(ds, DefaultKeyForTesting, nil, RejectIntegrityViolations)
 	require.NoError(t, err)
 
 	watchEvents, errChan := pds.Watch(context.Background(), headRev, datastore.WatchJustRelationships())
@@ -292,7 +353,7 @@ func BenchmarkQueryRelsWithIntegrity(b *testing.B) {
 			ds, err := dsfortesting.NewMemDBDatastoreForTesting(0, 5*time.Second, 1*time.Hour)
 			require.NoError(b, err)
 
-			pds, err := NewRelationshipIntegrityProxy(ds, DefaultKeyForTesting, nil)
+			pds, err := NewRelationshipIntegrityProxy(ds, DefaultKeyForTesting, nil, RejectIntegrityViolations)
 			require.NoError(b, err)
 
 			_, err = pds.ReadWriteTx(context.Background(), func(ctx context.Context, tx datastore.ReadWriteTransaction) error {
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/authzed/spicedb/internal/datastore/common"
 	log "github.com/authzed/spicedb/internal/logging"
@@ -14,6 +15,14 @@ import (
 	core "github.com/authzed/spicedb/pkg/proto/core/v1"
 )
 
+// ReplicatedDatastore is a datastore which writes to a primary and reads from read replicas.
+type ReplicatedDatastore interface {
+	datastore.Datastore
+
+	// Replicas returns the read replicas of the datastore.
+	Replicas() []datastore.ReadOnlyDatastore
+}
+
 // NewCheckingReplicatedDatastore creates a new datastore that writes to the provided primary and reads
 // from the provided replicas. The replicas are chosen in a round-robin fashion. If a replica does
 // not have the requested revision, the primary is used instead.
@@ -77,6 +86,16 @@ func NewStrictReplicatedDatastore(primary datastore.Datastore, replicas ...datas
 	}, nil
 }
 
+// setPrimaryRevisionQuantization changes the revision quantization of the primary, from which the
+// optimized revisions of a replicated datastore are computed.
+func setPrimaryRevisionQuantization(primary datastore.Datastore, quantization time.Duration) error {
+	requantizable := datastore.UnwrapAs[datastore.RequantizableDatastore](primary)
+	if requantizable == nil {
+		return errors.New("the primary datastore does not support changing the revision quantization")
+	}
+	return requantizable.SetRevisionQuantization(quantization)
+}
+
 func selectReplica[T any](replicas []T, lastReplica *uint64) T {
 	if len(replicas) == 1 {
 		return replicas[0]
@@ -101,6 +120,14 @@ type checkingReplicatedDatastore struct {
 	lastReplica uint64
 }
 
+func (rd *checkingReplicatedDatastore) Replicas() []datastore.ReadOnlyDatastore {
+	return rd.replicas
+}
+
+func (rd *checkingReplicatedDatastore) SetRevisionQuantization(quantization time.Duration) error {
+	return setPrimaryRevisionQuantization(rd.Datastore, quantization)
+}
+
 // SnapshotReader creates a read-only handle that reads the datastore at the specified revision.
 // Any errors establishing the reader will be returned by subsequent calls.
 func (rd *checkingReplicatedDatastore) SnapshotReader(revision datastore.Revision) datastore.Reader {
@@ -119,6 +146,14 @@ type strictReplicatedDatastore struct {
 	lastReplica uint64
 }
 
+func (rd *strictReplicatedDatastore) Replicas() []datastore.ReadOnlyDatastore {
+	return rd.replicas
+}
+
+func (rd *strictReplicatedDatastore) SetRevisionQuantization(quantization time.Duration) error {
+	return setPrimaryRevisionQuantization(rd.Datastore, quantization)
+}
+
 // SnapshotReader creates a read-only handle that reads the datastore at the specified revision.
 // Any errors establishing the reader will be returned by subsequent calls.
 func (rd *strictReplicatedDatastore) SnapshotReader(revision datastore.Revision) datastore.Reader {
@@ -181,6 +216,18 @@ func (rr *checkingStableReader) QueryRelationships(
 	return rr.chosenReader.QueryRelationships(ctx, filter, options...)
 }
 
+func (rr *checkingStableReader) QueryRelationshipsBatch(
+	ctx context.Context,
+	filters []datastore.RelationshipsFilter,
+	options ...options.QueryOptionsOption,
+) (datastore.TaggedRelationshipIterator, error) {
+	if err := rr.determineSource(ctx); err != nil {
+		return nil, err
+	}
+
+	return rr.chosenReader.QueryRelationshipsBatch(ctx, filters, options...)
+}
+
 func (rr *checkingStableReader) ReverseQueryRelationships(
 	ctx context.Context,
 	subjectsFilter datastore.SubjectsFilter,
@@ -319,6 +366,20 @@ func (rr *strictReadReplicatedReader) QueryRelationships(
 	return relationships, err
 }
 
+func (rr *strictReadReplicatedReader) QueryRelationshipsBatch(
+	ctx context.Context,
+	filters []datastore.RelationshipsFilter,
+	options ...options.QueryOptionsOption,
+) (datastore.TaggedRelationshipIterator, error) {
+	sr := rr.replica.SnapshotReader(rr.rev)
+	relationships, err := sr.QueryRelationshipsBatch(ctx, filters, options...)
+	if err != nil && errors.As(err, &common.RevisionUnavailableError{}) {
+		log.Trace().Str("revision", rr.rev.String()).Msg("replica does not contain the requested revision, using primary")
+		return rr.primary.SnapshotReader(rr.rev).QueryRelationshipsBatch(ctx, filters, options...)
+	}
+	return relationships, err
+}
+
 func (rr *strictReadReplicatedReader) ReverseQueryRelationships(
 	ctx context.Context,
 	subjectsFilter datastore.SubjectsFilter,
@@ -202,6 +202,10 @@ func (fakeSnapshotReader) QueryRelationships(context.Context, datastore.Relation
 	return nil, fmt.Errorf("not implemented")
 }
 
+func (fakeSnapshotReader) QueryRelationshipsBatch(context.Context, []datastore.RelationshipsFilter, ...options.QueryOptionsOption) (datastore.TaggedRelationshipIterator, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
 func (fakeSnapshotReader) ReverseQueryRelationships(context.Context, datastore.SubjectsFilter, ...options.ReverseQueryOptionsOption) (datastore.RelationshipIterator, error) {
 	return nil, fmt.Errorf("not implemented")
 }
@@ -0,0 +1,185 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/authzed/spicedb/internal/datastore/common"
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/options"
+)
+
+var writesDegradedGauge = promauto.NewGauge(prometheus.GaugeOpts{
+	Namespace: "spicedb",
+	Subsystem: "datastore",
+	Name:      "writes_degraded",
+	Help:      "1 if writes are failing fast because the datastore was detected to be read-only, 0 otherwise",
+})
+
+var errWritesDegraded = common.NewReadOnlyTransactionError(errors.New("writes are degraded since the datastore was detected to be read-only"))
+
+// WriteDegradingDatastore is a datastore which degrades gracefully when the underlying datastore
+// becomes read-only, such as during a failover or maintenance.
+type WriteDegradingDatastore interface {
+	datastore.Datastore
+
+	// WritesDegraded returns whether writes are currently degraded.
+	WritesDegraded() bool
+
+	// SetDegradationListener sets the function called whenever writes become degraded or recover.
+	SetDegradationListener(listener func(degraded bool))
+}
+
+type writeDegradationProxy struct {
+	datastore.Datastore
+
+	probeInterval time.Duration
+
+	lock             sync.Mutex
+	degraded         bool
+	nextProbe        time.Time
+	lastHeadRevision datastore.Revision
+	listener         func(degraded bool)
+}
+
+// NewWriteDegradationProxy creates a proxy which detects when the delegate datastore rejects writes
+// because it is read-only. While writes are degraded:
+//
+//   - read-write transactions fail fast with a ReadOnlyTransactionError, except for one transaction
+//     per probe interval which is let through to detect when the datastore accepts writes again.
+//   - head and optimized revisions are pinned to the last head revision known before writes were
+//     degraded, so reads continue without requiring the datastore to compute new revisions.
+func NewWriteDegradationProxy(delegate datastore.Datastore, probeInterval time.Duration) WriteDegradingDatastore {
+	return &writeDegradationProxy{Datastore: delegate, probeInterval: probeInterval}
+}
+
+func (p *writeDegradationProxy) Unwrap() datastore.Datastore {
+	return p.Datastore
+}
+
+func (p *writeDegradationProxy) WritesDegraded() bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.degraded
+}
+
+func (p *writeDegradationProxy) SetDegradationListener(listener func(degraded bool)) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.listener = listener
+}
+
+func (p *writeDegradationProxy) ReadWriteTx(ctx context.Context, f datastore.TxUserFunc, opts ...options.RWTOptionsOption) (datastore.Revision, error) {
+	if !p.allowWrite() {
+		return datastore.NoRevision, errWritesDegraded
+	}
+
+	rev, err := p.Datastore.ReadWriteTx(ctx, f, opts...)
+	switch {
+	case err == nil:
+		p.recordHeadRevision(rev)
+		p.setDegraded(ctx, false)
+	case errors.As(err, &common.ReadOnlyTransactionError{}):
+		p.setDegraded(ctx, true)
+	}
+	return rev, err
+}
+
+func (p *writeDegradationProxy) HeadRevision(ctx context.Context) (datastore.Revision, error) {
+	if rev := p.pinnedRevision(); rev != nil {
+		return rev, nil
+	}
+
+	rev, err := p.Datastore.HeadRevision(ctx)
+	if err == nil {
+		p.recordHeadRevision(rev)
+	}
+	return rev, err
+}
+
+func (p *writeDegradationProxy) OptimizedRevision(ctx context.Context) (datastore.Revision, error) {
+	if rev := p.pinnedRevision(); rev != nil {
+		return rev, nil
+	}
+
+	rev, err := p.Datastore.OptimizedRevision(ctx)
+	if err == nil {
+		p.recordHeadRevision(rev)
+	}
+	return rev, err
+}
+
+// allowWrite returns whether a write may be attempted: always when writes are not degraded, and
+// once per probe interval otherwise.
+func (p *writeDegradationProxy) allowWrite() bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if !p.degraded {
+		return true
+	}
+
+	now := time.Now()
+	if now.Before(p.nextProbe) {
+		return false
+	}
+
+	p.nextProbe = now.Add(p.probeInterval)
+	return true
+}
+
+// pinnedRevision returns the revision at which reads are pinned while writes are degraded, or nil.
+func (p *writeDegradationProxy) pinnedRevision() datastore.Revision {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if !p.degraded {
+		return nil
+	}
+	return p.lastHeadRevision
+}
+
+func (p *writeDegradationProxy) recordHeadRevision(rev datastore.Revision) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.lastHeadRevision == nil || rev.GreaterThan(p.lastHeadRevision) {
+		p.lastHeadRevision = rev
+	}
+}
+
+func (p *writeDegradationProxy) setDegraded(ctx context.Context, degraded bool) {
+	p.lock.Lock()
+	if p.degraded == degraded {
+		p.lock.Unlock()
+		return
+	}
+
+	p.degraded = degraded
+	p.nextProbe = time.Now().Add(p.probeInterval)
+	listener := p.listener
+	lastHeadRevision := p.lastHeadRevision
+	p.lock.Unlock()
+
+	if degraded {
+		writesDegradedGauge.Set(1)
+		event := log.Ctx(ctx).Warn().Dur("probe-interval", p.probeInterval)
+		if lastHeadRevision != nil {
+			event = event.Stringer("pinned-revision", lastHeadRevision)
+		}
+		event.Msg("datastore is read-only; writes are degraded")
+	} else {
+		writesDegradedGauge.Set(0)
+		log.Ctx(ctx).Info().Msg("datastore accepts writes again; writes are no longer degraded")
+	}
+
+	if listener != nil {
+		listener(degraded)
+	}
+}
@@ -0,0 +1,103 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/common"
+	"github.com/authzed/spicedb/internal/datastore/dsfortesting"
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/options"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// switchableReadOnlyDatastore rejects writes while readOnly is set, as a failed over datastore would.
+type switchableReadOnlyDatastore struct {
+	datastore.Datastore
+
+	readOnly  atomic.Bool
+	txAttempt atomic.Int32
+}
+
+func (ds *switchableReadOnlyDatastore) ReadWriteTx(ctx context.Context, f datastore.TxUserFunc, opts ...options.RWTOptionsOption) (datastore.Revision, error) {
+	ds.txAttempt.Add(1)
+	if ds.readOnly.Load() {
+		return datastore.NoRevision, common.NewReadOnlyTransactionError(errors.New("cannot execute in a read-only transaction"))
+	}
+	return ds.Datastore.ReadWriteTx(ctx, f, opts...)
+}
+
+func TestWriteDegradation(t *testing.T) {
+	rawDS, err := dsfortesting.NewMemDBDatastoreForTesting(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+
+	delegate := &switchableReadOnlyDatastore{Datastore: rawDS}
+	ds := NewWriteDegradationProxy(delegate, 50*time.Millisecond)
+
+	var transitions []bool
+	ds.SetDegradationListener(func(degraded bool) { transitions = append(transitions, degraded) })
+
+	ctx := context.Background()
+	written, err := common.WriteRelationships(ctx, ds, tuple.UpdateOperationCreate, tuple.MustParse("document:foo#viewer@user:tom"))
+	require.NoError(t, err)
+	require.False(t, ds.WritesDegraded())
+
+	// The datastore becomes read-only.
+	delegate.readOnly.Store(true)
+	_, err = common.WriteRelationships(ctx, ds, tuple.UpdateOperationCreate, tuple.MustParse("document:foo#viewer@user:fred"))
+	require.ErrorAs(t, err, &common.ReadOnlyTransactionError{})
+	require.True(t, ds.WritesDegraded())
+	require.Equal(t, []bool{true}, transitions)
+
+	// Writes fail fast without reaching the datastore.
+	attempts := delegate.txAttempt.Load()
+	_, err = common.WriteRelationships(ctx, ds, tuple.UpdateOperationCreate, tuple.MustParse("document:foo#viewer@user:fred"))
+	require.ErrorAs(t, err, &common.ReadOnlyTransactionError{})
+	require.Equal(t, attempts, delegate.txAttempt.Load())
+
+	// Reads continue at the last known head revision.
+	head, err := ds.HeadRevision(ctx)
+	require.NoError(t, err)
+	require.True(t, head.Equal(written))
+
+	optimized, err := ds.OptimizedRevision(ctx)
+	require.NoError(t, err)
+	require.True(t, optimized.Equal(written))
+
+	it, err := ds.SnapshotReader(optimized).QueryRelationships(ctx, datastore.RelationshipsFilter{OptionalResourceType: "document"})
+	require.NoError(t, err)
+	rels, err := datastore.IteratorToSlice(it)
+	require.NoError(t, err)
+	require.Len(t, rels, 1)
+
+	// Once the datastore accepts writes again, the next probe recovers.
+	delegate.readOnly.Store(false)
+	require.Eventually(t, func() bool {
+		_, err := common.WriteRelationships(ctx, ds, tuple.UpdateOperationTouch, tuple.MustParse("document:foo#viewer@user:fred"))
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+	require.False(t, ds.WritesDegraded())
+	require.Equal(t, []bool{true, false}, transitions)
+
+	head, err = ds.HeadRevision(ctx)
+	require.NoError(t, err)
+	require.True(t, head.GreaterThan(written))
+}
+
+func TestWriteDegradationIgnoresOtherErrors(t *testing.T) {
+	rawDS, err := dsfortesting.NewMemDBDatastoreForTesting(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+
+	ds := NewWriteDegradationProxy(rawDS, time.Minute)
+	_, err = ds.ReadWriteTx(context.Background(), func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		return errors.New("some other error")
+	})
+	require.Error(t, err)
+	require.False(t, ds.WritesDegraded())
+}
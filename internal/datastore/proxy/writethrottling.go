@@ -0,0 +1,326 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/options"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+var throttledWritesCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "spicedb",
+	Subsystem: "datastore",
+	Name:      "throttled_relationship_writes_total",
+	Help:      "total number of relationship writes throttled by object type, either delayed or rejected",
+}, []string{"object_type", "result"})
+
+// WriteRateLimit is the maximum rate at which relationships of a resource object type can be
+// written, as a token bucket refilled at PerSecond tokens per second and holding up to Burst
+// tokens. Every relationship created, touched or deleted takes a token.
+type WriteRateLimit struct {
+	ObjectType string
+	PerSecond  float64
+	Burst      int
+}
+
+// ParseWriteRateLimit parses a write rate limit of the form `objecttype=rate[:burst]`, e.g.
+// `usergroup=100` or `usergroup=100:500`. The burst defaults to the rate, rounded up.
+func ParseWriteRateLimit(value string) (WriteRateLimit, error) {
+	objectType, limit, ok := strings.Cut(value, "=")
+	if !ok || objectType == "" {
+		return WriteRateLimit{}, fmt.Errorf("invalid write rate limit `%s`: must be of the form `objecttype=rate[:burst]`", value)
+	}
+
+	perSecondStr, burstStr, hasBurst := strings.Cut(limit, ":")
+	perSecond, err := strconv.ParseFloat(perSecondStr, 64)
+	if err != nil || perSecond <= 0 || math.IsInf(perSecond, 0) {
+		return WriteRateLimit{}, fmt.Errorf("invalid write rate limit `%s`: rate must be a positive number of writes per second", value)
+	}
+
+	burst := int(math.Ceil(perSecond))
+	if hasBurst {
+		burst, err = strconv.Atoi(burstStr)
+		if err != nil || burst <= 0 {
+			return WriteRateLimit{}, fmt.Errorf("invalid write rate limit `%s`: burst must be a positive number of writes", value)
+		}
+	}
+
+	return WriteRateLimit{ObjectType: objectType, PerSecond: perSecond, Burst: burst}, nil
+}
+
+// WriteThrottledError is returned when writing relationships would exceed the write rate limit
+// of their resource object type.
+type WriteThrottledError struct {
+	error
+	ObjectType string
+}
+
+// GRPCStatus implements retrieving the gRPC status for the error.
+func (err WriteThrottledError) GRPCStatus() *status.Status {
+	return status.New(codes.ResourceExhausted, err.Error())
+}
+
+func (err WriteThrottledError) Unwrap() error {
+	return err.error
+}
+
+// tokensNeededError aborts the transaction of a write through the proxy when the tokens of an
+// object type are not available, so that they are waited for before the transaction is retried
+// rather than while it is open.
+type tokensNeededError struct {
+	objectType string
+	count      int
+}
+
+func (err tokensNeededError) Error() string {
+	return fmt.Sprintf("waiting for %d write tokens of object type `%s`", err.count, err.objectType)
+}
+
+type writeThrottlingProxy struct {
+	datastore.Datastore
+
+	limiters map[string]*rate.Limiter
+	maxWait  time.Duration
+}
+
+// NewWriteThrottlingProxy creates a proxy which throttles the writes of relationships by the
+// object type of their resource, to protect hot resources and the consumers of their changes
+// from runaway writers such as import jobs.
+//
+// Writes beyond the rate limit of their object type are delayed for up to maxWait, waiting for
+// the token bucket to refill, and rejected with a WriteThrottledError if they would have to wait
+// longer. A transaction is never held open while waiting: it is aborted, the tokens are waited
+// for, and the transaction is run again with them reserved. Tokens reserved by a transaction
+// are kept across the retries of the transaction by the datastore. Bulk loads, which cannot be
+// run again, and transactions with retries disabled are rejected rather than delayed.
+// Relationships deleted by filter, whose number is only known once deleted, are not throttled.
+func NewWriteThrottlingProxy(delegate datastore.Datastore, limits []WriteRateLimit, maxWait time.Duration) (datastore.Datastore, error) {
+	limiters := make(map[string]*rate.Limiter, len(limits))
+	for _, limit := range limits {
+		if _, ok := limiters[limit.ObjectType]; ok {
+			return nil, fmt.Errorf("found duplicate write rate limit for object type `%s`", limit.ObjectType)
+		}
+		limiters[limit.ObjectType] = rate.NewLimiter(rate.Limit(limit.PerSecond), limit.Burst)
+	}
+
+	return &writeThrottlingProxy{Datastore: delegate, limiters: limiters, maxWait: maxWait}, nil
+}
+
+func (p *writeThrottlingProxy) Unwrap() datastore.Datastore {
+	return p.Datastore
+}
+
+func (p *writeThrottlingProxy) ReadWriteTx(ctx context.Context, f datastore.TxUserFunc, opts ...options.RWTOptionsOption) (datastore.Revision, error) {
+	write := &throttledWrite{
+		parent:   p,
+		canRetry: !options.NewRWTOptionsWithOptions(opts...).DisableRetries,
+		reserved: make(map[string]int),
+	}
+
+	for {
+		revision, err := p.Datastore.ReadWriteTx(ctx, func(ctx context.Context, tx datastore.ReadWriteTransaction) error {
+			return f(ctx, &writeThrottlingTx{ReadWriteTransaction: tx, write: write, written: make(map[string]int)})
+		}, opts...)
+
+		var needed tokensNeededError
+		if !errors.As(err, &needed) {
+			if err != nil {
+				write.cancel()
+			}
+			return revision, err
+		}
+
+		reservation, err := p.wait(ctx, needed.objectType, needed.count)
+		if err != nil {
+			write.cancel()
+			return datastore.NoRevision, err
+		}
+		write.add(needed.objectType, needed.count, reservation)
+	}
+}
+
+// wait reserves count tokens of the given object type and waits until they are available,
+// outside of any transaction.
+func (p *writeThrottlingProxy) wait(ctx context.Context, objectType string, count int) (*rate.Reservation, error) {
+	limiter := p.limiters[objectType]
+	reservation := limiter.ReserveN(time.Now(), count)
+	delay := reservation.Delay()
+	if delay > p.maxWait {
+		reservation.Cancel()
+		return nil, p.rejectRate(objectType, count, delay)
+	}
+
+	throttledWritesCounter.WithLabelValues(objectType, "delayed").Add(float64(count))
+	log.Ctx(ctx).Trace().Str("object_type", objectType).Int("count", count).Stringer("delay", delay).Msg("delaying throttled relationship writes")
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return reservation, nil
+	case <-ctx.Done():
+		reservation.Cancel()
+		return nil, ctx.Err()
+	}
+}
+
+func (p *writeThrottlingProxy) rejectBurst(objectType string, count int) error {
+	throttledWritesCounter.WithLabelValues(objectType, "rejected").Add(float64(count))
+	return WriteThrottledError{
+		fmt.Errorf("cannot write %d relationships of object type `%s` at once: the write rate limit allows at most %d", count, objectType, p.limiters[objectType].Burst()),
+		objectType,
+	}
+}
+
+func (p *writeThrottlingProxy) rejectRate(objectType string, count int, delay time.Duration) error {
+	throttledWritesCounter.WithLabelValues(objectType, "rejected").Add(float64(count))
+	return WriteThrottledError{
+		fmt.Errorf("write rate limit of %v relationships per second exceeded for object type `%s`; retry in %s", float64(p.limiters[objectType].Limit()), objectType, delay.Round(time.Millisecond)),
+		objectType,
+	}
+}
+
+// throttledWrite holds the tokens reserved by a ReadWriteTx call through the proxy, across the
+// attempts of its transaction.
+type throttledWrite struct {
+	parent *writeThrottlingProxy
+
+	// canRetry is whether the transaction may be aborted to wait for tokens and run again.
+	canRetry bool
+
+	lock         sync.Mutex
+	reserved     map[string]int
+	reservations []*rate.Reservation
+}
+
+func (w *throttledWrite) add(objectType string, count int, reservation *rate.Reservation) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	w.reserved[objectType] += count
+	w.reservations = append(w.reservations, reservation)
+}
+
+// cancel returns the tokens reserved by a write which failed.
+func (w *throttledWrite) cancel() {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	for _, reservation := range w.reservations {
+		reservation.Cancel()
+	}
+	w.reservations = nil
+}
+
+// reserve ensures, without waiting, that tokens are reserved for the given number of
+// relationships of the object type written by the current attempt of the transaction. If the
+// tokens are not available, it fails with a tokensNeededError if the transaction can be retried
+// once they are, and with a WriteThrottledError otherwise. Only the tokens not yet reserved must fit
+// in the burst, so that a transaction can write more relationships than the burst across calls,
+// waiting for tokens as they run out.
+func (w *throttledWrite) reserve(objectType string, written int, canRetry bool) error {
+	limiter, ok := w.parent.limiters[objectType]
+	if !ok {
+		return nil
+	}
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	needed := written - w.reserved[objectType]
+	if needed <= 0 {
+		return nil
+	}
+
+	if needed > limiter.Burst() {
+		return w.parent.rejectBurst(objectType, needed)
+	}
+
+	reservation := limiter.ReserveN(time.Now(), needed)
+	if delay := reservation.Delay(); delay > 0 {
+		reservation.Cancel()
+		if !canRetry {
+			return w.parent.rejectRate(objectType, needed, delay)
+		}
+		return tokensNeededError{objectType: objectType, count: needed}
+	}
+
+	w.reserved[objectType] += needed
+	w.reservations = append(w.reservations, reservation)
+	return nil
+}
+
+type writeThrottlingTx struct {
+	datastore.ReadWriteTransaction
+
+	write *throttledWrite
+
+	// written holds the number of relationships written by this attempt of the transaction, by
+	// object type.
+	written map[string]int
+}
+
+func (t *writeThrottlingTx) WriteRelationships(ctx context.Context, mutations []tuple.RelationshipUpdate) error {
+	counts := make(map[string]int)
+	for _, mutation := range mutations {
+		objectType := mutation.Relationship.Resource.ObjectType
+		if _, ok := t.write.parent.limiters[objectType]; ok {
+			counts[objectType]++
+		}
+	}
+
+	for objectType, count := range counts {
+		t.written[objectType] += count
+		if err := t.write.reserve(objectType, t.written[objectType], t.write.canRetry); err != nil {
+			return err
+		}
+	}
+
+	return t.ReadWriteTransaction.WriteRelationships(ctx, mutations)
+}
+
+func (t *writeThrottlingTx) BulkLoad(ctx context.Context, iter datastore.BulkWriteRelationshipSource) (uint64, error) {
+	return t.ReadWriteTransaction.BulkLoad(ctx, &throttledBulkLoadSource{iter, t})
+}
+
+// throttledBulkLoadSource throttles the relationships of a bulk load one at a time. As the source
+// of a bulk load cannot be read again, the bulk load is rejected once the tokens of an object type
+// run out.
+type throttledBulkLoadSource struct {
+	wrapped datastore.BulkWriteRelationshipSource
+	tx      *writeThrottlingTx
+}
+
+func (s *throttledBulkLoadSource) Next(ctx context.Context) (*tuple.Relationship, error) {
+	rel, err := s.wrapped.Next(ctx)
+	if err != nil || rel == nil {
+		return rel, err
+	}
+
+	objectType := rel.Resource.ObjectType
+	if _, ok := s.tx.write.parent.limiters[objectType]; !ok {
+		return rel, nil
+	}
+
+	s.tx.written[objectType]++
+	if err := s.tx.write.reserve(objectType, s.tx.written[objectType], false); err != nil {
+		return nil, err
+	}
+	return rel, nil
+}
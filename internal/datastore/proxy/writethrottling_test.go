@@ -0,0 +1,222 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/authzed/spicedb/internal/datastore/dsfortesting"
+	"github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/options"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+func newWriteThrottlingProxyForTesting(t *testing.T, maxWait time.Duration, limits ...WriteRateLimit) datastore.Datastore {
+	ds, err := dsfortesting.NewMemDBDatastoreForTesting(0, 5*time.Second, 1*time.Hour)
+	require.NoError(t, err)
+
+	pds, err := NewWriteThrottlingProxy(ds, limits, maxWait)
+	require.NoError(t, err)
+	return pds
+}
+
+func writeRelationshipsWithErrorForTesting(ds datastore.Datastore, rels ...string) error {
+	_, err := ds.ReadWriteTx(context.Background(), func(ctx context.Context, tx datastore.ReadWriteTransaction) error {
+		updates := make([]tuple.RelationshipUpdate, 0, len(rels))
+		for _, rel := range rels {
+			updates = append(updates, tuple.Touch(tuple.MustParse(rel)))
+		}
+		return tx.WriteRelationships(ctx, updates)
+	})
+	return err
+}
+
+func TestWriteThrottlingRejectsBeyondMaxWait(t *testing.T) {
+	pds := newWriteThrottlingProxyForTesting(t, 0, WriteRateLimit{ObjectType: "usergroup", PerSecond: 0.001, Burst: 2})
+
+	require.NoError(t, writeRelationshipsWithErrorForTesting(pds,
+		"usergroup:admins#member@user:tom",
+		"usergroup:admins#member@user:fred",
+	))
+
+	err := writeRelationshipsWithErrorForTesting(pds, "usergroup:admins#member@user:sarah")
+	require.ErrorAs(t, err, &WriteThrottledError{})
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+	require.ErrorContains(t, err, "write rate limit of 0.001 relationships per second exceeded for object type `usergroup`")
+
+	// Relationships of other object types are not throttled.
+	require.NoError(t, writeRelationshipsWithErrorForTesting(pds,
+		"document:firstdoc#viewer@user:tom",
+		"document:firstdoc#viewer@user:fred",
+		"document:firstdoc#viewer@user:sarah",
+	))
+}
+
+func TestWriteThrottlingRejectsBeyondBurst(t *testing.T) {
+	pds := newWriteThrottlingProxyForTesting(t, time.Hour, WriteRateLimit{ObjectType: "usergroup", PerSecond: 1000, Burst: 2})
+
+	err := writeRelationshipsWithErrorForTesting(pds,
+		"usergroup:admins#member@user:tom",
+		"usergroup:admins#member@user:fred",
+		"usergroup:admins#member@user:sarah",
+	)
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+	require.ErrorContains(t, err, "cannot write 3 relationships of object type `usergroup` at once: the write rate limit allows at most 2")
+}
+
+func TestWriteThrottlingAllowsTransactionsBeyondBurst(t *testing.T) {
+	pds := newWriteThrottlingProxyForTesting(t, 5*time.Second, WriteRateLimit{ObjectType: "usergroup", PerSecond: 20, Burst: 2})
+
+	attempts := 0
+	_, err := pds.ReadWriteTx(context.Background(), func(ctx context.Context, tx datastore.ReadWriteTransaction) error {
+		attempts++
+		for _, rels := range [][]string{
+			{"usergroup:admins#member@user:tom", "usergroup:admins#member@user:fred"},
+			{"usergroup:admins#member@user:sarah", "usergroup:admins#member@user:jill"},
+		} {
+			updates := make([]tuple.RelationshipUpdate, 0, len(rels))
+			for _, rel := range rels {
+				updates = append(updates, tuple.Touch(tuple.MustParse(rel)))
+			}
+			if err := tx.WriteRelationships(ctx, updates); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	// The second call needs tokens beyond the burst, which are waited for before running again.
+	require.Equal(t, 2, attempts)
+}
+
+func TestWriteThrottlingDelaysWithinMaxWait(t *testing.T) {
+	pds := newWriteThrottlingProxyForTesting(t, 5*time.Second, WriteRateLimit{ObjectType: "usergroup", PerSecond: 20, Burst: 1})
+
+	start := time.Now()
+	for _, rel := range []string{
+		"usergroup:admins#member@user:tom",
+		"usergroup:admins#member@user:fred",
+		"usergroup:admins#member@user:sarah",
+	} {
+		require.NoError(t, writeRelationshipsWithErrorForTesting(pds, rel))
+	}
+
+	// The second and third writes each wait for a token, refilled every 50ms.
+	require.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+}
+
+func TestWriteThrottlingWaitsOutsideTransactions(t *testing.T) {
+	pds := newWriteThrottlingProxyForTesting(t, 5*time.Second, WriteRateLimit{ObjectType: "usergroup", PerSecond: 20, Burst: 1})
+	require.NoError(t, writeRelationshipsWithErrorForTesting(pds, "usergroup:admins#member@user:tom"))
+
+	start := time.Now()
+	attempts := 0
+	var inTransaction time.Duration
+	_, err := pds.ReadWriteTx(context.Background(), func(ctx context.Context, tx datastore.ReadWriteTransaction) error {
+		attempts++
+		attemptStart := time.Now()
+		defer func() { inTransaction += time.Since(attemptStart) }()
+
+		return tx.WriteRelationships(ctx, []tuple.RelationshipUpdate{tuple.Touch(tuple.MustParse("usergroup:admins#member@user:fred"))})
+	})
+	require.NoError(t, err)
+
+	// The transaction is aborted, the token waited for, and the transaction run again.
+	require.Equal(t, 2, attempts)
+	require.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+	require.Less(t, inTransaction, 40*time.Millisecond)
+}
+
+// retryingDatastore runs each transaction twice, as datastores do on serialization failures.
+type retryingDatastore struct {
+	datastore.Datastore
+}
+
+func (ds retryingDatastore) ReadWriteTx(ctx context.Context, f datastore.TxUserFunc, opts ...options.RWTOptionsOption) (datastore.Revision, error) {
+	if _, err := ds.Datastore.ReadWriteTx(ctx, func(ctx context.Context, tx datastore.ReadWriteTransaction) error {
+		if err := f(ctx, tx); err != nil {
+			return err
+		}
+		return errors.New("serialization failure")
+	}, opts...); err.Error() != "serialization failure" {
+		return datastore.NoRevision, err
+	}
+	return ds.Datastore.ReadWriteTx(ctx, f, opts...)
+}
+
+func TestWriteThrottlingKeepsTokensAcrossRetries(t *testing.T) {
+	ds, err := dsfortesting.NewMemDBDatastoreForTesting(0, 5*time.Second, 1*time.Hour)
+	require.NoError(t, err)
+
+	pds, err := NewWriteThrottlingProxy(retryingDatastore{ds}, []WriteRateLimit{{ObjectType: "usergroup", PerSecond: 0.001, Burst: 2}}, 0)
+	require.NoError(t, err)
+
+	// The retry of the transaction uses the tokens reserved by its first attempt.
+	require.NoError(t, writeRelationshipsWithErrorForTesting(pds,
+		"usergroup:admins#member@user:tom",
+		"usergroup:admins#member@user:fred",
+	))
+}
+
+func TestWriteThrottlingRejectsBulkLoadsBeyondTokens(t *testing.T) {
+	pds := newWriteThrottlingProxyForTesting(t, 5*time.Second, WriteRateLimit{ObjectType: "usergroup", PerSecond: 1, Burst: 5})
+
+	_, err := pds.ReadWriteTx(context.Background(), func(ctx context.Context, tx datastore.ReadWriteTransaction) error {
+		_, err := tx.BulkLoad(ctx, testfixtures.NewBulkRelationshipGenerator("usergroup", "member", "user", 4, t))
+		return err
+	})
+	require.NoError(t, err)
+
+	// Bulk loads cannot be run again once the tokens are available, so they are rejected.
+	_, err = pds.ReadWriteTx(context.Background(), func(ctx context.Context, tx datastore.ReadWriteTransaction) error {
+		_, err := tx.BulkLoad(ctx, testfixtures.NewBulkRelationshipGenerator("usergroup", "manager", "user", 4, t))
+		return err
+	})
+	require.ErrorAs(t, err, &WriteThrottledError{})
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
+
+func TestWriteThrottlingDuplicateLimits(t *testing.T) {
+	ds, err := dsfortesting.NewMemDBDatastoreForTesting(0, 5*time.Second, 1*time.Hour)
+	require.NoError(t, err)
+
+	_, err = NewWriteThrottlingProxy(ds, []WriteRateLimit{
+		{ObjectType: "usergroup", PerSecond: 1, Burst: 1},
+		{ObjectType: "usergroup", PerSecond: 2, Burst: 2},
+	}, time.Second)
+	require.ErrorContains(t, err, "found duplicate write rate limit for object type `usergroup`")
+}
+
+func TestParseWriteRateLimit(t *testing.T) {
+	for _, tc := range []struct {
+		value         string
+		expected      WriteRateLimit
+		expectedError string
+	}{
+		{"usergroup=100", WriteRateLimit{ObjectType: "usergroup", PerSecond: 100, Burst: 100}, ""},
+		{"tenant/usergroup=100:500", WriteRateLimit{ObjectType: "tenant/usergroup", PerSecond: 100, Burst: 500}, ""},
+		{"usergroup=0.5", WriteRateLimit{ObjectType: "usergroup", PerSecond: 0.5, Burst: 1}, ""},
+		{"usergroup", WriteRateLimit{}, "must be of the form `objecttype=rate[:burst]`"},
+		{"=100", WriteRateLimit{}, "must be of the form `objecttype=rate[:burst]`"},
+		{"usergroup=fast", WriteRateLimit{}, "rate must be a positive number"},
+		{"usergroup=-1", WriteRateLimit{}, "rate must be a positive number"},
+		{"usergroup=100:0", WriteRateLimit{}, "burst must be a positive number"},
+	} {
+		t.Run(tc.value, func(t *testing.T) {
+			limit, err := ParseWriteRateLimit(tc.value)
+			if tc.expectedError != "" {
+				require.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, limit)
+		})
+	}
+}
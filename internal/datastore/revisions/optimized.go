@@ -5,9 +5,12 @@ import (
 	"fmt"
 	"math/rand"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/benbjohnson/clock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/sync/singleflight"
@@ -18,6 +21,23 @@ import (
 
 var tracer = otel.Tracer("spicedb/internal/datastore/common/revisions")
 
+var (
+	windowOffsetGauge = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "spicedb",
+		Subsystem: "datastore",
+		Name:      "optimized_revision_window_offset_seconds",
+		Help:      "offset by which the boundaries of the optimized revision windows of this node are shifted, chosen within the configured jitter",
+	})
+
+	effectiveWindowHistogram = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: "spicedb",
+		Subsystem: "datastore",
+		Name:      "optimized_revision_effective_window_seconds",
+		Help:      "duration for which computed optimized revisions are served, including the window offset of this node",
+		Buckets:   []float64{.001, .01, .1, .5, 1, 2.5, 5, 10, 30, 60},
+	})
+)
+
 // OptimizedRevisionFunction instructs the datastore to compute its own current
 // optimized revision given the specific quantization, and return for how long
 // it will remain valid.
@@ -25,10 +45,35 @@ type OptimizedRevisionFunction func(context.Context) (rev datastore.Revision, va
 
 // NewCachedOptimizedRevisions returns a CachedOptimizedRevisions for the given configuration
 func NewCachedOptimizedRevisions(maxRevisionStaleness time.Duration) *CachedOptimizedRevisions {
-	return &CachedOptimizedRevisions{
-		maxRevisionStaleness: maxRevisionStaleness,
-		clockFn:              clock.New(),
+	cor := &CachedOptimizedRevisions{
+		clockFn: clock.New(),
+	}
+	cor.maxRevisionStalenessNanos.Store(maxRevisionStaleness.Nanoseconds())
+	return cor
+}
+
+// SetMaxRevisionStaleness changes the maximum staleness of the revisions returned, such as when
+// the revision quantization is changed while the datastore is running.
+func (cor *CachedOptimizedRevisions) SetMaxRevisionStaleness(maxRevisionStaleness time.Duration) {
+	cor.maxRevisionStalenessNanos.Store(maxRevisionStaleness.Nanoseconds())
+}
+
+// SetWindowJitter shifts the boundaries of the quantization windows of the optimized revisions by
+// a random offset within the jitter, chosen once per instance, such that the revisions cached by
+// the nodes of a fleet do not all expire, and get recomputed from the datastore, at the same time.
+// Revisions are served for up to the offset past the end of their window, increasing their
+// maximum staleness by up to the jitter.
+func (cor *CachedOptimizedRevisions) SetWindowJitter(jitter time.Duration) {
+	var offset time.Duration
+	if jitter > 0 {
+		// nolint:gosec
+		// G404 use of non cryptographically secure random number generator is not a security concern here,
+		// as the offset only spreads the expiry of revisions across nodes.
+		offset = time.Duration(rand.Int63n(jitter.Nanoseconds()))
 	}
+
+	cor.windowOffsetNanos.Store(offset.Nanoseconds())
+	windowOffsetGauge.Set(offset.Seconds())
 }
 
 // SetOptimizedRevisionFunc must be called after construction, and is the method
@@ -42,13 +87,14 @@ func (cor *CachedOptimizedRevisions) OptimizedRevision(ctx context.Context) (dat
 	localNow := cor.clockFn.Now()
 
 	// Subtract a random amount of time from now, to let barely expired candidates get selected
+	maxRevisionStaleness := time.Duration(cor.maxRevisionStalenessNanos.Load())
 	adjustedNow := localNow
-	if cor.maxRevisionStaleness > 0 {
+	if maxRevisionStaleness > 0 {
 		// nolint:gosec
 		// G404 use of non cryptographically secure random number generator is not a security concern here,
 		// as we are using it to introduce randomness to the accepted staleness of a revision and reduce the odds of
 		// a thundering herd to the datastore
-		adjustedNow = localNow.Add(-1 * time.Duration(rand.Int63n(cor.maxRevisionStaleness.Nanoseconds())) * time.Nanosecond)
+		adjustedNow = localNow.Add(-1 * time.Duration(rand.Int63n(maxRevisionStaleness.Nanoseconds())) * time.Nanosecond)
 	}
 
 	cor.RLock()
@@ -71,13 +117,19 @@ func (cor *CachedOptimizedRevisions) OptimizedRevision(ctx context.Context) (dat
 			return nil, fmt.Errorf("unable to compute optimized revision: %w", err)
 		}
 
+		// Revisions which are not quantized are not served past their validity, as they would be
+		// served for the offset alone.
+		if validFor > 0 {
+			validFor += time.Duration(cor.windowOffsetNanos.Load())
+		}
+		effectiveWindowHistogram.Observe(validFor.Seconds())
 		rvt := localNow.Add(validFor)
 
 		// Prune the candidates that have definitely expired
 		cor.Lock()
 		var numToDrop uint
 		for _, candidate := range cor.candidates {
-			if candidate.validThrough.Add(cor.maxRevisionStaleness).Before(localNow) {
+			if candidate.validThrough.Add(maxRevisionStaleness).Before(localNow) {
 				numToDrop++
 			} else {
 				break
@@ -101,9 +153,10 @@ func (cor *CachedOptimizedRevisions) OptimizedRevision(ctx context.Context) (dat
 type CachedOptimizedRevisions struct {
 	sync.RWMutex
 
-	maxRevisionStaleness time.Duration
-	optimizedFunc        OptimizedRevisionFunction
-	clockFn              clock.Clock
+	maxRevisionStalenessNanos atomic.Int64
+	windowOffsetNanos         atomic.Int64
+	optimizedFunc             OptimizedRevisionFunction
+	clockFn                   clock.Clock
 
 	// these values are read and set by multiple consumers, they're protected
 	// by a mutex
@@ -147,6 +147,48 @@ func TestOptimizedRevisionCache(t *testing.T) {
 	}
 }
 
+func TestOptimizedRevisionWindowJitter(t *testing.T) {
+	require := require.New(t)
+
+	or := NewCachedOptimizedRevisions(0)
+	mockTime := clock.NewMock()
+	or.clockFn = mockTime
+	mock := trackingRevisionFunction{}
+	or.SetOptimizedRevisionFunc(mock.optimizedRevisionFunc)
+
+	or.SetWindowJitter(10 * time.Millisecond)
+	offset := time.Duration(or.windowOffsetNanos.Load())
+	require.GreaterOrEqual(offset, time.Duration(0))
+	require.Less(offset, 10*time.Millisecond)
+
+	mock.On("optimizedRevisionFunc").Return(one, 5*time.Millisecond, nil).Once()
+	mock.On("optimizedRevisionFunc").Return(two, time.Duration(0), nil).Once()
+	mock.On("optimizedRevisionFunc").Return(three, time.Duration(0), nil).Once()
+
+	ctx := context.Background()
+	revision, err := or.OptimizedRevision(ctx)
+	require.NoError(err)
+	require.Equal(one, revision)
+
+	// The quantized revision is served until the end of its window shifted by the offset.
+	mockTime.Add(5*time.Millisecond + offset - time.Nanosecond)
+	revision, err = or.OptimizedRevision(ctx)
+	require.NoError(err)
+	require.Equal(one, revision)
+
+	mockTime.Add(time.Nanosecond)
+	revision, err = or.OptimizedRevision(ctx)
+	require.NoError(err)
+	require.Equal(two, revision)
+
+	// Revisions which are not quantized are never served past their validity.
+	revision, err = or.OptimizedRevision(ctx)
+	require.NoError(err)
+	require.Equal(three, revision)
+
+	mock.AssertExpectations(t)
+}
+
 func TestOptimizedRevisionCacheSingleFlight(t *testing.T) {
 	require := require.New(t)
 
@@ -2,6 +2,7 @@ package revisions
 
 import (
 	"context"
+	"sync/atomic"
 	"time"
 
 	log "github.com/authzed/spicedb/internal/logging"
@@ -20,7 +21,7 @@ type RemoteClockRevisions struct {
 	gcWindowNanos          int64
 	nowFunc                RemoteNowFunction
 	followerReadDelayNanos int64
-	quantizationNanos      int64
+	quantizationNanos      atomic.Int64
 }
 
 // NewRemoteClockRevisions returns a RemoteClockRevisions for the given configuration
@@ -40,8 +41,8 @@ func NewRemoteClockRevisions(gcWindow, maxRevisionStaleness, followerReadDelay,
 		),
 		gcWindowNanos:          gcWindow.Nanoseconds(),
 		followerReadDelayNanos: followerReadDelay.Nanoseconds(),
-		quantizationNanos:      quantization.Nanoseconds(),
 	}
+	revisions.quantizationNanos.Store(quantization.Nanoseconds())
 
 	revisions.SetOptimizedRevisionFunc(revisions.optimizedRevisionFunc)
 
@@ -66,10 +67,10 @@ func (rcr *RemoteClockRevisions) optimizedRevisionFunc(ctx context.Context) (dat
 	delayedNow := nowTS.TimestampNanoSec() - rcr.followerReadDelayNanos
 	quantized := delayedNow
 	validForNanos := int64(0)
-	if rcr.quantizationNanos > 0 {
-		afterLastQuantization := delayedNow % rcr.quantizationNanos
+	if quantizationNanos := rcr.quantizationNanos.Load(); quantizationNanos > 0 {
+		afterLastQuantization := delayedNow % quantizationNanos
 		quantized -= afterLastQuantization
-		validForNanos = rcr.quantizationNanos - afterLastQuantization
+		validForNanos = quantizationNanos - afterLastQuantization
 	}
 	log.Ctx(ctx).Debug().
 		Time("quantized", time.Unix(0, quantized)).
@@ -80,6 +81,13 @@ func (rcr *RemoteClockRevisions) optimizedRevisionFunc(ctx context.Context) (dat
 	return nowTS.ConstructForTimestamp(quantized), time.Duration(validForNanos) * time.Nanosecond, nil
 }
 
+// SetQuantization changes the quantization of the optimized revisions and their maximum staleness.
+// Revisions already computed remain valid until their previous quantization window ends.
+func (rcr *RemoteClockRevisions) SetQuantization(quantization, maxRevisionStaleness time.Duration) {
+	rcr.quantizationNanos.Store(quantization.Nanoseconds())
+	rcr.SetMaxRevisionStaleness(min(maxRevisionStaleness, time.Duration(rcr.gcWindowNanos)-1))
+}
+
 // SetNowFunc sets the function used to determine the head revision
 func (rcr *RemoteClockRevisions) SetNowFunc(nowFunc RemoteNowFunction) {
 	rcr.nowFunc = nowFunc
@@ -182,3 +182,30 @@ func TestRemoteClockStalenessBeyondGC(t *testing.T) {
 	err = rcr.CheckRevision(context.Background(), newOptimized)
 	require.NoError(t, err)
 }
+
+func TestRemoteClockSetQuantization(t *testing.T) {
+	rcr := NewRemoteClockRevisions(1*time.Hour, 0, 0, 5*time.Second)
+
+	remoteClock := clock.NewMock()
+	rcr.clockFn = remoteClock
+	rcr.SetNowFunc(func(ctx context.Context) (datastore.Revision, error) {
+		return NewForTime(remoteClock.Now()), nil
+	})
+
+	remoteClock.Set(time.Unix(1234, 0))
+	optimized, err := rcr.OptimizedRevision(context.Background())
+	require.NoError(t, err)
+	require.True(t, NewForTimestamp(1230*1_000_000_000).Equal(optimized), "unexpected revision %s", optimized)
+
+	// The new quantization applies once the revision computed with the previous one expires.
+	rcr.SetQuantization(10*time.Second, 0)
+	remoteClock.Set(time.Unix(1236, 0))
+	optimized, err = rcr.OptimizedRevision(context.Background())
+	require.NoError(t, err)
+	require.True(t, NewForTimestamp(1230*1_000_000_000).Equal(optimized), "unexpected revision %s", optimized)
+
+	remoteClock.Set(time.Unix(1249, 0))
+	optimized, err = rcr.OptimizedRevision(context.Background())
+	require.NoError(t, err)
+	require.True(t, NewForTimestamp(1240*1_000_000_000).Equal(optimized), "unexpected revision %s", optimized)
+}
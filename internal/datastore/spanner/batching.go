@@ -0,0 +1,185 @@
+package spanner
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"cloud.google.com/go/spanner"
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/authzed/spicedb/internal/datastore/revisions"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/spiceerrors"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+const (
+	// spannerMaxMutationsPerCommit is the maximum number of mutations Spanner accepts in a
+	// single commit: https://cloud.google.com/spanner/quotas#limits-for
+	spannerMaxMutationsPerCommit = 80_000
+
+	// relationshipIndexColumns is the number of columns of the secondary indexes over the
+	// relationship table, each of which counts as a mutation when a row is written.
+	relationshipIndexColumns = 9
+
+	// relationshipIndexCount is the number of secondary indexes over the relationship table,
+	// each of which counts as a mutation when a row is deleted.
+	relationshipIndexCount = 2
+)
+
+// relationshipMutationCost returns the number of mutations Spanner counts against the limit
+// of a commit when applying a relationship operation.
+func relationshipMutationCost(operation tuple.UpdateOperation) int {
+	if operation == tuple.UpdateOperationDelete {
+		return 1 + relationshipIndexCount
+	}
+	return len(allRelationshipCols) + relationshipIndexColumns
+}
+
+// bufferWriter buffers mutations into a read-write transaction.
+type bufferWriter interface {
+	BufferWrite(ms []*spanner.Mutation) error
+}
+
+// mutationBatcher buffers the relationship mutations of a read-write transaction, keeping the
+// mutations of the transaction within the limit of a single commit.
+//
+// Without relaxed atomicity, exceeding the limit fails the transaction. With relaxed
+// atomicity, the mutations over the limit are split into batches, each within the limit, which
+// are applied in order in their own commits once the transaction has committed.
+type mutationBatcher struct {
+	maxMutationsPerCommit int
+	relaxedAtomicity      bool
+
+	buffered     int
+	overflow     [][]*spanner.Mutation
+	overflowCost int
+}
+
+func newMutationBatcher(maxMutationsPerCommit int, relaxedAtomicity bool) *mutationBatcher {
+	return &mutationBatcher{
+		maxMutationsPerCommit: maxMutationsPerCommit,
+		relaxedAtomicity:      relaxedAtomicity,
+	}
+}
+
+// buffer buffers the mutation into the transaction if it fits within the limit, and otherwise
+// into the overflow batches.
+func (mb *mutationBatcher) buffer(rwt bufferWriter, mutation *spanner.Mutation, cost int) error {
+	if len(mb.overflow) == 0 && mb.buffered+cost <= mb.maxMutationsPerCommit {
+		mb.buffered += cost
+		return rwt.BufferWrite([]*spanner.Mutation{mutation})
+	}
+
+	if !mb.relaxedAtomicity {
+		return NewMutationLimitExceededError(mb.maxMutationsPerCommit)
+	}
+
+	// Once a mutation has overflowed, all later mutations overflow as well, so that mutations
+	// are applied in the order they were buffered.
+	if len(mb.overflow) == 0 || mb.overflowCost+cost > mb.maxMutationsPerCommit {
+		mb.overflow = append(mb.overflow, nil)
+		mb.overflowCost = 0
+	}
+
+	last := len(mb.overflow) - 1
+	mb.overflow[last] = append(mb.overflow[last], mutation)
+	mb.overflowCost += cost
+	return nil
+}
+
+// applyOverflow applies the overflow batches in order, each in its own commit, after the
+// transaction committed at the given revision. It returns the revision of the last commit.
+func (mb *mutationBatcher) applyOverflow(ctx context.Context, client *spanner.Client, transactionTag string, committed datastore.Revision) (datastore.Revision, error) {
+	last := committed
+	for index, batch := range mb.overflow {
+		commitTs, err := client.Apply(ctx, batch, spanner.TransactionTag(transactionTag), spanner.Priority(requestPriority(ctx)))
+		if err != nil {
+			if cerr := convertToWriteConstraintError(err); cerr != nil {
+				err = cerr
+			}
+			return last, NewPartialWriteError(err, index+1, len(mb.overflow)+1, last)
+		}
+		last = revisions.NewForTime(commitTs)
+	}
+	return last, nil
+}
+
+// MutationLimitExceededError is returned when a read-write transaction buffers more mutations
+// than fit in a single Spanner commit, and relaxed atomicity is disabled.
+type MutationLimitExceededError struct {
+	error
+
+	// Limit is the maximum number of mutations of a commit.
+	Limit int
+}
+
+// GRPCStatus implements retrieving the gRPC status for the error.
+func (err MutationLimitExceededError) GRPCStatus() *status.Status {
+	return spiceerrors.WithCodeAndDetails(
+		err,
+		codes.InvalidArgument,
+		spiceerrors.ForReason(
+			v1.ErrorReason_ERROR_REASON_TOO_MANY_UPDATES_IN_REQUEST,
+			map[string]string{
+				"mutation_limit": strconv.Itoa(err.Limit),
+			},
+		),
+	)
+}
+
+// NewMutationLimitExceededError creates a new MutationLimitExceededError.
+func NewMutationLimitExceededError(limit int) error {
+	return MutationLimitExceededError{
+		fmt.Errorf("the transaction exceeds the limit of %d mutations of a single commit; split the write into smaller requests or enable relaxed write atomicity", limit),
+		limit,
+	}
+}
+
+// PartialWriteError is returned when a transaction split across several commits fails after
+// some of its commits were applied. The changes of the applied commits are not rolled back.
+type PartialWriteError struct {
+	error
+
+	// AppliedCommits is the number of commits of the transaction that were applied.
+	AppliedCommits int
+
+	// TotalCommits is the number of commits the transaction was split into.
+	TotalCommits int
+
+	// AppliedRevision is the revision of the last applied commit.
+	AppliedRevision datastore.Revision
+}
+
+// GRPCStatus implements retrieving the gRPC status for the error.
+func (err PartialWriteError) GRPCStatus() *status.Status {
+	return spiceerrors.WithCodeAndDetails(
+		err,
+		codes.Internal,
+		spiceerrors.ForReason(
+			v1.ErrorReason_ERROR_REASON_UNSPECIFIED,
+			map[string]string{
+				"applied_commits":  strconv.Itoa(err.AppliedCommits),
+				"total_commits":    strconv.Itoa(err.TotalCommits),
+				"applied_revision": err.AppliedRevision.String(),
+			},
+		),
+	)
+}
+
+func (err PartialWriteError) Unwrap() error {
+	return err.error
+}
+
+// NewPartialWriteError creates a new PartialWriteError.
+func NewPartialWriteError(err error, appliedCommits, totalCommits int, appliedRevision datastore.Revision) error {
+	return PartialWriteError{
+		fmt.Errorf("write partially applied: %d of %d commits applied, up to revision %s: %w", appliedCommits, totalCommits, appliedRevision, err),
+		appliedCommits,
+		totalCommits,
+		appliedRevision,
+	}
+}
@@ -0,0 +1,103 @@
+package spanner
+
+import (
+	"errors"
+	"testing"
+
+	"cloud.google.com/go/spanner"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/authzed/spicedb/internal/datastore/revisions"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+type recordingBufferWriter struct {
+	buffered []*spanner.Mutation
+}
+
+func (rbw *recordingBufferWriter) BufferWrite(ms []*spanner.Mutation) error {
+	rbw.buffered = append(rbw.buffered, ms...)
+	return nil
+}
+
+func testMutations(count int) []*spanner.Mutation {
+	mutations := make([]*spanner.Mutation, 0, count)
+	for index := 0; index < count; index++ {
+		mutations = append(mutations, spanner.Delete(tableRelationship, spanner.Key{index}))
+	}
+	return mutations
+}
+
+func TestMutationBatcherWithinLimit(t *testing.T) {
+	rwt := &recordingBufferWriter{}
+	batcher := newMutationBatcher(10, false)
+
+	mutations := testMutations(5)
+	for _, mutation := range mutations {
+		require.NoError(t, batcher.buffer(rwt, mutation, 2))
+	}
+
+	require.Equal(t, mutations, rwt.buffered)
+	require.Empty(t, batcher.overflow)
+}
+
+func TestMutationBatcherStrictAtomicity(t *testing.T) {
+	rwt := &recordingBufferWriter{}
+	batcher := newMutationBatcher(10, false)
+
+	mutations := testMutations(6)
+	for _, mutation := range mutations[:5] {
+		require.NoError(t, batcher.buffer(rwt, mutation, 2))
+	}
+
+	err := batcher.buffer(rwt, mutations[5], 2)
+	var limitErr MutationLimitExceededError
+	require.ErrorAs(t, err, &limitErr)
+	require.Equal(t, 10, limitErr.Limit)
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	require.Len(t, rwt.buffered, 5)
+}
+
+func TestMutationBatcherRelaxedAtomicity(t *testing.T) {
+	rwt := &recordingBufferWriter{}
+	batcher := newMutationBatcher(10, true)
+
+	mutations := testMutations(12)
+	for index, mutation := range mutations {
+		cost := 3
+		if index == 4 {
+			// A cheaper mutation that would fit in the transaction still follows the
+			// mutations that overflowed before it.
+			cost = 1
+		}
+		require.NoError(t, batcher.buffer(rwt, mutation, cost))
+	}
+
+	require.Equal(t, mutations[:3], rwt.buffered)
+	require.Equal(t, [][]*spanner.Mutation{
+		mutations[3:7],
+		mutations[7:10],
+		mutations[10:12],
+	}, batcher.overflow)
+}
+
+func TestRelationshipMutationCost(t *testing.T) {
+	require.Equal(t, 3, relationshipMutationCost(tuple.UpdateOperationDelete))
+	require.Equal(t, len(allRelationshipCols)+relationshipIndexColumns, relationshipMutationCost(tuple.UpdateOperationCreate))
+	require.Equal(t, len(allRelationshipCols)+relationshipIndexColumns, relationshipMutationCost(tuple.UpdateOperationTouch))
+}
+
+func TestPartialWriteError(t *testing.T) {
+	cause := errors.New("commit failed")
+	err := NewPartialWriteError(cause, 2, 5, revisions.NewForTimestamp(42))
+
+	var partialErr PartialWriteError
+	require.ErrorAs(t, err, &partialErr)
+	require.Equal(t, 2, partialErr.AppliedCommits)
+	require.Equal(t, 5, partialErr.TotalCommits)
+	require.ErrorIs(t, err, cause)
+	require.Equal(t, codes.Internal, status.Code(err))
+	require.Contains(t, err.Error(), "2 of 5 commits applied")
+}
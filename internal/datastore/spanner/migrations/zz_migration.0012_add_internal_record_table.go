@@ -0,0 +1,35 @@
+package migrations
+
+import (
+	"context"
+
+	"cloud.google.com/go/spanner/admin/database/apiv1/databasepb"
+)
+
+const (
+	// Spanner deletes the records once expired; records without an expiration never expire.
+	addInternalRecordTable = `CREATE TABLE internal_record (
+			record_key STRING(MAX) NOT NULL,
+			record_value BYTES(MAX) NOT NULL,
+			expires_at TIMESTAMP
+		) PRIMARY KEY (record_key),
+		ROW DELETION POLICY (OLDER_THAN(expires_at, INTERVAL 0 DAY))
+	`
+)
+
+func init() {
+	if err := SpannerMigrations.Register("add-internal-record-table", "add-expiration-support", func(ctx context.Context, w Wrapper) error {
+		updateOp, err := w.adminClient.UpdateDatabaseDdl(ctx, &databasepb.UpdateDatabaseDdlRequest{
+			Database: w.client.DatabaseName(),
+			Statements: []string{
+				addInternalRecordTable,
+			},
+		})
+		if err != nil {
+			return err
+		}
+		return updateOp.Wait(ctx)
+	}, nil); err != nil {
+		panic("failed to register migration: " + err.Error())
+	}
+}
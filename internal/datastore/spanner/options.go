@@ -1,34 +1,45 @@
 package spanner
 
 import (
+	"errors"
 	"fmt"
 	"math"
 	"runtime"
 	"time"
 
+	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
+
 	"github.com/authzed/spicedb/internal/datastore/common"
 	log "github.com/authzed/spicedb/internal/logging"
 )
 
 type spannerOptions struct {
-	watchBufferLength           uint16
-	watchBufferWriteTimeout     time.Duration
-	revisionQuantization        time.Duration
-	followerReadDelay           time.Duration
-	maxRevisionStalenessPercent float64
-	credentialsFilePath         string
-	credentialsJSON             []byte
-	emulatorHost                string
-	disableStats                bool
-	readMaxOpen                 int
-	writeMaxOpen                int
-	minSessions                 uint64
-	maxSessions                 uint64
-	migrationPhase              string
-	allowedMigrations           []string
-	filterMaximumIDCount        uint16
-	columnOptimizationOption    common.ColumnOptimizationOption
-	expirationDisabled          bool
+	watchBufferLength            uint16
+	watchBufferWriteTimeout      time.Duration
+	revisionQuantization         time.Duration
+	followerReadDelay            time.Duration
+	maxRevisionStalenessPercent  float64
+	revisionQuantizationJitter   time.Duration
+	credentialsFilePath          string
+	credentialsJSON              []byte
+	emulatorHost                 string
+	disableStats                 bool
+	readMaxOpen                  int
+	writeMaxOpen                 int
+	minSessions                  uint64
+	maxSessions                  uint64
+	migrationPhase               string
+	allowedMigrations            []string
+	filterMaximumIDCount         uint16
+	slowQueryThreshold           time.Duration
+	queryShapeCacheCapacity      int
+	columnOptimizationOption     common.ColumnOptimizationOption
+	expirationDisabled           bool
+	directedReadLocation         string
+	directedReadReplicaType      string
+	watchEmitTransactionMetadata bool
+	maxMutationsPerCommit        int
+	relaxedWriteAtomicity        bool
 }
 
 type migrationPhase uint8
@@ -41,6 +52,12 @@ var migrationPhases = map[string]migrationPhase{
 	"": complete,
 }
 
+var directedReadReplicaTypes = map[string]sppb.DirectedReadOptions_ReplicaSelection_Type{
+	"":           sppb.DirectedReadOptions_ReplicaSelection_TYPE_UNSPECIFIED,
+	"read-write": sppb.DirectedReadOptions_ReplicaSelection_READ_WRITE,
+	"read-only":  sppb.DirectedReadOptions_ReplicaSelection_READ_ONLY,
+}
+
 const (
 	errQuantizationTooLarge = "revision quantization (%s) must be less than (%s)"
 
@@ -52,8 +69,14 @@ const (
 	defaultDisableStats                = false
 	maxRevisionQuantization            = 24 * time.Hour
 	defaultFilterMaximumIDCount        = 100
+	defaultQueryShapeCacheCapacity     = 1024
 	defaultColumnOptimizationOption    = common.ColumnOptimizationOptionNone
 	defaultExpirationDisabled          = false
+
+	// defaultMaxMutationsPerCommit leaves room under the Spanner limit for the mutations of a
+	// transaction other than relationship writes, such as its metadata.
+	defaultMaxMutationsPerCommit = 75_000
+	defaultRelaxedWriteAtomicity = false
 )
 
 // Option provides the facility to configure how clients within the Spanner
@@ -77,8 +100,11 @@ func generateConfig(options []Option) (spannerOptions, error) {
 		maxSessions:                 400,
 		migrationPhase:              "", // no migration
 		filterMaximumIDCount:        defaultFilterMaximumIDCount,
+		queryShapeCacheCapacity:     defaultQueryShapeCacheCapacity,
 		columnOptimizationOption:    defaultColumnOptimizationOption,
 		expirationDisabled:          defaultExpirationDisabled,
+		maxMutationsPerCommit:       defaultMaxMutationsPerCommit,
+		relaxedWriteAtomicity:       defaultRelaxedWriteAtomicity,
 	}
 
 	for _, option := range options {
@@ -98,6 +124,18 @@ func generateConfig(options []Option) (spannerOptions, error) {
 		return computed, fmt.Errorf("unknown migration phase: %s", computed.migrationPhase)
 	}
 
+	if computed.maxMutationsPerCommit <= 0 || computed.maxMutationsPerCommit > spannerMaxMutationsPerCommit {
+		return computed, fmt.Errorf("max mutations per commit (%d) must be between 1 and %d", computed.maxMutationsPerCommit, spannerMaxMutationsPerCommit)
+	}
+
+	if _, ok := directedReadReplicaTypes[computed.directedReadReplicaType]; !ok {
+		return computed, fmt.Errorf("unknown directed read replica type: %s", computed.directedReadReplicaType)
+	}
+
+	if computed.directedReadReplicaType != "" && computed.directedReadLocation == "" {
+		return computed, errors.New("directed read replica type requires a directed read location")
+	}
+
 	if computed.filterMaximumIDCount == 0 {
 		computed.filterMaximumIDCount = 100
 		log.Warn().Msg("filterMaximumIDCount not set, defaulting to 100")
@@ -152,6 +190,18 @@ func MaxRevisionStalenessPercent(stalenessPercent float64) Option {
 	}
 }
 
+// RevisionQuantizationJitter is the maximum random offset by which the boundaries of the
+// revision quantization windows of this instance are shifted, such that the optimized revisions
+// cached by the nodes of a fleet do not all expire at the same time. It adds up to the jitter to
+// the staleness of optimized revisions.
+//
+// This value defaults to 0 (disabled).
+func RevisionQuantizationJitter(jitter time.Duration) Option {
+	return func(so *spannerOptions) {
+		so.revisionQuantizationJitter = jitter
+	}
+}
+
 // CredentialsFile is the path to a file containing credentials for a service
 // account that can access the cloud spanner instance
 func CredentialsFile(path string) Option {
@@ -232,6 +282,22 @@ func FilterMaximumIDCount(filterMaximumIDCount uint16) Option {
 	return func(po *spannerOptions) { po.filterMaximumIDCount = filterMaximumIDCount }
 }
 
+// SlowQueryThreshold is the duration over which relationship queries are logged as slow, along with
+// the shape of the statement, the API method which triggered them and the revision used.
+//
+// Disabled (zero) by default.
+func SlowQueryThreshold(threshold time.Duration) Option {
+	return func(po *spannerOptions) { po.slowQueryThreshold = threshold }
+}
+
+// QueryShapeCacheCapacity is the maximum number of query shapes whose generated SQL is cached, so
+// that relationship queries differing only in their values are not rebuilt. Zero disables the cache.
+//
+// This value defaults to 1024.
+func QueryShapeCacheCapacity(capacity int) Option {
+	return func(po *spannerOptions) { po.queryShapeCacheCapacity = capacity }
+}
+
 // WithColumnOptimization configures the Spanner driver to optimize the columns
 // in the underlying tables.
 func WithColumnOptimization(isEnabled bool) Option {
@@ -250,3 +316,77 @@ func WithExpirationDisabled(isDisabled bool) Option {
 		po.expirationDisabled = isDisabled
 	}
 }
+
+// WatchEmitTransactionMetadata configures Watch to include the Spanner
+// transaction tag and data change record sequence of the originating
+// transaction in the metadata of each emitted revision, allowing consumers to
+// correlate changes to the application operation that caused them.
+//
+// Disabled by default.
+func WatchEmitTransactionMetadata(enabled bool) Option {
+	return func(po *spannerOptions) { po.watchEmitTransactionMetadata = enabled }
+}
+
+// DirectedReadLocation configures the Spanner client to route all read-only
+// (snapshot) reads to replicas in the given location (e.g. "us-east1"). This
+// can be used to reduce cross-region latency when running against a
+// multi-region Spanner instance. Read-write transactions are unaffected and
+// continue to be routed to the leader.
+//
+// Disabled (empty) by default.
+func DirectedReadLocation(location string) Option {
+	return func(po *spannerOptions) { po.directedReadLocation = location }
+}
+
+// DirectedReadReplicaType restricts directed reads to replicas of the given
+// type within the directed read location. Valid values are "read-only",
+// "read-write" or empty for any replica type.
+//
+// Defaults to any replica type.
+func DirectedReadReplicaType(replicaType string) Option {
+	return func(po *spannerOptions) { po.directedReadReplicaType = replicaType }
+}
+
+// MaxMutationsPerCommit is the maximum number of mutations, as counted by Spanner, that the
+// relationship writes of a read-write transaction may buffer into a single commit. It cannot
+// exceed the limit of 80,000 mutations per commit of Spanner.
+//
+// This value defaults to 75,000.
+func MaxMutationsPerCommit(maxMutations int) Option {
+	return func(so *spannerOptions) { so.maxMutationsPerCommit = maxMutations }
+}
+
+// RelaxedWriteAtomicity configures read-write transactions whose relationship writes exceed
+// MaxMutationsPerCommit to be split into multiple commits, instead of failing. The first
+// commit holds all other changes of the transaction, followed by commits of the remaining
+// relationship writes applied in order.
+//
+// Split transactions are not atomic: their commits are visible at distinct revisions, and a
+// failure after the first commit leaves the earlier commits applied, which is reported by a
+// PartialWriteError carrying the revision of the last applied commit.
+//
+// Disabled by default.
+func RelaxedWriteAtomicity(enabled bool) Option {
+	return func(so *spannerOptions) { so.relaxedWriteAtomicity = enabled }
+}
+
+// directedReadOptions returns the Spanner directed read options for the
+// configuration, or nil if directed reads are not enabled.
+func (so spannerOptions) directedReadOptions() *sppb.DirectedReadOptions {
+	if so.directedReadLocation == "" {
+		return nil
+	}
+
+	return &sppb.DirectedReadOptions{
+		Replicas: &sppb.DirectedReadOptions_IncludeReplicas_{
+			IncludeReplicas: &sppb.DirectedReadOptions_IncludeReplicas{
+				ReplicaSelections: []*sppb.DirectedReadOptions_ReplicaSelection{
+					{
+						Location: so.directedReadLocation,
+						Type:     directedReadReplicaTypes[so.directedReadReplicaType],
+					},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,35 @@
+package spanner
+
+import (
+	"testing"
+
+	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDirectedReadOptions(t *testing.T) {
+	config, err := generateConfig(nil)
+	require.NoError(t, err)
+	require.Nil(t, config.directedReadOptions())
+
+	config, err = generateConfig([]Option{DirectedReadLocation("us-east1")})
+	require.NoError(t, err)
+	dro := config.directedReadOptions()
+	require.NotNil(t, dro)
+	selections := dro.GetIncludeReplicas().GetReplicaSelections()
+	require.Len(t, selections, 1)
+	require.Equal(t, "us-east1", selections[0].Location)
+	require.Equal(t, sppb.DirectedReadOptions_ReplicaSelection_TYPE_UNSPECIFIED, selections[0].Type)
+
+	config, err = generateConfig([]Option{DirectedReadLocation("us-east1"), DirectedReadReplicaType("read-only")})
+	require.NoError(t, err)
+	selections = config.directedReadOptions().GetIncludeReplicas().GetReplicaSelections()
+	require.Len(t, selections, 1)
+	require.Equal(t, sppb.DirectedReadOptions_ReplicaSelection_READ_ONLY, selections[0].Type)
+
+	_, err = generateConfig([]Option{DirectedReadLocation("us-east1"), DirectedReadReplicaType("witness")})
+	require.Error(t, err)
+
+	_, err = generateConfig([]Option{DirectedReadReplicaType("read-only")})
+	require.Error(t, err)
+}
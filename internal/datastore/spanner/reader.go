@@ -143,6 +143,23 @@ func (sr spannerReader) QueryRelationships(
 	return sr.executor.ExecuteQuery(ctx, qBuilder, opts...)
 }
 
+func (sr spannerReader) QueryRelationshipsBatch(
+	ctx context.Context,
+	filters []datastore.RelationshipsFilter,
+	opts ...options.QueryOptionsOption,
+) (datastore.TaggedRelationshipIterator, error) {
+	qBuilders := make([]common.SchemaQueryFilterer, 0, len(filters))
+	for _, filter := range filters {
+		qBuilder, err := common.NewSchemaQueryFiltererForRelationshipsSelect(sr.schema, sr.filterMaximumIDCount).FilterWithRelationshipsFilter(filter)
+		if err != nil {
+			return nil, err
+		}
+		qBuilders = append(qBuilders, qBuilder)
+	}
+
+	return sr.executor.ExecuteBatchQuery(ctx, qBuilders, opts...)
+}
+
 func (sr spannerReader) ReverseQueryRelationships(
 	ctx context.Context,
 	subjectsFilter datastore.SubjectsFilter,
@@ -173,14 +190,34 @@ func (sr spannerReader) ReverseQueryRelationships(
 var errStopIterator = fmt.Errorf("stop iteration")
 
 func queryExecutor(txSource txFactory) common.ExecuteReadRelsQueryFunc {
+	batchExecutor := batchQueryExecutor(txSource)
 	return func(ctx context.Context, builder common.RelationshipsQueryBuilder) (datastore.RelationshipIterator, error) {
+		tagged, err := batchExecutor(ctx, builder)
+		if err != nil {
+			return nil, err
+		}
+
 		return func(yield func(tuple.Relationship, error) bool) {
+			for t, err := range tagged {
+				if !yield(t.Relationship, err) {
+					return
+				}
+			}
+		}, nil
+	}
+}
+
+// batchQueryExecutor returns an executor of relationship queries, tagging each relationship with
+// the index of the query of the batch it matched, if the builder combines a batch.
+func batchQueryExecutor(txSource txFactory) common.ExecuteBatchReadRelsQueryFunc {
+	return func(ctx context.Context, builder common.RelationshipsQueryBuilder) (datastore.TaggedRelationshipIterator, error) {
+		return func(yield func(datastore.TaggedRelationship, error) bool) {
 			span := trace.SpanFromContext(ctx)
 			span.AddEvent("Query issued to database")
 
 			sql, args, err := builder.SelectSQL()
 			if err != nil {
-				yield(tuple.Relationship{}, err)
+				yield(datastore.TaggedRelationship{}, err)
 				return
 			}
 
@@ -207,6 +244,8 @@ func queryExecutor(txSource txFactory) common.ExecuteReadRelsQueryFunc {
 			var integrityKeyID string
 			var integrityHash []byte
 			var timestamp time.Time
+			var filterIndex int64
+			var writeRevision string
 
 			colsToSelect, err := common.ColumnsToSelect(builder,
 				&resourceObjectType,
@@ -221,11 +260,13 @@ func queryExecutor(txSource txFactory) common.ExecuteReadRelsQueryFunc {
 				&integrityKeyID,
 				&integrityHash,
 				&timestamp,
+				&writeRevision,
 			)
 			if err != nil {
-				yield(tuple.Relationship{}, err)
+				yield(datastore.TaggedRelationship{}, err)
 				return
 			}
+			colsToSelect = builder.WithFilterIndexColumn(colsToSelect, &filterIndex)
 
 			if err := iter.Do(func(row *spanner.Row) error {
 				err := row.Columns(colsToSelect...)
@@ -245,22 +286,23 @@ func queryExecutor(txSource txFactory) common.ExecuteReadRelsQueryFunc {
 					expiration = &expirationOrNull.Time
 				}
 
-				if !yield(tuple.Relationship{
+				if !yield(datastore.TaggedRelationship{FilterIndex: int(filterIndex), Relationship: tuple.Relationship{
 					RelationshipReference: tuple.RelationshipReference{
 						Resource: tuple.ObjectAndRelation{
-							ObjectType: resourceObjectType,
+							ObjectType: tuple.InternName(resourceObjectType),
 							ObjectID:   resourceObjectID,
-							Relation:   relation,
+							Relation:   tuple.InternName(relation),
 						},
 						Subject: tuple.ObjectAndRelation{
-							ObjectType: subjectObjectType,
+							ObjectType: tuple.InternName(subjectObjectType),
 							ObjectID:   subjectObjectID,
-							Relation:   subjectRelation,
+							Relation:   tuple.InternName(subjectRelation),
 						},
 					},
-					OptionalCaveat:     caveat,
-					OptionalExpiration: expiration,
-				}, nil) {
+					OptionalCaveat:        caveat,
+					OptionalExpiration:    expiration,
+					OptionalWriteRevision: writeRevision,
+				}}, nil) {
 					return errStopIterator
 				}
 
@@ -270,7 +312,7 @@ func queryExecutor(txSource txFactory) common.ExecuteReadRelsQueryFunc {
 					return
 				}
 
-				yield(tuple.Relationship{}, err)
+				yield(datastore.TaggedRelationship{}, err)
 				return
 			}
 		}, nil
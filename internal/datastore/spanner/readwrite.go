@@ -24,6 +24,7 @@ import (
 type spannerReadWriteTXN struct {
 	spannerReader
 	spannerRWT *spanner.ReadWriteTransaction
+	batcher    *mutationBatcher
 }
 
 const inLimit = 10_000 // https://cloud.google.com/spanner/quotas#query-limits
@@ -115,7 +116,7 @@ func (rwt spannerReadWriteTXN) WriteRelationships(ctx context.Context, mutations
 		}
 		rowCountChange += countChange
 
-		if err := rwt.spannerRWT.BufferWrite([]*spanner.Mutation{txnMut}); err != nil {
+		if err := rwt.batcher.buffer(rwt.spannerRWT, txnMut, relationshipMutationCost(mutation.Operation)); err != nil {
 			return fmt.Errorf(errUnableToWriteRelationships, err)
 		}
 	}
@@ -211,7 +212,7 @@ func deleteWithFilterAndLimit(ctx context.Context, rwt *spanner.ReadWriteTransac
 	mutations := make([]*spanner.Mutation, 0, delLimit)
 
 	// Load the relationships to be deleted.
-	iter := rwt.Query(ctx, statementFromSQL(sql, args))
+	iter := rwt.QueryWithOptions(ctx, statementFromSQL(sql, args), spanner.QueryOptions{Priority: requestPriority(ctx)})
 	defer iter.Stop()
 
 	if err := iter.Do(func(row *spanner.Row) error {
@@ -277,7 +278,7 @@ func deleteWithFilterAndNoLimit(ctx context.Context, rwt *spanner.ReadWriteTrans
 	}
 
 	deleteStatement := statementFromSQL(sql, args)
-	return rwt.Update(ctx, deleteStatement)
+	return rwt.UpdateWithOptions(ctx, deleteStatement, spanner.QueryOptions{Priority: requestPriority(ctx)})
 }
 
 type builder[T any] interface {
@@ -417,7 +418,7 @@ func (rwt spannerReadWriteTXN) BulkLoad(ctx context.Context, iter datastore.Bulk
 		}
 		numLoaded++
 
-		if err := rwt.spannerRWT.BufferWrite([]*spanner.Mutation{txnMut}); err != nil {
+		if err := rwt.batcher.buffer(rwt.spannerRWT, txnMut, relationshipMutationCost(tuple.UpdateOperationCreate)); err != nil {
 			return 0, fmt.Errorf(errUnableToBulkLoadRelationships, err)
 		}
 	}
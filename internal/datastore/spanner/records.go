@@ -0,0 +1,77 @@
+package spanner
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/spanner"
+
+	"github.com/authzed/spicedb/pkg/datastore"
+)
+
+var queryRecordsByPrefix = fmt.Sprintf(
+	"SELECT %[1]s, %[2]s, %[3]s FROM %[4]s WHERE STARTS_WITH(%[1]s, @prefix) ORDER BY %[1]s",
+	colRecordKey,
+	colRecordValue,
+	colRecordExpiresAt,
+	tableInternalRecord,
+)
+
+// ReadRecords reads the records as of the start of the transaction: records written or deleted
+// earlier in the transaction are buffered by Spanner until it commits.
+func (rwt spannerReadWriteTXN) ReadRecords(ctx context.Context, keyPrefix string) ([]datastore.Record, error) {
+	iter := rwt.spannerRWT.Query(ctx, spanner.Statement{
+		SQL:    queryRecordsByPrefix,
+		Params: map[string]any{"prefix": keyPrefix},
+	})
+	defer iter.Stop()
+
+	var records []datastore.Record
+	if err := iter.Do(func(row *spanner.Row) error {
+		var record datastore.Record
+		var expiresAt *time.Time
+		if err := row.Columns(&record.Key, &record.Value, &expiresAt); err != nil {
+			return err
+		}
+		if expiresAt != nil {
+			record.ExpiresAt = *expiresAt
+		}
+		records = append(records, record)
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("failed to read records: %w", err)
+	}
+	return records, nil
+}
+
+func (rwt spannerReadWriteTXN) WriteRecords(_ context.Context, records ...datastore.Record) error {
+	mutations := make([]*spanner.Mutation, 0, len(records))
+	for _, record := range records {
+		var expiresAt *time.Time
+		if !record.ExpiresAt.IsZero() {
+			expiresAt = &record.ExpiresAt
+		}
+		mutations = append(mutations, spanner.InsertOrUpdate(tableInternalRecord,
+			[]string{colRecordKey, colRecordValue, colRecordExpiresAt},
+			[]any{record.Key, record.Value, expiresAt},
+		))
+	}
+
+	if err := rwt.spannerRWT.BufferWrite(mutations); err != nil {
+		return fmt.Errorf("failed to write records: %w", err)
+	}
+	return nil
+}
+
+func (rwt spannerReadWriteTXN) DeleteRecords(_ context.Context, keys ...string) error {
+	mutations := make([]*spanner.Mutation, 0, len(keys))
+	for _, key := range keys {
+		mutations = append(mutations, spanner.Delete(tableInternalRecord, spanner.Key{key}))
+	}
+
+	if err := rwt.spannerRWT.BufferWrite(mutations); err != nil {
+		return fmt.Errorf("failed to delete records: %w", err)
+	}
+	return nil
+}
@@ -26,6 +26,16 @@ func (sd *spannerDatastore) HeadRevision(ctx context.Context) (datastore.Revisio
 	return revisions.NewForTime(timestamp), nil
 }
 
+func (sd *spannerDatastore) SetRevisionQuantization(quantization time.Duration) error {
+	if quantization >= maxRevisionQuantization {
+		return fmt.Errorf(errQuantizationTooLarge, quantization, maxRevisionQuantization)
+	}
+
+	maxRevisionStaleness := time.Duration(float64(quantization.Nanoseconds())*sd.config.maxRevisionStalenessPercent) * time.Nanosecond
+	sd.SetQuantization(quantization, maxRevisionStaleness)
+	return nil
+}
+
 func (sd *spannerDatastore) staleHeadRevision(ctx context.Context) (datastore.Revision, error) {
 	var timestamp time.Time
 	if err := sd.client.Single().WithTimestampBound(spanner.ExactStaleness(sd.config.followerReadDelay)).Query(ctx, nowStmt).Do(func(r *spanner.Row) error {
@@ -35,3 +45,7 @@ func (sd *spannerDatastore) staleHeadRevision(ctx context.Context) (datastore.Re
 	}
 	return revisions.NewForTime(timestamp), nil
 }
+
+func (sd *spannerDatastore) RevisionAtTime(_ context.Context, at time.Time) (datastore.Revision, error) {
+	return revisions.NewForTime(at), nil
+}
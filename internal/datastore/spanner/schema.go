@@ -35,6 +35,11 @@ const (
 	tableTransactionMetadata = "transaction_metadata"
 	colTransactionTag        = "transaction_tag"
 	colMetadata              = "metadata"
+
+	tableInternalRecord = "internal_record"
+	colRecordKey        = "record_key"
+	colRecordValue      = "record_value"
+	colRecordExpiresAt  = "expires_at"
 )
 
 var allRelationshipCols = []string{
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"cloud.google.com/go/spanner"
+	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
 	ocprom "contrib.go.opencensus.io/exporter/prometheus"
 	sq "github.com/Masterminds/squirrel"
 	"github.com/google/uuid"
@@ -32,6 +33,7 @@ import (
 	log "github.com/authzed/spicedb/internal/logging"
 	"github.com/authzed/spicedb/pkg/datastore"
 	"github.com/authzed/spicedb/pkg/datastore/options"
+	"github.com/authzed/spicedb/pkg/middleware/priority"
 	"github.com/authzed/spicedb/pkg/tuple"
 )
 
@@ -97,6 +99,8 @@ type spannerDatastore struct {
 
 	tableSizesStatsTable string
 	filterMaximumIDCount uint16
+	slowQueryLogger      *common.SlowQueryLogger
+	shapeCache           *common.QueryShapeCache
 }
 
 // NewSpannerDatastore returns a datastore backed by cloud spanner
@@ -121,6 +125,13 @@ func NewSpannerDatastore(ctx context.Context, database string, opts ...Option) (
 		log.Info().Str("spanner-emulator-host", os.Getenv("SPANNER_EMULATOR_HOST")).Msg("running against spanner emulator")
 	}
 
+	if config.directedReadLocation != "" {
+		log.Info().
+			Str("location", config.directedReadLocation).
+			Str("replica-type", config.directedReadReplicaType).
+			Msg("spanner configured to direct snapshot reads to replicas")
+	}
+
 	// TODO(jschorr): Replace with OpenTelemetry instrumentation once available.
 	err = spanner.EnableStatViews() // nolint: staticcheck
 	if err != nil {
@@ -166,7 +177,10 @@ func NewSpannerDatastore(ctx context.Context, database string, opts ...Option) (
 	client, err := spanner.NewClientWithConfig(
 		context.Background(),
 		database,
-		spanner.ClientConfig{SessionPoolConfig: cfg},
+		spanner.ClientConfig{
+			SessionPoolConfig:   cfg,
+			DirectedReadOptions: config.directedReadOptions(),
+		},
 		spannerOpts...,
 	)
 	if err != nil {
@@ -192,9 +206,11 @@ func NewSpannerDatastore(ctx context.Context, database string, opts ...Option) (
 		common.WithColCaveatName(colCaveatName),
 		common.WithColCaveatContext(colCaveatContext),
 		common.WithColExpiration(colExpiration),
+		common.WithColWriteRevision("CAST("+colTimestamp+" AS STRING)"),
 		common.WithPaginationFilterType(common.ExpandedLogicComparison),
 		common.WithPlaceholderFormat(sq.AtP),
 		common.WithNowFunction("CURRENT_TIMESTAMP"),
+		common.WithEmptyCaveatContextCondition("(%[1]s IS NULL OR TO_JSON_STRING(%[1]s) = '{}')"),
 		common.WithColumnOptimization(config.columnOptimizationOption),
 	)
 
@@ -218,6 +234,8 @@ func NewSpannerDatastore(ctx context.Context, database string, opts ...Option) (
 		cachedEstimatedBytesPerRelationshipLock: sync.RWMutex{},
 		tableSizesStatsTable:                    tableSizesStatsTable,
 		filterMaximumIDCount:                    config.filterMaximumIDCount,
+		slowQueryLogger:                         common.NewSlowQueryLogger(config.slowQueryThreshold),
+		shapeCache:                              common.NewQueryShapeCache(Engine, config.queryShapeCacheCapacity),
 		schema:                                  *schema,
 	}
 	// Optimized revision and revision checking use a stale read for the
@@ -225,12 +243,22 @@ func NewSpannerDatastore(ctx context.Context, database string, opts ...Option) (
 	// TODO: Still investigating whether a stale read can be used for
 	//       HeadRevision for FullConsistency queries.
 	ds.RemoteClockRevisions.SetNowFunc(ds.staleHeadRevision)
+	ds.RemoteClockRevisions.SetWindowJitter(config.revisionQuantizationJitter)
 
 	return ds, nil
 }
 
+// readTXWithOptions is the subsection of the Spanner shared read transaction interface
+// accepting the options of each read, to which the reads of a traceableRTX are delegated.
+type readTXWithOptions interface {
+	ReadRowWithOptions(ctx context.Context, table string, key spanner.Key, columns []string, opts *spanner.ReadOptions) (*spanner.Row, error)
+	ReadWithOptions(ctx context.Context, table string, keys spanner.KeySet, columns []string, opts *spanner.ReadOptions) *spanner.RowIterator
+	QueryWithOptions(ctx context.Context, statement spanner.Statement, opts spanner.QueryOptions) *spanner.RowIterator
+}
+
+// traceableRTX traces the reads of a transaction, which it runs at the priority of the request.
 type traceableRTX struct {
-	delegate readTX
+	delegate readTXWithOptions
 }
 
 func (t *traceableRTX) ReadRow(ctx context.Context, table string, key spanner.Key, columns []string) (*spanner.Row, error) {
@@ -240,7 +268,7 @@ func (t *traceableRTX) ReadRow(ctx context.Context, table string, key spanner.Ke
 		attribute.String("key", key.String()),
 		attribute.StringSlice("columns", columns))
 
-	return t.delegate.ReadRow(ctx, table, key, columns)
+	return t.delegate.ReadRowWithOptions(ctx, table, key, columns, &spanner.ReadOptions{Priority: requestPriority(ctx)})
 }
 
 func (t *traceableRTX) Read(ctx context.Context, table string, keys spanner.KeySet, columns []string) *spanner.RowIterator {
@@ -249,7 +277,7 @@ func (t *traceableRTX) Read(ctx context.Context, table string, keys spanner.KeyS
 		attribute.String("table", table),
 		attribute.StringSlice("columns", columns))
 
-	return t.delegate.Read(ctx, table, keys, columns)
+	return t.delegate.ReadWithOptions(ctx, table, keys, columns, &spanner.ReadOptions{Priority: requestPriority(ctx)})
 }
 
 func (t *traceableRTX) Query(ctx context.Context, statement spanner.Statement) *spanner.RowIterator {
@@ -257,7 +285,20 @@ func (t *traceableRTX) Query(ctx context.Context, statement spanner.Statement) *
 		attribute.String("spannerAPI", "ReadOnlyTransaction.Query"),
 		attribute.String("statement", statement.SQL))
 
-	return t.delegate.Query(ctx, statement)
+	return t.delegate.QueryWithOptions(ctx, statement, spanner.QueryOptions{Priority: requestPriority(ctx)})
+}
+
+// requestPriority returns the Spanner priority of the requests made for the request of the
+// context, from its priority class.
+func requestPriority(ctx context.Context) sppb.RequestOptions_Priority {
+	switch priority.FromContext(ctx) {
+	case priority.Batch:
+		return sppb.RequestOptions_PRIORITY_MEDIUM
+	case priority.Background:
+		return sppb.RequestOptions_PRIORITY_LOW
+	default:
+		return sppb.RequestOptions_PRIORITY_HIGH
+	}
 }
 
 func (sd *spannerDatastore) SnapshotReader(revisionRaw datastore.Revision) datastore.Reader {
@@ -266,7 +307,13 @@ func (sd *spannerDatastore) SnapshotReader(revisionRaw datastore.Revision) datas
 	txSource := func() readTX {
 		return &traceableRTX{delegate: sd.client.Single().WithTimestampBound(spanner.ReadTimestamp(r.Time()))}
 	}
-	executor := common.QueryRelationshipsExecutor{Executor: queryExecutor(txSource)}
+	executor := common.QueryRelationshipsExecutor{
+		Executor:        queryExecutor(txSource),
+		BatchExecutor:   batchQueryExecutor(txSource),
+		SlowQueryLogger: sd.slowQueryLogger,
+		ShapeCache:      sd.shapeCache,
+		Revision:        r,
+	}
 	return spannerReader{executor, txSource, sd.filterMaximumIDCount, sd.schema}
 }
 
@@ -297,7 +344,10 @@ func (sd *spannerDatastore) ReadWriteTx(ctx context.Context, fn datastore.TxUser
 	transactionTag := "sdb-rwt-" + uuid.NewString()
 
 	ctx, cancel := context.WithCancel(ctx)
+	var batcher *mutationBatcher
 	rs, err := sd.client.ReadWriteTransactionWithOptions(ctx, func(ctx context.Context, spannerRWT *spanner.ReadWriteTransaction) error {
+		// The batcher is recreated on each attempt, as a retried attempt rebuffers its mutations.
+		batcher = newMutationBatcher(sd.config.maxMutationsPerCommit, sd.config.relaxedWriteAtomicity)
 		txSource := func() readTX {
 			return &traceableRTX{delegate: spannerRWT}
 		}
@@ -314,10 +364,16 @@ func (sd *spannerDatastore) ReadWriteTx(ctx context.Context, fn datastore.TxUser
 			}
 		}
 
-		executor := common.QueryRelationshipsExecutor{Executor: queryExecutor(txSource)}
+		executor := common.QueryRelationshipsExecutor{
+			Executor:        queryExecutor(txSource),
+			BatchExecutor:   batchQueryExecutor(txSource),
+			SlowQueryLogger: sd.slowQueryLogger,
+			ShapeCache:      sd.shapeCache,
+		}
 		rwt := spannerReadWriteTXN{
 			spannerReader{executor, txSource, sd.filterMaximumIDCount, sd.schema},
 			spannerRWT,
+			batcher,
 		}
 		err := func() error {
 			innerCtx, innerSpan := tracer.Start(ctx, "TxUserFunc")
@@ -333,7 +389,7 @@ func (sd *spannerDatastore) ReadWriteTx(ctx context.Context, fn datastore.TxUser
 		}
 
 		return nil
-	}, spanner.TransactionOptions{TransactionTag: transactionTag})
+	}, spanner.TransactionOptions{TransactionTag: transactionTag, CommitPriority: requestPriority(ctx)})
 	if err != nil {
 		if cerr := convertToWriteConstraintError(err); cerr != nil {
 			return datastore.NoRevision, cerr
@@ -341,7 +397,7 @@ func (sd *spannerDatastore) ReadWriteTx(ctx context.Context, fn datastore.TxUser
 		return datastore.NoRevision, err
 	}
 
-	return revisions.NewForTime(rs.CommitTs), nil
+	return batcher.applyOverflow(ctx, sd.client, transactionTag, revisions.NewForTime(rs.CommitTs))
 }
 
 func (sd *spannerDatastore) ReadyState(ctx context.Context) (datastore.ReadyState, error) {
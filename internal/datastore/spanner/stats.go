@@ -11,8 +11,8 @@ import (
 	"github.com/ccoveille/go-safecast"
 
 	"github.com/authzed/spicedb/pkg/datastore"
-	core "github.com/authzed/spicedb/pkg/proto/core/v1"
 	"github.com/authzed/spicedb/pkg/spiceerrors"
+	"github.com/authzed/spicedb/pkg/tuple"
 )
 
 var querySomeRandomRelationships = fmt.Sprintf(`SELECT %s FROM %s LIMIT 10`,
@@ -68,10 +68,9 @@ func (sd *spannerDatastore) Statistics(ctx context.Context) (datastore.Stats, er
 		totalRelationships := 0
 
 		if err := riter.Do(func(row *spanner.Row) error {
-			nextTuple := &core.RelationTuple{
-				ResourceAndRelation: &core.ObjectAndRelation{},
-				Subject:             &core.ObjectAndRelation{},
-			}
+			nextTuple := tuple.AcquireCoreTuple(tuple.Relationship{})
+			defer tuple.ReleaseCoreTuple(nextTuple)
+
 			err := row.Columns(
 				&nextTuple.ResourceAndRelation.Namespace,
 				&nextTuple.ResourceAndRelation.ObjectId,
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
 	"regexp"
 	"time"
 
@@ -25,6 +26,16 @@ import (
 
 const (
 	CombinedChangeStreamName = "combined_change_stream"
+
+	// TransactionTagMetadataKey is the key in the revision metadata emitted by Watch under which
+	// the Spanner transaction tag of the originating transaction is placed, when enabled via
+	// WatchEmitTransactionMetadata.
+	TransactionTagMetadataKey = "spanner_transaction_tag"
+
+	// RecordSequenceMetadataKey is the key in the revision metadata emitted by Watch under which
+	// the record sequence of the originating data change record is placed, when enabled via
+	// WatchEmitTransactionMetadata.
+	RecordSequenceMetadataKey = "spanner_record_sequence"
 )
 
 var retryHistogram = prometheus.NewHistogram(prometheus.HistogramOpts{
@@ -136,6 +147,18 @@ func (sd *spannerDatastore) watch(
 		return
 	}
 
+	if opts.IncludeSchemaSnapshot && opts.Content&datastore.WatchSchema == datastore.WatchSchema {
+		snapshot, err := common.SchemaSnapshotChanges(ctx, sd.SnapshotReader(afterRevision), afterRevision)
+		if err != nil {
+			sendError(err)
+			return
+		}
+
+		if snapshot != nil && !sendChange(snapshot) {
+			return
+		}
+	}
+
 	reader, err := changestreams.NewReaderWithConfig(
 		ctx,
 		project,
@@ -165,19 +188,24 @@ func (sd *spannerDatastore) watch(
 
 	metadataForTransactionTag := map[string]map[string]any{}
 
-	addMetadataForTransactionTag := func(ctx context.Context, tracked *common.Changes[revisions.TimestampRevision, int64], revision revisions.TimestampRevision, transactionTag string) error {
-		if metadata, ok := metadataForTransactionTag[transactionTag]; ok {
-			return tracked.SetRevisionMetadata(ctx, revision, metadata)
+	addMetadataForChangeRecord := func(ctx context.Context, tracked *common.Changes[revisions.TimestampRevision, int64], revision revisions.TimestampRevision, dcr *changestreams.DataChangeRecord) error {
+		transactionMetadata, ok := metadataForTransactionTag[dcr.TransactionTag]
+		if !ok {
+			// Load the metadata from the transactions metadata table.
+			loaded, err := sd.readTransactionMetadata(ctx, dcr.TransactionTag)
+			if err != nil {
+				return err
+			}
+
+			metadataForTransactionTag[dcr.TransactionTag] = loaded
+			transactionMetadata = loaded
 		}
 
-		// Otherwise, load the metadata from the transactions metadata table.
-		transactionMetadata, err := sd.readTransactionMetadata(ctx, transactionTag)
-		if err != nil {
-			return err
+		if !sd.config.watchEmitTransactionMetadata {
+			return tracked.SetRevisionMetadata(ctx, revision, transactionMetadata)
 		}
 
-		metadataForTransactionTag[transactionTag] = transactionMetadata
-		return tracked.SetRevisionMetadata(ctx, revision, transactionMetadata)
+		return tracked.SetRevisionMetadata(ctx, revision, withChangeRecordMetadata(transactionMetadata, dcr))
 	}
 
 	err = reader.Read(ctx, func(result *changestreams.ReadResult) error {
@@ -185,6 +213,10 @@ func (sd *spannerDatastore) watch(
 		for _, record := range result.ChangeRecords {
 			tracked := common.NewChanges(revisions.TimestampIDKeyFunc, opts.Content, opts.MaximumBufferedChangesByteSize)
 
+			// A single transaction can produce multiple data change records (one per table and
+			// partition), so only the first is used to set the metadata for its revision.
+			revisionsWithMetadata := map[int64]struct{}{}
+
 			for _, dcr := range record.DataChangeRecords {
 				changeRevision := revisions.NewForTime(dcr.CommitTimestamp)
 				modType := dcr.ModType // options are INSERT, UPDATE, DELETE
@@ -201,10 +233,12 @@ func (sd *spannerDatastore) watch(
 					continue
 				}
 
-				if len(dcr.TransactionTag) > 0 {
-					if err := addMetadataForTransactionTag(ctx, tracked, changeRevision, dcr.TransactionTag); err != nil {
+				revisionKey := revisions.TimestampIDKeyFunc(changeRevision)
+				if _, ok := revisionsWithMetadata[revisionKey]; !ok && len(dcr.TransactionTag) > 0 {
+					if err := addMetadataForChangeRecord(ctx, tracked, changeRevision, dcr); err != nil {
 						return err
 					}
+					revisionsWithMetadata[revisionKey] = struct{}{}
 				}
 
 				for _, mod := range dcr.Mods {
@@ -386,6 +420,16 @@ func (sd *spannerDatastore) watch(
 	}
 }
 
+// withChangeRecordMetadata returns a copy of the given transaction metadata with the transaction
+// tag and record sequence of the data change record added.
+func withChangeRecordMetadata(transactionMetadata map[string]any, dcr *changestreams.DataChangeRecord) map[string]any {
+	metadata := make(map[string]any, len(transactionMetadata)+2)
+	maps.Copy(metadata, transactionMetadata)
+	metadata[TransactionTagMetadataKey] = dcr.TransactionTag
+	metadata[RecordSequenceMetadataKey] = dcr.RecordSequence
+	return metadata
+}
+
 type unmarshallable interface {
 	UnmarshalVT([]byte) error
 }
@@ -0,0 +1,26 @@
+package spanner
+
+import (
+	"testing"
+
+	"github.com/cloudspannerecosystem/spanner-change-streams-tail/changestreams"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithChangeRecordMetadata(t *testing.T) {
+	transactionMetadata := map[string]any{"foo": "bar"}
+	dcr := &changestreams.DataChangeRecord{
+		TransactionTag: "sdb-rwt-1234",
+		RecordSequence: "00000001",
+	}
+
+	metadata := withChangeRecordMetadata(transactionMetadata, dcr)
+	require.Equal(t, map[string]any{
+		"foo":                     "bar",
+		TransactionTagMetadataKey: "sdb-rwt-1234",
+		RecordSequenceMetadataKey: "00000001",
+	}, metadata)
+
+	// Ensure the cached transaction metadata was not modified.
+	require.Equal(t, map[string]any{"foo": "bar"}, transactionMetadata)
+}
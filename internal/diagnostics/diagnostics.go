@@ -0,0 +1,145 @@
+// Package diagnostics implements the diagnostics HTTP API, serving runtime profiles, goroutine
+// dumps and snapshots of the resolutions being dispatched, protected by an admin token.
+package diagnostics
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/pprof"
+	runtimepprof "runtime/pprof"
+	"strings"
+
+	middleware "github.com/grpc-ecosystem/go-grpc-middleware/v2"
+	"google.golang.org/grpc"
+
+	"github.com/authzed/spicedb/internal/dispatch/inflight"
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/pkg/middleware/requestid"
+)
+
+// RequestIDLabel is the profiler label under which the goroutines serving an API request are
+// tagged with the ID of the request.
+const RequestIDLabel = "request_id"
+
+const bearerPrefix = "Bearer "
+
+var errMissingAdminToken = errors.New("the diagnostics API requires an admin token")
+
+// NewHandler returns the handler of the diagnostics API, which requires every request to carry
+// the admin token as a bearer token. It serves:
+//
+//   - /debug/pprof/: the runtime profiles of the process
+//   - /debug/goroutines: a dump of the goroutines, tagged with the IDs of the requests they serve,
+//     optionally restricted to a single request with the `request_id` query parameter
+//   - /debug/dispatches: a snapshot of the resolutions being dispatched by the node
+func NewHandler(adminToken string, tracker *inflight.Tracker) (http.Handler, error) {
+	if adminToken == "" {
+		return nil, errMissingAdminToken
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.HandleFunc("/debug/pprof/cmdline", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprintf(w, "This profile type has been disabled to avoid leaking private command-line arguments")
+	})
+	mux.HandleFunc("/debug/goroutines", serveGoroutines)
+	mux.Handle("/debug/dispatches", tracker)
+
+	return requireAdminToken(adminToken, mux), nil
+}
+
+func requireAdminToken(adminToken string, next http.Handler) http.Handler {
+	expected := []byte(adminToken)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), bearerPrefix)
+		if !ok || subtle.ConstantTimeCompare([]byte(token), expected) != 1 {
+			log.Ctx(r.Context()).Warn().Str("path", r.URL.Path).Str("remote", r.RemoteAddr).Msg("rejected unauthenticated diagnostics request")
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "invalid or missing admin token", http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// serveGoroutines writes the goroutine profile in its text form, in which each group of
+// goroutines lists its profiler labels, keeping only the goroutines of the request given by the
+// `request_id` query parameter if any.
+func serveGoroutines(w http.ResponseWriter, r *http.Request) {
+	var buf bytes.Buffer
+	if err := runtimepprof.Lookup("goroutine").WriteTo(&buf, 1); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	requestID := r.URL.Query().Get(RequestIDLabel)
+	if requestID == "" {
+		_, _ = w.Write(buf.Bytes())
+		return
+	}
+
+	_, _ = w.Write([]byte(filterGoroutines(buf.String(), requestID)))
+}
+
+// filterGoroutines keeps the groups of goroutines of the text goroutine profile which are tagged
+// with the given request ID.
+func filterGoroutines(profile, requestID string) string {
+	label := fmt.Sprintf("%q:%q", RequestIDLabel, requestID)
+	groups := strings.Split(profile, "\n\n")
+
+	kept := make([]string, 0, len(groups))
+	for _, group := range groups {
+		for _, line := range strings.Split(group, "\n") {
+			if strings.HasPrefix(line, "# labels: ") && strings.Contains(line, label) {
+				kept = append(kept, group)
+				break
+			}
+		}
+	}
+
+	return strings.Join(kept, "\n\n") + "\n"
+}
+
+// UnaryServerInterceptor returns a new unary server interceptor that tags the goroutines
+// serving each request with the ID of the request.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		requestID, ok := requestid.FromContext(ctx)
+		if !ok {
+			return handler(ctx, req)
+		}
+
+		runtimepprof.Do(ctx, runtimepprof.Labels(RequestIDLabel, requestID), func(ctx context.Context) {
+			resp, err = handler(ctx, req)
+		})
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor returns a new stream server interceptor that tags the goroutines
+// serving each request with the ID of the request.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		requestID, ok := requestid.FromContext(stream.Context())
+		if !ok {
+			return handler(srv, stream)
+		}
+
+		runtimepprof.Do(stream.Context(), runtimepprof.Labels(RequestIDLabel, requestID), func(ctx context.Context) {
+			wrapped := middleware.WrapServerStream(stream)
+			wrapped.WrappedContext = ctx
+			err = handler(srv, wrapped)
+		})
+		return err
+	}
+}
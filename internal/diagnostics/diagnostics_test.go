@@ -0,0 +1,99 @@
+package diagnostics
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"runtime/pprof"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/authzed/spicedb/internal/dispatch/inflight"
+)
+
+func TestNewHandlerRequiresAdminToken(t *testing.T) {
+	_, err := NewHandler("", inflight.NewTracker())
+	require.ErrorIs(t, err, errMissingAdminToken)
+}
+
+func TestHandlerAuthentication(t *testing.T) {
+	handler, err := NewHandler("sometoken", inflight.NewTracker())
+	require.NoError(t, err)
+
+	tcs := []struct {
+		name          string
+		authorization string
+		expectedCode  int
+	}{
+		{"missing token", "", http.StatusUnauthorized},
+		{"invalid token", "Bearer othertoken", http.StatusUnauthorized},
+		{"token without scheme", "sometoken", http.StatusUnauthorized},
+		{"valid token", "Bearer sometoken", http.StatusOK},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, path := range []string{"/debug/pprof/", "/debug/goroutines", "/debug/dispatches"} {
+				req := httptest.NewRequest(http.MethodGet, path, nil)
+				if tc.authorization != "" {
+					req.Header.Set("Authorization", tc.authorization)
+				}
+
+				recorder := httptest.NewRecorder()
+				handler.ServeHTTP(recorder, req)
+				require.Equal(t, tc.expectedCode, recorder.Code, path)
+			}
+		})
+	}
+}
+
+func TestGoroutinesTaggedWithRequestID(t *testing.T) {
+	handler, err := NewHandler("sometoken", inflight.NewTracker())
+	require.NoError(t, err)
+
+	dump := func(requestID string) string {
+		req := httptest.NewRequest(http.MethodGet, "/debug/goroutines?request_id="+requestID, nil)
+		req.Header.Set("Authorization", "Bearer sometoken")
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+		require.Equal(t, http.StatusOK, recorder.Code)
+
+		body, err := io.ReadAll(recorder.Body)
+		require.NoError(t, err)
+		return string(body)
+	}
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-request-id", "somerequest"))
+	_, err = UnaryServerInterceptor()(ctx, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, _ any) (any, error) {
+		labels := map[string]string{}
+		pprof.ForLabels(ctx, func(key, value string) bool {
+			labels[key] = value
+			return true
+		})
+		require.Equal(t, map[string]string{RequestIDLabel: "somerequest"}, labels)
+
+		require.Contains(t, dump("somerequest"), "TestGoroutinesTaggedWithRequestID")
+		require.NotContains(t, dump("otherrequest"), "TestGoroutinesTaggedWithRequestID")
+		return nil, nil
+	})
+	require.NoError(t, err)
+}
+
+func TestFilterGoroutines(t *testing.T) {
+	profile := strings.Join([]string{
+		"goroutine profile: total 3",
+		"1 @ 0x1\n# labels: {\"request_id\":\"first\"}\n#\t0x1\tfoo+0x1",
+		"1 @ 0x2\n# labels: {\"request_id\":\"second\"}\n#\t0x2\tbar+0x1",
+		"1 @ 0x3\n#\t0x3\tbaz+0x1",
+	}, "\n\n")
+
+	filtered := filterGoroutines(profile, "first")
+	require.Contains(t, filtered, "foo")
+	require.NotContains(t, filtered, "bar")
+	require.NotContains(t, filtered, "baz")
+}
@@ -2,6 +2,7 @@ package caching
 
 import (
 	"context"
+	"encoding/binary"
 	"fmt"
 	"maps"
 	"sync"
@@ -34,8 +35,17 @@ type Dispatcher struct {
 	c          cache.Cache[keys.DispatchCacheKey, any]
 	keyHandler keys.Handler
 
+	// negativeCheckCache, if set, holds the check results for which no resources
+	// have permission, separately from the positive results held in c.
+	negativeCheckCache cache.Cache[keys.DispatchCacheKey, any]
+
+	// hotEntries, if set, counts the cache hits of entries for cache warming.
+	hotEntries *hotEntryTracker
+
 	checkTotalCounter               prometheus.Counter
 	checkFromCacheCounter           prometheus.Counter
+	checkPositiveFromCacheCounter   prometheus.Counter
+	checkNegativeFromCacheCounter   prometheus.Counter
 	lookupResourcesTotalCounter     prometheus.Counter
 	lookupResourcesFromCacheCounter prometheus.Counter
 	lookupSubjectsTotalCounter      prometheus.Counter
@@ -68,6 +78,18 @@ func NewCachingDispatcher(cacheInst cache.Cache[keys.DispatchCacheKey, any], met
 		Subsystem: prometheusSubsystem,
 		Name:      "check_from_cache_total",
 	})
+	checkPositiveFromCacheCounter := prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: prometheusNamespace,
+		Subsystem: prometheusSubsystem,
+		Name:      "check_positive_from_cache_total",
+		Help:      "number of checks served from the cache for which at least one resource has permission",
+	})
+	checkNegativeFromCacheCounter := prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: prometheusNamespace,
+		Subsystem: prometheusSubsystem,
+		Name:      "check_negative_from_cache_total",
+		Help:      "number of checks served from the cache for which no resource has permission",
+	})
 
 	lookupResourcesTotalCounter := prometheus.NewCounter(prometheus.CounterOpts{
 		Namespace: prometheusNamespace,
@@ -100,6 +122,14 @@ func NewCachingDispatcher(cacheInst cache.Cache[keys.DispatchCacheKey, any], met
 		if err != nil {
 			return nil, fmt.Errorf(errCachingInitialization, err)
 		}
+		err = prometheus.Register(checkPositiveFromCacheCounter)
+		if err != nil {
+			return nil, fmt.Errorf(errCachingInitialization, err)
+		}
+		err = prometheus.Register(checkNegativeFromCacheCounter)
+		if err != nil {
+			return nil, fmt.Errorf(errCachingInitialization, err)
+		}
 		err = prometheus.Register(lookupResourcesTotalCounter)
 		if err != nil {
 			return nil, fmt.Errorf(errCachingInitialization, err)
@@ -128,6 +158,8 @@ func NewCachingDispatcher(cacheInst cache.Cache[keys.DispatchCacheKey, any], met
 		keyHandler:                      keyHandler,
 		checkTotalCounter:               checkTotalCounter,
 		checkFromCacheCounter:           checkFromCacheCounter,
+		checkPositiveFromCacheCounter:   checkPositiveFromCacheCounter,
+		checkNegativeFromCacheCounter:   checkNegativeFromCacheCounter,
 		lookupResourcesTotalCounter:     lookupResourcesTotalCounter,
 		lookupResourcesFromCacheCounter: lookupResourcesFromCacheCounter,
 		lookupSubjectsTotalCounter:      lookupSubjectsTotalCounter,
@@ -140,6 +172,36 @@ func (cd *Dispatcher) SetDelegate(delegate dispatch.Dispatcher) {
 	cd.d = delegate
 }
 
+// SetNegativeCheckCache sets a cache to hold the check results for which no resources have
+// permission, allowing them to be sized and expired separately from the positive results.
+// If unset, negative results are held in the same cache as positive results.
+func (cd *Dispatcher) SetNegativeCheckCache(negativeCache cache.Cache[keys.DispatchCacheKey, any]) {
+	cd.negativeCheckCache = negativeCache
+}
+
+// getCheck returns the cached check result for the key, if any.
+func (cd *Dispatcher) getCheck(requestKey keys.DispatchCacheKey) (any, bool) {
+	if cachedResultRaw, found := cd.c.Get(requestKey); found {
+		return cachedResultRaw, true
+	}
+
+	if cd.negativeCheckCache != nil {
+		return cd.negativeCheckCache.Get(requestKey)
+	}
+
+	return nil, false
+}
+
+// isNegativeCheckResponse returns whether no resources have permission in the check response.
+func isNegativeCheckResponse(resp *v1.DispatchCheckResponse) bool {
+	for _, result := range resp.ResultsByResourceId {
+		if result.Membership != v1.ResourceCheckResult_NOT_MEMBER {
+			return false
+		}
+	}
+	return true
+}
+
 // DispatchCheck implements dispatch.Check interface
 func (cd *Dispatcher) DispatchCheck(ctx context.Context, req *v1.DispatchCheckRequest) (*v1.DispatchCheckResponse, error) {
 	cd.checkTotalCounter.Inc()
@@ -151,7 +213,7 @@ func (cd *Dispatcher) DispatchCheck(ctx context.Context, req *v1.DispatchCheckRe
 
 	// Disable caching when debugging is enabled.
 	span := trace.SpanFromContext(ctx)
-	if cachedResultRaw, found := cd.c.Get(requestKey); found {
+	if cachedResultRaw, found := cd.getCheck(requestKey); found {
 		var response v1.DispatchCheckResponse
 		if err := response.UnmarshalVT(cachedResultRaw.([]byte)); err != nil {
 			return &v1.DispatchCheckResponse{Metadata: &v1.ResponseMeta{}}, err
@@ -159,6 +221,11 @@ func (cd *Dispatcher) DispatchCheck(ctx context.Context, req *v1.DispatchCheckRe
 
 		if req.Metadata.DepthRemaining >= response.Metadata.DepthRequired {
 			cd.checkFromCacheCounter.Inc()
+			if isNegativeCheckResponse(&response) {
+				cd.checkNegativeFromCacheCounter.Inc()
+			} else {
+				cd.checkPositiveFromCacheCounter.Inc()
+			}
 			// If debugging is requested, add the req and the response to the trace.
 			if req.Debug == v1.DispatchCheckRequest_ENABLE_BASIC_DEBUGGING {
 				nodeID, err := nodeid.FromContext(ctx)
@@ -176,6 +243,10 @@ func (cd *Dispatcher) DispatchCheck(ctx context.Context, req *v1.DispatchCheckRe
 				}
 			}
 
+			if cd.hotEntries != nil {
+				cd.hotEntries.recordHit(requestKey, v1.DumpHotCacheEntriesResponse_REQUEST_KIND_CHECK, req.MarshalVT)
+			}
+
 			span.SetAttributes(attribute.Bool("cached", true))
 			return &response, nil
 		}
@@ -195,7 +266,11 @@ func (cd *Dispatcher) DispatchCheck(ctx context.Context, req *v1.DispatchCheckRe
 			return &v1.DispatchCheckResponse{Metadata: &v1.ResponseMeta{}}, err
 		}
 
-		cd.c.Set(requestKey, adjustedBytes, sliceSize(adjustedBytes))
+		if cd.negativeCheckCache != nil && isNegativeCheckResponse(adjustedComputed) {
+			cd.negativeCheckCache.Set(requestKey, adjustedBytes, entryCost(sliceSize(adjustedBytes)))
+		} else {
+			cd.c.Set(requestKey, adjustedBytes, entryCost(sliceSize(adjustedBytes)))
+		}
 	}
 
 	// Return both the computed and err in ALL cases: computed contains resolved
@@ -209,11 +284,37 @@ func (cd *Dispatcher) DispatchExpand(ctx context.Context, req *v1.DispatchExpand
 	return resp, err
 }
 
+const (
+	// cacheKeySize is the size of the key of a cache entry, at most: caches hold the dispatch cache
+	// key as a string of its two varint-encoded sums.
+	cacheKeySize = int64(unsafe.Sizeof("")) + 2*binary.MaxVarintLen64
+
+	// cacheEntryOverhead is an estimate of the memory used by a cache for each entry, beyond its key
+	// and value: the interface holding the value and the bookkeeping of its hash table, expiration
+	// and eviction policy.
+	cacheEntryOverhead = 64
+)
+
+// entryCost returns the cost of a cache entry holding a value of the given size: the memory
+// used by the entry, in bytes.
+func entryCost(valueSize int64) int64 {
+	return cacheKeySize + cacheEntryOverhead + valueSize
+}
+
 func sliceSize(xs []byte) int64 {
 	// Slice Header + Slice Contents
 	return int64(int(unsafe.Sizeof(xs)) + len(xs))
 }
 
+func slicesSize(xs [][]byte) int64 {
+	// Outer Slice Header + Unused Outer Slice Capacity + Inner Slice Headers and Contents
+	size := int64(unsafe.Sizeof(xs)) + int64(cap(xs)-len(xs))*int64(unsafe.Sizeof([]byte(nil)))
+	for _, slice := range xs {
+		size += sliceSize(slice)
+	}
+	return size
+}
+
 func (cd *Dispatcher) DispatchLookupResources2(req *v1.DispatchLookupResources2Request, stream dispatch.LookupResources2Stream) error {
 	cd.lookupResourcesTotalCounter.Inc()
 
@@ -224,6 +325,9 @@ func (cd *Dispatcher) DispatchLookupResources2(req *v1.DispatchLookupResources2R
 
 	if cachedResultRaw, found := cd.c.Get(requestKey); found {
 		cd.lookupResourcesFromCacheCounter.Inc()
+		if cd.hotEntries != nil {
+			cd.hotEntries.recordHit(requestKey, v1.DumpHotCacheEntriesResponse_REQUEST_KIND_LOOKUP_RESOURCES2, req.MarshalVT)
+		}
 		for _, slice := range cachedResultRaw.([][]byte) {
 			var response v1.DispatchLookupResources2Response
 			if err := response.UnmarshalVT(slice); err != nil {
@@ -269,12 +373,7 @@ func (cd *Dispatcher) DispatchLookupResources2(req *v1.DispatchLookupResources2R
 		return err
 	}
 
-	var size int64
-	for _, slice := range toCacheResults {
-		size += sliceSize(slice)
-	}
-
-	cd.c.Set(requestKey, toCacheResults, size)
+	cd.c.Set(requestKey, toCacheResults, entryCost(slicesSize(toCacheResults)))
 	return nil
 }
 
@@ -289,6 +388,9 @@ func (cd *Dispatcher) DispatchLookupSubjects(req *v1.DispatchLookupSubjectsReque
 
 	if cachedResultRaw, found := cd.c.Get(requestKey); found {
 		cd.lookupSubjectsFromCacheCounter.Inc()
+		if cd.hotEntries != nil {
+			cd.hotEntries.recordHit(requestKey, v1.DumpHotCacheEntriesResponse_REQUEST_KIND_LOOKUP_SUBJECTS, req.MarshalVT)
+		}
 		for _, slice := range cachedResultRaw.([][]byte) {
 			var response v1.DispatchLookupSubjectsResponse
 			if err := response.UnmarshalVT(slice); err != nil {
@@ -334,18 +436,15 @@ func (cd *Dispatcher) DispatchLookupSubjects(req *v1.DispatchLookupSubjectsReque
 		return err
 	}
 
-	var size int64
-	for _, slice := range toCacheResults {
-		size += sliceSize(slice)
-	}
-
-	cd.c.Set(requestKey, toCacheResults, size)
+	cd.c.Set(requestKey, toCacheResults, entryCost(slicesSize(toCacheResults)))
 	return nil
 }
 
 func (cd *Dispatcher) Close() error {
 	prometheus.Unregister(cd.checkTotalCounter)
 	prometheus.Unregister(cd.checkFromCacheCounter)
+	prometheus.Unregister(cd.checkPositiveFromCacheCounter)
+	prometheus.Unregister(cd.checkNegativeFromCacheCounter)
 	prometheus.Unregister(cd.lookupResourcesTotalCounter)
 	prometheus.Unregister(cd.lookupResourcesFromCacheCounter)
 	prometheus.Unregister(cd.lookupSubjectsFromCacheCounter)
@@ -353,6 +452,9 @@ func (cd *Dispatcher) Close() error {
 	if cache := cd.c; cache != nil {
 		cache.Close()
 	}
+	if cache := cd.negativeCheckCache; cache != nil {
+		cache.Close()
+	}
 
 	return nil
 }
@@ -4,12 +4,14 @@ import (
 	"context"
 	"testing"
 	"time"
+	"unsafe"
 
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
 	"github.com/authzed/spicedb/internal/dispatch"
+	"github.com/authzed/spicedb/internal/dispatch/keys"
 	core "github.com/authzed/spicedb/pkg/proto/core/v1"
 	v1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
 	"github.com/authzed/spicedb/pkg/tuple"
@@ -149,6 +151,71 @@ func TestMaxDepthCaching(t *testing.T) {
 	}
 }
 
+func TestNegativeCheckCaching(t *testing.T) {
+	require := require.New(t)
+
+	checkRequest := func(resourceID string) *v1.DispatchCheckRequest {
+		return &v1.DispatchCheckRequest{
+			ResourceRelation: RR("document", "read"),
+			ResourceIds:      []string{resourceID},
+			Subject:          tuple.MustParseSubjectONR("user:user1#...").ToCoreONR(),
+			Metadata: &v1.ResolverMeta{
+				AtRevision:     decimal.Zero.String(),
+				DepthRemaining: 50,
+			},
+		}
+	}
+
+	delegate := delegateDispatchMock{&mock.Mock{}}
+	delegate.On("DispatchCheck", checkRequest("positive")).Return(&v1.DispatchCheckResponse{
+		ResultsByResourceId: map[string]*v1.ResourceCheckResult{
+			"positive": {Membership: v1.ResourceCheckResult_MEMBER},
+		},
+		Metadata: &v1.ResponseMeta{DispatchCount: 1, DepthRequired: 1},
+	}, nil).Times(1)
+	delegate.On("DispatchCheck", checkRequest("negative")).Return(&v1.DispatchCheckResponse{
+		ResultsByResourceId: map[string]*v1.ResourceCheckResult{},
+		Metadata:            &v1.ResponseMeta{DispatchCount: 1, DepthRequired: 1},
+	}, nil).Times(1)
+
+	positiveCache := DispatchTestCache(t)
+	negativeCache := DispatchTestCache(t)
+
+	dispatch, err := NewCachingDispatcher(positiveCache, false, "", nil)
+	require.NoError(err)
+	dispatch.SetDelegate(delegate)
+	dispatch.SetNegativeCheckCache(negativeCache)
+	defer dispatch.Close()
+
+	for range 2 {
+		for _, resourceID := range []string{"positive", "negative"} {
+			_, err := dispatch.DispatchCheck(context.Background(), checkRequest(resourceID))
+			require.NoError(err)
+		}
+
+		// We have to sleep a while to let the caches converge
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	delegate.AssertExpectations(t)
+
+	keyHandler := &keys.DirectKeyHandler{}
+	positiveKey, err := keyHandler.CheckCacheKey(context.Background(), checkRequest("positive"))
+	require.NoError(err)
+	negativeKey, err := keyHandler.CheckCacheKey(context.Background(), checkRequest("negative"))
+	require.NoError(err)
+
+	_, found := positiveCache.Get(positiveKey)
+	require.True(found)
+	_, found = negativeCache.Get(positiveKey)
+	require.False(found)
+
+	_, found = positiveCache.Get(negativeKey)
+	require.False(found)
+	_, found = negativeCache.Get(negativeKey)
+	require.True(found)
+}
+
 type delegateDispatchMock struct {
 	*mock.Mock
 }
@@ -179,3 +246,22 @@ func (ddm delegateDispatchMock) ReadyState() dispatch.ReadyState {
 }
 
 var _ dispatch.Dispatcher = &delegateDispatchMock{}
+
+func TestSlicesSize(t *testing.T) {
+	sliceHeaderSize := int64(unsafe.Sizeof([]byte(nil)))
+
+	require.Equal(t, sliceHeaderSize, slicesSize(nil))
+	require.Equal(t, sliceHeaderSize+sliceHeaderSize+3, slicesSize([][]byte{{1, 2, 3}}))
+
+	withCapacity := make([][]byte, 0, 4)
+	withCapacity = append(withCapacity, []byte{1, 2}, []byte{3})
+	require.Equal(t, sliceHeaderSize+4*sliceHeaderSize+3, slicesSize(withCapacity))
+}
+
+func TestEntryCost(t *testing.T) {
+	value := []byte{1, 2, 3}
+
+	// The cost of an entry includes its key and overhead, beyond its value.
+	require.Greater(t, entryCost(sliceSize(value)), sliceSize(value))
+	require.Equal(t, entryCost(0)+sliceSize(value), entryCost(sliceSize(value)))
+}
@@ -0,0 +1,219 @@
+package caching
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/authzed/spicedb/internal/dispatch/keys"
+	v1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+)
+
+// hotEntryTracker counts the cache hits of the entries of a dispatch cache, keeping the requests of
+// the entries so that they can be dumped to another node, whose cache keys differ.
+type hotEntryTracker struct {
+	lock     sync.Mutex
+	capacity int
+	entries  map[keys.DispatchCacheKey]*hotEntry
+}
+
+type hotEntry struct {
+	kind    v1.DumpHotCacheEntriesResponse_RequestKind
+	request []byte
+	hits    uint64
+}
+
+func newHotEntryTracker(capacity int) *hotEntryTracker {
+	return &hotEntryTracker{
+		capacity: capacity,
+		entries:  make(map[keys.DispatchCacheKey]*hotEntry, capacity),
+	}
+}
+
+// recordHit counts a cache hit for the key, marshaling the request only when the entry is not yet
+// tracked.
+func (t *hotEntryTracker) recordHit(key keys.DispatchCacheKey, kind v1.DumpHotCacheEntriesResponse_RequestKind, marshal func() ([]byte, error)) {
+	t.lock.Lock()
+	if entry, ok := t.entries[key]; ok {
+		entry.hits++
+		t.lock.Unlock()
+		return
+	}
+	t.lock.Unlock()
+
+	request, err := marshal()
+	if err != nil {
+		return
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if entry, ok := t.entries[key]; ok {
+		entry.hits++
+		return
+	}
+
+	if len(t.entries) >= t.capacity {
+		t.age()
+		if len(t.entries) >= t.capacity {
+			return
+		}
+	}
+	t.entries[key] = &hotEntry{kind: kind, request: request, hits: 1}
+}
+
+// age halves the hits of every entry, forgetting those left without any, so that entries which
+// are no longer hit make room for new ones. Must be called with the lock held.
+func (t *hotEntryTracker) age() {
+	for key, entry := range t.entries {
+		entry.hits /= 2
+		if entry.hits == 0 {
+			delete(t.entries, key)
+		}
+	}
+}
+
+type trackedEntry struct {
+	key keys.DispatchCacheKey
+	hotEntry
+}
+
+// hottest returns the tracked entries in descending order of hits.
+func (t *hotEntryTracker) hottest() []trackedEntry {
+	t.lock.Lock()
+	tracked := make([]trackedEntry, 0, len(t.entries))
+	for key, entry := range t.entries {
+		tracked = append(tracked, trackedEntry{key, *entry})
+	}
+	t.lock.Unlock()
+
+	slices.SortFunc(tracked, func(a, b trackedEntry) int {
+		switch {
+		case a.hits > b.hits:
+			return -1
+		case a.hits < b.hits:
+			return 1
+		default:
+			return 0
+		}
+	})
+	return tracked
+}
+
+func (t *hotEntryTracker) forget(key keys.DispatchCacheKey) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	delete(t.entries, key)
+}
+
+// TrackHotEntries enables counting the cache hits of up to capacity entries, so that the entries
+// with the most hits can be returned by HotCacheEntries. Must be called before dispatching.
+func (cd *Dispatcher) TrackHotEntries(capacity int) {
+	if capacity > 0 {
+		cd.hotEntries = newHotEntryTracker(capacity)
+	}
+}
+
+// HotCacheEntries returns up to limit of the cached entries with the most hits, in descending order
+// of hits. Nothing is returned unless TrackHotEntries was called.
+func (cd *Dispatcher) HotCacheEntries(limit int) []*v1.DumpHotCacheEntriesResponse {
+	if cd.hotEntries == nil || limit <= 0 {
+		return nil
+	}
+
+	var dumped []*v1.DumpHotCacheEntriesResponse
+	for _, entry := range cd.hotEntries.hottest() {
+		if len(dumped) >= limit {
+			break
+		}
+
+		var responses [][]byte
+		if entry.kind == v1.DumpHotCacheEntriesResponse_REQUEST_KIND_CHECK {
+			cached, found := cd.getCheck(entry.key)
+			if found {
+				responses = [][]byte{cached.([]byte)}
+			}
+		} else if cached, found := cd.c.Get(entry.key); found {
+			responses = cached.([][]byte)
+		}
+
+		// Entries evicted from the cache since their last hit are no longer worth tracking.
+		if responses == nil {
+			cd.hotEntries.forget(entry.key)
+			continue
+		}
+
+		dumped = append(dumped, &v1.DumpHotCacheEntriesResponse{
+			RequestKind: entry.kind,
+			Request:     entry.request,
+			Responses:   responses,
+			HitCount:    entry.hits,
+		})
+	}
+	return dumped
+}
+
+// WarmCache adds an entry dumped by HotCacheEntries, usually on another node, to the cache. The
+// context must carry what the key handler of the dispatcher needs to compute cache keys.
+func (cd *Dispatcher) WarmCache(ctx context.Context, entry *v1.DumpHotCacheEntriesResponse) error {
+	switch entry.RequestKind {
+	case v1.DumpHotCacheEntriesResponse_REQUEST_KIND_CHECK:
+		if len(entry.Responses) != 1 {
+			return fmt.Errorf("expected a single check response, found %d", len(entry.Responses))
+		}
+
+		var req v1.DispatchCheckRequest
+		if err := req.UnmarshalVT(entry.Request); err != nil {
+			return err
+		}
+		var resp v1.DispatchCheckResponse
+		if err := resp.UnmarshalVT(entry.Responses[0]); err != nil {
+			return err
+		}
+
+		requestKey, err := cd.keyHandler.CheckCacheKey(ctx, &req)
+		if err != nil {
+			return err
+		}
+
+		if cd.negativeCheckCache != nil && isNegativeCheckResponse(&resp) {
+			cd.negativeCheckCache.Set(requestKey, entry.Responses[0], entryCost(sliceSize(entry.Responses[0])))
+		} else {
+			cd.c.Set(requestKey, entry.Responses[0], entryCost(sliceSize(entry.Responses[0])))
+		}
+		return nil
+
+	case v1.DumpHotCacheEntriesResponse_REQUEST_KIND_LOOKUP_RESOURCES2:
+		var req v1.DispatchLookupResources2Request
+		if err := req.UnmarshalVT(entry.Request); err != nil {
+			return err
+		}
+
+		requestKey, err := cd.keyHandler.LookupResources2CacheKey(ctx, &req)
+		if err != nil {
+			return err
+		}
+
+		cd.c.Set(requestKey, entry.Responses, entryCost(slicesSize(entry.Responses)))
+		return nil
+
+	case v1.DumpHotCacheEntriesResponse_REQUEST_KIND_LOOKUP_SUBJECTS:
+		var req v1.DispatchLookupSubjectsRequest
+		if err := req.UnmarshalVT(entry.Request); err != nil {
+			return err
+		}
+
+		requestKey, err := cd.keyHandler.LookupSubjectsCacheKey(ctx, &req)
+		if err != nil {
+			return err
+		}
+
+		cd.c.Set(requestKey, entry.Responses, entryCost(slicesSize(entry.Responses)))
+		return nil
+
+	default:
+		return fmt.Errorf("unknown cache entry request kind: %v", entry.RequestKind)
+	}
+}
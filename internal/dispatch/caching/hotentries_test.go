@@ -0,0 +1,126 @@
+package caching
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/dispatch/keys"
+	v1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+func TestWarmCacheFromHotEntries(t *testing.T) {
+	require := require.New(t)
+
+	checkRequest := func(resourceID string) *v1.DispatchCheckRequest {
+		return &v1.DispatchCheckRequest{
+			ResourceRelation: RR("document", "read"),
+			ResourceIds:      []string{resourceID},
+			Subject:          tuple.MustParseSubjectONR("user:user1#...").ToCoreONR(),
+			Metadata: &v1.ResolverMeta{
+				AtRevision:     decimal.Zero.String(),
+				DepthRemaining: 50,
+			},
+		}
+	}
+	checkResponse := func(resourceID string) *v1.DispatchCheckResponse {
+		return &v1.DispatchCheckResponse{
+			ResultsByResourceId: map[string]*v1.ResourceCheckResult{
+				resourceID: {Membership: v1.ResourceCheckResult_MEMBER},
+			},
+			Metadata: &v1.ResponseMeta{DispatchCount: 1, DepthRequired: 1},
+		}
+	}
+
+	delegate := delegateDispatchMock{&mock.Mock{}}
+	for _, resourceID := range []string{"hot", "warm", "cold"} {
+		delegate.On("DispatchCheck", checkRequest(resourceID)).Return(checkResponse(resourceID), nil).Times(1)
+	}
+
+	peer, err := NewCachingDispatcher(DispatchTestCache(t), false, "", nil)
+	require.NoError(err)
+	peer.SetDelegate(delegate)
+	peer.TrackHotEntries(10)
+	defer peer.Close()
+
+	for resourceID, count := range map[string]int{"hot": 4, "warm": 2, "cold": 1} {
+		for range count {
+			_, err := peer.DispatchCheck(context.Background(), checkRequest(resourceID))
+			require.NoError(err)
+
+			// We have to sleep a while to let the cache converge
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	delegate.AssertExpectations(t)
+
+	// Entries never hit are not dumped, and the others are dumped hottest first.
+	entries := peer.HotCacheEntries(10)
+	require.Len(entries, 2)
+	require.Equal(uint64(3), entries[0].HitCount)
+	require.Equal(uint64(1), entries[1].HitCount)
+	require.Len(peer.HotCacheEntries(1), 1)
+
+	// A node warmed from the dump serves the dumped entries without dispatching.
+	node, err := NewCachingDispatcher(DispatchTestCache(t), false, "", nil)
+	require.NoError(err)
+	node.SetDelegate(delegateDispatchMock{&mock.Mock{}})
+	defer node.Close()
+
+	for _, entry := range entries {
+		require.NoError(node.WarmCache(context.Background(), entry))
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	for _, resourceID := range []string{"hot", "warm"} {
+		resp, err := node.DispatchCheck(context.Background(), checkRequest(resourceID))
+		require.NoError(err)
+		require.Equal(v1.ResourceCheckResult_MEMBER, resp.ResultsByResourceId[resourceID].Membership)
+	}
+}
+
+func TestHotEntryTrackerAging(t *testing.T) {
+	require := require.New(t)
+
+	checkRequest := func(resourceID string) *v1.DispatchCheckRequest {
+		return &v1.DispatchCheckRequest{
+			ResourceRelation: RR("document", "read"),
+			ResourceIds:      []string{resourceID},
+			Subject:          tuple.MustParseSubjectONR("user:user1#...").ToCoreONR(),
+			Metadata:         &v1.ResolverMeta{AtRevision: decimal.Zero.String()},
+		}
+	}
+
+	tracker := newHotEntryTracker(2)
+	record := func(resourceID string) {
+		req := checkRequest(resourceID)
+		key, err := (&keys.DirectKeyHandler{}).CheckCacheKey(context.Background(), req)
+		require.NoError(err)
+		tracker.recordHit(key, v1.DumpHotCacheEntriesResponse_REQUEST_KIND_CHECK, req.MarshalVT)
+	}
+
+	for range 4 {
+		record("hot")
+	}
+	record("cold")
+
+	// Tracking a new entry beyond the capacity ages the existing ones, forgetting those hit once.
+	record("new")
+	hottest := tracker.hottest()
+	require.Len(hottest, 2)
+
+	var first v1.DispatchCheckRequest
+	require.NoError(first.UnmarshalVT(hottest[0].request))
+	require.Equal([]string{"hot"}, first.ResourceIds)
+	require.Equal(uint64(2), hottest[0].hits)
+
+	var second v1.DispatchCheckRequest
+	require.NoError(second.UnmarshalVT(hottest[1].request))
+	require.Equal([]string{"new"}, second.ResourceIds)
+	require.Equal(uint64(1), hottest[1].hits)
+}
@@ -0,0 +1,211 @@
+// Package canary evaluates a sample of the checks dispatched by the API with an alternate canary
+// dispatcher too, such as one using a new implementation of the resolution of checks, logging and
+// counting the checks whose results differ, so that the implementation can be validated against
+// production traffic before it answers it.
+package canary
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/authzed/spicedb/internal/dispatch"
+	log "github.com/authzed/spicedb/internal/logging"
+	v1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+const (
+	outcomeMatch    = "match"
+	outcomeMismatch = "mismatch"
+	outcomeError    = "error"
+	outcomeSkipped  = "skipped"
+
+	defaultTimeout = 30 * time.Second
+
+	// maxLoggedResourceIDs is the maximum number of the resources whose results differ logged
+	// for a check.
+	maxLoggedResourceIDs = 10
+)
+
+var canaryCheckCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "spicedb",
+	Subsystem: "dispatch",
+	Name:      "canary_checks_total",
+	Help:      "number of checks sampled for evaluation by the canary dispatcher, by canary engine and by whether its results matched those of the dispatcher, it failed, or it was skipped as too many checks were being evaluated",
+}, []string{"engine", "outcome"})
+
+// Config is the configuration of a canary Dispatcher.
+type Config struct {
+	// Engine is the name of the code path of the canary dispatcher, with which its results are
+	// logged and counted.
+	Engine string
+
+	// Percent is the percentage of the checks evaluated by the canary dispatcher too.
+	Percent float64
+
+	// MaxInFlight is the maximum number of checks evaluated by the canary dispatcher at once,
+	// beyond which sampled checks are skipped.
+	MaxInFlight uint16
+
+	// Timeout is the maximum duration of the evaluation of a check by the canary dispatcher.
+	// Defaults to 30 seconds.
+	Timeout time.Duration
+}
+
+// NewDispatcher returns a dispatcher answering with the primary dispatcher and evaluating the
+// configured percentage of the checks with the canary dispatcher too, in the background.
+func NewDispatcher(primary, canary dispatch.Dispatcher, config Config) *Dispatcher {
+	if config.Timeout == 0 {
+		config.Timeout = defaultTimeout
+	}
+
+	return &Dispatcher{
+		primary:  primary,
+		canary:   canary,
+		config:   config,
+		inFlight: make(chan struct{}, max(config.MaxInFlight, 1)),
+		sample: func() bool {
+			return rand.Float64()*100 < config.Percent // nolint:gosec // sampling does not need a secure source
+		},
+		done: func() {},
+	}
+}
+
+// Dispatcher is a dispatcher evaluating a sample of the checks with a canary dispatcher too. All
+// other dispatches are delegated to the primary dispatcher.
+type Dispatcher struct {
+	primary  dispatch.Dispatcher
+	canary   dispatch.Dispatcher
+	config   Config
+	inFlight chan struct{}
+	sample   func() bool
+
+	// done is called once the canary evaluation of a check completes, for testing.
+	done func()
+}
+
+func (d *Dispatcher) DispatchCheck(ctx context.Context, req *v1.DispatchCheckRequest) (*v1.DispatchCheckResponse, error) {
+	resp, err := d.primary.DispatchCheck(ctx, req)
+
+	// The debug trace of a check is not compared, and there is nothing to compare to failures.
+	if err != nil || req.Debug != v1.DispatchCheckRequest_NO_DEBUG || !d.sample() {
+		return resp, err
+	}
+
+	select {
+	case d.inFlight <- struct{}{}:
+	default:
+		canaryCheckCounter.WithLabelValues(d.config.Engine, outcomeSkipped).Inc()
+		return resp, err
+	}
+
+	// The canary evaluation outlives the request, but keeps its values, such as its datastore.
+	canaryCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), d.config.Timeout)
+	canaryReq := req.CloneVT()
+	primaryResp := resp.CloneVT()
+	go func() {
+		defer func() {
+			cancel()
+			<-d.inFlight
+			d.done()
+		}()
+
+		canaryResp, canaryErr := d.canary.DispatchCheck(canaryCtx, canaryReq)
+		d.compare(canaryCtx, canaryReq, primaryResp, canaryResp, canaryErr)
+	}()
+
+	return resp, err
+}
+
+// compare logs and counts the outcome of the evaluation of the check by the canary dispatcher.
+func (d *Dispatcher) compare(ctx context.Context, req *v1.DispatchCheckRequest, resp, canaryResp *v1.DispatchCheckResponse, canaryErr error) {
+	if canaryErr != nil {
+		canaryCheckCounter.WithLabelValues(d.config.Engine, outcomeError).Inc()
+		log.Ctx(ctx).Warn().Err(canaryErr).Str("engine", d.config.Engine).Msg("canary dispatcher failed to evaluate check")
+		return
+	}
+
+	differing := differingResourceIDs(req, resp, canaryResp)
+	if len(differing) == 0 {
+		canaryCheckCounter.WithLabelValues(d.config.Engine, outcomeMatch).Inc()
+		return
+	}
+
+	canaryCheckCounter.WithLabelValues(d.config.Engine, outcomeMismatch).Inc()
+	log.Ctx(ctx).Warn().
+		Str("engine", d.config.Engine).
+		Str("resource_relation", tuple.StringCoreRR(req.ResourceRelation)).
+		Str("subject", tuple.StringCoreONR(req.Subject)).
+		Str("revision", req.Metadata.GetAtRevision()).
+		Strs("resource_ids", differing[:min(len(differing), maxLoggedResourceIDs)]).
+		Int("differing_count", len(differing)).
+		Msg("canary dispatcher returned different check results")
+}
+
+// differingResourceIDs returns the IDs of the resources whose membership differs between
+// the responses. When a single result is allowed, either dispatcher may return any member, so only
+// the best membership found by each is compared.
+func differingResourceIDs(req *v1.DispatchCheckRequest, resp, canaryResp *v1.DispatchCheckResponse) []string {
+	if req.ResultsSetting == v1.DispatchCheckRequest_ALLOW_SINGLE_RESULT {
+		if bestMembership(resp) != bestMembership(canaryResp) {
+			return req.ResourceIds
+		}
+		return nil
+	}
+
+	var differing []string
+	for _, resourceID := range req.ResourceIds {
+		if membership(resp, resourceID) != membership(canaryResp, resourceID) {
+			differing = append(differing, resourceID)
+		}
+	}
+	return differing
+}
+
+// membership returns the membership of the resource in the response, resources without a result
+// not being members.
+func membership(resp *v1.DispatchCheckResponse, resourceID string) v1.ResourceCheckResult_Membership {
+	result, ok := resp.GetResultsByResourceId()[resourceID]
+	if !ok {
+		return v1.ResourceCheckResult_NOT_MEMBER
+	}
+	return result.Membership
+}
+
+// bestMembership returns the membership closest to MEMBER among the results of the response.
+func bestMembership(resp *v1.DispatchCheckResponse) v1.ResourceCheckResult_Membership {
+	best := v1.ResourceCheckResult_NOT_MEMBER
+	for _, result := range resp.GetResultsByResourceId() {
+		switch result.Membership {
+		case v1.ResourceCheckResult_MEMBER:
+			return v1.ResourceCheckResult_MEMBER
+		case v1.ResourceCheckResult_CAVEATED_MEMBER:
+			best = v1.ResourceCheckResult_CAVEATED_MEMBER
+		}
+	}
+	return best
+}
+
+func (d *Dispatcher) DispatchExpand(ctx context.Context, req *v1.DispatchExpandRequest) (*v1.DispatchExpandResponse, error) {
+	return d.primary.DispatchExpand(ctx, req)
+}
+
+func (d *Dispatcher) DispatchLookupResources2(req *v1.DispatchLookupResources2Request, stream dispatch.LookupResources2Stream) error {
+	return d.primary.DispatchLookupResources2(req, stream)
+}
+
+func (d *Dispatcher) DispatchLookupSubjects(req *v1.DispatchLookupSubjectsRequest, stream dispatch.LookupSubjectsStream) error {
+	return d.primary.DispatchLookupSubjects(req, stream)
+}
+
+// Close closes both the primary and the canary dispatchers.
+func (d *Dispatcher) Close() error {
+	return errors.Join(d.primary.Close(), d.canary.Close())
+}
+
+func (d *Dispatcher) ReadyState() dispatch.ReadyState { return d.primary.ReadyState() }
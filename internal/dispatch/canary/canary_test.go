@@ -0,0 +1,190 @@
+package canary
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/dispatch"
+	v1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// fakeDispatcher answers checks with fixed memberships.
+type fakeDispatcher struct {
+	dispatch.Dispatcher
+
+	members map[string]v1.ResourceCheckResult_Membership
+	err     error
+	closed  bool
+}
+
+func (d *fakeDispatcher) Close() error {
+	d.closed = true
+	return nil
+}
+
+func (d *fakeDispatcher) DispatchCheck(_ context.Context, req *v1.DispatchCheckRequest) (*v1.DispatchCheckResponse, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	results := make(map[string]*v1.ResourceCheckResult)
+	for _, resourceID := range req.ResourceIds {
+		if membership, ok := d.members[resourceID]; ok {
+			results[resourceID] = &v1.ResourceCheckResult{Membership: membership}
+		}
+	}
+	return &v1.DispatchCheckResponse{Metadata: &v1.ResponseMeta{}, ResultsByResourceId: results}, nil
+}
+
+func newCheckRequest(setting v1.DispatchCheckRequest_ResultsSetting, resourceIDs ...string) *v1.DispatchCheckRequest {
+	return &v1.DispatchCheckRequest{
+		ResourceRelation: tuple.RelationReference{ObjectType: "document", Relation: "view"}.ToCoreRR(),
+		ResourceIds:      resourceIDs,
+		Subject:          tuple.CoreONR("user", "tom", tuple.Ellipsis),
+		ResultsSetting:   setting,
+		Metadata:         &v1.ResolverMeta{AtRevision: "1", DepthRemaining: 50},
+	}
+}
+
+func newTestDispatcher(engine string, primary, canary dispatch.Dispatcher) (*Dispatcher, *sync.WaitGroup) {
+	d := NewDispatcher(primary, canary, Config{Engine: engine, Percent: 100, MaxInFlight: 10})
+	var wg sync.WaitGroup
+	d.done = wg.Done
+	return d, &wg
+}
+
+// outcomeCounter returns a function returning the number of checks counted with the outcome since
+// it was created, as the counters are shared by all tests.
+func outcomeCounter(engine, outcome string) func() float64 {
+	initial := testutil.ToFloat64(canaryCheckCounter.WithLabelValues(engine, outcome))
+	return func() float64 {
+		return testutil.ToFloat64(canaryCheckCounter.WithLabelValues(engine, outcome)) - initial
+	}
+}
+
+func TestDispatcherCountsMatchesAndMismatches(t *testing.T) {
+	const engine = "matches"
+	primary := &fakeDispatcher{members: map[string]v1.ResourceCheckResult_Membership{
+		"first":  v1.ResourceCheckResult_MEMBER,
+		"second": v1.ResourceCheckResult_MEMBER,
+	}}
+	canary := &fakeDispatcher{members: map[string]v1.ResourceCheckResult_Membership{
+		"first":  v1.ResourceCheckResult_MEMBER,
+		"second": v1.ResourceCheckResult_CAVEATED_MEMBER,
+		"third":  v1.ResourceCheckResult_NOT_MEMBER,
+	}}
+	d, wg := newTestDispatcher(engine, primary, canary)
+	matches := outcomeCounter(engine, outcomeMatch)
+	mismatches := outcomeCounter(engine, outcomeMismatch)
+
+	checks := []*v1.DispatchCheckRequest{
+		newCheckRequest(v1.DispatchCheckRequest_REQUIRE_ALL_RESULTS, "first", "third"),
+		newCheckRequest(v1.DispatchCheckRequest_REQUIRE_ALL_RESULTS, "first", "second"),
+		// Either dispatcher may return any member when a single result is allowed.
+		newCheckRequest(v1.DispatchCheckRequest_ALLOW_SINGLE_RESULT, "first", "second"),
+	}
+	wg.Add(len(checks))
+	for _, req := range checks {
+		resp, err := d.DispatchCheck(context.Background(), req)
+		require.NoError(t, err)
+
+		// The results of the primary dispatcher are returned.
+		expected, err := primary.DispatchCheck(context.Background(), req)
+		require.NoError(t, err)
+		require.True(t, expected.EqualVT(resp))
+	}
+	wg.Wait()
+
+	require.Equal(t, 2.0, matches())
+	require.Equal(t, 1.0, mismatches())
+
+	require.Equal(t, []string{"second"}, differingResourceIDs(checks[1], must(primary.DispatchCheck(context.Background(), checks[1])), must(canary.DispatchCheck(context.Background(), checks[1]))))
+}
+
+func TestDispatcherCountsCanaryErrors(t *testing.T) {
+	const engine = "errors"
+	primary := &fakeDispatcher{}
+	d, wg := newTestDispatcher(engine, primary, &fakeDispatcher{err: errors.New("canary failure")})
+	failures := outcomeCounter(engine, outcomeError)
+
+	wg.Add(1)
+	_, err := d.DispatchCheck(context.Background(), newCheckRequest(v1.DispatchCheckRequest_REQUIRE_ALL_RESULTS, "first"))
+	require.NoError(t, err)
+	wg.Wait()
+	require.Equal(t, 1.0, failures())
+
+	// Failed and debugged checks are not evaluated by the canary dispatcher.
+	primary.err = errors.New("primary failure")
+	_, err = d.DispatchCheck(context.Background(), newCheckRequest(v1.DispatchCheckRequest_REQUIRE_ALL_RESULTS, "first"))
+	require.Error(t, err)
+
+	primary.err = nil
+	debugReq := newCheckRequest(v1.DispatchCheckRequest_REQUIRE_ALL_RESULTS, "first")
+	debugReq.Debug = v1.DispatchCheckRequest_ENABLE_BASIC_DEBUGGING
+	_, err = d.DispatchCheck(context.Background(), debugReq)
+	require.NoError(t, err)
+	require.Equal(t, 1.0, failures())
+}
+
+// blockingDispatcher blocks checks until released.
+type blockingDispatcher struct {
+	fakeDispatcher
+	release chan struct{}
+}
+
+func (d *blockingDispatcher) DispatchCheck(ctx context.Context, req *v1.DispatchCheckRequest) (*v1.DispatchCheckResponse, error) {
+	<-d.release
+	return d.fakeDispatcher.DispatchCheck(ctx, req)
+}
+
+func TestDispatcherSkipsChecksBeyondMaxInFlight(t *testing.T) {
+	const engine = "skipped"
+	canary := &blockingDispatcher{release: make(chan struct{})}
+	d := NewDispatcher(&fakeDispatcher{}, canary, Config{Engine: engine, Percent: 100, MaxInFlight: 1})
+	var wg sync.WaitGroup
+	d.done = wg.Done
+	skipped := outcomeCounter(engine, outcomeSkipped)
+	matches := outcomeCounter(engine, outcomeMatch)
+
+	wg.Add(1)
+	for range 3 {
+		_, err := d.DispatchCheck(context.Background(), newCheckRequest(v1.DispatchCheckRequest_REQUIRE_ALL_RESULTS, "first"))
+		require.NoError(t, err)
+	}
+	require.Equal(t, 2.0, skipped())
+
+	close(canary.release)
+	wg.Wait()
+	require.Equal(t, 1.0, matches())
+}
+
+func TestDispatcherSamplesPercentage(t *testing.T) {
+	const engine = "disabled"
+	d := NewDispatcher(&fakeDispatcher{}, &fakeDispatcher{err: errors.New("canary failure")}, Config{Engine: engine, Percent: 0})
+	failures := outcomeCounter(engine, outcomeError)
+	for range 100 {
+		_, err := d.DispatchCheck(context.Background(), newCheckRequest(v1.DispatchCheckRequest_REQUIRE_ALL_RESULTS, "first"))
+		require.NoError(t, err)
+	}
+	require.Zero(t, failures())
+}
+
+func TestDispatcherClosesBothDispatchers(t *testing.T) {
+	primary, canary := &fakeDispatcher{}, &fakeDispatcher{}
+	require.NoError(t, NewDispatcher(primary, canary, Config{Engine: "closed"}).Close())
+	require.True(t, primary.closed)
+	require.True(t, canary.closed)
+}
+
+func must[T any](value T, err error) T {
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
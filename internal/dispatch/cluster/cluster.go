@@ -5,8 +5,11 @@ import (
 
 	"github.com/authzed/spicedb/internal/dispatch"
 	"github.com/authzed/spicedb/internal/dispatch/caching"
+	"github.com/authzed/spicedb/internal/dispatch/flattened"
 	"github.com/authzed/spicedb/internal/dispatch/graph"
 	"github.com/authzed/spicedb/internal/dispatch/keys"
+	"github.com/authzed/spicedb/internal/dispatch/limits"
+	"github.com/authzed/spicedb/internal/dispatch/singleflight"
 	log "github.com/authzed/spicedb/internal/logging"
 	"github.com/authzed/spicedb/pkg/cache"
 )
@@ -18,9 +21,12 @@ type optionState struct {
 	metricsEnabled        bool
 	prometheusSubsystem   string
 	cache                 cache.Cache[keys.DispatchCacheKey, any]
+	negativeCheckCache    cache.Cache[keys.DispatchCacheKey, any]
 	concurrencyLimits     graph.ConcurrencyLimits
 	remoteDispatchTimeout time.Duration
 	dispatchChunkSize     uint16
+	flattenedMemberships  *flattened.Memberships
+	typeLimits            limits.Limits
 }
 
 // MetricsEnabled enables issuing prometheus metrics
@@ -44,6 +50,14 @@ func Cache(c cache.Cache[keys.DispatchCacheKey, any]) Option {
 	}
 }
 
+// NegativeCheckCache sets a separate cache for the check results of the remote dispatcher for which
+// no resources have permission. If unset, such results are held in the cache set by Cache.
+func NegativeCheckCache(c cache.Cache[keys.DispatchCacheKey, any]) Option {
+	return func(state *optionState) {
+		state.negativeCheckCache = c
+	}
+}
+
 // ConcurrencyLimits sets the max number of goroutines per operation
 func ConcurrencyLimits(limits graph.ConcurrencyLimits) Option {
 	return func(state *optionState) {
@@ -66,6 +80,22 @@ func RemoteDispatchTimeout(remoteDispatchTimeout time.Duration) Option {
 	}
 }
 
+// FlattenedMemberships sets the flattened group memberships from which checks of group relations
+// are answered when possible, rather than resolved.
+func FlattenedMemberships(memberships *flattened.Memberships) Option {
+	return func(state *optionState) {
+		state.flattenedMemberships = memberships
+	}
+}
+
+// TypeLimits sets the limits on the depth and duration of the dispatches of specific object types
+// and permissions resolved by this dispatcher.
+func TypeLimits(typeLimits limits.Limits) Option {
+	return func(state *optionState) {
+		state.typeLimits = typeLimits
+	}
+}
+
 // NewClusterDispatcher takes a dispatcher (such as one created by
 // combined.NewDispatcher) and returns a cluster dispatcher suitable for use as
 // the dispatcher for the dispatch grpc server.
@@ -81,6 +111,9 @@ func NewClusterDispatcher(dispatch dispatch.Dispatcher, options ...Option) (disp
 		log.Warn().Msgf("ClusterDispatcher: dispatchChunkSize not set, defaulting to %d", chunkSize)
 	}
 	clusterDispatch := graph.NewDispatcher(dispatch, opts.concurrencyLimits, opts.dispatchChunkSize)
+	if len(opts.typeLimits) > 0 {
+		clusterDispatch = limits.NewDispatcher(clusterDispatch, opts.typeLimits)
+	}
 
 	if opts.prometheusSubsystem == "" {
 		opts.prometheusSubsystem = "dispatch"
@@ -90,6 +123,15 @@ func NewClusterDispatcher(dispatch dispatch.Dispatcher, options ...Option) (disp
 	if err != nil {
 		return nil, err
 	}
-	cachingClusterDispatch.SetDelegate(clusterDispatch)
+	if opts.negativeCheckCache != nil {
+		cachingClusterDispatch.SetNegativeCheckCache(opts.negativeCheckCache)
+	}
+	// Identical requests arriving from other nodes in the same quantization window, such as
+	// checks on a heavily-accessed resource, are resolved only once.
+	delegate := singleflight.NewForClusterRequests(clusterDispatch, &keys.CanonicalKeyHandler{})
+	if opts.flattenedMemberships != nil {
+		delegate = flattened.NewDispatcher(delegate, opts.flattenedMemberships)
+	}
+	cachingClusterDispatch.SetDelegate(delegate)
 	return cachingClusterDispatch, nil
 }
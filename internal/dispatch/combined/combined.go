@@ -4,6 +4,7 @@ package combined
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -13,13 +14,16 @@ import (
 
 	"github.com/authzed/spicedb/internal/dispatch"
 	"github.com/authzed/spicedb/internal/dispatch/caching"
+	"github.com/authzed/spicedb/internal/dispatch/flattened"
 	"github.com/authzed/spicedb/internal/dispatch/graph"
 	"github.com/authzed/spicedb/internal/dispatch/keys"
+	"github.com/authzed/spicedb/internal/dispatch/limits"
 	"github.com/authzed/spicedb/internal/dispatch/remote"
 	"github.com/authzed/spicedb/internal/dispatch/singleflight"
 	"github.com/authzed/spicedb/internal/grpchelpers"
 	log "github.com/authzed/spicedb/internal/logging"
 	"github.com/authzed/spicedb/pkg/cache"
+	"github.com/authzed/spicedb/pkg/datastore"
 	v1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
 )
 
@@ -34,11 +38,20 @@ type optionState struct {
 	grpcPresharedKey       string
 	grpcDialOpts           []grpc.DialOption
 	cache                  cache.Cache[keys.DispatchCacheKey, any]
+	negativeCheckCache     cache.Cache[keys.DispatchCacheKey, any]
 	concurrencyLimits      graph.ConcurrencyLimits
 	remoteDispatchTimeout  time.Duration
 	secondaryUpstreamAddrs map[string]string
 	secondaryUpstreamExprs map[string]string
 	dispatchChunkSize      uint16
+	hedgingEnabled         bool
+	hedgingInitialDelay    time.Duration
+	hedgingQuantile        float64
+	flattenedMemberships   *flattened.Memberships
+	cacheWarmingEntries    uint32
+	cacheWarmingTimeout    time.Duration
+	cacheWarmingDatastore  datastore.Datastore
+	typeLimits             limits.Limits
 }
 
 // MetricsEnabled enables issuing prometheus metrics
@@ -110,6 +123,14 @@ func Cache(c cache.Cache[keys.DispatchCacheKey, any]) Option {
 	}
 }
 
+// NegativeCheckCache sets a separate cache for the check results of the dispatcher for which
+// no resources have permission. If unset, such results are held in the cache set by Cache.
+func NegativeCheckCache(c cache.Cache[keys.DispatchCacheKey, any]) Option {
+	return func(state *optionState) {
+		state.negativeCheckCache = c
+	}
+}
+
 // ConcurrencyLimits sets the max number of goroutines per operation
 func ConcurrencyLimits(limits graph.ConcurrencyLimits) Option {
 	return func(state *optionState) {
@@ -132,6 +153,45 @@ func RemoteDispatchTimeout(remoteDispatchTimeout time.Duration) Option {
 	}
 }
 
+// RemoteDispatchHedging enables hedging of remote dispatches which have not returned within
+// the given quantile of observed dispatch latency, using the initial delay until sufficient
+// latency statistics have been collected.
+func RemoteDispatchHedging(initialDelay time.Duration, quantile float64) Option {
+	return func(state *optionState) {
+		state.hedgingEnabled = true
+		state.hedgingInitialDelay = initialDelay
+		state.hedgingQuantile = quantile
+	}
+}
+
+// FlattenedMemberships sets the flattened group memberships from which checks of group relations
+// are answered when possible, rather than redispatched.
+func FlattenedMemberships(memberships *flattened.Memberships) Option {
+	return func(state *optionState) {
+		state.flattenedMemberships = memberships
+	}
+}
+
+// TypeLimits sets the limits on the depth and duration of the dispatches of specific object types
+// and permissions resolved by this dispatcher.
+func TypeLimits(typeLimits limits.Limits) Option {
+	return func(state *optionState) {
+		state.typeLimits = typeLimits
+	}
+}
+
+// CacheWarming enables counting the cache hits of the hottest entries of the cache, so that they
+// can be dumped to peers, and warming the cache on startup with up to the given number of the
+// hottest entries of a peer of the upstream, for at most the timeout. The datastore is used to
+// compute the cache keys of the warmed entries.
+func CacheWarming(entries uint32, timeout time.Duration, ds datastore.Datastore) Option {
+	return func(state *optionState) {
+		state.cacheWarmingEntries = entries
+		state.cacheWarmingTimeout = timeout
+		state.cacheWarmingDatastore = ds
+	}
+}
+
 // NewDispatcher initializes a Dispatcher that caches and redispatches
 // optionally to the provided upstream.
 func NewDispatcher(options ...Option) (dispatch.Dispatcher, error) {
@@ -149,6 +209,13 @@ func NewDispatcher(options ...Option) (dispatch.Dispatcher, error) {
 	if err != nil {
 		return nil, err
 	}
+	if opts.negativeCheckCache != nil {
+		cachingRedispatch.SetNegativeCheckCache(opts.negativeCheckCache)
+	}
+	if opts.cacheWarmingEntries > 0 {
+		// Track more entries than are dumped, so that newly hot entries can displace older ones.
+		cachingRedispatch.TrackHotEntries(2 * int(opts.cacheWarmingEntries))
+	}
 
 	chunkSize := opts.dispatchChunkSize
 	if chunkSize == 0 {
@@ -156,10 +223,18 @@ func NewDispatcher(options ...Option) (dispatch.Dispatcher, error) {
 		log.Warn().Msgf("CombinedDispatcher: dispatchChunkSize not set, defaulting to %d", chunkSize)
 	}
 	redispatch := graph.NewDispatcher(cachingRedispatch, opts.concurrencyLimits, chunkSize)
+	if len(opts.typeLimits) > 0 {
+		redispatch = limits.NewDispatcher(redispatch, opts.typeLimits)
+	}
 	redispatch = singleflight.New(redispatch, &keys.CanonicalKeyHandler{})
+	local := redispatch
 
 	// If an upstream is specified, create a cluster dispatcher.
 	if opts.upstreamAddr != "" {
+		if opts.hedgingEnabled && (opts.hedgingQuantile <= 0.0 || opts.hedgingQuantile >= 1.0) {
+			return nil, errors.New("remote dispatch hedging quantile must be in the range (0.0-1.0) exclusive")
+		}
+
 		if opts.upstreamCAPath != "" {
 			customCertOpt, err := grpcutil.WithCustomCerts(grpcutil.VerifyCA, opts.upstreamCAPath)
 			if err != nil {
@@ -203,8 +278,22 @@ func NewDispatcher(options ...Option) (dispatch.Dispatcher, error) {
 		redispatch = remote.NewClusterDispatcher(v1.NewDispatchServiceClient(conn), conn, remote.ClusterDispatcherConfig{
 			KeyHandler:             &keys.CanonicalKeyHandler{},
 			DispatchOverallTimeout: opts.remoteDispatchTimeout,
+			HedgingEnabled:         opts.hedgingEnabled,
+			HedgingInitialDelay:    opts.hedgingInitialDelay,
+			HedgingQuantile:        opts.hedgingQuantile,
 		}, secondaryClients, secondaryExprs)
 		redispatch = singleflight.New(redispatch, &keys.CanonicalKeyHandler{})
+
+		// Datastore forks are only held by this node, so dispatches at their revisions stay local.
+		redispatch = &forkRoutingDispatcher{local: local, cluster: redispatch}
+
+		if opts.cacheWarmingEntries > 0 && opts.cacheWarmingDatastore != nil {
+			go warmCacheFromPeer(v1.NewCacheWarmingServiceClient(conn), cachingRedispatch, opts.cacheWarmingDatastore, opts.cacheWarmingEntries, opts.cacheWarmingTimeout)
+		}
+	}
+
+	if opts.flattenedMemberships != nil {
+		redispatch = flattened.NewDispatcher(redispatch, opts.flattenedMemberships)
 	}
 
 	cachingRedispatch.SetDelegate(redispatch)
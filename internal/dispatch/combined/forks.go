@@ -0,0 +1,48 @@
+package combined
+
+import (
+	"context"
+
+	"github.com/authzed/spicedb/internal/datastore/fork"
+	"github.com/authzed/spicedb/internal/dispatch"
+	v1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+)
+
+// forkRoutingDispatcher dispatches the requests at the revisions of datastore forks to the local
+// dispatcher, as forks are only held by the node which created them, and all other requests to
+// the cluster dispatcher.
+type forkRoutingDispatcher struct {
+	local   dispatch.Dispatcher
+	cluster dispatch.Dispatcher
+}
+
+func (d *forkRoutingDispatcher) route(req dispatch.DispatchableRequest) dispatch.Dispatcher {
+	if fork.IsForkRevision(req.GetMetadata().GetAtRevision()) {
+		return d.local
+	}
+	return d.cluster
+}
+
+func (d *forkRoutingDispatcher) DispatchCheck(ctx context.Context, req *v1.DispatchCheckRequest) (*v1.DispatchCheckResponse, error) {
+	return d.route(req).DispatchCheck(ctx, req)
+}
+
+func (d *forkRoutingDispatcher) DispatchExpand(ctx context.Context, req *v1.DispatchExpandRequest) (*v1.DispatchExpandResponse, error) {
+	return d.route(req).DispatchExpand(ctx, req)
+}
+
+func (d *forkRoutingDispatcher) DispatchLookupResources2(req *v1.DispatchLookupResources2Request, stream dispatch.LookupResources2Stream) error {
+	return d.route(req).DispatchLookupResources2(req, stream)
+}
+
+func (d *forkRoutingDispatcher) DispatchLookupSubjects(req *v1.DispatchLookupSubjectsRequest, stream dispatch.LookupSubjectsStream) error {
+	return d.route(req).DispatchLookupSubjects(req, stream)
+}
+
+func (d *forkRoutingDispatcher) Close() error {
+	return d.cluster.Close()
+}
+
+func (d *forkRoutingDispatcher) ReadyState() dispatch.ReadyState {
+	return d.cluster.ReadyState()
+}
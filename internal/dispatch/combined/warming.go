@@ -0,0 +1,88 @@
+package combined
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/authzed/consistent"
+	"github.com/google/uuid"
+
+	"github.com/authzed/spicedb/internal/dispatch/caching"
+	log "github.com/authzed/spicedb/internal/logging"
+	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
+	"github.com/authzed/spicedb/pkg/datastore"
+	v1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+)
+
+// cacheWarmingAttempts is the number of peers from which a dump of hot cache entries is requested,
+// until a non-empty one is received. As the peer is chosen through the hashring, a request may be
+// routed back to the node itself, whose cache is still empty.
+const cacheWarmingAttempts = 3
+
+// defaultCacheWarmingTimeout is the time allowed for warming the cache, if unspecified.
+const defaultCacheWarmingTimeout = 30 * time.Second
+
+// warmCacheFromPeer pre-populates the cache of the dispatcher with the hottest entries of a peer,
+// reducing the latency of the dispatches of a newly started node.
+func warmCacheFromPeer(client v1.CacheWarmingServiceClient, cd *caching.Dispatcher, ds datastore.Datastore, limit uint32, timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = defaultCacheWarmingTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	ctx = datastoremw.ContextWithDatastore(ctx, ds)
+
+	started := time.Now()
+	for attempt := 0; attempt < cacheWarmingAttempts; attempt++ {
+		warmed, err := warmCacheFromRandomPeer(ctx, client, cd, limit)
+		if err != nil {
+			log.Ctx(ctx).Warn().Err(err).Int("attempt", attempt).Msg("error warming dispatch cache from peer")
+			if ctx.Err() != nil {
+				return
+			}
+			continue
+		}
+
+		if warmed > 0 {
+			log.Ctx(ctx).Info().Int("entries", warmed).Dur("duration", time.Since(started)).Msg("warmed dispatch cache from peer")
+			return
+		}
+	}
+
+	log.Ctx(ctx).Info().Msg("no peer had dispatch cache entries to warm from")
+}
+
+// warmCacheFromRandomPeer warms the cache from the peer to which a random key is hashed, returning
+// the number of entries warmed.
+func warmCacheFromRandomPeer(ctx context.Context, client v1.CacheWarmingServiceClient, cd *caching.Dispatcher, limit uint32) (int, error) {
+	peerKey, err := uuid.New().MarshalBinary()
+	if err != nil {
+		return 0, err
+	}
+
+	stream, err := client.DumpHotCacheEntries(context.WithValue(ctx, consistent.CtxKey, peerKey), &v1.DumpHotCacheEntriesRequest{Limit: limit})
+	if err != nil {
+		return 0, err
+	}
+
+	warmed := 0
+	for {
+		entry, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return warmed, nil
+		}
+		if err != nil {
+			return warmed, err
+		}
+
+		// An entry may reference a schema not yet known to this node, in which case it is skipped.
+		if err := cd.WarmCache(ctx, entry); err != nil {
+			log.Ctx(ctx).Debug().Err(err).Msg("skipping dispatch cache entry from peer")
+			continue
+		}
+		warmed++
+	}
+}
@@ -0,0 +1,128 @@
+package combined
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/authzed/spicedb/internal/datastore/dsfortesting"
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/internal/dispatch/caching"
+	"github.com/authzed/spicedb/internal/dispatch/keys"
+	"github.com/authzed/spicedb/internal/grpchelpers"
+	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
+	"github.com/authzed/spicedb/internal/testfixtures"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	dispatchv1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// fakeCacheWarmingServer serves an empty dump to the first request, as would the node itself
+// when the request is routed back to it, and its entries afterward.
+type fakeCacheWarmingServer struct {
+	dispatchv1.UnimplementedCacheWarmingServiceServer
+
+	requests atomic.Int32
+	entries  []*dispatchv1.DumpHotCacheEntriesResponse
+}
+
+func (fcws *fakeCacheWarmingServer) DumpHotCacheEntries(req *dispatchv1.DumpHotCacheEntriesRequest, stream dispatchv1.CacheWarmingService_DumpHotCacheEntriesServer) error {
+	if fcws.requests.Add(1) == 1 {
+		return nil
+	}
+
+	for _, entry := range fcws.entries[:min(int(req.Limit), len(fcws.entries))] {
+		if err := stream.Send(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestWarmCacheFromPeer(t *testing.T) {
+	require := require.New(t)
+
+	rawDS, err := dsfortesting.NewMemDBDatastoreForTesting(0, 0, memdb.DisableGC)
+	require.NoError(err)
+
+	ds, revision := testfixtures.DatastoreFromSchemaAndTestRelationships(rawDS, `
+		definition user {}
+
+		definition resource {
+			relation viewer: user
+			permission view = viewer
+		}
+	`, nil, require)
+
+	checkRequest := &dispatchv1.DispatchCheckRequest{
+		ResourceRelation: &core.RelationReference{Namespace: "resource", Relation: "view"},
+		ResourceIds:      []string{"someresource"},
+		Subject:          tuple.MustParseSubjectONR("user:tom").ToCoreONR(),
+		Metadata: &dispatchv1.ResolverMeta{
+			AtRevision:     revision.String(),
+			DepthRemaining: 50,
+		},
+	}
+	request, err := checkRequest.MarshalVT()
+	require.NoError(err)
+	response, err := (&dispatchv1.DispatchCheckResponse{
+		ResultsByResourceId: map[string]*dispatchv1.ResourceCheckResult{
+			"someresource": {Membership: dispatchv1.ResourceCheckResult_MEMBER},
+		},
+		Metadata: &dispatchv1.ResponseMeta{DepthRequired: 1},
+	}).MarshalVT()
+	require.NoError(err)
+
+	peer := &fakeCacheWarmingServer{entries: []*dispatchv1.DumpHotCacheEntriesResponse{{
+		RequestKind: dispatchv1.DumpHotCacheEntriesResponse_REQUEST_KIND_CHECK,
+		Request:     request,
+		Responses:   [][]byte{response},
+		HitCount:    5,
+	}}}
+
+	listener := bufconn.Listen(humanize.MiByte)
+	s := grpc.NewServer()
+	dispatchv1.RegisterCacheWarmingServiceServer(s, peer)
+	go func() {
+		// Ignore any errors
+		_ = s.Serve(listener)
+	}()
+
+	conn, err := grpchelpers.DialAndWait(
+		context.Background(),
+		"",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return listener.Dial()
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(err)
+	t.Cleanup(func() {
+		conn.Close()
+		listener.Close()
+		s.Stop()
+	})
+
+	cd, err := caching.NewCachingDispatcher(caching.DispatchTestCache(t), false, "", &keys.CanonicalKeyHandler{})
+	require.NoError(err)
+	t.Cleanup(func() { cd.Close() })
+
+	warmCacheFromPeer(dispatchv1.NewCacheWarmingServiceClient(conn), cd, ds, 10, time.Second)
+	require.Equal(int32(2), peer.requests.Load(), "the empty dump should have been retried")
+
+	// We have to sleep a while to let the cache converge
+	time.Sleep(10 * time.Millisecond)
+
+	// The warmed entry is served from the cache, without calling the delegate.
+	resp, err := cd.DispatchCheck(datastoremw.ContextWithDatastore(context.Background(), ds), checkRequest)
+	require.NoError(err)
+	require.Equal(dispatchv1.ResourceCheckResult_MEMBER, resp.ResultsByResourceId["someresource"].Membership)
+}
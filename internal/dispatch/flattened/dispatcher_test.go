@@ -0,0 +1,190 @@
+package flattened
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/common"
+	"github.com/authzed/spicedb/internal/datastore/dsfortesting"
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/internal/dispatch"
+	"github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/pkg/datastore"
+	v1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+const groupSchema = `
+	definition user {}
+
+	definition group {
+		relation member: user | user:* | group#member | user with somecaveat
+		relation banned: user
+	}
+
+	caveat somecaveat(somecondition int) {
+		somecondition == 42
+	}
+`
+
+func TestParseRelations(t *testing.T) {
+	relations, err := ParseRelations([]string{"group#member", "team#direct_member"})
+	require.NoError(t, err)
+	require.Equal(t, []tuple.RelationReference{
+		{ObjectType: "group", Relation: "member"},
+		{ObjectType: "team", Relation: "direct_member"},
+	}, relations)
+
+	for _, invalid := range []string{"group", "group#", "#member"} {
+		_, err := ParseRelations([]string{invalid})
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestDispatcherAnswersNestedGroups(t *testing.T) {
+	ds, revision := newGroupDatastore(t, []tuple.Relationship{
+		tuple.MustParse("group:engineering#member@user:alice"),
+		tuple.MustParse("group:backend#member@group:engineering#member"),
+		tuple.MustParse("group:everyone#member@group:backend#member"),
+		tuple.MustParse("group:public#member@user:*"),
+		tuple.MustParse("group:admins#member@user:bob"),
+	})
+	disp, delegated := newTestDispatcher(t, ds, revision)
+
+	resp, err := disp.DispatchCheck(context.Background(), checkRequest(revision, "alice", "engineering", "backend", "everyone", "public", "admins"))
+	require.NoError(t, err)
+	require.Zero(t, delegated.Load())
+	require.ElementsMatch(t, []string{"engineering", "backend", "everyone", "public"}, memberIDs(resp))
+
+	// Changes are reflected once the watch has checkpointed them.
+	updated, err := common.WriteRelationships(context.Background(), ds, tuple.UpdateOperationDelete, tuple.MustParse("group:backend#member@group:engineering#member"))
+	require.NoError(t, err)
+	waitForRevision(t, disp, updated)
+
+	resp, err = disp.DispatchCheck(context.Background(), checkRequest(updated, "alice", "engineering", "backend", "everyone"))
+	require.NoError(t, err)
+	require.Zero(t, delegated.Load())
+	require.ElementsMatch(t, []string{"engineering"}, memberIDs(resp))
+
+	// Revisions before the change cannot be answered from the memberships anymore.
+	_, err = disp.DispatchCheck(context.Background(), checkRequest(revision, "alice", "backend"))
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), delegated.Load())
+}
+
+func TestDispatcherDelegates(t *testing.T) {
+	ds, revision := newGroupDatastore(t, []tuple.Relationship{
+		tuple.MustParse("group:engineering#member@user:alice"),
+		tuple.MustParse("group:admins#banned@user:bob"),
+	})
+	disp, delegated := newTestDispatcher(t, ds, revision)
+
+	untracked := checkRequest(revision, "bob", "admins")
+	untracked.ResourceRelation = tuple.RR("group", "banned").ToCoreRR()
+
+	subjectSet := checkRequest(revision, "alice", "engineering")
+	subjectSet.Subject = tuple.CoreONR("group", "engineering", "member")
+
+	debugging := checkRequest(revision, "alice", "engineering")
+	debugging.Debug = v1.DispatchCheckRequest_ENABLE_BASIC_DEBUGGING
+
+	for _, req := range []*v1.DispatchCheckRequest{untracked, subjectSet, debugging} {
+		_, err := disp.DispatchCheck(context.Background(), req)
+		require.NoError(t, err)
+	}
+	require.Equal(t, uint64(3), delegated.Load())
+
+	// A caveated relationship prevents the relation from being flattened.
+	updated, err := common.WriteRelationships(context.Background(), ds, tuple.UpdateOperationTouch, tuple.MustParse("group:engineering#member@user:carol[somecaveat]"))
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		_, err := disp.DispatchCheck(context.Background(), checkRequest(updated, "alice", "engineering"))
+		require.NoError(t, err)
+		return delegated.Load() == 4
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func newGroupDatastore(t *testing.T, relationships []tuple.Relationship) (datastore.Datastore, datastore.Revision) {
+	rawDS, err := dsfortesting.NewMemDBDatastoreForTesting(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+
+	return testfixtures.DatastoreFromSchemaAndTestRelationships(rawDS, groupSchema, relationships, require.New(t))
+}
+
+func newTestDispatcher(t *testing.T, ds datastore.Datastore, revision datastore.Revision) (*Dispatcher, *atomic.Uint64) {
+	memberships := NewMemberships(ds, []tuple.RelationReference{{ObjectType: "group", Relation: "member"}}, 10*time.Millisecond, 0)
+	memberships.Start(context.Background())
+	t.Cleanup(func() {
+		require.NoError(t, memberships.Close())
+	})
+
+	delegated := &atomic.Uint64{}
+	disp := NewDispatcher(countingDispatcher{delegated}, memberships).(*Dispatcher)
+	waitForRevision(t, disp, revision)
+	return disp, delegated
+}
+
+// waitForRevision waits until the memberships can answer checks at the revision.
+func waitForRevision(t *testing.T, disp *Dispatcher, revision datastore.Revision) {
+	require.Eventually(t, func() bool {
+		_, ok := disp.memberships.isMember(tuple.RelationReference{ObjectType: "group", Relation: "member"}, revision.String(), tuple.ONR("user", "alice", tuple.Ellipsis))
+		return ok
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func checkRequest(revision datastore.Revision, userID string, groupIDs ...string) *v1.DispatchCheckRequest {
+	return &v1.DispatchCheckRequest{
+		ResourceRelation: tuple.RR("group", "member").ToCoreRR(),
+		ResourceIds:      groupIDs,
+		Subject:          tuple.CoreONR("user", userID, tuple.Ellipsis),
+		ResultsSetting:   v1.DispatchCheckRequest_REQUIRE_ALL_RESULTS,
+		Metadata: &v1.ResolverMeta{
+			AtRevision:     revision.String(),
+			DepthRemaining: 50,
+		},
+	}
+}
+
+func memberIDs(resp *v1.DispatchCheckResponse) []string {
+	ids := make([]string, 0, len(resp.ResultsByResourceId))
+	for id, result := range resp.ResultsByResourceId {
+		if result.Membership == v1.ResourceCheckResult_MEMBER {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+type countingDispatcher struct {
+	count *atomic.Uint64
+}
+
+func (c countingDispatcher) DispatchCheck(_ context.Context, _ *v1.DispatchCheckRequest) (*v1.DispatchCheckResponse, error) {
+	c.count.Add(1)
+	return &v1.DispatchCheckResponse{Metadata: &v1.ResponseMeta{}}, nil
+}
+
+func (c countingDispatcher) DispatchExpand(_ context.Context, _ *v1.DispatchExpandRequest) (*v1.DispatchExpandResponse, error) {
+	return &v1.DispatchExpandResponse{}, nil
+}
+
+func (c countingDispatcher) DispatchLookupResources2(_ *v1.DispatchLookupResources2Request, _ dispatch.LookupResources2Stream) error {
+	return nil
+}
+
+func (c countingDispatcher) DispatchLookupSubjects(_ *v1.DispatchLookupSubjectsRequest, _ dispatch.LookupSubjectsStream) error {
+	return nil
+}
+
+func (c countingDispatcher) Close() error {
+	return nil
+}
+
+func (c countingDispatcher) ReadyState() dispatch.ReadyState {
+	return dispatch.ReadyState{IsReady: true}
+}
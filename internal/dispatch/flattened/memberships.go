@@ -0,0 +1,469 @@
+// Package flattened maintains the flattened transitive memberships of group relations from the
+// Watch API, allowing checks of deeply nested groups to be answered without recursive dispatches.
+package flattened
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// DefaultMaxRelationships is the default maximum number of relationships of a single relation held
+// by its flattened memberships.
+const DefaultMaxRelationships = 1_000_000
+
+const (
+	// maxMemoizedSubjects is the maximum number of subjects whose transitive groups are memoized
+	// per relation before the memoized groups are cleared.
+	maxMemoizedSubjects = 100_000
+
+	// restartDelay is the delay before the memberships are reloaded after the watch fails.
+	restartDelay = 5 * time.Second
+)
+
+var trackedRelationshipsGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: "spicedb",
+	Subsystem: "dispatch",
+	Name:      "flattened_group_relationships",
+	Help:      "number of relationships held by the flattened memberships of each group relation",
+}, []string{"relation"})
+
+// ParseRelations parses group relations of the form `namespace#relation`.
+func ParseRelations(values []string) ([]tuple.RelationReference, error) {
+	relations := make([]tuple.RelationReference, 0, len(values))
+	for _, value := range values {
+		namespace, relation, ok := strings.Cut(value, "#")
+		if !ok || namespace == "" || relation == "" {
+			return nil, fmt.Errorf("invalid group relation `%s`: expected `namespace#relation`", value)
+		}
+
+		relations = append(relations, tuple.RelationReference{ObjectType: namespace, Relation: relation})
+	}
+	return relations, nil
+}
+
+// Memberships maintains, for each of a set of group relations, the groups of which each subject is
+// transitively a member. A group relation is a relation whose subjects are either direct subjects
+// or subject sets of the relation itself, such as `group#member` with subjects `user` and
+// `group#member`.
+//
+// The memberships are loaded at the head revision and then updated from the Watch API. They are
+// only consulted for revisions at which they are known to be current: those at or after the last
+// change to the relations, and at or before the last checkpoint of the watch.
+type Memberships struct {
+	ds               datastore.Datastore
+	relations        []tuple.RelationReference
+	heartbeat        time.Duration
+	maxRelationships int
+
+	lock         sync.Mutex
+	ready        bool
+	validFrom    datastore.Revision
+	validThrough datastore.Revision
+	indexes      map[tuple.RelationReference]*relationIndex
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewMemberships creates the flattened memberships of the given group relations, which must be
+// started with Start. The watch checkpoints at the given heartbeat, and relations with more than
+// maxRelationships relationships are not flattened.
+func NewMemberships(ds datastore.Datastore, relations []tuple.RelationReference, heartbeat time.Duration, maxRelationships int) *Memberships {
+	if maxRelationships <= 0 {
+		maxRelationships = DefaultMaxRelationships
+	}
+
+	return &Memberships{
+		ds:               ds,
+		relations:        relations,
+		heartbeat:        heartbeat,
+		maxRelationships: maxRelationships,
+		done:             make(chan struct{}),
+	}
+}
+
+// Start loads the memberships and keeps them up to date in the background until Close is called.
+func (m *Memberships) Start(ctx context.Context) {
+	ctx, m.cancel = context.WithCancel(ctx)
+	go m.run(ctx)
+}
+
+// Close stops maintaining the memberships.
+func (m *Memberships) Close() error {
+	if m.cancel == nil {
+		return nil
+	}
+
+	m.cancel()
+	<-m.done
+	return nil
+}
+
+func (m *Memberships) run(ctx context.Context) {
+	defer close(m.done)
+
+	for {
+		err := m.sync(ctx)
+
+		m.lock.Lock()
+		m.ready = false
+		m.indexes = nil
+		m.lock.Unlock()
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		log.Warn().Err(err).Dur("delay", restartDelay).Msg("flattened group memberships watch failed; reloading")
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(restartDelay):
+		}
+	}
+}
+
+// sync loads the memberships at the head revision, then applies the changes from the watch until
+// it fails.
+func (m *Memberships) sync(ctx context.Context) error {
+	headRevision, err := m.ds.HeadRevision(ctx)
+	if err != nil {
+		return err
+	}
+
+	reader := m.ds.SnapshotReader(headRevision)
+	indexes := make(map[tuple.RelationReference]*relationIndex, len(m.relations))
+	for _, relation := range m.relations {
+		index := newRelationIndex(relation, m.maxRelationships)
+
+		definition, _, err := reader.ReadNamespaceByName(ctx, relation.ObjectType)
+		if err != nil && !errors.As(err, &datastore.NamespaceNotFoundError{}) {
+			return err
+		}
+		index.setDefinition(definition)
+
+		it, err := reader.QueryRelationships(ctx, datastore.RelationshipsFilter{
+			OptionalResourceType:     relation.ObjectType,
+			OptionalResourceRelation: relation.Relation,
+		})
+		if err != nil {
+			return err
+		}
+
+		for rel, err := range it {
+			if err != nil {
+				return err
+			}
+			index.apply(tuple.UpdateOperationTouch, rel)
+		}
+
+		indexes[relation] = index
+		trackedRelationshipsGauge.WithLabelValues(tuple.StringRR(relation)).Set(float64(index.relationships))
+		log.Info().Str("relation", tuple.StringRR(relation)).Int("relationships", index.relationships).Str("revision", headRevision.String()).Msg("loaded flattened group memberships")
+	}
+
+	m.lock.Lock()
+	m.indexes = indexes
+	m.validFrom = headRevision
+	m.validThrough = headRevision
+	m.ready = true
+	m.lock.Unlock()
+
+	changes, errchan := m.ds.Watch(ctx, headRevision, datastore.WatchOptions{
+		Content:            datastore.WatchRelationships | datastore.WatchSchema | datastore.WatchCheckpoints,
+		CheckpointInterval: m.heartbeat,
+	})
+	for {
+		select {
+		case change, ok := <-changes:
+			if !ok {
+				changes = nil
+				continue
+			}
+			m.applyChanges(change)
+
+		case err := <-errchan:
+			if err == nil {
+				return errors.New("watch closed")
+			}
+			return err
+		}
+	}
+}
+
+func (m *Memberships) applyChanges(change *datastore.RevisionChanges) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if change.IsCheckpoint {
+		m.validThrough = change.Revision
+		return
+	}
+
+	changed := false
+	for _, update := range change.RelationshipChanges {
+		index, ok := m.indexes[tuple.RelationReference{
+			ObjectType: update.Relationship.Resource.ObjectType,
+			Relation:   update.Relationship.Resource.Relation,
+		}]
+		if !ok {
+			continue
+		}
+
+		index.apply(update.Operation, update.Relationship)
+		changed = true
+	}
+
+	for _, definition := range change.ChangedDefinitions {
+		namespace, ok := definition.(*core.NamespaceDefinition)
+		if !ok {
+			continue
+		}
+
+		for relation, index := range m.indexes {
+			if relation.ObjectType == namespace.Name {
+				index.setDefinition(namespace)
+				changed = true
+			}
+		}
+	}
+
+	for _, deleted := range change.DeletedNamespaces {
+		for relation, index := range m.indexes {
+			if relation.ObjectType == deleted {
+				index.setDefinition(nil)
+				changed = true
+			}
+		}
+	}
+
+	if !changed {
+		return
+	}
+
+	// Until the next checkpoint, the changes at the revision may not all have been received, so
+	// the memberships are not consulted for any revision.
+	m.validFrom = change.Revision
+	for relation, index := range m.indexes {
+		trackedRelationshipsGauge.WithLabelValues(tuple.StringRR(relation)).Set(float64(index.relationships))
+	}
+}
+
+// isMember returns a function reporting whether the subject is transitively a member of a group
+// of the relation at the revision, or false if the memberships cannot answer for the relation,
+// subject or revision.
+func (m *Memberships) isMember(relation tuple.RelationReference, serializedRevision string, subject tuple.ObjectAndRelation) (func(groupID string) bool, bool) {
+	if subject.Relation != tuple.Ellipsis || subject.ObjectID == tuple.PublicWildcard {
+		return nil, false
+	}
+
+	revision, err := m.ds.RevisionFromString(serializedRevision)
+	if err != nil {
+		return nil, false
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if !m.ready || revision.LessThan(m.validFrom) || revision.GreaterThan(m.validThrough) {
+		return nil, false
+	}
+
+	index, ok := m.indexes[relation]
+	if !ok || !index.usable() {
+		return nil, false
+	}
+
+	groups := index.groupsOf(tuple.StringONRStrings(subject.ObjectType, subject.ObjectID, tuple.Ellipsis))
+	wildcardGroups := index.groupsOf(tuple.StringONRStrings(subject.ObjectType, tuple.PublicWildcard, tuple.Ellipsis))
+	return func(groupID string) bool {
+		if _, ok := groups[groupID]; ok {
+			return true
+		}
+		_, ok := wildcardGroups[groupID]
+		return ok
+	}, true
+}
+
+// relationIndex holds the direct memberships of a single group relation, along with the memoized
+// transitive groups of the subjects looked up.
+type relationIndex struct {
+	relation         tuple.RelationReference
+	maxRelationships int
+
+	// members maps each group ID to its direct subjects, keyed by their string form, and parents
+	// maps each subject to the IDs of the groups of which it is a direct member. A nested group
+	// is the subject set of the relation on the group.
+	members map[string]map[string]bool
+	parents map[string]map[string]struct{}
+
+	// closures memoizes the IDs of the groups of which each subject is transitively a member.
+	closures map[string]map[string]struct{}
+
+	// relationships is the number of relationships held, unsupported the number of them which
+	// prevent the relation from being flattened: caveated or expiring relationships, and subject
+	// sets of other relations.
+	relationships int
+	unsupported   int
+
+	// flattenable is whether the relation is defined without a userset rewrite, and overflowed
+	// whether it had more than maxRelationships relationships.
+	flattenable bool
+	overflowed  bool
+}
+
+func newRelationIndex(relation tuple.RelationReference, maxRelationships int) *relationIndex {
+	return &relationIndex{
+		relation:         relation,
+		maxRelationships: maxRelationships,
+		members:          map[string]map[string]bool{},
+		parents:          map[string]map[string]struct{}{},
+		closures:         map[string]map[string]struct{}{},
+	}
+}
+
+func (ri *relationIndex) usable() bool {
+	return ri.flattenable && !ri.overflowed && ri.unsupported == 0
+}
+
+func (ri *relationIndex) setDefinition(definition *core.NamespaceDefinition) {
+	ri.flattenable = false
+	for _, relation := range definition.GetRelation() {
+		if relation.Name == ri.relation.Relation {
+			ri.flattenable = relation.UsersetRewrite == nil
+		}
+	}
+}
+
+func (ri *relationIndex) apply(operation tuple.UpdateOperation, rel tuple.Relationship) {
+	if ri.overflowed {
+		return
+	}
+
+	groupID := rel.Resource.ObjectID
+	subject := tuple.StringONR(rel.Subject)
+	existingUnsupported, exists := ri.members[groupID][subject]
+
+	if exists {
+		ri.relationships--
+		if existingUnsupported {
+			ri.unsupported--
+		}
+		delete(ri.members[groupID], subject)
+		if len(ri.members[groupID]) == 0 {
+			delete(ri.members, groupID)
+		}
+		delete(ri.parents[subject], groupID)
+		if len(ri.parents[subject]) == 0 {
+			delete(ri.parents, subject)
+		}
+	}
+
+	if operation != tuple.UpdateOperationDelete {
+		unsupported := rel.OptionalCaveat != nil || rel.OptionalExpiration != nil ||
+			(rel.Subject.Relation != tuple.Ellipsis && (rel.Subject.ObjectType != ri.relation.ObjectType || rel.Subject.Relation != ri.relation.Relation))
+
+		if _, ok := ri.members[groupID]; !ok {
+			ri.members[groupID] = map[string]bool{}
+		}
+		ri.members[groupID][subject] = unsupported
+
+		if _, ok := ri.parents[subject]; !ok {
+			ri.parents[subject] = map[string]struct{}{}
+		}
+		ri.parents[subject][groupID] = struct{}{}
+
+		ri.relationships++
+		if unsupported {
+			ri.unsupported++
+		}
+	}
+
+	if ri.relationships > ri.maxRelationships {
+		log.Warn().Str("relation", tuple.StringRR(ri.relation)).Int("maximum", ri.maxRelationships).Msg("group relation has too many relationships to be flattened")
+		ri.overflowed = true
+		ri.members = nil
+		ri.parents = nil
+		ri.closures = nil
+		return
+	}
+
+	ri.invalidate(subject)
+}
+
+// invalidate removes the memoized groups of the subject and, if it is a nested group, of all its
+// transitive members.
+func (ri *relationIndex) invalidate(subject string) {
+	if len(ri.closures) == 0 {
+		return
+	}
+
+	seen := map[string]struct{}{}
+	pending := []string{subject}
+	for len(pending) > 0 {
+		current := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if _, ok := seen[current]; ok {
+			continue
+		}
+		seen[current] = struct{}{}
+
+		delete(ri.closures, current)
+		if groupID, ok := ri.nestedGroupID(current); ok {
+			for member := range ri.members[groupID] {
+				pending = append(pending, member)
+			}
+		}
+	}
+}
+
+// groupsOf returns the IDs of the groups of which the subject is transitively a member.
+func (ri *relationIndex) groupsOf(subject string) map[string]struct{} {
+	if groups, ok := ri.closures[subject]; ok {
+		return groups
+	}
+
+	groups := map[string]struct{}{}
+	pending := []string{subject}
+	for len(pending) > 0 {
+		current := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+
+		for groupID := range ri.parents[current] {
+			if _, ok := groups[groupID]; ok {
+				continue
+			}
+
+			groups[groupID] = struct{}{}
+			pending = append(pending, tuple.StringONRStrings(ri.relation.ObjectType, groupID, ri.relation.Relation))
+		}
+	}
+
+	if len(ri.closures) >= maxMemoizedSubjects {
+		clear(ri.closures)
+	}
+	ri.closures[subject] = groups
+	return groups
+}
+
+// nestedGroupID returns the ID of the group if the subject is a nested group of the relation.
+func (ri *relationIndex) nestedGroupID(subject string) (string, bool) {
+	withoutRelation, ok := strings.CutSuffix(subject, "#"+ri.relation.Relation)
+	if !ok {
+		return "", false
+	}
+
+	return strings.CutPrefix(withoutRelation, ri.relation.ObjectType+":")
+}
@@ -13,9 +13,11 @@ import (
 	"google.golang.org/grpc/status"
 
 	"github.com/authzed/spicedb/internal/dispatch"
+	"github.com/authzed/spicedb/internal/dispatch/inflight"
 	"github.com/authzed/spicedb/internal/graph"
 	log "github.com/authzed/spicedb/internal/logging"
 	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
+	"github.com/authzed/spicedb/internal/taskrunner"
 	"github.com/authzed/spicedb/pkg/datastore"
 	"github.com/authzed/spicedb/pkg/middleware/nodeid"
 	core "github.com/authzed/spicedb/pkg/proto/core/v1"
@@ -35,6 +37,18 @@ type ConcurrencyLimits struct {
 	ReachableResources uint16 `debugmap:"visible"`
 	LookupResources    uint16 `debugmap:"visible"`
 	LookupSubjects     uint16 `debugmap:"visible"`
+
+	// Adaptive, if non-nil, is the server-wide adaptive limit applied to the fan-out of
+	// sub-problems, in addition to the fixed limits above.
+	Adaptive *taskrunner.AdaptiveLimiter `debugmap:"hidden"`
+
+	// AdaptivePerRequest, if non-zero, is the maximum adaptive limit applied to the fan-out of
+	// sub-problems within a single request.
+	AdaptivePerRequest uint16 `debugmap:"visible"`
+
+	// Planner, if non-nil, orders the evaluation of the branches of union and intersection
+	// rewrites in checks by how often each has determined the result.
+	Planner *graph.EvaluationPlanner `debugmap:"hidden"`
 }
 
 const defaultConcurrencyLimit = 50
@@ -50,6 +64,9 @@ func (cl ConcurrencyLimits) MarshalZerologObject(e *zerolog.Event) {
 	e.Uint16("concurrency-limit-lookup-resources", cl.LookupResources)
 	e.Uint16("concurrency-limit-lookup-subjects", cl.LookupSubjects)
 	e.Uint16("concurrency-limit-reachable-resources", cl.ReachableResources)
+	e.Bool("concurrency-limit-adaptive", cl.Adaptive != nil)
+	e.Uint16("concurrency-limit-adaptive-per-request", cl.AdaptivePerRequest)
+	e.Bool("check-evaluation-planner", cl.Planner != nil)
 }
 
 func limitsOrDefaults(limits ConcurrencyLimits, overallDefaultLimit uint16) ConcurrencyLimits {
@@ -86,16 +103,19 @@ func NewLocalOnlyDispatcher(concurrencyLimit uint16, dispatchChunkSize uint16) d
 // NewLocalOnlyDispatcherWithLimits creates a dispatcher thatg consults with the graph to formulate a response
 // and has the defined concurrency limits per dispatch type.
 func NewLocalOnlyDispatcherWithLimits(concurrencyLimits ConcurrencyLimits, dispatchChunkSize uint16) dispatch.Dispatcher {
-	d := &localDispatcher{}
-
 	concurrencyLimits = limitsOrDefaults(concurrencyLimits, defaultConcurrencyLimit)
+	d := &localDispatcher{
+		adaptiveLimiter:    concurrencyLimits.Adaptive,
+		adaptivePerRequest: concurrencyLimits.AdaptivePerRequest,
+	}
+
 	chunkSize := dispatchChunkSize
 	if chunkSize == 0 {
 		chunkSize = 100
 		log.Warn().Msgf("LocalOnlyDispatcher: dispatchChunkSize not set, defaulting to %d", chunkSize)
 	}
 
-	d.checker = graph.NewConcurrentChecker(d, concurrencyLimits.Check, chunkSize)
+	d.checker = graph.NewConcurrentChecker(d, concurrencyLimits.Check, chunkSize, concurrencyLimits.Planner)
 	d.expander = graph.NewConcurrentExpander(d)
 	d.lookupSubjectsHandler = graph.NewConcurrentLookupSubjects(d, concurrencyLimits.LookupSubjects, chunkSize)
 	d.lookupResourcesHandler2 = graph.NewCursoredLookupResources2(d, d, concurrencyLimits.LookupResources, chunkSize)
@@ -113,7 +133,7 @@ func NewDispatcher(redispatcher dispatch.Dispatcher, concurrencyLimits Concurren
 		log.Warn().Msgf("Dispatcher: dispatchChunkSize not set, defaulting to %d", chunkSize)
 	}
 
-	checker := graph.NewConcurrentChecker(redispatcher, concurrencyLimits.Check, chunkSize)
+	checker := graph.NewConcurrentChecker(redispatcher, concurrencyLimits.Check, chunkSize, concurrencyLimits.Planner)
 	expander := graph.NewConcurrentExpander(redispatcher)
 	lookupSubjectsHandler := graph.NewConcurrentLookupSubjects(redispatcher, concurrencyLimits.LookupSubjects, chunkSize)
 	lookupResourcesHandler2 := graph.NewCursoredLookupResources2(redispatcher, redispatcher, concurrencyLimits.LookupResources, chunkSize)
@@ -123,6 +143,8 @@ func NewDispatcher(redispatcher dispatch.Dispatcher, concurrencyLimits Concurren
 		expander:                expander,
 		lookupSubjectsHandler:   lookupSubjectsHandler,
 		lookupResourcesHandler2: lookupResourcesHandler2,
+		adaptiveLimiter:         concurrencyLimits.Adaptive,
+		adaptivePerRequest:      concurrencyLimits.AdaptivePerRequest,
 	}
 }
 
@@ -131,6 +153,24 @@ type localDispatcher struct {
 	expander                *graph.ConcurrentExpander
 	lookupSubjectsHandler   *graph.ConcurrentLookupSubjects
 	lookupResourcesHandler2 *graph.CursoredLookupResources2
+
+	adaptiveLimiter    *taskrunner.AdaptiveLimiter
+	adaptivePerRequest uint16
+}
+
+// withAdaptiveLimiter returns a context carrying the adaptive limiter to be used for the fan-out
+// of the request's sub-problems. If the context already carries a limiter, the request is a
+// sub-problem of a request being dispatched on this node and the existing limiter is kept.
+func (ld *localDispatcher) withAdaptiveLimiter(ctx context.Context) context.Context {
+	if ld.adaptiveLimiter == nil || taskrunner.LimiterFromContext(ctx) != nil {
+		return ctx
+	}
+
+	if ld.adaptivePerRequest == 0 {
+		return taskrunner.ContextWithLimiter(ctx, ld.adaptiveLimiter)
+	}
+
+	return taskrunner.ContextWithLimiter(ctx, ld.adaptiveLimiter.NewRequestLimiter(uint32(ld.adaptivePerRequest)))
 }
 
 func (ld *localDispatcher) loadNamespace(ctx context.Context, nsName string, revision datastore.Revision) (*core.NamespaceDefinition, error) {
@@ -183,6 +223,11 @@ func (ld *localDispatcher) DispatchCheck(ctx context.Context, req *v1.DispatchCh
 		attribute.String("node-id", nodeID),
 	))
 	defer span.End()
+	if inflight.DefaultTracker.Enabled() {
+		defer inflight.DefaultTracker.Start(ctx, "check", resourceType, len(req.ResourceIds), tuple.StringCoreONR(req.Subject), req.GetMetadata().GetDepthRemaining())()
+	}
+
+	ctx = ld.withAdaptiveLimiter(ctx)
 
 	if err := dispatch.CheckDepth(ctx, req); err != nil {
 		if req.Debug != v1.DispatchCheckRequest_ENABLE_BASIC_DEBUGGING {
@@ -244,11 +289,12 @@ func (ld *localDispatcher) DispatchCheck(ctx context.Context, req *v1.DispatchCh
 					Namespace: req.ResourceRelation.Namespace,
 					Relation:  relation.Name,
 				},
-				ResourceIds: req.ResourceIds,
-				Subject:     req.Subject,
-				Metadata:    req.Metadata,
-				Debug:       req.Debug,
-				CheckHints:  req.CheckHints,
+				ResourceIds:         req.ResourceIds,
+				Subject:             req.Subject,
+				Metadata:            req.Metadata,
+				Debug:               req.Debug,
+				CheckHints:          req.CheckHints,
+				ExcludedCaveatNames: req.ExcludedCaveatNames,
 			},
 			Revision:             revision,
 			OriginalRelationName: req.ResourceRelation.Relation,
@@ -277,6 +323,9 @@ func (ld *localDispatcher) DispatchExpand(ctx context.Context, req *v1.DispatchE
 		attribute.String("node-id", nodeID),
 	))
 	defer span.End()
+	if inflight.DefaultTracker.Enabled() {
+		defer inflight.DefaultTracker.Start(ctx, "expand", tuple.StringCoreONR(req.ResourceAndRelation), 1, "", req.GetMetadata().GetDepthRemaining())()
+	}
 
 	if err := dispatch.CheckDepth(ctx, req); err != nil {
 		return &v1.DispatchExpandResponse{Metadata: emptyMetadata}, err
@@ -320,6 +369,11 @@ func (ld *localDispatcher) DispatchLookupResources2(
 		attribute.String("node-id", nodeID),
 	))
 	defer span.End()
+	if inflight.DefaultTracker.Enabled() {
+		defer inflight.DefaultTracker.Start(ctx, "lookupresources", tuple.StringCoreRR(req.ResourceRelation), 0, tuple.StringCoreONR(req.TerminalSubject), req.GetMetadata().GetDepthRemaining())()
+	}
+
+	ctx = ld.withAdaptiveLimiter(ctx)
 
 	if err := dispatch.CheckDepth(ctx, req); err != nil {
 		return err
@@ -360,6 +414,11 @@ func (ld *localDispatcher) DispatchLookupSubjects(
 		attribute.String("node-id", nodeID),
 	))
 	defer span.End()
+	if inflight.DefaultTracker.Enabled() {
+		defer inflight.DefaultTracker.Start(ctx, "lookupsubjects", resourceType, len(req.ResourceIds), subjectRelation, req.GetMetadata().GetDepthRemaining())()
+	}
+
+	ctx = ld.withAdaptiveLimiter(ctx)
 
 	if err := dispatch.CheckDepth(ctx, req); err != nil {
 		return err
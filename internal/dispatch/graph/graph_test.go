@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/authzed/spicedb/internal/graph"
+	"github.com/authzed/spicedb/internal/taskrunner"
 
 	"github.com/authzed/grpcutil"
 	"github.com/stretchr/testify/require"
@@ -60,3 +61,26 @@ func TestSharedConcurrencyLimits(t *testing.T) {
 	require.Equal(t, uint16(42), withDefaults.LookupSubjects)
 	require.Equal(t, uint16(42), withDefaults.ReachableResources)
 }
+
+func TestWithAdaptiveLimiter(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	ld := &localDispatcher{}
+	require.Nil(t, taskrunner.LimiterFromContext(ld.withAdaptiveLimiter(ctx)))
+
+	serverLimiter := taskrunner.NewAdaptiveLimiter(taskrunner.AdaptiveLimiterConfig{MaxLimit: 10})
+	ld = &localDispatcher{adaptiveLimiter: serverLimiter}
+	require.Same(t, serverLimiter, taskrunner.LimiterFromContext(ld.withAdaptiveLimiter(ctx)))
+
+	ld = &localDispatcher{adaptiveLimiter: serverLimiter, adaptivePerRequest: 5}
+	requestCtx := ld.withAdaptiveLimiter(ctx)
+	requestLimiter := taskrunner.LimiterFromContext(requestCtx)
+	require.NotNil(t, requestLimiter)
+	require.NotSame(t, serverLimiter, requestLimiter)
+	require.Equal(t, uint32(5), requestLimiter.Limit())
+
+	// Sub-problems dispatched within the request reuse the request's limiter.
+	require.Same(t, requestLimiter, taskrunner.LimiterFromContext(ld.withAdaptiveLimiter(requestCtx)))
+}
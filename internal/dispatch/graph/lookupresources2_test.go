@@ -403,6 +403,69 @@ func TestLookupResources2OverSchemaWithCursors(t *testing.T) {
 			genResourceIds("document", 510),
 			nil,
 		},
+		{
+			"partial exclusion",
+			`definition user {}
+		
+		 	 definition document {
+				relation banned: user
+				relation viewer: user
+				permission view = viewer - banned
+  			 }`,
+			joinTuples(
+				genRels("document", "viewer", "user", "tom", 1010),
+				genRels("document", "banned", "user", "tom", 510),
+			),
+			RR("document", "view"),
+			ONR("user", "tom", "..."),
+			nil,
+			genResourceIds("document", 1010)[510:],
+			nil,
+		},
+		{
+			"partial intersection",
+			`definition user {}
+		
+		 	 definition document {
+				relation editor: user
+				relation viewer: user
+				permission view = viewer & editor
+  			 }`,
+			joinTuples(
+				genRels("document", "viewer", "user", "tom", 1010),
+				genRelsWithOffset("document", "editor", "user", "tom", 505, 505),
+			),
+			RR("document", "view"),
+			ONR("user", "tom", "..."),
+			nil,
+			genResourceIds("document", 1010)[505:],
+			nil,
+		},
+		{
+			"partial exclusion over arrow",
+			`definition user {}
+
+			 definition folder {
+				relation banned: user
+				relation viewer: user
+				permission view = viewer - banned
+			 }
+		
+		 	 definition document {
+				relation folder: folder
+				permission view = folder->view
+  			 }`,
+			joinTuples(
+				genRels("folder", "viewer", "user", "tom", 1010),
+				genRels("folder", "banned", "user", "tom", 510),
+				genRelsWithSubjectIDs("document", "folder", "folder", genResourceIds("folder", 1010)),
+			),
+			RR("document", "view"),
+			ONR("user", "tom", "..."),
+			nil,
+			genResourceIds("document", 1010)[510:],
+			nil,
+		},
 		{
 			"union and excluded union",
 			`definition user {}
@@ -1482,6 +1545,20 @@ func genRelsWithCaveatAndSubjectRelation(resourceName string, relation string, s
 	return rels
 }
 
+// genRelsWithSubjectIDs generates a relationship from resource `{resourceName}-{i}` to each subject ID at index i.
+func genRelsWithSubjectIDs(resourceName string, relation string, subjectName string, subjectIDs []string) []tuple.Relationship {
+	rels := make([]tuple.Relationship, 0, len(subjectIDs))
+	for i, subjectID := range subjectIDs {
+		rels = append(rels, tuple.Relationship{
+			RelationshipReference: tuple.RelationshipReference{
+				Resource: ONR(resourceName, fmt.Sprintf("%s-%d", resourceName, i), relation),
+				Subject:  ONR(subjectName, subjectID, "..."),
+			},
+		})
+	}
+	return rels
+}
+
 func genResourceIds(resourceName string, number int) []string {
 	resourceIDs := make([]string, 0, number)
 	for i := 0; i < number; i++ {
@@ -4,6 +4,9 @@ package graph
 import (
 	defaults "github.com/creasty/defaults"
 	helpers "github.com/ecordell/optgen/helpers"
+
+	graph "github.com/authzed/spicedb/internal/graph"
+	taskrunner "github.com/authzed/spicedb/internal/taskrunner"
 )
 
 type ConcurrencyLimitsOption func(c *ConcurrencyLimits)
@@ -34,6 +37,9 @@ func (c *ConcurrencyLimits) ToOption() ConcurrencyLimitsOption {
 		to.ReachableResources = c.ReachableResources
 		to.LookupResources = c.LookupResources
 		to.LookupSubjects = c.LookupSubjects
+		to.Adaptive = c.Adaptive
+		to.AdaptivePerRequest = c.AdaptivePerRequest
+		to.Planner = c.Planner
 	}
 }
 
@@ -44,6 +50,7 @@ func (c ConcurrencyLimits) DebugMap() map[string]any {
 	debugMap["ReachableResources"] = helpers.DebugValue(c.ReachableResources, false)
 	debugMap["LookupResources"] = helpers.DebugValue(c.LookupResources, false)
 	debugMap["LookupSubjects"] = helpers.DebugValue(c.LookupSubjects, false)
+	debugMap["AdaptivePerRequest"] = helpers.DebugValue(c.AdaptivePerRequest, false)
 	return debugMap
 }
 
@@ -90,3 +97,24 @@ func WithLookupSubjects(lookupSubjects uint16) ConcurrencyLimitsOption {
 		c.LookupSubjects = lookupSubjects
 	}
 }
+
+// WithAdaptive returns an option that can set Adaptive on a ConcurrencyLimits
+func WithAdaptive(adaptive *taskrunner.AdaptiveLimiter) ConcurrencyLimitsOption {
+	return func(c *ConcurrencyLimits) {
+		c.Adaptive = adaptive
+	}
+}
+
+// WithAdaptivePerRequest returns an option that can set AdaptivePerRequest on a ConcurrencyLimits
+func WithAdaptivePerRequest(adaptivePerRequest uint16) ConcurrencyLimitsOption {
+	return func(c *ConcurrencyLimits) {
+		c.AdaptivePerRequest = adaptivePerRequest
+	}
+}
+
+// WithPlanner returns an option that can set Planner on a ConcurrencyLimits
+func WithPlanner(planner *graph.EvaluationPlanner) ConcurrencyLimitsOption {
+	return func(c *ConcurrencyLimits) {
+		c.Planner = planner
+	}
+}
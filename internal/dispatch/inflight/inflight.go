@@ -0,0 +1,163 @@
+// Package inflight tracks the resolutions currently being executed by the dispatchers of the
+// node, so that a snapshot of them can be captured for diagnostics.
+package inflight
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/authzed/spicedb/pkg/middleware/requestid"
+)
+
+// DefaultTracker is the tracker of the resolutions executed by the local dispatchers of the node.
+// It is disabled until enabled by the diagnostics server.
+var DefaultTracker = NewTracker()
+
+// Resolution is a resolution being executed by a dispatcher.
+type Resolution struct {
+	// Kind is the kind of dispatch of the resolution, such as `check`.
+	Kind string `json:"kind"`
+
+	// Resource is the resource relation being resolved.
+	Resource string `json:"resource"`
+
+	// ResourceIDCount is the number of resource IDs being resolved, if the resolution is for
+	// specific resources.
+	ResourceIDCount int `json:"resourceIdCount,omitempty"`
+
+	// Subject is the subject, or subject relation, of the resolution.
+	Subject string `json:"subject"`
+
+	// DepthRemaining is the remaining depth of the resolution.
+	DepthRemaining uint32 `json:"depthRemaining"`
+
+	// RequestID is the ID of the API request which caused the resolution, if known.
+	RequestID string `json:"requestId,omitempty"`
+
+	// StartedAt is the time at which the resolution started.
+	StartedAt time.Time `json:"startedAt"`
+}
+
+// ResolutionSnapshot is a resolution as captured by a snapshot.
+type ResolutionSnapshot struct {
+	Resolution
+
+	// Elapsed is the time elapsed since the resolution started, when the snapshot was captured.
+	Elapsed time.Duration `json:"-"`
+
+	// ElapsedText is Elapsed formatted for display.
+	ElapsedText string `json:"elapsed"`
+}
+
+// Tracker tracks the resolutions currently being executed.
+type Tracker struct {
+	enabled atomic.Bool
+	now     func() time.Time
+
+	lock   sync.Mutex
+	nextID uint64
+	active map[uint64]Resolution
+}
+
+// NewTracker returns a new, disabled, tracker.
+func NewTracker() *Tracker {
+	return &Tracker{
+		now:    time.Now,
+		active: map[uint64]Resolution{},
+	}
+}
+
+// Enable enables the tracking of resolutions. Resolutions started while the tracker is disabled
+// are never tracked.
+func (t *Tracker) Enable() {
+	t.enabled.Store(true)
+}
+
+// Enabled returns whether the tracker is enabled. Callers should check it before formatting the
+// descriptions of the resolutions they start, so that nothing is formatted while it is disabled.
+func (t *Tracker) Enabled() bool {
+	return t.enabled.Load()
+}
+
+func noop() {}
+
+// Start starts tracking a resolution, returning the function to call once it completes. It
+// does nothing if the tracker is disabled.
+func (t *Tracker) Start(ctx context.Context, kind, resource string, resourceIDCount int, subject string, depthRemaining uint32) func() {
+	if !t.enabled.Load() {
+		return noop
+	}
+
+	requestID, _ := requestid.FromContext(ctx)
+	resolution := Resolution{
+		Kind:            kind,
+		Resource:        resource,
+		ResourceIDCount: resourceIDCount,
+		Subject:         subject,
+		DepthRemaining:  depthRemaining,
+		RequestID:       requestID,
+		StartedAt:       t.now(),
+	}
+
+	t.lock.Lock()
+	id := t.nextID
+	t.nextID++
+	t.active[id] = resolution
+	t.lock.Unlock()
+
+	return func() {
+		t.lock.Lock()
+		delete(t.active, id)
+		t.lock.Unlock()
+	}
+}
+
+// Snapshot returns the resolutions currently being executed, from the longest running.
+func (t *Tracker) Snapshot() []ResolutionSnapshot {
+	now := t.now()
+
+	t.lock.Lock()
+	snapshot := make([]ResolutionSnapshot, 0, len(t.active))
+	for _, resolution := range t.active {
+		elapsed := now.Sub(resolution.StartedAt)
+		snapshot = append(snapshot, ResolutionSnapshot{
+			Resolution:  resolution,
+			Elapsed:     elapsed,
+			ElapsedText: elapsed.String(),
+		})
+	}
+	t.lock.Unlock()
+
+	slices.SortFunc(snapshot, func(a, b ResolutionSnapshot) int {
+		return a.StartedAt.Compare(b.StartedAt)
+	})
+	return snapshot
+}
+
+// ServeHTTP implements http.Handler: GET requests return a snapshot of the resolutions currently
+// being executed as JSON. The `request_id` query parameter restricts the snapshot to the
+// resolutions of a single request.
+func (t *Tracker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	snapshot := t.Snapshot()
+	if requestID := r.URL.Query().Get("request_id"); requestID != "" {
+		snapshot = slices.DeleteFunc(snapshot, func(resolution ResolutionSnapshot) bool {
+			return resolution.RequestID != requestID
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(snapshot); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
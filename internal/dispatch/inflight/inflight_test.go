@@ -0,0 +1,87 @@
+package inflight
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestTrackerDisabled(t *testing.T) {
+	tracker := NewTracker()
+	require.False(t, tracker.Enabled())
+	done := tracker.Start(context.Background(), "check", "document#view", 1, "user:tom#...", 50)
+	require.Empty(t, tracker.Snapshot())
+	done()
+
+	tracker.Enable()
+	require.True(t, tracker.Enabled())
+}
+
+func TestTrackerSnapshot(t *testing.T) {
+	tracker := NewTracker()
+	tracker.Enable()
+
+	start := time.Date(2024, time.May, 3, 0, 0, 0, 0, time.UTC)
+	tracker.now = func() time.Time { return start }
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-request-id", "somerequest"))
+	doneFirst := tracker.Start(ctx, "check", "document#view", 2, "user:tom#...", 50)
+
+	tracker.now = func() time.Time { return start.Add(time.Second) }
+	doneSecond := tracker.Start(context.Background(), "lookupsubjects", "folder#viewer", 1, "user#...", 49)
+
+	tracker.now = func() time.Time { return start.Add(3 * time.Second) }
+	snapshot := tracker.Snapshot()
+	require.Len(t, snapshot, 2)
+
+	require.Equal(t, "check", snapshot[0].Kind)
+	require.Equal(t, "somerequest", snapshot[0].RequestID)
+	require.Equal(t, 2, snapshot[0].ResourceIDCount)
+	require.Equal(t, uint32(50), snapshot[0].DepthRemaining)
+	require.Equal(t, 3*time.Second, snapshot[0].Elapsed)
+
+	require.Equal(t, "lookupsubjects", snapshot[1].Kind)
+	require.Empty(t, snapshot[1].RequestID)
+	require.Equal(t, 2*time.Second, snapshot[1].Elapsed)
+
+	doneFirst()
+	snapshot = tracker.Snapshot()
+	require.Len(t, snapshot, 1)
+	require.Equal(t, "lookupsubjects", snapshot[0].Kind)
+
+	doneSecond()
+	require.Empty(t, tracker.Snapshot())
+}
+
+func TestTrackerServeHTTP(t *testing.T) {
+	tracker := NewTracker()
+	tracker.Enable()
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-request-id", "somerequest"))
+	defer tracker.Start(ctx, "check", "document#view", 1, "user:tom#...", 50)()
+	defer tracker.Start(context.Background(), "expand", "document:first#view", 1, "", 50)()
+
+	get := func(target string) []map[string]any {
+		recorder := httptest.NewRecorder()
+		tracker.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, target, nil))
+		require.Equal(t, http.StatusOK, recorder.Code)
+
+		var snapshot []map[string]any
+		require.NoError(t, json.NewDecoder(recorder.Body).Decode(&snapshot))
+		return snapshot
+	}
+
+	require.Len(t, get("/debug/dispatches"), 2)
+
+	snapshot := get("/debug/dispatches?request_id=somerequest")
+	require.Len(t, snapshot, 1)
+	require.Equal(t, "check", snapshot[0]["kind"])
+	require.Equal(t, "somerequest", snapshot[0]["requestId"])
+	require.Contains(t, snapshot[0], "elapsed")
+	require.Contains(t, snapshot[0], "startedAt")
+}
@@ -38,10 +38,12 @@ var cachePrefixes = []cachePrefix{
 // checkRequestToKey converts a check request into a cache key based on the relation
 func checkRequestToKey(req *v1.DispatchCheckRequest, option dispatchCacheKeyHashComputeOption) DispatchCacheKey {
 	return dispatchCacheKeyHash(checkViaRelationPrefix, req.Metadata.AtRevision, option,
-		hashableRelationReference{req.ResourceRelation},
-		hashableIds(req.ResourceIds),
-		hashableOnr{req.Subject},
-		hashableResultSetting(req.ResultsSetting),
+		withExcludedCaveatNames(req,
+			hashableRelationReference{req.ResourceRelation},
+			hashableIds(req.ResourceIds),
+			hashableOnr{req.Subject},
+			hashableResultSetting(req.ResultsSetting),
+		)...,
 	)
 }
 
@@ -50,11 +52,13 @@ func checkRequestToKey(req *v1.DispatchCheckRequest, option dispatchCacheKeyHash
 func checkRequestToKeyWithCanonical(req *v1.DispatchCheckRequest, canonicalKey string) (DispatchCacheKey, error) {
 	// NOTE: canonical cache keys are only unique *within* a version of a namespace.
 	cacheKey := dispatchCacheKeyHash(checkViaCanonicalPrefix, req.Metadata.AtRevision, computeBothHashes,
-		hashableString(req.ResourceRelation.Namespace),
-		hashableString(canonicalKey),
-		hashableIds(req.ResourceIds),
-		hashableOnr{req.Subject},
-		hashableResultSetting(req.ResultsSetting),
+		withExcludedCaveatNames(req,
+			hashableString(req.ResourceRelation.Namespace),
+			hashableString(canonicalKey),
+			hashableIds(req.ResourceIds),
+			hashableOnr{req.Subject},
+			hashableResultSetting(req.ResultsSetting),
+		)...,
 	)
 
 	if canonicalKey == "" {
@@ -64,6 +68,16 @@ func checkRequestToKeyWithCanonical(req *v1.DispatchCheckRequest, canonicalKey s
 	return cacheKey, nil
 }
 
+// withExcludedCaveatNames appends the excluded caveat names of a check request, if any, to the
+// values hashed for its key. Checks with excluded caveats may find fewer caveated results than
+// the same check without them, so they must not share cache entries.
+func withExcludedCaveatNames(req *v1.DispatchCheckRequest, values ...hashableValue) []hashableValue {
+	if len(req.ExcludedCaveatNames) == 0 {
+		return values
+	}
+	return append(values, hashableString("excluded-caveats"), hashableIds(req.ExcludedCaveatNames))
+}
+
 // expandRequestToKey converts an expand request into a cache key
 func expandRequestToKey(req *v1.DispatchExpandRequest, option dispatchCacheKeyHashComputeOption) DispatchCacheKey {
 	return dispatchCacheKeyHash(expandPrefix, req.Metadata.AtRevision, option,
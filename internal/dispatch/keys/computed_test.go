@@ -650,3 +650,24 @@ func TestComputeContextHash(t *testing.T) {
 
 	require.Equal(t, "81aab1c790f0be947d", hex.EncodeToString(result.StableSumAsBytes()))
 }
+
+func TestCheckKeysWithExcludedCaveatNames(t *testing.T) {
+	createRequest := func(excludedCaveatNames ...string) *v1.DispatchCheckRequest {
+		return &v1.DispatchCheckRequest{
+			ResourceRelation: RR("document", "view"),
+			ResourceIds:      []string{"foo"},
+			Subject:          ONR("user", "tom", "..."),
+			Metadata: &v1.ResolverMeta{
+				AtRevision: "1234",
+			},
+			ExcludedCaveatNames: excludedCaveatNames,
+		}
+	}
+
+	withoutExcluded := checkRequestToKey(createRequest(), computeBothHashes)
+	withExcluded := checkRequestToKey(createRequest("somecaveat", "anothercaveat"), computeBothHashes)
+	reordered := checkRequestToKey(createRequest("anothercaveat", "somecaveat"), computeBothHashes)
+
+	require.NotEqual(t, withoutExcluded.StableSumAsBytes(), withExcluded.StableSumAsBytes())
+	require.Equal(t, withExcluded.StableSumAsBytes(), reordered.StableSumAsBytes())
+}
@@ -0,0 +1,112 @@
+package limits
+
+import (
+	"context"
+
+	"github.com/authzed/spicedb/internal/dispatch"
+	v1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+)
+
+// NewDispatcher returns a dispatcher applying the limits of the object type or permission of each
+// dispatch before delegating it. The remaining depth of a dispatch is lowered to the maximum
+// depth of its type, so that the dispatches below it exceed the maximum depth once they are that
+// many levels deep, and the dispatch is canceled once the timeout of its type has elapsed.
+func NewDispatcher(delegate dispatch.Dispatcher, limits Limits) dispatch.Dispatcher {
+	return &Dispatcher{
+		delegate: delegate,
+		limits:   limits,
+	}
+}
+
+// Dispatcher is a dispatcher applying per object type and permission limits.
+type Dispatcher struct {
+	delegate dispatch.Dispatcher
+	limits   Limits
+}
+
+// limitDepth returns whether the remaining depth of the metadata exceeds the maximum depth of the
+// limit, along with the metadata lowered to it.
+func limitDepth(metadata *v1.ResolverMeta, limit Limit) (*v1.ResolverMeta, bool) {
+	if limit.MaxDepth == 0 || metadata.GetDepthRemaining() <= limit.MaxDepth {
+		return metadata, false
+	}
+
+	limited := metadata.CloneVT()
+	limited.DepthRemaining = limit.MaxDepth
+	return limited, true
+}
+
+// withTimeout returns the context bounded by the timeout of the limit, if any.
+func withTimeout(ctx context.Context, limit Limit) (context.Context, context.CancelFunc) {
+	if limit.Timeout == 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, limit.Timeout)
+}
+
+func (d *Dispatcher) DispatchCheck(ctx context.Context, req *v1.DispatchCheckRequest) (*v1.DispatchCheckResponse, error) {
+	limit, ok := d.limits.forRelation(req.ResourceRelation.GetNamespace(), req.ResourceRelation.GetRelation())
+	if !ok {
+		return d.delegate.DispatchCheck(ctx, req)
+	}
+
+	if metadata, limited := limitDepth(req.Metadata, limit); limited {
+		req = req.CloneVT()
+		req.Metadata = metadata
+	}
+
+	ctx, cancel := withTimeout(ctx, limit)
+	defer cancel()
+	return d.delegate.DispatchCheck(ctx, req)
+}
+
+func (d *Dispatcher) DispatchExpand(ctx context.Context, req *v1.DispatchExpandRequest) (*v1.DispatchExpandResponse, error) {
+	limit, ok := d.limits.forRelation(req.ResourceAndRelation.GetNamespace(), req.ResourceAndRelation.GetRelation())
+	if !ok {
+		return d.delegate.DispatchExpand(ctx, req)
+	}
+
+	if metadata, limited := limitDepth(req.Metadata, limit); limited {
+		req = req.CloneVT()
+		req.Metadata = metadata
+	}
+
+	ctx, cancel := withTimeout(ctx, limit)
+	defer cancel()
+	return d.delegate.DispatchExpand(ctx, req)
+}
+
+func (d *Dispatcher) DispatchLookupResources2(req *v1.DispatchLookupResources2Request, stream dispatch.LookupResources2Stream) error {
+	limit, ok := d.limits.forRelation(req.ResourceRelation.GetNamespace(), req.ResourceRelation.GetRelation())
+	if !ok {
+		return d.delegate.DispatchLookupResources2(req, stream)
+	}
+
+	if metadata, limited := limitDepth(req.Metadata, limit); limited {
+		req = req.CloneVT()
+		req.Metadata = metadata
+	}
+
+	ctx, cancel := withTimeout(stream.Context(), limit)
+	defer cancel()
+	return d.delegate.DispatchLookupResources2(req, dispatch.StreamWithContext(ctx, stream))
+}
+
+func (d *Dispatcher) DispatchLookupSubjects(req *v1.DispatchLookupSubjectsRequest, stream dispatch.LookupSubjectsStream) error {
+	limit, ok := d.limits.forRelation(req.ResourceRelation.GetNamespace(), req.ResourceRelation.GetRelation())
+	if !ok {
+		return d.delegate.DispatchLookupSubjects(req, stream)
+	}
+
+	if metadata, limited := limitDepth(req.Metadata, limit); limited {
+		req = req.CloneVT()
+		req.Metadata = metadata
+	}
+
+	ctx, cancel := withTimeout(stream.Context(), limit)
+	defer cancel()
+	return d.delegate.DispatchLookupSubjects(req, dispatch.StreamWithContext(ctx, stream))
+}
+
+func (d *Dispatcher) Close() error                    { return d.delegate.Close() }
+func (d *Dispatcher) ReadyState() dispatch.ReadyState { return d.delegate.ReadyState() }
@@ -0,0 +1,87 @@
+// Package limits implements a dispatcher bounding the depth and duration of the dispatches of
+// specific object types or permissions, so that a pathological recursive type can be bounded
+// tightly without lowering the limits of every dispatch.
+package limits
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limit bounds the dispatches of an object type or permission.
+type Limit struct {
+	// MaxDepth, if non-zero, is the maximum depth of the dispatches below a dispatch of the type.
+	MaxDepth uint32
+
+	// Timeout, if non-zero, is the maximum duration of a dispatch of the type, including the
+	// dispatches below it.
+	Timeout time.Duration
+}
+
+// Limits holds the limits of object types, keyed by `namespace`, and of permissions, keyed by
+// `namespace#relation`. The limits of a permission take precedence over those of its type.
+type Limits map[string]Limit
+
+// ParseLimits parses the maximum depths and timeouts keyed by `namespace` or `namespace#relation`.
+func ParseLimits(maxDepths map[string]string, timeouts map[string]string) (Limits, error) {
+	limits := make(Limits, len(maxDepths)+len(timeouts))
+	for key, value := range maxDepths {
+		if err := validateKey(key); err != nil {
+			return nil, err
+		}
+
+		maxDepth, err := strconv.ParseUint(value, 10, 32)
+		if err != nil || maxDepth == 0 {
+			return nil, fmt.Errorf("invalid maximum dispatch depth `%s` for `%s`: expected a positive integer", value, key)
+		}
+
+		limit := limits[key]
+		limit.MaxDepth = uint32(maxDepth)
+		limits[key] = limit
+	}
+
+	for key, value := range timeouts {
+		if err := validateKey(key); err != nil {
+			return nil, err
+		}
+
+		timeout, err := time.ParseDuration(value)
+		if err != nil || timeout <= 0 {
+			return nil, fmt.Errorf("invalid dispatch timeout `%s` for `%s`: expected a positive duration", value, key)
+		}
+
+		limit := limits[key]
+		limit.Timeout = timeout
+		limits[key] = limit
+	}
+
+	return limits, nil
+}
+
+func validateKey(key string) error {
+	namespace, relation, hasRelation := strings.Cut(key, "#")
+	if namespace == "" || (hasRelation && relation == "") {
+		return fmt.Errorf("invalid dispatch limit key `%s`: expected `namespace` or `namespace#relation`", key)
+	}
+	return nil
+}
+
+// forRelation returns the limit of the relation of the namespace, each bound of which is taken
+// from the permission if set, falling back to the type.
+func (l Limits) forRelation(namespace, relation string) (Limit, bool) {
+	typeLimit, hasTypeLimit := l[namespace]
+	relationLimit, hasRelationLimit := l[namespace+"#"+relation]
+	if !hasRelationLimit {
+		return typeLimit, hasTypeLimit
+	}
+
+	if relationLimit.MaxDepth == 0 {
+		relationLimit.MaxDepth = typeLimit.MaxDepth
+	}
+	if relationLimit.Timeout == 0 {
+		relationLimit.Timeout = typeLimit.Timeout
+	}
+	return relationLimit, true
+}
@@ -0,0 +1,166 @@
+package limits
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/dsfortesting"
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/internal/dispatch"
+	"github.com/authzed/spicedb/internal/dispatch/caching"
+	"github.com/authzed/spicedb/internal/dispatch/graph"
+	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
+	"github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	v1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+func TestParseLimits(t *testing.T) {
+	limits, err := ParseLimits(
+		map[string]string{"folder": "5", "folder#view": "3"},
+		map[string]string{"folder#view": "2s", "document": "500ms"},
+	)
+	require.NoError(t, err)
+	require.Equal(t, Limits{
+		"folder":      {MaxDepth: 5},
+		"folder#view": {MaxDepth: 3, Timeout: 2 * time.Second},
+		"document":    {Timeout: 500 * time.Millisecond},
+	}, limits)
+
+	for _, invalid := range []map[string]string{{"#view": "3"}, {"folder#": "3"}, {"folder": "0"}, {"folder": "deep"}} {
+		_, err := ParseLimits(invalid, nil)
+		require.Error(t, err, invalid)
+	}
+
+	for _, invalid := range []map[string]string{{"": "1s"}, {"folder": "0s"}, {"folder": "long"}} {
+		_, err := ParseLimits(nil, invalid)
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestLimitsForRelation(t *testing.T) {
+	limits := Limits{
+		"folder":        {MaxDepth: 5, Timeout: time.Second},
+		"folder#view":   {MaxDepth: 3},
+		"document#edit": {Timeout: 2 * time.Second},
+	}
+
+	for _, tc := range []struct {
+		namespace, relation string
+		expected            Limit
+		found               bool
+	}{
+		{"folder", "view", Limit{MaxDepth: 3, Timeout: time.Second}, true},
+		{"folder", "parent", Limit{MaxDepth: 5, Timeout: time.Second}, true},
+		{"document", "edit", Limit{Timeout: 2 * time.Second}, true},
+		{"document", "view", Limit{}, false},
+	} {
+		t.Run(tc.namespace+"#"+tc.relation, func(t *testing.T) {
+			limit, found := limits.forRelation(tc.namespace, tc.relation)
+			require.Equal(t, tc.found, found)
+			require.Equal(t, tc.expected, limit)
+		})
+	}
+}
+
+const folderSchema = `
+	definition user {}
+
+	definition folder {
+		relation parent: folder
+		relation viewer: user
+		permission view = viewer + parent->view
+	}
+`
+
+// newTestDispatcher returns a dispatcher resolving dispatches locally, applying the limits to
+// every dispatch, along with a context carrying a datastore with a chain of folders of the given
+// length, viewable by tom from its root.
+func newTestDispatcher(t *testing.T, limits Limits, folders int) (dispatch.Dispatcher, context.Context, datastore.Revision) {
+	rawDS, err := dsfortesting.NewMemDBDatastoreForTesting(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+
+	rels := []tuple.Relationship{tuple.MustParse("folder:folder0#viewer@user:tom")}
+	for i := 1; i < folders; i++ {
+		rels = append(rels, tuple.MustParse(fmt.Sprintf("folder:folder%d#parent@folder:folder%d", i, i-1)))
+	}
+	ds, revision := testfixtures.DatastoreFromSchemaAndTestRelationships(rawDS, folderSchema, rels, require.New(t))
+
+	cd, err := caching.NewCachingDispatcher(nil, false, "", nil)
+	require.NoError(t, err)
+	cd.SetDelegate(NewDispatcher(graph.NewDispatcher(cd, graph.SharedConcurrencyLimits(10), 100), limits))
+	t.Cleanup(func() { cd.Close() })
+
+	return cd, datastoremw.ContextWithDatastore(context.Background(), ds), revision
+}
+
+func checkViewRequest(revision datastore.Revision, folder string) *v1.DispatchCheckRequest {
+	return &v1.DispatchCheckRequest{
+		ResourceRelation: &core.RelationReference{Namespace: "folder", Relation: "view"},
+		ResourceIds:      []string{folder},
+		Subject:          tuple.MustParseSubjectONR("user:tom").ToCoreONR(),
+		ResultsSetting:   v1.DispatchCheckRequest_REQUIRE_ALL_RESULTS,
+		Metadata: &v1.ResolverMeta{
+			AtRevision:     revision.String(),
+			DepthRemaining: 50,
+		},
+	}
+}
+
+func TestDispatcherLimitsDepthOfType(t *testing.T) {
+	// Without limits, the check is resolved through the whole chain of folders.
+	disp, ctx, revision := newTestDispatcher(t, nil, 10)
+	resp, err := disp.DispatchCheck(ctx, checkViewRequest(revision, "folder9"))
+	require.NoError(t, err)
+	require.Equal(t, v1.ResourceCheckResult_MEMBER, resp.ResultsByResourceId["folder9"].Membership)
+
+	// With a maximum depth for the folder type, the same check exceeds it.
+	disp, ctx, revision = newTestDispatcher(t, Limits{"folder": {MaxDepth: 5}}, 10)
+	_, err = disp.DispatchCheck(ctx, checkViewRequest(revision, "folder9"))
+	require.ErrorContains(t, err, "max depth exceeded")
+
+	// Checks within the maximum depth are unaffected.
+	resp, err = disp.DispatchCheck(ctx, checkViewRequest(revision, "folder1"))
+	require.NoError(t, err)
+	require.Equal(t, v1.ResourceCheckResult_MEMBER, resp.ResultsByResourceId["folder1"].Membership)
+
+	// The limits of other types do not apply.
+	disp, ctx, revision = newTestDispatcher(t, Limits{"document": {MaxDepth: 1}}, 10)
+	_, err = disp.DispatchCheck(ctx, checkViewRequest(revision, "folder9"))
+	require.NoError(t, err)
+}
+
+// deadlineDispatcher records the deadline of the context of the dispatches it receives.
+type deadlineDispatcher struct {
+	dispatch.Dispatcher
+
+	deadline time.Time
+	bounded  bool
+}
+
+func (dd *deadlineDispatcher) DispatchCheck(ctx context.Context, _ *v1.DispatchCheckRequest) (*v1.DispatchCheckResponse, error) {
+	dd.deadline, dd.bounded = ctx.Deadline()
+	return &v1.DispatchCheckResponse{Metadata: &v1.ResponseMeta{}}, nil
+}
+
+func TestDispatcherLimitsDurationOfPermission(t *testing.T) {
+	delegate := &deadlineDispatcher{}
+	disp := NewDispatcher(delegate, Limits{"folder#view": {Timeout: time.Second}})
+
+	_, err := disp.DispatchCheck(context.Background(), checkViewRequest(datastore.NoRevision, "folder0"))
+	require.NoError(t, err)
+	require.True(t, delegate.bounded)
+	require.WithinDuration(t, time.Now().Add(time.Second), delegate.deadline, 100*time.Millisecond)
+
+	req := checkViewRequest(datastore.NoRevision, "folder0")
+	req.ResourceRelation.Relation = "viewer"
+	_, err = disp.DispatchCheck(context.Background(), req)
+	require.NoError(t, err)
+	require.False(t, delegate.bounded)
+}
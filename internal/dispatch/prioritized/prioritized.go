@@ -0,0 +1,110 @@
+// Package prioritized implements a dispatcher bounding the number of concurrent dispatches of
+// batch and background requests, so that bulk jobs cannot starve interactive requests of the
+// capacity of the node.
+package prioritized
+
+import (
+	"context"
+
+	"github.com/authzed/spicedb/internal/dispatch"
+	"github.com/authzed/spicedb/pkg/middleware/priority"
+	v1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+)
+
+// Limits bounds the number of concurrent dispatches of the requests of each priority class.
+// Dispatches of interactive requests are never bounded.
+type Limits struct {
+	// Batch, if non-zero, is the maximum number of concurrent dispatches of batch requests.
+	Batch uint16
+
+	// Background, if non-zero, is the maximum number of concurrent dispatches of background
+	// requests.
+	Background uint16
+}
+
+// Enabled returns whether any class is bounded.
+func (l Limits) Enabled() bool {
+	return l.Batch > 0 || l.Background > 0
+}
+
+// NewDispatcher returns a dispatcher bounding the dispatches of each priority class to its limit,
+// making the dispatches beyond it wait for a prior one of their class to complete.
+//
+// Only the dispatches of the requests served by the node must go through the dispatcher, and not
+// the subproblems those dispatches redispatch: a dispatch waiting for its subproblems would
+// otherwise hold its slot while they wait for one.
+func NewDispatcher(delegate dispatch.Dispatcher, limits Limits) dispatch.Dispatcher {
+	slots := make(map[priority.Class]chan struct{}, 2)
+	if limits.Batch > 0 {
+		slots[priority.Batch] = make(chan struct{}, limits.Batch)
+	}
+	if limits.Background > 0 {
+		slots[priority.Background] = make(chan struct{}, limits.Background)
+	}
+
+	return &Dispatcher{
+		delegate: delegate,
+		slots:    slots,
+	}
+}
+
+// Dispatcher is a dispatcher bounding the concurrent dispatches of each priority class.
+type Dispatcher struct {
+	delegate dispatch.Dispatcher
+	slots    map[priority.Class]chan struct{}
+}
+
+// acquire waits for a slot of the priority class of the request, if it is bounded, returning the
+// function releasing it.
+func (d *Dispatcher) acquire(ctx context.Context) (func(), error) {
+	slots, ok := d.slots[priority.FromContext(ctx)]
+	if !ok {
+		return func() {}, nil
+	}
+
+	select {
+	case slots <- struct{}{}:
+		return func() { <-slots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (d *Dispatcher) DispatchCheck(ctx context.Context, req *v1.DispatchCheckRequest) (*v1.DispatchCheckResponse, error) {
+	release, err := d.acquire(ctx)
+	if err != nil {
+		return &v1.DispatchCheckResponse{Metadata: &v1.ResponseMeta{}}, err
+	}
+	defer release()
+	return d.delegate.DispatchCheck(ctx, req)
+}
+
+func (d *Dispatcher) DispatchExpand(ctx context.Context, req *v1.DispatchExpandRequest) (*v1.DispatchExpandResponse, error) {
+	release, err := d.acquire(ctx)
+	if err != nil {
+		return &v1.DispatchExpandResponse{Metadata: &v1.ResponseMeta{}}, err
+	}
+	defer release()
+	return d.delegate.DispatchExpand(ctx, req)
+}
+
+func (d *Dispatcher) DispatchLookupResources2(req *v1.DispatchLookupResources2Request, stream dispatch.LookupResources2Stream) error {
+	release, err := d.acquire(stream.Context())
+	if err != nil {
+		return err
+	}
+	defer release()
+	return d.delegate.DispatchLookupResources2(req, stream)
+}
+
+func (d *Dispatcher) DispatchLookupSubjects(req *v1.DispatchLookupSubjectsRequest, stream dispatch.LookupSubjectsStream) error {
+	release, err := d.acquire(stream.Context())
+	if err != nil {
+		return err
+	}
+	defer release()
+	return d.delegate.DispatchLookupSubjects(req, stream)
+}
+
+func (d *Dispatcher) Close() error                    { return d.delegate.Close() }
+func (d *Dispatcher) ReadyState() dispatch.ReadyState { return d.delegate.ReadyState() }
@@ -0,0 +1,92 @@
+package prioritized
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/dispatch"
+	"github.com/authzed/spicedb/pkg/middleware/priority"
+	v1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+)
+
+// blockingDispatcher answers checks once released, counting those in flight.
+type blockingDispatcher struct {
+	dispatch.Dispatcher
+
+	release  chan struct{}
+	inFlight atomic.Int32
+}
+
+func (d *blockingDispatcher) DispatchCheck(ctx context.Context, _ *v1.DispatchCheckRequest) (*v1.DispatchCheckResponse, error) {
+	d.inFlight.Add(1)
+	defer d.inFlight.Add(-1)
+
+	select {
+	case <-d.release:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return &v1.DispatchCheckResponse{Metadata: &v1.ResponseMeta{}}, nil
+}
+
+func TestDispatcherLimitsClasses(t *testing.T) {
+	delegate := &blockingDispatcher{release: make(chan struct{})}
+	d := NewDispatcher(delegate, Limits{Batch: 2, Background: 1})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	started := func(class priority.Class, count int) chan error {
+		errs := make(chan error, count)
+		for range count {
+			go func() {
+				_, err := d.DispatchCheck(priority.ContextWithClass(ctx, class), &v1.DispatchCheckRequest{})
+				errs <- err
+			}()
+		}
+		return errs
+	}
+
+	batchErrs := started(priority.Batch, 4)
+	backgroundErrs := started(priority.Background, 2)
+
+	// Only the dispatches within the limits of their class reach the delegate.
+	require.Eventually(t, func() bool { return delegate.inFlight.Load() == 3 }, time.Second, time.Millisecond)
+	require.Never(t, func() bool { return delegate.inFlight.Load() > 3 }, 50*time.Millisecond, time.Millisecond)
+
+	// Interactive dispatches are not limited.
+	interactiveErrs := started(priority.Interactive, 5)
+	require.Eventually(t, func() bool { return delegate.inFlight.Load() == 8 }, time.Second, time.Millisecond)
+
+	close(delegate.release)
+	for _, errs := range []chan error{batchErrs, backgroundErrs, interactiveErrs} {
+		for range cap(errs) {
+			require.NoError(t, <-errs)
+		}
+	}
+}
+
+func TestDispatcherWaitCanceled(t *testing.T) {
+	delegate := &blockingDispatcher{release: make(chan struct{})}
+	d := NewDispatcher(delegate, Limits{Background: 1})
+
+	background := priority.ContextWithClass(context.Background(), priority.Background)
+	first := make(chan error, 1)
+	go func() {
+		_, err := d.DispatchCheck(background, &v1.DispatchCheckRequest{})
+		first <- err
+	}()
+	require.Eventually(t, func() bool { return delegate.inFlight.Load() == 1 }, time.Second, time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(background, 10*time.Millisecond)
+	defer cancel()
+	_, err := d.DispatchCheck(ctx, &v1.DispatchCheckRequest{})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	close(delegate.release)
+	require.NoError(t, <-first)
+}
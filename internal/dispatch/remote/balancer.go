@@ -0,0 +1,162 @@
+package remote
+
+import (
+	"math"
+	"sync/atomic"
+
+	"github.com/authzed/consistent"
+	"github.com/authzed/consistent/hashring"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/resolver"
+)
+
+// hedgedDispatchKey is the context key marking a dispatch as hedged, so that the hashring
+// balancer routes it to another member than the one its hashring key maps to.
+type hedgedDispatchKey struct{}
+
+// NewHashringBuilder returns a consistent hashring balancer builder, hashing with the given
+// function, which routes hedged dispatches to the first member of the hashring after those to
+// which their hashring key maps. A hedged dispatch is therefore never sent to the member
+// handling the dispatch it hedges, unless the hashring has no other member.
+func NewHashringBuilder(hashfn hashring.HashFunc) consistent.Builder {
+	return &hashringBuilder{Builder: consistent.NewBuilder(hashfn), hashfn: hashfn}
+}
+
+type hashringBuilder struct {
+	consistent.Builder
+	hashfn hashring.HashFunc
+}
+
+func (hb *hashringBuilder) Build(cc balancer.ClientConn, opts balancer.BuildOptions) balancer.Balancer {
+	hbal := &hashringBalancer{
+		hashfn:   hb.hashfn,
+		subConns: make(map[string]balancer.SubConn),
+	}
+	hbal.Balancer = hb.Builder.Build(&hashringClientConn{ClientConn: cc, balancer: hbal}, opts)
+	return hbal
+}
+
+// hashringBalancer wraps the consistent hashring balancer, maintaining a hashring of the same
+// members, from which the members of hedged dispatches are picked.
+type hashringBalancer struct {
+	balancer.Balancer
+	hashfn hashring.HashFunc
+
+	// config and subConns, the subconnections created by the wrapped balancer by hashring member
+	// key, are only accessed from the calls to the balancer, which are serialized by gRPC.
+	config   *consistent.BalancerConfig
+	subConns map[string]balancer.SubConn
+
+	hedgingRing atomic.Pointer[hedgingRing]
+}
+
+type hedgingRing struct {
+	ring   *hashring.Ring
+	spread uint8
+}
+
+type hashringMember struct {
+	balancer.SubConn
+	key string
+}
+
+func (hm hashringMember) Key() string { return hm.key }
+
+// memberKey returns the key of the hashring member of an address, as in the wrapped balancer.
+func memberKey(addr resolver.Address) string {
+	return addr.ServerName + addr.Addr
+}
+
+func (hb *hashringBalancer) UpdateClientConnState(s balancer.ClientConnState) error {
+	err := hb.Balancer.UpdateClientConnState(s)
+
+	if config, ok := s.BalancerConfig.(*consistent.BalancerConfig); ok {
+		hb.config = config
+	}
+	if hb.config == nil {
+		return err
+	}
+
+	ring, rerr := hashring.New(hb.hashfn, hb.config.ReplicationFactor)
+	if rerr != nil {
+		return rerr
+	}
+
+	current := make(map[string]struct{}, len(s.ResolverState.Addresses))
+	for _, addr := range s.ResolverState.Addresses {
+		key := memberKey(addr)
+		sc, ok := hb.subConns[key]
+		if !ok {
+			continue
+		}
+		if _, ok := current[key]; ok {
+			continue
+		}
+		current[key] = struct{}{}
+
+		if err := ring.Add(hashringMember{SubConn: sc, key: key}); err != nil {
+			return err
+		}
+	}
+
+	for key := range hb.subConns {
+		if _, ok := current[key]; !ok {
+			delete(hb.subConns, key)
+		}
+	}
+
+	hb.hedgingRing.Store(&hedgingRing{ring: ring, spread: hb.config.Spread})
+	return err
+}
+
+// hashringClientConn records the subconnections created by the wrapped balancer, and wraps its
+// pickers to route hedged dispatches.
+type hashringClientConn struct {
+	balancer.ClientConn
+	balancer *hashringBalancer
+}
+
+func (hcc *hashringClientConn) NewSubConn(addrs []resolver.Address, opts balancer.NewSubConnOptions) (balancer.SubConn, error) {
+	sc, err := hcc.ClientConn.NewSubConn(addrs, opts)
+	if err == nil && len(addrs) == 1 {
+		hcc.balancer.subConns[memberKey(addrs[0])] = sc
+	}
+	return sc, err
+}
+
+func (hcc *hashringClientConn) UpdateState(state balancer.State) {
+	// In transient failure, the picker of the wrapped balancer fails all picks.
+	if state.ConnectivityState != connectivity.TransientFailure {
+		state.Picker = &hedgingPicker{Picker: state.Picker, balancer: hcc.balancer}
+	}
+	hcc.ClientConn.UpdateState(state)
+}
+
+// hedgingPicker picks the member of hedged dispatches, deferring to the picker of the wrapped
+// balancer for all others.
+type hedgingPicker struct {
+	balancer.Picker
+	balancer *hashringBalancer
+}
+
+func (hp *hedgingPicker) Pick(info balancer.PickInfo) (balancer.PickResult, error) {
+	if hedged, _ := info.Ctx.Value(hedgedDispatchKey{}).(bool); !hedged {
+		return hp.Picker.Pick(info)
+	}
+
+	hr := hp.balancer.hedgingRing.Load()
+	key, ok := info.Ctx.Value(consistent.CtxKey).([]byte)
+	if hr == nil || !ok || hr.spread == math.MaxUint8 {
+		return hp.Picker.Pick(info)
+	}
+
+	// The dispatch being hedged was sent to one of the first spread members for the key, so the
+	// hedged dispatch is sent to the member after them.
+	members, err := hr.ring.FindN(key, hr.spread+1)
+	if err != nil {
+		return hp.Picker.Pick(info)
+	}
+
+	return balancer.PickResult{SubConn: members[hr.spread].(hashringMember).SubConn}, nil
+}
@@ -0,0 +1,115 @@
+package remote
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/authzed/consistent"
+	"github.com/cespare/xxhash/v2"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/resolver"
+)
+
+type fakeSubConn struct {
+	balancer.SubConn
+	addr string
+}
+
+func (fsc *fakeSubConn) Connect() {}
+
+type fakeClientConn struct {
+	balancer.ClientConn
+	picker balancer.Picker
+}
+
+func (fcc *fakeClientConn) NewSubConn(addrs []resolver.Address, _ balancer.NewSubConnOptions) (balancer.SubConn, error) {
+	return &fakeSubConn{addr: addrs[0].Addr}, nil
+}
+
+func (fcc *fakeClientConn) RemoveSubConn(balancer.SubConn) {}
+
+func (fcc *fakeClientConn) UpdateState(state balancer.State) {
+	fcc.picker = state.Picker
+}
+
+func TestHashringBalancerHedgedPicks(t *testing.T) {
+	tcs := []struct {
+		name        string
+		memberCount int
+		spread      uint8
+	}{
+		{"single member", 1, 1},
+		{"multiple members", 5, 1},
+		{"multiple members with spread", 5, 2},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			builder := NewHashringBuilder(xxhash.Sum64)
+			cc := &fakeClientConn{}
+			b := builder.Build(cc, balancer.BuildOptions{})
+
+			config, err := builder.ParseConfig([]byte(fmt.Sprintf(`{"replicationFactor": 100, "spread": %d}`, tc.spread)))
+			require.NoError(t, err)
+
+			addrs := make([]resolver.Address, 0, tc.memberCount)
+			for i := range tc.memberCount {
+				addrs = append(addrs, resolver.Address{Addr: fmt.Sprintf("10.0.0.%d:50053", i)})
+			}
+
+			require.NoError(t, b.UpdateClientConnState(balancer.ClientConnState{
+				ResolverState:  resolver.State{Addresses: addrs},
+				BalancerConfig: config,
+			}))
+
+			for i := range 100 {
+				ctx := context.WithValue(context.Background(), consistent.CtxKey, []byte(fmt.Sprintf("key%d", i)))
+				hedgedCtx, ok := hedgedContext(ctx)
+				require.True(t, ok)
+
+				hedged, err := cc.picker.Pick(balancer.PickInfo{Ctx: hedgedCtx})
+				require.NoError(t, err)
+
+				// The primary dispatch may be sent to any of the first spread members.
+				for range 10 {
+					primary, err := cc.picker.Pick(balancer.PickInfo{Ctx: ctx})
+					require.NoError(t, err)
+
+					if tc.memberCount == 1 {
+						require.Equal(t, primary.SubConn, hedged.SubConn)
+					} else {
+						require.NotEqual(t, primary.SubConn, hedged.SubConn)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestHashringBalancerRemovedMembers(t *testing.T) {
+	builder := NewHashringBuilder(xxhash.Sum64)
+	cc := &fakeClientConn{}
+	b := builder.Build(cc, balancer.BuildOptions{})
+
+	config, err := builder.ParseConfig([]byte(`{"replicationFactor": 100, "spread": 1}`))
+	require.NoError(t, err)
+
+	first := resolver.Address{Addr: "10.0.0.1:50053"}
+	second := resolver.Address{Addr: "10.0.0.2:50053"}
+	require.NoError(t, b.UpdateClientConnState(balancer.ClientConnState{
+		ResolverState:  resolver.State{Addresses: []resolver.Address{first, second}},
+		BalancerConfig: config,
+	}))
+	require.NoError(t, b.UpdateClientConnState(balancer.ClientConnState{
+		ResolverState: resolver.State{Addresses: []resolver.Address{first}},
+	}))
+
+	ctx := context.WithValue(context.Background(), consistent.CtxKey, []byte("somekey"))
+	hedgedCtx, _ := hedgedContext(ctx)
+
+	hedged, err := cc.picker.Pick(balancer.PickInfo{Ctx: hedgedCtx})
+	require.NoError(t, err)
+	require.Equal(t, first.Addr, hedged.SubConn.(*fakeSubConn).addr)
+}
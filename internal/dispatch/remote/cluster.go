@@ -47,6 +47,20 @@ type ClusterDispatcherConfig struct {
 	// DispatchOverallTimeout is the maximum duration of a dispatched request
 	// before it should timeout.
 	DispatchOverallTimeout time.Duration
+
+	// HedgingEnabled enables hedging of check and expand dispatches: if a dispatch has not
+	// returned within the hedging delay, it is also sent to the next member of the hashring, as
+	// routed by the balancer built by NewHashringBuilder, and the first successful response is
+	// used.
+	HedgingEnabled bool
+
+	// HedgingInitialDelay is the hedging delay used before latency statistics have been
+	// collected. Defaults to 100ms.
+	HedgingInitialDelay time.Duration
+
+	// HedgingQuantile is the quantile of observed dispatch latency after which a dispatch
+	// is hedged. Defaults to 0.95.
+	HedgingQuantile float64
 }
 
 // SecondaryDispatch defines a struct holding a client and its name for secondary
@@ -69,6 +83,11 @@ func NewClusterDispatcher(client ClusterClient, conn *grpc.ClientConn, config Cl
 		dispatchOverallTimeout = 60 * time.Second
 	}
 
+	var hedger *dispatchHedger
+	if config.HedgingEnabled {
+		hedger = newDispatchHedger(config.HedgingInitialDelay, config.HedgingQuantile)
+	}
+
 	return &clusterDispatcher{
 		clusterClient:          client,
 		conn:                   conn,
@@ -76,6 +95,7 @@ func NewClusterDispatcher(client ClusterClient, conn *grpc.ClientConn, config Cl
 		dispatchOverallTimeout: dispatchOverallTimeout,
 		secondaryDispatch:      secondaryDispatch,
 		secondaryDispatchExprs: secondaryDispatchExprs,
+		hedger:                 hedger,
 	}
 }
 
@@ -86,6 +106,7 @@ type clusterDispatcher struct {
 	dispatchOverallTimeout time.Duration
 	secondaryDispatch      map[string]SecondaryDispatch
 	secondaryDispatchExprs map[string]*DispatchExpr
+	hedger                 *dispatchHedger
 }
 
 func (cr *clusterDispatcher) DispatchCheck(ctx context.Context, req *v1.DispatchCheckRequest) (*v1.DispatchCheckResponse, error) {
@@ -143,13 +164,13 @@ func dispatchRequest[Q requestMessage, S responseMessage](ctx context.Context, c
 	defer cancelFn()
 
 	if len(cr.secondaryDispatchExprs) == 0 || len(cr.secondaryDispatch) == 0 {
-		return handler(withTimeout, cr.clusterClient)
+		return dispatchPrimary(withTimeout, cr, reqKey, handler)
 	}
 
 	// If no secondary dispatches are defined, just invoke directly.
 	expr, ok := cr.secondaryDispatchExprs[reqKey]
 	if !ok {
-		return handler(withTimeout, cr.clusterClient)
+		return dispatchPrimary(withTimeout, cr, reqKey, handler)
 	}
 
 	// Otherwise invoke in parallel with any secondary matches.
@@ -158,7 +179,7 @@ func dispatchRequest[Q requestMessage, S responseMessage](ctx context.Context, c
 
 	// Run the main dispatch.
 	go func() {
-		resp, err := handler(withTimeout, cr.clusterClient)
+		resp, err := dispatchPrimary(withTimeout, cr, reqKey, handler)
 		primaryResultChan <- respTuple[S]{resp, err}
 	}()
 
@@ -387,7 +408,9 @@ func (cr *clusterDispatcher) DispatchExpand(ctx context.Context, req *v1.Dispatc
 	withTimeout, cancelFn := context.WithTimeout(ctx, cr.dispatchOverallTimeout)
 	defer cancelFn()
 
-	resp, err := cr.clusterClient.DispatchExpand(withTimeout, req)
+	resp, err := dispatchPrimary(withTimeout, cr, "expand", func(ctx context.Context, client ClusterClient) (*v1.DispatchExpandResponse, error) {
+		return client.DispatchExpand(ctx, req)
+	})
 	if err != nil {
 		return &v1.DispatchExpandResponse{Metadata: requestFailureMetadata}, err
 	}
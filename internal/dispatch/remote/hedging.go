@@ -0,0 +1,180 @@
+package remote
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/authzed/consistent"
+	"github.com/influxdata/tdigest"
+	"github.com/prometheus/client_golang/prometheus"
+
+	log "github.com/authzed/spicedb/internal/logging"
+)
+
+var hedgedDispatchCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "spicedb",
+	Subsystem: "dispatch",
+	Name:      "hedged_remote_dispatch_total",
+	Help:      "total number of remote dispatches which were hedged, and whether the hedged dispatch returned first",
+}, []string{"request_kind", "hedge_won"})
+
+func init() {
+	prometheus.MustRegister(hedgedDispatchCounter)
+}
+
+const (
+	defaultHedgingInitialDelay = 100 * time.Millisecond
+	defaultHedgingQuantile     = 0.95
+	minimumHedgingDelay        = 1 * time.Millisecond
+	hedgingMaxSampleCount      = 10_000
+	hedgingTDigestCompression  = float64(1000)
+)
+
+// dispatchHedger tracks the latency of remote dispatches and computes the delay after which
+// a dispatch is considered slow enough to be hedged.
+type dispatchHedger struct {
+	quantile float64
+
+	lock    sync.Mutex
+	digests []*tdigest.TDigest
+}
+
+func newDispatchHedger(initialDelay time.Duration, quantile float64) *dispatchHedger {
+	if initialDelay <= 0 {
+		initialDelay = defaultHedgingInitialDelay
+	}
+
+	if quantile <= 0.0 || quantile >= 1.0 {
+		quantile = defaultHedgingQuantile
+	}
+
+	digests := []*tdigest.TDigest{
+		tdigest.NewWithCompression(hedgingTDigestCompression),
+		tdigest.NewWithCompression(hedgingTDigestCompression),
+	}
+
+	// As in the datastore hedging proxy, the first digest is pre-loaded with the initial delay,
+	// so that the first dispatches have a reasonable threshold and the digests are out of phase.
+	digests[0].Add(initialDelay.Seconds(), float64(hedgingMaxSampleCount)/2)
+
+	return &dispatchHedger{
+		quantile: quantile,
+		digests:  digests,
+	}
+}
+
+// delay returns the current delay after which a dispatch should be hedged.
+func (dh *dispatchHedger) delay() time.Duration {
+	dh.lock.Lock()
+	seconds := dh.digests[0].Quantile(dh.quantile)
+	dh.lock.Unlock()
+
+	return max(time.Duration(seconds*float64(time.Second)), minimumHedgingDelay)
+}
+
+// record records the duration of a completed dispatch.
+func (dh *dispatchHedger) record(duration time.Duration) {
+	dh.lock.Lock()
+	defer dh.lock.Unlock()
+
+	if dh.digests[0].Count() >= float64(hedgingMaxSampleCount) {
+		exhausted := dh.digests[0]
+		dh.digests = dh.digests[1:]
+		exhausted.Reset()
+		dh.digests = append(dh.digests, exhausted)
+	}
+
+	for _, digest := range dh.digests {
+		digest.Add(duration.Seconds(), 1)
+	}
+}
+
+// hedgedContext returns a context marking the dispatch as hedged, so that it is routed by the
+// balancer built by NewHashringBuilder to the next member of the hashring after the one handling
+// the dispatch found in the given context.
+func hedgedContext(ctx context.Context) (context.Context, bool) {
+	if _, ok := ctx.Value(consistent.CtxKey).([]byte); !ok {
+		return nil, false
+	}
+
+	return context.WithValue(ctx, hedgedDispatchKey{}, true), true
+}
+
+// dispatchPrimary invokes the handler against the primary cluster client. If hedging is enabled
+// and the dispatch has not returned within the hedging delay, the dispatch is also sent to another
+// member of the hashring and the first successful response is returned.
+func dispatchPrimary[S responseMessage](ctx context.Context, cr *clusterDispatcher, reqKey string, handler func(context.Context, ClusterClient) (S, error)) (S, error) {
+	if cr.hedger == nil {
+		return handler(ctx, cr.clusterClient)
+	}
+
+	hedgedCtx, ok := hedgedContext(ctx)
+	if !ok {
+		return handler(ctx, cr.clusterClient)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	hedgedCtx, hedgedCancel := context.WithCancel(hedgedCtx)
+	defer hedgedCancel()
+
+	delay := cr.hedger.delay()
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	primaryResultChan := make(chan respTuple[S], 1)
+	primaryStart := time.Now()
+	go func() {
+		resp, err := handler(ctx, cr.clusterClient)
+		primaryResultChan <- respTuple[S]{resp, err}
+	}()
+
+	select {
+	case r := <-primaryResultChan:
+		if r.err == nil {
+			cr.hedger.record(time.Since(primaryStart))
+		}
+		return r.resp, r.err
+
+	case <-timer.C:
+	}
+
+	log.Ctx(ctx).Debug().Dur("after", delay).Str("request-kind", reqKey).Msg("sending hedged dispatch")
+
+	hedgedResultChan := make(chan respTuple[S], 1)
+	go func() {
+		resp, err := handler(hedgedCtx, cr.clusterClient)
+		hedgedResultChan <- respTuple[S]{resp, err}
+	}()
+
+	// Return the first successful response. If one of the dispatches fails, wait for the other,
+	// returning the error from the primary if both fail.
+	var primaryErr error
+	for remaining := 2; remaining > 0; remaining-- {
+		select {
+		case r := <-primaryResultChan:
+			if r.err == nil {
+				cr.hedger.record(time.Since(primaryStart))
+				hedgedDispatchCounter.WithLabelValues(reqKey, "false").Inc()
+				return r.resp, nil
+			}
+			primaryErr = r.err
+			primaryResultChan = nil
+
+		case r := <-hedgedResultChan:
+			if r.err == nil {
+				// Only the latency of the primary dispatch is recorded, as that of the hedged
+				// dispatch would lower the hedging delay. The primary has taken at least as long.
+				cr.hedger.record(time.Since(primaryStart))
+				hedgedDispatchCounter.WithLabelValues(reqKey, "true").Inc()
+				return r.resp, nil
+			}
+			log.Ctx(ctx).Debug().Err(r.err).Str("request-kind", reqKey).Msg("got hedged dispatch error")
+			hedgedResultChan = nil
+		}
+	}
+
+	return *new(S), primaryErr
+}
@@ -0,0 +1,80 @@
+package remote
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/authzed/spicedb/internal/dispatch/keys"
+	corev1 "github.com/authzed/spicedb/pkg/proto/core/v1"
+	v1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+)
+
+// slowPrimaryClient is a cluster client which is slow to respond to all requests except hedged
+// ones.
+type slowPrimaryClient struct {
+	ClusterClient
+
+	primarySleepTime time.Duration
+}
+
+func (spc *slowPrimaryClient) DispatchCheck(ctx context.Context, _ *v1.DispatchCheckRequest, _ ...grpc.CallOption) (*v1.DispatchCheckResponse, error) {
+	if hedged, _ := ctx.Value(hedgedDispatchKey{}).(bool); hedged {
+		return &v1.DispatchCheckResponse{Metadata: &v1.ResponseMeta{DispatchCount: 2}}, nil
+	}
+
+	select {
+	case <-time.After(spc.primarySleepTime):
+		return &v1.DispatchCheckResponse{Metadata: &v1.ResponseMeta{DispatchCount: 1}}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestCheckHedging(t *testing.T) {
+	tcs := []struct {
+		name                  string
+		hedgingEnabled        bool
+		primarySleepTime      time.Duration
+		expectedDispatchCount uint32
+	}{
+		{"hedging disabled", false, 50 * time.Millisecond, 1},
+		{"fast primary", true, 0, 1},
+		{"slow primary", true, 5 * time.Second, 2},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			dispatcher := NewClusterDispatcher(&slowPrimaryClient{primarySleepTime: tc.primarySleepTime}, nil, ClusterDispatcherConfig{
+				KeyHandler:             &keys.DirectKeyHandler{},
+				DispatchOverallTimeout: 10 * time.Second,
+				HedgingEnabled:         tc.hedgingEnabled,
+				HedgingInitialDelay:    10 * time.Millisecond,
+				HedgingQuantile:        0.9,
+			}, nil, nil)
+
+			resp, err := dispatcher.DispatchCheck(context.Background(), &v1.DispatchCheckRequest{
+				ResourceRelation: &corev1.RelationReference{Namespace: "sometype", Relation: "relation"},
+				ResourceIds:      []string{"foo"},
+				Metadata:         &v1.ResolverMeta{DepthRemaining: 50},
+				Subject:          &corev1.ObjectAndRelation{Namespace: "foo", ObjectId: "bar", Relation: "..."},
+			})
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedDispatchCount, resp.Metadata.DispatchCount)
+		})
+	}
+}
+
+func TestDispatchHedgerDelay(t *testing.T) {
+	hedger := newDispatchHedger(100*time.Millisecond, 0.5)
+	require.Equal(t, 100*time.Millisecond, hedger.delay())
+
+	for range hedgingMaxSampleCount {
+		hedger.record(10 * time.Millisecond)
+	}
+
+	require.InDelta(t, float64(10*time.Millisecond), float64(hedger.delay()), float64(time.Millisecond))
+}
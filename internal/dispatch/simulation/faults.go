@@ -0,0 +1,131 @@
+package simulation
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/benbjohnson/clock"
+
+	"github.com/authzed/spicedb/internal/dispatch"
+	v1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// errInjectedFailure is the error returned by dispatches failed by the simulation.
+var errInjectedFailure = errors.New("injected dispatch failure")
+
+// faultInjectingDispatcher delays and fails the dispatches made through it on a virtual clock,
+// before handing them to its delegate.
+//
+// The faults of a dispatch are derived from the seed and the dispatched request alone, so that
+// the same request is faulted the same way regardless of the scheduling of its goroutine.
+type faultInjectingDispatcher struct {
+	delegate    dispatch.Dispatcher
+	clock       clock.Clock
+	seed        int64
+	maxLatency  time.Duration
+	failureRate float64
+	maxDepth    uint32
+
+	dispatchCount     atomic.Uint64
+	failureCount      atomic.Uint64
+	depthViolationsMu sync.Mutex
+	depthViolations   []string
+}
+
+func (fd *faultInjectingDispatcher) inject(ctx context.Context, req dispatch.DispatchableRequest, parts ...string) error {
+	fd.dispatchCount.Add(1)
+
+	if depthRemaining := req.GetMetadata().GetDepthRemaining(); depthRemaining > fd.maxDepth {
+		fd.depthViolationsMu.Lock()
+		fd.depthViolations = append(fd.depthViolations, strings.Join(parts, " "))
+		fd.depthViolationsMu.Unlock()
+	}
+
+	hasher := fnv.New64a()
+	for _, part := range parts {
+		_, _ = hasher.Write([]byte(part))
+		_, _ = hasher.Write([]byte{0})
+	}
+	rnd := rand.New(rand.NewSource(fd.seed ^ int64(hasher.Sum64()))) // nolint:gosec
+
+	if fd.maxLatency > 0 {
+		select {
+		case <-fd.clock.After(time.Duration(rnd.Int63n(int64(fd.maxLatency)))):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	if rnd.Float64() < fd.failureRate {
+		fd.failureCount.Add(1)
+		return errInjectedFailure
+	}
+
+	return nil
+}
+
+func (fd *faultInjectingDispatcher) DispatchCheck(ctx context.Context, req *v1.DispatchCheckRequest) (*v1.DispatchCheckResponse, error) {
+	if err := fd.inject(ctx, req, "check", tuple.StringCoreRR(req.ResourceRelation), sortedJoin(req.ResourceIds), tuple.StringCoreONR(req.Subject)); err != nil {
+		return &v1.DispatchCheckResponse{Metadata: emptyMetadata()}, err
+	}
+	return fd.delegate.DispatchCheck(ctx, req)
+}
+
+func (fd *faultInjectingDispatcher) DispatchExpand(ctx context.Context, req *v1.DispatchExpandRequest) (*v1.DispatchExpandResponse, error) {
+	if err := fd.inject(ctx, req, "expand", tuple.StringCoreONR(req.ResourceAndRelation)); err != nil {
+		return &v1.DispatchExpandResponse{Metadata: emptyMetadata()}, err
+	}
+	return fd.delegate.DispatchExpand(ctx, req)
+}
+
+func (fd *faultInjectingDispatcher) DispatchLookupResources2(req *v1.DispatchLookupResources2Request, stream dispatch.LookupResources2Stream) error {
+	if err := fd.inject(stream.Context(), req, "lookupresources", tuple.StringCoreRR(req.ResourceRelation), tuple.StringCoreRR(req.SubjectRelation), sortedJoin(req.SubjectIds)); err != nil {
+		return err
+	}
+	return fd.delegate.DispatchLookupResources2(req, stream)
+}
+
+func (fd *faultInjectingDispatcher) DispatchLookupSubjects(req *v1.DispatchLookupSubjectsRequest, stream dispatch.LookupSubjectsStream) error {
+	if err := fd.inject(stream.Context(), req, "lookupsubjects", tuple.StringCoreRR(req.ResourceRelation), sortedJoin(req.ResourceIds), tuple.StringCoreRR(req.SubjectRelation)); err != nil {
+		return err
+	}
+	return fd.delegate.DispatchLookupSubjects(req, stream)
+}
+
+func (fd *faultInjectingDispatcher) Close() error { return nil }
+
+func (fd *faultInjectingDispatcher) ReadyState() dispatch.ReadyState {
+	return fd.delegate.ReadyState()
+}
+
+func emptyMetadata() *v1.ResponseMeta {
+	return &v1.ResponseMeta{DispatchCount: 1}
+}
+
+func sortedJoin(values []string) string {
+	sorted := append([]string(nil), values...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ",")
+}
+
+// advanceClock advances the mock clock in steps of the given duration until the context is
+// canceled, firing the timers of the delayed dispatches in virtual time order.
+func advanceClock(ctx context.Context, mock *clock.Mock, step time.Duration) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+			// Add yields to the other goroutines after firing the due timers.
+			mock.Add(step)
+		}
+	}
+}
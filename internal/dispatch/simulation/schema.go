@@ -0,0 +1,357 @@
+package simulation
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+const userType = "user"
+
+// model is a generated schema, along with its relationships and a naive resolver over them.
+//
+// Definitions may only reference definitions generated before them, which makes the schema
+// acyclic and guarantees that naive resolution terminates.
+type model struct {
+	definitions   []*definition
+	byName        map[string]*definition
+	userIDs       []string
+	relationships []tuple.Relationship
+
+	subjectsByResource map[string][]tuple.ObjectAndRelation
+	memoized           map[string]bool
+}
+
+type definition struct {
+	name        string
+	objectIDs   []string
+	relations   []*relation
+	permissions []*permission
+}
+
+// names returns the names of the relations and permissions of the definition.
+func (d *definition) names() []string {
+	names := make([]string, 0, len(d.relations)+len(d.permissions))
+	for _, rel := range d.relations {
+		names = append(names, rel.name)
+	}
+	for _, perm := range d.permissions {
+		names = append(names, perm.name)
+	}
+	return names
+}
+
+func (d *definition) permission(name string) *permission {
+	for _, perm := range d.permissions {
+		if perm.name == name {
+			return perm
+		}
+	}
+	return nil
+}
+
+type relation struct {
+	name    string
+	allowed []allowedType
+}
+
+// parentType returns the definition walked by arrows over the relation, if it is a parent relation.
+func (r *relation) parentType() (string, bool) {
+	if len(r.allowed) == 1 && r.allowed[0].typeName != userType && r.allowed[0].relation == "" {
+		return r.allowed[0].typeName, true
+	}
+	return "", false
+}
+
+type allowedType struct {
+	typeName string
+	relation string
+}
+
+func (at allowedType) String() string {
+	if at.relation == "" {
+		return at.typeName
+	}
+	return at.typeName + "#" + at.relation
+}
+
+type permission struct {
+	name string
+	expr *expression
+}
+
+type expressionKind int
+
+const (
+	refExpression expressionKind = iota
+	arrowExpression
+	unionExpression
+	intersectionExpression
+	exclusionExpression
+)
+
+type expression struct {
+	kind        expressionKind
+	name        string
+	arrowTarget string
+	left, right *expression
+}
+
+func (e *expression) String() string {
+	switch e.kind {
+	case refExpression:
+		return e.name
+	case arrowExpression:
+		return e.name + "->" + e.arrowTarget
+	case unionExpression:
+		return "(" + e.left.String() + " + " + e.right.String() + ")"
+	case intersectionExpression:
+		return "(" + e.left.String() + " & " + e.right.String() + ")"
+	case exclusionExpression:
+		return "(" + e.left.String() + " - " + e.right.String() + ")"
+	default:
+		panic(fmt.Sprintf("unknown expression kind %d", e.kind))
+	}
+}
+
+// generateModel generates a schema of the given number of definitions, with relationships
+// between their objects.
+func generateModel(rnd *rand.Rand, definitionCount, objectsPerDefinition, userCount int) *model {
+	m := &model{
+		byName:             map[string]*definition{},
+		subjectsByResource: map[string][]tuple.ObjectAndRelation{},
+		memoized:           map[string]bool{},
+	}
+
+	for index := 0; index < userCount; index++ {
+		m.userIDs = append(m.userIDs, fmt.Sprintf("u%d", index))
+	}
+
+	for index := 0; index < definitionCount; index++ {
+		def := m.generateDefinition(rnd, index, objectsPerDefinition)
+		m.definitions = append(m.definitions, def)
+		m.byName[def.name] = def
+	}
+
+	for _, def := range m.definitions {
+		for _, objectID := range def.objectIDs {
+			for _, rel := range def.relations {
+				m.generateRelationships(rnd, def, objectID, rel)
+			}
+		}
+	}
+
+	return m
+}
+
+func (m *model) generateDefinition(rnd *rand.Rand, index, objectsPerDefinition int) *definition {
+	def := &definition{name: fmt.Sprintf("resource%d", index)}
+	for objectIndex := 0; objectIndex < objectsPerDefinition; objectIndex++ {
+		def.objectIDs = append(def.objectIDs, fmt.Sprintf("r%d_%d", index, objectIndex))
+	}
+
+	relationCount := 1 + rnd.Intn(3)
+	for relIndex := 0; relIndex < relationCount; relIndex++ {
+		rel := &relation{name: fmt.Sprintf("rel%d", relIndex)}
+		switch {
+		case index > 0 && rnd.Float64() < 0.4:
+			parent := m.definitions[rnd.Intn(index)]
+			rel.allowed = []allowedType{{typeName: parent.name}}
+
+		case index > 0 && rnd.Float64() < 0.5:
+			referenced := m.definitions[rnd.Intn(index)]
+			referencedRel := referenced.relations[rnd.Intn(len(referenced.relations))]
+			rel.allowed = []allowedType{{typeName: userType}, {typeName: referenced.name, relation: referencedRel.name}}
+
+		default:
+			rel.allowed = []allowedType{{typeName: userType}}
+		}
+		def.relations = append(def.relations, rel)
+	}
+
+	permissionCount := 1 + rnd.Intn(3)
+	for permIndex := 0; permIndex < permissionCount; permIndex++ {
+		perm := &permission{name: fmt.Sprintf("perm%d", permIndex)}
+		perm.expr = m.generateExpression(rnd, def, 2)
+		def.permissions = append(def.permissions, perm)
+	}
+
+	return def
+}
+
+func (m *model) generateExpression(rnd *rand.Rand, def *definition, depth int) *expression {
+	if depth == 0 || rnd.Float64() < 0.3 {
+		return m.generateLeaf(rnd, def)
+	}
+
+	kind := []expressionKind{unionExpression, unionExpression, intersectionExpression, exclusionExpression}[rnd.Intn(4)]
+	return &expression{
+		kind:  kind,
+		left:  m.generateExpression(rnd, def, depth-1),
+		right: m.generateExpression(rnd, def, depth-1),
+	}
+}
+
+func (m *model) generateLeaf(rnd *rand.Rand, def *definition) *expression {
+	var parentRelations []*relation
+	for _, rel := range def.relations {
+		if _, ok := rel.parentType(); ok {
+			parentRelations = append(parentRelations, rel)
+		}
+	}
+
+	if len(parentRelations) > 0 && rnd.Float64() < 0.4 {
+		rel := parentRelations[rnd.Intn(len(parentRelations))]
+		parentType, _ := rel.parentType()
+		targetNames := m.byName[parentType].names()
+		return &expression{kind: arrowExpression, name: rel.name, arrowTarget: targetNames[rnd.Intn(len(targetNames))]}
+	}
+
+	// Only the permissions generated so far can be referenced, to keep the schema acyclic.
+	names := def.names()
+	return &expression{kind: refExpression, name: names[rnd.Intn(len(names))]}
+}
+
+func (m *model) generateRelationships(rnd *rand.Rand, def *definition, objectID string, rel *relation) {
+	for _, allowed := range rel.allowed {
+		count := rnd.Intn(3)
+		for index := 0; index < count; index++ {
+			var subject tuple.ObjectAndRelation
+			switch {
+			case allowed.typeName == userType:
+				subject = tuple.ONR(userType, m.userIDs[rnd.Intn(len(m.userIDs))], tuple.Ellipsis)
+
+			case allowed.relation == "":
+				parent := m.byName[allowed.typeName]
+				subject = tuple.ONR(parent.name, parent.objectIDs[rnd.Intn(len(parent.objectIDs))], tuple.Ellipsis)
+
+			default:
+				referenced := m.byName[allowed.typeName]
+				subject = tuple.ONR(referenced.name, referenced.objectIDs[rnd.Intn(len(referenced.objectIDs))], allowed.relation)
+			}
+
+			resource := tuple.ONR(def.name, objectID, rel.name)
+			key := tuple.StringONR(resource)
+			if containsSubject(m.subjectsByResource[key], subject) {
+				continue
+			}
+
+			m.subjectsByResource[key] = append(m.subjectsByResource[key], subject)
+			m.relationships = append(m.relationships, tuple.Relationship{
+				RelationshipReference: tuple.RelationshipReference{Resource: resource, Subject: subject},
+			})
+		}
+	}
+}
+
+func containsSubject(subjects []tuple.ObjectAndRelation, subject tuple.ObjectAndRelation) bool {
+	for _, existing := range subjects {
+		if existing == subject {
+			return true
+		}
+	}
+	return false
+}
+
+// schema returns the schema of the model in the schema language.
+func (m *model) schema() string {
+	var sb strings.Builder
+	sb.WriteString("definition " + userType + " {}\n")
+
+	for _, def := range m.definitions {
+		sb.WriteString("\ndefinition " + def.name + " {\n")
+		for _, rel := range def.relations {
+			allowed := make([]string, 0, len(rel.allowed))
+			for _, at := range rel.allowed {
+				allowed = append(allowed, at.String())
+			}
+			sb.WriteString("\trelation " + rel.name + ": " + strings.Join(allowed, " | ") + "\n")
+		}
+		for _, perm := range def.permissions {
+			sb.WriteString("\tpermission " + perm.name + " = " + perm.expr.String() + "\n")
+		}
+		sb.WriteString("}\n")
+	}
+
+	return sb.String()
+}
+
+// check naively resolves whether the user has the relation or permission on the object, by
+// recursively walking the relationships of the model.
+func (m *model) check(defName, objectID, name, userID string) bool {
+	key := defName + ":" + objectID + "#" + name + "@" + userID
+	if result, ok := m.memoized[key]; ok {
+		return result
+	}
+
+	def := m.byName[defName]
+	var result bool
+	if perm := def.permission(name); perm != nil {
+		result = m.evaluate(perm.expr, def, objectID, userID)
+	} else {
+		result = m.checkRelation(defName, objectID, name, userID)
+	}
+
+	m.memoized[key] = result
+	return result
+}
+
+func (m *model) checkRelation(defName, objectID, relationName, userID string) bool {
+	resource := tuple.ONR(defName, objectID, relationName)
+	for _, subject := range m.subjectsByResource[tuple.StringONR(resource)] {
+		switch {
+		case subject.ObjectType == userType:
+			if subject.ObjectID == userID {
+				return true
+			}
+
+		case subject.Relation != tuple.Ellipsis:
+			if m.check(subject.ObjectType, subject.ObjectID, subject.Relation, userID) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (m *model) evaluate(expr *expression, def *definition, objectID, userID string) bool {
+	switch expr.kind {
+	case refExpression:
+		return m.check(def.name, objectID, expr.name, userID)
+
+	case arrowExpression:
+		resource := tuple.ONR(def.name, objectID, expr.name)
+		for _, subject := range m.subjectsByResource[tuple.StringONR(resource)] {
+			if m.check(subject.ObjectType, subject.ObjectID, expr.arrowTarget, userID) {
+				return true
+			}
+		}
+		return false
+
+	case unionExpression:
+		return m.evaluate(expr.left, def, objectID, userID) || m.evaluate(expr.right, def, objectID, userID)
+
+	case intersectionExpression:
+		return m.evaluate(expr.left, def, objectID, userID) && m.evaluate(expr.right, def, objectID, userID)
+
+	case exclusionExpression:
+		return m.evaluate(expr.left, def, objectID, userID) && !m.evaluate(expr.right, def, objectID, userID)
+
+	default:
+		panic(fmt.Sprintf("unknown expression kind %d", expr.kind))
+	}
+}
+
+// lookupResources naively resolves the objects of the definition on which the user has the
+// relation or permission.
+func (m *model) lookupResources(defName, name, userID string) []string {
+	found := []string{}
+	for _, objectID := range m.byName[defName].objectIDs {
+		if m.check(defName, objectID, name, userID) {
+			found = append(found, objectID)
+		}
+	}
+	return found
+}
@@ -0,0 +1,269 @@
+// Package simulation runs Check and LookupResources resolution over generated schemas through the
+// dispatch engine, with latency and failures injected into each dispatch on a virtual clock, and
+// asserts invariants of the engine: no deadlocks, bounded depth and results matching those of a
+// naive resolver.
+package simulation
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/dsfortesting"
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/internal/dispatch"
+	"github.com/authzed/spicedb/internal/dispatch/caching"
+	"github.com/authzed/spicedb/internal/dispatch/graph"
+	"github.com/authzed/spicedb/internal/dispatch/keys"
+	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
+	"github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/pkg/datastore"
+	v1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+const (
+	clockStep         = time.Millisecond
+	veryLargeLimit    = 1000000000
+	dispatchChunkSize = 2
+)
+
+// Options are the options of a simulation.
+type Options struct {
+	// Seed is the seed of the first simulation; each simulation uses the next seed.
+	Seed int64
+
+	// Simulations is the number of simulations run, each over a newly generated schema.
+	Simulations int
+
+	// Definitions is the number of definitions generated in each schema, besides `user`.
+	Definitions int
+
+	// ObjectsPerDefinition is the number of objects generated for each definition.
+	ObjectsPerDefinition int
+
+	// Users is the number of users generated as subjects.
+	Users int
+
+	// Checks is the number of checks run concurrently in each simulation.
+	Checks int
+
+	// Lookups is the number of LookupResources run concurrently in each simulation.
+	Lookups int
+
+	// MaxLatency is the maximum virtual latency injected into each dispatch.
+	MaxLatency time.Duration
+
+	// FailureRate is the probability of a dispatch failing.
+	FailureRate float64
+
+	// MaxDepth is the depth with which requests are made.
+	MaxDepth uint32
+
+	// ConcurrencyLimit is the concurrency limit of the dispatcher.
+	ConcurrencyLimit uint16
+
+	// DeadlockTimeout is the wall-clock time after which a request is considered deadlocked.
+	DeadlockTimeout time.Duration
+}
+
+// DefaultOptions are the default options of a simulation.
+var DefaultOptions = Options{
+	Seed:                 1,
+	Simulations:          10,
+	Definitions:          4,
+	ObjectsPerDefinition: 4,
+	Users:                4,
+	Checks:               40,
+	Lookups:              10,
+	MaxLatency:           5 * time.Millisecond,
+	FailureRate:          0.02,
+	MaxDepth:             50,
+	ConcurrencyLimit:     4,
+	DeadlockTimeout:      30 * time.Second,
+}
+
+// Run runs simulations with the given options, failing the test at the first violated invariant.
+func Run(t *testing.T, opts Options) {
+	for simulation := 0; simulation < opts.Simulations; simulation++ {
+		seed := opts.Seed + int64(simulation)
+		t.Run(fmt.Sprintf("seed-%d", seed), func(t *testing.T) {
+			runSimulation(t, seed, opts)
+		})
+	}
+}
+
+// result is the outcome of a request of a simulation.
+type result struct {
+	description   string
+	expected      any
+	actual        any
+	depthRequired uint32
+	err           error
+}
+
+func runSimulation(t *testing.T, seed int64, opts Options) {
+	rnd := rand.New(rand.NewSource(seed)) // nolint:gosec
+	m := generateModel(rnd, opts.Definitions, opts.ObjectsPerDefinition, opts.Users)
+	schema := m.schema()
+
+	rawDS, err := dsfortesting.NewMemDBDatastoreForTesting(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+	t.Cleanup(func() { rawDS.Close() })
+
+	ds, revision := testfixtures.DatastoreFromSchemaAndTestRelationships(rawDS, schema, m.relationships, require.New(t))
+
+	mockClock := clock.NewMock()
+	faults := &faultInjectingDispatcher{
+		clock:       mockClock,
+		seed:        seed,
+		maxLatency:  opts.MaxLatency,
+		failureRate: opts.FailureRate,
+		maxDepth:    opts.MaxDepth,
+	}
+
+	// The caching dispatcher is the redispatcher of the graph dispatcher, so that every
+	// subproblem goes through the cache and is then faulted.
+	cachingDispatcher, err := caching.NewCachingDispatcher(caching.DispatchTestCache(t), false, "", &keys.CanonicalKeyHandler{})
+	require.NoError(t, err)
+	cachingDispatcher.SetDelegate(faults)
+	faults.delegate = graph.NewDispatcher(cachingDispatcher, graph.SharedConcurrencyLimits(opts.ConcurrencyLimit), dispatchChunkSize)
+	t.Cleanup(func() { cachingDispatcher.Close() })
+
+	ctx, cancel := context.WithCancel(datastoremw.ContextWithHandle(context.Background()))
+	t.Cleanup(cancel)
+	require.NoError(t, datastoremw.SetInContext(ctx, ds))
+	go advanceClock(ctx, mockClock, clockStep)
+
+	results := make(chan result, opts.Checks+opts.Lookups)
+	var wg sync.WaitGroup
+	for index := 0; index < opts.Checks; index++ {
+		def := m.definitions[rnd.Intn(len(m.definitions))]
+		objectID := def.objectIDs[rnd.Intn(len(def.objectIDs))]
+		names := def.names()
+		name := names[rnd.Intn(len(names))]
+		userID := m.userIDs[rnd.Intn(len(m.userIDs))]
+		expected := m.check(def.name, objectID, name, userID)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results <- runCheck(ctx, cachingDispatcher, revision, opts.MaxDepth, def.name, objectID, name, userID, expected)
+		}()
+	}
+
+	for index := 0; index < opts.Lookups; index++ {
+		def := m.definitions[rnd.Intn(len(m.definitions))]
+		names := def.names()
+		name := names[rnd.Intn(len(names))]
+		userID := m.userIDs[rnd.Intn(len(m.userIDs))]
+		expected := m.lookupResources(def.name, name, userID)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results <- runLookupResources(ctx, cachingDispatcher, revision, opts.MaxDepth, def.name, name, userID, expected)
+		}()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(opts.DeadlockTimeout):
+		require.FailNow(t, "requests did not complete; possible deadlock in the dispatcher", "seed %d, schema:\n%s", seed, schema)
+	}
+	close(results)
+
+	for r := range results {
+		if r.err != nil {
+			require.ErrorIs(t, r.err, errInjectedFailure, "unexpected error for %s with seed %d, schema:\n%s", r.description, seed, schema)
+			continue
+		}
+
+		require.LessOrEqual(t, r.depthRequired, opts.MaxDepth, "unbounded depth for %s with seed %d", r.description, seed)
+		require.Equal(t, r.expected, r.actual, "result mismatch for %s with seed %d, schema:\n%s", r.description, seed, schema)
+	}
+
+	require.Empty(t, faults.depthViolations, "dispatches exceeding the maximum depth with seed %d", seed)
+	t.Logf("seed %d: %d dispatches, %d injected failures", seed, faults.dispatchCount.Load(), faults.failureCount.Load())
+}
+
+func runCheck(ctx context.Context, dispatcher dispatch.Dispatcher, revision datastore.Revision, maxDepth uint32, defName, objectID, name, userID string, expected bool) result {
+	r := result{
+		description: fmt.Sprintf("check %s:%s#%s@user:%s", defName, objectID, name, userID),
+		expected:    expected,
+	}
+
+	resp, err := dispatcher.DispatchCheck(ctx, &v1.DispatchCheckRequest{
+		ResourceRelation: tuple.RR(defName, name).ToCoreRR(),
+		ResourceIds:      []string{objectID},
+		Subject:          tuple.ONR(userType, userID, tuple.Ellipsis).ToCoreONR(),
+		ResultsSetting:   v1.DispatchCheckRequest_ALLOW_SINGLE_RESULT,
+		Metadata: &v1.ResolverMeta{
+			AtRevision:     revision.String(),
+			DepthRemaining: maxDepth,
+		},
+	})
+	if err != nil {
+		r.err = err
+		return r
+	}
+
+	found, ok := resp.ResultsByResourceId[objectID]
+	r.actual = ok && found.Membership == v1.ResourceCheckResult_MEMBER
+	r.depthRequired = resp.Metadata.DepthRequired
+	return r
+}
+
+func runLookupResources(ctx context.Context, dispatcher dispatch.Dispatcher, revision datastore.Revision, maxDepth uint32, defName, name, userID string, expected []string) result {
+	r := result{
+		description: fmt.Sprintf("lookup resources %s#%s@user:%s", defName, name, userID),
+		expected:    expected,
+	}
+
+	stream := dispatch.NewCollectingDispatchStream[*v1.DispatchLookupResources2Response](ctx)
+	err := dispatcher.DispatchLookupResources2(&v1.DispatchLookupResources2Request{
+		ResourceRelation: tuple.RR(defName, name).ToCoreRR(),
+		SubjectRelation:  tuple.RR(userType, tuple.Ellipsis).ToCoreRR(),
+		SubjectIds:       []string{userID},
+		TerminalSubject:  tuple.ONR(userType, userID, tuple.Ellipsis).ToCoreONR(),
+		Metadata: &v1.ResolverMeta{
+			AtRevision:     revision.String(),
+			DepthRemaining: maxDepth,
+		},
+		OptionalLimit: veryLargeLimit,
+	}, stream)
+	if err != nil {
+		r.err = err
+		return r
+	}
+
+	found := []string{}
+	for _, resp := range stream.Results() {
+		found = append(found, resp.Resource.ResourceId)
+		r.depthRequired = max(r.depthRequired, resp.Metadata.DepthRequired)
+	}
+
+	// Resources are compared as sorted sets, as they are streamed in no particular order.
+	r.expected = sortedUnique(expected)
+	r.actual = sortedUnique(found)
+	return r
+}
+
+func sortedUnique(values []string) []string {
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	return slices.Compact(sorted)
+}
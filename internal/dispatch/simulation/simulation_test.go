@@ -0,0 +1,7 @@
+package simulation
+
+import "testing"
+
+func TestDispatchSimulation(t *testing.T) {
+	Run(t, DefaultOptions)
+}
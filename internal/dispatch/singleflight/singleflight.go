@@ -36,9 +36,29 @@ func New(delegate dispatch.Dispatcher, handler keys.Handler) dispatch.Dispatcher
 	}
 }
 
+// clusterTraversalPrefix is the prefix applied to the keys recorded in the traversal bloom
+// filter by a singleflight dispatcher receiving requests from other nodes.
+const clusterTraversalPrefix = "cluster:"
+
+// NewForClusterRequests returns a singleflight dispatcher for use by a node receiving dispatched
+// requests from other nodes, ensuring identical requests arriving at the same time from any number
+// of nodes are only resolved once.
+//
+// Requests sent by another node have already had their key recorded in the traversal bloom filter
+// by the sending node's singleflight dispatcher, so the traversals are recorded under a separate
+// prefix in order to still detect recursive calls.
+func NewForClusterRequests(delegate dispatch.Dispatcher, handler keys.Handler) dispatch.Dispatcher {
+	return &Dispatcher{
+		delegate:        delegate,
+		keyHandler:      handler,
+		traversalPrefix: clusterTraversalPrefix,
+	}
+}
+
 type Dispatcher struct {
-	delegate   dispatch.Dispatcher
-	keyHandler keys.Handler
+	delegate        dispatch.Dispatcher
+	keyHandler      keys.Handler
+	traversalPrefix string
 
 	checkGroup  singleflight.Group[string, *v1.DispatchCheckResponse]
 	expandGroup singleflight.Group[string, *v1.DispatchExpandResponse]
@@ -71,7 +91,7 @@ func (d *Dispatcher) DispatchCheck(ctx context.Context, req *v1.DispatchCheckReq
 	// likely recursive call, so we dispatch it to the delegate to avoid the singleflight from blocking it.
 	// If the bloom filter presents a false positive, a dispatch will happen, which is a small inefficiency
 	// traded-off to prevent a recursive-call deadlock
-	possiblyLoop, err := req.Metadata.RecordTraversal(keyString)
+	possiblyLoop, err := req.Metadata.RecordTraversal(d.traversalPrefix + keyString)
 	if err != nil {
 		return &v1.DispatchCheckResponse{Metadata: &v1.ResponseMeta{DispatchCount: 1}}, err
 	} else if possiblyLoop {
@@ -115,7 +135,7 @@ func (d *Dispatcher) DispatchExpand(ctx context.Context, req *v1.DispatchExpandR
 		return d.delegate.DispatchExpand(ctx, req)
 	}
 
-	possiblyLoop, err := req.Metadata.RecordTraversal(keyString)
+	possiblyLoop, err := req.Metadata.RecordTraversal(d.traversalPrefix + keyString)
 	if err != nil {
 		return &v1.DispatchExpandResponse{Metadata: &v1.ResponseMeta{DispatchCount: 1}}, err
 	} else if possiblyLoop {
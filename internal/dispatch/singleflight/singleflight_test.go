@@ -165,6 +165,76 @@ func TestSingleFlightDispatcherDetectsLoopThroughDelegate(t *testing.T) {
 	assertCounterWithLabel(t, reg, 2, "spicedb_dispatch_single_flight_total", "loop")
 }
 
+func TestSingleFlightDispatcherForClusterRequests(t *testing.T) {
+	singleFlightCount = prometheus.NewCounterVec(singleFlightCountConfig, []string{"method", "shared"})
+	reg := registerMetricInGatherer(singleFlightCount)
+
+	var called atomic.Uint64
+	f := func() {
+		time.Sleep(100 * time.Millisecond)
+		called.Add(1)
+	}
+	keyHandler := &keys.DirectKeyHandler{}
+	disp := NewForClusterRequests(mockDispatcher{f: f}, keyHandler)
+
+	req := &v1.DispatchCheckRequest{
+		ResourceRelation: tuple.RR("document", "view").ToCoreRR(),
+		ResourceIds:      []string{"foo", "bar"},
+		Subject:          tuple.ONRStringToCore("user", "tom", "..."),
+		Metadata: &v1.ResolverMeta{
+			AtRevision: "1234",
+		},
+	}
+
+	// we simulate the request having been sent by the singleflight dispatcher of another node,
+	// which records the request in the traversal path before dispatching it
+	req.Metadata.TraversalBloom = bloomFilterForRequest(t, keyHandler, req)
+
+	wg := sync.WaitGroup{}
+	wg.Add(3)
+	for range 3 {
+		go func() {
+			_, _ = disp.DispatchCheck(context.Background(), req.CloneVT())
+			wg.Done()
+		}()
+	}
+
+	wg.Wait()
+
+	require.Equal(t, uint64(1), called.Load(), "should have dispatched %d calls but did %d", uint64(1), called.Load())
+	assertCounterWithLabel(t, reg, 1, "spicedb_dispatch_single_flight_total", "true")
+}
+
+func TestSingleFlightDispatcherForClusterRequestsDetectsLoop(t *testing.T) {
+	singleFlightCount = prometheus.NewCounterVec(singleFlightCountConfig, []string{"method", "shared"})
+	reg := registerMetricInGatherer(singleFlightCount)
+
+	var called atomic.Uint64
+	f := func() {
+		time.Sleep(100 * time.Millisecond)
+		called.Add(1)
+	}
+	keyHandler := &keys.DirectKeyHandler{}
+	// we simulate a request which loops back to the same node by nesting 2 cluster singleflight dispatchers
+	disp := NewForClusterRequests(New(NewForClusterRequests(mockDispatcher{f: f}, keyHandler), keyHandler), keyHandler)
+
+	req := &v1.DispatchCheckRequest{
+		ResourceRelation: tuple.RR("document", "view").ToCoreRR(),
+		ResourceIds:      []string{"foo", "bar"},
+		Subject:          tuple.ONRStringToCore("user", "tom", "..."),
+		Metadata: &v1.ResolverMeta{
+			AtRevision: "1234",
+		},
+	}
+	req.Metadata.TraversalBloom = bloomFilterForRequest(t, keyHandler, req)
+
+	_, err := disp.DispatchCheck(context.Background(), req)
+	require.NoError(t, err)
+
+	require.Equal(t, uint64(1), called.Load(), "should have dispatched %d calls but did %d", uint64(1), called.Load())
+	assertCounterWithLabel(t, reg, 2, "spicedb_dispatch_single_flight_total", "loop")
+}
+
 func TestSingleFlightDispatcherCancelation(t *testing.T) {
 	var called atomic.Uint64
 	run := make(chan struct{}, 1)
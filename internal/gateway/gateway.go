@@ -12,6 +12,7 @@ import (
 	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
 	"github.com/authzed/grpcutil"
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -25,6 +26,7 @@ import (
 	"google.golang.org/grpc/metadata"
 
 	"github.com/authzed/spicedb/internal/grpchelpers"
+	"github.com/authzed/spicedb/internal/scim"
 )
 
 var histogram = promauto.NewHistogramVec(prometheus.HistogramOpts{
@@ -34,13 +36,63 @@ var histogram = promauto.NewHistogramVec(prometheus.HistogramOpts{
 	Help:      "A histogram of the duration spent processing requests to the SpiceDB REST Gateway.",
 }, []string{"method"})
 
+// scimPathPrefix is the base URL of the SCIM API.
+const scimPathPrefix = "/scim/v2"
+
+// HandlerOption enables an optional API served by the gateway.
+type HandlerOption func(*handlerOptions)
+
+type handlerOptions struct {
+	graphQLEnabled    bool
+	openFGAEnabled    bool
+	opaDocuments      []OPADocument
+	opaPresharedKeys  []string
+	scimMapping       *scim.Mapping
+	scimPresharedKeys []string
+}
+
+// WithGraphQL serves GraphQL queries at /graphql.
+func WithGraphQL() HandlerOption {
+	return func(opts *handlerOptions) { opts.graphQLEnabled = true }
+}
+
+// WithOpenFGA serves the OpenFGA HTTP API, which the upstream must serve over gRPC.
+func WithOpenFGA() HandlerOption {
+	return func(opts *handlerOptions) { opts.openFGAEnabled = true }
+}
+
+// WithOPAData serves the documents as OPA data documents under /opa/data, watching the upstream
+// for changes. Requests must be authenticated with one of the preshared keys, the first of which
+// authenticates the calls to the upstream.
+func WithOPAData(documents []OPADocument, presharedKeys []string) HandlerOption {
+	return func(opts *handlerOptions) {
+		opts.opaDocuments = documents
+		opts.opaPresharedKeys = presharedKeys
+	}
+}
+
+// WithSCIM serves the SCIM 2.0 provisioning API under /scim/v2, writing the relationships of the
+// mapping. Requests must be authenticated with one of the preshared keys, the first of which
+// authenticates the calls to the upstream.
+func WithSCIM(mapping *scim.Mapping, presharedKeys []string) HandlerOption {
+	return func(opts *handlerOptions) {
+		opts.scimMapping = mapping
+		opts.scimPresharedKeys = presharedKeys
+	}
+}
+
 // NewHandler creates an REST gateway HTTP CloserHandler with the provided upstream
 // configuration.
-func NewHandler(ctx context.Context, upstreamAddr, upstreamTLSCertPath string) (*CloserHandler, error) {
+func NewHandler(ctx context.Context, upstreamAddr, upstreamTLSCertPath string, handlerOpts ...HandlerOption) (*CloserHandler, error) {
 	if upstreamAddr == "" {
 		return nil, fmt.Errorf("upstreamAddr must not be empty")
 	}
 
+	var enabled handlerOptions
+	for _, opt := range handlerOpts {
+		opt(&enabled)
+	}
+
 	opts := []grpc.DialOption{
 		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
 	}
@@ -80,14 +132,60 @@ func NewHandler(ctx context.Context, upstreamAddr, upstreamTLSCertPath string) (
 		return nil, err
 	}
 
+	closers := []io.Closer{schemaConn, permissionsConn, watchConn, healthConn, experimentalConn}
+	if enabled.openFGAEnabled {
+		openFGAConn, err := registerHandler(ctx, gwMux, upstreamAddr, opts, openfgav1.RegisterOpenFGAServiceHandler)
+		if err != nil {
+			return nil, err
+		}
+		closers = append(closers, openFGAConn)
+	}
+
 	mux := http.NewServeMux()
 	mux.Handle("/openapi.json", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		_, _ = io.WriteString(w, proto.OpenAPISchema)
 	}))
 	mux.Handle("/", gwMux)
 
+	if enabled.graphQLEnabled {
+		graphQLConn, err := grpchelpers.Dial(ctx, upstreamAddr, opts...)
+		if err != nil {
+			return nil, err
+		}
+
+		graphQLHandler, err := newGraphQLHandler(v1.NewPermissionsServiceClient(graphQLConn))
+		if err != nil {
+			return nil, err
+		}
+		mux.Handle("/graphql", graphQLHandler)
+		closers = append(closers, graphQLConn)
+	}
+
+	if len(enabled.opaDocuments) > 0 {
+		opaDataConn, err := grpchelpers.Dial(ctx, upstreamAddr, opts...)
+		if err != nil {
+			return nil, err
+		}
+
+		opaDataHandler := newOPADataHandler(ctx, opaDataConn, enabled.opaDocuments, enabled.opaPresharedKeys)
+		mux.Handle(opaDataPathPrefix, opaDataHandler)
+		mux.Handle(opaDataPathPrefix+"/", opaDataHandler)
+		closers = append(closers, opaDataHandler, opaDataConn)
+	}
+
+	if enabled.scimMapping != nil {
+		scimConn, err := grpchelpers.Dial(ctx, upstreamAddr, opts...)
+		if err != nil {
+			return nil, err
+		}
+
+		scimHandler := scim.NewHandler(v1.NewPermissionsServiceClient(scimConn), enabled.scimMapping, enabled.scimPresharedKeys)
+		mux.Handle(scimPathPrefix+"/", http.StripPrefix(scimPathPrefix, scimHandler))
+		closers = append(closers, scimConn)
+	}
+
 	finalHandler := promhttp.InstrumentHandlerDuration(histogram, otelhttp.NewHandler(mux, "gateway"))
-	return newCloserHandler(finalHandler, schemaConn, permissionsConn, watchConn, healthConn, experimentalConn), nil
+	return newCloserHandler(finalHandler, closers...), nil
 }
 
 // CloserHandler is a http.Handler and a io.Closer. Meant to keep track of resources to closer
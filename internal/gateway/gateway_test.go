@@ -56,4 +56,10 @@ func TestCloseConnections(t *testing.T) {
 
 	// if connections are not closed, goleak would detect it
 	require.NoError(t, gatewayHandler.Close())
+
+	// 1 more conn for each of the GraphQL queries and the OpenFGA API
+	gatewayHandler, err = NewHandler(context.Background(), "192.0.2.0:4321", "", WithGraphQL(), WithOpenFGA())
+	require.NoError(t, err)
+	require.Len(t, gatewayHandler.closers, 7)
+	require.NoError(t, gatewayHandler.Close())
 }
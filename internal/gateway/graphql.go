@@ -0,0 +1,522 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/graph-gophers/dataloader/v7"
+	graphql "github.com/graph-gophers/graphql-go"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const graphQLSchema = `
+schema {
+	query: Query
+}
+
+type Query {
+	# checkPermission checks whether the subject has the permission on the resource. The checks
+	# of a query are batched into CheckBulkPermissions requests.
+	checkPermission(resource: ObjectInput!, permission: String!, subject: SubjectInput!, consistency: ConsistencyInput): CheckPermissionResult!
+
+	# lookupResources returns the resources of a type on which the subject has the permission.
+	lookupResources(resourceObjectType: String!, permission: String!, subject: SubjectInput!, limit: Int, consistency: ConsistencyInput): [LookupResourcesResult!]!
+
+	# readRelationships returns the relationships matching the filter.
+	readRelationships(filter: RelationshipFilterInput!, limit: Int, consistency: ConsistencyInput): [Relationship!]!
+}
+
+input ObjectInput {
+	objectType: String!
+	objectId: String!
+}
+
+input SubjectInput {
+	object: ObjectInput!
+	optionalRelation: String
+}
+
+# ConsistencyInput sets at most one consistency requirement. Without any, the query is minimally
+# consistent.
+input ConsistencyInput {
+	fullyConsistent: Boolean
+	atLeastAsFresh: String
+	atExactSnapshot: String
+}
+
+input RelationshipFilterInput {
+	resourceType: String!
+	optionalResourceId: String
+	optionalRelation: String
+	optionalSubjectFilter: SubjectFilterInput
+}
+
+input SubjectFilterInput {
+	subjectType: String!
+	optionalSubjectId: String
+	optionalRelation: String
+}
+
+enum Permissionship {
+	NO_PERMISSION
+	HAS_PERMISSION
+	CONDITIONAL_PERMISSION
+}
+
+type CheckPermissionResult {
+	permissionship: Permissionship!
+	missingRequiredContext: [String!]!
+	checkedAt: String!
+}
+
+type LookupResourcesResult {
+	resourceObjectId: String!
+	permissionship: Permissionship!
+	lookedUpAt: String!
+}
+
+type Object {
+	objectType: String!
+	objectId: String!
+}
+
+type Subject {
+	object: Object!
+	optionalRelation: String
+}
+
+type Relationship {
+	resource: Object!
+	relation: String!
+	subject: Subject!
+	optionalCaveatName: String
+	readAt: String!
+}
+`
+
+const (
+	// graphQLCheckBatchWait is how long checks are collected before being sent as a batch.
+	graphQLCheckBatchWait = 2 * time.Millisecond
+
+	// graphQLCheckBatchCapacity is the maximum number of checks sent in a single batch, which is
+	// also the number of fields of a query resolved in parallel.
+	graphQLCheckBatchCapacity = 100
+)
+
+// graphQLHandler serves GraphQL queries over the permissions service.
+type graphQLHandler struct {
+	schema *graphql.Schema
+	client v1.PermissionsServiceClient
+}
+
+func newGraphQLHandler(client v1.PermissionsServiceClient) (*graphQLHandler, error) {
+	schema, err := graphql.ParseSchema(graphQLSchema, &graphQLResolver{client: client},
+		graphql.UseFieldResolvers(),
+		graphql.MaxParallelism(graphQLCheckBatchCapacity),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse graphql schema: %w", err)
+	}
+
+	return &graphQLHandler{schema: schema, client: client}, nil
+}
+
+func (gh *graphQLHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "graphql queries must be POSTed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var params struct {
+		Query         string         `json:"query"`
+		OperationName string         `json:"operationName"`
+		Variables     map[string]any `json:"variables"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx := metadata.NewOutgoingContext(r.Context(), OtelAnnotator(r.Context(), r))
+	if authorization := r.Header.Get("Authorization"); authorization != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "authorization", authorization)
+	}
+
+	// Checks are batched per query, so that the loader caches nothing across requests.
+	ctx = context.WithValue(ctx, checkLoaderKey{}, dataloader.NewBatchedLoader(
+		gh.batchCheck,
+		dataloader.WithWait[checkKey, checkResult](graphQLCheckBatchWait),
+		dataloader.WithBatchCapacity[checkKey, checkResult](graphQLCheckBatchCapacity),
+	))
+
+	response := gh.schema.Exec(ctx, params.Query, params.OperationName, params.Variables)
+	responseJSON, err := json.Marshal(response)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(responseJSON)
+}
+
+type checkLoaderKey struct{}
+
+// consistencyKey is a comparable form of ConsistencyInput, by which checks are grouped into batches.
+type consistencyKey struct {
+	fullyConsistent bool
+	atLeastAsFresh  string
+	atExactSnapshot string
+}
+
+type checkKey struct {
+	consistency     consistencyKey
+	resourceType    string
+	resourceID      string
+	permission      string
+	subjectType     string
+	subjectID       string
+	subjectRelation string
+}
+
+type checkResult struct {
+	item      *v1.CheckBulkPermissionsResponseItem
+	checkedAt *v1.ZedToken
+}
+
+// batchCheck sends a CheckBulkPermissions request for each consistency found among the checks.
+func (gh *graphQLHandler) batchCheck(ctx context.Context, keys []checkKey) []*dataloader.Result[checkResult] {
+	results := make([]*dataloader.Result[checkResult], len(keys))
+
+	indexesByConsistency := make(map[consistencyKey][]int)
+	var consistencies []consistencyKey
+	for i, key := range keys {
+		if _, ok := indexesByConsistency[key.consistency]; !ok {
+			consistencies = append(consistencies, key.consistency)
+		}
+		indexesByConsistency[key.consistency] = append(indexesByConsistency[key.consistency], i)
+	}
+
+	for _, ck := range consistencies {
+		indexes := indexesByConsistency[ck]
+		items := make([]*v1.CheckBulkPermissionsRequestItem, 0, len(indexes))
+		for _, i := range indexes {
+			key := keys[i]
+			items = append(items, &v1.CheckBulkPermissionsRequestItem{
+				Resource:   &v1.ObjectReference{ObjectType: key.resourceType, ObjectId: key.resourceID},
+				Permission: key.permission,
+				Subject: &v1.SubjectReference{
+					Object:           &v1.ObjectReference{ObjectType: key.subjectType, ObjectId: key.subjectID},
+					OptionalRelation: key.subjectRelation,
+				},
+			})
+		}
+
+		resp, err := gh.client.CheckBulkPermissions(ctx, &v1.CheckBulkPermissionsRequest{
+			Consistency: ck.toConsistency(),
+			Items:       items,
+		})
+		if err == nil && len(resp.Pairs) != len(items) {
+			err = fmt.Errorf("expected %d check results, found %d", len(items), len(resp.Pairs))
+		}
+		if err != nil {
+			for _, i := range indexes {
+				results[i] = &dataloader.Result[checkResult]{Error: err}
+			}
+			continue
+		}
+
+		for j, pair := range resp.Pairs {
+			if pair.GetError() != nil {
+				results[indexes[j]] = &dataloader.Result[checkResult]{Error: status.ErrorProto(pair.GetError())}
+				continue
+			}
+			results[indexes[j]] = &dataloader.Result[checkResult]{Data: checkResult{
+				item:      pair.GetItem(),
+				checkedAt: resp.CheckedAt,
+			}}
+		}
+	}
+	return results
+}
+
+type graphQLResolver struct {
+	client v1.PermissionsServiceClient
+}
+
+type objectInput struct {
+	ObjectType string
+	ObjectId   string //nolint:revive // matches the name of the GraphQL input field
+}
+
+type subjectInput struct {
+	Object           objectInput
+	OptionalRelation *string
+}
+
+type consistencyInput struct {
+	FullyConsistent *bool
+	AtLeastAsFresh  *string
+	AtExactSnapshot *string
+}
+
+func (ci *consistencyInput) key() (consistencyKey, error) {
+	if ci == nil {
+		return consistencyKey{}, nil
+	}
+
+	var key consistencyKey
+	set := 0
+	if ci.FullyConsistent != nil && *ci.FullyConsistent {
+		key.fullyConsistent = true
+		set++
+	}
+	if ci.AtLeastAsFresh != nil {
+		key.atLeastAsFresh = *ci.AtLeastAsFresh
+		set++
+	}
+	if ci.AtExactSnapshot != nil {
+		key.atExactSnapshot = *ci.AtExactSnapshot
+		set++
+	}
+	if set > 1 {
+		return consistencyKey{}, fmt.Errorf("at most one consistency requirement can be set")
+	}
+	return key, nil
+}
+
+func (ck consistencyKey) toConsistency() *v1.Consistency {
+	switch {
+	case ck.fullyConsistent:
+		return &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}}
+	case ck.atLeastAsFresh != "":
+		return &v1.Consistency{Requirement: &v1.Consistency_AtLeastAsFresh{AtLeastAsFresh: &v1.ZedToken{Token: ck.atLeastAsFresh}}}
+	case ck.atExactSnapshot != "":
+		return &v1.Consistency{Requirement: &v1.Consistency_AtExactSnapshot{AtExactSnapshot: &v1.ZedToken{Token: ck.atExactSnapshot}}}
+	default:
+		return &v1.Consistency{Requirement: &v1.Consistency_MinimizeLatency{MinimizeLatency: true}}
+	}
+}
+
+func (si subjectInput) toSubjectReference() *v1.SubjectReference {
+	return &v1.SubjectReference{
+		Object:           &v1.ObjectReference{ObjectType: si.Object.ObjectType, ObjectId: si.Object.ObjectId},
+		OptionalRelation: stringOrEmpty(si.OptionalRelation),
+	}
+}
+
+type checkPermissionResult struct {
+	Permissionship         string
+	MissingRequiredContext []string
+	CheckedAt              string
+}
+
+func (gr *graphQLResolver) CheckPermission(ctx context.Context, args struct {
+	Resource    objectInput
+	Permission  string
+	Subject     subjectInput
+	Consistency *consistencyInput
+},
+) (*checkPermissionResult, error) {
+	consistency, err := args.Consistency.key()
+	if err != nil {
+		return nil, err
+	}
+
+	loader, ok := ctx.Value(checkLoaderKey{}).(*dataloader.Loader[checkKey, checkResult])
+	if !ok {
+		return nil, fmt.Errorf("missing check loader")
+	}
+
+	result, err := loader.Load(ctx, checkKey{
+		consistency:     consistency,
+		resourceType:    args.Resource.ObjectType,
+		resourceID:      args.Resource.ObjectId,
+		permission:      args.Permission,
+		subjectType:     args.Subject.Object.ObjectType,
+		subjectID:       args.Subject.Object.ObjectId,
+		subjectRelation: stringOrEmpty(args.Subject.OptionalRelation),
+	})()
+	if err != nil {
+		return nil, err
+	}
+
+	missingRequiredContext := []string{}
+	if partial := result.item.GetPartialCaveatInfo(); partial != nil {
+		missingRequiredContext = partial.MissingRequiredContext
+	}
+
+	return &checkPermissionResult{
+		Permissionship:         graphQLPermissionship(result.item.GetPermissionship().String()),
+		MissingRequiredContext: missingRequiredContext,
+		CheckedAt:              result.checkedAt.GetToken(),
+	}, nil
+}
+
+type lookupResourcesResult struct {
+	ResourceObjectId string //nolint:revive // matches the name of the GraphQL field
+	Permissionship   string
+	LookedUpAt       string
+}
+
+func (gr *graphQLResolver) LookupResources(ctx context.Context, args struct {
+	ResourceObjectType string
+	Permission         string
+	Subject            subjectInput
+	Limit              *int32
+	Consistency        *consistencyInput
+},
+) ([]*lookupResourcesResult, error) {
+	consistency, err := args.Consistency.key()
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := gr.client.LookupResources(ctx, &v1.LookupResourcesRequest{
+		Consistency:        consistency.toConsistency(),
+		ResourceObjectType: args.ResourceObjectType,
+		Permission:         args.Permission,
+		Subject:            args.Subject.toSubjectReference(),
+		OptionalLimit:      limitOrZero(args.Limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	results := []*lookupResourcesResult{}
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			return results, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		results = append(results, &lookupResourcesResult{
+			ResourceObjectId: resp.ResourceObjectId,
+			Permissionship:   graphQLPermissionship(resp.Permissionship.String()),
+			LookedUpAt:       resp.LookedUpAt.GetToken(),
+		})
+	}
+}
+
+type relationshipFilterInput struct {
+	ResourceType          string
+	OptionalResourceId    *string //nolint:revive // matches the name of the GraphQL input field
+	OptionalRelation      *string
+	OptionalSubjectFilter *struct {
+		SubjectType       string
+		OptionalSubjectId *string //nolint:revive // matches the name of the GraphQL input field
+		OptionalRelation  *string
+	}
+}
+
+type graphQLObject struct {
+	ObjectType string
+	ObjectId   string //nolint:revive // matches the name of the GraphQL field
+}
+
+type graphQLSubject struct {
+	Object           *graphQLObject
+	OptionalRelation *string
+}
+
+type graphQLRelationship struct {
+	Resource           *graphQLObject
+	Relation           string
+	Subject            *graphQLSubject
+	OptionalCaveatName *string
+	ReadAt             string
+}
+
+func (gr *graphQLResolver) ReadRelationships(ctx context.Context, args struct {
+	Filter      relationshipFilterInput
+	Limit       *int32
+	Consistency *consistencyInput
+},
+) ([]*graphQLRelationship, error) {
+	consistency, err := args.Consistency.key()
+	if err != nil {
+		return nil, err
+	}
+
+	filter := &v1.RelationshipFilter{
+		ResourceType:       args.Filter.ResourceType,
+		OptionalResourceId: stringOrEmpty(args.Filter.OptionalResourceId),
+		OptionalRelation:   stringOrEmpty(args.Filter.OptionalRelation),
+	}
+	if sf := args.Filter.OptionalSubjectFilter; sf != nil {
+		filter.OptionalSubjectFilter = &v1.SubjectFilter{
+			SubjectType:       sf.SubjectType,
+			OptionalSubjectId: stringOrEmpty(sf.OptionalSubjectId),
+		}
+		if sf.OptionalRelation != nil {
+			filter.OptionalSubjectFilter.OptionalRelation = &v1.SubjectFilter_RelationFilter{Relation: *sf.OptionalRelation}
+		}
+	}
+
+	stream, err := gr.client.ReadRelationships(ctx, &v1.ReadRelationshipsRequest{
+		Consistency:        consistency.toConsistency(),
+		RelationshipFilter: filter,
+		OptionalLimit:      limitOrZero(args.Limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	relationships := []*graphQLRelationship{}
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			return relationships, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		rel := resp.Relationship
+		relationship := &graphQLRelationship{
+			Resource: &graphQLObject{ObjectType: rel.Resource.ObjectType, ObjectId: rel.Resource.ObjectId},
+			Relation: rel.Relation,
+			Subject: &graphQLSubject{
+				Object: &graphQLObject{ObjectType: rel.Subject.Object.ObjectType, ObjectId: rel.Subject.Object.ObjectId},
+			},
+			ReadAt: resp.ReadAt.GetToken(),
+		}
+		if rel.Subject.OptionalRelation != "" {
+			relationship.Subject.OptionalRelation = &rel.Subject.OptionalRelation
+		}
+		if rel.OptionalCaveat != nil {
+			relationship.OptionalCaveatName = &rel.OptionalCaveat.CaveatName
+		}
+		relationships = append(relationships, relationship)
+	}
+}
+
+// graphQLPermissionship returns the GraphQL enum value of a permissionship of the v1 API.
+func graphQLPermissionship(permissionship string) string {
+	return strings.TrimPrefix(strings.TrimPrefix(permissionship, "LOOKUP_"), "PERMISSIONSHIP_")
+}
+
+func stringOrEmpty(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func limitOrZero(limit *int32) uint32 {
+	if limit == nil || *limit < 0 {
+		return 0
+	}
+	return uint32(*limit)
+}
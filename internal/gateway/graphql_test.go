@@ -0,0 +1,205 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/dustin/go-humanize"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/authzed/spicedb/internal/grpchelpers"
+)
+
+// fakePermissionsServer grants the view permission on the resources whose ID starts with "public",
+// recording the CheckBulkPermissions requests it receives.
+type fakePermissionsServer struct {
+	v1.UnimplementedPermissionsServiceServer
+
+	lock               sync.Mutex
+	bulkCheckRequests  []*v1.CheckBulkPermissionsRequest
+	authorizationSeen  []string
+	relationships      []*v1.Relationship
+	lookupResourcesIDs []string
+}
+
+func (fps *fakePermissionsServer) CheckBulkPermissions(ctx context.Context, req *v1.CheckBulkPermissionsRequest) (*v1.CheckBulkPermissionsResponse, error) {
+	fps.lock.Lock()
+	defer fps.lock.Unlock()
+
+	fps.bulkCheckRequests = append(fps.bulkCheckRequests, req)
+	md, _ := metadata.FromIncomingContext(ctx)
+	fps.authorizationSeen = append(fps.authorizationSeen, md.Get("authorization")...)
+
+	pairs := make([]*v1.CheckBulkPermissionsPair, 0, len(req.Items))
+	for _, item := range req.Items {
+		permissionship := v1.CheckPermissionResponse_PERMISSIONSHIP_NO_PERMISSION
+		if strings.HasPrefix(item.Resource.ObjectId, "public") {
+			permissionship = v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION
+		}
+		pairs = append(pairs, &v1.CheckBulkPermissionsPair{
+			Request: item,
+			Response: &v1.CheckBulkPermissionsPair_Item{Item: &v1.CheckBulkPermissionsResponseItem{
+				Permissionship: permissionship,
+			}},
+		})
+	}
+	return &v1.CheckBulkPermissionsResponse{CheckedAt: &v1.ZedToken{Token: "checked"}, Pairs: pairs}, nil
+}
+
+func (fps *fakePermissionsServer) LookupResources(_ *v1.LookupResourcesRequest, stream v1.PermissionsService_LookupResourcesServer) error {
+	for _, id := range fps.lookupResourcesIDs {
+		if err := stream.Send(&v1.LookupResourcesResponse{
+			LookedUpAt:       &v1.ZedToken{Token: "lookedup"},
+			ResourceObjectId: id,
+			Permissionship:   v1.LookupPermissionship_LOOKUP_PERMISSIONSHIP_HAS_PERMISSION,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (fps *fakePermissionsServer) ReadRelationships(req *v1.ReadRelationshipsRequest, stream v1.PermissionsService_ReadRelationshipsServer) error {
+	for _, rel := range fps.relationships {
+		if rel.Resource.ObjectType != req.RelationshipFilter.ResourceType {
+			continue
+		}
+		if err := stream.Send(&v1.ReadRelationshipsResponse{ReadAt: &v1.ZedToken{Token: "read"}, Relationship: rel}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newTestGraphQLHandler(t *testing.T, fps *fakePermissionsServer) *graphQLHandler {
+	listener := bufconn.Listen(humanize.MiByte)
+	s := grpc.NewServer()
+	v1.RegisterPermissionsServiceServer(s, fps)
+	go func() {
+		// Ignore any errors
+		_ = s.Serve(listener)
+	}()
+
+	conn, err := grpchelpers.DialAndWait(
+		context.Background(),
+		"",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return listener.Dial()
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		conn.Close()
+		listener.Close()
+		s.Stop()
+	})
+
+	handler, err := newGraphQLHandler(v1.NewPermissionsServiceClient(conn))
+	require.NoError(t, err)
+	return handler
+}
+
+func execGraphQL(t *testing.T, handler http.Handler, query string) map[string]any {
+	body, err := json.Marshal(map[string]any{"query": query})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(string(body)))
+	req.Header.Set("Authorization", "Bearer sometoken")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var resp struct {
+		Data   map[string]any `json:"data"`
+		Errors []any          `json:"errors"`
+	}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+	require.Empty(t, resp.Errors)
+	return resp.Data
+}
+
+func TestGraphQLCheckPermissionBatching(t *testing.T) {
+	fps := &fakePermissionsServer{}
+	handler := newTestGraphQLHandler(t, fps)
+
+	data := execGraphQL(t, handler, `{
+		first: checkPermission(resource: {objectType: "document", objectId: "public1"}, permission: "view", subject: {object: {objectType: "user", objectId: "tom"}}) { permissionship checkedAt }
+		second: checkPermission(resource: {objectType: "document", objectId: "private"}, permission: "view", subject: {object: {objectType: "user", objectId: "tom"}}) { permissionship }
+		third: checkPermission(resource: {objectType: "document", objectId: "public2"}, permission: "view", subject: {object: {objectType: "user", objectId: "tom"}}) { permissionship }
+		consistent: checkPermission(resource: {objectType: "document", objectId: "public3"}, permission: "view", subject: {object: {objectType: "user", objectId: "tom"}}, consistency: {fullyConsistent: true}) { permissionship }
+	}`)
+
+	require.Equal(t, map[string]any{"permissionship": "HAS_PERMISSION", "checkedAt": "checked"}, data["first"])
+	require.Equal(t, map[string]any{"permissionship": "NO_PERMISSION"}, data["second"])
+	require.Equal(t, map[string]any{"permissionship": "HAS_PERMISSION"}, data["third"])
+	require.Equal(t, map[string]any{"permissionship": "HAS_PERMISSION"}, data["consistent"])
+
+	// The checks are batched into a request per consistency.
+	require.Len(t, fps.bulkCheckRequests, 2)
+	var itemCounts []int
+	for _, req := range fps.bulkCheckRequests {
+		itemCounts = append(itemCounts, len(req.Items))
+	}
+	require.ElementsMatch(t, []int{3, 1}, itemCounts)
+	require.Equal(t, []string{"Bearer sometoken", "Bearer sometoken"}, fps.authorizationSeen)
+}
+
+func TestGraphQLLookupAndReadQueries(t *testing.T) {
+	fps := &fakePermissionsServer{
+		lookupResourcesIDs: []string{"doc1", "doc2"},
+		relationships: []*v1.Relationship{{
+			Resource: &v1.ObjectReference{ObjectType: "document", ObjectId: "doc1"},
+			Relation: "viewer",
+			Subject: &v1.SubjectReference{
+				Object:           &v1.ObjectReference{ObjectType: "group", ObjectId: "eng"},
+				OptionalRelation: "member",
+			},
+		}},
+	}
+	handler := newTestGraphQLHandler(t, fps)
+
+	data := execGraphQL(t, handler, `{
+		lookupResources(resourceObjectType: "document", permission: "view", subject: {object: {objectType: "user", objectId: "tom"}}, limit: 10) { resourceObjectId permissionship }
+		readRelationships(filter: {resourceType: "document"}) { resource { objectId } relation subject { object { objectType objectId } optionalRelation } optionalCaveatName }
+	}`)
+
+	require.Equal(t, []any{
+		map[string]any{"resourceObjectId": "doc1", "permissionship": "HAS_PERMISSION"},
+		map[string]any{"resourceObjectId": "doc2", "permissionship": "HAS_PERMISSION"},
+	}, data["lookupResources"])
+	require.Equal(t, []any{map[string]any{
+		"resource": map[string]any{"objectId": "doc1"},
+		"relation": "viewer",
+		"subject": map[string]any{
+			"object":           map[string]any{"objectType": "group", "objectId": "eng"},
+			"optionalRelation": "member",
+		},
+		"optionalCaveatName": nil,
+	}}, data["readRelationships"])
+}
+
+func TestGraphQLRejectsInvalidRequests(t *testing.T) {
+	handler := newTestGraphQLHandler(t, &fakePermissionsServer{})
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/graphql", nil))
+	require.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+
+	body := `{"query": "{ checkPermission(resource: {objectType: \"document\", objectId: \"doc\"}, permission: \"view\", subject: {object: {objectType: \"user\", objectId: \"tom\"}}, consistency: {fullyConsistent: true, atLeastAsFresh: \"token\"}) { permissionship } }"}`
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(body)))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Contains(t, recorder.Body.String(), "at most one consistency requirement can be set")
+}
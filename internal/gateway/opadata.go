@@ -0,0 +1,413 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/cenkalti/backoff/v4"
+	"github.com/jzelinskie/stringz"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	yamlv3 "gopkg.in/yaml.v3"
+
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// opaDataPathPrefix is the path under which the OPA data documents are served: all of them at
+// the prefix itself, keyed by name, and each at the prefix followed by its name.
+const opaDataPathPrefix = "/opa/data"
+
+// OPADocument is a data document served to OPA, holding the results of a lookup. Documents are
+// read from YAML:
+//
+//	documents:
+//	  - name: readme_viewers
+//	    lookupSubjects:
+//	      resource: document:readme
+//	      permission: view
+//	      subjectType: user
+//	  - name: alice_documents
+//	    lookupResources:
+//	      resourceType: document
+//	      permission: view
+//	      subject: user:alice
+type OPADocument struct {
+	// Name is the name of the document, under which it is served.
+	Name string `yaml:"name"`
+
+	// Exactly one of LookupResources and LookupSubjects is set.
+	LookupResources *OPALookupResources `yaml:"lookupResources"`
+	LookupSubjects  *OPALookupSubjects  `yaml:"lookupSubjects"`
+}
+
+// OPALookupResources looks up the resources of a type on which the subject, of the form `type:id`
+// or `type:id#relation`, has the permission.
+type OPALookupResources struct {
+	ResourceType string `yaml:"resourceType"`
+	Permission   string `yaml:"permission"`
+	Subject      string `yaml:"subject"`
+}
+
+// OPALookupSubjects looks up the subjects of a type, and optionally relation, which have the
+// permission on the resource, of the form `type:id`.
+type OPALookupSubjects struct {
+	Resource        string `yaml:"resource"`
+	Permission      string `yaml:"permission"`
+	SubjectType     string `yaml:"subjectType"`
+	SubjectRelation string `yaml:"subjectRelation"`
+}
+
+// LoadOPADocuments reads the OPA data documents from the YAML file at the path.
+func LoadOPADocuments(path string) ([]OPADocument, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read OPA data documents: %w", err)
+	}
+
+	decoder := yamlv3.NewDecoder(bytes.NewReader(contents))
+	decoder.KnownFields(true)
+
+	var config struct {
+		Documents []OPADocument `yaml:"documents"`
+	}
+	if err := decoder.Decode(&config); err != nil {
+		return nil, fmt.Errorf("failed to parse OPA data documents: %w", err)
+	}
+
+	names := map[string]struct{}{}
+	for _, document := range config.Documents {
+		if err := document.validate(); err != nil {
+			return nil, fmt.Errorf("invalid OPA data document `%s`: %w", document.Name, err)
+		}
+		if _, ok := names[document.Name]; ok {
+			return nil, fmt.Errorf("duplicate OPA data document `%s`", document.Name)
+		}
+		names[document.Name] = struct{}{}
+	}
+	return config.Documents, nil
+}
+
+func (d OPADocument) validate() error {
+	if d.Name == "" || strings.Contains(d.Name, "/") {
+		return errors.New("a name without `/` is required")
+	}
+
+	switch {
+	case d.LookupResources != nil && d.LookupSubjects != nil, d.LookupResources == nil && d.LookupSubjects == nil:
+		return errors.New("exactly one of lookupResources and lookupSubjects is required")
+	case d.LookupResources != nil:
+		if d.LookupResources.ResourceType == "" || d.LookupResources.Permission == "" {
+			return errors.New("resource type and permission are required")
+		}
+		_, err := tuple.ParseSubjectONR(d.LookupResources.Subject)
+		return err
+	default:
+		if d.LookupSubjects.SubjectType == "" || d.LookupSubjects.Permission == "" {
+			return errors.New("subject type and permission are required")
+		}
+		_, err := tuple.ParseONR(d.LookupSubjects.Resource + "#" + d.LookupSubjects.Permission)
+		return err
+	}
+}
+
+// opaDataDocument is the JSON of a document: the IDs of the objects found by its lookup, and
+// separately those found conditionally on caveats, which OPA cannot evaluate.
+type opaDataDocument struct {
+	IDs            []string `json:"ids"`
+	ConditionalIDs []string `json:"conditionalIds"`
+}
+
+// opaDataHandler serves the OPA data documents. Documents are computed on demand and cached
+// until a change is received from the Watch API, which is watched in the background, so that
+// requests with the ETag of a document are answered with 304 Not Modified until a change alters
+// it. While the watch is not established, such as before a schema is written, documents are
+// computed for every request. Schema changes are not received from the watch, so documents
+// altered by one alone are refreshed on the next relationship change.
+type opaDataHandler struct {
+	permissions   v1.PermissionsServiceClient
+	schema        v1.SchemaServiceClient
+	watch         v1.WatchServiceClient
+	documents     []OPADocument
+	presharedKeys []string
+	cancel        context.CancelFunc
+
+	lock sync.Mutex
+	// watching is whether the watch is established, and cached documents are kept.
+	watching bool
+	// generation is incremented by every change, so that documents computed concurrently are not
+	// cached.
+	generation uint64
+	// changesThrough is the revision through which changes have been received.
+	changesThrough *v1.ZedToken
+	cached         map[string][]byte
+}
+
+func newOPADataHandler(ctx context.Context, conn grpc.ClientConnInterface, documents []OPADocument, presharedKeys []string) *opaDataHandler {
+	ctx, cancel := context.WithCancel(ctx)
+	odh := &opaDataHandler{
+		permissions:   v1.NewPermissionsServiceClient(conn),
+		schema:        v1.NewSchemaServiceClient(conn),
+		watch:         v1.NewWatchServiceClient(conn),
+		documents:     documents,
+		presharedKeys: presharedKeys,
+		cancel:        cancel,
+		cached:        map[string][]byte{},
+	}
+	go odh.watchChanges(odh.outgoingContext(ctx))
+	return odh
+}
+
+// Close stops watching changes.
+func (odh *opaDataHandler) Close() error {
+	odh.cancel()
+	return nil
+}
+
+// outgoingContext returns the context of the calls made to the upstream, authenticated with the
+// first preshared key.
+func (odh *opaDataHandler) outgoingContext(ctx context.Context) context.Context {
+	if len(odh.presharedKeys) == 0 {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+odh.presharedKeys[0])
+}
+
+func (odh *opaDataHandler) watchChanges(ctx context.Context) {
+	retry := backoff.NewExponentialBackOff()
+	retry.MaxElapsedTime = 0
+	for ctx.Err() == nil {
+		err := odh.watchOnce(ctx, retry)
+		odh.invalidate(false, nil)
+		if ctx.Err() != nil {
+			return
+		}
+
+		wait := retry.NextBackOff()
+		log.Ctx(ctx).Warn().Err(err).Dur("retry-in", wait).Msg("OPA data watch failed; documents are computed for every request until it is reestablished")
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+func (odh *opaDataHandler) watchOnce(ctx context.Context, retry backoff.BackOff) error {
+	// The watch starts at the revision at which the schema is read, and documents are computed at
+	// least as fresh from then on, so that no change to a cached document can be missed.
+	schemaResp, err := odh.schema.ReadSchema(ctx, &v1.ReadSchemaRequest{})
+	if err != nil {
+		return err
+	}
+
+	stream, err := odh.watch.Watch(ctx, &v1.WatchRequest{OptionalStartCursor: schemaResp.ReadAt})
+	if err != nil {
+		return err
+	}
+
+	odh.invalidate(true, schemaResp.ReadAt)
+	for {
+		resp, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		retry.Reset()
+		odh.invalidate(true, resp.ChangesThrough)
+	}
+}
+
+// invalidate drops the cached documents, recording whether the watch is established and the
+// revision through which changes were received, if any.
+func (odh *opaDataHandler) invalidate(watching bool, changesThrough *v1.ZedToken) {
+	odh.lock.Lock()
+	defer odh.lock.Unlock()
+
+	odh.watching = watching
+	odh.generation++
+	if changesThrough != nil {
+		odh.changesThrough = changesThrough
+	}
+	clear(odh.cached)
+}
+
+func (odh *opaDataHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "OPA data documents must be fetched with GET", http.StatusMethodNotAllowed)
+		return
+	}
+	if !odh.authorized(r) {
+		http.Error(w, "a valid preshared key is required", http.StatusUnauthorized)
+		return
+	}
+
+	name := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, opaDataPathPrefix), "/")
+	var body []byte
+	var err error
+	if name == "" {
+		body, err = odh.allDocuments(r.Context())
+	} else {
+		index := slices.IndexFunc(odh.documents, func(document OPADocument) bool { return document.Name == name })
+		if index < 0 {
+			http.Error(w, fmt.Sprintf("unknown OPA data document `%s`", name), http.StatusNotFound)
+			return
+		}
+		body, err = odh.document(r.Context(), odh.documents[index])
+	}
+	if err != nil {
+		log.Ctx(r.Context()).Warn().Err(err).Str("document", name).Msg("failed to compute OPA data document")
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	hash := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(hash[:16]) + `"`
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "no-cache")
+	if slices.ContainsFunc(strings.Split(r.Header.Get("If-None-Match"), ","), func(tag string) bool { return strings.TrimSpace(tag) == etag }) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(body)
+}
+
+func (odh *opaDataHandler) authorized(r *http.Request) bool {
+	if len(odh.presharedKeys) == 0 {
+		return true
+	}
+
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return false
+	}
+	return slices.ContainsFunc(odh.presharedKeys, func(key string) bool {
+		return subtle.ConstantTimeCompare([]byte(key), []byte(token)) == 1
+	})
+}
+
+// allDocuments returns the JSON object of all the documents, keyed by name.
+func (odh *opaDataHandler) allDocuments(ctx context.Context) ([]byte, error) {
+	all := make(map[string]json.RawMessage, len(odh.documents))
+	for _, document := range odh.documents {
+		body, err := odh.document(ctx, document)
+		if err != nil {
+			return nil, err
+		}
+		all[document.Name] = body
+	}
+	return json.Marshal(all)
+}
+
+// document returns the JSON of the document, from the cache if no change was received since it
+// was computed.
+func (odh *opaDataHandler) document(ctx context.Context, document OPADocument) ([]byte, error) {
+	odh.lock.Lock()
+	body, ok := odh.cached[document.Name]
+	generation := odh.generation
+	consistency := &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}}
+	if odh.changesThrough != nil {
+		consistency = &v1.Consistency{Requirement: &v1.Consistency_AtLeastAsFresh{AtLeastAsFresh: odh.changesThrough}}
+	}
+	odh.lock.Unlock()
+	if ok {
+		return body, nil
+	}
+
+	result, err := odh.lookup(odh.outgoingContext(ctx), document, consistency)
+	if err != nil {
+		return nil, err
+	}
+
+	slices.Sort(result.IDs)
+	slices.Sort(result.ConditionalIDs)
+	body, err = json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+
+	odh.lock.Lock()
+	defer odh.lock.Unlock()
+	if odh.watching && odh.generation == generation {
+		odh.cached[document.Name] = body
+	}
+	return body, nil
+}
+
+func (odh *opaDataHandler) lookup(ctx context.Context, document OPADocument, consistency *v1.Consistency) (opaDataDocument, error) {
+	result := opaDataDocument{IDs: []string{}, ConditionalIDs: []string{}}
+	if lr := document.LookupResources; lr != nil {
+		subject := tuple.MustParseSubjectONR(lr.Subject)
+		stream, err := odh.permissions.LookupResources(ctx, &v1.LookupResourcesRequest{
+			Consistency:        consistency,
+			ResourceObjectType: lr.ResourceType,
+			Permission:         lr.Permission,
+			Subject: &v1.SubjectReference{
+				Object:           &v1.ObjectReference{ObjectType: subject.ObjectType, ObjectId: subject.ObjectID},
+				OptionalRelation: stringz.Default(subject.Relation, "", tuple.Ellipsis),
+			},
+		})
+		if err != nil {
+			return result, err
+		}
+
+		for {
+			resp, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				return result, nil
+			} else if err != nil {
+				return result, err
+			}
+
+			if resp.Permissionship == v1.LookupPermissionship_LOOKUP_PERMISSIONSHIP_CONDITIONAL_PERMISSION {
+				result.ConditionalIDs = append(result.ConditionalIDs, resp.ResourceObjectId)
+			} else {
+				result.IDs = append(result.IDs, resp.ResourceObjectId)
+			}
+		}
+	}
+
+	ls := document.LookupSubjects
+	resourceType, resourceID, _ := strings.Cut(ls.Resource, ":")
+	stream, err := odh.permissions.LookupSubjects(ctx, &v1.LookupSubjectsRequest{
+		Consistency:             consistency,
+		Resource:                &v1.ObjectReference{ObjectType: resourceType, ObjectId: resourceID},
+		Permission:              ls.Permission,
+		SubjectObjectType:       ls.SubjectType,
+		OptionalSubjectRelation: ls.SubjectRelation,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return result, nil
+		} else if err != nil {
+			return result, err
+		}
+
+		if resp.Subject.Permissionship == v1.LookupPermissionship_LOOKUP_PERMISSIONSHIP_CONDITIONAL_PERMISSION {
+			result.ConditionalIDs = append(result.ConditionalIDs, resp.Subject.SubjectObjectId)
+		} else {
+			result.IDs = append(result.IDs, resp.Subject.SubjectObjectId)
+		}
+	}
+}
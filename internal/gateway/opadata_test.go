@@ -0,0 +1,271 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/dustin/go-humanize"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/authzed/spicedb/internal/grpchelpers"
+)
+
+// fakeOPAPermissionsServer answers lookups with its IDs, recording the consistency of each.
+type fakeOPAPermissionsServer struct {
+	v1.UnimplementedPermissionsServiceServer
+
+	lock              sync.Mutex
+	resourceIDs       []string
+	subjectIDs        []string
+	consistencies     []*v1.Consistency
+	authorizationSeen []string
+}
+
+func (fps *fakeOPAPermissionsServer) record(ctx context.Context, consistency *v1.Consistency) {
+	fps.consistencies = append(fps.consistencies, consistency)
+	md, _ := metadata.FromIncomingContext(ctx)
+	fps.authorizationSeen = append(fps.authorizationSeen, md.Get("authorization")...)
+}
+
+func (fps *fakeOPAPermissionsServer) LookupResources(req *v1.LookupResourcesRequest, stream v1.PermissionsService_LookupResourcesServer) error {
+	fps.lock.Lock()
+	defer fps.lock.Unlock()
+	fps.record(stream.Context(), req.Consistency)
+
+	for _, id := range fps.resourceIDs {
+		permissionship := v1.LookupPermissionship_LOOKUP_PERMISSIONSHIP_HAS_PERMISSION
+		if id == "conditional" {
+			permissionship = v1.LookupPermissionship_LOOKUP_PERMISSIONSHIP_CONDITIONAL_PERMISSION
+		}
+		if err := stream.Send(&v1.LookupResourcesResponse{ResourceObjectId: id, Permissionship: permissionship}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (fps *fakeOPAPermissionsServer) LookupSubjects(req *v1.LookupSubjectsRequest, stream v1.PermissionsService_LookupSubjectsServer) error {
+	fps.lock.Lock()
+	defer fps.lock.Unlock()
+	fps.record(stream.Context(), req.Consistency)
+
+	for _, id := range fps.subjectIDs {
+		if err := stream.Send(&v1.LookupSubjectsResponse{Subject: &v1.ResolvedSubject{
+			SubjectObjectId: id,
+			Permissionship:  v1.LookupPermissionship_LOOKUP_PERMISSIONSHIP_HAS_PERMISSION,
+		}}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type fakeOPASchemaServer struct {
+	v1.UnimplementedSchemaServiceServer
+}
+
+func (fakeOPASchemaServer) ReadSchema(context.Context, *v1.ReadSchemaRequest) (*v1.ReadSchemaResponse, error) {
+	return &v1.ReadSchemaResponse{ReadAt: &v1.ZedToken{Token: "schema"}}, nil
+}
+
+// fakeOPAWatchServer sends the responses of its channel, recording the start cursors of watches.
+type fakeOPAWatchServer struct {
+	v1.UnimplementedWatchServiceServer
+
+	lock         sync.Mutex
+	startCursors []*v1.ZedToken
+	changes      chan *v1.WatchResponse
+}
+
+func (fws *fakeOPAWatchServer) Watch(req *v1.WatchRequest, stream v1.WatchService_WatchServer) error {
+	fws.lock.Lock()
+	fws.startCursors = append(fws.startCursors, req.OptionalStartCursor)
+	fws.lock.Unlock()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case resp := <-fws.changes:
+			if err := stream.Send(resp); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+const testOPADocuments = `
+documents:
+  - name: readme_viewers
+    lookupSubjects:
+      resource: document:readme
+      permission: view
+      subjectType: user
+  - name: alice_documents
+    lookupResources:
+      resourceType: document
+      permission: view
+      subject: user:alice
+`
+
+func writeOPADocuments(t *testing.T, contents string) string {
+	path := filepath.Join(t.TempDir(), "documents.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0o600))
+	return path
+}
+
+func newTestOPADataHandler(t *testing.T, fps *fakeOPAPermissionsServer, fws *fakeOPAWatchServer) *opaDataHandler {
+	listener := bufconn.Listen(humanize.MiByte)
+	s := grpc.NewServer()
+	v1.RegisterPermissionsServiceServer(s, fps)
+	v1.RegisterSchemaServiceServer(s, fakeOPASchemaServer{})
+	v1.RegisterWatchServiceServer(s, fws)
+	go func() {
+		// Ignore any errors
+		_ = s.Serve(listener)
+	}()
+
+	conn, err := grpchelpers.DialAndWait(
+		context.Background(),
+		"",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return listener.Dial()
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+
+	documents, err := LoadOPADocuments(writeOPADocuments(t, testOPADocuments))
+	require.NoError(t, err)
+
+	handler := newOPADataHandler(context.Background(), conn, documents, []string{"upstreamkey", "otherkey"})
+	t.Cleanup(func() {
+		handler.Close()
+		conn.Close()
+		listener.Close()
+		s.Stop()
+	})
+
+	require.Eventually(t, func() bool {
+		handler.lock.Lock()
+		defer handler.lock.Unlock()
+		return handler.watching
+	}, 5*time.Second, 10*time.Millisecond)
+	return handler
+}
+
+func getOPAData(handler http.Handler, path, key, etag string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if key != "" {
+		req.Header.Set("Authorization", "Bearer "+key)
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestOPADataETagRefreshedByWatch(t *testing.T) {
+	fps := &fakeOPAPermissionsServer{resourceIDs: []string{"doc2", "conditional", "doc1"}}
+	fws := &fakeOPAWatchServer{changes: make(chan *v1.WatchResponse)}
+	handler := newTestOPADataHandler(t, fps, fws)
+	fws.lock.Lock()
+	require.Len(t, fws.startCursors, 1)
+	require.Equal(t, "schema", fws.startCursors[0].Token)
+	fws.lock.Unlock()
+
+	resp := getOPAData(handler, "/opa/data/alice_documents", "otherkey", "")
+	require.Equal(t, http.StatusOK, resp.Code)
+	require.JSONEq(t, `{"ids": ["doc1", "doc2"], "conditionalIds": ["conditional"]}`, resp.Body.String())
+	etag := resp.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	// Until a change is received, the document is served from the cache.
+	resp = getOPAData(handler, "/opa/data/alice_documents", "otherkey", etag)
+	require.Equal(t, http.StatusNotModified, resp.Code)
+	fps.lock.Lock()
+	require.Len(t, fps.consistencies, 1)
+	require.Equal(t, "schema", fps.consistencies[0].GetAtLeastAsFresh().GetToken())
+	require.Equal(t, []string{"Bearer upstreamkey"}, fps.authorizationSeen)
+	fps.resourceIDs = []string{"doc1", "doc3"}
+	fps.lock.Unlock()
+
+	fws.changes <- &v1.WatchResponse{
+		Updates:        []*v1.RelationshipUpdate{{Operation: v1.RelationshipUpdate_OPERATION_TOUCH}},
+		ChangesThrough: &v1.ZedToken{Token: "changed"},
+	}
+	require.Eventually(t, func() bool {
+		return getOPAData(handler, "/opa/data/alice_documents", "otherkey", etag).Code == http.StatusOK
+	}, 5*time.Second, 10*time.Millisecond)
+
+	resp = getOPAData(handler, "/opa/data/alice_documents", "otherkey", etag)
+	require.JSONEq(t, `{"ids": ["doc1", "doc3"], "conditionalIds": []}`, resp.Body.String())
+	require.NotEqual(t, etag, resp.Header().Get("ETag"))
+
+	fps.lock.Lock()
+	defer fps.lock.Unlock()
+	require.Equal(t, "changed", fps.consistencies[len(fps.consistencies)-1].GetAtLeastAsFresh().GetToken())
+}
+
+func TestOPADataAllDocuments(t *testing.T) {
+	fps := &fakeOPAPermissionsServer{resourceIDs: []string{"readme"}, subjectIDs: []string{"bob", "alice"}}
+	handler := newTestOPADataHandler(t, fps, &fakeOPAWatchServer{changes: make(chan *v1.WatchResponse)})
+
+	resp := getOPAData(handler, "/opa/data", "upstreamkey", "")
+	require.Equal(t, http.StatusOK, resp.Code)
+	require.Equal(t, "application/json", resp.Header().Get("Content-Type"))
+
+	var documents map[string]opaDataDocument
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &documents))
+	require.Equal(t, map[string]opaDataDocument{
+		"readme_viewers":  {IDs: []string{"alice", "bob"}, ConditionalIDs: []string{}},
+		"alice_documents": {IDs: []string{"readme"}, ConditionalIDs: []string{}},
+	}, documents)
+}
+
+func TestOPADataRejectsInvalidRequests(t *testing.T) {
+	handler := newTestOPADataHandler(t, &fakeOPAPermissionsServer{}, &fakeOPAWatchServer{changes: make(chan *v1.WatchResponse)})
+
+	require.Equal(t, http.StatusUnauthorized, getOPAData(handler, "/opa/data/alice_documents", "", "").Code)
+	require.Equal(t, http.StatusUnauthorized, getOPAData(handler, "/opa/data/alice_documents", "wrongkey", "").Code)
+	require.Equal(t, http.StatusNotFound, getOPAData(handler, "/opa/data/unknown", "upstreamkey", "").Code)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/opa/data", nil))
+	require.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+}
+
+func TestLoadOPADocumentsErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name          string
+		documents     string
+		expectedError string
+	}{
+		{"missing name", "documents: [{lookupResources: {resourceType: document, permission: view, subject: 'user:alice'}}]", "a name without `/` is required"},
+		{"no lookup", "documents: [{name: docs}]", "exactly one of lookupResources and lookupSubjects"},
+		{"invalid subject", "documents: [{name: docs, lookupResources: {resourceType: document, permission: view, subject: alice}}]", "invalid OPA data document `docs`"},
+		{"missing subject type", "documents: [{name: viewers, lookupSubjects: {resource: 'document:readme', permission: view}}]", "subject type and permission are required"},
+		{"duplicate", "documents: [{name: viewers, lookupSubjects: {resource: 'document:readme', permission: view, subjectType: user}}, {name: viewers, lookupSubjects: {resource: 'document:other', permission: view, subjectType: user}}]", "duplicate OPA data document"},
+		{"unknown field", "documents: [{name: viewers, query: all}]", "field query not found"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := LoadOPADocuments(writeOPADocuments(t, tc.documents))
+			require.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
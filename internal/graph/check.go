@@ -49,11 +49,13 @@ const noOriginalRelation = ""
 func init() {
 	prometheus.MustRegister(directDispatchQueryHistogram)
 	prometheus.MustRegister(dispatchChunkCountHistogram)
+	prometheus.MustRegister(plannedLeadingBranchCounter)
 }
 
-// NewConcurrentChecker creates an instance of ConcurrentChecker.
-func NewConcurrentChecker(d dispatch.Check, concurrencyLimit uint16, dispatchChunkSize uint16) *ConcurrentChecker {
-	return &ConcurrentChecker{d, concurrencyLimit, dispatchChunkSize}
+// NewConcurrentChecker creates an instance of ConcurrentChecker. If planner is non-nil, it is
+// used to order the evaluation of the branches of union and intersection rewrites.
+func NewConcurrentChecker(d dispatch.Check, concurrencyLimit uint16, dispatchChunkSize uint16, planner *EvaluationPlanner) *ConcurrentChecker {
+	return &ConcurrentChecker{d, concurrencyLimit, dispatchChunkSize, planner}
 }
 
 // ConcurrentChecker exposes a method to perform Check requests, and delegates subproblems to the
@@ -62,6 +64,7 @@ type ConcurrentChecker struct {
 	d                 dispatch.Check
 	concurrencyLimit  uint16
 	dispatchChunkSize uint16
+	planner           *EvaluationPlanner
 }
 
 // ValidatedCheckRequest represents a request after it has been validated and parsed for internal
@@ -418,10 +421,11 @@ func (cc *ConcurrentChecker) checkDirect(ctx context.Context, crc currentRequest
 		}
 
 		filter := datastore.RelationshipsFilter{
-			OptionalResourceType:      crc.parentReq.ResourceRelation.Namespace,
-			OptionalResourceIds:       crc.filteredResourceIDs,
-			OptionalResourceRelation:  crc.parentReq.ResourceRelation.Relation,
-			OptionalSubjectsSelectors: subjectSelectors,
+			OptionalResourceType:        crc.parentReq.ResourceRelation.Namespace,
+			OptionalResourceIds:         crc.filteredResourceIDs,
+			OptionalResourceRelation:    crc.parentReq.ResourceRelation.Relation,
+			OptionalSubjectsSelectors:   subjectSelectors,
+			OptionalExcludedCaveatNames: crc.parentReq.ExcludedCaveatNames,
 		}
 
 		it, err := ds.QueryRelationships(ctx, filter,
@@ -474,6 +478,7 @@ func (cc *ConcurrentChecker) checkDirect(ctx context.Context, crc currentRequest
 				RelationFilter: datastore.SubjectRelationFilter{}.WithOnlyNonEllipsisRelations(),
 			},
 		},
+		OptionalExcludedCaveatNames: crc.parentReq.ExcludedCaveatNames,
 	}
 
 	it, err := ds.QueryRelationships(ctx, filter,
@@ -512,9 +517,10 @@ func (cc *ConcurrentChecker) checkDirect(ctx context.Context, crc currentRequest
 				Subject:          crc.parentReq.Subject,
 				ResultsSetting:   resultsSetting,
 
-				Metadata:   decrementDepth(crc.parentReq.Metadata),
-				Debug:      crc.parentReq.Debug,
-				CheckHints: crc.parentReq.CheckHints,
+				Metadata:            decrementDepth(crc.parentReq.Metadata),
+				Debug:               crc.parentReq.Debug,
+				CheckHints:          crc.parentReq.CheckHints,
+				ExcludedCaveatNames: crc.parentReq.ExcludedCaveatNames,
 			},
 			crc.parentReq.Revision,
 			noOriginalRelation,
@@ -560,10 +566,16 @@ func (cc *ConcurrentChecker) checkUsersetRewrite(ctx context.Context, crc curren
 			ctx, span = tracer.Start(ctx, "+")
 			defer span.End()
 		}
+		if cc.planner != nil && len(rw.Union.Child) > 1 {
+			return cc.planner.union(ctx, crc, rw.Union.Child, cc.runSetOperation, cc.concurrencyLimit)
+		}
 		return union(ctx, crc, rw.Union.Child, cc.runSetOperation, cc.concurrencyLimit)
 	case *core.UsersetRewrite_Intersection:
 		ctx, span := tracer.Start(ctx, "&")
 		defer span.End()
+		if cc.planner != nil && len(rw.Intersection.Child) > 1 {
+			return cc.planner.all(ctx, crc, rw.Intersection.Child, cc.runSetOperation, cc.concurrencyLimit)
+		}
 		return all(ctx, crc, rw.Intersection.Child, cc.runSetOperation, cc.concurrencyLimit)
 	case *core.UsersetRewrite_Exclusion:
 		ctx, span := tracer.Start(ctx, "-")
@@ -659,13 +671,14 @@ func (cc *ConcurrentChecker) checkComputedUserset(ctx context.Context, crc curre
 
 	result := cc.dispatch(ctx, crc, ValidatedCheckRequest{
 		&v1.DispatchCheckRequest{
-			ResourceRelation: targetRR,
-			ResourceIds:      updatedTargetResourceIds,
-			Subject:          crc.parentReq.Subject,
-			ResultsSetting:   crc.resultsSetting,
-			Metadata:         decrementDepth(crc.parentReq.Metadata),
-			Debug:            crc.parentReq.Debug,
-			CheckHints:       crc.parentReq.CheckHints,
+			ResourceRelation:    targetRR,
+			ResourceIds:         updatedTargetResourceIds,
+			Subject:             crc.parentReq.Subject,
+			ResultsSetting:      crc.resultsSetting,
+			Metadata:            decrementDepth(crc.parentReq.Metadata),
+			Debug:               crc.parentReq.Debug,
+			CheckHints:          crc.parentReq.CheckHints,
+			ExcludedCaveatNames: crc.parentReq.ExcludedCaveatNames,
 		},
 		crc.parentReq.Revision,
 		noOriginalRelation,
@@ -796,9 +809,10 @@ func checkIntersectionTupleToUserset(
 	}
 
 	it, err := ds.QueryRelationships(ctx, datastore.RelationshipsFilter{
-		OptionalResourceType:     crc.parentReq.ResourceRelation.Namespace,
-		OptionalResourceIds:      crc.filteredResourceIDs,
-		OptionalResourceRelation: ttu.GetTupleset().GetRelation(),
+		OptionalResourceType:        crc.parentReq.ResourceRelation.Namespace,
+		OptionalResourceIds:         crc.filteredResourceIDs,
+		OptionalResourceRelation:    ttu.GetTupleset().GetRelation(),
+		OptionalExcludedCaveatNames: crc.parentReq.ExcludedCaveatNames,
 	}, queryOpts...)
 	if err != nil {
 		return checkResultError(NewCheckFailureErr(err), emptyMetadata)
@@ -963,9 +977,10 @@ func checkTupleToUserset[T relation](
 	}
 
 	it, err := ds.QueryRelationships(ctx, datastore.RelationshipsFilter{
-		OptionalResourceType:     crc.parentReq.ResourceRelation.Namespace,
-		OptionalResourceIds:      filteredResourceIDs,
-		OptionalResourceRelation: ttu.GetTupleset().GetRelation(),
+		OptionalResourceType:        crc.parentReq.ResourceRelation.Namespace,
+		OptionalResourceIds:         filteredResourceIDs,
+		OptionalResourceRelation:    ttu.GetTupleset().GetRelation(),
+		OptionalExcludedCaveatNames: crc.parentReq.ExcludedCaveatNames,
 	}, queryOpts...)
 	if err != nil {
 		return checkResultError(NewCheckFailureErr(err), emptyMetadata)
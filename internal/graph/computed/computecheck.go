@@ -47,6 +47,10 @@ type CheckParameters struct {
 	MaximumDepth  uint32
 	DebugOption   DebugOption
 	CheckHints    []*v1.CheckHint
+
+	// PrefilterCaveats indicates that relationships whose caveats evaluate to false under the
+	// CaveatContext alone should be excluded when relationships are loaded for the check.
+	PrefilterCaveats bool
 }
 
 // ComputeCheck computes a check result for the given resource and subject, computing any
@@ -109,6 +113,15 @@ func computeCheck(ctx context.Context,
 		return nil, nil, nil, spiceerrors.MustBugf("failed to create new traversal bloom filter")
 	}
 
+	var excludedCaveatNames []string
+	if params.PrefilterCaveats {
+		reader := datastoremw.MustFromContext(ctx).SnapshotReader(params.AtRevision)
+		excludedCaveatNames, err = cexpr.FalseCaveatNames(ctx, reader, params.CaveatContext)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
 	caveatRunner := cexpr.NewCaveatRunner()
 
 	// TODO(jschorr): Should we make this run in parallel via the preloadedTaskRunner?
@@ -124,8 +137,9 @@ func computeCheck(ctx context.Context,
 				DepthRemaining: params.MaximumDepth,
 				TraversalBloom: bf,
 			},
-			Debug:      debugging,
-			CheckHints: params.CheckHints,
+			Debug:               debugging,
+			CheckHints:          params.CheckHints,
+			ExcludedCaveatNames: excludedCaveatNames,
 		})
 
 		if checkResult.Metadata.DebugInfo != nil {
@@ -817,39 +817,42 @@ func TestComputeCheckWithCaveats(t *testing.T) {
 			require.NoError(t, err)
 
 			for _, r := range tt.checks {
-				r := r
-				t.Run(fmt.Sprintf("%s::%v", r.check, r.context), func(t *testing.T) {
-					rel := tuple.MustParse(r.check)
-
-					result, _, err := computed.ComputeCheck(ctx, dispatch,
-						computed.CheckParameters{
-							ResourceType:  rel.Resource.RelationReference(),
-							Subject:       rel.Subject,
-							CaveatContext: r.context,
-							AtRevision:    revision,
-							MaximumDepth:  50,
-							DebugOption:   computed.BasicDebuggingEnabled,
-						},
-						rel.Resource.ObjectID,
-						100,
-					)
-
-					if r.error != "" {
-						require.NotNil(t, err, "missing required error: %s", r.error)
-						require.Equal(t, err.Error(), r.error)
-					} else {
-						require.NoError(t, err)
-						require.Equal(t, v1.ResourceCheckResult_Membership_name[int32(r.member)], v1.ResourceCheckResult_Membership_name[int32(result.Membership)], "mismatch for %s with context %v", r.check, r.context)
-
-						if result.Membership == v1.ResourceCheckResult_CAVEATED_MEMBER {
-							foundFields := result.MissingExprFields
-							sort.Strings(foundFields)
-							sort.Strings(r.expectedMissingFields)
-
-							require.Equal(t, r.expectedMissingFields, foundFields)
+				for _, prefilterCaveats := range []bool{false, true} {
+					r := r
+					t.Run(fmt.Sprintf("%s::%v::prefilter=%v", r.check, r.context, prefilterCaveats), func(t *testing.T) {
+						rel := tuple.MustParse(r.check)
+
+						result, _, err := computed.ComputeCheck(ctx, dispatch,
+							computed.CheckParameters{
+								ResourceType:     rel.Resource.RelationReference(),
+								Subject:          rel.Subject,
+								CaveatContext:    r.context,
+								AtRevision:       revision,
+								MaximumDepth:     50,
+								DebugOption:      computed.BasicDebuggingEnabled,
+								PrefilterCaveats: prefilterCaveats,
+							},
+							rel.Resource.ObjectID,
+							100,
+						)
+
+						if r.error != "" {
+							require.NotNil(t, err, "missing required error: %s", r.error)
+							require.Equal(t, err.Error(), r.error)
+						} else {
+							require.NoError(t, err)
+							require.Equal(t, v1.ResourceCheckResult_Membership_name[int32(r.member)], v1.ResourceCheckResult_Membership_name[int32(result.Membership)], "mismatch for %s with context %v", r.check, r.context)
+
+							if result.Membership == v1.ResourceCheckResult_CAVEATED_MEMBER {
+								foundFields := result.MissingExprFields
+								sort.Strings(foundFields)
+								sort.Strings(r.expectedMissingFields)
+
+								require.Equal(t, r.expectedMissingFields, foundFields)
+							}
 						}
-					}
-				})
+					})
+				}
 			}
 		})
 	}
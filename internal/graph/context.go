@@ -7,6 +7,7 @@ import (
 
 	log "github.com/authzed/spicedb/internal/logging"
 	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
+	"github.com/authzed/spicedb/pkg/middleware/priority"
 	"github.com/authzed/spicedb/pkg/middleware/requestid"
 )
 
@@ -28,6 +29,7 @@ func branchContext(ctx context.Context) (context.Context, func(cancelErr error))
 	}
 
 	detachedContext = requestid.PropagateIfExists(ctx, detachedContext)
+	detachedContext = priority.PropagateIfExists(ctx, detachedContext)
 
 	return context.WithCancelCause(detachedContext)
 }
@@ -462,11 +462,6 @@ func (crr *CursoredLookupResources2) lookupTTUEntrypoint(ctx context.Context,
 	)
 }
 
-type possibleResourceAndIndex struct {
-	resource *v1.PossibleResource
-	index    int
-}
-
 // redispatchOrReport checks if further redispatching is necessary for the found resource
 // type. If not, and the found resource type+relation matches the target resource type+relation,
 // the resource is reported to the parent stream.
@@ -516,102 +511,25 @@ func (crr *CursoredLookupResources2) redispatchOrReport(
 						return nil
 					}
 
-					filtered := make([]possibleResourceAndIndex, 0, len(offsetted))
-					for index, resource := range offsetted {
-						filtered = append(filtered, possibleResourceAndIndex{
-							resource: resource,
-							index:    index,
-						})
-					}
-
-					metadata := emptyMetadata
-
-					// If the entrypoint is not a direct result, issue a check to further filter the results on the intersection or exclusion.
+					// If the entrypoint is not a direct result, the resources must be further filtered on the
+					// intersection or exclusion before being published.
 					if !entrypoint.IsDirectResult() {
-						resourceIDs := make([]string, 0, len(offsetted))
-						checkHints := make([]*v1.CheckHint, 0, len(offsetted))
-						for _, resource := range offsetted {
-							resourceIDs = append(resourceIDs, resource.ResourceId)
-
-							checkHint, err := hints.HintForEntrypoint(
-								entrypoint,
-								resource.ResourceId,
-								tuple.FromCoreObjectAndRelation(parentRequest.TerminalSubject),
-								&v1.ResourceCheckResult{
-									Membership: v1.ResourceCheckResult_MEMBER,
-								})
-							if err != nil {
-								return err
-							}
-							checkHints = append(checkHints, checkHint)
-						}
-
-						resultsByResourceID, checkMetadata, _, err := computed.ComputeBulkCheck(ctx, crr.dc, computed.CheckParameters{
-							ResourceType:  tuple.FromCoreRelationReference(parentRequest.ResourceRelation),
-							Subject:       tuple.FromCoreObjectAndRelation(parentRequest.TerminalSubject),
-							CaveatContext: parentRequest.Context.AsMap(),
-							AtRevision:    parentRequest.Revision,
-							MaximumDepth:  parentRequest.Metadata.DepthRemaining - 1,
-							DebugOption:   computed.NoDebugging,
-							CheckHints:    checkHints,
-						}, resourceIDs, crr.dispatchChunkSize)
-						if err != nil {
-							return err
-						}
-
-						metadata = addCallToResponseMetadata(checkMetadata)
-
-						filtered = make([]possibleResourceAndIndex, 0, len(offsetted))
-						for index, resource := range offsetted {
-							result, ok := resultsByResourceID[resource.ResourceId]
-							if !ok {
-								continue
-							}
-
-							switch result.Membership {
-							case v1.ResourceCheckResult_MEMBER:
-								filtered = append(filtered, possibleResourceAndIndex{
-									resource: resource,
-									index:    index,
-								})
-
-							case v1.ResourceCheckResult_CAVEATED_MEMBER:
-								missingContextParams := mapz.NewSet(result.MissingExprFields...)
-								missingContextParams.Extend(resource.MissingContextParams)
-
-								filtered = append(filtered, possibleResourceAndIndex{
-									resource: &v1.PossibleResource{
-										ResourceId:           resource.ResourceId,
-										ForSubjectIds:        resource.ForSubjectIds,
-										MissingContextParams: missingContextParams.AsSlice(),
-									},
-									index: index,
-								})
-
-							case v1.ResourceCheckResult_NOT_MEMBER:
-								// Skip.
-
-							default:
-								return spiceerrors.MustBugf("unexpected result from check: %v", result.Membership)
-							}
-						}
+						return crr.filterAndPublishViaCheck(ctx, ci, offsetted, currentOffset, nextCursorWith, entrypoint, parentStream, parentRequest)
 					}
 
-					for _, resourceAndIndex := range filtered {
+					for index, resource := range offsetted {
 						if !ci.limits.prepareForPublishing() {
 							return nil
 						}
 
 						err := parentStream.Publish(&v1.DispatchLookupResources2Response{
-							Resource:            resourceAndIndex.resource,
-							Metadata:            metadata,
-							AfterResponseCursor: nextCursorWith(currentOffset + resourceAndIndex.index + 1),
+							Resource:            resource,
+							Metadata:            emptyMetadata,
+							AfterResponseCursor: nextCursorWith(currentOffset + index + 1),
 						})
 						if err != nil {
 							return err
 						}
-
-						metadata = emptyMetadata
 					}
 					return nil
 				}
@@ -667,3 +585,142 @@ func (crr *CursoredLookupResources2) redispatchOrReport(
 			)
 		})
 }
+
+// filterAndPublishViaCheck filters the found resources on the intersection or exclusion of the
+// entrypoint and publishes those remaining. Rather than checking all the resources before publishing,
+// the resources are filtered and checked in chunks of the dispatch chunk size, with each chunk published
+// before the next is filtered, so that no further work is performed once the limit (if any) has been reached.
+func (crr *CursoredLookupResources2) filterAndPublishViaCheck(
+	ctx context.Context,
+	ci cursorInformation,
+	resources []*v1.PossibleResource,
+	currentOffset int,
+	nextCursorWith afterResponseCursor,
+	entrypoint typesystem.ReachabilityEntrypoint,
+	parentStream dispatch.LookupResources2Stream,
+	parentRequest ValidatedLookupResources2Request,
+) error {
+	// Filter the candidates via the datastore on the relations of the intersection or exclusion, if
+	// possible, to reduce the number of resources which must be checked.
+	reader := datastoremw.MustFromContext(ctx).SnapshotReader(parentRequest.Revision)
+	setFilter, err := newSetOperationFilter(ctx, reader, parentRequest.ResourceRelation, parentRequest.TerminalSubject, entrypoint)
+	if err != nil {
+		return err
+	}
+
+	// The metadata of checks is reported on the first result published after the checks were
+	// issued, so that checks filtering out all their resources are still reported.
+	var pendingMetadata *v1.ResponseMeta
+	addedCall := false
+
+	for chunkStart := 0; chunkStart < len(resources); chunkStart += int(crr.dispatchChunkSize) {
+		if ci.limits.hasExhaustedLimit() {
+			return nil
+		}
+
+		chunk := resources[chunkStart:min(chunkStart+int(crr.dispatchChunkSize), len(resources))]
+		resourceIDs := make([]string, 0, len(chunk))
+		for _, resource := range chunk {
+			resourceIDs = append(resourceIDs, resource.ResourceId)
+		}
+
+		if setFilter != nil {
+			resourceIDs, err = setFilter.filter(ctx, parentRequest.Revision, resourceIDs)
+			if err != nil {
+				return err
+			}
+
+			if len(resourceIDs) == 0 {
+				continue
+			}
+		}
+
+		checkHints := make([]*v1.CheckHint, 0, len(resourceIDs))
+		for _, resourceID := range resourceIDs {
+			checkHint, err := hints.HintForEntrypoint(
+				entrypoint,
+				resourceID,
+				tuple.FromCoreObjectAndRelation(parentRequest.TerminalSubject),
+				&v1.ResourceCheckResult{
+					Membership: v1.ResourceCheckResult_MEMBER,
+				})
+			if err != nil {
+				return err
+			}
+			checkHints = append(checkHints, checkHint)
+		}
+
+		resultsByResourceID, checkMetadata, _, err := computed.ComputeBulkCheck(ctx, crr.dc, computed.CheckParameters{
+			ResourceType:  tuple.FromCoreRelationReference(parentRequest.ResourceRelation),
+			Subject:       tuple.FromCoreObjectAndRelation(parentRequest.TerminalSubject),
+			CaveatContext: parentRequest.Context.AsMap(),
+			AtRevision:    parentRequest.Revision,
+			MaximumDepth:  parentRequest.Metadata.DepthRemaining - 1,
+			DebugOption:   computed.NoDebugging,
+			CheckHints:    checkHints,
+		}, resourceIDs, crr.dispatchChunkSize)
+		if err != nil {
+			return err
+		}
+
+		if !addedCall {
+			checkMetadata = addCallToResponseMetadata(checkMetadata)
+			addedCall = true
+		}
+
+		if pendingMetadata != nil {
+			pendingMetadata = combineResponseMetadata(ctx, pendingMetadata, checkMetadata)
+		} else {
+			pendingMetadata = checkMetadata
+		}
+
+		for index, resource := range chunk {
+			result, ok := resultsByResourceID[resource.ResourceId]
+			if !ok {
+				continue
+			}
+
+			switch result.Membership {
+			case v1.ResourceCheckResult_MEMBER:
+				// Publish as-is.
+
+			case v1.ResourceCheckResult_CAVEATED_MEMBER:
+				missingContextParams := mapz.NewSet(result.MissingExprFields...)
+				missingContextParams.Extend(resource.MissingContextParams)
+
+				resource = &v1.PossibleResource{
+					ResourceId:           resource.ResourceId,
+					ForSubjectIds:        resource.ForSubjectIds,
+					MissingContextParams: missingContextParams.AsSlice(),
+				}
+
+			case v1.ResourceCheckResult_NOT_MEMBER:
+				continue
+
+			default:
+				return spiceerrors.MustBugf("unexpected result from check: %v", result.Membership)
+			}
+
+			if !ci.limits.prepareForPublishing() {
+				return nil
+			}
+
+			metadata := emptyMetadata
+			if pendingMetadata != nil {
+				metadata = pendingMetadata
+				pendingMetadata = nil
+			}
+
+			err := parentStream.Publish(&v1.DispatchLookupResources2Response{
+				Resource:            resource,
+				Metadata:            metadata,
+				AfterResponseCursor: nextCursorWith(currentOffset + chunkStart + index + 1),
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
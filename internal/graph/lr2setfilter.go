@@ -0,0 +1,232 @@
+package graph
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/genutil/mapz"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+	"github.com/authzed/spicedb/pkg/typesystem"
+)
+
+// setOperationFilter filters candidate resources for a permission defined as an intersection or exclusion
+// by querying the datastore for the subject's relationships on the relations found in the operation,
+// removing those candidates for which the permission is known to not be granted without dispatching
+// a check for them.
+//
+// For example, for the permission `view = viewer & editor` on `document`, any candidate document without an
+// `editor` relationship to the subject cannot have the permission, and for the permission
+// `view = viewer - banned`, any candidate document with a non-caveated `banned` relationship to the subject
+// cannot have the permission.
+//
+// The filter is only ever used to *remove* candidates: those remaining must still be checked.
+type setOperationFilter struct {
+	resourceType string
+	subject      tuple.ObjectAndRelation
+
+	// requiredRelations are the relations for which the subject must have a relationship with the
+	// resource for the permission to be granted.
+	requiredRelations []string
+
+	// excludingRelations are the relations for which a non-caveated relationship between the resource
+	// and the subject ensures the permission is not granted.
+	excludingRelations []string
+}
+
+// newSetOperationFilter returns the filter to apply to candidate resources for the given permission and
+// terminal subject, found via the given entrypoint, or nil if the permission is not an intersection or
+// exclusion with any relations usable for filtering.
+func newSetOperationFilter(
+	ctx context.Context,
+	reader datastore.Reader,
+	resourceRelation *core.RelationReference,
+	subject *core.ObjectAndRelation,
+	entrypoint typesystem.ReachabilityEntrypoint,
+) (*setOperationFilter, error) {
+	// Subjects with a relation may be reached in other ways (such as the subject being the resource itself),
+	// so filtering is only performed for subjects without a relation.
+	if subject.Relation != tuple.Ellipsis {
+		return nil, nil
+	}
+
+	_, ts, err := typesystem.ReadNamespaceAndTypes(ctx, resourceRelation.Namespace, reader)
+	if err != nil {
+		return nil, err
+	}
+
+	relation, ok := ts.GetRelation(resourceRelation.Relation)
+	if !ok || relation.UsersetRewrite == nil {
+		return nil, nil
+	}
+
+	// The relation via which the candidates were found is already known to contain the subject, and
+	// will be provided as a hint to the check, so it is not queried again.
+	hintedRelation := ""
+	if entrypoint.EntrypointKind() == core.ReachabilityEntrypoint_COMPUTED_USERSET_ENTRYPOINT &&
+		entrypoint.ContainingRelationOrPermission().EqualVT(resourceRelation) {
+		hintedRelation, err = entrypoint.ComputedUsersetRelation()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	filter := &setOperationFilter{
+		resourceType: resourceRelation.Namespace,
+		subject:      tuple.FromCoreObjectAndRelation(subject),
+	}
+
+	switch rewrite := relation.UsersetRewrite.RewriteOperation.(type) {
+	case *core.UsersetRewrite_Intersection:
+		for _, child := range rewrite.Intersection.Child {
+			relationName, ok := childRelationName(ts.TypeSystem, child)
+			if !ok || relationName == hintedRelation {
+				continue
+			}
+
+			// The subject can only be found in the relation via a relationship if the relation does not allow
+			// subject relations or wildcards.
+			requiresRelationship, err := relationRequiresDirectRelationship(ts.TypeSystem, relationName)
+			if err != nil {
+				return nil, err
+			}
+
+			if requiresRelationship {
+				filter.requiredRelations = append(filter.requiredRelations, relationName)
+			}
+		}
+
+	case *core.UsersetRewrite_Exclusion:
+		// NOTE: only the excluded children (i.e. all but the first) can be used to remove candidates.
+		for _, child := range rewrite.Exclusion.Child[1:] {
+			if relationName, ok := childRelationName(ts.TypeSystem, child); ok {
+				filter.excludingRelations = append(filter.excludingRelations, relationName)
+			}
+		}
+
+	default:
+		return nil, nil
+	}
+
+	if len(filter.requiredRelations) == 0 && len(filter.excludingRelations) == 0 {
+		return nil, nil
+	}
+
+	return filter, nil
+}
+
+// childRelationName returns the name of the relation referenced by the set operation child, if the
+// child is a direct reference to a relation (and not a permission).
+func childRelationName(ts *typesystem.TypeSystem, child *core.SetOperation_Child) (string, bool) {
+	computedUserset := child.GetComputedUserset()
+	if computedUserset == nil || computedUserset.Object != core.ComputedUserset_TUPLE_OBJECT {
+		return "", false
+	}
+
+	if !ts.HasTypeInformation(computedUserset.Relation) || ts.IsPermission(computedUserset.Relation) {
+		return "", false
+	}
+
+	return computedUserset.Relation, true
+}
+
+func relationRequiresDirectRelationship(ts *typesystem.TypeSystem, relationName string) (bool, error) {
+	allowedRelations, err := ts.AllowedDirectRelationsAndWildcards(relationName)
+	if err != nil {
+		return false, err
+	}
+
+	for _, allowedRelation := range allowedRelations {
+		if allowedRelation.GetPublicWildcard() != nil || allowedRelation.GetRelation() != tuple.Ellipsis {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// filter returns those resource IDs which remain candidates for the permission, in the same order as given.
+func (sof *setOperationFilter) filter(ctx context.Context, revision datastore.Revision, resourceIDs []string) ([]string, error) {
+	if len(resourceIDs) == 0 {
+		return resourceIDs, nil
+	}
+
+	ctx, span := tracer.Start(ctx, "lr2SetOperationFilter", trace.WithAttributes(
+		attribute.Int("resource-id-count", len(resourceIDs)),
+	))
+	defer span.End()
+
+	reader := datastoremw.MustFromContext(ctx).SnapshotReader(revision)
+
+	relationNames := make([]string, 0, len(sof.requiredRelations)+len(sof.excludingRelations))
+	relationNames = append(relationNames, sof.requiredRelations...)
+	relationNames = append(relationNames, sof.excludingRelations...)
+
+	foundByRelation := make(map[string]*mapz.Set[string], len(relationNames))
+	for _, relationName := range relationNames {
+		foundByRelation[relationName] = mapz.NewSet[string]()
+	}
+
+	excluded := mapz.NewSet(sof.excludingRelations...)
+	for _, relationName := range relationNames {
+		it, err := reader.QueryRelationships(ctx, datastore.RelationshipsFilter{
+			OptionalResourceType:     sof.resourceType,
+			OptionalResourceIds:      resourceIDs,
+			OptionalResourceRelation: relationName,
+			OptionalSubjectsSelectors: []datastore.SubjectsSelector{
+				{
+					OptionalSubjectType: sof.subject.ObjectType,
+					OptionalSubjectIds:  []string{sof.subject.ObjectID},
+					RelationFilter:      datastore.SubjectRelationFilter{}.WithEllipsisRelation(),
+				},
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for rel, err := range it {
+			if err != nil {
+				return nil, err
+			}
+
+			// Caveated relationships may or may not exclude the resource, so only those without a
+			// caveat are considered for exclusion.
+			if excluded.Has(relationName) && rel.OptionalCaveat != nil && rel.OptionalCaveat.CaveatName != "" {
+				continue
+			}
+
+			foundByRelation[relationName].Insert(rel.Resource.ObjectID)
+		}
+	}
+
+	filtered := make([]string, 0, len(resourceIDs))
+	for _, resourceID := range resourceIDs {
+		if sof.isCandidate(resourceID, foundByRelation) {
+			filtered = append(filtered, resourceID)
+		}
+	}
+
+	span.SetAttributes(attribute.Int("filtered-resource-id-count", len(filtered)))
+	return filtered, nil
+}
+
+func (sof *setOperationFilter) isCandidate(resourceID string, foundByRelation map[string]*mapz.Set[string]) bool {
+	for _, relationName := range sof.requiredRelations {
+		if !foundByRelation[relationName].Has(resourceID) {
+			return false
+		}
+	}
+
+	for _, relationName := range sof.excludingRelations {
+		if foundByRelation[relationName].Has(resourceID) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,303 @@
+package graph
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/dsfortesting"
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
+	"github.com/authzed/spicedb/internal/testfixtures"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+	"github.com/authzed/spicedb/pkg/typesystem"
+)
+
+func TestSetOperationFilter(t *testing.T) {
+	tcs := []struct {
+		name                string
+		schema              string
+		relationships       []string
+		permission          string
+		subject             string
+		entrypointRelation  string
+		resourceIDs         []string
+		expectedNoFilter    bool
+		expectedRequired    []string
+		expectedExcluding   []string
+		expectedResourceIDs []string
+	}{
+		{
+			name: "union",
+			schema: `definition user {}
+
+			definition document {
+				relation viewer: user
+				relation editor: user
+				permission view = viewer + editor
+			}`,
+			permission:       "view",
+			subject:          "user:tom",
+			expectedNoFilter: true,
+		},
+		{
+			name: "computed userset",
+			schema: `definition user {}
+
+			definition document {
+				relation viewer: user
+				permission view = viewer
+			}`,
+			permission:       "view",
+			subject:          "user:tom",
+			expectedNoFilter: true,
+		},
+		{
+			name: "intersection",
+			schema: `definition user {}
+
+			definition document {
+				relation viewer: user
+				relation editor: user
+				permission view = viewer & editor
+			}`,
+			relationships: []string{
+				"document:first#viewer@user:tom",
+				"document:first#editor@user:tom",
+				"document:second#viewer@user:tom",
+				"document:second#editor@user:sarah",
+				"document:third#viewer@user:tom",
+			},
+			permission:          "view",
+			subject:             "user:tom",
+			resourceIDs:         []string{"first", "second", "third"},
+			expectedRequired:    []string{"editor"},
+			expectedResourceIDs: []string{"first"},
+		},
+		{
+			name: "intersection with caveated relationship",
+			schema: `definition user {}
+
+			caveat somecaveat(somevalue int) {
+				somevalue == 42
+			}
+
+			definition document {
+				relation viewer: user
+				relation editor: user with somecaveat
+				permission view = viewer & editor
+			}`,
+			relationships: []string{
+				"document:first#viewer@user:tom",
+				"document:first#editor@user:tom[somecaveat]",
+				"document:second#viewer@user:tom",
+			},
+			permission:          "view",
+			subject:             "user:tom",
+			resourceIDs:         []string{"first", "second"},
+			expectedRequired:    []string{"editor"},
+			expectedResourceIDs: []string{"first"},
+		},
+		{
+			name: "intersection with wildcard",
+			schema: `definition user {}
+
+			definition document {
+				relation viewer: user
+				relation editor: user | user:*
+				permission view = viewer & editor
+			}`,
+			permission:       "view",
+			subject:          "user:tom",
+			expectedNoFilter: true,
+		},
+		{
+			name: "intersection with subject relation",
+			schema: `definition user {}
+
+			definition group {
+				relation member: user
+			}
+
+			definition document {
+				relation viewer: user
+				relation editor: user | group#member
+				permission view = viewer & editor
+			}`,
+			permission:       "view",
+			subject:          "user:tom",
+			expectedNoFilter: true,
+		},
+		{
+			name: "intersection over multiple relations",
+			schema: `definition user {}
+
+			definition document {
+				relation viewer: user
+				relation editor: user
+				relation owner: user
+				permission view = viewer & editor & owner
+			}`,
+			relationships: []string{
+				"document:first#viewer@user:tom",
+				"document:first#editor@user:tom",
+				"document:first#owner@user:tom",
+				"document:second#viewer@user:tom",
+				"document:second#editor@user:tom",
+				"document:third#viewer@user:tom",
+				"document:third#owner@user:tom",
+			},
+			permission:          "view",
+			subject:             "user:tom",
+			expectedRequired:    []string{"editor", "owner"},
+			resourceIDs:         []string{"first", "second", "third"},
+			expectedResourceIDs: []string{"first"},
+		},
+		{
+			name: "intersection with permission",
+			schema: `definition user {}
+
+			definition document {
+				relation viewer: user
+				relation editor: user
+				permission edit = editor
+				permission view = viewer & edit
+			}`,
+			permission:       "view",
+			subject:          "user:tom",
+			expectedNoFilter: true,
+		},
+		{
+			name: "exclusion",
+			schema: `definition user {}
+
+			definition document {
+				relation viewer: user
+				relation banned: user
+				permission view = viewer - banned
+			}`,
+			relationships: []string{
+				"document:first#viewer@user:tom",
+				"document:second#viewer@user:tom",
+				"document:second#banned@user:tom",
+				"document:third#viewer@user:tom",
+				"document:third#banned@user:sarah",
+			},
+			permission:          "view",
+			subject:             "user:tom",
+			resourceIDs:         []string{"first", "second", "third"},
+			expectedExcluding:   []string{"banned"},
+			expectedResourceIDs: []string{"first", "third"},
+		},
+		{
+			name: "exclusion with caveated relationship",
+			schema: `definition user {}
+
+			caveat somecaveat(somevalue int) {
+				somevalue == 42
+			}
+
+			definition document {
+				relation viewer: user
+				relation banned: user | user with somecaveat
+				permission view = viewer - banned
+			}`,
+			relationships: []string{
+				"document:first#viewer@user:tom",
+				"document:first#banned@user:tom[somecaveat]",
+				"document:second#viewer@user:tom",
+				"document:second#banned@user:tom",
+			},
+			permission:          "view",
+			subject:             "user:tom",
+			resourceIDs:         []string{"first", "second"},
+			expectedExcluding:   []string{"banned"},
+			expectedResourceIDs: []string{"first"},
+		},
+		{
+			name: "exclusion of base",
+			schema: `definition user {}
+
+			definition document {
+				relation viewer: user
+				relation banned: user
+				permission viewable = viewer
+				permission view = banned - viewable
+			}`,
+			permission:         "view",
+			subject:            "user:tom",
+			entrypointRelation: "banned",
+			expectedNoFilter:   true,
+		},
+		{
+			name: "subject with relation",
+			schema: `definition user {}
+
+			definition document {
+				relation viewer: user
+				relation banned: user
+				permission view = viewer - banned
+			}`,
+			permission:       "view",
+			subject:          "user:tom#something",
+			expectedNoFilter: true,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			require := require.New(t)
+
+			rawDS, err := dsfortesting.NewMemDBDatastoreForTesting(0, 0, memdb.DisableGC)
+			require.NoError(err)
+
+			relationships := make([]tuple.Relationship, 0, len(tc.relationships))
+			for _, rel := range tc.relationships {
+				relationships = append(relationships, tuple.MustParse(rel))
+			}
+
+			ds, revision := testfixtures.DatastoreFromSchemaAndTestRelationships(rawDS, tc.schema, relationships, require)
+
+			ctx := datastoremw.ContextWithHandle(context.Background())
+			require.NoError(datastoremw.SetInContext(ctx, ds))
+
+			reader := ds.SnapshotReader(revision)
+			resourceRelation := &core.RelationReference{Namespace: "document", Relation: tc.permission}
+
+			_, ts, err := typesystem.ReadNamespaceAndTypes(ctx, "document", reader)
+			require.NoError(err)
+
+			entrypointRelation := tc.entrypointRelation
+			if entrypointRelation == "" {
+				entrypointRelation = "viewer"
+			}
+
+			// Find the entrypoint from the relation via which the candidates were found.
+			entrypoints, err := typesystem.ReachabilityGraphFor(ts).AllEntrypointsForSubjectToResource(ctx, &core.RelationReference{Namespace: "document", Relation: entrypointRelation}, resourceRelation)
+			require.NoError(err)
+			require.NotEmpty(entrypoints)
+
+			filter, err := newSetOperationFilter(ctx, reader, resourceRelation, tuple.MustParseSubjectONR(tc.subject).ToCoreONR(), entrypoints[0])
+			require.NoError(err)
+
+			if tc.expectedNoFilter {
+				require.Nil(filter)
+				return
+			}
+
+			require.NotNil(filter)
+			require.Equal(tc.expectedRequired, filter.requiredRelations)
+			require.Equal(tc.expectedExcluding, filter.excludingRelations)
+
+			if tc.resourceIDs == nil {
+				return
+			}
+
+			filtered, err := filter.filter(ctx, revision, tc.resourceIDs)
+			require.NoError(err)
+			require.Equal(tc.expectedResourceIDs, filtered)
+		})
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/authzed/spicedb/internal/dispatch"
 	"github.com/authzed/spicedb/internal/graph/computed"
 	"github.com/authzed/spicedb/internal/graph/hints"
+	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
 	"github.com/authzed/spicedb/internal/taskrunner"
 	"github.com/authzed/spicedb/pkg/genutil/mapz"
 	core "github.com/authzed/spicedb/pkg/proto/core/v1"
@@ -45,6 +46,14 @@ func runCheckerAndDispatch(
 		return err
 	}
 
+	// Filter the candidates via the datastore on the relations of the intersection or exclusion, if
+	// possible, to reduce the number of resources which must be checked.
+	reader := datastoremw.MustFromContext(ctx).SnapshotReader(parentReq.Revision)
+	setFilter, err := newSetOperationFilter(ctx, reader, newSubjectType, parentReq.TerminalSubject, entrypoint)
+	if err != nil {
+		return err
+	}
+
 	rdc := &checkAndDispatchRunner{
 		parentRequest:      parentReq,
 		foundResources:     foundResources,
@@ -54,6 +63,7 @@ func runCheckerAndDispatch(
 		filteredSubjectIDs: filteredSubjectIDs,
 		currentCheckIndex:  currentCheckIndex,
 		entrypoint:         entrypoint,
+		setFilter:          setFilter,
 		lrDispatcher:       lrDispatcher,
 		checkDispatcher:    checkDispatcher,
 		taskrunner:         taskrunner.NewTaskRunner(ctx, concurrencyLimit),
@@ -71,6 +81,7 @@ type checkAndDispatchRunner struct {
 	parentStream      dispatch.LookupResources2Stream
 	newSubjectType    *core.RelationReference
 	entrypoint        typesystem.ReachabilityEntrypoint
+	setFilter         *setOperationFilter
 	lrDispatcher      dispatch.LookupResources2
 	checkDispatcher   dispatch.Check
 	dispatchChunkSize uint16
@@ -106,6 +117,20 @@ func (rdc *checkAndDispatchRunner) runChecker(ctx context.Context, startingIndex
 		return nil
 	}
 
+	nextIndex := startingIndex + len(resourceIDsToCheck)
+	if rdc.setFilter != nil {
+		filtered, err := rdc.setFilter.filter(ctx, rdc.parentRequest.Revision, resourceIDsToCheck)
+		if err != nil {
+			return err
+		}
+
+		if len(filtered) == 0 {
+			rdc.scheduleChecker(nextIndex)
+			return nil
+		}
+		resourceIDsToCheck = filtered
+	}
+
 	ctx, span := tracer.Start(ctx, "lr2Check", trace.WithAttributes(
 		attribute.Int("resource-id-count", len(resourceIDsToCheck)),
 	))
@@ -176,15 +201,17 @@ func (rdc *checkAndDispatchRunner) runChecker(ctx context.Context, startingIndex
 		})
 	}
 
-	// Start the next check chunk (if applicable).
-	nextIndex := startingIndex + len(resourceIDsToCheck)
-	if nextIndex < len(rdc.filteredSubjectIDs) {
+	rdc.scheduleChecker(nextIndex)
+	return nil
+}
+
+// scheduleChecker starts the check chunk at the given index (if applicable).
+func (rdc *checkAndDispatchRunner) scheduleChecker(startingIndex int) {
+	if startingIndex < len(rdc.filteredSubjectIDs) {
 		rdc.taskrunner.Schedule(func(ctx context.Context) error {
-			return rdc.runChecker(ctx, nextIndex)
+			return rdc.runChecker(ctx, startingIndex)
 		})
 	}
-
-	return nil
 }
 
 func (rdc *checkAndDispatchRunner) runDispatch(
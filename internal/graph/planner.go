@@ -0,0 +1,321 @@
+package graph
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	v1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+)
+
+const (
+	// leadingBranchMinEvaluations is the number of evaluations of a branch required before it
+	// can be evaluated on its own, ahead of the other branches.
+	leadingBranchMinEvaluations = 100
+
+	// leadingBranchMinRate is the rate at which a branch must have determined the result of
+	// its rewrite for it to be evaluated on its own, ahead of the other branches.
+	leadingBranchMinRate = 0.8
+
+	// maxBranchEvaluations is the number of evaluations of a branch after which the statistics
+	// of its rewrite are halved, so that the planner follows changes in the data.
+	maxBranchEvaluations = 10_000
+
+	// maxPlannedRewrites is the maximum number of rewrites for which statistics are kept.
+	maxPlannedRewrites = 10_000
+)
+
+var plannedLeadingBranchCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "spicedb",
+	Subsystem: "check",
+	Name:      "planned_leading_branch_total",
+	Help:      "number of branches of union and intersection rewrites evaluated ahead of their siblings, by whether they determined the result",
+}, []string{"operation", "determined"})
+
+// EvaluationPlanner records which branches of the union and intersection rewrites of each
+// permission most often determine the result of a check, and orders the evaluation of the
+// branches accordingly.
+//
+// Branches are started in the order of the rate at which they have determined the result. A
+// branch that determines the result of nearly every check is evaluated on its own first, and the
+// remaining branches are only dispatched if it did not, trading latency for fewer dispatches.
+type EvaluationPlanner struct {
+	lock  sync.RWMutex
+	stats map[string]*branchStats
+}
+
+type branchStats struct {
+	lock       sync.Mutex
+	determined []uint64
+	evaluated  []uint64
+}
+
+// NewEvaluationPlanner creates a new EvaluationPlanner without any recorded statistics.
+func NewEvaluationPlanner() *EvaluationPlanner {
+	return &EvaluationPlanner{stats: make(map[string]*branchStats)}
+}
+
+// plan returns the order in which the branches of the rewrite with the given key should be
+// evaluated, and whether the first branch should be evaluated on its own.
+func (ep *EvaluationPlanner) plan(key string, branchCount int) ([]int, bool) {
+	order := make([]int, branchCount)
+	for i := range order {
+		order[i] = i
+	}
+
+	ep.lock.RLock()
+	stats, ok := ep.stats[key]
+	ep.lock.RUnlock()
+	if !ok {
+		return order, false
+	}
+
+	stats.lock.Lock()
+	defer stats.lock.Unlock()
+	if len(stats.evaluated) != branchCount {
+		return order, false
+	}
+
+	rates := make([]float64, branchCount)
+	for i := range rates {
+		// Smoothed so that branches that have not been evaluated are neither preferred nor avoided.
+		rates[i] = (float64(stats.determined[i]) + 1) / (float64(stats.evaluated[i]) + 2)
+	}
+
+	slices.SortStableFunc(order, func(a, b int) int {
+		switch {
+		case rates[a] > rates[b]:
+			return -1
+		case rates[a] < rates[b]:
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	leader := order[0]
+	return order, stats.evaluated[leader] >= leadingBranchMinEvaluations && rates[leader] >= leadingBranchMinRate
+}
+
+// record records an evaluation of a branch of the rewrite with the given key.
+func (ep *EvaluationPlanner) record(key string, branchCount int, branch int, determined bool) {
+	ep.lock.RLock()
+	stats, ok := ep.stats[key]
+	ep.lock.RUnlock()
+
+	if !ok {
+		ep.lock.Lock()
+		stats, ok = ep.stats[key]
+		if !ok {
+			if len(ep.stats) >= maxPlannedRewrites {
+				ep.lock.Unlock()
+				return
+			}
+
+			stats = &branchStats{}
+			ep.stats[key] = stats
+		}
+		ep.lock.Unlock()
+	}
+
+	stats.lock.Lock()
+	defer stats.lock.Unlock()
+
+	// The schema changed the number of branches of the rewrite; start over.
+	if len(stats.evaluated) != branchCount {
+		stats.determined = make([]uint64, branchCount)
+		stats.evaluated = make([]uint64, branchCount)
+	}
+
+	stats.evaluated[branch]++
+	if determined {
+		stats.determined[branch]++
+	}
+
+	if stats.evaluated[branch] >= maxBranchEvaluations {
+		for i := range stats.evaluated {
+			stats.evaluated[i] /= 2
+			stats.determined[i] /= 2
+		}
+	}
+}
+
+type plannedBranch struct {
+	index int
+	child *core.SetOperation_Child
+}
+
+// orderedBranches returns the branches of the rewrite in the planned order of evaluation.
+func (ep *EvaluationPlanner) orderedBranches(key string, children []*core.SetOperation_Child) ([]plannedBranch, bool) {
+	order, lead := ep.plan(key, len(children))
+	branches := make([]plannedBranch, 0, len(children))
+	for _, index := range order {
+		branches = append(branches, plannedBranch{index, children[index]})
+	}
+	return branches, lead
+}
+
+// union evaluates the branches of a union rewrite in the planned order, recording which
+// branches found a member.
+func (ep *EvaluationPlanner) union(
+	ctx context.Context,
+	crc currentRequestContext,
+	children []*core.SetOperation_Child,
+	handler func(ctx context.Context, crc currentRequestContext, child *core.SetOperation_Child) CheckResult,
+	concurrencyLimit uint16,
+) CheckResult {
+	key := rewritePlanKey(crc.parentReq.ResourceRelation, "+", children)
+	branches, lead := ep.orderedBranches(key, children)
+
+	recordingHandler := func(ctx context.Context, crc currentRequestContext, branch plannedBranch) CheckResult {
+		result := handler(ctx, crc, branch.child)
+		if result.Err == nil {
+			ep.record(key, len(children), branch.index, hasDeterminedMember(result.Resp.ResultsByResourceId))
+		}
+		return result
+	}
+
+	// Only a single member is needed for the first branch to determine the result.
+	if !lead || crc.resultsSetting != v1.DispatchCheckRequest_ALLOW_SINGLE_RESULT {
+		return union(ctx, crc, branches, recordingHandler, concurrencyLimit)
+	}
+
+	first := recordingHandler(ctx, crc, branches[0])
+	if first.Err != nil {
+		return checkResultError(first.Err, first.Resp.Metadata)
+	}
+
+	membershipSet := NewMembershipSet()
+	membershipSet.UnionWith(first.Resp.ResultsByResourceId)
+	if membershipSet.HasDeterminedMember() {
+		plannedLeadingBranchCounter.WithLabelValues("union", "true").Inc()
+		return checkResultsForMembership(membershipSet, first.Resp.Metadata)
+	}
+	plannedLeadingBranchCounter.WithLabelValues("union", "false").Inc()
+
+	rest := union(ctx, crc, branches[1:], recordingHandler, concurrencyLimit)
+	responseMetadata := combineResponseMetadata(ctx, first.Resp.Metadata, rest.Resp.Metadata)
+	if rest.Err != nil {
+		return checkResultError(rest.Err, responseMetadata)
+	}
+
+	membershipSet.UnionWith(rest.Resp.ResultsByResourceId)
+	return checkResultsForMembership(membershipSet, responseMetadata)
+}
+
+// all evaluates the branches of an intersection rewrite in the planned order, recording which
+// branches found no members.
+func (ep *EvaluationPlanner) all(
+	ctx context.Context,
+	crc currentRequestContext,
+	children []*core.SetOperation_Child,
+	handler func(ctx context.Context, crc currentRequestContext, child *core.SetOperation_Child) CheckResult,
+	concurrencyLimit uint16,
+) CheckResult {
+	key := rewritePlanKey(crc.parentReq.ResourceRelation, "&", children)
+	branches, lead := ep.orderedBranches(key, children)
+
+	recordingHandler := func(ctx context.Context, crc currentRequestContext, branch plannedBranch) CheckResult {
+		result := handler(ctx, crc, branch.child)
+		if result.Err == nil {
+			ep.record(key, len(children), branch.index, len(result.Resp.ResultsByResourceId) == 0)
+		}
+		return result
+	}
+
+	if !lead {
+		return all(ctx, crc, branches, recordingHandler, concurrencyLimit)
+	}
+
+	first := recordingHandler(ctx, currentRequestContext{
+		parentReq:           crc.parentReq,
+		filteredResourceIDs: crc.filteredResourceIDs,
+		resultsSetting:      v1.DispatchCheckRequest_REQUIRE_ALL_RESULTS,
+		dispatchChunkSize:   crc.dispatchChunkSize,
+	}, branches[0])
+	if first.Err != nil {
+		return checkResultError(first.Err, first.Resp.Metadata)
+	}
+
+	membershipSet := NewMembershipSet()
+	membershipSet.UnionWith(first.Resp.ResultsByResourceId)
+	if membershipSet.IsEmpty() {
+		plannedLeadingBranchCounter.WithLabelValues("intersection", "true").Inc()
+		return noMembersWithMetadata(first.Resp.Metadata)
+	}
+	plannedLeadingBranchCounter.WithLabelValues("intersection", "false").Inc()
+
+	rest := all(ctx, crc, branches[1:], recordingHandler, concurrencyLimit)
+	responseMetadata := combineResponseMetadata(ctx, first.Resp.Metadata, rest.Resp.Metadata)
+	if rest.Err != nil {
+		return checkResultError(rest.Err, responseMetadata)
+	}
+
+	membershipSet.IntersectWith(rest.Resp.ResultsByResourceId)
+	if membershipSet.IsEmpty() {
+		return noMembersWithMetadata(responseMetadata)
+	}
+	return checkResultsForMembership(membershipSet, responseMetadata)
+}
+
+func hasDeterminedMember(resultsMap CheckResultsMap) bool {
+	for _, result := range resultsMap {
+		if result.Membership == v1.ResourceCheckResult_MEMBER {
+			return true
+		}
+	}
+	return false
+}
+
+// rewritePlanKey returns the key under which the statistics of a rewrite of the given
+// permission are recorded, which is derived from the structure of the rewrite.
+func rewritePlanKey(rr *core.RelationReference, operation string, children []*core.SetOperation_Child) string {
+	var sb strings.Builder
+	sb.WriteString(rr.Namespace)
+	sb.WriteString("#")
+	sb.WriteString(rr.Relation)
+	sb.WriteString(":")
+	writeRewriteDescriptor(&sb, operation, children)
+	return sb.String()
+}
+
+func writeRewriteDescriptor(sb *strings.Builder, operation string, children []*core.SetOperation_Child) {
+	sb.WriteString(operation)
+	sb.WriteString("(")
+	for i, child := range children {
+		if i > 0 {
+			sb.WriteString(",")
+		}
+
+		switch c := child.ChildType.(type) {
+		case *core.SetOperation_Child_ComputedUserset:
+			sb.WriteString(c.ComputedUserset.Relation)
+		case *core.SetOperation_Child_TupleToUserset:
+			sb.WriteString(c.TupleToUserset.Tupleset.Relation)
+			sb.WriteString("->")
+			sb.WriteString(c.TupleToUserset.ComputedUserset.Relation)
+		case *core.SetOperation_Child_FunctionedTupleToUserset:
+			sb.WriteString(c.FunctionedTupleToUserset.Tupleset.Relation)
+			sb.WriteString(".")
+			sb.WriteString(c.FunctionedTupleToUserset.Function.String())
+			sb.WriteString("->")
+			sb.WriteString(c.FunctionedTupleToUserset.ComputedUserset.Relation)
+		case *core.SetOperation_Child_UsersetRewrite:
+			switch rw := c.UsersetRewrite.RewriteOperation.(type) {
+			case *core.UsersetRewrite_Union:
+				writeRewriteDescriptor(sb, "+", rw.Union.Child)
+			case *core.UsersetRewrite_Intersection:
+				writeRewriteDescriptor(sb, "&", rw.Intersection.Child)
+			case *core.UsersetRewrite_Exclusion:
+				writeRewriteDescriptor(sb, "-", rw.Exclusion.Child)
+			}
+		case *core.SetOperation_Child_XNil:
+			sb.WriteString("nil")
+		}
+	}
+	sb.WriteString(")")
+}
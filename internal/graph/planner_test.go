@@ -0,0 +1,171 @@
+package graph
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	ns "github.com/authzed/spicedb/pkg/namespace"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	v1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+)
+
+func TestEvaluationPlannerOrder(t *testing.T) {
+	planner := NewEvaluationPlanner()
+
+	order, lead := planner.plan("doc#view:+(a,b,c)", 3)
+	require.Equal(t, []int{0, 1, 2}, order)
+	require.False(t, lead)
+
+	for i := 0; i < 10; i++ {
+		planner.record("doc#view:+(a,b,c)", 3, 0, false)
+		planner.record("doc#view:+(a,b,c)", 3, 2, true)
+	}
+
+	order, lead = planner.plan("doc#view:+(a,b,c)", 3)
+	require.Equal(t, []int{2, 1, 0}, order)
+	require.False(t, lead, "too few evaluations to lead")
+
+	for i := 0; i < leadingBranchMinEvaluations; i++ {
+		planner.record("doc#view:+(a,b,c)", 3, 2, true)
+	}
+
+	order, lead = planner.plan("doc#view:+(a,b,c)", 3)
+	require.Equal(t, []int{2, 1, 0}, order)
+	require.True(t, lead)
+
+	// A change in the number of branches discards the statistics.
+	order, lead = planner.plan("doc#view:+(a,b,c)", 2)
+	require.Equal(t, []int{0, 1}, order)
+	require.False(t, lead)
+}
+
+func TestEvaluationPlannerDecay(t *testing.T) {
+	planner := NewEvaluationPlanner()
+	for i := 0; i < maxBranchEvaluations-1; i++ {
+		planner.record("doc#view:+(a,b)", 2, 0, true)
+	}
+
+	_, lead := planner.plan("doc#view:+(a,b)", 2)
+	require.True(t, lead)
+
+	// Once the branch stops determining the result, the halved statistics are quickly overtaken.
+	for i := 0; i < maxBranchEvaluations/2; i++ {
+		planner.record("doc#view:+(a,b)", 2, 0, false)
+	}
+
+	order, lead := planner.plan("doc#view:+(a,b)", 2)
+	require.Equal(t, []int{1, 0}, order)
+	require.False(t, lead)
+}
+
+func TestRewritePlanKey(t *testing.T) {
+	rewrite := ns.Union(
+		ns.ComputedUserset("owner"),
+		ns.TupleToUserset("parent", "view"),
+		ns.Rewrite(ns.Intersection(ns.ComputedUserset("viewer"), ns.ComputedUserset("member"))),
+		ns.Nil(),
+	)
+
+	key := rewritePlanKey(ns.RelationReference("document", "view"), "+", rewrite.GetUnion().Child)
+	require.Equal(t, "document#view:+(owner,parent->view,&(viewer,member),nil)", key)
+}
+
+func TestEvaluationPlannerLeadingBranch(t *testing.T) {
+	tcs := []struct {
+		name               string
+		intersection       bool
+		resultsSetting     v1.DispatchCheckRequest_ResultsSetting
+		members            map[string]bool
+		expectedEvaluated  int32
+		expectedMembership bool
+	}{
+		{
+			name:               "union determined by leading branch",
+			resultsSetting:     v1.DispatchCheckRequest_ALLOW_SINGLE_RESULT,
+			members:            map[string]bool{"leader": true},
+			expectedEvaluated:  1,
+			expectedMembership: true,
+		},
+		{
+			name:               "union not determined by leading branch",
+			resultsSetting:     v1.DispatchCheckRequest_ALLOW_SINGLE_RESULT,
+			members:            map[string]bool{"other": true},
+			expectedEvaluated:  2,
+			expectedMembership: true,
+		},
+		{
+			name:               "union requiring all results",
+			resultsSetting:     v1.DispatchCheckRequest_REQUIRE_ALL_RESULTS,
+			members:            map[string]bool{"leader": true},
+			expectedEvaluated:  2,
+			expectedMembership: true,
+		},
+		{
+			name:               "intersection determined by leading branch",
+			intersection:       true,
+			members:            map[string]bool{"other": true},
+			expectedEvaluated:  1,
+			expectedMembership: false,
+		},
+		{
+			name:               "intersection not determined by leading branch",
+			intersection:       true,
+			members:            map[string]bool{"leader": true, "other": true},
+			expectedEvaluated:  2,
+			expectedMembership: true,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			children := []*core.SetOperation_Child{ns.ComputedUserset("other"), ns.ComputedUserset("leader")}
+			rr := ns.RelationReference("document", "view")
+
+			operation := "+"
+			if tc.intersection {
+				operation = "&"
+			}
+
+			// The leading branch determined the result of every previous check.
+			planner := NewEvaluationPlanner()
+			key := rewritePlanKey(rr, operation, children)
+			for i := 0; i < leadingBranchMinEvaluations; i++ {
+				planner.record(key, 2, 1, true)
+			}
+
+			var evaluated atomic.Int32
+			handler := func(_ context.Context, _ currentRequestContext, child *core.SetOperation_Child) CheckResult {
+				evaluated.Add(1)
+				membershipSet := NewMembershipSet()
+				if tc.members[child.GetComputedUserset().Relation] {
+					membershipSet.AddDirectMember("somedoc", nil)
+				}
+				return checkResultsForMembership(membershipSet, emptyMetadata)
+			}
+
+			crc := currentRequestContext{
+				parentReq: ValidatedCheckRequest{
+					DispatchCheckRequest: &v1.DispatchCheckRequest{ResourceRelation: rr},
+				},
+				filteredResourceIDs: []string{"somedoc"},
+				resultsSetting:      tc.resultsSetting,
+				dispatchChunkSize:   100,
+			}
+
+			var result CheckResult
+			if tc.intersection {
+				result = planner.all(context.Background(), crc, children, handler, 10)
+			} else {
+				result = planner.union(context.Background(), crc, children, handler, 10)
+			}
+
+			require.NoError(t, result.Err)
+			require.Equal(t, tc.expectedEvaluated, evaluated.Load())
+			_, isMember := result.Resp.ResultsByResourceId["somedoc"]
+			require.Equal(t, tc.expectedMembership, isMember)
+		})
+	}
+}
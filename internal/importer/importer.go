@@ -0,0 +1,236 @@
+package importer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"google.golang.org/grpc"
+
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// Config is the configuration of an import.
+type Config struct {
+	// File is the path of the file whose relationships are imported.
+	File string
+
+	// Format is the format of the file: csv or ndjson.
+	Format string
+
+	// Mapping is the column-mapping spec of the file; see ParseMapping.
+	Mapping string
+
+	// BatchSize is the number of relationships imported per transaction.
+	BatchSize uint
+
+	// StateFile, if not empty, is the path of the file recording the progress of the import, from
+	// which an interrupted import is resumed.
+	StateFile string
+}
+
+func (c Config) validate() error {
+	if c.File == "" {
+		return errors.New("a file to import is required")
+	}
+
+	if c.BatchSize == 0 {
+		return errors.New("batch size must be positive")
+	}
+
+	return nil
+}
+
+// Summary is the result of an import.
+type Summary struct {
+	// Imported is the number of relationships imported by this run.
+	Imported uint64 `json:"imported"`
+
+	// Skipped is the number of relationships skipped as imported by a previous run.
+	Skipped uint64 `json:"skipped"`
+
+	// DurationSeconds is the duration of the import, in seconds.
+	DurationSeconds float64 `json:"durationSeconds"`
+}
+
+// state is the progress of an import, persisted to the state file after each batch.
+type state struct {
+	File     string `json:"file"`
+	Format   string `json:"format"`
+	Mapping  string `json:"mapping"`
+	Imported uint64 `json:"imported"`
+}
+
+// Run streams the relationships of the file into the SpiceDB instance behind the connection
+// through the bulk import API, one transaction per batch. As batches are committed, the number of
+// records imported is recorded in the state file, if any, and a rerun of the same import skips
+// them.
+//
+// Relationships are created, so importing a relationship which already exists fails the batch.
+func Run(ctx context.Context, conn grpc.ClientConnInterface, config Config) (*Summary, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+
+	mapping, err := ParseMapping(config.Mapping)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := loadState(config)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(config.File)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", config.File, err)
+	}
+	defer file.Close()
+
+	reader, err := newRecordReader(config.Format, file)
+	if err != nil {
+		return nil, err
+	}
+
+	client := v1.NewPermissionsServiceClient(conn)
+	summary := &Summary{}
+	start := time.Now()
+
+	var position uint64
+	batch := make([]*v1.Relationship, 0, config.BatchSize)
+	for {
+		rec, err := reader.read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		position++
+		if err != nil {
+			return summary, fmt.Errorf("failed to read record %d: %w", position, err)
+		}
+
+		if position <= current.Imported {
+			summary.Skipped++
+			continue
+		}
+
+		rel, err := mapping.relationship(rec)
+		if err != nil {
+			return summary, fmt.Errorf("invalid relationship in record %d: %w", position, err)
+		}
+		batch = append(batch, tuple.ToV1Relationship(rel))
+
+		if len(batch) == int(config.BatchSize) {
+			if err := importBatch(ctx, client, batch, &current, config, summary, start); err != nil {
+				return summary, err
+			}
+			batch = batch[:0]
+		}
+	}
+
+	if len(batch) > 0 {
+		if err := importBatch(ctx, client, batch, &current, config, summary, start); err != nil {
+			return summary, err
+		}
+	}
+
+	summary.DurationSeconds = time.Since(start).Seconds()
+	return summary, nil
+}
+
+func importBatch(ctx context.Context, client v1.PermissionsServiceClient, batch []*v1.Relationship, current *state, config Config, summary *Summary, start time.Time) error {
+	stream, err := client.ImportBulkRelationships(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start import: %w", err)
+	}
+
+	if err := stream.Send(&v1.ImportBulkRelationshipsRequest{Relationships: batch}); err != nil {
+		return fmt.Errorf("failed to import batch after %d relationships: %w", current.Imported, err)
+	}
+
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		return fmt.Errorf("failed to import batch after %d relationships: %w", current.Imported, err)
+	}
+
+	current.Imported += uint64(len(batch))
+	summary.Imported += resp.NumLoaded
+	if err := saveState(config, *current); err != nil {
+		return err
+	}
+
+	elapsed := time.Since(start)
+	log.Ctx(ctx).Info().
+		Uint64("imported", summary.Imported).
+		Uint64("skipped", summary.Skipped).
+		Float64("relationshipsPerSecond", float64(summary.Imported)/elapsed.Seconds()).
+		Msg("imported batch")
+	return nil
+}
+
+// loadState returns the progress of a previous run of the import, if any. A state file recording
+// the import of a different file or with a different mapping is an error, as resuming from it
+// would skip the wrong records.
+func loadState(config Config) (state, error) {
+	fresh := state{File: config.File, Format: config.Format, Mapping: config.Mapping}
+	if config.StateFile == "" {
+		return fresh, nil
+	}
+
+	contents, err := os.ReadFile(config.StateFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return fresh, nil
+	}
+	if err != nil {
+		return state{}, fmt.Errorf("failed to read state file: %w", err)
+	}
+
+	var previous state
+	if err := json.Unmarshal(contents, &previous); err != nil {
+		return state{}, fmt.Errorf("invalid state file %s: %w", config.StateFile, err)
+	}
+
+	if previous.File != fresh.File || previous.Format != fresh.Format || previous.Mapping != fresh.Mapping {
+		return state{}, fmt.Errorf("state file %s records the import of %s with format `%s` and mapping `%s`; remove it to start a new import", config.StateFile, previous.File, previous.Format, previous.Mapping)
+	}
+	return previous, nil
+}
+
+// saveState atomically replaces the state file with the current progress.
+func saveState(config Config, current state) error {
+	if config.StateFile == "" {
+		return nil
+	}
+
+	contents, err := json.Marshal(current)
+	if err != nil {
+		return err
+	}
+
+	temp, err := os.CreateTemp(filepath.Dir(config.StateFile), filepath.Base(config.StateFile)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	defer os.Remove(temp.Name())
+
+	if _, err := temp.Write(contents); err != nil {
+		temp.Close()
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	if err := temp.Close(); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+
+	if err := os.Rename(temp.Name(), config.StateFile); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	return nil
+}
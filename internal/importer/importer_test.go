@@ -0,0 +1,176 @@
+package importer
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/internal/testserver"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+func TestMappingRelationship(t *testing.T) {
+	tcs := []struct {
+		name          string
+		mapping       string
+		record        record
+		expected      string
+		expectedError string
+	}{
+		{
+			name:     "default columns",
+			record:   record{"resource_type": "document", "resource_id": "plan", "relation": "viewer", "subject_type": "user", "subject_id": "tom"},
+			expected: "document:plan#viewer@user:tom",
+		},
+		{
+			name:     "mapped columns and constants",
+			mapping:  "resource_type='document', resource_id=doc, relation='viewer', subject_type='group', subject_id=team, subject_relation='member'",
+			record:   record{"doc": "plan", "team": "eng"},
+			expected: "document:plan#viewer@group:eng#member",
+		},
+		{
+			name:     "whole relationship",
+			mapping:  "relationship=rel",
+			record:   record{"rel": "document:plan#viewer@user:tom"},
+			expected: "document:plan#viewer@user:tom",
+		},
+		{
+			name:     "caveat and expiration",
+			mapping:  "relationship=rel",
+			record:   record{"rel": "document:plan#viewer@user:tom", "caveat_name": "has_ip", "caveat_context": `{"allowed":"10.0.0.1"}`, "expires_at": "2030-01-02T03:04:05Z"},
+			expected: `document:plan#viewer@user:tom[has_ip:{"allowed":"10.0.0.1"}][expiration:2030-01-02T03:04:05Z]`,
+		},
+		{
+			name:          "missing field",
+			record:        record{"resource_type": "document", "resource_id": "plan", "relation": "viewer", "subject_type": "user"},
+			expectedError: "missing value for field `subject_id`",
+		},
+		{
+			name:          "invalid caveat context",
+			record:        record{"relationship": "document:plan#viewer@user:tom", "caveat_name": "has_ip", "caveat_context": "{"},
+			expectedError: "invalid caveat context",
+		},
+		{
+			name:          "invalid expiration",
+			record:        record{"relationship": "document:plan#viewer@user:tom", "expires_at": "tomorrow"},
+			expectedError: "invalid expiration",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			mapping, err := ParseMapping(tc.mapping)
+			require.NoError(t, err)
+
+			rel, err := mapping.relationship(tc.record)
+			if tc.expectedError != "" {
+				require.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, tuple.MustString(rel))
+		})
+	}
+}
+
+func TestParseMappingErrors(t *testing.T) {
+	_, err := ParseMapping("resource_type")
+	require.ErrorContains(t, err, "expected `field=column`")
+
+	_, err = ParseMapping("resource=type")
+	require.ErrorContains(t, err, "unknown relationship field `resource`")
+}
+
+func TestRecordReaders(t *testing.T) {
+	csvReader, err := newRecordReader(FormatCSV, strings.NewReader("relationship,caveat_context\ndocument:plan#viewer@user:tom,\"{\"\"a\"\":1}\"\n"))
+	require.NoError(t, err)
+
+	rec, err := csvReader.read()
+	require.NoError(t, err)
+	require.Equal(t, record{"relationship": "document:plan#viewer@user:tom", "caveat_context": `{"a":1}`}, rec)
+
+	ndjsonReader, err := newRecordReader(FormatNDJSON, strings.NewReader("{\"relationship\":\"document:plan#viewer@user:tom\",\"caveat_context\":{\"a\":1},\"expires_at\":null}\n\n"))
+	require.NoError(t, err)
+
+	rec, err = ndjsonReader.read()
+	require.NoError(t, err)
+	require.Equal(t, record{"relationship": "document:plan#viewer@user:tom", "caveat_context": `{"a":1}`}, rec)
+
+	_, err = newRecordReader("xml", strings.NewReader(""))
+	require.ErrorContains(t, err, "unknown import format")
+}
+
+func TestRun(t *testing.T) {
+	conn, cleanup, _, _ := testserver.NewTestServer(require.New(t), 0, time.Hour, true, testfixtures.EmptyDatastore)
+	t.Cleanup(cleanup)
+
+	_, err := v1.NewSchemaServiceClient(conn).WriteSchema(context.Background(), &v1.WriteSchemaRequest{
+		Schema: `definition user {}
+
+		definition document {
+			relation viewer: user
+		}`,
+	})
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "viewers.ndjson")
+	var lines []string
+	for _, id := range []string{"a", "b", "c", "d", "e"} {
+		lines = append(lines, `{"doc":"`+id+`","user":"tom"}`)
+	}
+	require.NoError(t, os.WriteFile(file, []byte(strings.Join(lines, "\n")), 0o600))
+
+	// Simulate an interrupted import which committed the first two relationships.
+	stateFile := filepath.Join(dir, "state.json")
+	config := Config{
+		File:      file,
+		Format:    FormatNDJSON,
+		Mapping:   "resource_type='document',resource_id=doc,relation='viewer',subject_type='user',subject_id=user",
+		BatchSize: 2,
+		StateFile: stateFile,
+	}
+	require.NoError(t, saveState(config, state{File: file, Format: FormatNDJSON, Mapping: config.Mapping, Imported: 2}))
+
+	summary, err := Run(context.Background(), conn, config)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), summary.Imported)
+	require.Equal(t, uint64(2), summary.Skipped)
+
+	contents, err := os.ReadFile(stateFile)
+	require.NoError(t, err)
+
+	var saved state
+	require.NoError(t, json.Unmarshal(contents, &saved))
+	require.Equal(t, uint64(5), saved.Imported)
+
+	stream, err := v1.NewPermissionsServiceClient(conn).ReadRelationships(context.Background(), &v1.ReadRelationshipsRequest{
+		Consistency:        &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}},
+		RelationshipFilter: &v1.RelationshipFilter{ResourceType: "document"},
+	})
+	require.NoError(t, err)
+
+	var imported []string
+	for {
+		resp, err := stream.Recv()
+		if err != nil {
+			break
+		}
+		imported = append(imported, resp.Relationship.Resource.ObjectId)
+	}
+	require.ElementsMatch(t, []string{"c", "d", "e"}, imported)
+
+	// A state file recording another import is not resumed from.
+	config.Mapping = "relationship=rel"
+	_, err = Run(context.Background(), conn, config)
+	require.ErrorContains(t, err, "remove it to start a new import")
+}
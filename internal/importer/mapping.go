@@ -0,0 +1,165 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// The fields of a relationship which can be mapped from the columns of a record.
+const (
+	FieldRelationship    = "relationship"
+	FieldResourceType    = "resource_type"
+	FieldResourceID      = "resource_id"
+	FieldRelation        = "relation"
+	FieldSubjectType     = "subject_type"
+	FieldSubjectID       = "subject_id"
+	FieldSubjectRelation = "subject_relation"
+	FieldCaveatName      = "caveat_name"
+	FieldCaveatContext   = "caveat_context"
+	FieldExpiresAt       = "expires_at"
+)
+
+var allFields = []string{
+	FieldRelationship,
+	FieldResourceType,
+	FieldResourceID,
+	FieldRelation,
+	FieldSubjectType,
+	FieldSubjectID,
+	FieldSubjectRelation,
+	FieldCaveatName,
+	FieldCaveatContext,
+	FieldExpiresAt,
+}
+
+var requiredFields = []string{
+	FieldResourceType,
+	FieldResourceID,
+	FieldRelation,
+	FieldSubjectType,
+	FieldSubjectID,
+}
+
+// source is where the value of a field is read from: a column of the records, or a constant.
+type source struct {
+	column   string
+	constant string
+}
+
+func (s source) value(r record) (string, bool) {
+	if s.column == "" {
+		return s.constant, true
+	}
+	return r.get(s.column)
+}
+
+// Mapping maps the fields of relationships to the columns of the records they are read from.
+type Mapping struct {
+	spec    string
+	sources map[string]source
+}
+
+// ParseMapping parses a column-mapping spec: a comma-separated list of `field=column` pairs, where
+// the value of a field can also be a constant, written `field='constant'`. Fields which are not
+// mapped are read from the column of the same name, if any.
+//
+// A relationship is either read whole, in the form `resource:id#relation@subject:id`, from the
+// `relationship` field, or from its resource_type, resource_id, relation, subject_type,
+// subject_id and optional subject_relation fields. Either way, its caveat is read from the
+// caveat_name and caveat_context fields, the latter holding a JSON object, and its expiration from
+// the expires_at field, in RFC 3339 format.
+func ParseMapping(spec string) (Mapping, error) {
+	mapping := Mapping{spec: spec, sources: make(map[string]source, len(allFields))}
+	for _, field := range allFields {
+		mapping.sources[field] = source{column: field}
+	}
+
+	if strings.TrimSpace(spec) == "" {
+		return mapping, nil
+	}
+
+	for _, pair := range strings.Split(spec, ",") {
+		field, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || field == "" || value == "" {
+			return Mapping{}, fmt.Errorf("invalid column mapping `%s`: expected `field=column`", pair)
+		}
+
+		if !slices.Contains(allFields, field) {
+			return Mapping{}, fmt.Errorf("unknown relationship field `%s` in column mapping: expected one of %s", field, strings.Join(allFields, ", "))
+		}
+
+		if len(value) >= 2 && strings.HasPrefix(value, "'") && strings.HasSuffix(value, "'") {
+			mapping.sources[field] = source{constant: value[1 : len(value)-1]}
+			continue
+		}
+		mapping.sources[field] = source{column: value}
+	}
+
+	return mapping, nil
+}
+
+// relationship builds the relationship of the record.
+func (m Mapping) relationship(r record) (tuple.Relationship, error) {
+	var rel tuple.Relationship
+	if value, ok := m.sources[FieldRelationship].value(r); ok && value != "" {
+		parsed, err := tuple.Parse(value)
+		if err != nil {
+			return tuple.Relationship{}, err
+		}
+		rel = parsed
+	} else {
+		values := make(map[string]string, len(requiredFields))
+		for _, field := range requiredFields {
+			value, ok := m.sources[field].value(r)
+			if !ok || value == "" {
+				return tuple.Relationship{}, fmt.Errorf("missing value for field `%s`", field)
+			}
+			values[field] = value
+		}
+
+		subjectRelation := tuple.Ellipsis
+		if value, ok := m.sources[FieldSubjectRelation].value(r); ok && value != "" {
+			subjectRelation = value
+		}
+
+		rel = tuple.Relationship{
+			RelationshipReference: tuple.RelationshipReference{
+				Resource: tuple.ONR(values[FieldResourceType], values[FieldResourceID], values[FieldRelation]),
+				Subject:  tuple.ONR(values[FieldSubjectType], values[FieldSubjectID], subjectRelation),
+			},
+		}
+	}
+
+	if caveatName, ok := m.sources[FieldCaveatName].value(r); ok && caveatName != "" {
+		var caveatContext map[string]any
+		if encoded, ok := m.sources[FieldCaveatContext].value(r); ok && encoded != "" {
+			if err := json.Unmarshal([]byte(encoded), &caveatContext); err != nil {
+				return tuple.Relationship{}, fmt.Errorf("invalid caveat context: %w", err)
+			}
+		}
+
+		withCaveat, err := tuple.WithCaveat(rel, caveatName, caveatContext)
+		if err != nil {
+			return tuple.Relationship{}, err
+		}
+		rel = withCaveat
+	}
+
+	if expiresAt, ok := m.sources[FieldExpiresAt].value(r); ok && expiresAt != "" {
+		expiration, err := time.Parse(time.RFC3339, expiresAt)
+		if err != nil {
+			return tuple.Relationship{}, fmt.Errorf("invalid expiration: %w", err)
+		}
+		rel.OptionalExpiration = &expiration
+	}
+
+	if err := rel.Validate(); err != nil {
+		return tuple.Relationship{}, err
+	}
+	return rel, nil
+}
@@ -0,0 +1,122 @@
+package importer
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// The formats of the files which can be imported.
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+)
+
+// record is a record of the file being imported, keyed by column.
+type record map[string]string
+
+func (r record) get(column string) (string, bool) {
+	value, ok := r[column]
+	return value, ok
+}
+
+// recordReader reads the records of a file, one at a time, returning io.EOF once all have been
+// read.
+type recordReader interface {
+	read() (record, error)
+}
+
+func newRecordReader(format string, r io.Reader) (recordReader, error) {
+	switch strings.ToLower(format) {
+	case FormatCSV:
+		return newCSVReader(r)
+	case FormatNDJSON:
+		return &ndjsonReader{scanner: newLineScanner(r)}, nil
+	default:
+		return nil, fmt.Errorf("unknown import format `%s`: expected %s or %s", format, FormatCSV, FormatNDJSON)
+	}
+}
+
+// csvReader reads the records of a CSV file, whose first row holds the names of the columns.
+type csvReader struct {
+	reader  *csv.Reader
+	columns []string
+}
+
+func newCSVReader(r io.Reader) (*csvReader, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	columns, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("missing CSV header row")
+		}
+		return nil, fmt.Errorf("failed to read CSV header row: %w", err)
+	}
+
+	return &csvReader{reader: reader, columns: columns}, nil
+}
+
+func (c *csvReader) read() (record, error) {
+	row, err := c.reader.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	rec := make(record, len(c.columns))
+	for index, column := range c.columns {
+		rec[column] = row[index]
+	}
+	return rec, nil
+}
+
+// ndjsonReader reads the records of a newline-delimited JSON file, each line holding an object.
+// Values which are not strings, such as caveat contexts, are kept in their JSON encoding.
+type ndjsonReader struct {
+	scanner *bufio.Scanner
+}
+
+func newLineScanner(r io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	return scanner
+}
+
+func (n *ndjsonReader) read() (record, error) {
+	for n.scanner.Scan() {
+		line := strings.TrimSpace(n.scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(line), &fields); err != nil {
+			return nil, fmt.Errorf("invalid JSON object: %w", err)
+		}
+
+		rec := make(record, len(fields))
+		for column, raw := range fields {
+			if string(raw) == "null" {
+				continue
+			}
+
+			var value string
+			if err := json.Unmarshal(raw, &value); err == nil {
+				rec[column] = value
+				continue
+			}
+			rec[column] = string(raw)
+		}
+		return rec, nil
+	}
+
+	if err := n.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
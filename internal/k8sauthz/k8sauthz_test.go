@@ -0,0 +1,268 @@
+package k8sauthz
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/require"
+	authorizationv1 "k8s.io/api/authorization/v1"
+
+	"github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/internal/testserver"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+const testMapping = `
+userType: user
+groupType: group
+groupRelation: member
+rules:
+  - verbs: [get, list, watch]
+    apiGroups: [""]
+    resources: [pods, pods/log]
+    resourceType: namespace
+    resourceID: "{namespace}"
+    permission: read_pods
+  - verbs: ["*"]
+    resources: ["*"]
+    resourceType: cluster
+    resourceID: main
+    permission: admin
+  - verbs: [get]
+    nonResourcePaths: [/healthz, /metrics*]
+    resourceType: cluster
+    resourceID: main
+    permission: read_status
+`
+
+func TestObjectID(t *testing.T) {
+	require.Equal(t, "system|serviceaccount|default|builder", ObjectID("system:serviceaccount:default:builder"))
+	require.Equal(t, "jane=40example=2Ecom", ObjectID("jane@example.com"))
+	require.Equal(t, "a=7Cb", ObjectID("a|b"))
+	require.Equal(t, "a=3D7Cb", ObjectID("a=7Cb"))
+}
+
+func TestConfigValidation(t *testing.T) {
+	for _, tc := range []struct {
+		name          string
+		config        Config
+		expectedError string
+	}{
+		{"no mapping", Config{}, "a mapping file is required"},
+		{"certificate without key", Config{MappingPath: "m.yaml", TLSCertPath: "tls.crt"}, "must be set together"},
+		{"no TLS", Config{MappingPath: "m.yaml", ClientCAPath: "ca.crt"}, "client certificates can only be verified when serving over TLS"},
+		{"insecure", Config{MappingPath: "m.yaml"}, "must be served over TLS unless insecure serving is explicitly allowed"},
+		{"no client CA", Config{MappingPath: "m.yaml", TLSCertPath: "tls.crt", TLSKeyPath: "tls.key"}, "a client CA is required"},
+		{"client certificates", Config{MappingPath: "m.yaml", TLSCertPath: "tls.crt", TLSKeyPath: "tls.key", ClientCAPath: "ca.crt"}, ""},
+		{"allowed insecure", Config{MappingPath: "m.yaml", AllowInsecure: true}, ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.config.validate()
+			if tc.expectedError == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestClientCertificatesRequired(t *testing.T) {
+	ca := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{Organization: []string{"testCA"}},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(5 * time.Minute),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	caDER, err := x509.CreateCertificate(rand.Reader, ca, ca, &caKey.PublicKey, caKey)
+	require.NoError(t, err)
+	caCert, err := x509.ParseCertificate(caDER)
+	require.NoError(t, err)
+
+	caPath := filepath.Join(t.TempDir(), "ca.crt")
+	require.NoError(t, os.WriteFile(caPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER}), 0o600))
+
+	client := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "kube-apiserver"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(5 * time.Minute),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	clientKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	clientDER, err := x509.CreateCertificate(rand.Reader, client, caCert, &clientKey.PublicKey, caKey)
+	require.NoError(t, err)
+
+	tlsConfig, err := Config{ClientCAPath: caPath}.tlsConfig()
+	require.NoError(t, err)
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	srv.TLS = tlsConfig
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+
+	// Clients without a certificate signed by the client CA are refused.
+	_, err = srv.Client().Get(srv.URL)
+	require.Error(t, err)
+
+	httpClient := srv.Client()
+	httpClient.Transport.(*http.Transport).TLSClientConfig.Certificates = []tls.Certificate{{
+		Certificate: [][]byte{clientDER},
+		PrivateKey:  clientKey,
+	}}
+	resp, err := httpClient.Get(srv.URL)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestParseMappingErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name          string
+		mapping       string
+		expectedError string
+	}{
+		{"missing user type", "rules: []", "object type of users is required"},
+		{"group without relation", "userType: user\ngroupType: group", "must be set together"},
+		{"missing verbs", "userType: user\nrules: [{resources: [pods], resourceType: ns, resourceID: x, permission: p}]", "verbs are required"},
+		{"resources and paths", "userType: user\nrules: [{verbs: [get], resources: [pods], nonResourcePaths: [/], resourceType: ns, resourceID: x, permission: p}]", "either resources or non-resource paths"},
+		{"unknown variable", "userType: user\nrules: [{verbs: [get], resources: [pods], resourceType: ns, resourceID: '{cluster}', permission: p}]", "unknown variable"},
+		{"unknown field", "userType: user\nusers: user", "field users not found"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseMapping([]byte(tc.mapping))
+			require.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestWebhook(t *testing.T) {
+	conn, cleanup, _, _ := testserver.NewTestServer(require.New(t), 0, time.Hour, true, testfixtures.EmptyDatastore)
+	t.Cleanup(cleanup)
+
+	_, err := v1.NewSchemaServiceClient(conn).WriteSchema(context.Background(), &v1.WriteSchemaRequest{
+		Schema: `definition user {}
+
+		definition group {
+			relation member: user
+		}
+
+		definition cluster {
+			relation admin: user | group#member
+			relation observer: user
+			permission read_status = observer + admin
+		}
+
+		definition namespace {
+			relation viewer: user | group#member
+			permission read_pods = viewer
+		}`,
+	})
+	require.NoError(t, err)
+
+	var updates []*v1.RelationshipUpdate
+	for _, rel := range []string{
+		"namespace:default#viewer@user:system|serviceaccount|default|builder",
+		"namespace:kube-system#viewer@group:sre#member",
+		"cluster:main#admin@group:system|masters#member",
+		"cluster:main#observer@user:prometheus",
+	} {
+		updates = append(updates, tuple.MustUpdateToV1RelationshipUpdate(tuple.Create(tuple.MustParse(rel))))
+	}
+	_, err = v1.NewPermissionsServiceClient(conn).WriteRelationships(context.Background(), &v1.WriteRelationshipsRequest{Updates: updates})
+	require.NoError(t, err)
+
+	mapping, err := ParseMapping([]byte(testMapping))
+	require.NoError(t, err)
+	webhook := NewWebhook(v1.NewPermissionsServiceClient(conn), mapping, 0)
+
+	pods := func(verb, namespace string) *authorizationv1.ResourceAttributes {
+		return &authorizationv1.ResourceAttributes{Verb: verb, Resource: "pods", Namespace: namespace}
+	}
+
+	for _, tc := range []struct {
+		name            string
+		spec            authorizationv1.SubjectAccessReviewSpec
+		denyUnpermitted bool
+		expectedAllowed bool
+		expectedDenied  bool
+	}{
+		{
+			name:            "user permitted",
+			spec:            authorizationv1.SubjectAccessReviewSpec{User: "system:serviceaccount:default:builder", ResourceAttributes: pods("list", "default")},
+			expectedAllowed: true,
+		},
+		{
+			name:            "group permitted",
+			spec:            authorizationv1.SubjectAccessReviewSpec{User: "alice", Groups: []string{"developers", "sre"}, ResourceAttributes: pods("get", "kube-system")},
+			expectedAllowed: true,
+		},
+		{
+			name: "not permitted has no opinion",
+			spec: authorizationv1.SubjectAccessReviewSpec{User: "alice", Groups: []string{"developers"}, ResourceAttributes: pods("get", "kube-system")},
+		},
+		{
+			name:            "not permitted denied",
+			spec:            authorizationv1.SubjectAccessReviewSpec{User: "alice", ResourceAttributes: pods("get", "kube-system")},
+			denyUnpermitted: true,
+			expectedDenied:  true,
+		},
+		{
+			name:            "later rule",
+			spec:            authorizationv1.SubjectAccessReviewSpec{User: "bob", Groups: []string{"system:masters"}, ResourceAttributes: pods("delete", "default")},
+			expectedAllowed: true,
+		},
+		{
+			name:            "non-resource path",
+			spec:            authorizationv1.SubjectAccessReviewSpec{User: "prometheus", NonResourceAttributes: &authorizationv1.NonResourceAttributes{Verb: "get", Path: "/metrics/cadvisor"}},
+			expectedAllowed: true,
+		},
+		{
+			name: "no rule",
+			spec: authorizationv1.SubjectAccessReviewSpec{User: "prometheus", NonResourceAttributes: &authorizationv1.NonResourceAttributes{Verb: "post", Path: "/metrics"}},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			mapping.DenyUnpermitted = tc.denyUnpermitted
+
+			body, err := json.Marshal(authorizationv1.SubjectAccessReview{Spec: tc.spec})
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			webhook.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(string(body))))
+			require.Equal(t, http.StatusOK, recorder.Code)
+
+			var review authorizationv1.SubjectAccessReview
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &review))
+			require.Equal(t, "authorization.k8s.io/v1", review.APIVersion)
+			require.Empty(t, review.Status.EvaluationError)
+			require.Equal(t, tc.expectedAllowed, review.Status.Allowed, review.Status.Reason)
+			require.Equal(t, tc.expectedDenied, review.Status.Denied, review.Status.Reason)
+		})
+	}
+}
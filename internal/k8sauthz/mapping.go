@@ -0,0 +1,212 @@
+package k8sauthz
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	yamlv3 "gopkg.in/yaml.v3"
+	authorizationv1 "k8s.io/api/authorization/v1"
+)
+
+// Mapping is the translation of SubjectAccessReviews into permission checks, as read from YAML:
+//
+//	userType: user
+//	groupType: group
+//	groupRelation: member
+//	rules:
+//	  - verbs: [get, list, watch]
+//	    apiGroups: [""]
+//	    resources: [pods, pods/log]
+//	    resourceType: namespace
+//	    resourceID: "{namespace}"
+//	    permission: read_pods
+//	  - verbs: [get]
+//	    nonResourcePaths: [/healthz, /metrics*]
+//	    resourceType: cluster
+//	    resourceID: main
+//	    permission: read_status
+//
+// The rules are matched in order against each review, and the first which matches is applied:
+// the review is allowed if the user, or one of its groups, has the permission on the resource.
+type Mapping struct {
+	// UserType is the object type of the Kubernetes users.
+	UserType string `yaml:"userType"`
+
+	// GroupType, if not empty, is the object type of the Kubernetes groups, whose subject set of
+	// GroupRelation is checked in addition to the user.
+	GroupType     string `yaml:"groupType"`
+	GroupRelation string `yaml:"groupRelation"`
+
+	// DenyUnpermitted denies the reviews matched by a rule whose permission is not granted, so
+	// that no other authorizer is consulted. Otherwise, the webhook has no opinion on them.
+	DenyUnpermitted bool `yaml:"denyUnpermitted"`
+
+	// Rules are the rules mapping reviews to permissions.
+	Rules []*Rule `yaml:"rules"`
+}
+
+// Rule maps the reviews matching its verbs and resources, or non-resource paths, to a permission.
+// They are matched as in RBAC: `*` matches any verb, API group or resource, a resource
+// `resource/subresource` matches a subresource, and a non-resource path ending with `*` matches
+// any path with its prefix. Rules without API groups match resources of any API group.
+//
+// The ID of the resource is a template in which `{namespace}`, `{name}`, `{resource}`,
+// `{subresource}`, `{apiGroup}`, `{verb}` and `{path}` are replaced by the attributes of the
+// review, escaped with ObjectID. Reviews for which it is empty, such as those for cluster-scoped
+// resources when it is `{namespace}`, are not matched by the rule.
+type Rule struct {
+	Verbs            []string `yaml:"verbs"`
+	APIGroups        []string `yaml:"apiGroups"`
+	Resources        []string `yaml:"resources"`
+	NonResourcePaths []string `yaml:"nonResourcePaths"`
+
+	ResourceType string `yaml:"resourceType"`
+	ResourceID   string `yaml:"resourceID"`
+	Permission   string `yaml:"permission"`
+}
+
+var templateVariables = []string{"namespace", "name", "resource", "subresource", "apiGroup", "verb", "path"}
+
+// LoadMapping reads the mapping from the YAML file at the path.
+func LoadMapping(path string) (*Mapping, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mapping: %w", err)
+	}
+	return ParseMapping(contents)
+}
+
+// ParseMapping parses and validates a mapping from YAML.
+func ParseMapping(contents []byte) (*Mapping, error) {
+	decoder := yamlv3.NewDecoder(bytes.NewReader(contents))
+	decoder.KnownFields(true)
+
+	var mapping Mapping
+	if err := decoder.Decode(&mapping); err != nil {
+		return nil, fmt.Errorf("failed to parse mapping: %w", err)
+	}
+
+	if mapping.UserType == "" {
+		return nil, errors.New("the object type of users is required")
+	}
+	if (mapping.GroupType == "") != (mapping.GroupRelation == "") {
+		return nil, errors.New("the object type and relation of groups must be set together")
+	}
+
+	for index, rule := range mapping.Rules {
+		if err := rule.validate(); err != nil {
+			return nil, fmt.Errorf("invalid rule %d: %w", index, err)
+		}
+	}
+	return &mapping, nil
+}
+
+func (r *Rule) validate() error {
+	if len(r.Verbs) == 0 {
+		return errors.New("verbs are required")
+	}
+	if (len(r.Resources) == 0) == (len(r.NonResourcePaths) == 0) {
+		return errors.New("either resources or non-resource paths are required")
+	}
+	if len(r.NonResourcePaths) > 0 && len(r.APIGroups) > 0 {
+		return errors.New("API groups cannot be matched with non-resource paths")
+	}
+	if r.ResourceType == "" || r.ResourceID == "" || r.Permission == "" {
+		return errors.New("resource type, resource ID and permission are required")
+	}
+
+	placeholders := make([]string, 0, 2*len(templateVariables))
+	for _, variable := range templateVariables {
+		placeholders = append(placeholders, "{"+variable+"}", "")
+	}
+	if unknown := strings.NewReplacer(placeholders...).Replace(r.ResourceID); strings.ContainsAny(unknown, "{}") {
+		return fmt.Errorf("resource ID `%s` holds an unknown variable", r.ResourceID)
+	}
+	return nil
+}
+
+// resourceID returns the ID of the resource checked for the review, if the rule matches it.
+func (r *Rule) resourceID(attributes reviewAttributes) (string, bool) {
+	if !matches(r.Verbs, attributes.verb) {
+		return "", false
+	}
+
+	if attributes.resource != nil {
+		if len(r.Resources) == 0 || (len(r.APIGroups) > 0 && !matches(r.APIGroups, attributes.resource.Group)) {
+			return "", false
+		}
+
+		resource := attributes.resource.Resource
+		if attributes.resource.Subresource != "" {
+			resource += "/" + attributes.resource.Subresource
+		}
+		if !matches(r.Resources, resource) {
+			return "", false
+		}
+	} else {
+		if len(r.NonResourcePaths) == 0 || !slices.ContainsFunc(r.NonResourcePaths, func(pattern string) bool {
+			if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+				return strings.HasPrefix(attributes.path, prefix)
+			}
+			return pattern == attributes.path
+		}) {
+			return "", false
+		}
+	}
+
+	id := attributes.replacer().Replace(r.ResourceID)
+	return id, id != ""
+}
+
+func matches(patterns []string, value string) bool {
+	return slices.Contains(patterns, "*") || slices.Contains(patterns, value)
+}
+
+// reviewAttributes are the attributes of a SubjectAccessReview which are matched by rules.
+type reviewAttributes struct {
+	verb     string
+	path     string
+	resource *authorizationv1.ResourceAttributes
+}
+
+func (ra reviewAttributes) replacer() *strings.Replacer {
+	var resource authorizationv1.ResourceAttributes
+	if ra.resource != nil {
+		resource = *ra.resource
+	}
+
+	return strings.NewReplacer(
+		"{namespace}", ObjectID(resource.Namespace),
+		"{name}", ObjectID(resource.Name),
+		"{resource}", ObjectID(resource.Resource),
+		"{subresource}", ObjectID(resource.Subresource),
+		"{apiGroup}", ObjectID(resource.Group),
+		"{verb}", ObjectID(ra.verb),
+		"{path}", ObjectID(ra.path),
+	)
+}
+
+// ObjectID escapes a Kubernetes name into a valid SpiceDB object ID: `:` is replaced by `|`, and
+// every other byte not allowed in object IDs, including `|` and `=`, by `=` followed by its
+// uppercase hexadecimal value. For example, the user `system:serviceaccount:default:builder` is
+// the object ID `system|serviceaccount|default|builder`, and `jane@example.com` is
+// `jane=40example=2Ecom`.
+func ObjectID(name string) string {
+	var escaped strings.Builder
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		switch {
+		case c == ':':
+			escaped.WriteByte('|')
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '/', c == '_', c == '-', c == '+':
+			escaped.WriteByte(c)
+		default:
+			fmt.Fprintf(&escaped, "=%02X", c)
+		}
+	}
+	return escaped.String()
+}
@@ -0,0 +1,275 @@
+// Package k8sauthz implements a Kubernetes authorization webhook backed by the permission checks of
+// a SpiceDB instance, translating SubjectAccessReviews into checks with a Mapping.
+package k8sauthz
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"google.golang.org/grpc"
+	authorizationv1 "k8s.io/api/authorization/v1"
+
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/pkg/x509util"
+)
+
+// Config is the configuration of the webhook server.
+type Config struct {
+	// Addr is the address on which the webhook is served.
+	Addr string
+
+	// TLSCertPath and TLSKeyPath, if not empty, are the paths of the certificate and key with
+	// which the webhook is served over HTTPS, as required by the Kubernetes API server.
+	TLSCertPath string
+	TLSKeyPath  string
+
+	// ClientCAPath is the path of the CA certificates, or of a directory of them, with which the
+	// client certificates of the API server are verified. Requests without a client certificate
+	// signed by one of them are refused.
+	ClientCAPath string
+
+	// AllowInsecure, if true, allows serving the webhook without TLS or without verifying client
+	// certificates, letting anyone able to reach it learn the permissions of any user.
+	AllowInsecure bool
+
+	// MappingPath is the path of the YAML file of the Mapping.
+	MappingPath string
+
+	// RequestTimeout is the maximum duration of the checks of a review.
+	RequestTimeout time.Duration
+}
+
+func (c Config) validate() error {
+	if c.MappingPath == "" {
+		return errors.New("a mapping file is required")
+	}
+	if (c.TLSCertPath == "") != (c.TLSKeyPath == "") {
+		return errors.New("the TLS certificate and key must be set together")
+	}
+	if c.ClientCAPath != "" && c.TLSCertPath == "" {
+		return errors.New("client certificates can only be verified when serving over TLS")
+	}
+	if !c.AllowInsecure {
+		if c.TLSCertPath == "" {
+			return errors.New("the webhook must be served over TLS unless insecure serving is explicitly allowed")
+		}
+		if c.ClientCAPath == "" {
+			return errors.New("a client CA is required to verify the client certificates of the API server unless insecure serving is explicitly allowed")
+		}
+	}
+	return nil
+}
+
+// tlsConfig returns the TLS configuration of the server, requiring client certificates signed by
+// the client CA if any.
+func (c Config) tlsConfig() (*tls.Config, error) {
+	if c.ClientCAPath == "" {
+		return nil, nil
+	}
+
+	pool, err := x509util.CustomCertPool(c.ClientCAPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the client CA: %w", err)
+	}
+
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ClientCAs:  pool,
+		ClientAuth: tls.RequireAndVerifyClientCert,
+	}, nil
+}
+
+// Run serves the webhook until the context is canceled, checking permissions with the SpiceDB
+// instance behind the connection.
+func Run(ctx context.Context, conn grpc.ClientConnInterface, config Config) error {
+	if err := config.validate(); err != nil {
+		return err
+	}
+
+	mapping, err := LoadMapping(config.MappingPath)
+	if err != nil {
+		return err
+	}
+
+	tlsConfig, err := config.tlsConfig()
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/", NewWebhook(v1.NewPermissionsServiceClient(conn), mapping, config.RequestTimeout))
+	server := &http.Server{
+		Addr:              config.Addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+		TLSConfig:         tlsConfig,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Ctx(ctx).Warn().Err(err).Msg("failed to gracefully shut down the webhook server")
+		}
+	}()
+
+	log.Ctx(ctx).Info().Str("addr", config.Addr).Bool("tls", config.TLSCertPath != "").Bool("client-certs", tlsConfig != nil).Int("rules", len(mapping.Rules)).Msg("serving kubernetes authorization webhook")
+	if config.AllowInsecure && tlsConfig == nil {
+		log.Ctx(ctx).Warn().Msg("serving kubernetes authorization webhook without verifying client certificates: anyone able to reach it can learn the permissions of any user")
+	}
+	if config.TLSCertPath != "" {
+		err = server.ListenAndServeTLS(config.TLSCertPath, config.TLSKeyPath)
+	} else {
+		err = server.ListenAndServe()
+	}
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// Webhook is the HTTP handler of SubjectAccessReviews.
+type Webhook struct {
+	client  v1.PermissionsServiceClient
+	mapping *Mapping
+	timeout time.Duration
+}
+
+// NewWebhook creates the handler of SubjectAccessReviews, checking the permissions of the mapping
+// with the client. A timeout of 0 does not limit the duration of checks.
+func NewWebhook(client v1.PermissionsServiceClient, mapping *Mapping, timeout time.Duration) *Webhook {
+	return &Webhook{client: client, mapping: mapping, timeout: timeout}
+}
+
+func (wh *Webhook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "SubjectAccessReviews must be POSTed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var review authorizationv1.SubjectAccessReview
+	if err := json.NewDecoder(r.Body).Decode(&review); err != nil {
+		http.Error(w, fmt.Sprintf("invalid SubjectAccessReview: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	if wh.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, wh.timeout)
+		defer cancel()
+	}
+
+	review.Status = wh.review(ctx, review.Spec)
+	if review.APIVersion == "" {
+		review.APIVersion = authorizationv1.SchemeGroupVersion.String()
+		review.Kind = "SubjectAccessReview"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(review); err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("failed to write SubjectAccessReview response")
+	}
+}
+
+// review returns the status of the review: allowed if the user or one of its groups has the
+// permission of the first rule matching it, and otherwise denied, or with no opinion.
+func (wh *Webhook) review(ctx context.Context, spec authorizationv1.SubjectAccessReviewSpec) authorizationv1.SubjectAccessReviewStatus {
+	attributes := reviewAttributes{resource: spec.ResourceAttributes}
+	switch {
+	case spec.ResourceAttributes != nil:
+		attributes.verb = spec.ResourceAttributes.Verb
+	case spec.NonResourceAttributes != nil:
+		attributes.verb = spec.NonResourceAttributes.Verb
+		attributes.path = spec.NonResourceAttributes.Path
+	default:
+		return authorizationv1.SubjectAccessReviewStatus{EvaluationError: "the review has neither resource nor non-resource attributes"}
+	}
+
+	for _, rule := range wh.mapping.Rules {
+		resourceID, ok := rule.resourceID(attributes)
+		if !ok {
+			continue
+		}
+
+		permitted, err := wh.check(ctx, spec, rule, resourceID)
+		switch {
+		case err != nil:
+			log.Ctx(ctx).Warn().Err(err).Str("user", spec.User).Msg("failed to check permission for SubjectAccessReview")
+			return authorizationv1.SubjectAccessReviewStatus{EvaluationError: err.Error()}
+		case permitted:
+			return authorizationv1.SubjectAccessReviewStatus{
+				Allowed: true,
+				Reason:  fmt.Sprintf("permission %s on %s:%s", rule.Permission, rule.ResourceType, resourceID),
+			}
+		default:
+			return authorizationv1.SubjectAccessReviewStatus{
+				Denied: wh.mapping.DenyUnpermitted,
+				Reason: fmt.Sprintf("no permission %s on %s:%s", rule.Permission, rule.ResourceType, resourceID),
+			}
+		}
+	}
+	return authorizationv1.SubjectAccessReviewStatus{Reason: "no rule matches the request"}
+}
+
+// check returns whether the user or one of its groups has the permission of the rule on the
+// resource, checked in bulk. The review is permitted if any of them is, even if the checks of
+// others fail.
+func (wh *Webhook) check(ctx context.Context, spec authorizationv1.SubjectAccessReviewSpec, rule *Rule, resourceID string) (bool, error) {
+	resource := &v1.ObjectReference{ObjectType: rule.ResourceType, ObjectId: resourceID}
+
+	var items []*v1.CheckBulkPermissionsRequestItem
+	if spec.User != "" {
+		items = append(items, &v1.CheckBulkPermissionsRequestItem{
+			Resource:   resource,
+			Permission: rule.Permission,
+			Subject: &v1.SubjectReference{
+				Object: &v1.ObjectReference{ObjectType: wh.mapping.UserType, ObjectId: ObjectID(spec.User)},
+			},
+		})
+	}
+	if wh.mapping.GroupType != "" {
+		for _, group := range spec.Groups {
+			items = append(items, &v1.CheckBulkPermissionsRequestItem{
+				Resource:   resource,
+				Permission: rule.Permission,
+				Subject: &v1.SubjectReference{
+					Object:           &v1.ObjectReference{ObjectType: wh.mapping.GroupType, ObjectId: ObjectID(group)},
+					OptionalRelation: wh.mapping.GroupRelation,
+				},
+			})
+		}
+	}
+	if len(items) == 0 {
+		return false, nil
+	}
+
+	resp, err := wh.client.CheckBulkPermissions(ctx, &v1.CheckBulkPermissionsRequest{
+		Consistency: &v1.Consistency{Requirement: &v1.Consistency_MinimizeLatency{MinimizeLatency: true}},
+		Items:       items,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	var checkErr error
+	for _, pair := range resp.Pairs {
+		switch response := pair.Response.(type) {
+		case *v1.CheckBulkPermissionsPair_Item:
+			if response.Item.Permissionship == v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION {
+				return true, nil
+			}
+		case *v1.CheckBulkPermissionsPair_Error:
+			checkErr = errors.New(response.Error.Message)
+		}
+	}
+	return false, checkErr
+}
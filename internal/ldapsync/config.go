@@ -0,0 +1,157 @@
+package ldapsync
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+
+	yamlv3 "gopkg.in/yaml.v3"
+
+	"github.com/authzed/spicedb/pkg/fips"
+)
+
+// IncrementalMode is how the changes made since the previous sync are found.
+type IncrementalMode string
+
+const (
+	// IncrementalNone runs a full sync every time.
+	IncrementalNone IncrementalMode = "none"
+
+	// IncrementalUSN finds the entries changed since the previous sync by their uSNChanged
+	// attribute, as maintained by Active Directory.
+	IncrementalUSN IncrementalMode = "usn"
+
+	// IncrementalChangelog finds the entries changed since the previous sync in the retro
+	// changelog of the directory, as maintained by 389 Directory Server and OpenLDAP.
+	IncrementalChangelog IncrementalMode = "changelog"
+)
+
+// Config is the configuration of the directory and of the relationships it is synced into, as
+// read from YAML:
+//
+//	url: ldaps://ad.example.com
+//	bindDN: cn=spicedb,ou=services,dc=example,dc=com
+//	bindPasswordEnv: LDAP_BIND_PASSWORD
+//	users:
+//	  baseDN: ou=people,dc=example,dc=com
+//	  filter: (objectClass=user)
+//	  idAttribute: sAMAccountName
+//	groups:
+//	  baseDN: ou=groups,dc=example,dc=com
+//	  filter: (objectClass=group)
+//	  idAttribute: cn
+//	  memberAttribute: member
+//	objectTypes:
+//	  user: user
+//	  group: group
+//	  memberRelation: member
+//	incremental: usn
+//
+// The members of each group, found by their DNs in its member attribute, are synced into the
+// member relation of the group: users as `group:engineering#member@user:jane`, and nested groups
+// as `group:engineering#member@group:platform#member`. IDs are escaped into object IDs as by the
+// SCIM API, so that users and groups provisioned by both match.
+type Config struct {
+	// URL is the URL of the directory, with the ldap or ldaps scheme.
+	URL string `yaml:"url"`
+
+	// StartTLS upgrades ldap connections to TLS.
+	StartTLS bool `yaml:"startTLS"`
+
+	// InsecureSkipVerify skips the verification of the certificate of the directory.
+	InsecureSkipVerify bool `yaml:"insecureSkipVerify"`
+
+	// BindDN is the DN with which the connector binds, and BindPasswordEnv the environment
+	// variable holding its password. If empty, the connector binds anonymously.
+	BindDN          string `yaml:"bindDN"`
+	BindPasswordEnv string `yaml:"bindPasswordEnv"`
+
+	Users  EntryConfig `yaml:"users"`
+	Groups EntryConfig `yaml:"groups"`
+
+	ObjectTypes ObjectTypes `yaml:"objectTypes"`
+
+	// Incremental is how changes are found between full syncs, defaulting to none.
+	Incremental IncrementalMode `yaml:"incremental"`
+
+	// ChangelogBaseDN is the base DN of the changelog, defaulting to `cn=changelog`.
+	ChangelogBaseDN string `yaml:"changelogBaseDN"`
+}
+
+// EntryConfig is where users or groups are searched in the directory.
+type EntryConfig struct {
+	BaseDN string `yaml:"baseDN"`
+	Filter string `yaml:"filter"`
+
+	// IDAttribute is the attribute holding the ID of the object of the entry.
+	IDAttribute string `yaml:"idAttribute"`
+
+	// MemberAttribute is the attribute of groups holding the DNs of their members.
+	MemberAttribute string `yaml:"memberAttribute"`
+}
+
+// ObjectTypes are the object types and relation into which memberships are synced.
+type ObjectTypes struct {
+	User           string `yaml:"user"`
+	Group          string `yaml:"group"`
+	MemberRelation string `yaml:"memberRelation"`
+}
+
+// LoadConfig reads the configuration from the YAML file at the path.
+func LoadConfig(path string) (*Config, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read LDAP sync config: %w", err)
+	}
+	return ParseConfig(contents)
+}
+
+// ParseConfig parses and validates a configuration from YAML, filling in defaults.
+func ParseConfig(contents []byte) (*Config, error) {
+	decoder := yamlv3.NewDecoder(bytes.NewReader(contents))
+	decoder.KnownFields(true)
+
+	var config Config
+	if err := decoder.Decode(&config); err != nil {
+		return nil, fmt.Errorf("failed to parse LDAP sync config: %w", err)
+	}
+
+	if config.URL == "" {
+		return nil, errors.New("the URL of the directory is required")
+	}
+	if config.BindPasswordEnv != "" && config.BindDN == "" {
+		return nil, errors.New("a bind DN is required with a bind password")
+	}
+	if config.InsecureSkipVerify && fips.Enabled() {
+		return nil, errors.New("the certificate of the directory must be verified in FIPS mode")
+	}
+	if config.Users.BaseDN == "" || config.Users.IDAttribute == "" {
+		return nil, errors.New("the base DN and ID attribute of users are required")
+	}
+	if config.Groups.BaseDN == "" || config.Groups.IDAttribute == "" || config.Groups.MemberAttribute == "" {
+		return nil, errors.New("the base DN, ID attribute and member attribute of groups are required")
+	}
+	if config.ObjectTypes.User == "" || config.ObjectTypes.Group == "" || config.ObjectTypes.MemberRelation == "" {
+		return nil, errors.New("the user and group object types and the member relation are required")
+	}
+
+	if config.Users.Filter == "" {
+		config.Users.Filter = "(objectClass=*)"
+	}
+	if config.Groups.Filter == "" {
+		config.Groups.Filter = "(objectClass=*)"
+	}
+	if config.ChangelogBaseDN == "" {
+		config.ChangelogBaseDN = "cn=changelog"
+	}
+
+	switch config.Incremental {
+	case "":
+		config.Incremental = IncrementalNone
+	case IncrementalNone, IncrementalUSN, IncrementalChangelog:
+	default:
+		return nil, fmt.Errorf("unknown incremental mode `%s`: must be one of none, usn or changelog", config.Incremental)
+	}
+	return &config, nil
+}
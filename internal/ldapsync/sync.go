@@ -0,0 +1,628 @@
+// Package ldapsync implements a connector syncing the group memberships of an LDAP directory, such
+// as Active Directory, into relationships.
+package ldapsync
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/go-ldap/ldap/v3"
+	"google.golang.org/grpc"
+
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/internal/scim"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+const (
+	// searchPageSize is the page size of searches, below the default limit of Active Directory.
+	searchPageSize = 500
+
+	// maxUpdatesPerWrite is the number of updates written per WriteRelationships request, which is
+	// the default maximum of the server.
+	maxUpdatesPerWrite = 1000
+)
+
+// ErrDeletionThreshold is returned by syncs which would delete more relationships than allowed by
+// the deletion protection thresholds, and which therefore write nothing.
+var ErrDeletionThreshold = errors.New("deletion protection threshold exceeded")
+
+// Options are the options of the runs of the connector.
+type Options struct {
+	// Interval is the duration between syncs. If 0, a single sync is run.
+	Interval time.Duration
+
+	// FullSyncInterval is the duration after which a full sync is run instead of an incremental
+	// one, as incremental syncs do not find deleted entries. If 0, only the first sync is full.
+	FullSyncInterval time.Duration
+
+	// DryRun writes the diff of each sync to DiffOutput instead of writing relationships.
+	DryRun     bool
+	DiffOutput io.Writer
+
+	// MaxDeletions and MaxDeletionPercent, if not 0, abort the syncs which would delete more
+	// relationships, or a larger percentage of the synced relationships.
+	MaxDeletions       int
+	MaxDeletionPercent float64
+}
+
+// Run syncs the directory into the SpiceDB instance behind the connection until the context is
+// canceled, or once if the interval of the options is 0. The directory is dialed for each sync.
+func Run(ctx context.Context, conn grpc.ClientConnInterface, config *Config, options Options) error {
+	syncer := NewSyncer(v1.NewPermissionsServiceClient(conn), config, options)
+	for {
+		err := syncOnce(ctx, syncer, config)
+		if options.Interval == 0 {
+			return err
+		}
+		if err != nil && ctx.Err() == nil {
+			log.Ctx(ctx).Error().Err(err).Dur("retry-in", options.Interval).Msg("failed to sync LDAP group memberships")
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(options.Interval):
+		}
+	}
+}
+
+func syncOnce(ctx context.Context, syncer *Syncer, config *Config) error {
+	directory, err := Dial(config)
+	if err != nil {
+		return err
+	}
+	defer directory.Close()
+	return syncer.Sync(ctx, directory)
+}
+
+// Dial connects and binds to the directory.
+func Dial(config *Config) (ldap.Client, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: config.InsecureSkipVerify} // nolint:gosec
+	if u, err := url.Parse(config.URL); err == nil {
+		tlsConfig.ServerName = u.Hostname()
+	}
+
+	conn, err := ldap.DialURL(config.URL, ldap.DialWithTLSConfig(tlsConfig))
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial LDAP directory: %w", err)
+	}
+	if config.StartTLS {
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to start TLS with LDAP directory: %w", err)
+		}
+	}
+	if config.BindDN != "" {
+		if err := conn.Bind(config.BindDN, os.Getenv(config.BindPasswordEnv)); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to bind to LDAP directory: %w", err)
+		}
+	}
+	return conn, nil
+}
+
+// Syncer syncs the group memberships of the directory into relationships, keeping the state of
+// the directory found by the previous sync for incremental syncs.
+type Syncer struct {
+	client  v1.PermissionsServiceClient
+	config  *Config
+	options Options
+	state   *syncState
+}
+
+// syncState is the state of the directory found by a sync.
+type syncState struct {
+	// users and groups are the object IDs of the users and groups, by normalized DN.
+	users  map[string]string
+	groups map[string]string
+
+	// cursor is the highest USN or change number through which changes were synced.
+	cursor int64
+
+	// synced is the number of relationships synced.
+	synced int
+
+	fullSyncAt time.Time
+}
+
+// NewSyncer creates a syncer writing the relationships with the client.
+func NewSyncer(client v1.PermissionsServiceClient, config *Config, options Options) *Syncer {
+	return &Syncer{client: client, config: config, options: options}
+}
+
+// Sync runs a full sync if none has yet succeeded, incremental syncs are disabled or the full sync
+// interval has elapsed, and otherwise an incremental sync.
+//
+// A full sync writes the memberships of all the groups, and deletes all other relationships of
+// the member relation of groups with users or groups as subjects. An incremental sync rewrites the
+// memberships of the groups changed since the previous sync, and of those with changed members.
+func (s *Syncer) Sync(ctx context.Context, directory ldap.Client) error {
+	full := s.state == nil || s.config.Incremental == IncrementalNone ||
+		(s.options.FullSyncInterval > 0 && time.Since(s.state.fullSyncAt) >= s.options.FullSyncInterval)
+
+	var next *syncState
+	var err error
+	if full {
+		next, err = s.fullSync(ctx, directory)
+	} else {
+		next, err = s.incrementalSync(ctx, directory)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Dry runs do not change the state, so that each diffs against the previous actual sync.
+	if !s.options.DryRun {
+		s.state = next
+	}
+	return nil
+}
+
+func (s *Syncer) fullSync(ctx context.Context, directory ldap.Client) (*syncState, error) {
+	cursor, err := s.readCursor(directory)
+	if err != nil {
+		return nil, err
+	}
+
+	next := &syncState{users: map[string]string{}, groups: map[string]string{}, cursor: cursor, fullSyncAt: time.Now()}
+	users, err := search(directory, s.config.Users.BaseDN, s.config.Users.Filter, []string{s.config.Users.IDAttribute})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search users: %w", err)
+	}
+	for _, entry := range users {
+		if id := entry.GetEqualFoldAttributeValue(s.config.Users.IDAttribute); id != "" {
+			next.users[normalizeDN(entry.DN)] = scim.ObjectID(id)
+		}
+	}
+
+	groups, err := search(directory, s.config.Groups.BaseDN, s.config.Groups.Filter, []string{s.config.Groups.IDAttribute, s.config.Groups.MemberAttribute})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search groups: %w", err)
+	}
+	for _, entry := range groups {
+		if id := entry.GetEqualFoldAttributeValue(s.config.Groups.IDAttribute); id != "" {
+			next.groups[normalizeDN(entry.DN)] = scim.ObjectID(id)
+		}
+	}
+
+	desired := relationshipSet{}
+	for _, entry := range groups {
+		if err := s.addMemberships(directory, next, desired, entry); err != nil {
+			return nil, err
+		}
+	}
+
+	current, err := s.readMemberships(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	if err := s.apply(ctx, current, desired, len(current), true); err != nil {
+		return nil, err
+	}
+
+	next.synced = len(desired)
+	return next, nil
+}
+
+func (s *Syncer) incrementalSync(ctx context.Context, directory ldap.Client) (*syncState, error) {
+	cursor, err := s.readCursor(directory)
+	if err != nil {
+		return nil, err
+	}
+
+	next := &syncState{
+		users:      maps.Clone(s.state.users),
+		groups:     maps.Clone(s.state.groups),
+		cursor:     cursor,
+		synced:     s.state.synced,
+		fullSyncAt: s.state.fullSyncAt,
+	}
+
+	changedUsers, changedGroups, err := s.changedDNs(directory)
+	if err != nil {
+		return nil, err
+	}
+
+	// Groups are affected if they changed, or if one of their members was added, removed or
+	// renamed, which changes the relationships of their memberships.
+	affected := changedGroups
+	for _, dn := range changedUsers {
+		entry, err := lookup(directory, dn, s.config.Users.Filter, []string{s.config.Users.IDAttribute})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read user `%s`: %w", dn, err)
+		}
+		if s.updateID(next.users, dn, entry, s.config.Users.IDAttribute) {
+			if err := s.addReferencingGroups(directory, dn, affected); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	entries := map[string]*ldap.Entry{}
+	previousIDs := map[string]string{}
+	for _, dn := range slices.Collect(maps.Values(changedGroups)) {
+		key := normalizeDN(dn)
+		previousIDs[key] = next.groups[key]
+
+		entry, err := lookup(directory, dn, s.config.Groups.Filter, []string{s.config.Groups.IDAttribute, s.config.Groups.MemberAttribute})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read group `%s`: %w", dn, err)
+		}
+		entries[key] = entry
+		if s.updateID(next.groups, dn, entry, s.config.Groups.IDAttribute) {
+			if err := s.addReferencingGroups(directory, dn, affected); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	current, desired := relationshipSet{}, relationshipSet{}
+	for key, dn := range affected {
+		entry, ok := entries[key]
+		if !ok {
+			previousIDs[key] = next.groups[key]
+			entry, err = lookup(directory, dn, s.config.Groups.Filter, []string{s.config.Groups.IDAttribute, s.config.Groups.MemberAttribute})
+			if err != nil {
+				return nil, fmt.Errorf("failed to read group `%s`: %w", dn, err)
+			}
+			s.updateID(next.groups, dn, entry, s.config.Groups.IDAttribute)
+		}
+
+		// The memberships of the group under both its previous and current IDs are replaced.
+		for _, id := range []string{previousIDs[key], next.groups[key]} {
+			if id == "" {
+				continue
+			}
+			memberships, err := s.readMemberships(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			maps.Copy(current, memberships)
+		}
+		if entry != nil {
+			if err := s.addMemberships(directory, next, desired, entry); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := s.apply(ctx, current, desired, s.state.synced, false); err != nil {
+		return nil, err
+	}
+	next.synced += len(desired) - len(current)
+	return next, nil
+}
+
+// updateID updates the ID of the entry with the DN, removing it if the entry no longer exists,
+// and returns whether it changed.
+func (s *Syncer) updateID(ids map[string]string, dn string, entry *ldap.Entry, idAttribute string) bool {
+	key := normalizeDN(dn)
+	previous := ids[key]
+	if entry == nil || entry.GetEqualFoldAttributeValue(idAttribute) == "" {
+		delete(ids, key)
+		return previous != ""
+	}
+
+	id := scim.ObjectID(entry.GetEqualFoldAttributeValue(idAttribute))
+	ids[key] = id
+	return previous != id
+}
+
+// addReferencingGroups adds the groups of which the entry with the DN is a member to affected.
+func (s *Syncer) addReferencingGroups(directory ldap.Client, dn string, affected map[string]string) error {
+	filter := fmt.Sprintf("(&%s(%s=%s))", s.config.Groups.Filter, s.config.Groups.MemberAttribute, ldap.EscapeFilter(dn))
+	groups, err := search(directory, s.config.Groups.BaseDN, filter, []string{"1.1"})
+	if err != nil {
+		return fmt.Errorf("failed to search groups of `%s`: %w", dn, err)
+	}
+	for _, group := range groups {
+		affected[normalizeDN(group.DN)] = group.DN
+	}
+	return nil
+}
+
+// changedDNs returns the DNs of the users and groups changed since the cursor of the previous
+// sync, by normalized DN.
+func (s *Syncer) changedDNs(directory ldap.Client) (map[string]string, map[string]string, error) {
+	users, groups := map[string]string{}, map[string]string{}
+	classify := func(dn string) {
+		key := normalizeDN(dn)
+		if isUnder(key, normalizeDN(s.config.Users.BaseDN)) {
+			users[key] = dn
+		}
+		if isUnder(key, normalizeDN(s.config.Groups.BaseDN)) {
+			groups[key] = dn
+		}
+	}
+
+	switch s.config.Incremental {
+	case IncrementalUSN:
+		filter := fmt.Sprintf("(uSNChanged>=%d)", s.state.cursor+1)
+		for _, baseDN := range []string{s.config.Users.BaseDN, s.config.Groups.BaseDN} {
+			entries, err := search(directory, baseDN, filter, []string{"1.1"})
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to search changed entries: %w", err)
+			}
+			for _, entry := range entries {
+				classify(entry.DN)
+			}
+		}
+
+	case IncrementalChangelog:
+		filter := fmt.Sprintf("(changeNumber>=%d)", s.state.cursor+1)
+		changes, err := search(directory, s.config.ChangelogBaseDN, filter, []string{"targetDN", "changeType", "newRDN", "newSuperior"})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to search changelog: %w", err)
+		}
+		for _, change := range changes {
+			target := change.GetEqualFoldAttributeValue("targetDN")
+			classify(target)
+
+			// Renamed and moved entries are also changed under their new DN.
+			if strings.EqualFold(change.GetEqualFoldAttributeValue("changeType"), "modrdn") {
+				superior := change.GetEqualFoldAttributeValue("newSuperior")
+				if superior == "" {
+					if _, parent, ok := strings.Cut(target, ","); ok {
+						superior = parent
+					}
+				}
+				classify(change.GetEqualFoldAttributeValue("newRDN") + "," + superior)
+			}
+		}
+	}
+	return users, groups, nil
+}
+
+// readCursor returns the highest USN or change number of the directory, through which changes
+// are synced.
+func (s *Syncer) readCursor(directory ldap.Client) (int64, error) {
+	var attribute string
+	switch s.config.Incremental {
+	case IncrementalUSN:
+		attribute = "highestCommittedUSN"
+	case IncrementalChangelog:
+		attribute = "lastChangeNumber"
+	default:
+		return 0, nil
+	}
+
+	result, err := directory.Search(ldap.NewSearchRequest("", ldap.ScopeBaseObject, ldap.NeverDerefAliases, 0, 0, false, "(objectClass=*)", []string{attribute}, nil))
+	if err != nil {
+		return 0, fmt.Errorf("failed to read root DSE: %w", err)
+	}
+	if len(result.Entries) == 0 || result.Entries[0].GetEqualFoldAttributeValue(attribute) == "" {
+		return 0, fmt.Errorf("the root DSE of the directory has no %s, required by the %s incremental mode", attribute, s.config.Incremental)
+	}
+	return strconv.ParseInt(result.Entries[0].GetEqualFoldAttributeValue(attribute), 10, 64)
+}
+
+// addMemberships adds the relationships of the members of the group entry which are known users
+// or groups to desired.
+func (s *Syncer) addMemberships(directory ldap.Client, state *syncState, desired relationshipSet, entry *ldap.Entry) error {
+	groupID := state.groups[normalizeDN(entry.DN)]
+	if groupID == "" {
+		return nil
+	}
+
+	members, err := s.memberDNs(directory, entry)
+	if err != nil {
+		return fmt.Errorf("failed to read members of group `%s`: %w", entry.DN, err)
+	}
+
+	for _, dn := range members {
+		key := normalizeDN(dn)
+		subject := &v1.SubjectReference{}
+		if userID, ok := state.users[key]; ok {
+			subject.Object = &v1.ObjectReference{ObjectType: s.config.ObjectTypes.User, ObjectId: userID}
+		} else if memberGroupID, ok := state.groups[key]; ok {
+			subject.Object = &v1.ObjectReference{ObjectType: s.config.ObjectTypes.Group, ObjectId: memberGroupID}
+			subject.OptionalRelation = s.config.ObjectTypes.MemberRelation
+		} else {
+			continue
+		}
+
+		desired.add(&v1.Relationship{
+			Resource: &v1.ObjectReference{ObjectType: s.config.ObjectTypes.Group, ObjectId: groupID},
+			Relation: s.config.ObjectTypes.MemberRelation,
+			Subject:  subject,
+		})
+	}
+	return nil
+}
+
+// memberDNs returns the DNs of the members of the group entry, reading the remaining ranges of
+// its member attribute if Active Directory returned it in ranges, as for large groups.
+func (s *Syncer) memberDNs(directory ldap.Client, entry *ldap.Entry) ([]string, error) {
+	attribute := s.config.Groups.MemberAttribute
+	members := slices.Clone(entry.GetEqualFoldAttributeValues(attribute))
+	for {
+		next := ""
+		for _, attr := range entry.Attributes {
+			if len(attr.Name) <= len(attribute)+7 || !strings.EqualFold(attr.Name[:len(attribute)+7], attribute+";range=") {
+				continue
+			}
+			members = append(members, attr.Values...)
+			if _, end, ok := strings.Cut(attr.Name[len(attribute)+7:], "-"); ok && end != "*" {
+				if last, err := strconv.Atoi(end); err == nil {
+					next = strconv.Itoa(last + 1)
+				}
+			}
+		}
+		if next == "" {
+			return members, nil
+		}
+
+		result, err := directory.Search(ldap.NewSearchRequest(entry.DN, ldap.ScopeBaseObject, ldap.NeverDerefAliases, 0, 0, false, "(objectClass=*)", []string{attribute + ";range=" + next + "-*"}, nil))
+		if err != nil {
+			return nil, err
+		}
+		if len(result.Entries) == 0 {
+			return members, nil
+		}
+		entry = result.Entries[0]
+	}
+}
+
+// readMemberships returns the relationships of the member relation of the group with the ID, or
+// of all groups if empty, whose subjects are users or the members of groups.
+func (s *Syncer) readMemberships(ctx context.Context, groupID string) (relationshipSet, error) {
+	stream, err := s.client.ReadRelationships(ctx, &v1.ReadRelationshipsRequest{
+		Consistency: &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}},
+		RelationshipFilter: &v1.RelationshipFilter{
+			ResourceType:       s.config.ObjectTypes.Group,
+			OptionalResourceId: groupID,
+			OptionalRelation:   s.config.ObjectTypes.MemberRelation,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	memberships := relationshipSet{}
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return memberships, nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		subject := resp.Relationship.Subject
+		switch {
+		case subject.Object.ObjectType == s.config.ObjectTypes.User && subject.OptionalRelation == "",
+			subject.Object.ObjectType == s.config.ObjectTypes.Group && subject.OptionalRelation == s.config.ObjectTypes.MemberRelation:
+			memberships.add(&v1.Relationship{Resource: resp.Relationship.Resource, Relation: resp.Relationship.Relation, Subject: subject})
+		}
+	}
+}
+
+// apply writes the relationships of desired which are not current, and deletes those of current
+// which are not desired, unless the deletions exceed the thresholds relative to the synced
+// relationships. In dry runs, the diff is written to the output instead.
+func (s *Syncer) apply(ctx context.Context, current, desired relationshipSet, synced int, full bool) error {
+	touches, deletes := desired.difference(current), current.difference(desired)
+	log.Ctx(ctx).Info().Bool("full", full).Bool("dry-run", s.options.DryRun).Int("touches", len(touches)).Int("deletes", len(deletes)).Msg("synced LDAP group memberships")
+
+	thresholdErr := s.checkDeletions(len(deletes), synced)
+	if s.options.DryRun {
+		for _, key := range touches {
+			if _, err := fmt.Fprintf(s.options.DiffOutput, "+ %s\n", key); err != nil {
+				return err
+			}
+		}
+		for _, key := range deletes {
+			if _, err := fmt.Fprintf(s.options.DiffOutput, "- %s\n", key); err != nil {
+				return err
+			}
+		}
+		if thresholdErr != nil {
+			log.Ctx(ctx).Warn().Err(thresholdErr).Msg("the sync would be aborted by deletion protection")
+		}
+		return nil
+	}
+	if thresholdErr != nil {
+		return thresholdErr
+	}
+
+	updates := make([]*v1.RelationshipUpdate, 0, len(touches)+len(deletes))
+	for _, key := range touches {
+		updates = append(updates, &v1.RelationshipUpdate{Operation: v1.RelationshipUpdate_OPERATION_TOUCH, Relationship: desired[key]})
+	}
+	for _, key := range deletes {
+		updates = append(updates, &v1.RelationshipUpdate{Operation: v1.RelationshipUpdate_OPERATION_DELETE, Relationship: current[key]})
+	}
+	for chunk := range slices.Chunk(updates, maxUpdatesPerWrite) {
+		if _, err := s.client.WriteRelationships(ctx, &v1.WriteRelationshipsRequest{Updates: chunk}); err != nil {
+			return fmt.Errorf("failed to write relationships: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *Syncer) checkDeletions(deletes, synced int) error {
+	if s.options.MaxDeletions > 0 && deletes > s.options.MaxDeletions {
+		return fmt.Errorf("%w: %d relationships would be deleted, more than the maximum of %d", ErrDeletionThreshold, deletes, s.options.MaxDeletions)
+	}
+	if s.options.MaxDeletionPercent > 0 && synced > 0 {
+		if percent := float64(deletes) * 100 / float64(synced); percent > s.options.MaxDeletionPercent {
+			return fmt.Errorf("%w: %.1f%% of the %d synced relationships would be deleted, more than the maximum of %.1f%%", ErrDeletionThreshold, percent, synced, s.options.MaxDeletionPercent)
+		}
+	}
+	return nil
+}
+
+// relationshipSet is a set of relationships, keyed by their string form.
+type relationshipSet map[string]*v1.Relationship
+
+func (rs relationshipSet) add(rel *v1.Relationship) {
+	rs[tuple.V1StringRelationshipWithoutCaveatOrExpiration(rel)] = rel
+}
+
+// difference returns the sorted keys of the relationships which are not in other.
+func (rs relationshipSet) difference(other relationshipSet) []string {
+	var keys []string
+	for key := range rs {
+		if _, ok := other[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func search(directory ldap.Client, baseDN, filter string, attributes []string) ([]*ldap.Entry, error) {
+	result, err := directory.SearchWithPaging(ldap.NewSearchRequest(baseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false, filter, attributes, nil), searchPageSize)
+	if err != nil {
+		return nil, err
+	}
+	return result.Entries, nil
+}
+
+// lookup returns the entry with the DN if it exists and matches the filter, and otherwise nil.
+func lookup(directory ldap.Client, dn, filter string, attributes []string) (*ldap.Entry, error) {
+	result, err := directory.Search(ldap.NewSearchRequest(dn, ldap.ScopeBaseObject, ldap.NeverDerefAliases, 0, 0, false, filter, attributes, nil))
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if len(result.Entries) == 0 {
+		return nil, nil
+	}
+	return result.Entries[0], nil
+}
+
+// normalizeDN returns the DN in lowercase without spaces between its components, as DNs are
+// compared case-insensitively.
+func normalizeDN(dn string) string {
+	parsed, err := ldap.ParseDN(dn)
+	if err != nil {
+		return strings.ToLower(dn)
+	}
+
+	rdns := make([]string, 0, len(parsed.RDNs))
+	for _, rdn := range parsed.RDNs {
+		attributes := make([]string, 0, len(rdn.Attributes))
+		for _, attribute := range rdn.Attributes {
+			attributes = append(attributes, strings.ToLower(attribute.Type)+"="+strings.ToLower(attribute.Value))
+		}
+		rdns = append(rdns, strings.Join(attributes, "+"))
+	}
+	return strings.Join(rdns, ",")
+}
+
+func isUnder(dn, baseDN string) bool {
+	return dn == baseDN || strings.HasSuffix(dn, ","+baseDN)
+}
@@ -0,0 +1,317 @@
+package ldapsync
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/internal/testserver"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+const testConfig = `
+url: ldap://localhost
+users:
+  baseDN: ou=people,dc=example,dc=com
+  filter: (objectClass=user)
+  idAttribute: sAMAccountName
+groups:
+  baseDN: ou=groups,dc=example,dc=com
+  filter: (objectClass=group)
+  idAttribute: cn
+  memberAttribute: member
+objectTypes:
+  user: user
+  group: group
+  memberRelation: member
+incremental: `
+
+// fakeDirectory is a directory of entries, matching the filters of the syncer: conjunctions,
+// presence, equality and greater-or-equal comparisons.
+type fakeDirectory struct {
+	ldap.Client
+
+	rootDSE map[string][]string
+	entries map[string]map[string][]string
+}
+
+func (fd *fakeDirectory) set(dn string, attributes map[string][]string) {
+	fd.entries[dn] = attributes
+}
+
+func (fd *fakeDirectory) SearchWithPaging(req *ldap.SearchRequest, _ uint32) (*ldap.SearchResult, error) {
+	return fd.Search(req)
+}
+
+func (fd *fakeDirectory) Search(req *ldap.SearchRequest) (*ldap.SearchResult, error) {
+	if req.BaseDN == "" {
+		return &ldap.SearchResult{Entries: []*ldap.Entry{ldap.NewEntry("", fd.rootDSE)}}, nil
+	}
+
+	result := &ldap.SearchResult{}
+	found := false
+	for dn, attributes := range fd.entries {
+		key, base := normalizeDN(dn), normalizeDN(req.BaseDN)
+		if (req.Scope == ldap.ScopeBaseObject && key != base) || !isUnder(key, base) {
+			continue
+		}
+		found = true
+		if matchFilter(req.Filter, attributes) {
+			result.Entries = append(result.Entries, ldap.NewEntry(dn, attributes))
+		}
+	}
+	if req.Scope == ldap.ScopeBaseObject && !found {
+		return nil, ldap.NewError(ldap.LDAPResultNoSuchObject, errors.New("no such object"))
+	}
+	return result, nil
+}
+
+func matchFilter(filter string, attributes map[string][]string) bool {
+	body := filter[1 : len(filter)-1]
+	if conjunction, ok := strings.CutPrefix(body, "&"); ok {
+		for depth, start, i := 0, 0, 0; i < len(conjunction); i++ {
+			switch conjunction[i] {
+			case '(':
+				if depth == 0 {
+					start = i
+				}
+				depth++
+			case ')':
+				depth--
+				if depth == 0 && !matchFilter(conjunction[start:i+1], attributes) {
+					return false
+				}
+			}
+		}
+		return true
+	}
+
+	if attribute, value, ok := strings.Cut(body, ">="); ok {
+		minimum, _ := strconv.Atoi(value)
+		for _, v := range attributes[attribute] {
+			if n, _ := strconv.Atoi(v); n >= minimum {
+				return true
+			}
+		}
+		return false
+	}
+
+	attribute, value, _ := strings.Cut(body, "=")
+	if value == "*" {
+		return attribute == "objectClass" || len(attributes[attribute]) > 0
+	}
+	for _, v := range attributes[attribute] {
+		if strings.EqualFold(v, value) || normalizeDN(v) == normalizeDN(value) {
+			return true
+		}
+	}
+	return false
+}
+
+func user(id string, usn int) map[string][]string {
+	return map[string][]string{"objectClass": {"user"}, "sAMAccountName": {id}, "uSNChanged": {strconv.Itoa(usn)}}
+}
+
+func group(cn string, usn int, members ...string) map[string][]string {
+	return map[string][]string{"objectClass": {"group"}, "cn": {cn}, "member": members, "uSNChanged": {strconv.Itoa(usn)}}
+}
+
+func newTestDirectory() *fakeDirectory {
+	fd := &fakeDirectory{rootDSE: map[string][]string{"highestCommittedUSN": {"10"}}, entries: map[string]map[string][]string{}}
+	fd.set("CN=Alice,OU=People,DC=example,DC=com", user("alice", 1))
+	fd.set("CN=Bob,OU=People,DC=example,DC=com", user("bob", 2))
+	fd.set("CN=Carol,OU=People,DC=example,DC=com", user("carol", 3))
+	fd.set("CN=Printer,OU=People,DC=example,DC=com", map[string][]string{"objectClass": {"device"}, "sAMAccountName": {"printer"}})
+	fd.set("CN=Engineering,OU=Groups,DC=example,DC=com", group("Engineering", 4,
+		"cn=alice, ou=people, dc=example, dc=com",
+		"CN=Bob,OU=People,DC=example,DC=com",
+		"CN=Platform Team,OU=Groups,DC=example,DC=com",
+		"CN=Printer,OU=People,DC=example,DC=com",
+	))
+	fd.set("CN=Platform Team,OU=Groups,DC=example,DC=com", group("Platform Team", 5, "CN=Carol,OU=People,DC=example,DC=com"))
+	return fd
+}
+
+func newTestClient(t *testing.T, relationships ...string) v1.PermissionsServiceClient {
+	conn, cleanup, _, _ := testserver.NewTestServer(require.New(t), 0, time.Hour, true, testfixtures.EmptyDatastore)
+	t.Cleanup(cleanup)
+
+	_, err := v1.NewSchemaServiceClient(conn).WriteSchema(context.Background(), &v1.WriteSchemaRequest{
+		Schema: `definition user {}
+
+		definition robot {}
+
+		definition group {
+			relation member: user | robot | group#member
+		}`,
+	})
+	require.NoError(t, err)
+
+	client := v1.NewPermissionsServiceClient(conn)
+	if len(relationships) > 0 {
+		var updates []*v1.RelationshipUpdate
+		for _, rel := range relationships {
+			updates = append(updates, &v1.RelationshipUpdate{Operation: v1.RelationshipUpdate_OPERATION_TOUCH, Relationship: tuple.MustParseV1Rel(rel)})
+		}
+		_, err := client.WriteRelationships(context.Background(), &v1.WriteRelationshipsRequest{Updates: updates})
+		require.NoError(t, err)
+	}
+	return client
+}
+
+func readRelationships(t *testing.T, client v1.PermissionsServiceClient) []string {
+	stream, err := client.ReadRelationships(context.Background(), &v1.ReadRelationshipsRequest{
+		Consistency:        &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}},
+		RelationshipFilter: &v1.RelationshipFilter{ResourceType: "group"},
+	})
+	require.NoError(t, err)
+
+	var rels []string
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return rels
+		}
+		require.NoError(t, err)
+		rels = append(rels, tuple.V1StringRelationshipWithoutCaveatOrExpiration(resp.Relationship))
+	}
+}
+
+func TestParseConfigErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name          string
+		config        string
+		expectedError string
+	}{
+		{"missing url", "users: {baseDN: dc=example}", "URL of the directory is required"},
+		{"password without bind DN", "url: ldap://localhost\nbindPasswordEnv: PASSWORD", "bind DN is required"},
+		{"missing member attribute", strings.Replace(testConfig, "memberAttribute: member", "", 1), "member attribute of groups are required"},
+		{"unknown mode", testConfig + "dirsync", "unknown incremental mode `dirsync`"},
+		{"unknown field", testConfig + "none\npassword: secret", "field password not found"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseConfig([]byte(tc.config))
+			require.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestFullAndUSNIncrementalSync(t *testing.T) {
+	config, err := ParseConfig([]byte(testConfig + "usn"))
+	require.NoError(t, err)
+
+	// Memberships not found in the directory are deleted, while those of other subject types are
+	// kept.
+	client := newTestClient(t, "group:Engineering#member@user:mallory", "group:Engineering#member@robot:builder")
+	directory := newTestDirectory()
+	syncer := NewSyncer(client, config, Options{})
+
+	require.NoError(t, syncer.Sync(context.Background(), directory))
+	require.ElementsMatch(t, []string{
+		"group:Engineering#member@user:alice",
+		"group:Engineering#member@user:bob",
+		"group:Engineering#member@group:Platform=20Team#member",
+		"group:Engineering#member@robot:builder",
+		"group:Platform=20Team#member@user:carol",
+	}, readRelationships(t, client))
+
+	// Bob is removed from Engineering, Carol is renamed and Dave joins a new group.
+	directory.rootDSE["highestCommittedUSN"] = []string{"14"}
+	directory.set("CN=Engineering,OU=Groups,DC=example,DC=com", group("Engineering", 11,
+		"CN=Alice,OU=People,DC=example,DC=com",
+		"CN=Platform Team,OU=Groups,DC=example,DC=com",
+	))
+	directory.set("CN=Carol,OU=People,DC=example,DC=com", user("caroline", 12))
+	directory.set("CN=Dave,OU=People,DC=example,DC=com", user("dave", 13))
+	directory.set("CN=Security,OU=Groups,DC=example,DC=com", group("Security", 14, "CN=Dave,OU=People,DC=example,DC=com"))
+
+	require.NoError(t, syncer.Sync(context.Background(), directory))
+	require.ElementsMatch(t, []string{
+		"group:Engineering#member@user:alice",
+		"group:Engineering#member@group:Platform=20Team#member",
+		"group:Engineering#member@robot:builder",
+		"group:Platform=20Team#member@user:caroline",
+		"group:Security#member@user:dave",
+	}, readRelationships(t, client))
+	require.Equal(t, int64(14), syncer.state.cursor)
+	require.Equal(t, 4, syncer.state.synced)
+}
+
+func TestChangelogIncrementalSync(t *testing.T) {
+	config, err := ParseConfig([]byte(testConfig + "changelog"))
+	require.NoError(t, err)
+
+	client := newTestClient(t)
+	directory := newTestDirectory()
+	directory.rootDSE = map[string][]string{"lastChangeNumber": {"100"}}
+	syncer := NewSyncer(client, config, Options{})
+	require.NoError(t, syncer.Sync(context.Background(), directory))
+
+	// The Platform Team group is renamed, which changes its membership in Engineering.
+	delete(directory.entries, "CN=Platform Team,OU=Groups,DC=example,DC=com")
+	directory.set("CN=Platform,OU=Groups,DC=example,DC=com", group("Platform", 0, "CN=Carol,OU=People,DC=example,DC=com"))
+	directory.set("CN=Engineering,OU=Groups,DC=example,DC=com", group("Engineering", 0,
+		"CN=Alice,OU=People,DC=example,DC=com",
+		"CN=Bob,OU=People,DC=example,DC=com",
+		"CN=Platform,OU=Groups,DC=example,DC=com",
+	))
+	directory.set("changeNumber=101,cn=changelog", map[string][]string{
+		"changeNumber": {"101"},
+		"targetDN":     {"CN=Platform Team,OU=Groups,DC=example,DC=com"},
+		"changeType":   {"modrdn"},
+		"newRDN":       {"CN=Platform"},
+	})
+	directory.rootDSE["lastChangeNumber"] = []string{"101"}
+
+	require.NoError(t, syncer.Sync(context.Background(), directory))
+	require.ElementsMatch(t, []string{
+		"group:Engineering#member@user:alice",
+		"group:Engineering#member@user:bob",
+		"group:Engineering#member@group:Platform#member",
+		"group:Platform#member@user:carol",
+	}, readRelationships(t, client))
+}
+
+func TestDeletionProtectionAndDryRun(t *testing.T) {
+	config, err := ParseConfig([]byte(testConfig + "none"))
+	require.NoError(t, err)
+
+	client := newTestClient(t)
+	directory := newTestDirectory()
+	require.NoError(t, NewSyncer(client, config, Options{}).Sync(context.Background(), directory))
+	synced := readRelationships(t, client)
+
+	directory.set("CN=Engineering,OU=Groups,DC=example,DC=com", group("Engineering", 0, "CN=Dave,OU=People,DC=example,DC=com"))
+	directory.set("CN=Dave,OU=People,DC=example,DC=com", user("dave", 0))
+
+	err = NewSyncer(client, config, Options{MaxDeletions: 2}).Sync(context.Background(), directory)
+	require.ErrorIs(t, err, ErrDeletionThreshold)
+	err = NewSyncer(client, config, Options{MaxDeletionPercent: 50}).Sync(context.Background(), directory)
+	require.ErrorIs(t, err, ErrDeletionThreshold)
+	require.ElementsMatch(t, synced, readRelationships(t, client))
+
+	var diff bytes.Buffer
+	require.NoError(t, NewSyncer(client, config, Options{DryRun: true, DiffOutput: &diff, MaxDeletions: 2}).Sync(context.Background(), directory))
+	require.Equal(t, `+ group:Engineering#member@user:dave
+- group:Engineering#member@group:Platform=20Team#member
+- group:Engineering#member@user:alice
+- group:Engineering#member@user:bob
+`, diff.String())
+	require.ElementsMatch(t, synced, readRelationships(t, client))
+
+	require.NoError(t, NewSyncer(client, config, Options{MaxDeletions: 3}).Sync(context.Background(), directory))
+	require.ElementsMatch(t, []string{
+		"group:Engineering#member@user:dave",
+		"group:Platform=20Team#member@user:carol",
+	}, readRelationships(t, client))
+}
@@ -18,6 +18,20 @@ func SetGlobalLogger(logger zerolog.Logger) {
 	zerolog.DefaultContextLogger = &Logger
 }
 
+// EnableLevelChanges makes the level of the global logger changeable at runtime with SetLevel, by
+// moving the current level of the logger to zerolog's global level. It must be called before the
+// global logger is used concurrently.
+func EnableLevelChanges() {
+	level := Logger.GetLevel()
+	SetGlobalLogger(Logger.Level(zerolog.TraceLevel))
+	zerolog.SetGlobalLevel(level)
+}
+
+// SetLevel changes the minimum level of the events logged, once enabled with EnableLevelChanges.
+func SetLevel(level zerolog.Level) {
+	zerolog.SetGlobalLevel(level)
+}
+
 func With() zerolog.Context { return Logger.With() }
 
 func Err(err error) *zerolog.Event { return Logger.Err(err) }
@@ -0,0 +1,236 @@
+package lsp
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/jzelinskie/persistent"
+	"github.com/sourcegraph/go-lsp"
+	"github.com/sourcegraph/jsonrpc2"
+
+	"github.com/authzed/spicedb/pkg/development"
+	"github.com/authzed/spicedb/pkg/namespace"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	iv1 "github.com/authzed/spicedb/pkg/proto/impl/v1"
+	"github.com/authzed/spicedb/pkg/schemadsl/compiler"
+)
+
+// completionTriggerCharacters are the characters after which the client requests completions
+// without the user asking for them.
+var completionTriggerCharacters = []string{".", "#", ":", ">", "("}
+
+var (
+	definitionHeaderPattern   = regexp.MustCompile(`\b(definition|caveat)\s+([\w/]+)`)
+	relationLinePattern       = regexp.MustCompile(`^\s*relation\s+\w+\s*:`)
+	permissionLinePattern     = regexp.MustCompile(`^\s*permission\s+\w+\s*=`)
+	subjectRelationPattern    = regexp.MustCompile(`([\w/]+)#\w*$`)
+	caveatReferencePattern    = regexp.MustCompile(`\bwith\s+[\w/]*$`)
+	arrowPattern              = regexp.MustCompile(`(\w+)(->|\.any\(|\.all\()\w*$`)
+	partialKeywordLinePattern = regexp.MustCompile(`^\s*\w*$`)
+)
+
+func (s *Server) textDocCompletion(_ context.Context, r *jsonrpc2.Request) (*lsp.CompletionList, error) {
+	params, err := unmarshalParams[lsp.CompletionParams](r)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []lsp.CompletionItem
+	err = s.withFiles(func(files *persistent.Map[lsp.DocumentURI, trackedFile]) error {
+		file, ok := files.Get(params.TextDocument.URI)
+		if !ok {
+			return &jsonrpc2.Error{Code: jsonrpc2.CodeInternalError, Message: "file not found"}
+		}
+
+		items = completionsAt(file.contents, params.Position)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &lsp.CompletionList{
+		IsIncomplete: false,
+		Items:        items,
+	}, nil
+}
+
+// completionsAt returns the completions for the schema at the position, based on the text of its
+// line before the position and on the definitions of the schema.
+func completionsAt(contents string, position lsp.Position) []lsp.CompletionItem {
+	items := make([]lsp.CompletionItem, 0) // Must not be nil for the consumer on the client side
+
+	lines := strings.Split(contents, "\n")
+	if position.Line < 0 || position.Line >= len(lines) {
+		return items
+	}
+
+	line := lines[position.Line]
+	linePrefix := line[:min(max(position.Character, 0), len(line))]
+	textBefore := strings.Join(lines[:position.Line], "\n") + "\n" + linePrefix
+
+	// Outside of a definition, only new definitions can be started.
+	enclosingDefinition, insideDefinition := enclosingDefinitionName(textBefore)
+	if !insideDefinition {
+		if partialKeywordLinePattern.MatchString(linePrefix) {
+			return keywordCompletions("definition", "caveat")
+		}
+		return items
+	}
+
+	compiled := compileForCompletion(lines, position.Line)
+	switch {
+	case relationLinePattern.MatchString(linePrefix):
+		if match := subjectRelationPattern.FindStringSubmatch(linePrefix); match != nil {
+			return relationCompletions(findObjectDefinition(compiled, match[1]))
+		}
+		if caveatReferencePattern.MatchString(linePrefix) {
+			return caveatCompletions(compiled)
+		}
+		return definitionCompletions(compiled)
+
+	case permissionLinePattern.MatchString(linePrefix):
+		definition := findObjectDefinition(compiled, enclosingDefinition)
+		if match := arrowPattern.FindStringSubmatch(linePrefix); match != nil {
+			return arrowCompletions(compiled, definition, match[1])
+		}
+		return append(relationCompletions(definition), keywordCompletions("nil")...)
+
+	case partialKeywordLinePattern.MatchString(linePrefix):
+		return keywordCompletions("relation", "permission")
+
+	default:
+		return items
+	}
+}
+
+// enclosingDefinitionName returns the name of the definition or caveat whose body contains the end
+// of the text, if any.
+func enclosingDefinitionName(text string) (string, bool) {
+	depth := 0
+	definitionName := ""
+	for _, line := range strings.Split(text, "\n") {
+		if commentStart := strings.Index(line, "//"); commentStart >= 0 {
+			line = line[:commentStart]
+		}
+
+		if depth == 0 {
+			if match := definitionHeaderPattern.FindStringSubmatch(line); match != nil {
+				definitionName = match[2]
+			}
+		}
+
+		depth += strings.Count(line, "{") - strings.Count(line, "}")
+		depth = max(depth, 0)
+	}
+
+	return definitionName, depth > 0
+}
+
+// compileForCompletion compiles the schema being edited. As the line being edited is usually
+// incomplete, it is removed if the schema does not compile with it.
+func compileForCompletion(lines []string, editedLine int) *compiler.CompiledSchema {
+	compiled, devErr, err := development.CompileSchema(strings.Join(lines, "\n"))
+	if err == nil && devErr == nil {
+		return compiled
+	}
+
+	withoutEditedLine := slices.Clone(lines)
+	withoutEditedLine[editedLine] = ""
+	compiled, devErr, err = development.CompileSchema(strings.Join(withoutEditedLine, "\n"))
+	if err == nil && devErr == nil {
+		return compiled
+	}
+
+	return nil
+}
+
+func findObjectDefinition(compiled *compiler.CompiledSchema, name string) *core.NamespaceDefinition {
+	if compiled == nil {
+		return nil
+	}
+
+	for _, definition := range compiled.ObjectDefinitions {
+		if definition.Name == name {
+			return definition
+		}
+	}
+	return nil
+}
+
+func keywordCompletions(keywords ...string) []lsp.CompletionItem {
+	items := make([]lsp.CompletionItem, 0, len(keywords))
+	for _, keyword := range keywords {
+		items = append(items, lsp.CompletionItem{Label: keyword, Kind: lsp.CIKKeyword})
+	}
+	return items
+}
+
+func definitionCompletions(compiled *compiler.CompiledSchema) []lsp.CompletionItem {
+	items := make([]lsp.CompletionItem, 0)
+	if compiled == nil {
+		return items
+	}
+
+	for _, definition := range compiled.ObjectDefinitions {
+		items = append(items, lsp.CompletionItem{Label: definition.Name, Kind: lsp.CIKClass, Detail: "definition"})
+	}
+	return items
+}
+
+func caveatCompletions(compiled *compiler.CompiledSchema) []lsp.CompletionItem {
+	items := make([]lsp.CompletionItem, 0)
+	if compiled == nil {
+		return items
+	}
+
+	for _, caveat := range compiled.CaveatDefinitions {
+		items = append(items, lsp.CompletionItem{Label: caveat.Name, Kind: lsp.CIKFunction, Detail: "caveat"})
+	}
+	return items
+}
+
+func relationCompletions(definition *core.NamespaceDefinition) []lsp.CompletionItem {
+	items := make([]lsp.CompletionItem, 0)
+	if definition == nil {
+		return items
+	}
+
+	for _, relation := range definition.Relation {
+		if namespace.GetRelationKind(relation) == iv1.RelationMetadata_PERMISSION {
+			items = append(items, lsp.CompletionItem{Label: relation.Name, Kind: lsp.CIKProperty, Detail: "permission"})
+		} else {
+			items = append(items, lsp.CompletionItem{Label: relation.Name, Kind: lsp.CIKField, Detail: "relation"})
+		}
+	}
+	return items
+}
+
+// arrowCompletions returns the relations and permissions which can be walked to from the
+// relation of the definition, i.e. those of its allowed subject types.
+func arrowCompletions(compiled *compiler.CompiledSchema, definition *core.NamespaceDefinition, relationName string) []lsp.CompletionItem {
+	items := make([]lsp.CompletionItem, 0)
+	if definition == nil {
+		return items
+	}
+
+	seen := map[string]struct{}{}
+	for _, relation := range definition.Relation {
+		if relation.Name != relationName || relation.TypeInformation == nil {
+			continue
+		}
+
+		for _, allowed := range relation.TypeInformation.AllowedDirectRelations {
+			for _, item := range relationCompletions(findObjectDefinition(compiled, allowed.Namespace)) {
+				if _, ok := seen[item.Label]; ok {
+					continue
+				}
+				seen[item.Label] = struct{}{}
+				items = append(items, item)
+			}
+		}
+	}
+	return items
+}
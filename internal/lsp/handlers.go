@@ -209,14 +209,9 @@ func (s *Server) getCompiledContents(path lsp.DocumentURI, files *persistent.Map
 	return justCompiled, nil
 }
 
-func (s *Server) textDocHover(_ context.Context, r *jsonrpc2.Request) (*Hover, error) {
-	params, err := unmarshalParams[lsp.TextDocumentPositionParams](r)
-	if err != nil {
-		return nil, err
-	}
-
-	var hoverContents *Hover
-	err = s.withFiles(func(files *persistent.Map[lsp.DocumentURI, trackedFile]) error {
+func (s *Server) resolveReference(params lsp.TextDocumentPositionParams) (*development.SchemaReference, error) {
+	var resolved *development.SchemaReference
+	err := s.withFiles(func(files *persistent.Map[lsp.DocumentURI, trackedFile]) error {
 		compiled, err := s.getCompiledContents(params.TextDocument.URI, files)
 		if err != nil {
 			return err
@@ -232,54 +227,92 @@ func (s *Server) textDocHover(_ context.Context, r *jsonrpc2.Request) (*Hover, e
 			ColumnPosition: params.Position.Character,
 		}
 
-		resolved, err := resolver.ReferenceAtPosition(input.Source("schema"), position)
-		if err != nil {
-			return err
-		}
+		resolved, err = resolver.ReferenceAtPosition(input.Source("schema"), position)
+		return err
+	})
+	return resolved, err
+}
 
-		if resolved == nil {
-			return nil
-		}
+// targetNameRange returns the range of the name of the node referenced, if it is in the schema.
+func targetNameRange(resolved *development.SchemaReference) *lsp.Range {
+	if resolved.TargetPosition == nil {
+		return nil
+	}
 
-		var lspRange *lsp.Range
-		if resolved.TargetPosition != nil {
-			lspRange = &lsp.Range{
-				Start: lsp.Position{
-					Line:      resolved.TargetPosition.LineNumber,
-					Character: resolved.TargetPosition.ColumnPosition + resolved.TargetNamePositionOffset,
-				},
-				End: lsp.Position{
-					Line:      resolved.TargetPosition.LineNumber,
-					Character: resolved.TargetPosition.ColumnPosition + resolved.TargetNamePositionOffset + len(resolved.Text),
-				},
-			}
-		}
+	return &lsp.Range{
+		Start: lsp.Position{
+			Line:      resolved.TargetPosition.LineNumber,
+			Character: resolved.TargetPosition.ColumnPosition + resolved.TargetNamePositionOffset,
+		},
+		End: lsp.Position{
+			Line:      resolved.TargetPosition.LineNumber,
+			Character: resolved.TargetPosition.ColumnPosition + resolved.TargetNamePositionOffset + len(resolved.Text),
+		},
+	}
+}
 
-		if resolved.TargetSourceCode != "" {
-			hoverContents = &Hover{
-				Contents: MarkupContent{
-					Language: "spicedb",
-					Value:    resolved.TargetSourceCode,
-				},
-				Range: lspRange,
-			}
-		} else {
-			hoverContents = &Hover{
-				Contents: MarkupContent{
-					Kind:  "markdown",
-					Value: resolved.ReferenceMarkdown,
-				},
-				Range: lspRange,
-			}
-		}
+func (s *Server) textDocDefinition(_ context.Context, r *jsonrpc2.Request) ([]lsp.Location, error) {
+	params, err := unmarshalParams[lsp.TextDocumentPositionParams](r)
+	if err != nil {
+		return nil, err
+	}
 
-		return nil
-	})
+	resolved, err := s.resolveReference(params)
 	if err != nil {
 		return nil, err
 	}
 
-	return hoverContents, nil
+	if resolved == nil {
+		return nil, nil
+	}
+
+	// References to built-ins, such as caveat parameter types, have no definition in the schema.
+	targetRange := targetNameRange(resolved)
+	if targetRange == nil {
+		return nil, nil
+	}
+
+	return []lsp.Location{
+		{
+			URI:   params.TextDocument.URI,
+			Range: *targetRange,
+		},
+	}, nil
+}
+
+func (s *Server) textDocHover(_ context.Context, r *jsonrpc2.Request) (*Hover, error) {
+	params, err := unmarshalParams[lsp.TextDocumentPositionParams](r)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved, err := s.resolveReference(params)
+	if err != nil {
+		return nil, err
+	}
+
+	if resolved == nil {
+		return nil, nil
+	}
+
+	lspRange := targetNameRange(resolved)
+	if resolved.TargetSourceCode != "" {
+		return &Hover{
+			Contents: MarkupContent{
+				Language: "spicedb",
+				Value:    resolved.TargetSourceCode,
+			},
+			Range: lspRange,
+		}, nil
+	}
+
+	return &Hover{
+		Contents: MarkupContent{
+			Kind:  "markdown",
+			Value: resolved.ReferenceMarkdown,
+		},
+		Range: lspRange,
+	}, nil
 }
 
 func (s *Server) textDocFormat(_ context.Context, r *jsonrpc2.Request) ([]lsp.TextEdit, error) {
@@ -349,7 +382,8 @@ func (s *Server) initialize(_ context.Context, r *jsonrpc2.Request) (any, error)
 	return InitializeResult{
 		Capabilities: ServerCapabilities{
 			TextDocumentSync:           &lsp.TextDocumentSyncOptionsOrKind{Kind: &syncKind},
-			CompletionProvider:         &lsp.CompletionOptions{TriggerCharacters: []string{"."}},
+			CompletionProvider:         &lsp.CompletionOptions{TriggerCharacters: completionTriggerCharacters},
+			DefinitionProvider:         true,
 			DocumentFormattingProvider: true,
 			DiagnosticProvider:         &DiagnosticOptions{Identifier: "spicedb", InterFileDependencies: false, WorkspaceDiagnostics: false},
 			HoverProvider:              true,
@@ -1,6 +1,8 @@
 package lsp
 
 import (
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/sourcegraph/go-lsp"
@@ -236,3 +238,128 @@ dfinition resource {
 	})
 	require.Error(t, err)
 }
+
+func TestDocumentDefinition(t *testing.T) {
+	tester := newLSPTester(t)
+	tester.initialize()
+
+	sendAndReceive[any](tester, "textDocument/didOpen", lsp.DidOpenTextDocumentParams{
+		TextDocument: lsp.TextDocumentItem{
+			URI:        lsp.DocumentURI("file:///test"),
+			LanguageID: "test",
+			Version:    1,
+			Text: `definition user {}
+
+definition resource {
+	relation viewer: user
+	permission view = viewer
+}
+`,
+		},
+	})
+
+	resp, _ := sendAndReceive[[]lsp.Location](tester, "textDocument/definition", lsp.TextDocumentPositionParams{
+		TextDocument: lsp.TextDocumentIdentifier{
+			URI: lsp.DocumentURI("file:///test"),
+		},
+		Position: lsp.Position{Line: 3, Character: 18},
+	})
+	require.Equal(t, []lsp.Location{
+		{
+			URI: lsp.DocumentURI("file:///test"),
+			Range: lsp.Range{
+				Start: lsp.Position{Line: 0, Character: 11},
+				End:   lsp.Position{Line: 0, Character: 15},
+			},
+		},
+	}, resp)
+
+	resp, _ = sendAndReceive[[]lsp.Location](tester, "textDocument/definition", lsp.TextDocumentPositionParams{
+		TextDocument: lsp.TextDocumentIdentifier{
+			URI: lsp.DocumentURI("file:///test"),
+		},
+		Position: lsp.Position{Line: 4, Character: 21},
+	})
+	require.Len(t, resp, 1)
+	require.Equal(t, 3, resp[0].Range.Start.Line)
+
+	// Positions without a reference have no definition.
+	resp, _ = sendAndReceive[[]lsp.Location](tester, "textDocument/definition", lsp.TextDocumentPositionParams{
+		TextDocument: lsp.TextDocumentIdentifier{
+			URI: lsp.DocumentURI("file:///test"),
+		},
+		Position: lsp.Position{Line: 1, Character: 0},
+	})
+	require.Empty(t, resp)
+}
+
+func TestDocumentCompletion(t *testing.T) {
+	const schema = `caveat only_on_tuesday(day string) {
+	day == 'tuesday'
+}
+
+definition user {}
+
+definition group {
+	relation member: user
+	permission membership = member
+}
+
+definition resource {
+	relation viewer: user | group#member
+	relation parent: group
+	permission view = viewer
+}
+`
+
+	tcs := []struct {
+		name           string
+		line           string
+		lineNumber     int
+		expectedLabels []string
+	}{
+		{"top level", "defi", 4, []string{"definition", "caveat"}},
+		{"inside definition", "	rel", 15, []string{"relation", "permission"}},
+		{"relation subject type", "	relation editor: ", 15, []string{"user", "group", "resource"}},
+		{"relation subject relation", "	relation editor: group#", 15, []string{"member", "membership"}},
+		{"relation caveat", "	relation editor: user with ", 15, []string{"only_on_tuesday"}},
+		{"permission", "	permission edit = ", 15, []string{"viewer", "parent", "view", "nil"}},
+		{"permission arrow", "	permission edit = parent->", 15, []string{"member", "membership"}},
+		{"permission any", "	permission edit = parent.any(", 15, []string{"member", "membership"}},
+		{"unknown arrow", "	permission edit = unknown->", 15, []string{}},
+		{"after expression", "	permission edit = viewer + ", 15, []string{"viewer", "parent", "view", "nil"}},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			tester := newLSPTester(t)
+			tester.initialize()
+
+			lines := strings.Split(schema, "\n")
+			lines = slices.Insert(lines, tc.lineNumber, tc.line)
+			sendAndReceive[any](tester, "textDocument/didOpen", lsp.DidOpenTextDocumentParams{
+				TextDocument: lsp.TextDocumentItem{
+					URI:        lsp.DocumentURI("file:///test"),
+					LanguageID: "test",
+					Version:    1,
+					Text:       strings.Join(lines, "\n"),
+				},
+			})
+
+			resp, _ := sendAndReceive[lsp.CompletionList](tester, "textDocument/completion", lsp.CompletionParams{
+				TextDocumentPositionParams: lsp.TextDocumentPositionParams{
+					TextDocument: lsp.TextDocumentIdentifier{
+						URI: lsp.DocumentURI("file:///test"),
+					},
+					Position: lsp.Position{Line: tc.lineNumber, Character: len(tc.line)},
+				},
+			})
+
+			labels := make([]string, 0, len(resp.Items))
+			for _, item := range resp.Items {
+				labels = append(labels, item.Label)
+			}
+			require.ElementsMatch(t, tc.expectedLabels, labels)
+		})
+	}
+}
@@ -12,6 +12,7 @@ type ServerCapabilities struct {
 	TextDocumentSync           *baselsp.TextDocumentSyncOptionsOrKind `json:"textDocumentSync,omitempty"`
 	CompletionProvider         *baselsp.CompletionOptions             `json:"completionProvider,omitempty"`
 	DocumentFormattingProvider bool                                   `json:"documentFormattingProvider,omitempty"`
+	DefinitionProvider         bool                                   `json:"definitionProvider,omitempty"`
 	DiagnosticProvider         *DiagnosticOptions                     `json:"diagnosticProvider,omitempty"`
 	HoverProvider              bool                                   `json:"hoverProvider,omitempty"`
 }
@@ -0,0 +1,385 @@
+// Package capture captures a sample of the CheckPermission, CheckBulkPermissions,
+// LookupResources and LookupSubjects requests served, along with the revision at which they were
+// served, a digest of their results and their latency, so that the traffic can be replayed against
+// another build or datastore and the results compared.
+//
+// Captures are anonymized: neither the headers of the requests, including their tokens, nor the
+// addresses of their callers are recorded. Unless hashing is disabled, the object and subject IDs
+// and the caveat context values of the requests, and the IDs from which the digests of their
+// results are computed, are replaced by their HMAC keyed with a random salt of the capture, which
+// is never recorded. Hashed requests keep their shape, and the requests referencing the same
+// objects within a capture can be told apart, but they cannot be replayed against the data on
+// which they were captured; captures to be replayed must be made with hashing disabled.
+package capture
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math/rand/v2"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	middleware "github.com/grpc-ecosystem/go-grpc-middleware/v2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/pkg/middleware/consistency"
+	"github.com/authzed/spicedb/pkg/requestmeta"
+)
+
+// Method describes a captured gRPC method.
+type Method struct {
+	// Streaming is true if the method streams its responses.
+	Streaming bool
+
+	// NewRequest and NewResponse return new messages of the request and response types of the
+	// method.
+	NewRequest  func() proto.Message
+	NewResponse func() proto.Message
+}
+
+var methods = map[string]Method{
+	v1.PermissionsService_CheckPermission_FullMethodName: {
+		NewRequest:  func() proto.Message { return &v1.CheckPermissionRequest{} },
+		NewResponse: func() proto.Message { return &v1.CheckPermissionResponse{} },
+	},
+	v1.PermissionsService_CheckBulkPermissions_FullMethodName: {
+		NewRequest:  func() proto.Message { return &v1.CheckBulkPermissionsRequest{} },
+		NewResponse: func() proto.Message { return &v1.CheckBulkPermissionsResponse{} },
+	},
+	v1.PermissionsService_LookupResources_FullMethodName: {
+		Streaming:   true,
+		NewRequest:  func() proto.Message { return &v1.LookupResourcesRequest{} },
+		NewResponse: func() proto.Message { return &v1.LookupResourcesResponse{} },
+	},
+	v1.PermissionsService_LookupSubjects_FullMethodName: {
+		Streaming:   true,
+		NewRequest:  func() proto.Message { return &v1.LookupSubjectsRequest{} },
+		NewResponse: func() proto.Message { return &v1.LookupSubjectsResponse{} },
+	},
+}
+
+// MethodFor returns the description of the captured gRPC method with the given full name.
+func MethodFor(fullMethod string) (Method, bool) {
+	method, ok := methods[fullMethod]
+	return method, ok
+}
+
+// Record is a captured request, written as a line of JSON to the capture file.
+type Record struct {
+	// CapturedAt is the time at which the request was received.
+	CapturedAt time.Time `json:"capturedAt"`
+
+	// Method is the full name of the gRPC method of the request.
+	Method string `json:"method"`
+
+	// Revision is the ZedToken of the revision at which the request was served.
+	Revision string `json:"revision,omitempty"`
+
+	// Request is the request, as protobuf JSON.
+	Request json.RawMessage `json:"request"`
+
+	// Result is the digest of the results of the request.
+	Result Result `json:"result"`
+
+	// LatencyMillis is the duration for which the request was served, in milliseconds.
+	LatencyMillis float64 `json:"latencyMs"`
+
+	// Hashed is true if the identifiers and caveat context values of the request, and the
+	// identifiers of the digest of its results, were hashed.
+	Hashed bool `json:"hashed,omitempty"`
+}
+
+// Result is the digest of the results of a request, from which the results of replays are
+// compared without recording them.
+type Result struct {
+	// Code is the gRPC status code with which the request completed.
+	Code string `json:"code"`
+
+	// Count is the number of results: permissionships for checks, resources or subjects for
+	// lookups.
+	Count uint64 `json:"count"`
+
+	// Digest is an order-independent hash of the results.
+	Digest string `json:"digest"`
+}
+
+// ResultBuilder computes the Result of a request from its responses.
+type ResultBuilder struct {
+	count  uint64
+	digest uint64
+
+	// hasher, if set, hashes the identifiers of the results before they are added to the digest.
+	hasher *hasher
+}
+
+// Add adds the results of a response of the request.
+func (b *ResultBuilder) Add(resp proto.Message) {
+	switch resp := resp.(type) {
+	case *v1.CheckPermissionResponse:
+		b.add(resp.Permissionship.String())
+
+	case *v1.CheckBulkPermissionsResponse:
+		for index, pair := range resp.Pairs {
+			if pair.GetError() != nil {
+				b.add(strconv.Itoa(index) + ":error:" + status.FromProto(pair.GetError()).Code().String())
+				continue
+			}
+			b.add(strconv.Itoa(index) + ":" + pair.GetItem().GetPermissionship().String())
+		}
+
+	case *v1.LookupResourcesResponse:
+		b.add(b.id(resp.ResourceObjectId) + ":" + resp.Permissionship.String())
+
+	case *v1.LookupSubjectsResponse:
+		b.add(b.id(resp.GetSubject().GetSubjectObjectId()) + ":" + resp.GetSubject().GetPermissionship().String())
+	}
+}
+
+// id returns the object ID of a result, hashed if the builder hashes identifiers, as the digest
+// of a single result would otherwise reveal it.
+func (b *ResultBuilder) id(objectID string) string {
+	if b.hasher == nil {
+		return objectID
+	}
+	return b.hasher.hashID(objectID)
+}
+
+// add adds a result to the digest by summing the hashes of the results, so that the digest does
+// not depend on the order in which they are streamed.
+func (b *ResultBuilder) add(item string) {
+	hasher := fnv.New64a()
+	_, _ = hasher.Write([]byte(item))
+	b.count++
+	b.digest += hasher.Sum64()
+}
+
+// Result returns the Result of the request given the error with which it completed.
+func (b *ResultBuilder) Result(err error) Result {
+	return Result{
+		Code:   status.Code(err).String(),
+		Count:  b.count,
+		Digest: fmt.Sprintf("%016x", b.digest),
+	}
+}
+
+// Config is the configuration of a Capturer.
+type Config struct {
+	// File is the file to which the captured requests are appended, one JSON record per line.
+	File string
+
+	// SampleRate is the fraction of the requests of the captured methods which are captured,
+	// between 0 (exclusive) and 1.
+	SampleRate float64
+
+	// MaxRecords is the maximum number of requests captured, after which capturing stops. Zero
+	// leaves the number unlimited.
+	MaxRecords uint64
+
+	// DisableHashing, if true, records the identifiers and caveat context values of the requests
+	// as sent, so that they can be replayed, rather than their hashes.
+	DisableHashing bool
+}
+
+// Capturer captures a sample of the requests served to a file.
+type Capturer struct {
+	config Config
+	sample func() bool
+	hasher *hasher
+
+	recorded atomic.Uint64
+
+	lock    sync.Mutex
+	file    *os.File
+	encoder *json.Encoder
+}
+
+// NewCapturer returns a new Capturer appending the requests it captures to the configured file.
+func NewCapturer(config Config) (*Capturer, error) {
+	if config.File == "" {
+		return nil, errors.New("a capture file is required")
+	}
+
+	if config.SampleRate <= 0 || config.SampleRate > 1 {
+		return nil, fmt.Errorf("capture sample rate must be greater than 0 and at most 1, found %v", config.SampleRate)
+	}
+
+	var hasher *hasher
+	if !config.DisableHashing {
+		var err error
+		hasher, err = newHasher()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate capture salt: %w", err)
+		}
+	}
+
+	file, err := os.OpenFile(config.File, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open capture file: %w", err)
+	}
+
+	return &Capturer{
+		config: config,
+		sample: func() bool {
+			return rand.Float64() < config.SampleRate // nolint:gosec // sampling does not need a secure source
+		},
+		hasher:  hasher,
+		file:    file,
+		encoder: json.NewEncoder(file),
+	}, nil
+}
+
+// Close closes the capture file.
+func (c *Capturer) Close() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.file.Close()
+}
+
+// shouldCapture returns whether to capture a call of the given method. Requests to datastore
+// forks are never captured, as their revisions cannot be replayed.
+func (c *Capturer) shouldCapture(ctx context.Context, fullMethod string) bool {
+	if _, ok := methods[fullMethod]; !ok {
+		return false
+	}
+
+	if c.config.MaxRecords > 0 && c.recorded.Load() >= c.config.MaxRecords {
+		return false
+	}
+
+	if len(metadata.ValueFromIncomingContext(ctx, string(requestmeta.RequestFork))) > 0 {
+		return false
+	}
+
+	return c.sample()
+}
+
+// record writes the record of a captured call.
+func (c *Capturer) record(ctx context.Context, fullMethod string, start time.Time, req any, result Result) {
+	latency := time.Since(start)
+
+	msg, ok := req.(proto.Message)
+	if !ok {
+		return
+	}
+	if c.hasher != nil {
+		msg = c.hasher.hashRequest(msg)
+	}
+
+	request, err := protojson.Marshal(msg)
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Str("method", fullMethod).Msg("unable to marshal captured request")
+		return
+	}
+
+	record := Record{
+		CapturedAt:    start.UTC(),
+		Method:        fullMethod,
+		Request:       request,
+		Result:        result,
+		LatencyMillis: float64(latency) / float64(time.Millisecond),
+		Hashed:        c.hasher != nil,
+	}
+
+	if _, zedToken, err := consistency.RevisionFromContext(ctx); err == nil && zedToken != nil {
+		record.Revision = zedToken.Token
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	// The limit is checked again under the lock, as calls sampled concurrently may exceed it.
+	if c.config.MaxRecords > 0 && c.recorded.Load() >= c.config.MaxRecords {
+		return
+	}
+
+	if err := c.encoder.Encode(record); err != nil {
+		log.Ctx(ctx).Warn().Err(err).Str("method", fullMethod).Msg("unable to write captured request")
+		return
+	}
+	c.recorded.Add(1)
+}
+
+// UnaryServerInterceptor returns a new unary server interceptor capturing a sample of the
+// requests. It must run after the consistency middleware, so that the revision at which the
+// requests are served is recorded.
+func (c *Capturer) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if !c.shouldCapture(ctx, info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		start := time.Now()
+		resp, err := handler(ctx, req)
+
+		builder := ResultBuilder{hasher: c.hasher}
+		if msg, ok := resp.(proto.Message); ok && err == nil {
+			builder.Add(msg)
+		}
+		c.record(ctx, info.FullMethod, start, req, builder.Result(err))
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor returns a new stream server interceptor capturing a sample of the
+// requests. It must run after the consistency middleware, so that the revision at which the
+// requests are served is recorded.
+func (c *Capturer) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !c.shouldCapture(stream.Context(), info.FullMethod) {
+			return handler(srv, stream)
+		}
+
+		start := time.Now()
+		capturing := &capturingStream{
+			WrappedServerStream: middleware.WrapServerStream(stream),
+			builder:             ResultBuilder{hasher: c.hasher},
+		}
+		err := handler(srv, capturing)
+
+		c.record(stream.Context(), info.FullMethod, start, capturing.request, capturing.builder.Result(err))
+		return err
+	}
+}
+
+// capturingStream keeps the request received on the stream and the results of the responses
+// sent on it.
+type capturingStream struct {
+	*middleware.WrappedServerStream
+
+	request any
+	builder ResultBuilder
+}
+
+func (s *capturingStream) RecvMsg(m any) error {
+	if err := s.WrappedServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+
+	if s.request == nil {
+		s.request = m
+	}
+	return nil
+}
+
+func (s *capturingStream) SendMsg(m any) error {
+	if err := s.WrappedServerStream.SendMsg(m); err != nil {
+		return err
+	}
+
+	if msg, ok := m.(proto.Message); ok {
+		s.builder.Add(msg)
+	}
+	return nil
+}
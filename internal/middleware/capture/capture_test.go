@@ -0,0 +1,266 @@
+package capture
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/authzed/spicedb/pkg/requestmeta"
+)
+
+func newTestCapturer(t *testing.T, config Config) (*Capturer, string) {
+	config.File = filepath.Join(t.TempDir(), "capture.ndjson")
+	config.SampleRate = 1
+	capturer, err := NewCapturer(config)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = capturer.Close() })
+	return capturer, config.File
+}
+
+func readRecords(t *testing.T, file string) []Record {
+	f, err := os.Open(file)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var records []Record
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var record Record
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+		records = append(records, record)
+	}
+	require.NoError(t, scanner.Err())
+	return records
+}
+
+func TestNewCapturerValidation(t *testing.T) {
+	_, err := NewCapturer(Config{SampleRate: 1})
+	require.ErrorContains(t, err, "capture file")
+
+	_, err = NewCapturer(Config{File: filepath.Join(t.TempDir(), "capture.ndjson"), SampleRate: 1.5})
+	require.ErrorContains(t, err, "sample rate")
+}
+
+func TestResultBuilderIsOrderIndependent(t *testing.T) {
+	var first, second ResultBuilder
+	first.Add(&v1.LookupResourcesResponse{ResourceObjectId: "a", Permissionship: v1.LookupPermissionship_LOOKUP_PERMISSIONSHIP_HAS_PERMISSION})
+	first.Add(&v1.LookupResourcesResponse{ResourceObjectId: "b", Permissionship: v1.LookupPermissionship_LOOKUP_PERMISSIONSHIP_HAS_PERMISSION})
+	second.Add(&v1.LookupResourcesResponse{ResourceObjectId: "b", Permissionship: v1.LookupPermissionship_LOOKUP_PERMISSIONSHIP_HAS_PERMISSION})
+	second.Add(&v1.LookupResourcesResponse{ResourceObjectId: "a", Permissionship: v1.LookupPermissionship_LOOKUP_PERMISSIONSHIP_HAS_PERMISSION})
+	require.Equal(t, first.Result(nil), second.Result(nil))
+	require.Equal(t, uint64(2), first.Result(nil).Count)
+
+	var conditional ResultBuilder
+	conditional.Add(&v1.LookupResourcesResponse{ResourceObjectId: "a", Permissionship: v1.LookupPermissionship_LOOKUP_PERMISSIONSHIP_CONDITIONAL_PERMISSION})
+	conditional.Add(&v1.LookupResourcesResponse{ResourceObjectId: "b", Permissionship: v1.LookupPermissionship_LOOKUP_PERMISSIONSHIP_HAS_PERMISSION})
+	require.NotEqual(t, first.Result(nil), conditional.Result(nil))
+
+	require.Equal(t, codes.NotFound.String(), first.Result(status.Error(codes.NotFound, "not found")).Code)
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	capturer, file := newTestCapturer(t, Config{MaxRecords: 2, DisableHashing: true})
+	interceptor := capturer.UnaryServerInterceptor()
+
+	req := &v1.CheckPermissionRequest{
+		Resource:   &v1.ObjectReference{ObjectType: "document", ObjectId: "doc"},
+		Permission: "view",
+		Subject:    &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: "user", ObjectId: "tom"}},
+	}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return &v1.CheckPermissionResponse{Permissionship: v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION}, nil
+	}
+	checkInfo := &grpc.UnaryServerInfo{FullMethod: v1.PermissionsService_CheckPermission_FullMethodName}
+
+	// The callers of requests are never captured.
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "bearer sometoken"))
+	_, err := interceptor(ctx, req, checkInfo, handler)
+	require.NoError(t, err)
+
+	// Requests of other methods and to forks are not captured.
+	_, err = interceptor(context.Background(), &v1.ReadSchemaRequest{}, &grpc.UnaryServerInfo{FullMethod: v1.SchemaService_ReadSchema_FullMethodName}, handler)
+	require.NoError(t, err)
+
+	forkCtx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(string(requestmeta.RequestFork), "somefork"))
+	_, err = interceptor(forkCtx, req, checkInfo, handler)
+	require.NoError(t, err)
+
+	// Failed requests are captured with their code, up to the maximum number of records.
+	for range 3 {
+		_, err = interceptor(context.Background(), req, checkInfo, func(ctx context.Context, req any) (any, error) {
+			return nil, status.Error(codes.FailedPrecondition, "failed")
+		})
+		require.Error(t, err)
+	}
+
+	records := readRecords(t, file)
+	require.Len(t, records, 2)
+
+	require.Equal(t, v1.PermissionsService_CheckPermission_FullMethodName, records[0].Method)
+	require.NotContains(t, string(records[0].Request), "sometoken")
+	captured := &v1.CheckPermissionRequest{}
+	require.NoError(t, protojson.Unmarshal(records[0].Request, captured))
+	require.True(t, captured.EqualVT(req))
+
+	var expected ResultBuilder
+	expected.Add(&v1.CheckPermissionResponse{Permissionship: v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION})
+	require.Equal(t, expected.Result(nil), records[0].Result)
+
+	require.Equal(t, codes.FailedPrecondition.String(), records[1].Result.Code)
+	require.Zero(t, records[1].Result.Count)
+	require.False(t, records[0].Hashed)
+}
+
+func TestHashing(t *testing.T) {
+	capturer, file := newTestCapturer(t, Config{})
+	interceptor := capturer.UnaryServerInterceptor()
+
+	caveatContext, err := structpb.NewStruct(map[string]any{
+		"ip":      "10.0.0.1",
+		"allowed": []any{"first", 42},
+		"nested":  map[string]any{"enabled": true, "unset": nil},
+	})
+	require.NoError(t, err)
+
+	req := &v1.CheckBulkPermissionsRequest{Items: []*v1.CheckBulkPermissionsRequestItem{
+		{
+			Resource:   &v1.ObjectReference{ObjectType: "document", ObjectId: "doc"},
+			Permission: "view",
+			Subject:    &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: "user", ObjectId: "tom"}},
+			Context:    caveatContext,
+		},
+		{
+			Resource:   &v1.ObjectReference{ObjectType: "document", ObjectId: "doc"},
+			Permission: "view",
+			Subject:    &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: "user", ObjectId: "*"}},
+		},
+	}}
+	_, err = interceptor(context.Background(), req, &grpc.UnaryServerInfo{FullMethod: v1.PermissionsService_CheckBulkPermissions_FullMethodName}, func(ctx context.Context, req any) (any, error) {
+		return &v1.CheckBulkPermissionsResponse{}, nil
+	})
+	require.NoError(t, err)
+
+	records := readRecords(t, file)
+	require.Len(t, records, 1)
+	require.True(t, records[0].Hashed)
+	for _, sent := range []string{"doc", "tom", "10.0.0.1", "first"} {
+		require.NotContains(t, string(records[0].Request), `"`+sent+`"`)
+	}
+
+	captured := &v1.CheckBulkPermissionsRequest{}
+	require.NoError(t, protojson.Unmarshal(records[0].Request, captured))
+	first, second := captured.Items[0], captured.Items[1]
+
+	// The same identifiers have the same hash, and the wildcard is kept.
+	require.Len(t, first.Resource.ObjectId, hashedLength)
+	require.Equal(t, first.Resource.ObjectId, second.Resource.ObjectId)
+	require.NotEqual(t, first.Resource.ObjectId, first.Subject.Object.ObjectId)
+	require.Equal(t, "*", second.Subject.Object.ObjectId)
+	require.Equal(t, "document", first.Resource.ObjectType)
+
+	// The caveat context keeps its keys and structure.
+	fields := first.Context.AsMap()
+	require.Len(t, fields["ip"], hashedLength)
+	require.Len(t, fields["allowed"], 2)
+	require.Len(t, fields["nested"].(map[string]any)["enabled"], hashedLength)
+	require.Nil(t, fields["nested"].(map[string]any)["unset"])
+
+	// The request served is left unchanged.
+	require.Equal(t, "doc", req.Items[0].Resource.ObjectId)
+	require.Equal(t, "10.0.0.1", req.Items[0].Context.Fields["ip"].GetStringValue())
+
+	// Captures are hashed with different salts.
+	other, err := newHasher()
+	require.NoError(t, err)
+	require.NotEqual(t, capturer.hasher.hash("doc"), other.hash("doc"))
+}
+
+func TestHashingLookups(t *testing.T) {
+	capturer, file := newTestCapturer(t, Config{})
+	interceptor := capturer.StreamServerInterceptor()
+
+	stream := &testServerStream{request: &v1.LookupResourcesRequest{ResourceObjectType: "document"}}
+	info := &grpc.StreamServerInfo{FullMethod: v1.PermissionsService_LookupResources_FullMethodName}
+	err := interceptor(nil, stream, info, func(srv any, stream grpc.ServerStream) error {
+		req := &v1.LookupResourcesRequest{}
+		if err := stream.RecvMsg(req); err != nil {
+			return err
+		}
+		return stream.SendMsg(&v1.LookupResourcesResponse{ResourceObjectId: "secret"})
+	})
+	require.NoError(t, err)
+
+	// The digest of the results is computed from the hashes of their identifiers.
+	var unhashed ResultBuilder
+	unhashed.Add(&v1.LookupResourcesResponse{ResourceObjectId: "secret"})
+
+	hashed := ResultBuilder{hasher: capturer.hasher}
+	hashed.Add(&v1.LookupResourcesResponse{ResourceObjectId: "secret"})
+
+	records := readRecords(t, file)
+	require.Len(t, records, 1)
+	require.Equal(t, hashed.Result(nil), records[0].Result)
+	require.NotEqual(t, unhashed.Result(nil).Digest, records[0].Result.Digest)
+
+	cursored := capturer.hasher.hashRequest(&v1.LookupResourcesRequest{
+		Subject:        &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: "user", ObjectId: "tom"}},
+		OptionalCursor: &v1.Cursor{Token: "sometoken"},
+	}).(*v1.LookupResourcesRequest)
+	require.Nil(t, cursored.OptionalCursor)
+	require.Equal(t, capturer.hasher.hash("tom"), cursored.Subject.Object.ObjectId)
+}
+
+type testServerStream struct {
+	grpc.ServerStream
+	request *v1.LookupResourcesRequest
+}
+
+func (s *testServerStream) Context() context.Context { return context.Background() }
+
+func (s *testServerStream) RecvMsg(m any) error {
+	m.(*v1.LookupResourcesRequest).ResourceObjectType = s.request.ResourceObjectType
+	return nil
+}
+
+func (s *testServerStream) SendMsg(m any) error { return nil }
+
+func TestStreamServerInterceptor(t *testing.T) {
+	capturer, file := newTestCapturer(t, Config{})
+	interceptor := capturer.StreamServerInterceptor()
+
+	stream := &testServerStream{request: &v1.LookupResourcesRequest{ResourceObjectType: "document"}}
+	info := &grpc.StreamServerInfo{FullMethod: v1.PermissionsService_LookupResources_FullMethodName}
+	err := interceptor(nil, stream, info, func(srv any, stream grpc.ServerStream) error {
+		req := &v1.LookupResourcesRequest{}
+		if err := stream.RecvMsg(req); err != nil {
+			return err
+		}
+
+		for _, id := range []string{"first", "second"} {
+			if err := stream.SendMsg(&v1.LookupResourcesResponse{ResourceObjectId: id}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	records := readRecords(t, file)
+	require.Len(t, records, 1)
+	require.Equal(t, v1.PermissionsService_LookupResources_FullMethodName, records[0].Method)
+	require.JSONEq(t, `{"resourceObjectType":"document"}`, string(records[0].Request))
+	require.Equal(t, uint64(2), records[0].Result.Count)
+	require.Equal(t, codes.OK.String(), records[0].Result.Code)
+}
@@ -0,0 +1,133 @@
+package capture
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// hashedLength is the number of hexadecimal characters of the hashes replacing the identifiers,
+// which are valid object IDs.
+const hashedLength = 32
+
+// hasher replaces the object and subject IDs and the caveat context values of the captured
+// requests with their HMAC keyed with a salt of the capture, so that the requests referencing the
+// same objects can be told apart without recording the objects. As the salt is never recorded,
+// the hashes cannot be reversed by hashing guessed identifiers.
+type hasher struct {
+	salt []byte
+}
+
+func newHasher() (*hasher, error) {
+	salt := make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return &hasher{salt: salt}, nil
+}
+
+func (h *hasher) hash(value string) string {
+	mac := hmac.New(sha256.New, h.salt)
+	_, _ = mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))[:hashedLength]
+}
+
+// hashID returns the hash of the object ID, keeping the wildcard, which references no object.
+func (h *hasher) hashID(objectID string) string {
+	if objectID == tuple.PublicWildcard {
+		return objectID
+	}
+	return h.hash(objectID)
+}
+
+// hashRequest returns a copy of the request with its identifiers and caveat context values
+// replaced by their hashes. The cursors of lookups, which encode identifiers, are dropped.
+func (h *hasher) hashRequest(msg proto.Message) proto.Message {
+	switch req := proto.Clone(msg).(type) {
+	case *v1.CheckPermissionRequest:
+		h.hashObject(req.Resource)
+		h.hashSubject(req.Subject)
+		h.hashStruct(req.Context)
+		return req
+
+	case *v1.CheckBulkPermissionsRequest:
+		for _, item := range req.Items {
+			h.hashObject(item.Resource)
+			h.hashSubject(item.Subject)
+			h.hashStruct(item.Context)
+		}
+		return req
+
+	case *v1.LookupResourcesRequest:
+		h.hashSubject(req.Subject)
+		h.hashStruct(req.Context)
+		req.OptionalCursor = nil
+		return req
+
+	case *v1.LookupSubjectsRequest:
+		h.hashObject(req.Resource)
+		h.hashStruct(req.Context)
+		return req
+
+	default:
+		return req
+	}
+}
+
+func (h *hasher) hashObject(object *v1.ObjectReference) {
+	if object != nil {
+		object.ObjectId = h.hashID(object.ObjectId)
+	}
+}
+
+func (h *hasher) hashSubject(subject *v1.SubjectReference) {
+	if subject != nil {
+		h.hashObject(subject.Object)
+	}
+}
+
+// hashStruct replaces the values of the caveat context with their hashes, keeping its keys and
+// structure.
+func (h *hasher) hashStruct(context *structpb.Struct) {
+	if context == nil {
+		return
+	}
+
+	for key, value := range context.Fields {
+		context.Fields[key] = h.hashValue(value)
+	}
+}
+
+func (h *hasher) hashValue(value *structpb.Value) *structpb.Value {
+	switch kind := value.GetKind().(type) {
+	case *structpb.Value_StructValue:
+		h.hashStruct(kind.StructValue)
+		return value
+
+	case *structpb.Value_ListValue:
+		for index, item := range kind.ListValue.GetValues() {
+			kind.ListValue.Values[index] = h.hashValue(item)
+		}
+		return value
+
+	case *structpb.Value_StringValue:
+		return structpb.NewStringValue(h.hash(kind.StringValue))
+
+	case *structpb.Value_NumberValue:
+		return structpb.NewStringValue(h.hash(strconv.FormatFloat(kind.NumberValue, 'g', -1, 64)))
+
+	case *structpb.Value_BoolValue:
+		return structpb.NewStringValue(h.hash(strconv.FormatBool(kind.BoolValue)))
+
+	default:
+		return value
+	}
+}
@@ -0,0 +1,91 @@
+// Package errorcodes provides interceptors attaching the code of the error catalogue to every
+// error returned by the API.
+package errorcodes
+
+import (
+	"context"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/authzed/spicedb/pkg/errorcodes"
+	"github.com/authzed/spicedb/pkg/spiceerrors"
+)
+
+// UnaryServerInterceptor returns a new interceptor which attaches error codes to the errors of
+// unary methods.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		resp, err := handler(ctx, req)
+		return resp, WithErrorCode(err)
+	}
+}
+
+// StreamServerInterceptor returns a new interceptor which attaches error codes to the errors of
+// streaming methods.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return WithErrorCode(handler(srv, stream))
+	}
+}
+
+// WithErrorCode returns the error with an error code in the metadata of each of its ErrorInfo
+// details, derived from their reason or the status code of the error when missing. An ErrorInfo
+// is added to errors without any.
+func WithErrorCode(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	// Errors without a status are returned with the Unknown code by gRPC.
+	s, _ := status.FromError(err)
+	p := s.Proto()
+
+	found := false
+	changed := false
+	for i, detail := range p.Details {
+		info := &errdetails.ErrorInfo{}
+		if !detail.MessageIs(info) {
+			continue
+		}
+
+		if err := detail.UnmarshalTo(info); err != nil {
+			continue
+		}
+
+		found = true
+		if info.Metadata[errorcodes.MetadataKey] != "" {
+			continue
+		}
+
+		if info.Metadata == nil {
+			info.Metadata = make(map[string]string, 1)
+		}
+		info.Metadata[errorcodes.MetadataKey] = string(errorcodes.Derive(s.Code(), info))
+
+		updated, err := anypb.New(info)
+		if err != nil {
+			continue
+		}
+		p.Details[i] = updated
+		changed = true
+	}
+
+	if !found {
+		added, err := anypb.New(spiceerrors.ForReason(v1.ErrorReason_ERROR_REASON_UNSPECIFIED, map[string]string{
+			errorcodes.MetadataKey: string(errorcodes.Derive(s.Code(), nil)),
+		}))
+		if err == nil {
+			p.Details = append(p.Details, added)
+			changed = true
+		}
+	}
+
+	if !changed {
+		return err
+	}
+	return status.FromProto(p).Err()
+}
@@ -0,0 +1,91 @@
+package errorcodes
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+
+	"github.com/authzed/spicedb/pkg/errorcodes"
+	"github.com/authzed/spicedb/pkg/spiceerrors"
+)
+
+func TestWithErrorCode(t *testing.T) {
+	require.NoError(t, WithErrorCode(nil))
+
+	tcs := []struct {
+		name            string
+		err             error
+		expectedCode    codes.Code
+		expectedErrCode errorcodes.Code
+	}{
+		{
+			"error without status",
+			errors.New("some error"),
+			codes.Unknown,
+			errorcodes.Unknown,
+		},
+		{
+			"status without details",
+			status.Error(codes.Unauthenticated, "no token"),
+			codes.Unauthenticated,
+			errorcodes.Unauthenticated,
+		},
+		{
+			"status with reason",
+			spiceerrors.WithCodeAndReason(errors.New("unknown caveat"), codes.FailedPrecondition, v1.ErrorReason_ERROR_REASON_UNKNOWN_CAVEAT),
+			codes.FailedPrecondition,
+			errorcodes.UnknownCaveat,
+		},
+		{
+			"status with code",
+			spiceerrors.WithCodeAndDetailsAsError(errors.New("gone"), codes.OutOfRange, spiceerrors.ForCode(errorcodes.RevisionGarbageCollected, nil)),
+			codes.OutOfRange,
+			errorcodes.RevisionGarbageCollected,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			err := WithErrorCode(tc.err)
+			s, ok := status.FromError(err)
+			require.True(t, ok)
+			require.Equal(t, tc.expectedCode, s.Code())
+
+			code, ok := errorcodes.FromError(err)
+			require.True(t, ok)
+			require.Equal(t, tc.expectedErrCode, code)
+		})
+	}
+}
+
+func TestWithErrorCodeKeepsDetails(t *testing.T) {
+	err := spiceerrors.WithCodeAndDetailsAsError(
+		errors.New("invalid"),
+		codes.InvalidArgument,
+		&errdetails.BadRequest{FieldViolations: []*errdetails.BadRequest_FieldViolation{{Field: "updates[0]"}}},
+		spiceerrors.ForReason(v1.ErrorReason_ERROR_REASON_INVALID_SUBJECT_TYPE, map[string]string{"update_index": "0"}),
+		spiceerrors.ForReason(v1.ErrorReason_ERROR_REASON_UNKNOWN_CAVEAT, map[string]string{"update_index": "1"}),
+	)
+
+	s, ok := status.FromError(WithErrorCode(err))
+	require.True(t, ok)
+	require.Equal(t, "invalid", s.Message())
+
+	details := s.Details()
+	require.Len(t, details, 3)
+	require.IsType(t, &errdetails.BadRequest{}, details[0])
+
+	first := details[1].(*errdetails.ErrorInfo)
+	require.Equal(t, "0", first.Metadata["update_index"])
+	require.Equal(t, string(errorcodes.InvalidSubjectType), first.Metadata[errorcodes.MetadataKey])
+
+	second := details[2].(*errdetails.ErrorInfo)
+	require.Equal(t, "1", second.Metadata["update_index"])
+	require.Equal(t, string(errorcodes.UnknownCaveat), second.Metadata[errorcodes.MetadataKey])
+}
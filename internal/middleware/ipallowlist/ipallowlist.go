@@ -0,0 +1,140 @@
+// Package ipallowlist provides gRPC interceptors and HTTP middleware restricting the peers
+// allowed to call a server to a set of CIDRs.
+package ipallowlist
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	log "github.com/authzed/spicedb/internal/logging"
+)
+
+// Allowlist is a set of CIDRs from which peers are allowed to call a server.
+type Allowlist struct {
+	prefixes []netip.Prefix
+}
+
+// New returns the allowlist of the given CIDRs, e.g. `10.0.0.0/8` or `2001:db8::/32`. A bare
+// IP address is allowed as the CIDR of only that address. IPv4-mapped IPv6 CIDRs, e.g.
+// `::ffff:10.0.0.0/104`, are allowed as the IPv4 CIDRs they map, as peer addresses are compared
+// unmapped.
+func New(cidrs []string) (*Allowlist, error) {
+	prefixes := make([]netip.Prefix, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		cidr = strings.TrimSpace(cidr)
+		if !strings.Contains(cidr, "/") {
+			addr, err := netip.ParseAddr(cidr)
+			if err != nil {
+				return nil, fmt.Errorf("invalid allowed CIDR `%s`: %w", cidr, err)
+			}
+			addr = addr.Unmap()
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowed CIDR `%s`: %w", cidr, err)
+		}
+
+		if prefix.Addr().Is4In6() {
+			// The mapped IPv4 address is in the last 32 bits of the IPv6 address.
+			bits := prefix.Bits() - 96
+			if bits < 0 {
+				return nil, fmt.Errorf("invalid allowed CIDR `%s`: IPv4-mapped CIDRs must have a prefix length of at least 96", cidr)
+			}
+			prefix = netip.PrefixFrom(prefix.Addr().Unmap(), bits)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+
+	return &Allowlist{prefixes: prefixes}, nil
+}
+
+// Allows returns whether the given peer address is in one of the CIDRs of the allowlist.
+func (a *Allowlist) Allows(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, prefix := range a.prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowsPeer returns whether the given network address of a peer is allowed. Peers which are not
+// connected over IP, such as those of unix sockets and in-memory listeners, are local to the
+// server and therefore always allowed.
+func (a *Allowlist) allowsPeer(addr net.Addr) bool {
+	switch addr := addr.(type) {
+	case *net.TCPAddr:
+		ip, ok := netip.AddrFromSlice(addr.IP)
+		return ok && a.Allows(ip)
+	case *net.UDPAddr:
+		ip, ok := netip.AddrFromSlice(addr.IP)
+		return ok && a.Allows(ip)
+	case *net.IPAddr:
+		ip, ok := netip.AddrFromSlice(addr.IP)
+		return ok && a.Allows(ip)
+	default:
+		return true
+	}
+}
+
+func (a *Allowlist) checkPeer(ctx context.Context, fullMethod string) error {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return status.Error(codes.PermissionDenied, "unable to determine the address of the peer")
+	}
+
+	if !a.allowsPeer(p.Addr) {
+		log.Ctx(ctx).Debug().Str("peer", p.Addr.String()).Str("method", fullMethod).Msg("rejected request from peer outside of the allowed CIDRs")
+		return status.Errorf(codes.PermissionDenied, "peer %s is not allowed to call this server", p.Addr)
+	}
+	return nil
+}
+
+// UnaryServerInterceptor returns a new unary server interceptor rejecting requests from peers
+// outside of the allowlist.
+func UnaryServerInterceptor(allowlist *Allowlist) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := allowlist.checkPeer(ctx, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns a new stream server interceptor rejecting streams from peers
+// outside of the allowlist.
+func StreamServerInterceptor(allowlist *Allowlist) grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := allowlist.checkPeer(stream.Context(), info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, stream)
+	}
+}
+
+// HTTPHandler returns a handler responding with 403 Forbidden to requests from peers outside of
+// the allowlist, and serving the others with the given handler.
+func HTTPHandler(allowlist *Allowlist, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		addrPort, err := netip.ParseAddrPort(r.RemoteAddr)
+		if err != nil || !allowlist.Allows(addrPort.Addr()) {
+			log.Ctx(r.Context()).Debug().Str("peer", r.RemoteAddr).Str("path", r.URL.Path).Msg("rejected request from peer outside of the allowed CIDRs")
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,136 @@
+package ipallowlist
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func TestNew(t *testing.T) {
+	_, err := New([]string{"10.0.0.0/33"})
+	require.ErrorContains(t, err, "invalid allowed CIDR `10.0.0.0/33`")
+
+	_, err = New([]string{"notanip"})
+	require.ErrorContains(t, err, "invalid allowed CIDR `notanip`")
+
+	_, err = New([]string{"::ffff:0.0.0.0/95"})
+	require.ErrorContains(t, err, "invalid allowed CIDR `::ffff:0.0.0.0/95`")
+
+	allowlist, err := New([]string{"10.1.2.3/8", " 192.168.1.10", "2001:db8::/32", "::ffff:172.16.0.0/108", "::ffff:192.168.2.1"})
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		addr    string
+		allowed bool
+	}{
+		{"10.0.0.1", true},
+		{"10.255.255.255", true},
+		{"::ffff:10.0.0.1", true},
+		{"11.0.0.1", false},
+		{"192.168.1.10", true},
+		{"192.168.1.11", false},
+		{"2001:db8::1", true},
+		{"2001:db9::1", false},
+		{"172.16.0.1", true},
+		{"::ffff:172.31.255.255", true},
+		{"172.32.0.1", false},
+		{"192.168.2.1", true},
+		{"::ffff:192.168.2.1", true},
+	} {
+		t.Run(tc.addr, func(t *testing.T) {
+			require.Equal(t, tc.allowed, allowlist.Allows(netip.MustParseAddr(tc.addr)))
+		})
+	}
+
+	empty, err := New(nil)
+	require.NoError(t, err)
+	require.False(t, empty.Allows(netip.MustParseAddr("127.0.0.1")))
+}
+
+func contextWithPeer(addr net.Addr) context.Context {
+	return peer.NewContext(context.Background(), &peer.Peer{Addr: addr})
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	allowlist, err := New([]string{"10.0.0.0/8"})
+	require.NoError(t, err)
+
+	interceptor := UnaryServerInterceptor(allowlist)
+	handler := func(ctx context.Context, req any) (any, error) { return req, nil }
+	info := &grpc.UnaryServerInfo{FullMethod: "/authzed.api.v1.WatchService/Watch"}
+
+	_, err = interceptor(contextWithPeer(&net.TCPAddr{IP: net.ParseIP("10.1.1.1"), Port: 1234}), "req", info, handler)
+	require.NoError(t, err)
+
+	_, err = interceptor(contextWithPeer(&net.TCPAddr{IP: net.ParseIP("172.16.0.1"), Port: 1234}), "req", info, handler)
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = interceptor(context.Background(), "req", info, handler)
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// Peers of unix sockets are local and always allowed.
+	_, err = interceptor(contextWithPeer(&net.UnixAddr{Name: "/tmp/spicedb.sock", Net: "unix"}), "req", info, handler)
+	require.NoError(t, err)
+}
+
+type fakeServerStream struct {
+	grpc.ServerStream
+
+	ctx context.Context
+}
+
+func (s *fakeServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	allowlist, err := New([]string{"2001:db8::/32"})
+	require.NoError(t, err)
+
+	interceptor := StreamServerInterceptor(allowlist)
+	handler := func(srv any, stream grpc.ServerStream) error { return nil }
+	info := &grpc.StreamServerInfo{FullMethod: "/authzed.api.v1.WatchService/Watch"}
+
+	stream := &fakeServerStream{ctx: contextWithPeer(&net.TCPAddr{IP: net.ParseIP("2001:db8::1"), Port: 1234})}
+	require.NoError(t, interceptor(nil, stream, info, handler))
+
+	stream = &fakeServerStream{ctx: contextWithPeer(&net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 1234})}
+	require.Equal(t, codes.PermissionDenied, status.Code(interceptor(nil, stream, info, handler)))
+}
+
+func TestHTTPHandler(t *testing.T) {
+	allowlist, err := New([]string{"192.0.2.0/24"})
+	require.NoError(t, err)
+
+	handler := HTTPHandler(allowlist, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	for _, tc := range []struct {
+		remoteAddr string
+		expected   int
+	}{
+		{"192.0.2.1:1234", http.StatusOK},
+		{"198.51.100.1:1234", http.StatusForbidden},
+		{"[::ffff:192.0.2.1]:1234", http.StatusOK},
+		{"@", http.StatusForbidden},
+	} {
+		t.Run(tc.remoteAddr, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+			req.RemoteAddr = tc.remoteAddr
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+			require.Equal(t, tc.expected, recorder.Code)
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package metering
+
+import (
+	"context"
+
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/options"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// countingDatastore counts the relationships read through it into the usage of a call.
+type countingDatastore struct {
+	datastore.Datastore
+	usage *requestUsage
+}
+
+func newCountingDatastore(delegate datastore.Datastore, usage *requestUsage) datastore.Datastore {
+	return countingDatastore{Datastore: delegate, usage: usage}
+}
+
+func (cd countingDatastore) Unwrap() datastore.Datastore {
+	return cd.Datastore
+}
+
+func (cd countingDatastore) SnapshotReader(rev datastore.Revision) datastore.Reader {
+	return countingReader{Reader: cd.Datastore.SnapshotReader(rev), usage: cd.usage}
+}
+
+func (cd countingDatastore) ReadWriteTx(ctx context.Context, fn datastore.TxUserFunc, opts ...options.RWTOptionsOption) (datastore.Revision, error) {
+	return cd.Datastore.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		return fn(ctx, countingRWT{
+			ReadWriteTransaction: rwt,
+			reader:               countingReader{Reader: rwt, usage: cd.usage},
+		})
+	}, opts...)
+}
+
+type countingReader struct {
+	datastore.Reader
+	usage *requestUsage
+}
+
+func (cr countingReader) QueryRelationships(ctx context.Context, filter datastore.RelationshipsFilter, options ...options.QueryOptionsOption) (datastore.RelationshipIterator, error) {
+	iterator, err := cr.Reader.QueryRelationships(ctx, filter, options...)
+	if err != nil {
+		return iterator, err
+	}
+	return countRelationships(iterator, cr.usage), nil
+}
+
+func (cr countingReader) ReverseQueryRelationships(ctx context.Context, subjectsFilter datastore.SubjectsFilter, options ...options.ReverseQueryOptionsOption) (datastore.RelationshipIterator, error) {
+	iterator, err := cr.Reader.ReverseQueryRelationships(ctx, subjectsFilter, options...)
+	if err != nil {
+		return iterator, err
+	}
+	return countRelationships(iterator, cr.usage), nil
+}
+
+func (cr countingReader) QueryRelationshipsBatch(ctx context.Context, filters []datastore.RelationshipsFilter, options ...options.QueryOptionsOption) (datastore.TaggedRelationshipIterator, error) {
+	iterator, err := cr.Reader.QueryRelationshipsBatch(ctx, filters, options...)
+	if err != nil {
+		return iterator, err
+	}
+
+	return func(yield func(datastore.TaggedRelationship, error) bool) {
+		for tagged, err := range iterator {
+			if err == nil {
+				cr.usage.relationshipsRead.Add(1)
+			}
+			if !yield(tagged, err) {
+				return
+			}
+		}
+	}, nil
+}
+
+func countRelationships(iterator datastore.RelationshipIterator, usage *requestUsage) datastore.RelationshipIterator {
+	return func(yield func(tuple.Relationship, error) bool) {
+		for rel, err := range iterator {
+			if err == nil {
+				usage.relationshipsRead.Add(1)
+			}
+			if !yield(rel, err) {
+				return
+			}
+		}
+	}
+}
+
+// countingRWT counts the relationships read by a read-write transaction, delegating its
+// queries to a countingReader.
+type countingRWT struct {
+	datastore.ReadWriteTransaction
+	reader countingReader
+}
+
+func (crwt countingRWT) QueryRelationships(ctx context.Context, filter datastore.RelationshipsFilter, options ...options.QueryOptionsOption) (datastore.RelationshipIterator, error) {
+	return crwt.reader.QueryRelationships(ctx, filter, options...)
+}
+
+func (crwt countingRWT) ReverseQueryRelationships(ctx context.Context, subjectsFilter datastore.SubjectsFilter, options ...options.ReverseQueryOptionsOption) (datastore.RelationshipIterator, error) {
+	return crwt.reader.ReverseQueryRelationships(ctx, subjectsFilter, options...)
+}
+
+func (crwt countingRWT) QueryRelationshipsBatch(ctx context.Context, filters []datastore.RelationshipsFilter, options ...options.QueryOptionsOption) (datastore.TaggedRelationshipIterator, error) {
+	return crwt.reader.QueryRelationshipsBatch(ctx, filters, options...)
+}
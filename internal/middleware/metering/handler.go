@@ -0,0 +1,60 @@
+package metering
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// UsageReport is the usage of the API in a month, as served by the meter.
+type UsageReport struct {
+	// Month is the month of the usage, formatted as YYYY-MM.
+	Month string `json:"month"`
+
+	// Usage is the usage of each caller during the month.
+	Usage map[string]Usage `json:"usage"`
+
+	// Quota is the monthly quota of each caller.
+	Quota Quota `json:"quota"`
+
+	// AvailableMonths are the months for which usage can be reported.
+	AvailableMonths []string `json:"availableMonths"`
+}
+
+// ServeHTTP implements http.Handler: GET requests return the UsageReport of the current month
+// as JSON. The `month` query parameter (YYYY-MM) selects another month, and the `caller` query
+// parameter restricts the report to a single caller.
+func (m *Meter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	month := r.URL.Query().Get("month")
+	if month == "" {
+		month = m.CurrentMonth()
+	} else if _, err := time.Parse(monthLayout, month); err != nil {
+		http.Error(w, "invalid month, expected YYYY-MM: "+month, http.StatusBadRequest)
+		return
+	}
+
+	usage := m.UsageForMonth(month)
+	if caller := r.URL.Query().Get("caller"); caller != "" {
+		callerUsage, ok := usage[caller]
+		usage = map[string]Usage{}
+		if ok {
+			usage[caller] = callerUsage
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(UsageReport{
+		Month:           month,
+		Usage:           usage,
+		Quota:           m.Quota(),
+		AvailableMonths: m.Months(),
+	}); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,304 @@
+// Package metering meters the usage of the API by each caller: the API calls it makes, the
+// sub-problems dispatched to answer them and the relationships read from the datastore while
+// doing so. Usage is aggregated by calendar month (in UTC), exported as Prometheus metrics,
+// served as JSON by an HTTP handler and optionally limited by monthly quotas.
+//
+// Usage is metered by each node independently and kept in memory: quotas are enforced per node,
+// and the usage of a node is lost when it restarts.
+package metering
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	middleware "github.com/grpc-ecosystem/go-grpc-middleware/v2"
+	grpcauth "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/auth"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
+	"github.com/authzed/spicedb/pkg/errorcodes"
+	"github.com/authzed/spicedb/pkg/spiceerrors"
+)
+
+const (
+	// AnonymousCaller is the caller under which the usage of calls without a token is metered.
+	AnonymousCaller = "anonymous"
+
+	monthLayout       = "2006-01"
+	retainedMonths    = 12
+	callerTokenPrefix = "token:"
+	callerHashLength  = 12
+)
+
+var (
+	apiCallsCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "spicedb",
+		Subsystem: "metering",
+		Name:      "api_calls_total",
+		Help:      "total number of API calls made by each caller.",
+	}, []string{"caller"})
+
+	dispatchedSubproblemsCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "spicedb",
+		Subsystem: "metering",
+		Name:      "dispatched_subproblems_total",
+		Help:      "total number of sub-problems dispatched to answer the API calls of each caller.",
+	}, []string{"caller"})
+
+	relationshipsReadCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "spicedb",
+		Subsystem: "metering",
+		Name:      "relationships_read_total",
+		Help:      "total number of relationships read from the datastore to answer the API calls of each caller.",
+	}, []string{"caller"})
+
+	quotaExceededCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "spicedb",
+		Subsystem: "metering",
+		Name:      "quota_exceeded_total",
+		Help:      "total number of API calls rejected because their caller exceeded its monthly quota.",
+	}, []string{"caller"})
+)
+
+// Usage is the usage of the API by a caller.
+type Usage struct {
+	APICalls              uint64 `json:"api_calls"`
+	DispatchedSubproblems uint64 `json:"dispatched_subproblems"`
+	RelationshipsRead     uint64 `json:"relationships_read"`
+}
+
+func (u *Usage) add(other Usage) {
+	u.APICalls += other.APICalls
+	u.DispatchedSubproblems += other.DispatchedSubproblems
+	u.RelationshipsRead += other.RelationshipsRead
+}
+
+// Quota is the usage allowed to each caller in a month. A zero value leaves the corresponding
+// usage unlimited.
+type Quota struct {
+	APICalls              uint64 `json:"api_calls,omitempty"`
+	DispatchedSubproblems uint64 `json:"dispatched_subproblems,omitempty"`
+	RelationshipsRead     uint64 `json:"relationships_read,omitempty"`
+}
+
+// exceededBy returns whether the usage has reached any of the limits of the quota.
+func (q Quota) exceededBy(usage Usage) bool {
+	return (q.APICalls > 0 && usage.APICalls >= q.APICalls) ||
+		(q.DispatchedSubproblems > 0 && usage.DispatchedSubproblems >= q.DispatchedSubproblems) ||
+		(q.RelationshipsRead > 0 && usage.RelationshipsRead >= q.RelationshipsRead)
+}
+
+// Meter meters the usage of the API by each caller.
+type Meter struct {
+	quota Quota
+	now   func() time.Time
+
+	lock    sync.RWMutex
+	byMonth map[string]map[string]*Usage
+}
+
+// NewMeter returns a new meter rejecting the calls of callers whose usage of the current month
+// exceeds the quota.
+func NewMeter(quota Quota) *Meter {
+	return &Meter{
+		quota:   quota,
+		now:     time.Now,
+		byMonth: map[string]map[string]*Usage{},
+	}
+}
+
+// Quota returns the monthly quota of each caller.
+func (m *Meter) Quota() Quota {
+	return m.quota
+}
+
+// CurrentMonth returns the month in which usage is currently metered, formatted as YYYY-MM.
+func (m *Meter) CurrentMonth() string {
+	return m.now().UTC().Format(monthLayout)
+}
+
+// Months returns the months for which usage has been metered, in order.
+func (m *Meter) Months() []string {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	months := make([]string, 0, len(m.byMonth))
+	for month := range m.byMonth {
+		months = append(months, month)
+	}
+	slices.Sort(months)
+	return months
+}
+
+// UsageForMonth returns the usage of each caller in the given month, formatted as YYYY-MM.
+func (m *Meter) UsageForMonth(month string) map[string]Usage {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	usage := make(map[string]Usage, len(m.byMonth[month]))
+	for caller, callerUsage := range m.byMonth[month] {
+		usage[caller] = *callerUsage
+	}
+	return usage
+}
+
+// untilNextMonth returns the duration until the start of the next month, when the usage of all
+// callers is reset.
+func (m *Meter) untilNextMonth() time.Duration {
+	now := m.now().UTC()
+	return time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, time.UTC).Sub(now)
+}
+
+func (m *Meter) exceedsQuota(caller string) bool {
+	if m.quota == (Quota{}) {
+		return false
+	}
+
+	month := m.CurrentMonth()
+
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	usage, ok := m.byMonth[month][caller]
+	return ok && m.quota.exceededBy(*usage)
+}
+
+func (m *Meter) record(caller string, usage Usage) {
+	apiCallsCounter.WithLabelValues(caller).Add(float64(usage.APICalls))
+	dispatchedSubproblemsCounter.WithLabelValues(caller).Add(float64(usage.DispatchedSubproblems))
+	relationshipsReadCounter.WithLabelValues(caller).Add(float64(usage.RelationshipsRead))
+
+	month := m.CurrentMonth()
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	callers, ok := m.byMonth[month]
+	if !ok {
+		callers = map[string]*Usage{}
+		m.byMonth[month] = callers
+		m.pruneMonthsLocked()
+	}
+
+	callerUsage, ok := callers[caller]
+	if !ok {
+		callerUsage = &Usage{}
+		callers[caller] = callerUsage
+	}
+	callerUsage.add(usage)
+}
+
+// pruneMonthsLocked forgets the usage of the oldest months beyond those retained.
+func (m *Meter) pruneMonthsLocked() {
+	if len(m.byMonth) <= retainedMonths {
+		return
+	}
+
+	months := make([]string, 0, len(m.byMonth))
+	for month := range m.byMonth {
+		months = append(months, month)
+	}
+	slices.Sort(months)
+	for _, month := range months[:len(months)-retainedMonths] {
+		delete(m.byMonth, month)
+	}
+}
+
+// CallerFromContext returns the caller under which the usage of a call is metered. Callers are
+// identified by a hash of their bearer token, so that tokens are never exposed by the metrics
+// or the usage reports.
+func CallerFromContext(ctx context.Context) string {
+	token, err := grpcauth.AuthFromMD(ctx, "bearer")
+	if err != nil || token == "" {
+		return AnonymousCaller
+	}
+
+	hash := sha256.Sum256([]byte(token))
+	return callerTokenPrefix + hex.EncodeToString(hash[:])[:callerHashLength]
+}
+
+// UnaryServerInterceptor returns a new unary server interceptor that meters the usage of each
+// call. It must run after the datastore middleware, whose datastore it wraps to count the
+// relationships read.
+func (m *Meter) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		meteredCtx, finish, err := m.start(ctx)
+		if err != nil {
+			return nil, err
+		}
+		defer finish()
+
+		return handler(meteredCtx, req)
+	}
+}
+
+// StreamServerInterceptor returns a new stream server interceptor that meters the usage of each
+// call. It must run after the datastore middleware, whose datastore it wraps to count the
+// relationships read.
+func (m *Meter) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		meteredCtx, finish, err := m.start(stream.Context())
+		if err != nil {
+			return err
+		}
+		defer finish()
+
+		wrapped := middleware.WrapServerStream(stream)
+		wrapped.WrappedContext = meteredCtx
+		return handler(srv, wrapped)
+	}
+}
+
+// start begins metering a call, returning the context in which it must run and a function
+// recording its usage once it completes.
+func (m *Meter) start(ctx context.Context) (context.Context, func(), error) {
+	caller := CallerFromContext(ctx)
+	if m.exceedsQuota(caller) {
+		quotaExceededCounter.WithLabelValues(caller).Inc()
+		return nil, nil, spiceerrors.WithCodeAndDetailsAsError(
+			fmt.Errorf("monthly usage quota exceeded for caller %s", caller),
+			codes.ResourceExhausted,
+			spiceerrors.ForCode(errorcodes.QuotaExceeded, map[string]string{"caller": caller}),
+			spiceerrors.ForRetryDelay(m.untilNextMonth()),
+		)
+	}
+
+	handle := &requestUsage{}
+	ctx = context.WithValue(ctx, requestUsageKey{}, handle)
+	if ds := datastoremw.FromContext(ctx); ds != nil {
+		ctx = datastoremw.ContextWithDatastore(ctx, newCountingDatastore(ds, handle))
+	}
+
+	return ctx, func() {
+		m.record(caller, Usage{
+			APICalls:              1,
+			DispatchedSubproblems: handle.dispatchedSubproblems.Load(),
+			RelationshipsRead:     handle.relationshipsRead.Load(),
+		})
+	}, nil
+}
+
+type requestUsageKey struct{}
+
+// requestUsage is the usage of a single call, updated as the call is served.
+type requestUsage struct {
+	dispatchedSubproblems atomic.Uint64
+	relationshipsRead     atomic.Uint64
+}
+
+// RecordDispatchedSubproblems records the number of sub-problems dispatched to answer the call
+// running in the context, if it is metered.
+func RecordDispatchedSubproblems(ctx context.Context, count uint32) {
+	if handle, ok := ctx.Value(requestUsageKey{}).(*requestUsage); ok {
+		handle.dispatchedSubproblems.Add(uint64(count))
+	}
+}
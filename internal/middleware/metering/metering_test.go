@@ -0,0 +1,168 @@
+package metering
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/authzed/spicedb/internal/datastore/dsfortesting"
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
+	"github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/errorcodes"
+	"github.com/authzed/spicedb/pkg/spiceerrors"
+)
+
+func contextWithToken(token string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "bearer "+token))
+}
+
+func TestCallerFromContext(t *testing.T) {
+	require.Equal(t, AnonymousCaller, CallerFromContext(context.Background()))
+
+	caller := CallerFromContext(contextWithToken("sometoken"))
+	require.Equal(t, caller, CallerFromContext(contextWithToken("sometoken")))
+	require.NotEqual(t, caller, CallerFromContext(contextWithToken("othertoken")))
+	require.NotContains(t, caller, "sometoken")
+	require.Len(t, caller, len(callerTokenPrefix)+callerHashLength)
+}
+
+func TestMeterRecordsUsage(t *testing.T) {
+	rawDS, err := dsfortesting.NewMemDBDatastoreForTesting(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+	t.Cleanup(func() { rawDS.Close() })
+
+	ds, revision := testfixtures.StandardDatastoreWithData(rawDS, require.New(t))
+
+	meter := NewMeter(Quota{})
+	interceptor := meter.UnaryServerInterceptor()
+
+	ctx := datastoremw.ContextWithDatastore(contextWithToken("sometoken"), ds)
+	_, err = interceptor(ctx, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, _ any) (any, error) {
+		RecordDispatchedSubproblems(ctx, 3)
+
+		reader := datastoremw.MustFromContext(ctx).SnapshotReader(revision)
+		iter, err := reader.QueryRelationships(ctx, datastore.RelationshipsFilter{OptionalResourceType: "document"})
+		if err != nil {
+			return nil, err
+		}
+
+		count := 0
+		for _, err := range iter {
+			if err != nil {
+				return nil, err
+			}
+			count++
+		}
+		require.Positive(t, count)
+		return count, nil
+	})
+	require.NoError(t, err)
+
+	usage := meter.UsageForMonth(meter.CurrentMonth())
+	require.Len(t, usage, 1)
+
+	callerUsage := usage[CallerFromContext(ctx)]
+	require.Equal(t, uint64(1), callerUsage.APICalls)
+	require.Equal(t, uint64(3), callerUsage.DispatchedSubproblems)
+	require.Positive(t, callerUsage.RelationshipsRead)
+}
+
+func TestMeterEnforcesQuota(t *testing.T) {
+	meter := NewMeter(Quota{APICalls: 2})
+	meter.now = func() time.Time { return time.Date(2024, 2, 28, 12, 0, 0, 0, time.UTC) }
+	interceptor := meter.UnaryServerInterceptor()
+	handler := func(ctx context.Context, _ any) (any, error) { return nil, nil }
+
+	ctx := contextWithToken("sometoken")
+	for range 2 {
+		_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{}, handler)
+		require.NoError(t, err)
+	}
+
+	_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{}, handler)
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	// The caller is told to retry once the quota resets.
+	retryInfo, ok := spiceerrors.GetDetails[*errdetails.RetryInfo](err)
+	require.True(t, ok)
+	require.Equal(t, 36*time.Hour, retryInfo.RetryDelay.AsDuration())
+
+	code, _ := errorcodes.FromError(err)
+	require.Equal(t, errorcodes.QuotaExceeded, code)
+
+	// Other callers have their own quota.
+	_, err = interceptor(contextWithToken("othertoken"), nil, &grpc.UnaryServerInfo{}, handler)
+	require.NoError(t, err)
+
+	// Quotas reset with each month.
+	meter.now = func() time.Time { return time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC) }
+	_, err = interceptor(ctx, nil, &grpc.UnaryServerInfo{}, handler)
+	require.NoError(t, err)
+}
+
+func TestMeterPrunesMonths(t *testing.T) {
+	meter := NewMeter(Quota{})
+	start := time.Date(2024, time.January, 15, 0, 0, 0, 0, time.UTC)
+	for month := range retainedMonths + 3 {
+		meter.now = func() time.Time { return start.AddDate(0, month, 0) }
+		meter.record(AnonymousCaller, Usage{APICalls: 1})
+	}
+
+	months := meter.Months()
+	require.Len(t, months, retainedMonths)
+	require.Equal(t, "2024-04", months[0])
+	require.Equal(t, "2025-03", months[len(months)-1])
+}
+
+func TestUsageHandler(t *testing.T) {
+	meter := NewMeter(Quota{RelationshipsRead: 100})
+	meter.now = func() time.Time { return time.Date(2024, time.May, 3, 0, 0, 0, 0, time.UTC) }
+	meter.record("token:aaa", Usage{APICalls: 1, RelationshipsRead: 5})
+	meter.record("token:bbb", Usage{APICalls: 2})
+
+	get := func(target string) (int, UsageReport) {
+		recorder := httptest.NewRecorder()
+		meter.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, target, nil))
+
+		var report UsageReport
+		if recorder.Code == http.StatusOK {
+			require.NoError(t, json.NewDecoder(recorder.Body).Decode(&report))
+		}
+		return recorder.Code, report
+	}
+
+	code, report := get("/debug/usage")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "2024-05", report.Month)
+	require.Equal(t, map[string]Usage{
+		"token:aaa": {APICalls: 1, RelationshipsRead: 5},
+		"token:bbb": {APICalls: 2},
+	}, report.Usage)
+	require.Equal(t, Quota{RelationshipsRead: 100}, report.Quota)
+	require.Equal(t, []string{"2024-05"}, report.AvailableMonths)
+
+	_, report = get("/debug/usage?caller=token:bbb")
+	require.Equal(t, map[string]Usage{"token:bbb": {APICalls: 2}}, report.Usage)
+
+	_, report = get("/debug/usage?month=2024-04")
+	require.Empty(t, report.Usage)
+
+	code, _ = get("/debug/usage?month=may")
+	require.Equal(t, http.StatusBadRequest, code)
+
+	recorder := httptest.NewRecorder()
+	meter.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/debug/usage", nil))
+	require.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+}
@@ -0,0 +1,111 @@
+// Package msgsize provides interceptors enforcing per-method maximum sizes of received messages.
+package msgsize
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// Limits are the maximum sizes, in bytes, of the messages received by gRPC methods.
+type Limits struct {
+	// Default is the maximum size of messages received by methods without a specific limit.
+	Default int
+
+	// PerMethod are the maximum sizes of messages received by specific methods, keyed by full
+	// method name (e.g. `/authzed.api.v1.PermissionsService/CheckPermission`) or by service name
+	// (e.g. `authzed.api.v1.PermissionsService`) to apply to all methods of the service.
+	PerMethod map[string]int
+}
+
+// NewLimits returns the limits for the given default and per-method maximum sizes, validating
+// them. A leading slash is optional in the per-method keys.
+func NewLimits(defaultLimit int, perMethod map[string]int) (Limits, error) {
+	if defaultLimit <= 0 {
+		return Limits{}, fmt.Errorf("maximum received message size must be positive, found %d", defaultLimit)
+	}
+
+	normalized := make(map[string]int, len(perMethod))
+	for method, limit := range perMethod {
+		if limit <= 0 {
+			return Limits{}, fmt.Errorf("maximum received message size for `%s` must be positive, found %d", method, limit)
+		}
+		normalized[strings.TrimPrefix(method, "/")] = limit
+	}
+
+	return Limits{Default: defaultLimit, PerMethod: normalized}, nil
+}
+
+// Max returns the largest of the limits, which must be used as the transport-level limit of the
+// server so that messages are only rejected by the interceptors.
+func (l Limits) Max() int {
+	largest := l.Default
+	for _, limit := range l.PerMethod {
+		largest = max(largest, limit)
+	}
+	return largest
+}
+
+// ForMethod returns the maximum size of messages received by the given full method name.
+func (l Limits) ForMethod(fullMethod string) int {
+	fullMethod = strings.TrimPrefix(fullMethod, "/")
+	if limit, ok := l.PerMethod[fullMethod]; ok {
+		return limit
+	}
+
+	service, _, _ := strings.Cut(fullMethod, "/")
+	if limit, ok := l.PerMethod[service]; ok {
+		return limit
+	}
+
+	return l.Default
+}
+
+func checkSize(m any, limit int) error {
+	msg, ok := m.(proto.Message)
+	if !ok {
+		return nil
+	}
+
+	if size := proto.Size(msg); size > limit {
+		return status.Errorf(codes.ResourceExhausted, "received message larger than max for method (%d vs. %d)", size, limit)
+	}
+	return nil
+}
+
+// UnaryServerInterceptor returns a new unary server interceptor rejecting requests larger than
+// the limit of their method.
+func UnaryServerInterceptor(limits Limits) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := checkSize(req, limits.ForMethod(info.FullMethod)); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns a new stream server interceptor rejecting received messages
+// larger than the limit of their method.
+func StreamServerInterceptor(limits Limits) grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &recvWrapper{stream, limits.ForMethod(info.FullMethod)})
+	}
+}
+
+type recvWrapper struct {
+	grpc.ServerStream
+
+	limit int
+}
+
+func (s *recvWrapper) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	return checkSize(m, s.limit)
+}
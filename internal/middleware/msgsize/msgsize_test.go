@@ -0,0 +1,78 @@
+package msgsize
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestLimits(t *testing.T) {
+	_, err := NewLimits(0, nil)
+	require.ErrorContains(t, err, "must be positive")
+
+	_, err = NewLimits(1024, map[string]int{"authzed.api.v1.PermissionsService": -1})
+	require.ErrorContains(t, err, "authzed.api.v1.PermissionsService")
+
+	limits, err := NewLimits(1024, map[string]int{
+		"/authzed.api.v1.ExperimentalService/BulkImportRelationships": 64 * 1024,
+		"authzed.api.v1.PermissionsService":                           2048,
+		"authzed.api.v1.PermissionsService/CheckPermission":           512,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, 64*1024, limits.Max())
+	require.Equal(t, 64*1024, limits.ForMethod("/authzed.api.v1.ExperimentalService/BulkImportRelationships"))
+	require.Equal(t, 1024, limits.ForMethod("/authzed.api.v1.ExperimentalService/BulkExportRelationships"))
+	require.Equal(t, 512, limits.ForMethod("/authzed.api.v1.PermissionsService/CheckPermission"))
+	require.Equal(t, 2048, limits.ForMethod("/authzed.api.v1.PermissionsService/LookupResources"))
+	require.Equal(t, 1024, limits.ForMethod("/authzed.api.v1.SchemaService/ReadSchema"))
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	limits, err := NewLimits(1024, map[string]int{"authzed.api.v1.SchemaService/WriteSchema": 64})
+	require.NoError(t, err)
+
+	interceptor := UnaryServerInterceptor(limits)
+	handler := func(ctx context.Context, req any) (any, error) { return req, nil }
+
+	req := &v1.WriteSchemaRequest{Schema: strings.Repeat("a", 100)}
+	_, err = interceptor(context.Background(), req, &grpc.UnaryServerInfo{FullMethod: "/authzed.api.v1.SchemaService/WriteSchema"}, handler)
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	_, err = interceptor(context.Background(), req, &grpc.UnaryServerInfo{FullMethod: "/authzed.api.v1.SchemaService/ReadSchema"}, handler)
+	require.NoError(t, err)
+}
+
+type fakeServerStream struct {
+	grpc.ServerStream
+
+	schema string
+}
+
+func (s *fakeServerStream) RecvMsg(m any) error {
+	m.(*v1.WriteSchemaRequest).Schema = s.schema
+	return nil
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	limits, err := NewLimits(64, nil)
+	require.NoError(t, err)
+
+	interceptor := StreamServerInterceptor(limits)
+	info := &grpc.StreamServerInfo{FullMethod: "/authzed.api.v1.ExperimentalService/BulkImportRelationships"}
+	recv := func(srv any, stream grpc.ServerStream) error {
+		return stream.RecvMsg(&v1.WriteSchemaRequest{})
+	}
+
+	err = interceptor(nil, &fakeServerStream{schema: "small"}, info, recv)
+	require.NoError(t, err)
+
+	err = interceptor(nil, &fakeServerStream{schema: strings.Repeat("a", 100)}, info, recv)
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
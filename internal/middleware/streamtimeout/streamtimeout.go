@@ -42,8 +42,9 @@ func (s *sendWrapper) Context() context.Context {
 	return s.ctx
 }
 
-func (s *sendWrapper) SetTrailer(_ metadata.MD) {
+func (s *sendWrapper) SetTrailer(md metadata.MD) {
 	s.timer.Stop()
+	s.ServerStream.SetTrailer(md)
 }
 
 func (s *sendWrapper) SendMsg(m any) error {
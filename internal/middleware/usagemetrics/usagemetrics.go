@@ -13,6 +13,7 @@ import (
 	"google.golang.org/grpc"
 
 	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/internal/middleware/metering"
 	dispatch "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
 )
 
@@ -97,7 +98,11 @@ type metaHandle struct{ metadata *dispatch.ResponseMeta }
 
 // SetInContext should be called in a gRPC handler to correctly set the response metadata
 // for the dispatched request.
+//
+// The dispatches of the request are also recorded into its metered usage, if it is metered.
 func SetInContext(ctx context.Context, metadata *dispatch.ResponseMeta) {
+	metering.RecordDispatchedSubproblems(ctx, metadata.GetDispatchCount())
+
 	possibleHandle := ctx.Value(metadataCtxKey)
 	if possibleHandle == nil {
 		return
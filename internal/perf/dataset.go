@@ -0,0 +1,176 @@
+// Package perf implements reproducible load testing of a SpiceDB instance, populating it with a
+// synthetic schema and relationships and driving a configurable mix of traffic against it.
+package perf
+
+import (
+	"math/rand/v2"
+	"strconv"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+
+	"github.com/authzed/spicedb/pkg/genutil/mapz"
+)
+
+// Schema is the synthetic schema used for load testing, modeling documents shared with users and
+// groups and nested within a hierarchy of folders.
+const Schema = `definition perf/user {}
+
+definition perf/group {
+	relation member: perf/user
+}
+
+definition perf/folder {
+	relation parent: perf/folder
+	relation viewer: perf/user | perf/group#member
+	permission view = viewer + parent->view
+}
+
+definition perf/document {
+	relation folder: perf/folder
+	relation viewer: perf/user | perf/group#member
+	relation editor: perf/user
+	relation banned: perf/user
+	permission edit = editor - banned
+	permission view = (viewer + edit + folder->view) - banned
+}`
+
+const (
+	userType     = "perf/user"
+	groupType    = "perf/group"
+	folderType   = "perf/folder"
+	documentType = "perf/document"
+
+	// folderFanout is the number of child folders of each folder in the folder hierarchy.
+	folderFanout = 4
+)
+
+// DatasetConfig is the configuration of the synthetic relationships used for load testing.
+type DatasetConfig struct {
+	// Seed is the seed for all random generation, making datasets and traffic reproducible.
+	Seed uint64
+
+	// Users is the number of users.
+	Users uint64
+
+	// Groups is the number of groups.
+	Groups uint64
+
+	// Folders is the number of folders, arranged into a hierarchy.
+	Folders uint64
+
+	// Documents is the number of documents.
+	Documents uint64
+
+	// MembersPerGroup is the number of members of each group.
+	MembersPerGroup uint64
+
+	// RelationshipsPerDocument is the average number of viewer, editor and banned relationships on
+	// each document.
+	RelationshipsPerDocument uint64
+
+	// ZipfExponent is the exponent of the Zipfian distribution used to select users, groups and
+	// documents. Must be greater than 1; larger values skew popularity towards fewer objects.
+	ZipfExponent float64
+}
+
+// generator generates random objects and relationships following the configured distributions.
+type generator struct {
+	config DatasetConfig
+	rng    *rand.Rand
+
+	users     *rand.Zipf
+	groups    *rand.Zipf
+	documents *rand.Zipf
+}
+
+func newGenerator(config DatasetConfig, stream uint64) *generator {
+	rng := rand.New(rand.NewPCG(config.Seed, stream))
+	return &generator{
+		config:    config,
+		rng:       rng,
+		users:     rand.NewZipf(rng, config.ZipfExponent, 1, max(config.Users, 1)-1),
+		groups:    rand.NewZipf(rng, config.ZipfExponent, 1, max(config.Groups, 1)-1),
+		documents: rand.NewZipf(rng, config.ZipfExponent, 1, max(config.Documents, 1)-1),
+	}
+}
+
+func objectID(index uint64) string {
+	return strconv.FormatUint(index, 10)
+}
+
+func (g *generator) user() *v1.SubjectReference {
+	return &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: userType, ObjectId: objectID(g.users.Uint64())}}
+}
+
+func (g *generator) groupMember() *v1.SubjectReference {
+	return &v1.SubjectReference{
+		Object:           &v1.ObjectReference{ObjectType: groupType, ObjectId: objectID(g.groups.Uint64())},
+		OptionalRelation: "member",
+	}
+}
+
+func (g *generator) document() *v1.ObjectReference {
+	return &v1.ObjectReference{ObjectType: documentType, ObjectId: objectID(g.documents.Uint64())}
+}
+
+func (g *generator) folder() *v1.ObjectReference {
+	return &v1.ObjectReference{ObjectType: folderType, ObjectId: objectID(g.rng.Uint64N(max(g.config.Folders, 1)))}
+}
+
+// documentSubject returns a random subject for a document, a group's members one time in five.
+func (g *generator) documentSubject() *v1.SubjectReference {
+	if g.config.Groups > 0 && g.rng.IntN(5) == 0 {
+		return g.groupMember()
+	}
+	return g.user()
+}
+
+// relationships returns the full set of relationships in the dataset, without duplicates.
+func (g *generator) relationships() []*v1.Relationship {
+	seen := mapz.NewSet[string]()
+	rels := make([]*v1.Relationship, 0, g.config.Groups*g.config.MembersPerGroup+g.config.Folders*2+g.config.Documents*(g.config.RelationshipsPerDocument+1))
+	add := func(resource *v1.ObjectReference, relation string, subject *v1.SubjectReference) {
+		rel := &v1.Relationship{Resource: resource, Relation: relation, Subject: subject}
+		key := resource.ObjectType + ":" + resource.ObjectId + "#" + relation + "@" +
+			subject.Object.ObjectType + ":" + subject.Object.ObjectId + "#" + subject.OptionalRelation
+		if seen.Add(key) {
+			rels = append(rels, rel)
+		}
+	}
+
+	for group := range g.config.Groups {
+		for range g.config.MembersPerGroup {
+			add(&v1.ObjectReference{ObjectType: groupType, ObjectId: objectID(group)}, "member", g.user())
+		}
+	}
+
+	for folder := range g.config.Folders {
+		resource := &v1.ObjectReference{ObjectType: folderType, ObjectId: objectID(folder)}
+		if folder > 0 {
+			add(resource, "parent", &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: folderType, ObjectId: objectID((folder - 1) / folderFanout)}})
+		}
+		add(resource, "viewer", g.documentSubject())
+	}
+
+	for document := range g.config.Documents {
+		resource := &v1.ObjectReference{ObjectType: documentType, ObjectId: objectID(document)}
+		if g.config.Folders > 0 {
+			add(resource, "folder", &v1.SubjectReference{Object: g.folder()})
+		}
+	}
+
+	// Viewer, editor and banned relationships are distributed over documents by popularity, so that
+	// the most popular documents are shared most widely.
+	for range g.config.Documents * g.config.RelationshipsPerDocument {
+		switch n := g.rng.IntN(20); {
+		case n == 0:
+			add(g.document(), "banned", g.user())
+		case n < 3:
+			add(g.document(), "editor", g.user())
+		default:
+			add(g.document(), "viewer", g.documentSubject())
+		}
+	}
+
+	return rels
+}
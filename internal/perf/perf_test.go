@@ -0,0 +1,101 @@
+package perf
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/internal/testserver"
+)
+
+var testDataset = DatasetConfig{
+	Seed:                     42,
+	Users:                    100,
+	Groups:                   10,
+	Folders:                  20,
+	Documents:                100,
+	MembersPerGroup:          5,
+	RelationshipsPerDocument: 3,
+	ZipfExponent:             1.5,
+}
+
+func TestRelationshipsAreReproducible(t *testing.T) {
+	first := newGenerator(testDataset, 0).relationships()
+	second := newGenerator(testDataset, 0).relationships()
+	require.NotEmpty(t, first)
+	require.Equal(t, len(first), len(second))
+	for i := range first {
+		require.True(t, first[i].EqualVT(second[i]))
+	}
+
+	otherSeed := testDataset
+	otherSeed.Seed = 43
+	third := newGenerator(otherSeed, 0).relationships()
+	require.False(t, len(first) == len(third) && first[len(first)-1].EqualVT(third[len(third)-1]))
+}
+
+func TestZipfianPopularity(t *testing.T) {
+	g := newGenerator(testDataset, 1)
+	counts := make(map[string]int)
+	for range 10_000 {
+		counts[g.document().ObjectId]++
+	}
+
+	require.Greater(t, counts["0"], counts["50"]*10)
+}
+
+func TestPercentile(t *testing.T) {
+	var latencies []time.Duration
+	for i := 1; i <= 100; i++ {
+		latencies = append(latencies, time.Duration(i)*time.Millisecond)
+	}
+
+	require.Equal(t, time.Duration(0), percentile(nil, 0.5))
+	require.Equal(t, 50*time.Millisecond, percentile(latencies, 0.5))
+	require.Equal(t, 99*time.Millisecond, percentile(latencies, 0.99))
+	require.Equal(t, 100*time.Millisecond, percentile(latencies, 1))
+}
+
+func TestRun(t *testing.T) {
+	conn, cleanup, _, _ := testserver.NewTestServer(require.New(t), 0, time.Hour, true, testfixtures.EmptyDatastore)
+	t.Cleanup(cleanup)
+
+	config := Config{
+		Dataset:               testDataset,
+		Workers:               4,
+		Duration:              500 * time.Millisecond,
+		CheckWeight:           1,
+		LookupResourcesWeight: 1,
+		WriteWeight:           1,
+	}
+
+	report, err := Run(context.Background(), conn, config)
+	require.NoError(t, err)
+	require.Equal(t, len(newGenerator(testDataset, 0).relationships()), report.RelationshipsLoaded)
+
+	for _, op := range []Operation{OperationCheck, OperationLookupResources, OperationWrite} {
+		require.Contains(t, report.Operations, op)
+		require.Positive(t, report.Operations[op].Count)
+		require.Zero(t, report.Operations[op].Errors)
+		require.LessOrEqual(t, report.Operations[op].P50Millis, report.Operations[op].MaxMillis)
+	}
+
+	// Running again against the loaded dataset succeeds.
+	config.SkipLoad = true
+	report, err = Run(context.Background(), conn, config)
+	require.NoError(t, err)
+	require.Zero(t, report.RelationshipsLoaded)
+}
+
+func TestRunValidation(t *testing.T) {
+	_, err := Run(context.Background(), nil, Config{Dataset: testDataset, Duration: time.Second, CheckWeight: 1})
+	require.ErrorContains(t, err, "worker")
+
+	invalidZipf := testDataset
+	invalidZipf.ZipfExponent = 1
+	_, err = Run(context.Background(), nil, Config{Dataset: invalidZipf, Workers: 1, Duration: time.Second, CheckWeight: 1})
+	require.ErrorContains(t, err, "zipf")
+}
@@ -0,0 +1,347 @@
+package perf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"google.golang.org/grpc"
+
+	log "github.com/authzed/spicedb/internal/logging"
+)
+
+// Operation is a kind of request sent during load testing.
+type Operation string
+
+const (
+	// OperationCheck is a CheckPermission request for the `view` permission of a document.
+	OperationCheck Operation = "check"
+
+	// OperationLookupResources is a LookupResources request for the documents a user can `view`.
+	OperationLookupResources Operation = "lookup_resources"
+
+	// OperationWrite is a WriteRelationships request touching or deleting a document viewer.
+	OperationWrite Operation = "write"
+)
+
+// writeBatchSize is the number of relationships written per request when loading the dataset.
+const writeBatchSize = 1000
+
+// Config is the configuration of a load test.
+type Config struct {
+	// Dataset is the configuration of the synthetic dataset.
+	Dataset DatasetConfig
+
+	// SkipLoad, if true, skips writing the schema and dataset, which must already have been loaded
+	// using the same dataset configuration.
+	SkipLoad bool
+
+	// Workers is the number of concurrent workers sending requests.
+	Workers uint
+
+	// Duration is the duration for which traffic is sent.
+	Duration time.Duration
+
+	// CheckWeight, LookupResourcesWeight and WriteWeight are the relative weights of each
+	// operation in the traffic mix.
+	CheckWeight           uint
+	LookupResourcesWeight uint
+	WriteWeight           uint
+
+	// FullyConsistent, if true, sends reads with full consistency instead of minimizing latency.
+	FullyConsistent bool
+}
+
+func (c Config) validate() error {
+	if c.Workers == 0 {
+		return errors.New("at least one worker is required")
+	}
+
+	if c.Duration <= 0 {
+		return errors.New("duration must be positive")
+	}
+
+	if c.CheckWeight+c.LookupResourcesWeight+c.WriteWeight == 0 {
+		return errors.New("at least one operation must have a non-zero weight")
+	}
+
+	if c.Dataset.ZipfExponent <= 1 {
+		return fmt.Errorf("zipf exponent must be greater than 1, found %v", c.Dataset.ZipfExponent)
+	}
+
+	if c.Dataset.Users == 0 || c.Dataset.Documents == 0 {
+		return errors.New("at least one user and one document are required")
+	}
+
+	return nil
+}
+
+// OperationReport is the result of sending a single kind of operation.
+type OperationReport struct {
+	// Count is the number of requests which completed successfully.
+	Count uint64 `json:"count"`
+
+	// Errors is the number of requests which failed.
+	Errors uint64 `json:"errors"`
+
+	// Throughput is the number of successful requests per second.
+	Throughput float64 `json:"throughputPerSecond"`
+
+	// P50Millis, P90Millis, P95Millis, P99Millis and MaxMillis are percentiles of the latency of
+	// successful requests, in milliseconds.
+	P50Millis float64 `json:"p50Ms"`
+	P90Millis float64 `json:"p90Ms"`
+	P95Millis float64 `json:"p95Ms"`
+	P99Millis float64 `json:"p99Ms"`
+	MaxMillis float64 `json:"maxMs"`
+}
+
+// Report is the result of a load test.
+type Report struct {
+	// Seed is the seed used for the dataset and traffic.
+	Seed uint64 `json:"seed"`
+
+	// RelationshipsLoaded is the number of relationships written when loading the dataset.
+	RelationshipsLoaded int `json:"relationshipsLoaded"`
+
+	// DurationSeconds is the duration for which traffic was sent, in seconds.
+	DurationSeconds float64 `json:"durationSeconds"`
+
+	// Operations is the report for each kind of operation sent.
+	Operations map[Operation]*OperationReport `json:"operations"`
+}
+
+// Run loads the synthetic dataset into the SpiceDB instance behind the connection and then drives
+// the configured traffic against it, returning a report of the latency of each operation.
+func Run(ctx context.Context, conn grpc.ClientConnInterface, config Config) (*Report, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+
+	report := &Report{
+		Seed:       config.Dataset.Seed,
+		Operations: map[Operation]*OperationReport{},
+	}
+
+	permissions := v1.NewPermissionsServiceClient(conn)
+	if !config.SkipLoad {
+		loaded, err := load(ctx, v1.NewSchemaServiceClient(conn), permissions, config.Dataset)
+		if err != nil {
+			return nil, err
+		}
+		report.RelationshipsLoaded = loaded
+	}
+
+	trafficCtx, cancel := context.WithTimeout(ctx, config.Duration)
+	defer cancel()
+
+	workers := make([]*worker, 0, config.Workers)
+	var wg sync.WaitGroup
+	start := time.Now()
+	for i := range config.Workers {
+		// Stream zero is used by the dataset, so each worker uses its own subsequent stream.
+		w := &worker{
+			config:      config,
+			permissions: permissions,
+			generator:   newGenerator(config.Dataset, uint64(i)+1),
+			latencies:   map[Operation][]time.Duration{},
+			errors:      map[Operation]uint64{},
+		}
+		workers = append(workers, w)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.run(trafficCtx)
+		}()
+	}
+	wg.Wait()
+
+	elapsed := time.Since(start)
+	report.DurationSeconds = elapsed.Seconds()
+
+	for _, op := range []Operation{OperationCheck, OperationLookupResources, OperationWrite} {
+		var latencies []time.Duration
+		var errorCount uint64
+		for _, w := range workers {
+			latencies = append(latencies, w.latencies[op]...)
+			errorCount += w.errors[op]
+		}
+
+		if len(latencies) == 0 && errorCount == 0 {
+			continue
+		}
+
+		report.Operations[op] = newOperationReport(latencies, errorCount, elapsed)
+	}
+
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	return report, nil
+}
+
+// load writes the schema and dataset, returning the number of relationships written.
+func load(ctx context.Context, schema v1.SchemaServiceClient, permissions v1.PermissionsServiceClient, config DatasetConfig) (int, error) {
+	if _, err := schema.WriteSchema(ctx, &v1.WriteSchemaRequest{Schema: Schema}); err != nil {
+		return 0, fmt.Errorf("failed to write schema: %w", err)
+	}
+
+	rels := newGenerator(config, 0).relationships()
+	for batch := range slices.Chunk(rels, writeBatchSize) {
+		updates := make([]*v1.RelationshipUpdate, 0, len(batch))
+		for _, rel := range batch {
+			updates = append(updates, &v1.RelationshipUpdate{
+				Operation:    v1.RelationshipUpdate_OPERATION_TOUCH,
+				Relationship: rel,
+			})
+		}
+
+		if _, err := permissions.WriteRelationships(ctx, &v1.WriteRelationshipsRequest{Updates: updates}); err != nil {
+			return 0, fmt.Errorf("failed to write relationships: %w", err)
+		}
+	}
+
+	log.Ctx(ctx).Info().Int("relationships", len(rels)).Msg("loaded load testing dataset")
+	return len(rels), nil
+}
+
+type worker struct {
+	config      Config
+	permissions v1.PermissionsServiceClient
+	generator   *generator
+
+	latencies map[Operation][]time.Duration
+	errors    map[Operation]uint64
+}
+
+func (w *worker) run(ctx context.Context) {
+	for ctx.Err() == nil {
+		op := w.nextOperation()
+
+		start := time.Now()
+		err := w.send(ctx, op)
+		duration := time.Since(start)
+
+		// Requests interrupted by the end of the test are not recorded.
+		if ctx.Err() != nil {
+			return
+		}
+
+		if err != nil {
+			log.Ctx(ctx).Debug().Err(err).Str("operation", string(op)).Msg("load testing request failed")
+			w.errors[op]++
+			continue
+		}
+
+		w.latencies[op] = append(w.latencies[op], duration)
+	}
+}
+
+func (w *worker) nextOperation() Operation {
+	n := w.generator.rng.UintN(w.config.CheckWeight + w.config.LookupResourcesWeight + w.config.WriteWeight)
+	switch {
+	case n < w.config.CheckWeight:
+		return OperationCheck
+	case n < w.config.CheckWeight+w.config.LookupResourcesWeight:
+		return OperationLookupResources
+	default:
+		return OperationWrite
+	}
+}
+
+func (w *worker) consistency() *v1.Consistency {
+	if w.config.FullyConsistent {
+		return &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}}
+	}
+	return &v1.Consistency{Requirement: &v1.Consistency_MinimizeLatency{MinimizeLatency: true}}
+}
+
+func (w *worker) send(ctx context.Context, op Operation) error {
+	switch op {
+	case OperationCheck:
+		_, err := w.permissions.CheckPermission(ctx, &v1.CheckPermissionRequest{
+			Consistency: w.consistency(),
+			Resource:    w.generator.document(),
+			Permission:  "view",
+			Subject:     w.generator.user(),
+		})
+		return err
+
+	case OperationLookupResources:
+		stream, err := w.permissions.LookupResources(ctx, &v1.LookupResourcesRequest{
+			Consistency:        w.consistency(),
+			ResourceObjectType: documentType,
+			Permission:         "view",
+			Subject:            w.generator.user(),
+		})
+		if err != nil {
+			return err
+		}
+
+		for {
+			if _, err := stream.Recv(); err != nil {
+				if errors.Is(err, io.EOF) {
+					return nil
+				}
+				return err
+			}
+		}
+
+	case OperationWrite:
+		// Viewers are touched and deleted with equal probability, keeping the size of the dataset stable.
+		operation := v1.RelationshipUpdate_OPERATION_TOUCH
+		if w.generator.rng.IntN(2) == 0 {
+			operation = v1.RelationshipUpdate_OPERATION_DELETE
+		}
+
+		_, err := w.permissions.WriteRelationships(ctx, &v1.WriteRelationshipsRequest{
+			Updates: []*v1.RelationshipUpdate{{
+				Operation: operation,
+				Relationship: &v1.Relationship{
+					Resource: w.generator.document(),
+					Relation: "viewer",
+					Subject:  w.generator.documentSubject(),
+				},
+			}},
+		})
+		return err
+
+	default:
+		return fmt.Errorf("unknown operation %q", op)
+	}
+}
+
+func newOperationReport(latencies []time.Duration, errorCount uint64, elapsed time.Duration) *OperationReport {
+	slices.Sort(latencies)
+	return &OperationReport{
+		Count:      uint64(len(latencies)),
+		Errors:     errorCount,
+		Throughput: float64(len(latencies)) / elapsed.Seconds(),
+		P50Millis:  millis(percentile(latencies, 0.5)),
+		P90Millis:  millis(percentile(latencies, 0.9)),
+		P95Millis:  millis(percentile(latencies, 0.95)),
+		P99Millis:  millis(percentile(latencies, 0.99)),
+		MaxMillis:  millis(percentile(latencies, 1)),
+	}
+}
+
+// percentile returns the given percentile of the sorted latencies, using the nearest-rank method.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	rank := int(math.Ceil(p * float64(len(sorted))))
+	return sorted[max(rank, 1)-1]
+}
+
+func millis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
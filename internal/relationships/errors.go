@@ -26,6 +26,12 @@ type InvalidSubjectTypeError struct {
 	relationship      tuple.Relationship
 	relationType      *core.AllowedRelation
 	additionalDetails map[string]string
+
+	// allowedSubjectTypes are the subject types allowed on the relation, as found in the schema.
+	allowedSubjectTypes []string
+
+	// allowedCaveats are the caveats allowed on the relation for the type of the subject.
+	allowedCaveats []string
 }
 
 // NewInvalidSubjectTypeError constructs a new error for attempting to write an invalid subject type.
@@ -39,6 +45,22 @@ func NewInvalidSubjectTypeError(
 		return err
 	}
 
+	allowedTypeStrings := make([]string, 0, len(allowedTypes))
+	allowedCaveats := mapz.NewSet[string]()
+	for _, allowedType := range allowedTypes {
+		allowedTypeStrings = append(allowedTypeStrings, typesystem.SourceForAllowedRelation(allowedType))
+
+		if allowedType.RequiredCaveat != nil &&
+			allowedType.RequiredCaveat.CaveatName != "" &&
+			allowedType.Namespace == relationship.Subject.ObjectType &&
+			allowedType.GetRelation() == relationship.Subject.Relation {
+			allowedCaveats.Add(allowedType.RequiredCaveat.CaveatName)
+		}
+	}
+
+	allowedCaveatsSlice := allowedCaveats.AsSlice()
+	sort.Strings(allowedCaveatsSlice)
+
 	// Special case: if the subject is uncaveated but only a caveated version is allowed, return
 	// a more descriptive error.
 	if relationship.OptionalCaveat == nil {
@@ -68,15 +90,12 @@ func NewInvalidSubjectTypeError(
 				additionalDetails: map[string]string{
 					"allowed_caveats": strings.Join(allowedCaveatsForSubject.AsSlice(), ","),
 				},
+				allowedSubjectTypes: allowedTypeStrings,
+				allowedCaveats:      allowedCaveatsSlice,
 			}
 		}
 	}
 
-	allowedTypeStrings := make([]string, 0, len(allowedTypes))
-	for _, allowedType := range allowedTypes {
-		allowedTypeStrings = append(allowedTypeStrings, typesystem.SourceForAllowedRelation(allowedType))
-	}
-
 	matches := fuzzy.RankFind(typesystem.SourceForAllowedRelation(relationType), allowedTypeStrings)
 	sort.Sort(matches)
 	if len(matches) > 0 {
@@ -88,9 +107,11 @@ func NewInvalidSubjectTypeError(
 				relationship.Resource.Relation,
 				matches[0].Target,
 			),
-			relationship:      relationship,
-			relationType:      relationType,
-			additionalDetails: nil,
+			relationship:        relationship,
+			relationType:        relationType,
+			additionalDetails:   nil,
+			allowedSubjectTypes: allowedTypeStrings,
+			allowedCaveats:      allowedCaveatsSlice,
 		}
 	}
 
@@ -101,9 +122,21 @@ func NewInvalidSubjectTypeError(
 			relationship.Resource.ObjectType,
 			relationship.Resource.Relation,
 		),
-		relationship:      relationship,
-		relationType:      relationType,
-		additionalDetails: nil,
+		relationship:        relationship,
+		relationType:        relationType,
+		additionalDetails:   nil,
+		allowedSubjectTypes: allowedTypeStrings,
+		allowedCaveats:      allowedCaveatsSlice,
+	}
+}
+
+// ConformanceDetails returns the details of what the relation allows, for reporting the
+// relationships which do not conform to the schema: the allowed subject types and the caveats
+// allowed for the type of the subject, each comma-separated.
+func (err InvalidSubjectTypeError) ConformanceDetails() map[string]string {
+	return map[string]string{
+		"allowed_subject_types": strings.Join(err.allowedSubjectTypes, ","),
+		"allowed_caveats":       strings.Join(err.allowedCaveats, ","),
 	}
 }
 
@@ -35,17 +35,7 @@ func ValidateRelationshipUpdates(
 
 	// Validate each updates's types.
 	for _, update := range updates {
-		option := ValidateRelationshipForCreateOrTouch
-		if update.Operation == tuple.UpdateOperationDelete {
-			option = ValidateRelationshipForDeletion
-		}
-
-		if err := ValidateOneRelationship(
-			referencedNamespaceMap,
-			referencedCaveatMap,
-			update.Relationship,
-			option,
-		); err != nil {
+		if err := validateOneUpdate(referencedNamespaceMap, referencedCaveatMap, update); err != nil {
 			return err
 		}
 	}
@@ -53,6 +43,52 @@ func ValidateRelationshipUpdates(
 	return nil
 }
 
+// InvalidUpdate is a relationship update which cannot be applied, with its index in the updates.
+type InvalidUpdate struct {
+	Index int
+	Err   error
+}
+
+// FindInvalidRelationshipUpdates validates all of the given relationship updates, returning each
+// one which cannot be applied against the datastore instead of stopping at the first.
+func FindInvalidRelationshipUpdates(
+	ctx context.Context,
+	reader datastore.Reader,
+	updates []tuple.RelationshipUpdate,
+) ([]InvalidUpdate, error) {
+	rels := lo.Map(updates, func(item tuple.RelationshipUpdate, _ int) tuple.Relationship {
+		return item.Relationship
+	})
+
+	// Load namespaces and caveats.
+	referencedNamespaceMap, referencedCaveatMap, err := loadNamespacesAndCaveats(ctx, rels, reader)
+	if err != nil {
+		return nil, err
+	}
+
+	var invalid []InvalidUpdate
+	for index, update := range updates {
+		if err := validateOneUpdate(referencedNamespaceMap, referencedCaveatMap, update); err != nil {
+			invalid = append(invalid, InvalidUpdate{Index: index, Err: err})
+		}
+	}
+
+	return invalid, nil
+}
+
+func validateOneUpdate(
+	namespaceMap map[string]*typesystem.TypeSystem,
+	caveatMap map[string]*core.CaveatDefinition,
+	update tuple.RelationshipUpdate,
+) error {
+	option := ValidateRelationshipForCreateOrTouch
+	if update.Operation == tuple.UpdateOperationDelete {
+		option = ValidateRelationshipForDeletion
+	}
+
+	return ValidateOneRelationship(namespaceMap, caveatMap, update.Relationship, option)
+}
+
 // ValidateRelationshipsForCreateOrTouch performs validation on the given relationships to be written, ensuring that
 // they can be applied against the datastore.
 //
@@ -0,0 +1,352 @@
+// Package replay re-executes the requests captured by the capture middleware against a SpiceDB
+// instance, comparing their results and latencies with those captured.
+package replay
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/internal/middleware/capture"
+)
+
+// maxRecordSize is the maximum size of a line of the capture file.
+const maxRecordSize = 16 * 1024 * 1024
+
+// Config is the configuration of a replay.
+type Config struct {
+	// File is the capture file whose requests are replayed.
+	File string
+
+	// Workers is the number of concurrent workers replaying requests.
+	Workers uint
+
+	// AtCapturedRevision, if true, replays each request at the exact revision at which it was
+	// captured, which is only possible against the datastore on which it was captured and within
+	// its GC window. Otherwise, requests are replayed with full consistency.
+	AtCapturedRevision bool
+
+	// MaxDiffs is the maximum number of differing results reported.
+	MaxDiffs uint
+}
+
+func (c Config) validate() error {
+	if c.File == "" {
+		return errors.New("a capture file is required")
+	}
+
+	if c.Workers == 0 {
+		return errors.New("at least one worker is required")
+	}
+
+	return nil
+}
+
+// MethodReport is the result of replaying the requests of a single gRPC method.
+type MethodReport struct {
+	// Count is the number of requests replayed.
+	Count uint64 `json:"count"`
+
+	// Mismatches is the number of requests whose results differ from those captured.
+	Mismatches uint64 `json:"mismatches"`
+
+	// CapturedP50Millis, CapturedP99Millis, ReplayedP50Millis and ReplayedP99Millis are
+	// percentiles of the latency of the requests when captured and when replayed, in milliseconds.
+	CapturedP50Millis float64 `json:"capturedP50Ms"`
+	CapturedP99Millis float64 `json:"capturedP99Ms"`
+	ReplayedP50Millis float64 `json:"replayedP50Ms"`
+	ReplayedP99Millis float64 `json:"replayedP99Ms"`
+}
+
+// Diff is a request whose replayed results differ from those captured.
+type Diff struct {
+	// Line is the line of the request in the capture file, starting at 1.
+	Line int `json:"line"`
+
+	// Method is the full name of the gRPC method of the request.
+	Method string `json:"method"`
+
+	// Request is the request, as captured.
+	Request json.RawMessage `json:"request"`
+
+	// Captured and Replayed are the results of the request when captured and when replayed.
+	Captured capture.Result `json:"captured"`
+	Replayed capture.Result `json:"replayed"`
+}
+
+// Report is the result of a replay.
+type Report struct {
+	// Replayed is the number of requests replayed.
+	Replayed uint64 `json:"replayed"`
+
+	// Mismatches is the number of requests whose results differ from those captured.
+	Mismatches uint64 `json:"mismatches"`
+
+	// Methods is the report for each gRPC method replayed.
+	Methods map[string]*MethodReport `json:"methods"`
+
+	// Diffs are the first requests, in the order of the capture file, whose results differ from
+	// those captured, up to the configured maximum.
+	Diffs []Diff `json:"diffs"`
+}
+
+// replayed is the outcome of replaying a single request.
+type replayed struct {
+	line    int
+	record  capture.Record
+	result  capture.Result
+	latency time.Duration
+}
+
+// Run replays the requests of the capture file against the SpiceDB instance behind the
+// connection, returning a report comparing their results and latencies with those captured.
+func Run(ctx context.Context, conn grpc.ClientConnInterface, config Config) (*Report, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(config.File)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open capture file: %w", err)
+	}
+	defer file.Close()
+
+	type job struct {
+		line   int
+		record capture.Record
+	}
+
+	jobs := make(chan job)
+	var lock sync.Mutex
+	var outcomes []replayed
+	var wg sync.WaitGroup
+	for range config.Workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				outcome := replay(ctx, conn, config, j.line, j.record)
+				lock.Lock()
+				outcomes = append(outcomes, outcome)
+				lock.Unlock()
+			}
+		}()
+	}
+
+	readErr := read(ctx, file, func(line int, record capture.Record) {
+		jobs <- job{line, record}
+	})
+	close(jobs)
+	wg.Wait()
+
+	if readErr != nil {
+		return nil, readErr
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	return newReport(outcomes, config.MaxDiffs), nil
+}
+
+// read calls the function with each record of the capture file, in order.
+func read(ctx context.Context, r io.Reader, fn func(line int, record capture.Record)) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxRecordSize)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var record capture.Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return fmt.Errorf("invalid record on line %d of capture file: %w", line, err)
+		}
+
+		if _, ok := capture.MethodFor(record.Method); !ok {
+			return fmt.Errorf("unsupported method %q on line %d of capture file", record.Method, line)
+		}
+
+		if record.Hashed {
+			return fmt.Errorf("the request on line %d of capture file was captured with hashed identifiers and cannot be replayed: requests to replay must be captured with hashing disabled", line)
+		}
+
+		if ctx.Err() != nil {
+			return nil
+		}
+		fn(line, record)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read capture file: %w", err)
+	}
+	return nil
+}
+
+// replay re-executes a captured request.
+func replay(ctx context.Context, conn grpc.ClientConnInterface, config Config, line int, record capture.Record) replayed {
+	outcome := replayed{line: line, record: record}
+
+	method, _ := capture.MethodFor(record.Method)
+	req := method.NewRequest()
+	if err := protojson.Unmarshal(record.Request, req); err != nil {
+		outcome.result = capture.Result{Code: "InvalidRecord"}
+		log.Ctx(ctx).Warn().Err(err).Int("line", line).Msg("unable to unmarshal captured request")
+		return outcome
+	}
+	setConsistency(req, consistencyFor(config, record))
+
+	var builder capture.ResultBuilder
+	start := time.Now()
+	err := invoke(ctx, conn, record.Method, method, req, builder.Add)
+	outcome.latency = time.Since(start)
+	outcome.result = builder.Result(err)
+	if err != nil {
+		log.Ctx(ctx).Debug().Err(err).Int("line", line).Str("method", record.Method).Msg("replayed request failed")
+	}
+	return outcome
+}
+
+// invoke sends the request, calling the function with each response received.
+func invoke(ctx context.Context, conn grpc.ClientConnInterface, fullMethod string, method capture.Method, req proto.Message, onResponse func(proto.Message)) error {
+	if !method.Streaming {
+		resp := method.NewResponse()
+		if err := conn.Invoke(ctx, fullMethod, req, resp); err != nil {
+			return err
+		}
+		onResponse(resp)
+		return nil
+	}
+
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := conn.NewStream(streamCtx, &grpc.StreamDesc{ServerStreams: true}, fullMethod)
+	if err != nil {
+		return err
+	}
+
+	if err := stream.SendMsg(req); err != nil {
+		return err
+	}
+
+	if err := stream.CloseSend(); err != nil {
+		return err
+	}
+
+	for {
+		resp := method.NewResponse()
+		if err := stream.RecvMsg(resp); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		onResponse(resp)
+	}
+}
+
+func consistencyFor(config Config, record capture.Record) *v1.Consistency {
+	if config.AtCapturedRevision && record.Revision != "" {
+		return &v1.Consistency{Requirement: &v1.Consistency_AtExactSnapshot{
+			AtExactSnapshot: &v1.ZedToken{Token: record.Revision},
+		}}
+	}
+	return &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}}
+}
+
+func setConsistency(req proto.Message, consistency *v1.Consistency) {
+	switch req := req.(type) {
+	case *v1.CheckPermissionRequest:
+		req.Consistency = consistency
+	case *v1.CheckBulkPermissionsRequest:
+		req.Consistency = consistency
+	case *v1.LookupResourcesRequest:
+		req.Consistency = consistency
+	case *v1.LookupSubjectsRequest:
+		req.Consistency = consistency
+	}
+}
+
+func newReport(outcomes []replayed, maxDiffs uint) *Report {
+	slices.SortFunc(outcomes, func(a, b replayed) int { return a.line - b.line })
+
+	report := &Report{
+		Methods: map[string]*MethodReport{},
+		Diffs:   []Diff{},
+	}
+
+	captured := map[string][]time.Duration{}
+	replayedLatencies := map[string][]time.Duration{}
+	for _, outcome := range outcomes {
+		method := outcome.record.Method
+		methodReport, ok := report.Methods[method]
+		if !ok {
+			methodReport = &MethodReport{}
+			report.Methods[method] = methodReport
+		}
+
+		report.Replayed++
+		methodReport.Count++
+		captured[method] = append(captured[method], time.Duration(outcome.record.LatencyMillis*float64(time.Millisecond)))
+		replayedLatencies[method] = append(replayedLatencies[method], outcome.latency)
+
+		if outcome.result == outcome.record.Result {
+			continue
+		}
+
+		report.Mismatches++
+		methodReport.Mismatches++
+		if uint(len(report.Diffs)) < maxDiffs {
+			report.Diffs = append(report.Diffs, Diff{
+				Line:     outcome.line,
+				Method:   method,
+				Request:  outcome.record.Request,
+				Captured: outcome.record.Result,
+				Replayed: outcome.result,
+			})
+		}
+	}
+
+	for method, methodReport := range report.Methods {
+		slices.Sort(captured[method])
+		slices.Sort(replayedLatencies[method])
+		methodReport.CapturedP50Millis = millis(percentile(captured[method], 0.5))
+		methodReport.CapturedP99Millis = millis(percentile(captured[method], 0.99))
+		methodReport.ReplayedP50Millis = millis(percentile(replayedLatencies[method], 0.5))
+		methodReport.ReplayedP99Millis = millis(percentile(replayedLatencies[method], 0.99))
+	}
+
+	return report
+}
+
+// percentile returns the given percentile of the sorted latencies, using the nearest-rank method.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	rank := int(math.Ceil(p * float64(len(sorted))))
+	return sorted[max(rank, 1)-1]
+}
+
+func millis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,122 @@
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/authzed/spicedb/internal/middleware/capture"
+	"github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/internal/testserver"
+)
+
+func newRecord(t *testing.T, method string, req proto.Message, responses ...proto.Message) capture.Record {
+	request, err := protojson.Marshal(req)
+	require.NoError(t, err)
+
+	var builder capture.ResultBuilder
+	for _, resp := range responses {
+		builder.Add(resp)
+	}
+
+	return capture.Record{
+		CapturedAt:    time.Now(),
+		Method:        method,
+		Request:       request,
+		Result:        builder.Result(nil),
+		LatencyMillis: 1,
+	}
+}
+
+func writeCaptureFile(t *testing.T, records ...capture.Record) string {
+	file := filepath.Join(t.TempDir(), "capture.ndjson")
+	f, err := os.Create(file)
+	require.NoError(t, err)
+	defer f.Close()
+
+	encoder := json.NewEncoder(f)
+	for _, record := range records {
+		require.NoError(t, encoder.Encode(record))
+	}
+	return file
+}
+
+func TestRun(t *testing.T) {
+	conn, cleanup, _, _ := testserver.NewTestServer(require.New(t), 0, time.Hour, true, testfixtures.StandardDatastoreWithData)
+	t.Cleanup(cleanup)
+
+	check := &v1.CheckPermissionRequest{
+		Resource:   &v1.ObjectReference{ObjectType: "document", ObjectId: "masterplan"},
+		Permission: "view",
+		Subject:    &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: "user", ObjectId: "eng_lead"}},
+	}
+
+	lookup := &v1.LookupResourcesRequest{
+		ResourceObjectType: "document",
+		Permission:         "view",
+		Subject:            &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: "user", ObjectId: "eng_lead"}},
+	}
+	stream, err := v1.NewPermissionsServiceClient(conn).LookupResources(context.Background(), lookup)
+	require.NoError(t, err)
+	var lookedUp []proto.Message
+	for {
+		resp, err := stream.Recv()
+		if err != nil {
+			break
+		}
+		lookedUp = append(lookedUp, resp)
+	}
+	require.NotEmpty(t, lookedUp)
+
+	file := writeCaptureFile(t,
+		newRecord(t, v1.PermissionsService_CheckPermission_FullMethodName, check,
+			&v1.CheckPermissionResponse{Permissionship: v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION}),
+		newRecord(t, v1.PermissionsService_LookupResources_FullMethodName, lookup, lookedUp...),
+		newRecord(t, v1.PermissionsService_CheckPermission_FullMethodName, check,
+			&v1.CheckPermissionResponse{Permissionship: v1.CheckPermissionResponse_PERMISSIONSHIP_NO_PERMISSION}),
+	)
+
+	report, err := Run(context.Background(), conn, Config{File: file, Workers: 2, MaxDiffs: 10})
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), report.Replayed)
+	require.Equal(t, uint64(1), report.Mismatches)
+
+	require.Equal(t, uint64(2), report.Methods[v1.PermissionsService_CheckPermission_FullMethodName].Count)
+	require.Equal(t, uint64(1), report.Methods[v1.PermissionsService_CheckPermission_FullMethodName].Mismatches)
+	require.Equal(t, uint64(1), report.Methods[v1.PermissionsService_LookupResources_FullMethodName].Count)
+	require.Zero(t, report.Methods[v1.PermissionsService_LookupResources_FullMethodName].Mismatches)
+	require.Equal(t, 1.0, report.Methods[v1.PermissionsService_CheckPermission_FullMethodName].CapturedP50Millis)
+
+	require.Len(t, report.Diffs, 1)
+	require.Equal(t, 3, report.Diffs[0].Line)
+	require.Equal(t, uint64(1), report.Diffs[0].Replayed.Count)
+
+	report, err = Run(context.Background(), conn, Config{File: file, Workers: 1})
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), report.Mismatches)
+	require.Empty(t, report.Diffs)
+}
+
+func TestRunValidation(t *testing.T) {
+	_, err := Run(context.Background(), nil, Config{Workers: 1})
+	require.ErrorContains(t, err, "capture file")
+
+	_, err = Run(context.Background(), nil, Config{File: "capture.ndjson"})
+	require.ErrorContains(t, err, "worker")
+
+	file := writeCaptureFile(t, capture.Record{Method: v1.SchemaService_ReadSchema_FullMethodName, Request: json.RawMessage(`{}`)})
+	_, err = Run(context.Background(), nil, Config{File: file, Workers: 1})
+	require.ErrorContains(t, err, "unsupported method")
+
+	file = writeCaptureFile(t, capture.Record{Method: v1.PermissionsService_CheckPermission_FullMethodName, Request: json.RawMessage(`{}`), Hashed: true})
+	_, err = Run(context.Background(), nil, Config{File: file, Workers: 1})
+	require.ErrorContains(t, err, "captured with hashed identifiers")
+}
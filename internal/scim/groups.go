@@ -0,0 +1,394 @@
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+)
+
+const (
+	userMemberType  = "User"
+	groupMemberType = "Group"
+)
+
+type group struct {
+	Schemas     []string     `json:"schemas"`
+	ID          string       `json:"id"`
+	DisplayName string       `json:"displayName"`
+	Members     []member     `json:"members,omitempty"`
+	Meta        resourceMeta `json:"meta"`
+}
+
+// member is a member of a group: a user or, if its type is `Group`, a nested group.
+type member struct {
+	Value string `json:"value"`
+	Type  string `json:"type,omitempty"`
+}
+
+func (m member) normalized() member {
+	if strings.EqualFold(m.Type, groupMemberType) {
+		return member{Value: m.Value, Type: groupMemberType}
+	}
+	return member{Value: m.Value, Type: userMemberType}
+}
+
+// groupInput is the body of the requests creating and replacing groups.
+type groupInput struct {
+	DisplayName string   `json:"displayName"`
+	Members     []member `json:"members"`
+}
+
+// memberSet is the set of normalized members of a group.
+type memberSet map[member]struct{}
+
+func (ms memberSet) add(members []member) error {
+	for _, m := range members {
+		if m.Value == "" {
+			return invalidValue("the value of members is required")
+		}
+		ms[m.normalized()] = struct{}{}
+	}
+	return nil
+}
+
+// remove removes the users and groups with the values of the members.
+func (ms memberSet) remove(members []member) {
+	for _, m := range members {
+		delete(ms, member{Value: m.Value, Type: userMemberType})
+		delete(ms, member{Value: m.Value, Type: groupMemberType})
+	}
+}
+
+func (ms memberSet) sorted() []member {
+	return slices.SortedFunc(maps.Keys(ms), func(a, b member) int {
+		return strings.Compare(a.Type+":"+a.Value, b.Type+":"+b.Value)
+	})
+}
+
+func newGroup(id string, members []member) *group {
+	return &group{
+		Schemas:     []string{groupSchema},
+		ID:          id,
+		DisplayName: Name(id),
+		Members:     members,
+		Meta:        resourceMeta{ResourceType: "Group"},
+	}
+}
+
+func (h *Handler) groupProvisioned(ctx context.Context, id string) (bool, error) {
+	return h.provisioned(ctx, h.mapping.Groups.Relationships, h.mapping.Groups.ObjectType, id)
+}
+
+func (h *Handler) memberRelationship(groupID string, m member) *v1.Relationship {
+	subject := &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: h.mapping.Users.ObjectType, ObjectId: m.Value}}
+	if m.Type == groupMemberType {
+		subject = &v1.SubjectReference{
+			Object:           &v1.ObjectReference{ObjectType: h.mapping.Groups.ObjectType, ObjectId: m.Value},
+			OptionalRelation: h.mapping.Groups.MemberRelation,
+		}
+	}
+
+	return &v1.Relationship{
+		Resource: &v1.ObjectReference{ObjectType: h.mapping.Groups.ObjectType, ObjectId: groupID},
+		Relation: h.mapping.Groups.MemberRelation,
+		Subject:  subject,
+	}
+}
+
+// readMembers returns the users and nested groups which are members of the group.
+func (h *Handler) readMembers(ctx context.Context, id string) (memberSet, error) {
+	members := memberSet{}
+
+	userIDs, err := h.readSubjectIDs(ctx, &v1.RelationshipFilter{
+		ResourceType:          h.mapping.Groups.ObjectType,
+		OptionalResourceId:    id,
+		OptionalRelation:      h.mapping.Groups.MemberRelation,
+		OptionalSubjectFilter: &v1.SubjectFilter{SubjectType: h.mapping.Users.ObjectType},
+	}, 0)
+	if err != nil {
+		return nil, err
+	}
+	for _, userID := range userIDs {
+		members[member{Value: userID, Type: userMemberType}] = struct{}{}
+	}
+
+	groupIDs, err := h.readSubjectIDs(ctx, &v1.RelationshipFilter{
+		ResourceType:       h.mapping.Groups.ObjectType,
+		OptionalResourceId: id,
+		OptionalRelation:   h.mapping.Groups.MemberRelation,
+		OptionalSubjectFilter: &v1.SubjectFilter{
+			SubjectType:      h.mapping.Groups.ObjectType,
+			OptionalRelation: &v1.SubjectFilter_RelationFilter{Relation: h.mapping.Groups.MemberRelation},
+		},
+	}, 0)
+	if err != nil {
+		return nil, err
+	}
+	for _, groupID := range groupIDs {
+		members[member{Value: groupID, Type: groupMemberType}] = struct{}{}
+	}
+	return members, nil
+}
+
+// writeMembers writes the relationships of the members added to the group, and deletes those of
+// the members removed from it.
+func (h *Handler) writeMembers(ctx context.Context, id string, current, desired memberSet) error {
+	var updates []*v1.RelationshipUpdate
+	for m := range desired {
+		if _, ok := current[m]; !ok {
+			updates = append(updates, &v1.RelationshipUpdate{Operation: v1.RelationshipUpdate_OPERATION_TOUCH, Relationship: h.memberRelationship(id, m)})
+		}
+	}
+	for m := range current {
+		if _, ok := desired[m]; !ok {
+			updates = append(updates, &v1.RelationshipUpdate{Operation: v1.RelationshipUpdate_OPERATION_DELETE, Relationship: h.memberRelationship(id, m)})
+		}
+	}
+	return h.write(ctx, updates)
+}
+
+func (h *Handler) listGroups(r *http.Request) (int, any, error) {
+	id, err := parseFilter(r, "displayName")
+	if err != nil {
+		return 0, nil, err
+	}
+
+	ids, err := h.provisionedIDs(r.Context(), h.mapping.Groups.Relationships, h.mapping.Groups.ObjectType, id)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	excludeMembers := slices.ContainsFunc(strings.Split(r.URL.Query().Get("excludedAttributes"), ","), func(attribute string) bool {
+		return strings.EqualFold(strings.TrimSpace(attribute), "members")
+	})
+	resp, err := paginate(r, ids, func(id string) (any, error) {
+		if excludeMembers {
+			return newGroup(id, nil), nil
+		}
+		members, err := h.readMembers(r.Context(), id)
+		if err != nil {
+			return nil, err
+		}
+		return newGroup(id, members.sorted()), nil
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+	return http.StatusOK, resp, nil
+}
+
+func (h *Handler) createGroup(r *http.Request) (int, any, error) {
+	var input groupInput
+	if err := decodeBody(r, &input); err != nil {
+		return 0, nil, err
+	}
+	if input.DisplayName == "" {
+		return 0, nil, invalidValue("displayName is required")
+	}
+
+	id := ObjectID(input.DisplayName)
+	exists, err := h.groupProvisioned(r.Context(), id)
+	if err != nil {
+		return 0, nil, err
+	}
+	if exists {
+		return 0, nil, &scimError{status: http.StatusConflict, scimType: "uniqueness", detail: "group `" + input.DisplayName + "` already exists"}
+	}
+
+	members := memberSet{}
+	if err := members.add(input.Members); err != nil {
+		return 0, nil, err
+	}
+
+	subject := &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: h.mapping.Groups.ObjectType, ObjectId: id}}
+	if err := h.write(r.Context(), updates(v1.RelationshipUpdate_OPERATION_TOUCH, relationships(h.mapping.Groups.Relationships, subject))); err != nil {
+		return 0, nil, err
+	}
+	if err := h.writeMembers(r.Context(), id, memberSet{}, members); err != nil {
+		return 0, nil, err
+	}
+	return http.StatusCreated, newGroup(id, members.sorted()), nil
+}
+
+// existingGroupMembers returns the members of the group, or an error if it is not provisioned.
+func (h *Handler) existingGroupMembers(ctx context.Context, id string) (memberSet, error) {
+	exists, err := h.groupProvisioned(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, notFound("group", id)
+	}
+	return h.readMembers(ctx, id)
+}
+
+func (h *Handler) getGroup(r *http.Request) (int, any, error) {
+	id := r.PathValue("id")
+	members, err := h.existingGroupMembers(r.Context(), id)
+	if err != nil {
+		return 0, nil, err
+	}
+	return http.StatusOK, newGroup(id, members.sorted()), nil
+}
+
+func (h *Handler) replaceGroup(r *http.Request) (int, any, error) {
+	id := r.PathValue("id")
+	var input groupInput
+	if err := decodeBody(r, &input); err != nil {
+		return 0, nil, err
+	}
+	if input.DisplayName != "" && ObjectID(input.DisplayName) != id {
+		return 0, nil, immutableName("displayName", id)
+	}
+
+	current, err := h.existingGroupMembers(r.Context(), id)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	desired := memberSet{}
+	if err := desired.add(input.Members); err != nil {
+		return 0, nil, err
+	}
+	if err := h.writeMembers(r.Context(), id, current, desired); err != nil {
+		return 0, nil, err
+	}
+	return http.StatusOK, newGroup(id, desired.sorted()), nil
+}
+
+var memberValuePathRegex = regexp.MustCompile(`(?i)^members\[\s*value\s+eq\s+("(?:[^"\\]|\\.)*")\s*\]$`)
+
+// patchGroup applies the changes of the members of the group, as sent by identity providers:
+// members are added with the path `members`, and removed with the path `members` and the
+// members as value, or with the path `members[value eq "id"]`.
+func (h *Handler) patchGroup(r *http.Request) (int, any, error) {
+	id := r.PathValue("id")
+	var patch patchRequest
+	if err := decodeBody(r, &patch); err != nil {
+		return 0, nil, err
+	}
+
+	current, err := h.existingGroupMembers(r.Context(), id)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	desired := maps.Clone(current)
+	for _, op := range patch.Operations {
+		if err := h.applyGroupOperation(id, op, desired); err != nil {
+			return 0, nil, err
+		}
+	}
+
+	if err := h.writeMembers(r.Context(), id, current, desired); err != nil {
+		return 0, nil, err
+	}
+	return http.StatusNoContent, nil, nil
+}
+
+func (h *Handler) applyGroupOperation(id string, op patchOperation, members memberSet) error {
+	replace := strings.EqualFold(op.Op, "replace")
+	switch {
+	case strings.EqualFold(op.Op, "remove"):
+		if match := memberValuePathRegex.FindStringSubmatch(op.Path); match != nil {
+			var value string
+			if err := json.Unmarshal([]byte(match[1]), &value); err != nil {
+				return invalidValue("invalid member value %s", match[1])
+			}
+			members.remove([]member{{Value: value}})
+			return nil
+		}
+		if !strings.EqualFold(op.Path, "members") {
+			return &scimError{status: http.StatusBadRequest, scimType: "invalidPath", detail: "unsupported path `" + op.Path + "` of remove operation"}
+		}
+
+		if len(op.Value) == 0 || string(op.Value) == "null" {
+			clear(members)
+			return nil
+		}
+		var removed []member
+		if err := json.Unmarshal(op.Value, &removed); err != nil {
+			return invalidValue("the value of the members path must be a list of members")
+		}
+		members.remove(removed)
+		return nil
+
+	case strings.EqualFold(op.Op, "add"), replace:
+		values := map[string]json.RawMessage{}
+		if op.Path == "" {
+			if err := json.Unmarshal(op.Value, &values); err != nil {
+				return invalidValue("the value of an operation without path must be an object")
+			}
+		} else {
+			values[op.Path] = op.Value
+		}
+
+		for attribute, value := range values {
+			switch {
+			case strings.EqualFold(attribute, "members"):
+				var added []member
+				if err := json.Unmarshal(value, &added); err != nil {
+					return invalidValue("the value of the members path must be a list of members")
+				}
+				if replace {
+					clear(members)
+				}
+				if err := members.add(added); err != nil {
+					return err
+				}
+			case strings.EqualFold(attribute, "displayName"):
+				var displayName string
+				if err := json.Unmarshal(value, &displayName); err != nil || ObjectID(displayName) != id {
+					return immutableName("displayName", id)
+				}
+			}
+		}
+		return nil
+
+	default:
+		return &scimError{status: http.StatusBadRequest, scimType: "invalidSyntax", detail: "unsupported operation `" + op.Op + "`"}
+	}
+}
+
+func (h *Handler) deleteGroup(r *http.Request) (int, any, error) {
+	id := r.PathValue("id")
+	exists, err := h.groupProvisioned(r.Context(), id)
+	if err != nil {
+		return 0, nil, err
+	}
+	if !exists {
+		return 0, nil, notFound("group", id)
+	}
+
+	subject := &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: h.mapping.Groups.ObjectType, ObjectId: id}}
+	if err := h.write(r.Context(), updates(v1.RelationshipUpdate_OPERATION_DELETE, relationships(h.mapping.Groups.Relationships, subject))); err != nil {
+		return 0, nil, err
+	}
+
+	// The members of the group are removed from it, and it from the groups of which it is a member.
+	if err := h.deleteRelationships(r.Context(),
+		&v1.RelationshipFilter{
+			ResourceType:       h.mapping.Groups.ObjectType,
+			OptionalResourceId: id,
+			OptionalRelation:   h.mapping.Groups.MemberRelation,
+		},
+		&v1.RelationshipFilter{
+			ResourceType:     h.mapping.Groups.ObjectType,
+			OptionalRelation: h.mapping.Groups.MemberRelation,
+			OptionalSubjectFilter: &v1.SubjectFilter{
+				SubjectType:       h.mapping.Groups.ObjectType,
+				OptionalSubjectId: id,
+				OptionalRelation:  &v1.SubjectFilter_RelationFilter{Relation: h.mapping.Groups.MemberRelation},
+			},
+		},
+	); err != nil {
+		return 0, nil, err
+	}
+	return http.StatusNoContent, nil, nil
+}
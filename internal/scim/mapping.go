@@ -0,0 +1,173 @@
+package scim
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	yamlv3 "gopkg.in/yaml.v3"
+
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// Mapping is the translation of SCIM provisioning operations into relationship writes, as read
+// from YAML:
+//
+//	users:
+//	  objectType: user
+//	  relationships:
+//	    - organization:acme#member
+//	groups:
+//	  objectType: group
+//	  memberRelation: member
+//	  relationships:
+//	    - organization:acme#group
+//
+// Each active user is the subject of the relationships of the users, such as
+// `organization:acme#member@user:jane=40example=2Ecom`, and each group of those of the groups.
+// The members of a group are the subjects of its member relation: users as
+// `group:engineering#member@user:jane=40example=2Ecom`, and nested groups as
+// `group:engineering#member@group:platform#member`.
+//
+// The first relationship of users and groups records that they are provisioned: users and groups
+// are listed and found by reading it. The IDs of users and groups are their escaped user names and
+// display names, which therefore cannot be changed.
+type Mapping struct {
+	Users  UserMapping  `yaml:"users"`
+	Groups GroupMapping `yaml:"groups"`
+}
+
+// UserMapping maps the SCIM users to objects.
+type UserMapping struct {
+	// ObjectType is the object type of the users.
+	ObjectType string `yaml:"objectType"`
+
+	// Relationships are the resources and relations, of the form `type:id#relation`, of which
+	// active users are subjects. Deactivated and deleted users are removed from them.
+	Relationships []string `yaml:"relationships"`
+}
+
+// GroupMapping maps the SCIM groups to objects.
+type GroupMapping struct {
+	// ObjectType is the object type of the groups.
+	ObjectType string `yaml:"objectType"`
+
+	// MemberRelation is the relation of groups to their members.
+	MemberRelation string `yaml:"memberRelation"`
+
+	// Relationships are the resources and relations, of the form `type:id#relation`, of which
+	// groups are subjects.
+	Relationships []string `yaml:"relationships"`
+}
+
+// LoadMapping reads the mapping from the YAML file at the path.
+func LoadMapping(path string) (*Mapping, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SCIM mapping: %w", err)
+	}
+	return ParseMapping(contents)
+}
+
+// ParseMapping parses and validates a mapping from YAML.
+func ParseMapping(contents []byte) (*Mapping, error) {
+	decoder := yamlv3.NewDecoder(bytes.NewReader(contents))
+	decoder.KnownFields(true)
+
+	var mapping Mapping
+	if err := decoder.Decode(&mapping); err != nil {
+		return nil, fmt.Errorf("failed to parse SCIM mapping: %w", err)
+	}
+
+	if mapping.Users.ObjectType == "" || mapping.Groups.ObjectType == "" {
+		return nil, errors.New("the object types of users and groups are required")
+	}
+	if mapping.Groups.MemberRelation == "" {
+		return nil, errors.New("the member relation of groups is required")
+	}
+	if err := validateRelationships(mapping.Users.Relationships); err != nil {
+		return nil, fmt.Errorf("invalid relationships of users: %w", err)
+	}
+	if err := validateRelationships(mapping.Groups.Relationships); err != nil {
+		return nil, fmt.Errorf("invalid relationships of groups: %w", err)
+	}
+	return &mapping, nil
+}
+
+func validateRelationships(relationships []string) error {
+	if len(relationships) == 0 {
+		return errors.New("at least one relationship is required to record provisioning")
+	}
+	for _, relationship := range relationships {
+		if _, err := tuple.ParseONR(relationship); err != nil {
+			return fmt.Errorf("`%s` is not of the form `type:id#relation`", relationship)
+		}
+	}
+	return nil
+}
+
+// relationships returns the relationships of which the object is the subject.
+func relationships(resources []string, subject *v1.SubjectReference) []*v1.Relationship {
+	rels := make([]*v1.Relationship, 0, len(resources))
+	for _, resource := range resources {
+		onr := tuple.MustParseONR(resource)
+		rels = append(rels, &v1.Relationship{
+			Resource: &v1.ObjectReference{ObjectType: onr.ObjectType, ObjectId: onr.ObjectID},
+			Relation: onr.Relation,
+			Subject:  subject,
+		})
+	}
+	return rels
+}
+
+// provisioningFilter returns the filter of the relationships recording the provisioning of the
+// objects of the type, optionally with the ID.
+func provisioningFilter(resources []string, objectType, objectID string) *v1.RelationshipFilter {
+	onr := tuple.MustParseONR(resources[0])
+	return &v1.RelationshipFilter{
+		ResourceType:       onr.ObjectType,
+		OptionalResourceId: onr.ObjectID,
+		OptionalRelation:   onr.Relation,
+		OptionalSubjectFilter: &v1.SubjectFilter{
+			SubjectType:       objectType,
+			OptionalSubjectId: objectID,
+		},
+	}
+}
+
+// ObjectID escapes a SCIM user or display name into a valid object ID: every byte not allowed in
+// object IDs, including `=` and `|`, is replaced by `=` followed by its uppercase hexadecimal
+// value. For example, `jane@example.com` is the object ID `jane=40example=2Ecom`.
+func ObjectID(name string) string {
+	var escaped strings.Builder
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '/', c == '_', c == '-', c == '+':
+			escaped.WriteByte(c)
+		default:
+			fmt.Fprintf(&escaped, "=%02X", c)
+		}
+	}
+	return escaped.String()
+}
+
+// Name returns the name escaped into the object ID by ObjectID.
+func Name(objectID string) string {
+	var name strings.Builder
+	for i := 0; i < len(objectID); i++ {
+		if objectID[i] == '=' && i+2 < len(objectID) {
+			if c, err := strconv.ParseUint(objectID[i+1:i+3], 16, 8); err == nil {
+				name.WriteByte(byte(c))
+				i += 2
+				continue
+			}
+		}
+		name.WriteByte(objectID[i])
+	}
+	return name.String()
+}
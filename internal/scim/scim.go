@@ -0,0 +1,346 @@
+// Package scim implements a SCIM 2.0 server, as defined by RFC 7643 and RFC 7644, which maps the
+// provisioning of users and groups by identity providers to relationship writes with a Mapping.
+package scim
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	log "github.com/authzed/spicedb/internal/logging"
+)
+
+const (
+	userSchema                  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	groupSchema                 = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	serviceProviderConfigSchema = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+	listResponseSchema          = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	errorSchema                 = "urn:ietf:params:scim:api:messages:2.0:Error"
+
+	contentType = "application/scim+json"
+
+	// maxUpdatesPerWrite is the number of updates written per WriteRelationships request, which is
+	// the default maximum of the server.
+	maxUpdatesPerWrite = 1000
+)
+
+// Handler is the HTTP handler of the SCIM endpoints, which are served relative to the base URL
+// configured in identity providers: /Users, /Groups and /ServiceProviderConfig.
+//
+// Deactivating a user removes it from the relationships of users and from all groups, so that it
+// loses all access, and it is no longer found until it is activated again. Writes of more than
+// 1000 relationships, such as those replacing the members of large groups, are not atomic.
+type Handler struct {
+	client        v1.PermissionsServiceClient
+	mapping       *Mapping
+	presharedKeys []string
+	mux           *http.ServeMux
+}
+
+// NewHandler creates the handler of the SCIM endpoints, writing the relationships of the mapping
+// with the client. Requests must be authenticated with one of the preshared keys as bearer token,
+// the first of which authenticates the calls made with the client.
+func NewHandler(client v1.PermissionsServiceClient, mapping *Mapping, presharedKeys []string) *Handler {
+	h := &Handler{client: client, mapping: mapping, presharedKeys: presharedKeys, mux: http.NewServeMux()}
+
+	h.mux.Handle("GET /ServiceProviderConfig", endpoint(h.serviceProviderConfig))
+	h.mux.Handle("GET /Users", endpoint(h.listUsers))
+	h.mux.Handle("POST /Users", endpoint(h.createUser))
+	h.mux.Handle("GET /Users/{id}", endpoint(h.getUser))
+	h.mux.Handle("PUT /Users/{id}", endpoint(h.replaceUser))
+	h.mux.Handle("PATCH /Users/{id}", endpoint(h.patchUser))
+	h.mux.Handle("DELETE /Users/{id}", endpoint(h.deleteUser))
+	h.mux.Handle("GET /Groups", endpoint(h.listGroups))
+	h.mux.Handle("POST /Groups", endpoint(h.createGroup))
+	h.mux.Handle("GET /Groups/{id}", endpoint(h.getGroup))
+	h.mux.Handle("PUT /Groups/{id}", endpoint(h.replaceGroup))
+	h.mux.Handle("PATCH /Groups/{id}", endpoint(h.patchGroup))
+	h.mux.Handle("DELETE /Groups/{id}", endpoint(h.deleteGroup))
+	return h
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !h.authorized(r) {
+		writeError(w, &scimError{status: http.StatusUnauthorized, detail: "a valid preshared key is required"})
+		return
+	}
+	if len(h.presharedKeys) > 0 {
+		r = r.WithContext(metadata.AppendToOutgoingContext(r.Context(), "authorization", "Bearer "+h.presharedKeys[0]))
+	}
+	h.mux.ServeHTTP(w, r)
+}
+
+func (h *Handler) authorized(r *http.Request) bool {
+	if len(h.presharedKeys) == 0 {
+		return true
+	}
+
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return false
+	}
+	return slices.ContainsFunc(h.presharedKeys, func(key string) bool {
+		return subtle.ConstantTimeCompare([]byte(key), []byte(token)) == 1
+	})
+}
+
+// endpoint is a SCIM endpoint, returning the status and resource of the response, or an error.
+type endpoint func(r *http.Request) (int, any, error)
+
+func (e endpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	code, resource, err := e(r)
+	if err != nil {
+		var se *scimError
+		if !errors.As(err, &se) {
+			se = fromGRPCError(err)
+			if se.status == http.StatusInternalServerError {
+				log.Ctx(r.Context()).Warn().Err(err).Str("path", r.URL.Path).Msg("failed to serve SCIM request")
+			}
+		}
+		writeError(w, se)
+		return
+	}
+
+	if resource == nil {
+		w.WriteHeader(code)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(resource); err != nil {
+		log.Ctx(r.Context()).Warn().Err(err).Msg("failed to write SCIM response")
+	}
+}
+
+// scimError is an error answered with a SCIM error response.
+type scimError struct {
+	status   int
+	scimType string
+	detail   string
+}
+
+func (se *scimError) Error() string {
+	return se.detail
+}
+
+func fromGRPCError(err error) *scimError {
+	switch status.Code(err) {
+	case codes.InvalidArgument, codes.FailedPrecondition:
+		return &scimError{status: http.StatusBadRequest, scimType: "invalidValue", detail: status.Convert(err).Message()}
+	case codes.Unauthenticated, codes.PermissionDenied:
+		return &scimError{status: http.StatusForbidden, detail: status.Convert(err).Message()}
+	default:
+		return &scimError{status: http.StatusInternalServerError, detail: err.Error()}
+	}
+}
+
+func writeError(w http.ResponseWriter, se *scimError) {
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(se.status)
+	_ = json.NewEncoder(w).Encode(struct {
+		Schemas  []string `json:"schemas"`
+		Status   string   `json:"status"`
+		SCIMType string   `json:"scimType,omitempty"`
+		Detail   string   `json:"detail"`
+	}{[]string{errorSchema}, strconv.Itoa(se.status), se.scimType, se.detail})
+}
+
+func invalidValue(format string, args ...any) error {
+	return &scimError{status: http.StatusBadRequest, scimType: "invalidValue", detail: fmt.Sprintf(format, args...)}
+}
+
+func notFound(resourceType, id string) error {
+	return &scimError{status: http.StatusNotFound, detail: fmt.Sprintf("%s `%s` not found", resourceType, id)}
+}
+
+// immutableName returns the error of a request changing the name of a user or group, from which
+// its ID is derived.
+func immutableName(attribute, id string) error {
+	return &scimError{status: http.StatusBadRequest, scimType: "mutability", detail: fmt.Sprintf("the %s of `%s` cannot be changed, as its ID is derived from it", attribute, id)}
+}
+
+func decodeBody(r *http.Request, body any) error {
+	if err := json.NewDecoder(io.LimitReader(r.Body, 10<<20)).Decode(body); err != nil {
+		return &scimError{status: http.StatusBadRequest, scimType: "invalidSyntax", detail: fmt.Sprintf("invalid request body: %s", err)}
+	}
+	return nil
+}
+
+type resourceMeta struct {
+	ResourceType string `json:"resourceType"`
+}
+
+type listResponse struct {
+	Schemas      []string `json:"schemas"`
+	TotalResults int      `json:"totalResults"`
+	StartIndex   int      `json:"startIndex"`
+	ItemsPerPage int      `json:"itemsPerPage"`
+	Resources    []any    `json:"Resources"`
+}
+
+var filterRegex = regexp.MustCompile(`(?i)^\s*(\w+)\s+eq\s+("(?:[^"\\]|\\.)*")\s*$`)
+
+// parseFilter parses the filter of a list request, of which only equality of an attribute is
+// supported, returning the ID it filters on, if any.
+func parseFilter(r *http.Request, nameAttribute string) (string, error) {
+	filter := r.URL.Query().Get("filter")
+	if filter == "" {
+		return "", nil
+	}
+
+	match := filterRegex.FindStringSubmatch(filter)
+	if match == nil {
+		return "", &scimError{status: http.StatusBadRequest, scimType: "invalidFilter", detail: fmt.Sprintf("unsupported filter `%s`: only `%s eq \"value\"` and `id eq \"value\"` are supported", filter, nameAttribute)}
+	}
+
+	var value string
+	if err := json.Unmarshal([]byte(match[2]), &value); err != nil || value == "" {
+		return "", &scimError{status: http.StatusBadRequest, scimType: "invalidFilter", detail: fmt.Sprintf("invalid filter value %s", match[2])}
+	}
+
+	switch {
+	case strings.EqualFold(match[1], "id"):
+		return value, nil
+	case strings.EqualFold(match[1], nameAttribute):
+		return ObjectID(value), nil
+	default:
+		return "", &scimError{status: http.StatusBadRequest, scimType: "invalidFilter", detail: fmt.Sprintf("unsupported filter attribute `%s`", match[1])}
+	}
+}
+
+// paginate returns the list response of the page of IDs requested with startIndex and count.
+func paginate(r *http.Request, ids []string, resource func(id string) (any, error)) (*listResponse, error) {
+	startIndex, count := 1, len(ids)
+	if param := r.URL.Query().Get("startIndex"); param != "" {
+		parsed, err := strconv.Atoi(param)
+		if err != nil {
+			return nil, invalidValue("invalid startIndex `%s`", param)
+		}
+		startIndex = max(parsed, 1)
+	}
+	if param := r.URL.Query().Get("count"); param != "" {
+		parsed, err := strconv.Atoi(param)
+		if err != nil {
+			return nil, invalidValue("invalid count `%s`", param)
+		}
+		count = max(parsed, 0)
+	}
+
+	page := ids[min(startIndex-1, len(ids)):]
+	page = page[:min(count, len(page))]
+
+	resp := &listResponse{
+		Schemas:      []string{listResponseSchema},
+		TotalResults: len(ids),
+		StartIndex:   startIndex,
+		ItemsPerPage: len(page),
+		Resources:    make([]any, 0, len(page)),
+	}
+	for _, id := range page {
+		res, err := resource(id)
+		if err != nil {
+			return nil, err
+		}
+		resp.Resources = append(resp.Resources, res)
+	}
+	return resp, nil
+}
+
+func (h *Handler) serviceProviderConfig(*http.Request) (int, any, error) {
+	return http.StatusOK, map[string]any{
+		"schemas":        []string{serviceProviderConfigSchema},
+		"patch":          map[string]any{"supported": true},
+		"bulk":           map[string]any{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":         map[string]any{"supported": true, "maxResults": 0},
+		"changePassword": map[string]any{"supported": false},
+		"sort":           map[string]any{"supported": false},
+		"etag":           map[string]any{"supported": false},
+		"authenticationSchemes": []map[string]any{{
+			"type":        "oauthbearertoken",
+			"name":        "Bearer token",
+			"description": "Authentication with a preshared key of SpiceDB as bearer token",
+		}},
+	}, nil
+}
+
+// readSubjectIDs returns the sorted IDs of the subjects of the relationships matching the filter.
+func (h *Handler) readSubjectIDs(ctx context.Context, filter *v1.RelationshipFilter, limit uint32) ([]string, error) {
+	stream, err := h.client.ReadRelationships(ctx, &v1.ReadRelationshipsRequest{
+		Consistency:        &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}},
+		RelationshipFilter: filter,
+		OptionalLimit:      limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		ids = append(ids, resp.Relationship.Subject.Object.ObjectId)
+	}
+
+	slices.Sort(ids)
+	return slices.Compact(ids), nil
+}
+
+// provisionedIDs returns the IDs of the provisioned objects of the type, or only the one with
+// the ID, if not empty.
+func (h *Handler) provisionedIDs(ctx context.Context, resources []string, objectType, id string) ([]string, error) {
+	var limit uint32
+	if id != "" {
+		limit = 1
+	}
+	return h.readSubjectIDs(ctx, provisioningFilter(resources, objectType, id), limit)
+}
+
+func (h *Handler) provisioned(ctx context.Context, resources []string, objectType, id string) (bool, error) {
+	ids, err := h.provisionedIDs(ctx, resources, objectType, id)
+	return len(ids) > 0, err
+}
+
+// write writes the updates, in chunks of at most maxUpdatesPerWrite.
+func (h *Handler) write(ctx context.Context, updates []*v1.RelationshipUpdate) error {
+	for chunk := range slices.Chunk(updates, maxUpdatesPerWrite) {
+		if _, err := h.client.WriteRelationships(ctx, &v1.WriteRelationshipsRequest{Updates: chunk}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func updates(operation v1.RelationshipUpdate_Operation, rels []*v1.Relationship) []*v1.RelationshipUpdate {
+	updates := make([]*v1.RelationshipUpdate, 0, len(rels))
+	for _, rel := range rels {
+		updates = append(updates, &v1.RelationshipUpdate{Operation: operation, Relationship: rel})
+	}
+	return updates
+}
+
+// deleteRelationships deletes the relationships matching each of the filters.
+func (h *Handler) deleteRelationships(ctx context.Context, filters ...*v1.RelationshipFilter) error {
+	for _, filter := range filters {
+		if _, err := h.client.DeleteRelationships(ctx, &v1.DeleteRelationshipsRequest{RelationshipFilter: filter}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
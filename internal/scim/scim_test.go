@@ -0,0 +1,212 @@
+package scim_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/scim"
+	"github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/internal/testserver"
+)
+
+const testMapping = `
+users:
+  objectType: user
+  relationships:
+    - organization:acme#member
+groups:
+  objectType: group
+  memberRelation: member
+  relationships:
+    - organization:acme#group
+`
+
+func TestObjectID(t *testing.T) {
+	for name, id := range map[string]string{
+		"jane@example.com": "jane=40example=2Ecom",
+		"Engineering Team": "Engineering=20Team",
+		"a=b|c":            "a=3Db=7Cc",
+		"plain_name-1":     "plain_name-1",
+	} {
+		require.Equal(t, id, scim.ObjectID(name))
+		require.Equal(t, name, scim.Name(id))
+	}
+}
+
+func TestParseMappingErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name          string
+		mapping       string
+		expectedError string
+	}{
+		{"missing object types", "users: {relationships: ['org:acme#member']}", "object types of users and groups are required"},
+		{"missing member relation", "users: {objectType: user, relationships: ['org:acme#member']}\ngroups: {objectType: group, relationships: ['org:acme#group']}", "member relation of groups is required"},
+		{"missing relationships", "users: {objectType: user}\ngroups: {objectType: group, memberRelation: member, relationships: ['org:acme#group']}", "at least one relationship is required"},
+		{"invalid relationship", "users: {objectType: user, relationships: ['org:acme']}\ngroups: {objectType: group, memberRelation: member, relationships: ['org:acme#group']}", "not of the form `type:id#relation`"},
+		{"unknown field", "users: {objectType: user, relation: member}", "field relation not found"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := scim.ParseMapping([]byte(tc.mapping))
+			require.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
+
+func newTestHandler(t *testing.T, presharedKeys ...string) (*scim.Handler, v1.PermissionsServiceClient) {
+	conn, cleanup, _, _ := testserver.NewTestServer(require.New(t), 0, time.Hour, true, testfixtures.EmptyDatastore)
+	t.Cleanup(cleanup)
+
+	_, err := v1.NewSchemaServiceClient(conn).WriteSchema(context.Background(), &v1.WriteSchemaRequest{
+		Schema: `definition user {}
+
+		definition group {
+			relation member: user | group#member
+		}
+
+		definition organization {
+			relation member: user
+			relation group: group
+		}`,
+	})
+	require.NoError(t, err)
+
+	mapping, err := scim.ParseMapping([]byte(testMapping))
+	require.NoError(t, err)
+
+	client := v1.NewPermissionsServiceClient(conn)
+	return scim.NewHandler(client, mapping, presharedKeys), client
+}
+
+func do(t *testing.T, handler http.Handler, method, path, body string, expectedStatus int) map[string]any {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/scim+json")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+	require.Equal(t, expectedStatus, recorder.Code, recorder.Body.String())
+
+	if recorder.Body.Len() == 0 {
+		return nil
+	}
+	var resp map[string]any
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+	return resp
+}
+
+func relationshipCount(t *testing.T, client v1.PermissionsServiceClient, filter *v1.RelationshipFilter) int {
+	stream, err := client.ReadRelationships(context.Background(), &v1.ReadRelationshipsRequest{
+		Consistency:        &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}},
+		RelationshipFilter: filter,
+	})
+	require.NoError(t, err)
+
+	count := 0
+	for {
+		if _, err := stream.Recv(); err != nil {
+			return count
+		}
+		count++
+	}
+}
+
+func TestUserProvisioning(t *testing.T) {
+	handler, client := newTestHandler(t)
+
+	created := do(t, handler, http.MethodPost, "/Users", `{"schemas": ["urn:ietf:params:scim:schemas:core:2.0:User"], "userName": "jane@example.com", "name": {"givenName": "Jane"}}`, http.StatusCreated)
+	require.Equal(t, "jane=40example=2Ecom", created["id"])
+	require.Equal(t, "jane@example.com", created["userName"])
+	require.Equal(t, true, created["active"])
+	do(t, handler, http.MethodPost, "/Users", `{"userName": "jane@example.com"}`, http.StatusConflict)
+	do(t, handler, http.MethodPost, "/Users", `{"userName": "bob@example.com"}`, http.StatusCreated)
+
+	list := do(t, handler, http.MethodGet, "/Users?filter="+strings.ReplaceAll(`userName eq "jane@example.com"`, " ", "%20"), "", http.StatusOK)
+	require.Equal(t, float64(1), list["totalResults"])
+	require.Equal(t, "jane=40example=2Ecom", list["Resources"].([]any)[0].(map[string]any)["id"])
+
+	list = do(t, handler, http.MethodGet, "/Users?startIndex=2&count=5", "", http.StatusOK)
+	require.Equal(t, float64(2), list["totalResults"])
+	require.Equal(t, float64(1), list["itemsPerPage"])
+	require.Equal(t, "jane=40example=2Ecom", list["Resources"].([]any)[0].(map[string]any)["id"])
+
+	do(t, handler, http.MethodGet, "/Users?filter=emails%20co%20%22example%22", "", http.StatusBadRequest)
+	do(t, handler, http.MethodPatch, "/Users/jane=40example=2Ecom", `{"Operations": [{"op": "replace", "path": "userName", "value": "janet@example.com"}]}`, http.StatusBadRequest)
+
+	do(t, handler, http.MethodPost, "/Groups", `{"displayName": "Engineering", "members": [{"value": "jane=40example=2Ecom"}]}`, http.StatusCreated)
+	require.Equal(t, 1, relationshipCount(t, client, &v1.RelationshipFilter{ResourceType: "group", OptionalRelation: "member"}))
+
+	// Deactivating the user, as sent with a string value by some identity providers, removes it
+	// from the organization and its groups.
+	deactivated := do(t, handler, http.MethodPatch, "/Users/jane=40example=2Ecom", `{"Operations": [{"op": "Replace", "path": "active", "value": "False"}]}`, http.StatusOK)
+	require.Equal(t, false, deactivated["active"])
+	do(t, handler, http.MethodGet, "/Users/jane=40example=2Ecom", "", http.StatusNotFound)
+	require.Equal(t, 0, relationshipCount(t, client, &v1.RelationshipFilter{ResourceType: "group", OptionalRelation: "member"}))
+
+	reactivated := do(t, handler, http.MethodPatch, "/Users/jane=40example=2Ecom", `{"Operations": [{"op": "replace", "value": {"active": true}}]}`, http.StatusOK)
+	require.Equal(t, true, reactivated["active"])
+	do(t, handler, http.MethodGet, "/Users/jane=40example=2Ecom", "", http.StatusOK)
+
+	do(t, handler, http.MethodDelete, "/Users/bob=40example=2Ecom", "", http.StatusNoContent)
+	do(t, handler, http.MethodDelete, "/Users/bob=40example=2Ecom", "", http.StatusNotFound)
+	require.Equal(t, 1, relationshipCount(t, client, &v1.RelationshipFilter{ResourceType: "organization", OptionalRelation: "member"}))
+}
+
+func TestGroupProvisioning(t *testing.T) {
+	handler, client := newTestHandler(t)
+
+	created := do(t, handler, http.MethodPost, "/Groups", `{"displayName": "Engineering Team", "members": [{"value": "alice"}, {"value": "bob"}]}`, http.StatusCreated)
+	require.Equal(t, "Engineering=20Team", created["id"])
+	do(t, handler, http.MethodPost, "/Groups", `{"displayName": "Platform"}`, http.StatusCreated)
+	do(t, handler, http.MethodPost, "/Groups", `{"displayName": "Platform"}`, http.StatusConflict)
+
+	// Members are added and removed as sent by identity providers.
+	do(t, handler, http.MethodPatch, "/Groups/Engineering=20Team", `{"Operations": [
+		{"op": "add", "path": "members", "value": [{"value": "Platform", "type": "Group"}, {"value": "carol"}]},
+		{"op": "remove", "path": "members[value eq \"alice\"]"}
+	]}`, http.StatusNoContent)
+	do(t, handler, http.MethodPatch, "/Groups/Engineering=20Team", `{"Operations": [{"op": "Remove", "path": "members", "value": [{"value": "bob"}]}]}`, http.StatusNoContent)
+
+	group := do(t, handler, http.MethodGet, "/Groups/Engineering=20Team", "", http.StatusOK)
+	require.Equal(t, "Engineering Team", group["displayName"])
+	require.Equal(t, []any{
+		map[string]any{"value": "Platform", "type": "Group"},
+		map[string]any{"value": "carol", "type": "User"},
+	}, group["members"])
+
+	list := do(t, handler, http.MethodGet, "/Groups?excludedAttributes=members&filter=displayName%20eq%20%22Engineering%20Team%22", "", http.StatusOK)
+	require.Equal(t, float64(1), list["totalResults"])
+	require.NotContains(t, list["Resources"].([]any)[0], "members")
+
+	replaced := do(t, handler, http.MethodPut, "/Groups/Engineering=20Team", `{"displayName": "Engineering Team", "members": [{"value": "dave"}]}`, http.StatusOK)
+	require.Equal(t, []any{map[string]any{"value": "dave", "type": "User"}}, replaced["members"])
+	do(t, handler, http.MethodPut, "/Groups/Engineering=20Team", `{"displayName": "Engineering"}`, http.StatusBadRequest)
+
+	// Deleting a group removes its members, and it from the groups of which it is a member.
+	do(t, handler, http.MethodPatch, "/Groups/Engineering=20Team", `{"Operations": [{"op": "add", "value": {"members": [{"value": "Platform", "type": "Group"}]}}]}`, http.StatusNoContent)
+	do(t, handler, http.MethodPatch, "/Groups/Platform", `{"Operations": [{"op": "add", "path": "members", "value": [{"value": "erin"}]}]}`, http.StatusNoContent)
+	do(t, handler, http.MethodDelete, "/Groups/Platform", "", http.StatusNoContent)
+	do(t, handler, http.MethodGet, "/Groups/Platform", "", http.StatusNotFound)
+	require.Equal(t, 1, relationshipCount(t, client, &v1.RelationshipFilter{ResourceType: "group", OptionalRelation: "member"}))
+	require.Equal(t, 1, relationshipCount(t, client, &v1.RelationshipFilter{ResourceType: "organization", OptionalRelation: "group"}))
+}
+
+func TestAuthentication(t *testing.T) {
+	handler, _ := newTestHandler(t, "somekey")
+
+	resp := do(t, handler, http.MethodGet, "/Users", "", http.StatusUnauthorized)
+	require.Equal(t, []any{"urn:ietf:params:scim:api:messages:2.0:Error"}, resp["schemas"])
+	require.Equal(t, "401", resp["status"])
+
+	req := httptest.NewRequest(http.MethodGet, "/ServiceProviderConfig", nil)
+	req.Header.Set("Authorization", "Bearer somekey")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, "application/scim+json", recorder.Header().Get("Content-Type"))
+}
@@ -0,0 +1,239 @@
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+)
+
+type user struct {
+	Schemas  []string     `json:"schemas"`
+	ID       string       `json:"id"`
+	UserName string       `json:"userName"`
+	Active   bool         `json:"active"`
+	Meta     resourceMeta `json:"meta"`
+}
+
+func newUser(id string, active bool) *user {
+	return &user{
+		Schemas:  []string{userSchema},
+		ID:       id,
+		UserName: Name(id),
+		Active:   active,
+		Meta:     resourceMeta{ResourceType: "User"},
+	}
+}
+
+// userInput is the body of the requests creating and replacing users. Attributes other than the
+// user name and whether the user is active are ignored, as they are not stored.
+type userInput struct {
+	UserName string `json:"userName"`
+	Active   *bool  `json:"active"`
+}
+
+func (h *Handler) userSubject(id string) *v1.SubjectReference {
+	return &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: h.mapping.Users.ObjectType, ObjectId: id}}
+}
+
+func (h *Handler) userProvisioned(ctx context.Context, id string) (bool, error) {
+	return h.provisioned(ctx, h.mapping.Users.Relationships, h.mapping.Users.ObjectType, id)
+}
+
+// setUserActive writes the relationships of the user if it is active, and otherwise removes it
+// from them and from all groups.
+func (h *Handler) setUserActive(ctx context.Context, id string, active bool) error {
+	rels := relationships(h.mapping.Users.Relationships, h.userSubject(id))
+	if active {
+		return h.write(ctx, updates(v1.RelationshipUpdate_OPERATION_TOUCH, rels))
+	}
+
+	if err := h.write(ctx, updates(v1.RelationshipUpdate_OPERATION_DELETE, rels)); err != nil {
+		return err
+	}
+	return h.deleteRelationships(ctx, &v1.RelationshipFilter{
+		ResourceType:     h.mapping.Groups.ObjectType,
+		OptionalRelation: h.mapping.Groups.MemberRelation,
+		OptionalSubjectFilter: &v1.SubjectFilter{
+			SubjectType:       h.mapping.Users.ObjectType,
+			OptionalSubjectId: id,
+		},
+	})
+}
+
+func (h *Handler) listUsers(r *http.Request) (int, any, error) {
+	id, err := parseFilter(r, "userName")
+	if err != nil {
+		return 0, nil, err
+	}
+
+	ids, err := h.provisionedIDs(r.Context(), h.mapping.Users.Relationships, h.mapping.Users.ObjectType, id)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	resp, err := paginate(r, ids, func(id string) (any, error) { return newUser(id, true), nil })
+	if err != nil {
+		return 0, nil, err
+	}
+	return http.StatusOK, resp, nil
+}
+
+func (h *Handler) createUser(r *http.Request) (int, any, error) {
+	var input userInput
+	if err := decodeBody(r, &input); err != nil {
+		return 0, nil, err
+	}
+	if input.UserName == "" {
+		return 0, nil, invalidValue("userName is required")
+	}
+
+	id := ObjectID(input.UserName)
+	exists, err := h.userProvisioned(r.Context(), id)
+	if err != nil {
+		return 0, nil, err
+	}
+	if exists {
+		return 0, nil, &scimError{status: http.StatusConflict, scimType: "uniqueness", detail: "user `" + input.UserName + "` already exists"}
+	}
+
+	active := input.Active == nil || *input.Active
+	if active {
+		if err := h.setUserActive(r.Context(), id, true); err != nil {
+			return 0, nil, err
+		}
+	}
+	return http.StatusCreated, newUser(id, active), nil
+}
+
+func (h *Handler) getUser(r *http.Request) (int, any, error) {
+	id := r.PathValue("id")
+	exists, err := h.userProvisioned(r.Context(), id)
+	if err != nil {
+		return 0, nil, err
+	}
+	if !exists {
+		return 0, nil, notFound("user", id)
+	}
+	return http.StatusOK, newUser(id, true), nil
+}
+
+// replaceUser sets whether the user is active. Users which are not provisioned, such as
+// deactivated ones, are provisioned again if they are set active.
+func (h *Handler) replaceUser(r *http.Request) (int, any, error) {
+	id := r.PathValue("id")
+	var input userInput
+	if err := decodeBody(r, &input); err != nil {
+		return 0, nil, err
+	}
+	if input.UserName != "" && ObjectID(input.UserName) != id {
+		return 0, nil, immutableName("userName", id)
+	}
+
+	active := input.Active == nil || *input.Active
+	if err := h.setUserActive(r.Context(), id, active); err != nil {
+		return 0, nil, err
+	}
+	return http.StatusOK, newUser(id, active), nil
+}
+
+// patchOperation is an operation of a PATCH request.
+type patchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value"`
+}
+
+type patchRequest struct {
+	Operations []patchOperation `json:"Operations"`
+}
+
+// parseBool parses a boolean value, which some identity providers send as a string.
+func parseBool(value json.RawMessage) (bool, error) {
+	var b bool
+	if err := json.Unmarshal(value, &b); err == nil {
+		return b, nil
+	}
+
+	var s string
+	if err := json.Unmarshal(value, &s); err != nil {
+		return false, invalidValue("invalid boolean %s", value)
+	}
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return false, invalidValue("invalid boolean %s", value)
+	}
+	return b, nil
+}
+
+// patchUser applies the changes of whether the user is active. Changes of other attributes are
+// ignored, as they are not stored.
+func (h *Handler) patchUser(r *http.Request) (int, any, error) {
+	id := r.PathValue("id")
+	var patch patchRequest
+	if err := decodeBody(r, &patch); err != nil {
+		return 0, nil, err
+	}
+
+	var active *bool
+	for _, op := range patch.Operations {
+		if !strings.EqualFold(op.Op, "add") && !strings.EqualFold(op.Op, "replace") {
+			if strings.EqualFold(op.Op, "remove") {
+				continue
+			}
+			return 0, nil, &scimError{status: http.StatusBadRequest, scimType: "invalidSyntax", detail: "unsupported operation `" + op.Op + "`"}
+		}
+
+		values := map[string]json.RawMessage{}
+		if op.Path == "" {
+			if err := json.Unmarshal(op.Value, &values); err != nil {
+				return 0, nil, invalidValue("the value of an operation without path must be an object")
+			}
+		} else {
+			values[op.Path] = op.Value
+		}
+
+		for attribute, value := range values {
+			switch {
+			case strings.EqualFold(attribute, "active"):
+				b, err := parseBool(value)
+				if err != nil {
+					return 0, nil, err
+				}
+				active = &b
+			case strings.EqualFold(attribute, "userName"):
+				var userName string
+				if err := json.Unmarshal(value, &userName); err != nil || ObjectID(userName) != id {
+					return 0, nil, immutableName("userName", id)
+				}
+			}
+		}
+	}
+
+	if active == nil {
+		return h.getUser(r)
+	}
+	if err := h.setUserActive(r.Context(), id, *active); err != nil {
+		return 0, nil, err
+	}
+	return http.StatusOK, newUser(id, *active), nil
+}
+
+func (h *Handler) deleteUser(r *http.Request) (int, any, error) {
+	id := r.PathValue("id")
+	exists, err := h.userProvisioned(r.Context(), id)
+	if err != nil {
+		return 0, nil, err
+	}
+	if !exists {
+		return 0, nil, notFound("user", id)
+	}
+
+	if err := h.setUserActive(r.Context(), id, false); err != nil {
+		return 0, nil, err
+	}
+	return http.StatusNoContent, nil, nil
+}
@@ -11,14 +11,20 @@ import (
 	dispatchv1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
 )
 
-// RegisterGrpcServices registers an internal dispatch service with the specified server.
+// RegisterGrpcServices registers an internal dispatch service with the specified server. If
+// hotEntries is set, the cache warming service is registered as well, dumping its entries.
 func RegisterGrpcServices(
 	srv *grpc.Server,
 	d dispatch.Dispatcher,
+	hotEntries dispatch_v1.HotCacheEntrySource,
 ) {
 	srv.RegisterService(&dispatchv1.DispatchService_ServiceDesc, dispatch_v1.NewDispatchServer(d))
 	healthSrv := grpcutil.NewAuthlessHealthServer()
 	healthSrv.SetServicesHealthy(&dispatchv1.DispatchService_ServiceDesc)
+	if hotEntries != nil {
+		srv.RegisterService(&dispatchv1.CacheWarmingService_ServiceDesc, dispatch_v1.NewCacheWarmingServer(hotEntries))
+		healthSrv.SetServicesHealthy(&dispatchv1.CacheWarmingService_ServiceDesc)
+	}
 	healthpb.RegisterHealthServer(srv, healthSrv)
 	reflection.Register(srv)
 }
@@ -18,6 +18,7 @@ import (
 	log "github.com/authzed/spicedb/internal/logging"
 	"github.com/authzed/spicedb/internal/services/shared"
 	dispatchv1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
 )
 
 const streamAPITimeout = 45 * time.Second
@@ -44,11 +45,16 @@ func NewDispatchServer(localDispatch dispatch.Dispatcher) dispatchv1.DispatchSer
 }
 
 func (ds *dispatchServer) DispatchCheck(ctx context.Context, req *dispatchv1.DispatchCheckRequest) (*dispatchv1.DispatchCheckResponse, error) {
+	// The names of received requests are interned, as they are kept by the requests dispatched
+	// from them and by in-flight and cached results for as long as these are.
+	tuple.InternCoreRR(req.ResourceRelation)
+	tuple.InternCoreONR(req.Subject)
 	resp, err := ds.localDispatch.DispatchCheck(ctx, req)
 	return resp, rewriteGraphError(ctx, err)
 }
 
 func (ds *dispatchServer) DispatchExpand(ctx context.Context, req *dispatchv1.DispatchExpandRequest) (*dispatchv1.DispatchExpandResponse, error) {
+	tuple.InternCoreONR(req.ResourceAndRelation)
 	resp, err := ds.localDispatch.DispatchExpand(ctx, req)
 	return resp, rewriteGraphError(ctx, err)
 }
@@ -57,6 +63,9 @@ func (ds *dispatchServer) DispatchLookupResources2(
 	req *dispatchv1.DispatchLookupResources2Request,
 	resp dispatchv1.DispatchService_DispatchLookupResources2Server,
 ) error {
+	tuple.InternCoreRR(req.ResourceRelation)
+	tuple.InternCoreRR(req.SubjectRelation)
+	tuple.InternCoreONR(req.TerminalSubject)
 	return ds.localDispatch.DispatchLookupResources2(req,
 		dispatch.WrapGRPCStream[*dispatchv1.DispatchLookupResources2Response](resp))
 }
@@ -65,6 +74,8 @@ func (ds *dispatchServer) DispatchLookupSubjects(
 	req *dispatchv1.DispatchLookupSubjectsRequest,
 	resp dispatchv1.DispatchService_DispatchLookupSubjectsServer,
 ) error {
+	tuple.InternCoreRR(req.ResourceRelation)
+	tuple.InternCoreRR(req.SubjectRelation)
 	return ds.localDispatch.DispatchLookupSubjects(req,
 		dispatch.WrapGRPCStream[*dispatchv1.DispatchLookupSubjectsResponse](resp))
 }
@@ -0,0 +1,36 @@
+package dispatch
+
+import (
+	dispatchv1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+)
+
+// HotCacheEntrySource is the source of the dispatch cache entries with the most hits.
+type HotCacheEntrySource interface {
+	// HotCacheEntries returns up to limit of the cache entries with the most hits, in descending
+	// order of hits.
+	HotCacheEntries(limit int) []*dispatchv1.DumpHotCacheEntriesResponse
+}
+
+type cacheWarmingServer struct {
+	dispatchv1.UnimplementedCacheWarmingServiceServer
+
+	source HotCacheEntrySource
+}
+
+// NewCacheWarmingServer creates a server from which peers can warm their dispatch cache with the
+// hottest entries of the source.
+func NewCacheWarmingServer(source HotCacheEntrySource) dispatchv1.CacheWarmingServiceServer {
+	return &cacheWarmingServer{source: source}
+}
+
+func (cws *cacheWarmingServer) DumpHotCacheEntries(
+	req *dispatchv1.DumpHotCacheEntriesRequest,
+	resp dispatchv1.CacheWarmingService_DumpHotCacheEntriesServer,
+) error {
+	for _, entry := range cws.source.HotCacheEntries(int(req.Limit)) {
+		if err := resp.Send(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+// Package extauthz implements the external authorization service of Envoy, so that API gateways
+// built on Envoy can authorize requests with SpiceDB without a shim service.
+//
+// Each request is mapped to a permission check by the first rule of the configured ruleset which
+// matches its method and path, filling the resource and subject of the check from its path and
+// headers. The request is allowed if the subject has the permission on the resource.
+package extauthz
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	authv3 "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
+	typev3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	grpcvalidate "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/validator"
+	rpcstatus "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/authzed/spicedb/internal/middleware"
+	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
+	"github.com/authzed/spicedb/internal/middleware/usagemetrics"
+	"github.com/authzed/spicedb/internal/services/shared"
+	"github.com/authzed/spicedb/pkg/middleware/consistency"
+)
+
+const serviceLabel = "extauthz"
+
+type authorizationServer struct {
+	authv3.UnimplementedAuthorizationServer
+	shared.WithServiceSpecificInterceptors
+
+	permissions v1.PermissionsServiceServer
+	rules       *Rules
+}
+
+// NewAuthorizationServer creates an instance of the Envoy external authorization server, checking
+// the permissions mapped from the requests by the rules with the given permissions server.
+func NewAuthorizationServer(permissions v1.PermissionsServiceServer, rules *Rules) authv3.AuthorizationServer {
+	return &authorizationServer{
+		WithServiceSpecificInterceptors: shared.WithServiceSpecificInterceptors{
+			Unary: middleware.ChainUnaryServer(
+				grpcvalidate.UnaryServerInterceptor(),
+				usagemetrics.UnaryServerInterceptor(),
+			),
+		},
+		permissions: permissions,
+		rules:       rules,
+	}
+}
+
+func (as *authorizationServer) Check(ctx context.Context, req *authv3.CheckRequest) (*authv3.CheckResponse, error) {
+	httpReq := req.GetAttributes().GetRequest().GetHttp()
+	if httpReq == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "only HTTP requests can be authorized")
+	}
+
+	path, _, _ := strings.Cut(httpReq.Path, "?")
+	rule, params := as.rules.matchingRule(httpReq.Method, path)
+	switch {
+	case rule == nil && as.rules.DefaultAllow, rule != nil && rule.Allow:
+		return allowed(), nil
+	case rule == nil:
+		return denied(typev3.StatusCode_Forbidden, "no rule matches the request"), nil
+	}
+
+	lookup := func(name string) (string, bool) {
+		if header, isHeader := strings.CutPrefix(name, "header:"); isHeader {
+			value := httpReq.Headers[strings.ToLower(header)]
+			return value, value != ""
+		}
+		return params[name], true
+	}
+
+	subject, err := expandTemplate(rule.Subject, lookup)
+	if err != nil {
+		return denied(typev3.StatusCode_Unauthorized, "the request does not identify its subject"), nil
+	}
+	resource, err := expandTemplate(rule.Resource, lookup)
+	if err != nil {
+		return denied(typev3.StatusCode_Forbidden, "the request does not identify its resource"), nil
+	}
+
+	checkReq, err := checkRequest(resource, rule.Permission, subject)
+	if err != nil {
+		return denied(typev3.StatusCode_Forbidden, err.Error()), nil
+	}
+	if err := checkReq.Validate(); err != nil {
+		return denied(typev3.StatusCode_Forbidden, err.Error()), nil
+	}
+	if err := consistency.AddRevisionToContext(ctx, checkReq, datastoremw.MustFromContext(ctx), serviceLabel); err != nil {
+		return nil, err
+	}
+
+	resp, err := as.permissions.CheckPermission(ctx, checkReq)
+	if err != nil {
+		return nil, err
+	}
+
+	// Conditional permissions are denied, as the requests hold no caveat context.
+	if resp.Permissionship != v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION {
+		return denied(typev3.StatusCode_Forbidden, fmt.Sprintf("%s does not have permission %s on %s", subject, rule.Permission, resource)), nil
+	}
+	return allowed(), nil
+}
+
+// checkRequest returns the check of the permission of the subject, of the form `type:id` or
+// `type:id#relation`, on the resource, of the form `type:id`.
+func checkRequest(resource, permission, subject string) (*v1.CheckPermissionRequest, error) {
+	resourceType, resourceID, ok := strings.Cut(resource, ":")
+	if !ok {
+		return nil, fmt.Errorf("invalid resource `%s`: expected `type:id`", resource)
+	}
+
+	subjectObject, subjectRelation, _ := strings.Cut(subject, "#")
+	subjectType, subjectID, ok := strings.Cut(subjectObject, ":")
+	if !ok {
+		return nil, fmt.Errorf("invalid subject `%s`: expected `type:id` or `type:id#relation`", subject)
+	}
+
+	return &v1.CheckPermissionRequest{
+		Consistency: &v1.Consistency{Requirement: &v1.Consistency_MinimizeLatency{MinimizeLatency: true}},
+		Resource:    &v1.ObjectReference{ObjectType: resourceType, ObjectId: resourceID},
+		Permission:  permission,
+		Subject: &v1.SubjectReference{
+			Object:           &v1.ObjectReference{ObjectType: subjectType, ObjectId: subjectID},
+			OptionalRelation: subjectRelation,
+		},
+	}, nil
+}
+
+func allowed() *authv3.CheckResponse {
+	return &authv3.CheckResponse{
+		Status:       &rpcstatus.Status{Code: int32(codes.OK)},
+		HttpResponse: &authv3.CheckResponse_OkResponse{OkResponse: &authv3.OkHttpResponse{}},
+	}
+}
+
+func denied(code typev3.StatusCode, reason string) *authv3.CheckResponse {
+	return &authv3.CheckResponse{
+		Status: &rpcstatus.Status{Code: int32(codes.PermissionDenied), Message: reason},
+		HttpResponse: &authv3.CheckResponse_DeniedResponse{DeniedResponse: &authv3.DeniedHttpResponse{
+			Status: &typev3.HttpStatus{Code: code},
+			Body:   reason,
+		}},
+	}
+}
@@ -0,0 +1,133 @@
+package extauthz
+
+import (
+	"context"
+	"testing"
+
+	authv3 "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
+	typev3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+
+	"github.com/authzed/spicedb/internal/datastore/dsfortesting"
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/internal/dispatch/graph"
+	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
+	v1svc "github.com/authzed/spicedb/internal/services/v1"
+	"github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/pkg/middleware/consistency"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+const testSchema = `
+	definition user {}
+
+	definition group {
+		relation member: user
+	}
+
+	definition document {
+		relation viewer: user | group#member
+		permission view = viewer
+	}
+`
+
+const testRules = `
+rules:
+  - path: /healthz
+    allow: true
+  - methods: [get]
+    path: /documents/{id}
+    resource: document:{id}
+    permission: view
+    subject: user:{header:X-User-ID}
+  - path: /groups/{group}/documents/{path...}
+    resource: document:{path}
+    permission: view
+    subject: group:{group}#member
+`
+
+func TestParseRules(t *testing.T) {
+	rules, err := ParseRules([]byte(testRules))
+	require.NoError(t, err)
+	require.Len(t, rules.Rules, 3)
+	require.Equal(t, []string{"GET"}, rules.Rules[1].Methods)
+	require.False(t, rules.DefaultAllow)
+
+	for _, tc := range []struct {
+		name          string
+		rules         string
+		expectedError string
+	}{
+		{"relative path", "rules: [{path: documents, allow: true}]", "must start with `/`"},
+		{"missing permission", "rules: [{path: /documents, resource: document:x, subject: user:y}]", "resource, permission and subject are required"},
+		{"allow with check", "rules: [{path: /documents, allow: true, permission: view}]", "cannot check a permission"},
+		{"unknown parameter", "rules: [{path: '/documents/{id}', resource: 'document:{name}', permission: view, subject: user:y}]", "unknown parameter `name`"},
+		{"rest not last", "rules: [{path: '/documents/{path...}/view', allow: true}]", "must be the last segment"},
+		{"unknown field", "rules: [{path: /documents, allowed: true}]", "field allowed not found"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseRules([]byte(tc.rules))
+			require.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	rules, err := ParseRules([]byte(testRules))
+	require.NoError(t, err)
+
+	rawDS, err := dsfortesting.NewMemDBDatastoreForTesting(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+
+	ds, _ := testfixtures.DatastoreFromSchemaAndTestRelationships(rawDS, testSchema, []tuple.Relationship{
+		tuple.MustParse("document:roadmap#viewer@user:anne"),
+		tuple.MustParse("document:reports/q1#viewer@group:finance#member"),
+	}, require.New(t))
+	t.Cleanup(func() { ds.Close() })
+
+	dispatcher := graph.NewLocalOnlyDispatcher(10, 100)
+	t.Cleanup(func() { dispatcher.Close() })
+
+	srv := NewAuthorizationServer(v1svc.NewPermissionsServer(dispatcher, v1svc.PermissionsServerConfig{
+		MaximumAPIDepth: 50,
+	}), rules)
+
+	for _, tc := range []struct {
+		name           string
+		method         string
+		path           string
+		headers        map[string]string
+		expectedStatus typev3.StatusCode
+	}{
+		{"allowed without check", "GET", "/healthz", nil, typev3.StatusCode_OK},
+		{"permitted", "GET", "/documents/roadmap?full=true", map[string]string{"x-user-id": "anne"}, typev3.StatusCode_OK},
+		{"not permitted", "GET", "/documents/roadmap", map[string]string{"x-user-id": "bob"}, typev3.StatusCode_Forbidden},
+		{"missing subject", "GET", "/documents/roadmap", nil, typev3.StatusCode_Unauthorized},
+		{"method not matched", "DELETE", "/documents/roadmap", map[string]string{"x-user-id": "anne"}, typev3.StatusCode_Forbidden},
+		{"rest of path", "PUT", "/groups/finance/documents/reports/q1", nil, typev3.StatusCode_OK},
+		{"rest of path not permitted", "PUT", "/groups/finance/documents/reports/q2", nil, typev3.StatusCode_Forbidden},
+		{"no rule", "GET", "/folders/roadmap", nil, typev3.StatusCode_Forbidden},
+		{"invalid resource", "GET", "/documents/road$map", map[string]string{"x-user-id": "anne"}, typev3.StatusCode_Forbidden},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := consistency.ContextWithHandle(datastoremw.ContextWithDatastore(context.Background(), ds))
+			resp, err := srv.Check(ctx, &authv3.CheckRequest{Attributes: &authv3.AttributeContext{
+				Request: &authv3.AttributeContext_Request{Http: &authv3.AttributeContext_HttpRequest{
+					Method:  tc.method,
+					Path:    tc.path,
+					Headers: tc.headers,
+				}},
+			}})
+			require.NoError(t, err)
+
+			if tc.expectedStatus == typev3.StatusCode_OK {
+				require.Equal(t, int32(codes.OK), resp.Status.Code)
+				require.NotNil(t, resp.GetOkResponse())
+				return
+			}
+			require.Equal(t, int32(codes.PermissionDenied), resp.Status.Code)
+			require.Equal(t, tc.expectedStatus, resp.GetDeniedResponse().Status.Code)
+		})
+	}
+}
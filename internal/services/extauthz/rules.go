@@ -0,0 +1,213 @@
+package extauthz
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// Rules is the ruleset mapping HTTP requests to permission checks, as read from YAML:
+//
+//	defaultAllow: false
+//	rules:
+//	  - path: /healthz
+//	    allow: true
+//	  - methods: [GET, HEAD]
+//	    path: /documents/{id}
+//	    resource: document:{id}
+//	    permission: view
+//	    subject: user:{header:x-user-id}
+//
+// The rules are matched in order against the method and path of each request, and the first
+// which matches is applied.
+type Rules struct {
+	// Rules are the rules to match requests against.
+	Rules []*Rule `yaml:"rules"`
+
+	// DefaultAllow allows the requests matched by no rule, which are denied otherwise.
+	DefaultAllow bool `yaml:"defaultAllow"`
+}
+
+// Rule maps the requests matching its methods and path to a permission check.
+//
+// The path is matched segment by segment: a segment `{name}` matches any segment, and a last
+// segment `{name...}` matches the rest of the path, capturing them as path parameters. The
+// resource, of the form `type:id`, and the subject, of the form `type:id` or `type:id#relation`,
+// are templates in which `{name}` is replaced by a path parameter and `{header:name}` by the value
+// of a request header.
+type Rule struct {
+	// Methods are the HTTP methods matched by the rule. Any method is matched if empty.
+	Methods []string `yaml:"methods"`
+
+	// Path is the pattern of the paths matched by the rule, ignoring their query string.
+	Path string `yaml:"path"`
+
+	// Allow allows the requests matched by the rule, without checking any permission.
+	Allow bool `yaml:"allow"`
+
+	Resource   string `yaml:"resource"`
+	Permission string `yaml:"permission"`
+	Subject    string `yaml:"subject"`
+
+	segments []string
+}
+
+// LoadRules reads the ruleset from the YAML file at the path.
+func LoadRules(path string) (*Rules, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ext_authz rules: %w", err)
+	}
+	return ParseRules(contents)
+}
+
+// ParseRules parses and validates a ruleset from YAML.
+func ParseRules(contents []byte) (*Rules, error) {
+	decoder := yamlv3.NewDecoder(bytes.NewReader(contents))
+	decoder.KnownFields(true)
+
+	var rules Rules
+	if err := decoder.Decode(&rules); err != nil {
+		return nil, fmt.Errorf("failed to parse ext_authz rules: %w", err)
+	}
+
+	for index, rule := range rules.Rules {
+		if err := rule.compile(); err != nil {
+			return nil, fmt.Errorf("invalid ext_authz rule %d: %w", index, err)
+		}
+	}
+	return &rules, nil
+}
+
+func (r *Rule) compile() error {
+	if !strings.HasPrefix(r.Path, "/") {
+		return fmt.Errorf("path `%s` must start with `/`", r.Path)
+	}
+
+	r.segments = strings.Split(strings.TrimPrefix(r.Path, "/"), "/")
+	params := map[string]struct{}{}
+	for index, segment := range r.segments {
+		name, isParam := pathParam(segment)
+		if !isParam {
+			continue
+		}
+
+		if strings.HasSuffix(name, "...") {
+			if index != len(r.segments)-1 {
+				return fmt.Errorf("path parameter `%s` must be the last segment", segment)
+			}
+			name = strings.TrimSuffix(name, "...")
+		}
+		if name == "" {
+			return fmt.Errorf("path parameter `%s` must be named", segment)
+		}
+		params[name] = struct{}{}
+	}
+
+	for index, method := range r.Methods {
+		r.Methods[index] = strings.ToUpper(method)
+	}
+
+	if r.Allow {
+		if r.Resource != "" || r.Permission != "" || r.Subject != "" {
+			return errors.New("a rule allowing requests cannot check a permission")
+		}
+		return nil
+	}
+
+	if r.Resource == "" || r.Permission == "" || r.Subject == "" {
+		return errors.New("resource, permission and subject are required")
+	}
+	for _, template := range []string{r.Resource, r.Subject} {
+		if _, err := expandTemplate(template, func(name string) (string, bool) {
+			if _, isHeader := strings.CutPrefix(name, "header:"); isHeader {
+				return "x", true
+			}
+			_, ok := params[name]
+			return "x", ok
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// match returns the path parameters of the request if it is matched by the rule.
+func (r *Rule) match(method, path string) (map[string]string, bool) {
+	if len(r.Methods) > 0 && !slices.Contains(r.Methods, method) {
+		return nil, false
+	}
+
+	segments := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	params := map[string]string{}
+	for index, pattern := range r.segments {
+		name, isParam := pathParam(pattern)
+		if rest, isRest := strings.CutSuffix(name, "..."); isParam && isRest {
+			if index >= len(segments) {
+				return nil, false
+			}
+			params[rest] = strings.Join(segments[index:], "/")
+			return params, true
+		}
+
+		if index >= len(segments) {
+			return nil, false
+		}
+		switch {
+		case isParam && segments[index] != "":
+			params[name] = segments[index]
+		case isParam || pattern != segments[index]:
+			return nil, false
+		}
+	}
+	return params, len(segments) == len(r.segments)
+}
+
+// matchingRule returns the first rule matching the request, and its path parameters.
+func (rs *Rules) matchingRule(method, path string) (*Rule, map[string]string) {
+	for _, rule := range rs.Rules {
+		if params, ok := rule.match(method, path); ok {
+			return rule, params
+		}
+	}
+	return nil, nil
+}
+
+func pathParam(segment string) (string, bool) {
+	if !strings.HasPrefix(segment, "{") || !strings.HasSuffix(segment, "}") {
+		return "", false
+	}
+	return segment[1 : len(segment)-1], true
+}
+
+// expandTemplate replaces each `{name}` of the template with the value returned by lookup.
+func expandTemplate(template string, lookup func(name string) (string, bool)) (string, error) {
+	var expanded strings.Builder
+	for {
+		start := strings.IndexByte(template, '{')
+		if start < 0 {
+			expanded.WriteString(template)
+			return expanded.String(), nil
+		}
+
+		end := strings.IndexByte(template[start:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated parameter in `%s`", template)
+		}
+
+		name := template[start+1 : start+end]
+		value, ok := lookup(name)
+		if !ok {
+			return "", fmt.Errorf("unknown parameter `%s`", name)
+		}
+
+		expanded.WriteString(template[:start])
+		expanded.WriteString(value)
+		template = template[start+end+1:]
+	}
+}
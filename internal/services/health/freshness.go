@@ -0,0 +1,160 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/authzed/spicedb/internal/datastore/revisions"
+	"github.com/authzed/spicedb/pkg/datastore"
+)
+
+// maxReplicaLagSamples bounds the number of primary head revisions remembered while waiting for
+// replicas to catch up.
+const maxReplicaLagSamples = 100
+
+var optimizedRevisionAgeGauge = promauto.NewGauge(prometheus.GaugeOpts{
+	Namespace: "spicedb",
+	Subsystem: "health",
+	Name:      "optimized_revision_age_seconds",
+	Help:      "age of the optimized revision at the last freshness check, for datastores with timestamped revisions",
+})
+
+var replicaLagGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: "spicedb",
+	Subsystem: "health",
+	Name:      "replica_lag_seconds",
+	Help:      "lag of each read replica behind the primary at the last freshness check",
+}, []string{"replica"})
+
+// FreshnessConfig configures the checks of the freshness of the revisions served.
+type FreshnessConfig struct {
+	// CheckInterval is the interval between freshness checks once the server is ready. Zero
+	// disables the checks.
+	CheckInterval time.Duration
+
+	// MaxRevisionStaleness is the maximum age of the optimized revision. Zero disables the check.
+	MaxRevisionStaleness time.Duration
+
+	// MaxReplicaLag is the maximum time a read replica may take to serve a revision written to
+	// the primary. Zero disables the check.
+	MaxReplicaLag time.Duration
+}
+
+// FreshnessChecker checks that the revisions served by a datastore are not stale: that the
+// optimized revision is advancing and that read replicas, if any, are not lagging too far behind
+// the primary.
+//
+// The age of the optimized revision can only be measured for datastores whose revisions carry a
+// timestamp, such as CockroachDB and Spanner. For the other datastores, which compute the
+// optimized revision from their transaction log, the checker only verifies that the optimized
+// revision can be computed and never moves backwards.
+type FreshnessChecker struct {
+	config   FreshnessConfig
+	ds       datastore.ReadOnlyDatastore
+	replicas []datastore.ReadOnlyDatastore
+	now      func() time.Time
+
+	lastOptimized datastore.Revision
+	headSamples   []headSample
+}
+
+type headSample struct {
+	revision datastore.Revision
+	at       time.Time
+}
+
+// NewFreshnessChecker returns a checker of the freshness of the revisions of the given datastore
+// and its read replicas.
+func NewFreshnessChecker(config FreshnessConfig, ds datastore.ReadOnlyDatastore, replicas []datastore.ReadOnlyDatastore) *FreshnessChecker {
+	return &FreshnessChecker{
+		config:   config,
+		ds:       ds,
+		replicas: replicas,
+		now:      time.Now,
+	}
+}
+
+// Check returns an error describing why the revisions served are stale, or nil if they are fresh.
+// It must not be called concurrently.
+func (fc *FreshnessChecker) Check(ctx context.Context) error {
+	if err := fc.checkOptimizedRevision(ctx); err != nil {
+		return err
+	}
+	return fc.checkReplicaLag(ctx)
+}
+
+func (fc *FreshnessChecker) checkOptimizedRevision(ctx context.Context) error {
+	optimized, err := fc.ds.OptimizedRevision(ctx)
+	if err != nil {
+		return fmt.Errorf("could not compute optimized revision: %w", err)
+	}
+
+	if fc.config.MaxRevisionStaleness <= 0 {
+		return nil
+	}
+
+	if timestamped, ok := optimized.(revisions.WithTimestampRevision); ok {
+		age := fc.now().Sub(time.Unix(0, timestamped.TimestampNanoSec()))
+		optimizedRevisionAgeGauge.Set(age.Seconds())
+		if age > fc.config.MaxRevisionStaleness {
+			return fmt.Errorf("optimized revision %s is %s old, exceeding the maximum staleness of %s", optimized, age, fc.config.MaxRevisionStaleness)
+		}
+		return nil
+	}
+
+	if fc.lastOptimized != nil && optimized.LessThan(fc.lastOptimized) {
+		return fmt.Errorf("optimized revision %s moved backwards from %s", optimized, fc.lastOptimized)
+	}
+	fc.lastOptimized = optimized
+	return nil
+}
+
+func (fc *FreshnessChecker) checkReplicaLag(ctx context.Context) error {
+	if fc.config.MaxReplicaLag <= 0 || len(fc.replicas) == 0 {
+		return nil
+	}
+
+	head, err := fc.ds.HeadRevision(ctx)
+	if err != nil {
+		return fmt.Errorf("could not compute head revision: %w", err)
+	}
+
+	now := fc.now()
+	fc.headSamples = append(fc.headSamples, headSample{head, now})
+	if len(fc.headSamples) > maxReplicaLagSamples {
+		fc.headSamples = fc.headSamples[len(fc.headSamples)-maxReplicaLagSamples:]
+	}
+
+	// Replicas serve revisions in order, so the lag of a replica is the age of the oldest sample
+	// it cannot serve yet. Samples served by every replica are no longer needed.
+	caughtUp := len(fc.headSamples)
+	var lagErr error
+	for index, replica := range fc.replicas {
+		served := 0
+		for _, sample := range fc.headSamples {
+			if err := replica.CheckRevision(ctx, sample.revision); err != nil {
+				break
+			}
+			served++
+		}
+		caughtUp = min(caughtUp, served)
+
+		var lag time.Duration
+		if served < len(fc.headSamples) {
+			lag = now.Sub(fc.headSamples[served].at)
+		}
+		replicaLagGauge.WithLabelValues(strconv.Itoa(index)).Set(lag.Seconds())
+
+		if lag > fc.config.MaxReplicaLag && lagErr == nil {
+			lagErr = fmt.Errorf("read replica %d is %s behind the primary, exceeding the maximum lag of %s", index, lag, fc.config.MaxReplicaLag)
+		}
+	}
+
+	fc.headSamples = fc.headSamples[caughtUp:]
+	return lagErr
+}
@@ -0,0 +1,103 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/revisions"
+	"github.com/authzed/spicedb/pkg/datastore"
+)
+
+type fakeRevisionDatastore struct {
+	datastore.ReadOnlyDatastore
+
+	optimized datastore.Revision
+	head      datastore.Revision
+	err       error
+}
+
+func (ds *fakeRevisionDatastore) OptimizedRevision(_ context.Context) (datastore.Revision, error) {
+	return ds.optimized, ds.err
+}
+
+func (ds *fakeRevisionDatastore) HeadRevision(_ context.Context) (datastore.Revision, error) {
+	return ds.head, ds.err
+}
+
+func (ds *fakeRevisionDatastore) CheckRevision(_ context.Context, revision datastore.Revision) error {
+	if revision.GreaterThan(ds.head) {
+		return errors.New("revision not yet replicated")
+	}
+	return nil
+}
+
+func TestFreshnessCheckerTimestampedRevisions(t *testing.T) {
+	now := time.Now()
+	ds := &fakeRevisionDatastore{optimized: revisions.NewForTime(now.Add(-5 * time.Second))}
+
+	checker := NewFreshnessChecker(FreshnessConfig{MaxRevisionStaleness: 10 * time.Second}, ds, nil)
+	checker.now = func() time.Time { return now }
+	require.NoError(t, checker.Check(context.Background()))
+
+	now = now.Add(10 * time.Second)
+	require.ErrorContains(t, checker.Check(context.Background()), "exceeding the maximum staleness")
+
+	ds.optimized = revisions.NewForTime(now)
+	require.NoError(t, checker.Check(context.Background()))
+
+	ds.err = errors.New("connection refused")
+	require.ErrorContains(t, checker.Check(context.Background()), "could not compute optimized revision")
+}
+
+func TestFreshnessCheckerTransactionRevisions(t *testing.T) {
+	ds := &fakeRevisionDatastore{optimized: revisions.NewForTransactionID(10)}
+
+	checker := NewFreshnessChecker(FreshnessConfig{MaxRevisionStaleness: time.Second}, ds, nil)
+	require.NoError(t, checker.Check(context.Background()))
+	require.NoError(t, checker.Check(context.Background()))
+
+	ds.optimized = revisions.NewForTransactionID(12)
+	require.NoError(t, checker.Check(context.Background()))
+
+	ds.optimized = revisions.NewForTransactionID(11)
+	require.ErrorContains(t, checker.Check(context.Background()), "moved backwards")
+}
+
+func TestFreshnessCheckerReplicaLag(t *testing.T) {
+	now := time.Now()
+	primary := &fakeRevisionDatastore{optimized: revisions.NewForTransactionID(1), head: revisions.NewForTransactionID(1)}
+	upToDate := &fakeRevisionDatastore{head: revisions.NewForTransactionID(1)}
+	lagging := &fakeRevisionDatastore{head: revisions.NewForTransactionID(1)}
+
+	checker := NewFreshnessChecker(FreshnessConfig{MaxReplicaLag: 25 * time.Second}, primary, []datastore.ReadOnlyDatastore{upToDate, lagging})
+	checker.now = func() time.Time { return now }
+	require.NoError(t, checker.Check(context.Background()))
+	require.Empty(t, checker.headSamples)
+
+	// The lagging replica stops replicating, but is within the maximum lag for a while.
+	for i := uint64(2); i <= 4; i++ {
+		now = now.Add(10 * time.Second)
+		primary.head = revisions.NewForTransactionID(i)
+		upToDate.head = revisions.NewForTransactionID(i)
+		require.NoError(t, checker.Check(context.Background()))
+	}
+	require.Len(t, checker.headSamples, 3)
+
+	now = now.Add(10 * time.Second)
+	primary.head = revisions.NewForTransactionID(5)
+	upToDate.head = revisions.NewForTransactionID(5)
+	require.ErrorContains(t, checker.Check(context.Background()), "read replica 1 is 30s behind the primary")
+
+	// Catching up partially within the maximum lag is fresh again.
+	lagging.head = revisions.NewForTransactionID(4)
+	require.NoError(t, checker.Check(context.Background()))
+	require.Len(t, checker.headSamples, 2)
+
+	lagging.head = revisions.NewForTransactionID(5)
+	require.NoError(t, checker.Check(context.Background()))
+	require.Empty(t, checker.headSamples)
+}
@@ -2,6 +2,7 @@ package health
 
 import (
 	"context"
+	"sync"
 	"time"
 
 	"github.com/authzed/grpcutil"
@@ -15,12 +16,32 @@ import (
 
 const datastoreReadyTimeout = time.Millisecond * 500
 
+// DatastoreWritesHealthCheckKey is the name of the health check service which reports NOT_SERVING
+// while datastore writes are degraded, even though the server continues serving reads.
+const DatastoreWritesHealthCheckKey = "spicedb.datastore.writes"
+
 // NewHealthManager creates and returns a new health manager that checks the IsReady
 // status of the given dispatcher and datastore checker and sets the health check to
 // return healthy once both have gone to true.
-func NewHealthManager(dispatcher dispatch.Dispatcher, dsc DatastoreChecker) Manager {
+func NewHealthManager(dispatcher dispatch.Dispatcher, dsc DatastoreChecker, opts ...Option) Manager {
 	healthSvc := grpcutil.NewAuthlessHealthServer()
-	return &healthManager{healthSvc, dispatcher, dsc, map[string]struct{}{}}
+	hm := &healthManager{healthSvc: healthSvc, dispatcher: dispatcher, dsc: dsc, serviceNames: map[string]struct{}{}}
+	for _, opt := range opts {
+		opt(hm)
+	}
+	return hm
+}
+
+// Option is an option for the health manager.
+type Option func(hm *healthManager)
+
+// WithFreshnessChecker makes the health manager keep checking the freshness of the datastore
+// revisions once the server is ready, reporting NOT_SERVING while they are stale so that
+// traffic is not routed to a server serving very stale data.
+func WithFreshnessChecker(checker *FreshnessChecker) Option {
+	return func(hm *healthManager) {
+		hm.freshnessChecker = checker
+	}
 }
 
 // DatastoreChecker is an interface for determining if the datastore is ready for
@@ -41,6 +62,10 @@ type Manager interface {
 
 	// Checker returns a function that can be run via an errgroup to perform the health checks.
 	Checker(ctx context.Context) func() error
+
+	// SetWritesDegraded sets whether datastore writes are degraded, as reported by the
+	// DatastoreWritesHealthCheckKey service once the server is ready.
+	SetWritesDegraded(degraded bool)
 }
 
 type healthManager struct {
@@ -48,6 +73,13 @@ type healthManager struct {
 	dispatcher   dispatch.Dispatcher
 	dsc          DatastoreChecker
 	serviceNames map[string]struct{}
+
+	freshnessChecker *FreshnessChecker
+
+	lock           sync.Mutex
+	ready          bool
+	stale          bool
+	writesDegraded bool
 }
 
 func (hm *healthManager) HealthSvc() *grpcutil.AuthlessHealthServer {
@@ -82,10 +114,18 @@ func (hm *healthManager) Checker(ctx context.Context) func() error {
 
 			isReady := hm.checkIsReady(ctx)
 			if isReady {
+				hm.lock.Lock()
 				for serviceName := range hm.serviceNames {
 					hm.healthSvc.Server.SetServingStatus(serviceName, healthpb.HealthCheckResponse_SERVING)
 				}
-				return nil
+				hm.ready = true
+				hm.reportWritesStatus()
+				hm.lock.Unlock()
+
+				if hm.freshnessChecker == nil || hm.freshnessChecker.config.CheckInterval <= 0 {
+					return nil
+				}
+				return hm.checkFreshness(ctx)
 			}
 
 			nextPush := backoffInterval.NextBackOff()
@@ -98,6 +138,56 @@ func (hm *healthManager) Checker(ctx context.Context) func() error {
 	}
 }
 
+// checkFreshness periodically checks the freshness of the datastore revisions until the context
+// is canceled, reporting NOT_SERVING for all services while they are stale.
+func (hm *healthManager) checkFreshness(ctx context.Context) error {
+	interval := hm.freshnessChecker.config.CheckInterval
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			log.Ctx(ctx).Info().Msg("datastore revision freshness check canceled")
+			return nil
+		}
+
+		checkCtx, cancel := context.WithTimeout(ctx, interval)
+		err := hm.freshnessChecker.Check(checkCtx)
+		cancel()
+		if err != nil && ctx.Err() != nil {
+			return nil
+		}
+
+		hm.setStale(ctx, err)
+	}
+}
+
+func (hm *healthManager) setStale(ctx context.Context, err error) {
+	hm.lock.Lock()
+	defer hm.lock.Unlock()
+
+	stale := err != nil
+	if stale == hm.stale {
+		return
+	}
+	hm.stale = stale
+
+	status := healthpb.HealthCheckResponse_SERVING
+	if stale {
+		log.Ctx(ctx).Warn().Err(err).Msg("datastore revisions are stale, reporting not serving")
+		status = healthpb.HealthCheckResponse_NOT_SERVING
+	} else {
+		log.Ctx(ctx).Info().Msg("datastore revisions are fresh again, reporting serving")
+	}
+
+	for serviceName := range hm.serviceNames {
+		hm.healthSvc.Server.SetServingStatus(serviceName, status)
+	}
+	hm.reportWritesStatus()
+}
+
 func (hm *healthManager) checkIsReady(ctx context.Context) bool {
 	log.Ctx(ctx).Debug().Msg("checking if datastore and dispatcher are ready")
 
@@ -123,3 +213,26 @@ func (hm *healthManager) checkIsReady(ctx context.Context) bool {
 	log.Ctx(ctx).Debug().Bool("datastoreReady", true).Bool("dispatchReady", true).Msg("completed dispatcher and datastore readiness checks")
 	return true
 }
+
+func (hm *healthManager) SetWritesDegraded(degraded bool) {
+	hm.lock.Lock()
+	defer hm.lock.Unlock()
+
+	hm.writesDegraded = degraded
+	if hm.ready {
+		hm.reportWritesStatus()
+	}
+}
+
+// reportWritesStatus reports the status of datastore writes. Must be called with the lock held.
+func (hm *healthManager) reportWritesStatus() {
+	if _, ok := hm.serviceNames[DatastoreWritesHealthCheckKey]; !ok {
+		return
+	}
+
+	status := healthpb.HealthCheckResponse_SERVING
+	if hm.writesDegraded || hm.stale {
+		status = healthpb.HealthCheckResponse_NOT_SERVING
+	}
+	hm.healthSvc.Server.SetServingStatus(DatastoreWritesHealthCheckKey, status)
+}
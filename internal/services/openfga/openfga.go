@@ -0,0 +1,404 @@
+// Package openfga implements the check, expand, read and write methods of the OpenFGA API on top
+// of the SpiceDB permissions service, so that OpenFGA clients can be pointed at SpiceDB.
+//
+// OpenFGA stores map to the whole SpiceDB instance, and authorization model IDs are ignored in
+// favor of the current schema. Tuples map to relationships: an OpenFGA object `type:id` is the
+// resource, its relation the relation, and an OpenFGA user (`type:id`, `type:id#relation` or
+// `type:*`) the subject. Conditions map to caveats.
+package openfga
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	grpcvalidate "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/validator"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/authzed/spicedb/internal/middleware"
+	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
+	"github.com/authzed/spicedb/internal/middleware/usagemetrics"
+	"github.com/authzed/spicedb/internal/services/shared"
+	"github.com/authzed/spicedb/pkg/middleware/consistency"
+)
+
+const (
+	// defaultReadPageSize is the page size of Read requests which do not set one, as in OpenFGA.
+	defaultReadPageSize = 50
+
+	serviceLabel = "openfga"
+)
+
+type openFGAServer struct {
+	openfgav1.UnimplementedOpenFGAServiceServer
+	shared.WithServiceSpecificInterceptors
+
+	permissions v1.PermissionsServiceServer
+	storeID     string
+}
+
+// NewOpenFGAServer creates an instance of the OpenFGA compatibility server, translating requests
+// into calls of the given permissions server. If storeID is not empty, requests for other stores
+// are rejected; otherwise, every store ID refers to the SpiceDB instance.
+func NewOpenFGAServer(permissions v1.PermissionsServiceServer, storeID string) openfgav1.OpenFGAServiceServer {
+	return &openFGAServer{
+		WithServiceSpecificInterceptors: shared.WithServiceSpecificInterceptors{
+			Unary: middleware.ChainUnaryServer(
+				grpcvalidate.UnaryServerInterceptor(),
+				usagemetrics.UnaryServerInterceptor(),
+			),
+		},
+		permissions: permissions,
+		storeID:     storeID,
+	}
+}
+
+func (ofs *openFGAServer) Check(ctx context.Context, req *openfgav1.CheckRequest) (*openfgav1.CheckResponse, error) {
+	if err := ofs.checkStore(req.StoreId); err != nil {
+		return nil, err
+	}
+	if len(req.GetContextualTuples().GetTupleKeys()) > 0 {
+		return nil, status.Errorf(codes.Unimplemented, "contextual tuples are not supported")
+	}
+
+	resource, err := parseObject(req.TupleKey.GetObject())
+	if err != nil {
+		return nil, err
+	}
+	subject, err := parseUser(req.TupleKey.GetUser())
+	if err != nil {
+		return nil, err
+	}
+
+	checkReq := &v1.CheckPermissionRequest{
+		Consistency: consistencyFor(req.Consistency),
+		Resource:    resource,
+		Permission:  req.TupleKey.GetRelation(),
+		Subject:     subject,
+		Context:     req.Context,
+	}
+	if err := prepare(ctx, checkReq); err != nil {
+		return nil, err
+	}
+
+	resp, err := ofs.permissions.CheckPermission(ctx, checkReq)
+	if err != nil {
+		return nil, err
+	}
+
+	switch resp.Permissionship {
+	case v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION:
+		return &openfgav1.CheckResponse{Allowed: true}, nil
+	case v1.CheckPermissionResponse_PERMISSIONSHIP_CONDITIONAL_PERMISSION:
+		return nil, status.Errorf(codes.InvalidArgument, "failed to evaluate condition: missing context parameters %s",
+			strings.Join(resp.PartialCaveatInfo.GetMissingRequiredContext(), ", "))
+	default:
+		return &openfgav1.CheckResponse{Allowed: false}, nil
+	}
+}
+
+func (ofs *openFGAServer) Expand(ctx context.Context, req *openfgav1.ExpandRequest) (*openfgav1.ExpandResponse, error) {
+	if err := ofs.checkStore(req.StoreId); err != nil {
+		return nil, err
+	}
+	if len(req.GetContextualTuples().GetTupleKeys()) > 0 {
+		return nil, status.Errorf(codes.Unimplemented, "contextual tuples are not supported")
+	}
+
+	resource, err := parseObject(req.TupleKey.GetObject())
+	if err != nil {
+		return nil, err
+	}
+
+	expandReq := &v1.ExpandPermissionTreeRequest{
+		Consistency: consistencyFor(req.Consistency),
+		Resource:    resource,
+		Permission:  req.TupleKey.GetRelation(),
+	}
+	if err := prepare(ctx, expandReq); err != nil {
+		return nil, err
+	}
+
+	resp, err := ofs.permissions.ExpandPermissionTree(ctx, expandReq)
+	if err != nil {
+		return nil, err
+	}
+
+	return &openfgav1.ExpandResponse{
+		Tree: &openfgav1.UsersetTree{Root: usersetTreeNode(resp.TreeRoot)},
+	}, nil
+}
+
+func (ofs *openFGAServer) Write(ctx context.Context, req *openfgav1.WriteRequest) (*openfgav1.WriteResponse, error) {
+	if err := ofs.checkStore(req.StoreId); err != nil {
+		return nil, err
+	}
+
+	updates := make([]*v1.RelationshipUpdate, 0, len(req.GetWrites().GetTupleKeys())+len(req.GetDeletes().GetTupleKeys()))
+	for _, tk := range req.GetWrites().GetTupleKeys() {
+		rel, err := relationshipFor(tk.Object, tk.Relation, tk.User)
+		if err != nil {
+			return nil, err
+		}
+		if tk.Condition != nil {
+			rel.OptionalCaveat = &v1.ContextualizedCaveat{
+				CaveatName: tk.Condition.Name,
+				Context:    tk.Condition.Context,
+			}
+		}
+
+		// OpenFGA fails to write tuples which already exist.
+		updates = append(updates, &v1.RelationshipUpdate{
+			Operation:    v1.RelationshipUpdate_OPERATION_CREATE,
+			Relationship: rel,
+		})
+	}
+
+	for _, tk := range req.GetDeletes().GetTupleKeys() {
+		rel, err := relationshipFor(tk.Object, tk.Relation, tk.User)
+		if err != nil {
+			return nil, err
+		}
+
+		updates = append(updates, &v1.RelationshipUpdate{
+			Operation:    v1.RelationshipUpdate_OPERATION_DELETE,
+			Relationship: rel,
+		})
+	}
+
+	writeReq := &v1.WriteRelationshipsRequest{Updates: updates}
+	if err := prepare(ctx, writeReq); err != nil {
+		return nil, err
+	}
+
+	if _, err := ofs.permissions.WriteRelationships(ctx, writeReq); err != nil {
+		return nil, err
+	}
+	return &openfgav1.WriteResponse{}, nil
+}
+
+func (ofs *openFGAServer) Read(ctx context.Context, req *openfgav1.ReadRequest) (*openfgav1.ReadResponse, error) {
+	if err := ofs.checkStore(req.StoreId); err != nil {
+		return nil, err
+	}
+
+	filter, err := relationshipFilterFor(req.TupleKey)
+	if err != nil {
+		return nil, err
+	}
+
+	pageSize := uint32(defaultReadPageSize)
+	if req.PageSize != nil && req.PageSize.Value > 0 {
+		pageSize = uint32(req.PageSize.Value)
+	}
+
+	readReq := &v1.ReadRelationshipsRequest{
+		Consistency:        consistencyFor(req.Consistency),
+		RelationshipFilter: filter,
+		OptionalLimit:      pageSize,
+	}
+	if req.ContinuationToken != "" {
+		readReq.OptionalCursor = &v1.Cursor{Token: req.ContinuationToken}
+	}
+	if err := prepare(ctx, readReq); err != nil {
+		return nil, err
+	}
+
+	collector := &readRelationshipsCollector{ctx: ctx}
+	if err := ofs.permissions.ReadRelationships(readReq, collector); err != nil {
+		return nil, err
+	}
+
+	// Like OpenFGA, a continuation token is returned whenever the page is full.
+	var continuationToken string
+	if len(collector.tuples) == int(pageSize) {
+		continuationToken = collector.afterResultCursor
+	}
+
+	return &openfgav1.ReadResponse{
+		Tuples:            collector.tuples,
+		ContinuationToken: continuationToken,
+	}, nil
+}
+
+func (ofs *openFGAServer) checkStore(storeID string) error {
+	if ofs.storeID != "" && storeID != ofs.storeID {
+		return status.Errorf(codes.NotFound, "store `%s` not found", storeID)
+	}
+	return nil
+}
+
+// prepare validates a request to the permissions server and sets the revision at which it is
+// performed, as the interceptors of the permissions service would have.
+func prepare(ctx context.Context, req interface{ Validate() error }) error {
+	if err := req.Validate(); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return consistency.AddRevisionToContext(ctx, req, datastoremw.MustFromContext(ctx), serviceLabel)
+}
+
+func consistencyFor(preference openfgav1.ConsistencyPreference) *v1.Consistency {
+	if preference == openfgav1.ConsistencyPreference_HIGHER_CONSISTENCY {
+		return &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}}
+	}
+	return &v1.Consistency{Requirement: &v1.Consistency_MinimizeLatency{MinimizeLatency: true}}
+}
+
+// parseObject parses an OpenFGA object of the form `type:id`.
+func parseObject(object string) (*v1.ObjectReference, error) {
+	objectType, objectID, ok := strings.Cut(object, ":")
+	if !ok || objectType == "" || objectID == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid object `%s`: expected `type:id`", object)
+	}
+	return &v1.ObjectReference{ObjectType: objectType, ObjectId: objectID}, nil
+}
+
+// parseUser parses an OpenFGA user of the form `type:id`, `type:id#relation` or `type:*`.
+func parseUser(user string) (*v1.SubjectReference, error) {
+	object, relation, _ := strings.Cut(user, "#")
+	objectRef, err := parseObject(object)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid user `%s`: expected `type:id`, `type:id#relation` or `type:*`", user)
+	}
+	return &v1.SubjectReference{Object: objectRef, OptionalRelation: relation}, nil
+}
+
+func relationshipFor(object, relation, user string) (*v1.Relationship, error) {
+	resource, err := parseObject(object)
+	if err != nil {
+		return nil, err
+	}
+	subject, err := parseUser(user)
+	if err != nil {
+		return nil, err
+	}
+	return &v1.Relationship{Resource: resource, Relation: relation, Subject: subject}, nil
+}
+
+// relationshipFilterFor returns the filter of the relationships matching an OpenFGA read, whose
+// object can omit its ID (`type:`) to read all the objects of the type.
+func relationshipFilterFor(tk *openfgav1.ReadRequestTupleKey) (*v1.RelationshipFilter, error) {
+	objectType, objectID, _ := strings.Cut(tk.GetObject(), ":")
+	if objectType == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "reading requires the type of the object")
+	}
+
+	filter := &v1.RelationshipFilter{
+		ResourceType:       objectType,
+		OptionalResourceId: objectID,
+		OptionalRelation:   tk.GetRelation(),
+	}
+	if tk.GetUser() != "" {
+		subject, err := parseUser(tk.GetUser())
+		if err != nil {
+			return nil, err
+		}
+
+		filter.OptionalSubjectFilter = &v1.SubjectFilter{
+			SubjectType:       subject.Object.ObjectType,
+			OptionalSubjectId: subject.Object.ObjectId,
+		}
+		if subject.OptionalRelation != "" {
+			filter.OptionalSubjectFilter.OptionalRelation = &v1.SubjectFilter_RelationFilter{Relation: subject.OptionalRelation}
+		}
+	}
+	return filter, nil
+}
+
+func userString(subject *v1.SubjectReference) string {
+	user := subject.Object.ObjectType + ":" + subject.Object.ObjectId
+	if subject.OptionalRelation != "" {
+		user += "#" + subject.OptionalRelation
+	}
+	return user
+}
+
+func tupleKeyFor(rel *v1.Relationship) *openfgav1.TupleKey {
+	tk := &openfgav1.TupleKey{
+		User:     userString(rel.Subject),
+		Relation: rel.Relation,
+		Object:   rel.Resource.ObjectType + ":" + rel.Resource.ObjectId,
+	}
+	if rel.OptionalCaveat != nil {
+		tk.Condition = &openfgav1.RelationshipCondition{
+			Name:    rel.OptionalCaveat.CaveatName,
+			Context: rel.OptionalCaveat.Context,
+		}
+	}
+	return tk
+}
+
+// usersetTreeNode converts an expanded permission tree into an OpenFGA userset tree.
+func usersetTreeNode(tree *v1.PermissionRelationshipTree) *openfgav1.UsersetTree_Node {
+	node := &openfgav1.UsersetTree_Node{}
+	if tree.ExpandedObject != nil {
+		node.Name = fmt.Sprintf("%s:%s#%s", tree.ExpandedObject.ObjectType, tree.ExpandedObject.ObjectId, tree.ExpandedRelation)
+	}
+
+	switch treeType := tree.TreeType.(type) {
+	case *v1.PermissionRelationshipTree_Leaf:
+		users := make([]string, 0, len(treeType.Leaf.Subjects))
+		for _, subject := range treeType.Leaf.Subjects {
+			users = append(users, userString(subject))
+		}
+		node.Value = &openfgav1.UsersetTree_Node_Leaf{Leaf: &openfgav1.UsersetTree_Leaf{
+			Value: &openfgav1.UsersetTree_Leaf_Users{Users: &openfgav1.UsersetTree_Users{Users: users}},
+		}}
+
+	case *v1.PermissionRelationshipTree_Intermediate:
+		children := make([]*openfgav1.UsersetTree_Node, 0, len(treeType.Intermediate.Children))
+		for _, child := range treeType.Intermediate.Children {
+			children = append(children, usersetTreeNode(child))
+		}
+
+		switch treeType.Intermediate.Operation {
+		case v1.AlgebraicSubjectSet_OPERATION_INTERSECTION:
+			node.Value = &openfgav1.UsersetTree_Node_Intersection{Intersection: &openfgav1.UsersetTree_Nodes{Nodes: children}}
+
+		case v1.AlgebraicSubjectSet_OPERATION_EXCLUSION:
+			if len(children) == 0 {
+				break
+			}
+
+			// Differences have a single subtracted node, so any further ones are united.
+			subtract := &openfgav1.UsersetTree_Node{Value: &openfgav1.UsersetTree_Node_Union{Union: &openfgav1.UsersetTree_Nodes{Nodes: children[1:]}}}
+			if len(children) == 2 {
+				subtract = children[1]
+			}
+			node.Value = &openfgav1.UsersetTree_Node_Difference{Difference: &openfgav1.UsersetTree_Difference{
+				Base:     children[0],
+				Subtract: subtract,
+			}}
+
+		default:
+			node.Value = &openfgav1.UsersetTree_Node_Union{Union: &openfgav1.UsersetTree_Nodes{Nodes: children}}
+		}
+	}
+	return node
+}
+
+// readRelationshipsCollector collects the relationships read by an in-process ReadRelationships
+// call as tuples. Responses are converted as they are sent, since the server reuses them.
+type readRelationshipsCollector struct {
+	grpc.ServerStream
+
+	ctx               context.Context
+	tuples            []*openfgav1.Tuple
+	afterResultCursor string
+}
+
+func (rrc *readRelationshipsCollector) Context() context.Context { return rrc.ctx }
+
+func (rrc *readRelationshipsCollector) Send(resp *v1.ReadRelationshipsResponse) error {
+	rrc.tuples = append(rrc.tuples, &openfgav1.Tuple{Key: tupleKeyFor(resp.Relationship)})
+	rrc.afterResultCursor = resp.AfterResultCursor.GetToken()
+	return nil
+}
+
+// SetTrailer drops the trailer of the read, which has no counterpart in OpenFGA.
+func (rrc *readRelationshipsCollector) SetTrailer(metadata.MD) {}
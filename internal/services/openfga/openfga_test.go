@@ -0,0 +1,240 @@
+package openfga
+
+import (
+	"context"
+	"testing"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/authzed/spicedb/internal/datastore/dsfortesting"
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/internal/dispatch/graph"
+	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
+	v1svc "github.com/authzed/spicedb/internal/services/v1"
+	"github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/middleware/consistency"
+)
+
+const testSchema = `
+	definition user {}
+
+	definition group {
+		relation member: user
+	}
+
+	definition document {
+		relation owner: user
+		relation viewer: user | user:* | group#member
+		relation banned: user
+		permission view = (viewer + owner) - banned
+	}
+`
+
+const testStoreID = "01JGX0CW9SN4CW4CJ49EH3JWE9"
+
+func newTestServer(t *testing.T, storeID string) (openfgav1.OpenFGAServiceServer, datastore.Datastore) {
+	rawDS, err := dsfortesting.NewMemDBDatastoreForTesting(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+
+	ds, _ := testfixtures.DatastoreFromSchemaAndTestRelationships(rawDS, testSchema, nil, require.New(t))
+	t.Cleanup(func() { ds.Close() })
+
+	dispatcher := graph.NewLocalOnlyDispatcher(10, 100)
+	t.Cleanup(func() { dispatcher.Close() })
+
+	return NewOpenFGAServer(v1svc.NewPermissionsServer(dispatcher, v1svc.PermissionsServerConfig{
+		MaxUpdatesPerWrite:        1000,
+		MaxPreconditionsCount:     1000,
+		MaximumAPIDepth:           50,
+		MaxReadRelationshipsLimit: 1000,
+	}), storeID), ds
+}
+
+// requestContext returns the context of a request, as set up by the middleware of the server.
+func requestContext(ds datastore.Datastore) context.Context {
+	return consistency.ContextWithHandle(datastoremw.ContextWithDatastore(context.Background(), ds))
+}
+
+func TestOpenFGAWriteCheckAndRead(t *testing.T) {
+	require := require.New(t)
+	srv, ds := newTestServer(t, "")
+
+	_, err := srv.Write(requestContext(ds), &openfgav1.WriteRequest{
+		StoreId: testStoreID,
+		Writes: &openfgav1.WriteRequestWrites{TupleKeys: []*openfgav1.TupleKey{
+			{User: "user:anne", Relation: "owner", Object: "document:roadmap"},
+			{User: "group:eng#member", Relation: "viewer", Object: "document:roadmap"},
+			{User: "user:*", Relation: "viewer", Object: "document:readme"},
+			{User: "user:bob", Relation: "member", Object: "group:eng"},
+			{User: "user:bob", Relation: "banned", Object: "document:readme"},
+			{User: "user:charlie", Relation: "banned", Object: "document:roadmap"},
+		}},
+	})
+	require.NoError(err)
+
+	for _, tc := range []struct {
+		user, object string
+		allowed      bool
+	}{
+		{"user:anne", "document:roadmap", true},
+		{"user:bob", "document:roadmap", true},
+		{"user:charlie", "document:roadmap", false},
+		{"user:charlie", "document:readme", true},
+		{"user:bob", "document:readme", false},
+	} {
+		resp, err := srv.Check(requestContext(ds), &openfgav1.CheckRequest{
+			StoreId:     testStoreID,
+			TupleKey:    &openfgav1.CheckRequestTupleKey{User: tc.user, Relation: "view", Object: tc.object},
+			Consistency: openfgav1.ConsistencyPreference_HIGHER_CONSISTENCY,
+		})
+		require.NoError(err)
+		require.Equal(tc.allowed, resp.Allowed, "%s on %s", tc.user, tc.object)
+	}
+
+	// Reads filter by object type, object and user, and page with continuation tokens.
+	resp, err := srv.Read(requestContext(ds), &openfgav1.ReadRequest{
+		StoreId:     testStoreID,
+		TupleKey:    &openfgav1.ReadRequestTupleKey{Object: "document:roadmap"},
+		Consistency: openfgav1.ConsistencyPreference_HIGHER_CONSISTENCY,
+	})
+	require.NoError(err)
+	require.ElementsMatch([]string{
+		"document:roadmap#owner@user:anne",
+		"document:roadmap#viewer@group:eng#member",
+		"document:roadmap#banned@user:charlie",
+	}, tupleStrings(resp.Tuples))
+	require.Empty(resp.ContinuationToken)
+
+	resp, err = srv.Read(requestContext(ds), &openfgav1.ReadRequest{
+		StoreId:  testStoreID,
+		TupleKey: &openfgav1.ReadRequestTupleKey{Object: "document:", User: "user:bob"},
+	})
+	require.NoError(err)
+	require.Equal([]string{"document:readme#banned@user:bob"}, tupleStrings(resp.Tuples))
+
+	var paged []string
+	continuationToken := ""
+	for {
+		resp, err := srv.Read(requestContext(ds), &openfgav1.ReadRequest{
+			StoreId:           testStoreID,
+			TupleKey:          &openfgav1.ReadRequestTupleKey{Object: "document:"},
+			PageSize:          wrapperspb.Int32(2),
+			ContinuationToken: continuationToken,
+		})
+		require.NoError(err)
+		require.LessOrEqual(len(resp.Tuples), 2)
+		paged = append(paged, tupleStrings(resp.Tuples)...)
+
+		continuationToken = resp.ContinuationToken
+		if continuationToken == "" {
+			break
+		}
+	}
+	require.Len(paged, 5)
+
+	// Deletes remove the tuples.
+	_, err = srv.Write(requestContext(ds), &openfgav1.WriteRequest{
+		StoreId: testStoreID,
+		Deletes: &openfgav1.WriteRequestDeletes{TupleKeys: []*openfgav1.TupleKeyWithoutCondition{
+			{User: "user:charlie", Relation: "banned", Object: "document:roadmap"},
+		}},
+	})
+	require.NoError(err)
+
+	checkResp, err := srv.Check(requestContext(ds), &openfgav1.CheckRequest{
+		StoreId:     testStoreID,
+		TupleKey:    &openfgav1.CheckRequestTupleKey{User: "user:charlie", Relation: "view", Object: "document:roadmap"},
+		Consistency: openfgav1.ConsistencyPreference_HIGHER_CONSISTENCY,
+	})
+	require.NoError(err)
+	require.False(checkResp.Allowed)
+}
+
+func TestOpenFGAExpand(t *testing.T) {
+	require := require.New(t)
+	srv, ds := newTestServer(t, "")
+
+	_, err := srv.Write(requestContext(ds), &openfgav1.WriteRequest{
+		StoreId: testStoreID,
+		Writes: &openfgav1.WriteRequestWrites{TupleKeys: []*openfgav1.TupleKey{
+			{User: "user:anne", Relation: "owner", Object: "document:roadmap"},
+			{User: "group:eng#member", Relation: "viewer", Object: "document:roadmap"},
+			{User: "user:charlie", Relation: "banned", Object: "document:roadmap"},
+		}},
+	})
+	require.NoError(err)
+
+	resp, err := srv.Expand(requestContext(ds), &openfgav1.ExpandRequest{
+		StoreId:     testStoreID,
+		TupleKey:    &openfgav1.ExpandRequestTupleKey{Relation: "view", Object: "document:roadmap"},
+		Consistency: openfgav1.ConsistencyPreference_HIGHER_CONSISTENCY,
+	})
+	require.NoError(err)
+
+	root := resp.Tree.Root
+	require.Equal("document:roadmap#view", root.Name)
+	difference := root.GetDifference()
+	require.NotNil(difference)
+	require.NotNil(difference.Base.GetUnion())
+	require.ElementsMatch([]string{"user:charlie"}, leafUsers(difference.Subtract))
+
+	var viewers []string
+	for _, node := range difference.Base.GetUnion().Nodes {
+		viewers = append(viewers, leafUsers(node)...)
+	}
+	require.ElementsMatch([]string{"user:anne", "group:eng#member"}, viewers)
+}
+
+func TestOpenFGAUnsupportedRequests(t *testing.T) {
+	srv, ds := newTestServer(t, testStoreID)
+
+	_, err := srv.Check(requestContext(ds), &openfgav1.CheckRequest{
+		StoreId:  "01JGX0CW9SN4CW4CJ49EH3JWF0",
+		TupleKey: &openfgav1.CheckRequestTupleKey{User: "user:anne", Relation: "view", Object: "document:roadmap"},
+	})
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = srv.Check(requestContext(ds), &openfgav1.CheckRequest{
+		StoreId:  testStoreID,
+		TupleKey: &openfgav1.CheckRequestTupleKey{User: "user:anne", Relation: "view", Object: "document:roadmap"},
+		ContextualTuples: &openfgav1.ContextualTupleKeys{TupleKeys: []*openfgav1.TupleKey{
+			{User: "user:anne", Relation: "owner", Object: "document:roadmap"},
+		}},
+	})
+	require.Equal(t, codes.Unimplemented, status.Code(err))
+
+	_, err = srv.Check(requestContext(ds), &openfgav1.CheckRequest{
+		StoreId:  testStoreID,
+		TupleKey: &openfgav1.CheckRequestTupleKey{User: "anne", Relation: "view", Object: "document:roadmap"},
+	})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = srv.Read(requestContext(ds), &openfgav1.ReadRequest{StoreId: testStoreID})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func tupleStrings(tuples []*openfgav1.Tuple) []string {
+	strs := make([]string, 0, len(tuples))
+	for _, tuple := range tuples {
+		strs = append(strs, tuple.Key.Object+"#"+tuple.Key.Relation+"@"+tuple.Key.User)
+	}
+	return strs
+}
+
+// leafUsers returns the users of the leaves of a userset tree node.
+func leafUsers(node *openfgav1.UsersetTree_Node) []string {
+	if leaf := node.GetLeaf(); leaf != nil {
+		return leaf.GetUsers().GetUsers()
+	}
+
+	var users []string
+	for _, child := range node.GetUnion().GetNodes() {
+		users = append(users, leafUsers(child)...)
+	}
+	return users
+}
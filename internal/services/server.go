@@ -12,6 +12,9 @@ import (
 	"github.com/authzed/spicedb/internal/dispatch"
 	"github.com/authzed/spicedb/internal/services/health"
 	v1svc "github.com/authzed/spicedb/internal/services/v1"
+	expansionv1 "github.com/authzed/spicedb/pkg/proto/expansion/v1"
+	lookupwatchv1 "github.com/authzed/spicedb/pkg/proto/lookupwatch/v1"
+	schemadocv1 "github.com/authzed/spicedb/pkg/proto/schemadoc/v1"
 )
 
 // SchemaServiceOption defines the options for enabling or disabling the V1 Schema service.
@@ -55,16 +58,19 @@ func RegisterGrpcServices(
 	watchServiceOption WatchServiceOption,
 	permSysConfig v1svc.PermissionsServerConfig,
 	watchHeartbeatDuration time.Duration,
+	watchShuttingDown <-chan struct{},
 ) {
 	healthManager.RegisterReportedService(OverallServerHealthCheckKey)
+	healthManager.RegisterReportedService(health.DatastoreWritesHealthCheckKey)
 
 	v1.RegisterPermissionsServiceServer(srv, v1svc.NewPermissionsServer(dispatch, permSysConfig))
 	v1.RegisterExperimentalServiceServer(srv, v1svc.NewExperimentalServer(dispatch, permSysConfig))
+	expansionv1.RegisterExpansionServiceServer(srv, v1svc.NewExpansionServer(dispatch, permSysConfig))
+	schemadocv1.RegisterSchemaDocumentationServiceServer(srv, v1svc.NewSchemaDocumentationServer())
 	healthManager.RegisterReportedService(v1.PermissionsService_ServiceDesc.ServiceName)
 
 	if watchServiceOption == WatchServiceEnabled {
-		v1.RegisterWatchServiceServer(srv, v1svc.NewWatchServer(watchHeartbeatDuration))
-		healthManager.RegisterReportedService(v1.WatchService_ServiceDesc.ServiceName)
+		registerWatchServices(srv, healthManager, dispatch, permSysConfig, watchHeartbeatDuration, watchShuttingDown)
 	}
 
 	if schemaServiceOption == V1SchemaServiceEnabled || schemaServiceOption == V1SchemaServiceAdditiveOnly {
@@ -75,3 +81,39 @@ func RegisterGrpcServices(
 	healthpb.RegisterHealthServer(srv, healthManager.HealthSvc())
 	reflection.Register(grpcutil.NewAuthlessReflectionInterceptor(srv))
 }
+
+// RegisterAdminGrpcServices registers the services to be exposed on the admin GRPC server, which
+// serves the Watch APIs on a listener separate from the data-plane APIs. The Watch APIs should
+// then be disabled when calling RegisterGrpcServices for the main GRPC server.
+func RegisterAdminGrpcServices(
+	srv *grpc.Server,
+	healthManager health.Manager,
+	dispatch dispatch.Dispatcher,
+	watchServiceOption WatchServiceOption,
+	permSysConfig v1svc.PermissionsServerConfig,
+	watchHeartbeatDuration time.Duration,
+	watchShuttingDown <-chan struct{},
+) {
+	if watchServiceOption == WatchServiceEnabled {
+		registerWatchServices(srv, healthManager, dispatch, permSysConfig, watchHeartbeatDuration, watchShuttingDown)
+	}
+
+	healthpb.RegisterHealthServer(srv, healthManager.HealthSvc())
+	reflection.Register(grpcutil.NewAuthlessReflectionInterceptor(srv))
+}
+
+func registerWatchServices(
+	srv *grpc.Server,
+	healthManager health.Manager,
+	dispatch dispatch.Dispatcher,
+	permSysConfig v1svc.PermissionsServerConfig,
+	watchHeartbeatDuration time.Duration,
+	watchShuttingDown <-chan struct{},
+) {
+	v1.RegisterWatchServiceServer(srv, v1svc.NewWatchServer(watchHeartbeatDuration, watchShuttingDown))
+	healthManager.RegisterReportedService(v1.WatchService_ServiceDesc.ServiceName)
+
+	// The experimental lookup watch service builds upon the Watch API.
+	lookupwatchv1.RegisterLookupWatchServiceServer(srv, v1svc.NewLookupWatchServer(dispatch, permSysConfig, watchHeartbeatDuration))
+	healthManager.RegisterReportedService(lookupwatchv1.LookupWatchService_ServiceDesc.ServiceName)
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/authzed/spicedb/internal/sharederrors"
 	"github.com/authzed/spicedb/pkg/cursor"
 	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/errorcodes"
 	dispatchv1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
 	"github.com/authzed/spicedb/pkg/schemadsl/compiler"
 	"github.com/authzed/spicedb/pkg/spiceerrors"
@@ -62,13 +63,65 @@ func (err SchemaWriteDataValidationError) GRPCStatus() *status.Status {
 	return spiceerrors.WithCodeAndDetails(
 		err,
 		codes.InvalidArgument,
-		spiceerrors.ForReason(
-			v1.ErrorReason_ERROR_REASON_SCHEMA_TYPE_ERROR,
+		spiceerrors.ForCode(
+			errorcodes.SchemaWriteUnreferencedData,
 			map[string]string{},
 		),
 	)
 }
 
+// NewSchemaWriteBlockedError creates a new error representing that a schema write cannot be
+// completed due to the existing relationships given.
+func NewSchemaWriteBlockedError(blocking []BlockingRelationships) SchemaWriteBlockedError {
+	var total uint64
+	for _, b := range blocking {
+		total += b.Count
+	}
+
+	return SchemaWriteBlockedError{
+		error:    fmt.Errorf("cannot apply schema, as %d existing relationships would no longer conform to it", total),
+		blocking: blocking,
+		total:    total,
+	}
+}
+
+// SchemaWriteBlockedError occurs when a schema cannot be applied due to existing relationships,
+// with the exact number of relationships blocking each change.
+type SchemaWriteBlockedError struct {
+	error
+	blocking []BlockingRelationships
+	total    uint64
+}
+
+// MarshalZerologObject implements zerolog object marshalling.
+func (err SchemaWriteBlockedError) MarshalZerologObject(e *zerolog.Event) {
+	e.Err(err.error).Uint64("blockingRelationships", err.total)
+}
+
+// GRPCStatus implements retrieving the gRPC status for the error.
+func (err SchemaWriteBlockedError) GRPCStatus() *status.Status {
+	violations := make([]*errdetails.PreconditionFailure_Violation, 0, len(err.blocking))
+	for _, b := range err.blocking {
+		violations = append(violations, &errdetails.PreconditionFailure_Violation{
+			Type:        "BLOCKING_RELATIONSHIPS",
+			Subject:     b.Change,
+			Description: strconv.FormatUint(b.Count, 10),
+		})
+	}
+
+	return spiceerrors.WithCodeAndDetails(
+		err,
+		codes.InvalidArgument,
+		spiceerrors.ForCode(
+			errorcodes.SchemaWriteBlocked,
+			map[string]string{
+				"blocking_relationship_count": strconv.FormatUint(err.total, 10),
+			},
+		),
+		&errdetails.PreconditionFailure{Violations: violations},
+	)
+}
+
 // MaxDepthExceededError is an error returned when the maximum depth for dispatching has been exceeded.
 type MaxDepthExceededError struct {
 	*spiceerrors.WithAdditionalDetailsError
@@ -173,11 +226,15 @@ func rewriteError(ctx context.Context, err error, config *ConfigForErrors) error
 	case errors.As(err, &datastore.ReadOnlyError{}):
 		return ErrServiceReadOnly
 	case errors.As(err, &datastore.InvalidRevisionError{}):
-		return status.Errorf(codes.OutOfRange, "invalid zedtoken: %s", err)
+		return spiceerrors.WithCodeAndDetailsAsError(
+			fmt.Errorf("invalid zedtoken: %w", err),
+			codes.OutOfRange,
+			spiceerrors.ForCode(errorcodes.InvalidRevision, nil),
+		)
 	case errors.As(err, &datastore.CaveatNameNotFoundError{}):
 		return spiceerrors.WithCodeAndReason(err, codes.FailedPrecondition, v1.ErrorReason_ERROR_REASON_UNKNOWN_CAVEAT)
 	case errors.As(err, &datastore.WatchDisabledError{}):
-		return status.Errorf(codes.FailedPrecondition, "%s", err)
+		return spiceerrors.WithCodeAndDetailsAsError(err, codes.FailedPrecondition, spiceerrors.ForCode(errorcodes.WatchDisabled, nil))
 	case errors.As(err, &datastore.CounterAlreadyRegisteredError{}):
 		return spiceerrors.WithCodeAndReason(err, codes.FailedPrecondition, v1.ErrorReason_ERROR_REASON_COUNTER_ALREADY_REGISTERED)
 	case errors.As(err, &datastore.CounterNotRegisteredError{}):
@@ -0,0 +1,326 @@
+package shared
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/authzed/cel-go/cel"
+	"github.com/authzed/cel-go/common"
+	"github.com/authzed/cel-go/common/types"
+
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/internal/relationships"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/options"
+	"github.com/authzed/spicedb/pkg/tuple"
+	"github.com/authzed/spicedb/pkg/zedtoken"
+)
+
+// rewriteFields are the fields of a relationship which can be read and rewritten by a rewrite
+// expression.
+var rewriteFields = []string{"resource_type", "resource_id", "relation", "subject_type", "subject_id", "subject_relation"}
+
+// tombstoneField marks the value of `tombstone` in rewrite expressions, which cannot be built
+// otherwise as it is not a field of relationships.
+const tombstoneField = "$tombstone"
+
+// RewriteExpression is a CEL expression rewriting a relationship, given as the `relationship`
+// map of its fields: resource_type, resource_id, relation, subject_type, subject_id and
+// subject_relation, the last being `...` for subjects without a relation. The expression results
+// in a map of the fields to change, such as `{"relation": "reader"}`, or in `tombstone` to delete
+// the relationship. The caveat and expiration of relationships are kept.
+type RewriteExpression struct {
+	expression string
+	program    cel.Program
+}
+
+// ParseRewriteExpression parses and type checks a rewrite expression.
+func ParseRewriteExpression(expression string) (*RewriteExpression, error) {
+	env, err := cel.NewEnv(
+		cel.Variable("relationship", cel.MapType(cel.StringType, cel.StringType)),
+		cel.Variable("tombstone", cel.MapType(cel.StringType, cel.StringType)),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	ast, issues := env.CompileSource(common.NewStringSource(expression, "rewrite"))
+	if issues != nil && issues.Err() != nil {
+		return nil, issues.Err()
+	}
+
+	// Empty maps are typed as map(dyn, dyn), so their keys and values are checked once evaluated.
+	if kind := ast.OutputType().Kind(); kind != types.MapKind && kind != types.DynKind {
+		return nil, fmt.Errorf("rewrite expression must result in a map value: found `%s`", ast.OutputType().String())
+	}
+
+	program, err := env.Program(ast, cel.CostLimit(10_000))
+	if err != nil {
+		return nil, err
+	}
+
+	return &RewriteExpression{expression: expression, program: program}, nil
+}
+
+func (re *RewriteExpression) String() string {
+	return re.expression
+}
+
+// Rewrite returns the relationship rewritten by the expression, or false if it is deleted.
+func (re *RewriteExpression) Rewrite(rel tuple.Relationship) (tuple.Relationship, bool, error) {
+	fields := map[string]*string{
+		"resource_type":    &rel.Resource.ObjectType,
+		"resource_id":      &rel.Resource.ObjectID,
+		"relation":         &rel.Resource.Relation,
+		"subject_type":     &rel.Subject.ObjectType,
+		"subject_id":       &rel.Subject.ObjectID,
+		"subject_relation": &rel.Subject.Relation,
+	}
+
+	values := make(map[string]string, len(fields))
+	for name, field := range fields {
+		values[name] = *field
+	}
+
+	val, _, err := re.program.Eval(map[string]any{
+		"relationship": values,
+		"tombstone":    map[string]string{tombstoneField: ""},
+	})
+	if err != nil {
+		return tuple.Relationship{}, false, fmt.Errorf("unable to evaluate rewrite expression for relationship `%s`: %w", tuple.StringWithoutCaveatOrExpiration(rel), err)
+	}
+
+	native, err := val.ConvertToNative(reflect.TypeOf(map[string]string{}))
+	if err != nil {
+		return tuple.Relationship{}, false, fmt.Errorf("rewrite expression must result in a map(string, string) value for relationship `%s`: %w", tuple.StringWithoutCaveatOrExpiration(rel), err)
+	}
+
+	changes := native.(map[string]string)
+	if _, ok := changes[tombstoneField]; ok {
+		return tuple.Relationship{}, false, nil
+	}
+
+	for name, value := range changes {
+		field, ok := fields[name]
+		if !ok {
+			return tuple.Relationship{}, false, fmt.Errorf("unknown field `%s` in rewrite of relationship `%s`: must be one of %s", name, tuple.StringWithoutCaveatOrExpiration(rel), strings.Join(rewriteFields, ", "))
+		}
+		*field = value
+	}
+
+	// The integrity of the relationship is computed anew when it is written.
+	rel.OptionalIntegrity = nil
+	if err := tuple.ToV1Relationship(rel).Validate(); err != nil {
+		return tuple.Relationship{}, false, fmt.Errorf("invalid rewrite of relationship to `%s`: %w", tuple.StringWithoutCaveatOrExpiration(rel), err)
+	}
+	return rel, true, nil
+}
+
+// RelationshipRewrite is the rewrite of the relationships matching a filter by an expression.
+type RelationshipRewrite struct {
+	// Filter is the filter of the rewritten relationships. Its resource type is required.
+	Filter datastore.RelationshipsFilter
+
+	// Expression is the expression applied to each relationship.
+	Expression *RewriteExpression
+}
+
+// RelationshipRewriteProgress is the progress of a rewrite applied by
+// RewriteRelationshipsInDatastore.
+type RelationshipRewriteProgress struct {
+	// Cursor is the cursor from which the rewrite can be resumed.
+	Cursor string
+
+	// RelationshipsRead is the number of relationships read which matched the filter.
+	RelationshipsRead uint64
+
+	// RelationshipsRewritten is the number of relationships replaced by their rewrite.
+	RelationshipsRewritten uint64
+
+	// RelationshipsDeleted is the number of relationships deleted by the expression.
+	RelationshipsDeleted uint64
+}
+
+// ErrInvalidRewriteCursor is returned by RewriteRelationshipsInDatastore for a cursor not
+// returned in its progress.
+var ErrInvalidRewriteCursor = errors.New("invalid rewrite cursor")
+
+// RewriteRelationshipsInDatastore applies the rewrite to the relationships matching its filter, in
+// transactions of up to batchSize relationships each, calling progress after each.
+//
+// The relationships are read in batches at the revision at which the rewrite started, so that
+// rewritten relationships still matching the filter are not rewritten again. Each relationship
+// is rewritten as found at the head revision, and skipped if it was deleted since the rewrite
+// started. A rewrite interrupted after a batch is resumed from the cursor of its progress, which
+// must be within the garbage collection window of the datastore.
+func RewriteRelationshipsInDatastore(ctx context.Context, ds datastore.Datastore, rewrite RelationshipRewrite, batchSize uint64, cursor string, progress func(RelationshipRewriteProgress)) (*RelationshipRewriteProgress, error) {
+	if rewrite.Filter.OptionalResourceType == "" {
+		return nil, errors.New("the resource type of the rewritten relationships is required")
+	}
+	if batchSize == 0 {
+		return nil, errors.New("the batch size must be greater than zero")
+	}
+
+	revision, after, err := decodeRewriteCursor(ctx, ds, cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &RelationshipRewriteProgress{Cursor: cursor}
+	for {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		it, err := ds.SnapshotReader(revision).QueryRelationships(ctx, rewrite.Filter,
+			options.WithSort(options.ByResource),
+			options.WithAfter(after),
+			options.WithLimit(&batchSize),
+		)
+		if err != nil {
+			return result, err
+		}
+
+		var batch []tuple.Relationship
+		for rel, err := range it {
+			if err != nil {
+				return result, err
+			}
+			batch = append(batch, rel)
+		}
+		if len(batch) == 0 {
+			return result, nil
+		}
+
+		var rewritten, deleted uint64
+		_, err = ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+			var err error
+			rewritten, deleted, err = rewrite.rewriteBatch(ctx, rwt, batch)
+			return err
+		})
+		if err != nil {
+			return result, err
+		}
+
+		last := batch[len(batch)-1]
+		after = &last
+		result.Cursor = zedtoken.MustNewFromRevision(revision).Token + " " + tuple.StringWithoutCaveatOrExpiration(last)
+		result.RelationshipsRead += uint64(len(batch))
+		result.RelationshipsRewritten += rewritten
+		result.RelationshipsDeleted += deleted
+		log.Ctx(ctx).Debug().Stringer("expression", rewrite.Expression).Uint64("read", result.RelationshipsRead).Msg("rewrote batch of relationships")
+		if progress != nil {
+			progress(*result)
+		}
+
+		if uint64(len(batch)) < batchSize {
+			return result, nil
+		}
+	}
+}
+
+// decodeRewriteCursor returns the revision at which to read the relationships, and the
+// relationship after which to resume reading them, if any.
+func decodeRewriteCursor(ctx context.Context, ds datastore.Datastore, cursor string) (datastore.Revision, options.Cursor, error) {
+	if cursor == "" {
+		revision, err := ds.HeadRevision(ctx)
+		return revision, nil, err
+	}
+
+	token, relString, ok := strings.Cut(cursor, " ")
+	if !ok {
+		return nil, nil, ErrInvalidRewriteCursor
+	}
+
+	revision, err := zedtoken.DecodeRevision(&v1.ZedToken{Token: token}, ds)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", ErrInvalidRewriteCursor, err)
+	}
+
+	after, err := tuple.Parse(relString)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", ErrInvalidRewriteCursor, err)
+	}
+	return revision, &after, nil
+}
+
+// rewriteBatch rewrites the relationships of the batch which still exist, and returns the number
+// of relationships rewritten and deleted.
+func (rewrite RelationshipRewrite) rewriteBatch(ctx context.Context, rwt datastore.ReadWriteTransaction, batch []tuple.Relationship) (uint64, uint64, error) {
+	// Read the relationships of the batch as they are now.
+	filter := rewrite.Filter
+	for _, rel := range batch {
+		if !slices.Contains(filter.OptionalResourceIds, rel.Resource.ObjectID) {
+			filter.OptionalResourceIds = append(filter.OptionalResourceIds, rel.Resource.ObjectID)
+		}
+	}
+	filter.OptionalResourceIDPrefix = ""
+
+	it, err := rwt.QueryRelationships(ctx, filter)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	current := make(map[string]tuple.Relationship, len(batch))
+	for rel, err := range it {
+		if err != nil {
+			return 0, 0, err
+		}
+		current[tuple.StringWithoutCaveatOrExpiration(rel)] = rel
+	}
+
+	// Deletions are overridden by rewrites to the same relationship, such that relationships can
+	// be swapped within a batch.
+	var rewritten, deleted uint64
+	var touches []tuple.RelationshipUpdate
+	mutations := make(map[string]tuple.RelationshipUpdate, len(batch))
+	for _, batchRel := range batch {
+		key := tuple.StringWithoutCaveatOrExpiration(batchRel)
+		rel, ok := current[key]
+		if !ok {
+			continue
+		}
+
+		newRel, ok, err := rewrite.Expression.Rewrite(rel)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		if !ok {
+			deleted++
+		} else {
+			newKey := tuple.StringWithoutCaveatOrExpiration(newRel)
+			if newKey == key {
+				continue
+			}
+			rewritten++
+			touches = append(touches, tuple.Touch(newRel))
+		}
+
+		if _, ok := mutations[key]; !ok {
+			mutations[key] = tuple.Delete(rel)
+		}
+	}
+	for _, touch := range touches {
+		mutations[tuple.StringWithoutCaveatOrExpiration(touch.Relationship)] = touch
+	}
+
+	if len(mutations) == 0 {
+		return 0, 0, nil
+	}
+
+	if err := relationships.ValidateRelationshipUpdates(ctx, rwt, touches); err != nil {
+		return 0, 0, err
+	}
+
+	updates := make([]tuple.RelationshipUpdate, 0, len(mutations))
+	for _, mutation := range mutations {
+		updates = append(updates, mutation)
+	}
+	return rewritten, deleted, rwt.WriteRelationships(ctx, updates)
+}
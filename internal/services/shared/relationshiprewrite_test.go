@@ -0,0 +1,210 @@
+package shared
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/dsfortesting"
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+const rewriteTestSchema = `
+	definition user {}
+
+	definition group {
+		relation member: user
+	}
+
+	definition document {
+		relation viewer: user | group#member
+		relation reader: user | group#member
+	}`
+
+var rewriteTestRelationships = []tuple.Relationship{
+	tuple.MustParse("document:a#viewer@user:tom"),
+	tuple.MustParse("document:b#viewer@user:banned"),
+	tuple.MustParse("document:b#viewer@group:admins#member"),
+	tuple.MustParse("document:c#viewer@user:sarah"),
+	tuple.MustParse("document:c#reader@user:fred"),
+	tuple.MustParse("group:admins#member@user:sarah"),
+}
+
+func newRewriteTestDatastore(t *testing.T) datastore.Datastore {
+	rawDS, err := dsfortesting.NewMemDBDatastoreForTesting(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+
+	ds, _ := testfixtures.DatastoreFromSchemaAndTestRelationships(rawDS, rewriteTestSchema, rewriteTestRelationships, require.New(t))
+	return ds
+}
+
+func TestRewriteRelationshipsInDatastore(t *testing.T) {
+	tcs := []struct {
+		name                  string
+		filter                datastore.RelationshipsFilter
+		expression            string
+		expectedProgress      RelationshipRewriteProgress
+		expectedRelationships []string
+		expectedError         string
+	}{
+		{
+			name:             "relation with tombstones",
+			filter:           datastore.RelationshipsFilter{OptionalResourceType: "document", OptionalResourceRelation: "viewer"},
+			expression:       `relationship.subject_id == "banned" ? tombstone : {"relation": "reader"}`,
+			expectedProgress: RelationshipRewriteProgress{RelationshipsRead: 4, RelationshipsRewritten: 3, RelationshipsDeleted: 1},
+			expectedRelationships: []string{
+				"document:a#reader@user:tom",
+				"document:b#reader@group:admins#member",
+				"document:c#reader@user:fred",
+				"document:c#reader@user:sarah",
+				"group:admins#member@user:sarah",
+			},
+		},
+		{
+			name:             "unchanged relationships",
+			filter:           datastore.RelationshipsFilter{OptionalResourceType: "document"},
+			expression:       `relationship.subject_type == "group" ? {"subject_id": "administrators"} : {}`,
+			expectedProgress: RelationshipRewriteProgress{RelationshipsRead: 5, RelationshipsRewritten: 1},
+			expectedRelationships: []string{
+				"document:a#viewer@user:tom",
+				"document:b#viewer@group:administrators#member",
+				"document:b#viewer@user:banned",
+				"document:c#reader@user:fred",
+				"document:c#viewer@user:sarah",
+				"group:admins#member@user:sarah",
+			},
+		},
+		{
+			// The rewritten relationships match the filter and sort after the existing ones, but
+			// are not rewritten again.
+			name:             "rewritten relationships matching the filter",
+			filter:           datastore.RelationshipsFilter{OptionalResourceType: "document", OptionalResourceRelation: "viewer"},
+			expression:       `{"resource_id": relationship.resource_id + "2"}`,
+			expectedProgress: RelationshipRewriteProgress{RelationshipsRead: 4, RelationshipsRewritten: 4},
+			expectedRelationships: []string{
+				"document:a2#viewer@user:tom",
+				"document:b2#viewer@group:admins#member",
+				"document:b2#viewer@user:banned",
+				"document:c#reader@user:fred",
+				"document:c2#viewer@user:sarah",
+				"group:admins#member@user:sarah",
+			},
+		},
+		{
+			name:          "disallowed subject type",
+			filter:        datastore.RelationshipsFilter{OptionalResourceType: "group"},
+			expression:    `{"subject_type": "document"}`,
+			expectedError: "subjects of type `document` are not allowed on relation `group#member`",
+		},
+		{
+			name:          "unknown field",
+			filter:        datastore.RelationshipsFilter{OptionalResourceType: "group"},
+			expression:    `{"caveat": "only_weekdays"}`,
+			expectedError: "unknown field `caveat`",
+		},
+		{
+			name:          "invalid object ID",
+			filter:        datastore.RelationshipsFilter{OptionalResourceType: "group"},
+			expression:    `{"subject_id": "some user"}`,
+			expectedError: "invalid rewrite of relationship to `group:admins#member@user:some user`",
+		},
+		{
+			name:          "missing resource type",
+			filter:        datastore.RelationshipsFilter{OptionalResourceRelation: "viewer"},
+			expression:    `{}`,
+			expectedError: "resource type of the rewritten relationships is required",
+		},
+	}
+
+	for _, tc := range tcs {
+		for _, batchSize := range []uint64{1, 2, 100} {
+			t.Run(tc.name, func(t *testing.T) {
+				ds := newRewriteTestDatastore(t)
+
+				expression, err := ParseRewriteExpression(tc.expression)
+				require.NoError(t, err)
+
+				var batches uint64
+				result, err := RewriteRelationshipsInDatastore(context.Background(), ds, RelationshipRewrite{Filter: tc.filter, Expression: expression}, batchSize, "", func(RelationshipRewriteProgress) {
+					batches++
+				})
+				if tc.expectedError != "" {
+					require.ErrorContains(t, err, tc.expectedError)
+
+					// Failed batches are not partially applied.
+					require.Len(t, readRelationshipsForTesting(t, ds), len(rewriteTestRelationships))
+					return
+				}
+				require.NoError(t, err)
+				require.Equal(t, tc.expectedProgress.RelationshipsRead, result.RelationshipsRead)
+				require.Equal(t, tc.expectedProgress.RelationshipsRewritten, result.RelationshipsRewritten)
+				require.Equal(t, tc.expectedProgress.RelationshipsDeleted, result.RelationshipsDeleted)
+				require.Equal(t, (result.RelationshipsRead+batchSize-1)/batchSize, batches)
+				require.Equal(t, tc.expectedRelationships, readRelationshipsForTesting(t, ds))
+			})
+		}
+	}
+}
+
+func TestRewriteRelationshipsInDatastoreResumes(t *testing.T) {
+	ds := newRewriteTestDatastore(t)
+
+	expression, err := ParseRewriteExpression(`{"relation": "reader"}`)
+	require.NoError(t, err)
+	rewrite := RelationshipRewrite{
+		Filter:     datastore.RelationshipsFilter{OptionalResourceType: "document", OptionalResourceRelation: "viewer"},
+		Expression: expression,
+	}
+
+	// Interrupt the rewrite after its first batch.
+	ctx, cancel := context.WithCancel(context.Background())
+	result, err := RewriteRelationshipsInDatastore(ctx, ds, rewrite, 2, "", func(RelationshipRewriteProgress) {
+		cancel()
+	})
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, uint64(2), result.RelationshipsRewritten)
+
+	// A relationship not yet rewritten is deleted in the meantime, and is skipped once resumed.
+	_, err = ds.ReadWriteTx(context.Background(), func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		return rwt.WriteRelationships(ctx, []tuple.RelationshipUpdate{tuple.Delete(tuple.MustParse("document:c#viewer@user:sarah"))})
+	})
+	require.NoError(t, err)
+
+	resumed, err := RewriteRelationshipsInDatastore(context.Background(), ds, rewrite, 2, result.Cursor, nil)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), resumed.RelationshipsRead)
+	require.Equal(t, uint64(1), resumed.RelationshipsRewritten)
+	require.Equal(t, []string{
+		"document:a#reader@user:tom",
+		"document:b#reader@group:admins#member",
+		"document:b#reader@user:banned",
+		"document:c#reader@user:fred",
+		"group:admins#member@user:sarah",
+	}, readRelationshipsForTesting(t, ds))
+
+	_, err = RewriteRelationshipsInDatastore(context.Background(), ds, rewrite, 2, "invalid", nil)
+	require.ErrorIs(t, err, ErrInvalidRewriteCursor)
+}
+
+func TestParseRewriteExpression(t *testing.T) {
+	for expression, expectedError := range map[string]string{
+		`{"relation": "reader"}`: "",
+		`{}`:                     "",
+		`tombstone`:              "",
+		`relationship.relation == "viewer" ? {"relation": "reader"} : tombstone`: "",
+		`relationship.relation`: "must result in a map value: found `string`",
+		`null`:                  "must result in a map value: found `null_type`",
+		`relationship.unknown(`: "Syntax error",
+	} {
+		_, err := ParseRewriteExpression(expression)
+		if expectedError == "" {
+			require.NoError(t, err, expression)
+		} else {
+			require.ErrorContains(t, err, expectedError, expression)
+		}
+	}
+}
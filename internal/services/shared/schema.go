@@ -334,30 +334,7 @@ func sanityCheckNamespaceChanges(
 				return nil, spiceerrors.MustBugf("relation `%s` not found in existing namespace definition", delta.RelationName)
 			}
 
-			subjectSelectors := make([]datastore.SubjectsSelector, 0, len(previousRelation.TypeInformation.AllowedDirectRelations))
-			for _, allowedType := range previousRelation.TypeInformation.AllowedDirectRelations {
-				if allowedType.GetRelation() == datastore.Ellipsis {
-					subjectSelectors = append(subjectSelectors, datastore.SubjectsSelector{
-						OptionalSubjectType: allowedType.Namespace,
-						RelationFilter: datastore.SubjectRelationFilter{
-							IncludeEllipsisRelation: true,
-						},
-					})
-				} else {
-					subjectSelectors = append(subjectSelectors, datastore.SubjectsSelector{
-						OptionalSubjectType: allowedType.Namespace,
-						RelationFilter: datastore.SubjectRelationFilter{
-							NonEllipsisRelation: allowedType.GetRelation(),
-						},
-					})
-				}
-			}
-
-			qy, qyErr := rwt.QueryRelationships(ctx, datastore.RelationshipsFilter{
-				OptionalResourceType:      nsdef.Name,
-				OptionalResourceRelation:  delta.RelationName,
-				OptionalSubjectsSelectors: subjectSelectors,
-			}, options.WithLimit(options.LimitOne))
+			qy, qyErr := rwt.QueryRelationships(ctx, removedRelationFilter(nsdef.Name, previousRelation), options.WithLimit(options.LimitOne))
 
 			err = errorIfTupleIteratorReturnsTuples(
 				ctx,
@@ -369,12 +346,7 @@ func sanityCheckNamespaceChanges(
 			}
 
 			// Also check for right sides of tuples.
-			qy, qyErr = rwt.ReverseQueryRelationships(ctx, datastore.SubjectsFilter{
-				SubjectType: nsdef.Name,
-				RelationFilter: datastore.SubjectRelationFilter{
-					NonEllipsisRelation: delta.RelationName,
-				},
-			}, options.WithLimitForReverse(options.LimitOne))
+			qy, qyErr = rwt.ReverseQueryRelationships(ctx, removedRelationSubjectsFilter(nsdef.Name, delta.RelationName), options.WithLimitForReverse(options.LimitOne))
 			err = errorIfTupleIteratorReturnsTuples(
 				ctx,
 				qy,
@@ -385,42 +357,9 @@ func sanityCheckNamespaceChanges(
 			}
 
 		case nsdiff.RelationAllowedTypeRemoved:
-			var optionalSubjectIds []string
-			var relationFilter datastore.SubjectRelationFilter
-			optionalCaveatName := ""
-
-			if delta.AllowedType.GetPublicWildcard() != nil {
-				optionalSubjectIds = []string{tuple.PublicWildcard}
-			} else {
-				relationFilter = datastore.SubjectRelationFilter{
-					NonEllipsisRelation: delta.AllowedType.GetRelation(),
-				}
-			}
-
-			if delta.AllowedType.GetRequiredCaveat() != nil {
-				optionalCaveatName = delta.AllowedType.GetRequiredCaveat().CaveatName
-			}
-
-			expirationOption := datastore.ExpirationFilterOptionNoExpiration
-			if delta.AllowedType.RequiredExpiration != nil {
-				expirationOption = datastore.ExpirationFilterOptionHasExpiration
-			}
-
 			qyr, qyrErr := rwt.QueryRelationships(
 				ctx,
-				datastore.RelationshipsFilter{
-					OptionalResourceType:     nsdef.Name,
-					OptionalResourceRelation: delta.RelationName,
-					OptionalSubjectsSelectors: []datastore.SubjectsSelector{
-						{
-							OptionalSubjectType: delta.AllowedType.Namespace,
-							OptionalSubjectIds:  optionalSubjectIds,
-							RelationFilter:      relationFilter,
-						},
-					},
-					OptionalCaveatName:       optionalCaveatName,
-					OptionalExpirationOption: expirationOption,
-				},
+				removedAllowedTypeFilter(nsdef.Name, delta.RelationName, delta.AllowedType),
 				options.WithLimit(options.LimitOne),
 			)
 			err = errorIfTupleIteratorReturnsTuples(
@@ -437,6 +376,89 @@ func sanityCheckNamespaceChanges(
 	return diff, nil
 }
 
+// removedRelationFilter returns the filter for the relationships of a relation removed from a
+// definition.
+func removedRelationFilter(namespaceName string, previousRelation *core.Relation) datastore.RelationshipsFilter {
+	// NOTE: We add the subject filters here to ensure the reverse relationship index is used
+	// by the datastores. As there is no index that has {namespace, relation} directly, but there
+	// *is* an index that has {subject_namespace, subject_relation, namespace, relation}, we can
+	// force the datastore to use the reverse index by adding the subject filters.
+	subjectSelectors := make([]datastore.SubjectsSelector, 0, len(previousRelation.TypeInformation.AllowedDirectRelations))
+	for _, allowedType := range previousRelation.TypeInformation.AllowedDirectRelations {
+		if allowedType.GetRelation() == datastore.Ellipsis {
+			subjectSelectors = append(subjectSelectors, datastore.SubjectsSelector{
+				OptionalSubjectType: allowedType.Namespace,
+				RelationFilter: datastore.SubjectRelationFilter{
+					IncludeEllipsisRelation: true,
+				},
+			})
+		} else {
+			subjectSelectors = append(subjectSelectors, datastore.SubjectsSelector{
+				OptionalSubjectType: allowedType.Namespace,
+				RelationFilter: datastore.SubjectRelationFilter{
+					NonEllipsisRelation: allowedType.GetRelation(),
+				},
+			})
+		}
+	}
+
+	return datastore.RelationshipsFilter{
+		OptionalResourceType:      namespaceName,
+		OptionalResourceRelation:  previousRelation.Name,
+		OptionalSubjectsSelectors: subjectSelectors,
+	}
+}
+
+// removedRelationSubjectsFilter returns the filter for the relationships whose subjects reference
+// a relation removed from a definition.
+func removedRelationSubjectsFilter(namespaceName string, relationName string) datastore.SubjectsFilter {
+	return datastore.SubjectsFilter{
+		SubjectType: namespaceName,
+		RelationFilter: datastore.SubjectRelationFilter{
+			NonEllipsisRelation: relationName,
+		},
+	}
+}
+
+// removedAllowedTypeFilter returns the filter for the relationships of a relation with a subject
+// type removed from its allowed types.
+func removedAllowedTypeFilter(namespaceName string, relationName string, allowedType *core.AllowedRelation) datastore.RelationshipsFilter {
+	var optionalSubjectIds []string
+	var relationFilter datastore.SubjectRelationFilter
+	optionalCaveatName := ""
+
+	if allowedType.GetPublicWildcard() != nil {
+		optionalSubjectIds = []string{tuple.PublicWildcard}
+	} else {
+		relationFilter = datastore.SubjectRelationFilter{
+			NonEllipsisRelation: allowedType.GetRelation(),
+		}
+	}
+
+	if allowedType.GetRequiredCaveat() != nil {
+		optionalCaveatName = allowedType.GetRequiredCaveat().CaveatName
+	}
+
+	expirationOption := datastore.ExpirationFilterOptionNoExpiration
+	if allowedType.RequiredExpiration != nil {
+		expirationOption = datastore.ExpirationFilterOptionHasExpiration
+	}
+
+	return datastore.RelationshipsFilter{
+		OptionalResourceType:     namespaceName,
+		OptionalResourceRelation: relationName,
+		OptionalSubjectsSelectors: []datastore.SubjectsSelector{
+			{
+				OptionalSubjectType: allowedType.Namespace,
+				OptionalSubjectIds:  optionalSubjectIds,
+				RelationFilter:      relationFilter,
+			},
+		},
+		OptionalCaveatName:       optionalCaveatName,
+		OptionalExpirationOption: expirationOption,
+	}
+}
+
 // errorIfTupleIteratorReturnsTuples takes a tuple iterator and any error that was generated
 // when the original iterator was created, and returns an error if iterator contains any tuples.
 func errorIfTupleIteratorReturnsTuples(_ context.Context, qy datastore.RelationshipIterator, qyErr error, message string, args ...interface{}) error {
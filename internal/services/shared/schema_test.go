@@ -2,6 +2,8 @@ package shared
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -329,3 +331,31 @@ func TestApplySchemaChanges(t *testing.T) {
 		})
 	}
 }
+
+func FuzzValidateSchemaChanges(f *testing.F) {
+	filenames, err := filepath.Glob("../../../pkg/schemadsl/parser/tests/*.zed")
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	for _, filename := range filenames {
+		b, err := os.ReadFile(filename)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(string(b))
+	}
+
+	f.Fuzz(func(t *testing.T, schema string) {
+		compiled, err := compiler.Compile(compiler.InputSchema{
+			Source:       input.Source("fuzz"),
+			SchemaString: schema,
+		}, compiler.AllowUnprefixedObjectType())
+		if err != nil {
+			return
+		}
+
+		// Type checking may reject the schema, but must never panic.
+		_, _ = ValidateSchemaChanges(context.Background(), compiled, false)
+	})
+}
@@ -0,0 +1,259 @@
+package shared
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/genutil/mapz"
+	"github.com/authzed/spicedb/pkg/namespace"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	iv1 "github.com/authzed/spicedb/pkg/proto/impl/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+	"github.com/authzed/spicedb/pkg/typesystem"
+)
+
+const (
+	// maxCountedInvalidatedRelationships is the number of invalidated relationships counted for
+	// each change, after which the count is a lower bound.
+	maxCountedInvalidatedRelationships = 1000
+
+	// maxInvalidatedRelationshipExamples is the number of invalidated relationships returned as
+	// examples for each change.
+	maxInvalidatedRelationshipExamples = 5
+)
+
+// SchemaChangesImpact is the impact of writing a schema over the existing schema and
+// relationships, as analyzed by AnalyzeSchemaChanges.
+type SchemaChangesImpact struct {
+	// Writable is whether the schema can be written, i.e. whether no existing relationship is
+	// invalidated and no caveat parameter is removed or changes type.
+	Writable bool `json:"writable"`
+
+	// Errors are the reasons for which the schema cannot be written, if any.
+	Errors []string `json:"errors,omitempty"`
+
+	// InvalidatedRelationships are the existing relationships which would no longer conform to
+	// the schema, by change.
+	InvalidatedRelationships []InvalidatedRelationships `json:"invalidatedRelationships,omitempty"`
+
+	// ReachabilityChanges are the permissions whose reachable relations and permissions change.
+	ReachabilityChanges []ReachabilityChange `json:"reachabilityChanges,omitempty"`
+
+	// EstimatedRelationshipCount is the estimated number of relationships in the datastore, from
+	// its statistics, to put the counts of invalidated relationships in perspective.
+	EstimatedRelationshipCount uint64 `json:"estimatedRelationshipCount"`
+}
+
+// InvalidatedRelationships are the existing relationships invalidated by a schema change.
+type InvalidatedRelationships struct {
+	// Change describes the schema change.
+	Change string `json:"change"`
+
+	// Count is the number of invalidated relationships, counted up to a maximum.
+	Count uint64 `json:"count"`
+
+	// CountIsLowerBound is whether the maximum was reached, and more relationships may be
+	// invalidated.
+	CountIsLowerBound bool `json:"countIsLowerBound,omitempty"`
+
+	// Examples are some of the invalidated relationships.
+	Examples []string `json:"examples"`
+}
+
+// ReachabilityChange is the change of the relations and permissions reachable from a permission,
+// i.e. those it is computed from.
+type ReachabilityChange struct {
+	// Permission is the changed permission, as `definition#permission`.
+	Permission string `json:"permission"`
+
+	// Added are the relations and permissions which become reachable.
+	Added []string `json:"added,omitempty"`
+
+	// Removed are the relations and permissions which are no longer reachable.
+	Removed []string `json:"removed,omitempty"`
+}
+
+// AnalyzeSchemaChanges analyzes the impact of applying the validated schema changes over the
+// schema and relationships of the reader, without applying them.
+func AnalyzeSchemaChanges(ctx context.Context, reader datastore.Reader, validated *ValidatedSchemaChanges, stats datastore.Stats) (*SchemaChangesImpact, error) {
+	existingCaveats, err := reader.ListAllCaveats(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	existingObjectDefs, err := reader.ListAllNamespaces(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	impact := &SchemaChangesImpact{
+		EstimatedRelationshipCount: stats.EstimatedRelationshipCount,
+	}
+
+	existingCaveatDefMap := make(map[string]*core.CaveatDefinition, len(existingCaveats))
+	for _, existingCaveat := range datastore.DefinitionsOf(existingCaveats) {
+		existingCaveatDefMap[existingCaveat.Name] = existingCaveat
+	}
+
+	for _, caveatDef := range validated.compiled.CaveatDefinitions {
+		if _, err := sanityCheckCaveatChanges(ctx, nil, caveatDef, existingCaveatDefMap); err != nil {
+			impact.Errors = append(impact.Errors, err.Error())
+		}
+	}
+
+	queries, err := blockingRelationshipQueries(validated, datastore.DefinitionsOf(existingObjectDefs))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, query := range queries {
+		if err := impact.addInvalidated(ctx, reader, query); err != nil {
+			return nil, err
+		}
+	}
+
+	impact.ReachabilityChanges, err = reachabilityChanges(ctx, validated, datastore.DefinitionsOf(existingObjectDefs), datastore.DefinitionsOf(existingCaveats))
+	if err != nil {
+		return nil, err
+	}
+
+	impact.Writable = len(impact.Errors) == 0
+	return impact, nil
+}
+
+// addInvalidated adds the relationships returned by the query as invalidated by its change.
+func (impact *SchemaChangesImpact) addInvalidated(ctx context.Context, reader datastore.Reader, query blockingRelationshipQuery) error {
+	limit := uint64(maxCountedInvalidatedRelationships + 1)
+	it, err := query.execute(ctx, reader, &limit)
+	if err != nil {
+		return err
+	}
+
+	invalidated := InvalidatedRelationships{
+		Change:   query.change,
+		Examples: make([]string, 0, maxInvalidatedRelationshipExamples),
+	}
+	for rel, err := range it {
+		if err != nil {
+			return err
+		}
+
+		if invalidated.Count == maxCountedInvalidatedRelationships {
+			invalidated.CountIsLowerBound = true
+			break
+		}
+
+		invalidated.Count++
+		if len(invalidated.Examples) < maxInvalidatedRelationshipExamples {
+			invalidated.Examples = append(invalidated.Examples, tuple.MustString(rel))
+		}
+	}
+
+	if invalidated.Count == 0 {
+		return nil
+	}
+
+	impact.InvalidatedRelationships = append(impact.InvalidatedRelationships, invalidated)
+	impact.Errors = append(impact.Errors, fmt.Sprintf("%s, but relationships exist with it", query.change))
+	return nil
+}
+
+func findRelation(nsdef *core.NamespaceDefinition, relationName string) (*core.Relation, bool) {
+	for _, relation := range nsdef.GetRelation() {
+		if relation.Name == relationName {
+			return relation, true
+		}
+	}
+	return nil, false
+}
+
+// reachabilityChanges returns the permissions defined in both the existing and the new schema
+// whose reachable relations and permissions differ between them.
+func reachabilityChanges(
+	ctx context.Context,
+	validated *ValidatedSchemaChanges,
+	existingObjectDefs []*core.NamespaceDefinition,
+	existingCaveats []*core.CaveatDefinition,
+) ([]ReachabilityChange, error) {
+	existingResolver := typesystem.ResolverForPredefinedDefinitions(typesystem.PredefinedElements{
+		Namespaces: existingObjectDefs,
+		Caveats:    existingCaveats,
+	})
+
+	var changes []ReachabilityChange
+	for _, existingDef := range existingObjectDefs {
+		newTypeSystem, ok := validated.validatedTypeSystems[existingDef.Name]
+		if !ok {
+			continue
+		}
+
+		existingTypeSystem, err := typesystem.NewNamespaceTypeSystem(existingDef, existingResolver)
+		if err != nil {
+			return nil, err
+		}
+
+		existingValidated, err := existingTypeSystem.Validate(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		existingGraph := typesystem.ReachabilityGraphFor(existingValidated)
+		newGraph := typesystem.ReachabilityGraphFor(newTypeSystem)
+
+		for _, relation := range existingDef.Relation {
+			if namespace.GetRelationKind(relation) != iv1.RelationMetadata_PERMISSION {
+				continue
+			}
+
+			if _, ok := findRelation(newTypeSystem.Namespace(), relation.Name); !ok {
+				continue
+			}
+
+			permission := &core.RelationReference{Namespace: existingDef.Name, Relation: relation.Name}
+			existingReachable, err := reachableRelations(ctx, existingGraph, permission)
+			if err != nil {
+				return nil, err
+			}
+
+			newReachable, err := reachableRelations(ctx, newGraph, permission)
+			if err != nil {
+				return nil, err
+			}
+
+			added := newReachable.Subtract(existingReachable).AsSlice()
+			removed := existingReachable.Subtract(newReachable).AsSlice()
+			if len(added) == 0 && len(removed) == 0 {
+				continue
+			}
+
+			slices.Sort(added)
+			slices.Sort(removed)
+			changes = append(changes, ReachabilityChange{
+				Permission: tuple.StringCoreRR(permission),
+				Added:      added,
+				Removed:    removed,
+			})
+		}
+	}
+
+	slices.SortFunc(changes, func(a, b ReachabilityChange) int {
+		return strings.Compare(a.Permission, b.Permission)
+	})
+	return changes, nil
+}
+
+func reachableRelations(ctx context.Context, graph *typesystem.ReachabilityGraph, permission *core.RelationReference) (*mapz.Set[string], error) {
+	encountered, err := graph.RelationsEncounteredForResource(ctx, permission)
+	if err != nil {
+		return nil, err
+	}
+
+	reachable := mapz.NewSet[string]()
+	for _, relation := range encountered {
+		reachable.Insert(tuple.StringCoreRR(relation))
+	}
+	return reachable, nil
+}
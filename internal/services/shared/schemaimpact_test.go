@@ -0,0 +1,161 @@
+package shared
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/dsfortesting"
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/schemadsl/compiler"
+	"github.com/authzed/spicedb/pkg/schemadsl/input"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+func TestAnalyzeSchemaChanges(t *testing.T) {
+	startingSchema := `
+		definition user {}
+
+		definition group {
+			relation member: user
+		}
+
+		definition document {
+			relation viewer: user | group#member
+			relation editor: user
+			permission view = viewer + editor
+			permission edit = editor
+		}
+
+		caveat somecaveat(someparam int) {
+			someparam == 42
+		}`
+
+	tcs := []struct {
+		name           string
+		endingSchema   string
+		expectedImpact SchemaChangesImpact
+	}{
+		{
+			name:         "no changes",
+			endingSchema: startingSchema,
+			expectedImpact: SchemaChangesImpact{
+				Writable:                   true,
+				EstimatedRelationshipCount: 42,
+			},
+		},
+		{
+			name: "invalidated relationships and changed reachability",
+			endingSchema: `
+				definition user {}
+
+				definition document {
+					relation viewer: user
+					permission view = viewer
+					permission edit = nil
+				}
+
+				caveat somecaveat(someparam int) {
+					someparam == 42
+				}`,
+			expectedImpact: SchemaChangesImpact{
+				Writable: false,
+				Errors: []string{
+					"relation `editor` removed from object definition `document`, but relationships exist with it",
+					"allowed type `group#member` removed from relation `viewer` in object definition `document`, but relationships exist with it",
+					"object definition `group` removed, but relationships exist with it",
+					"object definition `group` removed, referenced by subjects, but relationships exist with it",
+				},
+				InvalidatedRelationships: []InvalidatedRelationships{
+					{
+						Change:   "relation `editor` removed from object definition `document`",
+						Count:    2,
+						Examples: []string{"document:firstdoc#editor@user:tom", "document:seconddoc#editor@user:sarah"},
+					},
+					{
+						Change:   "allowed type `group#member` removed from relation `viewer` in object definition `document`",
+						Count:    1,
+						Examples: []string{"document:firstdoc#viewer@group:admins#member"},
+					},
+					{
+						Change:   "object definition `group` removed",
+						Count:    1,
+						Examples: []string{"group:admins#member@user:sarah"},
+					},
+					{
+						Change:   "object definition `group` removed, referenced by subjects",
+						Count:    1,
+						Examples: []string{"document:firstdoc#viewer@group:admins#member"},
+					},
+				},
+				ReachabilityChanges: []ReachabilityChange{
+					{
+						Permission: "document#edit",
+						Removed:    []string{"document#editor"},
+					},
+					{
+						Permission: "document#view",
+						Removed:    []string{"document#editor", "group#member"},
+					},
+				},
+				EstimatedRelationshipCount: 42,
+			},
+		},
+		{
+			name: "changed caveat parameter",
+			endingSchema: `
+				definition user {}
+
+				definition group {
+					relation member: user
+				}
+
+				definition document {
+					relation viewer: user | group#member
+					relation editor: user
+					permission view = viewer + editor
+					permission edit = editor
+				}
+
+				caveat somecaveat(someparam string) {
+					someparam == "42"
+				}`,
+			expectedImpact: SchemaChangesImpact{
+				Writable:                   false,
+				Errors:                     []string{"cannot change the type of parameter `someparam` on caveat `somecaveat`"},
+				EstimatedRelationshipCount: 42,
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			rawDS, err := dsfortesting.NewMemDBDatastoreForTesting(0, 0, memdb.DisableGC)
+			require.NoError(t, err)
+
+			relationships := []tuple.Relationship{
+				tuple.MustParse("document:firstdoc#editor@user:tom"),
+				tuple.MustParse("document:seconddoc#editor@user:sarah"),
+				tuple.MustParse("document:firstdoc#viewer@group:admins#member"),
+				tuple.MustParse("group:admins#member@user:sarah"),
+			}
+			ds, revision := testfixtures.DatastoreFromSchemaAndTestRelationships(rawDS, startingSchema, relationships, require.New(t))
+
+			compiled, err := compiler.Compile(compiler.InputSchema{
+				Source:       input.Source("schema"),
+				SchemaString: tc.endingSchema,
+			}, compiler.AllowUnprefixedObjectType())
+			require.NoError(t, err)
+
+			validated, err := ValidateSchemaChanges(context.Background(), compiled, false)
+			require.NoError(t, err)
+
+			impact, err := AnalyzeSchemaChanges(context.Background(), ds.SnapshotReader(revision), validated, datastore.Stats{EstimatedRelationshipCount: 42})
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedImpact, *impact)
+		})
+	}
+}
@@ -0,0 +1,245 @@
+package shared
+
+import (
+	"context"
+	"fmt"
+
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/options"
+	nsdiff "github.com/authzed/spicedb/pkg/diff/namespace"
+	"github.com/authzed/spicedb/pkg/genutil/mapz"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+	"github.com/authzed/spicedb/pkg/typesystem"
+)
+
+// BlockingRelationships are the existing relationships which block a schema change from being
+// applied, as they would no longer conform to the schema.
+type BlockingRelationships struct {
+	// Change describes the schema change.
+	Change string
+
+	// Count is the exact number of blocking relationships.
+	Count uint64
+}
+
+// blockingRelationshipQuery is the query for the relationships which block a schema change.
+type blockingRelationshipQuery struct {
+	change string
+
+	// filter is the filter of the relationships, unless subjectsFilter is set.
+	filter datastore.RelationshipsFilter
+
+	// subjectsFilter, if set, is the filter of the subjects of the relationships.
+	subjectsFilter *datastore.SubjectsFilter
+}
+
+func (query blockingRelationshipQuery) execute(ctx context.Context, reader datastore.Reader, limit *uint64) (datastore.RelationshipIterator, error) {
+	if query.subjectsFilter != nil {
+		return reader.ReverseQueryRelationships(ctx, *query.subjectsFilter, options.WithLimitForReverse(limit))
+	}
+	return reader.QueryRelationships(ctx, query.filter, options.WithLimit(limit))
+}
+
+// blockingRelationshipQueries returns the queries for the relationships which block the validated
+// schema changes from being applied over the existing object definitions: those of removed
+// relations, allowed types and definitions.
+func blockingRelationshipQueries(validated *ValidatedSchemaChanges, existingObjectDefs []*core.NamespaceDefinition) ([]blockingRelationshipQuery, error) {
+	existingObjectDefMap := make(map[string]*core.NamespaceDefinition, len(existingObjectDefs))
+	for _, existingDef := range existingObjectDefs {
+		existingObjectDefMap[existingDef.Name] = existingDef
+	}
+
+	var queries []blockingRelationshipQuery
+	for _, nsdef := range validated.compiled.ObjectDefinitions {
+		diff, err := nsdiff.DiffNamespaces(existingObjectDefMap[nsdef.Name], nsdef)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, delta := range diff.Deltas() {
+			switch delta.Type {
+			case nsdiff.RemovedRelation:
+				previousRelation, ok := findRelation(existingObjectDefMap[nsdef.Name], delta.RelationName)
+				if !ok {
+					continue
+				}
+
+				subjectsFilter := removedRelationSubjectsFilter(nsdef.Name, delta.RelationName)
+				queries = append(queries,
+					blockingRelationshipQuery{
+						change: fmt.Sprintf("relation `%s` removed from object definition `%s`", delta.RelationName, nsdef.Name),
+						filter: removedRelationFilter(nsdef.Name, previousRelation),
+					},
+					blockingRelationshipQuery{
+						change:         fmt.Sprintf("relation `%s` removed from object definition `%s`, referenced by subjects", delta.RelationName, nsdef.Name),
+						subjectsFilter: &subjectsFilter,
+					},
+				)
+
+			case nsdiff.RelationAllowedTypeRemoved:
+				queries = append(queries, blockingRelationshipQuery{
+					change: fmt.Sprintf("allowed type `%s` removed from relation `%s` in object definition `%s`", typesystem.SourceForAllowedRelation(delta.AllowedType), delta.RelationName, nsdef.Name),
+					filter: removedAllowedTypeFilter(nsdef.Name, delta.RelationName, delta.AllowedType),
+				})
+			}
+		}
+	}
+
+	// Removed definitions are not deleted when only additive changes are allowed.
+	if !validated.additiveOnly {
+		for _, existingDef := range existingObjectDefs {
+			if validated.newObjectDefNames.Has(existingDef.Name) {
+				continue
+			}
+
+			subjectsFilter := datastore.SubjectsFilter{SubjectType: existingDef.Name}
+			queries = append(queries,
+				blockingRelationshipQuery{
+					change: fmt.Sprintf("object definition `%s` removed", existingDef.Name),
+					filter: datastore.RelationshipsFilter{OptionalResourceType: existingDef.Name},
+				},
+				blockingRelationshipQuery{
+					change:         fmt.Sprintf("object definition `%s` removed, referenced by subjects", existingDef.Name),
+					subjectsFilter: &subjectsFilter,
+				},
+			)
+		}
+	}
+
+	return queries, nil
+}
+
+// CountBlockingRelationships returns the exact number of existing relationships of the reader
+// which block each of the validated schema changes from being applied, for the changes blocked by
+// at least one relationship.
+func CountBlockingRelationships(ctx context.Context, reader datastore.Reader, validated *ValidatedSchemaChanges) ([]BlockingRelationships, error) {
+	existingObjectDefs, err := reader.ListAllNamespaces(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	queries, err := blockingRelationshipQueries(validated, datastore.DefinitionsOf(existingObjectDefs))
+	if err != nil {
+		return nil, err
+	}
+
+	var blocking []BlockingRelationships
+	for _, query := range queries {
+		it, err := query.execute(ctx, reader, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var count uint64
+		for _, err := range it {
+			if err != nil {
+				return nil, err
+			}
+			count++
+		}
+
+		if count > 0 {
+			blocking = append(blocking, BlockingRelationships{Change: query.change, Count: count})
+		}
+	}
+
+	return blocking, nil
+}
+
+// ApplySchemaChangesDeletingBlockingRelationships applies the validated schema changes within the
+// transaction, after deleting the existing relationships which block them within the same
+// transaction, in writes of up to batchSize relationships each, and returns the number of
+// relationships deleted. Either the relationships are deleted and the schema is written, or
+// neither is.
+func ApplySchemaChangesDeletingBlockingRelationships(ctx context.Context, rwt datastore.ReadWriteTransaction, validated *ValidatedSchemaChanges, batchSize uint64) (*AppliedSchemaChanges, uint64, error) {
+	existingCaveats, err := rwt.ListAllCaveats(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	existingObjectDefs, err := rwt.ListAllNamespaces(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	queries, err := blockingRelationshipQueries(validated, datastore.DefinitionsOf(existingObjectDefs))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// The blocking relationships are all read before any is deleted, as a relationship can block
+	// several changes, and as some datastores do not return the writes of a transaction to its
+	// reads.
+	deleted := mapz.NewSet[string]()
+	var mutations []tuple.RelationshipUpdate
+	for _, query := range queries {
+		it, err := query.execute(ctx, rwt, nil)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		for rel, err := range it {
+			if err != nil {
+				return nil, 0, err
+			}
+			if deleted.Add(tuple.StringWithoutCaveatOrExpiration(rel)) {
+				mutations = append(mutations, tuple.Delete(rel))
+			}
+		}
+
+		log.Ctx(ctx).Debug().Str("change", query.change).Int("deleted", len(mutations)).Msg("deleting relationships blocking schema change")
+	}
+
+	for start := 0; start < len(mutations); start += int(batchSize) {
+		if err := rwt.WriteRelationships(ctx, mutations[start:min(start+int(batchSize), len(mutations))]); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	applied, err := ApplySchemaChangesOverExisting(ctx, excludingRelationshipsTxn{rwt, deleted}, validated, datastore.DefinitionsOf(existingCaveats), datastore.DefinitionsOf(existingObjectDefs))
+	if err != nil {
+		return nil, 0, err
+	}
+	return applied, uint64(len(mutations)), nil
+}
+
+// excludingRelationshipsTxn is a transaction whose relationship queries exclude the relationships
+// deleted within it, for the datastores which do not return the writes of a transaction to its
+// reads.
+type excludingRelationshipsTxn struct {
+	datastore.ReadWriteTransaction
+
+	// excluded holds the excluded relationships, without their caveats or expirations.
+	excluded *mapz.Set[string]
+}
+
+func (txn excludingRelationshipsTxn) QueryRelationships(ctx context.Context, filter datastore.RelationshipsFilter, opts ...options.QueryOptionsOption) (datastore.RelationshipIterator, error) {
+	it, err := txn.ReadWriteTransaction.QueryRelationships(ctx, filter, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return txn.exclude(it), nil
+}
+
+func (txn excludingRelationshipsTxn) ReverseQueryRelationships(ctx context.Context, subjectsFilter datastore.SubjectsFilter, opts ...options.ReverseQueryOptionsOption) (datastore.RelationshipIterator, error) {
+	it, err := txn.ReadWriteTransaction.ReverseQueryRelationships(ctx, subjectsFilter, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return txn.exclude(it), nil
+}
+
+func (txn excludingRelationshipsTxn) exclude(it datastore.RelationshipIterator) datastore.RelationshipIterator {
+	return func(yield func(tuple.Relationship, error) bool) {
+		for rel, err := range it {
+			if err == nil && txn.excluded.Has(tuple.StringWithoutCaveatOrExpiration(rel)) {
+				continue
+			}
+			if !yield(rel, err) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package shared
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/dsfortesting"
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/schemadsl/compiler"
+	"github.com/authzed/spicedb/pkg/schemadsl/input"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+func TestBlockingRelationships(t *testing.T) {
+	startingSchema := `
+		definition user {}
+
+		definition group {
+			relation member: user
+		}
+
+		definition document {
+			relation viewer: user | group#member
+			relation editor: user
+		}`
+
+	endingSchema := `
+		definition user {}
+
+		definition document {
+			relation viewer: user
+		}`
+
+	rawDS, err := dsfortesting.NewMemDBDatastoreForTesting(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+
+	relationships := []tuple.Relationship{
+		tuple.MustParse("document:firstdoc#editor@user:tom"),
+		tuple.MustParse("document:seconddoc#editor@user:sarah"),
+		tuple.MustParse("document:thirddoc#editor@user:sarah"),
+		tuple.MustParse("document:firstdoc#viewer@group:admins#member"),
+		tuple.MustParse("document:firstdoc#viewer@user:tom"),
+		tuple.MustParse("group:admins#member@user:sarah"),
+	}
+	ds, revision := testfixtures.DatastoreFromSchemaAndTestRelationships(rawDS, startingSchema, relationships, require.New(t))
+
+	compiled, err := compiler.Compile(compiler.InputSchema{
+		Source:       input.Source("schema"),
+		SchemaString: endingSchema,
+	}, compiler.AllowUnprefixedObjectType())
+	require.NoError(t, err)
+
+	validated, err := ValidateSchemaChanges(context.Background(), compiled, false)
+	require.NoError(t, err)
+
+	blocking, err := CountBlockingRelationships(context.Background(), ds.SnapshotReader(revision), validated)
+	require.NoError(t, err)
+	require.Equal(t, []BlockingRelationships{
+		{Change: "relation `editor` removed from object definition `document`", Count: 3},
+		{Change: "allowed type `group#member` removed from relation `viewer` in object definition `document`", Count: 1},
+		{Change: "object definition `group` removed", Count: 1},
+		{Change: "object definition `group` removed, referenced by subjects", Count: 1},
+	}, blocking)
+
+	var deleted uint64
+	_, err = ds.ReadWriteTx(context.Background(), func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		var err error
+		_, deleted, err = ApplySchemaChangesDeletingBlockingRelationships(ctx, rwt, validated, 2)
+		return err
+	})
+	require.NoError(t, err)
+	require.Equal(t, uint64(5), deleted)
+
+	headRevision, err := ds.HeadRevision(context.Background())
+	require.NoError(t, err)
+
+	it, err := ds.SnapshotReader(headRevision).QueryRelationships(context.Background(), datastore.RelationshipsFilter{OptionalResourceType: "document"})
+	require.NoError(t, err)
+
+	remaining, err := datastore.IteratorToSlice(it)
+	require.NoError(t, err)
+	require.Len(t, remaining, 1)
+	require.Equal(t, "document:firstdoc#viewer@user:tom", tuple.MustString(remaining[0]))
+}
+
+func TestDeleteBlockingRelationshipsWithFailedSchemaWrite(t *testing.T) {
+	startingSchema := `
+		definition user {}
+
+		caveat somecaveat(first int, second int) {
+			first == second
+		}
+
+		definition document {
+			relation viewer: user | user with somecaveat
+			relation editor: user
+		}`
+
+	// The caveat parameter cannot be removed, so the schema write fails after the blocking
+	// relationships are deleted.
+	endingSchema := `
+		definition user {}
+
+		caveat somecaveat(first int) {
+			first == 42
+		}
+
+		definition document {
+			relation viewer: user | user with somecaveat
+		}`
+
+	rawDS, err := dsfortesting.NewMemDBDatastoreForTesting(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+
+	relationships := []tuple.Relationship{
+		tuple.MustParse("document:firstdoc#editor@user:tom"),
+		tuple.MustParse("document:firstdoc#viewer@user:tom"),
+	}
+	ds, _ := testfixtures.DatastoreFromSchemaAndTestRelationships(rawDS, startingSchema, relationships, require.New(t))
+
+	compiled, err := compiler.Compile(compiler.InputSchema{
+		Source:       input.Source("schema"),
+		SchemaString: endingSchema,
+	}, compiler.AllowUnprefixedObjectType())
+	require.NoError(t, err)
+
+	validated, err := ValidateSchemaChanges(context.Background(), compiled, false)
+	require.NoError(t, err)
+
+	_, err = ds.ReadWriteTx(context.Background(), func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		_, _, err := ApplySchemaChangesDeletingBlockingRelationships(ctx, rwt, validated, 2)
+		return err
+	})
+	require.ErrorContains(t, err, "cannot remove parameter `second` on caveat `somecaveat`")
+
+	// The blocking relationships are not deleted, as the schema was not written.
+	headRevision, err := ds.HeadRevision(context.Background())
+	require.NoError(t, err)
+
+	it, err := ds.SnapshotReader(headRevision).QueryRelationships(context.Background(), datastore.RelationshipsFilter{OptionalResourceType: "document"})
+	require.NoError(t, err)
+
+	remaining, err := datastore.IteratorToSlice(it)
+	require.NoError(t, err)
+	require.Len(t, remaining, 2)
+}
@@ -0,0 +1,775 @@
+package shared
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/pkg/datastore"
+	nsdiff "github.com/authzed/spicedb/pkg/diff/namespace"
+	"github.com/authzed/spicedb/pkg/graph"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/schemadsl/compiler"
+	"github.com/authzed/spicedb/pkg/schemadsl/generator"
+	"github.com/authzed/spicedb/pkg/schemadsl/input"
+	"github.com/authzed/spicedb/pkg/tuple"
+	"github.com/authzed/spicedb/pkg/typesystem"
+)
+
+// SchemaRename is the rename of an object definition, or of a relation or permission of an object
+// definition, in the schema and in the relationships referencing it.
+type SchemaRename struct {
+	// DefinitionName is the name of the renamed object definition, or of the object definition of
+	// the renamed relation or permission.
+	DefinitionName string
+
+	// RelationName is the name of the renamed relation or permission, if any.
+	RelationName string
+
+	// NewName is the new name of the object definition, or of the relation or permission.
+	NewName string
+}
+
+func (rename SchemaRename) String() string {
+	if rename.RelationName != "" {
+		return fmt.Sprintf("%s#%s to %s#%s", rename.DefinitionName, rename.RelationName, rename.DefinitionName, rename.NewName)
+	}
+	return fmt.Sprintf("%s to %s", rename.DefinitionName, rename.NewName)
+}
+
+// SchemaRenameResult is the result of a rename applied by RenameInDatastore.
+type SchemaRenameResult struct {
+	// RelationshipsRenamed is the number of relationships rewritten with the new name.
+	RelationshipsRenamed uint64
+
+	// AlreadyApplied is whether the rename had already been applied, in which case nothing was
+	// changed.
+	AlreadyApplied bool
+}
+
+// ErrRenameTargetNotFound is returned by RenameInDatastore if neither the renamed object
+// definition, relation or permission nor its new name exist in the schema.
+var ErrRenameTargetNotFound = errors.New("the renamed object definition, relation or permission does not exist")
+
+// RenameInDatastore renames an object definition, relation or permission in both the schema and
+// the relationships of the datastore.
+//
+// If batchSize is zero, the rename is applied in a single transaction. Otherwise, the new name is
+// first added to the schema alongside the existing one, the relationships are then rewritten in
+// transactions of up to batchSize relationships each, and the existing name is finally removed.
+// While relationships are being rewritten, the permissions referencing a renamed relation
+// reference both its existing and new names, and relations allow both the existing and new names
+// of a renamed object definition, so that permissions are computed from both the relationships
+// already rewritten and those not yet rewritten. Relationships whose subject is a renamed object
+// definition or subject relation are however only resolved from the name they were written with.
+// If interrupted, the rename can be resumed by applying it again.
+func RenameInDatastore(ctx context.Context, ds datastore.Datastore, rename SchemaRename, batchSize uint64) (*SchemaRenameResult, error) {
+	if rename.NewName == "" || rename.NewName == rename.RelationName || (rename.RelationName == "" && rename.NewName == rename.DefinitionName) {
+		return nil, fmt.Errorf("invalid new name `%s`", rename.NewName)
+	}
+
+	if batchSize == 0 {
+		return renameAtomically(ctx, ds, rename)
+	}
+
+	result := &SchemaRenameResult{}
+
+	// Add the new name alongside the existing one.
+	var queries []blockingRelationshipQuery
+	_, err := ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		existingObjectDefs, existingCaveats, err := listDefinitions(ctx, rwt)
+		if err != nil {
+			return err
+		}
+
+		applied, err := rename.alreadyApplied(existingObjectDefs)
+		if err != nil || applied {
+			result.AlreadyApplied = applied
+			return err
+		}
+
+		intermediate, err := rename.withNewName(existingObjectDefs)
+		if err != nil {
+			return err
+		}
+
+		queries = rename.relationshipQueries(existingObjectDefs)
+		return applyDefinitions(ctx, rwt, intermediate, existingCaveats)
+	})
+	if err != nil || result.AlreadyApplied {
+		return result, err
+	}
+
+	// Rewrite the relationships in batches.
+	for _, query := range queries {
+		for {
+			var batchRenamed uint64
+			_, err := ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+				var err error
+				batchRenamed, err = rename.renameRelationships(ctx, rwt, []blockingRelationshipQuery{query}, &batchSize)
+				return err
+			})
+			if err != nil {
+				return result, err
+			}
+
+			result.RelationshipsRenamed += batchRenamed
+			log.Ctx(ctx).Debug().Stringer("rename", rename).Uint64("renamed", result.RelationshipsRenamed).Msg("renamed batch of relationships")
+
+			if batchRenamed < batchSize {
+				break
+			}
+		}
+	}
+
+	// Remove the existing name.
+	_, err = ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		existingObjectDefs, existingCaveats, err := listDefinitions(ctx, rwt)
+		if err != nil {
+			return err
+		}
+
+		renamed, err := rename.renamedDefinitions(existingObjectDefs)
+		if err != nil {
+			return err
+		}
+		return applyDefinitions(ctx, rwt, renamed, existingCaveats)
+	})
+	return result, err
+}
+
+func renameAtomically(ctx context.Context, ds datastore.Datastore, rename SchemaRename) (*SchemaRenameResult, error) {
+	result := &SchemaRenameResult{}
+	_, err := ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		existingObjectDefs, existingCaveats, err := listDefinitions(ctx, rwt)
+		if err != nil {
+			return err
+		}
+
+		result.AlreadyApplied, err = rename.alreadyApplied(existingObjectDefs)
+		if err != nil || result.AlreadyApplied {
+			return err
+		}
+
+		renamed, err := rename.renamedDefinitions(existingObjectDefs)
+		if err != nil {
+			return err
+		}
+
+		result.RelationshipsRenamed, err = rename.renameRelationships(ctx, rwt, rename.relationshipQueries(existingObjectDefs), nil)
+		if err != nil {
+			return err
+		}
+
+		return applyDefinitions(ctx, rwt, renamed, existingCaveats)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func listDefinitions(ctx context.Context, reader datastore.Reader) ([]*core.NamespaceDefinition, []*core.CaveatDefinition, error) {
+	existingObjectDefs, err := reader.ListAllNamespaces(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	existingCaveats, err := reader.ListAllCaveats(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return datastore.DefinitionsOf(existingObjectDefs), datastore.DefinitionsOf(existingCaveats), nil
+}
+
+// applyDefinitions applies the object definitions and caveats as the schema, validating them as
+// a schema written by WriteSchema would be.
+func applyDefinitions(ctx context.Context, rwt datastore.ReadWriteTransaction, objectDefs []*core.NamespaceDefinition, caveats []*core.CaveatDefinition) error {
+	definitions := make([]compiler.SchemaDefinition, 0, len(objectDefs)+len(caveats))
+	for _, caveat := range caveats {
+		definitions = append(definitions, caveat)
+	}
+	for _, objectDef := range objectDefs {
+		definitions = append(definitions, objectDef)
+	}
+
+	// The schema is compiled from its source, so that it is annotated as any written schema.
+	schemaText, _, err := generator.GenerateSchema(definitions)
+	if err != nil {
+		return err
+	}
+
+	compiled, err := compiler.Compile(compiler.InputSchema{
+		Source:       input.Source("schema"),
+		SchemaString: schemaText,
+	}, compiler.AllowUnprefixedObjectType())
+	if err != nil {
+		return err
+	}
+
+	validated, err := ValidateSchemaChanges(ctx, compiled, false)
+	if err != nil {
+		return err
+	}
+
+	_, err = ApplySchemaChanges(ctx, rwt, validated)
+	return err
+}
+
+// alreadyApplied returns whether the rename was already applied to the object definitions.
+func (rename SchemaRename) alreadyApplied(objectDefs []*core.NamespaceDefinition) (bool, error) {
+	var existing, renamed bool
+	if rename.RelationName == "" {
+		existing = findDefinition(objectDefs, rename.DefinitionName) != nil
+		renamed = findDefinition(objectDefs, rename.NewName) != nil
+	} else {
+		definition := findDefinition(objectDefs, rename.DefinitionName)
+		_, existing = findRelation(definition, rename.RelationName)
+		_, renamed = findRelation(definition, rename.NewName)
+	}
+
+	if !existing && !renamed {
+		return false, ErrRenameTargetNotFound
+	}
+	return !existing, nil
+}
+
+// relationshipQueries returns the queries for the relationships referencing the renamed object
+// definition, relation or permission, as resource or subject.
+func (rename SchemaRename) relationshipQueries(objectDefs []*core.NamespaceDefinition) []blockingRelationshipQuery {
+	if rename.RelationName == "" {
+		return []blockingRelationshipQuery{
+			{
+				change: fmt.Sprintf("rename of object definition `%s`", rename.DefinitionName),
+				filter: datastore.RelationshipsFilter{OptionalResourceType: rename.DefinitionName},
+			},
+			{
+				change:         fmt.Sprintf("rename of object definition `%s`, referenced by subjects", rename.DefinitionName),
+				subjectsFilter: &datastore.SubjectsFilter{SubjectType: rename.DefinitionName},
+			},
+		}
+	}
+
+	subjectsFilter := removedRelationSubjectsFilter(rename.DefinitionName, rename.RelationName)
+	queries := []blockingRelationshipQuery{
+		{
+			change:         fmt.Sprintf("rename of relation `%s` in object definition `%s`, referenced by subjects", rename.RelationName, rename.DefinitionName),
+			subjectsFilter: &subjectsFilter,
+		},
+	}
+
+	// Permissions have no relationships of their own.
+	relation, _ := findRelation(findDefinition(objectDefs, rename.DefinitionName), rename.RelationName)
+	if relation.GetTypeInformation() != nil {
+		queries = append([]blockingRelationshipQuery{
+			{
+				change: fmt.Sprintf("rename of relation `%s` in object definition `%s`", rename.RelationName, rename.DefinitionName),
+				filter: removedRelationFilter(rename.DefinitionName, relation),
+			},
+		}, queries...)
+	}
+	return queries
+}
+
+// renameRelationships rewrites the relationships returned by the queries, up to the limit for
+// each query if given, with the new name, and returns the number of relationships rewritten.
+func (rename SchemaRename) renameRelationships(ctx context.Context, rwt datastore.ReadWriteTransaction, queries []blockingRelationshipQuery, limit *uint64) (uint64, error) {
+	// A relationship can be returned by several queries, such as one whose resource and subject
+	// are both of a renamed object definition.
+	found := make(map[string]tuple.Relationship)
+	for _, query := range queries {
+		it, err := query.execute(ctx, rwt, limit)
+		if err != nil {
+			return 0, err
+		}
+
+		for rel, err := range it {
+			if err != nil {
+				return 0, err
+			}
+			found[tuple.StringWithoutCaveatOrExpiration(rel)] = rel
+		}
+	}
+
+	if len(found) == 0 {
+		return 0, nil
+	}
+
+	mutations := make([]tuple.RelationshipUpdate, 0, len(found)*2)
+	for _, rel := range found {
+		mutations = append(mutations, tuple.Delete(rel), tuple.Touch(rename.renamedRelationship(rel)))
+	}
+
+	if err := rwt.WriteRelationships(ctx, mutations); err != nil {
+		return 0, err
+	}
+	return uint64(len(found)), nil
+}
+
+func (rename SchemaRename) renamedRelationship(rel tuple.Relationship) tuple.Relationship {
+	renamed := rel
+
+	// The integrity of the relationship is computed anew when it is written.
+	renamed.OptionalIntegrity = nil
+
+	if rename.RelationName == "" {
+		if renamed.Resource.ObjectType == rename.DefinitionName {
+			renamed.Resource.ObjectType = rename.NewName
+		}
+		if renamed.Subject.ObjectType == rename.DefinitionName {
+			renamed.Subject.ObjectType = rename.NewName
+		}
+		return renamed
+	}
+
+	if renamed.Resource.ObjectType == rename.DefinitionName && renamed.Resource.Relation == rename.RelationName {
+		renamed.Resource.Relation = rename.NewName
+	}
+	if renamed.Subject.ObjectType == rename.DefinitionName && renamed.Subject.Relation == rename.RelationName {
+		renamed.Subject.Relation = rename.NewName
+	}
+	return renamed
+}
+
+// withNewName returns the object definitions with the renamed object definition, relation or
+// permission copied under its new name, and the new name allowed wherever the existing one is.
+// If the new name already exists, it must be such a copy, added by an interrupted rename.
+func (rename SchemaRename) withNewName(existingObjectDefs []*core.NamespaceDefinition) ([]*core.NamespaceDefinition, error) {
+	objectDefs := cloneDefinitions(existingObjectDefs)
+
+	definition := findDefinition(objectDefs, rename.DefinitionName)
+	if definition == nil {
+		return nil, ErrRenameTargetNotFound
+	}
+
+	var copied *core.NamespaceDefinition
+	var existingCopy *core.NamespaceDefinition
+	if rename.RelationName == "" {
+		copied = proto.Clone(definition).(*core.NamespaceDefinition)
+		copied.Name = rename.NewName
+		existingCopy = findDefinition(objectDefs, rename.NewName)
+		if existingCopy == nil {
+			objectDefs = append(objectDefs, copied)
+		}
+	} else {
+		relation, ok := findRelation(definition, rename.RelationName)
+		if !ok {
+			return nil, ErrRenameTargetNotFound
+		}
+
+		copiedRelation := proto.Clone(relation).(*core.Relation)
+		copiedRelation.Name = rename.NewName
+		copied = &core.NamespaceDefinition{Name: definition.Name, Relation: []*core.Relation{copiedRelation}}
+		if existingRelation, ok := findRelation(definition, rename.NewName); ok {
+			existingCopy = &core.NamespaceDefinition{Name: definition.Name, Relation: []*core.Relation{existingRelation}}
+		} else {
+			// The copy is placed after the relation, which it replaces once renamed.
+			index := slices.Index(definition.Relation, relation)
+			definition.Relation = slices.Insert(definition.Relation, index+1, copiedRelation)
+		}
+	}
+
+	for _, objectDef := range append(objectDefs, copied) {
+		for _, relation := range objectDef.Relation {
+			if relation.TypeInformation == nil {
+				continue
+			}
+
+			allowed := relation.TypeInformation.AllowedDirectRelations
+			for _, allowedType := range allowed {
+				if renamed, ok := rename.renamedAllowedType(allowedType); ok && !hasAllowedType(relation.TypeInformation.AllowedDirectRelations, renamed) {
+					relation.TypeInformation.AllowedDirectRelations = append(relation.TypeInformation.AllowedDirectRelations, renamed)
+				}
+			}
+		}
+	}
+
+	// Until the relationships of the renamed relation are all rewritten, the permissions
+	// referencing it must be computed from those of both names.
+	if relation, _ := findRelation(definition, rename.RelationName); relation.GetTypeInformation() != nil {
+		rename.addNewNameReferences(objectDefs)
+	}
+
+	if existingCopy != nil {
+		diff, err := nsdiff.DiffNamespaces(existingCopy, copied)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, delta := range diff.Deltas() {
+			switch delta.Type {
+			case nsdiff.NamespaceCommentsChanged, nsdiff.ChangedRelationComment, nsdiff.ChangedPermissionComment:
+				continue
+			default:
+				return nil, fmt.Errorf("cannot rename %s, as the new name already exists", rename)
+			}
+		}
+	}
+
+	return objectDefs, nil
+}
+
+// addNewNameReferences adds, alongside every reference to the renamed relation in the permissions
+// of the object definitions, the same reference to its new name, as a union. References already
+// added, by an interrupted rename, are not added again.
+func (rename SchemaRename) addNewNameReferences(objectDefs []*core.NamespaceDefinition) {
+	for _, objectDef := range objectDefs {
+		for _, relation := range objectDef.Relation {
+			if relation.UsersetRewrite != nil {
+				rename.addNewNameReferencesIn(objectDefs, objectDef, relation.UsersetRewrite)
+			}
+		}
+	}
+}
+
+func (rename SchemaRename) addNewNameReferencesIn(objectDefs []*core.NamespaceDefinition, objectDef *core.NamespaceDefinition, rewrite *core.UsersetRewrite) {
+	operation := setOperationOf(rewrite)
+	_, isUnion := rewrite.RewriteOperation.(*core.UsersetRewrite_Union)
+
+	children := make([]*core.SetOperation_Child, 0, len(operation.Child))
+	for _, child := range operation.Child {
+		if nested := child.GetUsersetRewrite(); nested != nil {
+			rename.addNewNameReferencesIn(objectDefs, objectDef, nested)
+			children = append(children, child)
+			continue
+		}
+
+		references := rename.newNameReferences(objectDefs, objectDef, child)
+		switch {
+		case len(references) == 1:
+			children = append(children, child)
+
+		case isUnion:
+			// The references are added to the union itself, unless already part of it.
+			for _, reference := range references {
+				if !slices.ContainsFunc(operation.Child, func(other *core.SetOperation_Child) bool {
+					return equalIgnoringPositions(reference, other)
+				}) || reference == child {
+					children = append(children, reference)
+				}
+			}
+
+		default:
+			children = append(children, &core.SetOperation_Child{
+				ChildType: &core.SetOperation_Child_UsersetRewrite{
+					UsersetRewrite: &core.UsersetRewrite{
+						RewriteOperation: &core.UsersetRewrite_Union{Union: &core.SetOperation{Child: references}},
+					},
+				},
+			})
+		}
+	}
+	operation.Child = children
+}
+
+// newNameReferences returns the child, followed by its copies referencing the new name of the
+// renamed relation in place of its existing name, if it references it.
+func (rename SchemaRename) newNameReferences(objectDefs []*core.NamespaceDefinition, objectDef *core.NamespaceDefinition, child *core.SetOperation_Child) []*core.SetOperation_Child {
+	references := []*core.SetOperation_Child{child}
+	withNewName := func(rename func(*core.SetOperation_Child)) {
+		for _, reference := range slices.Clone(references) {
+			renamed := proto.Clone(reference).(*core.SetOperation_Child)
+			rename(renamed)
+			references = append(references, renamed)
+		}
+	}
+
+	var tupleset, computed string
+	switch child := child.ChildType.(type) {
+	case *core.SetOperation_Child_ComputedUserset:
+		if objectDef.Name == rename.DefinitionName && child.ComputedUserset.Relation == rename.RelationName {
+			withNewName(func(renamed *core.SetOperation_Child) {
+				renamed.GetComputedUserset().Relation = rename.NewName
+			})
+		}
+		return references
+
+	case *core.SetOperation_Child_TupleToUserset:
+		tupleset, computed = child.TupleToUserset.Tupleset.Relation, child.TupleToUserset.ComputedUserset.Relation
+
+	case *core.SetOperation_Child_FunctionedTupleToUserset:
+		tupleset, computed = child.FunctionedTupleToUserset.Tupleset.Relation, child.FunctionedTupleToUserset.ComputedUserset.Relation
+
+	default:
+		return references
+	}
+
+	if objectDef.Name == rename.DefinitionName && tupleset == rename.RelationName {
+		withNewName(func(renamed *core.SetOperation_Child) {
+			if ttu := renamed.GetTupleToUserset(); ttu != nil {
+				ttu.Tupleset.Relation = rename.NewName
+			} else {
+				renamed.GetFunctionedTupleToUserset().Tupleset.Relation = rename.NewName
+			}
+		})
+	}
+
+	if walksRenamed, _ := rename.arrowTargets(objectDefs, objectDef, tupleset); walksRenamed && computed == rename.RelationName {
+		withNewName(func(renamed *core.SetOperation_Child) {
+			if ttu := renamed.GetTupleToUserset(); ttu != nil {
+				ttu.ComputedUserset.Relation = rename.NewName
+			} else {
+				renamed.GetFunctionedTupleToUserset().ComputedUserset.Relation = rename.NewName
+			}
+		})
+	}
+	return references
+}
+
+// removeNewNameReferences removes the references added by addNewNameReferences, which are
+// duplicates within their union once references are renamed.
+func removeNewNameReferences(objectDefs []*core.NamespaceDefinition) {
+	var removeIn func(rewrite *core.UsersetRewrite)
+	removeIn = func(rewrite *core.UsersetRewrite) {
+		operation := setOperationOf(rewrite)
+		_, isUnion := rewrite.RewriteOperation.(*core.UsersetRewrite_Union)
+
+		children := make([]*core.SetOperation_Child, 0, len(operation.Child))
+		for _, child := range operation.Child {
+			if nested := child.GetUsersetRewrite(); nested != nil {
+				removeIn(nested)
+				if nestedOperation := setOperationOf(nested); len(nestedOperation.Child) == 1 {
+					child = nestedOperation.Child[0]
+				}
+			}
+
+			if isUnion && slices.ContainsFunc(children, func(other *core.SetOperation_Child) bool {
+				return equalIgnoringPositions(child, other)
+			}) {
+				continue
+			}
+			children = append(children, child)
+		}
+		operation.Child = children
+	}
+
+	for _, objectDef := range objectDefs {
+		for _, relation := range objectDef.Relation {
+			if relation.UsersetRewrite != nil {
+				removeIn(relation.UsersetRewrite)
+			}
+		}
+	}
+}
+
+func setOperationOf(rewrite *core.UsersetRewrite) *core.SetOperation {
+	switch operation := rewrite.RewriteOperation.(type) {
+	case *core.UsersetRewrite_Union:
+		return operation.Union
+	case *core.UsersetRewrite_Intersection:
+		return operation.Intersection
+	case *core.UsersetRewrite_Exclusion:
+		return operation.Exclusion
+	default:
+		return &core.SetOperation{}
+	}
+}
+
+func equalIgnoringPositions(a *core.SetOperation_Child, b *core.SetOperation_Child) bool {
+	return cmp.Equal(a, b, protocmp.Transform(), protocmp.IgnoreMessages(&core.SourcePosition{}))
+}
+
+// renamedDefinitions returns the object definitions with the renamed object definition, relation
+// or permission renamed, and every reference to it renamed. If the new name was added alongside
+// the existing one by withNewName, the existing one is removed.
+func (rename SchemaRename) renamedDefinitions(existingObjectDefs []*core.NamespaceDefinition) ([]*core.NamespaceDefinition, error) {
+	// Ensure that the new name, if it exists, was added by withNewName.
+	if _, err := rename.withNewName(existingObjectDefs); err != nil {
+		return nil, err
+	}
+
+	objectDefs := cloneDefinitions(existingObjectDefs)
+
+	definition := findDefinition(objectDefs, rename.DefinitionName)
+	if definition == nil {
+		return nil, ErrRenameTargetNotFound
+	}
+
+	if rename.RelationName == "" {
+		if findDefinition(objectDefs, rename.NewName) != nil {
+			objectDefs = slices.DeleteFunc(objectDefs, func(objectDef *core.NamespaceDefinition) bool {
+				return objectDef == definition
+			})
+		} else {
+			definition.Name = rename.NewName
+		}
+	} else {
+		relation, ok := findRelation(definition, rename.RelationName)
+		if !ok {
+			return nil, ErrRenameTargetNotFound
+		}
+
+		if _, ok := findRelation(definition, rename.NewName); ok {
+			definition.Relation = slices.DeleteFunc(definition.Relation, func(r *core.Relation) bool {
+				return r == relation
+			})
+		} else {
+			relation.Name = rename.NewName
+		}
+
+		if err := rename.renameRelationReferences(objectDefs); err != nil {
+			return nil, err
+		}
+		removeNewNameReferences(objectDefs)
+	}
+
+	for _, objectDef := range objectDefs {
+		for _, relation := range objectDef.Relation {
+			if relation.TypeInformation == nil {
+				continue
+			}
+
+			renamedAllowed := make([]*core.AllowedRelation, 0, len(relation.TypeInformation.AllowedDirectRelations))
+			for _, allowedType := range relation.TypeInformation.AllowedDirectRelations {
+				if renamed, ok := rename.renamedAllowedType(allowedType); ok {
+					allowedType = renamed
+				}
+				if !hasAllowedType(renamedAllowed, allowedType) {
+					renamedAllowed = append(renamedAllowed, allowedType)
+				}
+			}
+			relation.TypeInformation.AllowedDirectRelations = renamedAllowed
+		}
+	}
+
+	return objectDefs, nil
+}
+
+// renameRelationReferences renames the references to the renamed relation or permission in the
+// permissions of the object definitions.
+func (rename SchemaRename) renameRelationReferences(objectDefs []*core.NamespaceDefinition) error {
+	for _, objectDef := range objectDefs {
+		for _, relation := range objectDef.Relation {
+			_, err := graph.WalkRewrite(relation.UsersetRewrite, func(childOneof *core.SetOperation_Child) (interface{}, error) {
+				var tupleset, computed string
+				var renameTupleset func()
+				var renameComputed func()
+
+				switch child := childOneof.ChildType.(type) {
+				case *core.SetOperation_Child_ComputedUserset:
+					if objectDef.Name == rename.DefinitionName && child.ComputedUserset.Relation == rename.RelationName {
+						child.ComputedUserset.Relation = rename.NewName
+					}
+					return nil, nil
+
+				case *core.SetOperation_Child_TupleToUserset:
+					tupleset, computed = child.TupleToUserset.Tupleset.Relation, child.TupleToUserset.ComputedUserset.Relation
+					renameTupleset = func() { child.TupleToUserset.Tupleset.Relation = rename.NewName }
+					renameComputed = func() { child.TupleToUserset.ComputedUserset.Relation = rename.NewName }
+
+				case *core.SetOperation_Child_FunctionedTupleToUserset:
+					tupleset, computed = child.FunctionedTupleToUserset.Tupleset.Relation, child.FunctionedTupleToUserset.ComputedUserset.Relation
+					renameTupleset = func() { child.FunctionedTupleToUserset.Tupleset.Relation = rename.NewName }
+					renameComputed = func() { child.FunctionedTupleToUserset.ComputedUserset.Relation = rename.NewName }
+
+				default:
+					return nil, nil
+				}
+
+				// The relation walked by an arrow is renamed if it is walked on the renamed
+				// relation's object definition. It cannot be renamed if it is also walked on
+				// other object definitions with a relation of the same name.
+				if computed == rename.RelationName {
+					walksRenamed, walksOther := rename.arrowTargets(objectDefs, objectDef, tupleset)
+					if walksRenamed && walksOther {
+						return nil, fmt.Errorf("cannot rename %s, as `%s->%s` in object definition `%s` also walks `%s` on other object definitions", rename, tupleset, computed, objectDef.Name, computed)
+					}
+					if walksRenamed {
+						renameComputed()
+					}
+				}
+
+				if objectDef.Name == rename.DefinitionName && tupleset == rename.RelationName {
+					renameTupleset()
+				}
+				return nil, nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// arrowTargets returns whether an arrow over the tupleset relation of the object definition walks
+// the renamed relation, and whether it walks a relation of the existing or new name on other
+// object definitions.
+func (rename SchemaRename) arrowTargets(objectDefs []*core.NamespaceDefinition, objectDef *core.NamespaceDefinition, tupleset string) (walksRenamed bool, walksOther bool) {
+	tuplesetRelation, ok := findRelation(objectDef, tupleset)
+	if !ok {
+		// The tupleset was renamed already.
+		tuplesetRelation, ok = findRelation(objectDef, rename.NewName)
+		if !ok {
+			return false, false
+		}
+	}
+
+	for _, allowedType := range tuplesetRelation.GetTypeInformation().GetAllowedDirectRelations() {
+		if allowedType.Namespace == rename.DefinitionName {
+			walksRenamed = true
+			continue
+		}
+
+		otherDef := findDefinition(objectDefs, allowedType.Namespace)
+		_, hasRelation := findRelation(otherDef, rename.RelationName)
+		_, hasNewName := findRelation(otherDef, rename.NewName)
+		if hasRelation || hasNewName {
+			walksOther = true
+		}
+	}
+	return walksRenamed, walksOther
+}
+
+// renamedAllowedType returns the allowed type with the new name, if it references the renamed
+// object definition, relation or permission.
+func (rename SchemaRename) renamedAllowedType(allowedType *core.AllowedRelation) (*core.AllowedRelation, bool) {
+	if allowedType.Namespace != rename.DefinitionName {
+		return nil, false
+	}
+
+	if rename.RelationName == "" {
+		renamed := proto.Clone(allowedType).(*core.AllowedRelation)
+		renamed.Namespace = rename.NewName
+		return renamed, true
+	}
+
+	if allowedType.GetRelation() != rename.RelationName {
+		return nil, false
+	}
+
+	renamed := proto.Clone(allowedType).(*core.AllowedRelation)
+	renamed.RelationOrWildcard = &core.AllowedRelation_Relation{Relation: rename.NewName}
+	return renamed, true
+}
+
+func hasAllowedType(allowedTypes []*core.AllowedRelation, allowedType *core.AllowedRelation) bool {
+	source := typesystem.SourceForAllowedRelation(allowedType)
+	return slices.ContainsFunc(allowedTypes, func(existing *core.AllowedRelation) bool {
+		return typesystem.SourceForAllowedRelation(existing) == source
+	})
+}
+
+func cloneDefinitions(objectDefs []*core.NamespaceDefinition) []*core.NamespaceDefinition {
+	cloned := make([]*core.NamespaceDefinition, 0, len(objectDefs))
+	for _, objectDef := range objectDefs {
+		cloned = append(cloned, proto.Clone(objectDef).(*core.NamespaceDefinition))
+	}
+	return cloned
+}
+
+func findDefinition(objectDefs []*core.NamespaceDefinition, name string) *core.NamespaceDefinition {
+	for _, objectDef := range objectDefs {
+		if objectDef.Name == name {
+			return objectDef
+		}
+	}
+	return nil
+}
@@ -0,0 +1,324 @@
+package shared
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	"github.com/authzed/spicedb/internal/datastore/dsfortesting"
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/schemadsl/compiler"
+	"github.com/authzed/spicedb/pkg/schemadsl/generator"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+func TestRenameInDatastore(t *testing.T) {
+	startingSchema := `
+		definition user {}
+
+		definition group {
+			relation member: user | group#member
+		}
+
+		definition folder {
+			relation viewer: user
+		}
+
+		definition document {
+			relation parent: folder
+			relation viewer: user | group#member
+			permission view = viewer + parent->viewer
+		}`
+
+	relationships := []tuple.Relationship{
+		tuple.MustParse("group:admins#member@user:sarah"),
+		tuple.MustParse("group:admins#member@group:owners#member"),
+		tuple.MustParse("document:firstdoc#viewer@group:admins#member"),
+		tuple.MustParse("document:firstdoc#viewer@user:tom"),
+		tuple.MustParse("document:firstdoc#parent@folder:somefolder"),
+		tuple.MustParse("folder:somefolder#viewer@user:tom"),
+	}
+
+	tcs := []struct {
+		name                  string
+		rename                SchemaRename
+		expectedRenamed       uint64
+		expectedSchema        string
+		expectedRelationships []string
+		expectedError         string
+	}{
+		{
+			name:            "definition",
+			rename:          SchemaRename{DefinitionName: "group", NewName: "team"},
+			expectedRenamed: 3,
+			expectedSchema: `definition document {
+	relation parent: folder
+	relation viewer: user | team#member
+	permission view = viewer + parent->viewer
+}
+
+definition folder {
+	relation viewer: user
+}
+
+definition team {
+	relation member: user | team#member
+}
+
+definition user {}`,
+			expectedRelationships: []string{
+				"document:firstdoc#parent@folder:somefolder",
+				"document:firstdoc#viewer@team:admins#member",
+				"document:firstdoc#viewer@user:tom",
+				"folder:somefolder#viewer@user:tom",
+				"team:admins#member@team:owners#member",
+				"team:admins#member@user:sarah",
+			},
+		},
+		{
+			name:            "relation referenced by subjects",
+			rename:          SchemaRename{DefinitionName: "group", RelationName: "member", NewName: "participant"},
+			expectedRenamed: 3,
+			expectedSchema: `definition document {
+	relation parent: folder
+	relation viewer: user | group#participant
+	permission view = viewer + parent->viewer
+}
+
+definition folder {
+	relation viewer: user
+}
+
+definition group {
+	relation participant: user | group#participant
+}
+
+definition user {}`,
+			expectedRelationships: []string{
+				"document:firstdoc#parent@folder:somefolder",
+				"document:firstdoc#viewer@group:admins#participant",
+				"document:firstdoc#viewer@user:tom",
+				"folder:somefolder#viewer@user:tom",
+				"group:admins#participant@group:owners#participant",
+				"group:admins#participant@user:sarah",
+			},
+		},
+		{
+			name:            "relation walked by arrows",
+			rename:          SchemaRename{DefinitionName: "folder", RelationName: "viewer", NewName: "reader"},
+			expectedRenamed: 1,
+			expectedSchema: `definition document {
+	relation parent: folder
+	relation viewer: user | group#member
+	permission view = viewer + parent->reader
+}
+
+definition folder {
+	relation reader: user
+}
+
+definition group {
+	relation member: user | group#member
+}
+
+definition user {}`,
+			expectedRelationships: []string{
+				"document:firstdoc#parent@folder:somefolder",
+				"document:firstdoc#viewer@group:admins#member",
+				"document:firstdoc#viewer@user:tom",
+				"folder:somefolder#reader@user:tom",
+				"group:admins#member@group:owners#member",
+				"group:admins#member@user:sarah",
+			},
+		},
+		{
+			name:            "relation used as tupleset",
+			rename:          SchemaRename{DefinitionName: "document", RelationName: "parent", NewName: "folder"},
+			expectedRenamed: 1,
+			expectedSchema: `definition document {
+	relation folder: folder
+	relation viewer: user | group#member
+	permission view = viewer + folder->viewer
+}
+
+definition folder {
+	relation viewer: user
+}
+
+definition group {
+	relation member: user | group#member
+}
+
+definition user {}`,
+			expectedRelationships: []string{
+				"document:firstdoc#folder@folder:somefolder",
+				"document:firstdoc#viewer@group:admins#member",
+				"document:firstdoc#viewer@user:tom",
+				"folder:somefolder#viewer@user:tom",
+				"group:admins#member@group:owners#member",
+				"group:admins#member@user:sarah",
+			},
+		},
+		{
+			name:          "unknown definition",
+			rename:        SchemaRename{DefinitionName: "unknown", NewName: "other"},
+			expectedError: ErrRenameTargetNotFound.Error(),
+		},
+		{
+			name:          "existing definition",
+			rename:        SchemaRename{DefinitionName: "group", NewName: "folder"},
+			expectedError: "the new name already exists",
+		},
+	}
+
+	for _, tc := range tcs {
+		for _, batchSize := range []uint64{0, 1, 100} {
+			t.Run(fmt.Sprintf("%s/batch size %d", tc.name, batchSize), func(t *testing.T) {
+				rawDS, err := dsfortesting.NewMemDBDatastoreForTesting(0, 0, memdb.DisableGC)
+				require.NoError(t, err)
+
+				ds, _ := testfixtures.DatastoreFromSchemaAndTestRelationships(rawDS, startingSchema, relationships, require.New(t))
+
+				result, err := RenameInDatastore(context.Background(), ds, tc.rename, batchSize)
+				if tc.expectedError != "" {
+					require.ErrorContains(t, err, tc.expectedError)
+					return
+				}
+				require.NoError(t, err)
+				require.Equal(t, tc.expectedRenamed, result.RelationshipsRenamed)
+				require.False(t, result.AlreadyApplied)
+
+				require.Equal(t, tc.expectedSchema, readSchemaForTesting(t, ds))
+				require.Equal(t, tc.expectedRelationships, readRelationshipsForTesting(t, ds))
+
+				// Applying the rename again changes nothing.
+				result, err = RenameInDatastore(context.Background(), ds, tc.rename, batchSize)
+				require.NoError(t, err)
+				require.True(t, result.AlreadyApplied)
+			})
+		}
+	}
+}
+
+func TestRenameInDatastoreResumes(t *testing.T) {
+	rawDS, err := dsfortesting.NewMemDBDatastoreForTesting(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+
+	ds, _ := testfixtures.DatastoreFromSchemaAndTestRelationships(rawDS, `
+		definition user {}
+
+		definition document {
+			relation viewer: user
+			permission view = viewer
+		}`, []tuple.Relationship{
+		tuple.MustParse("document:firstdoc#viewer@user:tom"),
+		tuple.MustParse("document:seconddoc#viewer@user:tom"),
+	}, require.New(t))
+
+	// Simulate an interrupted rename, which added the new name and rewrote a relationship.
+	rename := SchemaRename{DefinitionName: "document", RelationName: "viewer", NewName: "reader"}
+	_, err = ds.ReadWriteTx(context.Background(), func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		existingObjectDefs, existingCaveats, err := listDefinitions(ctx, rwt)
+		if err != nil {
+			return err
+		}
+
+		intermediate, err := rename.withNewName(existingObjectDefs)
+		if err != nil {
+			return err
+		}
+
+		if err := applyDefinitions(ctx, rwt, intermediate, existingCaveats); err != nil {
+			return err
+		}
+
+		return rwt.WriteRelationships(ctx, []tuple.RelationshipUpdate{
+			tuple.Delete(tuple.MustParse("document:firstdoc#viewer@user:tom")),
+			tuple.Touch(tuple.MustParse("document:firstdoc#reader@user:tom")),
+		})
+	})
+	require.NoError(t, err)
+
+	// Until the relationships are all rewritten, the permission references both names.
+	intermediateSchema := `definition document {
+	relation viewer: user
+	relation reader: user
+	permission view = viewer + reader
+}
+
+definition user {}`
+	require.Equal(t, intermediateSchema, readSchemaForTesting(t, ds))
+
+	// Adding the new name again, as a resumed rename does, changes nothing.
+	headRevision, err := ds.HeadRevision(context.Background())
+	require.NoError(t, err)
+
+	intermediateObjectDefs, _, err := listDefinitions(context.Background(), ds.SnapshotReader(headRevision))
+	require.NoError(t, err)
+
+	resumedObjectDefs, err := rename.withNewName(intermediateObjectDefs)
+	require.NoError(t, err)
+	for index, objectDef := range resumedObjectDefs {
+		require.Empty(t, cmp.Diff(intermediateObjectDefs[index], objectDef, protocmp.Transform()))
+	}
+
+	result, err := RenameInDatastore(context.Background(), ds, rename, 10)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), result.RelationshipsRenamed)
+
+	require.Equal(t, `definition document {
+	relation reader: user
+	permission view = reader
+}
+
+definition user {}`, readSchemaForTesting(t, ds))
+	require.Equal(t, []string{
+		"document:firstdoc#reader@user:tom",
+		"document:seconddoc#reader@user:tom",
+	}, readRelationshipsForTesting(t, ds))
+}
+
+func readSchemaForTesting(t *testing.T, ds datastore.Datastore) string {
+	headRevision, err := ds.HeadRevision(context.Background())
+	require.NoError(t, err)
+
+	objectDefs, caveats, err := listDefinitions(context.Background(), ds.SnapshotReader(headRevision))
+	require.NoError(t, err)
+
+	definitions := make([]compiler.SchemaDefinition, 0, len(objectDefs)+len(caveats))
+	for _, objectDef := range objectDefs {
+		definitions = append(definitions, objectDef)
+	}
+
+	schemaText, _, err := generator.GenerateSchema(definitions)
+	require.NoError(t, err)
+	return strings.TrimSpace(schemaText)
+}
+
+func readRelationshipsForTesting(t *testing.T, ds datastore.Datastore) []string {
+	headRevision, err := ds.HeadRevision(context.Background())
+	require.NoError(t, err)
+
+	var relationships []string
+	for _, resourceType := range []string{"document", "folder", "group", "team"} {
+		it, err := ds.SnapshotReader(headRevision).QueryRelationships(context.Background(), datastore.RelationshipsFilter{OptionalResourceType: resourceType})
+		require.NoError(t, err)
+
+		rels, err := datastore.IteratorToSlice(it)
+		require.NoError(t, err)
+		for _, rel := range rels {
+			relationships = append(relationships, tuple.MustString(rel))
+		}
+	}
+
+	slices.Sort(relationships)
+	return relationships
+}
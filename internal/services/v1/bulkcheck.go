@@ -36,6 +36,7 @@ type bulkChecker struct {
 
 	dispatch          dispatch.Dispatcher
 	dispatchChunkSize uint16
+	prefilterCaveats  bool
 }
 
 const maxBulkCheckCount = 10000
@@ -73,6 +74,7 @@ func (bc *bulkChecker) checkBulkPermissions(ctx context.Context, req *v1.CheckBu
 		maxCaveatContextSize: bc.maxCaveatContextSize,
 		maximumAPIDepth:      bc.maxAPIDepth,
 		withTracing:          req.WithTracing,
+		prefilterCaveats:     bc.prefilterCaveats,
 	}, req.Items)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,217 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	dispatch "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+	"github.com/authzed/spicedb/pkg/requestmeta"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// CheckReasonCode is the machine-readable reason for the result of a CheckPermission request.
+type CheckReasonCode string
+
+const (
+	// CheckReasonDirectRelationship is the reason for a permission granted by a relationship of
+	// the checked resource with the subject itself.
+	CheckReasonDirectRelationship CheckReasonCode = "DIRECT_RELATIONSHIP"
+
+	// CheckReasonViaRelation is the reason for a permission granted by a relationship of another
+	// object, reached from the checked resource, such as the membership of a group.
+	CheckReasonViaRelation CheckReasonCode = "VIA_RELATION"
+
+	// CheckReasonWildcard is the reason for a permission granted by a relationship with a wildcard
+	// subject of the type of the subject.
+	CheckReasonWildcard CheckReasonCode = "WILDCARD"
+
+	// CheckReasonCaveatMissingContext is the reason for a conditional permission, whose caveats
+	// could not be evaluated as fields were missing from their context.
+	CheckReasonCaveatMissingContext CheckReasonCode = "CAVEAT_MISSING_CONTEXT"
+
+	// CheckReasonNoRelationship is the reason for a permission not granted, as no relationship
+	// leads from the checked resource to the subject.
+	CheckReasonNoRelationship CheckReasonCode = "NO_RELATIONSHIP"
+)
+
+// CheckReason is the reason for the result of a CheckPermission request, returned as JSON in the
+// requestmeta.CheckReasonResponseHeaderKey response header when requested.
+type CheckReason struct {
+	Code CheckReasonCode `json:"code"`
+
+	// Relation is the relation, as `namespace#relation`, of the relationship granting the
+	// permission, when it was granted.
+	Relation string `json:"relation,omitempty"`
+
+	// Path is the chain of permissions and relations, as `namespace:id#relation`, followed from
+	// the checked resource to the relationship granting the permission, when it was granted.
+	Path []string `json:"path,omitempty"`
+
+	// MissingContextFields are the caveat context fields missing to evaluate the caveats of a
+	// conditional permission, and Caveats the names of those caveats.
+	MissingContextFields []string `json:"missingContextFields,omitempty"`
+	Caveats              []string `json:"caveats,omitempty"`
+}
+
+func checkReasonRequested(ctx context.Context) bool {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return false
+	}
+
+	_, requested := md[string(requestmeta.RequestCheckReason)]
+	return requested
+}
+
+// setCheckReason computes the reason for the result of a check from its debug trace, and returns
+// it in the response headers.
+func setCheckReason(ctx context.Context, reader datastore.Reader, subject tuple.ObjectAndRelation, result *dispatch.ResourceCheckResult, debugInfo *dispatch.DebugInformation) error {
+	reason, err := computeCheckReason(ctx, reader, subject, result, debugInfo)
+	if err != nil {
+		return err
+	}
+
+	encoded, err := json.Marshal(reason)
+	if err != nil {
+		return err
+	}
+
+	return grpc.SetHeader(ctx, metadata.Pairs(requestmeta.CheckReasonResponseHeaderKey, string(encoded)))
+}
+
+func computeCheckReason(ctx context.Context, reader datastore.Reader, subject tuple.ObjectAndRelation, result *dispatch.ResourceCheckResult, debugInfo *dispatch.DebugInformation) (CheckReason, error) {
+	switch result.Membership {
+	case dispatch.ResourceCheckResult_CAVEATED_MEMBER:
+		return CheckReason{
+			Code:                 CheckReasonCaveatMissingContext,
+			MissingContextFields: result.MissingExprFields,
+			Caveats:              caveatNames(result.Expression),
+		}, nil
+
+	case dispatch.ResourceCheckResult_MEMBER:
+		break
+
+	default:
+		return CheckReason{Code: CheckReasonNoRelationship}, nil
+	}
+
+	path := grantingPath(debugInfo.GetCheck())
+	if len(path) == 0 {
+		return CheckReason{Code: CheckReasonViaRelation}, nil
+	}
+
+	reason := CheckReason{Code: CheckReasonViaRelation, Path: make([]string, 0, len(path))}
+	for _, step := range path {
+		reason.Path = append(reason.Path, tuple.StringONR(step))
+	}
+
+	// The last step of the path is the relation of the relationship granting the permission, unless
+	// its result was cached, in which case the relationship is not known.
+	granting := path[len(path)-1]
+	reason.Relation = tuple.JoinRelRef(granting.ObjectType, granting.Relation)
+
+	it, err := reader.QueryRelationships(ctx, datastore.RelationshipsFilter{
+		OptionalResourceType:     granting.ObjectType,
+		OptionalResourceIds:      []string{granting.ObjectID},
+		OptionalResourceRelation: granting.Relation,
+		OptionalSubjectsSelectors: []datastore.SubjectsSelector{
+			{
+				OptionalSubjectType: subject.ObjectType,
+				OptionalSubjectIds:  []string{subject.ObjectID, tuple.PublicWildcard},
+				RelationFilter:      subjectRelationFilter(subject.Relation),
+			},
+		},
+	})
+	if err != nil {
+		return CheckReason{}, err
+	}
+
+	foundSubject, foundWildcard := false, false
+	for rel, err := range it {
+		if err != nil {
+			return CheckReason{}, err
+		}
+
+		if rel.Subject.ObjectID == tuple.PublicWildcard {
+			foundWildcard = true
+		} else {
+			foundSubject = true
+		}
+	}
+
+	switch {
+	case foundSubject && granting.ObjectType == path[0].ObjectType && granting.ObjectID == path[0].ObjectID:
+		reason.Code = CheckReasonDirectRelationship
+	case !foundSubject && foundWildcard:
+		reason.Code = CheckReasonWildcard
+	}
+
+	return reason, nil
+}
+
+// grantingPath returns the resources and relations of the traces leading from the root of the trace
+// to the deepest trace granting the permission.
+func grantingPath(trace *dispatch.CheckDebugTrace) []tuple.ObjectAndRelation {
+	if trace == nil || trace.Request == nil {
+		return nil
+	}
+
+	resourceID, ok := resultResourceID(trace, dispatch.ResourceCheckResult_MEMBER)
+	if !ok {
+		return nil
+	}
+
+	step := tuple.ONR(trace.Request.ResourceRelation.Namespace, resourceID, trace.Request.ResourceRelation.Relation)
+	for _, subProblem := range trace.SubProblems {
+		if subPath := grantingPath(subProblem); len(subPath) > 0 {
+			return append([]tuple.ObjectAndRelation{step}, subPath...)
+		}
+	}
+
+	return []tuple.ObjectAndRelation{step}
+}
+
+// resultResourceID returns the first resource ID, in order, of the trace with a result of the
+// given membership.
+func resultResourceID(trace *dispatch.CheckDebugTrace, membership dispatch.ResourceCheckResult_Membership) (string, bool) {
+	resourceIDs := slices.Clone(trace.Request.ResourceIds)
+	slices.Sort(resourceIDs)
+	for _, resourceID := range resourceIDs {
+		if result, ok := trace.Results[resourceID]; ok && result.Membership == membership {
+			return resourceID, true
+		}
+	}
+	return "", false
+}
+
+func subjectRelationFilter(relation string) datastore.SubjectRelationFilter {
+	if relation == tuple.Ellipsis {
+		return datastore.SubjectRelationFilter{}.WithEllipsisRelation()
+	}
+	return datastore.SubjectRelationFilter{}.WithNonEllipsisRelation(relation)
+}
+
+// caveatNames returns the sorted names of the caveats found in the expression.
+func caveatNames(expr *core.CaveatExpression) []string {
+	var names []string
+	var collect func(expr *core.CaveatExpression)
+	collect = func(expr *core.CaveatExpression) {
+		if caveat := expr.GetCaveat(); caveat != nil {
+			names = append(names, caveat.CaveatName)
+			return
+		}
+		for _, child := range expr.GetOperation().GetChildren() {
+			collect(child)
+		}
+	}
+	collect(expr)
+
+	slices.Sort(names)
+	return slices.Compact(names)
+}
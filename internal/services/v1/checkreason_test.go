@@ -0,0 +1,146 @@
+package v1_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	authzedrequestmeta "github.com/authzed/authzed-go/pkg/requestmeta"
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	v1svc "github.com/authzed/spicedb/internal/services/v1"
+	tf "github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/internal/testserver"
+	"github.com/authzed/spicedb/pkg/requestmeta"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+func TestCheckPermissionReason(t *testing.T) {
+	tcs := []struct {
+		name           string
+		check          string
+		expectedReason v1svc.CheckReason
+	}{
+		{
+			name:  "direct relationship",
+			check: "document:masterplan#view@user:eng_lead",
+			expectedReason: v1svc.CheckReason{
+				Code:     v1svc.CheckReasonDirectRelationship,
+				Relation: "document#viewer",
+				Path:     []string{"document:masterplan#view", "document:masterplan#viewer"},
+			},
+		},
+		{
+			name:  "via relation",
+			check: "document:companyplan#view@user:auditor",
+			expectedReason: v1svc.CheckReason{
+				Code:     v1svc.CheckReasonViaRelation,
+				Relation: "folder#viewer",
+				Path: []string{
+					"document:companyplan#view",
+					"folder:company#view",
+					"folder:company#viewer",
+					"folder:auditors#viewer",
+				},
+			},
+		},
+		{
+			name:           "no relationship",
+			check:          "document:masterplan#view@user:villain",
+			expectedReason: v1svc.CheckReason{Code: v1svc.CheckReasonNoRelationship},
+		},
+		{
+			name:  "caveat missing context",
+			check: "document:caveatedplan#caveated_viewer@user:caveatedguy",
+			expectedReason: v1svc.CheckReason{
+				Code:                 v1svc.CheckReasonCaveatMissingContext,
+				MissingContextFields: []string{"secret"},
+				Caveats:              []string{"test"},
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			conn, cleanup, _, _ := testserver.NewTestServer(require.New(t), 0, memdb.DisableGC, true, tf.StandardDatastoreWithCaveatedData)
+			t.Cleanup(cleanup)
+
+			resp, reason := checkWithReason(t, v1.NewPermissionsServiceClient(conn), tc.check)
+			require.Nil(t, resp.DebugTrace)
+			require.Equal(t, tc.expectedReason, reason)
+		})
+	}
+}
+
+func TestCheckPermissionReasonWildcard(t *testing.T) {
+	conn, cleanup, _, _ := testserver.NewTestServer(require.New(t), 0, memdb.DisableGC, true, tf.EmptyDatastore)
+	t.Cleanup(cleanup)
+
+	_, err := v1.NewSchemaServiceClient(conn).WriteSchema(context.Background(), &v1.WriteSchemaRequest{
+		Schema: `definition user {}
+
+		definition document {
+			relation viewer: user | user:*
+			permission view = viewer
+		}`,
+	})
+	require.NoError(t, err)
+
+	client := v1.NewPermissionsServiceClient(conn)
+	_, err = client.WriteRelationships(context.Background(), &v1.WriteRelationshipsRequest{
+		Updates: []*v1.RelationshipUpdate{
+			tuple.MustUpdateToV1RelationshipUpdate(tuple.Touch(tuple.MustParse("document:public#viewer@user:*"))),
+			tuple.MustUpdateToV1RelationshipUpdate(tuple.Touch(tuple.MustParse("document:public#viewer@user:tom"))),
+		},
+	})
+	require.NoError(t, err)
+
+	_, reason := checkWithReason(t, client, "document:public#view@user:sarah")
+	require.Equal(t, v1svc.CheckReasonWildcard, reason.Code)
+	require.Equal(t, "document#viewer", reason.Relation)
+
+	// A relationship with the subject itself takes precedence over the wildcard.
+	_, reason = checkWithReason(t, client, "document:public#view@user:tom")
+	require.Equal(t, v1svc.CheckReasonDirectRelationship, reason.Code)
+}
+
+func TestCheckPermissionReasonNotRequested(t *testing.T) {
+	conn, cleanup, _, _ := testserver.NewTestServer(require.New(t), 0, memdb.DisableGC, true, tf.StandardDatastoreWithData)
+	t.Cleanup(cleanup)
+
+	rel := tuple.MustParse("document:masterplan#view@user:eng_lead")
+	var header metadata.MD
+	_, err := v1.NewPermissionsServiceClient(conn).CheckPermission(context.Background(), &v1.CheckPermissionRequest{
+		Consistency: &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}},
+		Resource:    tuple.ToV1Relationship(rel).Resource,
+		Permission:  rel.Resource.Relation,
+		Subject:     tuple.ToV1Relationship(rel).Subject,
+	}, grpc.Header(&header))
+	require.NoError(t, err)
+	require.Empty(t, header.Get(requestmeta.CheckReasonResponseHeaderKey))
+}
+
+func checkWithReason(t *testing.T, client v1.PermissionsServiceClient, check string) (*v1.CheckPermissionResponse, v1svc.CheckReason) {
+	rel := tuple.MustParse(check)
+
+	ctx := authzedrequestmeta.AddRequestHeaders(context.Background(), requestmeta.RequestCheckReason)
+	var header metadata.MD
+	resp, err := client.CheckPermission(ctx, &v1.CheckPermissionRequest{
+		Consistency: &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}},
+		Resource:    tuple.ToV1Relationship(rel).Resource,
+		Permission:  rel.Resource.Relation,
+		Subject:     tuple.ToV1Relationship(rel).Subject,
+	}, grpc.Header(&header))
+	require.NoError(t, err)
+
+	encoded := header.Get(requestmeta.CheckReasonResponseHeaderKey)
+	require.Len(t, encoded, 1)
+
+	var reason v1svc.CheckReason
+	require.NoError(t, json.Unmarshal([]byte(encoded[0]), &reason))
+	return resp, reason
+}
@@ -0,0 +1,157 @@
+package v1
+
+import (
+	"context"
+	"errors"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc/metadata"
+
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/internal/middleware/usagemetrics"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/options"
+	"github.com/authzed/spicedb/pkg/genutil"
+	dispatchv1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+	"github.com/authzed/spicedb/pkg/requestmeta"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+var chunkedWriteCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "spicedb",
+	Subsystem: "v1",
+	Name:      "chunked_write_relationships_total",
+	Help:      "The number of WriteRelationships calls applied in multiple transactions, by outcome",
+}, []string{"outcome"})
+
+func chunkedWriteRequested(ctx context.Context) bool {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return false
+	}
+
+	_, requested := md[string(requestmeta.RequestChunkedWrite)]
+	return requested
+}
+
+// writeRelationshipsInChunks applies the updates in transactions of at most MaxUpdatesPerWrite
+// updates each. The preconditions are checked and all the updates validated in the first
+// transaction. Each transaction records the changes it makes in a journal persisted in the
+// datastore, which is rolled back should a later transaction fail, or by recovery should this
+// node fail before the write completes, so that either all or none of the updates are applied.
+// The idempotency key of the claim, if not nil, is claimed as pending in the first transaction and
+// completed in the last one, which deletes the journal.
+func (ps *permissionServer) writeRelationshipsInChunks(
+	ctx context.Context,
+	ds datastore.Datastore,
+	preconditions []*v1.Precondition,
+	updates []tuple.RelationshipUpdate,
+	expectedVersions map[int]string,
+	validationMode writeValidationMode,
+	claim *idempotencyClaim,
+	opts ...options.RWTOptionsOption,
+) (datastore.Revision, error) {
+	chunkSize := int(ps.config.MaxUpdatesPerWrite)
+	journal := newWriteJournal()
+	if claim != nil {
+		journal.idempotencyRecord = claim.recordKey()
+	}
+
+	var revision datastore.Revision
+	chunks := 0
+	for start := 0; start < len(updates); start += chunkSize {
+		end := min(start+chunkSize, len(updates))
+		chunk := updates[start:end]
+		seq := chunks
+
+		chunkRevision, err := ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+			if seq == 0 {
+				for _, precond := range preconditions {
+					if err := validatePrecondition(ctx, precond, rwt); err != nil {
+						return err
+					}
+				}
+
+				if err := ps.validateRelationshipUpdates(ctx, rwt, updates, validationMode); err != nil {
+					return ps.rewriteError(ctx, err)
+				}
+
+				if err := checkPreconditions(ctx, rwt, preconditions); err != nil {
+					return err
+				}
+
+				if err := claim.claim(ctx, rwt, end < len(updates)); err != nil {
+					return err
+				}
+			}
+
+			if err := checkExpectedRelationshipVersions(ctx, rwt, updates, expectedVersionsInRange(expectedVersions, start, end)); err != nil {
+				return err
+			}
+
+			if end == len(updates) {
+				if err := journal.release(ctx, rwt, seq); err != nil {
+					return err
+				}
+				if seq > 0 {
+					if err := claim.complete(ctx, rwt); err != nil {
+						return err
+					}
+				}
+			} else {
+				if err := journal.hold(ctx, rwt, seq); err != nil {
+					return err
+				}
+				if err := journal.record(ctx, rwt, seq, chunk); err != nil {
+					return err
+				}
+			}
+
+			return rwt.WriteRelationships(ctx, chunk)
+		}, opts...)
+		if err != nil {
+			// Nothing was applied, or the write is already being rolled back by recovery.
+			if seq == 0 || errors.Is(err, errWriteJournalReleased) {
+				return nil, err
+			}
+
+			// The rollback must complete even if the client has gone away.
+			if _, rollbackErr := journal.rollback(context.WithoutCancel(ctx), ds, true, opts...); rollbackErr != nil {
+				chunkedWriteCounter.WithLabelValues("rollback_failed").Inc()
+				log.Ctx(ctx).Error().Err(rollbackErr).Str("write", journal.id).Int("chunks_applied", seq).Msg("failed to roll back chunked write; it will be rolled back by recovery")
+				return nil, NewChunkedWriteRollbackErr(err, rollbackErr, seq)
+			}
+
+			chunkedWriteCounter.WithLabelValues("rolled_back").Inc()
+			return nil, err
+		}
+
+		chunks++
+		revision = chunkRevision
+	}
+
+	dispatchCount, err := genutil.EnsureUInt32(len(preconditions) + chunks)
+	if err != nil {
+		return nil, err
+	}
+
+	usagemetrics.SetInContext(ctx, &dispatchv1.ResponseMeta{
+		// One request per precondition and one request per transaction of the writes.
+		DispatchCount: dispatchCount,
+	})
+
+	chunkedWriteCounter.WithLabelValues("applied").Inc()
+	return revision, nil
+}
+
+func expectedVersionsInRange(expectedVersions map[int]string, start, end int) map[int]string {
+	inRange := make(map[int]string)
+	for index, version := range expectedVersions {
+		if index >= start && index < end {
+			inRange[index] = version
+		}
+	}
+	return inRange
+}
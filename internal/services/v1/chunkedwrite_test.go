@@ -0,0 +1,202 @@
+package v1_test
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	authzedrequestmeta "github.com/authzed/authzed-go/pkg/requestmeta"
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/authzed/grpcutil"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	tf "github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/internal/testserver"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/requestmeta"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+func newChunkedWriteTestServer(t *testing.T) (v1.PermissionsServiceClient, datastore.Datastore) {
+	config := testserver.DefaultTestServerConfig
+	config.MaxUpdatesPerWrite = 5
+	config.MaxUpdatesPerChunkedWrite = 50
+	config.WriteIdempotencyKeyTTL = time.Minute
+
+	conn, cleanup, ds, _ := testserver.NewTestServerWithConfig(require.New(t), 0, memdb.DisableGC, true, config, tf.StandardDatastoreWithCaveatedData)
+	t.Cleanup(cleanup)
+	return v1.NewPermissionsServiceClient(conn), ds
+}
+
+func TestChunkedWriteRelationships(t *testing.T) {
+	client, ds := newChunkedWriteTestServer(t)
+
+	var updates []*v1.RelationshipUpdate
+	for i := 0; i < 23; i++ {
+		updates = append(updates, tuple.MustUpdateToV1RelationshipUpdate(tuple.Create(tuple.MustParse(fmt.Sprintf("document:chunked%d#viewer@user:tom", i)))))
+	}
+
+	// Without the header, the updates must fit in a single transaction.
+	_, err := client.WriteRelationships(context.Background(), &v1.WriteRelationshipsRequest{Updates: updates})
+	grpcutil.RequireStatus(t, codes.InvalidArgument, err)
+
+	ctx := authzedrequestmeta.AddRequestHeaders(context.Background(), requestmeta.RequestChunkedWrite)
+	resp, err := client.WriteRelationships(ctx, &v1.WriteRelationshipsRequest{Updates: updates})
+	require.NoError(t, err)
+	require.NotNil(t, resp.WrittenAt)
+
+	for i := 0; i < 23; i++ {
+		_, found := readRelationship(t, ds, fmt.Sprintf("document:chunked%d#viewer@user:tom", i))
+		require.True(t, found, "missing relationship %d", i)
+	}
+	require.Empty(t, readWriteJournals(t, ds))
+
+	// The maximum of chunked writes still applies.
+	for i := 23; i < 51; i++ {
+		updates = append(updates, tuple.MustUpdateToV1RelationshipUpdate(tuple.Touch(tuple.MustParse(fmt.Sprintf("document:chunked%d#viewer@user:tom", i)))))
+	}
+	_, err = client.WriteRelationships(ctx, &v1.WriteRelationshipsRequest{Updates: updates})
+	grpcutil.RequireStatus(t, codes.InvalidArgument, err)
+}
+
+func TestChunkedWriteRelationshipsRollback(t *testing.T) {
+	client, ds := newChunkedWriteTestServer(t)
+
+	var updates []*v1.RelationshipUpdate
+	for i := 0; i < 12; i++ {
+		updates = append(updates, tuple.MustUpdateToV1RelationshipUpdate(tuple.Touch(tuple.MustParse(fmt.Sprintf("document:chunked%d#viewer@user:tom", i)))))
+	}
+	updates = append(updates,
+		tuple.MustUpdateToV1RelationshipUpdate(tuple.Delete(tuple.MustParse("document:masterplan#viewer@user:eng_lead"))),
+		tuple.MustUpdateToV1RelationshipUpdate(tuple.Touch(tuple.MustParse(`document:caveatedplan#caveated_viewer@user:caveatedguy[test:{"expectedSecret":"5678"}]`))),
+	)
+	for i := 12; i < 18; i++ {
+		updates = append(updates, tuple.MustUpdateToV1RelationshipUpdate(tuple.Touch(tuple.MustParse(fmt.Sprintf("document:chunked%d#viewer@user:tom", i)))))
+	}
+
+	// The last transaction fails, as the relationship to create already exists.
+	updates = append(updates, tuple.MustUpdateToV1RelationshipUpdate(tuple.Create(tuple.MustParse("folder:company#viewer@user:legal"))))
+
+	ctx := authzedrequestmeta.AddRequestHeaders(context.Background(), requestmeta.RequestChunkedWrite)
+	_, err := client.WriteRelationships(ctx, &v1.WriteRelationshipsRequest{Updates: updates})
+	grpcutil.RequireStatus(t, codes.AlreadyExists, err)
+
+	// All the transactions applied were rolled back.
+	for i := 0; i < 18; i++ {
+		_, found := readRelationship(t, ds, fmt.Sprintf("document:chunked%d#viewer@user:tom", i))
+		require.False(t, found, "relationship %d was not rolled back", i)
+	}
+
+	_, found := readRelationship(t, ds, "document:masterplan#viewer@user:eng_lead")
+	require.True(t, found)
+
+	caveated, found := readRelationship(t, ds, "document:caveatedplan#caveated_viewer@user:caveatedguy")
+	require.True(t, found)
+	require.Equal(t, "1234", caveated.OptionalCaveat.Context.AsMap()["expectedSecret"])
+	require.Empty(t, readWriteJournals(t, ds))
+}
+
+func TestChunkedWriteRelationshipsIdempotencyKey(t *testing.T) {
+	client, ds := newChunkedWriteTestServer(t)
+
+	var updates []*v1.RelationshipUpdate
+	for i := 0; i < 12; i++ {
+		updates = append(updates, tuple.MustUpdateToV1RelationshipUpdate(tuple.Touch(tuple.MustParse(fmt.Sprintf("document:chunked%d#viewer@user:tom", i)))))
+	}
+	failing := append(slices.Clone(updates), tuple.MustUpdateToV1RelationshipUpdate(tuple.Create(tuple.MustParse("folder:company#viewer@user:legal"))))
+
+	ctx := authzedrequestmeta.AddRequestHeaders(context.Background(), requestmeta.RequestChunkedWrite)
+	ctx = authzedrequestmeta.SetRequestHeaders(ctx, map[authzedrequestmeta.RequestMetadataHeaderKey]string{
+		requestmeta.RequestIdempotencyKey: "chunkedkey",
+	})
+	_, err := client.WriteRelationships(ctx, &v1.WriteRelationshipsRequest{Updates: failing})
+	grpcutil.RequireStatus(t, codes.AlreadyExists, err)
+
+	// The claim of the key is rolled back with the write.
+	require.Empty(t, readIdempotencyRecords(t, ds))
+
+	resp, err := client.WriteRelationships(ctx, &v1.WriteRelationshipsRequest{Updates: updates})
+	require.NoError(t, err)
+	require.Len(t, readIdempotencyRecords(t, ds), 1)
+
+	retried, err := client.WriteRelationships(ctx, &v1.WriteRelationshipsRequest{Updates: updates})
+	require.NoError(t, err)
+	require.Equal(t, resp.WrittenAt.Token, retried.WrittenAt.Token)
+
+	_, err = client.WriteRelationships(ctx, &v1.WriteRelationshipsRequest{Updates: failing})
+	grpcutil.RequireStatus(t, codes.InvalidArgument, err)
+}
+
+func TestChunkedWriteRelationshipsPreconditions(t *testing.T) {
+	client, ds := newChunkedWriteTestServer(t)
+
+	var updates []*v1.RelationshipUpdate
+	for i := 0; i < 8; i++ {
+		updates = append(updates, tuple.MustUpdateToV1RelationshipUpdate(tuple.Touch(tuple.MustParse(fmt.Sprintf("document:chunked%d#viewer@user:tom", i)))))
+	}
+
+	ctx := authzedrequestmeta.AddRequestHeaders(context.Background(), requestmeta.RequestChunkedWrite)
+	_, err := client.WriteRelationships(ctx, &v1.WriteRelationshipsRequest{
+		Updates: updates,
+		OptionalPreconditions: []*v1.Precondition{
+			{
+				Operation: v1.Precondition_OPERATION_MUST_MATCH,
+				Filter: &v1.RelationshipFilter{
+					ResourceType:       "document",
+					OptionalResourceId: "unknown",
+				},
+			},
+		},
+	})
+	grpcutil.RequireStatus(t, codes.FailedPrecondition, err)
+
+	_, found := readRelationship(t, ds, "document:chunked0#viewer@user:tom")
+	require.False(t, found)
+}
+
+func readRelationship(t *testing.T, ds datastore.Datastore, relString string) (tuple.Relationship, bool) {
+	rel := tuple.MustParse(relString)
+
+	revision, err := ds.HeadRevision(context.Background())
+	require.NoError(t, err)
+
+	it, err := ds.SnapshotReader(revision).QueryRelationships(context.Background(), datastore.RelationshipsFilter{
+		OptionalResourceType:     rel.Resource.ObjectType,
+		OptionalResourceIds:      []string{rel.Resource.ObjectID},
+		OptionalResourceRelation: rel.Resource.Relation,
+		OptionalSubjectsSelectors: []datastore.SubjectsSelector{
+			{
+				OptionalSubjectType: rel.Subject.ObjectType,
+				OptionalSubjectIds:  []string{rel.Subject.ObjectID},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	found, ok, err := datastore.FirstRelationshipIn(it)
+	require.NoError(t, err)
+	return found, ok
+}
+
+func readWriteJournals(t *testing.T, ds datastore.Datastore) []datastore.Record {
+	return readRecords(t, ds, "write_journal/")
+}
+
+func readIdempotencyRecords(t *testing.T, ds datastore.Datastore) []datastore.Record {
+	return readRecords(t, ds, "idempotency_key/")
+}
+
+func readRecords(t *testing.T, ds datastore.Datastore, prefix string) []datastore.Record {
+	var records []datastore.Record
+	_, err := ds.ReadWriteTx(context.Background(), func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		var err error
+		records, err = rwt.ReadRecords(ctx, prefix)
+		return err
+	})
+	require.NoError(t, err)
+	return records
+}
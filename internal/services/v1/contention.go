@@ -0,0 +1,190 @@
+package v1
+
+import (
+	"errors"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/runtime/protoiface"
+
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/spiceerrors"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+const (
+	// contentionBaseRetryDelay is the delay suggested for retrying the first write of a contention
+	// key that failed due to contention.
+	contentionBaseRetryDelay = 50 * time.Millisecond
+
+	// contentionMaxRetryDelay is the maximum delay suggested for retrying a write.
+	contentionMaxRetryDelay = 5 * time.Second
+
+	// contentionWindow is the duration after which the failures of the writes of a contention key
+	// are forgotten, if none has failed since.
+	contentionWindow = 10 * time.Second
+
+	// maxContentionKeys is the maximum number of contention keys whose failures are tracked.
+	maxContentionKeys = 10_000
+)
+
+// contentionBackoff tracks the writes which recently failed due to contention with concurrent
+// writes, by contention key, to compute the delay after which clients should retry them. The delay
+// grows exponentially with the number of consecutive failures of writes with the same key, so
+// that clients contending over the same relationships spread their retries.
+type contentionBackoff struct {
+	now    func() time.Time
+	jitter func(time.Duration) time.Duration
+
+	lock     sync.Mutex
+	failures map[string]contentionFailures
+}
+
+type contentionFailures struct {
+	count       int
+	lastFailure time.Time
+}
+
+func newContentionBackoff() *contentionBackoff {
+	return &contentionBackoff{
+		now:      time.Now,
+		jitter:   func(d time.Duration) time.Duration { return rand.N(d + 1) },
+		failures: make(map[string]contentionFailures),
+	}
+}
+
+// failed records a failure of a write with the given contention key, returning the delay after
+// which it should be retried.
+func (cb *contentionBackoff) failed(key string) time.Duration {
+	now := cb.now()
+
+	cb.lock.Lock()
+	failures, ok := cb.failures[key]
+	if !ok || now.Sub(failures.lastFailure) > contentionWindow {
+		failures = contentionFailures{}
+	}
+
+	if ok || len(cb.failures) < maxContentionKeys || cb.pruneLocked(now) {
+		failures.count++
+		failures.lastFailure = now
+		cb.failures[key] = failures
+	} else {
+		failures.count = 1
+	}
+	cb.lock.Unlock()
+
+	delay := contentionMaxRetryDelay
+	if failures.count <= 16 {
+		delay = min(contentionBaseRetryDelay<<(failures.count-1), contentionMaxRetryDelay)
+	}
+
+	// Half of the delay is jittered, so that retries of writes which failed together do not
+	// contend again.
+	return delay/2 + cb.jitter(delay/2)
+}
+
+// succeeded records the success of a write with the given contention key.
+func (cb *contentionBackoff) succeeded(key string) {
+	cb.lock.Lock()
+	defer cb.lock.Unlock()
+	delete(cb.failures, key)
+}
+
+// pruneLocked removes the keys without failures in the contention window, returning whether any
+// was removed.
+func (cb *contentionBackoff) pruneLocked(now time.Time) bool {
+	pruned := false
+	for key, failures := range cb.failures {
+		if now.Sub(failures.lastFailure) > contentionWindow {
+			delete(cb.failures, key)
+			pruned = true
+		}
+	}
+	return pruned
+}
+
+// withRetryGuidance returns the error of a write with the given contention key, with a RetryInfo
+// containing the delay after which the write should be retried and the contention key in the
+// metadata of its ErrorInfo if the write failed due to contention with concurrent writes.
+func (cb *contentionBackoff) withRetryGuidance(err error, key string) error {
+	var contentionErr datastore.ErrContention
+	if !errors.As(err, &contentionErr) || !contentionErr.IsContentionError() {
+		return err
+	}
+
+	s, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+
+	var errorInfo *errdetails.ErrorInfo
+	details := make([]protoiface.MessageV1, 0, len(s.Details())+1)
+	for _, detail := range s.Details() {
+		switch detail := detail.(type) {
+		case *errdetails.ErrorInfo:
+			if errorInfo == nil {
+				errorInfo = detail
+			}
+			details = append(details, detail)
+		case *errdetails.RetryInfo:
+			// Replaced below.
+		case protoiface.MessageV1:
+			details = append(details, detail)
+		}
+	}
+
+	if errorInfo == nil {
+		errorInfo = spiceerrors.ForReason(v1.ErrorReason_ERROR_REASON_UNSPECIFIED, nil)
+		details = append(details, errorInfo)
+	}
+
+	if key != "" {
+		if errorInfo.Metadata == nil {
+			errorInfo.Metadata = make(map[string]string, 1)
+		}
+		errorInfo.Metadata[string(spiceerrors.ContentionKeyDetailsKey)] = key
+	}
+
+	details = append(details, spiceerrors.ForRetryDelay(cb.failed(key)))
+	return spiceerrors.WithCodeAndDetailsAsError(err, s.Code(), details...)
+}
+
+// contentionKeyForUpdates returns the contention key of a write of the given updates: the resource
+// of the updates if they all update the same resource, otherwise their resource type if they all
+// share one, and otherwise empty.
+func contentionKeyForUpdates(updates []tuple.RelationshipUpdate) string {
+	if len(updates) == 0 {
+		return ""
+	}
+
+	first := updates[0].Relationship.Resource
+	sameResource := true
+	for _, update := range updates[1:] {
+		resource := update.Relationship.Resource
+		if resource.ObjectType != first.ObjectType {
+			return ""
+		}
+		if resource.ObjectID != first.ObjectID {
+			sameResource = false
+		}
+	}
+
+	if sameResource {
+		return tuple.StringONRStrings(first.ObjectType, first.ObjectID, tuple.Ellipsis)
+	}
+	return first.ObjectType
+}
+
+// contentionKeyForFilter returns the contention key of a deletion of the relationships matching
+// the given filter: the resource if the filter matches a single resource, and otherwise the
+// resource type.
+func contentionKeyForFilter(filter *v1.RelationshipFilter) string {
+	if filter.OptionalResourceId != "" {
+		return tuple.StringONRStrings(filter.ResourceType, filter.OptionalResourceId, tuple.Ellipsis)
+	}
+	return filter.ResourceType
+}
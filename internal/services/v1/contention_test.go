@@ -0,0 +1,100 @@
+package v1
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/authzed/spicedb/internal/datastore/common"
+	"github.com/authzed/spicedb/pkg/spiceerrors"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+func TestContentionBackoffDelays(t *testing.T) {
+	now := time.Now()
+	cb := newContentionBackoff()
+	cb.now = func() time.Time { return now }
+	cb.jitter = func(d time.Duration) time.Duration { return d }
+
+	require.Equal(t, 50*time.Millisecond, cb.failed("document:somedoc"))
+	require.Equal(t, 100*time.Millisecond, cb.failed("document:somedoc"))
+	require.Equal(t, 200*time.Millisecond, cb.failed("document:somedoc"))
+
+	// Other keys back off independently.
+	require.Equal(t, 50*time.Millisecond, cb.failed("document:otherdoc"))
+
+	for range 10 {
+		cb.failed("document:somedoc")
+	}
+	require.Equal(t, contentionMaxRetryDelay, cb.failed("document:somedoc"))
+
+	// A success resets the backoff.
+	cb.succeeded("document:somedoc")
+	require.Equal(t, 50*time.Millisecond, cb.failed("document:somedoc"))
+
+	// So does the end of the contention window.
+	now = now.Add(contentionWindow + time.Second)
+	require.Equal(t, 50*time.Millisecond, cb.failed("document:otherdoc"))
+}
+
+func TestContentionBackoffJitter(t *testing.T) {
+	cb := newContentionBackoff()
+	for range 100 {
+		delay := cb.failed("")
+		cb.succeeded("")
+		require.GreaterOrEqual(t, delay, contentionBaseRetryDelay/2)
+		require.LessOrEqual(t, delay, contentionBaseRetryDelay)
+	}
+}
+
+func TestWithRetryGuidance(t *testing.T) {
+	cb := newContentionBackoff()
+	cb.jitter = func(d time.Duration) time.Duration { return d }
+
+	otherErr := errors.New("some error")
+	require.Equal(t, otherErr, cb.withRetryGuidance(otherErr, "document:somedoc"))
+
+	err := cb.withRetryGuidance(common.NewSerializationError(errors.New("could not serialize")), "document:somedoc")
+	require.Equal(t, codes.Aborted, status.Code(err))
+	spiceerrors.RequireReason(t, v1.ErrorReason_ERROR_REASON_SERIALIZATION_FAILURE, err, string(spiceerrors.ContentionKeyDetailsKey))
+
+	errorInfo, ok := spiceerrors.GetDetails[*errdetails.ErrorInfo](err)
+	require.True(t, ok)
+	require.Equal(t, "document:somedoc", errorInfo.Metadata[string(spiceerrors.ContentionKeyDetailsKey)])
+
+	retryInfo, ok := spiceerrors.GetDetails[*errdetails.RetryInfo](err)
+	require.True(t, ok)
+	require.Equal(t, contentionBaseRetryDelay, retryInfo.RetryDelay.AsDuration())
+}
+
+func TestContentionKeys(t *testing.T) {
+	tcs := []struct {
+		name          string
+		relationships []string
+		expectedKey   string
+	}{
+		{"no updates", nil, ""},
+		{"single resource", []string{"document:somedoc#viewer@user:tom", "document:somedoc#editor@user:sarah"}, "document:somedoc"},
+		{"single resource type", []string{"document:somedoc#viewer@user:tom", "document:otherdoc#viewer@user:tom"}, "document"},
+		{"several resource types", []string{"document:somedoc#viewer@user:tom", "folder:somefolder#viewer@user:tom"}, ""},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			updates := make([]tuple.RelationshipUpdate, 0, len(tc.relationships))
+			for _, rel := range tc.relationships {
+				updates = append(updates, tuple.Touch(tuple.MustParse(rel)))
+			}
+			require.Equal(t, tc.expectedKey, contentionKeyForUpdates(updates))
+		})
+	}
+
+	require.Equal(t, "document:somedoc", contentionKeyForFilter(&v1.RelationshipFilter{ResourceType: "document", OptionalResourceId: "somedoc"}))
+	require.Equal(t, "document", contentionKeyForFilter(&v1.RelationshipFilter{ResourceType: "document"}))
+}
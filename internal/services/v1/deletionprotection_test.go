@@ -0,0 +1,162 @@
+package v1_test
+
+import (
+	"context"
+	"testing"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/authzed/grpcutil"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/internal/datastore/proxy"
+	tf "github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/internal/testserver"
+	"github.com/authzed/spicedb/pkg/errorcodes"
+	reconciliationv1 "github.com/authzed/spicedb/pkg/proto/reconciliation/v1"
+	"github.com/authzed/spicedb/pkg/requestmeta"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+func withDeletionProtectionOverride(token string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), string(requestmeta.RequestOverrideDeletionProtection), token)
+}
+
+func TestDeletionProtectionObjectTypes(t *testing.T) {
+	require := require.New(t)
+	config := testserver.DefaultTestServerConfig
+	config.DeletionProtection = proxy.DeletionProtectionConfig{
+		ProtectedObjectTypes: []string{"folder"},
+		AdminToken:           "admintoken",
+	}
+
+	conn, cleanup, _, _ := testserver.NewTestServerWithConfig(require, 0, memdb.DisableGC, true, config, tf.StandardDatastoreWithData)
+	t.Cleanup(cleanup)
+	client := v1.NewPermissionsServiceClient(conn)
+
+	deleteFolder := &v1.DeleteRelationshipsRequest{
+		RelationshipFilter: &v1.RelationshipFilter{ResourceType: "folder", OptionalResourceId: "company"},
+	}
+
+	_, err := client.DeleteRelationships(context.Background(), deleteFolder)
+	grpcutil.RequireStatus(t, codes.FailedPrecondition, err)
+	require.ErrorContains(err, "relationships of protected object type `folder` cannot be deleted")
+	code, ok := errorcodes.FromError(err)
+	require.True(ok)
+	require.Equal(errorcodes.DeletionProtected, code)
+
+	_, err = client.DeleteRelationships(withDeletionProtectionOverride("wrongtoken"), deleteFolder)
+	grpcutil.RequireStatus(t, codes.PermissionDenied, err)
+
+	_, err = client.DeleteRelationships(withDeletionProtectionOverride("admintoken"), deleteFolder)
+	require.NoError(err)
+
+	// Relationships of other object types are not protected.
+	_, err = client.DeleteRelationships(context.Background(), &v1.DeleteRelationshipsRequest{
+		RelationshipFilter: &v1.RelationshipFilter{ResourceType: "document", OptionalResourceId: "masterplan"},
+	})
+	require.NoError(err)
+}
+
+func TestDeletionProtectionMaxDeletedRelationships(t *testing.T) {
+	require := require.New(t)
+	config := testserver.DefaultTestServerConfig
+	config.DeletionProtection = proxy.DeletionProtectionConfig{MaxDeletedRelationships: 3}
+
+	conn, cleanup, _, _ := testserver.NewTestServerWithConfig(require, 0, memdb.DisableGC, true, config, tf.StandardDatastoreWithData)
+	t.Cleanup(cleanup)
+	client := v1.NewPermissionsServiceClient(conn)
+
+	// The filter matches the four relationships of the document.
+	deleteMasterplan := &v1.DeleteRelationshipsRequest{
+		RelationshipFilter: &v1.RelationshipFilter{ResourceType: "document", OptionalResourceId: "masterplan"},
+	}
+
+	_, err := client.DeleteRelationships(context.Background(), deleteMasterplan)
+	grpcutil.RequireStatus(t, codes.FailedPrecondition, err)
+	require.ErrorContains(err, "the filter matches more than the maximum of relationships that can be deleted at once")
+
+	// Without an admin token configured, the rules cannot be overridden.
+	_, err = client.DeleteRelationships(withDeletionProtectionOverride(""), deleteMasterplan)
+	grpcutil.RequireStatus(t, codes.PermissionDenied, err)
+
+	// A limit within the maximum bounds the delete.
+	resp, err := client.DeleteRelationships(context.Background(), &v1.DeleteRelationshipsRequest{
+		RelationshipFilter:            deleteMasterplan.RelationshipFilter,
+		OptionalLimit:                 2,
+		OptionalAllowPartialDeletions: true,
+	})
+	require.NoError(err)
+	require.Equal(v1.DeleteRelationshipsResponse_DELETION_PROGRESS_PARTIAL, resp.DeletionProgress)
+
+	// The two remaining relationships are within the maximum.
+	_, err = client.DeleteRelationships(context.Background(), deleteMasterplan)
+	require.NoError(err)
+}
+
+func TestDeletionProtectionWriteRelationships(t *testing.T) {
+	require := require.New(t)
+	config := testserver.DefaultTestServerConfig
+	config.DeletionProtection = proxy.DeletionProtectionConfig{
+		MaxDeletedRelationships: 1,
+		ProtectedObjectTypes:    []string{"folder"},
+		AdminToken:              "admintoken",
+	}
+
+	conn, cleanup, _, _ := testserver.NewTestServerWithConfig(require, 0, memdb.DisableGC, true, config, tf.StandardDatastoreWithData)
+	t.Cleanup(cleanup)
+	client := v1.NewPermissionsServiceClient(conn)
+
+	deleteUpdates := func(rels ...string) *v1.WriteRelationshipsRequest {
+		req := &v1.WriteRelationshipsRequest{}
+		for _, rel := range rels {
+			req.Updates = append(req.Updates, tuple.MustUpdateToV1RelationshipUpdate(tuple.Delete(tuple.MustParse(rel))))
+		}
+		return req
+	}
+
+	_, err := client.WriteRelationships(context.Background(), deleteUpdates("folder:company#viewer@user:legal"))
+	grpcutil.RequireStatus(t, codes.FailedPrecondition, err)
+	require.ErrorContains(err, "relationships of protected object type `folder` cannot be deleted")
+
+	_, err = client.WriteRelationships(context.Background(), deleteUpdates(
+		"document:masterplan#viewer@user:eng_lead",
+		"document:masterplan#owner@user:product_manager",
+	))
+	grpcutil.RequireStatus(t, codes.FailedPrecondition, err)
+	require.ErrorContains(err, "the write deletes more than the maximum of relationships that can be deleted at once")
+
+	_, err = client.WriteRelationships(context.Background(), deleteUpdates("document:masterplan#viewer@user:eng_lead"))
+	require.NoError(err)
+
+	_, err = client.WriteRelationships(withDeletionProtectionOverride("admintoken"), deleteUpdates("folder:company#viewer@user:legal"))
+	require.NoError(err)
+}
+
+func TestDeletionProtectionApplyRelationshipsSnapshot(t *testing.T) {
+	require := require.New(t)
+	config := testserver.DefaultTestServerConfig
+	config.DeletionProtection = proxy.DeletionProtectionConfig{
+		ProtectedObjectTypes: []string{"folder"},
+		AdminToken:           "admintoken",
+	}
+
+	conn, cleanup, _, _ := testserver.NewTestServerWithConfig(require, 0, memdb.DisableGC, true, config, tf.StandardDatastoreWithData)
+	t.Cleanup(cleanup)
+	reconciliation := reconciliationv1.NewReconciliationServiceClient(conn)
+
+	// An empty snapshot deletes every relationship of the scope.
+	emptyFolder := &reconciliationv1.ApplyRelationshipsSnapshotRequest{
+		RelationshipFilter: &v1.RelationshipFilter{ResourceType: "folder", OptionalResourceId: "company"},
+	}
+
+	_, err := reconciliation.ApplyRelationshipsSnapshot(context.Background(), emptyFolder)
+	grpcutil.RequireStatus(t, codes.FailedPrecondition, err)
+	require.ErrorContains(err, "relationships of protected object type `folder` cannot be deleted")
+
+	resp, err := reconciliation.ApplyRelationshipsSnapshot(withDeletionProtectionOverride("admintoken"), emptyFolder)
+	require.NoError(err)
+	require.NotZero(resp.RelationshipsDeleted)
+}
@@ -2,17 +2,25 @@ package v1
 
 import (
 	"fmt"
+	"maps"
 	"strconv"
+	"strings"
 
 	"github.com/rs/zerolog"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/runtime/protoiface"
 
 	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
 
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/errorcodes"
+	"github.com/authzed/spicedb/pkg/requestmeta"
 	"github.com/authzed/spicedb/pkg/spiceerrors"
 	"github.com/authzed/spicedb/pkg/tuple"
+	"github.com/authzed/spicedb/pkg/zedtoken"
 )
 
 // ExceedsMaximumLimitError occurs when a limit that is too large is given to a call.
@@ -51,6 +59,41 @@ func NewExceedsMaximumLimitErr(providedLimit uint64, maxLimitAllowed uint64) Exc
 	}
 }
 
+// ServerShuttingDownError occurs when a watch is ended because the server is shutting down.
+type ServerShuttingDownError struct {
+	error
+	changesThrough *v1.ZedToken
+}
+
+// MarshalZerologObject implements zerolog object marshalling.
+func (err ServerShuttingDownError) MarshalZerologObject(e *zerolog.Event) {
+	e.Err(err.error).Str("changesThrough", err.changesThrough.Token)
+}
+
+// GRPCStatus implements retrieving the gRPC status for the error.
+func (err ServerShuttingDownError) GRPCStatus() *status.Status {
+	return spiceerrors.WithCodeAndDetails(
+		err,
+		codes.Unavailable,
+		spiceerrors.ForCode(
+			errorcodes.ServerShuttingDown,
+			map[string]string{
+				"server_status":   "shutting_down",
+				"changes_through": err.changesThrough.Token,
+			},
+		),
+	)
+}
+
+// NewServerShuttingDownErr creates a new error representing that a watch was ended because the
+// server is shutting down, after sending all changes through the given revision.
+func NewServerShuttingDownErr(changesThrough datastore.Revision) ServerShuttingDownError {
+	return ServerShuttingDownError{
+		error:          fmt.Errorf("server is shutting down; resume the watch from changes through revision %s", changesThrough),
+		changesThrough: zedtoken.MustNewFromRevision(changesThrough),
+	}
+}
+
 // ExceedsMaximumChecksError occurs when too many checks are given to a call.
 type ExceedsMaximumChecksError struct {
 	error
@@ -68,8 +111,8 @@ func (err ExceedsMaximumChecksError) GRPCStatus() *status.Status {
 	return spiceerrors.WithCodeAndDetails(
 		err,
 		codes.InvalidArgument,
-		spiceerrors.ForReason(
-			v1.ErrorReason_ERROR_REASON_UNSPECIFIED,
+		spiceerrors.ForCode(
+			errorcodes.TooManyChecks,
 			map[string]string{
 				"check_count":            strconv.FormatUint(err.checkCount, 10),
 				"maximum_checks_allowed": strconv.FormatUint(err.maxCountAllowed, 10),
@@ -215,13 +258,15 @@ func (err PreconditionFailedError) GRPCStatus() *status.Status {
 		}
 	}
 
+	code := errorcodes.PreconditionMustMatchFailed
+	if err.precondition.Operation == v1.Precondition_OPERATION_MUST_NOT_MATCH {
+		code = errorcodes.PreconditionMustNotMatchFailed
+	}
+
 	return spiceerrors.WithCodeAndDetails(
 		err,
 		codes.FailedPrecondition,
-		spiceerrors.ForReason(
-			v1.ErrorReason_ERROR_REASON_WRITE_OR_DELETE_PRECONDITION_FAILURE,
-			metadata,
-		),
+		spiceerrors.ForCode(code, metadata),
 	)
 }
 
@@ -350,6 +395,149 @@ func (err CouldNotTransactionallyDeleteError) GRPCStatus() *status.Status {
 	)
 }
 
+// RelationshipVersionMismatchError occurs when the relationship of an update with an expected
+// version does not exist or has another version.
+type RelationshipVersionMismatchError struct {
+	error
+	updateIndex     int
+	relationship    string
+	expectedVersion string
+	currentVersion  string
+}
+
+// NewRelationshipVersionMismatchErr constructs a new relationship version mismatch error. The
+// current version is empty if the relationship does not exist.
+func NewRelationshipVersionMismatchErr(updateIndex int, relationship string, expectedVersion string, currentVersion string) RelationshipVersionMismatchError {
+	if currentVersion == "" {
+		return RelationshipVersionMismatchError{
+			error:           fmt.Errorf("relationship `%s` of update %d was expected at version `%s`, but it does not exist", relationship, updateIndex, expectedVersion),
+			updateIndex:     updateIndex,
+			relationship:    relationship,
+			expectedVersion: expectedVersion,
+		}
+	}
+
+	return RelationshipVersionMismatchError{
+		error:           fmt.Errorf("relationship `%s` of update %d was expected at version `%s`, but it is at version `%s`", relationship, updateIndex, expectedVersion, currentVersion),
+		updateIndex:     updateIndex,
+		relationship:    relationship,
+		expectedVersion: expectedVersion,
+		currentVersion:  currentVersion,
+	}
+}
+
+// GRPCStatus implements retrieving the gRPC status for the error.
+func (err RelationshipVersionMismatchError) GRPCStatus() *status.Status {
+	return spiceerrors.WithCodeAndDetails(
+		err,
+		codes.FailedPrecondition,
+		spiceerrors.ForCode(
+			errorcodes.RelationshipVersionMismatch,
+			map[string]string{
+				"update_index":     strconv.Itoa(err.updateIndex),
+				"relationship":     err.relationship,
+				"expected_version": err.expectedVersion,
+				"current_version":  err.currentVersion,
+			},
+		),
+	)
+}
+
+// RelationshipVersionsLimitError occurs when the versions of the relationships read by a
+// ReadRelationships request are requested without a limit, or with a limit which is too large.
+type RelationshipVersionsLimitError struct {
+	error
+	providedLimit   uint32
+	maxLimitAllowed uint32
+}
+
+// NewRelationshipVersionsLimitErr constructs a new relationship versions limit error.
+func NewRelationshipVersionsLimitErr(providedLimit uint32, maxLimitAllowed uint32) RelationshipVersionsLimitError {
+	return RelationshipVersionsLimitError{
+		error:           fmt.Errorf("relationship versions require a limit between 1 and %d, found %d", maxLimitAllowed, providedLimit),
+		providedLimit:   providedLimit,
+		maxLimitAllowed: maxLimitAllowed,
+	}
+}
+
+// GRPCStatus implements retrieving the gRPC status for the error.
+func (err RelationshipVersionsLimitError) GRPCStatus() *status.Status {
+	return spiceerrors.WithCodeAndDetails(
+		err,
+		codes.InvalidArgument,
+		spiceerrors.ForReason(
+			v1.ErrorReason_ERROR_REASON_EXCEEDS_MAXIMUM_ALLOWABLE_LIMIT,
+			map[string]string{
+				"limit_provided":        strconv.FormatUint(uint64(err.providedLimit), 10),
+				"maximum_limit_allowed": strconv.FormatUint(uint64(err.maxLimitAllowed), 10),
+			},
+		),
+	)
+}
+
+// InvalidExpectedRelationshipVersionError occurs when an expected relationship version header of
+// a write is malformed or does not refer to an update of the write.
+type InvalidExpectedRelationshipVersionError struct {
+	error
+	value string
+}
+
+// NewInvalidExpectedRelationshipVersionErr constructs a new invalid expected relationship version
+// error.
+func NewInvalidExpectedRelationshipVersionErr(value string, reason string) InvalidExpectedRelationshipVersionError {
+	return InvalidExpectedRelationshipVersionError{
+		error: fmt.Errorf("invalid expected relationship version `%s`: %s", value, reason),
+		value: value,
+	}
+}
+
+// GRPCStatus implements retrieving the gRPC status for the error.
+func (err InvalidExpectedRelationshipVersionError) GRPCStatus() *status.Status {
+	return spiceerrors.WithCodeAndDetails(
+		err,
+		codes.InvalidArgument,
+		&errdetails.BadRequest{
+			FieldViolations: []*errdetails.BadRequest_FieldViolation{
+				{
+					Field:       requestmeta.ExpectedRelationshipVersionHeaderKey,
+					Description: err.Error(),
+				},
+			},
+		},
+		spiceerrors.ForCode(errorcodes.InvalidExpectedRelationshipVersion, nil),
+	)
+}
+
+// IdempotencyKeyReusedError occurs when the idempotency key of a write was already used for
+// another write request.
+type IdempotencyKeyReusedError struct {
+	error
+}
+
+// NewIdempotencyKeyReusedErr constructs a new idempotency key reused error.
+func NewIdempotencyKeyReusedErr(idempotencyKey string) IdempotencyKeyReusedError {
+	return IdempotencyKeyReusedError{
+		error: fmt.Errorf("idempotency key `%s` was already used for another write request", idempotencyKey),
+	}
+}
+
+// GRPCStatus implements retrieving the gRPC status for the error.
+func (err IdempotencyKeyReusedError) GRPCStatus() *status.Status {
+	return spiceerrors.WithCodeAndDetails(
+		err,
+		codes.InvalidArgument,
+		&errdetails.BadRequest{
+			FieldViolations: []*errdetails.BadRequest_FieldViolation{
+				{
+					Field:       string(requestmeta.RequestIdempotencyKey),
+					Description: err.Error(),
+				},
+			},
+		},
+		spiceerrors.ForCode(errorcodes.IdempotencyKeyReused, nil),
+	)
+}
+
 // InvalidCursorError indicates that an invalid cursor was found.
 type InvalidCursorError struct {
 	error
@@ -427,14 +615,10 @@ type EmptyPreconditionError struct {
 
 // GRPCStatus implements retrieving the gRPC status for the error.
 func (err EmptyPreconditionError) GRPCStatus() *status.Status {
-	// TODO(jschorr): Put a proper error reason in here.
 	return spiceerrors.WithCodeAndDetails(
 		err,
 		codes.InvalidArgument,
-		spiceerrors.ForReason(
-			v1.ErrorReason_ERROR_REASON_UNSPECIFIED,
-			map[string]string{},
-		),
+		spiceerrors.ForCode(errorcodes.EmptyPrecondition, map[string]string{}),
 	)
 }
 
@@ -459,8 +643,8 @@ func (err NotAPermissionError) GRPCStatus() *status.Status {
 	return spiceerrors.WithCodeAndDetails(
 		err,
 		codes.InvalidArgument,
-		spiceerrors.ForReason(
-			v1.ErrorReason_ERROR_REASON_UNKNOWN_RELATION_OR_PERMISSION,
+		spiceerrors.ForCode(
+			errorcodes.NotAPermission,
 			map[string]string{
 				"relationName": err.relationName,
 			},
@@ -509,3 +693,111 @@ func (err TransactionMetadataTooLargeError) GRPCStatus() *status.Status {
 		),
 	)
 }
+
+// NonConformingUpdatesError indicates that one or more updates of a write with strict validation
+// do not conform to the schema.
+type NonConformingUpdatesError struct {
+	error
+	violations []UpdateViolation
+}
+
+// UpdateViolation is an update which does not conform to the schema.
+type UpdateViolation struct {
+	// Index is the index of the update in the request.
+	Index int
+
+	// Relationship is the relationship of the update.
+	Relationship string
+
+	// Status is the status of the validation error of the update.
+	Status *status.Status
+
+	// Details are details of the schema to add to those of the validation error, such as the
+	// subject types allowed on the relation.
+	Details map[string]string
+}
+
+// NewNonConformingUpdatesErr constructs a new error for the updates which do not conform to the schema.
+func NewNonConformingUpdatesErr(updateCount int, violations []UpdateViolation) NonConformingUpdatesError {
+	messages := make([]string, 0, len(violations))
+	for _, violation := range violations {
+		messages = append(messages, fmt.Sprintf("updates[%d] `%s`: %s", violation.Index, violation.Relationship, violation.Status.Message()))
+	}
+
+	return NonConformingUpdatesError{
+		error: fmt.Errorf(
+			"%d of %d relationship updates do not conform to the schema: %s",
+			len(violations),
+			updateCount,
+			strings.Join(messages, "; "),
+		),
+		violations: violations,
+	}
+}
+
+// GRPCStatus implements retrieving the gRPC status for the error. The details contain a BadRequest
+// with a field violation per non-conforming update, followed by the ErrorInfo of each of their
+// errors, with the index of the update in the update_index metadata.
+func (err NonConformingUpdatesError) GRPCStatus() *status.Status {
+	badRequest := &errdetails.BadRequest{}
+	details := []protoiface.MessageV1{badRequest}
+	for _, violation := range err.violations {
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       fmt.Sprintf("updates[%d].relationship", violation.Index),
+			Description: violation.Status.Message(),
+		})
+
+		errorInfo := spiceerrors.ForReason(v1.ErrorReason_ERROR_REASON_UNSPECIFIED, nil)
+		for _, detail := range violation.Status.Details() {
+			if info, ok := detail.(*errdetails.ErrorInfo); ok {
+				errorInfo = proto.Clone(info).(*errdetails.ErrorInfo)
+				break
+			}
+		}
+
+		if errorInfo.Metadata == nil {
+			errorInfo.Metadata = map[string]string{}
+		}
+		maps.Copy(errorInfo.Metadata, violation.Details)
+		errorInfo.Metadata["update_index"] = strconv.Itoa(violation.Index)
+		errorInfo.Metadata["relationship"] = violation.Relationship
+		details = append(details, errorInfo)
+	}
+
+	return spiceerrors.WithCodeAndDetails(err, codes.InvalidArgument, details...)
+}
+
+// ChunkedWriteRollbackError occurs when a transaction of a chunked write failed, and the
+// transactions already applied could not be rolled back, leaving the write partially applied.
+type ChunkedWriteRollbackError struct {
+	error
+	chunksApplied int
+}
+
+// NewChunkedWriteRollbackErr constructs a new chunked write rollback error from the error of the
+// failed transaction and that of the rollback.
+func NewChunkedWriteRollbackErr(writeErr error, rollbackErr error, chunksApplied int) ChunkedWriteRollbackError {
+	return ChunkedWriteRollbackError{
+		error:         fmt.Errorf("chunked write failed after %d transactions were applied, which could not be rolled back: %w (rollback error: %w)", chunksApplied, writeErr, rollbackErr),
+		chunksApplied: chunksApplied,
+	}
+}
+
+// MarshalZerologObject implements zerolog object marshalling.
+func (err ChunkedWriteRollbackError) MarshalZerologObject(e *zerolog.Event) {
+	e.Err(err.error).Int("chunksApplied", err.chunksApplied)
+}
+
+// GRPCStatus implements retrieving the gRPC status for the error.
+func (err ChunkedWriteRollbackError) GRPCStatus() *status.Status {
+	return spiceerrors.WithCodeAndDetails(
+		err,
+		codes.Internal,
+		spiceerrors.ForCode(
+			errorcodes.ChunkedWriteRollbackFailed,
+			map[string]string{
+				"chunks_applied": strconv.Itoa(err.chunksApplied),
+			},
+		),
+	)
+}
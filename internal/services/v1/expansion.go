@@ -0,0 +1,358 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	grpcvalidate "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/validator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/authzed/spicedb/internal/dispatch"
+	"github.com/authzed/spicedb/internal/middleware"
+	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
+	"github.com/authzed/spicedb/internal/middleware/usagemetrics"
+	"github.com/authzed/spicedb/internal/namespace"
+	"github.com/authzed/spicedb/internal/services/shared"
+	"github.com/authzed/spicedb/pkg/cursor"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/middleware/consistency"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	dispatchv1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+	expansionv1 "github.com/authzed/spicedb/pkg/proto/expansion/v1"
+	"github.com/authzed/spicedb/pkg/spiceerrors"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+type expansionServer struct {
+	expansionv1.UnimplementedExpansionServiceServer
+	shared.WithServiceSpecificInterceptors
+
+	dispatch                  dispatch.Dispatcher
+	maximumAPIDepth           uint32
+	maxReadRelationshipsLimit uint32
+}
+
+// NewExpansionServer creates an instance of the experimental expansion server.
+func NewExpansionServer(dispatch dispatch.Dispatcher, config PermissionsServerConfig) expansionv1.ExpansionServiceServer {
+	return &expansionServer{
+		WithServiceSpecificInterceptors: shared.WithServiceSpecificInterceptors{
+			Unary: middleware.ChainUnaryServer(
+				grpcvalidate.UnaryServerInterceptor(),
+				usagemetrics.UnaryServerInterceptor(),
+			),
+		},
+		dispatch:                  dispatch,
+		maximumAPIDepth:           defaultIfZero(config.MaximumAPIDepth, 50),
+		maxReadRelationshipsLimit: defaultIfZero(config.MaxReadRelationshipsLimit, 1_000),
+	}
+}
+
+// ExpandPermissionTreeWithLimits expands the permission tree of the resource, then the trees of the
+// subject sets found in its leaves, down to the requested depth. The leaf subjects are walked in a
+// deterministic order, so that pages of them can be returned at the same revision, and expansion
+// stops as soon as the page is filled.
+func (es *expansionServer) ExpandPermissionTreeWithLimits(ctx context.Context, req *expansionv1.ExpandPermissionTreeWithLimitsRequest) (*expansionv1.ExpandPermissionTreeWithLimitsResponse, error) {
+	if req.Resource.GetObjectType() == "" || req.Resource.GetObjectId() == "" || req.Permission == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "resource and permission are required")
+	}
+	if req.MaxDepth > es.maximumAPIDepth {
+		return nil, es.rewriteError(ctx, NewExceedsMaximumLimitErr(uint64(req.MaxDepth), uint64(es.maximumAPIDepth)))
+	}
+	if req.OptionalLimit > es.maxReadRelationshipsLimit {
+		return nil, es.rewriteError(ctx, NewExceedsMaximumLimitErr(uint64(req.OptionalLimit), uint64(es.maxReadRelationshipsLimit)))
+	}
+
+	atRevision, expandedAt, err := consistency.RevisionFromContext(ctx)
+	if err != nil {
+		return nil, es.rewriteError(ctx, err)
+	}
+
+	ds := datastoremw.MustFromContext(ctx).SnapshotReader(atRevision)
+	err = namespace.CheckNamespaceAndRelation(ctx, req.Resource.ObjectType, req.Permission, false, ds)
+	if err != nil {
+		return nil, es.rewriteError(ctx, err)
+	}
+
+	requestHash, err := computeExpandWithLimitsRequestHash(req)
+	if err != nil {
+		return nil, es.rewriteError(ctx, err)
+	}
+
+	var offset uint64
+	if req.OptionalCursor != nil {
+		decodedCursor, _, err := cursor.DecodeToDispatchCursor(req.OptionalCursor, requestHash)
+		if err != nil {
+			return nil, es.rewriteError(ctx, err)
+		}
+
+		if len(decodedCursor.Sections) != 1 {
+			return nil, es.rewriteError(ctx, cursor.NewInvalidCursorErr(fmt.Errorf("expected a single cursor section, found %d", len(decodedCursor.Sections))))
+		}
+
+		offset, err = strconv.ParseUint(decodedCursor.Sections[0], 10, 64)
+		if err != nil {
+			return nil, es.rewriteError(ctx, cursor.NewInvalidCursorErr(err))
+		}
+	}
+
+	expander := &limitedExpander{
+		dispatch:        es.dispatch,
+		revision:        atRevision,
+		maximumAPIDepth: es.maximumAPIDepth,
+		maxDepth:        req.MaxDepth,
+		offset:          offset,
+		limit:           uint64(req.OptionalLimit),
+		meta:            &dispatchv1.ResponseMeta{},
+	}
+
+	resource := &core.ObjectAndRelation{
+		Namespace: req.Resource.ObjectType,
+		ObjectId:  req.Resource.ObjectId,
+		Relation:  req.Permission,
+	}
+	root, err := expander.expand(ctx, resource, 0, nil)
+	usagemetrics.SetInContext(ctx, expander.meta)
+	if err != nil {
+		return nil, es.rewriteError(ctx, err)
+	}
+
+	if root == nil {
+		root = &core.RelationTupleTreeNode{
+			NodeType: &core.RelationTupleTreeNode_LeafNode{LeafNode: &core.DirectSubjects{}},
+			Expanded: resource,
+		}
+	}
+
+	frozen := make([]*v1.SubjectReference, 0, len(expander.frozen))
+	for _, onr := range expander.frozen {
+		frozen = append(frozen, &v1.SubjectReference{
+			Object: &v1.ObjectReference{
+				ObjectType: onr.Namespace,
+				ObjectId:   onr.ObjectId,
+			},
+			OptionalRelation: denormalizeSubjectRelation(onr.Relation),
+		})
+	}
+
+	var afterResultCursor *v1.Cursor
+	if expander.hasMore {
+		afterResultCursor, err = cursor.EncodeFromDispatchCursor(&dispatchv1.Cursor{
+			DispatchVersion: 1,
+			Sections:        []string{strconv.FormatUint(offset+expander.limit, 10)},
+		}, requestHash, atRevision, nil)
+		if err != nil {
+			return nil, es.rewriteError(ctx, err)
+		}
+	}
+
+	return &expansionv1.ExpandPermissionTreeWithLimitsResponse{
+		TreeRoot:          TranslateExpansionTree(root),
+		ExpandedAt:        expandedAt,
+		FrozenSubjectSets: frozen,
+		AfterResultCursor: afterResultCursor,
+	}, nil
+}
+
+func (es *expansionServer) rewriteError(ctx context.Context, err error) error {
+	return shared.RewriteError(ctx, err, &shared.ConfigForErrors{
+		MaximumAPIDepth: es.maximumAPIDepth,
+	})
+}
+
+// limitedExpander expands permission trees through the subject sets of their leaves, returning only
+// the leaf subjects within a window of the ordered subjects.
+type limitedExpander struct {
+	dispatch        dispatch.Dispatcher
+	revision        datastore.Revision
+	maximumAPIDepth uint32
+	maxDepth        uint32
+
+	// offset is the index of the first leaf subject returned, and limit the maximum number of leaf
+	// subjects returned, with zero meaning no limit.
+	offset uint64
+	limit  uint64
+
+	// seen is the number of leaf subjects walked, hasMore whether a leaf subject was found beyond
+	// the window and frozen the subject sets of the window which were not expanded due to the
+	// depth.
+	seen    uint64
+	hasMore bool
+	frozen  []*core.ObjectAndRelation
+	meta    *dispatchv1.ResponseMeta
+}
+
+// expand dispatches a shallow expansion of the relation and walks the resulting tree, returning nil
+// if it contains no leaf subjects within the window.
+func (le *limitedExpander) expand(ctx context.Context, onr *core.ObjectAndRelation, depth uint32, path []string) (*core.RelationTupleTreeNode, error) {
+	bf, err := dispatchv1.NewTraversalBloomFilter(uint(le.maximumAPIDepth))
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := le.dispatch.DispatchExpand(ctx, &dispatchv1.DispatchExpandRequest{
+		Metadata: &dispatchv1.ResolverMeta{
+			AtRevision:     le.revision.String(),
+			DepthRemaining: le.maximumAPIDepth,
+			TraversalBloom: bf,
+		},
+		ResourceAndRelation: onr,
+		ExpansionMode:       dispatchv1.DispatchExpandRequest_SHALLOW,
+	})
+	if resp.GetMetadata() != nil {
+		le.meta.DispatchCount += resp.Metadata.DispatchCount
+		le.meta.CachedDispatchCount += resp.Metadata.CachedDispatchCount
+		le.meta.DepthRequired = max(le.meta.DepthRequired, resp.Metadata.DepthRequired)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return le.walk(ctx, resp.TreeNode, depth, append(slices.Clip(path), tuple.StringCoreONR(onr)))
+}
+
+// walk returns the tree of the node pruned to the leaf subjects within the window, with the subject
+// sets of its leaves expanded if the depth allows. path holds the subject sets being expanded, which
+// are not expanded again when found within their own trees.
+func (le *limitedExpander) walk(ctx context.Context, node *core.RelationTupleTreeNode, depth uint32, path []string) (*core.RelationTupleTreeNode, error) {
+	switch t := node.NodeType.(type) {
+	case *core.RelationTupleTreeNode_IntermediateNode:
+		children := t.IntermediateNode.ChildNodes
+		if t.IntermediateNode.Operation == core.SetOperationUserset_UNION {
+			// The children of unions, such as those of arrows, are in datastore order.
+			children = slices.Clone(children)
+			slices.SortStableFunc(children, func(a, b *core.RelationTupleTreeNode) int {
+				return strings.Compare(expandedKey(a), expandedKey(b))
+			})
+		}
+
+		var kept []*core.RelationTupleTreeNode
+		for _, child := range children {
+			if le.hasMore {
+				break
+			}
+
+			pruned, err := le.walk(ctx, child, depth, path)
+			if err != nil {
+				return nil, err
+			}
+			if pruned != nil {
+				kept = append(kept, pruned)
+			}
+		}
+
+		if len(kept) == 0 {
+			return nil, nil
+		}
+
+		return &core.RelationTupleTreeNode{
+			NodeType: &core.RelationTupleTreeNode_IntermediateNode{
+				IntermediateNode: &core.SetOperationUserset{
+					Operation:  t.IntermediateNode.Operation,
+					ChildNodes: kept,
+				},
+			},
+			Expanded: node.Expanded,
+		}, nil
+
+	case *core.RelationTupleTreeNode_LeafNode:
+		subjects := slices.Clone(t.LeafNode.Subjects)
+		slices.SortFunc(subjects, func(a, b *core.DirectSubject) int {
+			return strings.Compare(tuple.StringCoreONR(a.Subject), tuple.StringCoreONR(b.Subject))
+		})
+
+		// The subjects which are not expanded are walked first, in the order in which they are
+		// returned, followed by the trees of the expanded subject sets.
+		var direct []*core.DirectSubject
+		var toExpand []*core.ObjectAndRelation
+		for _, subject := range subjects {
+			isSubjectSet := subject.Subject.Relation != tuple.Ellipsis
+			if isSubjectSet && depth < le.maxDepth && !slices.Contains(path, tuple.StringCoreONR(subject.Subject)) {
+				toExpand = append(toExpand, subject.Subject)
+				continue
+			}
+
+			if le.hasMore || !le.take() {
+				continue
+			}
+
+			direct = append(direct, subject)
+			if isSubjectSet && depth >= le.maxDepth {
+				le.frozen = append(le.frozen, subject.Subject)
+			}
+		}
+
+		var expansions []*core.RelationTupleTreeNode
+		for _, subjectSet := range toExpand {
+			if le.hasMore {
+				break
+			}
+
+			expansion, err := le.expand(ctx, subjectSet, depth+1, path)
+			if err != nil {
+				return nil, err
+			}
+			if expansion != nil {
+				expansions = append(expansions, expansion)
+			}
+		}
+
+		var leaf *core.RelationTupleTreeNode
+		if len(direct) > 0 {
+			leaf = &core.RelationTupleTreeNode{
+				NodeType: &core.RelationTupleTreeNode_LeafNode{LeafNode: &core.DirectSubjects{Subjects: direct}},
+				Expanded: node.Expanded,
+			}
+		}
+
+		if len(expansions) == 0 {
+			return leaf, nil
+		}
+
+		// The expanded subject sets replace their subjects in a union with the remaining subjects.
+		if leaf != nil {
+			expansions = append([]*core.RelationTupleTreeNode{leaf}, expansions...)
+		}
+
+		return &core.RelationTupleTreeNode{
+			NodeType: &core.RelationTupleTreeNode_IntermediateNode{
+				IntermediateNode: &core.SetOperationUserset{
+					Operation:  core.SetOperationUserset_UNION,
+					ChildNodes: expansions,
+				},
+			},
+			Expanded: node.Expanded,
+		}, nil
+
+	default:
+		return nil, spiceerrors.MustBugf("unknown type of expansion tree node")
+	}
+}
+
+// take counts a leaf subject, returning whether it is within the window.
+func (le *limitedExpander) take() bool {
+	index := le.seen
+	le.seen++
+
+	if index < le.offset {
+		return false
+	}
+
+	if le.limit > 0 && index >= le.offset+le.limit {
+		le.hasMore = true
+		return false
+	}
+
+	return true
+}
+
+func expandedKey(node *core.RelationTupleTreeNode) string {
+	if node.Expanded == nil {
+		return ""
+	}
+	return tuple.StringCoreONR(node.Expanded)
+}
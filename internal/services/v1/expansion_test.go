@@ -0,0 +1,207 @@
+package v1_test
+
+import (
+	"context"
+	"testing"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/authzed/grpcutil"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/internal/testserver"
+	expansionv1 "github.com/authzed/spicedb/pkg/proto/expansion/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+	"github.com/authzed/spicedb/pkg/zedtoken"
+)
+
+func TestExpandPermissionTreeWithLimits(t *testing.T) {
+	tcs := []struct {
+		name             string
+		resourceID       string
+		permission       string
+		maxDepth         uint32
+		expectedSubjects []string
+		expectedFrozen   []string
+	}{
+		{
+			name:             "subject set frozen at depth zero",
+			resourceID:       "company",
+			permission:       "viewer",
+			expectedSubjects: []string{"folder:auditors#viewer", "user:legal"},
+			expectedFrozen:   []string{"folder:auditors#viewer"},
+		},
+		{
+			name:             "subject set expanded",
+			resourceID:       "company",
+			permission:       "viewer",
+			maxDepth:         1,
+			expectedSubjects: []string{"user:auditor", "user:legal"},
+		},
+		{
+			name:             "permission with arrows",
+			resourceID:       "strategy",
+			permission:       "view",
+			maxDepth:         1,
+			expectedSubjects: []string{"user:auditor", "user:legal", "user:owner", "user:vp_product"},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			require := require.New(t)
+			conn, cleanup, _, revision := testserver.NewTestServer(require, 0, memdb.DisableGC, true, testfixtures.StandardDatastoreWithData)
+			t.Cleanup(cleanup)
+
+			resp, err := expansionv1.NewExpansionServiceClient(conn).ExpandPermissionTreeWithLimits(context.Background(), &expansionv1.ExpandPermissionTreeWithLimitsRequest{
+				Consistency: &v1.Consistency{
+					Requirement: &v1.Consistency_AtLeastAsFresh{AtLeastAsFresh: zedtoken.MustNewFromRevision(revision)},
+				},
+				Resource:   &v1.ObjectReference{ObjectType: "folder", ObjectId: tc.resourceID},
+				Permission: tc.permission,
+				MaxDepth:   tc.maxDepth,
+			})
+			require.NoError(err)
+			require.NotNil(resp.ExpandedAt)
+			require.Nil(resp.AfterResultCursor)
+			require.ElementsMatch(tc.expectedSubjects, leafSubjects(resp.TreeRoot))
+
+			var frozen []string
+			for _, subject := range resp.FrozenSubjectSets {
+				frozen = append(frozen, tuple.V1StringSubjectRef(subject))
+			}
+			require.ElementsMatch(tc.expectedFrozen, frozen)
+		})
+	}
+}
+
+func TestExpandPermissionTreeWithLimitsPaging(t *testing.T) {
+	require := require.New(t)
+	conn, cleanup, _, revision := testserver.NewTestServer(require, 0, memdb.DisableGC, true, testfixtures.StandardDatastoreWithData)
+	t.Cleanup(cleanup)
+
+	client := expansionv1.NewExpansionServiceClient(conn)
+	request := func(limit uint32, cursor *v1.Cursor) *expansionv1.ExpandPermissionTreeWithLimitsResponse {
+		resp, err := client.ExpandPermissionTreeWithLimits(context.Background(), &expansionv1.ExpandPermissionTreeWithLimitsRequest{
+			Consistency: &v1.Consistency{
+				Requirement: &v1.Consistency_AtLeastAsFresh{AtLeastAsFresh: zedtoken.MustNewFromRevision(revision)},
+			},
+			Resource:       &v1.ObjectReference{ObjectType: "document", ObjectId: "masterplan"},
+			Permission:     "view",
+			MaxDepth:       2,
+			OptionalLimit:  limit,
+			OptionalCursor: cursor,
+		})
+		require.NoError(err)
+		return resp
+	}
+
+	expected := leafSubjects(request(0, nil).TreeRoot)
+	require.Len(expected, 7)
+
+	var paged []string
+	var cursor *v1.Cursor
+	pages := 0
+	for {
+		resp := request(3, cursor)
+		pages++
+
+		subjects := leafSubjects(resp.TreeRoot)
+		require.LessOrEqual(len(subjects), 3)
+		paged = append(paged, subjects...)
+
+		if resp.AfterResultCursor == nil {
+			break
+		}
+		cursor = resp.AfterResultCursor
+	}
+
+	require.Equal(3, pages)
+	require.Equal(expected, paged)
+
+	// A cursor cannot be used with different parameters.
+	_, err := client.ExpandPermissionTreeWithLimits(context.Background(), &expansionv1.ExpandPermissionTreeWithLimitsRequest{
+		Resource:       &v1.ObjectReference{ObjectType: "document", ObjectId: "masterplan"},
+		Permission:     "view",
+		MaxDepth:       1,
+		OptionalLimit:  3,
+		OptionalCursor: cursor,
+	})
+	grpcutil.RequireStatus(t, codes.InvalidArgument, err)
+}
+
+func TestExpandPermissionTreeWithLimitsErrors(t *testing.T) {
+	tcs := []struct {
+		name         string
+		request      *expansionv1.ExpandPermissionTreeWithLimitsRequest
+		expectedCode codes.Code
+	}{
+		{
+			name: "missing resource",
+			request: &expansionv1.ExpandPermissionTreeWithLimitsRequest{
+				Permission: "view",
+			},
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name: "unknown permission",
+			request: &expansionv1.ExpandPermissionTreeWithLimitsRequest{
+				Resource:   &v1.ObjectReference{ObjectType: "document", ObjectId: "masterplan"},
+				Permission: "unknown",
+			},
+			expectedCode: codes.FailedPrecondition,
+		},
+		{
+			name: "depth above the maximum",
+			request: &expansionv1.ExpandPermissionTreeWithLimitsRequest{
+				Resource:   &v1.ObjectReference{ObjectType: "document", ObjectId: "masterplan"},
+				Permission: "view",
+				MaxDepth:   1_000,
+			},
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name: "limit above the maximum",
+			request: &expansionv1.ExpandPermissionTreeWithLimitsRequest{
+				Resource:      &v1.ObjectReference{ObjectType: "document", ObjectId: "masterplan"},
+				Permission:    "view",
+				OptionalLimit: 100_000,
+			},
+			expectedCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			conn, cleanup, _, _ := testserver.NewTestServer(require.New(t), 0, memdb.DisableGC, true, testfixtures.StandardDatastoreWithData)
+			t.Cleanup(cleanup)
+
+			_, err := expansionv1.NewExpansionServiceClient(conn).ExpandPermissionTreeWithLimits(context.Background(), tc.request)
+			grpcutil.RequireStatus(t, tc.expectedCode, err)
+		})
+	}
+}
+
+// leafSubjects returns the subjects of the leaves of the tree, in order.
+func leafSubjects(node *v1.PermissionRelationshipTree) []string {
+	switch t := node.TreeType.(type) {
+	case *v1.PermissionRelationshipTree_Leaf:
+		subjects := make([]string, 0, len(t.Leaf.Subjects))
+		for _, subject := range t.Leaf.Subjects {
+			subjects = append(subjects, tuple.V1StringSubjectRef(subject))
+		}
+		return subjects
+
+	case *v1.PermissionRelationshipTree_Intermediate:
+		var subjects []string
+		for _, child := range t.Intermediate.Children {
+			subjects = append(subjects, leafSubjects(child)...)
+		}
+		return subjects
+
+	default:
+		panic("unknown node type")
+	}
+}
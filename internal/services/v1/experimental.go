@@ -112,6 +112,7 @@ func NewExperimentalServer(dispatch dispatch.Dispatcher, permServerConfig Permis
 			maxConcurrency:       config.BulkCheckMaxConcurrency,
 			dispatch:             dispatch,
 			dispatchChunkSize:    chunkSize,
+			prefilterCaveats:     permServerConfig.CaveatPrefilteringEnabled,
 		},
 	}
 }
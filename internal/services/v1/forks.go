@@ -0,0 +1,293 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	middleware "github.com/grpc-ecosystem/go-grpc-middleware/v2"
+	grpcvalidate "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/validator"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/authzed/spicedb/internal/datastore/fork"
+	log "github.com/authzed/spicedb/internal/logging"
+	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
+	"github.com/authzed/spicedb/internal/services/shared"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/middleware/consistency"
+	forksv1 "github.com/authzed/spicedb/pkg/proto/forks/v1"
+	spicedbrequestmeta "github.com/authzed/spicedb/pkg/requestmeta"
+	"github.com/authzed/spicedb/pkg/zedtoken"
+)
+
+// ForksConfig is the configuration of the experimental forks server.
+type ForksConfig struct {
+	// MaxTTL is the maximum duration for which a fork is kept, which is also the default when a
+	// fork is created without a TTL. Defaults to a day.
+	MaxTTL time.Duration
+
+	// MaxForks is the maximum number of forks held at once, beyond which creations are rejected.
+	// Defaults to 16.
+	MaxForks uint16
+}
+
+// ForksServer is the experimental forks server, which must be closed to discard its forks.
+type ForksServer interface {
+	forksv1.ForkServiceServer
+	io.Closer
+
+	// lookup returns the datastore of the fork with the given ID.
+	lookup(forkID string) (datastore.Datastore, error)
+}
+
+type forksServer struct {
+	forksv1.UnimplementedForkServiceServer
+	shared.WithServiceSpecificInterceptors
+
+	base   datastore.Datastore
+	config ForksConfig
+	now    func() time.Time
+
+	lock  sync.Mutex
+	forks map[string]*datastoreFork
+}
+
+// datastoreFork is a fork of the datastore, along with the pin of the revision it was forked at,
+// if the datastore supports pinning revisions.
+type datastoreFork struct {
+	id        string
+	ds        datastore.Datastore
+	forkedAt  datastore.Revision
+	createdAt time.Time
+	expiresAt time.Time
+	pinID     string
+}
+
+// NewForksServer creates an instance of the experimental forks server, forking the given
+// datastore.
+func NewForksServer(ds datastore.Datastore, config ForksConfig) ForksServer {
+	return &forksServer{
+		WithServiceSpecificInterceptors: shared.WithServiceSpecificInterceptors{
+			Unary:  grpcvalidate.UnaryServerInterceptor(),
+			Stream: grpcvalidate.StreamServerInterceptor(),
+		},
+		base: ds,
+		config: ForksConfig{
+			MaxTTL:   defaultIfZero(config.MaxTTL, 24*time.Hour),
+			MaxForks: defaultIfZero(config.MaxForks, 16),
+		},
+		now:   time.Now,
+		forks: make(map[string]*datastoreFork),
+	}
+}
+
+// CreateFork forks the datastore at the revision selected by the consistency of the request. If
+// the datastore supports it, the revision is pinned until the fork expires, so that it is not
+// garbage collected while the fork reads it.
+func (fs *forksServer) CreateFork(ctx context.Context, req *forksv1.CreateForkRequest) (*forksv1.CreateForkResponse, error) {
+	if forkIDFromContext(ctx) != "" {
+		return nil, status.Errorf(codes.FailedPrecondition, "forks cannot be forked")
+	}
+
+	ttl := fs.config.MaxTTL
+	if req.OptionalTtl != nil {
+		ttl = min(req.OptionalTtl.AsDuration(), fs.config.MaxTTL)
+		if ttl <= 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "the TTL of a fork must be positive")
+		}
+	}
+
+	forkedAt, _, err := consistency.RevisionFromContext(ctx)
+	if err != nil {
+		return nil, shared.RewriteErrorWithoutConfig(ctx, err)
+	}
+
+	fs.lock.Lock()
+	fs.pruneLocked(ctx)
+	count := len(fs.forks)
+	fs.lock.Unlock()
+	if count >= int(fs.config.MaxForks) {
+		return nil, status.Errorf(codes.ResourceExhausted, "too many forks exist: at most %d can exist at once", fs.config.MaxForks)
+	}
+
+	now := fs.now()
+	f := &datastoreFork{
+		id:        uuid.NewString(),
+		forkedAt:  forkedAt,
+		createdAt: now,
+		expiresAt: now.Add(ttl),
+	}
+
+	if pinner := datastore.UnwrapAs[datastore.RevisionPinner](fs.base); pinner != nil {
+		f.pinID, err = pinner.PinRevision(ctx, forkedAt, f.expiresAt)
+		if err != nil {
+			return nil, shared.RewriteErrorWithoutConfig(ctx, err)
+		}
+	}
+
+	f.ds, err = fork.New(ctx, f.id, fs.base, forkedAt)
+	if err != nil {
+		_ = fs.discard(ctx, f)
+		return nil, shared.RewriteErrorWithoutConfig(ctx, err)
+	}
+
+	fs.lock.Lock()
+	fs.forks[f.id] = f
+	fs.lock.Unlock()
+
+	log.Ctx(ctx).Info().Str("fork", f.id).Stringer("forked_at", forkedAt).Time("expires_at", f.expiresAt).Msg("created datastore fork")
+	return &forksv1.CreateForkResponse{Fork: forkProto(f)}, nil
+}
+
+// GetFork returns the fork.
+func (fs *forksServer) GetFork(ctx context.Context, req *forksv1.GetForkRequest) (*forksv1.GetForkResponse, error) {
+	fs.lock.Lock()
+	defer fs.lock.Unlock()
+
+	fs.pruneLocked(ctx)
+	f, ok := fs.forks[req.ForkId]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "fork `%s` not found", req.ForkId)
+	}
+	return &forksv1.GetForkResponse{Fork: forkProto(f)}, nil
+}
+
+// DeleteFork discards the fork and everything written to it.
+func (fs *forksServer) DeleteFork(ctx context.Context, req *forksv1.DeleteForkRequest) (*forksv1.DeleteForkResponse, error) {
+	fs.lock.Lock()
+	f, ok := fs.forks[req.ForkId]
+	delete(fs.forks, req.ForkId)
+	fs.lock.Unlock()
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "fork `%s` not found", req.ForkId)
+	}
+
+	_ = fs.discard(ctx, f)
+	return &forksv1.DeleteForkResponse{}, nil
+}
+
+// Close discards all the forks.
+func (fs *forksServer) Close() error {
+	fs.lock.Lock()
+	defer fs.lock.Unlock()
+
+	var errs []error
+	for id, f := range fs.forks {
+		errs = append(errs, fs.discard(context.Background(), f))
+		delete(fs.forks, id)
+	}
+	return errors.Join(errs...)
+}
+
+func (fs *forksServer) lookup(forkID string) (datastore.Datastore, error) {
+	fs.lock.Lock()
+	defer fs.lock.Unlock()
+
+	f, ok := fs.forks[forkID]
+	if !ok || !fs.now().Before(f.expiresAt) {
+		return nil, status.Errorf(codes.NotFound, "fork `%s` not found", forkID)
+	}
+	return f.ds, nil
+}
+
+// pruneLocked discards the expired forks.
+func (fs *forksServer) pruneLocked(ctx context.Context) {
+	now := fs.now()
+	for id, f := range fs.forks {
+		if now.Before(f.expiresAt) {
+			continue
+		}
+
+		delete(fs.forks, id)
+		_ = fs.discard(ctx, f)
+	}
+}
+
+// discard closes the fork and unpins the revision it was forked at.
+func (fs *forksServer) discard(ctx context.Context, f *datastoreFork) error {
+	var errs []error
+	if f.ds != nil {
+		errs = append(errs, f.ds.Close())
+	}
+
+	if f.pinID != "" {
+		if pinner := datastore.UnwrapAs[datastore.RevisionPinner](fs.base); pinner != nil {
+			errs = append(errs, pinner.UnpinRevision(ctx, f.pinID))
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		log.Ctx(ctx).Warn().Err(err).Str("fork", f.id).Msg("unable to discard datastore fork")
+		return err
+	}
+	return nil
+}
+
+func forkProto(f *datastoreFork) *forksv1.Fork {
+	return &forksv1.Fork{
+		ForkId:    f.id,
+		ForkedAt:  zedtoken.MustNewFromRevision(f.forkedAt),
+		CreatedAt: timestamppb.New(f.createdAt),
+		ExpiresAt: timestamppb.New(f.expiresAt),
+	}
+}
+
+// forkIDFromContext returns the ID of the fork from which the request is served, if any.
+func forkIDFromContext(ctx context.Context) string {
+	values := metadata.ValueFromIncomingContext(ctx, string(spicedbrequestmeta.RequestFork))
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// withFork replaces the datastore of the request by the fork from which it is served, if any. The
+// forks server is nil when forks are not enabled, in which case requests to a fork are rejected
+// rather than served from the datastore itself.
+func withFork(ctx context.Context, forks ForksServer) error {
+	forkID := forkIDFromContext(ctx)
+	if forkID == "" {
+		return nil
+	}
+
+	if forks == nil {
+		return status.Errorf(codes.FailedPrecondition, "the request is for fork `%s`, but forks are not enabled", forkID)
+	}
+
+	ds, err := forks.lookup(forkID)
+	if err != nil {
+		return err
+	}
+	return datastoremw.SetInContext(ctx, ds)
+}
+
+// ForksUnaryServerInterceptor returns a new unary server interceptor serving requests from the
+// forks set in their headers. It must run after the datastore middleware, whose datastore it
+// replaces.
+func ForksUnaryServerInterceptor(forks ForksServer) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := withFork(ctx, forks); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// ForksStreamServerInterceptor returns a new stream server interceptor serving requests from the
+// forks set in their headers. It must run after the datastore middleware, whose datastore it
+// replaces.
+func ForksStreamServerInterceptor(forks ForksServer) grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := withFork(stream.Context(), forks); err != nil {
+			return err
+		}
+		return handler(srv, middleware.WrapServerStream(stream))
+	}
+}
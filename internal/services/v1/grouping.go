@@ -20,6 +20,7 @@ type groupingParameters struct {
 	maximumAPIDepth      uint32
 	maxCaveatContextSize int
 	withTracing          bool
+	prefilterCaveats     bool
 }
 
 // groupItems takes a slice of CheckBulkPermissionsRequestItem and groups them based
@@ -62,11 +63,12 @@ func checkParametersFromCheckBulkPermissionsRequestItem(
 	}
 
 	return &computed.CheckParameters{
-		ResourceType:  tuple.RR(bc.Resource.ObjectType, bc.Permission),
-		Subject:       tuple.ONR(bc.Subject.Object.ObjectType, bc.Subject.Object.ObjectId, normalizeSubjectRelation(bc.Subject)),
-		CaveatContext: caveatContext,
-		AtRevision:    params.atRevision,
-		MaximumDepth:  params.maximumAPIDepth,
-		DebugOption:   debugOption,
+		ResourceType:     tuple.RR(bc.Resource.ObjectType, bc.Permission),
+		Subject:          tuple.ONR(bc.Subject.Object.ObjectType, bc.Subject.Object.ObjectId, normalizeSubjectRelation(bc.Subject)),
+		CaveatContext:    caveatContext,
+		AtRevision:       params.atRevision,
+		MaximumDepth:     params.maximumAPIDepth,
+		DebugOption:      debugOption,
+		PrefilterCaveats: params.prefilterCaveats,
 	}
 }
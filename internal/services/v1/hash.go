@@ -4,9 +4,11 @@ import (
 	"strconv"
 
 	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/structpb"
 
 	"github.com/authzed/spicedb/pkg/caveats"
+	expansionv1 "github.com/authzed/spicedb/pkg/proto/expansion/v1"
 	"github.com/authzed/spicedb/pkg/spiceerrors"
 	"github.com/authzed/spicedb/pkg/tuple"
 )
@@ -66,6 +68,27 @@ func computeLRRequestHash(req *v1.LookupResourcesRequest) (string, error) {
 	})
 }
 
+func computeExpandWithLimitsRequestHash(req *expansionv1.ExpandPermissionTreeWithLimitsRequest) (string, error) {
+	return computeCallHash("expansion.v1.expandpermissiontreewithlimits", req.Consistency, map[string]any{
+		"resource-type": req.Resource.ObjectType,
+		"resource-id":   req.Resource.ObjectId,
+		"permission":    req.Permission,
+		"max-depth":     req.MaxDepth,
+		"limit":         req.OptionalLimit,
+	})
+}
+
+func computeWriteRelationshipsRequestHash(req *v1.WriteRelationshipsRequest) (string, error) {
+	requestBytes, err := proto.MarshalOptions{Deterministic: true}.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+
+	return computeAPICallHash("v1.writerelationships", map[string]string{
+		"request": string(requestBytes),
+	})
+}
+
 func computeCallHash(apiName string, consistency *v1.Consistency, arguments map[string]any) (string, error) {
 	stringArguments := make(map[string]string, len(arguments)+1)
 
@@ -4,21 +4,31 @@
 package v1
 
 import (
+	"crypto/sha256"
 	"fmt"
+	"hash"
+	"io"
 	"sort"
 
 	"github.com/cespare/xxhash/v2"
 	"golang.org/x/exp/maps"
+
+	"github.com/authzed/spicedb/pkg/fips"
 )
 
 func computeAPICallHash(apiName string, arguments map[string]string) (string, error) {
-	hasher := xxhash.New()
-	_, err := hasher.WriteString(apiName)
+	// xxhash is not a FIPS-approved hash function, so SHA-256 is used in FIPS mode, as in WASM.
+	var hasher hash.Hash = xxhash.New()
+	if fips.Enabled() {
+		hasher = sha256.New()
+	}
+
+	_, err := io.WriteString(hasher, apiName)
 	if err != nil {
 		return "", err
 	}
 
-	_, err = hasher.WriteString(":")
+	_, err = io.WriteString(hasher, ":")
 	if err != nil {
 		return "", err
 	}
@@ -27,22 +37,22 @@ func computeAPICallHash(apiName string, arguments map[string]string) (string, er
 	sort.Strings(keys)
 
 	for _, key := range keys {
-		_, err = hasher.WriteString(key)
+		_, err = io.WriteString(hasher, key)
 		if err != nil {
 			return "", err
 		}
 
-		_, err = hasher.WriteString(":")
+		_, err = io.WriteString(hasher, ":")
 		if err != nil {
 			return "", err
 		}
 
-		_, err = hasher.WriteString(arguments[key])
+		_, err = io.WriteString(hasher, arguments[key])
 		if err != nil {
 			return "", err
 		}
 
-		_, err = hasher.WriteString(";")
+		_, err = io.WriteString(hasher, ";")
 		if err != nil {
 			return "", err
 		}
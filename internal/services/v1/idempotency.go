@@ -0,0 +1,278 @@
+package v1
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+	"resenje.org/singleflight"
+
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/requestmeta"
+)
+
+// IdempotencyKeyMetadataKey is the key in the transaction metadata under which the idempotency
+// key of a write is recorded, so that the writes of retried requests can be identified on Watch.
+const IdempotencyKeyMetadataKey = "spicedb_idempotency_key"
+
+// idempotencyRecordPrefix is the prefix of the keys of the records claiming idempotency keys,
+// followed by the hex-encoded SHA-256 hash of the idempotency key.
+const idempotencyRecordPrefix = "idempotency_key/"
+
+// idempotentWriteAppliedError is returned from the transaction of a write whose idempotency key
+// was already claimed by a completed write of the same request, such that the write is not
+// applied again, with the revision of that write if it was recorded in the claim.
+type idempotentWriteAppliedError struct {
+	revision string
+}
+
+func (err idempotentWriteAppliedError) Error() string {
+	return "a write with the idempotency key was already applied"
+}
+
+// errIdempotentWriteInProgress is returned from the transaction of a write whose idempotency key
+// was claimed by a chunked write of the same request which is still being applied.
+var errIdempotentWriteInProgress = status.Error(codes.Aborted, "a write with the idempotency key is in progress; retry the write")
+
+// idempotencyKeyFromContext returns the idempotency key of a WriteRelationships request, from its
+// headers.
+func idempotencyKeyFromContext(ctx context.Context) (string, bool) {
+	values := metadata.ValueFromIncomingContext(ctx, string(requestmeta.RequestIdempotencyKey))
+	if len(values) == 0 || values[0] == "" {
+		return "", false
+	}
+	return values[0], true
+}
+
+// withIdempotencyKey returns a copy of the given transaction metadata with the idempotency key
+// added under IdempotencyKeyMetadataKey, overwriting any caller-supplied value.
+func withIdempotencyKey(transactionMetadata *structpb.Struct, idempotencyKey string) *structpb.Struct {
+	fields := make(map[string]*structpb.Value, len(transactionMetadata.GetFields())+1)
+	for key, value := range transactionMetadata.GetFields() {
+		fields[key] = value
+	}
+	fields[IdempotencyKeyMetadataKey] = structpb.NewStringValue(idempotencyKey)
+	return &structpb.Struct{Fields: fields}
+}
+
+// idempotencyClaim is the claim of the idempotency key of a write, recorded in the datastore
+// within the transaction of the write, so that retries of the write are not applied again
+// whichever node they reach, including after a restart.
+type idempotencyClaim struct {
+	idempotencyKey string
+	requestHash    string
+	ttl            time.Duration
+}
+
+// idempotencyRecord is the value of the record claiming an idempotency key.
+type idempotencyRecord struct {
+	RequestHash string `json:"requestHash"`
+
+	// Pending is whether the key is claimed by a chunked write which is not yet complete.
+	Pending bool `json:"pending,omitempty"`
+
+	// Revision is the revision at which the write was committed, recorded once it has been.
+	Revision string `json:"revision,omitempty"`
+}
+
+func (c *idempotencyClaim) recordKey() string {
+	hash := sha256.Sum256([]byte(c.idempotencyKey))
+	return idempotencyRecordPrefix + hex.EncodeToString(hash[:])
+}
+
+// claim claims the idempotency key within the transaction of the write, pending if the write is
+// chunked and thus not complete once the transaction commits. It fails with
+// an idempotentWriteAppliedError if the key was claimed by a completed write of the same request, with
+// errIdempotentWriteInProgress if it is claimed by a write of the same request in progress, and
+// with an IdempotencyKeyReusedError if it was claimed by another request. A nil claim claims
+// nothing.
+func (c *idempotencyClaim) claim(ctx context.Context, rwt datastore.ReadWriteTransaction, pending bool) error {
+	if c == nil {
+		return nil
+	}
+
+	key := c.recordKey()
+	records, err := rwt.ReadRecords(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, record := range records {
+		if record.Key != key || record.Expired(now) {
+			continue
+		}
+
+		var existing idempotencyRecord
+		if err := json.Unmarshal(record.Value, &existing); err != nil {
+			return fmt.Errorf("invalid idempotency key record: %w", err)
+		}
+
+		switch {
+		case existing.RequestHash != c.requestHash:
+			return NewIdempotencyKeyReusedErr(c.idempotencyKey)
+		case existing.Pending:
+			return errIdempotentWriteInProgress
+		default:
+			return idempotentWriteAppliedError{revision: existing.Revision}
+		}
+	}
+
+	return c.write(ctx, rwt, idempotencyRecord{RequestHash: c.requestHash, Pending: pending})
+}
+
+// complete marks the claim of a chunked write as complete, within the transaction of its last
+// chunk.
+func (c *idempotencyClaim) complete(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+	if c == nil {
+		return nil
+	}
+	return c.write(ctx, rwt, idempotencyRecord{RequestHash: c.requestHash})
+}
+
+// recordRevision records the revision at which the write claiming the idempotency key was
+// committed in its claim, in a transaction of its own, so that the retries of the write which
+// reach another node, or this one after a restart, return it. Retries which find the claim
+// before its revision is recorded return the head revision instead.
+func (c *idempotencyClaim) recordRevision(ctx context.Context, ds datastore.Datastore, revision datastore.Revision) error {
+	if c == nil {
+		return nil
+	}
+
+	_, err := ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		return c.write(ctx, rwt, idempotencyRecord{RequestHash: c.requestHash, Revision: revision.String()})
+	})
+	return err
+}
+
+func (c *idempotencyClaim) write(ctx context.Context, rwt datastore.ReadWriteTransaction, record idempotencyRecord) error {
+	value, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	return rwt.WriteRecords(ctx, datastore.Record{
+		Key:       c.recordKey(),
+		Value:     value,
+		ExpiresAt: time.Now().Add(c.ttl),
+	})
+}
+
+// idempotentWrites caches the results of the successful WriteRelationships calls with an
+// idempotency key, for a limited duration, so that retries of the calls reaching this node return
+// the revision of the original write without a transaction. The keys are claimed in the
+// datastore by the writes themselves (see idempotencyClaim), which is what ensures a write is
+// applied once; the cache only saves the transaction of a retry.
+type idempotentWrites struct {
+	ttl     time.Duration
+	maxKeys int
+	now     func() time.Time
+
+	// inflight ensures that concurrent calls with the same key, such as a retry of a call which
+	// is still being applied, are only applied once.
+	inflight singleflight.Group[string, idempotentWrite]
+
+	lock    sync.Mutex
+	written map[string]idempotentWrite
+	// order holds the written keys in the order they were written, and thus expire.
+	order []idempotencyKeyExpiration
+}
+
+type idempotentWrite struct {
+	requestHash string
+	writtenAt   *v1.ZedToken
+	expiresAt   time.Time
+}
+
+type idempotencyKeyExpiration struct {
+	key       string
+	expiresAt time.Time
+}
+
+func newIdempotentWrites(ttl time.Duration, maxKeys int) *idempotentWrites {
+	return &idempotentWrites{
+		ttl:     ttl,
+		maxKeys: maxKeys,
+		now:     time.Now,
+		written: make(map[string]idempotentWrite),
+	}
+}
+
+// write applies the write for the idempotency key, unless a write is cached for it, in which case
+// the revision of that write is returned. It fails if the key was used for another request.
+func (iw *idempotentWrites) write(
+	ctx context.Context,
+	idempotencyKey string,
+	requestHash string,
+	write func(ctx context.Context) (*v1.WriteRelationshipsResponse, error),
+) (*v1.WriteRelationshipsResponse, error) {
+	result, _, err := iw.inflight.Do(ctx, idempotencyKey, func(ctx context.Context) (idempotentWrite, error) {
+		if existing, ok := iw.lookup(idempotencyKey); ok {
+			return existing, nil
+		}
+
+		resp, err := write(ctx)
+		if err != nil {
+			return idempotentWrite{}, err
+		}
+
+		return iw.record(idempotencyKey, requestHash, resp.WrittenAt), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if result.requestHash != requestHash {
+		return nil, NewIdempotencyKeyReusedErr(idempotencyKey)
+	}
+
+	return &v1.WriteRelationshipsResponse{WrittenAt: result.writtenAt}, nil
+}
+
+func (iw *idempotentWrites) lookup(idempotencyKey string) (idempotentWrite, bool) {
+	iw.lock.Lock()
+	defer iw.lock.Unlock()
+
+	iw.pruneLocked()
+	existing, ok := iw.written[idempotencyKey]
+	return existing, ok
+}
+
+func (iw *idempotentWrites) record(idempotencyKey string, requestHash string, writtenAt *v1.ZedToken) idempotentWrite {
+	iw.lock.Lock()
+	defer iw.lock.Unlock()
+
+	written := idempotentWrite{
+		requestHash: requestHash,
+		writtenAt:   writtenAt,
+		expiresAt:   iw.now().Add(iw.ttl),
+	}
+	iw.written[idempotencyKey] = written
+	iw.order = append(iw.order, idempotencyKeyExpiration{key: idempotencyKey, expiresAt: written.expiresAt})
+	iw.pruneLocked()
+	return written
+}
+
+// pruneLocked forgets the expired keys, and the oldest keys over the maximum number of keys.
+func (iw *idempotentWrites) pruneLocked() {
+	now := iw.now()
+	for len(iw.order) > 0 && (len(iw.order) > iw.maxKeys || !iw.order[0].expiresAt.After(now)) {
+		oldest := iw.order[0]
+		iw.order[0] = idempotencyKeyExpiration{}
+		iw.order = iw.order[1:]
+
+		// The key may have been written again after it expired.
+		if current, ok := iw.written[oldest.key]; ok && current.expiresAt.Equal(oldest.expiresAt) {
+			delete(iw.written, oldest.key)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotentWrites(t *testing.T) {
+	now := time.Now()
+	writes := newIdempotentWrites(time.Minute, 2)
+	writes.now = func() time.Time { return now }
+
+	writeCount := 0
+	write := func(token string) func(context.Context) (*v1.WriteRelationshipsResponse, error) {
+		return func(context.Context) (*v1.WriteRelationshipsResponse, error) {
+			writeCount++
+			return &v1.WriteRelationshipsResponse{WrittenAt: &v1.ZedToken{Token: token}}, nil
+		}
+	}
+
+	ctx := context.Background()
+	resp, err := writes.write(ctx, "key1", "hash1", write("first"))
+	require.NoError(t, err)
+	require.Equal(t, "first", resp.WrittenAt.Token)
+
+	// A retry returns the revision of the first write without writing.
+	resp, err = writes.write(ctx, "key1", "hash1", write("second"))
+	require.NoError(t, err)
+	require.Equal(t, "first", resp.WrittenAt.Token)
+	require.Equal(t, 1, writeCount)
+
+	// The key cannot be used for another request.
+	_, err = writes.write(ctx, "key1", "hash2", write("third"))
+	var reusedErr IdempotencyKeyReusedError
+	require.ErrorAs(t, err, &reusedErr)
+	require.Equal(t, 1, writeCount)
+
+	// Failed writes are not remembered.
+	_, err = writes.write(ctx, "key2", "hash1", func(context.Context) (*v1.WriteRelationshipsResponse, error) {
+		return nil, errors.New("failed")
+	})
+	require.Error(t, err)
+	resp, err = writes.write(ctx, "key2", "hash1", write("fourth"))
+	require.NoError(t, err)
+	require.Equal(t, "fourth", resp.WrittenAt.Token)
+	require.Equal(t, 2, writeCount)
+
+	// The oldest keys are forgotten over the maximum number of keys.
+	_, err = writes.write(ctx, "key3", "hash1", write("fifth"))
+	require.NoError(t, err)
+	resp, err = writes.write(ctx, "key1", "hash1", write("sixth"))
+	require.NoError(t, err)
+	require.Equal(t, "sixth", resp.WrittenAt.Token)
+	require.Equal(t, 4, writeCount)
+
+	// Keys are forgotten after the TTL.
+	now = now.Add(time.Minute)
+	resp, err = writes.write(ctx, "key3", "hash1", write("seventh"))
+	require.NoError(t, err)
+	require.Equal(t, "seventh", resp.WrittenAt.Token)
+	require.Equal(t, 5, writeCount)
+}
@@ -0,0 +1,427 @@
+package v1
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/google/uuid"
+	grpcvalidate "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/validator"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protodelim"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/authzed/spicedb/internal/dispatch"
+	log "github.com/authzed/spicedb/internal/logging"
+	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
+	"github.com/authzed/spicedb/internal/services/shared"
+	"github.com/authzed/spicedb/pkg/middleware/consistency"
+	"github.com/authzed/spicedb/pkg/middleware/priority"
+	lookupjobsv1 "github.com/authzed/spicedb/pkg/proto/lookupjobs/v1"
+)
+
+// lookupJobResultsPerResponse is the maximum number of results sent in a single
+// DownloadLookupJobResults response.
+const lookupJobResultsPerResponse = 1_000
+
+// LookupJobsConfig is the configuration of the experimental lookup jobs server.
+type LookupJobsConfig struct {
+	// Directory is the directory in which the results of jobs are materialized. Defaults to the
+	// temporary directory of the system.
+	Directory string
+
+	// Retention is the duration for which done jobs and their results are kept. Defaults to an
+	// hour.
+	Retention time.Duration
+
+	// MaxRunningJobs is the maximum number of jobs running at once, beyond which submissions are
+	// rejected. Defaults to 4.
+	MaxRunningJobs uint16
+}
+
+// LookupJobsServer is the experimental lookup jobs server, which must be closed to stop its jobs
+// and delete their results.
+type LookupJobsServer interface {
+	lookupjobsv1.LookupJobsServiceServer
+	io.Closer
+}
+
+type lookupJobsServer struct {
+	lookupjobsv1.UnimplementedLookupJobsServiceServer
+	shared.WithServiceSpecificInterceptors
+
+	permissions v1.PermissionsServiceServer
+	config      LookupJobsConfig
+	now         func() time.Time
+
+	// jobsCtx is the context of the jobs, canceled when the server is closed.
+	jobsCtx    context.Context
+	cancelJobs context.CancelFunc
+	running    sync.WaitGroup
+
+	lock         sync.Mutex
+	jobs         map[string]*lookupJob
+	runningCount int
+}
+
+// lookupJob is a job running a LookupResources or LookupSubjects query, whose results are written
+// to a file as length-delimited responses of the query.
+type lookupJob struct {
+	id             string
+	path           string
+	lookupSubjects bool
+	lookedUpAt     *v1.ZedToken
+	submittedAt    time.Time
+	cancel         context.CancelFunc
+	resultCount    atomic.Uint64
+
+	// done is closed once the job is done.
+	done chan struct{}
+
+	// Guarded by the lock of the server.
+	state  lookupjobsv1.LookupJob_State
+	doneAt time.Time
+	err    error
+}
+
+// NewLookupJobsServer creates an instance of the experimental lookup jobs server, running the
+// queries of jobs with a permissions server created with the given dispatcher and configuration.
+func NewLookupJobsServer(dispatch dispatch.Dispatcher, permSysConfig PermissionsServerConfig, config LookupJobsConfig) LookupJobsServer {
+	if config.Directory == "" {
+		config.Directory = os.TempDir()
+	}
+
+	jobsCtx, cancelJobs := context.WithCancel(context.Background())
+	return &lookupJobsServer{
+		WithServiceSpecificInterceptors: shared.WithServiceSpecificInterceptors{
+			Unary:  grpcvalidate.UnaryServerInterceptor(),
+			Stream: grpcvalidate.StreamServerInterceptor(),
+		},
+		permissions: NewPermissionsServer(dispatch, permSysConfig),
+		config: LookupJobsConfig{
+			Directory:      config.Directory,
+			Retention:      defaultIfZero(config.Retention, time.Hour),
+			MaxRunningJobs: defaultIfZero(config.MaxRunningJobs, 4),
+		},
+		now:        time.Now,
+		jobsCtx:    jobsCtx,
+		cancelJobs: cancelJobs,
+		jobs:       make(map[string]*lookupJob),
+	}
+}
+
+// SubmitLookupJob selects the revision of the query and starts a job running it in the background.
+// Jobs run at the batch priority, unless the request specifies another.
+func (ljs *lookupJobsServer) SubmitLookupJob(ctx context.Context, req *lookupjobsv1.SubmitLookupJobRequest) (*lookupjobsv1.SubmitLookupJobResponse, error) {
+	var query interface {
+		proto.Message
+		GetConsistency() *v1.Consistency
+	}
+	switch q := req.Query.(type) {
+	case *lookupjobsv1.SubmitLookupJobRequest_LookupResources:
+		if q.LookupResources.OptionalLimit > 0 || q.LookupResources.OptionalCursor != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "lookup jobs materialize all the results of their query, which must not have a limit or cursor")
+		}
+		query = q.LookupResources
+	case *lookupjobsv1.SubmitLookupJobRequest_LookupSubjects:
+		query = q.LookupSubjects
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "a LookupResources or LookupSubjects query is required")
+	}
+
+	ds := datastoremw.MustFromContext(ctx)
+	jobCtx := datastoremw.ContextWithDatastore(ljs.jobsCtx, ds)
+	jobCtx = priority.ContextWithClass(jobCtx, priority.Batch)
+	jobCtx = priority.PropagateIfExists(ctx, jobCtx)
+	jobCtx = log.Ctx(ctx).WithContext(jobCtx)
+	jobCtx = consistency.ContextWithHandle(jobCtx)
+	if err := consistency.AddRevisionToContext(jobCtx, query, ds, ""); err != nil {
+		return nil, shared.RewriteErrorWithoutConfig(ctx, err)
+	}
+
+	_, lookedUpAt, err := consistency.RevisionFromContext(jobCtx)
+	if err != nil {
+		return nil, shared.RewriteErrorWithoutConfig(ctx, err)
+	}
+
+	file, err := os.CreateTemp(ljs.config.Directory, "spicedb-lookup-job-*")
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "unable to create the results file of the job: %s", err)
+	}
+
+	jobCtx, cancel := context.WithCancel(jobCtx)
+	job := &lookupJob{
+		id:             uuid.NewString(),
+		path:           file.Name(),
+		lookupSubjects: req.GetLookupSubjects() != nil,
+		lookedUpAt:     lookedUpAt,
+		submittedAt:    ljs.now(),
+		cancel:         cancel,
+		done:           make(chan struct{}),
+		state:          lookupjobsv1.LookupJob_STATE_RUNNING,
+	}
+
+	ljs.lock.Lock()
+	ljs.pruneLocked()
+	if ljs.runningCount >= int(ljs.config.MaxRunningJobs) {
+		ljs.lock.Unlock()
+		cancel()
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+		return nil, status.Errorf(codes.ResourceExhausted, "too many lookup jobs are running: at most %d can run at once", ljs.config.MaxRunningJobs)
+	}
+	ljs.jobs[job.id] = job
+	ljs.runningCount++
+	ljs.running.Add(1)
+	ljs.lock.Unlock()
+
+	go ljs.run(jobCtx, job, req, file)
+
+	return &lookupjobsv1.SubmitLookupJobResponse{Job: ljs.jobProto(job)}, nil
+}
+
+// run runs the query of the job, writing its results to the file.
+func (ljs *lookupJobsServer) run(ctx context.Context, job *lookupJob, req *lookupjobsv1.SubmitLookupJobRequest, file *os.File) {
+	defer ljs.running.Done()
+
+	writer := bufio.NewWriter(file)
+	var err error
+	switch q := req.Query.(type) {
+	case *lookupjobsv1.SubmitLookupJobRequest_LookupResources:
+		err = ljs.permissions.LookupResources(q.LookupResources, &lookupJobResultsWriter[v1.LookupResourcesResponse]{ctx: ctx, writer: writer, count: &job.resultCount})
+	case *lookupjobsv1.SubmitLookupJobRequest_LookupSubjects:
+		err = ljs.permissions.LookupSubjects(q.LookupSubjects, &lookupJobResultsWriter[v1.LookupSubjectsResponse]{ctx: ctx, writer: writer, count: &job.resultCount})
+	}
+	if err == nil {
+		err = writer.Flush()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Str("job", job.id).Msg("lookup job failed")
+	}
+
+	ljs.lock.Lock()
+	defer ljs.lock.Unlock()
+	ljs.runningCount--
+	job.doneAt = ljs.now()
+	job.err = err
+	job.state = lookupjobsv1.LookupJob_STATE_COMPLETED
+	if err != nil {
+		job.state = lookupjobsv1.LookupJob_STATE_FAILED
+	}
+	close(job.done)
+}
+
+// GetLookupJob returns the current state of the job.
+func (ljs *lookupJobsServer) GetLookupJob(_ context.Context, req *lookupjobsv1.GetLookupJobRequest) (*lookupjobsv1.GetLookupJobResponse, error) {
+	job, err := ljs.job(req.JobId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &lookupjobsv1.GetLookupJobResponse{Job: ljs.jobProto(job)}, nil
+}
+
+// WatchLookupJob sends the current state of the job, and its final state once it is done.
+func (ljs *lookupJobsServer) WatchLookupJob(req *lookupjobsv1.WatchLookupJobRequest, stream lookupjobsv1.LookupJobsService_WatchLookupJobServer) error {
+	job, err := ljs.job(req.JobId)
+	if err != nil {
+		return err
+	}
+
+	current := ljs.jobProto(job)
+	if err := stream.Send(&lookupjobsv1.WatchLookupJobResponse{Job: current}); err != nil {
+		return err
+	}
+	if current.State != lookupjobsv1.LookupJob_STATE_RUNNING {
+		return nil
+	}
+
+	select {
+	case <-job.done:
+		return stream.Send(&lookupjobsv1.WatchLookupJobResponse{Job: ljs.jobProto(job)})
+	case <-stream.Context().Done():
+		return status.FromContextError(stream.Context().Err()).Err()
+	}
+}
+
+// DownloadLookupJobResults streams the results of the completed job from the offset.
+func (ljs *lookupJobsServer) DownloadLookupJobResults(req *lookupjobsv1.DownloadLookupJobResultsRequest, stream lookupjobsv1.LookupJobsService_DownloadLookupJobResultsServer) error {
+	job, err := ljs.job(req.JobId)
+	if err != nil {
+		return err
+	}
+
+	ljs.lock.Lock()
+	state := job.state
+	ljs.lock.Unlock()
+	if state != lookupjobsv1.LookupJob_STATE_COMPLETED {
+		return status.Errorf(codes.FailedPrecondition, "lookup job `%s` is not completed", job.id)
+	}
+
+	file, err := os.Open(job.path)
+	if err != nil {
+		return status.Errorf(codes.NotFound, "results of lookup job `%s` are no longer available", job.id)
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	offset := uint64(0)
+	resp := &lookupjobsv1.DownloadLookupJobResultsResponse{}
+	for {
+		var result proto.Message = &v1.LookupResourcesResponse{}
+		if job.lookupSubjects {
+			result = &v1.LookupSubjectsResponse{}
+		}
+
+		err := protodelim.UnmarshalFrom(reader, result)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return status.Errorf(codes.Internal, "unable to read the results of lookup job `%s`: %s", job.id, err)
+		}
+
+		offset++
+		if offset <= req.OptionalOffset {
+			continue
+		}
+
+		switch result := result.(type) {
+		case *v1.LookupResourcesResponse:
+			resp.LookupResourcesResults = append(resp.LookupResourcesResults, result)
+		case *v1.LookupSubjectsResponse:
+			resp.LookupSubjectsResults = append(resp.LookupSubjectsResults, result)
+		}
+
+		if len(resp.LookupResourcesResults)+len(resp.LookupSubjectsResults) == lookupJobResultsPerResponse {
+			resp.NextOffset = offset
+			if err := stream.Send(resp); err != nil {
+				return err
+			}
+			resp = &lookupjobsv1.DownloadLookupJobResultsResponse{}
+		}
+	}
+
+	if len(resp.LookupResourcesResults)+len(resp.LookupSubjectsResults) == 0 {
+		return nil
+	}
+
+	resp.NextOffset = offset
+	return stream.Send(resp)
+}
+
+// DeleteLookupJob cancels the job if it is running, and deletes it along with its results.
+func (ljs *lookupJobsServer) DeleteLookupJob(_ context.Context, req *lookupjobsv1.DeleteLookupJobRequest) (*lookupjobsv1.DeleteLookupJobResponse, error) {
+	job, err := ljs.job(req.JobId)
+	if err != nil {
+		return nil, err
+	}
+
+	ljs.lock.Lock()
+	delete(ljs.jobs, job.id)
+	ljs.lock.Unlock()
+
+	job.cancel()
+	<-job.done
+	_ = os.Remove(job.path)
+
+	return &lookupjobsv1.DeleteLookupJobResponse{}, nil
+}
+
+// Close cancels the running jobs, waits for them to stop and deletes the results of all jobs.
+func (ljs *lookupJobsServer) Close() error {
+	ljs.cancelJobs()
+	ljs.running.Wait()
+
+	ljs.lock.Lock()
+	defer ljs.lock.Unlock()
+
+	var errs []error
+	for id, job := range ljs.jobs {
+		if err := os.Remove(job.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, err)
+		}
+		delete(ljs.jobs, id)
+	}
+	return errors.Join(errs...)
+}
+
+// job returns the job with the given ID, if it has not expired.
+func (ljs *lookupJobsServer) job(jobID string) (*lookupJob, error) {
+	ljs.lock.Lock()
+	defer ljs.lock.Unlock()
+
+	ljs.pruneLocked()
+	job, ok := ljs.jobs[jobID]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "lookup job `%s` not found", jobID)
+	}
+	return job, nil
+}
+
+// pruneLocked deletes the jobs done for longer than the retention, along with their results.
+func (ljs *lookupJobsServer) pruneLocked() {
+	now := ljs.now()
+	for id, job := range ljs.jobs {
+		if job.state == lookupjobsv1.LookupJob_STATE_RUNNING || now.Sub(job.doneAt) <= ljs.config.Retention {
+			continue
+		}
+
+		delete(ljs.jobs, id)
+		_ = os.Remove(job.path)
+	}
+}
+
+func (ljs *lookupJobsServer) jobProto(job *lookupJob) *lookupjobsv1.LookupJob {
+	ljs.lock.Lock()
+	defer ljs.lock.Unlock()
+
+	jobProto := &lookupjobsv1.LookupJob{
+		JobId:       job.id,
+		State:       job.state,
+		ResultCount: job.resultCount.Load(),
+		LookedUpAt:  job.lookedUpAt,
+		SubmittedAt: timestamppb.New(job.submittedAt),
+	}
+	if job.state != lookupjobsv1.LookupJob_STATE_RUNNING {
+		jobProto.DoneAt = timestamppb.New(job.doneAt)
+		jobProto.ExpiresAt = timestamppb.New(job.doneAt.Add(ljs.config.Retention))
+	}
+	if job.err != nil {
+		jobProto.Error = status.Convert(job.err).Proto()
+	}
+	return jobProto
+}
+
+// lookupJobResultsWriter is the stream of an in-process LookupResources or LookupSubjects call of
+// a job, writing each response to the results of the job as it is sent, since the server reuses
+// them.
+type lookupJobResultsWriter[T any] struct {
+	grpc.ServerStream
+
+	ctx    context.Context
+	writer *bufio.Writer
+	count  *atomic.Uint64
+}
+
+func (ljrw *lookupJobResultsWriter[T]) Context() context.Context { return ljrw.ctx }
+
+func (ljrw *lookupJobResultsWriter[T]) Send(result *T) error {
+	if _, err := protodelim.MarshalTo(ljrw.writer, any(result).(proto.Message)); err != nil {
+		return err
+	}
+	ljrw.count.Add(1)
+	return nil
+}
@@ -0,0 +1,173 @@
+package v1_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/authzed/grpcutil"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	tf "github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/internal/testserver"
+	lookupjobsv1 "github.com/authzed/spicedb/pkg/proto/lookupjobs/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+func TestLookupJobs(t *testing.T) {
+	require := require.New(t)
+	config := testserver.DefaultTestServerConfig
+	config.LookupJobsDirectory = t.TempDir()
+
+	conn, cleanup, _, _ := testserver.NewTestServerWithConfig(require, 0, memdb.DisableGC, true, config, tf.EmptyDatastore)
+	t.Cleanup(cleanup)
+
+	_, err := v1.NewSchemaServiceClient(conn).WriteSchema(context.Background(), &v1.WriteSchemaRequest{
+		Schema: `
+			definition user {}
+
+			definition document {
+				relation viewer: user
+				permission view = viewer
+			}
+		`,
+	})
+	require.NoError(err)
+
+	// Write enough relationships for the results to span several download responses.
+	const documentCount = 1_200
+	permissions := v1.NewPermissionsServiceClient(conn)
+	for start := 0; start < documentCount; start += 600 {
+		var updates []*v1.RelationshipUpdate
+		for i := start; i < start+600; i++ {
+			updates = append(updates, &v1.RelationshipUpdate{
+				Operation:    v1.RelationshipUpdate_OPERATION_CREATE,
+				Relationship: tuple.MustParseV1Rel(fmt.Sprintf("document:doc%d#viewer@user:alice", i)),
+			})
+		}
+		_, err = permissions.WriteRelationships(context.Background(), &v1.WriteRelationshipsRequest{Updates: updates})
+		require.NoError(err)
+	}
+
+	client := lookupjobsv1.NewLookupJobsServiceClient(conn)
+	lookupResources := &v1.LookupResourcesRequest{
+		Consistency:        &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}},
+		ResourceObjectType: "document",
+		Permission:         "view",
+		Subject:            &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: "user", ObjectId: "alice"}},
+	}
+
+	submitted, err := client.SubmitLookupJob(context.Background(), &lookupjobsv1.SubmitLookupJobRequest{
+		Query: &lookupjobsv1.SubmitLookupJobRequest_LookupResources{LookupResources: lookupResources},
+	})
+	require.NoError(err)
+	require.NotEmpty(submitted.Job.JobId)
+	require.NotNil(submitted.Job.LookedUpAt)
+	jobID := submitted.Job.JobId
+
+	watch, err := client.WatchLookupJob(context.Background(), &lookupjobsv1.WatchLookupJobRequest{JobId: jobID})
+	require.NoError(err)
+	var last *lookupjobsv1.LookupJob
+	for {
+		resp, err := watch.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(err)
+		last = resp.Job
+	}
+	require.Equal(lookupjobsv1.LookupJob_STATE_COMPLETED, last.State)
+	require.Equal(uint64(documentCount), last.ResultCount)
+	require.NotNil(last.DoneAt)
+	require.NotNil(last.ExpiresAt)
+
+	download := func(offset uint64) (map[string]struct{}, []uint64) {
+		stream, err := client.DownloadLookupJobResults(context.Background(), &lookupjobsv1.DownloadLookupJobResultsRequest{JobId: jobID, OptionalOffset: offset})
+		require.NoError(err)
+
+		resourceIDs := make(map[string]struct{})
+		var offsets []uint64
+		for {
+			resp, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				return resourceIDs, offsets
+			}
+			require.NoError(err)
+			require.Empty(resp.LookupSubjectsResults)
+			for _, result := range resp.LookupResourcesResults {
+				resourceIDs[result.ResourceObjectId] = struct{}{}
+			}
+			offsets = append(offsets, resp.NextOffset)
+		}
+	}
+
+	resourceIDs, offsets := download(0)
+	require.Len(resourceIDs, documentCount)
+	require.Equal([]uint64{1_000, documentCount}, offsets)
+
+	resourceIDs, offsets = download(1_100)
+	require.Len(resourceIDs, 100)
+	require.Equal([]uint64{documentCount}, offsets)
+
+	got, err := client.GetLookupJob(context.Background(), &lookupjobsv1.GetLookupJobRequest{JobId: jobID})
+	require.NoError(err)
+	require.Equal(lookupjobsv1.LookupJob_STATE_COMPLETED, got.Job.State)
+
+	_, err = client.DeleteLookupJob(context.Background(), &lookupjobsv1.DeleteLookupJobRequest{JobId: jobID})
+	require.NoError(err)
+
+	_, err = client.GetLookupJob(context.Background(), &lookupjobsv1.GetLookupJobRequest{JobId: jobID})
+	grpcutil.RequireStatus(t, codes.NotFound, err)
+
+	lookupResources.OptionalLimit = 10
+	_, err = client.SubmitLookupJob(context.Background(), &lookupjobsv1.SubmitLookupJobRequest{
+		Query: &lookupjobsv1.SubmitLookupJobRequest_LookupResources{LookupResources: lookupResources},
+	})
+	grpcutil.RequireStatus(t, codes.InvalidArgument, err)
+
+	_, err = client.SubmitLookupJob(context.Background(), &lookupjobsv1.SubmitLookupJobRequest{})
+	grpcutil.RequireStatus(t, codes.InvalidArgument, err)
+}
+
+func TestLookupJobsFailure(t *testing.T) {
+	require := require.New(t)
+	config := testserver.DefaultTestServerConfig
+	config.LookupJobsDirectory = t.TempDir()
+
+	conn, cleanup, _, _ := testserver.NewTestServerWithConfig(require, 0, memdb.DisableGC, true, config, tf.EmptyDatastore)
+	t.Cleanup(cleanup)
+
+	client := lookupjobsv1.NewLookupJobsServiceClient(conn)
+	submitted, err := client.SubmitLookupJob(context.Background(), &lookupjobsv1.SubmitLookupJobRequest{
+		Query: &lookupjobsv1.SubmitLookupJobRequest_LookupSubjects{LookupSubjects: &v1.LookupSubjectsRequest{
+			Resource:          &v1.ObjectReference{ObjectType: "document", ObjectId: "doc"},
+			Permission:        "view",
+			SubjectObjectType: "user",
+		}},
+	})
+	require.NoError(err)
+
+	watch, err := client.WatchLookupJob(context.Background(), &lookupjobsv1.WatchLookupJobRequest{JobId: submitted.Job.JobId})
+	require.NoError(err)
+	var last *lookupjobsv1.LookupJob
+	for {
+		resp, err := watch.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(err)
+		last = resp.Job
+	}
+	require.Equal(lookupjobsv1.LookupJob_STATE_FAILED, last.State)
+	require.Equal(int32(codes.FailedPrecondition), last.Error.Code)
+
+	download, err := client.DownloadLookupJobResults(context.Background(), &lookupjobsv1.DownloadLookupJobResultsRequest{JobId: submitted.Job.JobId})
+	require.NoError(err)
+	_, err = download.Recv()
+	grpcutil.RequireStatus(t, codes.FailedPrecondition, err)
+}
@@ -0,0 +1,287 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	grpcvalidate "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/validator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/authzed/spicedb/internal/dispatch"
+	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
+	"github.com/authzed/spicedb/internal/middleware/usagemetrics"
+	"github.com/authzed/spicedb/internal/namespace"
+	"github.com/authzed/spicedb/internal/services/shared"
+	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	dispatchv1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+	lookupwatchv1 "github.com/authzed/spicedb/pkg/proto/lookupwatch/v1"
+	"github.com/authzed/spicedb/pkg/watchhints"
+	"github.com/authzed/spicedb/pkg/zedtoken"
+)
+
+// maxLookupWatchChangesPerResponse is the maximum number of resource changes sent in a single
+// WatchLookupResources response.
+const maxLookupWatchChangesPerResponse = 1_000
+
+type lookupWatchServer struct {
+	lookupwatchv1.UnimplementedLookupWatchServiceServer
+	shared.WithStreamServiceSpecificInterceptor
+
+	dispatch          dispatch.Dispatcher
+	maximumAPIDepth   uint32
+	heartbeatDuration time.Duration
+}
+
+// NewLookupWatchServer creates an instance of the experimental lookup watch server.
+func NewLookupWatchServer(dispatch dispatch.Dispatcher, config PermissionsServerConfig, heartbeatDuration time.Duration) lookupwatchv1.LookupWatchServiceServer {
+	return &lookupWatchServer{
+		WithStreamServiceSpecificInterceptor: shared.WithStreamServiceSpecificInterceptor{
+			Stream: grpcvalidate.StreamServerInterceptor(),
+		},
+		dispatch:          dispatch,
+		maximumAPIDepth:   defaultIfZero(config.MaximumAPIDepth, 50),
+		heartbeatDuration: heartbeatDuration,
+	}
+}
+
+// accessibleResources maps the ID of each resource accessible to the subject to its
+// permissionship.
+type accessibleResources map[string]v1.LookupPermissionship
+
+// WatchLookupResources first sends the resources accessible to the subject at the start revision,
+// then watches the datastore for changes. The accessible resources are re-resolved at the revision
+// of a change only when the permission hints computed from the schema show that the permission
+// may have been affected by it, and the differences are sent.
+func (lws *lookupWatchServer) WatchLookupResources(req *lookupwatchv1.WatchLookupResourcesRequest, stream lookupwatchv1.LookupWatchService_WatchLookupResourcesServer) error {
+	if req.ResourceObjectType == "" || req.Permission == "" {
+		return status.Errorf(codes.InvalidArgument, "resource object type and permission are required")
+	}
+	if req.Subject.GetObject().GetObjectType() == "" || req.Subject.GetObject().GetObjectId() == "" {
+		return status.Errorf(codes.InvalidArgument, "subject is required")
+	}
+
+	ctx := stream.Context()
+	ds := datastoremw.MustFromContext(ctx)
+
+	var startRevision datastore.Revision
+	if req.OptionalStartCursor != nil && req.OptionalStartCursor.Token != "" {
+		decodedRevision, err := zedtoken.DecodeRevision(req.OptionalStartCursor, ds)
+		if err != nil {
+			return status.Errorf(codes.InvalidArgument, "failed to decode start revision: %s", err)
+		}
+
+		startRevision = decodedRevision
+	} else {
+		var err error
+		startRevision, err = ds.OptimizedRevision(ctx)
+		if err != nil {
+			return status.Errorf(codes.Unavailable, "failed to start watch: %s", err)
+		}
+	}
+
+	if err := lws.checkRequestTypes(ctx, ds.SnapshotReader(startRevision), req); err != nil {
+		return lws.rewriteError(ctx, err)
+	}
+
+	usagemetrics.SetInContext(ctx, &dispatchv1.ResponseMeta{
+		DispatchCount: 1,
+	})
+
+	current, err := lws.lookupAccessible(ctx, req, startRevision)
+	if err != nil {
+		return lws.rewriteError(ctx, err)
+	}
+
+	if err := sendResourceChanges(stream, true, diffAccessibleResources(nil, current), startRevision); err != nil {
+		return err
+	}
+
+	watched := watchhints.PermissionReference{ObjectType: req.ResourceObjectType, Permission: req.Permission}
+	updates, errchan := watchhints.Watch(ctx, ds, startRevision, datastore.WatchOptions{
+		Content:            datastore.WatchRelationships,
+		CheckpointInterval: lws.heartbeatDuration,
+	})
+	for {
+		select {
+		case update, ok := <-updates:
+			if !ok {
+				updates = nil
+				continue
+			}
+
+			schemaChanged := len(update.ChangedDefinitions) > 0 || len(update.DeletedNamespaces) > 0
+			if !schemaChanged && !slices.Contains(update.PermissionHints, watched) {
+				continue
+			}
+
+			if schemaChanged {
+				if err := lws.checkRequestTypes(ctx, ds.SnapshotReader(update.Revision), req); err != nil {
+					return lws.rewriteError(ctx, err)
+				}
+			}
+
+			next, err := lws.lookupAccessible(ctx, req, update.Revision)
+			if err != nil {
+				return lws.rewriteError(ctx, err)
+			}
+
+			if err := sendResourceChanges(stream, false, diffAccessibleResources(current, next), update.Revision); err != nil {
+				return err
+			}
+			current = next
+
+		case err := <-errchan:
+			switch {
+			case err == nil:
+				return nil
+			case errors.As(err, &datastore.WatchCanceledError{}):
+				return status.Errorf(codes.Canceled, "watch canceled by user: %s", err)
+			case errors.As(err, &datastore.WatchDisconnectedError{}):
+				return status.Errorf(codes.ResourceExhausted, "watch disconnected: %s", err)
+			default:
+				return lws.rewriteError(ctx, err)
+			}
+		}
+	}
+}
+
+func (lws *lookupWatchServer) checkRequestTypes(ctx context.Context, reader datastore.Reader, req *lookupwatchv1.WatchLookupResourcesRequest) error {
+	return namespace.CheckNamespaceAndRelations(ctx,
+		[]namespace.TypeAndRelationToCheck{
+			{
+				NamespaceName: req.ResourceObjectType,
+				RelationName:  req.Permission,
+				AllowEllipsis: false,
+			},
+			{
+				NamespaceName: req.Subject.Object.ObjectType,
+				RelationName:  normalizeSubjectRelation(req.Subject),
+				AllowEllipsis: true,
+			},
+		}, reader)
+}
+
+// lookupAccessible resolves the resources accessible to the subject of the request at the given
+// revision.
+func (lws *lookupWatchServer) lookupAccessible(ctx context.Context, req *lookupwatchv1.WatchLookupResourcesRequest, revision datastore.Revision) (accessibleResources, error) {
+	accessible := accessibleResources{}
+	stream := dispatch.NewHandlingDispatchStream(ctx, func(result *dispatchv1.DispatchLookupResources2Response) error {
+		found := result.Resource
+		if len(found.MissingContextParams) > 0 {
+			if _, ok := accessible[found.ResourceId]; !ok {
+				accessible[found.ResourceId] = v1.LookupPermissionship_LOOKUP_PERMISSIONSHIP_CONDITIONAL_PERMISSION
+			}
+			return nil
+		}
+
+		accessible[found.ResourceId] = v1.LookupPermissionship_LOOKUP_PERMISSIONSHIP_HAS_PERMISSION
+		return nil
+	})
+
+	bf, err := dispatchv1.NewTraversalBloomFilter(uint(lws.maximumAPIDepth))
+	if err != nil {
+		return nil, err
+	}
+
+	err = lws.dispatch.DispatchLookupResources2(
+		&dispatchv1.DispatchLookupResources2Request{
+			Metadata: &dispatchv1.ResolverMeta{
+				AtRevision:     revision.String(),
+				DepthRemaining: lws.maximumAPIDepth,
+				TraversalBloom: bf,
+			},
+			ResourceRelation: &core.RelationReference{
+				Namespace: req.ResourceObjectType,
+				Relation:  req.Permission,
+			},
+			SubjectRelation: &core.RelationReference{
+				Namespace: req.Subject.Object.ObjectType,
+				Relation:  normalizeSubjectRelation(req.Subject),
+			},
+			SubjectIds: []string{req.Subject.Object.ObjectId},
+			TerminalSubject: &core.ObjectAndRelation{
+				Namespace: req.Subject.Object.ObjectType,
+				ObjectId:  req.Subject.Object.ObjectId,
+				Relation:  normalizeSubjectRelation(req.Subject),
+			},
+			Context: req.Context,
+		},
+		stream)
+	if err != nil {
+		return nil, err
+	}
+
+	return accessible, nil
+}
+
+// diffAccessibleResources returns the changes turning the previous accessible resources into the
+// next ones, sorted by resource ID.
+func diffAccessibleResources(previous, next accessibleResources) []*lookupwatchv1.ResourceChange {
+	var changes []*lookupwatchv1.ResourceChange
+	for resourceID, permissionship := range next {
+		if existing, ok := previous[resourceID]; ok && existing == permissionship {
+			continue
+		}
+
+		changes = append(changes, &lookupwatchv1.ResourceChange{
+			Type:             lookupwatchv1.ResourceChange_CHANGE_TYPE_ADDED,
+			ResourceObjectId: resourceID,
+			Permissionship:   permissionship,
+		})
+	}
+
+	for resourceID := range previous {
+		if _, ok := next[resourceID]; ok {
+			continue
+		}
+
+		changes = append(changes, &lookupwatchv1.ResourceChange{
+			Type:             lookupwatchv1.ResourceChange_CHANGE_TYPE_REMOVED,
+			ResourceObjectId: resourceID,
+		})
+	}
+
+	slices.SortFunc(changes, func(a, b *lookupwatchv1.ResourceChange) int {
+		return strings.Compare(a.ResourceObjectId, b.ResourceObjectId)
+	})
+	return changes
+}
+
+// sendResourceChanges sends the changes through the revision, split into responses of at most
+// maxLookupWatchChangesPerResponse changes. A snapshot is always sent, even if empty, while other
+// changes are only sent if there are any.
+func sendResourceChanges(stream lookupwatchv1.LookupWatchService_WatchLookupResourcesServer, snapshot bool, changes []*lookupwatchv1.ResourceChange, revision datastore.Revision) error {
+	if len(changes) == 0 && !snapshot {
+		return nil
+	}
+
+	changesThrough := zedtoken.MustNewFromRevision(revision)
+	for {
+		chunk := changes[:min(len(changes), maxLookupWatchChangesPerResponse)]
+		changes = changes[len(chunk):]
+
+		if err := stream.Send(&lookupwatchv1.WatchLookupResourcesResponse{
+			Snapshot:       snapshot,
+			Changes:        chunk,
+			ChangesThrough: changesThrough,
+		}); err != nil {
+			return status.Errorf(codes.Canceled, "watch canceled by user: %s", err)
+		}
+
+		if len(changes) == 0 {
+			return nil
+		}
+	}
+}
+
+func (lws *lookupWatchServer) rewriteError(ctx context.Context, err error) error {
+	return shared.RewriteError(ctx, err, &shared.ConfigForErrors{
+		MaximumAPIDepth: lws.maximumAPIDepth,
+	})
+}
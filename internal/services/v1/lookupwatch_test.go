@@ -0,0 +1,161 @@
+package v1_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/authzed/grpcutil"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/internal/testserver"
+	lookupwatchv1 "github.com/authzed/spicedb/pkg/proto/lookupwatch/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+	"github.com/authzed/spicedb/pkg/zedtoken"
+)
+
+func TestWatchLookupResources(t *testing.T) {
+	require := require.New(t)
+
+	conn, cleanup, ds, revision := testserver.NewTestServer(require, 0, memdb.DisableGC, true, testfixtures.StandardDatastoreWithData)
+	t.Cleanup(cleanup)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := lookupwatchv1.NewLookupWatchServiceClient(conn).WatchLookupResources(ctx, &lookupwatchv1.WatchLookupResourcesRequest{
+		ResourceObjectType: "document",
+		Permission:         "view",
+		Subject: &v1.SubjectReference{
+			Object: &v1.ObjectReference{ObjectType: "user", ObjectId: "eng_lead"},
+		},
+		OptionalStartCursor: zedtoken.MustNewFromRevision(revision),
+	})
+	require.NoError(err)
+
+	responses := make(chan *lookupwatchv1.WatchLookupResourcesResponse)
+	go func() {
+		defer close(responses)
+		for {
+			resp, err := stream.Recv()
+			if err != nil {
+				return
+			}
+			responses <- resp
+		}
+	}()
+
+	receive := func() *lookupwatchv1.WatchLookupResourcesResponse {
+		select {
+		case resp := <-responses:
+			require.NotNil(resp)
+			return resp
+		case <-time.After(5 * time.Second):
+			require.FailNow("timed out waiting for response")
+			return nil
+		}
+	}
+
+	snapshot := receive()
+	require.True(snapshot.Snapshot)
+	require.Equal([]*lookupwatchv1.ResourceChange{
+		{
+			Type:             lookupwatchv1.ResourceChange_CHANGE_TYPE_ADDED,
+			ResourceObjectId: "masterplan",
+			Permissionship:   v1.LookupPermissionship_LOOKUP_PERMISSIONSHIP_HAS_PERMISSION,
+		},
+	}, snapshot.Changes)
+
+	write := func(updates ...tuple.RelationshipUpdate) {
+		v1Updates, err := tuple.UpdatesToV1RelationshipUpdates(updates)
+		require.NoError(err)
+
+		_, err = v1.NewPermissionsServiceClient(conn).WriteRelationships(context.Background(), &v1.WriteRelationshipsRequest{
+			Updates: v1Updates,
+		})
+		require.NoError(err)
+	}
+
+	write(tuple.Touch(tuple.MustParse("document:newplan#viewer@user:eng_lead")))
+	added := receive()
+	require.False(added.Snapshot)
+	require.Equal([]*lookupwatchv1.ResourceChange{
+		{
+			Type:             lookupwatchv1.ResourceChange_CHANGE_TYPE_ADDED,
+			ResourceObjectId: "newplan",
+			Permissionship:   v1.LookupPermissionship_LOOKUP_PERMISSIONSHIP_HAS_PERMISSION,
+		},
+	}, added.Changes)
+
+	// A change which does not affect the accessible resources is not sent.
+	write(tuple.Touch(tuple.MustParse("document:newplan#viewer@user:someone_else")))
+
+	write(tuple.Delete(tuple.MustParse("document:masterplan#viewer@user:eng_lead")))
+	removed := receive()
+	require.Equal([]*lookupwatchv1.ResourceChange{
+		{
+			Type:             lookupwatchv1.ResourceChange_CHANGE_TYPE_REMOVED,
+			ResourceObjectId: "masterplan",
+		},
+	}, removed.Changes)
+
+	rev, err := zedtoken.DecodeRevision(removed.ChangesThrough, ds)
+	require.NoError(err)
+	require.True(rev.GreaterThan(revision))
+}
+
+func TestWatchLookupResourcesErrors(t *testing.T) {
+	tcs := []struct {
+		name         string
+		request      *lookupwatchv1.WatchLookupResourcesRequest
+		expectedCode codes.Code
+	}{
+		{
+			name: "missing subject",
+			request: &lookupwatchv1.WatchLookupResourcesRequest{
+				ResourceObjectType: "document",
+				Permission:         "view",
+			},
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name: "unknown resource type",
+			request: &lookupwatchv1.WatchLookupResourcesRequest{
+				ResourceObjectType: "unknown",
+				Permission:         "view",
+				Subject: &v1.SubjectReference{
+					Object: &v1.ObjectReference{ObjectType: "user", ObjectId: "eng_lead"},
+				},
+			},
+			expectedCode: codes.FailedPrecondition,
+		},
+		{
+			name: "unknown permission",
+			request: &lookupwatchv1.WatchLookupResourcesRequest{
+				ResourceObjectType: "document",
+				Permission:         "unknown",
+				Subject: &v1.SubjectReference{
+					Object: &v1.ObjectReference{ObjectType: "user", ObjectId: "eng_lead"},
+				},
+			},
+			expectedCode: codes.FailedPrecondition,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			conn, cleanup, _, _ := testserver.NewTestServer(require.New(t), 0, memdb.DisableGC, true, testfixtures.StandardDatastoreWithData)
+			t.Cleanup(cleanup)
+
+			stream, err := lookupwatchv1.NewLookupWatchServiceClient(conn).WatchLookupResources(context.Background(), tc.request)
+			require.NoError(t, err)
+
+			_, err = stream.Recv()
+			grpcutil.RequireStatus(t, tc.expectedCode, err)
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	cexpr "github.com/authzed/spicedb/internal/caveats"
+	"github.com/authzed/spicedb/internal/graph/computed"
+	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	dispatch "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+	"github.com/authzed/spicedb/pkg/requestmeta"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// MissingCaveatContext is a caveat of a conditional CheckPermission result which could not be
+// evaluated, returned as JSON in the requestmeta.MissingCaveatContextResponseHeaderKey response
+// header.
+type MissingCaveatContext struct {
+	// Caveat is the name of the caveat, and MissingFields the parameters of the caveat missing
+	// from both the context of the request and that of the relationship.
+	Caveat        string   `json:"caveat"`
+	MissingFields []string `json:"missingFields"`
+
+	// Path is the chain of permissions and relations, as `namespace:id#relation`, followed from
+	// the checked resource to the relation of the caveated relationship.
+	Path []string `json:"path"`
+}
+
+// setMissingCaveatContext finds the caveats of a conditional check result missing context, along
+// with the path to each of them in the debug trace of the check, and returns them in the response
+// headers. If the check was not debugged, it is performed again with debugging enabled.
+func (ps *permissionServer) setMissingCaveatContext(ctx context.Context, reader datastore.Reader, params computed.CheckParameters, resourceID string, debugInfo *dispatch.DebugInformation) error {
+	if debugInfo == nil {
+		params.DebugOption = computed.BasicDebuggingEnabled
+		_, checkMetadata, err := computed.ComputeCheck(ctx, ps.dispatch, params, resourceID, ps.config.DispatchChunkSize)
+		if err != nil {
+			return err
+		}
+		debugInfo = checkMetadata.DebugInfo
+	}
+
+	missing, err := findMissingCaveatContext(ctx, reader, params.CaveatContext, debugInfo.GetCheck())
+	if err != nil {
+		return err
+	}
+
+	encoded, err := json.Marshal(missing)
+	if err != nil {
+		return err
+	}
+
+	return grpc.SetHeader(ctx, metadata.Pairs(requestmeta.MissingCaveatContextResponseHeaderKey, string(encoded)))
+}
+
+func findMissingCaveatContext(ctx context.Context, reader datastore.Reader, caveatContext map[string]any, trace *dispatch.CheckDebugTrace) ([]MissingCaveatContext, error) {
+	finder := &missingContextFinder{
+		reader:        reader,
+		caveatContext: caveatContext,
+		runner:        cexpr.NewCaveatRunner(),
+		missing:       []MissingCaveatContext{},
+	}
+	if err := finder.walk(ctx, trace, nil); err != nil {
+		return nil, err
+	}
+	return finder.missing, nil
+}
+
+type missingContextFinder struct {
+	reader        datastore.Reader
+	caveatContext map[string]any
+	runner        *cexpr.CaveatRunner
+	missing       []MissingCaveatContext
+}
+
+// walk follows the caveated results of the trace down to the traces at which their caveats were
+// introduced, recording the caveats missing context there.
+func (f *missingContextFinder) walk(ctx context.Context, trace *dispatch.CheckDebugTrace, path []string) error {
+	if trace == nil || trace.Request == nil {
+		return nil
+	}
+
+	resourceID, ok := resultResourceID(trace, dispatch.ResourceCheckResult_CAVEATED_MEMBER)
+	if !ok {
+		return nil
+	}
+
+	result := trace.Results[resourceID]
+	path = append(slices.Clone(path), tuple.StringONRStrings(trace.Request.ResourceRelation.Namespace, resourceID, trace.Request.ResourceRelation.Relation))
+
+	// Caveats found in the results of the subproblems are reported at the subproblems; the others
+	// were introduced by the relationships read at this trace.
+	fromSubProblems := map[string]struct{}{}
+	for _, subProblem := range trace.SubProblems {
+		if subProblem.Request == nil {
+			continue
+		}
+		if subResourceID, ok := resultResourceID(subProblem, dispatch.ResourceCheckResult_CAVEATED_MEMBER); ok {
+			for _, name := range caveatNames(subProblem.Results[subResourceID].Expression) {
+				fromSubProblems[name] = struct{}{}
+			}
+		}
+
+		if err := f.walk(ctx, subProblem, path); err != nil {
+			return err
+		}
+	}
+
+	for _, caveat := range contextualizedCaveats(result.Expression) {
+		if _, ok := fromSubProblems[caveat.CaveatName]; ok {
+			continue
+		}
+
+		evaluated, err := f.runner.RunCaveatExpression(ctx, &core.CaveatExpression{
+			OperationOrCaveat: &core.CaveatExpression_Caveat{Caveat: caveat},
+		}, f.caveatContext, f.reader, cexpr.RunCaveatExpressionNoDebugging)
+		if err != nil {
+			return err
+		}
+
+		if !evaluated.IsPartial() {
+			continue
+		}
+
+		missingFields, err := evaluated.MissingVarNames()
+		if err != nil {
+			return err
+		}
+		slices.Sort(missingFields)
+
+		f.missing = append(f.missing, MissingCaveatContext{
+			Caveat:        caveat.CaveatName,
+			MissingFields: missingFields,
+			Path:          path,
+		})
+	}
+
+	return nil
+}
+
+// contextualizedCaveats returns the caveats found in the expression.
+func contextualizedCaveats(expr *core.CaveatExpression) []*core.ContextualizedCaveat {
+	if caveat := expr.GetCaveat(); caveat != nil {
+		return []*core.ContextualizedCaveat{caveat}
+	}
+
+	var caveats []*core.ContextualizedCaveat
+	for _, child := range expr.GetOperation().GetChildren() {
+		caveats = append(caveats, contextualizedCaveats(child)...)
+	}
+	return caveats
+}
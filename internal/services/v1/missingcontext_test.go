@@ -0,0 +1,130 @@
+package v1_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	v1svc "github.com/authzed/spicedb/internal/services/v1"
+	tf "github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/internal/testserver"
+	"github.com/authzed/spicedb/pkg/requestmeta"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+func TestCheckPermissionMissingCaveatContext(t *testing.T) {
+	conn, cleanup, _, _ := testserver.NewTestServer(require.New(t), 0, memdb.DisableGC, true, tf.EmptyDatastore)
+	t.Cleanup(cleanup)
+
+	_, err := v1.NewSchemaServiceClient(conn).WriteSchema(context.Background(), &v1.WriteSchemaRequest{
+		Schema: `caveat has_ip(ip string, allowed string) {
+			ip == allowed
+		}
+
+		caveat in_hours(hour int) {
+			hour > 9
+		}
+
+		definition user {}
+
+		definition group {
+			relation member: user with in_hours
+		}
+
+		definition document {
+			relation viewer: user | user with has_ip | group#member
+			permission view = viewer
+		}`,
+	})
+	require.NoError(t, err)
+
+	client := v1.NewPermissionsServiceClient(conn)
+	_, err = client.WriteRelationships(context.Background(), &v1.WriteRelationshipsRequest{
+		Updates: []*v1.RelationshipUpdate{
+			tuple.MustUpdateToV1RelationshipUpdate(tuple.Touch(tuple.MustParse(`document:plan#viewer@user:tom[has_ip:{"allowed":"10.0.0.1"}]`))),
+			tuple.MustUpdateToV1RelationshipUpdate(tuple.Touch(tuple.MustParse("document:plan#viewer@group:eng#member"))),
+			tuple.MustUpdateToV1RelationshipUpdate(tuple.Touch(tuple.MustParse("group:eng#member@user:tom[in_hours]"))),
+			tuple.MustUpdateToV1RelationshipUpdate(tuple.Touch(tuple.MustParse("document:plan#viewer@user:sarah"))),
+		},
+	})
+	require.NoError(t, err)
+
+	// As view is an alias of viewer, the paths start at the viewer relation.
+	tcs := []struct {
+		name            string
+		subjectID       string
+		context         map[string]any
+		expectedMissing []v1svc.MissingCaveatContext
+	}{
+		{
+			name:      "all caveats missing context",
+			subjectID: "tom",
+			expectedMissing: []v1svc.MissingCaveatContext{
+				{
+					Caveat:        "has_ip",
+					MissingFields: []string{"ip"},
+					Path:          []string{"document:plan#viewer"},
+				},
+				{
+					Caveat:        "in_hours",
+					MissingFields: []string{"hour"},
+					Path:          []string{"document:plan#viewer", "group:eng#member"},
+				},
+			},
+		},
+		{
+			name:      "caveat evaluated with the given context",
+			subjectID: "tom",
+			context:   map[string]any{"ip": "10.0.0.2"},
+			expectedMissing: []v1svc.MissingCaveatContext{
+				{
+					Caveat:        "in_hours",
+					MissingFields: []string{"hour"},
+					Path:          []string{"document:plan#viewer", "group:eng#member"},
+				},
+			},
+		},
+		{
+			name:      "unconditional permission",
+			subjectID: "sarah",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			caveatContext, err := structpb.NewStruct(tc.context)
+			require.NoError(t, err)
+
+			var header metadata.MD
+			resp, err := client.CheckPermission(context.Background(), &v1.CheckPermissionRequest{
+				Consistency: &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}},
+				Resource:    &v1.ObjectReference{ObjectType: "document", ObjectId: "plan"},
+				Permission:  "view",
+				Subject:     &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: "user", ObjectId: tc.subjectID}},
+				Context:     caveatContext,
+			}, grpc.Header(&header))
+			require.NoError(t, err)
+
+			encoded := header.Get(requestmeta.MissingCaveatContextResponseHeaderKey)
+			if len(tc.expectedMissing) == 0 {
+				require.Equal(t, v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION, resp.Permissionship)
+				require.Empty(t, encoded)
+				return
+			}
+
+			require.Equal(t, v1.CheckPermissionResponse_PERMISSIONSHIP_CONDITIONAL_PERMISSION, resp.Permissionship)
+			require.Len(t, encoded, 1)
+
+			var missing []v1svc.MissingCaveatContext
+			require.NoError(t, json.Unmarshal([]byte(encoded[0]), &missing))
+			require.ElementsMatch(t, tc.expectedMissing, missing)
+		})
+	}
+}
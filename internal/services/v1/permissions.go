@@ -95,18 +95,24 @@ func (ps *permissionServer) CheckPermission(ctx context.Context, req *v1.CheckPe
 		debugOption = computed.BasicDebuggingEnabled
 	}
 
-	cr, metadata, err := computed.ComputeCheck(ctx, ps.dispatch,
-		computed.CheckParameters{
-			ResourceType:  tuple.RR(req.Resource.ObjectType, req.Permission),
-			Subject:       tuple.ONR(req.Subject.Object.ObjectType, req.Subject.Object.ObjectId, normalizeSubjectRelation(req.Subject)),
-			CaveatContext: caveatContext,
-			AtRevision:    atRevision,
-			MaximumDepth:  ps.config.MaximumAPIDepth,
-			DebugOption:   debugOption,
-		},
-		req.Resource.ObjectId,
-		ps.config.DispatchChunkSize,
-	)
+	// The reason for the result is found from the debug trace of the check, which is only
+	// returned if requested.
+	reasonRequested := checkReasonRequested(ctx)
+	checkDebugOption := debugOption
+	if reasonRequested {
+		checkDebugOption = computed.BasicDebuggingEnabled
+	}
+
+	params := computed.CheckParameters{
+		ResourceType:     tuple.RR(req.Resource.ObjectType, req.Permission),
+		Subject:          tuple.ONR(req.Subject.Object.ObjectType, req.Subject.Object.ObjectId, normalizeSubjectRelation(req.Subject)),
+		CaveatContext:    caveatContext,
+		AtRevision:       atRevision,
+		MaximumDepth:     ps.config.MaximumAPIDepth,
+		DebugOption:      checkDebugOption,
+		PrefilterCaveats: ps.config.CaveatPrefilteringEnabled,
+	}
+	cr, metadata, err := computed.ComputeCheck(ctx, ps.dispatch, params, req.Resource.ObjectId, ps.config.DispatchChunkSize)
 	usagemetrics.SetInContext(ctx, metadata)
 
 	var debugTrace *v1.DebugInformation
@@ -122,7 +128,7 @@ func (ps *permissionServer) CheckPermission(ctx context.Context, req *v1.CheckPe
 	if err != nil {
 		// If the error already contains debug information, rewrite it. This can happen if
 		// a dispatch error occurs and debug was requested.
-		if dispatchDebugInfo, ok := spiceerrors.GetDetails[*dispatch.DebugInformation](err); ok {
+		if dispatchDebugInfo, ok := spiceerrors.GetDetails[*dispatch.DebugInformation](err); ok && debugOption != computed.NoDebugging {
 			// Convert the dispatch debug information into API debug information.
 			converted, cerr := ConvertCheckDispatchDebugInformation(ctx, caveatContext, dispatchDebugInfo, ds)
 			if cerr != nil {
@@ -137,6 +143,18 @@ func (ps *permissionServer) CheckPermission(ctx context.Context, req *v1.CheckPe
 		return nil, ps.rewriteErrorWithOptionalDebugTrace(ctx, err, debugTrace)
 	}
 
+	if reasonRequested {
+		if err := setCheckReason(ctx, ds, params.Subject, cr, metadata.DebugInfo); err != nil {
+			return nil, ps.rewriteError(ctx, err)
+		}
+	}
+
+	if cr.Membership == dispatch.ResourceCheckResult_CAVEATED_MEMBER {
+		if err := ps.setMissingCaveatContext(ctx, ds, params, req.Resource.ObjectId, metadata.DebugInfo); err != nil {
+			return nil, ps.rewriteError(ctx, err)
+		}
+	}
+
 	permissionship, partialCaveat := checkResultToAPITypes(cr)
 
 	return &v1.CheckPermissionResponse{
@@ -219,6 +237,22 @@ func (ps *permissionServer) ExpandPermissionTree(ctx context.Context, req *v1.Ex
 		return nil, ps.rewriteError(ctx, err)
 	}
 
+	if zanzibarExpandRequested(ctx) {
+		tree, err := zanzibarExpansionTree(ctx, ds, &core.ObjectAndRelation{
+			Namespace: req.Resource.ObjectType,
+			ObjectId:  req.Resource.ObjectId,
+			Relation:  req.Permission,
+		})
+		if err != nil {
+			return nil, ps.rewriteError(ctx, err)
+		}
+
+		return &v1.ExpandPermissionTreeResponse{
+			TreeRoot:   TranslateExpansionTree(tree),
+			ExpandedAt: expandedAt,
+		}, nil
+	}
+
 	bf, err := dispatch.NewTraversalBloomFilter(uint(ps.config.MaximumAPIDepth))
 	if err != nil {
 		return nil, err
@@ -329,7 +363,7 @@ func TranslateExpansionTree(node *core.RelationTupleTreeNode) *v1.PermissionRela
 			panic("unknown set operation")
 		}
 
-		var children []*v1.PermissionRelationshipTree
+		children := make([]*v1.PermissionRelationshipTree, 0, len(node.GetIntermediateNode().ChildNodes))
 		for _, child := range node.GetIntermediateNode().ChildNodes {
 			children = append(children, TranslateExpansionTree(child))
 		}
@@ -356,15 +390,17 @@ func TranslateExpansionTree(node *core.RelationTupleTreeNode) *v1.PermissionRela
 		}
 
 	case *core.RelationTupleTreeNode_LeafNode:
-		var subjects []*v1.SubjectReference
-		for _, found := range t.LeafNode.Subjects {
-			subjects = append(subjects, &v1.SubjectReference{
-				Object: &v1.ObjectReference{
-					ObjectType: found.Subject.Namespace,
-					ObjectId:   found.Subject.ObjectId,
-				},
-				OptionalRelation: denormalizeSubjectRelation(found.Subject.Relation),
-			})
+		// Leaves of large groups hold millions of subjects, so their references are allocated
+		// in slabs rather than one by one.
+		subjects := make([]*v1.SubjectReference, len(t.LeafNode.Subjects))
+		subjectRefs := make([]v1.SubjectReference, len(t.LeafNode.Subjects))
+		objectRefs := make([]v1.ObjectReference, len(t.LeafNode.Subjects))
+		for i, found := range t.LeafNode.Subjects {
+			objectRefs[i].ObjectType = found.Subject.Namespace
+			objectRefs[i].ObjectId = found.Subject.ObjectId
+			subjectRefs[i].Object = &objectRefs[i]
+			subjectRefs[i].OptionalRelation = denormalizeSubjectRelation(found.Subject.Relation)
+			subjects[i] = &subjectRefs[i]
 		}
 
 		if node.Expanded == nil {
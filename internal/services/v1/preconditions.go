@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/authzed/spicedb/pkg/datastore"
 	"github.com/authzed/spicedb/pkg/datastore/options"
@@ -12,40 +13,111 @@ import (
 
 var limitOne uint64 = 1
 
+// maxPreconditionBatchSize is the maximum number of precondition filters checked by a single
+// batched query.
+const maxPreconditionBatchSize = 100
+
 // checkPreconditions checks whether the preconditions are met in the context of a datastore
-// read-write transaction, and returns an error if they are not met.
+// read-write transaction, or of a snapshot when validating a write, and returns an error if they
+// are not met.
+//
+// The filters of the preconditions are checked together, by batched queries reading at most one
+// relationship per filter, so that a broad filter does not read all the relationships it matches.
+// Preconditions with identical filters share their query. If several preconditions are not met,
+// the error is for the first of them.
 func checkPreconditions(
 	ctx context.Context,
-	rwt datastore.ReadWriteTransaction,
+	rwt datastore.Reader,
 	preconditions []*v1.Precondition,
 ) error {
 	for _, precond := range preconditions {
-		dsFilter, err := datastore.RelationshipsFilterFromPublicFilter(precond.Filter)
+		if precond.Operation != v1.Precondition_OPERATION_MUST_MATCH && precond.Operation != v1.Precondition_OPERATION_MUST_NOT_MATCH {
+			return fmt.Errorf("unspecified precondition operation: %s", precond.Operation)
+		}
+	}
+
+	batches, err := planPreconditionBatches(preconditions)
+	if err != nil {
+		return err
+	}
+
+	matched := make([]bool, len(preconditions))
+	for _, batch := range batches {
+		if err := batch.check(ctx, rwt, matched); err != nil {
+			return err
+		}
+	}
+
+	for index, precond := range preconditions {
+		if matched[index] != (precond.Operation == v1.Precondition_OPERATION_MUST_MATCH) {
+			return NewPreconditionFailedErr(precond)
+		}
+	}
+
+	return nil
+}
+
+// preconditionBatch is a set of preconditions which are checked by a single batched query.
+type preconditionBatch struct {
+	filters []datastore.RelationshipsFilter
+
+	// preconditions are the indexes of the preconditions of each filter of the batch.
+	preconditions [][]int
+}
+
+// planPreconditionBatches returns the batches in which the preconditions are checked.
+func planPreconditionBatches(preconditions []*v1.Precondition) ([]*preconditionBatch, error) {
+	var batches []*preconditionBatch
+	type filterLocation struct {
+		batch *preconditionBatch
+		index int
+	}
+	seen := make(map[string]filterLocation, len(preconditions))
+
+	for index, precond := range preconditions {
+		key, err := proto.MarshalOptions{Deterministic: true}.Marshal(precond.Filter)
 		if err != nil {
-			return fmt.Errorf("error converting filter: %w", err)
+			return nil, fmt.Errorf("error converting filter: %w", err)
 		}
 
-		iter, err := rwt.QueryRelationships(ctx, dsFilter, options.WithLimit(&limitOne))
+		if location, ok := seen[string(key)]; ok {
+			location.batch.preconditions[location.index] = append(location.batch.preconditions[location.index], index)
+			continue
+		}
+
+		dsFilter, err := datastore.RelationshipsFilterFromPublicFilter(precond.Filter)
 		if err != nil {
-			return fmt.Errorf("error reading relationships: %w", err)
+			return nil, fmt.Errorf("error converting filter: %w", err)
 		}
 
-		_, ok, err := datastore.FirstRelationshipIn(iter)
+		if len(batches) == 0 || len(batches[len(batches)-1].filters) >= maxPreconditionBatchSize {
+			batches = append(batches, &preconditionBatch{})
+		}
+
+		batch := batches[len(batches)-1]
+		seen[string(key)] = filterLocation{batch, len(batch.filters)}
+		batch.filters = append(batch.filters, dsFilter)
+		batch.preconditions = append(batch.preconditions, []int{index})
+	}
+
+	return batches, nil
+}
+
+// check queries at most one relationship matching each filter of the batch and marks the
+// preconditions of the filters for which one was found as matched.
+func (pb *preconditionBatch) check(ctx context.Context, reader datastore.Reader, matched []bool) error {
+	iter, err := reader.QueryRelationshipsBatch(ctx, pb.filters, options.WithLimit(&limitOne))
+	if err != nil {
+		return fmt.Errorf("error reading relationships: %w", err)
+	}
+
+	for tagged, err := range iter {
 		if err != nil {
 			return fmt.Errorf("error reading relationships from iterator: %w", err)
 		}
 
-		switch precond.Operation {
-		case v1.Precondition_OPERATION_MUST_NOT_MATCH:
-			if ok {
-				return NewPreconditionFailedErr(precond)
-			}
-		case v1.Precondition_OPERATION_MUST_MATCH:
-			if !ok {
-				return NewPreconditionFailedErr(precond)
-			}
-		default:
-			return fmt.Errorf("unspecified precondition operation: %s", precond.Operation)
+		for _, index := range pb.preconditions[tagged.FilterIndex] {
+			matched[index] = true
 		}
 	}
 
@@ -2,6 +2,7 @@ package v1
 
 import (
 	"context"
+	"fmt"
 	"testing"
 
 	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
@@ -11,6 +12,9 @@ import (
 	"github.com/authzed/spicedb/internal/datastore/memdb"
 	"github.com/authzed/spicedb/internal/testfixtures"
 	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/options"
+	"github.com/authzed/spicedb/pkg/errorcodes"
+	"github.com/authzed/spicedb/pkg/testutil"
 )
 
 var companyPlanFolder = &v1.RelationshipFilter{
@@ -46,12 +50,15 @@ func TestPreconditions(t *testing.T) {
 				Filter:    companyPlanFolder,
 			},
 		}))
-		require.Error(checkPreconditions(ctx, rwt, []*v1.Precondition{
+		err := checkPreconditions(ctx, rwt, []*v1.Precondition{
 			{
 				Operation: v1.Precondition_OPERATION_MUST_NOT_MATCH,
 				Filter:    companyPlanFolder,
 			},
-		}))
+		})
+		require.Error(err)
+		code, _ := errorcodes.FromError(err)
+		require.Equal(errorcodes.PreconditionMustNotMatchFailed, code)
 		require.NoError(checkPreconditions(ctx, rwt, []*v1.Precondition{
 			{
 				Operation: v1.Precondition_OPERATION_MUST_MATCH,
@@ -68,3 +75,144 @@ func TestPreconditions(t *testing.T) {
 	})
 	require.NoError(err)
 }
+
+func TestBatchedPreconditions(t *testing.T) {
+	uninitialized, err := dsfortesting.NewMemDBDatastoreForTesting(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+
+	ds, _ := testfixtures.StandardDatastoreWithData(uninitialized, require.New(t))
+
+	parentFilter := func(resourceID string) *v1.RelationshipFilter {
+		return &v1.RelationshipFilter{
+			ResourceType:       "document",
+			OptionalResourceId: resourceID,
+			OptionalRelation:   "parent",
+		}
+	}
+
+	tcs := []struct {
+		name           string
+		preconditions  []*v1.Precondition
+		expectedFailed *v1.Precondition
+	}{
+		{
+			"all match",
+			[]*v1.Precondition{
+				{Operation: v1.Precondition_OPERATION_MUST_MATCH, Filter: parentFilter("companyplan")},
+				{Operation: v1.Precondition_OPERATION_MUST_MATCH, Filter: parentFilter("masterplan")},
+				{Operation: v1.Precondition_OPERATION_MUST_MATCH, Filter: parentFilter("healthplan")},
+				{Operation: v1.Precondition_OPERATION_MUST_NOT_MATCH, Filter: parentFilter("unknownplan")},
+				{Operation: v1.Precondition_OPERATION_MUST_MATCH, Filter: prefixMatch},
+			},
+			nil,
+		},
+		{
+			"duplicate resource IDs",
+			[]*v1.Precondition{
+				{Operation: v1.Precondition_OPERATION_MUST_MATCH, Filter: parentFilter("masterplan")},
+				{Operation: v1.Precondition_OPERATION_MUST_MATCH, Filter: parentFilter("masterplan")},
+				{Operation: v1.Precondition_OPERATION_MUST_MATCH, Filter: parentFilter("companyplan")},
+			},
+			nil,
+		},
+		{
+			"one does not match",
+			[]*v1.Precondition{
+				{Operation: v1.Precondition_OPERATION_MUST_MATCH, Filter: parentFilter("companyplan")},
+				{Operation: v1.Precondition_OPERATION_MUST_MATCH, Filter: parentFilter("unknownplan")},
+				{Operation: v1.Precondition_OPERATION_MUST_MATCH, Filter: parentFilter("masterplan")},
+			},
+			&v1.Precondition{Operation: v1.Precondition_OPERATION_MUST_MATCH, Filter: parentFilter("unknownplan")},
+		},
+		{
+			"first failure is returned",
+			[]*v1.Precondition{
+				{Operation: v1.Precondition_OPERATION_MUST_MATCH, Filter: prefixNoMatch},
+				{Operation: v1.Precondition_OPERATION_MUST_NOT_MATCH, Filter: parentFilter("masterplan")},
+			},
+			&v1.Precondition{Operation: v1.Precondition_OPERATION_MUST_MATCH, Filter: prefixNoMatch},
+		},
+		{
+			"must not match in batch",
+			[]*v1.Precondition{
+				{Operation: v1.Precondition_OPERATION_MUST_NOT_MATCH, Filter: parentFilter("unknownplan")},
+				{Operation: v1.Precondition_OPERATION_MUST_NOT_MATCH, Filter: parentFilter("healthplan")},
+			},
+			&v1.Precondition{Operation: v1.Precondition_OPERATION_MUST_NOT_MATCH, Filter: parentFilter("healthplan")},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ds.ReadWriteTx(context.Background(), func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+				return checkPreconditions(ctx, rwt, tc.preconditions)
+			})
+			if tc.expectedFailed == nil {
+				require.NoError(t, err)
+				return
+			}
+
+			var failed PreconditionFailedError
+			require.ErrorAs(t, err, &failed)
+			testutil.RequireProtoEqual(t, tc.expectedFailed, failed.precondition, "unexpected failed precondition")
+		})
+	}
+}
+
+func TestPlanPreconditionBatches(t *testing.T) {
+	preconditions := make([]*v1.Precondition, 0, maxPreconditionBatchSize+3)
+	for i := 0; i < maxPreconditionBatchSize; i++ {
+		preconditions = append(preconditions, &v1.Precondition{
+			Operation: v1.Precondition_OPERATION_MUST_MATCH,
+			Filter: &v1.RelationshipFilter{
+				ResourceType:       "document",
+				OptionalResourceId: fmt.Sprintf("doc%d", i),
+				OptionalRelation:   "viewer",
+			},
+		})
+	}
+	preconditions = append(preconditions,
+		&v1.Precondition{Operation: v1.Precondition_OPERATION_MUST_MATCH, Filter: companyPlanFolder},
+		&v1.Precondition{Operation: v1.Precondition_OPERATION_MUST_MATCH, Filter: prefixMatch},
+		&v1.Precondition{Operation: v1.Precondition_OPERATION_MUST_NOT_MATCH, Filter: companyPlanFolder},
+	)
+
+	batches, err := planPreconditionBatches(preconditions)
+	require.NoError(t, err)
+	require.Len(t, batches, 2)
+	require.Len(t, batches[0].filters, maxPreconditionBatchSize)
+	require.Equal(t, []string{"doc0"}, batches[0].filters[0].OptionalResourceIds)
+
+	// Identical filters share their query.
+	require.Len(t, batches[1].filters, 2)
+	require.Equal(t, []string{"companyplan"}, batches[1].filters[0].OptionalResourceIds)
+	require.Equal(t, []int{maxPreconditionBatchSize, maxPreconditionBatchSize + 2}, batches[1].preconditions[0])
+	require.Equal(t, "c", batches[1].filters[1].OptionalResourceIDPrefix)
+}
+
+// limitRecordingReader records the limits of the batched queries of the reader.
+type limitRecordingReader struct {
+	datastore.Reader
+	limits []*uint64
+}
+
+func (r *limitRecordingReader) QueryRelationshipsBatch(ctx context.Context, filters []datastore.RelationshipsFilter, opts ...options.QueryOptionsOption) (datastore.TaggedRelationshipIterator, error) {
+	r.limits = append(r.limits, options.NewQueryOptionsWithOptions(opts...).Limit)
+	return r.Reader.QueryRelationshipsBatch(ctx, filters, opts...)
+}
+
+func TestBatchedPreconditionsReadOneRelationshipPerFilter(t *testing.T) {
+	uninitialized, err := dsfortesting.NewMemDBDatastoreForTesting(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+
+	ds, revision := testfixtures.StandardDatastoreWithData(uninitialized, require.New(t))
+
+	reader := &limitRecordingReader{Reader: ds.SnapshotReader(revision)}
+	require.NoError(t, checkPreconditions(context.Background(), reader, []*v1.Precondition{
+		{Operation: v1.Precondition_OPERATION_MUST_MATCH, Filter: &v1.RelationshipFilter{ResourceType: "document"}},
+		{Operation: v1.Precondition_OPERATION_MUST_MATCH, Filter: companyPlanFolder},
+	}))
+	require.Len(t, reader.limits, 1)
+	require.NotNil(t, reader.limits[0])
+	require.Equal(t, uint64(1), *reader.limits[0])
+}
@@ -0,0 +1,77 @@
+package v1
+
+import (
+	"context"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/authzed/spicedb/internal/middleware/metering"
+	"github.com/authzed/spicedb/pkg/middleware/requestid"
+)
+
+// ProvenanceMetadataKey is the key in the transaction metadata under which the provenance
+// of a write (the request ID, the authenticated caller and information about its connection) is recorded, when enabled.
+// Because transaction metadata is surfaced on Watch events, this allows audit and sync
+// consumers to determine which request caused each relationship change.
+const ProvenanceMetadataKey = "spicedb_provenance"
+
+const (
+	provenanceRequestIDKey   = "request_id"
+	provenanceCallerKey      = "caller"
+	provenancePeerAddressKey = "peer_address"
+	provenanceUserAgentKey   = "user_agent"
+)
+
+// withWriteProvenance returns a copy of the given transaction metadata with the provenance
+// of the current request added under ProvenanceMetadataKey. Any caller-supplied value under
+// the same key is overwritten, to ensure the recorded provenance can be trusted.
+func withWriteProvenance(ctx context.Context, transactionMetadata *structpb.Struct) (*structpb.Struct, error) {
+	provenance := map[string]any{}
+	if requestID, ok := requestid.FromContext(ctx); ok {
+		provenance[provenanceRequestIDKey] = requestID
+	}
+
+	// The caller is identified as in usage metering, by a hash of its bearer token, so that the
+	// token is never exposed to Watch consumers.
+	if caller := metering.CallerFromContext(ctx); caller != metering.AnonymousCaller {
+		provenance[provenanceCallerKey] = caller
+	}
+
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		provenance[provenancePeerAddressKey] = p.Addr.String()
+	}
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if userAgents := md.Get("user-agent"); len(userAgents) > 0 {
+			provenance[provenanceUserAgentKey] = userAgents[0]
+		}
+	}
+
+	if len(provenance) == 0 {
+		return transactionMetadata, nil
+	}
+
+	provenanceValue, err := structpb.NewValue(provenance)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]*structpb.Value, len(transactionMetadata.GetFields())+1)
+	for key, value := range transactionMetadata.GetFields() {
+		fields[key] = value
+	}
+	fields[ProvenanceMetadataKey] = provenanceValue
+	return &structpb.Struct{Fields: fields}, nil
+}
+
+// transactionMetadataForWrite returns the metadata to be stored alongside the transaction
+// for a write request.
+func (ps *permissionServer) transactionMetadataForWrite(ctx context.Context, transactionMetadata *structpb.Struct) (*structpb.Struct, error) {
+	if !ps.config.WriteProvenanceEnabled {
+		return transactionMetadata, nil
+	}
+
+	return withWriteProvenance(ctx, transactionMetadata)
+}
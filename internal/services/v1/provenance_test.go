@@ -0,0 +1,52 @@
+package v1
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/authzed/spicedb/internal/middleware/metering"
+)
+
+func TestWithWriteProvenance(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		"x-request-id", "somerequestid",
+		"user-agent", "sometestclient",
+		"authorization", "Bearer sometoken",
+	))
+	ctx = peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 1234}})
+
+	userMetadata, err := structpb.NewStruct(map[string]any{
+		"foo":                 "bar",
+		ProvenanceMetadataKey: "spoofed",
+	})
+	require.NoError(t, err)
+
+	withProvenance, err := withWriteProvenance(ctx, userMetadata)
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{
+		"foo": "bar",
+		ProvenanceMetadataKey: map[string]any{
+			provenanceRequestIDKey:   "somerequestid",
+			provenanceCallerKey:      metering.CallerFromContext(ctx),
+			provenancePeerAddressKey: "10.0.0.1:1234",
+			provenanceUserAgentKey:   "sometestclient",
+		},
+	}, withProvenance.AsMap())
+
+	// The caller is recorded by a hash of its token, never by the token itself.
+	require.NotContains(t, withProvenance.String(), "sometoken")
+
+	// Ensure the original metadata was not modified.
+	require.Equal(t, "spoofed", userMetadata.AsMap()[ProvenanceMetadataKey])
+
+	// Without any provenance information, the metadata is returned as-is.
+	unchanged, err := withWriteProvenance(context.Background(), nil)
+	require.NoError(t, err)
+	require.Nil(t, unchanged)
+}
@@ -0,0 +1,148 @@
+package v1_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	authzedrequestmeta "github.com/authzed/authzed-go/pkg/requestmeta"
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/authzed/grpcutil"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	tf "github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/internal/testserver"
+	"github.com/authzed/spicedb/pkg/requestmeta"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+func TestReadRelationshipsAtTime(t *testing.T) {
+	beforeStart := time.Now().Add(-time.Hour)
+
+	conn, cleanup, _, _ := testserver.NewTestServer(require.New(t), 0, memdb.DisableGC, true, tf.StandardDatastoreWithData)
+	t.Cleanup(cleanup)
+	client := v1.NewPermissionsServiceClient(conn)
+
+	readViewers := func(ctx context.Context) ([]string, error) {
+		stream, err := client.ReadRelationships(ctx, &v1.ReadRelationshipsRequest{
+			Consistency: &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}},
+			RelationshipFilter: &v1.RelationshipFilter{
+				ResourceType:       "document",
+				OptionalResourceId: "masterplan",
+				OptionalRelation:   "viewer",
+			},
+		})
+		require.NoError(t, err)
+
+		var viewers []string
+		for {
+			resp, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				return viewers, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			viewers = append(viewers, resp.Relationship.Subject.Object.ObjectId)
+		}
+	}
+
+	atTime := func(at time.Time) context.Context {
+		return authzedrequestmeta.SetRequestHeaders(context.Background(), map[authzedrequestmeta.RequestMetadataHeaderKey]string{
+			requestmeta.RequestReadAtTime: at.Format(time.RFC3339Nano),
+		})
+	}
+
+	beforeDelete := time.Now()
+	time.Sleep(1 * time.Millisecond)
+
+	_, err := client.WriteRelationships(context.Background(), &v1.WriteRelationshipsRequest{
+		Updates: []*v1.RelationshipUpdate{
+			tuple.MustUpdateToV1RelationshipUpdate(tuple.Delete(tuple.MustParse("document:masterplan#viewer@user:eng_lead"))),
+		},
+	})
+	require.NoError(t, err)
+
+	current, err := readViewers(context.Background())
+	require.NoError(t, err)
+	require.NotContains(t, current, "eng_lead")
+
+	past, err := readViewers(atTime(beforeDelete))
+	require.NoError(t, err)
+	require.Contains(t, past, "eng_lead")
+	require.ElementsMatch(t, append(current, "eng_lead"), past)
+
+	_, err = readViewers(atTime(beforeStart))
+	grpcutil.RequireStatus(t, codes.OutOfRange, err)
+
+	_, err = readViewers(atTime(time.Now().Add(time.Hour)))
+	grpcutil.RequireStatus(t, codes.InvalidArgument, err)
+
+	_, err = readViewers(authzedrequestmeta.SetRequestHeaders(context.Background(), map[authzedrequestmeta.RequestMetadataHeaderKey]string{
+		requestmeta.RequestReadAtTime: "last tuesday",
+	}))
+	grpcutil.RequireStatus(t, codes.InvalidArgument, err)
+}
+
+func TestCheckPermissionAtTime(t *testing.T) {
+	beforeStart := time.Now().Add(-time.Hour)
+
+	conn, cleanup, _, _ := testserver.NewTestServer(require.New(t), 0, memdb.DisableGC, true, tf.StandardDatastoreWithData)
+	t.Cleanup(cleanup)
+	client := v1.NewPermissionsServiceClient(conn)
+
+	checkView := func(ctx context.Context) (v1.CheckPermissionResponse_Permissionship, error) {
+		resp, err := client.CheckPermission(ctx, &v1.CheckPermissionRequest{
+			Consistency: &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}},
+			Resource:    &v1.ObjectReference{ObjectType: "document", ObjectId: "masterplan"},
+			Permission:  "view",
+			Subject:     &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: "user", ObjectId: "eng_lead"}},
+		})
+		if err != nil {
+			return v1.CheckPermissionResponse_PERMISSIONSHIP_UNSPECIFIED, err
+		}
+		return resp.Permissionship, nil
+	}
+
+	atTime := func(at time.Time) context.Context {
+		return authzedrequestmeta.SetRequestHeaders(context.Background(), map[authzedrequestmeta.RequestMetadataHeaderKey]string{
+			requestmeta.RequestReadAtTime: at.Format(time.RFC3339Nano),
+		})
+	}
+
+	beforeDelete := time.Now()
+	time.Sleep(1 * time.Millisecond)
+
+	_, err := client.WriteRelationships(context.Background(), &v1.WriteRelationshipsRequest{
+		Updates: []*v1.RelationshipUpdate{
+			tuple.MustUpdateToV1RelationshipUpdate(tuple.Delete(tuple.MustParse("document:masterplan#viewer@user:eng_lead"))),
+		},
+	})
+	require.NoError(t, err)
+
+	current, err := checkView(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, v1.CheckPermissionResponse_PERMISSIONSHIP_NO_PERMISSION, current)
+
+	past, err := checkView(atTime(beforeDelete))
+	require.NoError(t, err)
+	require.Equal(t, v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION, past)
+
+	_, err = checkView(atTime(beforeStart))
+	grpcutil.RequireStatus(t, codes.OutOfRange, err)
+	errStatus, ok := status.FromError(err)
+	require.True(t, ok)
+	require.Len(t, errStatus.Details(), 1)
+	errInfo, ok := errStatus.Details()[0].(*errdetails.ErrorInfo)
+	require.True(t, ok)
+	require.Equal(t, "garbage_collected", errInfo.Metadata["revision_status"])
+
+	_, err = checkView(atTime(time.Now().Add(time.Hour)))
+	grpcutil.RequireStatus(t, codes.InvalidArgument, err)
+}
@@ -0,0 +1,172 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	grpcvalidate "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/validator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/authzed/spicedb/internal/middleware"
+	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
+	"github.com/authzed/spicedb/internal/middleware/usagemetrics"
+	"github.com/authzed/spicedb/internal/relationships"
+	"github.com/authzed/spicedb/internal/services/shared"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/options"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	dispatchv1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+	reconciliationv1 "github.com/authzed/spicedb/pkg/proto/reconciliation/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+	"github.com/authzed/spicedb/pkg/zedtoken"
+)
+
+type reconciliationServer struct {
+	reconciliationv1.UnimplementedReconciliationServiceServer
+	shared.WithServiceSpecificInterceptors
+
+	maxUpdatesPerWrite           uint16
+	maxRelationshipContextSize   int
+	expiringRelationshipsEnabled bool
+}
+
+// NewReconciliationServer creates an instance of the experimental reconciliation server.
+func NewReconciliationServer(config PermissionsServerConfig) reconciliationv1.ReconciliationServiceServer {
+	return &reconciliationServer{
+		WithServiceSpecificInterceptors: shared.WithServiceSpecificInterceptors{
+			Unary: middleware.ChainUnaryServer(
+				grpcvalidate.UnaryServerInterceptor(),
+				usagemetrics.UnaryServerInterceptor(),
+			),
+		},
+		maxUpdatesPerWrite:           defaultIfZero(config.MaxUpdatesPerWrite, 1_000),
+		maxRelationshipContextSize:   defaultIfZero(config.MaxRelationshipContextSize, 25_000),
+		expiringRelationshipsEnabled: config.ExpiringRelationshipsEnabled,
+	}
+}
+
+// ApplyRelationshipsSnapshot reads the relationships matching the filter and writes the difference
+// with the snapshot within the same transaction, so that the scope matches the snapshot exactly at
+// the returned revision. Only the changes count towards the maximum updates per write, so snapshots
+// may be larger than it as long as few of their relationships change.
+func (rs *reconciliationServer) ApplyRelationshipsSnapshot(ctx context.Context, req *reconciliationv1.ApplyRelationshipsSnapshotRequest) (*reconciliationv1.ApplyRelationshipsSnapshotResponse, error) {
+	if req.RelationshipFilter.GetResourceType() == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "the resource type of the relationship filter is required")
+	}
+
+	filter, err := datastore.RelationshipsFilterFromPublicFilter(req.RelationshipFilter)
+	if err != nil {
+		return nil, rs.rewriteError(ctx, err)
+	}
+
+	desired := make(map[string]tuple.Relationship, len(req.Relationships))
+	for _, v1rel := range req.Relationships {
+		rel := tuple.FromV1Relationship(v1rel)
+		key := tuple.StringWithoutCaveatOrExpiration(rel)
+		if _, ok := desired[key]; ok {
+			return nil, status.Errorf(codes.InvalidArgument, "relationship `%s` is found more than once in the snapshot", key)
+		}
+		if !filter.Test(rel) {
+			return nil, status.Errorf(codes.InvalidArgument, "relationship `%s` of the snapshot does not match the relationship filter", key)
+		}
+		if proto.Size(v1rel.OptionalCaveat) > rs.maxRelationshipContextSize {
+			update := &v1.RelationshipUpdate{Operation: v1.RelationshipUpdate_OPERATION_TOUCH, Relationship: v1rel}
+			return nil, rs.rewriteError(ctx, NewMaxRelationshipContextError(update, rs.maxRelationshipContextSize))
+		}
+		if !rs.expiringRelationshipsEnabled && v1rel.OptionalExpiresAt != nil {
+			return nil, rs.rewriteError(ctx, fmt.Errorf("support for expiring relationships is not enabled"))
+		}
+		desired[key] = rel
+	}
+
+	var created, updated, deleted uint64
+	ds := datastoremw.MustFromContext(ctx)
+	revision, err := ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		created, updated, deleted = 0, 0, 0
+		if err := validateRelationshipsFilter(ctx, req.RelationshipFilter, rwt); err != nil {
+			return err
+		}
+
+		usagemetrics.SetInContext(ctx, &dispatchv1.ResponseMeta{
+			DispatchCount: 1,
+		})
+
+		// Every relationship of the scope beyond those of the snapshot and the maximum updates is
+		// necessarily a deletion over the maximum, so the read stops there.
+		maxUpdates := uint64(rs.maxUpdatesPerWrite)
+		readLimit := uint64(len(desired)) + maxUpdates + 1
+		it, err := rwt.QueryRelationships(ctx, filter, options.WithLimit(&readLimit))
+		if err != nil {
+			return err
+		}
+
+		var updates []tuple.RelationshipUpdate
+		existing := make(map[string]struct{}, len(desired))
+		for rel, err := range it {
+			if err != nil {
+				return err
+			}
+
+			key := tuple.StringWithoutCaveatOrExpiration(rel)
+			existing[key] = struct{}{}
+			desiredRel, ok := desired[key]
+			switch {
+			case !ok:
+				updates = append(updates, tuple.Delete(rel))
+				deleted++
+			case !tuple.Equal(withoutEmptyCaveatContext(rel), withoutEmptyCaveatContext(desiredRel)):
+				updates = append(updates, tuple.Touch(desiredRel))
+				updated++
+			}
+
+			if uint64(len(updates)) > maxUpdates {
+				return NewExceedsMaximumUpdatesErr(uint64(len(updates)), maxUpdates)
+			}
+		}
+
+		for _, v1rel := range req.Relationships {
+			rel := tuple.FromV1Relationship(v1rel)
+			if _, ok := existing[tuple.StringWithoutCaveatOrExpiration(rel)]; !ok {
+				updates = append(updates, tuple.Touch(rel))
+				created++
+			}
+		}
+		if uint64(len(updates)) > maxUpdates {
+			return NewExceedsMaximumUpdatesErr(uint64(len(updates)), maxUpdates)
+		}
+		if len(updates) == 0 {
+			return nil
+		}
+
+		if err := relationships.ValidateRelationshipUpdates(ctx, rwt, updates); err != nil {
+			return err
+		}
+		return rwt.WriteRelationships(ctx, updates)
+	})
+	if err != nil {
+		return nil, rs.rewriteError(ctx, err)
+	}
+
+	return &reconciliationv1.ApplyRelationshipsSnapshotResponse{
+		WrittenAt:            zedtoken.MustNewFromRevision(revision),
+		RelationshipsCreated: created,
+		RelationshipsUpdated: updated,
+		RelationshipsDeleted: deleted,
+	}, nil
+}
+
+func (rs *reconciliationServer) rewriteError(ctx context.Context, err error) error {
+	return shared.RewriteErrorWithoutConfig(ctx, err)
+}
+
+// withoutEmptyCaveatContext returns the relationship with a nil context in place of an empty one, as
+// datastores may read back either for a caveat written without context.
+func withoutEmptyCaveatContext(rel tuple.Relationship) tuple.Relationship {
+	if rel.OptionalCaveat != nil && len(rel.OptionalCaveat.Context.GetFields()) == 0 {
+		rel.OptionalCaveat = &core.ContextualizedCaveat{CaveatName: rel.OptionalCaveat.CaveatName}
+	}
+	return rel
+}
@@ -0,0 +1,149 @@
+package v1_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/authzed/grpcutil"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	tf "github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/internal/testserver"
+	reconciliationv1 "github.com/authzed/spicedb/pkg/proto/reconciliation/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+func TestApplyRelationshipsSnapshot(t *testing.T) {
+	require := require.New(t)
+	conn, cleanup, _, _ := testserver.NewTestServer(require, 0, memdb.DisableGC, true, tf.EmptyDatastore)
+	t.Cleanup(cleanup)
+
+	_, err := v1.NewSchemaServiceClient(conn).WriteSchema(context.Background(), &v1.WriteSchemaRequest{
+		Schema: `
+			definition user {}
+
+			caveat only_weekdays(day int) {
+				day < 6
+			}
+
+			definition document {
+				relation viewer: user | user with only_weekdays
+				relation editor: user
+			}
+		`,
+	})
+	require.NoError(err)
+
+	client := v1.NewPermissionsServiceClient(conn)
+	var updates []*v1.RelationshipUpdate
+	for _, rel := range []string{
+		"document:a#viewer@user:alice",
+		"document:a#viewer@user:bob",
+		"document:b#viewer@user:alice",
+		"document:a#editor@user:alice",
+	} {
+		updates = append(updates, &v1.RelationshipUpdate{Operation: v1.RelationshipUpdate_OPERATION_CREATE, Relationship: tuple.MustParseV1Rel(rel)})
+	}
+	_, err = client.WriteRelationships(context.Background(), &v1.WriteRelationshipsRequest{Updates: updates})
+	require.NoError(err)
+
+	readRelationships := func() []string {
+		stream, err := client.ReadRelationships(context.Background(), &v1.ReadRelationshipsRequest{
+			Consistency:        &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}},
+			RelationshipFilter: &v1.RelationshipFilter{ResourceType: "document"},
+		})
+		require.NoError(err)
+
+		var rels []string
+		for {
+			resp, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				return rels
+			}
+			require.NoError(err)
+			rels = append(rels, tuple.MustV1RelString(resp.Relationship))
+		}
+	}
+
+	snapshot := func(rels ...string) []*v1.Relationship {
+		parsed := make([]*v1.Relationship, 0, len(rels))
+		for _, rel := range rels {
+			parsed = append(parsed, tuple.MustParseV1Rel(rel))
+		}
+		return parsed
+	}
+
+	reconciliation := reconciliationv1.NewReconciliationServiceClient(conn)
+	viewers := &v1.RelationshipFilter{ResourceType: "document", OptionalRelation: "viewer"}
+	resp, err := reconciliation.ApplyRelationshipsSnapshot(context.Background(), &reconciliationv1.ApplyRelationshipsSnapshotRequest{
+		RelationshipFilter: viewers,
+		Relationships: snapshot(
+			"document:a#viewer@user:alice",
+			"document:a#viewer@user:carol",
+			"document:b#viewer@user:alice[only_weekdays]",
+		),
+	})
+	require.NoError(err)
+	require.NotNil(resp.WrittenAt)
+	require.Equal(uint64(1), resp.RelationshipsCreated)
+	require.Equal(uint64(1), resp.RelationshipsUpdated)
+	require.Equal(uint64(1), resp.RelationshipsDeleted)
+
+	// Relationships outside of the scope are kept.
+	expected := []string{
+		"document:a#editor@user:alice",
+		"document:a#viewer@user:alice",
+		"document:a#viewer@user:carol",
+		"document:b#viewer@user:alice[only_weekdays]",
+	}
+	require.ElementsMatch(expected, readRelationships())
+
+	// Applying the same snapshot again changes nothing.
+	resp, err = reconciliation.ApplyRelationshipsSnapshot(context.Background(), &reconciliationv1.ApplyRelationshipsSnapshotRequest{
+		RelationshipFilter: viewers,
+		Relationships: snapshot(
+			"document:b#viewer@user:alice[only_weekdays]",
+			"document:a#viewer@user:carol",
+			"document:a#viewer@user:alice",
+		),
+	})
+	require.NoError(err)
+	require.Zero(resp.RelationshipsCreated + resp.RelationshipsUpdated + resp.RelationshipsDeleted)
+
+	// An empty snapshot deletes the whole scope.
+	resp, err = reconciliation.ApplyRelationshipsSnapshot(context.Background(), &reconciliationv1.ApplyRelationshipsSnapshotRequest{
+		RelationshipFilter: &v1.RelationshipFilter{ResourceType: "document", OptionalResourceId: "a"},
+	})
+	require.NoError(err)
+	require.Equal(uint64(3), resp.RelationshipsDeleted)
+	require.ElementsMatch([]string{"document:b#viewer@user:alice[only_weekdays]"}, readRelationships())
+
+	for _, tc := range []struct {
+		name          string
+		filter        *v1.RelationshipFilter
+		relationships []string
+		expectedCode  codes.Code
+		expectedError string
+	}{
+		{"missing resource type", &v1.RelationshipFilter{OptionalRelation: "viewer"}, nil, codes.InvalidArgument, "resource type of the relationship filter is required"},
+		{"unknown resource type", &v1.RelationshipFilter{ResourceType: "folder"}, nil, codes.FailedPrecondition, "object definition `folder` not found"},
+		{"outside of the scope", viewers, []string{"document:a#editor@user:alice"}, codes.InvalidArgument, "does not match the relationship filter"},
+		{"duplicate", viewers, []string{"document:a#viewer@user:alice", "document:a#viewer@user:alice[only_weekdays]"}, codes.InvalidArgument, "found more than once in the snapshot"},
+		{"invalid subject type", viewers, []string{"document:a#viewer@document:b"}, codes.InvalidArgument, "subjects of type `document` are not allowed"},
+	} {
+		_, err := reconciliation.ApplyRelationshipsSnapshot(context.Background(), &reconciliationv1.ApplyRelationshipsSnapshotRequest{
+			RelationshipFilter: tc.filter,
+			Relationships:      snapshot(tc.relationships...),
+		})
+		grpcutil.RequireStatus(t, tc.expectedCode, err)
+		require.ErrorContains(err, tc.expectedError, tc.name)
+	}
+
+	// Failed snapshots are not partially applied.
+	require.ElementsMatch([]string{"document:b#viewer@user:alice[only_weekdays]"}, readRelationships())
+}
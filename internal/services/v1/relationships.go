@@ -2,7 +2,9 @@ package v1
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
@@ -11,17 +13,18 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/structpb"
 
 	"github.com/authzed/spicedb/internal/dispatch"
+	log "github.com/authzed/spicedb/internal/logging"
 	"github.com/authzed/spicedb/internal/middleware"
 	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
 	"github.com/authzed/spicedb/internal/middleware/handwrittenvalidation"
 	"github.com/authzed/spicedb/internal/middleware/streamtimeout"
 	"github.com/authzed/spicedb/internal/middleware/usagemetrics"
 	"github.com/authzed/spicedb/internal/namespace"
-	"github.com/authzed/spicedb/internal/relationships"
 	"github.com/authzed/spicedb/internal/services/shared"
 	"github.com/authzed/spicedb/pkg/cursor"
 	"github.com/authzed/spicedb/pkg/datastore"
@@ -31,6 +34,7 @@ import (
 	"github.com/authzed/spicedb/pkg/genutil/mapz"
 	"github.com/authzed/spicedb/pkg/middleware/consistency"
 	dispatchv1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+	"github.com/authzed/spicedb/pkg/requestmeta"
 	"github.com/authzed/spicedb/pkg/tuple"
 	"github.com/authzed/spicedb/pkg/zedtoken"
 )
@@ -51,6 +55,11 @@ type PermissionsServerConfig struct {
 	// WriteRelationships call.
 	MaxUpdatesPerWrite uint16
 
+	// MaxUpdatesPerChunkedWrite holds the maximum number of updates allowed per
+	// WriteRelationships call in chunked mode, in which the updates are applied in
+	// transactions of at most MaxUpdatesPerWrite updates. Zero disables chunked mode.
+	MaxUpdatesPerChunkedWrite uint32
+
 	// MaxPreconditionsCount holds the maximum number of preconditions allowed
 	// on a WriteRelationships or DeleteRelationships call.
 	MaxPreconditionsCount uint16
@@ -98,6 +107,23 @@ type PermissionsServerConfig struct {
 
 	// ExpiringRelationshipsEnabled defines whether or not expiring relationships are enabled.
 	ExpiringRelationshipsEnabled bool
+
+	// WriteProvenanceEnabled defines whether the provenance of writes (request ID and caller
+	// information) is recorded in the transaction metadata, and thus surfaced on Watch.
+	WriteProvenanceEnabled bool
+
+	// IdempotencyKeyTTL is the duration for which WriteRelationships calls with an idempotency
+	// key are remembered in the datastore, such that retries of the calls are not applied again.
+	// Zero disables idempotency keys.
+	IdempotencyKeyTTL time.Duration
+
+	// MaxIdempotencyKeys is the maximum number of idempotency keys cached in memory with the
+	// revisions of their writes.
+	MaxIdempotencyKeys uint32
+
+	// CaveatPrefilteringEnabled defines whether relationships whose caveats evaluate to false
+	// under the context of a check are excluded when loading relationships for the check.
+	CaveatPrefilteringEnabled bool
 }
 
 // NewPermissionsServer creates a PermissionsServiceServer instance.
@@ -108,6 +134,7 @@ func NewPermissionsServer(
 	configWithDefaults := PermissionsServerConfig{
 		MaxPreconditionsCount:           defaultIfZero(config.MaxPreconditionsCount, 1000),
 		MaxUpdatesPerWrite:              defaultIfZero(config.MaxUpdatesPerWrite, 1000),
+		MaxUpdatesPerChunkedWrite:       config.MaxUpdatesPerChunkedWrite,
 		MaximumAPIDepth:                 defaultIfZero(config.MaximumAPIDepth, 50),
 		StreamingAPITimeout:             defaultIfZero(config.StreamingAPITimeout, 30*time.Second),
 		MaxCaveatContextSize:            defaultIfZero(config.MaxCaveatContextSize, 4096),
@@ -119,6 +146,15 @@ func NewPermissionsServer(
 		MaxBulkExportRelationshipsLimit: defaultIfZero(config.MaxBulkExportRelationshipsLimit, 100_000),
 		DispatchChunkSize:               defaultIfZero(config.DispatchChunkSize, 100),
 		ExpiringRelationshipsEnabled:    true,
+		WriteProvenanceEnabled:          config.WriteProvenanceEnabled,
+		IdempotencyKeyTTL:               config.IdempotencyKeyTTL,
+		MaxIdempotencyKeys:              defaultIfZero(config.MaxIdempotencyKeys, 10_000),
+		CaveatPrefilteringEnabled:       config.CaveatPrefilteringEnabled,
+	}
+
+	var writes *idempotentWrites
+	if configWithDefaults.IdempotencyKeyTTL > 0 {
+		writes = newIdempotentWrites(configWithDefaults.IdempotencyKeyTTL, int(configWithDefaults.MaxIdempotencyKeys))
 	}
 
 	return &permissionServer{
@@ -143,7 +179,10 @@ func NewPermissionsServer(
 			maxConcurrency:       configWithDefaults.MaxCheckBulkConcurrency,
 			dispatch:             dispatch,
 			dispatchChunkSize:    configWithDefaults.DispatchChunkSize,
+			prefilterCaveats:     configWithDefaults.CaveatPrefilteringEnabled,
 		},
+		idempotentWrites: writes,
+		contention:       newContentionBackoff(),
 	}
 }
 
@@ -155,6 +194,13 @@ type permissionServer struct {
 	config   PermissionsServerConfig
 
 	bulkChecker *bulkChecker
+
+	// idempotentWrites remembers the writes with an idempotency key. It is nil if idempotency keys
+	// are disabled.
+	idempotentWrites *idempotentWrites
+
+	// contention tracks the writes which failed due to contention, to guide their retries.
+	contention *contentionBackoff
 }
 
 func (ps *permissionServer) ReadRelationships(req *v1.ReadRelationshipsRequest, resp v1.PermissionsService_ReadRelationshipsServer) error {
@@ -162,6 +208,11 @@ func (ps *permissionServer) ReadRelationships(req *v1.ReadRelationshipsRequest,
 		return ps.rewriteError(resp.Context(), NewExceedsMaximumLimitErr(uint64(req.OptionalLimit), uint64(ps.config.MaxReadRelationshipsLimit)))
 	}
 
+	withVersions := relationshipVersionsRequested(resp.Context())
+	if withVersions && (req.OptionalLimit == 0 || req.OptionalLimit > maxRelationshipVersionsPerRead) {
+		return ps.rewriteError(resp.Context(), NewRelationshipVersionsLimitErr(req.OptionalLimit, maxRelationshipVersionsPerRead))
+	}
+
 	ctx := resp.Context()
 	atRevision, revisionReadAt, err := consistency.RevisionFromContext(ctx)
 	if err != nil {
@@ -224,6 +275,7 @@ func (ps *permissionServer) ReadRelationships(req *v1.ReadRelationshipsRequest,
 		pageSize,
 		options.ByResource,
 		startCursor,
+		options.WithIncludeWriteRevisions(withVersions),
 	)
 	if err != nil {
 		return ps.rewriteError(ctx, err)
@@ -244,6 +296,8 @@ func (ps *permissionServer) ReadRelationships(req *v1.ReadRelationshipsRequest,
 		Sections:        []string{""},
 	}
 
+	var versions []string
+
 	var returnedCount uint64
 	for rel, err := range it {
 		if err != nil {
@@ -260,6 +314,14 @@ func (ps *permissionServer) ReadRelationships(req *v1.ReadRelationshipsRequest,
 			return ps.rewriteError(ctx, err)
 		}
 
+		if withVersions {
+			version, err := tuple.VersionToken(rel)
+			if err != nil {
+				return ps.rewriteError(ctx, err)
+			}
+			versions = append(versions, version)
+		}
+
 		tuple.CopyToV1Relationship(rel, response.Relationship)
 		response.AfterResultCursor = encodedCursor
 
@@ -269,23 +331,70 @@ func (ps *permissionServer) ReadRelationships(req *v1.ReadRelationshipsRequest,
 		}
 		returnedCount++
 	}
+
+	if len(versions) > 0 {
+		resp.SetTrailer(metadata.Pairs(requestmeta.RelationshipVersionsTrailerKey, strings.Join(versions, ",")))
+	}
 	return nil
 }
 
 func (ps *permissionServer) WriteRelationships(ctx context.Context, req *v1.WriteRelationshipsRequest) (*v1.WriteRelationshipsResponse, error) {
+	idempotencyKey, ok := idempotencyKeyFromContext(ctx)
+	if !ok || ps.idempotentWrites == nil || writeValidationModeFromContext(ctx).validateOnly {
+		return ps.writeRelationships(ctx, req, nil)
+	}
+
+	requestHash, err := computeWriteRelationshipsRequestHash(req)
+	if err != nil {
+		return nil, ps.rewriteError(ctx, err)
+	}
+
+	claim := &idempotencyClaim{
+		idempotencyKey: idempotencyKey,
+		requestHash:    requestHash,
+		ttl:            ps.idempotentWrites.ttl,
+	}
+	resp, err := ps.idempotentWrites.write(ctx, idempotencyKey, requestHash, func(ctx context.Context) (*v1.WriteRelationshipsResponse, error) {
+		return ps.writeRelationships(ctx, req, claim)
+	})
+	if err != nil {
+		return nil, ps.rewriteError(ctx, err)
+	}
+	return resp, nil
+}
+
+// writeRelationships applies the write, claiming the idempotency key of the claim, if not nil, in
+// the transaction of the write.
+func (ps *permissionServer) writeRelationships(ctx context.Context, req *v1.WriteRelationshipsRequest, claim *idempotencyClaim) (*v1.WriteRelationshipsResponse, error) {
 	if err := ps.validateTransactionMetadata(req.OptionalTransactionMetadata); err != nil {
 		return nil, ps.rewriteError(ctx, err)
 	}
 
+	transactionMetadata, err := ps.transactionMetadataForWrite(ctx, req.OptionalTransactionMetadata)
+	if err != nil {
+		return nil, ps.rewriteError(ctx, err)
+	}
+
+	if claim != nil {
+		transactionMetadata = withIdempotencyKey(transactionMetadata, claim.idempotencyKey)
+	}
+
 	ds := datastoremw.MustFromContext(ctx)
+	validationMode := writeValidationModeFromContext(ctx)
 
 	span := trace.SpanFromContext(ctx)
 	span.AddEvent("validating mutations")
 	// Ensure that the updates and preconditions are not over the configured limits.
-	if len(req.Updates) > int(ps.config.MaxUpdatesPerWrite) {
+	chunked := chunkedWriteRequested(ctx) && ps.config.MaxUpdatesPerChunkedWrite > 0
+	maxUpdates := uint64(ps.config.MaxUpdatesPerWrite)
+	if chunked {
+		maxUpdates = max(maxUpdates, uint64(ps.config.MaxUpdatesPerChunkedWrite))
+	}
+
+	if uint64(len(req.Updates)) > maxUpdates {
 		return nil, ps.rewriteError(
 			ctx,
-			NewExceedsMaximumUpdatesErr(uint64(len(req.Updates)), uint64(ps.config.MaxUpdatesPerWrite)),
+			NewExceedsMaximumUpdatesErr(uint64(len(req.Updates)), maxUpdates),
 		)
 	}
 
@@ -329,6 +438,43 @@ func (ps *permissionServer) WriteRelationships(ctx context.Context, req *v1.Writ
 		return nil, ps.rewriteError(ctx, err)
 	}
 
+	expectedVersions, err := expectedRelationshipVersionsFromContext(ctx, len(relUpdates))
+	if err != nil {
+		return nil, ps.rewriteError(ctx, err)
+	}
+
+	if validationMode.validateOnly {
+		span.AddEvent("validate only")
+		revision, err := ps.validateWriteOnly(ctx, ds, req.OptionalPreconditions, relUpdates, expectedVersions, validationMode)
+		if err != nil {
+			return nil, ps.rewriteError(ctx, err)
+		}
+
+		return &v1.WriteRelationshipsResponse{
+			WrittenAt: zedtoken.MustNewFromRevision(revision),
+		}, nil
+	}
+
+	contentionKey := contentionKeyForUpdates(relUpdates)
+	if chunked && len(relUpdates) > int(ps.config.MaxUpdatesPerWrite) {
+		span.AddEvent("chunked write")
+		revision, err := ps.writeRelationshipsInChunks(ctx, ds, req.OptionalPreconditions, relUpdates, expectedVersions, validationMode, claim, options.WithMetadata(transactionMetadata))
+		var applied idempotentWriteAppliedError
+		if errors.As(err, &applied) {
+			return ps.alreadyAppliedWrite(ctx, ds, applied.revision)
+		}
+		if err != nil {
+			return nil, ps.rewriteError(ctx, ps.contention.withRetryGuidance(err, contentionKey))
+		}
+		ps.contention.succeeded(contentionKey)
+		ps.recordWriteRevision(ctx, ds, claim, revision)
+
+		observeWriteUpdateCounts(req.Updates)
+		return &v1.WriteRelationshipsResponse{
+			WrittenAt: zedtoken.MustNewFromRevision(revision),
+		}, nil
+	}
+
 	revision, err := ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
 		span.AddEvent("preconditions")
 
@@ -341,7 +487,7 @@ func (ps *permissionServer) WriteRelationships(ctx context.Context, req *v1.Writ
 
 		// Validate the updates.
 		span.AddEvent("validate updates")
-		err := relationships.ValidateRelationshipUpdates(ctx, rwt, relUpdates)
+		err := ps.validateRelationshipUpdates(ctx, rwt, relUpdates, validationMode)
 		if err != nil {
 			return ps.rewriteError(ctx, err)
 		}
@@ -361,26 +507,73 @@ func (ps *permissionServer) WriteRelationships(ctx context.Context, req *v1.Writ
 			return err
 		}
 
+		if err := checkExpectedRelationshipVersions(ctx, rwt, relUpdates, expectedVersions); err != nil {
+			return err
+		}
+
+		if err := claim.claim(ctx, rwt, false); err != nil {
+			return err
+		}
+
 		span.AddEvent("write relationships")
 		return rwt.WriteRelationships(ctx, relUpdates)
-	}, options.WithMetadata(req.OptionalTransactionMetadata))
+	}, options.WithMetadata(transactionMetadata))
+	var applied idempotentWriteAppliedError
+	if errors.As(err, &applied) {
+		return ps.alreadyAppliedWrite(ctx, ds, applied.revision)
+	}
+	if err != nil {
+		return nil, ps.rewriteError(ctx, ps.contention.withRetryGuidance(err, contentionKey))
+	}
+	ps.contention.succeeded(contentionKey)
+	ps.recordWriteRevision(ctx, ds, claim, revision)
+
+	observeWriteUpdateCounts(req.Updates)
+	return &v1.WriteRelationshipsResponse{
+		WrittenAt: zedtoken.MustNewFromRevision(revision),
+	}, nil
+}
+
+// alreadyAppliedWrite returns the response to a write whose idempotency key was claimed by a
+// completed write of the same request, such as on another node or before a restart, at the
+// revision of that write recorded in the claim. If the revision was not yet recorded, the head
+// revision, at or after it, is returned instead.
+func (ps *permissionServer) alreadyAppliedWrite(ctx context.Context, ds datastore.Datastore, recordedRevision string) (*v1.WriteRelationshipsResponse, error) {
+	var revision datastore.Revision
+	var err error
+	if recordedRevision != "" {
+		revision, err = ds.RevisionFromString(recordedRevision)
+	} else {
+		revision, err = ds.HeadRevision(ctx)
+	}
 	if err != nil {
 		return nil, ps.rewriteError(ctx, err)
 	}
 
-	// Log a metric of the counts of the different kinds of update operations.
+	return &v1.WriteRelationshipsResponse{
+		WrittenAt: zedtoken.MustNewFromRevision(revision),
+	}, nil
+}
+
+// recordWriteRevision records the revision at which a write was committed in the claim of its
+// idempotency key, if any. The write is applied whether or not its revision is recorded, so
+// failing to record it is only logged.
+func (ps *permissionServer) recordWriteRevision(ctx context.Context, ds datastore.Datastore, claim *idempotencyClaim, revision datastore.Revision) {
+	if err := claim.recordRevision(context.WithoutCancel(ctx), ds, revision); err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("failed to record the revision of an idempotent write")
+	}
+}
+
+// observeWriteUpdateCounts logs a metric of the counts of the different kinds of update operations.
+func observeWriteUpdateCounts(updates []*v1.RelationshipUpdate) {
 	updateCountByOperation := make(map[v1.RelationshipUpdate_Operation]int, 0)
-	for _, update := range req.Updates {
+	for _, update := range updates {
 		updateCountByOperation[update.Operation]++
 	}
 
 	for kind, count := range updateCountByOperation {
 		writeUpdateCounter.WithLabelValues(v1.RelationshipUpdate_Operation_name[int32(kind)]).Observe(float64(count))
 	}
-
-	return &v1.WriteRelationshipsResponse{
-		WrittenAt: zedtoken.MustNewFromRevision(revision),
-	}, nil
 }
 
 func (ps *permissionServer) validateTransactionMetadata(metadata *structpb.Struct) error {
@@ -405,6 +598,11 @@ func (ps *permissionServer) DeleteRelationships(ctx context.Context, req *v1.Del
 		return nil, ps.rewriteError(ctx, err)
 	}
 
+	transactionMetadata, err := ps.transactionMetadataForWrite(ctx, req.OptionalTransactionMetadata)
+	if err != nil {
+		return nil, ps.rewriteError(ctx, err)
+	}
+
 	if len(req.OptionalPreconditions) > int(ps.config.MaxPreconditionsCount) {
 		return nil, ps.rewriteError(
 			ctx,
@@ -491,10 +689,12 @@ func (ps *permissionServer) DeleteRelationships(ctx context.Context, req *v1.Del
 		// Otherwise, kick off an unlimited deletion.
 		_, err = rwt.DeleteRelationships(ctx, req.RelationshipFilter)
 		return err
-	}, options.WithMetadata(req.OptionalTransactionMetadata))
+	}, options.WithMetadata(transactionMetadata))
+	contentionKey := contentionKeyForFilter(req.RelationshipFilter)
 	if err != nil {
-		return nil, ps.rewriteError(ctx, err)
+		return nil, ps.rewriteError(ctx, ps.contention.withRetryGuidance(err, contentionKey))
 	}
+	ps.contention.succeeded(contentionKey)
 
 	return &v1.DeleteRelationshipsResponse{
 		DeletedAt:        zedtoken.MustNewFromRevision(revision),
@@ -11,6 +11,7 @@ import (
 	"testing"
 	"time"
 
+	authzedrequestmeta "github.com/authzed/authzed-go/pkg/requestmeta"
 	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
 	"github.com/authzed/grpcutil"
 	"github.com/ccoveille/go-safecast"
@@ -26,7 +27,9 @@ import (
 	tf "github.com/authzed/spicedb/internal/testfixtures"
 	"github.com/authzed/spicedb/internal/testserver"
 	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/errorcodes"
 	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/requestmeta"
 	"github.com/authzed/spicedb/pkg/spiceerrors"
 	"github.com/authzed/spicedb/pkg/tuple"
 	"github.com/authzed/spicedb/pkg/zedtoken"
@@ -397,6 +400,9 @@ func TestWriteRelationships(t *testing.T) {
 		"precondition_subject_relation",
 		"precondition_subject_type",
 	)
+	code, ok := errorcodes.FromError(err)
+	require.True(ok)
+	require.Equal(errorcodes.PreconditionMustMatchFailed, code)
 
 	existing := tuple.MustParse(tf.StandardRelationships[0])
 	require.NotNil(existing)
@@ -1855,3 +1861,86 @@ func TestManyConcurrentWriteRelationshipsReturnsSerializationErrorOnMemdb(t *tes
 	require.ErrorContains(werr, "serialization max retries exceeded")
 	grpcutil.RequireStatus(t, codes.DeadlineExceeded, werr)
 }
+
+func TestWriteRelationshipsIdempotencyKey(t *testing.T) {
+	config := testserver.DefaultTestServerConfig
+	config.WriteIdempotencyKeyTTL = time.Minute
+	conn, cleanup, _, _ := testserver.NewTestServerWithConfig(require.New(t), 0, memdb.DisableGC, true, config, tf.StandardDatastoreWithData)
+	t.Cleanup(cleanup)
+	client := v1.NewPermissionsServiceClient(conn)
+
+	req := &v1.WriteRelationshipsRequest{
+		Updates: []*v1.RelationshipUpdate{
+			{Operation: v1.RelationshipUpdate_OPERATION_CREATE, Relationship: rel("document", "newdoc", "viewer", "user", "tom", "")},
+		},
+	}
+
+	ctx := authzedrequestmeta.SetRequestHeaders(context.Background(), map[authzedrequestmeta.RequestMetadataHeaderKey]string{
+		requestmeta.RequestIdempotencyKey: "somekey",
+	})
+	resp, err := client.WriteRelationships(ctx, req)
+	require.NoError(t, err)
+
+	// A retry returns the original revision instead of failing to create the relationship again.
+	retried, err := client.WriteRelationships(ctx, req)
+	require.NoError(t, err)
+	require.Equal(t, resp.WrittenAt.Token, retried.WrittenAt.Token)
+
+	_, err = client.WriteRelationships(context.Background(), req)
+	require.Equal(t, codes.AlreadyExists, status.Code(err))
+
+	// The key cannot be used for another request.
+	_, err = client.WriteRelationships(ctx, &v1.WriteRelationshipsRequest{
+		Updates: []*v1.RelationshipUpdate{
+			{Operation: v1.RelationshipUpdate_OPERATION_CREATE, Relationship: rel("document", "newdoc", "viewer", "user", "sarah", "")},
+		},
+	})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	require.ErrorContains(t, err, "was already used for another write request")
+}
+
+func TestWriteRelationshipsIdempotencyKeyAcrossServers(t *testing.T) {
+	config := testserver.DefaultTestServerConfig
+	config.WriteIdempotencyKeyTTL = time.Minute
+	conn, cleanup, ds, _ := testserver.NewTestServerWithConfig(require.New(t), 0, memdb.DisableGC, true, config, tf.StandardDatastoreWithData)
+	t.Cleanup(cleanup)
+
+	// Another server sharing the datastore, such as another node or this one after a restart.
+	otherConn, otherCleanup, _, _ := testserver.NewTestServerWithConfigAndDatastore(require.New(t), 0, memdb.DisableGC, true, config, ds,
+		func(ds datastore.Datastore, _ *require.Assertions) (datastore.Datastore, datastore.Revision) {
+			return ds, nil
+		})
+	t.Cleanup(otherCleanup)
+
+	req := &v1.WriteRelationshipsRequest{
+		Updates: []*v1.RelationshipUpdate{
+			{Operation: v1.RelationshipUpdate_OPERATION_CREATE, Relationship: rel("document", "newdoc", "viewer", "user", "tom", "")},
+		},
+	}
+
+	ctx := authzedrequestmeta.SetRequestHeaders(context.Background(), map[authzedrequestmeta.RequestMetadataHeaderKey]string{
+		requestmeta.RequestIdempotencyKey: "somekey",
+	})
+	written, err := v1.NewPermissionsServiceClient(conn).WriteRelationships(ctx, req)
+	require.NoError(t, err)
+
+	// Another write moves the head revision past that of the write.
+	_, err = v1.NewPermissionsServiceClient(conn).WriteRelationships(context.Background(), &v1.WriteRelationshipsRequest{
+		Updates: []*v1.RelationshipUpdate{
+			{Operation: v1.RelationshipUpdate_OPERATION_CREATE, Relationship: rel("document", "otherdoc", "viewer", "user", "tom", "")},
+		},
+	})
+	require.NoError(t, err)
+
+	// The retry is not applied again by the other server, which returns the revision of the write.
+	retried, err := v1.NewPermissionsServiceClient(otherConn).WriteRelationships(ctx, req)
+	require.NoError(t, err)
+	require.Equal(t, written.WrittenAt.Token, retried.WrittenAt.Token)
+
+	_, err = v1.NewPermissionsServiceClient(otherConn).WriteRelationships(ctx, &v1.WriteRelationshipsRequest{
+		Updates: []*v1.RelationshipUpdate{
+			{Operation: v1.RelationshipUpdate_OPERATION_CREATE, Relationship: rel("document", "newdoc", "viewer", "user", "sarah", "")},
+		},
+	})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
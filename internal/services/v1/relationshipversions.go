@@ -0,0 +1,119 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/options"
+	"github.com/authzed/spicedb/pkg/requestmeta"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// maxRelationshipVersionsPerRead is the maximum limit of a ReadRelationships request returning the
+// versions of the relationships it reads, so that its trailer fits within the limits of gRPC and
+// of proxies on the size of headers.
+const maxRelationshipVersionsPerRead = 100
+
+// relationshipVersionsRequested returns whether the version tokens of the relationships read are
+// requested in the headers of a ReadRelationships request, with a true boolean value.
+func relationshipVersionsRequested(ctx context.Context) bool {
+	for _, value := range metadata.ValueFromIncomingContext(ctx, string(requestmeta.RequestRelationshipVersions)) {
+		if requested, err := strconv.ParseBool(value); err == nil && requested {
+			return true
+		}
+	}
+	return false
+}
+
+// expectedRelationshipVersionsFromContext returns the expected versions of the relationships of
+// the updates of a WriteRelationships request, by update index, from its headers.
+func expectedRelationshipVersionsFromContext(ctx context.Context, updateCount int) (map[int]string, error) {
+	values := metadata.ValueFromIncomingContext(ctx, requestmeta.ExpectedRelationshipVersionHeaderKey)
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	expected := make(map[int]string, len(values))
+	for _, value := range values {
+		indexStr, token, ok := strings.Cut(value, "=")
+		if !ok || token == "" {
+			return nil, NewInvalidExpectedRelationshipVersionErr(value, "expected `<update index>=<version token>`")
+		}
+
+		index, err := strconv.Atoi(indexStr)
+		if err != nil || index < 0 || index >= updateCount {
+			return nil, NewInvalidExpectedRelationshipVersionErr(value, fmt.Sprintf("update index must be between 0 and %d", updateCount-1))
+		}
+
+		if _, ok := expected[index]; ok {
+			return nil, NewInvalidExpectedRelationshipVersionErr(value, "found multiple expected versions for the update")
+		}
+		expected[index] = token
+	}
+
+	return expected, nil
+}
+
+// checkExpectedRelationshipVersions checks that the relationship of each update with an expected
+// version exists at that version, in the context of a datastore read-write transaction, or of a
+// snapshot when validating a write.
+func checkExpectedRelationshipVersions(
+	ctx context.Context,
+	reader datastore.Reader,
+	updates []tuple.RelationshipUpdate,
+	expected map[int]string,
+) error {
+	indexes := make([]int, 0, len(expected))
+	for index := range expected {
+		indexes = append(indexes, index)
+	}
+	slices.Sort(indexes)
+
+	for _, index := range indexes {
+		rel := updates[index].Relationship
+		filter := datastore.RelationshipsFilter{
+			OptionalResourceType:     rel.Resource.ObjectType,
+			OptionalResourceIds:      []string{rel.Resource.ObjectID},
+			OptionalResourceRelation: rel.Resource.Relation,
+			OptionalSubjectsSelectors: []datastore.SubjectsSelector{
+				{
+					OptionalSubjectType: rel.Subject.ObjectType,
+					OptionalSubjectIds:  []string{rel.Subject.ObjectID},
+					RelationFilter:      datastore.SubjectRelationFilter{}.WithRelation(rel.Subject.Relation),
+				},
+			},
+		}
+
+		iter, err := reader.QueryRelationships(ctx, filter, options.WithLimit(&limitOne), options.WithIncludeWriteRevisions(true))
+		if err != nil {
+			return fmt.Errorf("error reading relationships: %w", err)
+		}
+
+		current, found, err := datastore.FirstRelationshipIn(iter)
+		if err != nil {
+			return fmt.Errorf("error reading relationships from iterator: %w", err)
+		}
+
+		relString := tuple.StringWithoutCaveatOrExpiration(rel)
+		if !found {
+			return NewRelationshipVersionMismatchErr(index, relString, expected[index], "")
+		}
+
+		currentVersion, err := tuple.VersionToken(current)
+		if err != nil {
+			return err
+		}
+
+		if currentVersion != expected[index] {
+			return NewRelationshipVersionMismatchErr(index, relString, expected[index], currentVersion)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,185 @@
+package v1_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	authzedrequestmeta "github.com/authzed/authzed-go/pkg/requestmeta"
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	tf "github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/internal/testserver"
+	"github.com/authzed/spicedb/pkg/requestmeta"
+)
+
+func TestRelationshipVersions(t *testing.T) {
+	conn, cleanup, _, _ := testserver.NewTestServer(require.New(t), 0, memdb.DisableGC, true, tf.StandardDatastoreWithCaveatedData)
+	t.Cleanup(cleanup)
+	client := v1.NewPermissionsServiceClient(conn)
+
+	readVersion := func(t *testing.T) (*v1.Relationship, string) {
+		var trailer metadata.MD
+		ctx := authzedrequestmeta.AddRequestHeaders(context.Background(), requestmeta.RequestRelationshipVersions)
+		stream, err := client.ReadRelationships(ctx, &v1.ReadRelationshipsRequest{
+			Consistency:        &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}},
+			RelationshipFilter: &v1.RelationshipFilter{ResourceType: "document", OptionalResourceId: "caveatedplan"},
+			OptionalLimit:      100,
+		}, grpc.Trailer(&trailer))
+		require.NoError(t, err)
+
+		var relationships []*v1.Relationship
+		for {
+			resp, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			require.NoError(t, err)
+			relationships = append(relationships, resp.Relationship)
+		}
+
+		require.Len(t, relationships, 1)
+		require.Len(t, trailer.Get(requestmeta.RelationshipVersionsTrailerKey), 1)
+		versions := strings.Split(trailer.Get(requestmeta.RelationshipVersionsTrailerKey)[0], ",")
+		require.Len(t, versions, 1)
+
+		return relationships[0], versions[0]
+	}
+
+	writeWithVersion := func(relationship *v1.Relationship, version string) error {
+		ctx := requestmeta.WithExpectedRelationshipVersion(context.Background(), 0, version)
+		_, err := client.WriteRelationships(ctx, &v1.WriteRelationshipsRequest{
+			Updates: []*v1.RelationshipUpdate{
+				{Operation: v1.RelationshipUpdate_OPERATION_TOUCH, Relationship: relationship},
+			},
+		})
+		return err
+	}
+
+	relationship, version := readVersion(t)
+	updated := mustRelWithCaveatAndContext("document", "caveatedplan", "caveated_viewer", "user", "caveatedguy", "", "test", map[string]any{"expectedSecret": "5678"})
+
+	// Writing with the read version succeeds and changes the version.
+	require.NoError(t, writeWithVersion(updated, version))
+	_, newVersion := readVersion(t)
+	require.NotEqual(t, version, newVersion)
+
+	// Writing with the stale version fails, without applying the update.
+	err := writeWithVersion(relationship, version)
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+	require.ErrorContains(t, err, "is at version `"+newVersion+"`")
+
+	_, currentVersion := readVersion(t)
+	require.Equal(t, newVersion, currentVersion)
+
+	// Writing the relationship back to its prior caveat context changes its version all the same.
+	require.NoError(t, writeWithVersion(relationship, newVersion))
+	_, restoredVersion := readVersion(t)
+	require.NotEqual(t, version, restoredVersion)
+
+	err = writeWithVersion(updated, version)
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	// Writing a relationship which does not exist with an expected version fails.
+	err = writeWithVersion(rel("document", "newdoc", "viewer", "user", "tom", ""), version)
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+	require.ErrorContains(t, err, "does not exist")
+
+	// Expected versions must refer to an update.
+	ctx := requestmeta.WithExpectedRelationshipVersion(context.Background(), 1, version)
+	_, err = client.WriteRelationships(ctx, &v1.WriteRelationshipsRequest{
+		Updates: []*v1.RelationshipUpdate{
+			{Operation: v1.RelationshipUpdate_OPERATION_TOUCH, Relationship: updated},
+		},
+	})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestRelationshipVersionsRequireLimit(t *testing.T) {
+	conn, cleanup, _, _ := testserver.NewTestServer(require.New(t), 0, memdb.DisableGC, true, tf.StandardDatastoreWithData)
+	t.Cleanup(cleanup)
+	client := v1.NewPermissionsServiceClient(conn)
+
+	readVersions := func(limit uint32) ([]string, error) {
+		var trailer metadata.MD
+		ctx := authzedrequestmeta.AddRequestHeaders(context.Background(), requestmeta.RequestRelationshipVersions)
+		stream, err := client.ReadRelationships(ctx, &v1.ReadRelationshipsRequest{
+			Consistency:        &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}},
+			RelationshipFilter: &v1.RelationshipFilter{ResourceType: "document"},
+			OptionalLimit:      limit,
+		}, grpc.Trailer(&trailer))
+		if err != nil {
+			return nil, err
+		}
+
+		for {
+			if _, err := stream.Recv(); err != nil {
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				return nil, err
+			}
+		}
+		return strings.Split(trailer.Get(requestmeta.RelationshipVersionsTrailerKey)[0], ","), nil
+	}
+
+	for _, limit := range []uint32{0, 101} {
+		_, err := readVersions(limit)
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+		require.ErrorContains(t, err, "relationship versions require a limit between 1 and 100")
+	}
+
+	versions, err := readVersions(2)
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+}
+
+func TestRelationshipVersionsRequireTrueHeader(t *testing.T) {
+	conn, cleanup, _, _ := testserver.NewTestServer(require.New(t), 0, memdb.DisableGC, true, tf.StandardDatastoreWithData)
+	t.Cleanup(cleanup)
+	client := v1.NewPermissionsServiceClient(conn)
+
+	for _, tc := range []struct {
+		value     string
+		requested bool
+	}{
+		{"1", true},
+		{"true", true},
+		{"0", false},
+		{"false", false},
+		{"", false},
+	} {
+		t.Run(tc.value, func(t *testing.T) {
+			var trailer metadata.MD
+			ctx := metadata.AppendToOutgoingContext(context.Background(), string(requestmeta.RequestRelationshipVersions), tc.value)
+			stream, err := client.ReadRelationships(ctx, &v1.ReadRelationshipsRequest{
+				Consistency:        &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}},
+				RelationshipFilter: &v1.RelationshipFilter{ResourceType: "document"},
+				OptionalLimit:      2,
+			}, grpc.Trailer(&trailer))
+			require.NoError(t, err)
+
+			for {
+				_, err := stream.Recv()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				require.NoError(t, err)
+			}
+
+			if tc.requested {
+				require.Len(t, trailer.Get(requestmeta.RelationshipVersionsTrailerKey), 1)
+			} else {
+				require.Empty(t, trailer.Get(requestmeta.RelationshipVersionsTrailerKey))
+			}
+		})
+	}
+}
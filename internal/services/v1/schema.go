@@ -131,9 +131,21 @@ func (ss *schemaServer) WriteSchema(ctx context.Context, in *v1.WriteSchemaReque
 		return nil, ss.rewriteError(ctx, err)
 	}
 
-	// Update the schema.
+	mode := schemaWriteModeFromContext(ctx)
+	if mode.dryRun {
+		return ss.dryRunWriteSchema(ctx, ds, validated)
+	}
+
+	// Update the schema, deleting the blocking relationships in the same transaction if requested.
+	var deleted uint64
 	revision, err := ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
-		applied, err := shared.ApplySchemaChanges(ctx, rwt, validated)
+		var applied *shared.AppliedSchemaChanges
+		var err error
+		if mode.deleteBlocking {
+			applied, deleted, err = shared.ApplySchemaChangesDeletingBlockingRelationships(ctx, rwt, validated, schemaMigrationDeleteBatchSize)
+		} else {
+			applied, err = shared.ApplySchemaChanges(ctx, rwt, validated)
+		}
 		if err != nil {
 			return err
 		}
@@ -149,9 +161,18 @@ func (ss *schemaServer) WriteSchema(ctx context.Context, in *v1.WriteSchemaReque
 		return nil
 	})
 	if err != nil {
+		if mode.countBlocking {
+			err = ss.withBlockingRelationshipCounts(ctx, ds, validated, err)
+		}
 		return nil, ss.rewriteError(ctx, err)
 	}
 
+	if mode.deleteBlocking {
+		if err := setDeletedRelationshipsHeader(ctx, deleted); err != nil {
+			return nil, ss.rewriteError(ctx, err)
+		}
+	}
+
 	return &v1.WriteSchemaResponse{
 		WrittenAt: zedtoken.MustNewFromRevision(revision),
 	}, nil
@@ -2,19 +2,28 @@ package v1_test
 
 import (
 	"context"
+	"encoding/json"
+	"io"
 	"testing"
 
 	"google.golang.org/protobuf/types/known/structpb"
 
+	authzedrequestmeta "github.com/authzed/authzed-go/pkg/requestmeta"
 	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
 	"github.com/authzed/grpcutil"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 
 	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/internal/services/shared"
 	tf "github.com/authzed/spicedb/internal/testfixtures"
 	"github.com/authzed/spicedb/internal/testserver"
 	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/requestmeta"
 	"github.com/authzed/spicedb/pkg/spiceerrors"
 	"github.com/authzed/spicedb/pkg/tuple"
 )
@@ -702,3 +711,109 @@ func TestSchemaChangeExpirationAllowed(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, newSchema, readback.SchemaText)
 }
+
+func TestSchemaWriteDryRun(t *testing.T) {
+	conn, cleanup, _, _ := testserver.NewTestServer(require.New(t), 0, memdb.DisableGC, true, tf.StandardDatastoreWithData)
+	t.Cleanup(cleanup)
+	client := v1.NewSchemaServiceClient(conn)
+
+	original, err := client.ReadSchema(context.Background(), &v1.ReadSchemaRequest{})
+	require.NoError(t, err)
+
+	ctx := authzedrequestmeta.AddRequestHeaders(context.Background(), requestmeta.RequestSchemaDryRun)
+	var header metadata.MD
+	resp, err := client.WriteSchema(ctx, &v1.WriteSchemaRequest{
+		Schema: `definition user {}`,
+	}, grpc.Header(&header))
+	require.NoError(t, err)
+	require.NotNil(t, resp.WrittenAt)
+
+	encoded := header.Get(requestmeta.SchemaImpactResponseHeaderKey)
+	require.Len(t, encoded, 1)
+
+	var impact shared.SchemaChangesImpact
+	require.NoError(t, json.Unmarshal([]byte(encoded[0]), &impact))
+	require.False(t, impact.Writable)
+	require.NotEmpty(t, impact.InvalidatedRelationships)
+	require.Positive(t, impact.InvalidatedRelationships[0].Count)
+
+	// The schema was not written.
+	current, err := client.ReadSchema(context.Background(), &v1.ReadSchemaRequest{})
+	require.NoError(t, err)
+	require.Equal(t, original.SchemaText, current.SchemaText)
+}
+
+func TestSchemaWriteBlockingRelationships(t *testing.T) {
+	conn, cleanup, _, _ := testserver.NewTestServer(require.New(t), 0, memdb.DisableGC, true, tf.EmptyDatastore)
+	t.Cleanup(cleanup)
+	client := v1.NewSchemaServiceClient(conn)
+	v1client := v1.NewPermissionsServiceClient(conn)
+
+	_, err := client.WriteSchema(context.Background(), &v1.WriteSchemaRequest{
+		Schema: `definition example/user {}
+
+		definition example/document {
+			relation somerelation: example/user
+			relation anotherrelation: example/user
+		}`,
+	})
+	require.NoError(t, err)
+
+	updates := make([]*v1.RelationshipUpdate, 0, 4)
+	for _, rel := range []string{
+		"example/document:firstdoc#somerelation@example/user:someuser",
+		"example/document:seconddoc#somerelation@example/user:someuser",
+		"example/document:thirddoc#somerelation@example/user:anotheruser",
+		"example/document:firstdoc#anotherrelation@example/user:someuser",
+	} {
+		updates = append(updates, tuple.MustUpdateToV1RelationshipUpdate(tuple.Create(tuple.MustParse(rel))))
+	}
+	_, err = v1client.WriteRelationships(context.Background(), &v1.WriteRelationshipsRequest{Updates: updates})
+	require.NoError(t, err)
+
+	updatedSchema := `definition example/user {}
+
+		definition example/document {
+			relation anotherrelation: example/user
+		}`
+
+	// With blocking relationship counts, the error holds the exact count.
+	ctx := authzedrequestmeta.AddRequestHeaders(context.Background(), requestmeta.RequestSchemaBlockingRelationshipCounts)
+	_, err = client.WriteSchema(ctx, &v1.WriteSchemaRequest{Schema: updatedSchema})
+	grpcutil.RequireStatus(t, codes.InvalidArgument, err)
+	require.ErrorContains(t, err, "3 existing relationships would no longer conform")
+
+	details := status.Convert(err).Details()
+	require.Len(t, details, 2)
+
+	errorInfo, ok := details[0].(*errdetails.ErrorInfo)
+	require.True(t, ok)
+	require.Equal(t, "3", errorInfo.Metadata["blocking_relationship_count"])
+
+	preconditionFailure, ok := details[1].(*errdetails.PreconditionFailure)
+	require.True(t, ok)
+	require.Len(t, preconditionFailure.Violations, 1)
+	require.Equal(t, "relation `somerelation` removed from object definition `example/document`", preconditionFailure.Violations[0].Subject)
+	require.Equal(t, "3", preconditionFailure.Violations[0].Description)
+
+	// Deleting the blocking relationships writes the schema.
+	ctx = authzedrequestmeta.AddRequestHeaders(context.Background(), requestmeta.RequestSchemaDeleteBlockingRelationships)
+	var header metadata.MD
+	resp, err := client.WriteSchema(ctx, &v1.WriteSchemaRequest{Schema: updatedSchema}, grpc.Header(&header))
+	require.NoError(t, err)
+	require.NotNil(t, resp.WrittenAt)
+	require.Equal(t, []string{"3"}, header.Get(requestmeta.SchemaDeletedRelationshipsResponseHeaderKey))
+
+	stream, err := v1client.ReadRelationships(context.Background(), &v1.ReadRelationshipsRequest{
+		Consistency:        &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}},
+		RelationshipFilter: &v1.RelationshipFilter{ResourceType: "example/document"},
+	})
+	require.NoError(t, err)
+
+	read, err := stream.Recv()
+	require.NoError(t, err)
+	require.Equal(t, "anotherrelation", read.Relationship.Relation)
+
+	_, err = stream.Recv()
+	require.ErrorIs(t, err, io.EOF)
+}
@@ -0,0 +1,141 @@
+package v1
+
+import (
+	"context"
+	"strings"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	grpcvalidate "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/validator"
+
+	"github.com/authzed/spicedb/internal/middleware"
+	"github.com/authzed/spicedb/internal/middleware/usagemetrics"
+	"github.com/authzed/spicedb/internal/services/shared"
+	schemadocv1 "github.com/authzed/spicedb/pkg/proto/schemadoc/v1"
+	"github.com/authzed/spicedb/pkg/zedtoken"
+)
+
+type schemaDocumentationServer struct {
+	schemadocv1.UnimplementedSchemaDocumentationServiceServer
+	shared.WithServiceSpecificInterceptors
+}
+
+// NewSchemaDocumentationServer creates an instance of the experimental schema documentation server.
+func NewSchemaDocumentationServer() schemadocv1.SchemaDocumentationServiceServer {
+	return &schemaDocumentationServer{
+		WithServiceSpecificInterceptors: shared.WithServiceSpecificInterceptors{
+			Unary: middleware.ChainUnaryServer(
+				grpcvalidate.UnaryServerInterceptor(),
+				usagemetrics.UnaryServerInterceptor(),
+			),
+		},
+	}
+}
+
+// ReflectSchemaDocumentation reflects the schema as ExperimentalReflectSchema does, parsing the
+// annotations out of the doc comments of its definitions, relations, permissions and caveats.
+func (sds *schemaDocumentationServer) ReflectSchemaDocumentation(ctx context.Context, req *schemadocv1.ReflectSchemaDocumentationRequest) (*schemadocv1.ReflectSchemaDocumentationResponse, error) {
+	schema, atRevision, err := loadCurrentSchema(ctx)
+	if err != nil {
+		return nil, shared.RewriteErrorWithoutConfig(ctx, err)
+	}
+
+	filters, err := newSchemaFilters(req.OptionalFilters)
+	if err != nil {
+		return nil, shared.RewriteErrorWithoutConfig(ctx, err)
+	}
+
+	definitions := make([]*schemadocv1.DefinitionDocumentation, 0, len(schema.ObjectDefinitions))
+	if filters.HasNamespaces() {
+		for _, ns := range schema.ObjectDefinitions {
+			def, err := namespaceAPIRepr(ns, filters)
+			if err != nil {
+				return nil, shared.RewriteErrorWithoutConfig(ctx, err)
+			}
+
+			if def != nil {
+				definitions = append(definitions, definitionDocumentation(def))
+			}
+		}
+	}
+
+	caveats := make([]*schemadocv1.CaveatDocumentation, 0, len(schema.CaveatDefinitions))
+	if filters.HasCaveats() {
+		for _, cd := range schema.CaveatDefinitions {
+			caveat, err := caveatAPIRepr(cd, filters)
+			if err != nil {
+				return nil, shared.RewriteErrorWithoutConfig(ctx, err)
+			}
+
+			if caveat != nil {
+				caveats = append(caveats, &schemadocv1.CaveatDocumentation{
+					Caveat:      caveat,
+					Annotations: parseAnnotations(caveat.Comment),
+				})
+			}
+		}
+	}
+
+	return &schemadocv1.ReflectSchemaDocumentationResponse{
+		Definitions: definitions,
+		Caveats:     caveats,
+		ReadAt:      zedtoken.MustNewFromRevision(atRevision),
+	}, nil
+}
+
+// definitionDocumentation returns the documentation of a reflected definition, along with the
+// annotations of those of its relations and permissions which have any.
+func definitionDocumentation(def *v1.ExpDefinition) *schemadocv1.DefinitionDocumentation {
+	var members []*schemadocv1.MemberDocumentation
+	addMember := func(name, comment string) {
+		if annotations := parseAnnotations(comment); len(annotations) > 0 {
+			members = append(members, &schemadocv1.MemberDocumentation{
+				Name:        name,
+				Annotations: annotations,
+			})
+		}
+	}
+
+	for _, relation := range def.Relations {
+		addMember(relation.Name, relation.Comment)
+	}
+	for _, permission := range def.Permissions {
+		addMember(permission.Name, permission.Comment)
+	}
+
+	return &schemadocv1.DefinitionDocumentation{
+		Definition:  def,
+		Annotations: parseAnnotations(def.Comment),
+		Members:     members,
+	}
+}
+
+// parseAnnotations returns the annotations found in a doc comment, as reflected from the schema:
+// the lines of its `//` or `/* */` comments which start with `@key`, followed by an optional value.
+func parseAnnotations(comment string) []*schemadocv1.Annotation {
+	var annotations []*schemadocv1.Annotation
+	for _, line := range strings.Split(comment, "\n") {
+		line = strings.TrimSpace(line)
+		line = strings.TrimPrefix(line, "//")
+		line = strings.TrimPrefix(line, "/**")
+		line = strings.TrimPrefix(line, "/*")
+		line = strings.TrimSuffix(line, "*/")
+		line = strings.TrimSpace(line)
+		line = strings.TrimPrefix(line, "*")
+		line = strings.TrimSpace(line)
+
+		if !strings.HasPrefix(line, "@") {
+			continue
+		}
+
+		key, value, _ := strings.Cut(line[1:], " ")
+		if key == "" {
+			continue
+		}
+
+		annotations = append(annotations, &schemadocv1.Annotation{
+			Key:   key,
+			Value: strings.TrimSpace(value),
+		})
+	}
+	return annotations
+}
@@ -0,0 +1,104 @@
+package v1_test
+
+import (
+	"context"
+	"testing"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	tf "github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/internal/testserver"
+	schemadocv1 "github.com/authzed/spicedb/pkg/proto/schemadoc/v1"
+)
+
+func TestReflectSchemaDocumentation(t *testing.T) {
+	require := require.New(t)
+	conn, cleanup, _, _ := testserver.NewTestServer(require, 0, memdb.DisableGC, true, tf.EmptyDatastore)
+	t.Cleanup(cleanup)
+
+	_, err := v1.NewSchemaServiceClient(conn).WriteSchema(context.Background(), &v1.WriteSchemaRequest{
+		Schema: `
+			/** user represents a user */
+			definition user {}
+
+			/**
+			 * only_weekdays allows access on weekdays
+			 * @since 2024
+			 */
+			caveat only_weekdays(day int) {
+				day < 6
+			}
+
+			/**
+			 * document is a protected document
+			 * @owner docs-team
+			 * @pii
+			 */
+			definition document {
+				// viewer can read the document
+				// @audience external
+				relation viewer: user | user with only_weekdays
+
+				relation editor: user
+
+				// @deprecated use viewer instead
+				permission read = viewer + editor
+			}
+		`,
+	})
+	require.NoError(err)
+
+	resp, err := schemadocv1.NewSchemaDocumentationServiceClient(conn).ReflectSchemaDocumentation(context.Background(), &schemadocv1.ReflectSchemaDocumentationRequest{
+		Consistency: &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}},
+	})
+	require.NoError(err)
+	require.NotNil(resp.ReadAt)
+
+	definitions := map[string]*schemadocv1.DefinitionDocumentation{}
+	for _, def := range resp.Definitions {
+		definitions[def.Definition.Name] = def
+	}
+	require.Len(definitions, 2)
+	require.Empty(definitions["user"].Annotations)
+	require.Empty(definitions["user"].Members)
+
+	document := definitions["document"]
+	require.Contains(document.Definition.Comment, "document is a protected document")
+	require.Equal([]string{"owner=docs-team", "pii="}, annotationStrings(document.Annotations))
+
+	// Subject types are preserved in the reflected definition.
+	require.Len(document.Definition.Relations, 2)
+	require.Len(document.Definition.Relations[0].SubjectTypes, 2)
+	require.Equal("only_weekdays", document.Definition.Relations[0].SubjectTypes[1].OptionalCaveatName)
+
+	// Only the members with annotations are documented.
+	require.Len(document.Members, 2)
+	require.Equal("viewer", document.Members[0].Name)
+	require.Equal([]string{"audience=external"}, annotationStrings(document.Members[0].Annotations))
+	require.Equal("read", document.Members[1].Name)
+	require.Equal([]string{"deprecated=use viewer instead"}, annotationStrings(document.Members[1].Annotations))
+
+	require.Len(resp.Caveats, 1)
+	require.Equal("int", resp.Caveats[0].Caveat.Parameters[0].Type)
+	require.Equal([]string{"since=2024"}, annotationStrings(resp.Caveats[0].Annotations))
+
+	// Filters apply as for ExperimentalReflectSchema.
+	resp, err = schemadocv1.NewSchemaDocumentationServiceClient(conn).ReflectSchemaDocumentation(context.Background(), &schemadocv1.ReflectSchemaDocumentationRequest{
+		Consistency:     &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}},
+		OptionalFilters: []*v1.ExpSchemaFilter{{OptionalDefinitionNameFilter: "doc"}},
+	})
+	require.NoError(err)
+	require.Len(resp.Definitions, 1)
+	require.Equal("document", resp.Definitions[0].Definition.Name)
+	require.Empty(resp.Caveats)
+}
+
+func annotationStrings(annotations []*schemadocv1.Annotation) []string {
+	strs := make([]string, 0, len(annotations))
+	for _, annotation := range annotations {
+		strs = append(strs, annotation.Key+"="+annotation.Value)
+	}
+	return strs
+}
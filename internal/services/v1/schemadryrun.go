@@ -0,0 +1,47 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/authzed/spicedb/internal/services/shared"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/requestmeta"
+	"github.com/authzed/spicedb/pkg/zedtoken"
+)
+
+// dryRunWriteSchema analyzes the impact of writing the validated schema at the head revision of
+// the datastore, without writing it, and returns the impact in the response headers.
+func (ss *schemaServer) dryRunWriteSchema(ctx context.Context, ds datastore.Datastore, validated *shared.ValidatedSchemaChanges) (*v1.WriteSchemaResponse, error) {
+	revision, err := ds.HeadRevision(ctx)
+	if err != nil {
+		return nil, ss.rewriteError(ctx, err)
+	}
+
+	stats, err := ds.Statistics(ctx)
+	if err != nil {
+		return nil, ss.rewriteError(ctx, err)
+	}
+
+	impact, err := shared.AnalyzeSchemaChanges(ctx, ds.SnapshotReader(revision), validated, stats)
+	if err != nil {
+		return nil, ss.rewriteError(ctx, err)
+	}
+
+	encoded, err := json.Marshal(impact)
+	if err != nil {
+		return nil, ss.rewriteError(ctx, err)
+	}
+
+	if err := grpc.SetHeader(ctx, metadata.Pairs(requestmeta.SchemaImpactResponseHeaderKey, string(encoded))); err != nil {
+		return nil, ss.rewriteError(ctx, err)
+	}
+
+	return &v1.WriteSchemaResponse{
+		WrittenAt: zedtoken.MustNewFromRevision(revision),
+	}, nil
+}
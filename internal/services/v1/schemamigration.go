@@ -0,0 +1,76 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/authzed/spicedb/internal/services/shared"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/requestmeta"
+)
+
+// schemaMigrationDeleteBatchSize is the number of relationships deleted by each write when
+// deleting the relationships blocking a schema write, within its transaction.
+const schemaMigrationDeleteBatchSize = 1000
+
+// schemaWriteMode is the mode of a WriteSchema request, as requested in its headers.
+type schemaWriteMode struct {
+	// dryRun analyzes the impact of writing the schema without writing it.
+	dryRun bool
+
+	// countBlocking returns the exact number of relationships blocking each change when the
+	// schema cannot be written due to existing relationships.
+	countBlocking bool
+
+	// deleteBlocking deletes the relationships blocking the changes in the transaction writing the
+	// schema.
+	deleteBlocking bool
+}
+
+func schemaWriteModeFromContext(ctx context.Context) schemaWriteMode {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return schemaWriteMode{}
+	}
+
+	_, dryRun := md[string(requestmeta.RequestSchemaDryRun)]
+	_, countBlocking := md[string(requestmeta.RequestSchemaBlockingRelationshipCounts)]
+	_, deleteBlocking := md[string(requestmeta.RequestSchemaDeleteBlockingRelationships)]
+	return schemaWriteMode{dryRun: dryRun, countBlocking: countBlocking, deleteBlocking: deleteBlocking}
+}
+
+// setDeletedRelationshipsHeader returns the number of relationships deleted by a schema write in
+// the response headers.
+func setDeletedRelationshipsHeader(ctx context.Context, deleted uint64) error {
+	return grpc.SetHeader(ctx, metadata.Pairs(requestmeta.SchemaDeletedRelationshipsResponseHeaderKey, strconv.FormatUint(deleted, 10)))
+}
+
+// withBlockingRelationshipCounts returns the error of a failed schema write with the exact number
+// of relationships blocking each change, if it failed due to existing relationships.
+func (ss *schemaServer) withBlockingRelationshipCounts(ctx context.Context, ds datastore.Datastore, validated *shared.ValidatedSchemaChanges, writeErr error) error {
+	var dataErr shared.SchemaWriteDataValidationError
+	if !errors.As(writeErr, &dataErr) {
+		return writeErr
+	}
+
+	headRevision, err := ds.HeadRevision(ctx)
+	if err != nil {
+		return err
+	}
+
+	blocking, err := shared.CountBlockingRelationships(ctx, ds.SnapshotReader(headRevision), validated)
+	if err != nil {
+		return err
+	}
+
+	// The write can fail for other reasons, such as changes to the parameters of caveats.
+	if len(blocking) == 0 {
+		return writeErr
+	}
+
+	return shared.NewSchemaWriteBlockedError(blocking)
+}
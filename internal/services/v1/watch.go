@@ -25,15 +25,20 @@ type watchServer struct {
 	shared.WithStreamServiceSpecificInterceptor
 
 	heartbeatDuration time.Duration
+	shuttingDown      <-chan struct{}
 }
 
-// NewWatchServer creates an instance of the watch server.
-func NewWatchServer(heartbeatDuration time.Duration) v1.WatchServiceServer {
+// NewWatchServer creates an instance of the watch server. Once the shuttingDown channel is closed,
+// each watch sends a final response with the revision its changes have been sent through and ends
+// with a ServerShuttingDownError, from which clients can resume on another server. The channel
+// can be nil if the server never shuts down its watches.
+func NewWatchServer(heartbeatDuration time.Duration, shuttingDown <-chan struct{}) v1.WatchServiceServer {
 	s := &watchServer{
 		WithStreamServiceSpecificInterceptor: shared.WithStreamServiceSpecificInterceptor{
 			Stream: grpcvalidate.StreamServerInterceptor(),
 		},
 		heartbeatDuration: heartbeatDuration,
+		shuttingDown:      shuttingDown,
 	}
 	return s
 }
@@ -88,10 +93,23 @@ func (ws *watchServer) Watch(req *v1.WatchRequest, stream v1.WatchService_WatchS
 		Content:            datastore.WatchRelationships,
 		CheckpointInterval: ws.heartbeatDuration,
 	})
+
+	// Changes are only emitted by the datastore once checkpointed, so all changes through the
+	// revision of the last update received have been sent, whether or not they were filtered.
+	changesThrough := afterRevision
 	for {
 		select {
+		case <-ws.shuttingDown:
+			if err := stream.Send(&v1.WatchResponse{
+				ChangesThrough: zedtoken.MustNewFromRevision(changesThrough),
+			}); err != nil {
+				return status.Errorf(codes.Canceled, "watch canceled by user: %s", err)
+			}
+			return NewServerShuttingDownErr(changesThrough)
+
 		case update, ok := <-updates:
 			if ok {
+				changesThrough = update.Revision
 				filtered := filterUpdates(objectTypes, filters, update.RelationshipChanges)
 				if len(filtered) > 0 {
 					converted, err := tuple.UpdatesToV1RelationshipUpdates(filtered)
@@ -0,0 +1,503 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/authzed/spicedb/internal/datastore/proxy"
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/options"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+const (
+	// writeJournalPrefix is the prefix of the keys of the records of the journals of chunked
+	// writes. The header of the journal of a write is stored under the prefix followed by the ID
+	// of the write, and each of its entries under the key of the header followed by the sequence
+	// number of the entry.
+	writeJournalPrefix = "write_journal/"
+
+	// writeJournalHold is how long a journal is held by the node applying or rolling back its
+	// write after each transaction, before the write is considered abandoned and is rolled back by
+	// recovery.
+	writeJournalHold = 5 * time.Minute
+)
+
+var chunkedWriteConflictCounter = promauto.NewCounter(prometheus.CounterOpts{
+	Namespace: "spicedb",
+	Subsystem: "v1",
+	Name:      "chunked_write_rollback_conflicts_total",
+	Help:      "The number of relationships left as they were by the rollback of a chunked write, as they had been changed by another write since",
+})
+
+// errWriteJournalReleased is returned when applying a chunk of a write whose journal was claimed
+// to be rolled back, such that the write must not continue.
+var errWriteJournalReleased = status.Error(codes.Aborted, "the chunked write was rolled back after being held for too long; retry the write")
+
+// writeJournalHeader is the header of the journal of a chunked write.
+type writeJournalHeader struct {
+	// HeldUntil is the time until which the journal is held by the node applying or rolling back
+	// the write.
+	HeldUntil time.Time `json:"heldUntil"`
+
+	// RollingBack is whether the write is being rolled back, such that no further chunk may be
+	// applied.
+	RollingBack bool `json:"rollingBack,omitempty"`
+
+	// IdempotencyRecord is the key of the record claiming the idempotency key of the write, if
+	// any, which is deleted once the write is rolled back so that the write may be retried.
+	IdempotencyRecord string `json:"idempotencyRecord,omitempty"`
+}
+
+// writeJournalEntry records the changes made by the transaction of a chunk of a write.
+type writeJournalEntry struct {
+	Changes []writeJournalChange `json:"changes"`
+}
+
+// writeJournalChange records the state of a relationship before and after the transaction of a
+// chunk, each written as a relationship string or empty if the relationship did not exist.
+type writeJournalChange struct {
+	Before string `json:"before,omitempty"`
+	After  string `json:"after,omitempty"`
+}
+
+// writeJournal is the journal of a chunked write, persisted in the datastore so that the write is
+// rolled back should the node applying it fail before it is complete.
+type writeJournal struct {
+	id string
+
+	// idempotencyRecord is the key of the record claiming the idempotency key of the write, if
+	// any.
+	idempotencyRecord string
+}
+
+func newWriteJournal() writeJournal {
+	return writeJournal{id: uuid.NewString()}
+}
+
+func (j writeJournal) headerKey() string {
+	return writeJournalPrefix + j.id
+}
+
+func (j writeJournal) entryKey(seq int) string {
+	return fmt.Sprintf("%s/%06d", j.headerKey(), seq)
+}
+
+// hold checks, within the transaction of the chunk with the sequence number, that the write may
+// continue, and holds its journal for another writeJournalHold.
+func (j writeJournal) hold(ctx context.Context, rwt datastore.ReadWriteTransaction, seq int) error {
+	if seq > 0 {
+		header, _, err := j.read(ctx, rwt)
+		if err != nil {
+			return err
+		}
+		if header == nil || header.RollingBack {
+			return errWriteJournalReleased
+		}
+	}
+
+	return writeJournalHeaderRecord(ctx, rwt, j.headerKey(), writeJournalHeader{
+		HeldUntil:         time.Now().Add(writeJournalHold),
+		IdempotencyRecord: j.idempotencyRecord,
+	})
+}
+
+// record appends to the journal, within the transaction of the chunk with the sequence number,
+// the changes the updates of the chunk make to their relationships, whose state before the
+// transaction is read.
+func (j writeJournal) record(ctx context.Context, rwt datastore.ReadWriteTransaction, seq int, updates []tuple.RelationshipUpdate) error {
+	before, err := currentStates(ctx, rwt, updates)
+	if err != nil {
+		return err
+	}
+
+	var entry writeJournalEntry
+	for index, update := range updates {
+		var change writeJournalChange
+		if current, ok := before[index]; ok {
+			if change.Before, err = tuple.String(current); err != nil {
+				return err
+			}
+		}
+		if update.Operation != tuple.UpdateOperationDelete {
+			if change.After, err = tuple.String(update.Relationship); err != nil {
+				return err
+			}
+		}
+		if change.Before == "" && change.After == "" {
+			continue
+		}
+		entry.Changes = append(entry.Changes, change)
+	}
+
+	value, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return rwt.WriteRecords(ctx, datastore.Record{Key: j.entryKey(seq), Value: value})
+}
+
+// release deletes the journal, within the transaction of the last chunk of the write, once the
+// journal holds the given number of entries.
+func (j writeJournal) release(ctx context.Context, rwt datastore.ReadWriteTransaction, entries int) error {
+	header, _, err := j.read(ctx, rwt)
+	if err != nil {
+		return err
+	}
+	if header == nil || header.RollingBack {
+		return errWriteJournalReleased
+	}
+
+	keys := make([]string, 0, entries+1)
+	keys = append(keys, j.headerKey())
+	for seq := range entries {
+		keys = append(keys, j.entryKey(seq))
+	}
+	return rwt.DeleteRecords(ctx, keys...)
+}
+
+// read returns the header of the journal, or nil if the journal does not exist, and its entries
+// in order.
+func (j writeJournal) read(ctx context.Context, rwt datastore.ReadWriteTransaction) (*writeJournalHeader, []datastore.Record, error) {
+	records, err := rwt.ReadRecords(ctx, j.headerKey())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var header *writeJournalHeader
+	var entries []datastore.Record
+	for _, record := range records {
+		switch {
+		case record.Key == j.headerKey():
+			header = &writeJournalHeader{}
+			if err := json.Unmarshal(record.Value, header); err != nil {
+				return nil, nil, fmt.Errorf("invalid header of write journal %s: %w", j.id, err)
+			}
+		case strings.HasPrefix(record.Key, j.headerKey()+"/"):
+			entries = append(entries, record)
+		}
+	}
+	return header, entries, nil
+}
+
+// rollback rolls back the write of the journal, one transaction per entry in reverse, and deletes
+// the journal. A relationship is restored to its state before the write only if it is still in
+// the state the write left it in; otherwise it was changed by another write since, which is kept.
+// The claim of the idempotency key of the write, if any, is deleted with the journal. As the
+// relationships are restored to their state before the write, deletion protection does not apply.
+// Unless
+// force is set, the write is only rolled back if its journal is no longer held. Returns whether
+// the write was rolled back by this call.
+func (j writeJournal) rollback(ctx context.Context, ds datastore.Datastore, force bool, opts ...options.RWTOptionsOption) (bool, error) {
+	ctx = proxy.WithoutDeletionProtection(ctx)
+
+	var entryKeys []string
+	var idempotencyRecord string
+	claimed := false
+	if _, err := ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		header, entries, err := j.read(ctx, rwt)
+		if err != nil {
+			return err
+		}
+
+		claimed = header != nil && (force || !time.Now().Before(header.HeldUntil))
+		if !claimed {
+			return nil
+		}
+
+		entryKeys = make([]string, 0, len(entries))
+		for _, entry := range entries {
+			entryKeys = append(entryKeys, entry.Key)
+		}
+		idempotencyRecord = header.IdempotencyRecord
+
+		return writeJournalHeaderRecord(ctx, rwt, j.headerKey(), writeJournalHeader{
+			HeldUntil:         time.Now().Add(writeJournalHold),
+			RollingBack:       true,
+			IdempotencyRecord: idempotencyRecord,
+		})
+	}, opts...); err != nil {
+		return false, err
+	}
+	if !claimed {
+		return false, nil
+	}
+
+	for _, entryKey := range slices.Backward(entryKeys) {
+		if _, err := ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+			return j.rollbackEntry(ctx, rwt, entryKey)
+		}, opts...); err != nil {
+			return false, err
+		}
+	}
+
+	if _, err := ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		keys := []string{j.headerKey()}
+		if idempotencyRecord != "" {
+			keys = append(keys, idempotencyRecord)
+		}
+		return rwt.DeleteRecords(ctx, keys...)
+	}, opts...); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// rollbackEntry restores, within a transaction, the relationships changed by the entry with the
+// key which are still in the state the entry left them in, and deletes the entry.
+func (j writeJournal) rollbackEntry(ctx context.Context, rwt datastore.ReadWriteTransaction, entryKey string) error {
+	header, entries, err := j.read(ctx, rwt)
+	if err != nil {
+		return err
+	}
+	if header == nil {
+		// The write was rolled back by a concurrent recovery.
+		return nil
+	}
+
+	index := slices.IndexFunc(entries, func(record datastore.Record) bool { return record.Key == entryKey })
+	if index < 0 {
+		// The entry was rolled back by a concurrent recovery.
+		return nil
+	}
+
+	var entry writeJournalEntry
+	if err := json.Unmarshal(entries[index].Value, &entry); err != nil {
+		return fmt.Errorf("invalid entry %s of write journal: %w", entryKey, err)
+	}
+
+	changes := make([]parsedWriteJournalChange, 0, len(entry.Changes))
+	updates := make([]tuple.RelationshipUpdate, 0, len(entry.Changes))
+	for _, change := range entry.Changes {
+		parsed, err := change.parse()
+		if err != nil {
+			return fmt.Errorf("invalid entry %s of write journal: %w", entryKey, err)
+		}
+		changes = append(changes, parsed)
+		updates = append(updates, tuple.Touch(parsed.relationship()))
+	}
+
+	current, err := currentStates(ctx, rwt, updates)
+	if err != nil {
+		return err
+	}
+
+	rollback := make([]tuple.RelationshipUpdate, 0, len(changes))
+	for index, change := range changes {
+		found, exists := current[index]
+		if !sameState(found, exists, change.after) {
+			chunkedWriteConflictCounter.Inc()
+			log.Ctx(ctx).Warn().
+				Str("write", j.id).
+				Str("relationship", tuple.StringWithoutCaveatOrExpiration(change.relationship())).
+				Msg("relationship changed since the chunked write; not rolling it back")
+			continue
+		}
+
+		switch {
+		case change.before != nil:
+			rollback = append(rollback, tuple.Touch(*change.before))
+		case exists:
+			rollback = append(rollback, tuple.Delete(found))
+		}
+	}
+
+	if len(rollback) > 0 {
+		if err := rwt.WriteRelationships(ctx, rollback); err != nil {
+			return err
+		}
+	}
+
+	if err := writeJournalHeaderRecord(ctx, rwt, j.headerKey(), writeJournalHeader{
+		HeldUntil:         time.Now().Add(writeJournalHold),
+		RollingBack:       true,
+		IdempotencyRecord: header.IdempotencyRecord,
+	}); err != nil {
+		return err
+	}
+	return rwt.DeleteRecords(ctx, entryKey)
+}
+
+// parsedWriteJournalChange is a change of a journal entry with its states parsed, each nil if the
+// relationship did not exist.
+type parsedWriteJournalChange struct {
+	before *tuple.Relationship
+	after  *tuple.Relationship
+}
+
+func (c writeJournalChange) parse() (parsedWriteJournalChange, error) {
+	var parsed parsedWriteJournalChange
+	for _, state := range []struct {
+		encoded string
+		into    **tuple.Relationship
+	}{{c.Before, &parsed.before}, {c.After, &parsed.after}} {
+		if state.encoded == "" {
+			continue
+		}
+		rel, err := tuple.Parse(state.encoded)
+		if err != nil {
+			return parsedWriteJournalChange{}, err
+		}
+		*state.into = &rel
+	}
+	if parsed.before == nil && parsed.after == nil {
+		return parsedWriteJournalChange{}, errors.New("change without a relationship")
+	}
+	return parsed, nil
+}
+
+func (c parsedWriteJournalChange) relationship() tuple.Relationship {
+	if c.after != nil {
+		return *c.after
+	}
+	return *c.before
+}
+
+// sameState returns whether the current state of a relationship is the expected state, nil if
+// the relationship should not exist.
+func sameState(current tuple.Relationship, exists bool, expected *tuple.Relationship) bool {
+	if !exists || expected == nil {
+		return !exists && expected == nil
+	}
+	return tuple.Equal(normalizedState(current), normalizedState(*expected))
+}
+
+// normalizedState returns the relationship with its caveat context and expiration normalized as
+// the datastores store them: without a context being the same as an empty context, and
+// expirations being stored with a precision of a microsecond.
+func normalizedState(rel tuple.Relationship) tuple.Relationship {
+	if rel.OptionalCaveat != nil && rel.OptionalCaveat.CaveatName == "" {
+		rel.OptionalCaveat = nil
+	}
+	if rel.OptionalCaveat != nil && rel.OptionalCaveat.Context == nil {
+		caveat := rel.OptionalCaveat.CloneVT()
+		caveat.Context = &structpb.Struct{}
+		rel.OptionalCaveat = caveat
+	}
+	if rel.OptionalExpiration != nil {
+		expiration := rel.OptionalExpiration.Truncate(time.Microsecond)
+		rel.OptionalExpiration = &expiration
+	}
+	return rel
+}
+
+func writeJournalHeaderRecord(ctx context.Context, rwt datastore.ReadWriteTransaction, key string, header writeJournalHeader) error {
+	value, err := json.Marshal(header)
+	if err != nil {
+		return err
+	}
+	return rwt.WriteRecords(ctx, datastore.Record{Key: key, Value: value})
+}
+
+// currentStates returns the relationships of the updates which currently exist, by the index of
+// their update.
+func currentStates(ctx context.Context, reader datastore.Reader, updates []tuple.RelationshipUpdate) (map[int]tuple.Relationship, error) {
+	filters := make([]datastore.RelationshipsFilter, 0, len(updates))
+	for _, update := range updates {
+		rel := update.Relationship
+		filters = append(filters, datastore.RelationshipsFilter{
+			OptionalResourceType:     rel.Resource.ObjectType,
+			OptionalResourceIds:      []string{rel.Resource.ObjectID},
+			OptionalResourceRelation: rel.Resource.Relation,
+			OptionalSubjectsSelectors: []datastore.SubjectsSelector{
+				{
+					OptionalSubjectType: rel.Subject.ObjectType,
+					OptionalSubjectIds:  []string{rel.Subject.ObjectID},
+					RelationFilter:      datastore.SubjectRelationFilter{}.WithRelation(rel.Subject.Relation),
+				},
+			},
+		})
+	}
+
+	it, err := reader.QueryRelationshipsBatch(ctx, filters, options.WithLimit(&limitOne))
+	if err != nil {
+		return nil, err
+	}
+
+	existing := make(map[int]tuple.Relationship, len(updates))
+	for tagged, err := range it {
+		if err != nil {
+			return nil, err
+		}
+		existing[tagged.FilterIndex] = tagged.Relationship
+	}
+	return existing, nil
+}
+
+// RecoverChunkedWrites rolls back the chunked writes whose journals are no longer held, such as
+// because the node applying them failed, and returns the number of writes rolled back.
+func RecoverChunkedWrites(ctx context.Context, ds datastore.Datastore) (int, error) {
+	var abandoned []writeJournal
+	if _, err := ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		abandoned = nil
+		records, err := rwt.ReadRecords(ctx, writeJournalPrefix)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for _, record := range records {
+			id := strings.TrimPrefix(record.Key, writeJournalPrefix)
+			if strings.Contains(id, "/") {
+				continue
+			}
+
+			var header writeJournalHeader
+			if err := json.Unmarshal(record.Value, &header); err != nil {
+				return fmt.Errorf("invalid header of write journal %s: %w", id, err)
+			}
+			if !now.Before(header.HeldUntil) {
+				abandoned = append(abandoned, writeJournal{id: id})
+			}
+		}
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+
+	recovered := 0
+	for _, journal := range abandoned {
+		rolledBack, err := journal.rollback(ctx, ds, false)
+		if err != nil {
+			return recovered, fmt.Errorf("failed to roll back chunked write %s: %w", journal.id, err)
+		}
+		if rolledBack {
+			chunkedWriteCounter.WithLabelValues("recovered").Inc()
+			log.Ctx(ctx).Info().Str("write", journal.id).Msg("rolled back abandoned chunked write")
+			recovered++
+		}
+	}
+	return recovered, nil
+}
+
+// RunChunkedWriteRecovery recovers the abandoned chunked writes once started and then at every
+// interval, until the context is canceled.
+func RunChunkedWriteRecovery(ctx context.Context, ds datastore.Datastore, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := RecoverChunkedWrites(ctx, ds); err != nil && ctx.Err() == nil {
+			log.Ctx(ctx).Warn().Err(err).Msg("failed to recover abandoned chunked writes")
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
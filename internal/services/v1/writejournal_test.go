@@ -0,0 +1,195 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/authzed/spicedb/internal/datastore/dsfortesting"
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+func newWriteJournalTestDatastore(t *testing.T) datastore.Datastore {
+	uninitialized, err := dsfortesting.NewMemDBDatastoreForTesting(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+
+	ds, _ := testfixtures.StandardDatastoreWithCaveatedData(uninitialized, require.New(t))
+	return ds
+}
+
+// applyJournaledChunk applies the updates as a non-final chunk of the write of the journal.
+func applyJournaledChunk(ds datastore.Datastore, journal writeJournal, seq int, updates ...tuple.RelationshipUpdate) error {
+	_, err := ds.ReadWriteTx(context.Background(), func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		if err := journal.hold(ctx, rwt, seq); err != nil {
+			return err
+		}
+		if err := journal.record(ctx, rwt, seq, updates); err != nil {
+			return err
+		}
+		return rwt.WriteRelationships(ctx, updates)
+	})
+	return err
+}
+
+// abandonWriteJournal makes the journal held until the given time, as if the node applying its
+// write stopped renewing it then.
+func abandonWriteJournal(t *testing.T, ds datastore.Datastore, journal writeJournal, heldUntil time.Time) {
+	_, err := ds.ReadWriteTx(context.Background(), func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		return writeJournalHeaderRecord(ctx, rwt, journal.headerKey(), writeJournalHeader{HeldUntil: heldUntil})
+	})
+	require.NoError(t, err)
+}
+
+func readJournalRecords(t *testing.T, ds datastore.Datastore) []datastore.Record {
+	var records []datastore.Record
+	_, err := ds.ReadWriteTx(context.Background(), func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		var err error
+		records, err = rwt.ReadRecords(ctx, writeJournalPrefix)
+		return err
+	})
+	require.NoError(t, err)
+	return records
+}
+
+func requireRelationship(t *testing.T, ds datastore.Datastore, expected string, exists bool) {
+	rel := tuple.MustParse(expected)
+	revision, err := ds.HeadRevision(context.Background())
+	require.NoError(t, err)
+
+	current, err := currentStates(context.Background(), ds.SnapshotReader(revision), []tuple.RelationshipUpdate{tuple.Touch(rel)})
+	require.NoError(t, err)
+
+	found, ok := current[0]
+	require.Equal(t, exists, ok, "unexpected existence of %s", expected)
+	if exists {
+		require.True(t, sameState(found, true, &rel), "expected %s, found %s", expected, tuple.MustString(found))
+	}
+}
+
+func TestRecoverAbandonedChunkedWrite(t *testing.T) {
+	ds := newWriteJournalTestDatastore(t)
+	journal := newWriteJournal()
+
+	require.NoError(t, applyJournaledChunk(ds, journal, 0,
+		tuple.Touch(tuple.MustParse("document:journaled#viewer@user:tom")),
+		tuple.Delete(tuple.MustParse("document:masterplan#viewer@user:eng_lead")),
+		tuple.Delete(tuple.MustParse("document:missing#viewer@user:tom")),
+	))
+	require.NoError(t, applyJournaledChunk(ds, journal, 1,
+		tuple.Touch(tuple.MustParse(`document:caveatedplan#caveated_viewer@user:caveatedguy[test:{"expectedSecret":"5678"}]`)),
+	))
+
+	// The journal of a write in progress is not recovered.
+	recovered, err := RecoverChunkedWrites(context.Background(), ds)
+	require.NoError(t, err)
+	require.Zero(t, recovered)
+	requireRelationship(t, ds, "document:journaled#viewer@user:tom", true)
+
+	abandonWriteJournal(t, ds, journal, time.Now().Add(-time.Second))
+
+	recovered, err = RecoverChunkedWrites(context.Background(), ds)
+	require.NoError(t, err)
+	require.Equal(t, 1, recovered)
+
+	requireRelationship(t, ds, "document:journaled#viewer@user:tom", false)
+	requireRelationship(t, ds, "document:masterplan#viewer@user:eng_lead", true)
+	requireRelationship(t, ds, `document:caveatedplan#caveated_viewer@user:caveatedguy[test:{"expectedSecret":"1234"}]`, true)
+	require.Empty(t, readJournalRecords(t, ds))
+
+	// The write cannot continue once recovered.
+	err = applyJournaledChunk(ds, journal, 2, tuple.Touch(tuple.MustParse("document:journaled2#viewer@user:tom")))
+	require.ErrorIs(t, err, errWriteJournalReleased)
+	requireRelationship(t, ds, "document:journaled2#viewer@user:tom", false)
+}
+
+func TestChunkedWriteRollbackKeepsLaterChanges(t *testing.T) {
+	ds := newWriteJournalTestDatastore(t)
+	journal := newWriteJournal()
+
+	require.NoError(t, applyJournaledChunk(ds, journal, 0,
+		tuple.Touch(tuple.MustParse("document:first#viewer@user:tom")),
+		tuple.Touch(tuple.MustParse("document:second#viewer@user:tom")),
+		tuple.Delete(tuple.MustParse("document:masterplan#viewer@user:eng_lead")),
+	))
+
+	// Another write changes relationships of the chunked write before it is rolled back.
+	_, err := ds.ReadWriteTx(context.Background(), func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		return rwt.WriteRelationships(ctx, []tuple.RelationshipUpdate{
+			tuple.Touch(tuple.MustParse("document:first#viewer@user:tom[expiration:2300-01-01T00:00:00Z]")),
+			tuple.Touch(tuple.MustParse("document:masterplan#viewer@user:eng_lead[expiration:2300-01-01T00:00:00Z]")),
+		})
+	})
+	require.NoError(t, err)
+
+	rolledBack, err := journal.rollback(context.Background(), ds, true)
+	require.NoError(t, err)
+	require.True(t, rolledBack)
+
+	requireRelationship(t, ds, "document:first#viewer@user:tom[expiration:2300-01-01T00:00:00Z]", true)
+	requireRelationship(t, ds, "document:second#viewer@user:tom", false)
+	requireRelationship(t, ds, "document:masterplan#viewer@user:eng_lead[expiration:2300-01-01T00:00:00Z]", true)
+	require.Empty(t, readJournalRecords(t, ds))
+
+	// Rolling back a released journal does nothing.
+	rolledBack, err = journal.rollback(context.Background(), ds, true)
+	require.NoError(t, err)
+	require.False(t, rolledBack)
+}
+
+func TestReleaseWriteJournal(t *testing.T) {
+	ds := newWriteJournalTestDatastore(t)
+	journal := newWriteJournal()
+
+	require.NoError(t, applyJournaledChunk(ds, journal, 0, tuple.Touch(tuple.MustParse("document:first#viewer@user:tom"))))
+	require.NoError(t, applyJournaledChunk(ds, journal, 1, tuple.Touch(tuple.MustParse("document:second#viewer@user:tom"))))
+
+	records := readJournalRecords(t, ds)
+	require.Len(t, records, 3)
+
+	var entry writeJournalEntry
+	require.NoError(t, json.Unmarshal(records[2].Value, &entry))
+	require.Equal(t, []writeJournalChange{{After: "document:second#viewer@user:tom"}}, entry.Changes)
+
+	_, err := ds.ReadWriteTx(context.Background(), func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		return journal.release(ctx, rwt, 2)
+	})
+	require.NoError(t, err)
+	require.Empty(t, readJournalRecords(t, ds))
+
+	recovered, err := RecoverChunkedWrites(context.Background(), ds)
+	require.NoError(t, err)
+	require.Zero(t, recovered)
+	requireRelationship(t, ds, "document:second#viewer@user:tom", true)
+}
+
+func TestSameState(t *testing.T) {
+	rel := tuple.MustParse("document:first#viewer@user:tom[test]")
+	require.True(t, sameState(tuple.Relationship{}, false, nil))
+	require.False(t, sameState(rel, true, nil))
+	require.False(t, sameState(tuple.Relationship{}, false, &rel))
+
+	// A caveat without a context is the same as one with an empty context.
+	withEmptyContext := rel
+	withEmptyContext.OptionalCaveat = rel.OptionalCaveat.CloneVT()
+	withEmptyContext.OptionalCaveat.Context = &structpb.Struct{}
+	require.True(t, sameState(withEmptyContext, true, &rel))
+
+	// Expirations are compared with the precision with which they are stored.
+	expiration := time.Date(2300, 1, 1, 0, 0, 0, 1500, time.UTC)
+	expiring := tuple.MustParse("document:first#viewer@user:tom")
+	expiring.OptionalExpiration = &expiration
+	stored := expiring
+	storedExpiration := expiration.Truncate(time.Microsecond)
+	stored.OptionalExpiration = &storedExpiration
+	require.True(t, sameState(stored, true, &expiring))
+
+	changed := tuple.MustParse(`document:first#viewer@user:tom[test:{"expectedSecret":"1234"}]`)
+	require.False(t, sameState(changed, true, &rel))
+}
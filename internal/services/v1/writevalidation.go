@@ -0,0 +1,102 @@
+package v1
+
+import (
+	"context"
+	"errors"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/authzed/spicedb/internal/relationships"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/requestmeta"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// writeValidationMode is how the updates of a WriteRelationships request are validated, as
+// requested in its headers.
+type writeValidationMode struct {
+	// strict reports every update which does not conform to the schema, with details of what the
+	// schema allows, rather than the first.
+	strict bool
+
+	// validateOnly validates the updates and checks the preconditions without applying them.
+	validateOnly bool
+}
+
+func writeValidationModeFromContext(ctx context.Context) writeValidationMode {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return writeValidationMode{}
+	}
+
+	_, strict := md[string(requestmeta.RequestStrictWriteValidation)]
+	_, validateOnly := md[string(requestmeta.RequestValidateWriteOnly)]
+	return writeValidationMode{strict: strict, validateOnly: validateOnly}
+}
+
+// validateRelationshipUpdates validates the updates against the schema, returning an error listing
+// each non-conforming update in strict mode.
+func (ps *permissionServer) validateRelationshipUpdates(ctx context.Context, reader datastore.Reader, updates []tuple.RelationshipUpdate, mode writeValidationMode) error {
+	if !mode.strict {
+		return relationships.ValidateRelationshipUpdates(ctx, reader, updates)
+	}
+
+	invalid, err := relationships.FindInvalidRelationshipUpdates(ctx, reader, updates)
+	if err != nil {
+		return err
+	}
+
+	if len(invalid) == 0 {
+		return nil
+	}
+
+	violations := make([]UpdateViolation, 0, len(invalid))
+	for _, invalidUpdate := range invalid {
+		violation := UpdateViolation{
+			Index:        invalidUpdate.Index,
+			Relationship: tuple.StringWithoutCaveatOrExpiration(updates[invalidUpdate.Index].Relationship),
+			Status:       status.Convert(ps.rewriteError(ctx, invalidUpdate.Err)),
+		}
+
+		var subjectTypeErr relationships.InvalidSubjectTypeError
+		if errors.As(invalidUpdate.Err, &subjectTypeErr) {
+			violation.Details = subjectTypeErr.ConformanceDetails()
+		}
+		violations = append(violations, violation)
+	}
+
+	return NewNonConformingUpdatesErr(len(updates), violations)
+}
+
+// validateWriteOnly validates the updates and checks the preconditions and expected relationship
+// versions of the write at the head revision of the datastore, without applying them, returning
+// the revision.
+func (ps *permissionServer) validateWriteOnly(ctx context.Context, ds datastore.Datastore, preconditions []*v1.Precondition, updates []tuple.RelationshipUpdate, expectedVersions map[int]string, mode writeValidationMode) (datastore.Revision, error) {
+	revision, err := ds.HeadRevision(ctx)
+	if err != nil {
+		return datastore.NoRevision, err
+	}
+
+	reader := ds.SnapshotReader(revision)
+	for _, precond := range preconditions {
+		if err := validatePrecondition(ctx, precond, reader); err != nil {
+			return datastore.NoRevision, err
+		}
+	}
+
+	if err := ps.validateRelationshipUpdates(ctx, reader, updates, mode); err != nil {
+		return datastore.NoRevision, err
+	}
+
+	if err := checkPreconditions(ctx, reader, preconditions); err != nil {
+		return datastore.NoRevision, err
+	}
+
+	if err := checkExpectedRelationshipVersions(ctx, reader, updates, expectedVersions); err != nil {
+		return datastore.NoRevision, err
+	}
+
+	return revision, nil
+}
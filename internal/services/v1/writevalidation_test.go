@@ -0,0 +1,120 @@
+package v1_test
+
+import (
+	"context"
+	"testing"
+
+	authzedrequestmeta "github.com/authzed/authzed-go/pkg/requestmeta"
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	tf "github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/internal/testserver"
+	"github.com/authzed/spicedb/pkg/requestmeta"
+)
+
+func TestWriteRelationshipsStrictValidation(t *testing.T) {
+	conn, cleanup, _, _ := testserver.NewTestServer(require.New(t), 0, memdb.DisableGC, true, tf.StandardDatastoreWithData)
+	t.Cleanup(cleanup)
+	client := v1.NewPermissionsServiceClient(conn)
+
+	updates := []*v1.RelationshipUpdate{
+		{Operation: v1.RelationshipUpdate_OPERATION_TOUCH, Relationship: rel("document", "newdoc", "viewer", "folder", "afolder", "")},
+		{Operation: v1.RelationshipUpdate_OPERATION_TOUCH, Relationship: rel("document", "newdoc", "editor", "user", "tom", "")},
+		{Operation: v1.RelationshipUpdate_OPERATION_TOUCH, Relationship: rel("document", "newdoc", "caveated_viewer", "user", "tom", "")},
+		{Operation: v1.RelationshipUpdate_OPERATION_TOUCH, Relationship: rel("document", "newdoc", "unknown", "user", "tom", "")},
+	}
+
+	// Without strict validation, only the first error is returned.
+	_, err := client.WriteRelationships(context.Background(), &v1.WriteRelationshipsRequest{Updates: updates})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	require.NotContains(t, err.Error(), "do not conform")
+
+	ctx := authzedrequestmeta.AddRequestHeaders(context.Background(), requestmeta.RequestStrictWriteValidation)
+	_, err = client.WriteRelationships(ctx, &v1.WriteRelationshipsRequest{Updates: updates})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	require.ErrorContains(t, err, "3 of 4 relationship updates do not conform to the schema")
+
+	details := status.Convert(err).Details()
+	require.Len(t, details, 4)
+
+	badRequest, ok := details[0].(*errdetails.BadRequest)
+	require.True(t, ok)
+	fields := make([]string, 0, len(badRequest.FieldViolations))
+	for _, violation := range badRequest.FieldViolations {
+		fields = append(fields, violation.Field)
+	}
+	require.Equal(t, []string{"updates[0].relationship", "updates[2].relationship", "updates[3].relationship"}, fields)
+
+	invalidType, ok := details[1].(*errdetails.ErrorInfo)
+	require.True(t, ok)
+	require.Equal(t, v1.ErrorReason_ERROR_REASON_INVALID_SUBJECT_TYPE.String(), invalidType.Reason)
+	require.Equal(t, "0", invalidType.Metadata["update_index"])
+	require.Equal(t, "document:newdoc#viewer@folder:afolder", invalidType.Metadata["relationship"])
+	require.Equal(t, "user,user with test", invalidType.Metadata["allowed_subject_types"])
+
+	missingCaveat, ok := details[2].(*errdetails.ErrorInfo)
+	require.True(t, ok)
+	require.Equal(t, "2", missingCaveat.Metadata["update_index"])
+	require.Equal(t, "user with test", missingCaveat.Metadata["allowed_subject_types"])
+	require.Equal(t, "test", missingCaveat.Metadata["allowed_caveats"])
+
+	unknownRelation, ok := details[3].(*errdetails.ErrorInfo)
+	require.True(t, ok)
+	require.Equal(t, v1.ErrorReason_ERROR_REASON_UNKNOWN_RELATION_OR_PERMISSION.String(), unknownRelation.Reason)
+	require.Equal(t, "3", unknownRelation.Metadata["update_index"])
+
+	// Valid updates are written as usual.
+	_, err = client.WriteRelationships(ctx, &v1.WriteRelationshipsRequest{Updates: updates[1:2]})
+	require.NoError(t, err)
+}
+
+func TestWriteRelationshipsValidateOnly(t *testing.T) {
+	conn, cleanup, _, _ := testserver.NewTestServer(require.New(t), 0, memdb.DisableGC, true, tf.StandardDatastoreWithData)
+	t.Cleanup(cleanup)
+	client := v1.NewPermissionsServiceClient(conn)
+
+	ctx := authzedrequestmeta.AddRequestHeaders(context.Background(), requestmeta.RequestValidateWriteOnly)
+	resp, err := client.WriteRelationships(ctx, &v1.WriteRelationshipsRequest{
+		Updates: []*v1.RelationshipUpdate{
+			{Operation: v1.RelationshipUpdate_OPERATION_CREATE, Relationship: rel("document", "newdoc", "viewer", "user", "tom", "")},
+		},
+	})
+	require.NoError(t, err)
+	require.NotNil(t, resp.WrittenAt)
+
+	// The relationship was not written.
+	stream, err := client.ReadRelationships(context.Background(), &v1.ReadRelationshipsRequest{
+		Consistency:        &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}},
+		RelationshipFilter: &v1.RelationshipFilter{ResourceType: "document", OptionalResourceId: "newdoc"},
+	})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	require.ErrorContains(t, err, "EOF")
+
+	// Invalid updates fail as they would when writing.
+	_, err = client.WriteRelationships(ctx, &v1.WriteRelationshipsRequest{
+		Updates: []*v1.RelationshipUpdate{
+			{Operation: v1.RelationshipUpdate_OPERATION_TOUCH, Relationship: rel("document", "newdoc", "viewer", "folder", "afolder", "")},
+		},
+	})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// Preconditions are checked.
+	_, err = client.WriteRelationships(ctx, &v1.WriteRelationshipsRequest{
+		OptionalPreconditions: []*v1.Precondition{
+			{
+				Operation: v1.Precondition_OPERATION_MUST_MATCH,
+				Filter:    &v1.RelationshipFilter{ResourceType: "document", OptionalResourceId: "newdoc"},
+			},
+		},
+		Updates: []*v1.RelationshipUpdate{
+			{Operation: v1.RelationshipUpdate_OPERATION_TOUCH, Relationship: rel("document", "newdoc", "viewer", "user", "tom", "")},
+		},
+	})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
@@ -0,0 +1,203 @@
+package v1
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc/metadata"
+
+	"github.com/authzed/spicedb/internal/caveats"
+	"github.com/authzed/spicedb/internal/graph"
+	"github.com/authzed/spicedb/internal/namespace"
+	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/requestmeta"
+	"github.com/authzed/spicedb/pkg/spiceerrors"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+func zanzibarExpandRequested(ctx context.Context) bool {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return false
+	}
+
+	_, requested := md[string(requestmeta.RequestZanzibarExpand)]
+	return requested
+}
+
+// zanzibarExpansionTree returns the userset tree of the relation of the resource with the
+// semantics of the Expand API of the Zanzibar paper. Unlike a dispatched expand, the relations
+// and arrows referenced by the rewrite of a permission are not expanded, but returned as leaves
+// holding the usersets they reference.
+func zanzibarExpansionTree(ctx context.Context, reader datastore.Reader, resource *core.ObjectAndRelation) (*core.RelationTupleTreeNode, error) {
+	_, relation, err := namespace.ReadNamespaceAndRelation(ctx, resource.Namespace, resource.Relation, reader)
+	if err != nil {
+		return nil, err
+	}
+
+	if relation.UsersetRewrite == nil {
+		return zanzibarDirectLeaf(ctx, reader, resource)
+	}
+	return zanzibarRewriteNode(ctx, reader, resource, relation.UsersetRewrite)
+}
+
+// zanzibarDirectLeaf returns the leaf holding the subjects of the relationships of the relation of
+// the resource, whose subject sets are left unexpanded.
+func zanzibarDirectLeaf(ctx context.Context, reader datastore.Reader, resource *core.ObjectAndRelation) (*core.RelationTupleTreeNode, error) {
+	it, err := reader.QueryRelationships(ctx, datastore.RelationshipsFilter{
+		OptionalResourceType:     resource.Namespace,
+		OptionalResourceIds:      []string{resource.ObjectId},
+		OptionalResourceRelation: resource.Relation,
+	})
+	if err != nil {
+		return nil, graph.NewExpansionFailureErr(err)
+	}
+
+	subjects := []*core.DirectSubject{}
+	for rel, err := range it {
+		if err != nil {
+			return nil, graph.NewExpansionFailureErr(err)
+		}
+
+		subjects = append(subjects, &core.DirectSubject{
+			Subject:          rel.Subject.ToCoreONR(),
+			CaveatExpression: caveats.CaveatAsExpr(rel.OptionalCaveat),
+		})
+	}
+	return zanzibarLeaf(resource, subjects), nil
+}
+
+func zanzibarRewriteNode(ctx context.Context, reader datastore.Reader, resource *core.ObjectAndRelation, rewrite *core.UsersetRewrite) (*core.RelationTupleTreeNode, error) {
+	var operation core.SetOperationUserset_Operation
+	var setOperation *core.SetOperation
+	switch rw := rewrite.RewriteOperation.(type) {
+	case *core.UsersetRewrite_Union:
+		operation, setOperation = core.SetOperationUserset_UNION, rw.Union
+	case *core.UsersetRewrite_Intersection:
+		operation, setOperation = core.SetOperationUserset_INTERSECTION, rw.Intersection
+	case *core.UsersetRewrite_Exclusion:
+		operation, setOperation = core.SetOperationUserset_EXCLUSION, rw.Exclusion
+	default:
+		return nil, spiceerrors.MustBugf("unknown userset rewrite operation `%T` in expand", rw)
+	}
+
+	children := make([]*core.RelationTupleTreeNode, 0, len(setOperation.Child))
+	for _, childOneof := range setOperation.Child {
+		var child *core.RelationTupleTreeNode
+		var err error
+		switch c := childOneof.ChildType.(type) {
+		case *core.SetOperation_Child_XThis:
+			return nil, errors.New("use of _this is unsupported; please rewrite your schema")
+		case *core.SetOperation_Child_ComputedUserset:
+			child = zanzibarLeaf(resource, []*core.DirectSubject{{
+				Subject: &core.ObjectAndRelation{
+					Namespace: resource.Namespace,
+					ObjectId:  resource.ObjectId,
+					Relation:  c.ComputedUserset.Relation,
+				},
+			}})
+		case *core.SetOperation_Child_UsersetRewrite:
+			child, err = zanzibarRewriteNode(ctx, reader, resource, c.UsersetRewrite)
+		case *core.SetOperation_Child_TupleToUserset:
+			child, err = zanzibarTupleToUsersetNode(ctx, reader, resource, c.TupleToUserset.Tupleset.Relation, c.TupleToUserset.ComputedUserset.Relation, false)
+		case *core.SetOperation_Child_FunctionedTupleToUserset:
+			switch c.FunctionedTupleToUserset.Function {
+			case core.FunctionedTupleToUserset_FUNCTION_ANY:
+				child, err = zanzibarTupleToUsersetNode(ctx, reader, resource, c.FunctionedTupleToUserset.Tupleset.Relation, c.FunctionedTupleToUserset.ComputedUserset.Relation, false)
+			case core.FunctionedTupleToUserset_FUNCTION_ALL:
+				child, err = zanzibarTupleToUsersetNode(ctx, reader, resource, c.FunctionedTupleToUserset.Tupleset.Relation, c.FunctionedTupleToUserset.ComputedUserset.Relation, true)
+			default:
+				return nil, spiceerrors.MustBugf("unknown function `%s` in expand", c.FunctionedTupleToUserset.Function)
+			}
+		case *core.SetOperation_Child_XNil:
+			child = zanzibarLeaf(resource, []*core.DirectSubject{})
+		default:
+			return nil, spiceerrors.MustBugf("unknown set operation child `%T` in expand", c)
+		}
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, child)
+	}
+
+	return &core.RelationTupleTreeNode{
+		NodeType: &core.RelationTupleTreeNode_IntermediateNode{
+			IntermediateNode: &core.SetOperationUserset{
+				Operation:  operation,
+				ChildNodes: children,
+			},
+		},
+		Expanded: resource,
+	}, nil
+}
+
+// zanzibarTupleToUsersetNode returns the usersets referenced by an arrow: the computed relation
+// of each subject of the tupleset relation of the resource whose type defines it. The usersets
+// are held by a single leaf, or, for an arrow requiring all of them, by a leaf each under an
+// intersection.
+func zanzibarTupleToUsersetNode(ctx context.Context, reader datastore.Reader, resource *core.ObjectAndRelation, tuplesetRelation, computedRelation string, requireAll bool) (*core.RelationTupleTreeNode, error) {
+	it, err := reader.QueryRelationships(ctx, datastore.RelationshipsFilter{
+		OptionalResourceType:     resource.Namespace,
+		OptionalResourceIds:      []string{resource.ObjectId},
+		OptionalResourceRelation: tuplesetRelation,
+	})
+	if err != nil {
+		return nil, graph.NewExpansionFailureErr(err)
+	}
+
+	var rels []tuple.Relationship
+	for rel, err := range it {
+		if err != nil {
+			return nil, graph.NewExpansionFailureErr(err)
+		}
+		rels = append(rels, rel)
+	}
+
+	subjects := []*core.DirectSubject{}
+	for _, rel := range rels {
+		err := namespace.CheckNamespaceAndRelation(ctx, rel.Subject.ObjectType, computedRelation, true, reader)
+		if err != nil {
+			if errors.As(err, &namespace.RelationNotFoundError{}) {
+				continue
+			}
+			return nil, err
+		}
+
+		subjects = append(subjects, &core.DirectSubject{
+			Subject: &core.ObjectAndRelation{
+				Namespace: rel.Subject.ObjectType,
+				ObjectId:  rel.Subject.ObjectID,
+				Relation:  computedRelation,
+			},
+			CaveatExpression: caveats.CaveatAsExpr(rel.OptionalCaveat),
+		})
+	}
+
+	if !requireAll {
+		return zanzibarLeaf(resource, subjects), nil
+	}
+
+	children := make([]*core.RelationTupleTreeNode, 0, len(subjects))
+	for _, subject := range subjects {
+		children = append(children, zanzibarLeaf(resource, []*core.DirectSubject{subject}))
+	}
+	return &core.RelationTupleTreeNode{
+		NodeType: &core.RelationTupleTreeNode_IntermediateNode{
+			IntermediateNode: &core.SetOperationUserset{
+				Operation:  core.SetOperationUserset_INTERSECTION,
+				ChildNodes: children,
+			},
+		},
+		Expanded: resource,
+	}, nil
+}
+
+func zanzibarLeaf(resource *core.ObjectAndRelation, subjects []*core.DirectSubject) *core.RelationTupleTreeNode {
+	return &core.RelationTupleTreeNode{
+		NodeType: &core.RelationTupleTreeNode_LeafNode{
+			LeafNode: &core.DirectSubjects{Subjects: subjects},
+		},
+		Expanded: resource,
+	}
+}
@@ -0,0 +1,56 @@
+package v1_test
+
+import (
+	"context"
+	"testing"
+
+	authzedrequestmeta "github.com/authzed/authzed-go/pkg/requestmeta"
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	tf "github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/internal/testserver"
+	"github.com/authzed/spicedb/pkg/requestmeta"
+)
+
+func TestZanzibarExpand(t *testing.T) {
+	conn, cleanup, _, _ := testserver.NewTestServer(require.New(t), 0, memdb.DisableGC, true, tf.StandardDatastoreWithData)
+	t.Cleanup(cleanup)
+	client := v1.NewPermissionsServiceClient(conn)
+
+	ctx := authzedrequestmeta.AddRequestHeaders(context.Background(), requestmeta.RequestZanzibarExpand)
+	expand := func(resourceType, resourceID, permission string) *v1.PermissionRelationshipTree {
+		resp, err := client.ExpandPermissionTree(ctx, &v1.ExpandPermissionTreeRequest{
+			Consistency: &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}},
+			Resource:    &v1.ObjectReference{ObjectType: resourceType, ObjectId: resourceID},
+			Permission:  permission,
+		})
+		require.NoError(t, err)
+		return resp.TreeRoot
+	}
+
+	// The relations and arrows of the permission are leaves of usersets, rather than expanded.
+	root := expand("document", "masterplan", "view")
+	require.Equal(t, "view", root.ExpandedRelation)
+	require.Equal(t, v1.AlgebraicSubjectSet_OPERATION_UNION, root.GetIntermediate().Operation)
+
+	var leaves [][]string
+	for _, child := range root.GetIntermediate().Children {
+		require.NotNil(t, child.GetLeaf())
+		leaves = append(leaves, leafSubjects(child))
+	}
+	require.Len(t, leaves, 3)
+	require.Equal(t, []string{"document:masterplan#viewer"}, leaves[0])
+	require.Equal(t, []string{"document:masterplan#edit"}, leaves[1])
+	require.ElementsMatch(t, []string{"folder:strategy#view", "folder:plans#view"}, leaves[2])
+
+	intersection := expand("document", "masterplan", "view_and_edit")
+	require.Equal(t, v1.AlgebraicSubjectSet_OPERATION_INTERSECTION, intersection.GetIntermediate().Operation)
+	require.Len(t, intersection.GetIntermediate().Children, 2)
+
+	// Relations are leaves of the subjects of their relationships, whose subject sets are left
+	// unexpanded.
+	require.ElementsMatch(t, []string{"user:eng_lead"}, leafSubjects(expand("document", "masterplan", "viewer")))
+	require.ElementsMatch(t, []string{"user:legal", "folder:auditors#viewer"}, leafSubjects(expand("folder", "company", "viewer")))
+}
@@ -0,0 +1,199 @@
+package taskrunner
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var adaptiveLimitGauge = promauto.NewGauge(prometheus.GaugeOpts{
+	Namespace: "spicedb",
+	Subsystem: "dispatch",
+	Name:      "adaptive_concurrency_limit",
+	Help:      "current server-wide adaptive limit on the number of additional goroutines used for sub-problem fan-out",
+})
+
+const (
+	// adaptiveBackoffRatio is the ratio by which the limit is multiplied when overload is observed.
+	adaptiveBackoffRatio = 0.9
+
+	defaultAdaptiveMinLimit = 1
+)
+
+// AdaptiveLimiterConfig is the configuration for an AdaptiveLimiter.
+type AdaptiveLimiterConfig struct {
+	// MinLimit is the minimum limit to which the limiter will back off. Defaults to 1.
+	MinLimit uint32
+
+	// MaxLimit is the maximum, and initial, limit of the limiter.
+	MaxLimit uint32
+
+	// LatencyThreshold, if non-zero, is the duration over which a task is considered to have
+	// been slowed by overload.
+	LatencyThreshold time.Duration
+}
+
+// AdaptiveLimiter is an additive-increase/multiplicative-decrease (AIMD) limit on the number of
+// additional goroutines which may be spawned by task runners to run tasks concurrently. The limit
+// is decreased multiplicatively whenever a task is observed to be overloaded, either by exceeding
+// the latency threshold or by failing with a deadline or resource exhaustion error, and increased
+// additively whenever a task completes normally while the limit is being utilized.
+//
+// A task runner always runs tasks in at least one goroutine, regardless of the limiter, so a
+// limiter at its minimum reduces fan-out to serial execution but never blocks progress.
+type AdaptiveLimiter struct {
+	parent *AdaptiveLimiter
+	config AdaptiveLimiterConfig
+
+	lock     sync.Mutex
+	limit    float64
+	inFlight uint32
+}
+
+// NewAdaptiveLimiter creates a new server-wide adaptive limiter.
+func NewAdaptiveLimiter(config AdaptiveLimiterConfig) *AdaptiveLimiter {
+	limiter := newAdaptiveLimiter(nil, config)
+	adaptiveLimitGauge.Set(limiter.limit)
+	return limiter
+}
+
+func newAdaptiveLimiter(parent *AdaptiveLimiter, config AdaptiveLimiterConfig) *AdaptiveLimiter {
+	if config.MinLimit == 0 {
+		config.MinLimit = defaultAdaptiveMinLimit
+	}
+	config.MaxLimit = max(config.MaxLimit, config.MinLimit)
+
+	return &AdaptiveLimiter{
+		parent: parent,
+		config: config,
+		limit:  float64(config.MaxLimit),
+	}
+}
+
+// NewRequestLimiter returns a limiter for a single request, limited to the given maximum and
+// further limited by this limiter.
+func (al *AdaptiveLimiter) NewRequestLimiter(maxLimit uint32) *AdaptiveLimiter {
+	return newAdaptiveLimiter(al, AdaptiveLimiterConfig{
+		MinLimit:         al.config.MinLimit,
+		MaxLimit:         maxLimit,
+		LatencyThreshold: al.config.LatencyThreshold,
+	})
+}
+
+// Limit returns the current limit.
+func (al *AdaptiveLimiter) Limit() uint32 {
+	al.lock.Lock()
+	defer al.lock.Unlock()
+	return uint32(al.limit)
+}
+
+// SetMaxLimit changes the maximum limit of the limiter, lowering the current limit if it exceeds
+// the new maximum. A raised maximum is reached additively as tasks complete normally.
+func (al *AdaptiveLimiter) SetMaxLimit(maxLimit uint32) {
+	al.lock.Lock()
+	al.config.MaxLimit = max(maxLimit, al.config.MinLimit)
+	al.limit = min(al.limit, float64(al.config.MaxLimit))
+	limit := al.limit
+	al.lock.Unlock()
+
+	if al.parent == nil {
+		adaptiveLimitGauge.Set(limit)
+	}
+}
+
+// TryAcquire attempts to acquire a slot under the limit, returning whether it was acquired. Each
+// successful call must be followed by a call to Release.
+func (al *AdaptiveLimiter) TryAcquire() bool {
+	al.lock.Lock()
+	if al.inFlight >= uint32(al.limit) {
+		al.lock.Unlock()
+		return false
+	}
+	al.inFlight++
+	al.lock.Unlock()
+
+	if al.parent != nil && !al.parent.TryAcquire() {
+		al.lock.Lock()
+		al.inFlight--
+		al.lock.Unlock()
+		return false
+	}
+
+	return true
+}
+
+// Release releases a slot acquired by TryAcquire.
+func (al *AdaptiveLimiter) Release() {
+	al.lock.Lock()
+	if al.inFlight > 0 {
+		al.inFlight--
+	}
+	al.lock.Unlock()
+
+	if al.parent != nil {
+		al.parent.Release()
+	}
+}
+
+// Observe records the duration and result of a completed task, adjusting the limit.
+func (al *AdaptiveLimiter) Observe(duration time.Duration, err error) {
+	overloaded := isOverloaded(duration, err, al.config.LatencyThreshold)
+
+	al.lock.Lock()
+	switch {
+	case overloaded:
+		al.limit = max(al.limit*adaptiveBackoffRatio, float64(al.config.MinLimit))
+
+	case float64(al.inFlight)*2 >= al.limit:
+		al.limit = min(al.limit+1, float64(al.config.MaxLimit))
+	}
+	limit := al.limit
+	al.lock.Unlock()
+
+	if al.parent != nil {
+		al.parent.Observe(duration, err)
+	} else {
+		adaptiveLimitGauge.Set(limit)
+	}
+}
+
+func isOverloaded(duration time.Duration, err error, latencyThreshold time.Duration) bool {
+	if latencyThreshold > 0 && duration > latencyThreshold {
+		return true
+	}
+
+	if err == nil {
+		return false
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	switch status.Code(err) {
+	case codes.ResourceExhausted, codes.DeadlineExceeded, codes.Unavailable:
+		return true
+	default:
+		return false
+	}
+}
+
+type limiterCtxKey struct{}
+
+// ContextWithLimiter returns a context carrying the adaptive limiter, which will be used by
+// preloaded task runners created with the context.
+func ContextWithLimiter(ctx context.Context, limiter *AdaptiveLimiter) context.Context {
+	return context.WithValue(ctx, limiterCtxKey{}, limiter)
+}
+
+// LimiterFromContext returns the adaptive limiter found in the context, if any.
+func LimiterFromContext(ctx context.Context) *AdaptiveLimiter {
+	limiter, _ := ctx.Value(limiterCtxKey{}).(*AdaptiveLimiter)
+	return limiter
+}
@@ -0,0 +1,107 @@
+package taskrunner
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestAdaptiveLimiterBacksOffOnOverload(t *testing.T) {
+	limiter := newAdaptiveLimiter(nil, AdaptiveLimiterConfig{
+		MinLimit:         2,
+		MaxLimit:         10,
+		LatencyThreshold: 100 * time.Millisecond,
+	})
+	require.Equal(t, uint32(10), limiter.Limit())
+
+	limiter.Observe(200*time.Millisecond, nil)
+	require.Equal(t, uint32(9), limiter.Limit())
+
+	limiter.Observe(time.Millisecond, status.Error(codes.ResourceExhausted, "too many connections"))
+	require.Equal(t, uint32(8), limiter.Limit())
+
+	for range 100 {
+		limiter.Observe(time.Millisecond, context.DeadlineExceeded)
+	}
+	require.Equal(t, uint32(2), limiter.Limit())
+
+	// Errors which do not indicate overload do not change the limit.
+	limiter.Observe(time.Millisecond, errors.New("some error"))
+	require.Equal(t, uint32(2), limiter.Limit())
+}
+
+func TestAdaptiveLimiterIncreasesWhenUtilized(t *testing.T) {
+	limiter := newAdaptiveLimiter(nil, AdaptiveLimiterConfig{MinLimit: 1, MaxLimit: 4})
+	for range 100 {
+		limiter.Observe(time.Millisecond, context.DeadlineExceeded)
+	}
+	require.Equal(t, uint32(1), limiter.Limit())
+
+	// The limit is not increased unless it is being utilized.
+	limiter.Observe(time.Millisecond, nil)
+	require.Equal(t, uint32(1), limiter.Limit())
+
+	require.True(t, limiter.TryAcquire())
+	limiter.Observe(time.Millisecond, nil)
+	require.Equal(t, uint32(2), limiter.Limit())
+	limiter.Observe(time.Millisecond, nil)
+	require.Equal(t, uint32(3), limiter.Limit())
+
+	require.True(t, limiter.TryAcquire())
+	for range 10 {
+		limiter.Observe(time.Millisecond, nil)
+	}
+	require.Equal(t, uint32(4), limiter.Limit())
+
+	limiter.Release()
+	limiter.Release()
+}
+
+func TestAdaptiveLimiterSetMaxLimit(t *testing.T) {
+	limiter := newAdaptiveLimiter(nil, AdaptiveLimiterConfig{MinLimit: 1, MaxLimit: 4})
+	require.Equal(t, uint32(4), limiter.Limit())
+
+	limiter.SetMaxLimit(2)
+	require.Equal(t, uint32(2), limiter.Limit())
+
+	limiter.SetMaxLimit(0)
+	require.Equal(t, uint32(1), limiter.Limit())
+
+	// A raised maximum is reached as the limit is utilized.
+	limiter.SetMaxLimit(3)
+	require.Equal(t, uint32(1), limiter.Limit())
+	require.True(t, limiter.TryAcquire())
+	for range 10 {
+		limiter.Observe(time.Millisecond, nil)
+	}
+	require.Equal(t, uint32(3), limiter.Limit())
+}
+
+func TestAdaptiveLimiterTryAcquire(t *testing.T) {
+	parent := newAdaptiveLimiter(nil, AdaptiveLimiterConfig{MaxLimit: 3})
+	first := parent.NewRequestLimiter(2)
+	second := parent.NewRequestLimiter(2)
+
+	require.True(t, first.TryAcquire())
+	require.True(t, first.TryAcquire())
+	require.False(t, first.TryAcquire(), "expected request limit to be reached")
+
+	require.True(t, second.TryAcquire())
+	require.False(t, second.TryAcquire(), "expected server limit to be reached")
+
+	first.Release()
+	require.True(t, second.TryAcquire())
+	require.False(t, second.TryAcquire(), "expected request limit to be reached")
+}
+
+func TestLimiterFromContext(t *testing.T) {
+	require.Nil(t, LimiterFromContext(context.Background()))
+
+	limiter := NewAdaptiveLimiter(AdaptiveLimiterConfig{MaxLimit: 5})
+	require.Same(t, limiter, LimiterFromContext(ContextWithLimiter(context.Background(), limiter)))
+}
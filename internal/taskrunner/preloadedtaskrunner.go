@@ -3,6 +3,7 @@ package taskrunner
 import (
 	"context"
 	"sync"
+	"time"
 )
 
 // PreloadedTaskRunner is a task runner that invokes a series of preloaded tasks,
@@ -18,6 +19,11 @@ type PreloadedTaskRunner struct {
 	// not exceed the concurrencyLimit with spawned goroutines.
 	sem chan struct{}
 
+	// limiter, if non-nil, is the adaptive limiter which must be acquired to spawn any
+	// goroutine beyond the first.
+	limiter *AdaptiveLimiter
+	spawned int
+
 	wg    sync.WaitGroup
 	err   error
 	lock  sync.Mutex
@@ -32,10 +38,11 @@ func NewPreloadedTaskRunner(ctx context.Context, concurrencyLimit uint16, initia
 
 	ctxWithCancel, cancel := context.WithCancel(ctx)
 	return &PreloadedTaskRunner{
-		ctx:    ctxWithCancel,
-		cancel: cancel,
-		sem:    make(chan struct{}, concurrencyLimit),
-		tasks:  make([]TaskFunc, 0, initialCapacity),
+		ctx:     ctxWithCancel,
+		cancel:  cancel,
+		sem:     make(chan struct{}, concurrencyLimit),
+		limiter: LimiterFromContext(ctx),
+		tasks:   make([]TaskFunc, 0, initialCapacity),
 	}
 }
 
@@ -71,7 +78,16 @@ func (tr *PreloadedTaskRunner) spawnIfAvailable() {
 	// been canceled, in which case nothing needs to be done.
 	select {
 	case tr.sem <- struct{}{}:
-		go tr.runner()
+		// The first runner is always spawned, to ensure the tasks make progress. Any further
+		// runners are only spawned if permitted by the adaptive limiter, if any.
+		limited := tr.limiter != nil && tr.spawned > 0
+		if limited && !tr.limiter.TryAcquire() {
+			<-tr.sem
+			return
+		}
+
+		tr.spawned++
+		go tr.runner(limited)
 
 	case <-tr.ctx.Done():
 		// If the context was canceled, nothing more to do.
@@ -83,7 +99,11 @@ func (tr *PreloadedTaskRunner) spawnIfAvailable() {
 	}
 }
 
-func (tr *PreloadedTaskRunner) runner() {
+func (tr *PreloadedTaskRunner) runner(limited bool) {
+	if limited {
+		defer tr.limiter.Release()
+	}
+
 	for {
 		select {
 		case <-tr.ctx.Done():
@@ -99,7 +119,11 @@ func (tr *PreloadedTaskRunner) runner() {
 			}
 
 			// Run the task. If an error occurs, store it and cancel any further tasks.
+			start := time.Now()
 			err := task(tr.ctx)
+			if tr.limiter != nil {
+				tr.limiter.Observe(time.Since(start), err)
+			}
 			if err != nil {
 				tr.storeErrorAndCancel(err)
 			}
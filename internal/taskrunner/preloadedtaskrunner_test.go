@@ -169,3 +169,67 @@ func TestPreloadedTaskRunnerEmpty(t *testing.T) {
 	err := tr.StartAndWait()
 	require.NoError(t, err)
 }
+
+func TestPreloadedTaskRunnerRespectsAdaptiveLimiter(t *testing.T) {
+	t.Parallel()
+
+	limiter := newAdaptiveLimiter(nil, AdaptiveLimiterConfig{MinLimit: 1, MaxLimit: 2})
+	ctx := ContextWithLimiter(context.Background(), limiter)
+
+	tr := NewPreloadedTaskRunner(ctx, 10, 10)
+
+	var lock sync.Mutex
+	running := 0
+	maxRunning := 0
+	for i := 0; i < 10; i++ {
+		tr.Add(func(ctx context.Context) error {
+			lock.Lock()
+			running++
+			maxRunning = max(maxRunning, running)
+			lock.Unlock()
+
+			time.Sleep(10 * time.Millisecond)
+
+			lock.Lock()
+			running--
+			lock.Unlock()
+			return nil
+		})
+	}
+
+	require.NoError(t, tr.StartAndWait())
+
+	// One runner is always spawned, with the limiter allowing two more.
+	require.LessOrEqual(t, maxRunning, 3)
+
+	// Ensure all the permits have been released.
+	require.Eventually(t, func() bool {
+		limiter.lock.Lock()
+		defer limiter.lock.Unlock()
+		return limiter.inFlight == 0
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestPreloadedTaskRunnerMakesProgressWithExhaustedLimiter(t *testing.T) {
+	t.Parallel()
+
+	limiter := newAdaptiveLimiter(nil, AdaptiveLimiterConfig{MinLimit: 1, MaxLimit: 1})
+	require.True(t, limiter.TryAcquire())
+	defer limiter.Release()
+
+	ctx := ContextWithLimiter(context.Background(), limiter)
+	tr := NewPreloadedTaskRunner(ctx, 10, 5)
+
+	completed := 0
+	for i := 0; i < 5; i++ {
+		tr.Add(func(ctx context.Context) error {
+			completed++
+			return nil
+		})
+	}
+
+	testutil.RequireWithin(t, func(t *testing.T) {
+		require.NoError(t, tr.StartAndWait())
+	}, 5*time.Second)
+	require.Equal(t, 5, completed)
+}
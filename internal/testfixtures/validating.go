@@ -103,6 +103,13 @@ func (vsr validatingSnapshotReader) QueryRelationships(ctx context.Context,
 	return vsr.delegate.QueryRelationships(ctx, filter, opts...)
 }
 
+func (vsr validatingSnapshotReader) QueryRelationshipsBatch(ctx context.Context,
+	filters []datastore.RelationshipsFilter,
+	opts ...options.QueryOptionsOption,
+) (datastore.TaggedRelationshipIterator, error) {
+	return vsr.delegate.QueryRelationshipsBatch(ctx, filters, opts...)
+}
+
 func (vsr validatingSnapshotReader) ReadNamespaceByName(
 	ctx context.Context,
 	nsName string,
@@ -196,6 +203,23 @@ func (vrwt validatingReadWriteTransaction) StoreCounterValue(ctx context.Context
 	return vrwt.delegate.StoreCounterValue(ctx, name, value, computedAtRevision)
 }
 
+func (vrwt validatingReadWriteTransaction) ReadRecords(ctx context.Context, keyPrefix string) ([]datastore.Record, error) {
+	return vrwt.delegate.ReadRecords(ctx, keyPrefix)
+}
+
+func (vrwt validatingReadWriteTransaction) WriteRecords(ctx context.Context, records ...datastore.Record) error {
+	for _, record := range records {
+		if record.Key == "" {
+			return errors.New("record key must not be empty")
+		}
+	}
+	return vrwt.delegate.WriteRecords(ctx, records...)
+}
+
+func (vrwt validatingReadWriteTransaction) DeleteRecords(ctx context.Context, keys ...string) error {
+	return vrwt.delegate.DeleteRecords(ctx, keys...)
+}
+
 func (vrwt validatingReadWriteTransaction) WriteNamespaces(ctx context.Context, newConfigs ...*core.NamespaceDefinition) error {
 	for _, newConfig := range newConfigs {
 		if err := newConfig.Validate(); err != nil {
@@ -10,8 +10,10 @@ import (
 	"google.golang.org/grpc"
 
 	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/internal/datastore/proxy"
 	"github.com/authzed/spicedb/internal/dispatch/graph"
 	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
+	"github.com/authzed/spicedb/internal/middleware/errorcodes"
 	"github.com/authzed/spicedb/pkg/cmd/server"
 	"github.com/authzed/spicedb/pkg/cmd/util"
 	"github.com/authzed/spicedb/pkg/datastore"
@@ -22,9 +24,13 @@ import (
 // ServerConfig is configuration for the test server.
 type ServerConfig struct {
 	MaxUpdatesPerWrite         uint16
+	MaxUpdatesPerChunkedWrite  uint32
 	MaxPreconditionsCount      uint16
 	MaxRelationshipContextSize int
 	StreamingAPITimeout        time.Duration
+	WriteIdempotencyKeyTTL     time.Duration
+	LookupJobsDirectory        string
+	DeletionProtection         proxy.DeletionProtectionConfig
 }
 
 var DefaultTestServerConfig = ServerConfig{
@@ -69,6 +75,14 @@ func NewTestServerWithConfigAndDatastore(require *require.Assertions,
 	dsInitFunc func(datastore.Datastore, *require.Assertions) (datastore.Datastore, datastore.Revision),
 ) (*grpc.ClientConn, func(), datastore.Datastore, datastore.Revision) {
 	ds, revision := dsInitFunc(emptyDS, require)
+
+	// The middleware below replaces those of the server, and so must provide the datastore with
+	// the proxies the server would have added to it.
+	middlewareDS := ds
+	if config.DeletionProtection.Enabled() {
+		middlewareDS = proxy.NewDeletionProtectionProxy(ds, config.DeletionProtection)
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	srv, err := server.NewConfigWithOptionsAndDefaults(
 		server.WithEnableExperimentalRelationshipExpiration(true),
@@ -77,9 +91,17 @@ func NewTestServerWithConfigAndDatastore(require *require.Assertions,
 		server.WithDispatchMaxDepth(50),
 		server.WithMaximumPreconditionCount(config.MaxPreconditionsCount),
 		server.WithMaximumUpdatesPerWrite(config.MaxUpdatesPerWrite),
+		server.WithMaximumUpdatesPerChunkedWrite(config.MaxUpdatesPerChunkedWrite),
 		server.WithStreamingAPITimeout(config.StreamingAPITimeout),
 		server.WithMaxCaveatContextSize(4096),
 		server.WithMaxRelationshipContextSize(config.MaxRelationshipContextSize),
+		server.WithWriteIdempotencyKeyTTL(config.WriteIdempotencyKeyTTL),
+		server.WithEnableExperimentalReconciliation(true),
+		server.WithEnableExperimentalLookupJobs(config.LookupJobsDirectory != ""),
+		server.WithLookupJobsDirectory(config.LookupJobsDirectory),
+		server.WithDeletionProtectionMaxDeletedRelationships(config.DeletionProtection.MaxDeletedRelationships),
+		server.SetDeletionProtectionObjectTypes(config.DeletionProtection.ProtectedObjectTypes),
+		server.WithDeletionProtectionAdminToken(config.DeletionProtection.AdminToken),
 		server.WithGRPCServer(util.GRPCServerConfig{
 			Network: util.BufferedNetwork,
 			Enabled: true,
@@ -99,9 +121,13 @@ func NewTestServerWithConfigAndDatastore(require *require.Assertions,
 						Name:       "logging",
 						Middleware: logging.UnaryServerInterceptor(),
 					},
+					{
+						Name:       "errorcodes",
+						Middleware: errorcodes.UnaryServerInterceptor(),
+					},
 					{
 						Name:       "datastore",
-						Middleware: datastoremw.UnaryServerInterceptor(ds),
+						Middleware: datastoremw.UnaryServerInterceptor(middlewareDS),
 					},
 					{
 						Name:       "consistency",
@@ -122,9 +148,13 @@ func NewTestServerWithConfigAndDatastore(require *require.Assertions,
 						Name:       "logging",
 						Middleware: logging.StreamServerInterceptor(),
 					},
+					{
+						Name:       "errorcodes",
+						Middleware: errorcodes.StreamServerInterceptor(),
+					},
 					{
 						Name:       "datastore",
-						Middleware: datastoremw.StreamServerInterceptor(ds),
+						Middleware: datastoremw.StreamServerInterceptor(middlewareDS),
 					},
 					{
 						Name:       "consistency",
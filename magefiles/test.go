@@ -46,6 +46,32 @@ func (Test) unit(coverage bool) error {
 	return goTest("./...", args...)
 }
 
+// fuzzTargets are the native fuzz targets hardening schema and caveat inputs, keyed by package.
+var fuzzTargets = map[string][]string{
+	"./pkg/schemadsl/parser":     {"FuzzParse"},
+	"./pkg/schemadsl/compiler":   {"FuzzCompile"},
+	"./pkg/caveats":              {"FuzzCompileCaveat"},
+	"./internal/services/shared": {"FuzzValidateSchemaChanges"},
+}
+
+// Fuzz Runs each schema and caveat fuzz target for a short, bounded time
+func (Test) Fuzz() error {
+	fuzzTime := os.Getenv("FUZZ_TIME")
+	if fuzzTime == "" {
+		fuzzTime = "30s"
+	}
+
+	for pkg, targets := range fuzzTargets {
+		for _, target := range targets {
+			fmt.Printf("fuzzing %s in %s\n", target, pkg)
+			if err := goTest(pkg, "-run", "^$", "-fuzz", "^"+target+"$", "-fuzztime", fuzzTime); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 // Image Run tests that run the built image
 func (Test) Image() error {
 	mg.Deps(Build{}.Testimage)
@@ -160,3 +186,38 @@ func consistencyTest(datastore string) error {
 		"-timeout", "10m",
 		"-run", fmt.Sprintf("TestConsistencyPerDatastore/%s", datastore))
 }
+
+type Testdiff mg.Namespace
+
+// Crdb Run differential tests against memdb for crdb
+func (Testdiff) Crdb() error {
+	return differentialTest("cockroachdb")
+}
+
+// Spanner Run differential tests against memdb for spanner
+func (Testdiff) Spanner() error {
+	return differentialTest("spanner")
+}
+
+// Postgres Run differential tests against memdb for postgres
+func (Testdiff) Postgres() error {
+	return differentialTest("postgres$")
+}
+
+// Pgbouncer Run differential tests against memdb for postgres with pgbouncer
+func (Testdiff) Pgbouncer() error {
+	return differentialTest("postgres-pgbouncer")
+}
+
+// Mysql Run differential tests against memdb for mysql
+func (Testdiff) Mysql() error {
+	return differentialTest("mysql")
+}
+
+func differentialTest(datastore string) error {
+	mg.Deps(checkDocker)
+	return goTest("./internal/datastore/differential/...",
+		"-tags", "ci,docker",
+		"-timeout", "10m",
+		"-run", fmt.Sprintf("TestDriversAgainstOracle/%s", datastore))
+}
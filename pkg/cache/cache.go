@@ -80,6 +80,12 @@ type Cache[K KeyString, V any] interface {
 	zerolog.LogObjectMarshaler
 }
 
+// Resizable is a cache whose capacity can be changed while it is in use.
+type Resizable interface {
+	// SetMaxCost sets the capacity of the cache, evicting items if it is lowered.
+	SetMaxCost(maxCost int64)
+}
+
 // Metrics defines metrics exported by the cache.
 type Metrics interface {
 	// Hits is the number of cache hits.
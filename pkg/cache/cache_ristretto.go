@@ -82,7 +82,14 @@ func (w wrapped[K, V]) Wait() {
 	w.ristretto.Wait()
 }
 
-var _ Cache[StringKey, any] = (*wrapped[StringKey, any])(nil)
+func (w wrapped[K, V]) SetMaxCost(maxCost int64) {
+	w.ristretto.UpdateMaxCost(maxCost)
+}
+
+var (
+	_ Cache[StringKey, any] = (*wrapped[StringKey, any])(nil)
+	_ Resizable             = (*wrapped[StringKey, any])(nil)
+)
 
 func (w wrapped[K, V]) GetMetrics() Metrics                   { return w.ristretto.Metrics }
 func (w wrapped[K, V]) MarshalZerologObject(e *zerolog.Event) { e.EmbedObject(w.config) }
@@ -44,6 +44,13 @@ var (
 		[]string{"cache"},
 		nil,
 	)
+
+	descCostBytes = prometheus.NewDesc(
+		stringz.Join("_", promNamespace, promSubsystem, "cost_bytes"),
+		"Current cost of the entries held in the cache",
+		[]string{"cache"},
+		nil,
+	)
 )
 
 var caches sync.Map
@@ -78,10 +85,21 @@ func (c collector) Collect(ch chan<- prometheus.Metric) {
 		ch <- prometheus.MustNewConstMetric(descCacheMissesTotal, prometheus.CounterValue, float64(metrics.Misses()), cacheName)
 		ch <- prometheus.MustNewConstMetric(descCostAddedBytes, prometheus.CounterValue, float64(metrics.CostAdded()), cacheName)
 		ch <- prometheus.MustNewConstMetric(descCostEvictedBytes, prometheus.CounterValue, float64(metrics.CostEvicted()), cacheName)
+		ch <- prometheus.MustNewConstMetric(descCostBytes, prometheus.GaugeValue, float64(currentCost(metrics)), cacheName)
 		return true
 	})
 }
 
+// currentCost returns the cost of the entries currently held in a cache. Updates to the cost of
+// an existing entry are reflected in the cost added, and removals of all kinds in the cost evicted.
+func currentCost(metrics Metrics) uint64 {
+	added, evicted := metrics.CostAdded(), metrics.CostEvicted()
+	if evicted > added {
+		return 0
+	}
+	return added - evicted
+}
+
 type withMetrics interface {
 	GetMetrics() Metrics
 }
@@ -401,3 +401,34 @@ func TestRewriteVariable(t *testing.T) {
 		})
 	}
 }
+
+func FuzzCompileCaveat(f *testing.F) {
+	f.Add("a == 1")
+	f.Add("a + b == 2")
+	f.Add("l.all(i, i > 42)")
+	f.Add("m['foo'] == 'bar' && a < b")
+	f.Add("a +")
+	f.Add("")
+
+	vars := map[string]types.VariableType{
+		"a": types.IntType,
+		"b": types.IntType,
+		"l": types.MustListType(types.IntType),
+		"m": types.MustMapType(types.StringType),
+	}
+
+	f.Fuzz(func(t *testing.T, expr string) {
+		compiled, err := CompileCaveatWithName(MustEnvForVariables(vars), expr, "fuzz")
+		if err != nil {
+			return
+		}
+
+		// Any successfully compiled caveat must round-trip through serialization.
+		serialized, err := compiled.Serialize()
+		require.NoError(t, err)
+
+		deserialized, err := DeserializeCaveat(serialized, vars)
+		require.NoError(t, err)
+		require.Equal(t, "fuzz", deserialized.Name())
+	})
+}
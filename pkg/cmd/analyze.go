@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/go-logr/zerologr"
+	"github.com/jzelinskie/cobrautil/v2"
+	"github.com/jzelinskie/cobrautil/v2/cobrazerolog"
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+
+	"github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/pkg/cmd/termination"
+	"github.com/authzed/spicedb/pkg/schemaanalysis"
+)
+
+// AnalyzeSchemaConfig is the configuration for the analyze-schema command.
+type AnalyzeSchemaConfig struct {
+	// FailOnFindings causes the command to fail if the schema contains any unreachable relations
+	// or any cycles which can never be resolved.
+	FailOnFindings bool
+}
+
+// ErrSchemaAnalysisFindings is returned by the analyze-schema command if FailOnFindings is set and
+// the schema contains findings.
+var ErrSchemaAnalysisFindings = errors.New("schema analysis found unreachable relations or unresolvable cycles")
+
+func RegisterAnalyzeSchemaFlags(cmd *cobra.Command, config *AnalyzeSchemaConfig) {
+	cmd.Flags().BoolVar(&config.FailOnFindings, "fail-on-findings", false, "exit with an error if the schema contains unreachable relations or unresolvable cycles")
+}
+
+func NewAnalyzeSchemaCommand(programName string, config *AnalyzeSchemaConfig) *cobra.Command {
+	return &cobra.Command{
+		Use:   "analyze-schema <schema file, or - for stdin>",
+		Short: "statically analyze the permission graph of a schema, outputting JSON",
+		Long:  "Statically analyzes the permission graph of a schema, computing the relations reachable from each permission, detecting unreachable relations, cycles and unbounded recursion, and estimating the worst-case dispatch depth of each permission. The analysis is written as JSON to stdout.",
+		Args:  cobra.ExactArgs(1),
+		PreRunE: cobrautil.CommandStack(
+			cobrautil.SyncViperDotEnvPreRunE(programName, "spicedb.env", zerologr.New(&logging.Logger)),
+			cobrazerolog.New(
+				cobrazerolog.WithTarget(func(logger zerolog.Logger) {
+					logging.SetGlobalLogger(logger)
+				}),
+			).RunE(),
+		),
+		RunE: termination.PublishError(func(cmd *cobra.Command, args []string) error {
+			return analyzeSchema(cmd.InOrStdin(), cmd.OutOrStdout(), args[0], config)
+		}),
+	}
+}
+
+func analyzeSchema(stdin io.Reader, out io.Writer, path string, config *AnalyzeSchemaConfig) error {
+	var schema []byte
+	var err error
+	if path == "-" {
+		schema, err = io.ReadAll(stdin)
+	} else {
+		schema, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read schema: %w", err)
+	}
+
+	report, err := schemaanalysis.AnalyzeSchema(string(schema))
+	if err != nil {
+		return fmt.Errorf("failed to analyze schema: %w", err)
+	}
+
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return err
+	}
+
+	if config.FailOnFindings && report.HasFindings() {
+		return ErrSchemaAnalysisFindings
+	}
+	return nil
+}
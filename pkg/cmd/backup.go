@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"encoding/json"
+
+	"github.com/spf13/cobra"
+
+	"github.com/authzed/spicedb/internal/backup"
+	"github.com/authzed/spicedb/pkg/cmd/server"
+	"github.com/authzed/spicedb/pkg/cmd/termination"
+)
+
+const backupLocationHelp = "The location is a local directory, or the prefix of objects in a bucket, written s3://bucket/prefix, gs://bucket/prefix or azblob://container/prefix. " +
+	"S3 and GCS are accessed with the default credentials of their SDKs, and Azure Blob Storage with the connection string in AZURE_STORAGE_CONNECTION_STRING."
+
+const backupEncryptionKeyHelp = "URI of the KMS key wrapping the data key of the backup, written awskms://<key ID, ARN or alias> or gcpkms://projects/<project>/locations/<location>/keyRings/<key ring>/cryptoKeys/<key>"
+
+// BackupCreateConfig is the configuration for the backup create command.
+type BackupCreateConfig struct {
+	ClientConfig
+
+	backup.Config
+}
+
+// BackupRestoreConfig is the configuration for the backup restore command.
+type BackupRestoreConfig struct {
+	ClientConfig
+
+	backup.RestoreConfig
+}
+
+func NewBackupCommand(programName string) *cobra.Command {
+	backupCmd := &cobra.Command{
+		Use:   "backup",
+		Short: "backup operations",
+		Long:  "Backs up the schema and relationships of a SpiceDB instance to a local directory or object storage, and restores them",
+	}
+
+	createConfig := new(BackupCreateConfig)
+	createCmd := NewBackupCreateCommand(programName, createConfig)
+	RegisterBackupCreateFlags(createCmd, createConfig)
+	backupCmd.AddCommand(createCmd)
+
+	restoreConfig := new(BackupRestoreConfig)
+	restoreCmd := NewBackupRestoreCommand(programName, restoreConfig)
+	RegisterBackupRestoreFlags(restoreCmd, restoreConfig)
+	backupCmd.AddCommand(restoreCmd)
+
+	rewrapKeyCmd := NewBackupRewrapKeyCommand(programName)
+	backupCmd.AddCommand(rewrapKeyCmd)
+
+	return backupCmd
+}
+
+func RegisterBackupCreateFlags(cmd *cobra.Command, config *BackupCreateConfig) {
+	registerClientFlags(cmd, &config.ClientConfig, "back up")
+
+	cmd.Flags().UintVar(&config.ChunkSize, "chunk-size", 10_000, "number of relationships written to each chunk of the backup")
+	cmd.Flags().StringVar(&config.EncryptionKey, "encryption-key", "", "if set, the backup is encrypted with AES-256-GCM with a data key wrapped by this key; "+backupEncryptionKeyHelp)
+}
+
+func NewBackupCreateCommand(programName string, config *BackupCreateConfig) *cobra.Command {
+	return &cobra.Command{
+		Use:   "create <location>",
+		Short: "back up a SpiceDB instance",
+		Long: "Backs up the schema and relationships of a SpiceDB instance, exported at a single revision, to zstd-compressed chunks and a manifest recording the revision and the checksum of each chunk. " +
+			"The manifest is written last, once every chunk has been written. Writes the manifest to stdout. " +
+			"With --encryption-key, each object is encrypted with a random data key, stored in the manifest wrapped by the KMS key, and the manifest is authenticated with the data key. " + backupLocationHelp,
+		Args:    cobra.ExactArgs(1),
+		PreRunE: server.DefaultPreRunE(programName),
+		RunE: termination.PublishError(func(cmd *cobra.Command, args []string) error {
+			conn, err := config.dial()
+			if err != nil {
+				return err
+			}
+			defer conn.Close()
+
+			signalctx := SignalContextWithGracePeriod(cmd.Context(), 0)
+			sink, err := backup.OpenSink(signalctx, args[0])
+			if err != nil {
+				return err
+			}
+			defer sink.Close()
+
+			manifest, err := backup.Create(signalctx, conn, sink, config.Config)
+			if err != nil {
+				return err
+			}
+
+			encoder := json.NewEncoder(cmd.OutOrStdout())
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(manifest)
+		}),
+	}
+}
+
+func RegisterBackupRestoreFlags(cmd *cobra.Command, config *BackupRestoreConfig) {
+	registerClientFlags(cmd, &config.ClientConfig, "restore into")
+
+	cmd.Flags().StringVar(&config.EncryptionKey, "encryption-key", "", "if set, the data key of an encrypted backup is unwrapped with this key instead of the key recorded in its manifest, and backups which are not encrypted are rejected; "+backupEncryptionKeyHelp)
+}
+
+func NewBackupRestoreCommand(programName string, config *BackupRestoreConfig) *cobra.Command {
+	return &cobra.Command{
+		Use:   "restore <location>",
+		Short: "restore a backup into a SpiceDB instance",
+		Long: "Restores the schema and relationships of a backup into a SpiceDB instance without relationships, verifying each chunk against the checksum in the manifest before importing it. " +
+			"The data key of an encrypted backup is unwrapped by the KMS key recorded in its manifest, which KMS unwraps after the key has been rotated; --encryption-key unwraps it with another key. " +
+			"The manifest of an encrypted backup is verified against its MAC; that of a backup which is not encrypted is not authenticated. " +
+			"Writes a JSON summary of the restore to stdout. " + backupLocationHelp,
+		Args:    cobra.ExactArgs(1),
+		PreRunE: server.DefaultPreRunE(programName),
+		RunE: termination.PublishError(func(cmd *cobra.Command, args []string) error {
+			conn, err := config.dial()
+			if err != nil {
+				return err
+			}
+			defer conn.Close()
+
+			signalctx := SignalContextWithGracePeriod(cmd.Context(), 0)
+			sink, err := backup.OpenSink(signalctx, args[0])
+			if err != nil {
+				return err
+			}
+			defer sink.Close()
+
+			summary, err := backup.Restore(signalctx, conn, sink, config.RestoreConfig)
+			if err != nil {
+				return err
+			}
+
+			encoder := json.NewEncoder(cmd.OutOrStdout())
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(summary)
+		}),
+	}
+}
+
+func NewBackupRewrapKeyCommand(programName string) *cobra.Command {
+	return &cobra.Command{
+		Use:   "rewrap-key <location> <key URI>",
+		Short: "wrap the data key of an encrypted backup with another KMS key",
+		Long: "Unwraps the data key of an encrypted backup with the KMS key recorded in its manifest, and replaces it with the data key wrapped by another KMS key, such as before retiring the key recorded. " +
+			"The encrypted objects of the backup are not rewritten. Writes the updated manifest to stdout. The key URI is written " +
+			"awskms://<key ID, ARN or alias> or gcpkms://projects/<project>/locations/<location>/keyRings/<key ring>/cryptoKeys/<key>. " + backupLocationHelp,
+		Args:    cobra.ExactArgs(2),
+		PreRunE: server.DefaultPreRunE(programName),
+		RunE: termination.PublishError(func(cmd *cobra.Command, args []string) error {
+			signalctx := SignalContextWithGracePeriod(cmd.Context(), 0)
+			sink, err := backup.OpenSink(signalctx, args[0])
+			if err != nil {
+				return err
+			}
+			defer sink.Close()
+
+			manifest, err := backup.RewrapKey(signalctx, sink, args[1])
+			if err != nil {
+				return err
+			}
+
+			encoder := json.NewEncoder(cmd.OutOrStdout())
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(manifest)
+		}),
+	}
+}
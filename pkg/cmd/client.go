@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/authzed/grpcutil"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// ClientConfig is the configuration of commands connecting to a SpiceDB instance.
+type ClientConfig struct {
+	// Endpoint is the address of the SpiceDB instance.
+	Endpoint string
+
+	// Token is the preshared key used to authenticate with the SpiceDB instance.
+	Token string
+
+	// Insecure, if true, connects without TLS.
+	Insecure bool
+
+	// NoVerifyCA, if true, does not verify the certificate presented by the SpiceDB instance.
+	NoVerifyCA bool
+}
+
+func registerClientFlags(cmd *cobra.Command, config *ClientConfig, purpose string) {
+	cmd.Flags().StringVar(&config.Endpoint, "endpoint", "localhost:50051", "address of the SpiceDB instance to "+purpose)
+	cmd.Flags().StringVar(&config.Token, "token", "", "preshared key used to authenticate with the SpiceDB instance")
+	cmd.Flags().BoolVar(&config.Insecure, "insecure", false, "connect to the SpiceDB instance without TLS")
+	cmd.Flags().BoolVar(&config.NoVerifyCA, "no-verify-ca", false, "do not verify the certificate presented by the SpiceDB instance")
+}
+
+func (c *ClientConfig) dial() (*grpc.ClientConn, error) {
+	var opts []grpc.DialOption
+	if c.Insecure {
+		opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if c.Token != "" {
+			opts = append(opts, grpcutil.WithInsecureBearerToken(c.Token))
+		}
+	} else {
+		verification := grpcutil.VerifyCA
+		if c.NoVerifyCA {
+			verification = grpcutil.SkipVerifyCA
+		}
+
+		certs, err := grpcutil.WithSystemCerts(verification)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load system certificates: %w", err)
+		}
+		opts = append(opts, certs)
+		if c.Token != "" {
+			opts = append(opts, grpcutil.WithBearerToken(c.Token))
+		}
+	}
+
+	conn, err := grpc.NewClient(c.Endpoint, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", c.Endpoint, err)
+	}
+	return conn, nil
+}
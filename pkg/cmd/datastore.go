@@ -2,18 +2,23 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
 	"github.com/spf13/cobra"
 
 	"github.com/authzed/spicedb/internal/datastore/common"
 	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/internal/services/shared"
 	"github.com/authzed/spicedb/pkg/cmd/datastore"
 	"github.com/authzed/spicedb/pkg/cmd/server"
 	"github.com/authzed/spicedb/pkg/cmd/termination"
 	"github.com/authzed/spicedb/pkg/cmd/util"
 	dspkg "github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/zedtoken"
 )
 
 func RegisterDatastoreRootFlags(_ *cobra.Command) {
@@ -30,6 +35,10 @@ func NewDatastoreCommand(programName string) (*cobra.Command, error) {
 	RegisterMigrateFlags(migrateCmd)
 	datastoreCmd.AddCommand(migrateCmd)
 
+	migrateStatusCmd := NewMigrateStatusCommand(programName)
+	RegisterMigrateStatusFlags(migrateStatusCmd)
+	migrateCmd.AddCommand(migrateStatusCmd)
+
 	cfg := datastore.NewConfigWithOptionsAndDefaults()
 
 	gcCmd := NewGCDatastoreCommand(programName, cfg)
@@ -46,6 +55,37 @@ func NewDatastoreCommand(programName string) (*cobra.Command, error) {
 	util.RegisterCommonFlags(repairCmd)
 	datastoreCmd.AddCommand(repairCmd)
 
+	renameCmd := NewRenameDatastoreCommand(programName, cfg)
+	if err := datastore.RegisterDatastoreFlagsWithPrefix(renameCmd.Flags(), "", cfg); err != nil {
+		return nil, err
+	}
+	RegisterRenameFlags(renameCmd)
+	util.RegisterCommonFlags(renameCmd)
+	datastoreCmd.AddCommand(renameCmd)
+
+	pinCmd := NewPinRevisionCommand(programName, cfg)
+	if err := datastore.RegisterDatastoreFlagsWithPrefix(pinCmd.Flags(), "", cfg); err != nil {
+		return nil, err
+	}
+	RegisterPinRevisionFlags(pinCmd)
+	util.RegisterCommonFlags(pinCmd)
+	datastoreCmd.AddCommand(pinCmd)
+
+	unpinCmd := NewUnpinRevisionCommand(programName, cfg)
+	if err := datastore.RegisterDatastoreFlagsWithPrefix(unpinCmd.Flags(), "", cfg); err != nil {
+		return nil, err
+	}
+	util.RegisterCommonFlags(unpinCmd)
+	datastoreCmd.AddCommand(unpinCmd)
+
+	rewriteCmd := NewRewriteRelationshipsCommand(programName, cfg)
+	if err := datastore.RegisterDatastoreFlagsWithPrefix(rewriteCmd.Flags(), "", cfg); err != nil {
+		return nil, err
+	}
+	RegisterRewriteRelationshipsFlags(rewriteCmd)
+	util.RegisterCommonFlags(rewriteCmd)
+	datastoreCmd.AddCommand(rewriteCmd)
+
 	headCmd := NewHeadCommand(programName)
 	RegisterHeadFlags(headCmd)
 	datastoreCmd.AddCommand(headCmd)
@@ -135,3 +175,285 @@ func NewRepairDatastoreCommand(programName string, cfg *datastore.Config) *cobra
 		}),
 	}
 }
+
+func RegisterPinRevisionFlags(cmd *cobra.Command) {
+	cmd.Flags().Duration("duration", time.Hour, "amount of time for which the revision is pinned")
+}
+
+func NewPinRevisionCommand(programName string, cfg *datastore.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "pin-revision <zedtoken>",
+		Short: "pins a revision, preventing its garbage collection",
+		Long: "Pins the revision of a ZedToken for the given duration, preventing the garbage collection of the data read at it, " +
+			"such as for the duration of a long-running export or investigation. Prints the ID of the pin, which can be removed early with unpin-revision.",
+		Args:    cobra.ExactArgs(1),
+		PreRunE: server.DefaultPreRunE(programName),
+		RunE: termination.PublishError(func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+
+			duration, err := cmd.Flags().GetDuration("duration")
+			if err != nil {
+				return err
+			}
+			if duration <= 0 {
+				return errors.New("duration must be positive")
+			}
+
+			pinner, err := newRevisionPinner(ctx, cfg)
+			if err != nil {
+				return err
+			}
+
+			revision, err := zedtoken.DecodeRevision(&v1.ZedToken{Token: args[0]}, pinner)
+			if err != nil {
+				return fmt.Errorf("invalid zedtoken: %w", err)
+			}
+
+			if err := pinner.CheckRevision(ctx, revision); err != nil {
+				return fmt.Errorf("cannot pin revision: %w", err)
+			}
+
+			expiresAt := time.Now().Add(duration)
+			pinID, err := pinner.PinRevision(ctx, revision, expiresAt)
+			if err != nil {
+				return err
+			}
+
+			log.Ctx(ctx).Info().Stringer("revision", revision).Time("expires_at", expiresAt).Str("pin_id", pinID).Msg("Revision pinned")
+			fmt.Println(pinID)
+			return nil
+		}),
+	}
+}
+
+func NewUnpinRevisionCommand(programName string, cfg *datastore.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:     "unpin-revision <pin ID>",
+		Short:   "removes the pin of a revision",
+		Long:    "Removes a pin created by pin-revision before it expires, allowing the garbage collection of the data read at its revision",
+		Args:    cobra.ExactArgs(1),
+		PreRunE: server.DefaultPreRunE(programName),
+		RunE: termination.PublishError(func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+
+			pinner, err := newRevisionPinner(ctx, cfg)
+			if err != nil {
+				return err
+			}
+
+			if err := pinner.UnpinRevision(ctx, args[0]); err != nil {
+				return err
+			}
+
+			log.Ctx(ctx).Info().Str("pin_id", args[0]).Msg("Revision unpinned")
+			return nil
+		}),
+	}
+}
+
+func newRevisionPinner(ctx context.Context, cfg *datastore.Config) (dspkg.RevisionPinner, error) {
+	// Disable background GC and hedging.
+	cfg.GCInterval = -1 * time.Hour
+	cfg.RequestHedgingEnabled = false
+
+	ds, err := datastore.NewDatastore(ctx, cfg.ToOption())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create datastore: %w", err)
+	}
+
+	pinner := dspkg.UnwrapAs[dspkg.RevisionPinner](ds)
+	if pinner == nil {
+		return nil, fmt.Errorf("datastore of type %T does not support pinning revisions", ds)
+	}
+	return pinner, nil
+}
+
+func RegisterRenameFlags(cmd *cobra.Command) {
+	cmd.Flags().Uint64("batch-size", 0, "number of relationships rewritten in each transaction; if 0, the rename is applied in a single transaction")
+}
+
+func NewRenameDatastoreCommand(programName string, cfg *datastore.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "rename <definition or definition#relation> <new name>",
+		Short: "renames an object definition, relation or permission",
+		Long: "Renames an object definition, or a relation or permission of one, in both the schema and the relationships referencing it. " +
+			"With --batch-size, the new name is first added to the schema alongside the existing one, the relationships are rewritten in batches, and the existing name is then removed; " +
+			"permissions are computed from the relationships not yet rewritten in the meantime. An interrupted rename is resumed by running it again.",
+		Args:    cobra.ExactArgs(2),
+		PreRunE: server.DefaultPreRunE(programName),
+		RunE: termination.PublishError(func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+
+			rename, err := parseSchemaRename(args[0], args[1])
+			if err != nil {
+				return err
+			}
+
+			batchSize, err := cmd.Flags().GetUint64("batch-size")
+			if err != nil {
+				return err
+			}
+
+			// Disable background GC and hedging.
+			cfg.GCInterval = -1 * time.Hour
+			cfg.RequestHedgingEnabled = false
+
+			ds, err := datastore.NewDatastore(ctx, cfg.ToOption())
+			if err != nil {
+				return fmt.Errorf("failed to create datastore: %w", err)
+			}
+
+			log.Ctx(ctx).Info().Stringer("rename", rename).Uint64("batch_size", batchSize).Msg("Running rename...")
+			result, err := shared.RenameInDatastore(ctx, ds, rename, batchSize)
+			if err != nil {
+				return err
+			}
+
+			if result.AlreadyApplied {
+				log.Ctx(ctx).Info().Stringer("rename", rename).Msg("Rename was already applied")
+				return nil
+			}
+
+			log.Ctx(ctx).Info().Uint64("relationships_renamed", result.RelationshipsRenamed).Msg("Rename completed")
+			return nil
+		}),
+	}
+}
+
+// parseSchemaRename parses the rename of an object definition, as `definition`, or of a relation
+// or permission, as `definition#relation`, to the new name, which can be prefixed with the
+// definition of a relation or permission.
+func parseSchemaRename(existing string, newName string) (shared.SchemaRename, error) {
+	definitionName, relationName, isRelation := strings.Cut(existing, "#")
+	if definitionName == "" || (isRelation && relationName == "") {
+		return shared.SchemaRename{}, fmt.Errorf("invalid object definition, relation or permission `%s`", existing)
+	}
+
+	if isRelation {
+		if newDefinitionName, newRelationName, ok := strings.Cut(newName, "#"); ok {
+			if newDefinitionName != definitionName {
+				return shared.SchemaRename{}, fmt.Errorf("cannot move relation `%s` to object definition `%s`", existing, newDefinitionName)
+			}
+			newName = newRelationName
+		}
+	}
+
+	if newName == "" || strings.Contains(newName, "#") {
+		return shared.SchemaRename{}, fmt.Errorf("invalid new name `%s`", newName)
+	}
+
+	return shared.SchemaRename{
+		DefinitionName: definitionName,
+		RelationName:   relationName,
+		NewName:        newName,
+	}, nil
+}
+
+func RegisterRewriteRelationshipsFlags(cmd *cobra.Command) {
+	cmd.Flags().Uint64("batch-size", 1000, "number of relationships rewritten in each transaction")
+	cmd.Flags().String("cursor", "", "cursor logged by an interrupted rewrite, from which to resume it")
+}
+
+func NewRewriteRelationshipsCommand(programName string, cfg *datastore.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "rewrite-relationships <type[#relation][@subject type[#subject relation]]> <expression>",
+		Short: "rewrites the relationships matching a filter with a CEL expression",
+		Long: "Rewrites each relationship matching the filter with a CEL expression, for refactors of the data model. " +
+			"The expression reads the fields of the `relationship` map: resource_type, resource_id, relation, subject_type, subject_id and subject_relation, " +
+			"and results in a map of the fields to change, or in `tombstone` to delete the relationship, such as " +
+			"`relationship.subject_id == \"banned\" ? tombstone : {\"relation\": \"reader\"}`. " +
+			"The relationships are rewritten in batches, logging a cursor after each; an interrupted rewrite is resumed by running it again with --cursor.",
+		Args:    cobra.ExactArgs(2),
+		PreRunE: server.DefaultPreRunE(programName),
+		RunE: termination.PublishError(func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+
+			filter, err := parseRewriteFilter(args[0])
+			if err != nil {
+				return err
+			}
+
+			expression, err := shared.ParseRewriteExpression(args[1])
+			if err != nil {
+				return fmt.Errorf("invalid rewrite expression: %w", err)
+			}
+
+			batchSize, err := cmd.Flags().GetUint64("batch-size")
+			if err != nil {
+				return err
+			}
+
+			cursor, err := cmd.Flags().GetString("cursor")
+			if err != nil {
+				return err
+			}
+
+			// Disable background GC and hedging.
+			cfg.GCInterval = -1 * time.Hour
+			cfg.RequestHedgingEnabled = false
+
+			ds, err := datastore.NewDatastore(ctx, cfg.ToOption())
+			if err != nil {
+				return fmt.Errorf("failed to create datastore: %w", err)
+			}
+
+			// Interrupting the rewrite stops it after the batch in progress.
+			ctx = SignalContextWithGracePeriod(ctx, 0)
+
+			log.Ctx(ctx).Info().Str("filter", args[0]).Stringer("expression", expression).Uint64("batch_size", batchSize).Msg("Rewriting relationships...")
+			rewrite := shared.RelationshipRewrite{Filter: filter, Expression: expression}
+			result, err := shared.RewriteRelationshipsInDatastore(ctx, ds, rewrite, batchSize, cursor, func(progress shared.RelationshipRewriteProgress) {
+				log.Ctx(ctx).Info().
+					Uint64("relationships_read", progress.RelationshipsRead).
+					Uint64("relationships_rewritten", progress.RelationshipsRewritten).
+					Uint64("relationships_deleted", progress.RelationshipsDeleted).
+					Str("cursor", progress.Cursor).
+					Msg("Rewrote batch of relationships")
+			})
+			if err != nil {
+				if result != nil && result.Cursor != "" {
+					log.Ctx(ctx).Warn().Str("cursor", result.Cursor).Msg("Rewrite interrupted; run it again with --cursor to resume it")
+				}
+				return err
+			}
+
+			log.Ctx(ctx).Info().
+				Uint64("relationships_read", result.RelationshipsRead).
+				Uint64("relationships_rewritten", result.RelationshipsRewritten).
+				Uint64("relationships_deleted", result.RelationshipsDeleted).
+				Msg("Rewrite completed")
+			return nil
+		}),
+	}
+}
+
+// parseRewriteFilter parses the filter of the relationships to rewrite, as a resource type with an
+// optional relation, and an optional subject type with an optional subject relation, such as
+// `document#viewer@group#member`.
+func parseRewriteFilter(filter string) (dspkg.RelationshipsFilter, error) {
+	resource, subject, hasSubject := strings.Cut(filter, "@")
+	resourceType, relation, hasRelation := strings.Cut(resource, "#")
+	if resourceType == "" || (hasRelation && relation == "") {
+		return dspkg.RelationshipsFilter{}, fmt.Errorf("invalid relationship filter `%s`", filter)
+	}
+
+	parsed := dspkg.RelationshipsFilter{
+		OptionalResourceType:     resourceType,
+		OptionalResourceRelation: relation,
+	}
+	if !hasSubject {
+		return parsed, nil
+	}
+
+	subjectType, subjectRelation, hasSubjectRelation := strings.Cut(subject, "#")
+	if subjectType == "" || (hasSubjectRelation && subjectRelation == "") {
+		return dspkg.RelationshipsFilter{}, fmt.Errorf("invalid relationship filter `%s`", filter)
+	}
+
+	selector := dspkg.SubjectsSelector{OptionalSubjectType: subjectType}
+	if hasSubjectRelation {
+		selector.RelationFilter = dspkg.SubjectRelationFilter{}.WithRelation(subjectRelation)
+	}
+	parsed.OptionalSubjectsSelectors = []dspkg.SubjectsSelector{selector}
+	return parsed, nil
+}
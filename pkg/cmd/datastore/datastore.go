@@ -103,6 +103,7 @@ type Config struct {
 	LegacyFuzzing               time.Duration `debugmap:"visible"`
 	RevisionQuantization        time.Duration `debugmap:"visible"`
 	MaxRevisionStalenessPercent float64       `debugmap:"visible"`
+	RevisionQuantizationJitter  time.Duration `debugmap:"visible"`
 	CredentialsProviderName     string        `debugmap:"visible"`
 	FilterMaximumIDCount        uint16        `debugmap:"hidden" default:"100"`
 
@@ -113,6 +114,11 @@ type Config struct {
 	EnableDatastoreMetrics         bool           `debugmap:"visible"`
 	DisableStats                   bool           `debugmap:"visible"`
 	IncludeQueryParametersInTraces bool           `debugmap:"visible"`
+	SlowQueryThreshold             time.Duration  `debugmap:"visible"`
+	ConnMaxErrors                  int            `debugmap:"visible"`
+	PreparedStatementCacheCapacity int            `debugmap:"visible"`
+	IndexPlannerRefreshInterval    time.Duration  `debugmap:"visible"`
+	QueryShapeCacheCapacity        int            `debugmap:"visible"`
 
 	// Read Replicas
 	ReadReplicaConnPool                ConnPoolConfig `debugmap:"visible"`
@@ -131,6 +137,16 @@ type Config struct {
 	RequestHedgingMaxRequests      uint64        `debugmap:"visible"`
 	RequestHedgingQuantile         float64       `debugmap:"visible"`
 
+	// Fault Injection
+	FaultInjectionEnabled bool `debugmap:"visible"`
+
+	// Read-only Degradation
+	ReadOnlyDegradationEnabled       bool          `debugmap:"visible"`
+	ReadOnlyDegradationProbeInterval time.Duration `debugmap:"visible"`
+
+	// Head Revision Polling
+	HeadRevisionMaxStaleness time.Duration `debugmap:"visible"`
+
 	// CRDB
 	FollowerReadDelay         time.Duration `debugmap:"visible"`
 	MaxRetries                int           `debugmap:"visible"`
@@ -140,8 +156,15 @@ type Config struct {
 	ConnectRate               time.Duration `debugmap:"visible"`
 
 	// Postgres
-	GCInterval         time.Duration `debugmap:"visible"`
-	GCMaxOperationTime time.Duration `debugmap:"visible"`
+	GCInterval               time.Duration `debugmap:"visible"`
+	GCMaxOperationTime       time.Duration `debugmap:"visible"`
+	RelationshipsGCRetention time.Duration `debugmap:"visible"`
+	NamespacesGCRetention    time.Duration `debugmap:"visible"`
+	GCLeaseDuration          time.Duration `debugmap:"visible"`
+	WatchLogicalReplication  bool          `debugmap:"visible"`
+
+	LowPriorityReadConnsMaxOpen int           `debugmap:"visible"`
+	LowPriorityStatementTimeout time.Duration `debugmap:"visible"`
 
 	// Spanner
 	SpannerCredentialsFile string `debugmap:"visible"`
@@ -150,6 +173,14 @@ type Config struct {
 	SpannerMinSessions     uint64 `debugmap:"visible"`
 	SpannerMaxSessions     uint64 `debugmap:"visible"`
 
+	SpannerDirectedReadLocation    string `debugmap:"visible"`
+	SpannerDirectedReadReplicaType string `debugmap:"visible"`
+
+	SpannerWatchEmitTransactionMetadata bool `debugmap:"visible"`
+
+	SpannerMaxMutationsPerCommit int  `debugmap:"visible"`
+	SpannerRelaxedWriteAtomicity bool `debugmap:"visible"`
+
 	// MySQL
 	TablePrefix string `debugmap:"visible"`
 
@@ -157,6 +188,17 @@ type Config struct {
 	RelationshipIntegrityEnabled     bool            `debugmap:"visible"`
 	RelationshipIntegrityCurrentKey  RelIntegrityKey `debugmap:"visible"`
 	RelationshipIntegrityExpiredKeys []string        `debugmap:"visible"`
+	RelationshipIntegrityMode        string          `debugmap:"visible"`
+
+	// Caveat Context Encryption
+	CaveatContextEncryptionEnabled            bool     `debugmap:"visible"`
+	CaveatContextEncryptionCurrentKeyID       string   `debugmap:"visible"`
+	CaveatContextEncryptionCurrentKeyFilename string   `debugmap:"visible"`
+	CaveatContextEncryptionPreviousKeys       []string `debugmap:"visible"`
+
+	// Write Throttling
+	WriteThrottlingLimits  []string      `debugmap:"visible"`
+	WriteThrottlingMaxWait time.Duration `debugmap:"visible"`
 
 	// Internal
 	WatchBufferLength       uint16        `debugmap:"visible"`
@@ -224,8 +266,15 @@ func RegisterDatastoreFlagsWithPrefix(flagSet *pflag.FlagSet, prefix string, opt
 	flagSet.DurationVar(&opts.GCWindow, flagName("datastore-gc-window"), defaults.GCWindow, "amount of time before revisions are garbage collected")
 	flagSet.DurationVar(&opts.GCInterval, flagName("datastore-gc-interval"), defaults.GCInterval, "amount of time between passes of garbage collection (postgres driver only)")
 	flagSet.DurationVar(&opts.GCMaxOperationTime, flagName("datastore-gc-max-operation-time"), defaults.GCMaxOperationTime, "maximum amount of time a garbage collection pass can operate before timing out (postgres driver only)")
+	flagSet.DurationVar(&opts.RelationshipsGCRetention, flagName("datastore-gc-relationships-retention"), defaults.RelationshipsGCRetention, "amount of time the history of relationships is retained before being garbage collected; retentions shorter than the GC window retain it for the GC window (postgres and mysql drivers only)")
+	flagSet.BoolVar(&opts.WatchLogicalReplication, flagName("datastore-watch-logical-replication"), defaults.WatchLogicalReplication, "stream watch changes from temporary logical replication slots decoded by wal2json instead of polling, requiring wal_level=logical; watches fall back to polling otherwise (postgres driver only)")
+	flagSet.DurationVar(&opts.NamespacesGCRetention, flagName("datastore-gc-namespaces-retention"), defaults.NamespacesGCRetention, "amount of time the history of the schema is retained before being garbage collected; retentions shorter than the GC window retain it for the GC window (postgres and mysql drivers only)")
+	flagSet.DurationVar(&opts.GCLeaseDuration, flagName("datastore-gc-lease-duration"), defaults.GCLeaseDuration, "duration of the lease held by the node elected to run garbage collection, so that a single node of the cluster runs it; 0 runs it on every node (postgres and mysql drivers only)")
+	flagSet.IntVar(&opts.LowPriorityReadConnsMaxOpen, flagName("datastore-low-priority-read-conn-pool-max-open"), defaults.LowPriorityReadConnsMaxOpen, "number of connections of a read pool of their own serving the reads of batch and background priority requests, so that they cannot take the read connections of interactive requests (0 to share the read pool; postgres driver only)")
+	flagSet.DurationVar(&opts.LowPriorityStatementTimeout, flagName("datastore-low-priority-statement-timeout"), defaults.LowPriorityStatementTimeout, "maximum duration of the statements of the write transactions of batch and background priority requests, so that they cannot hold locks contended by interactive writes for long (0 to disable; postgres driver only)")
 	flagSet.DurationVar(&opts.RevisionQuantization, flagName("datastore-revision-quantization-interval"), defaults.RevisionQuantization, "boundary interval to which to round the quantized revision")
 	flagSet.Float64Var(&opts.MaxRevisionStalenessPercent, flagName("datastore-revision-quantization-max-staleness-percent"), defaults.MaxRevisionStalenessPercent, "float percentage (where 1 = 100%) of the revision quantization interval where we may opt to select a stale revision for performance reasons. Defaults to 0.1 (representing 10%)")
+	flagSet.DurationVar(&opts.RevisionQuantizationJitter, flagName("datastore-revision-quantization-jitter"), defaults.RevisionQuantizationJitter, "maximum random offset by which the revision quantization window boundaries of this node are shifted, so that cached revisions do not expire on every node of a fleet at once; adds up to this duration to the staleness of revisions")
 	flagSet.BoolVar(&opts.ReadOnly, flagName("datastore-readonly"), defaults.ReadOnly, "set the service to read-only mode")
 	flagSet.StringSliceVar(&opts.BootstrapFiles, flagName("datastore-bootstrap-files"), defaults.BootstrapFiles, "bootstrap data yaml files to load")
 	flagSet.BoolVar(&opts.BootstrapOverwrite, flagName("datastore-bootstrap-overwrite"), defaults.BootstrapOverwrite, "overwrite any existing data with bootstrap data (this can be quite slow)")
@@ -234,6 +283,10 @@ func RegisterDatastoreFlagsWithPrefix(flagSet *pflag.FlagSet, prefix string, opt
 	flagSet.DurationVar(&opts.RequestHedgingInitialSlowValue, flagName("datastore-request-hedging-initial-slow-value"), defaults.RequestHedgingInitialSlowValue, "initial value to use for slow datastore requests, before statistics have been collected")
 	flagSet.Uint64Var(&opts.RequestHedgingMaxRequests, flagName("datastore-request-hedging-max-requests"), defaults.RequestHedgingMaxRequests, "maximum number of historical requests to consider")
 	flagSet.Float64Var(&opts.RequestHedgingQuantile, flagName("datastore-request-hedging-quantile"), defaults.RequestHedgingQuantile, "quantile of historical datastore request time over which a request will be considered slow")
+	flagSet.BoolVar(&opts.FaultInjectionEnabled, flagName("datastore-fault-injection"), defaults.FaultInjectionEnabled, "enable injection of latency, errors and stale revisions into datastore operations, controlled via the /debug/datastore-faults endpoint of the metrics server, which requires the preshared key as a bearer token (for resilience testing only)")
+	flagSet.BoolVar(&opts.ReadOnlyDegradationEnabled, flagName("datastore-read-only-degradation"), defaults.ReadOnlyDegradationEnabled, "when the datastore is detected to be read-only, fail writes fast while continuing to serve reads at the last known head revision (postgres, CRDB and MySQL drivers only)")
+	flagSet.DurationVar(&opts.ReadOnlyDegradationProbeInterval, flagName("datastore-read-only-degradation-probe-interval"), defaults.ReadOnlyDegradationProbeInterval, "interval at which a write is let through to detect whether a read-only datastore accepts writes again")
+	flagSet.DurationVar(&opts.HeadRevisionMaxStaleness, flagName("datastore-head-revision-max-staleness"), defaults.HeadRevisionMaxStaleness, "when set, serve head revisions (e.g. of fully consistent requests) from a single head revision polled in the background which is at most this old, instead of querying the datastore for each request; writes made through other nodes may not be observed for up to this duration")
 	flagSet.BoolVar(&opts.EnableDatastoreMetrics, flagName("datastore-prometheus-metrics"), defaults.EnableDatastoreMetrics, "set to false to disabled prometheus metrics from the datastore")
 	// See crdb doc for info about follower reads and how it is configured: https://www.cockroachlabs.com/docs/stable/follower-reads.html
 	flagSet.DurationVar(&opts.FollowerReadDelay, flagName("datastore-follower-read-delay-duration"), 4_800*time.Millisecond, "amount of time to subtract from non-sync revision timestamps to ensure they are sufficiently in the past to enable follower reads (cockroach driver only)")
@@ -246,6 +299,11 @@ func RegisterDatastoreFlagsWithPrefix(flagSet *pflag.FlagSet, prefix string, opt
 	flagSet.StringVar(&opts.SpannerEmulatorHost, flagName("datastore-spanner-emulator-host"), "", "URI of spanner emulator instance used for development and testing (e.g. localhost:9010)")
 	flagSet.Uint64Var(&opts.SpannerMinSessions, flagName("datastore-spanner-min-sessions"), 100, "minimum number of sessions across all Spanner gRPC connections the client can have at a given time")
 	flagSet.Uint64Var(&opts.SpannerMaxSessions, flagName("datastore-spanner-max-sessions"), 400, "maximum number of sessions across all Spanner gRPC connections the client can have at a given time")
+	flagSet.StringVar(&opts.SpannerDirectedReadLocation, flagName("datastore-spanner-directed-read-location"), defaults.SpannerDirectedReadLocation, "replica location (e.g. us-east1) to which snapshot reads will be directed when using a multi-region Spanner instance (omit to use Spanner's default routing)")
+	flagSet.StringVar(&opts.SpannerDirectedReadReplicaType, flagName("datastore-spanner-directed-read-replica-type"), defaults.SpannerDirectedReadReplicaType, `type of replica to which directed reads will be routed ("read-only", "read-write", or empty for any); requires --datastore-spanner-directed-read-location`)
+	flagSet.BoolVar(&opts.SpannerWatchEmitTransactionMetadata, flagName("datastore-spanner-watch-emit-transaction-metadata"), defaults.SpannerWatchEmitTransactionMetadata, "include the Spanner transaction tag and record sequence of the originating transaction in the metadata of watch events")
+	flagSet.IntVar(&opts.SpannerMaxMutationsPerCommit, flagName("datastore-spanner-max-mutations-per-commit"), defaults.SpannerMaxMutationsPerCommit, "maximum number of Spanner mutations the relationship writes of a transaction may buffer into a single commit (at most 80000)")
+	flagSet.BoolVar(&opts.SpannerRelaxedWriteAtomicity, flagName("datastore-spanner-relaxed-write-atomicity"), defaults.SpannerRelaxedWriteAtomicity, "split transactions exceeding the mutations per commit into multiple commits instead of failing them; split transactions are not atomic and may be partially applied")
 	flagSet.StringVar(&opts.TablePrefix, flagName("datastore-mysql-table-prefix"), "", "prefix to add to the name of all SpiceDB database tables")
 	flagSet.StringVar(&opts.MigrationPhase, flagName("datastore-migration-phase"), "", "datastore-specific flag that should be used to signal to a datastore which phase of a multi-step migration it is in")
 	flagSet.StringArrayVar(&opts.AllowedMigrations, flagName("datastore-allowed-migrations"), []string{}, "migration levels that will not fail the health check (in addition to the current head migration)")
@@ -253,11 +311,23 @@ func RegisterDatastoreFlagsWithPrefix(flagSet *pflag.FlagSet, prefix string, opt
 	flagSet.DurationVar(&opts.WatchBufferWriteTimeout, flagName("datastore-watch-buffer-write-timeout"), 1*time.Second, "how long the watch buffer should queue before forcefully disconnecting the reader")
 	flagSet.DurationVar(&opts.WatchConnectTimeout, flagName("datastore-watch-connect-timeout"), 1*time.Second, "how long the watch connection should wait before timing out (cockroachdb driver only)")
 	flagSet.BoolVar(&opts.IncludeQueryParametersInTraces, flagName("datastore-include-query-parameters-in-traces"), false, "include query parameters in traces (postgres and CRDB drivers only)")
+	flagSet.DurationVar(&opts.SlowQueryThreshold, flagName("datastore-slow-query-threshold"), defaults.SlowQueryThreshold, "duration over which relationship queries are logged as slow, including the statement shape, API method and revision (0 to disable; SQL and Spanner drivers only)")
+	flagSet.IntVar(&opts.ConnMaxErrors, flagName("datastore-conn-max-errors"), defaults.ConnMaxErrors, "number of consecutive connection errors after which a pooled connection is closed instead of reused (0 to disable; postgres, CRDB and MySQL drivers only)")
+	flagSet.IntVar(&opts.PreparedStatementCacheCapacity, flagName("datastore-prepared-statement-cache-capacity"), defaults.PreparedStatementCacheCapacity, "number of prepared statements of relationship queries cached on each connection, reused by queries of the same shape (0 to disable; postgres and CRDB drivers only)")
+	flagSet.DurationVar(&opts.IndexPlannerRefreshInterval, flagName("datastore-index-planner-refresh-interval"), defaults.IndexPlannerRefreshInterval, "interval at which the index statistics of the relationships table are reloaded to choose between the subject and resource indexes for reverse queries (0 to disable; CRDB and MySQL drivers only)")
+	flagSet.IntVar(&opts.QueryShapeCacheCapacity, flagName("datastore-query-shape-cache-capacity"), defaults.QueryShapeCacheCapacity, "maximum number of query shapes whose generated SQL is cached, so relationship queries differing only in their values are not rebuilt (0 to disable; SQL and Spanner drivers only)")
 
 	flagSet.BoolVar(&opts.RelationshipIntegrityEnabled, flagName("datastore-relationship-integrity-enabled"), false, "enables relationship integrity checks. only supported on CRDB")
 	flagSet.StringVar(&opts.RelationshipIntegrityCurrentKey.KeyID, flagName("datastore-relationship-integrity-current-key-id"), "", "current key id for relationship integrity checks")
 	flagSet.StringVar(&opts.RelationshipIntegrityCurrentKey.KeyFilename, flagName("datastore-relationship-integrity-current-key-filename"), "", "current key filename for relationship integrity checks")
 	flagSet.StringArrayVar(&opts.RelationshipIntegrityExpiredKeys, flagName("datastore-relationship-integrity-expired-keys"), []string{}, "config for expired keys for relationship integrity checks")
+	flagSet.BoolVar(&opts.CaveatContextEncryptionEnabled, flagName("datastore-caveat-context-encryption-enabled"), false, "enables encryption of the caveat contexts of relationships at rest, with AES-256-GCM")
+	flagSet.StringVar(&opts.CaveatContextEncryptionCurrentKeyID, flagName("datastore-caveat-context-encryption-current-key-id"), "", "id of the key with which caveat contexts are encrypted")
+	flagSet.StringVar(&opts.CaveatContextEncryptionCurrentKeyFilename, flagName("datastore-caveat-context-encryption-current-key-filename"), "", "filename of the 32 byte key with which caveat contexts are encrypted")
+	flagSet.StringArrayVar(&opts.CaveatContextEncryptionPreviousKeys, flagName("datastore-caveat-context-encryption-previous-keys"), []string{}, `keys with which existing caveat contexts were encrypted before the current key was rotated in, as {"key_id": ..., "key_filename": ...}`)
+	flagSet.StringVar(&opts.RelationshipIntegrityMode, flagName("datastore-relationship-integrity-mode"), "reject", `how relationships failing integrity checks, such as those written directly to the database, are handled: "reject" fails the request, and "flag" logs and counts them but still returns them`)
+	flagSet.StringSliceVar(&opts.WriteThrottlingLimits, flagName("datastore-write-throttling-limits"), []string{}, "maximum rates at which relationships of specific resource object types can be written, as `objecttype=rate[:burst]` in relationships per second, e.g. `usergroup=100:500`; the burst defaults to the rate")
+	flagSet.DurationVar(&opts.WriteThrottlingMaxWait, flagName("datastore-write-throttling-max-wait"), 1*time.Second, "how long writes exceeding the write throttling limit of their object type are delayed, before their transaction is opened, before being rejected with ResourceExhausted; bulk imports are rejected without being delayed")
 
 	// disabling stats is only for tests
 	flagSet.BoolVar(&opts.DisableStats, flagName("datastore-disable-stats"), false, "disable recording relationship counts to the stats table")
@@ -287,6 +357,7 @@ func DefaultDatastoreConfig() *Config {
 		LegacyFuzzing:                            -1,
 		RevisionQuantization:                     5 * time.Second,
 		MaxRevisionStalenessPercent:              .1, // 10%
+		RevisionQuantizationJitter:               0,
 		ReadConnPool:                             *DefaultReadConnPool(),
 		WriteConnPool:                            *DefaultWriteConnPool(),
 		ReadReplicaConnPool:                      *DefaultReadConnPool(),
@@ -299,6 +370,10 @@ func DefaultDatastoreConfig() *Config {
 		EnableConnectionBalancing:                true,
 		GCInterval:                               3 * time.Minute,
 		GCMaxOperationTime:                       1 * time.Minute,
+		GCLeaseDuration:                          30 * time.Second,
+		WatchLogicalReplication:                  false,
+		LowPriorityReadConnsMaxOpen:              0,
+		LowPriorityStatementTimeout:              0,
 		WatchBufferLength:                        1024,
 		WatchBufferWriteTimeout:                  1 * time.Second,
 		WatchConnectTimeout:                      1 * time.Second,
@@ -311,6 +386,10 @@ func DefaultDatastoreConfig() *Config {
 		RequestHedgingInitialSlowValue:           10000000,
 		RequestHedgingMaxRequests:                1_000_000,
 		RequestHedgingQuantile:                   0.95,
+		FaultInjectionEnabled:                    false,
+		ReadOnlyDegradationEnabled:               false,
+		ReadOnlyDegradationProbeInterval:         5 * time.Second,
+		HeadRevisionMaxStaleness:                 0,
 		SpannerCredentialsFile:                   "",
 		SpannerEmulatorHost:                      "",
 		TablePrefix:                              "",
@@ -318,13 +397,28 @@ func DefaultDatastoreConfig() *Config {
 		FollowerReadDelay:                        4_800 * time.Millisecond,
 		SpannerMinSessions:                       100,
 		SpannerMaxSessions:                       400,
+		SpannerDirectedReadLocation:              "",
+		SpannerDirectedReadReplicaType:           "",
+		SpannerWatchEmitTransactionMetadata:      false,
+		SpannerMaxMutationsPerCommit:             75_000,
+		SpannerRelaxedWriteAtomicity:             false,
 		FilterMaximumIDCount:                     100,
 		RelationshipIntegrityEnabled:             false,
 		RelationshipIntegrityCurrentKey:          RelIntegrityKey{},
 		RelationshipIntegrityExpiredKeys:         []string{},
+		RelationshipIntegrityMode:                "reject",
+		CaveatContextEncryptionEnabled:           false,
+		CaveatContextEncryptionPreviousKeys:      []string{},
+		WriteThrottlingLimits:                    []string{},
+		WriteThrottlingMaxWait:                   1 * time.Second,
 		AllowedMigrations:                        []string{},
 		ExperimentalColumnOptimization:           false,
 		IncludeQueryParametersInTraces:           false,
+		SlowQueryThreshold:                       0,
+		ConnMaxErrors:                            0,
+		PreparedStatementCacheCapacity:           0,
+		IndexPlannerRefreshInterval:              5 * time.Minute,
+		QueryShapeCacheCapacity:                  1024,
 		EnableExperimentalRelationshipExpiration: false,
 	}
 }
@@ -394,6 +488,12 @@ func NewDatastore(ctx context.Context, options ...ConfigOption) (datastore.Datas
 		log.Ctx(ctx).Info().Strs("files", opts.BootstrapFiles).Msg("completed datastore initialization from bootstrap files")
 	}
 
+	// Faults are injected beneath the hedging proxy, so that injected latency can be hedged.
+	if opts.FaultInjectionEnabled {
+		log.Ctx(ctx).Warn().Msg("enabling datastore fault injection; this should never be enabled in production")
+		ds = proxy.NewFaultInjectionProxy(ds, proxy.NewFaultInjector())
+	}
+
 	if opts.RequestHedgingEnabled {
 		log.Ctx(ctx).Info().
 			Stringer("initialSlowRequest", opts.RequestHedgingInitialSlowValue).
@@ -413,11 +513,73 @@ func NewDatastore(ctx context.Context, options ...ConfigOption) (datastore.Datas
 		ds = hds
 	}
 
+	if opts.ReadOnlyDegradationEnabled {
+		log.Ctx(ctx).Info().
+			Stringer("probeInterval", opts.ReadOnlyDegradationProbeInterval).
+			Msg("read-only datastore degradation enabled")
+		ds = proxy.NewWriteDegradationProxy(ds, opts.ReadOnlyDegradationProbeInterval)
+	}
+
+	if opts.HeadRevisionMaxStaleness > 0 {
+		log.Ctx(ctx).Info().
+			Stringer("maxStaleness", opts.HeadRevisionMaxStaleness).
+			Msg("head revision polling enabled")
+		ds = proxy.NewHeadRevisionPollingProxy(ds, opts.HeadRevisionMaxStaleness)
+	}
+
 	if opts.ReadOnly {
 		log.Ctx(ctx).Warn().Msg("setting the datastore to read-only")
 		ds = proxy.NewReadonlyDatastore(ds)
 	}
 
+	if len(opts.WriteThrottlingLimits) > 0 {
+		limits := make([]proxy.WriteRateLimit, 0, len(opts.WriteThrottlingLimits))
+		for _, value := range opts.WriteThrottlingLimits {
+			limit, err := proxy.ParseWriteRateLimit(value)
+			if err != nil {
+				return nil, err
+			}
+			limits = append(limits, limit)
+		}
+
+		log.Ctx(ctx).Info().
+			Strs("limits", opts.WriteThrottlingLimits).
+			Stringer("maxWait", opts.WriteThrottlingMaxWait).
+			Msg("write throttling enabled")
+
+		tds, err := proxy.NewWriteThrottlingProxy(ds, limits, opts.WriteThrottlingMaxWait)
+		if err != nil {
+			return nil, fmt.Errorf("error in configuring write throttling: %w", err)
+		}
+		ds = tds
+	}
+
+	// Caveat contexts are encrypted beneath the relationship integrity proxy, so that integrity
+	// is computed over the plaintext contexts.
+	if opts.CaveatContextEncryptionEnabled {
+		log.Ctx(ctx).Info().Msg("enabling caveat context encryption")
+
+		keyBytes, err := os.ReadFile(opts.CaveatContextEncryptionCurrentKeyFilename)
+		if err != nil {
+			return nil, fmt.Errorf("error in opening current caveat context encryption key file: %w", err)
+		}
+
+		previousKeys, err := readPreviousEncryptionKeys(opts.CaveatContextEncryptionPreviousKeys)
+		if err != nil {
+			return nil, fmt.Errorf("error in reading previous caveat context encryption keys: %w", err)
+		}
+
+		keys, err := proxy.NewStaticEncryptionKeyProvider(proxy.EncryptionKey{
+			ID:    opts.CaveatContextEncryptionCurrentKeyID,
+			Bytes: keyBytes,
+		}, previousKeys)
+		if err != nil {
+			return nil, fmt.Errorf("error in configuring caveat context encryption: %w", err)
+		}
+
+		ds = proxy.NewCaveatContextEncryptionProxy(ds, keys)
+	}
+
 	if opts.RelationshipIntegrityEnabled {
 		log.Ctx(ctx).Info().Msg("enabling relationship integrity checks")
 
@@ -436,7 +598,12 @@ func NewDatastore(ctx context.Context, options ...ConfigOption) (datastore.Datas
 			return nil, fmt.Errorf("error in reading expired keys: %w", err)
 		}
 
-		wrapped, err := proxy.NewRelationshipIntegrityProxy(ds, currentKey, expiredKeys)
+		violationMode, err := proxy.ParseIntegrityViolationMode(opts.RelationshipIntegrityMode)
+		if err != nil {
+			return nil, err
+		}
+
+		wrapped, err := proxy.NewRelationshipIntegrityProxy(ds, currentKey, expiredKeys, violationMode)
 		if err != nil {
 			return nil, fmt.Errorf("error in configuring relationship integrity checks: %w", err)
 		}
@@ -479,6 +646,30 @@ func readExpiredKeys(expiredKeyStrings []string) ([]proxy.KeyConfig, error) {
 	return expiredKeys, nil
 }
 
+type encryptionKeyStruct struct {
+	KeyID       string `json:"key_id"`
+	KeyFilename string `json:"key_filename"`
+}
+
+func readPreviousEncryptionKeys(keyStrings []string) ([]proxy.EncryptionKey, error) {
+	keys := make([]proxy.EncryptionKey, 0, len(keyStrings))
+	for index, keyString := range keyStrings {
+		key := encryptionKeyStruct{}
+		if err := json.Unmarshal([]byte(keyString), &key); err != nil {
+			return nil, fmt.Errorf("error in unmarshalling previous key #%d: %w", index+1, err)
+		}
+
+		keyBytes, err := os.ReadFile(key.KeyFilename)
+		if err != nil {
+			return nil, fmt.Errorf("error in opening previous key file: %w", err)
+		}
+
+		keys = append(keys, proxy.EncryptionKey{ID: key.KeyID, Bytes: keyBytes})
+	}
+
+	return keys, nil
+}
+
 func newCRDBDatastore(ctx context.Context, opts Config) (datastore.Datastore, error) {
 	if len(opts.ReadReplicaURIs) > 0 {
 		return nil, errors.New("read replicas are not supported for the CockroachDB datastore engine")
@@ -495,6 +686,7 @@ func newCRDBDatastore(ctx context.Context, opts Config) (datastore.Datastore, er
 		crdb.GCWindow(opts.GCWindow),
 		crdb.RevisionQuantization(opts.RevisionQuantization),
 		crdb.MaxRevisionStalenessPercent(opts.MaxRevisionStalenessPercent),
+		crdb.RevisionQuantizationJitter(opts.RevisionQuantizationJitter),
 		crdb.ReadConnsMaxOpen(opts.ReadConnPool.MaxOpenConns),
 		crdb.ReadConnsMinOpen(opts.ReadConnPool.MinOpenConns),
 		crdb.ReadConnMaxIdleTime(opts.ReadConnPool.MaxIdleTime),
@@ -518,6 +710,11 @@ func newCRDBDatastore(ctx context.Context, opts Config) (datastore.Datastore, er
 		crdb.WithEnableConnectionBalancing(opts.EnableConnectionBalancing),
 		crdb.ConnectRate(opts.ConnectRate),
 		crdb.FilterMaximumIDCount(opts.FilterMaximumIDCount),
+		crdb.SlowQueryThreshold(opts.SlowQueryThreshold),
+		crdb.QueryShapeCacheCapacity(opts.QueryShapeCacheCapacity),
+		crdb.IndexPlannerRefreshInterval(opts.IndexPlannerRefreshInterval),
+		crdb.ConnMaxErrors(opts.ConnMaxErrors),
+		crdb.PreparedStatementCacheCapacity(opts.PreparedStatementCacheCapacity),
 		crdb.WithIntegrity(opts.RelationshipIntegrityEnabled),
 		crdb.AllowedMigrations(opts.AllowedMigrations),
 		crdb.WithColumnOptimization(opts.ExperimentalColumnOptimization),
@@ -563,9 +760,15 @@ func commonPostgresDatastoreOptions(opts Config) ([]postgres.Option, error) {
 		postgres.WithEnablePrometheusStats(opts.EnableDatastoreMetrics),
 		postgres.MaxRetries(maxRetries),
 		postgres.FilterMaximumIDCount(opts.FilterMaximumIDCount),
+		postgres.SlowQueryThreshold(opts.SlowQueryThreshold),
+		postgres.QueryShapeCacheCapacity(opts.QueryShapeCacheCapacity),
+		postgres.ConnMaxErrors(opts.ConnMaxErrors),
+		postgres.PreparedStatementCacheCapacity(opts.PreparedStatementCacheCapacity),
 		postgres.WithColumnOptimization(opts.ExperimentalColumnOptimization),
 		postgres.IncludeQueryParametersInTraces(opts.IncludeQueryParametersInTraces),
 		postgres.WithExpirationDisabled(!opts.EnableExperimentalRelationshipExpiration),
+		postgres.LowPriorityReadConnsMaxOpen(opts.LowPriorityReadConnsMaxOpen),
+		postgres.LowPriorityStatementTimeout(opts.LowPriorityStatementTimeout),
 	}, nil
 }
 
@@ -596,6 +799,7 @@ func newPostgresPrimaryDatastore(ctx context.Context, opts Config) (datastore.Da
 		postgres.GCEnabled(!opts.ReadOnly),
 		postgres.RevisionQuantization(opts.RevisionQuantization),
 		postgres.MaxRevisionStalenessPercent(opts.MaxRevisionStalenessPercent),
+		postgres.RevisionQuantizationJitter(opts.RevisionQuantizationJitter),
 		postgres.ReadConnsMaxOpen(opts.ReadConnPool.MaxOpenConns),
 		postgres.ReadConnsMinOpen(opts.ReadConnPool.MinOpenConns),
 		postgres.ReadConnMaxIdleTime(opts.ReadConnPool.MaxIdleTime),
@@ -610,8 +814,12 @@ func newPostgresPrimaryDatastore(ctx context.Context, opts Config) (datastore.Da
 		postgres.WriteConnHealthCheckInterval(opts.WriteConnPool.HealthCheckInterval),
 		postgres.GCInterval(opts.GCInterval),
 		postgres.GCMaxOperationTime(opts.GCMaxOperationTime),
+		postgres.RelationshipsGCRetention(opts.RelationshipsGCRetention),
+		postgres.NamespacesGCRetention(opts.NamespacesGCRetention),
+		postgres.GCLeaseDuration(opts.GCLeaseDuration),
 		postgres.WatchBufferLength(opts.WatchBufferLength),
 		postgres.WatchBufferWriteTimeout(opts.WatchBufferWriteTimeout),
+		postgres.WatchLogicalReplication(opts.WatchLogicalReplication),
 		postgres.MigrationPhase(opts.MigrationPhase),
 		postgres.AllowedMigrations(opts.AllowedMigrations),
 	}
@@ -635,6 +843,7 @@ func newSpannerDatastore(ctx context.Context, opts Config) (datastore.Datastore,
 		spanner.FollowerReadDelay(opts.FollowerReadDelay),
 		spanner.RevisionQuantization(opts.RevisionQuantization),
 		spanner.MaxRevisionStalenessPercent(opts.MaxRevisionStalenessPercent),
+		spanner.RevisionQuantizationJitter(opts.RevisionQuantizationJitter),
 		spanner.CredentialsFile(opts.SpannerCredentialsFile),
 		spanner.CredentialsJSON(opts.SpannerCredentialsJSON),
 		spanner.WatchBufferLength(opts.WatchBufferLength),
@@ -645,9 +854,16 @@ func newSpannerDatastore(ctx context.Context, opts Config) (datastore.Datastore,
 		spanner.WriteConnsMaxOpen(opts.WriteConnPool.MaxOpenConns),
 		spanner.MinSessionCount(opts.SpannerMinSessions),
 		spanner.MaxSessionCount(opts.SpannerMaxSessions),
+		spanner.DirectedReadLocation(opts.SpannerDirectedReadLocation),
+		spanner.DirectedReadReplicaType(opts.SpannerDirectedReadReplicaType),
+		spanner.WatchEmitTransactionMetadata(opts.SpannerWatchEmitTransactionMetadata),
+		spanner.MaxMutationsPerCommit(opts.SpannerMaxMutationsPerCommit),
+		spanner.RelaxedWriteAtomicity(opts.SpannerRelaxedWriteAtomicity),
 		spanner.MigrationPhase(opts.MigrationPhase),
 		spanner.AllowedMigrations(opts.AllowedMigrations),
 		spanner.FilterMaximumIDCount(opts.FilterMaximumIDCount),
+		spanner.SlowQueryThreshold(opts.SlowQueryThreshold),
+		spanner.QueryShapeCacheCapacity(opts.QueryShapeCacheCapacity),
 		spanner.WithColumnOptimization(opts.ExperimentalColumnOptimization),
 		spanner.WithExpirationDisabled(!opts.EnableExperimentalRelationshipExpiration),
 	)
@@ -691,8 +907,13 @@ func commonMySQLDatastoreOptions(opts Config) ([]mysql.Option, error) {
 		mysql.OverrideLockWaitTimeout(1),
 		mysql.WithEnablePrometheusStats(opts.EnableDatastoreMetrics),
 		mysql.MaxRevisionStalenessPercent(opts.MaxRevisionStalenessPercent),
+		mysql.RevisionQuantizationJitter(opts.RevisionQuantizationJitter),
 		mysql.RevisionQuantization(opts.RevisionQuantization),
 		mysql.FilterMaximumIDCount(opts.FilterMaximumIDCount),
+		mysql.SlowQueryThreshold(opts.SlowQueryThreshold),
+		mysql.QueryShapeCacheCapacity(opts.QueryShapeCacheCapacity),
+		mysql.IndexPlannerRefreshInterval(opts.IndexPlannerRefreshInterval),
+		mysql.ConnMaxErrors(opts.ConnMaxErrors),
 		mysql.AllowedMigrations(opts.AllowedMigrations),
 		mysql.WithColumnOptimization(opts.ExperimentalColumnOptimization),
 		mysql.WithExpirationDisabled(!opts.EnableExperimentalRelationshipExpiration),
@@ -724,6 +945,9 @@ func newMySQLPrimaryDatastore(ctx context.Context, opts Config) (datastore.Datas
 		mysql.GCInterval(opts.GCInterval),
 		mysql.GCEnabled(!opts.ReadOnly),
 		mysql.GCMaxOperationTime(opts.GCMaxOperationTime),
+		mysql.RelationshipsGCRetention(opts.RelationshipsGCRetention),
+		mysql.NamespacesGCRetention(opts.NamespacesGCRetention),
+		mysql.GCLeaseDuration(opts.GCLeaseDuration),
 		mysql.MaxOpenConns(opts.ReadConnPool.MaxOpenConns),
 		mysql.ConnMaxIdleTime(opts.ReadConnPool.MaxIdleTime),
 		mysql.ConnMaxLifetime(opts.ReadConnPool.MaxLifetime),
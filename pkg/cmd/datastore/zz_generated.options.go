@@ -37,6 +37,7 @@ func (c *Config) ToOption() ConfigOption {
 		to.LegacyFuzzing = c.LegacyFuzzing
 		to.RevisionQuantization = c.RevisionQuantization
 		to.MaxRevisionStalenessPercent = c.MaxRevisionStalenessPercent
+		to.RevisionQuantizationJitter = c.RevisionQuantizationJitter
 		to.CredentialsProviderName = c.CredentialsProviderName
 		to.FilterMaximumIDCount = c.FilterMaximumIDCount
 		to.ReadConnPool = c.ReadConnPool
@@ -45,6 +46,11 @@ func (c *Config) ToOption() ConfigOption {
 		to.EnableDatastoreMetrics = c.EnableDatastoreMetrics
 		to.DisableStats = c.DisableStats
 		to.IncludeQueryParametersInTraces = c.IncludeQueryParametersInTraces
+		to.SlowQueryThreshold = c.SlowQueryThreshold
+		to.ConnMaxErrors = c.ConnMaxErrors
+		to.PreparedStatementCacheCapacity = c.PreparedStatementCacheCapacity
+		to.IndexPlannerRefreshInterval = c.IndexPlannerRefreshInterval
+		to.QueryShapeCacheCapacity = c.QueryShapeCacheCapacity
 		to.ReadReplicaConnPool = c.ReadReplicaConnPool
 		to.ReadReplicaURIs = c.ReadReplicaURIs
 		to.ReadReplicaCredentialsProviderName = c.ReadReplicaCredentialsProviderName
@@ -56,6 +62,10 @@ func (c *Config) ToOption() ConfigOption {
 		to.RequestHedgingInitialSlowValue = c.RequestHedgingInitialSlowValue
 		to.RequestHedgingMaxRequests = c.RequestHedgingMaxRequests
 		to.RequestHedgingQuantile = c.RequestHedgingQuantile
+		to.FaultInjectionEnabled = c.FaultInjectionEnabled
+		to.ReadOnlyDegradationEnabled = c.ReadOnlyDegradationEnabled
+		to.ReadOnlyDegradationProbeInterval = c.ReadOnlyDegradationProbeInterval
+		to.HeadRevisionMaxStaleness = c.HeadRevisionMaxStaleness
 		to.FollowerReadDelay = c.FollowerReadDelay
 		to.MaxRetries = c.MaxRetries
 		to.OverlapKey = c.OverlapKey
@@ -64,15 +74,33 @@ func (c *Config) ToOption() ConfigOption {
 		to.ConnectRate = c.ConnectRate
 		to.GCInterval = c.GCInterval
 		to.GCMaxOperationTime = c.GCMaxOperationTime
+		to.RelationshipsGCRetention = c.RelationshipsGCRetention
+		to.NamespacesGCRetention = c.NamespacesGCRetention
+		to.GCLeaseDuration = c.GCLeaseDuration
+		to.WatchLogicalReplication = c.WatchLogicalReplication
+		to.LowPriorityReadConnsMaxOpen = c.LowPriorityReadConnsMaxOpen
+		to.LowPriorityStatementTimeout = c.LowPriorityStatementTimeout
 		to.SpannerCredentialsFile = c.SpannerCredentialsFile
 		to.SpannerCredentialsJSON = c.SpannerCredentialsJSON
 		to.SpannerEmulatorHost = c.SpannerEmulatorHost
 		to.SpannerMinSessions = c.SpannerMinSessions
 		to.SpannerMaxSessions = c.SpannerMaxSessions
+		to.SpannerDirectedReadLocation = c.SpannerDirectedReadLocation
+		to.SpannerDirectedReadReplicaType = c.SpannerDirectedReadReplicaType
+		to.SpannerWatchEmitTransactionMetadata = c.SpannerWatchEmitTransactionMetadata
+		to.SpannerMaxMutationsPerCommit = c.SpannerMaxMutationsPerCommit
+		to.SpannerRelaxedWriteAtomicity = c.SpannerRelaxedWriteAtomicity
 		to.TablePrefix = c.TablePrefix
 		to.RelationshipIntegrityEnabled = c.RelationshipIntegrityEnabled
 		to.RelationshipIntegrityCurrentKey = c.RelationshipIntegrityCurrentKey
 		to.RelationshipIntegrityExpiredKeys = c.RelationshipIntegrityExpiredKeys
+		to.RelationshipIntegrityMode = c.RelationshipIntegrityMode
+		to.CaveatContextEncryptionEnabled = c.CaveatContextEncryptionEnabled
+		to.CaveatContextEncryptionCurrentKeyID = c.CaveatContextEncryptionCurrentKeyID
+		to.CaveatContextEncryptionCurrentKeyFilename = c.CaveatContextEncryptionCurrentKeyFilename
+		to.CaveatContextEncryptionPreviousKeys = c.CaveatContextEncryptionPreviousKeys
+		to.WriteThrottlingLimits = c.WriteThrottlingLimits
+		to.WriteThrottlingMaxWait = c.WriteThrottlingMaxWait
 		to.WatchBufferLength = c.WatchBufferLength
 		to.WatchBufferWriteTimeout = c.WatchBufferWriteTimeout
 		to.WatchConnectTimeout = c.WatchConnectTimeout
@@ -92,6 +120,7 @@ func (c Config) DebugMap() map[string]any {
 	debugMap["LegacyFuzzing"] = helpers.DebugValue(c.LegacyFuzzing, false)
 	debugMap["RevisionQuantization"] = helpers.DebugValue(c.RevisionQuantization, false)
 	debugMap["MaxRevisionStalenessPercent"] = helpers.DebugValue(c.MaxRevisionStalenessPercent, false)
+	debugMap["RevisionQuantizationJitter"] = helpers.DebugValue(c.RevisionQuantizationJitter, false)
 	debugMap["CredentialsProviderName"] = helpers.DebugValue(c.CredentialsProviderName, false)
 	debugMap["ReadConnPool"] = helpers.DebugValue(c.ReadConnPool, false)
 	debugMap["WriteConnPool"] = helpers.DebugValue(c.WriteConnPool, false)
@@ -99,6 +128,11 @@ func (c Config) DebugMap() map[string]any {
 	debugMap["EnableDatastoreMetrics"] = helpers.DebugValue(c.EnableDatastoreMetrics, false)
 	debugMap["DisableStats"] = helpers.DebugValue(c.DisableStats, false)
 	debugMap["IncludeQueryParametersInTraces"] = helpers.DebugValue(c.IncludeQueryParametersInTraces, false)
+	debugMap["SlowQueryThreshold"] = helpers.DebugValue(c.SlowQueryThreshold, false)
+	debugMap["ConnMaxErrors"] = helpers.DebugValue(c.ConnMaxErrors, false)
+	debugMap["PreparedStatementCacheCapacity"] = helpers.DebugValue(c.PreparedStatementCacheCapacity, false)
+	debugMap["IndexPlannerRefreshInterval"] = helpers.DebugValue(c.IndexPlannerRefreshInterval, false)
+	debugMap["QueryShapeCacheCapacity"] = helpers.DebugValue(c.QueryShapeCacheCapacity, false)
 	debugMap["ReadReplicaConnPool"] = helpers.DebugValue(c.ReadReplicaConnPool, false)
 	debugMap["ReadReplicaURIs"] = helpers.SensitiveDebugValue(c.ReadReplicaURIs)
 	debugMap["ReadReplicaCredentialsProviderName"] = helpers.DebugValue(c.ReadReplicaCredentialsProviderName, false)
@@ -110,6 +144,10 @@ func (c Config) DebugMap() map[string]any {
 	debugMap["RequestHedgingInitialSlowValue"] = helpers.DebugValue(c.RequestHedgingInitialSlowValue, false)
 	debugMap["RequestHedgingMaxRequests"] = helpers.DebugValue(c.RequestHedgingMaxRequests, false)
 	debugMap["RequestHedgingQuantile"] = helpers.DebugValue(c.RequestHedgingQuantile, false)
+	debugMap["FaultInjectionEnabled"] = helpers.DebugValue(c.FaultInjectionEnabled, false)
+	debugMap["ReadOnlyDegradationEnabled"] = helpers.DebugValue(c.ReadOnlyDegradationEnabled, false)
+	debugMap["ReadOnlyDegradationProbeInterval"] = helpers.DebugValue(c.ReadOnlyDegradationProbeInterval, false)
+	debugMap["HeadRevisionMaxStaleness"] = helpers.DebugValue(c.HeadRevisionMaxStaleness, false)
 	debugMap["FollowerReadDelay"] = helpers.DebugValue(c.FollowerReadDelay, false)
 	debugMap["MaxRetries"] = helpers.DebugValue(c.MaxRetries, false)
 	debugMap["OverlapKey"] = helpers.DebugValue(c.OverlapKey, false)
@@ -118,15 +156,33 @@ func (c Config) DebugMap() map[string]any {
 	debugMap["ConnectRate"] = helpers.DebugValue(c.ConnectRate, false)
 	debugMap["GCInterval"] = helpers.DebugValue(c.GCInterval, false)
 	debugMap["GCMaxOperationTime"] = helpers.DebugValue(c.GCMaxOperationTime, false)
+	debugMap["RelationshipsGCRetention"] = helpers.DebugValue(c.RelationshipsGCRetention, false)
+	debugMap["NamespacesGCRetention"] = helpers.DebugValue(c.NamespacesGCRetention, false)
+	debugMap["GCLeaseDuration"] = helpers.DebugValue(c.GCLeaseDuration, false)
+	debugMap["WatchLogicalReplication"] = helpers.DebugValue(c.WatchLogicalReplication, false)
+	debugMap["LowPriorityReadConnsMaxOpen"] = helpers.DebugValue(c.LowPriorityReadConnsMaxOpen, false)
+	debugMap["LowPriorityStatementTimeout"] = helpers.DebugValue(c.LowPriorityStatementTimeout, false)
 	debugMap["SpannerCredentialsFile"] = helpers.DebugValue(c.SpannerCredentialsFile, false)
 	debugMap["SpannerCredentialsJSON"] = helpers.SensitiveDebugValue(c.SpannerCredentialsJSON)
 	debugMap["SpannerEmulatorHost"] = helpers.DebugValue(c.SpannerEmulatorHost, false)
 	debugMap["SpannerMinSessions"] = helpers.DebugValue(c.SpannerMinSessions, false)
 	debugMap["SpannerMaxSessions"] = helpers.DebugValue(c.SpannerMaxSessions, false)
+	debugMap["SpannerDirectedReadLocation"] = helpers.DebugValue(c.SpannerDirectedReadLocation, false)
+	debugMap["SpannerDirectedReadReplicaType"] = helpers.DebugValue(c.SpannerDirectedReadReplicaType, false)
+	debugMap["SpannerWatchEmitTransactionMetadata"] = helpers.DebugValue(c.SpannerWatchEmitTransactionMetadata, false)
+	debugMap["SpannerMaxMutationsPerCommit"] = helpers.DebugValue(c.SpannerMaxMutationsPerCommit, false)
+	debugMap["SpannerRelaxedWriteAtomicity"] = helpers.DebugValue(c.SpannerRelaxedWriteAtomicity, false)
 	debugMap["TablePrefix"] = helpers.DebugValue(c.TablePrefix, false)
 	debugMap["RelationshipIntegrityEnabled"] = helpers.DebugValue(c.RelationshipIntegrityEnabled, false)
 	debugMap["RelationshipIntegrityCurrentKey"] = helpers.DebugValue(c.RelationshipIntegrityCurrentKey, false)
 	debugMap["RelationshipIntegrityExpiredKeys"] = helpers.DebugValue(c.RelationshipIntegrityExpiredKeys, false)
+	debugMap["RelationshipIntegrityMode"] = helpers.DebugValue(c.RelationshipIntegrityMode, false)
+	debugMap["CaveatContextEncryptionEnabled"] = helpers.DebugValue(c.CaveatContextEncryptionEnabled, false)
+	debugMap["CaveatContextEncryptionCurrentKeyID"] = helpers.DebugValue(c.CaveatContextEncryptionCurrentKeyID, false)
+	debugMap["CaveatContextEncryptionCurrentKeyFilename"] = helpers.DebugValue(c.CaveatContextEncryptionCurrentKeyFilename, false)
+	debugMap["CaveatContextEncryptionPreviousKeys"] = helpers.DebugValue(c.CaveatContextEncryptionPreviousKeys, false)
+	debugMap["WriteThrottlingLimits"] = helpers.DebugValue(c.WriteThrottlingLimits, false)
+	debugMap["WriteThrottlingMaxWait"] = helpers.DebugValue(c.WriteThrottlingMaxWait, false)
 	debugMap["WatchBufferLength"] = helpers.DebugValue(c.WatchBufferLength, false)
 	debugMap["WatchBufferWriteTimeout"] = helpers.DebugValue(c.WatchBufferWriteTimeout, false)
 	debugMap["WatchConnectTimeout"] = helpers.DebugValue(c.WatchConnectTimeout, false)
@@ -195,6 +251,13 @@ func WithMaxRevisionStalenessPercent(maxRevisionStalenessPercent float64) Config
 	}
 }
 
+// WithRevisionQuantizationJitter returns an option that can set RevisionQuantizationJitter on a Config
+func WithRevisionQuantizationJitter(revisionQuantizationJitter time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.RevisionQuantizationJitter = revisionQuantizationJitter
+	}
+}
+
 // WithCredentialsProviderName returns an option that can set CredentialsProviderName on a Config
 func WithCredentialsProviderName(credentialsProviderName string) ConfigOption {
 	return func(c *Config) {
@@ -251,6 +314,41 @@ func WithIncludeQueryParametersInTraces(includeQueryParametersInTraces bool) Con
 	}
 }
 
+// WithSlowQueryThreshold returns an option that can set SlowQueryThreshold on a Config
+func WithSlowQueryThreshold(slowQueryThreshold time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.SlowQueryThreshold = slowQueryThreshold
+	}
+}
+
+// WithConnMaxErrors returns an option that can set ConnMaxErrors on a Config
+func WithConnMaxErrors(connMaxErrors int) ConfigOption {
+	return func(c *Config) {
+		c.ConnMaxErrors = connMaxErrors
+	}
+}
+
+// WithPreparedStatementCacheCapacity returns an option that can set PreparedStatementCacheCapacity on a Config
+func WithPreparedStatementCacheCapacity(preparedStatementCacheCapacity int) ConfigOption {
+	return func(c *Config) {
+		c.PreparedStatementCacheCapacity = preparedStatementCacheCapacity
+	}
+}
+
+// WithIndexPlannerRefreshInterval returns an option that can set IndexPlannerRefreshInterval on a Config
+func WithIndexPlannerRefreshInterval(indexPlannerRefreshInterval time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.IndexPlannerRefreshInterval = indexPlannerRefreshInterval
+	}
+}
+
+// WithQueryShapeCacheCapacity returns an option that can set QueryShapeCacheCapacity on a Config
+func WithQueryShapeCacheCapacity(queryShapeCacheCapacity int) ConfigOption {
+	return func(c *Config) {
+		c.QueryShapeCacheCapacity = queryShapeCacheCapacity
+	}
+}
+
 // WithReadReplicaConnPool returns an option that can set ReadReplicaConnPool on a Config
 func WithReadReplicaConnPool(readReplicaConnPool ConnPoolConfig) ConfigOption {
 	return func(c *Config) {
@@ -349,6 +447,34 @@ func WithRequestHedgingQuantile(requestHedgingQuantile float64) ConfigOption {
 	}
 }
 
+// WithFaultInjectionEnabled returns an option that can set FaultInjectionEnabled on a Config
+func WithFaultInjectionEnabled(faultInjectionEnabled bool) ConfigOption {
+	return func(c *Config) {
+		c.FaultInjectionEnabled = faultInjectionEnabled
+	}
+}
+
+// WithReadOnlyDegradationEnabled returns an option that can set ReadOnlyDegradationEnabled on a Config
+func WithReadOnlyDegradationEnabled(readOnlyDegradationEnabled bool) ConfigOption {
+	return func(c *Config) {
+		c.ReadOnlyDegradationEnabled = readOnlyDegradationEnabled
+	}
+}
+
+// WithReadOnlyDegradationProbeInterval returns an option that can set ReadOnlyDegradationProbeInterval on a Config
+func WithReadOnlyDegradationProbeInterval(readOnlyDegradationProbeInterval time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.ReadOnlyDegradationProbeInterval = readOnlyDegradationProbeInterval
+	}
+}
+
+// WithHeadRevisionMaxStaleness returns an option that can set HeadRevisionMaxStaleness on a Config
+func WithHeadRevisionMaxStaleness(headRevisionMaxStaleness time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.HeadRevisionMaxStaleness = headRevisionMaxStaleness
+	}
+}
+
 // WithFollowerReadDelay returns an option that can set FollowerReadDelay on a Config
 func WithFollowerReadDelay(followerReadDelay time.Duration) ConfigOption {
 	return func(c *Config) {
@@ -405,6 +531,48 @@ func WithGCMaxOperationTime(gCMaxOperationTime time.Duration) ConfigOption {
 	}
 }
 
+// WithRelationshipsGCRetention returns an option that can set RelationshipsGCRetention on a Config
+func WithRelationshipsGCRetention(relationshipsGCRetention time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.RelationshipsGCRetention = relationshipsGCRetention
+	}
+}
+
+// WithNamespacesGCRetention returns an option that can set NamespacesGCRetention on a Config
+func WithNamespacesGCRetention(namespacesGCRetention time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.NamespacesGCRetention = namespacesGCRetention
+	}
+}
+
+// WithGCLeaseDuration returns an option that can set GCLeaseDuration on a Config
+func WithGCLeaseDuration(gCLeaseDuration time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.GCLeaseDuration = gCLeaseDuration
+	}
+}
+
+// WithWatchLogicalReplication returns an option that can set WatchLogicalReplication on a Config
+func WithWatchLogicalReplication(watchLogicalReplication bool) ConfigOption {
+	return func(c *Config) {
+		c.WatchLogicalReplication = watchLogicalReplication
+	}
+}
+
+// WithLowPriorityReadConnsMaxOpen returns an option that can set LowPriorityReadConnsMaxOpen on a Config
+func WithLowPriorityReadConnsMaxOpen(lowPriorityReadConnsMaxOpen int) ConfigOption {
+	return func(c *Config) {
+		c.LowPriorityReadConnsMaxOpen = lowPriorityReadConnsMaxOpen
+	}
+}
+
+// WithLowPriorityStatementTimeout returns an option that can set LowPriorityStatementTimeout on a Config
+func WithLowPriorityStatementTimeout(lowPriorityStatementTimeout time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.LowPriorityStatementTimeout = lowPriorityStatementTimeout
+	}
+}
+
 // WithSpannerCredentialsFile returns an option that can set SpannerCredentialsFile on a Config
 func WithSpannerCredentialsFile(spannerCredentialsFile string) ConfigOption {
 	return func(c *Config) {
@@ -447,6 +615,41 @@ func WithSpannerMaxSessions(spannerMaxSessions uint64) ConfigOption {
 	}
 }
 
+// WithSpannerDirectedReadLocation returns an option that can set SpannerDirectedReadLocation on a Config
+func WithSpannerDirectedReadLocation(spannerDirectedReadLocation string) ConfigOption {
+	return func(c *Config) {
+		c.SpannerDirectedReadLocation = spannerDirectedReadLocation
+	}
+}
+
+// WithSpannerDirectedReadReplicaType returns an option that can set SpannerDirectedReadReplicaType on a Config
+func WithSpannerDirectedReadReplicaType(spannerDirectedReadReplicaType string) ConfigOption {
+	return func(c *Config) {
+		c.SpannerDirectedReadReplicaType = spannerDirectedReadReplicaType
+	}
+}
+
+// WithSpannerWatchEmitTransactionMetadata returns an option that can set SpannerWatchEmitTransactionMetadata on a Config
+func WithSpannerWatchEmitTransactionMetadata(spannerWatchEmitTransactionMetadata bool) ConfigOption {
+	return func(c *Config) {
+		c.SpannerWatchEmitTransactionMetadata = spannerWatchEmitTransactionMetadata
+	}
+}
+
+// WithSpannerMaxMutationsPerCommit returns an option that can set SpannerMaxMutationsPerCommit on a Config
+func WithSpannerMaxMutationsPerCommit(spannerMaxMutationsPerCommit int) ConfigOption {
+	return func(c *Config) {
+		c.SpannerMaxMutationsPerCommit = spannerMaxMutationsPerCommit
+	}
+}
+
+// WithSpannerRelaxedWriteAtomicity returns an option that can set SpannerRelaxedWriteAtomicity on a Config
+func WithSpannerRelaxedWriteAtomicity(spannerRelaxedWriteAtomicity bool) ConfigOption {
+	return func(c *Config) {
+		c.SpannerRelaxedWriteAtomicity = spannerRelaxedWriteAtomicity
+	}
+}
+
 // WithTablePrefix returns an option that can set TablePrefix on a Config
 func WithTablePrefix(tablePrefix string) ConfigOption {
 	return func(c *Config) {
@@ -482,6 +685,69 @@ func SetRelationshipIntegrityExpiredKeys(relationshipIntegrityExpiredKeys []stri
 	}
 }
 
+// WithRelationshipIntegrityMode returns an option that can set RelationshipIntegrityMode on a Config
+func WithRelationshipIntegrityMode(relationshipIntegrityMode string) ConfigOption {
+	return func(c *Config) {
+		c.RelationshipIntegrityMode = relationshipIntegrityMode
+	}
+}
+
+// WithCaveatContextEncryptionEnabled returns an option that can set CaveatContextEncryptionEnabled on a Config
+func WithCaveatContextEncryptionEnabled(caveatContextEncryptionEnabled bool) ConfigOption {
+	return func(c *Config) {
+		c.CaveatContextEncryptionEnabled = caveatContextEncryptionEnabled
+	}
+}
+
+// WithCaveatContextEncryptionCurrentKeyID returns an option that can set CaveatContextEncryptionCurrentKeyID on a Config
+func WithCaveatContextEncryptionCurrentKeyID(caveatContextEncryptionCurrentKeyID string) ConfigOption {
+	return func(c *Config) {
+		c.CaveatContextEncryptionCurrentKeyID = caveatContextEncryptionCurrentKeyID
+	}
+}
+
+// WithCaveatContextEncryptionCurrentKeyFilename returns an option that can set CaveatContextEncryptionCurrentKeyFilename on a Config
+func WithCaveatContextEncryptionCurrentKeyFilename(caveatContextEncryptionCurrentKeyFilename string) ConfigOption {
+	return func(c *Config) {
+		c.CaveatContextEncryptionCurrentKeyFilename = caveatContextEncryptionCurrentKeyFilename
+	}
+}
+
+// WithCaveatContextEncryptionPreviousKeys returns an option that can append CaveatContextEncryptionPreviousKeyss to Config.CaveatContextEncryptionPreviousKeys
+func WithCaveatContextEncryptionPreviousKeys(caveatContextEncryptionPreviousKeys string) ConfigOption {
+	return func(c *Config) {
+		c.CaveatContextEncryptionPreviousKeys = append(c.CaveatContextEncryptionPreviousKeys, caveatContextEncryptionPreviousKeys)
+	}
+}
+
+// SetCaveatContextEncryptionPreviousKeys returns an option that can set CaveatContextEncryptionPreviousKeys on a Config
+func SetCaveatContextEncryptionPreviousKeys(caveatContextEncryptionPreviousKeys []string) ConfigOption {
+	return func(c *Config) {
+		c.CaveatContextEncryptionPreviousKeys = caveatContextEncryptionPreviousKeys
+	}
+}
+
+// WithWriteThrottlingLimits returns an option that can append WriteThrottlingLimitss to Config.WriteThrottlingLimits
+func WithWriteThrottlingLimits(writeThrottlingLimits string) ConfigOption {
+	return func(c *Config) {
+		c.WriteThrottlingLimits = append(c.WriteThrottlingLimits, writeThrottlingLimits)
+	}
+}
+
+// SetWriteThrottlingLimits returns an option that can set WriteThrottlingLimits on a Config
+func SetWriteThrottlingLimits(writeThrottlingLimits []string) ConfigOption {
+	return func(c *Config) {
+		c.WriteThrottlingLimits = writeThrottlingLimits
+	}
+}
+
+// WithWriteThrottlingMaxWait returns an option that can set WriteThrottlingMaxWait on a Config
+func WithWriteThrottlingMaxWait(writeThrottlingMaxWait time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.WriteThrottlingMaxWait = writeThrottlingMaxWait
+	}
+}
+
 // WithWatchBufferLength returns an option that can set WatchBufferLength on a Config
 func WithWatchBufferLength(watchBufferLength uint16) ConfigOption {
 	return func(c *Config) {
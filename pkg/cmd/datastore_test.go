@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/services/shared"
+	dspkg "github.com/authzed/spicedb/pkg/datastore"
+)
+
+func TestParseSchemaRename(t *testing.T) {
+	tcs := []struct {
+		existing      string
+		newName       string
+		expected      shared.SchemaRename
+		expectedError string
+	}{
+		{"document", "file", shared.SchemaRename{DefinitionName: "document", NewName: "file"}, ""},
+		{"document#viewer", "reader", shared.SchemaRename{DefinitionName: "document", RelationName: "viewer", NewName: "reader"}, ""},
+		{"document#viewer", "document#reader", shared.SchemaRename{DefinitionName: "document", RelationName: "viewer", NewName: "reader"}, ""},
+		{"document#viewer", "folder#reader", shared.SchemaRename{}, "cannot move relation"},
+		{"document#", "reader", shared.SchemaRename{}, "invalid object definition"},
+		{"document", "file#reader", shared.SchemaRename{}, "invalid new name"},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.existing+" to "+tc.newName, func(t *testing.T) {
+			rename, err := parseSchemaRename(tc.existing, tc.newName)
+			if tc.expectedError != "" {
+				require.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, rename)
+		})
+	}
+}
+
+func TestParseRewriteFilter(t *testing.T) {
+	tcs := []struct {
+		filter        string
+		expected      dspkg.RelationshipsFilter
+		expectedError string
+	}{
+		{"document", dspkg.RelationshipsFilter{OptionalResourceType: "document"}, ""},
+		{"document#viewer", dspkg.RelationshipsFilter{OptionalResourceType: "document", OptionalResourceRelation: "viewer"}, ""},
+		{"document@user", dspkg.RelationshipsFilter{
+			OptionalResourceType:      "document",
+			OptionalSubjectsSelectors: []dspkg.SubjectsSelector{{OptionalSubjectType: "user"}},
+		}, ""},
+		{"document#viewer@group#member", dspkg.RelationshipsFilter{
+			OptionalResourceType:     "document",
+			OptionalResourceRelation: "viewer",
+			OptionalSubjectsSelectors: []dspkg.SubjectsSelector{{
+				OptionalSubjectType: "group",
+				RelationFilter:      dspkg.SubjectRelationFilter{}.WithNonEllipsisRelation("member"),
+			}},
+		}, ""},
+		{"document#viewer@user#...", dspkg.RelationshipsFilter{
+			OptionalResourceType:     "document",
+			OptionalResourceRelation: "viewer",
+			OptionalSubjectsSelectors: []dspkg.SubjectsSelector{{
+				OptionalSubjectType: "user",
+				RelationFilter:      dspkg.SubjectRelationFilter{}.WithEllipsisRelation(),
+			}},
+		}, ""},
+		{"#viewer", dspkg.RelationshipsFilter{}, "invalid relationship filter"},
+		{"document#", dspkg.RelationshipsFilter{}, "invalid relationship filter"},
+		{"document@", dspkg.RelationshipsFilter{}, "invalid relationship filter"},
+		{"document@group#", dspkg.RelationshipsFilter{}, "invalid relationship filter"},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.filter, func(t *testing.T) {
+			filter, err := parseRewriteFilter(tc.filter)
+			if tc.expectedError != "" {
+				require.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, filter)
+		})
+	}
+}
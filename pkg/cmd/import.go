@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"encoding/json"
+
+	"github.com/spf13/cobra"
+
+	"github.com/authzed/spicedb/internal/importer"
+	"github.com/authzed/spicedb/pkg/cmd/server"
+	"github.com/authzed/spicedb/pkg/cmd/termination"
+)
+
+// ImportConfig is the configuration for the import command.
+type ImportConfig struct {
+	ClientConfig
+
+	importer.Config
+}
+
+func RegisterImportFlags(cmd *cobra.Command, config *ImportConfig) {
+	registerClientFlags(cmd, &config.ClientConfig, "import into")
+
+	cmd.Flags().StringVar(&config.Format, "format", importer.FormatCSV, "format of the file to import: csv, whose first row names the columns, or ndjson")
+	cmd.Flags().StringVar(&config.Mapping, "mapping", "", "comma-separated `field=column` pairs mapping the relationship fields (relationship, resource_type, resource_id, relation, subject_type, subject_id, subject_relation, caveat_name, caveat_context, expires_at) to columns, or to constants written `field='value'`; unmapped fields are read from the column of the same name")
+	cmd.Flags().UintVar(&config.BatchSize, "batch-size", 1_000, "number of relationships imported per transaction")
+	cmd.Flags().StringVar(&config.StateFile, "state-file", "", "file recording the progress of the import, from which an interrupted import is resumed")
+}
+
+func NewImportCommand(programName string, config *ImportConfig) *cobra.Command {
+	return &cobra.Command{
+		Use:     "import <file>",
+		Short:   "import relationships from a file",
+		Long:    "Imports the relationships of a CSV or NDJSON file into a SpiceDB instance through the bulk import API, logging progress after each batch. With a state file, an interrupted import resumes after the last batch imported. Writes a JSON summary of the import to stdout.",
+		Args:    cobra.ExactArgs(1),
+		PreRunE: server.DefaultPreRunE(programName),
+		RunE: termination.PublishError(func(cmd *cobra.Command, args []string) error {
+			conn, err := config.dial()
+			if err != nil {
+				return err
+			}
+			defer conn.Close()
+
+			config.File = args[0]
+			signalctx := SignalContextWithGracePeriod(cmd.Context(), 0)
+			summary, err := importer.Run(signalctx, conn, config.Config)
+			if err != nil {
+				return err
+			}
+
+			encoder := json.NewEncoder(cmd.OutOrStdout())
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(summary)
+		}),
+	}
+}
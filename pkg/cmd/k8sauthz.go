@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/authzed/spicedb/internal/k8sauthz"
+	"github.com/authzed/spicedb/pkg/cmd/server"
+	"github.com/authzed/spicedb/pkg/cmd/termination"
+)
+
+// K8sAuthzWebhookConfig is the configuration for the serve-k8s-authz-webhook command.
+type K8sAuthzWebhookConfig struct {
+	ClientConfig
+
+	k8sauthz.Config
+}
+
+func RegisterK8sAuthzWebhookFlags(cmd *cobra.Command, config *K8sAuthzWebhookConfig) {
+	registerClientFlags(cmd, &config.ClientConfig, "check permissions with")
+
+	cmd.Flags().StringVar(&config.Addr, "http-addr", ":8443", "address on which to serve the webhook")
+	cmd.Flags().StringVar(&config.TLSCertPath, "http-tls-cert-path", "", "local path to the TLS certificate with which to serve the webhook over HTTPS, as required by the Kubernetes API server. Required unless --k8s-authz-insecure is set")
+	cmd.Flags().StringVar(&config.TLSKeyPath, "http-tls-key-path", "", "local path to the TLS key with which to serve the webhook over HTTPS")
+	cmd.Flags().StringVar(&config.ClientCAPath, "k8s-authz-client-ca", "", "local path to the CA certificates, or to a directory of them, with which to verify the client certificates of the Kubernetes API server; requests without a valid client certificate are refused")
+	cmd.Flags().BoolVar(&config.AllowInsecure, "k8s-authz-insecure", false, "allow serving the webhook without TLS or without verifying client certificates, letting anyone able to reach it learn the permissions of any user. Only for development")
+	cmd.Flags().StringVar(&config.MappingPath, "mapping", "", "path to the YAML file mapping Kubernetes users, groups, verbs and resources to the object types and permissions of the schema")
+	cmd.Flags().DurationVar(&config.RequestTimeout, "request-timeout", 5*time.Second, "maximum duration of the permission checks of a SubjectAccessReview, after which it is answered with an evaluation error")
+}
+
+func NewK8sAuthzWebhookCommand(programName string, config *K8sAuthzWebhookConfig) *cobra.Command {
+	return &cobra.Command{
+		Use:     "serve-k8s-authz-webhook",
+		Short:   "serve a Kubernetes authorization webhook backed by SpiceDB",
+		Long:    "Serves the Kubernetes SubjectAccessReview webhook protocol, allowing the requests for which the user, or one of its groups, has the permission that the mapping file maps the verb and resource of the request to on a SpiceDB instance. Requests matched by no rule of the mapping are answered with no opinion, deferring to the other authorizers of the API server.",
+		Args:    cobra.NoArgs,
+		PreRunE: server.DefaultPreRunE(programName),
+		RunE: termination.PublishError(func(cmd *cobra.Command, args []string) error {
+			conn, err := config.dial()
+			if err != nil {
+				return err
+			}
+			defer conn.Close()
+
+			signalctx := SignalContextWithGracePeriod(cmd.Context(), 0)
+			return k8sauthz.Run(signalctx, conn, config.Config)
+		}),
+	}
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/authzed/spicedb/internal/ldapsync"
+	"github.com/authzed/spicedb/pkg/cmd/server"
+	"github.com/authzed/spicedb/pkg/cmd/termination"
+)
+
+// LDAPSyncConfig is the configuration for the ldap-sync command.
+type LDAPSyncConfig struct {
+	ClientConfig
+
+	ldapsync.Options
+
+	ConfigPath string
+}
+
+func RegisterLDAPSyncFlags(cmd *cobra.Command, config *LDAPSyncConfig) {
+	registerClientFlags(cmd, &config.ClientConfig, "sync group memberships into")
+
+	cmd.Flags().StringVar(&config.ConfigPath, "config", "", "path to the YAML file configuring the LDAP directory, where its users and groups are searched, and the object types and relation into which memberships are synced")
+	cmd.Flags().DurationVar(&config.Interval, "interval", 5*time.Minute, "duration between syncs. If 0, a single sync is run")
+	cmd.Flags().DurationVar(&config.FullSyncInterval, "full-sync-interval", 24*time.Hour, "duration after which a full sync is run instead of an incremental one, finding deleted entries. If 0, only the first sync is full")
+	cmd.Flags().BoolVar(&config.DryRun, "dry-run", false, "print the relationships each sync would write (+) and delete (-) instead of writing them")
+	cmd.Flags().IntVar(&config.MaxDeletions, "max-deletions", 1000, "abort syncs which would delete more relationships. If 0, the number of deletions is not limited")
+	cmd.Flags().Float64Var(&config.MaxDeletionPercent, "max-deletion-percent", 10, "abort syncs which would delete a larger percentage of the synced relationships. If 0, the percentage of deletions is not limited")
+}
+
+func NewLDAPSyncCommand(programName string, config *LDAPSyncConfig) *cobra.Command {
+	return &cobra.Command{
+		Use:     "ldap-sync",
+		Short:   "sync LDAP group memberships into relationships",
+		Long:    "Syncs the group memberships of an LDAP directory, such as Active Directory, into the member relation of groups on a SpiceDB instance on a schedule: a full sync first, and then incremental syncs of the entries changed since, found by their USNs or in the changelog of the directory. Syncs which would delete more relationships than the deletion protection thresholds are aborted.",
+		Args:    cobra.NoArgs,
+		PreRunE: server.DefaultPreRunE(programName),
+		RunE: termination.PublishError(func(cmd *cobra.Command, args []string) error {
+			ldapConfig, err := ldapsync.LoadConfig(config.ConfigPath)
+			if err != nil {
+				return err
+			}
+
+			conn, err := config.dial()
+			if err != nil {
+				return err
+			}
+			defer conn.Close()
+
+			config.DiffOutput = cmd.OutOrStdout()
+			signalctx := SignalContextWithGracePeriod(cmd.Context(), 0)
+			return ldapsync.Run(signalctx, conn, ldapConfig, config.Options)
+		}),
+	}
+}
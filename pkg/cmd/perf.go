@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/authzed/spicedb/internal/perf"
+	"github.com/authzed/spicedb/pkg/cmd/server"
+	"github.com/authzed/spicedb/pkg/cmd/termination"
+)
+
+// PerfConfig is the configuration for the perf command.
+type PerfConfig struct {
+	ClientConfig
+
+	perf.Config
+}
+
+func RegisterPerfFlags(cmd *cobra.Command, config *PerfConfig) {
+	registerClientFlags(cmd, &config.ClientConfig, "test")
+
+	cmd.Flags().Uint64Var(&config.Dataset.Seed, "seed", 1, "seed for the generated dataset and traffic")
+	cmd.Flags().Uint64Var(&config.Dataset.Users, "users", 10_000, "number of users in the generated dataset")
+	cmd.Flags().Uint64Var(&config.Dataset.Groups, "groups", 500, "number of groups in the generated dataset")
+	cmd.Flags().Uint64Var(&config.Dataset.Folders, "folders", 1_000, "number of folders in the generated dataset")
+	cmd.Flags().Uint64Var(&config.Dataset.Documents, "documents", 10_000, "number of documents in the generated dataset")
+	cmd.Flags().Uint64Var(&config.Dataset.MembersPerGroup, "members-per-group", 20, "number of members of each group in the generated dataset")
+	cmd.Flags().Uint64Var(&config.Dataset.RelationshipsPerDocument, "relationships-per-document", 5, "average number of relationships on each document in the generated dataset")
+	cmd.Flags().Float64Var(&config.Dataset.ZipfExponent, "zipf-exponent", 1.1, "exponent of the Zipfian popularity of users, groups and documents; must be greater than 1")
+	cmd.Flags().BoolVar(&config.SkipLoad, "skip-load", false, "skip loading the schema and dataset, which must have been loaded by a previous run with the same dataset flags")
+
+	cmd.Flags().UintVar(&config.Workers, "workers", 16, "number of concurrent workers sending requests")
+	cmd.Flags().DurationVar(&config.Duration, "duration", 30*time.Second, "duration for which to send traffic")
+	cmd.Flags().UintVar(&config.CheckWeight, "check-weight", 80, "relative weight of CheckPermission requests in the traffic mix")
+	cmd.Flags().UintVar(&config.LookupResourcesWeight, "lookup-resources-weight", 10, "relative weight of LookupResources requests in the traffic mix")
+	cmd.Flags().UintVar(&config.WriteWeight, "write-weight", 10, "relative weight of WriteRelationships requests in the traffic mix")
+	cmd.Flags().BoolVar(&config.FullyConsistent, "fully-consistent", false, "send reads with full consistency instead of minimizing latency")
+}
+
+func NewPerfCommand(programName string, config *PerfConfig) *cobra.Command {
+	return &cobra.Command{
+		Use:     "perf",
+		Short:   "load test a SpiceDB instance",
+		Long:    "Loads a synthetic schema and relationships with Zipfian popularity into a SpiceDB instance, drives a configurable mix of CheckPermission, LookupResources and WriteRelationships traffic against it and writes a JSON report of the latency percentiles of each operation to stdout. Runs with the same flags generate the same dataset and traffic.",
+		Args:    cobra.NoArgs,
+		PreRunE: server.DefaultPreRunE(programName),
+		RunE: termination.PublishError(func(cmd *cobra.Command, args []string) error {
+			conn, err := config.dial()
+			if err != nil {
+				return err
+			}
+			defer conn.Close()
+
+			signalctx := SignalContextWithGracePeriod(cmd.Context(), 0)
+			report, err := perf.Run(signalctx, conn, config.Config)
+			if err != nil {
+				return err
+			}
+
+			encoder := json.NewEncoder(cmd.OutOrStdout())
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(report)
+		}),
+	}
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"encoding/json"
+
+	"github.com/spf13/cobra"
+
+	"github.com/authzed/spicedb/internal/replay"
+	"github.com/authzed/spicedb/pkg/cmd/server"
+	"github.com/authzed/spicedb/pkg/cmd/termination"
+)
+
+// ReplayConfig is the configuration for the replay command.
+type ReplayConfig struct {
+	ClientConfig
+
+	replay.Config
+}
+
+func RegisterReplayFlags(cmd *cobra.Command, config *ReplayConfig) {
+	registerClientFlags(cmd, &config.ClientConfig, "replay against")
+
+	cmd.Flags().UintVar(&config.Workers, "workers", 4, "number of concurrent workers replaying requests")
+	cmd.Flags().BoolVar(&config.AtCapturedRevision, "at-captured-revision", false, "replay each request at the exact revision at which it was captured, which is only possible against the datastore on which it was captured and within its GC window; otherwise requests are replayed with full consistency")
+	cmd.Flags().UintVar(&config.MaxDiffs, "max-diffs", 100, "maximum number of requests with differing results included in the report")
+}
+
+func NewReplayCommand(programName string, config *ReplayConfig) *cobra.Command {
+	return &cobra.Command{
+		Use:     "replay <capture file>",
+		Short:   "replay captured requests against a SpiceDB instance",
+		Long:    "Re-executes the CheckPermission, CheckBulkPermissions, LookupResources and LookupSubjects requests of a file captured with --request-capture-file and --request-capture-disable-hashing against a SpiceDB instance, such as another build or one backed by another datastore, and writes a JSON report comparing their results and latencies with those captured to stdout.",
+		Args:    cobra.ExactArgs(1),
+		PreRunE: server.DefaultPreRunE(programName),
+		RunE: termination.PublishError(func(cmd *cobra.Command, args []string) error {
+			conn, err := config.dial()
+			if err != nil {
+				return err
+			}
+			defer conn.Close()
+
+			config.File = args[0]
+			signalctx := SignalContextWithGracePeriod(cmd.Context(), 0)
+			report, err := replay.Run(signalctx, conn, config.Config)
+			if err != nil {
+				return err
+			}
+
+			encoder := json.NewEncoder(cmd.OutOrStdout())
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(report)
+		}),
+	}
+}
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/go-logr/zerologr"
+	"github.com/jzelinskie/cobrautil/v2"
+	"github.com/jzelinskie/cobrautil/v2/cobrazerolog"
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+
+	"github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/pkg/cmd/termination"
+	"github.com/authzed/spicedb/pkg/schemadsl/generator"
+)
+
+// SchemaFormatConfig is the configuration for the schema format command.
+type SchemaFormatConfig struct {
+	// Check causes the command to fail, without writing the formatted schema, if the schema is not
+	// formatted.
+	Check bool
+
+	// Write causes the command to replace the schema file with the formatted schema, instead of
+	// writing it to stdout.
+	Write bool
+}
+
+// ErrSchemaNotFormatted is returned by the schema format command if Check is set and the schema is
+// not formatted.
+var ErrSchemaNotFormatted = errors.New("schema is not formatted")
+
+func NewSchemaCommand(programName string) *cobra.Command {
+	schemaCmd := &cobra.Command{
+		Use:   "schema",
+		Short: "schema operations",
+		Long:  "Operations on schema files",
+	}
+
+	formatConfig := new(SchemaFormatConfig)
+	formatCmd := NewSchemaFormatCommand(programName, formatConfig)
+	RegisterSchemaFormatFlags(formatCmd, formatConfig)
+	schemaCmd.AddCommand(formatCmd)
+
+	return schemaCmd
+}
+
+func RegisterSchemaFormatFlags(cmd *cobra.Command, config *SchemaFormatConfig) {
+	cmd.Flags().BoolVar(&config.Check, "check", false, "exit with an error if the schema is not formatted, without writing it")
+	cmd.Flags().BoolVarP(&config.Write, "write", "w", false, "replace the schema file with the formatted schema instead of writing it to stdout")
+}
+
+func NewSchemaFormatCommand(programName string, config *SchemaFormatConfig) *cobra.Command {
+	return &cobra.Command{
+		Use:   "format <schema file, or - for stdin>",
+		Short: "format a schema in canonical formatting",
+		Long:  "Formats a schema in canonical formatting, preserving its comments, and writes it to stdout. With --check, nothing is written and the command fails if the schema is not formatted, to enforce formatting in CI.",
+		Args:  cobra.ExactArgs(1),
+		PreRunE: cobrautil.CommandStack(
+			cobrautil.SyncViperDotEnvPreRunE(programName, "spicedb.env", zerologr.New(&logging.Logger)),
+			cobrazerolog.New(
+				cobrazerolog.WithTarget(func(logger zerolog.Logger) {
+					logging.SetGlobalLogger(logger)
+				}),
+			).RunE(),
+		),
+		RunE: termination.PublishError(func(cmd *cobra.Command, args []string) error {
+			return formatSchema(cmd.InOrStdin(), cmd.OutOrStdout(), args[0], config)
+		}),
+	}
+}
+
+func formatSchema(stdin io.Reader, out io.Writer, path string, config *SchemaFormatConfig) error {
+	if config.Check && config.Write {
+		return errors.New("--check and --write cannot be used together")
+	}
+	if config.Write && path == "-" {
+		return errors.New("--write cannot be used with stdin")
+	}
+
+	var schema []byte
+	var err error
+	if path == "-" {
+		schema, err = io.ReadAll(stdin)
+	} else {
+		schema, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read schema: %w", err)
+	}
+
+	formatted, err := generator.FormatSchema(string(schema))
+	if err != nil {
+		return fmt.Errorf("failed to format schema: %w", err)
+	}
+
+	switch {
+	case config.Check:
+		if formatted != string(schema) {
+			return ErrSchemaNotFormatted
+		}
+		return nil
+
+	case config.Write:
+		if formatted == string(schema) {
+			return nil
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(path, []byte(formatted), info.Mode().Perm())
+
+	default:
+		_, err = io.WriteString(out, formatted)
+		return err
+	}
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatSchema(t *testing.T) {
+	const unformatted = "definition user{}  // the user\n"
+	const formatted = "// the user\ndefinition user {}\n"
+
+	var out bytes.Buffer
+	require.NoError(t, formatSchema(strings.NewReader(unformatted), &out, "-", &SchemaFormatConfig{}))
+	require.Equal(t, formatted, out.String())
+
+	err := formatSchema(strings.NewReader(unformatted), &out, "-", &SchemaFormatConfig{Check: true})
+	require.ErrorIs(t, err, ErrSchemaNotFormatted)
+	require.NoError(t, formatSchema(strings.NewReader(formatted), &out, "-", &SchemaFormatConfig{Check: true}))
+
+	path := filepath.Join(t.TempDir(), "schema.zed")
+	require.NoError(t, os.WriteFile(path, []byte(unformatted), 0o600))
+	require.NoError(t, formatSchema(nil, &out, path, &SchemaFormatConfig{Write: true}))
+
+	written, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, formatted, string(written))
+
+	err = formatSchema(strings.NewReader("definition user {"), &out, "-", &SchemaFormatConfig{})
+	require.ErrorContains(t, err, "failed to format schema")
+}
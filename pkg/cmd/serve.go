@@ -11,12 +11,14 @@ import (
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 
+	"github.com/authzed/spicedb/internal/dispatch/flattened"
 	"github.com/authzed/spicedb/internal/telemetry"
 	"github.com/authzed/spicedb/pkg/cmd/datastore"
 	"github.com/authzed/spicedb/pkg/cmd/server"
 	"github.com/authzed/spicedb/pkg/cmd/termination"
 	"github.com/authzed/spicedb/pkg/cmd/util"
 	"github.com/authzed/spicedb/pkg/runtime"
+	"github.com/authzed/spicedb/pkg/tailsampling"
 )
 
 const PresharedKeyFlag = "grpc-preshared-key"
@@ -48,6 +50,24 @@ var (
 		MaxCost:             "70%",
 		CacheKindForTesting: "",
 	}
+
+	dispatchNegativeCacheDefaults = &server.CacheConfig{
+		Name:                "dispatch_negative",
+		Enabled:             false,
+		Metrics:             true,
+		NumCounters:         10_000,
+		MaxCost:             "5%",
+		CacheKindForTesting: "",
+	}
+
+	dispatchClusterNegativeCacheDefaults = &server.CacheConfig{
+		Name:                "cluster_dispatch_negative",
+		Enabled:             false,
+		Metrics:             true,
+		NumCounters:         100_000,
+		MaxCost:             "10%",
+		CacheKindForTesting: "",
+	}
 )
 
 func BoldBlue(name string) string {
@@ -70,6 +90,8 @@ func RegisterServeFlags(cmd *cobra.Command, config *server.Config) error {
 	util.RegisterGRPCServerFlags(grpcFlagSet, &config.GRPCServer, "grpc", "gRPC", ":50051", true)
 	grpcFlagSet.StringSliceVar(&config.PresharedSecureKey, PresharedKeyFlag, []string{}, "preshared key(s) to require for authenticated requests")
 	grpcFlagSet.DurationVar(&config.ShutdownGracePeriod, "grpc-shutdown-grace-period", 0*time.Second, "amount of time after receiving sigint to continue serving")
+	grpcFlagSet.StringSliceVar(&config.GRPCCompressors, "grpc-compressors", []string{}, `additional compressors clients may request for gRPC messages, besides gzip and s2 ("zstd", "br")`)
+	util.RegisterGRPCServerFlags(grpcFlagSet, &config.GRPCAdminServer, "grpc-admin", "admin gRPC (Watch APIs)", ":50055", false)
 	if err := cobra.MarkFlagRequired(grpcFlagSet, PresharedKeyFlag); err != nil {
 		return fmt.Errorf("failed to mark flag as required: %w", err)
 	}
@@ -89,6 +111,10 @@ func RegisterServeFlags(cmd *cobra.Command, config *server.Config) error {
 	if err := httpFlags.MarkHidden("http-cors-enabled"); err != nil {
 		return fmt.Errorf("failed to mark flag as hidden: %w", err)
 	}
+	httpFlags.StringSliceVar(&config.HTTPGatewayCompression, "http-compression", []string{}, `encodings with which to compress http gateway responses for clients accepting them, in order of preference ("zstd", "br", "gzip")`)
+	httpFlags.BoolVar(&config.HTTPGatewayGraphQLEnabled, "http-graphql-enabled", false, "serve checkPermission, lookupResources and readRelationships GraphQL queries at /graphql on the http gateway, batching checks into CheckBulkPermissions requests")
+	httpFlags.StringVar(&config.HTTPGatewayOPADataConfigPath, "http-opa-data-config-path", "", "path to a YAML file of LookupResources and LookupSubjects results served as OPA data documents under /opa/data on the http gateway, with ETags refreshed by watching changes")
+	httpFlags.StringVar(&config.HTTPGatewaySCIMMappingPath, "http-scim-mapping-path", "", "path to a YAML file mapping SCIM 2.0 users and groups to relationships, enabling the SCIM provisioning API under /scim/v2 on the http gateway, authenticated with the preshared keys")
 	httpFlags.StringSliceVar(&config.HTTPGatewayCorsAllowedOrigins, "http-cors-allowed-origins", []string{"*"}, "Set CORS allowed origins for http gateway, defaults to all origins")
 	if err := httpFlags.MarkHidden("http-cors-allowed-origins"); err != nil {
 		return fmt.Errorf("failed to mark flag as hidden: %w", err)
@@ -104,6 +130,7 @@ func RegisterServeFlags(cmd *cobra.Command, config *server.Config) error {
 	}
 	apiFlags.BoolVar(&config.DisableVersionResponse, "disable-version-response", false, "disables version response support in the API")
 	apiFlags.Uint16Var(&config.MaximumUpdatesPerWrite, "write-relationships-max-updates-per-call", 1000, "maximum number of updates allowed for WriteRelationships calls")
+	apiFlags.Uint32Var(&config.MaximumUpdatesPerChunkedWrite, "write-relationships-max-updates-per-chunked-call", 0, "maximum number of updates allowed for WriteRelationships calls in chunked mode, in which the updates are applied in transactions of at most --write-relationships-max-updates-per-call updates and rolled back should any fail. 0 disables chunked mode")
 	apiFlags.IntVar(&config.MaxCaveatContextSize, "max-caveat-context-size", 4096, "maximum allowed size of request caveat context in bytes. A value of zero or less means no limit")
 	apiFlags.IntVar(&config.MaxRelationshipContextSize, "max-relationship-context-size", 25000, "maximum allowed size of the context to be stored in a relationship")
 	apiFlags.DurationVar(&config.StreamingAPITimeout, "streaming-api-response-delay-timeout", 30*time.Second, "max duration time elapsed between messages sent by the server-side to the client (responses) before the stream times out")
@@ -112,6 +139,26 @@ func RegisterServeFlags(cmd *cobra.Command, config *server.Config) error {
 	apiFlags.Uint32Var(&config.MaxDeleteRelationshipsLimit, "max-delete-relationships-limit", 1000, "maximum number of relationships that can be deleted in a single request")
 	apiFlags.Uint32Var(&config.MaxLookupResourcesLimit, "max-lookup-resources-limit", 1000, "maximum number of resources that can be looked up in a single request")
 	apiFlags.Uint32Var(&config.MaxBulkExportRelationshipsLimit, "max-bulk-export-relationships-limit", 10_000, "maximum number of relationships that can be exported in a single request")
+	apiFlags.BoolVar(&config.EnableWriteProvenanceMetadata, "enable-write-provenance-metadata", false, "record the request ID, caller (identified by a hash of its bearer token), peer address and user agent of relationship writes in the transaction metadata surfaced by the Watch API")
+	apiFlags.DurationVar(&config.WriteIdempotencyKeyTTL, "write-relationships-idempotency-key-ttl", 10*time.Minute, "duration for which a WriteRelationships call with an idempotency key is remembered, such that retries of the call are not applied again. Keys are recorded in the datastore with the write, so retries reaching any node are recognized. 0 disables idempotency keys")
+	apiFlags.Uint32Var(&config.WriteIdempotencyMaxKeys, "write-relationships-idempotency-max-keys", 10_000, "maximum number of WriteRelationships idempotency keys cached by each node with the revisions of their writes, after which the oldest are evicted")
+	apiFlags.BoolVar(&config.OpenFGAAPIEnabled, "openfga-api-enabled", false, "serve the check, expand, read and write methods of the OpenFGA API over gRPC and the http gateway, translated onto the SpiceDB schema and relationships")
+	apiFlags.StringVar(&config.OpenFGAStoreID, "openfga-store-id", "", "ID of the single OpenFGA store served by the OpenFGA API. If empty, requests for any store are served")
+	apiFlags.StringVar(&config.ExtAuthzRulesPath, "ext-authz-rules-path", "", "path to a YAML file of rules mapping HTTP requests to permission checks. If set, the Envoy ext_authz gRPC service is served, authorizing the requests with those checks")
+
+	apiFlags.Uint64Var(&config.DeletionProtectionMaxDeletedRelationships, "deletion-protection-max-deleted-relationships", 0, "maximum number of relationships a single write can delete, whether by DeleteRelationships, WriteRelationships, ApplyRelationshipsSnapshot or a schema write, beyond which the write is rejected unless overridden. 0 means no limit")
+	apiFlags.StringSliceVar(&config.DeletionProtectionObjectTypes, "deletion-protection-object-types", []string{}, "resource object types whose relationships cannot be deleted by any write unless overridden, such as those of critical memberships")
+	apiFlags.StringVar(&config.DeletionProtectionAdminToken, "deletion-protection-admin-token", "", "admin token, distinct from the preshared keys, with which writes can override the deletion protection rules by setting it in the io.spicedb.overridedeletionprotection header. If empty, the rules cannot be overridden")
+
+	apiFlags.BoolVar(&config.EnableUsageMetering, "enable-usage-metering", false, "meter the API calls, dispatched sub-problems and relationships read of each caller token, exported as metrics and served by /debug/usage on the metrics server")
+	apiFlags.Uint64Var(&config.UsageMeteringMonthlyAPICallQuota, "usage-metering-monthly-api-call-quota", 0, "maximum number of API calls a caller token can make in a calendar month, after which its calls are rejected. Enforced by each node independently. 0 means no limit")
+	apiFlags.Uint64Var(&config.UsageMeteringMonthlyDispatchQuota, "usage-metering-monthly-dispatch-quota", 0, "maximum number of sub-problems that can be dispatched for the calls of a caller token in a calendar month, after which its calls are rejected. Enforced by each node independently. 0 means no limit")
+	apiFlags.Uint64Var(&config.UsageMeteringMonthlyRelationshipsQuota, "usage-metering-monthly-relationships-read-quota", 0, "maximum number of relationships that can be read for the calls of a caller token in a calendar month, after which its calls are rejected. Enforced by each node independently. 0 means no limit")
+
+	apiFlags.StringVar(&config.RequestCaptureFile, "request-capture-file", "", "file to which a sample of the CheckPermission, CheckBulkPermissions, LookupResources and LookupSubjects requests served is appended, with the revisions at which they were served, digests of their results and their latencies, to be replayed with `spicedb replay`. Headers and caller addresses are never captured, and object IDs, subject IDs and caveat context values are hashed unless --request-capture-disable-hashing is set")
+	apiFlags.Float64Var(&config.RequestCaptureSampleRate, "request-capture-sample-rate", 0.01, "fraction of the requests captured when a request capture file is set, between 0 (exclusive) and 1")
+	apiFlags.Uint64Var(&config.RequestCaptureMaxRecords, "request-capture-max-records", 100_000, "maximum number of requests captured by a node, after which capturing stops. 0 means no limit")
+	apiFlags.BoolVar(&config.RequestCaptureDisableHashing, "request-capture-disable-hashing", false, "record the object IDs, subject IDs and caveat context values of captured requests as sent, rather than hashed with a salt of the capture, so that the captured requests can be replayed. Only for captures of data that may be exported")
 
 	datastoreFlags := nfs.FlagSet(BoldBlue("Datastore"))
 	// Flags for the datastore
@@ -119,6 +166,9 @@ func RegisterServeFlags(cmd *cobra.Command, config *server.Config) error {
 		return err
 	}
 	datastoreFlags.Uint64Var(&config.MaxDatastoreReadPageSize, "max-datastore-read-page-size", 1_000, "limit on the maximum page size that we will load into memory from the datastore at one time")
+	datastoreFlags.DurationVar(&config.RevisionFreshnessCheckInterval, "datastore-revision-freshness-check-interval", 0, "interval at which the freshness of datastore revisions is checked once ready, reporting not serving in the health check while they are stale. 0 disables the checks")
+	datastoreFlags.DurationVar(&config.MaxRevisionStaleness, "datastore-max-revision-staleness", 1*time.Minute, "maximum age of the optimized revision before the health check reports not serving (only measured for datastores with timestamped revisions). 0 disables the check")
+	datastoreFlags.DurationVar(&config.MaxReplicaLag, "datastore-max-replica-lag", 1*time.Minute, "maximum time a read replica may lag behind the primary before the health check reports not serving. 0 disables the check")
 
 	namespaceCacheFlags := nfs.FlagSet(BoldBlue("Namespace Cache"))
 	// Flags for the namespace cache
@@ -134,10 +184,16 @@ func RegisterServeFlags(cmd *cobra.Command, config *server.Config) error {
 	util.RegisterGRPCServerFlags(dispatchFlags, &config.DispatchServer, "dispatch-cluster", "dispatch", ":50053", false)
 	server.MustRegisterCacheFlags(dispatchFlags, "dispatch-cache", &config.DispatchCacheConfig, dispatchCacheDefaults)
 	server.MustRegisterCacheFlags(dispatchFlags, "dispatch-cluster-cache", &config.ClusterDispatchCacheConfig, dispatchClusterCacheDefaults)
+	server.MustRegisterCacheFlags(dispatchFlags, "dispatch-negative-cache", &config.DispatchNegativeCacheConfig, dispatchNegativeCacheDefaults)
+	dispatchFlags.DurationVar(&config.DispatchNegativeCacheConfig.TTL, "dispatch-negative-cache-ttl", 0, "amount of time a negative (no permission) dispatch result should remain cached. 0 means to derive it from the revision quantization")
+	server.MustRegisterCacheFlags(dispatchFlags, "dispatch-cluster-negative-cache", &config.ClusterDispatchNegativeCacheConfig, dispatchClusterNegativeCacheDefaults)
+	dispatchFlags.DurationVar(&config.ClusterDispatchNegativeCacheConfig.TTL, "dispatch-cluster-negative-cache-ttl", 0, "amount of time a negative (no permission) cluster dispatch result should remain cached. 0 means to derive it from the revision quantization")
 
 	// Flags for configuring dispatch requests
 	dispatchFlags.Uint16Var(&config.DispatchChunkSize, "dispatch-chunk-size", 100, "maximum number of object IDs in a dispatched request")
 	dispatchFlags.Uint32Var(&config.DispatchMaxDepth, "dispatch-max-depth", 50, "maximum recursion depth for nested calls")
+	dispatchFlags.StringToStringVar(&config.DispatchMaxDepthOverrides, "dispatch-max-depth-overrides", nil, "maximum recursion depth for nested calls below an object type (`namespace`) or permission (`namespace#relation`), bounding recursive types more tightly than --dispatch-max-depth")
+	dispatchFlags.StringToStringVar(&config.DispatchTimeoutOverrides, "dispatch-timeout-overrides", nil, "maximum duration of a dispatch of an object type (`namespace`) or permission (`namespace#relation`), including its nested calls")
 	dispatchFlags.StringVar(&config.DispatchUpstreamAddr, "dispatch-upstream-addr", "", "upstream grpc address to dispatch to")
 	dispatchFlags.StringVar(&config.DispatchUpstreamCAPath, "dispatch-upstream-ca-path", "", "local path to the TLS CA used when connecting to the dispatch cluster")
 	dispatchFlags.DurationVar(&config.DispatchUpstreamTimeout, "dispatch-upstream-timeout", 60*time.Second, "maximum duration of a dispatch call an upstream cluster before it times out")
@@ -152,6 +208,24 @@ func RegisterServeFlags(cmd *cobra.Command, config *server.Config) error {
 	dispatchFlags.Uint16Var(&config.DispatchHashringReplicationFactor, "dispatch-hashring-replication-factor", 100, "set the replication factor of the consistent hasher used for the dispatcher")
 	dispatchFlags.Uint8Var(&config.DispatchHashringSpread, "dispatch-hashring-spread", 1, "set the spread of the consistent hasher used for the dispatcher")
 
+	dispatchFlags.BoolVar(&config.DispatchHedgingEnabled, "dispatch-hedging", false, "enable hedging of check and expand dispatches to the upstream cluster, sending slow dispatches to another member of the hashring")
+	dispatchFlags.DurationVar(&config.DispatchHedgingInitialDelay, "dispatch-hedging-initial-delay", 100*time.Millisecond, "initial delay after which a dispatch is hedged, before latency statistics have been collected")
+	dispatchFlags.Float64Var(&config.DispatchHedgingQuantile, "dispatch-hedging-quantile", 0.95, "quantile of historical dispatch latency after which a dispatch is hedged")
+	dispatchFlags.Uint32Var(&config.DispatchCacheWarmingEntries, "dispatch-cache-warming-entries", 0, "number of the hottest dispatch cache entries requested from a peer of the upstream cluster on startup, and served to peers starting up. 0 disables cache warming")
+	dispatchFlags.DurationVar(&config.DispatchCacheWarmingTimeout, "dispatch-cache-warming-timeout", 30*time.Second, "maximum duration of warming the dispatch cache from a peer on startup")
+	dispatchFlags.StringSliceVar(&config.DispatchFlattenedGroupRelations, "dispatch-flattened-group-relations", nil, "group relations (`namespace#relation`) whose transitive memberships are flattened from the Watch API and used to answer checks of nested groups without recursive dispatches")
+	dispatchFlags.Uint32Var(&config.DispatchFlattenedGroupMaxRelationships, "dispatch-flattened-group-max-relationships", flattened.DefaultMaxRelationships, "maximum number of relationships of a group relation held in memory for flattening; relations with more are resolved normally")
+	dispatchFlags.BoolVar(&config.DispatchAdaptiveConcurrencyEnabled, "dispatch-adaptive-concurrency", false, "enable adaptive limits on the number of parallel goroutines created for dispatch sub-problems, backing off when sub-problems are slow or fail due to overload")
+	dispatchFlags.Uint32Var(&config.DispatchAdaptiveConcurrencyLimit, "dispatch-adaptive-concurrency-limit", 1000, "maximum adaptive number of additional parallel goroutines created for dispatch sub-problems across all requests")
+	dispatchFlags.Uint16Var(&config.DispatchConcurrencyLimits.AdaptivePerRequest, "dispatch-adaptive-concurrency-request-limit", 100, "maximum adaptive number of additional parallel goroutines created for dispatch sub-problems within a single request. 0 to only apply the server-wide limit")
+	dispatchFlags.DurationVar(&config.DispatchAdaptiveConcurrencyLatencyThreshold, "dispatch-adaptive-concurrency-latency-threshold", 1*time.Second, "duration after which a dispatch sub-problem is considered slowed by overload, reducing the adaptive limits. 0 to only back off on errors")
+	dispatchFlags.BoolVar(&config.DispatchCheckPlannerEnabled, "dispatch-check-adaptive-ordering", false, "order the evaluation of the branches of union and intersection permissions in checks by how often each has determined the result, evaluating a branch that nearly always does on its own first to reduce dispatches")
+	dispatchFlags.StringVar(&config.DispatchCanaryEngine, "dispatch-canary-engine", "", `engine with which a sample of checks are evaluated again in the background, logging and counting those whose results differ ("local", "check-adaptive-ordering" or "flattened-groups"). empty disables the canary`)
+	dispatchFlags.Float64Var(&config.DispatchCanaryPercent, "dispatch-canary-percent", 1, "percentage of checks evaluated again with the dispatch canary engine")
+	dispatchFlags.Uint16Var(&config.DispatchCanaryMaxInFlight, "dispatch-canary-max-in-flight", 16, "maximum number of checks evaluated with the dispatch canary engine at once, beyond which sampled checks are skipped")
+	dispatchFlags.Uint16Var(&config.DispatchPriorityLimits.Batch, "dispatch-batch-priority-concurrency-limit", 100, "maximum number of dispatches of requests of the batch priority class served by the API of this node at once, beyond which they wait for a prior one to complete. dispatches of interactive requests are never limited. 0 for no limit")
+	dispatchFlags.Uint16Var(&config.DispatchPriorityLimits.Background, "dispatch-background-priority-concurrency-limit", 10, "maximum number of dispatches of requests of the background priority class served by the API of this node at once, beyond which they wait for a prior one to complete. 0 for no limit")
+
 	cmd.Flags().BoolVar(&config.V1SchemaAdditiveOnly, "testing-only-schema-additive-writes", false, "append new definitions to the existing schema, rather than overwriting it")
 	if err := cmd.Flags().MarkHidden("testing-only-schema-additive-writes"); err != nil {
 		return fmt.Errorf("failed to mark flag as hidden: %w", err)
@@ -170,6 +244,15 @@ func RegisterServeFlags(cmd *cobra.Command, config *server.Config) error {
 	}
 
 	experimentalFlags.BoolVar(&config.EnableExperimentalRelationshipExpiration, "enable-experimental-relationship-expiration", false, "enables experimental support for first-class relationship expiration")
+	experimentalFlags.BoolVar(&config.EnableExperimentalCaveatPrefiltering, "enable-experimental-caveat-prefiltering", false, "excludes relationships whose caveats evaluate to false under the context of a check, and have no context of their own, from the relationships loaded for the check")
+	experimentalFlags.BoolVar(&config.EnableExperimentalLookupJobs, "enable-experimental-lookup-jobs", false, "serves the experimental lookup jobs API, which runs LookupResources and LookupSubjects queries in the background and materializes their full results to files to be downloaded. Jobs and their results are kept by the node running them")
+	experimentalFlags.StringVar(&config.LookupJobsDirectory, "experimental-lookup-jobs-dir", "", "directory in which the results of lookup jobs are materialized. Defaults to the temporary directory of the system")
+	experimentalFlags.DurationVar(&config.LookupJobsRetention, "experimental-lookup-jobs-retention", time.Hour, "duration for which lookup jobs and their results are kept once done")
+	experimentalFlags.Uint16Var(&config.LookupJobsMaxRunning, "experimental-lookup-jobs-max-running", 4, "maximum number of lookup jobs running at once on a node, beyond which submissions are rejected")
+	experimentalFlags.BoolVar(&config.EnableExperimentalForks, "enable-experimental-forks", false, "serves the experimental forks API, which creates copy-on-write forks of the datastore at a revision for ephemeral environments. Requests are served from a fork when its ID is set in their `io.spicedb.fork` header, and forks are held in memory by the node which created them")
+	experimentalFlags.DurationVar(&config.ForksMaxTTL, "experimental-forks-max-ttl", 24*time.Hour, "maximum duration for which a fork is kept, which is also the default when a fork is created without a TTL")
+	experimentalFlags.Uint16Var(&config.ForksMaxCount, "experimental-forks-max-count", 16, "maximum number of forks held at once on a node, beyond which creations are rejected")
+	experimentalFlags.BoolVar(&config.EnableExperimentalReconciliation, "enable-experimental-reconciliation", false, "serves the experimental reconciliation API, which makes the relationships matching a filter exactly those of a desired snapshot, in a single transaction")
 	experimentalFlags.BoolVar(&config.EnableExperimentalWatchableSchemaCache, "enable-experimental-watchable-schema-cache", false, "enables the experimental schema cache which makes use of the Watch API for automatic updates")
 	// TODO: these two could reasonably be put in either the Dispatch group or the Experimental group. Is there a preference?
 	experimentalFlags.StringToStringVar(&config.DispatchSecondaryUpstreamAddrs, "experimental-dispatch-secondary-upstream-addrs", nil, "secondary upstream addresses for dispatches, each with a name")
@@ -180,12 +263,18 @@ func RegisterServeFlags(cmd *cobra.Command, config *server.Config) error {
 	// NOTE: cobraotel.New takes service name as an arg rather than command name.
 	otel := cobraotel.New("spicedb")
 	otel.RegisterFlags(observabilityFlags)
+	tailsampling.RegisterFlags(observabilityFlags)
 	runtime.RegisterFlags(observabilityFlags)
 
 	metricsFlags := nfs.FlagSet(BoldBlue("Metrics Server"))
 	// Flags for metrics
 	util.RegisterHTTPServerFlags(metricsFlags, &config.MetricsAPI, "metrics", "metrics", ":9090", true)
 
+	diagnosticsFlags := nfs.FlagSet(BoldBlue("Diagnostics Server"))
+	// Flags for the diagnostics API
+	util.RegisterHTTPServerFlags(diagnosticsFlags, &config.DiagnosticsAPI, "diagnostics", "diagnostics (pprof, goroutine dumps and dispatch snapshots)", ":9091", false)
+	diagnosticsFlags.StringVar(&config.DiagnosticsAdminToken, "diagnostics-admin-token", "", "bearer token required by every request to the diagnostics server, distinct from the preshared keys of the API (required when the diagnostics server is enabled)")
+
 	telemetryFlags := nfs.FlagSet(BoldBlue("Telemetry"))
 	// Flags for telemetry
 	telemetryFlags.StringVar(&config.TelemetryEndpoint, "telemetry-endpoint", telemetry.DefaultEndpoint, "endpoint to which telemetry is reported, empty string to disable")
@@ -195,7 +284,10 @@ func RegisterServeFlags(cmd *cobra.Command, config *server.Config) error {
 	miscellaneousFlags := nfs.FlagSet(BoldBlue("Miscellaneous"))
 	// Flags for things that don't neatly fit into another bucket
 	termination.RegisterFlags(miscellaneousFlags)
+	server.RegisterConfigFileFlag(miscellaneousFlags)
+	miscellaneousFlags.BoolVar(&config.RequireFIPSMode, "require-fips-mode", false, "fail startup unless running in FIPS mode, with GODEBUG=fips140=on or a binary built with GOEXPERIMENT=boringcrypto, in which TLS is restricted to FIPS-approved settings and options relying on unapproved cryptography are rejected")
 	miscellaneousFlags.BoolVar(&config.SchemaPrefixesRequired, "schema-prefixes-required", false, "require prefixes on all object definitions in schemas")
+	miscellaneousFlags.StringVar(&config.ConfigReloadPath, "config-reload-path", "", "path to a file of SPICEDB_ environment variables for the settings which can be changed without a restart (log level, cache max costs, adaptive dispatch concurrency limit, datastore revision quantization), reloaded on SIGHUP or when the file changes")
 
 	// Flags for misc services
 
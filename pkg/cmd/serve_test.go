@@ -27,6 +27,8 @@ func RunServeTest(t *testing.T, args []string, assertConfig func(t *testing.T, m
 	config.DatastoreConfig.EnableDatastoreMetrics = false
 	config.DispatchCacheConfig.Metrics = false
 	config.ClusterDispatchCacheConfig.Metrics = false
+	config.DispatchNegativeCacheConfig.Metrics = false
+	config.ClusterDispatchNegativeCacheConfig.Metrics = false
 	config.NamespaceCacheConfig.Metrics = false
 
 	cmd.SetArgs(args)
@@ -207,3 +209,38 @@ func TestConfigPrecedence(t *testing.T) {
 		require.Equal(t, uint16(23000), mergedConfig.DatastoreConfig.WatchBufferLength)
 	})
 }
+
+func TestYAMLConfigFilePrecedence(t *testing.T) {
+	// The YAML config file has the lowest precedence (over default values)
+	confdir := prepareTempConfigDir(t)
+	configPath := filepath.Join(confdir, "spicedb.yaml")
+	require.Nil(t, os.WriteFile(configPath, []byte(`
+grpc-preshared-key: ${TEST_PRESHARED_KEY}
+grpc-addr: 127.0.0.1:31051
+http-enabled: true
+http-addr: 127.0.0.1:31443
+datastore-engine: memory
+datastore-watch-buffer-length: 31000
+experimental-dispatch-secondary-upstream-addrs:
+  secondary: ${TEST_SECONDARY_ADDR:-localhost:50053}
+`), 0o600))
+	t.Setenv("TEST_PRESHARED_KEY", "some_key")
+
+	// Env variables override the config file
+	t.Setenv("SPICEDB_HTTP_ADDR", "127.0.0.1:32443")
+
+	// command line flags override everything
+	flags := []string{
+		"--config", configPath,
+		"--datastore-watch-buffer-length", "33000",
+	}
+	RunServeTest(t, flags, func(t *testing.T, mergedConfig *server.Config) {
+		require.Equal(t, []string{"some_key"}, mergedConfig.PresharedSecureKey)
+		require.Equal(t, "127.0.0.1:31051", mergedConfig.GRPCServer.Address)
+		require.Equal(t, true, mergedConfig.HTTPGateway.HTTPEnabled)
+		require.Equal(t, "127.0.0.1:32443", mergedConfig.HTTPGateway.HTTPAddress)
+		require.Equal(t, "memory", mergedConfig.DatastoreConfig.Engine)
+		require.Equal(t, uint16(33000), mergedConfig.DatastoreConfig.WatchBufferLength)
+		require.Equal(t, map[string]string{"secondary": "localhost:50053"}, mergedConfig.DispatchSecondaryUpstreamAddrs)
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/KimMachineGun/automemlimit/memlimit"
 	"github.com/ccoveille/go-safecast"
 	"github.com/dustin/go-humanize"
 	"github.com/jzelinskie/stringz"
@@ -20,14 +21,25 @@ import (
 const ttlExtensionFactor = 2.0
 
 var (
-	// At startup, measure 75% of available free memory.
+	// At startup, measure 75% of available memory.
 	freeMemory uint64
 
 	errOverHundredPercent = errors.New("percentage greater than 100")
 )
 
 func init() {
-	freeMemory = memory.FreeMemory() / 100 * 75
+	freeMemory = availableMemory(memory.FreeMemory(), memlimit.FromCgroup) / 100 * 75
+}
+
+// availableMemory returns the free memory of the host or, if running within a container
+// with a lower memory limit, the container's memory limit, so that cache sizes given as
+// a percentage cannot exceed the memory available to the process.
+func availableMemory(hostFreeMemory uint64, containerLimit func() (uint64, error)) uint64 {
+	limit, err := containerLimit()
+	if err != nil || limit == 0 || limit >= hostFreeMemory {
+		return hostFreeMemory
+	}
+	return limit
 }
 
 // CacheConfig defines the configuration various SpiceDB caches.
@@ -39,6 +51,7 @@ type CacheConfig struct {
 	NumCounters         int64         `debugmap:"visible"`
 	Metrics             bool          `debugmap:"visible"`
 	Enabled             bool          `debugmap:"visible"`
+	TTL                 time.Duration `debugmap:"visible"`
 	defaultTTL          time.Duration `debugmap:"visible"`
 	CacheKindForTesting string        `debugmap:"visible"`
 }
@@ -49,8 +62,9 @@ func (cc *CacheConfig) WithRevisionParameters(
 	quantizationInterval time.Duration,
 	followerReadDelay time.Duration,
 	maxStalenessPercent float64,
+	quantizationJitter time.Duration,
 ) *CacheConfig {
-	maxExpectedLifetime := float64(quantizationInterval.Nanoseconds())*(1+maxStalenessPercent) + float64(followerReadDelay.Nanoseconds())
+	maxExpectedLifetime := float64(quantizationInterval.Nanoseconds())*(1+maxStalenessPercent) + float64(followerReadDelay.Nanoseconds()) + float64(quantizationJitter.Nanoseconds())
 	cc.defaultTTL = time.Duration(maxExpectedLifetime*ttlExtensionFactor) * time.Nanosecond
 	return cc
 }
@@ -61,23 +75,16 @@ func CompleteCache[K cache.KeyString, V any](cc *CacheConfig) (cache.Cache[K, V]
 		return cache.NoopCache[K, V](), nil
 	}
 
-	var (
-		maxCost uint64
-		err     error
-	)
-
-	if strings.HasSuffix(cc.MaxCost, "%") {
-		maxCost, err = parsePercent(cc.MaxCost, freeMemory)
-	} else {
-		maxCost, err = humanize.ParseBytes(cc.MaxCost)
-	}
-	if err != nil {
-		return nil, fmt.Errorf("error parsing cache max memory: `%s`: %w", cc.MaxCost, err)
+	// An explicitly configured TTL takes precedence over the TTL derived from the
+	// revision parameters.
+	ttl := cc.defaultTTL
+	if cc.TTL > 0 {
+		ttl = cc.TTL
 	}
 
-	intMaxCost, err := safecast.ToInt64(maxCost)
+	intMaxCost, err := parseMaxCost(cc.MaxCost)
 	if err != nil {
-		return nil, fmt.Errorf("could not cast max cost to int64")
+		return nil, err
 	}
 
 	if cc.CacheKindForTesting != "" {
@@ -86,14 +93,14 @@ func CompleteCache[K cache.KeyString, V any](cc *CacheConfig) (cache.Cache[K, V]
 			return cache.NewTheineCache[K, V](&cache.Config{
 				MaxCost:     intMaxCost,
 				NumCounters: cc.NumCounters,
-				DefaultTTL:  cc.defaultTTL,
+				DefaultTTL:  ttl,
 			})
 
 		case "otter":
 			return cache.NewOtterCache[K, V](&cache.Config{
 				MaxCost:     intMaxCost,
 				NumCounters: cc.NumCounters,
-				DefaultTTL:  cc.defaultTTL,
+				DefaultTTL:  ttl,
 			})
 
 		default:
@@ -105,17 +112,40 @@ func CompleteCache[K cache.KeyString, V any](cc *CacheConfig) (cache.Cache[K, V]
 		return cache.NewStandardCacheWithMetrics[K, V](cc.Name, &cache.Config{
 			MaxCost:     intMaxCost,
 			NumCounters: cc.NumCounters,
-			DefaultTTL:  cc.defaultTTL,
+			DefaultTTL:  ttl,
 		})
 	}
 
 	return cache.NewStandardCache[K, V](&cache.Config{
 		MaxCost:     intMaxCost,
 		NumCounters: cc.NumCounters,
-		DefaultTTL:  cc.defaultTTL,
+		DefaultTTL:  ttl,
 	})
 }
 
+// parseMaxCost parses a cache max cost given in bytes or in percent of the available memory.
+func parseMaxCost(maxCost string) (int64, error) {
+	var (
+		parsed uint64
+		err    error
+	)
+
+	if strings.HasSuffix(maxCost, "%") {
+		parsed, err = parsePercent(maxCost, freeMemory)
+	} else {
+		parsed, err = humanize.ParseBytes(maxCost)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("error parsing cache max memory: `%s`: %w", maxCost, err)
+	}
+
+	intMaxCost, err := safecast.ToInt64(parsed)
+	if err != nil {
+		return 0, fmt.Errorf("could not cast max cost to int64")
+	}
+	return intMaxCost, nil
+}
+
 func parsePercent(str string, freeMem uint64) (uint64, error) {
 	percent := strings.TrimSuffix(str, "%")
 	parsedPercent, err := strconv.ParseUint(percent, 10, 64)
@@ -135,7 +165,7 @@ func parsePercent(str string, freeMem uint64) (uint64, error) {
 func MustRegisterCacheFlags(flags *pflag.FlagSet, flagPrefix string, config, defaults *CacheConfig) {
 	config.Name = defaults.Name
 	flagPrefix = stringz.DefaultEmpty(flagPrefix, "cache")
-	flags.StringVar(&config.MaxCost, flagPrefix+"-max-cost", defaults.MaxCost, "upper bound cache size in bytes or percent of available memory")
+	flags.StringVar(&config.MaxCost, flagPrefix+"-max-cost", defaults.MaxCost, "upper bound cache size in bytes or percent of available memory (the lower of free memory and the container memory limit)")
 	flags.Int64Var(&config.NumCounters, flagPrefix+"-num-counters", defaults.NumCounters, "number of TinyLFU samples to track")
 	flags.BoolVar(&config.Metrics, flagPrefix+"-metrics", defaults.Metrics, "enable cache metrics")
 	flags.BoolVar(&config.Enabled, flagPrefix+"-enabled", defaults.Enabled, "enable caching")
@@ -1,6 +1,7 @@
 package server
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -30,3 +31,27 @@ func TestParsePercent(t *testing.T) {
 		require.Equal(t, tt.expected, v)
 	}
 }
+
+func TestAvailableMemory(t *testing.T) {
+	table := []struct {
+		name           string
+		hostFreeMemory uint64
+		containerLimit uint64
+		limitErr       error
+		expected       uint64
+	}{
+		{"no container limit", 1000, 0, nil, 1000},
+		{"lower container limit", 1000, 500, nil, 500},
+		{"higher container limit", 1000, 2000, nil, 1000},
+		{"error reading container limit", 1000, 500, errors.New("no cgroup"), 1000},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			v := availableMemory(tt.hostFreeMemory, func() (uint64, error) {
+				return tt.containerLimit, tt.limitErr
+			})
+			require.Equal(t, tt.expected, v)
+		})
+	}
+}
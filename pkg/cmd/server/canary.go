@@ -0,0 +1,92 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/authzed/spicedb/internal/dispatch"
+	"github.com/authzed/spicedb/internal/dispatch/canary"
+	combineddispatch "github.com/authzed/spicedb/internal/dispatch/combined"
+	"github.com/authzed/spicedb/internal/dispatch/flattened"
+	"github.com/authzed/spicedb/internal/dispatch/graph"
+	"github.com/authzed/spicedb/internal/dispatch/limits"
+	maingraph "github.com/authzed/spicedb/internal/graph"
+)
+
+// The engines with which checks can be evaluated by the canary dispatcher.
+const (
+	// DispatchCanaryEngineLocal evaluates checks with the same engine, locally and without caching,
+	// which validates the caching and the remote dispatch of checks.
+	DispatchCanaryEngineLocal = "local"
+
+	// DispatchCanaryEngineCheckAdaptiveOrdering evaluates checks with their branches ordered by how
+	// often each has determined the result.
+	DispatchCanaryEngineCheckAdaptiveOrdering = "check-adaptive-ordering"
+
+	// DispatchCanaryEngineFlattenedGroups evaluates checks with the flattened group memberships of
+	// the configured relations, which are then not used to answer checks.
+	DispatchCanaryEngineFlattenedGroups = "flattened-groups"
+)
+
+// validateDispatchCanary returns an error if the canary dispatcher is misconfigured.
+func (c *Config) validateDispatchCanary() error {
+	switch c.DispatchCanaryEngine {
+	case "":
+		return nil
+
+	case DispatchCanaryEngineLocal:
+
+	case DispatchCanaryEngineCheckAdaptiveOrdering:
+		if c.DispatchCheckPlannerEnabled {
+			return fmt.Errorf("dispatch canary engine %q requires check adaptive ordering to be disabled, as it is already used to answer checks", c.DispatchCanaryEngine)
+		}
+
+	case DispatchCanaryEngineFlattenedGroups:
+		if len(c.DispatchFlattenedGroupRelations) == 0 {
+			return fmt.Errorf("dispatch canary engine %q requires flattened group relations to be configured", c.DispatchCanaryEngine)
+		}
+
+	default:
+		return fmt.Errorf("unknown dispatch canary engine %q: must be one of %q, %q or %q", c.DispatchCanaryEngine,
+			DispatchCanaryEngineLocal, DispatchCanaryEngineCheckAdaptiveOrdering, DispatchCanaryEngineFlattenedGroups)
+	}
+
+	if c.DispatchCanaryPercent <= 0 || c.DispatchCanaryPercent > 100 {
+		return fmt.Errorf("dispatch canary percent must be greater than 0 and at most 100, found %v", c.DispatchCanaryPercent)
+	}
+	return nil
+}
+
+// newCanaryDispatcher returns the local, uncached dispatcher evaluating sampled checks with the
+// configured canary engine.
+func (c *Config) newCanaryDispatcher(concurrencyLimits graph.ConcurrencyLimits, flattenedMemberships *flattened.Memberships, typeLimits limits.Limits) (dispatch.Dispatcher, error) {
+	// The canary dispatcher neither feeds the adaptive concurrency limiter nor the check planner of
+	// the primary dispatcher, so that it does not alter how checks are answered.
+	concurrencyLimits.Adaptive = nil
+	concurrencyLimits.Planner = nil
+	if c.DispatchCheckPlannerEnabled || c.DispatchCanaryEngine == DispatchCanaryEngineCheckAdaptiveOrdering {
+		concurrencyLimits.Planner = maingraph.NewEvaluationPlanner()
+	}
+
+	options := []combineddispatch.Option{
+		combineddispatch.ConcurrencyLimits(concurrencyLimits),
+		combineddispatch.DispatchChunkSize(c.DispatchChunkSize),
+		combineddispatch.PrometheusSubsystem("dispatch_canary"),
+	}
+	if flattenedMemberships != nil {
+		options = append(options, combineddispatch.FlattenedMemberships(flattenedMemberships))
+	}
+	if len(typeLimits) > 0 {
+		options = append(options, combineddispatch.TypeLimits(typeLimits))
+	}
+	return combineddispatch.NewDispatcher(options...)
+}
+
+// withDispatchCanary wraps the dispatcher so that the configured percentage of its checks are
+// evaluated by the canary dispatcher too.
+func (c *Config) withDispatchCanary(dispatcher, canaryDispatcher dispatch.Dispatcher) dispatch.Dispatcher {
+	return canary.NewDispatcher(dispatcher, canaryDispatcher, canary.Config{
+		Engine:      c.DispatchCanaryEngine,
+		Percent:     c.DispatchCanaryPercent,
+		MaxInFlight: c.DispatchCanaryMaxInFlight,
+	})
+}
@@ -0,0 +1,179 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/jzelinskie/cobrautil/v2"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// ConfigFileFlag is the name of the flag of the path to a YAML file of flag values.
+const ConfigFileFlag = "config"
+
+// RegisterConfigFileFlag registers the flag of the path to a YAML file of flag values.
+func RegisterConfigFileFlag(flags *pflag.FlagSet) {
+	flags.String(ConfigFileFlag, "", "path to a YAML file of flag values keyed by flag name, e.g. `datastore-engine: postgres`, where ${VAR} and ${VAR:-default} are replaced by environment variables; flags set on the command line or through environment variables take precedence")
+}
+
+// ConfigFileRunE sets the flags which were not set on the command line or through environment
+// variables from the YAML file given by the config flag, if the command has one.
+func ConfigFileRunE() cobrautil.CobraRunFunc {
+	return func(cmd *cobra.Command, args []string) error {
+		if cobrautil.IsBuiltinCommand(cmd) {
+			return nil
+		}
+
+		configFlag := cmd.Flags().Lookup(ConfigFileFlag)
+		if configFlag == nil || configFlag.Value.String() == "" {
+			return nil
+		}
+
+		contents, err := os.ReadFile(configFlag.Value.String())
+		if err != nil {
+			return fmt.Errorf("failed to read config file: %w", err)
+		}
+		if err := applyConfigFile(cmd.Flags(), contents); err != nil {
+			return fmt.Errorf("invalid config file %s: %w", configFlag.Value.String(), err)
+		}
+		return nil
+	}
+}
+
+// applyConfigFile sets the unchanged flags from the YAML mapping of flag names to values. Every
+// key must be the name of a flag.
+func applyConfigFile(flags *pflag.FlagSet, contents []byte) error {
+	var document yamlv3.Node
+	if err := yamlv3.Unmarshal(contents, &document); err != nil {
+		return err
+	}
+	if len(document.Content) == 0 {
+		return nil
+	}
+
+	root := document.Content[0]
+	if root.Kind != yamlv3.MappingNode {
+		return fmt.Errorf("line %d: expected a mapping of flag names to values", root.Line)
+	}
+
+	seen := make(map[string]struct{}, len(root.Content)/2)
+	for i := 0; i < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+		if key.Kind != yamlv3.ScalarNode {
+			return fmt.Errorf("line %d: expected a flag name", key.Line)
+		}
+
+		name := key.Value
+		if _, ok := seen[name]; ok {
+			return fmt.Errorf("line %d: %s is set more than once", key.Line, name)
+		}
+		seen[name] = struct{}{}
+
+		if name == ConfigFileFlag {
+			return fmt.Errorf("line %d: %s cannot be set in the config file", key.Line, name)
+		}
+		flag := flags.Lookup(name)
+		if flag == nil {
+			return fmt.Errorf("line %d: unknown flag %s", key.Line, name)
+		}
+		if flag.Changed {
+			continue
+		}
+
+		if err := setFlagFromNode(flags, flag, value); err != nil {
+			return fmt.Errorf("line %d: invalid value for %s: %w", value.Line, name, err)
+		}
+	}
+	return nil
+}
+
+func setFlagFromNode(flags *pflag.FlagSet, flag *pflag.Flag, node *yamlv3.Node) error {
+	switch node.Kind {
+	case yamlv3.ScalarNode:
+		value, err := interpolateEnv(node.Value)
+		if err != nil {
+			return err
+		}
+		return flags.Set(flag.Name, value)
+
+	case yamlv3.SequenceNode:
+		sliceValue, ok := flag.Value.(pflag.SliceValue)
+		if !ok {
+			return errors.New("expected a single value, not a list")
+		}
+
+		values := make([]string, 0, len(node.Content))
+		for _, item := range node.Content {
+			if item.Kind != yamlv3.ScalarNode {
+				return fmt.Errorf("line %d: expected a list of values", item.Line)
+			}
+			value, err := interpolateEnv(item.Value)
+			if err != nil {
+				return err
+			}
+			values = append(values, value)
+		}
+		if err := sliceValue.Replace(values); err != nil {
+			return err
+		}
+		flag.Changed = true
+		return nil
+
+	case yamlv3.MappingNode:
+		if !strings.HasPrefix(flag.Value.Type(), "stringTo") {
+			return errors.New("expected a single value, not a mapping")
+		}
+
+		pairs := make([]string, 0, len(node.Content)/2)
+		for i := 0; i < len(node.Content); i += 2 {
+			key, item := node.Content[i], node.Content[i+1]
+			if key.Kind != yamlv3.ScalarNode || item.Kind != yamlv3.ScalarNode {
+				return fmt.Errorf("line %d: expected a mapping of keys to values", key.Line)
+			}
+			value, err := interpolateEnv(item.Value)
+			if err != nil {
+				return err
+			}
+			pairs = append(pairs, key.Value+"="+value)
+		}
+		return flags.Set(flag.Name, strings.Join(pairs, ","))
+
+	default:
+		return errors.New("unsupported value")
+	}
+}
+
+var envReferencePattern = regexp.MustCompile(`\$(\$|\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\})`)
+
+// interpolateEnv replaces the references to environment variables in the value: ${VAR} by the
+// value of VAR, which must be set, ${VAR:-default} by the value of VAR or default if VAR is unset
+// or empty, and $$ by $.
+func interpolateEnv(value string) (string, error) {
+	var err error
+	interpolated := envReferencePattern.ReplaceAllStringFunc(value, func(reference string) string {
+		groups := envReferencePattern.FindStringSubmatch(reference)
+		if groups[1] == "$" {
+			return "$"
+		}
+
+		name, hasDefault, defaultValue := groups[2], groups[3] != "", groups[4]
+		envValue, ok := os.LookupEnv(name)
+		switch {
+		case hasDefault && envValue == "":
+			return defaultValue
+		case !ok:
+			if err == nil {
+				err = fmt.Errorf("environment variable %s is not set", name)
+			}
+			return ""
+		default:
+			return envValue
+		}
+	})
+	return interpolated, err
+}
@@ -33,17 +33,24 @@ import (
 
 	"github.com/authzed/spicedb/internal/dispatch"
 	"github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/internal/diagnostics"
+	"github.com/authzed/spicedb/internal/middleware/capture"
 	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
 	dispatchmw "github.com/authzed/spicedb/internal/middleware/dispatcher"
+	errorcodesmw "github.com/authzed/spicedb/internal/middleware/errorcodes"
+	"github.com/authzed/spicedb/internal/middleware/metering"
 	"github.com/authzed/spicedb/internal/middleware/servicespecific"
+	v1svc "github.com/authzed/spicedb/internal/services/v1"
 	"github.com/authzed/spicedb/pkg/datastore"
 	consistencymw "github.com/authzed/spicedb/pkg/middleware/consistency"
 	logmw "github.com/authzed/spicedb/pkg/middleware/logging"
 	"github.com/authzed/spicedb/pkg/middleware/nodeid"
+	"github.com/authzed/spicedb/pkg/middleware/priority"
 	"github.com/authzed/spicedb/pkg/middleware/requestid"
 	"github.com/authzed/spicedb/pkg/middleware/serverversion"
 	"github.com/authzed/spicedb/pkg/releases"
 	"github.com/authzed/spicedb/pkg/runtime"
+	"github.com/authzed/spicedb/pkg/tailsampling"
 )
 
 var DisableTelemetryHandler *prometheus.Registry
@@ -64,11 +71,14 @@ func ServeExample(programName string) string {
 	)
 }
 
-// DefaultPreRunE sets up viper, zerolog, and OpenTelemetry flag handling for a
-// command.
+// DefaultPreRunE sets up viper, config file, zerolog, OpenTelemetry and tail sampling
+// flag handling for a command.
 func DefaultPreRunE(programName string) cobrautil.CobraRunFunc {
 	return cobrautil.CommandStack(
 		cobrautil.SyncViperDotEnvPreRunE(programName, "spicedb.env", zerologr.New(&logging.Logger)),
+		// NOTE: The config file is applied after the environment so that
+		// flags set through environment variables take precedence.
+		ConfigFileRunE(),
 		cobrazerolog.New(
 			cobrazerolog.WithTarget(func(logger zerolog.Logger) {
 				logging.SetGlobalLogger(logger)
@@ -84,6 +94,7 @@ func DefaultPreRunE(programName string) cobrautil.CobraRunFunc {
 		cobraotel.New("spicedb",
 			cobraotel.WithLogger(zerologr.New(&logging.Logger)),
 		).RunE(),
+		tailsampling.RunE(),
 		releases.CheckAndLogRunE(),
 		runtime.RunE(),
 	)
@@ -172,11 +183,17 @@ const (
 	DefaultMiddlewareGRPCAuth      = "grpcauth"
 	DefaultMiddlewareGRPCProm      = "grpcprom"
 	DefaultMiddlewareServerVersion = "serverversion"
+	DefaultMiddlewareErrorCodes    = "errorcodes"
+	DefaultMiddlewarePriority      = "priority"
 
 	DefaultInternalMiddlewareDispatch       = "dispatch"
 	DefaultInternalMiddlewareDatastore      = "datastore"
 	DefaultInternalMiddlewareConsistency    = "consistency"
 	DefaultInternalMiddlewareServerSpecific = "servicespecific"
+	DefaultInternalMiddlewareMetering       = "metering"
+	DefaultInternalMiddlewareFork           = "fork"
+	DefaultInternalMiddlewareCapture        = "capture"
+	DefaultInternalMiddlewareDiagnostics    = "diagnostics"
 )
 
 //go:generate go run github.com/ecordell/optgen -output zz_generated.middlewareoption.go . MiddlewareOption
@@ -329,6 +346,11 @@ func DefaultUnaryMiddleware(opts MiddlewareOption) (*MiddlewareChain[grpc.UnaryS
 			WithInterceptor(grpcMetricsUnaryInterceptor).
 			Done(),
 
+		NewUnaryMiddleware().
+			WithName(DefaultMiddlewareErrorCodes).
+			WithInterceptor(errorcodesmw.UnaryServerInterceptor()).
+			Done(),
+
 		NewUnaryMiddleware().
 			WithName(DefaultMiddlewareGRPCAuth).
 			WithInterceptor(grpcauth.UnaryServerInterceptor(opts.AuthFunc)).
@@ -340,6 +362,11 @@ func DefaultUnaryMiddleware(opts MiddlewareOption) (*MiddlewareChain[grpc.UnaryS
 			WithInterceptor(serverversion.UnaryServerInterceptor(opts.EnableVersionResponse)).
 			Done(),
 
+		NewUnaryMiddleware().
+			WithName(DefaultMiddlewarePriority).
+			WithInterceptor(priority.UnaryServerInterceptor()).
+			Done(),
+
 		NewUnaryMiddleware().
 			WithName(DefaultInternalMiddlewareDispatch).
 			WithInternal(true).
@@ -407,6 +434,11 @@ func DefaultStreamingMiddleware(opts MiddlewareOption) (*MiddlewareChain[grpc.St
 			WithInterceptor(grpcMetricsStreamingInterceptor).
 			Done(),
 
+		NewStreamMiddleware().
+			WithName(DefaultMiddlewareErrorCodes).
+			WithInterceptor(errorcodesmw.StreamServerInterceptor()).
+			Done(),
+
 		NewStreamMiddleware().
 			WithName(DefaultMiddlewareGRPCAuth).
 			WithInterceptor(grpcauth.StreamServerInterceptor(opts.AuthFunc)).
@@ -418,6 +450,11 @@ func DefaultStreamingMiddleware(opts MiddlewareOption) (*MiddlewareChain[grpc.St
 			WithInterceptor(serverversion.StreamServerInterceptor(opts.EnableVersionResponse)).
 			Done(),
 
+		NewStreamMiddleware().
+			WithName(DefaultMiddlewarePriority).
+			WithInterceptor(priority.StreamServerInterceptor()).
+			Done(),
+
 		NewStreamMiddleware().
 			WithName(DefaultInternalMiddlewareDispatch).
 			WithInternal(true).
@@ -440,6 +477,130 @@ func DefaultStreamingMiddleware(opts MiddlewareOption) (*MiddlewareChain[grpc.St
 	return &chain, err
 }
 
+// addMeteringMiddleware adds the middleware metering the usage of the API with the given meter
+// to the default middleware chains, right after the datastore middleware whose datastore it wraps.
+func addMeteringMiddleware(unaryChain *MiddlewareChain[grpc.UnaryServerInterceptor], streamingChain *MiddlewareChain[grpc.StreamServerInterceptor], meter *metering.Meter) error {
+	if err := unaryChain.modify(MiddlewareModification[grpc.UnaryServerInterceptor]{
+		DependencyMiddlewareName: DefaultInternalMiddlewareDatastore,
+		Operation:                OperationAppend,
+		Middlewares: []ReferenceableMiddleware[grpc.UnaryServerInterceptor]{
+			NewUnaryMiddleware().
+				WithName(DefaultInternalMiddlewareMetering).
+				WithInternal(true).
+				WithInterceptor(meter.UnaryServerInterceptor()).
+				Done(),
+		},
+	}); err != nil {
+		return err
+	}
+
+	return streamingChain.modify(MiddlewareModification[grpc.StreamServerInterceptor]{
+		DependencyMiddlewareName: DefaultInternalMiddlewareDatastore,
+		Operation:                OperationAppend,
+		Middlewares: []ReferenceableMiddleware[grpc.StreamServerInterceptor]{
+			NewStreamMiddleware().
+				WithName(DefaultInternalMiddlewareMetering).
+				WithInternal(true).
+				WithInterceptor(meter.StreamServerInterceptor()).
+				Done(),
+		},
+	})
+}
+
+// addForkMiddleware adds the middleware serving requests from the datastore forks set in their
+// headers to the default middleware chains, right after the datastore middleware whose datastore
+// it replaces. It must be added after the metering middleware, so that the usage of forks is
+// metered too. The forks server is nil when forks are not enabled, in which case the middleware
+// rejects requests to forks.
+func addForkMiddleware(unaryChain *MiddlewareChain[grpc.UnaryServerInterceptor], streamingChain *MiddlewareChain[grpc.StreamServerInterceptor], forks v1svc.ForksServer) error {
+	if err := unaryChain.modify(MiddlewareModification[grpc.UnaryServerInterceptor]{
+		DependencyMiddlewareName: DefaultInternalMiddlewareDatastore,
+		Operation:                OperationAppend,
+		Middlewares: []ReferenceableMiddleware[grpc.UnaryServerInterceptor]{
+			NewUnaryMiddleware().
+				WithName(DefaultInternalMiddlewareFork).
+				WithInternal(true).
+				WithInterceptor(v1svc.ForksUnaryServerInterceptor(forks)).
+				Done(),
+		},
+	}); err != nil {
+		return err
+	}
+
+	return streamingChain.modify(MiddlewareModification[grpc.StreamServerInterceptor]{
+		DependencyMiddlewareName: DefaultInternalMiddlewareDatastore,
+		Operation:                OperationAppend,
+		Middlewares: []ReferenceableMiddleware[grpc.StreamServerInterceptor]{
+			NewStreamMiddleware().
+				WithName(DefaultInternalMiddlewareFork).
+				WithInternal(true).
+				WithInterceptor(v1svc.ForksStreamServerInterceptor(forks)).
+				Done(),
+		},
+	})
+}
+
+// addCaptureMiddleware adds the middleware capturing a sample of the requests with the given
+// capturer to the default middleware chains, right after the consistency middleware computing the
+// revision it records.
+func addCaptureMiddleware(unaryChain *MiddlewareChain[grpc.UnaryServerInterceptor], streamingChain *MiddlewareChain[grpc.StreamServerInterceptor], capturer *capture.Capturer) error {
+	if err := unaryChain.modify(MiddlewareModification[grpc.UnaryServerInterceptor]{
+		DependencyMiddlewareName: DefaultInternalMiddlewareConsistency,
+		Operation:                OperationAppend,
+		Middlewares: []ReferenceableMiddleware[grpc.UnaryServerInterceptor]{
+			NewUnaryMiddleware().
+				WithName(DefaultInternalMiddlewareCapture).
+				WithInternal(true).
+				WithInterceptor(capturer.UnaryServerInterceptor()).
+				Done(),
+		},
+	}); err != nil {
+		return err
+	}
+
+	return streamingChain.modify(MiddlewareModification[grpc.StreamServerInterceptor]{
+		DependencyMiddlewareName: DefaultInternalMiddlewareConsistency,
+		Operation:                OperationAppend,
+		Middlewares: []ReferenceableMiddleware[grpc.StreamServerInterceptor]{
+			NewStreamMiddleware().
+				WithName(DefaultInternalMiddlewareCapture).
+				WithInternal(true).
+				WithInterceptor(capturer.StreamServerInterceptor()).
+				Done(),
+		},
+	})
+}
+
+// addDiagnosticsMiddleware adds the middleware tagging the goroutines of each request with its
+// ID to the default middleware chains, right after the middleware assigning the request ID.
+func addDiagnosticsMiddleware(unaryChain *MiddlewareChain[grpc.UnaryServerInterceptor], streamingChain *MiddlewareChain[grpc.StreamServerInterceptor]) error {
+	if err := unaryChain.modify(MiddlewareModification[grpc.UnaryServerInterceptor]{
+		DependencyMiddlewareName: DefaultMiddlewareRequestID,
+		Operation:                OperationAppend,
+		Middlewares: []ReferenceableMiddleware[grpc.UnaryServerInterceptor]{
+			NewUnaryMiddleware().
+				WithName(DefaultInternalMiddlewareDiagnostics).
+				WithInternal(true).
+				WithInterceptor(diagnostics.UnaryServerInterceptor()).
+				Done(),
+		},
+	}); err != nil {
+		return err
+	}
+
+	return streamingChain.modify(MiddlewareModification[grpc.StreamServerInterceptor]{
+		DependencyMiddlewareName: DefaultMiddlewareRequestID,
+		Operation:                OperationAppend,
+		Middlewares: []ReferenceableMiddleware[grpc.StreamServerInterceptor]{
+			NewStreamMiddleware().
+				WithName(DefaultInternalMiddlewareDiagnostics).
+				WithInternal(true).
+				WithInterceptor(diagnostics.StreamServerInterceptor()).
+				Done(),
+		},
+	})
+}
+
 func determineEventsToLog(opts MiddlewareOption) grpclog.Option {
 	eventsToLog := []grpclog.LoggableEvent{grpclog.FinishCall}
 	if opts.EnableRequestLog {
@@ -3,6 +3,7 @@ package server
 import (
 	"context"
 	"fmt"
+	"slices"
 
 	"github.com/authzed/spicedb/pkg/genutil/mapz"
 	"github.com/authzed/spicedb/pkg/spiceerrors"
@@ -458,3 +459,108 @@ type executedHandle struct {
 type interceptorsExecuted struct{}
 
 type streamExecuted struct{}
+
+// MiddlewarePosition is a position in the middleware chains of the server, relative to one of its
+// named middlewares, at which custom middlewares can be inserted with a MiddlewareBuilder.
+type MiddlewarePosition struct {
+	dependency string
+	operation  MiddlewareOperation
+}
+
+var (
+	// PositionBeforeAuth is the position right before the authentication of requests.
+	PositionBeforeAuth = PositionBefore(DefaultMiddlewareGRPCAuth)
+
+	// PositionAfterAuth is the position right after the authentication of requests: only
+	// authenticated requests reach middlewares inserted there.
+	PositionAfterAuth = PositionAfter(DefaultMiddlewareGRPCAuth)
+
+	// PositionBeforeDispatch is the position right before the dispatcher is added to the context
+	// of requests, after all of the non-internal middlewares.
+	PositionBeforeDispatch = PositionBefore(DefaultInternalMiddlewareDispatch)
+)
+
+// PositionBefore returns the position right before the named middleware.
+func PositionBefore(name string) MiddlewarePosition {
+	return MiddlewarePosition{dependency: name, operation: OperationPrepend}
+}
+
+// PositionAfter returns the position right after the named middleware.
+func PositionAfter(name string) MiddlewarePosition {
+	return MiddlewarePosition{dependency: name, operation: OperationAppend}
+}
+
+// MiddlewareBuilder builds the modifications which insert custom middlewares at named positions in
+// the middleware chains of the server, such as for embedders of SpiceDB to add their own telemetry.
+// Middlewares inserted at the same position run in the order they were inserted.
+type MiddlewareBuilder struct {
+	positions []MiddlewarePosition
+	unary     map[MiddlewarePosition][]ReferenceableMiddleware[grpc.UnaryServerInterceptor]
+	streaming map[MiddlewarePosition][]ReferenceableMiddleware[grpc.StreamServerInterceptor]
+}
+
+// NewMiddlewareBuilder creates a new, empty, MiddlewareBuilder.
+func NewMiddlewareBuilder() *MiddlewareBuilder {
+	return &MiddlewareBuilder{
+		unary:     make(map[MiddlewarePosition][]ReferenceableMiddleware[grpc.UnaryServerInterceptor]),
+		streaming: make(map[MiddlewarePosition][]ReferenceableMiddleware[grpc.StreamServerInterceptor]),
+	}
+}
+
+// Insert inserts the named unary and stream interceptors at the position. Either interceptor can be
+// nil, in which case only the other chain is modified.
+func (mb *MiddlewareBuilder) Insert(position MiddlewarePosition, name string, unary grpc.UnaryServerInterceptor, stream grpc.StreamServerInterceptor) *MiddlewareBuilder {
+	if !slices.Contains(mb.positions, position) {
+		mb.positions = append(mb.positions, position)
+	}
+
+	if unary != nil {
+		mb.unary[position] = append(mb.unary[position], NewUnaryMiddleware().
+			WithName(name).
+			WithInterceptor(unary).
+			Done())
+	}
+
+	if stream != nil {
+		mb.streaming[position] = append(mb.streaming[position], NewStreamMiddleware().
+			WithName(name).
+			WithInterceptor(stream).
+			Done())
+	}
+
+	return mb
+}
+
+// UnaryModifications returns the modifications of the unary middleware chain.
+func (mb *MiddlewareBuilder) UnaryModifications() []MiddlewareModification[grpc.UnaryServerInterceptor] {
+	return modificationsAt(mb.positions, mb.unary)
+}
+
+// StreamingModifications returns the modifications of the streaming middleware chain.
+func (mb *MiddlewareBuilder) StreamingModifications() []MiddlewareModification[grpc.StreamServerInterceptor] {
+	return modificationsAt(mb.positions, mb.streaming)
+}
+
+// ConfigOption returns an option which adds the modifications of the builder to those of a Config.
+func (mb *MiddlewareBuilder) ConfigOption() ConfigOption {
+	return func(c *Config) {
+		c.UnaryMiddlewareModification = append(c.UnaryMiddlewareModification, mb.UnaryModifications()...)
+		c.StreamingMiddlewareModification = append(c.StreamingMiddlewareModification, mb.StreamingModifications()...)
+	}
+}
+
+func modificationsAt[T middlewareTypes](positions []MiddlewarePosition, middlewares map[MiddlewarePosition][]ReferenceableMiddleware[T]) []MiddlewareModification[T] {
+	modifications := make([]MiddlewareModification[T], 0, len(positions))
+	for _, position := range positions {
+		if len(middlewares[position]) == 0 {
+			continue
+		}
+
+		modifications = append(modifications, MiddlewareModification[T]{
+			DependencyMiddlewareName: position.dependency,
+			Operation:                position.operation,
+			Middlewares:              middlewares[position],
+		})
+	}
+	return modifications
+}
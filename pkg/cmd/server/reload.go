@@ -0,0 +1,286 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/joho/godotenv"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/rs/zerolog"
+
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/internal/taskrunner"
+	"github.com/authzed/spicedb/pkg/cache"
+	"github.com/authzed/spicedb/pkg/datastore"
+)
+
+var configReloadsCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "spicedb",
+	Subsystem: "server",
+	Name:      "config_reloads_total",
+	Help:      "total number of reloads of the reloadable configuration, by result",
+}, []string{"result"})
+
+const (
+	reloadKeyLogLevel                 = "SPICEDB_LOG_LEVEL"
+	reloadKeyAdaptiveConcurrencyLimit = "SPICEDB_DISPATCH_ADAPTIVE_CONCURRENCY_LIMIT"
+	reloadKeyRevisionQuantization     = "SPICEDB_DATASTORE_REVISION_QUANTIZATION_INTERVAL"
+)
+
+// cacheReloadKey returns the key of the reloadable max cost of the cache with the flag prefix.
+func cacheReloadKey(flagPrefix string) string {
+	return "SPICEDB_" + strings.ToUpper(strings.ReplaceAll(flagPrefix, "-", "_")) + "_MAX_COST"
+}
+
+// configReloader applies the settings which are safe to change without restarting the server,
+// read from a file of environment variables in the same format as `spicedb.env`, whenever the
+// file changes or the process receives SIGHUP.
+//
+// Settings missing from the file revert to the values the server was started with. A reload is
+// applied atomically: if any setting is invalid, none is changed.
+type configReloader struct {
+	path string
+
+	caches      map[string]cache.Resizable
+	limiter     *taskrunner.AdaptiveLimiter
+	quantizable datastore.RequantizableDatastore
+
+	lock    sync.Mutex
+	initial map[string]string
+
+	// applied are the settings currently applied, initially those the server was started with.
+	applied map[string]string
+}
+
+func newConfigReloader(path string) *configReloader {
+	log.EnableLevelChanges()
+
+	initial := map[string]string{
+		reloadKeyLogLevel: zerolog.GlobalLevel().String(),
+	}
+	return &configReloader{
+		path:    path,
+		caches:  map[string]cache.Resizable{},
+		initial: initial,
+		applied: initial,
+	}
+}
+
+// addCache makes the max cost of the cache with the flag prefix reloadable.
+func (r *configReloader) addCache(flagPrefix string, cc *CacheConfig, c any) {
+	if r == nil {
+		return
+	}
+
+	key := cacheReloadKey(flagPrefix)
+	r.initial[key] = cc.MaxCost
+	if resizable, ok := c.(cache.Resizable); ok {
+		r.caches[key] = resizable
+	}
+}
+
+// setAdaptiveLimiter makes the limit of the adaptive dispatch concurrency limiter reloadable.
+func (r *configReloader) setAdaptiveLimiter(limiter *taskrunner.AdaptiveLimiter, limit uint32) {
+	if r == nil {
+		return
+	}
+
+	r.limiter = limiter
+	r.initial[reloadKeyAdaptiveConcurrencyLimit] = strconv.FormatUint(uint64(limit), 10)
+}
+
+// setDatastore makes the revision quantization of the datastore reloadable, if supported.
+func (r *configReloader) setDatastore(ds datastore.Datastore, quantization time.Duration) {
+	if r == nil {
+		return
+	}
+
+	r.quantizable = datastore.UnwrapAs[datastore.RequantizableDatastore](ds)
+	r.initial[reloadKeyRevisionQuantization] = quantization.String()
+}
+
+// Run reloads the configuration once, then on every change of the file or SIGHUP, until the
+// context is canceled.
+func (r *configReloader) Run(ctx context.Context) error {
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+	defer signal.Stop(sighup)
+
+	// The directory is watched rather than the file so that the file can be replaced, as done
+	// by editors and by Kubernetes when updating a mounted ConfigMap.
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to watch reloadable config: %w", err)
+	}
+	defer watcher.Close()
+
+	dir, file := filepath.Split(filepath.Clean(r.path))
+	if err := watcher.Add(filepath.Clean(dir)); err != nil {
+		return fmt.Errorf("failed to watch reloadable config: %w", err)
+	}
+
+	r.reloadAndLog(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case <-sighup:
+			log.Ctx(ctx).Info().Str("path", r.path).Msg("received SIGHUP, reloading config")
+			r.reloadAndLog(ctx)
+
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			name := filepath.Base(event.Name)
+			if name == file || name == "..data" {
+				r.reloadAndLog(ctx)
+			}
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			log.Ctx(ctx).Warn().Err(err).Str("path", r.path).Msg("error watching reloadable config")
+		}
+	}
+}
+
+func (r *configReloader) reloadAndLog(ctx context.Context) {
+	if err := r.Reload(ctx); err != nil {
+		configReloadsCounter.WithLabelValues("failure").Inc()
+		log.Ctx(ctx).Error().Err(err).Str("path", r.path).Msg("failed to reload config, keeping the current settings")
+		return
+	}
+	configReloadsCounter.WithLabelValues("success").Inc()
+}
+
+// Reload reads, validates and applies the reloadable settings from the file.
+func (r *configReloader) Reload(ctx context.Context) error {
+	values, err := godotenv.Read(r.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			log.Ctx(ctx).Debug().Str("path", r.path).Msg("reloadable config does not exist")
+			return nil
+		}
+		return fmt.Errorf("failed to read reloadable config: %w", err)
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	settings := make(map[string]string, len(r.initial))
+	for key, value := range r.initial {
+		settings[key] = value
+	}
+	for key, value := range values {
+		if _, ok := r.initial[key]; ok {
+			settings[key] = value
+		} else if strings.HasPrefix(key, "SPICEDB_") {
+			log.Ctx(ctx).Warn().Str("setting", key).Msg("setting cannot be changed without a restart, ignoring")
+		}
+	}
+
+	changes, err := r.validate(settings)
+	if err != nil {
+		return err
+	}
+
+	// The revision quantization is validated by the datastore when set, so it is set first to
+	// leave every setting unchanged if it is invalid.
+	if quantization, ok := changes[reloadKeyRevisionQuantization]; ok {
+		if err := quantization(); err != nil {
+			return fmt.Errorf("invalid %s: %w", reloadKeyRevisionQuantization, err)
+		}
+		delete(changes, reloadKeyRevisionQuantization)
+	}
+	for _, apply := range changes {
+		_ = apply()
+	}
+
+	for key, value := range settings {
+		if r.applied[key] != value {
+			log.Ctx(ctx).Info().Str("setting", key).Str("from", r.applied[key]).Str("to", value).Msg("reloaded setting")
+		}
+	}
+	r.applied = settings
+	return nil
+}
+
+// validate parses the settings which differ from those applied, returning the functions
+// applying them.
+func (r *configReloader) validate(settings map[string]string) (map[string]func() error, error) {
+	changes := map[string]func() error{}
+	for key, value := range settings {
+		if r.applied[key] == value {
+			continue
+		}
+
+		switch {
+		case key == reloadKeyLogLevel:
+			level, err := zerolog.ParseLevel(strings.ToLower(value))
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s: %w", key, err)
+			}
+			changes[key] = func() error {
+				log.SetLevel(level)
+				return nil
+			}
+
+		case key == reloadKeyAdaptiveConcurrencyLimit:
+			if r.limiter == nil {
+				return nil, fmt.Errorf("cannot change %s: adaptive dispatch concurrency is disabled", key)
+			}
+			limit, err := strconv.ParseUint(value, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s: %w", key, err)
+			}
+			changes[key] = func() error {
+				r.limiter.SetMaxLimit(uint32(limit))
+				return nil
+			}
+
+		case key == reloadKeyRevisionQuantization:
+			if r.quantizable == nil {
+				return nil, fmt.Errorf("cannot change %s: the datastore does not support it", key)
+			}
+			quantization, err := time.ParseDuration(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s: %w", key, err)
+			}
+			changes[key] = func() error {
+				return r.quantizable.SetRevisionQuantization(quantization)
+			}
+
+		default:
+			resizable, ok := r.caches[key]
+			if !ok {
+				return nil, fmt.Errorf("cannot change %s: the cache is disabled", key)
+			}
+			maxCost, err := parseMaxCost(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s: %w", key, err)
+			}
+			if maxCost <= 0 {
+				return nil, fmt.Errorf("invalid %s: the cache cannot be disabled without a restart", key)
+			}
+			changes[key] = func() error {
+				resizable.SetMaxCost(maxCost)
+				return nil
+			}
+		}
+	}
+	return changes, nil
+}
@@ -0,0 +1,120 @@
+package server
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/internal/taskrunner"
+	"github.com/authzed/spicedb/pkg/cache"
+)
+
+type fakeResizableCache struct {
+	maxCost int64
+}
+
+func (c *fakeResizableCache) SetMaxCost(maxCost int64) {
+	c.maxCost = maxCost
+}
+
+// useInfoLogger sets an info-level global logger for the test, since the reloader makes the level
+// of the global logger changeable.
+func useInfoLogger(t *testing.T) {
+	previousLogger, previousLevel := log.Logger, zerolog.GlobalLevel()
+	t.Cleanup(func() {
+		log.SetGlobalLogger(previousLogger)
+		zerolog.SetGlobalLevel(previousLevel)
+	})
+	log.SetGlobalLogger(zerolog.New(io.Discard).Level(zerolog.InfoLevel))
+	zerolog.SetGlobalLevel(zerolog.TraceLevel)
+}
+
+func TestConfigReloader(t *testing.T) {
+	useInfoLogger(t)
+
+	ds, err := memdb.NewMemdbDatastore(0, 5*time.Second, time.Hour)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ds.Close() })
+
+	path := filepath.Join(t.TempDir(), "reload.env")
+	writeConfig := func(content string) {
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+
+	dispatchCache := &fakeResizableCache{}
+	limiter := taskrunner.NewAdaptiveLimiter(taskrunner.AdaptiveLimiterConfig{MaxLimit: 100})
+
+	reloader := newConfigReloader(path)
+	reloader.setDatastore(ds, 5*time.Second)
+	reloader.addCache("dispatch-cache", &CacheConfig{MaxCost: "1MiB"}, dispatchCache)
+	reloader.addCache("ns-cache", &CacheConfig{MaxCost: "1MiB"}, cache.NoopCache[cache.StringKey, any]())
+	reloader.setAdaptiveLimiter(limiter, 100)
+
+	// A missing file leaves the settings unchanged.
+	require.NoError(t, reloader.Reload(context.Background()))
+
+	writeConfig(`
+SPICEDB_LOG_LEVEL=debug
+SPICEDB_DISPATCH_CACHE_MAX_COST=2MiB
+SPICEDB_DISPATCH_ADAPTIVE_CONCURRENCY_LIMIT=10
+SPICEDB_DATASTORE_REVISION_QUANTIZATION_INTERVAL=1s
+SPICEDB_GRPC_PRESHARED_KEY=ignored
+`)
+	require.NoError(t, reloader.Reload(context.Background()))
+	require.Equal(t, zerolog.DebugLevel, zerolog.GlobalLevel())
+	require.Equal(t, int64(2*1024*1024), dispatchCache.maxCost)
+	require.Equal(t, uint32(10), limiter.Limit())
+
+	// Reloads are atomic: an invalid setting leaves all settings unchanged.
+	for _, invalid := range []string{
+		"SPICEDB_DISPATCH_CACHE_MAX_COST=lots",
+		"SPICEDB_NS_CACHE_MAX_COST=2MiB",
+		"SPICEDB_DATASTORE_REVISION_QUANTIZATION_INTERVAL=2h",
+	} {
+		writeConfig("SPICEDB_LOG_LEVEL=warn\nSPICEDB_DISPATCH_ADAPTIVE_CONCURRENCY_LIMIT=5\n" + invalid + "\n")
+		require.Error(t, reloader.Reload(context.Background()), invalid)
+		require.Equal(t, zerolog.DebugLevel, zerolog.GlobalLevel())
+		require.Equal(t, int64(2*1024*1024), dispatchCache.maxCost)
+		require.Equal(t, uint32(10), limiter.Limit())
+	}
+
+	// Settings removed from the file revert to their initial values.
+	writeConfig("SPICEDB_DISPATCH_ADAPTIVE_CONCURRENCY_LIMIT=5\n")
+	require.NoError(t, reloader.Reload(context.Background()))
+	require.Equal(t, zerolog.InfoLevel, zerolog.GlobalLevel())
+	require.Equal(t, int64(1024*1024), dispatchCache.maxCost)
+	require.Equal(t, uint32(5), limiter.Limit())
+}
+
+func TestConfigReloaderRun(t *testing.T) {
+	useInfoLogger(t)
+
+	path := filepath.Join(t.TempDir(), "reload.env")
+	require.NoError(t, os.WriteFile(path, []byte("SPICEDB_LOG_LEVEL=warn\n"), 0o600))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- newConfigReloader(path).Run(ctx)
+	}()
+
+	require.Eventually(t, func() bool {
+		return zerolog.GlobalLevel() == zerolog.WarnLevel
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, os.WriteFile(path, []byte("SPICEDB_LOG_LEVEL=error\n"), 0o600))
+	require.Eventually(t, func() bool {
+		return zerolog.GlobalLevel() == zerolog.ErrorLevel
+	}, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-done)
+}
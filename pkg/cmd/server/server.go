@@ -2,19 +2,23 @@ package server
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/authzed/consistent"
 	"github.com/authzed/grpcutil"
 	"github.com/cespare/xxhash/v2"
 	"github.com/ecordell/optgen/helpers"
+	authv3 "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
 	grpc_auth "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/auth"
 	"github.com/hashicorp/go-multierror"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/rs/cors"
 	"github.com/rs/zerolog"
@@ -25,31 +29,56 @@ import (
 	_ "google.golang.org/grpc/encoding/gzip" // enable gzip compression on all derivative servers
 
 	"github.com/authzed/spicedb/internal/auth"
+	"github.com/authzed/spicedb/internal/compression"
 	"github.com/authzed/spicedb/internal/datastore/proxy"
 	"github.com/authzed/spicedb/internal/datastore/proxy/schemacaching"
+	"github.com/authzed/spicedb/internal/diagnostics"
 	"github.com/authzed/spicedb/internal/dispatch"
 	clusterdispatch "github.com/authzed/spicedb/internal/dispatch/cluster"
 	combineddispatch "github.com/authzed/spicedb/internal/dispatch/combined"
+	"github.com/authzed/spicedb/internal/dispatch/flattened"
 	"github.com/authzed/spicedb/internal/dispatch/graph"
+	"github.com/authzed/spicedb/internal/dispatch/inflight"
 	"github.com/authzed/spicedb/internal/dispatch/keys"
+	"github.com/authzed/spicedb/internal/dispatch/limits"
+	"github.com/authzed/spicedb/internal/dispatch/prioritized"
+	"github.com/authzed/spicedb/internal/dispatch/remote"
 	"github.com/authzed/spicedb/internal/gateway"
+	maingraph "github.com/authzed/spicedb/internal/graph"
 	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/internal/middleware/capture"
+	"github.com/authzed/spicedb/internal/middleware/metering"
+	"github.com/authzed/spicedb/internal/scim"
 	"github.com/authzed/spicedb/internal/services"
 	dispatchSvc "github.com/authzed/spicedb/internal/services/dispatch"
+	dispatchSvcV1 "github.com/authzed/spicedb/internal/services/dispatch/v1"
+	"github.com/authzed/spicedb/internal/services/extauthz"
 	"github.com/authzed/spicedb/internal/services/health"
+	"github.com/authzed/spicedb/internal/services/openfga"
 	v1svc "github.com/authzed/spicedb/internal/services/v1"
+	"github.com/authzed/spicedb/internal/taskrunner"
 	"github.com/authzed/spicedb/internal/telemetry"
 	"github.com/authzed/spicedb/pkg/cache"
 	datastorecfg "github.com/authzed/spicedb/pkg/cmd/datastore"
 	"github.com/authzed/spicedb/pkg/cmd/util"
 	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/fips"
+	"github.com/authzed/spicedb/pkg/middleware/priority"
 	"github.com/authzed/spicedb/pkg/middleware/requestid"
+	forksv1 "github.com/authzed/spicedb/pkg/proto/forks/v1"
+	lookupjobsv1 "github.com/authzed/spicedb/pkg/proto/lookupjobs/v1"
+	reconciliationv1 "github.com/authzed/spicedb/pkg/proto/reconciliation/v1"
 	"github.com/authzed/spicedb/pkg/spiceerrors"
 )
 
 // ConsistentHashringBuilder is a balancer Builder that uses xxhash as the
-// underlying hash for the ConsistentHashringBalancers it creates.
-var ConsistentHashringBuilder = consistent.NewBuilder(xxhash.Sum64)
+// underlying hash for the ConsistentHashringBalancers it creates, and routes
+// hedged dispatches to another member of the hashring.
+var ConsistentHashringBuilder = remote.NewHashringBuilder(xxhash.Sum64)
+
+// chunkedWriteRecoveryInterval is how often the server rolls back the chunked writes abandoned by
+// failed nodes.
+const chunkedWriteRecoveryInterval = time.Minute
 
 //go:generate go run github.com/ecordell/optgen -output zz_generated.options.go . Config
 type Config struct {
@@ -60,6 +89,9 @@ type Config struct {
 	ShutdownGracePeriod    time.Duration         `debugmap:"visible"`
 	DisableVersionResponse bool                  `debugmap:"visible"`
 	ServerName             string                `debugmap:"visible"`
+	GRPCCompressors        []string              `debugmap:"visible-format"`
+	RequireFIPSMode        bool                  `debugmap:"visible"`
+	GRPCAdminServer        util.GRPCServerConfig `debugmap:"visible"`
 
 	// GRPC Gateway config
 	HTTPGateway                    util.HTTPServerConfig `debugmap:"visible"`
@@ -67,6 +99,10 @@ type Config struct {
 	HTTPGatewayUpstreamTLSCertPath string                `debugmap:"visible"`
 	HTTPGatewayCorsEnabled         bool                  `debugmap:"visible"`
 	HTTPGatewayCorsAllowedOrigins  []string              `debugmap:"visible-format"`
+	HTTPGatewayCompression         []string              `debugmap:"visible-format"`
+	HTTPGatewayGraphQLEnabled      bool                  `debugmap:"visible"`
+	HTTPGatewayOPADataConfigPath   string                `debugmap:"visible"`
+	HTTPGatewaySCIMMappingPath     string                `debugmap:"visible"`
 
 	// Datastore
 	DatastoreConfig datastorecfg.Config `debugmap:"visible"`
@@ -76,6 +112,11 @@ type Config struct {
 	MaxCaveatContextSize       int `debugmap:"visible" default:"4096"`
 	MaxRelationshipContextSize int `debugmap:"visible" default:"25_000"`
 
+	// Datastore revision freshness
+	RevisionFreshnessCheckInterval time.Duration `debugmap:"visible"`
+	MaxRevisionStaleness           time.Duration `debugmap:"visible"`
+	MaxReplicaLag                  time.Duration `debugmap:"visible"`
+
 	// Namespace cache
 	EnableExperimentalWatchableSchemaCache bool          `debugmap:"visible"`
 	SchemaWatchHeartbeat                   time.Duration `debugmap:"visible"`
@@ -100,17 +141,43 @@ type Config struct {
 	DispatchHashringReplicationFactor uint16                  `debugmap:"visible"`
 	DispatchHashringSpread            uint8                   `debugmap:"visible"`
 	DispatchChunkSize                 uint16                  `debugmap:"visible" default:"100"`
+	DispatchHedgingEnabled            bool                    `debugmap:"visible"`
+	DispatchHedgingInitialDelay       time.Duration           `debugmap:"visible"`
+	DispatchHedgingQuantile           float64                 `debugmap:"visible"`
+	DispatchCacheWarmingEntries       uint32                  `debugmap:"visible"`
+	DispatchCacheWarmingTimeout       time.Duration           `debugmap:"visible"`
+
+	DispatchMaxDepthOverrides map[string]string `debugmap:"visible"`
+	DispatchTimeoutOverrides  map[string]string `debugmap:"visible"`
+
+	DispatchFlattenedGroupRelations        []string `debugmap:"visible"`
+	DispatchFlattenedGroupMaxRelationships uint32   `debugmap:"visible"`
+
+	DispatchAdaptiveConcurrencyEnabled          bool          `debugmap:"visible"`
+	DispatchAdaptiveConcurrencyLimit            uint32        `debugmap:"visible"`
+	DispatchAdaptiveConcurrencyLatencyThreshold time.Duration `debugmap:"visible"`
+
+	DispatchCheckPlannerEnabled bool `debugmap:"visible"`
+
+	DispatchCanaryEngine      string  `debugmap:"visible"`
+	DispatchCanaryPercent     float64 `debugmap:"visible"`
+	DispatchCanaryMaxInFlight uint16  `debugmap:"visible"`
+
+	DispatchPriorityLimits prioritized.Limits `debugmap:"visible"`
 
 	DispatchSecondaryUpstreamAddrs map[string]string `debugmap:"visible"`
 	DispatchSecondaryUpstreamExprs map[string]string `debugmap:"visible"`
 
-	DispatchCacheConfig        CacheConfig `debugmap:"visible"`
-	ClusterDispatchCacheConfig CacheConfig `debugmap:"visible"`
+	DispatchCacheConfig                CacheConfig `debugmap:"visible"`
+	ClusterDispatchCacheConfig         CacheConfig `debugmap:"visible"`
+	DispatchNegativeCacheConfig        CacheConfig `debugmap:"visible"`
+	ClusterDispatchNegativeCacheConfig CacheConfig `debugmap:"visible"`
 
 	// API Behavior
 	DisableV1SchemaAPI                       bool          `debugmap:"visible"`
 	V1SchemaAdditiveOnly                     bool          `debugmap:"visible"`
 	MaximumUpdatesPerWrite                   uint16        `debugmap:"visible"`
+	MaximumUpdatesPerChunkedWrite            uint32        `debugmap:"visible"`
 	MaximumPreconditionCount                 uint16        `debugmap:"visible"`
 	MaxDatastoreReadPageSize                 uint64        `debugmap:"visible"`
 	StreamingAPITimeout                      time.Duration `debugmap:"visible"`
@@ -119,11 +186,31 @@ type Config struct {
 	MaxDeleteRelationshipsLimit              uint32        `debugmap:"visible"`
 	MaxLookupResourcesLimit                  uint32        `debugmap:"visible"`
 	MaxBulkExportRelationshipsLimit          uint32        `debugmap:"visible"`
+	EnableWriteProvenanceMetadata            bool          `debugmap:"visible"`
+	WriteIdempotencyKeyTTL                   time.Duration `debugmap:"visible"`
+	WriteIdempotencyMaxKeys                  uint32        `debugmap:"visible"`
 	EnableExperimentalLookupResources        bool          `debugmap:"visible"`
 	EnableExperimentalRelationshipExpiration bool          `debugmap:"visible"`
+	EnableExperimentalCaveatPrefiltering     bool          `debugmap:"visible"`
+	EnableExperimentalLookupJobs             bool          `debugmap:"visible"`
+	LookupJobsDirectory                      string        `debugmap:"visible"`
+	LookupJobsRetention                      time.Duration `debugmap:"visible"`
+	LookupJobsMaxRunning                     uint16        `debugmap:"visible"`
+	EnableExperimentalForks                  bool          `debugmap:"visible"`
+	ForksMaxTTL                              time.Duration `debugmap:"visible"`
+	ForksMaxCount                            uint16        `debugmap:"visible"`
+	EnableExperimentalReconciliation         bool          `debugmap:"visible"`
+
+	// Deletion protection
+	DeletionProtectionMaxDeletedRelationships uint64   `debugmap:"visible"`
+	DeletionProtectionObjectTypes             []string `debugmap:"visible-format"`
+	DeletionProtectionAdminToken              string   `debugmap:"sensitive"`
 
 	// Additional Services
-	MetricsAPI util.HTTPServerConfig `debugmap:"visible"`
+	MetricsAPI        util.HTTPServerConfig `debugmap:"visible"`
+	OpenFGAAPIEnabled bool                  `debugmap:"visible"`
+	OpenFGAStoreID    string                `debugmap:"visible"`
+	ExtAuthzRulesPath string                `debugmap:"visible"`
 
 	// Middleware for grpc API
 	UnaryMiddlewareModification     []MiddlewareModification[grpc.UnaryServerInterceptor]  `debugmap:"hidden"`
@@ -133,6 +220,9 @@ type Config struct {
 	DispatchUnaryMiddleware     []grpc.UnaryServerInterceptor  `debugmap:"hidden"`
 	DispatchStreamingMiddleware []grpc.StreamServerInterceptor `debugmap:"hidden"`
 
+	// Reloadable configuration
+	ConfigReloadPath string `debugmap:"visible"`
+
 	// Telemetry
 	SilentlyDisableTelemetry bool          `debugmap:"visible"`
 	TelemetryCAOverridePath  string        `debugmap:"visible"`
@@ -145,6 +235,22 @@ type Config struct {
 
 	// Metrics
 	DisableGRPCLatencyHistogram bool `debugmap:"visible"`
+
+	// Usage metering
+	EnableUsageMetering                    bool   `debugmap:"visible"`
+	UsageMeteringMonthlyAPICallQuota       uint64 `debugmap:"visible"`
+	UsageMeteringMonthlyDispatchQuota      uint64 `debugmap:"visible"`
+	UsageMeteringMonthlyRelationshipsQuota uint64 `debugmap:"visible"`
+
+	// Request capture
+	RequestCaptureFile           string  `debugmap:"visible"`
+	RequestCaptureSampleRate     float64 `debugmap:"visible"`
+	RequestCaptureMaxRecords     uint64  `debugmap:"visible"`
+	RequestCaptureDisableHashing bool    `debugmap:"visible"`
+
+	// Diagnostics
+	DiagnosticsAPI        util.HTTPServerConfig `debugmap:"visible"`
+	DiagnosticsAdminToken string                `debugmap:"sensitive"`
 }
 
 type closeableStack struct {
@@ -201,6 +307,19 @@ func (c *Config) Complete(ctx context.Context) (RunnableServer, error) {
 		}
 	}()
 
+	if err := compression.ValidateGRPCCompressors(c.GRPCCompressors); err != nil {
+		return nil, fmt.Errorf("failed to configure gRPC compressors: %w", err)
+	}
+
+	if c.RequireFIPSMode {
+		if err := fips.Require(); err != nil {
+			return nil, err
+		}
+	}
+	if fips.Enabled() {
+		log.Ctx(ctx).Info().Str("mode", fips.Mode()).Msg("running in FIPS mode")
+	}
+
 	if len(c.PresharedSecureKey) < 1 && c.GRPCAuthFunc == nil {
 		return nil, fmt.Errorf("a preshared key must be provided to authenticate API requests")
 	}
@@ -238,10 +357,17 @@ func (c *Config) Complete(ctx context.Context) (RunnableServer, error) {
 	}
 	closeables.AddWithError(ds.Close)
 
+	var reloader *configReloader
+	if c.ConfigReloadPath != "" {
+		reloader = newConfigReloader(c.ConfigReloadPath)
+	}
+	reloader.setDatastore(ds, c.DatastoreConfig.RevisionQuantization)
+
 	nscc, err := CompleteCache[cache.StringKey, schemacaching.CacheEntry](&c.NamespaceCacheConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create namespace cache: %w", err)
 	}
+	reloader.addCache("ns-cache", &c.NamespaceCacheConfig, nscc)
 	log.Ctx(ctx).Info().EmbedObject(nscc).Msg("configured namespace cache")
 
 	cachingMode := schemacaching.JustInTimeCaching
@@ -249,13 +375,70 @@ func (c *Config) Complete(ctx context.Context) (RunnableServer, error) {
 		cachingMode = schemacaching.WatchIfSupported
 	}
 
+	// The fault injector, write degradation and replicas, if any, are found before the datastore is wrapped in proxies which cannot be unwrapped.
+	faultInjectingDS := datastore.UnwrapAs[proxy.FaultInjectingDatastore](ds)
+	writeDegradingDS := datastore.UnwrapAs[proxy.WriteDegradingDatastore](ds)
+
+	var replicas []datastore.ReadOnlyDatastore
+	if replicatedDS := datastore.UnwrapAs[proxy.ReplicatedDatastore](ds); replicatedDS != nil {
+		replicas = replicatedDS.Replicas()
+	}
+
 	ds = proxy.NewObservableDatastoreProxy(ds)
 	ds = proxy.NewSingleflightDatastoreProxy(ds)
+
+	deletionProtection := proxy.DeletionProtectionConfig{
+		MaxDeletedRelationships: c.DeletionProtectionMaxDeletedRelationships,
+		ProtectedObjectTypes:    c.DeletionProtectionObjectTypes,
+		AdminToken:              c.DeletionProtectionAdminToken,
+	}
+	if deletionProtection.Enabled() {
+		ds = proxy.NewDeletionProtectionProxy(ds, deletionProtection)
+	}
+
 	ds = schemacaching.NewCachingDatastoreProxy(ds, nscc, c.DatastoreConfig.GCWindow, cachingMode, c.SchemaWatchHeartbeat)
 	closeables.AddWithError(ds.Close)
 
 	specificConcurrencyLimits := c.DispatchConcurrencyLimits
 	concurrencyLimits := specificConcurrencyLimits.WithOverallDefaultLimit(c.GlobalDispatchConcurrencyLimit)
+	if c.DispatchAdaptiveConcurrencyEnabled {
+		concurrencyLimits.Adaptive = taskrunner.NewAdaptiveLimiter(taskrunner.AdaptiveLimiterConfig{
+			MaxLimit:         c.DispatchAdaptiveConcurrencyLimit,
+			LatencyThreshold: c.DispatchAdaptiveConcurrencyLatencyThreshold,
+		})
+		reloader.setAdaptiveLimiter(concurrencyLimits.Adaptive, c.DispatchAdaptiveConcurrencyLimit)
+	}
+	if c.DispatchCheckPlannerEnabled {
+		concurrencyLimits.Planner = maingraph.NewEvaluationPlanner()
+	}
+
+	if err := c.validateDispatchCanary(); err != nil {
+		return nil, err
+	}
+
+	var flattenedMemberships *flattened.Memberships
+	if len(c.DispatchFlattenedGroupRelations) > 0 {
+		relations, err := flattened.ParseRelations(c.DispatchFlattenedGroupRelations)
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure flattened group memberships: %w", err)
+		}
+
+		flattenedMemberships = flattened.NewMemberships(ds, relations, c.WatchHeartbeat, int(c.DispatchFlattenedGroupMaxRelationships))
+		closeables.AddWithError(flattenedMemberships.Close)
+		log.Ctx(ctx).Info().Strs("relations", c.DispatchFlattenedGroupRelations).Msg("configured flattened group memberships")
+	}
+
+	// With the flattened groups canary engine, only the canary dispatcher uses the flattened group
+	// memberships.
+	var canaryFlattenedMemberships *flattened.Memberships
+	if c.DispatchCanaryEngine == DispatchCanaryEngineFlattenedGroups {
+		canaryFlattenedMemberships, flattenedMemberships = flattenedMemberships, nil
+	}
+
+	typeLimits, err := limits.ParseLimits(c.DispatchMaxDepthOverrides, c.DispatchTimeoutOverrides)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse dispatch limit overrides: %w", err)
+	}
 
 	dispatcher := c.Dispatcher
 	if dispatcher == nil {
@@ -263,12 +446,27 @@ func (c *Config) Complete(ctx context.Context) (RunnableServer, error) {
 			c.DatastoreConfig.RevisionQuantization,
 			c.DatastoreConfig.FollowerReadDelay,
 			c.DatastoreConfig.MaxRevisionStalenessPercent,
+			c.DatastoreConfig.RevisionQuantizationJitter,
 		))
 		if err != nil {
 			return nil, fmt.Errorf("failed to create dispatcher: %w", err)
 		}
 		closeables.AddWithoutError(cc.Close)
 		log.Ctx(ctx).Info().EmbedObject(cc).Msg("configured dispatch cache")
+		reloader.addCache("dispatch-cache", &c.DispatchCacheConfig, cc)
+
+		ncc, err := CompleteCache[keys.DispatchCacheKey, any](c.DispatchNegativeCacheConfig.WithRevisionParameters(
+			c.DatastoreConfig.RevisionQuantization,
+			c.DatastoreConfig.FollowerReadDelay,
+			c.DatastoreConfig.MaxRevisionStalenessPercent,
+			c.DatastoreConfig.RevisionQuantizationJitter,
+		))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create dispatcher: %w", err)
+		}
+		closeables.AddWithoutError(ncc.Close)
+		log.Ctx(ctx).Info().EmbedObject(ncc).Msg("configured dispatch negative cache")
+		reloader.addCache("dispatch-negative-cache", &c.DispatchNegativeCacheConfig, ncc)
 
 		dispatchPresharedKey := ""
 		if len(c.PresharedSecureKey) > 0 {
@@ -283,7 +481,7 @@ func (c *Config) Complete(ctx context.Context) (RunnableServer, error) {
 			return nil, fmt.Errorf("failed to create gRPC hashring balancer config: %w", err)
 		}
 
-		dispatcher, err = combineddispatch.NewDispatcher(
+		dispatcherOptions := []combineddispatch.Option{
 			combineddispatch.UpstreamAddr(c.DispatchUpstreamAddr),
 			combineddispatch.UpstreamCAPath(c.DispatchUpstreamCAPath),
 			combineddispatch.SecondaryUpstreamAddrs(c.DispatchSecondaryUpstreamAddrs),
@@ -294,9 +492,11 @@ func (c *Config) Complete(ctx context.Context) (RunnableServer, error) {
 				grpc.WithDefaultServiceConfig(hashringConfigJSON),
 				grpc.WithChainUnaryInterceptor(
 					requestid.UnaryClientInterceptor(),
+					priority.UnaryClientInterceptor(),
 				),
 				grpc.WithChainStreamInterceptor(
 					requestid.StreamClientInterceptor(),
+					priority.StreamClientInterceptor(),
 				),
 			),
 			combineddispatch.MetricsEnabled(c.DispatchClientMetricsEnabled),
@@ -304,7 +504,24 @@ func (c *Config) Complete(ctx context.Context) (RunnableServer, error) {
 			combineddispatch.Cache(cc),
 			combineddispatch.ConcurrencyLimits(concurrencyLimits),
 			combineddispatch.DispatchChunkSize(c.DispatchChunkSize),
-		)
+		}
+		if c.DispatchNegativeCacheConfig.Enabled {
+			dispatcherOptions = append(dispatcherOptions, combineddispatch.NegativeCheckCache(ncc))
+		}
+		if c.DispatchHedgingEnabled {
+			dispatcherOptions = append(dispatcherOptions, combineddispatch.RemoteDispatchHedging(c.DispatchHedgingInitialDelay, c.DispatchHedgingQuantile))
+		}
+		if flattenedMemberships != nil {
+			dispatcherOptions = append(dispatcherOptions, combineddispatch.FlattenedMemberships(flattenedMemberships))
+		}
+		if len(typeLimits) > 0 {
+			dispatcherOptions = append(dispatcherOptions, combineddispatch.TypeLimits(typeLimits))
+		}
+		if c.DispatchCacheWarmingEntries > 0 {
+			dispatcherOptions = append(dispatcherOptions, combineddispatch.CacheWarming(c.DispatchCacheWarmingEntries, c.DispatchCacheWarmingTimeout, ds))
+		}
+
+		dispatcher, err = combineddispatch.NewDispatcher(dispatcherOptions...)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create dispatcher: %w", err)
 		}
@@ -327,31 +544,63 @@ func (c *Config) Complete(ctx context.Context) (RunnableServer, error) {
 			c.DatastoreConfig.RevisionQuantization,
 			c.DatastoreConfig.FollowerReadDelay,
 			c.DatastoreConfig.MaxRevisionStalenessPercent,
+			c.DatastoreConfig.RevisionQuantizationJitter,
 		))
 		if err != nil {
 			return nil, fmt.Errorf("failed to configure cluster dispatch: %w", err)
 		}
 		log.Ctx(ctx).Info().EmbedObject(cdcc).Msg("configured cluster dispatch cache")
 		closeables.AddWithoutError(cdcc.Close)
+		reloader.addCache("dispatch-cluster-cache", &c.ClusterDispatchCacheConfig, cdcc)
 
-		cachingClusterDispatch, err = clusterdispatch.NewClusterDispatcher(
-			dispatcher,
+		cdncc, err := CompleteCache[keys.DispatchCacheKey, any](c.ClusterDispatchNegativeCacheConfig.WithRevisionParameters(
+			c.DatastoreConfig.RevisionQuantization,
+			c.DatastoreConfig.FollowerReadDelay,
+			c.DatastoreConfig.MaxRevisionStalenessPercent,
+			c.DatastoreConfig.RevisionQuantizationJitter,
+		))
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure cluster dispatch: %w", err)
+		}
+		log.Ctx(ctx).Info().EmbedObject(cdncc).Msg("configured cluster dispatch negative cache")
+		closeables.AddWithoutError(cdncc.Close)
+		reloader.addCache("dispatch-cluster-negative-cache", &c.ClusterDispatchNegativeCacheConfig, cdncc)
+
+		clusterDispatcherOptions := []clusterdispatch.Option{
 			clusterdispatch.MetricsEnabled(c.DispatchClusterMetricsEnabled),
 			clusterdispatch.PrometheusSubsystem(c.DispatchClusterMetricsPrefix),
 			clusterdispatch.Cache(cdcc),
 			clusterdispatch.RemoteDispatchTimeout(c.DispatchUpstreamTimeout),
 			clusterdispatch.ConcurrencyLimits(concurrencyLimits),
 			clusterdispatch.DispatchChunkSize(c.DispatchChunkSize),
-		)
+		}
+		if c.ClusterDispatchNegativeCacheConfig.Enabled {
+			clusterDispatcherOptions = append(clusterDispatcherOptions, clusterdispatch.NegativeCheckCache(cdncc))
+		}
+		if flattenedMemberships != nil {
+			clusterDispatcherOptions = append(clusterDispatcherOptions, clusterdispatch.FlattenedMemberships(flattenedMemberships))
+		}
+		if len(typeLimits) > 0 {
+			clusterDispatcherOptions = append(clusterDispatcherOptions, clusterdispatch.TypeLimits(typeLimits))
+		}
+
+		cachingClusterDispatch, err = clusterdispatch.NewClusterDispatcher(dispatcher, clusterDispatcherOptions...)
 		if err != nil {
 			return nil, fmt.Errorf("failed to configure cluster dispatch: %w", err)
 		}
 		closeables.AddWithError(cachingClusterDispatch.Close)
 	}
 
+	// Peers warm their dispatch cache from the hottest entries of the cache of this node, when
+	// cache warming is enabled.
+	var hotCacheEntries dispatchSvcV1.HotCacheEntrySource
+	if source, ok := dispatcher.(dispatchSvcV1.HotCacheEntrySource); ok && c.DispatchCacheWarmingEntries > 0 {
+		hotCacheEntries = source
+	}
+
 	dispatchGrpcServer, err := c.DispatchServer.Complete(zerolog.InfoLevel,
 		func(server *grpc.Server) {
-			dispatchSvc.RegisterGrpcServices(server, cachingClusterDispatch)
+			dispatchSvc.RegisterGrpcServices(server, cachingClusterDispatch, hotCacheEntries)
 		},
 		grpc.ChainUnaryInterceptor(c.DispatchUnaryMiddleware...),
 		grpc.ChainStreamInterceptor(c.DispatchStreamingMiddleware...),
@@ -362,6 +611,29 @@ func (c *Config) Complete(ctx context.Context) (RunnableServer, error) {
 	}
 	closeables.AddWithoutError(dispatchGrpcServer.GracefulStop)
 
+	// Only the checks of the API are evaluated by the canary dispatcher, not those dispatched by
+	// peers, so that each check is sampled once.
+	if c.DispatchCanaryEngine != "" {
+		canaryDispatcher, err := c.newCanaryDispatcher(concurrencyLimits, canaryFlattenedMemberships, typeLimits)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create dispatch canary: %w", err)
+		}
+		// The primary dispatcher is already registered to be closed, so the canary dispatcher is
+		// registered on its own rather than through the wrapper, which closes both.
+		closeables.AddWithError(canaryDispatcher.Close)
+
+		dispatcher = c.withDispatchCanary(dispatcher, canaryDispatcher)
+		log.Ctx(ctx).Info().Str("engine", c.DispatchCanaryEngine).Float64("percent", c.DispatchCanaryPercent).Msg("configured dispatch canary")
+	}
+
+	// Only the dispatches of the API are bounded by priority class, and not the subproblems they
+	// redispatch nor those dispatched by peers, which would otherwise wait for slots held by the
+	// dispatches waiting for them.
+	if c.DispatchPriorityLimits.Enabled() {
+		dispatcher = prioritized.NewDispatcher(dispatcher, c.DispatchPriorityLimits)
+		log.Ctx(ctx).Info().Uint16("batch", c.DispatchPriorityLimits.Batch).Uint16("background", c.DispatchPriorityLimits.Background).Msg("configured dispatch priority limits")
+	}
+
 	datastoreFeatures, err := ds.Features(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("error determining datastore features: %w", err)
@@ -409,6 +681,66 @@ func (c *Config) Complete(ctx context.Context) (RunnableServer, error) {
 		return nil, fmt.Errorf("error building default middlewares: %w", err)
 	}
 
+	var meter *metering.Meter
+	if c.EnableUsageMetering {
+		meter = metering.NewMeter(metering.Quota{
+			APICalls:              c.UsageMeteringMonthlyAPICallQuota,
+			DispatchedSubproblems: c.UsageMeteringMonthlyDispatchQuota,
+			RelationshipsRead:     c.UsageMeteringMonthlyRelationshipsQuota,
+		})
+
+		if err := addMeteringMiddleware(defaultUnaryMiddlewareChain, defaultStreamingMiddlewareChain, meter); err != nil {
+			return nil, fmt.Errorf("error building default middlewares: %w", err)
+		}
+	} else if c.UsageMeteringMonthlyAPICallQuota > 0 || c.UsageMeteringMonthlyDispatchQuota > 0 || c.UsageMeteringMonthlyRelationshipsQuota > 0 {
+		return nil, errors.New("usage metering quotas require usage metering to be enabled")
+	}
+
+	// The fork middleware is added even when forks are not enabled, so that requests to forks are
+	// rejected rather than served from the datastore itself.
+	var forks v1svc.ForksServer
+	if c.EnableExperimentalForks {
+		forks = v1svc.NewForksServer(ds, v1svc.ForksConfig{
+			MaxTTL:   c.ForksMaxTTL,
+			MaxForks: c.ForksMaxCount,
+		})
+		closeables.AddCloser(forks)
+	}
+
+	if err := addForkMiddleware(defaultUnaryMiddlewareChain, defaultStreamingMiddlewareChain, forks); err != nil {
+		return nil, fmt.Errorf("error building default middlewares: %w", err)
+	}
+
+	if c.RequestCaptureFile != "" {
+		capturer, err := capture.NewCapturer(capture.Config{
+			File:           c.RequestCaptureFile,
+			SampleRate:     c.RequestCaptureSampleRate,
+			MaxRecords:     c.RequestCaptureMaxRecords,
+			DisableHashing: c.RequestCaptureDisableHashing,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize request capture: %w", err)
+		}
+		closeables.AddCloser(capturer)
+
+		if err := addCaptureMiddleware(defaultUnaryMiddlewareChain, defaultStreamingMiddlewareChain, capturer); err != nil {
+			return nil, fmt.Errorf("error building default middlewares: %w", err)
+		}
+	}
+
+	var diagnosticsHandler http.Handler
+	if c.DiagnosticsAPI.HTTPEnabled {
+		diagnosticsHandler, err = diagnostics.NewHandler(c.DiagnosticsAdminToken, inflight.DefaultTracker)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize diagnostics API: %w", err)
+		}
+
+		if err := addDiagnosticsMiddleware(defaultUnaryMiddlewareChain, defaultStreamingMiddlewareChain); err != nil {
+			return nil, fmt.Errorf("error building default middlewares: %w", err)
+		}
+		inflight.DefaultTracker.Enable()
+	}
+
 	sameMiddlewares := defaultUnaryMiddlewareChain.Names().Equal(defaultStreamingMiddlewareChain.Names())
 	if !sameMiddlewares {
 		return nil, fmt.Errorf("unary and streaming middlewares differ: %v / %v",
@@ -430,6 +762,7 @@ func (c *Config) Complete(ctx context.Context) (RunnableServer, error) {
 	permSysConfig := v1svc.PermissionsServerConfig{
 		MaxPreconditionsCount:           c.MaximumPreconditionCount,
 		MaxUpdatesPerWrite:              c.MaximumUpdatesPerWrite,
+		MaxUpdatesPerChunkedWrite:       c.MaximumUpdatesPerChunkedWrite,
 		MaximumAPIDepth:                 c.DispatchMaxDepth,
 		MaxCaveatContextSize:            c.MaxCaveatContextSize,
 		MaxRelationshipContextSize:      c.MaxRelationshipContextSize,
@@ -441,9 +774,56 @@ func (c *Config) Complete(ctx context.Context) (RunnableServer, error) {
 		MaxBulkExportRelationshipsLimit: c.MaxBulkExportRelationshipsLimit,
 		DispatchChunkSize:               c.DispatchChunkSize,
 		ExpiringRelationshipsEnabled:    c.EnableExperimentalRelationshipExpiration,
+		WriteProvenanceEnabled:          c.EnableWriteProvenanceMetadata,
+		IdempotencyKeyTTL:               c.WriteIdempotencyKeyTTL,
+		MaxIdempotencyKeys:              c.WriteIdempotencyMaxKeys,
+		CaveatPrefilteringEnabled:       c.EnableExperimentalCaveatPrefiltering,
+	}
+
+	var extAuthzRules *extauthz.Rules
+	if c.ExtAuthzRulesPath != "" {
+		extAuthzRules, err = extauthz.LoadRules(c.ExtAuthzRulesPath)
+		if err != nil {
+			return nil, spiceerrors.NewTerminationErrorBuilder(err).
+				Component("ext-authz").
+				ExitCode(sysexits.Config).
+				Error()
+		}
+	}
+
+	var healthOpts []health.Option
+	if c.RevisionFreshnessCheckInterval > 0 {
+		freshnessChecker := health.NewFreshnessChecker(health.FreshnessConfig{
+			CheckInterval:        c.RevisionFreshnessCheckInterval,
+			MaxRevisionStaleness: c.MaxRevisionStaleness,
+			MaxReplicaLag:        c.MaxReplicaLag,
+		}, ds, replicas)
+		healthOpts = append(healthOpts, health.WithFreshnessChecker(freshnessChecker))
+	}
+
+	healthManager := health.NewHealthManager(dispatcher, ds, healthOpts...)
+	if writeDegradingDS != nil {
+		writeDegradingDS.SetDegradationListener(healthManager.SetWritesDegraded)
 	}
 
-	healthManager := health.NewHealthManager(dispatcher, ds)
+	var lookupJobs v1svc.LookupJobsServer
+	if c.EnableExperimentalLookupJobs {
+		lookupJobs = v1svc.NewLookupJobsServer(dispatcher, permSysConfig, v1svc.LookupJobsConfig{
+			Directory:      c.LookupJobsDirectory,
+			Retention:      c.LookupJobsRetention,
+			MaxRunningJobs: c.LookupJobsMaxRunning,
+		})
+		closeables.AddCloser(lookupJobs)
+	}
+
+	// When the admin gRPC server is enabled, the Watch APIs are only served by it, so that they
+	// can be locked down independently of the data-plane APIs.
+	dataPlaneWatchServiceOption := watchServiceOption
+	if c.GRPCAdminServer.Enabled {
+		dataPlaneWatchServiceOption = services.WatchServiceDisabled
+	}
+
+	watchShuttingDown := make(chan struct{})
 	grpcServer, err := c.GRPCServer.Complete(zerolog.InfoLevel,
 		func(server *grpc.Server) {
 			services.RegisterGrpcServices(
@@ -451,17 +831,64 @@ func (c *Config) Complete(ctx context.Context) (RunnableServer, error) {
 				healthManager,
 				dispatcher,
 				v1SchemaServiceOption,
-				watchServiceOption,
+				dataPlaneWatchServiceOption,
 				permSysConfig,
 				c.WatchHeartbeat,
+				watchShuttingDown,
 			)
+
+			if c.OpenFGAAPIEnabled {
+				openfgav1.RegisterOpenFGAServiceServer(server, openfga.NewOpenFGAServer(v1svc.NewPermissionsServer(dispatcher, permSysConfig), c.OpenFGAStoreID))
+			}
+
+			if extAuthzRules != nil {
+				authv3.RegisterAuthorizationServer(server, extauthz.NewAuthorizationServer(v1svc.NewPermissionsServer(dispatcher, permSysConfig), extAuthzRules))
+			}
+
+			if lookupJobs != nil {
+				lookupjobsv1.RegisterLookupJobsServiceServer(server, lookupJobs)
+			}
+
+			if forks != nil {
+				forksv1.RegisterForkServiceServer(server, forks)
+			}
+
+			if c.EnableExperimentalReconciliation {
+				reconciliationv1.RegisterReconciliationServiceServer(server, v1svc.NewReconciliationServer(permSysConfig))
+			}
 		},
+		grpc.ChainUnaryInterceptor(compression.UnaryServerInterceptor(c.GRPCCompressors)),
+		grpc.ChainStreamInterceptor(compression.StreamServerInterceptor(c.GRPCCompressors)),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create gRPC server: %w", err)
 	}
 	closeables.AddWithoutError(grpcServer.GracefulStop)
 
+	adminGRPCServer, err := c.GRPCAdminServer.Complete(zerolog.InfoLevel,
+		func(server *grpc.Server) {
+			services.RegisterAdminGrpcServices(
+				server,
+				healthManager,
+				dispatcher,
+				watchServiceOption,
+				permSysConfig,
+				c.WatchHeartbeat,
+				watchShuttingDown,
+			)
+		},
+		grpc.ChainUnaryInterceptor(compression.UnaryServerInterceptor(c.GRPCCompressors)),
+		grpc.ChainStreamInterceptor(compression.StreamServerInterceptor(c.GRPCCompressors)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create admin gRPC server: %w", err)
+	}
+	closeables.AddWithoutError(adminGRPCServer.GracefulStop)
+
+	// Closers run in reverse order, so watches are ended before the server waits for in-flight
+	// requests to complete, as they would otherwise never complete.
+	closeables.AddWithoutError(sync.OnceFunc(func() { close(watchShuttingDown) }))
+
 	gatewayServer, gatewayCloser, err := c.initializeGateway(ctx)
 	if err != nil {
 		return nil, err
@@ -503,24 +930,53 @@ func (c *Config) Complete(ctx context.Context) (RunnableServer, error) {
 		}
 	}
 
-	metricsServer, err := c.MetricsAPI.Complete(zerolog.InfoLevel, MetricsHandler(telemetryRegistry, c))
+	metricsHandler := MetricsHandler(telemetryRegistry, c)
+	if faultInjectingDS != nil || meter != nil {
+		mux := http.NewServeMux()
+		mux.Handle("/", metricsHandler)
+		if faultInjectingDS != nil {
+			faultInjectorHandler, err := faultInjectingDS.FaultInjector().AuthenticatedHandler(c.PresharedSecureKey)
+			if err != nil {
+				return nil, fmt.Errorf("failed to initialize datastore fault injection: %w", err)
+			}
+			mux.Handle("/debug/datastore-faults", faultInjectorHandler)
+		}
+		if meter != nil {
+			mux.Handle("/debug/usage", meter)
+		}
+		metricsHandler = mux
+	}
+
+	metricsServer, err := c.MetricsAPI.Complete(zerolog.InfoLevel, metricsHandler)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize metrics server: %w", err)
 	}
 	closeables.AddWithoutError(metricsServer.Close)
 
+	diagnosticsServer, err := c.DiagnosticsAPI.Complete(zerolog.WarnLevel, diagnosticsHandler)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize diagnostics server: %w", err)
+	}
+	closeables.AddWithoutError(diagnosticsServer.Close)
+
 	return &completedServerConfig{
 		ds:                  ds,
 		gRPCServer:          grpcServer,
+		adminGRPCServer:     adminGRPCServer,
 		dispatchGRPCServer:  dispatchGrpcServer,
 		gatewayServer:       gatewayServer,
 		metricsServer:       metricsServer,
+		diagnosticsServer:   diagnosticsServer,
 		unaryMiddleware:     unaryMiddleware,
 		streamingMiddleware: streamingMiddleware,
 		presharedKeys:       c.PresharedSecureKey,
 		telemetryReporter:   reporter,
 		healthManager:       healthManager,
+		configReloader:      reloader,
 		closeFunc:           closeables.Close,
+
+		flattenedMemberships: flattenedMemberships,
+		recoverChunkedWrites: !c.DatastoreConfig.ReadOnly,
 	}, nil
 }
 
@@ -550,6 +1006,32 @@ func (c *Config) buildStreamingMiddleware(defaultMiddleware *MiddlewareChain[grp
 	return chain.ToGRPCInterceptors(), nil
 }
 
+// gatewayOptions returns the options enabling the optional APIs of the gateway.
+func (c *Config) gatewayOptions() ([]gateway.HandlerOption, error) {
+	var opts []gateway.HandlerOption
+	if c.HTTPGatewayGraphQLEnabled {
+		opts = append(opts, gateway.WithGraphQL())
+	}
+	if c.OpenFGAAPIEnabled {
+		opts = append(opts, gateway.WithOpenFGA())
+	}
+	if c.HTTPGatewayOPADataConfigPath != "" {
+		documents, err := gateway.LoadOPADocuments(c.HTTPGatewayOPADataConfigPath)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, gateway.WithOPAData(documents, c.PresharedSecureKey))
+	}
+	if c.HTTPGatewaySCIMMappingPath != "" {
+		mapping, err := scim.LoadMapping(c.HTTPGatewaySCIMMappingPath)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, gateway.WithSCIM(mapping, c.PresharedSecureKey))
+	}
+	return opts, nil
+}
+
 // initializeGateway Configures the gateway to serve HTTP
 func (c *Config) initializeGateway(ctx context.Context) (util.RunnableHTTPServer, io.Closer, error) {
 	if len(c.HTTPGatewayUpstreamAddr) == 0 {
@@ -574,8 +1056,13 @@ func (c *Config) initializeGateway(ctx context.Context) (util.RunnableHTTPServer
 		return gatewayServer, nil, nil
 	}
 
+	gatewayOpts, err := c.gatewayOptions()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize rest gateway: %w", err)
+	}
+
 	var gatewayHandler http.Handler
-	closeableGatewayHandler, err := gateway.NewHandler(ctx, c.HTTPGatewayUpstreamAddr, c.HTTPGatewayUpstreamTLSCertPath)
+	closeableGatewayHandler, err := gateway.NewHandler(ctx, c.HTTPGatewayUpstreamAddr, c.HTTPGatewayUpstreamTLSCertPath, gatewayOpts...)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to initialize rest gateway: %w", err)
 	}
@@ -591,6 +1078,14 @@ func (c *Config) initializeGateway(ctx context.Context) (util.RunnableHTTPServer
 		}).Handler(gatewayHandler)
 	}
 
+	if len(c.HTTPGatewayCompression) > 0 {
+		log.Ctx(ctx).Info().Strs("encodings", c.HTTPGatewayCompression).Msg("enabling REST gateway response compression")
+		gatewayHandler, err = compression.NewHTTPHandler(gatewayHandler, c.HTTPGatewayCompression)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to initialize rest gateway: %w", err)
+		}
+	}
+
 	if c.HTTPGateway.HTTPEnabled {
 		log.Ctx(ctx).Info().Str("upstream", c.HTTPGatewayUpstreamAddr).Msg("starting REST gateway")
 	}
@@ -613,14 +1108,19 @@ type RunnableServer interface {
 // but is assumed have already been validated via `Complete()` on Config.
 // It offers limited options for mutation before Run() starts the services.
 type completedServerConfig struct {
-	ds datastore.Datastore
+	ds                   datastore.Datastore
+	recoverChunkedWrites bool
+	flattenedMemberships *flattened.Memberships
 
 	gRPCServer         util.RunnableGRPCServer
+	adminGRPCServer    util.RunnableGRPCServer
 	dispatchGRPCServer util.RunnableGRPCServer
 	gatewayServer      util.RunnableHTTPServer
 	metricsServer      util.RunnableHTTPServer
+	diagnosticsServer  util.RunnableHTTPServer
 	telemetryReporter  telemetry.Reporter
 	healthManager      health.Manager
+	configReloader     *configReloader
 
 	unaryMiddleware     []grpc.UnaryServerInterceptor
 	streamingMiddleware []grpc.StreamServerInterceptor
@@ -654,6 +1154,10 @@ func (c *completedServerConfig) Run(ctx context.Context) error {
 		}
 	}
 
+	if c.flattenedMemberships != nil {
+		c.flattenedMemberships.Start(ctx)
+	}
+
 	g, ctx := errgroup.WithContext(ctx)
 
 	stopOnCancelWithErr := func(stopFn func() error) func() error {
@@ -668,12 +1172,25 @@ func (c *completedServerConfig) Run(ctx context.Context) error {
 		grpc.ChainStreamInterceptor(c.streamingMiddleware...),
 		grpc.StatsHandler(otelgrpc.NewServerHandler()))
 
+	adminGRPCServer := c.adminGRPCServer.WithOpts(
+		grpc.ChainUnaryInterceptor(c.unaryMiddleware...),
+		grpc.ChainStreamInterceptor(c.streamingMiddleware...),
+		grpc.StatsHandler(otelgrpc.NewServerHandler()))
+
 	g.Go(c.healthManager.Checker(ctx))
 	g.Go(grpcServer.Listen(ctx))
+	g.Go(adminGRPCServer.Listen(ctx))
 	g.Go(c.dispatchGRPCServer.Listen(ctx))
 	g.Go(c.gatewayServer.ListenAndServe)
 	g.Go(c.metricsServer.ListenAndServe)
+	g.Go(c.diagnosticsServer.ListenAndServe)
 	g.Go(func() error { return c.telemetryReporter(ctx) })
+	if c.configReloader != nil {
+		g.Go(func() error { return c.configReloader.Run(ctx) })
+	}
+	if c.recoverChunkedWrites {
+		g.Go(func() error { return v1svc.RunChunkedWriteRecovery(ctx, c.ds, chunkedWriteRecoveryInterval) })
+	}
 
 	g.Go(stopOnCancelWithErr(c.closeFunc))
 
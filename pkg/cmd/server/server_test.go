@@ -2,24 +2,41 @@ package server
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"log"
+	"os"
+	"path/filepath"
+	"slices"
 	"testing"
 	"time"
 
+	"github.com/authzed/spicedb/internal/datastore/common"
 	"github.com/authzed/spicedb/internal/datastore/dsfortesting"
 	"github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/internal/middleware/capture"
+	"github.com/authzed/spicedb/internal/middleware/metering"
 	"github.com/authzed/spicedb/pkg/cmd/datastore"
 	"github.com/authzed/spicedb/pkg/cmd/util"
+	forksv1 "github.com/authzed/spicedb/pkg/proto/forks/v1"
+	reconciliationv1 "github.com/authzed/spicedb/pkg/proto/reconciliation/v1"
+	"github.com/authzed/spicedb/pkg/requestmeta"
 	"github.com/authzed/spicedb/pkg/testutil"
+	"github.com/authzed/spicedb/pkg/tuple"
+	"github.com/authzed/spicedb/pkg/zedtoken"
 
+	authzedrequestmeta "github.com/authzed/authzed-go/pkg/requestmeta"
 	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/authzed/grpcutil"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/sdk/trace"
 	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	"go.uber.org/goleak"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 )
 
 func TestServerGracefulTermination(t *testing.T) {
@@ -59,6 +76,69 @@ func TestServerGracefulTermination(t *testing.T) {
 	<-ch
 }
 
+func TestServerShutdownEndsWatches(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	ds, err := dsfortesting.NewMemDBDatastoreForTesting(0, 1*time.Second, 10*time.Second)
+	require.NoError(t, err)
+
+	startRevision, err := ds.HeadRevision(ctx)
+	require.NoError(t, err)
+
+	c := ConfigWithOptions(
+		&Config{},
+		WithPresharedSecureKey("psk"),
+		WithDatastore(ds),
+		WithGRPCServer(util.GRPCServerConfig{
+			Network:             util.BufferedNetwork,
+			Enabled:             true,
+			ShutdownDrainPeriod: 1 * time.Second,
+		}),
+		WithHTTPGateway(util.HTTPServerConfig{HTTPEnabled: false}),
+		WithMetricsAPI(util.HTTPServerConfig{HTTPEnabled: false}),
+	)
+	rs, err := c.Complete(ctx)
+	require.NoError(t, err)
+
+	runCtx, stop := context.WithCancel(ctx)
+	stopped := make(chan struct{})
+	go func() {
+		_ = rs.Run(runCtx)
+		close(stopped)
+	}()
+
+	conn, err := rs.GRPCDialContext(ctx)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	stream, err := v1.NewWatchServiceClient(conn).Watch(ctx, &v1.WatchRequest{
+		OptionalStartCursor: zedtoken.MustNewFromRevision(startRevision),
+	})
+	require.NoError(t, err)
+
+	// Ensure the watch is streaming before shutting down.
+	writtenRevision, err := common.WriteRelationships(ctx, ds, tuple.UpdateOperationCreate, tuple.MustParse("document:firstdoc#viewer@user:tom"))
+	require.NoError(t, err)
+
+	resp, err := stream.Recv()
+	require.NoError(t, err)
+	require.Len(t, resp.Updates, 1)
+
+	stop()
+
+	resp, err = stream.Recv()
+	require.NoError(t, err)
+	require.Empty(t, resp.Updates)
+	require.Equal(t, zedtoken.MustNewFromRevision(writtenRevision).Token, resp.ChangesThrough.Token)
+
+	_, err = stream.Recv()
+	grpcutil.RequireStatus(t, codes.Unavailable, err)
+	require.ErrorContains(t, err, "server is shutting down")
+
+	<-stopped
+}
+
 func TestOTelReporting(t *testing.T) {
 	defer goleak.VerifyNone(t, append(testutil.GoLeakIgnores(), goleak.IgnoreCurrent())...)
 
@@ -272,3 +352,596 @@ func TestModifyStreamingMiddleware(t *testing.T) {
 	err = streaming[1](context.Background(), nil, nil, nil)
 	require.ErrorContains(t, err, "hi")
 }
+
+func TestAddMeteringMiddleware(t *testing.T) {
+	opt := MiddlewareOption{logging.Logger, nil, false, nil, false, false, false, "testing", nil, nil}
+	opt = opt.WithDatastore(nil)
+
+	unaryMw, err := DefaultUnaryMiddleware(opt)
+	require.NoError(t, err)
+
+	streamingMw, err := DefaultStreamingMiddleware(opt)
+	require.NoError(t, err)
+
+	err = addMeteringMiddleware(unaryMw, streamingMw, metering.NewMeter(metering.Quota{}))
+	require.NoError(t, err)
+	require.True(t, unaryMw.Names().Equal(streamingMw.Names()))
+
+	for index, mw := range unaryMw.chain {
+		if mw.Name == DefaultInternalMiddlewareMetering {
+			require.Equal(t, DefaultInternalMiddlewareDatastore, unaryMw.chain[index-1].Name)
+			return
+		}
+	}
+	require.Fail(t, "metering middleware not found")
+}
+
+func TestAddDiagnosticsMiddleware(t *testing.T) {
+	opt := MiddlewareOption{logging.Logger, nil, false, nil, false, false, false, "testing", nil, nil}
+	opt = opt.WithDatastore(nil)
+
+	unaryMw, err := DefaultUnaryMiddleware(opt)
+	require.NoError(t, err)
+
+	streamingMw, err := DefaultStreamingMiddleware(opt)
+	require.NoError(t, err)
+
+	err = addDiagnosticsMiddleware(unaryMw, streamingMw)
+	require.NoError(t, err)
+	require.True(t, unaryMw.Names().Equal(streamingMw.Names()))
+	require.Equal(t, DefaultMiddlewareRequestID, unaryMw.chain[0].Name)
+	require.Equal(t, DefaultInternalMiddlewareDiagnostics, unaryMw.chain[1].Name)
+}
+
+func TestAddForkMiddleware(t *testing.T) {
+	opt := MiddlewareOption{logging.Logger, nil, false, nil, false, false, false, "testing", nil, nil}
+	opt = opt.WithDatastore(nil)
+
+	unaryMw, err := DefaultUnaryMiddleware(opt)
+	require.NoError(t, err)
+
+	streamingMw, err := DefaultStreamingMiddleware(opt)
+	require.NoError(t, err)
+
+	err = addMeteringMiddleware(unaryMw, streamingMw, metering.NewMeter(metering.Quota{}))
+	require.NoError(t, err)
+
+	err = addForkMiddleware(unaryMw, streamingMw, nil)
+	require.NoError(t, err)
+	require.True(t, unaryMw.Names().Equal(streamingMw.Names()))
+
+	for index, mw := range unaryMw.chain {
+		if mw.Name == DefaultInternalMiddlewareFork {
+			require.Equal(t, DefaultInternalMiddlewareDatastore, unaryMw.chain[index-1].Name)
+			require.Equal(t, DefaultInternalMiddlewareMetering, unaryMw.chain[index+1].Name)
+			return
+		}
+	}
+	require.Fail(t, "fork middleware not found")
+}
+
+func TestAddCaptureMiddleware(t *testing.T) {
+	opt := MiddlewareOption{logging.Logger, nil, false, nil, false, false, false, "testing", nil, nil}
+	opt = opt.WithDatastore(nil)
+
+	unaryMw, err := DefaultUnaryMiddleware(opt)
+	require.NoError(t, err)
+
+	streamingMw, err := DefaultStreamingMiddleware(opt)
+	require.NoError(t, err)
+
+	capturer, err := capture.NewCapturer(capture.Config{File: filepath.Join(t.TempDir(), "capture.ndjson"), SampleRate: 1})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = capturer.Close() })
+
+	err = addCaptureMiddleware(unaryMw, streamingMw, capturer)
+	require.NoError(t, err)
+	require.True(t, unaryMw.Names().Equal(streamingMw.Names()))
+
+	for index, mw := range unaryMw.chain {
+		if mw.Name == DefaultInternalMiddlewareCapture {
+			require.Equal(t, DefaultInternalMiddlewareConsistency, unaryMw.chain[index-1].Name)
+			return
+		}
+	}
+	require.Fail(t, "capture middleware not found")
+}
+
+func TestMiddlewareBuilder(t *testing.T) {
+	builder := NewMiddlewareBuilder().
+		Insert(PositionAfterAuth, "first", mockUnaryInterceptor{val: 1}.unaryIntercept, mockStreamInterceptor{val: errors.New("first")}.streamIntercept).
+		Insert(PositionBeforeDispatch, "dispatching", mockUnaryInterceptor{val: 2}.unaryIntercept, nil).
+		Insert(PositionAfterAuth, "second", nil, mockStreamInterceptor{val: errors.New("second")}.streamIntercept)
+
+	c := Config{}
+	builder.ConfigOption()(&c)
+
+	opt := MiddlewareOption{logging.Logger, nil, false, nil, false, false, false, "testing", nil, nil}
+	opt = opt.WithDatastore(nil)
+
+	unaryMw, err := DefaultUnaryMiddleware(opt)
+	require.NoError(t, err)
+	require.NoError(t, unaryMw.modify(c.UnaryMiddlewareModification...))
+
+	streamingMw, err := DefaultStreamingMiddleware(opt)
+	require.NoError(t, err)
+	require.NoError(t, streamingMw.modify(c.StreamingMiddlewareModification...))
+
+	unaryNames := make([]string, 0, len(unaryMw.chain))
+	for _, mw := range unaryMw.chain {
+		unaryNames = append(unaryNames, mw.Name)
+	}
+	require.Equal(t, slices.Index(unaryNames, DefaultMiddlewareGRPCAuth)+1, slices.Index(unaryNames, "first"))
+	require.Equal(t, slices.Index(unaryNames, DefaultInternalMiddlewareDispatch)-1, slices.Index(unaryNames, "dispatching"))
+	require.NotContains(t, unaryNames, "second")
+
+	streamingNames := make([]string, 0, len(streamingMw.chain))
+	for _, mw := range streamingMw.chain {
+		streamingNames = append(streamingNames, mw.Name)
+	}
+	require.Equal(t, slices.Index(streamingNames, DefaultMiddlewareGRPCAuth)+1, slices.Index(streamingNames, "first"))
+	require.Equal(t, slices.Index(streamingNames, "first")+1, slices.Index(streamingNames, "second"))
+	require.NotContains(t, streamingNames, "dispatching")
+}
+
+func TestMiddlewareBuilderUnknownPosition(t *testing.T) {
+	c := Config{}
+	NewMiddlewareBuilder().
+		Insert(PositionBefore("unknown"), "custom", mockUnaryInterceptor{val: 1}.unaryIntercept, nil).
+		ConfigOption()(&c)
+
+	opt := MiddlewareOption{logging.Logger, nil, false, nil, false, false, false, "testing", nil, nil}
+	opt = opt.WithDatastore(nil)
+
+	defaultMw, err := DefaultUnaryMiddleware(opt)
+	require.NoError(t, err)
+
+	_, err = c.buildUnaryMiddleware(defaultMw)
+	require.ErrorContains(t, err, "referenced dependency does not exist on chain: unknown")
+}
+
+func TestAdminGRPCServerServesWatch(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	ds, err := dsfortesting.NewMemDBDatastoreForTesting(0, 1*time.Second, 10*time.Second)
+	require.NoError(t, err)
+
+	startRevision, err := ds.HeadRevision(ctx)
+	require.NoError(t, err)
+
+	c := ConfigWithOptions(
+		&Config{},
+		WithPresharedSecureKey("psk"),
+		WithDatastore(ds),
+		WithGRPCServer(util.GRPCServerConfig{
+			Network: util.BufferedNetwork,
+			Enabled: true,
+		}),
+		WithGRPCAdminServer(util.GRPCServerConfig{
+			Network: util.BufferedNetwork,
+			Enabled: true,
+		}),
+		WithHTTPGateway(util.HTTPServerConfig{HTTPEnabled: false}),
+		WithMetricsAPI(util.HTTPServerConfig{HTTPEnabled: false}),
+	)
+	rs, err := c.Complete(ctx)
+	require.NoError(t, err)
+
+	runCtx, stop := context.WithCancel(ctx)
+	stopped := make(chan struct{})
+	go func() {
+		_ = rs.Run(runCtx)
+		close(stopped)
+	}()
+	t.Cleanup(func() {
+		stop()
+		<-stopped
+	})
+
+	conn, err := rs.GRPCDialContext(ctx)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	// The Watch API is not served by the main server.
+	stream, err := v1.NewWatchServiceClient(conn).Watch(ctx, &v1.WatchRequest{
+		OptionalStartCursor: zedtoken.MustNewFromRevision(startRevision),
+	})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	grpcutil.RequireStatus(t, codes.Unimplemented, err)
+
+	adminConn, err := rs.(*completedServerConfig).adminGRPCServer.DialContext(ctx, grpcutil.WithInsecureBearerToken("psk"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = adminConn.Close() })
+
+	// The admin server requires the same authentication as the main server.
+	unauthenticatedConn, err := rs.(*completedServerConfig).adminGRPCServer.DialContext(ctx)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = unauthenticatedConn.Close() })
+
+	stream, err = v1.NewWatchServiceClient(unauthenticatedConn).Watch(ctx, &v1.WatchRequest{
+		OptionalStartCursor: zedtoken.MustNewFromRevision(startRevision),
+	})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	grpcutil.RequireStatus(t, codes.Unauthenticated, err)
+
+	stream, err = v1.NewWatchServiceClient(adminConn).Watch(ctx, &v1.WatchRequest{
+		OptionalStartCursor: zedtoken.MustNewFromRevision(startRevision),
+	})
+	require.NoError(t, err)
+
+	_, err = common.WriteRelationships(ctx, ds, tuple.UpdateOperationCreate, tuple.MustParse("document:firstdoc#viewer@user:tom"))
+	require.NoError(t, err)
+
+	resp, err := stream.Recv()
+	require.NoError(t, err)
+	require.Len(t, resp.Updates, 1)
+}
+
+func runForksTestServer(t *testing.T, ctx context.Context, enableForks bool) *grpc.ClientConn {
+	ds, err := dsfortesting.NewMemDBDatastoreForTesting(0, 1*time.Second, 10*time.Second)
+	require.NoError(t, err)
+
+	c := ConfigWithOptions(
+		&Config{},
+		WithPresharedSecureKey("psk"),
+		WithDatastore(ds),
+		WithEnableExperimentalForks(enableForks),
+		WithGRPCServer(util.GRPCServerConfig{
+			Network: util.BufferedNetwork,
+			Enabled: true,
+		}),
+		WithHTTPGateway(util.HTTPServerConfig{HTTPEnabled: false}),
+		WithMetricsAPI(util.HTTPServerConfig{HTTPEnabled: false}),
+	)
+	rs, err := c.Complete(ctx)
+	require.NoError(t, err)
+
+	runCtx, stop := context.WithCancel(ctx)
+	stopped := make(chan struct{})
+	go func() {
+		_ = rs.Run(runCtx)
+		close(stopped)
+	}()
+	t.Cleanup(func() {
+		stop()
+		<-stopped
+	})
+
+	conn, err := rs.GRPCDialContext(ctx, grpcutil.WithInsecureBearerToken("psk"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	_, err = v1.NewSchemaServiceClient(conn).WriteSchema(ctx, &v1.WriteSchemaRequest{
+		Schema: `
+			definition user {}
+
+			definition document {
+				relation viewer: user
+				permission view = viewer
+			}
+		`,
+	})
+	require.NoError(t, err)
+
+	_, err = v1.NewPermissionsServiceClient(conn).WriteRelationships(ctx, &v1.WriteRelationshipsRequest{
+		Updates: []*v1.RelationshipUpdate{tuple.MustUpdateToV1RelationshipUpdate(tuple.Create(tuple.MustParse("document:firstdoc#viewer@user:tom")))},
+	})
+	require.NoError(t, err)
+	return conn
+}
+
+func TestForks(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	conn := runForksTestServer(t, ctx, true)
+	forks := forksv1.NewForkServiceClient(conn)
+	permissions := v1.NewPermissionsServiceClient(conn)
+
+	created, err := forks.CreateFork(ctx, &forksv1.CreateForkRequest{
+		Consistency: &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}},
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, created.Fork.ForkId)
+	require.NotNil(t, created.Fork.ForkedAt)
+
+	forkCtx := authzedrequestmeta.SetRequestHeaders(ctx, map[authzedrequestmeta.RequestMetadataHeaderKey]string{
+		requestmeta.RequestFork: created.Fork.ForkId,
+	})
+
+	// Writes to the fork overwrite and delete the relationships of the datastore in the fork only.
+	_, err = permissions.WriteRelationships(forkCtx, &v1.WriteRelationshipsRequest{
+		Updates: []*v1.RelationshipUpdate{
+			tuple.MustUpdateToV1RelationshipUpdate(tuple.Delete(tuple.MustParse("document:firstdoc#viewer@user:tom"))),
+			tuple.MustUpdateToV1RelationshipUpdate(tuple.Create(tuple.MustParse("document:firstdoc#viewer@user:fred"))),
+		},
+	})
+	require.NoError(t, err)
+
+	check := func(ctx context.Context, subjectID string) v1.CheckPermissionResponse_Permissionship {
+		resp, err := permissions.CheckPermission(ctx, &v1.CheckPermissionRequest{
+			Consistency: &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}},
+			Resource:    &v1.ObjectReference{ObjectType: "document", ObjectId: "firstdoc"},
+			Permission:  "view",
+			Subject:     &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: "user", ObjectId: subjectID}},
+		})
+		require.NoError(t, err)
+		return resp.Permissionship
+	}
+
+	require.Equal(t, v1.CheckPermissionResponse_PERMISSIONSHIP_NO_PERMISSION, check(forkCtx, "tom"))
+	require.Equal(t, v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION, check(forkCtx, "fred"))
+	require.Equal(t, v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION, check(ctx, "tom"))
+	require.Equal(t, v1.CheckPermissionResponse_PERMISSIONSHIP_NO_PERMISSION, check(ctx, "fred"))
+
+	// Forks cannot be forked.
+	_, err = forks.CreateFork(forkCtx, &forksv1.CreateForkRequest{})
+	grpcutil.RequireStatus(t, codes.FailedPrecondition, err)
+
+	got, err := forks.GetFork(ctx, &forksv1.GetForkRequest{ForkId: created.Fork.ForkId})
+	require.NoError(t, err)
+	require.Equal(t, created.Fork.ForkId, got.Fork.ForkId)
+
+	_, err = forks.DeleteFork(ctx, &forksv1.DeleteForkRequest{ForkId: created.Fork.ForkId})
+	require.NoError(t, err)
+
+	_, err = forks.GetFork(ctx, &forksv1.GetForkRequest{ForkId: created.Fork.ForkId})
+	grpcutil.RequireStatus(t, codes.NotFound, err)
+
+	_, err = permissions.CheckPermission(forkCtx, &v1.CheckPermissionRequest{
+		Consistency: &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}},
+		Resource:    &v1.ObjectReference{ObjectType: "document", ObjectId: "firstdoc"},
+		Permission:  "view",
+		Subject:     &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: "user", ObjectId: "tom"}},
+	})
+	grpcutil.RequireStatus(t, codes.NotFound, err)
+}
+
+func TestForksDisabled(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	conn := runForksTestServer(t, ctx, false)
+
+	_, err := forksv1.NewForkServiceClient(conn).CreateFork(ctx, &forksv1.CreateForkRequest{})
+	grpcutil.RequireStatus(t, codes.Unimplemented, err)
+
+	// Writes to a fork must never be applied to the datastore itself.
+	forkCtx := authzedrequestmeta.SetRequestHeaders(ctx, map[authzedrequestmeta.RequestMetadataHeaderKey]string{
+		requestmeta.RequestFork: "somefork",
+	})
+	_, err = v1.NewPermissionsServiceClient(conn).WriteRelationships(forkCtx, &v1.WriteRelationshipsRequest{
+		Updates: []*v1.RelationshipUpdate{tuple.MustUpdateToV1RelationshipUpdate(tuple.Delete(tuple.MustParse("document:firstdoc#viewer@user:tom")))},
+	})
+	grpcutil.RequireStatus(t, codes.FailedPrecondition, err)
+}
+
+func TestReconciliationDisabled(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	conn := runForksTestServer(t, ctx, false)
+
+	_, err := reconciliationv1.NewReconciliationServiceClient(conn).ApplyRelationshipsSnapshot(ctx, &reconciliationv1.ApplyRelationshipsSnapshotRequest{
+		RelationshipFilter: &v1.RelationshipFilter{ResourceType: "document"},
+	})
+	grpcutil.RequireStatus(t, codes.Unimplemented, err)
+}
+
+func TestRequestCapture(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	ds, err := dsfortesting.NewMemDBDatastoreForTesting(0, 1*time.Second, 10*time.Second)
+	require.NoError(t, err)
+
+	captureFile := filepath.Join(t.TempDir(), "capture.ndjson")
+	c := ConfigWithOptions(
+		&Config{},
+		WithPresharedSecureKey("psk"),
+		WithDatastore(ds),
+		WithRequestCaptureFile(captureFile),
+		WithRequestCaptureSampleRate(1),
+		WithGRPCServer(util.GRPCServerConfig{
+			Network: util.BufferedNetwork,
+			Enabled: true,
+		}),
+		WithHTTPGateway(util.HTTPServerConfig{HTTPEnabled: false}),
+		WithMetricsAPI(util.HTTPServerConfig{HTTPEnabled: false}),
+	)
+	rs, err := c.Complete(ctx)
+	require.NoError(t, err)
+
+	runCtx, stop := context.WithCancel(ctx)
+	stopped := make(chan struct{})
+	go func() {
+		_ = rs.Run(runCtx)
+		close(stopped)
+	}()
+
+	conn, err := rs.GRPCDialContext(ctx, grpcutil.WithInsecureBearerToken("psk"))
+	require.NoError(t, err)
+
+	_, err = v1.NewSchemaServiceClient(conn).WriteSchema(ctx, &v1.WriteSchemaRequest{
+		Schema: `
+			definition user {}
+
+			definition document {
+				relation viewer: user
+				permission view = viewer
+			}
+		`,
+	})
+	require.NoError(t, err)
+
+	resp, err := v1.NewPermissionsServiceClient(conn).CheckPermission(ctx, &v1.CheckPermissionRequest{
+		Consistency: &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}},
+		Resource:    &v1.ObjectReference{ObjectType: "document", ObjectId: "firstdoc"},
+		Permission:  "view",
+		Subject:     &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: "user", ObjectId: "tom"}},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, conn.Close())
+	stop()
+	<-stopped
+
+	contents, err := os.ReadFile(captureFile)
+	require.NoError(t, err)
+
+	var record capture.Record
+	require.NoError(t, json.Unmarshal(contents, &record))
+	require.Equal(t, v1.PermissionsService_CheckPermission_FullMethodName, record.Method)
+	require.Equal(t, resp.CheckedAt.Token, record.Revision)
+	require.Equal(t, codes.OK.String(), record.Result.Code)
+	require.Equal(t, uint64(1), record.Result.Count)
+}
+
+func TestValidateDispatchCanary(t *testing.T) {
+	tcs := []struct {
+		name          string
+		config        *Config
+		expectedError string
+	}{
+		{"disabled", &Config{}, ""},
+		{"local", &Config{DispatchCanaryEngine: DispatchCanaryEngineLocal, DispatchCanaryPercent: 1}, ""},
+		{"unknown engine", &Config{DispatchCanaryEngine: "unknown", DispatchCanaryPercent: 1}, "unknown dispatch canary engine"},
+		{"no percent", &Config{DispatchCanaryEngine: DispatchCanaryEngineLocal}, "must be greater than 0"},
+		{"too large percent", &Config{DispatchCanaryEngine: DispatchCanaryEngineLocal, DispatchCanaryPercent: 101}, "must be greater than 0"},
+		{"adaptive ordering", &Config{DispatchCanaryEngine: DispatchCanaryEngineCheckAdaptiveOrdering, DispatchCanaryPercent: 1}, ""},
+		{
+			"adaptive ordering already enabled",
+			&Config{DispatchCanaryEngine: DispatchCanaryEngineCheckAdaptiveOrdering, DispatchCanaryPercent: 1, DispatchCheckPlannerEnabled: true},
+			"requires check adaptive ordering to be disabled",
+		},
+		{
+			"flattened groups",
+			&Config{DispatchCanaryEngine: DispatchCanaryEngineFlattenedGroups, DispatchCanaryPercent: 1, DispatchFlattenedGroupRelations: []string{"group#member"}},
+			"",
+		},
+		{"flattened groups without relations", &Config{DispatchCanaryEngine: DispatchCanaryEngineFlattenedGroups, DispatchCanaryPercent: 1}, "requires flattened group relations"},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.config.validateDispatchCanary()
+			if tc.expectedError == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
+
+// dispatchCanaryChecks returns the number of checks evaluated by the dispatch canary with the
+// engine and outcome.
+func dispatchCanaryChecks(t require.TestingT, engine, outcome string) float64 {
+	families, err := prometheus.DefaultGatherer.Gather()
+	require.NoError(t, err)
+
+	var count float64
+	for _, family := range families {
+		if family.GetName() != "spicedb_dispatch_canary_checks_total" {
+			continue
+		}
+
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["engine"] == engine && labels["outcome"] == outcome {
+				count += metric.GetCounter().GetValue()
+			}
+		}
+	}
+	return count
+}
+
+func TestDispatchCanary(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	ds, err := dsfortesting.NewMemDBDatastoreForTesting(0, 1*time.Second, 10*time.Second)
+	require.NoError(t, err)
+
+	c := ConfigWithOptions(
+		&Config{},
+		WithPresharedSecureKey("psk"),
+		WithDatastore(ds),
+		WithDispatchCanaryEngine(DispatchCanaryEngineFlattenedGroups),
+		WithDispatchCanaryPercent(100),
+		WithDispatchCanaryMaxInFlight(16),
+		SetDispatchFlattenedGroupRelations([]string{"group#member"}),
+		WithDispatchChunkSize(100),
+		WithGRPCServer(util.GRPCServerConfig{
+			Network: util.BufferedNetwork,
+			Enabled: true,
+		}),
+		WithHTTPGateway(util.HTTPServerConfig{HTTPEnabled: false}),
+		WithMetricsAPI(util.HTTPServerConfig{HTTPEnabled: false}),
+	)
+	rs, err := c.Complete(ctx)
+	require.NoError(t, err)
+
+	runCtx, stop := context.WithCancel(ctx)
+	stopped := make(chan struct{})
+	go func() {
+		_ = rs.Run(runCtx)
+		close(stopped)
+	}()
+	t.Cleanup(func() {
+		stop()
+		<-stopped
+	})
+
+	conn, err := rs.GRPCDialContext(ctx, grpcutil.WithInsecureBearerToken("psk"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	_, err = v1.NewSchemaServiceClient(conn).WriteSchema(ctx, &v1.WriteSchemaRequest{
+		Schema: `
+			definition user {}
+
+			definition group {
+				relation member: user | group#member
+			}
+
+			definition document {
+				relation viewer: group#member
+				permission view = viewer
+			}
+		`,
+	})
+	require.NoError(t, err)
+
+	_, err = v1.NewPermissionsServiceClient(conn).WriteRelationships(ctx, &v1.WriteRelationshipsRequest{
+		Updates: []*v1.RelationshipUpdate{
+			tuple.MustUpdateToV1RelationshipUpdate(tuple.Create(tuple.MustParse("document:firstdoc#viewer@group:engineering#member"))),
+			tuple.MustUpdateToV1RelationshipUpdate(tuple.Create(tuple.MustParse("group:engineering#member@group:backend#member"))),
+			tuple.MustUpdateToV1RelationshipUpdate(tuple.Create(tuple.MustParse("group:backend#member@user:tom"))),
+		},
+	})
+	require.NoError(t, err)
+
+	matches := dispatchCanaryChecks(t, DispatchCanaryEngineFlattenedGroups, "match")
+	for _, userID := range []string{"tom", "sarah"} {
+		_, err := v1.NewPermissionsServiceClient(conn).CheckPermission(ctx, &v1.CheckPermissionRequest{
+			Consistency: &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}},
+			Resource:    &v1.ObjectReference{ObjectType: "document", ObjectId: "firstdoc"},
+			Permission:  "view",
+			Subject:     &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: "user", ObjectId: userID}},
+		})
+		require.NoError(t, err)
+	}
+
+	// The checks are evaluated by the canary dispatcher in the background.
+	require.EventuallyWithT(t, func(c *assert.CollectT) {
+		require.GreaterOrEqual(c, dispatchCanaryChecks(c, DispatchCanaryEngineFlattenedGroups, "match"), matches+2)
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Zero(t, dispatchCanaryChecks(t, DispatchCanaryEngineFlattenedGroups, "mismatch"))
+}
@@ -4,6 +4,7 @@ package server
 import (
 	defaults "github.com/creasty/defaults"
 	helpers "github.com/ecordell/optgen/helpers"
+	"time"
 )
 
 type CacheConfigOption func(c *CacheConfig)
@@ -35,6 +36,7 @@ func (c *CacheConfig) ToOption() CacheConfigOption {
 		to.NumCounters = c.NumCounters
 		to.Metrics = c.Metrics
 		to.Enabled = c.Enabled
+		to.TTL = c.TTL
 		to.defaultTTL = c.defaultTTL
 		to.CacheKindForTesting = c.CacheKindForTesting
 	}
@@ -48,6 +50,7 @@ func (c CacheConfig) DebugMap() map[string]any {
 	debugMap["NumCounters"] = helpers.DebugValue(c.NumCounters, false)
 	debugMap["Metrics"] = helpers.DebugValue(c.Metrics, false)
 	debugMap["Enabled"] = helpers.DebugValue(c.Enabled, false)
+	debugMap["TTL"] = helpers.DebugValue(c.TTL, false)
 	debugMap["CacheKindForTesting"] = helpers.DebugValue(c.CacheKindForTesting, false)
 	return debugMap
 }
@@ -103,6 +106,13 @@ func WithEnabled(enabled bool) CacheConfigOption {
 	}
 }
 
+// WithTTL returns an option that can set TTL on a CacheConfig
+func WithTTL(tTL time.Duration) CacheConfigOption {
+	return func(c *CacheConfig) {
+		c.TTL = tTL
+	}
+}
+
 // WithCacheKindForTesting returns an option that can set CacheKindForTesting on a CacheConfig
 func WithCacheKindForTesting(cacheKindForTesting string) CacheConfigOption {
 	return func(c *CacheConfig) {
@@ -4,6 +4,7 @@ package server
 import (
 	dispatch "github.com/authzed/spicedb/internal/dispatch"
 	graph "github.com/authzed/spicedb/internal/dispatch/graph"
+	prioritized "github.com/authzed/spicedb/internal/dispatch/prioritized"
 	datastore "github.com/authzed/spicedb/pkg/cmd/datastore"
 	util "github.com/authzed/spicedb/pkg/cmd/util"
 	datastore1 "github.com/authzed/spicedb/pkg/datastore"
@@ -44,15 +45,25 @@ func (c *Config) ToOption() ConfigOption {
 		to.ShutdownGracePeriod = c.ShutdownGracePeriod
 		to.DisableVersionResponse = c.DisableVersionResponse
 		to.ServerName = c.ServerName
+		to.GRPCCompressors = c.GRPCCompressors
+		to.RequireFIPSMode = c.RequireFIPSMode
+		to.GRPCAdminServer = c.GRPCAdminServer
 		to.HTTPGateway = c.HTTPGateway
 		to.HTTPGatewayUpstreamAddr = c.HTTPGatewayUpstreamAddr
 		to.HTTPGatewayUpstreamTLSCertPath = c.HTTPGatewayUpstreamTLSCertPath
 		to.HTTPGatewayCorsEnabled = c.HTTPGatewayCorsEnabled
 		to.HTTPGatewayCorsAllowedOrigins = c.HTTPGatewayCorsAllowedOrigins
+		to.HTTPGatewayCompression = c.HTTPGatewayCompression
+		to.HTTPGatewayGraphQLEnabled = c.HTTPGatewayGraphQLEnabled
+		to.HTTPGatewayOPADataConfigPath = c.HTTPGatewayOPADataConfigPath
+		to.HTTPGatewaySCIMMappingPath = c.HTTPGatewaySCIMMappingPath
 		to.DatastoreConfig = c.DatastoreConfig
 		to.Datastore = c.Datastore
 		to.MaxCaveatContextSize = c.MaxCaveatContextSize
 		to.MaxRelationshipContextSize = c.MaxRelationshipContextSize
+		to.RevisionFreshnessCheckInterval = c.RevisionFreshnessCheckInterval
+		to.MaxRevisionStaleness = c.MaxRevisionStaleness
+		to.MaxReplicaLag = c.MaxReplicaLag
 		to.EnableExperimentalWatchableSchemaCache = c.EnableExperimentalWatchableSchemaCache
 		to.SchemaWatchHeartbeat = c.SchemaWatchHeartbeat
 		to.NamespaceCacheConfig = c.NamespaceCacheConfig
@@ -72,13 +83,33 @@ func (c *Config) ToOption() ConfigOption {
 		to.DispatchHashringReplicationFactor = c.DispatchHashringReplicationFactor
 		to.DispatchHashringSpread = c.DispatchHashringSpread
 		to.DispatchChunkSize = c.DispatchChunkSize
+		to.DispatchHedgingEnabled = c.DispatchHedgingEnabled
+		to.DispatchHedgingInitialDelay = c.DispatchHedgingInitialDelay
+		to.DispatchHedgingQuantile = c.DispatchHedgingQuantile
+		to.DispatchCacheWarmingEntries = c.DispatchCacheWarmingEntries
+		to.DispatchCacheWarmingTimeout = c.DispatchCacheWarmingTimeout
+		to.DispatchMaxDepthOverrides = c.DispatchMaxDepthOverrides
+		to.DispatchTimeoutOverrides = c.DispatchTimeoutOverrides
+		to.DispatchFlattenedGroupRelations = c.DispatchFlattenedGroupRelations
+		to.DispatchFlattenedGroupMaxRelationships = c.DispatchFlattenedGroupMaxRelationships
+		to.DispatchAdaptiveConcurrencyEnabled = c.DispatchAdaptiveConcurrencyEnabled
+		to.DispatchAdaptiveConcurrencyLimit = c.DispatchAdaptiveConcurrencyLimit
+		to.DispatchAdaptiveConcurrencyLatencyThreshold = c.DispatchAdaptiveConcurrencyLatencyThreshold
+		to.DispatchCheckPlannerEnabled = c.DispatchCheckPlannerEnabled
+		to.DispatchCanaryEngine = c.DispatchCanaryEngine
+		to.DispatchCanaryPercent = c.DispatchCanaryPercent
+		to.DispatchCanaryMaxInFlight = c.DispatchCanaryMaxInFlight
+		to.DispatchPriorityLimits = c.DispatchPriorityLimits
 		to.DispatchSecondaryUpstreamAddrs = c.DispatchSecondaryUpstreamAddrs
 		to.DispatchSecondaryUpstreamExprs = c.DispatchSecondaryUpstreamExprs
 		to.DispatchCacheConfig = c.DispatchCacheConfig
 		to.ClusterDispatchCacheConfig = c.ClusterDispatchCacheConfig
+		to.DispatchNegativeCacheConfig = c.DispatchNegativeCacheConfig
+		to.ClusterDispatchNegativeCacheConfig = c.ClusterDispatchNegativeCacheConfig
 		to.DisableV1SchemaAPI = c.DisableV1SchemaAPI
 		to.V1SchemaAdditiveOnly = c.V1SchemaAdditiveOnly
 		to.MaximumUpdatesPerWrite = c.MaximumUpdatesPerWrite
+		to.MaximumUpdatesPerChunkedWrite = c.MaximumUpdatesPerChunkedWrite
 		to.MaximumPreconditionCount = c.MaximumPreconditionCount
 		to.MaxDatastoreReadPageSize = c.MaxDatastoreReadPageSize
 		to.StreamingAPITimeout = c.StreamingAPITimeout
@@ -87,20 +118,49 @@ func (c *Config) ToOption() ConfigOption {
 		to.MaxDeleteRelationshipsLimit = c.MaxDeleteRelationshipsLimit
 		to.MaxLookupResourcesLimit = c.MaxLookupResourcesLimit
 		to.MaxBulkExportRelationshipsLimit = c.MaxBulkExportRelationshipsLimit
+		to.EnableWriteProvenanceMetadata = c.EnableWriteProvenanceMetadata
+		to.WriteIdempotencyKeyTTL = c.WriteIdempotencyKeyTTL
+		to.WriteIdempotencyMaxKeys = c.WriteIdempotencyMaxKeys
 		to.EnableExperimentalLookupResources = c.EnableExperimentalLookupResources
 		to.EnableExperimentalRelationshipExpiration = c.EnableExperimentalRelationshipExpiration
+		to.EnableExperimentalCaveatPrefiltering = c.EnableExperimentalCaveatPrefiltering
+		to.EnableExperimentalLookupJobs = c.EnableExperimentalLookupJobs
+		to.LookupJobsDirectory = c.LookupJobsDirectory
+		to.LookupJobsRetention = c.LookupJobsRetention
+		to.LookupJobsMaxRunning = c.LookupJobsMaxRunning
+		to.EnableExperimentalForks = c.EnableExperimentalForks
+		to.ForksMaxTTL = c.ForksMaxTTL
+		to.ForksMaxCount = c.ForksMaxCount
+		to.EnableExperimentalReconciliation = c.EnableExperimentalReconciliation
+		to.DeletionProtectionMaxDeletedRelationships = c.DeletionProtectionMaxDeletedRelationships
+		to.DeletionProtectionObjectTypes = c.DeletionProtectionObjectTypes
+		to.DeletionProtectionAdminToken = c.DeletionProtectionAdminToken
 		to.MetricsAPI = c.MetricsAPI
+		to.OpenFGAAPIEnabled = c.OpenFGAAPIEnabled
+		to.OpenFGAStoreID = c.OpenFGAStoreID
+		to.ExtAuthzRulesPath = c.ExtAuthzRulesPath
 		to.UnaryMiddlewareModification = c.UnaryMiddlewareModification
 		to.StreamingMiddlewareModification = c.StreamingMiddlewareModification
 		to.DispatchUnaryMiddleware = c.DispatchUnaryMiddleware
 		to.DispatchStreamingMiddleware = c.DispatchStreamingMiddleware
 		to.SilentlyDisableTelemetry = c.SilentlyDisableTelemetry
 		to.TelemetryCAOverridePath = c.TelemetryCAOverridePath
+		to.ConfigReloadPath = c.ConfigReloadPath
 		to.TelemetryEndpoint = c.TelemetryEndpoint
 		to.TelemetryInterval = c.TelemetryInterval
 		to.EnableRequestLogs = c.EnableRequestLogs
 		to.EnableResponseLogs = c.EnableResponseLogs
 		to.DisableGRPCLatencyHistogram = c.DisableGRPCLatencyHistogram
+		to.EnableUsageMetering = c.EnableUsageMetering
+		to.UsageMeteringMonthlyAPICallQuota = c.UsageMeteringMonthlyAPICallQuota
+		to.UsageMeteringMonthlyDispatchQuota = c.UsageMeteringMonthlyDispatchQuota
+		to.UsageMeteringMonthlyRelationshipsQuota = c.UsageMeteringMonthlyRelationshipsQuota
+		to.RequestCaptureFile = c.RequestCaptureFile
+		to.RequestCaptureSampleRate = c.RequestCaptureSampleRate
+		to.RequestCaptureMaxRecords = c.RequestCaptureMaxRecords
+		to.RequestCaptureDisableHashing = c.RequestCaptureDisableHashing
+		to.DiagnosticsAPI = c.DiagnosticsAPI
+		to.DiagnosticsAdminToken = c.DiagnosticsAdminToken
 	}
 }
 
@@ -113,15 +173,25 @@ func (c Config) DebugMap() map[string]any {
 	debugMap["ShutdownGracePeriod"] = helpers.DebugValue(c.ShutdownGracePeriod, false)
 	debugMap["DisableVersionResponse"] = helpers.DebugValue(c.DisableVersionResponse, false)
 	debugMap["ServerName"] = helpers.DebugValue(c.ServerName, false)
+	debugMap["GRPCCompressors"] = helpers.DebugValue(c.GRPCCompressors, true)
+	debugMap["RequireFIPSMode"] = helpers.DebugValue(c.RequireFIPSMode, false)
+	debugMap["GRPCAdminServer"] = helpers.DebugValue(c.GRPCAdminServer, false)
 	debugMap["HTTPGateway"] = helpers.DebugValue(c.HTTPGateway, false)
 	debugMap["HTTPGatewayUpstreamAddr"] = helpers.DebugValue(c.HTTPGatewayUpstreamAddr, false)
 	debugMap["HTTPGatewayUpstreamTLSCertPath"] = helpers.DebugValue(c.HTTPGatewayUpstreamTLSCertPath, false)
 	debugMap["HTTPGatewayCorsEnabled"] = helpers.DebugValue(c.HTTPGatewayCorsEnabled, false)
 	debugMap["HTTPGatewayCorsAllowedOrigins"] = helpers.DebugValue(c.HTTPGatewayCorsAllowedOrigins, true)
+	debugMap["HTTPGatewayCompression"] = helpers.DebugValue(c.HTTPGatewayCompression, true)
+	debugMap["HTTPGatewayGraphQLEnabled"] = helpers.DebugValue(c.HTTPGatewayGraphQLEnabled, false)
+	debugMap["HTTPGatewayOPADataConfigPath"] = helpers.DebugValue(c.HTTPGatewayOPADataConfigPath, false)
+	debugMap["HTTPGatewaySCIMMappingPath"] = helpers.DebugValue(c.HTTPGatewaySCIMMappingPath, false)
 	debugMap["DatastoreConfig"] = helpers.DebugValue(c.DatastoreConfig, false)
 	debugMap["Datastore"] = helpers.DebugValue(c.Datastore, false)
 	debugMap["MaxCaveatContextSize"] = helpers.DebugValue(c.MaxCaveatContextSize, false)
 	debugMap["MaxRelationshipContextSize"] = helpers.DebugValue(c.MaxRelationshipContextSize, false)
+	debugMap["RevisionFreshnessCheckInterval"] = helpers.DebugValue(c.RevisionFreshnessCheckInterval, false)
+	debugMap["MaxRevisionStaleness"] = helpers.DebugValue(c.MaxRevisionStaleness, false)
+	debugMap["MaxReplicaLag"] = helpers.DebugValue(c.MaxReplicaLag, false)
 	debugMap["EnableExperimentalWatchableSchemaCache"] = helpers.DebugValue(c.EnableExperimentalWatchableSchemaCache, false)
 	debugMap["SchemaWatchHeartbeat"] = helpers.DebugValue(c.SchemaWatchHeartbeat, false)
 	debugMap["NamespaceCacheConfig"] = helpers.DebugValue(c.NamespaceCacheConfig, false)
@@ -141,13 +211,33 @@ func (c Config) DebugMap() map[string]any {
 	debugMap["DispatchHashringReplicationFactor"] = helpers.DebugValue(c.DispatchHashringReplicationFactor, false)
 	debugMap["DispatchHashringSpread"] = helpers.DebugValue(c.DispatchHashringSpread, false)
 	debugMap["DispatchChunkSize"] = helpers.DebugValue(c.DispatchChunkSize, false)
+	debugMap["DispatchHedgingEnabled"] = helpers.DebugValue(c.DispatchHedgingEnabled, false)
+	debugMap["DispatchHedgingInitialDelay"] = helpers.DebugValue(c.DispatchHedgingInitialDelay, false)
+	debugMap["DispatchHedgingQuantile"] = helpers.DebugValue(c.DispatchHedgingQuantile, false)
+	debugMap["DispatchCacheWarmingEntries"] = helpers.DebugValue(c.DispatchCacheWarmingEntries, false)
+	debugMap["DispatchCacheWarmingTimeout"] = helpers.DebugValue(c.DispatchCacheWarmingTimeout, false)
+	debugMap["DispatchMaxDepthOverrides"] = helpers.DebugValue(c.DispatchMaxDepthOverrides, false)
+	debugMap["DispatchTimeoutOverrides"] = helpers.DebugValue(c.DispatchTimeoutOverrides, false)
+	debugMap["DispatchFlattenedGroupRelations"] = helpers.DebugValue(c.DispatchFlattenedGroupRelations, false)
+	debugMap["DispatchFlattenedGroupMaxRelationships"] = helpers.DebugValue(c.DispatchFlattenedGroupMaxRelationships, false)
+	debugMap["DispatchAdaptiveConcurrencyEnabled"] = helpers.DebugValue(c.DispatchAdaptiveConcurrencyEnabled, false)
+	debugMap["DispatchAdaptiveConcurrencyLimit"] = helpers.DebugValue(c.DispatchAdaptiveConcurrencyLimit, false)
+	debugMap["DispatchAdaptiveConcurrencyLatencyThreshold"] = helpers.DebugValue(c.DispatchAdaptiveConcurrencyLatencyThreshold, false)
+	debugMap["DispatchCheckPlannerEnabled"] = helpers.DebugValue(c.DispatchCheckPlannerEnabled, false)
+	debugMap["DispatchCanaryEngine"] = helpers.DebugValue(c.DispatchCanaryEngine, false)
+	debugMap["DispatchCanaryPercent"] = helpers.DebugValue(c.DispatchCanaryPercent, false)
+	debugMap["DispatchCanaryMaxInFlight"] = helpers.DebugValue(c.DispatchCanaryMaxInFlight, false)
+	debugMap["DispatchPriorityLimits"] = helpers.DebugValue(c.DispatchPriorityLimits, false)
 	debugMap["DispatchSecondaryUpstreamAddrs"] = helpers.DebugValue(c.DispatchSecondaryUpstreamAddrs, false)
 	debugMap["DispatchSecondaryUpstreamExprs"] = helpers.DebugValue(c.DispatchSecondaryUpstreamExprs, false)
 	debugMap["DispatchCacheConfig"] = helpers.DebugValue(c.DispatchCacheConfig, false)
 	debugMap["ClusterDispatchCacheConfig"] = helpers.DebugValue(c.ClusterDispatchCacheConfig, false)
+	debugMap["DispatchNegativeCacheConfig"] = helpers.DebugValue(c.DispatchNegativeCacheConfig, false)
+	debugMap["ClusterDispatchNegativeCacheConfig"] = helpers.DebugValue(c.ClusterDispatchNegativeCacheConfig, false)
 	debugMap["DisableV1SchemaAPI"] = helpers.DebugValue(c.DisableV1SchemaAPI, false)
 	debugMap["V1SchemaAdditiveOnly"] = helpers.DebugValue(c.V1SchemaAdditiveOnly, false)
 	debugMap["MaximumUpdatesPerWrite"] = helpers.DebugValue(c.MaximumUpdatesPerWrite, false)
+	debugMap["MaximumUpdatesPerChunkedWrite"] = helpers.DebugValue(c.MaximumUpdatesPerChunkedWrite, false)
 	debugMap["MaximumPreconditionCount"] = helpers.DebugValue(c.MaximumPreconditionCount, false)
 	debugMap["MaxDatastoreReadPageSize"] = helpers.DebugValue(c.MaxDatastoreReadPageSize, false)
 	debugMap["StreamingAPITimeout"] = helpers.DebugValue(c.StreamingAPITimeout, false)
@@ -156,16 +246,45 @@ func (c Config) DebugMap() map[string]any {
 	debugMap["MaxDeleteRelationshipsLimit"] = helpers.DebugValue(c.MaxDeleteRelationshipsLimit, false)
 	debugMap["MaxLookupResourcesLimit"] = helpers.DebugValue(c.MaxLookupResourcesLimit, false)
 	debugMap["MaxBulkExportRelationshipsLimit"] = helpers.DebugValue(c.MaxBulkExportRelationshipsLimit, false)
+	debugMap["EnableWriteProvenanceMetadata"] = helpers.DebugValue(c.EnableWriteProvenanceMetadata, false)
+	debugMap["WriteIdempotencyKeyTTL"] = helpers.DebugValue(c.WriteIdempotencyKeyTTL, false)
+	debugMap["WriteIdempotencyMaxKeys"] = helpers.DebugValue(c.WriteIdempotencyMaxKeys, false)
 	debugMap["EnableExperimentalLookupResources"] = helpers.DebugValue(c.EnableExperimentalLookupResources, false)
 	debugMap["EnableExperimentalRelationshipExpiration"] = helpers.DebugValue(c.EnableExperimentalRelationshipExpiration, false)
+	debugMap["EnableExperimentalCaveatPrefiltering"] = helpers.DebugValue(c.EnableExperimentalCaveatPrefiltering, false)
+	debugMap["EnableExperimentalLookupJobs"] = helpers.DebugValue(c.EnableExperimentalLookupJobs, false)
+	debugMap["LookupJobsDirectory"] = helpers.DebugValue(c.LookupJobsDirectory, false)
+	debugMap["LookupJobsRetention"] = helpers.DebugValue(c.LookupJobsRetention, false)
+	debugMap["LookupJobsMaxRunning"] = helpers.DebugValue(c.LookupJobsMaxRunning, false)
+	debugMap["EnableExperimentalForks"] = helpers.DebugValue(c.EnableExperimentalForks, false)
+	debugMap["ForksMaxTTL"] = helpers.DebugValue(c.ForksMaxTTL, false)
+	debugMap["ForksMaxCount"] = helpers.DebugValue(c.ForksMaxCount, false)
+	debugMap["EnableExperimentalReconciliation"] = helpers.DebugValue(c.EnableExperimentalReconciliation, false)
+	debugMap["DeletionProtectionMaxDeletedRelationships"] = helpers.DebugValue(c.DeletionProtectionMaxDeletedRelationships, false)
+	debugMap["DeletionProtectionObjectTypes"] = helpers.DebugValue(c.DeletionProtectionObjectTypes, true)
+	debugMap["DeletionProtectionAdminToken"] = helpers.SensitiveDebugValue(c.DeletionProtectionAdminToken)
 	debugMap["MetricsAPI"] = helpers.DebugValue(c.MetricsAPI, false)
+	debugMap["OpenFGAAPIEnabled"] = helpers.DebugValue(c.OpenFGAAPIEnabled, false)
+	debugMap["OpenFGAStoreID"] = helpers.DebugValue(c.OpenFGAStoreID, false)
+	debugMap["ExtAuthzRulesPath"] = helpers.DebugValue(c.ExtAuthzRulesPath, false)
 	debugMap["SilentlyDisableTelemetry"] = helpers.DebugValue(c.SilentlyDisableTelemetry, false)
 	debugMap["TelemetryCAOverridePath"] = helpers.DebugValue(c.TelemetryCAOverridePath, false)
+	debugMap["ConfigReloadPath"] = helpers.DebugValue(c.ConfigReloadPath, false)
 	debugMap["TelemetryEndpoint"] = helpers.DebugValue(c.TelemetryEndpoint, false)
 	debugMap["TelemetryInterval"] = helpers.DebugValue(c.TelemetryInterval, false)
 	debugMap["EnableRequestLogs"] = helpers.DebugValue(c.EnableRequestLogs, false)
 	debugMap["EnableResponseLogs"] = helpers.DebugValue(c.EnableResponseLogs, false)
 	debugMap["DisableGRPCLatencyHistogram"] = helpers.DebugValue(c.DisableGRPCLatencyHistogram, false)
+	debugMap["EnableUsageMetering"] = helpers.DebugValue(c.EnableUsageMetering, false)
+	debugMap["UsageMeteringMonthlyAPICallQuota"] = helpers.DebugValue(c.UsageMeteringMonthlyAPICallQuota, false)
+	debugMap["UsageMeteringMonthlyDispatchQuota"] = helpers.DebugValue(c.UsageMeteringMonthlyDispatchQuota, false)
+	debugMap["UsageMeteringMonthlyRelationshipsQuota"] = helpers.DebugValue(c.UsageMeteringMonthlyRelationshipsQuota, false)
+	debugMap["RequestCaptureFile"] = helpers.DebugValue(c.RequestCaptureFile, false)
+	debugMap["RequestCaptureSampleRate"] = helpers.DebugValue(c.RequestCaptureSampleRate, false)
+	debugMap["RequestCaptureMaxRecords"] = helpers.DebugValue(c.RequestCaptureMaxRecords, false)
+	debugMap["RequestCaptureDisableHashing"] = helpers.DebugValue(c.RequestCaptureDisableHashing, false)
+	debugMap["DiagnosticsAPI"] = helpers.DebugValue(c.DiagnosticsAPI, false)
+	debugMap["DiagnosticsAdminToken"] = helpers.SensitiveDebugValue(c.DiagnosticsAdminToken)
 	return debugMap
 }
 
@@ -234,6 +353,34 @@ func WithServerName(serverName string) ConfigOption {
 	}
 }
 
+// WithGRPCCompressors returns an option that can append GRPCCompressorss to Config.GRPCCompressors
+func WithGRPCCompressors(gRPCCompressors string) ConfigOption {
+	return func(c *Config) {
+		c.GRPCCompressors = append(c.GRPCCompressors, gRPCCompressors)
+	}
+}
+
+// SetGRPCCompressors returns an option that can set GRPCCompressors on a Config
+func SetGRPCCompressors(gRPCCompressors []string) ConfigOption {
+	return func(c *Config) {
+		c.GRPCCompressors = gRPCCompressors
+	}
+}
+
+// WithRequireFIPSMode returns an option that can set RequireFIPSMode on a Config
+func WithRequireFIPSMode(requireFIPSMode bool) ConfigOption {
+	return func(c *Config) {
+		c.RequireFIPSMode = requireFIPSMode
+	}
+}
+
+// WithGRPCAdminServer returns an option that can set GRPCAdminServer on a Config
+func WithGRPCAdminServer(gRPCAdminServer util.GRPCServerConfig) ConfigOption {
+	return func(c *Config) {
+		c.GRPCAdminServer = gRPCAdminServer
+	}
+}
+
 // WithHTTPGateway returns an option that can set HTTPGateway on a Config
 func WithHTTPGateway(hTTPGateway util.HTTPServerConfig) ConfigOption {
 	return func(c *Config) {
@@ -276,6 +423,41 @@ func SetHTTPGatewayCorsAllowedOrigins(hTTPGatewayCorsAllowedOrigins []string) Co
 	}
 }
 
+// WithHTTPGatewayCompression returns an option that can append HTTPGatewayCompressions to Config.HTTPGatewayCompression
+func WithHTTPGatewayCompression(hTTPGatewayCompression string) ConfigOption {
+	return func(c *Config) {
+		c.HTTPGatewayCompression = append(c.HTTPGatewayCompression, hTTPGatewayCompression)
+	}
+}
+
+// SetHTTPGatewayCompression returns an option that can set HTTPGatewayCompression on a Config
+func SetHTTPGatewayCompression(hTTPGatewayCompression []string) ConfigOption {
+	return func(c *Config) {
+		c.HTTPGatewayCompression = hTTPGatewayCompression
+	}
+}
+
+// WithHTTPGatewayGraphQLEnabled returns an option that can set HTTPGatewayGraphQLEnabled on a Config
+func WithHTTPGatewayGraphQLEnabled(hTTPGatewayGraphQLEnabled bool) ConfigOption {
+	return func(c *Config) {
+		c.HTTPGatewayGraphQLEnabled = hTTPGatewayGraphQLEnabled
+	}
+}
+
+// WithHTTPGatewayOPADataConfigPath returns an option that can set HTTPGatewayOPADataConfigPath on a Config
+func WithHTTPGatewayOPADataConfigPath(hTTPGatewayOPADataConfigPath string) ConfigOption {
+	return func(c *Config) {
+		c.HTTPGatewayOPADataConfigPath = hTTPGatewayOPADataConfigPath
+	}
+}
+
+// WithHTTPGatewaySCIMMappingPath returns an option that can set HTTPGatewaySCIMMappingPath on a Config
+func WithHTTPGatewaySCIMMappingPath(hTTPGatewaySCIMMappingPath string) ConfigOption {
+	return func(c *Config) {
+		c.HTTPGatewaySCIMMappingPath = hTTPGatewaySCIMMappingPath
+	}
+}
+
 // WithDatastoreConfig returns an option that can set DatastoreConfig on a Config
 func WithDatastoreConfig(datastoreConfig datastore.Config) ConfigOption {
 	return func(c *Config) {
@@ -304,6 +486,27 @@ func WithMaxRelationshipContextSize(maxRelationshipContextSize int) ConfigOption
 	}
 }
 
+// WithRevisionFreshnessCheckInterval returns an option that can set RevisionFreshnessCheckInterval on a Config
+func WithRevisionFreshnessCheckInterval(revisionFreshnessCheckInterval time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.RevisionFreshnessCheckInterval = revisionFreshnessCheckInterval
+	}
+}
+
+// WithMaxRevisionStaleness returns an option that can set MaxRevisionStaleness on a Config
+func WithMaxRevisionStaleness(maxRevisionStaleness time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.MaxRevisionStaleness = maxRevisionStaleness
+	}
+}
+
+// WithMaxReplicaLag returns an option that can set MaxReplicaLag on a Config
+func WithMaxReplicaLag(maxReplicaLag time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.MaxReplicaLag = maxReplicaLag
+	}
+}
+
 // WithEnableExperimentalWatchableSchemaCache returns an option that can set EnableExperimentalWatchableSchemaCache on a Config
 func WithEnableExperimentalWatchableSchemaCache(enableExperimentalWatchableSchemaCache bool) ConfigOption {
 	return func(c *Config) {
@@ -437,6 +640,146 @@ func WithDispatchChunkSize(dispatchChunkSize uint16) ConfigOption {
 	}
 }
 
+// WithDispatchHedgingEnabled returns an option that can set DispatchHedgingEnabled on a Config
+func WithDispatchHedgingEnabled(dispatchHedgingEnabled bool) ConfigOption {
+	return func(c *Config) {
+		c.DispatchHedgingEnabled = dispatchHedgingEnabled
+	}
+}
+
+// WithDispatchHedgingInitialDelay returns an option that can set DispatchHedgingInitialDelay on a Config
+func WithDispatchHedgingInitialDelay(dispatchHedgingInitialDelay time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.DispatchHedgingInitialDelay = dispatchHedgingInitialDelay
+	}
+}
+
+// WithDispatchHedgingQuantile returns an option that can set DispatchHedgingQuantile on a Config
+func WithDispatchHedgingQuantile(dispatchHedgingQuantile float64) ConfigOption {
+	return func(c *Config) {
+		c.DispatchHedgingQuantile = dispatchHedgingQuantile
+	}
+}
+
+// WithDispatchCacheWarmingEntries returns an option that can set DispatchCacheWarmingEntries on a Config
+func WithDispatchCacheWarmingEntries(dispatchCacheWarmingEntries uint32) ConfigOption {
+	return func(c *Config) {
+		c.DispatchCacheWarmingEntries = dispatchCacheWarmingEntries
+	}
+}
+
+// WithDispatchCacheWarmingTimeout returns an option that can set DispatchCacheWarmingTimeout on a Config
+func WithDispatchCacheWarmingTimeout(dispatchCacheWarmingTimeout time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.DispatchCacheWarmingTimeout = dispatchCacheWarmingTimeout
+	}
+}
+
+// WithDispatchMaxDepthOverrides returns an option that can append DispatchMaxDepthOverridess to Config.DispatchMaxDepthOverrides
+func WithDispatchMaxDepthOverrides(key string, value string) ConfigOption {
+	return func(c *Config) {
+		c.DispatchMaxDepthOverrides[key] = value
+	}
+}
+
+// SetDispatchMaxDepthOverrides returns an option that can set DispatchMaxDepthOverrides on a Config
+func SetDispatchMaxDepthOverrides(dispatchMaxDepthOverrides map[string]string) ConfigOption {
+	return func(c *Config) {
+		c.DispatchMaxDepthOverrides = dispatchMaxDepthOverrides
+	}
+}
+
+// WithDispatchTimeoutOverrides returns an option that can append DispatchTimeoutOverridess to Config.DispatchTimeoutOverrides
+func WithDispatchTimeoutOverrides(key string, value string) ConfigOption {
+	return func(c *Config) {
+		c.DispatchTimeoutOverrides[key] = value
+	}
+}
+
+// SetDispatchTimeoutOverrides returns an option that can set DispatchTimeoutOverrides on a Config
+func SetDispatchTimeoutOverrides(dispatchTimeoutOverrides map[string]string) ConfigOption {
+	return func(c *Config) {
+		c.DispatchTimeoutOverrides = dispatchTimeoutOverrides
+	}
+}
+
+// WithDispatchFlattenedGroupRelations returns an option that can append DispatchFlattenedGroupRelationss to Config.DispatchFlattenedGroupRelations
+func WithDispatchFlattenedGroupRelations(dispatchFlattenedGroupRelations string) ConfigOption {
+	return func(c *Config) {
+		c.DispatchFlattenedGroupRelations = append(c.DispatchFlattenedGroupRelations, dispatchFlattenedGroupRelations)
+	}
+}
+
+// SetDispatchFlattenedGroupRelations returns an option that can set DispatchFlattenedGroupRelations on a Config
+func SetDispatchFlattenedGroupRelations(dispatchFlattenedGroupRelations []string) ConfigOption {
+	return func(c *Config) {
+		c.DispatchFlattenedGroupRelations = dispatchFlattenedGroupRelations
+	}
+}
+
+// WithDispatchFlattenedGroupMaxRelationships returns an option that can set DispatchFlattenedGroupMaxRelationships on a Config
+func WithDispatchFlattenedGroupMaxRelationships(dispatchFlattenedGroupMaxRelationships uint32) ConfigOption {
+	return func(c *Config) {
+		c.DispatchFlattenedGroupMaxRelationships = dispatchFlattenedGroupMaxRelationships
+	}
+}
+
+// WithDispatchAdaptiveConcurrencyEnabled returns an option that can set DispatchAdaptiveConcurrencyEnabled on a Config
+func WithDispatchAdaptiveConcurrencyEnabled(dispatchAdaptiveConcurrencyEnabled bool) ConfigOption {
+	return func(c *Config) {
+		c.DispatchAdaptiveConcurrencyEnabled = dispatchAdaptiveConcurrencyEnabled
+	}
+}
+
+// WithDispatchAdaptiveConcurrencyLimit returns an option that can set DispatchAdaptiveConcurrencyLimit on a Config
+func WithDispatchAdaptiveConcurrencyLimit(dispatchAdaptiveConcurrencyLimit uint32) ConfigOption {
+	return func(c *Config) {
+		c.DispatchAdaptiveConcurrencyLimit = dispatchAdaptiveConcurrencyLimit
+	}
+}
+
+// WithDispatchAdaptiveConcurrencyLatencyThreshold returns an option that can set DispatchAdaptiveConcurrencyLatencyThreshold on a Config
+func WithDispatchAdaptiveConcurrencyLatencyThreshold(dispatchAdaptiveConcurrencyLatencyThreshold time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.DispatchAdaptiveConcurrencyLatencyThreshold = dispatchAdaptiveConcurrencyLatencyThreshold
+	}
+}
+
+// WithDispatchCheckPlannerEnabled returns an option that can set DispatchCheckPlannerEnabled on a Config
+func WithDispatchCheckPlannerEnabled(dispatchCheckPlannerEnabled bool) ConfigOption {
+	return func(c *Config) {
+		c.DispatchCheckPlannerEnabled = dispatchCheckPlannerEnabled
+	}
+}
+
+// WithDispatchCanaryEngine returns an option that can set DispatchCanaryEngine on a Config
+func WithDispatchCanaryEngine(dispatchCanaryEngine string) ConfigOption {
+	return func(c *Config) {
+		c.DispatchCanaryEngine = dispatchCanaryEngine
+	}
+}
+
+// WithDispatchCanaryPercent returns an option that can set DispatchCanaryPercent on a Config
+func WithDispatchCanaryPercent(dispatchCanaryPercent float64) ConfigOption {
+	return func(c *Config) {
+		c.DispatchCanaryPercent = dispatchCanaryPercent
+	}
+}
+
+// WithDispatchCanaryMaxInFlight returns an option that can set DispatchCanaryMaxInFlight on a Config
+func WithDispatchCanaryMaxInFlight(dispatchCanaryMaxInFlight uint16) ConfigOption {
+	return func(c *Config) {
+		c.DispatchCanaryMaxInFlight = dispatchCanaryMaxInFlight
+	}
+}
+
+// WithDispatchPriorityLimits returns an option that can set DispatchPriorityLimits on a Config
+func WithDispatchPriorityLimits(dispatchPriorityLimits prioritized.Limits) ConfigOption {
+	return func(c *Config) {
+		c.DispatchPriorityLimits = dispatchPriorityLimits
+	}
+}
+
 // WithDispatchSecondaryUpstreamAddrs returns an option that can append DispatchSecondaryUpstreamAddrss to Config.DispatchSecondaryUpstreamAddrs
 func WithDispatchSecondaryUpstreamAddrs(key string, value string) ConfigOption {
 	return func(c *Config) {
@@ -479,6 +822,20 @@ func WithClusterDispatchCacheConfig(clusterDispatchCacheConfig CacheConfig) Conf
 	}
 }
 
+// WithDispatchNegativeCacheConfig returns an option that can set DispatchNegativeCacheConfig on a Config
+func WithDispatchNegativeCacheConfig(dispatchNegativeCacheConfig CacheConfig) ConfigOption {
+	return func(c *Config) {
+		c.DispatchNegativeCacheConfig = dispatchNegativeCacheConfig
+	}
+}
+
+// WithClusterDispatchNegativeCacheConfig returns an option that can set ClusterDispatchNegativeCacheConfig on a Config
+func WithClusterDispatchNegativeCacheConfig(clusterDispatchNegativeCacheConfig CacheConfig) ConfigOption {
+	return func(c *Config) {
+		c.ClusterDispatchNegativeCacheConfig = clusterDispatchNegativeCacheConfig
+	}
+}
+
 // WithDisableV1SchemaAPI returns an option that can set DisableV1SchemaAPI on a Config
 func WithDisableV1SchemaAPI(disableV1SchemaAPI bool) ConfigOption {
 	return func(c *Config) {
@@ -500,6 +857,13 @@ func WithMaximumUpdatesPerWrite(maximumUpdatesPerWrite uint16) ConfigOption {
 	}
 }
 
+// WithMaximumUpdatesPerChunkedWrite returns an option that can set MaximumUpdatesPerChunkedWrite on a Config
+func WithMaximumUpdatesPerChunkedWrite(maximumUpdatesPerChunkedWrite uint32) ConfigOption {
+	return func(c *Config) {
+		c.MaximumUpdatesPerChunkedWrite = maximumUpdatesPerChunkedWrite
+	}
+}
+
 // WithMaximumPreconditionCount returns an option that can set MaximumPreconditionCount on a Config
 func WithMaximumPreconditionCount(maximumPreconditionCount uint16) ConfigOption {
 	return func(c *Config) {
@@ -556,6 +920,27 @@ func WithMaxBulkExportRelationshipsLimit(maxBulkExportRelationshipsLimit uint32)
 	}
 }
 
+// WithEnableWriteProvenanceMetadata returns an option that can set EnableWriteProvenanceMetadata on a Config
+func WithEnableWriteProvenanceMetadata(enableWriteProvenanceMetadata bool) ConfigOption {
+	return func(c *Config) {
+		c.EnableWriteProvenanceMetadata = enableWriteProvenanceMetadata
+	}
+}
+
+// WithWriteIdempotencyKeyTTL returns an option that can set WriteIdempotencyKeyTTL on a Config
+func WithWriteIdempotencyKeyTTL(writeIdempotencyKeyTTL time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.WriteIdempotencyKeyTTL = writeIdempotencyKeyTTL
+	}
+}
+
+// WithWriteIdempotencyMaxKeys returns an option that can set WriteIdempotencyMaxKeys on a Config
+func WithWriteIdempotencyMaxKeys(writeIdempotencyMaxKeys uint32) ConfigOption {
+	return func(c *Config) {
+		c.WriteIdempotencyMaxKeys = writeIdempotencyMaxKeys
+	}
+}
+
 // WithEnableExperimentalLookupResources returns an option that can set EnableExperimentalLookupResources on a Config
 func WithEnableExperimentalLookupResources(enableExperimentalLookupResources bool) ConfigOption {
 	return func(c *Config) {
@@ -570,6 +955,97 @@ func WithEnableExperimentalRelationshipExpiration(enableExperimentalRelationship
 	}
 }
 
+// WithEnableExperimentalCaveatPrefiltering returns an option that can set EnableExperimentalCaveatPrefiltering on a Config
+func WithEnableExperimentalCaveatPrefiltering(enableExperimentalCaveatPrefiltering bool) ConfigOption {
+	return func(c *Config) {
+		c.EnableExperimentalCaveatPrefiltering = enableExperimentalCaveatPrefiltering
+	}
+}
+
+// WithEnableExperimentalLookupJobs returns an option that can set EnableExperimentalLookupJobs on a Config
+func WithEnableExperimentalLookupJobs(enableExperimentalLookupJobs bool) ConfigOption {
+	return func(c *Config) {
+		c.EnableExperimentalLookupJobs = enableExperimentalLookupJobs
+	}
+}
+
+// WithLookupJobsDirectory returns an option that can set LookupJobsDirectory on a Config
+func WithLookupJobsDirectory(lookupJobsDirectory string) ConfigOption {
+	return func(c *Config) {
+		c.LookupJobsDirectory = lookupJobsDirectory
+	}
+}
+
+// WithLookupJobsRetention returns an option that can set LookupJobsRetention on a Config
+func WithLookupJobsRetention(lookupJobsRetention time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.LookupJobsRetention = lookupJobsRetention
+	}
+}
+
+// WithLookupJobsMaxRunning returns an option that can set LookupJobsMaxRunning on a Config
+func WithLookupJobsMaxRunning(lookupJobsMaxRunning uint16) ConfigOption {
+	return func(c *Config) {
+		c.LookupJobsMaxRunning = lookupJobsMaxRunning
+	}
+}
+
+// WithEnableExperimentalForks returns an option that can set EnableExperimentalForks on a Config
+func WithEnableExperimentalForks(enableExperimentalForks bool) ConfigOption {
+	return func(c *Config) {
+		c.EnableExperimentalForks = enableExperimentalForks
+	}
+}
+
+// WithForksMaxTTL returns an option that can set ForksMaxTTL on a Config
+func WithForksMaxTTL(forksMaxTTL time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.ForksMaxTTL = forksMaxTTL
+	}
+}
+
+// WithForksMaxCount returns an option that can set ForksMaxCount on a Config
+func WithForksMaxCount(forksMaxCount uint16) ConfigOption {
+	return func(c *Config) {
+		c.ForksMaxCount = forksMaxCount
+	}
+}
+
+// WithEnableExperimentalReconciliation returns an option that can set EnableExperimentalReconciliation on a Config
+func WithEnableExperimentalReconciliation(enableExperimentalReconciliation bool) ConfigOption {
+	return func(c *Config) {
+		c.EnableExperimentalReconciliation = enableExperimentalReconciliation
+	}
+}
+
+// WithDeletionProtectionMaxDeletedRelationships returns an option that can set DeletionProtectionMaxDeletedRelationships on a Config
+func WithDeletionProtectionMaxDeletedRelationships(deletionProtectionMaxDeletedRelationships uint64) ConfigOption {
+	return func(c *Config) {
+		c.DeletionProtectionMaxDeletedRelationships = deletionProtectionMaxDeletedRelationships
+	}
+}
+
+// WithDeletionProtectionObjectTypes returns an option that can append DeletionProtectionObjectTypess to Config.DeletionProtectionObjectTypes
+func WithDeletionProtectionObjectTypes(deletionProtectionObjectTypes string) ConfigOption {
+	return func(c *Config) {
+		c.DeletionProtectionObjectTypes = append(c.DeletionProtectionObjectTypes, deletionProtectionObjectTypes)
+	}
+}
+
+// SetDeletionProtectionObjectTypes returns an option that can set DeletionProtectionObjectTypes on a Config
+func SetDeletionProtectionObjectTypes(deletionProtectionObjectTypes []string) ConfigOption {
+	return func(c *Config) {
+		c.DeletionProtectionObjectTypes = deletionProtectionObjectTypes
+	}
+}
+
+// WithDeletionProtectionAdminToken returns an option that can set DeletionProtectionAdminToken on a Config
+func WithDeletionProtectionAdminToken(deletionProtectionAdminToken string) ConfigOption {
+	return func(c *Config) {
+		c.DeletionProtectionAdminToken = deletionProtectionAdminToken
+	}
+}
+
 // WithMetricsAPI returns an option that can set MetricsAPI on a Config
 func WithMetricsAPI(metricsAPI util.HTTPServerConfig) ConfigOption {
 	return func(c *Config) {
@@ -577,6 +1053,27 @@ func WithMetricsAPI(metricsAPI util.HTTPServerConfig) ConfigOption {
 	}
 }
 
+// WithOpenFGAAPIEnabled returns an option that can set OpenFGAAPIEnabled on a Config
+func WithOpenFGAAPIEnabled(openFGAAPIEnabled bool) ConfigOption {
+	return func(c *Config) {
+		c.OpenFGAAPIEnabled = openFGAAPIEnabled
+	}
+}
+
+// WithOpenFGAStoreID returns an option that can set OpenFGAStoreID on a Config
+func WithOpenFGAStoreID(openFGAStoreID string) ConfigOption {
+	return func(c *Config) {
+		c.OpenFGAStoreID = openFGAStoreID
+	}
+}
+
+// WithExtAuthzRulesPath returns an option that can set ExtAuthzRulesPath on a Config
+func WithExtAuthzRulesPath(extAuthzRulesPath string) ConfigOption {
+	return func(c *Config) {
+		c.ExtAuthzRulesPath = extAuthzRulesPath
+	}
+}
+
 // WithUnaryMiddlewareModification returns an option that can append UnaryMiddlewareModifications to Config.UnaryMiddlewareModification
 func WithUnaryMiddlewareModification(unaryMiddlewareModification MiddlewareModification[grpc.UnaryServerInterceptor]) ConfigOption {
 	return func(c *Config) {
@@ -647,6 +1144,13 @@ func WithTelemetryCAOverridePath(telemetryCAOverridePath string) ConfigOption {
 	}
 }
 
+// WithConfigReloadPath returns an option that can set ConfigReloadPath on a Config
+func WithConfigReloadPath(configReloadPath string) ConfigOption {
+	return func(c *Config) {
+		c.ConfigReloadPath = configReloadPath
+	}
+}
+
 // WithTelemetryEndpoint returns an option that can set TelemetryEndpoint on a Config
 func WithTelemetryEndpoint(telemetryEndpoint string) ConfigOption {
 	return func(c *Config) {
@@ -681,3 +1185,73 @@ func WithDisableGRPCLatencyHistogram(disableGRPCLatencyHistogram bool) ConfigOpt
 		c.DisableGRPCLatencyHistogram = disableGRPCLatencyHistogram
 	}
 }
+
+// WithEnableUsageMetering returns an option that can set EnableUsageMetering on a Config
+func WithEnableUsageMetering(enableUsageMetering bool) ConfigOption {
+	return func(c *Config) {
+		c.EnableUsageMetering = enableUsageMetering
+	}
+}
+
+// WithUsageMeteringMonthlyAPICallQuota returns an option that can set UsageMeteringMonthlyAPICallQuota on a Config
+func WithUsageMeteringMonthlyAPICallQuota(usageMeteringMonthlyAPICallQuota uint64) ConfigOption {
+	return func(c *Config) {
+		c.UsageMeteringMonthlyAPICallQuota = usageMeteringMonthlyAPICallQuota
+	}
+}
+
+// WithUsageMeteringMonthlyDispatchQuota returns an option that can set UsageMeteringMonthlyDispatchQuota on a Config
+func WithUsageMeteringMonthlyDispatchQuota(usageMeteringMonthlyDispatchQuota uint64) ConfigOption {
+	return func(c *Config) {
+		c.UsageMeteringMonthlyDispatchQuota = usageMeteringMonthlyDispatchQuota
+	}
+}
+
+// WithUsageMeteringMonthlyRelationshipsQuota returns an option that can set UsageMeteringMonthlyRelationshipsQuota on a Config
+func WithUsageMeteringMonthlyRelationshipsQuota(usageMeteringMonthlyRelationshipsQuota uint64) ConfigOption {
+	return func(c *Config) {
+		c.UsageMeteringMonthlyRelationshipsQuota = usageMeteringMonthlyRelationshipsQuota
+	}
+}
+
+// WithRequestCaptureFile returns an option that can set RequestCaptureFile on a Config
+func WithRequestCaptureFile(requestCaptureFile string) ConfigOption {
+	return func(c *Config) {
+		c.RequestCaptureFile = requestCaptureFile
+	}
+}
+
+// WithRequestCaptureSampleRate returns an option that can set RequestCaptureSampleRate on a Config
+func WithRequestCaptureSampleRate(requestCaptureSampleRate float64) ConfigOption {
+	return func(c *Config) {
+		c.RequestCaptureSampleRate = requestCaptureSampleRate
+	}
+}
+
+// WithRequestCaptureMaxRecords returns an option that can set RequestCaptureMaxRecords on a Config
+func WithRequestCaptureMaxRecords(requestCaptureMaxRecords uint64) ConfigOption {
+	return func(c *Config) {
+		c.RequestCaptureMaxRecords = requestCaptureMaxRecords
+	}
+}
+
+// WithRequestCaptureDisableHashing returns an option that can set RequestCaptureDisableHashing on a Config
+func WithRequestCaptureDisableHashing(requestCaptureDisableHashing bool) ConfigOption {
+	return func(c *Config) {
+		c.RequestCaptureDisableHashing = requestCaptureDisableHashing
+	}
+}
+
+// WithDiagnosticsAPI returns an option that can set DiagnosticsAPI on a Config
+func WithDiagnosticsAPI(diagnosticsAPI util.HTTPServerConfig) ConfigOption {
+	return func(c *Config) {
+		c.DiagnosticsAPI = diagnosticsAPI
+	}
+}
+
+// WithDiagnosticsAdminToken returns an option that can set DiagnosticsAdminToken on a Config
+func WithDiagnosticsAdminToken(diagnosticsAdminToken string) ConfigOption {
+	return func(c *Config) {
+		c.DiagnosticsAdminToken = diagnosticsAdminToken
+	}
+}
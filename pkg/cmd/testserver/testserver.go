@@ -87,6 +87,7 @@ func (c *Config) Complete() (RunnableTestServer, error) {
 				ExpiringRelationshipsEnabled:    true,
 			},
 			1*time.Second,
+			nil,
 		)
 	}
 
@@ -32,8 +32,11 @@ import (
 
 	"github.com/authzed/spicedb/internal/grpchelpers"
 	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/internal/middleware/ipallowlist"
+	"github.com/authzed/spicedb/internal/middleware/msgsize"
 	"github.com/authzed/spicedb/pkg/cmd/termination"
 	"github.com/authzed/spicedb/pkg/runtime"
+	"github.com/authzed/spicedb/pkg/tailsampling"
 	"github.com/authzed/spicedb/pkg/x509util"
 )
 
@@ -50,15 +53,26 @@ type GRPCServerConfig struct {
 	ClientCAPath string        `debugmap:"visible"`
 	MaxWorkers   uint32        `debugmap:"visible"`
 
+	MaxRecvMsgSize        int            `debugmap:"visible"`
+	MethodMaxRecvMsgSizes map[string]int `debugmap:"visible"`
+	InitialWindowSize     int32          `debugmap:"visible"`
+	InitialConnWindowSize int32          `debugmap:"visible"`
+	ShutdownDrainPeriod   time.Duration  `debugmap:"visible"`
+	AllowedCIDRs          []string       `debugmap:"visible-format"`
+
 	flagPrefix string
 }
 
+// defaultMaxRecvMsgSize is the default maximum size of messages received by gRPC servers.
+const defaultMaxRecvMsgSize = 4 * 1024 * 1024
+
 // RegisterGRPCServerFlags adds the following flags for use with
 // GrpcServerFromFlags:
 // - "$PREFIX-addr"
 // - "$PREFIX-tls-cert-path"
 // - "$PREFIX-tls-key-path"
 // - "$PREFIX-max-conn-age"
+// - "$PREFIX-allowed-cidrs"
 func RegisterGRPCServerFlags(flags *pflag.FlagSet, config *GRPCServerConfig, flagPrefix, serviceName, defaultAddr string, defaultEnabled bool) {
 	flagPrefix = stringz.DefaultEmpty(flagPrefix, "grpc")
 	serviceName = stringz.DefaultEmpty(serviceName, "grpc")
@@ -72,6 +86,12 @@ func RegisterGRPCServerFlags(flags *pflag.FlagSet, config *GRPCServerConfig, fla
 	flags.DurationVar(&config.MaxConnAge, flagPrefix+"-max-conn-age", 30*time.Second, "how long a connection serving "+serviceName+" should be able to live")
 	flags.BoolVar(&config.Enabled, flagPrefix+"-enabled", defaultEnabled, "enable "+serviceName+" gRPC server")
 	flags.Uint32Var(&config.MaxWorkers, flagPrefix+"-max-workers", 0, "set the number of workers for this server (0 value means 1 worker per request)")
+	flags.IntVar(&config.MaxRecvMsgSize, flagPrefix+"-max-recv-msg-size", defaultMaxRecvMsgSize, "maximum size in bytes of messages received by "+serviceName+" methods without a specific limit")
+	flags.StringToIntVar(&config.MethodMaxRecvMsgSizes, flagPrefix+"-method-max-recv-msg-size", nil, "maximum size in bytes of messages received by specific "+serviceName+" methods or services, e.g. `authzed.api.v1.ExperimentalService/BulkImportRelationships=67108864`")
+	flags.Int32Var(&config.InitialWindowSize, flagPrefix+"-initial-window-size", 0, "initial flow-control window size in bytes of each stream served by "+serviceName+"; disables dynamic window sizing when set (0 for dynamic window sizing)")
+	flags.Int32Var(&config.InitialConnWindowSize, flagPrefix+"-initial-conn-window-size", 0, "initial flow-control window size in bytes of each connection served by "+serviceName+"; disables dynamic window sizing when set (0 for dynamic window sizing)")
+	flags.DurationVar(&config.ShutdownDrainPeriod, flagPrefix+"-shutdown-drain-period", 0, "how long to wait on shutdown for in-flight "+serviceName+" requests, such as write transactions, to complete before closing them (0 to wait for all of them)")
+	flags.StringSliceVar(&config.AllowedCIDRs, flagPrefix+"-allowed-cidrs", nil, "CIDRs of the peers allowed to call "+serviceName+", e.g. `10.0.0.0/8`; requests from other peers are rejected with PermissionDenied (empty to allow all peers)")
 }
 
 type (
@@ -87,10 +107,48 @@ func (c *GRPCServerConfig) Complete(level zerolog.Level, svcRegistrationFn func(
 	if c.BufferSize == 0 {
 		c.BufferSize = 1024 * 1024
 	}
+	// The allowlist interceptors are placed before all others, so that requests from disallowed
+	// peers are rejected before any other work is done.
+	if len(c.AllowedCIDRs) > 0 {
+		allowlist, err := ipallowlist.New(c.AllowedCIDRs)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --%s-allowed-cidrs: %w", c.flagPrefix, err)
+		}
+		opts = append([]grpc.ServerOption{
+			grpc.ChainUnaryInterceptor(ipallowlist.UnaryServerInterceptor(allowlist)),
+			grpc.ChainStreamInterceptor(ipallowlist.StreamServerInterceptor(allowlist)),
+		}, opts...)
+	}
+
 	opts = append(opts, grpc.KeepaliveParams(keepalive.ServerParameters{
 		MaxConnectionAge: c.MaxConnAge,
 	}), grpc.NumStreamWorkers(c.MaxWorkers))
 
+	if c.MaxRecvMsgSize == 0 {
+		c.MaxRecvMsgSize = defaultMaxRecvMsgSize
+	}
+	limits, err := msgsize.NewLimits(c.MaxRecvMsgSize, c.MethodMaxRecvMsgSizes)
+	if err != nil {
+		return nil, err
+	}
+
+	// Messages are only limited by the transport up to the largest limit, and per-method limits
+	// are enforced by interceptors.
+	opts = append(opts, grpc.MaxRecvMsgSize(limits.Max()))
+	if len(limits.PerMethod) > 0 {
+		opts = append(opts,
+			grpc.ChainUnaryInterceptor(msgsize.UnaryServerInterceptor(limits)),
+			grpc.ChainStreamInterceptor(msgsize.StreamServerInterceptor(limits)),
+		)
+	}
+
+	if c.InitialWindowSize > 0 {
+		opts = append(opts, grpc.InitialWindowSize(c.InitialWindowSize))
+	}
+	if c.InitialConnWindowSize > 0 {
+		opts = append(opts, grpc.InitialConnWindowSize(c.InitialConnWindowSize))
+	}
+
 	tlsOpts, certWatcher, err := c.tlsOpts()
 	if err != nil {
 		return nil, err
@@ -112,6 +170,7 @@ func (c *GRPCServerConfig) Complete(level zerolog.Level, svcRegistrationFn func(
 		Str("service", c.flagPrefix).
 		Uint32("workers", c.MaxWorkers).
 		Bool("insecure", c.TLSCertPath == "" && c.TLSKeyPath == "").
+		Strs("allowed-cidrs", c.AllowedCIDRs).
 		Msg("grpc server started serving")
 
 	srv := grpc.NewServer(opts...)
@@ -123,8 +182,9 @@ func (c *GRPCServerConfig) Complete(level zerolog.Level, svcRegistrationFn func(
 		listenFunc: func() error {
 			return srv.Serve(l)
 		},
-		dial:    dial,
-		netDial: netDial,
+		dial:        dial,
+		netDial:     netDial,
+		drainPeriod: c.ShutdownDrainPeriod,
 		prestopFunc: func() {
 			log.WithLevel(level).
 				Str("addr", c.Address).
@@ -132,7 +192,7 @@ func (c *GRPCServerConfig) Complete(level zerolog.Level, svcRegistrationFn func(
 				Str("service", c.flagPrefix).
 				Msg("grpc server stopped serving")
 		},
-		stopFunc:    srv.GracefulStop,
+		stopFunc:    drainAndStop(srv, c.ShutdownDrainPeriod),
 		creds:       clientCreds,
 		certWatcher: certWatcher,
 	}, nil
@@ -217,6 +277,7 @@ type completedGRPCServer struct {
 	listenFunc        func() error
 	prestopFunc       func()
 	stopFunc          func()
+	drainPeriod       time.Duration
 	dial              func(context.Context, ...grpc.DialOption) (*grpc.ClientConn, error)
 	netDial           func(ctx context.Context, s string) (net.Conn, error)
 	creds             credentials.TransportCredentials
@@ -231,10 +292,38 @@ func (c *completedGRPCServer) WithOpts(opts ...grpc.ServerOption) RunnableGRPCSe
 	c.listenFunc = func() error {
 		return srv.Serve(c.listener)
 	}
-	c.stopFunc = srv.GracefulStop
+	c.stopFunc = drainAndStop(srv, c.drainPeriod)
 	return c
 }
 
+// drainAndStop returns a function which gracefully stops the server, waiting for its in-flight
+// requests to complete for up to the drain period, after which they are closed. A drain period
+// of zero waits for all of the requests to complete.
+func drainAndStop(srv *grpc.Server, drainPeriod time.Duration) func() {
+	if drainPeriod <= 0 {
+		return srv.GracefulStop
+	}
+
+	return func() {
+		stopped := make(chan struct{})
+		go func() {
+			srv.GracefulStop()
+			close(stopped)
+		}()
+
+		timer := time.NewTimer(drainPeriod)
+		defer timer.Stop()
+
+		select {
+		case <-stopped:
+		case <-timer.C:
+			log.Warn().Stringer("drain-period", drainPeriod).Msg("in-flight requests did not complete within the shutdown drain period; closing them")
+			srv.Stop()
+			<-stopped
+		}
+	}
+}
+
 // Listen runs a configured server
 func (c *completedGRPCServer) Listen(ctx context.Context) func() error {
 	if c.certWatcher != nil {
@@ -307,6 +396,8 @@ type HTTPServerConfig struct {
 	HTTPTLSKeyPath  string `debugmap:"visible"`
 	HTTPEnabled     bool   `debugmap:"visible"`
 
+	HTTPAllowedCIDRs []string `debugmap:"visible-format"`
+
 	flagPrefix string
 }
 
@@ -314,6 +405,13 @@ func (c *HTTPServerConfig) Complete(level zerolog.Level, handler http.Handler) (
 	if !c.HTTPEnabled {
 		return &disabledHTTPServer{}, nil
 	}
+	if len(c.HTTPAllowedCIDRs) > 0 {
+		allowlist, err := ipallowlist.New(c.HTTPAllowedCIDRs)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --%s-allowed-cidrs: %w", c.flagPrefix, err)
+		}
+		handler = ipallowlist.HTTPHandler(allowlist, handler)
+	}
 	srv := &http.Server{
 		Addr:              c.HTTPAddress,
 		Handler:           handler,
@@ -404,6 +502,7 @@ func (c *completedHTTPServer) Close() {
 // - "$PREFIX-tls-cert-path"
 // - "$PREFIX-tls-key-path"
 // - "$PREFIX-enabled"
+// - "$PREFIX-allowed-cidrs"
 func RegisterHTTPServerFlags(flags *pflag.FlagSet, config *HTTPServerConfig, flagPrefix, serviceName, defaultAddr string, defaultEnabled bool) {
 	flagPrefix = stringz.DefaultEmpty(flagPrefix, "http")
 	serviceName = stringz.DefaultEmpty(serviceName, "http")
@@ -413,6 +512,7 @@ func RegisterHTTPServerFlags(flags *pflag.FlagSet, config *HTTPServerConfig, fla
 	flags.StringVar(&config.HTTPTLSCertPath, flagPrefix+"-tls-cert-path", "", "local path to the TLS certificate used to serve "+serviceName)
 	flags.StringVar(&config.HTTPTLSKeyPath, flagPrefix+"-tls-key-path", "", "local path to the TLS key used to serve "+serviceName)
 	flags.BoolVar(&config.HTTPEnabled, flagPrefix+"-enabled", defaultEnabled, "enable http "+serviceName+" server")
+	flags.StringSliceVar(&config.HTTPAllowedCIDRs, flagPrefix+"-allowed-cidrs", nil, "CIDRs of the peers allowed to call the http "+serviceName+" server, e.g. `10.0.0.0/8`; requests from other peers are rejected with 403 Forbidden (empty to allow all peers)")
 }
 
 // RegisterDeprecatedHTTPServerFlags registers a set of HTTP server flags as fully deprecated, for a removed HTTP service.
@@ -459,6 +559,7 @@ func (d *disabledHTTPServer) Close() {}
 func RegisterCommonFlags(cmd *cobra.Command) {
 	otel := cobraotel.New("spicedb")
 	otel.RegisterFlags(cmd.Flags())
+	tailsampling.RegisterFlags(cmd.Flags())
 	termination.RegisterFlags(cmd.Flags())
 	runtime.RegisterFlags(cmd.Flags())
 }
@@ -22,3 +22,11 @@ func TestDisabledHTTP(t *testing.T) {
 	require.NoError(t, s.ListenAndServe())
 	s.Close()
 }
+
+func TestInvalidAllowedCIDRs(t *testing.T) {
+	_, err := (&GRPCServerConfig{Enabled: true, AllowedCIDRs: []string{"10.0.0.0/40"}, flagPrefix: "grpc"}).Complete(zerolog.InfoLevel, nil)
+	require.ErrorContains(t, err, "invalid --grpc-allowed-cidrs")
+
+	_, err = (&HTTPServerConfig{HTTPEnabled: true, HTTPAllowedCIDRs: []string{"metrics"}, flagPrefix: "metrics"}).Complete(zerolog.InfoLevel, nil)
+	require.ErrorContains(t, err, "invalid --metrics-allowed-cidrs")
+}
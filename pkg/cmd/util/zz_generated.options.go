@@ -40,6 +40,12 @@ func (g *GRPCServerConfig) ToOption() GRPCServerConfigOption {
 		to.BufferSize = g.BufferSize
 		to.ClientCAPath = g.ClientCAPath
 		to.MaxWorkers = g.MaxWorkers
+		to.MaxRecvMsgSize = g.MaxRecvMsgSize
+		to.MethodMaxRecvMsgSizes = g.MethodMaxRecvMsgSizes
+		to.InitialWindowSize = g.InitialWindowSize
+		to.InitialConnWindowSize = g.InitialConnWindowSize
+		to.ShutdownDrainPeriod = g.ShutdownDrainPeriod
+		to.AllowedCIDRs = g.AllowedCIDRs
 		to.flagPrefix = g.flagPrefix
 	}
 }
@@ -56,6 +62,12 @@ func (g GRPCServerConfig) DebugMap() map[string]any {
 	debugMap["BufferSize"] = helpers.DebugValue(g.BufferSize, false)
 	debugMap["ClientCAPath"] = helpers.DebugValue(g.ClientCAPath, false)
 	debugMap["MaxWorkers"] = helpers.DebugValue(g.MaxWorkers, false)
+	debugMap["MaxRecvMsgSize"] = helpers.DebugValue(g.MaxRecvMsgSize, false)
+	debugMap["MethodMaxRecvMsgSizes"] = helpers.DebugValue(g.MethodMaxRecvMsgSizes, false)
+	debugMap["InitialWindowSize"] = helpers.DebugValue(g.InitialWindowSize, false)
+	debugMap["InitialConnWindowSize"] = helpers.DebugValue(g.InitialConnWindowSize, false)
+	debugMap["ShutdownDrainPeriod"] = helpers.DebugValue(g.ShutdownDrainPeriod, false)
+	debugMap["AllowedCIDRs"] = helpers.DebugValue(g.AllowedCIDRs, true)
 	return debugMap
 }
 
@@ -138,6 +150,62 @@ func WithMaxWorkers(maxWorkers uint32) GRPCServerConfigOption {
 	}
 }
 
+// WithMaxRecvMsgSize returns an option that can set MaxRecvMsgSize on a GRPCServerConfig
+func WithMaxRecvMsgSize(maxRecvMsgSize int) GRPCServerConfigOption {
+	return func(g *GRPCServerConfig) {
+		g.MaxRecvMsgSize = maxRecvMsgSize
+	}
+}
+
+// WithMethodMaxRecvMsgSizes returns an option that can append MethodMaxRecvMsgSizess to GRPCServerConfig.MethodMaxRecvMsgSizes
+func WithMethodMaxRecvMsgSizes(key string, value int) GRPCServerConfigOption {
+	return func(g *GRPCServerConfig) {
+		g.MethodMaxRecvMsgSizes[key] = value
+	}
+}
+
+// SetMethodMaxRecvMsgSizes returns an option that can set MethodMaxRecvMsgSizes on a GRPCServerConfig
+func SetMethodMaxRecvMsgSizes(methodMaxRecvMsgSizes map[string]int) GRPCServerConfigOption {
+	return func(g *GRPCServerConfig) {
+		g.MethodMaxRecvMsgSizes = methodMaxRecvMsgSizes
+	}
+}
+
+// WithInitialWindowSize returns an option that can set InitialWindowSize on a GRPCServerConfig
+func WithInitialWindowSize(initialWindowSize int32) GRPCServerConfigOption {
+	return func(g *GRPCServerConfig) {
+		g.InitialWindowSize = initialWindowSize
+	}
+}
+
+// WithInitialConnWindowSize returns an option that can set InitialConnWindowSize on a GRPCServerConfig
+func WithInitialConnWindowSize(initialConnWindowSize int32) GRPCServerConfigOption {
+	return func(g *GRPCServerConfig) {
+		g.InitialConnWindowSize = initialConnWindowSize
+	}
+}
+
+// WithShutdownDrainPeriod returns an option that can set ShutdownDrainPeriod on a GRPCServerConfig
+func WithShutdownDrainPeriod(shutdownDrainPeriod time.Duration) GRPCServerConfigOption {
+	return func(g *GRPCServerConfig) {
+		g.ShutdownDrainPeriod = shutdownDrainPeriod
+	}
+}
+
+// WithAllowedCIDRs returns an option that can append AllowedCIDRss to GRPCServerConfig.AllowedCIDRs
+func WithAllowedCIDRs(allowedCIDRs string) GRPCServerConfigOption {
+	return func(g *GRPCServerConfig) {
+		g.AllowedCIDRs = append(g.AllowedCIDRs, allowedCIDRs)
+	}
+}
+
+// SetAllowedCIDRs returns an option that can set AllowedCIDRs on a GRPCServerConfig
+func SetAllowedCIDRs(allowedCIDRs []string) GRPCServerConfigOption {
+	return func(g *GRPCServerConfig) {
+		g.AllowedCIDRs = allowedCIDRs
+	}
+}
+
 type HTTPServerConfigOption func(h *HTTPServerConfig)
 
 // NewHTTPServerConfigWithOptions creates a new HTTPServerConfig with the passed in options set
@@ -166,6 +234,7 @@ func (h *HTTPServerConfig) ToOption() HTTPServerConfigOption {
 		to.HTTPTLSCertPath = h.HTTPTLSCertPath
 		to.HTTPTLSKeyPath = h.HTTPTLSKeyPath
 		to.HTTPEnabled = h.HTTPEnabled
+		to.HTTPAllowedCIDRs = h.HTTPAllowedCIDRs
 		to.flagPrefix = h.flagPrefix
 	}
 }
@@ -177,6 +246,7 @@ func (h HTTPServerConfig) DebugMap() map[string]any {
 	debugMap["HTTPTLSCertPath"] = helpers.DebugValue(h.HTTPTLSCertPath, false)
 	debugMap["HTTPTLSKeyPath"] = helpers.DebugValue(h.HTTPTLSKeyPath, false)
 	debugMap["HTTPEnabled"] = helpers.DebugValue(h.HTTPEnabled, false)
+	debugMap["HTTPAllowedCIDRs"] = helpers.DebugValue(h.HTTPAllowedCIDRs, true)
 	return debugMap
 }
 
@@ -223,3 +293,17 @@ func WithHTTPEnabled(hTTPEnabled bool) HTTPServerConfigOption {
 		h.HTTPEnabled = hTTPEnabled
 	}
 }
+
+// WithHTTPAllowedCIDRs returns an option that can append HTTPAllowedCIDRss to HTTPServerConfig.HTTPAllowedCIDRs
+func WithHTTPAllowedCIDRs(hTTPAllowedCIDRs string) HTTPServerConfigOption {
+	return func(h *HTTPServerConfig) {
+		h.HTTPAllowedCIDRs = append(h.HTTPAllowedCIDRs, hTTPAllowedCIDRs)
+	}
+}
+
+// SetHTTPAllowedCIDRs returns an option that can set HTTPAllowedCIDRs on a HTTPServerConfig
+func SetHTTPAllowedCIDRs(hTTPAllowedCIDRs []string) HTTPServerConfigOption {
+	return func(h *HTTPServerConfig) {
+		h.HTTPAllowedCIDRs = hTTPAllowedCIDRs
+	}
+}
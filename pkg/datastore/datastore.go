@@ -150,6 +150,11 @@ type RelationshipsFilter struct {
 	// If nil, all caveated and non-caveated relationships are allowed
 	OptionalCaveatName string
 
+	// OptionalExcludedCaveatNames are the names of caveats whose relationships are excluded, if
+	// they have no written caveat context. Relationships with a written context are always
+	// allowed, as the written context takes precedence over that of a request.
+	OptionalExcludedCaveatNames []string
+
 	// OptionalExpirationOption is the filter to use for relationships with or without an expiration.
 	OptionalExpirationOption ExpirationFilterOption
 }
@@ -187,6 +192,12 @@ func (rf RelationshipsFilter) Test(relationship tuple.Relationship) bool {
 		}
 	}
 
+	if len(rf.OptionalExcludedCaveatNames) > 0 && relationship.OptionalCaveat != nil &&
+		len(relationship.OptionalCaveat.Context.GetFields()) == 0 &&
+		slices.Contains(rf.OptionalExcludedCaveatNames, relationship.OptionalCaveat.CaveatName) {
+		return false
+	}
+
 	if rf.OptionalExpirationOption == ExpirationFilterOptionHasExpiration && relationship.OptionalExpiration == nil {
 		return false
 	}
@@ -477,6 +488,16 @@ type Reader interface {
 		options ...options.QueryOptionsOption,
 	) (RelationshipIterator, error)
 
+	// QueryRelationshipsBatch reads the relationships matching each of the filters, starting from
+	// the resource side, in a single round trip to the datastore where supported. The options apply
+	// to each filter separately; cursors are not supported. Relationships are tagged with the index
+	// of the filter they matched, and are returned once per matching filter.
+	QueryRelationshipsBatch(
+		ctx context.Context,
+		filters []RelationshipsFilter,
+		options ...options.QueryOptionsOption,
+	) (TaggedRelationshipIterator, error)
+
 	// ReverseQueryRelationships reads relationships, starting from the subject.
 	ReverseQueryRelationships(
 		ctx context.Context,
@@ -499,6 +520,7 @@ type ReadWriteTransaction interface {
 	Reader
 	CaveatStorer
 	CounterRegisterer
+	RecordStorer
 
 	// WriteRelationships takes a list of tuple mutations and applies them to the datastore.
 	WriteRelationships(ctx context.Context, mutations []tuple.RelationshipUpdate) error
@@ -586,6 +608,11 @@ type WatchOptions struct {
 	// EmissionStrategy defines when are changes streamed to the client. If unspecified, changes will be buffered until
 	// they can be checkpointed, which is the default behavior.
 	EmissionStrategy EmissionStrategy
+
+	// IncludeSchemaSnapshot, if true and the schema is watched, emits the full schema at the starting revision,
+	// all of its namespaces and caveats, as the changed definitions of a single change at that revision before
+	// any other change, so that schema caches can be built from the watch alone.
+	IncludeSchemaSnapshot bool
 }
 
 // EmissionStrategy describes when changes are emitted to the client.
@@ -710,6 +737,16 @@ type StartableDatastore interface {
 	Start(ctx context.Context) error
 }
 
+// RequantizableDatastore is a datastore whose revision quantization can be changed while it is
+// running.
+type RequantizableDatastore interface {
+	Datastore
+
+	// SetRevisionQuantization changes the interval to which optimized revisions are quantized,
+	// returning an error without changing it if the interval is not valid for the datastore.
+	SetRevisionQuantization(quantization time.Duration) error
+}
+
 // RepairOperation represents a single kind of repair operation that can be run in a repairable
 // datastore.
 type RepairOperation struct {
@@ -732,6 +769,31 @@ type RepairableDatastore interface {
 	RepairOperations() []RepairOperation
 }
 
+// RevisionPinner is an optional extension to the datastore interface that, when implemented,
+// provides the ability to pin revisions, preventing the garbage collection of the history read at
+// them, such as for the duration of a long-running export.
+type RevisionPinner interface {
+	Datastore
+
+	// PinRevision pins the revision until the given time, returning the ID of the pin.
+	PinRevision(ctx context.Context, revision Revision, expiresAt time.Time) (string, error)
+
+	// UnpinRevision removes the pin with the given ID, if it exists.
+	UnpinRevision(ctx context.Context, pinID string) error
+}
+
+// HistoricalRevisionDatastore is an optional extension to the datastore interface that, when
+// implemented, provides the ability to find the revision of the datastore as of a point in time,
+// such as to read the relationships of the past.
+type HistoricalRevisionDatastore interface {
+	Datastore
+
+	// RevisionAtTime returns the revision at which the data reads as it did at the given time.
+	// The revision must still be checked with CheckRevision before reading at it, as it may have
+	// fallen outside the GC window.
+	RevisionAtTime(ctx context.Context, at time.Time) (Revision, error)
+}
+
 // UnwrappableDatastore represents a datastore that can be unwrapped into the underlying
 // datastore.
 type UnwrappableDatastore interface {
@@ -834,6 +896,65 @@ type Stats struct {
 // iterator.
 type RelationshipIterator iter.Seq2[tuple.Relationship, error]
 
+// TaggedRelationship is a relationship read by a batched query, along with the index of the
+// filter it matched.
+type TaggedRelationship struct {
+	// FilterIndex is the index of the filter, in the batch, matched by the relationship.
+	FilterIndex int
+
+	// Relationship is the relationship read.
+	Relationship tuple.Relationship
+}
+
+// TaggedRelationshipIterator is an iterator over the relationships read by a batched query.
+type TaggedRelationshipIterator iter.Seq2[TaggedRelationship, error]
+
+// QueryRelationshipsBatchSequentially implements QueryRelationshipsBatch over a reader by
+// querying each filter in turn. It is meant for readers for which round trips are cheap.
+func QueryRelationshipsBatchSequentially(
+	ctx context.Context,
+	reader Reader,
+	filters []RelationshipsFilter,
+	opts ...options.QueryOptionsOption,
+) (TaggedRelationshipIterator, error) {
+	if options.NewQueryOptionsWithOptions(opts...).After != nil {
+		return nil, ErrCursorsInBatch
+	}
+
+	return func(yield func(TaggedRelationship, error) bool) {
+		for index, filter := range filters {
+			it, err := reader.QueryRelationships(ctx, filter, opts...)
+			if err != nil {
+				yield(TaggedRelationship{}, err)
+				return
+			}
+
+			for rel, err := range it {
+				if err != nil {
+					yield(TaggedRelationship{}, err)
+					return
+				}
+
+				if !yield(TaggedRelationship{FilterIndex: index, Relationship: rel}, nil) {
+					return
+				}
+			}
+		}
+	}, nil
+}
+
+// TaggedIteratorToSlices collects the relationships read by a batched query, by filter index.
+func TaggedIteratorToSlices(it TaggedRelationshipIterator, filterCount int) ([][]tuple.Relationship, error) {
+	results := make([][]tuple.Relationship, filterCount)
+	for tagged, err := range it {
+		if err != nil {
+			return nil, err
+		}
+		results[tagged.FilterIndex] = append(results[tagged.FilterIndex], tagged.Relationship)
+	}
+	return results, nil
+}
+
 func IteratorToSlice(iter RelationshipIterator) ([]tuple.Relationship, error) {
 	results := make([]tuple.Relationship, 0)
 	for rel, err := range iter {
@@ -14,6 +14,12 @@ type ErrNotFound interface {
 	IsNotFoundError() bool
 }
 
+// ErrContention is a shared interface for errors of transactions which failed due to contention
+// with concurrent transactions, and may succeed if retried after a backoff.
+type ErrContention interface {
+	IsContentionError() bool
+}
+
 // NamespaceNotFoundError occurs when a namespace was not found.
 type NamespaceNotFoundError struct {
 	error
@@ -278,4 +284,5 @@ var (
 	ErrClosedIterator        = errors.New("unable to iterate: iterator closed")
 	ErrCursorsWithoutSorting = errors.New("cursors are disabled on unsorted results")
 	ErrCursorEmpty           = errors.New("cursors are only available after the first result")
+	ErrCursorsInBatch        = errors.New("cursors are not supported in batched relationships queries")
 )
@@ -44,16 +44,35 @@ func ToRelationship(c Cursor) *tuple.Relationship {
 type Assertion func(sql string)
 
 // QueryOptions are the options that can affect the results of a normal forward query.
+//
+// All datastores implement the same contract for limits and cursors:
+//   - A sorted query returns relationships ordered by all six components of the relationship,
+//     resource and subject, in the order given by the SortOrder. The order is therefore total and
+//     stable: the same query at the same revision always returns the same relationships in the
+//     same order.
+//   - A Limit caps the number of relationships returned; a limit of zero returns no
+//     relationships and a nil limit returns all of them.
+//   - A cursor is exclusive: the query returns only the relationships ordered strictly after the
+//     relationship of the cursor, which need not itself exist. Cursors require a sort order, and
+//     the query fails with datastore.ErrCursorsWithoutSorting if it is Unsorted.
+//
+// Resuming a sorted query with a limit from the last relationship returned therefore pages
+// through all of its results exactly once.
+//
+// If IncludeWriteRevisions is set, each relationship returned carries the identifier of the
+// datastore transaction which last wrote it in its OptionalWriteRevision.
 type QueryOptions struct {
-	Limit          *uint64   `debugmap:"visible"`
-	Sort           SortOrder `debugmap:"visible"`
-	After          Cursor    `debugmap:"visible"`
-	SkipCaveats    bool      `debugmap:"visible"`
-	SkipExpiration bool      `debugmap:"visible"`
-	SQLAssertion   Assertion `debugmap:"visible"`
+	Limit                 *uint64   `debugmap:"visible"`
+	Sort                  SortOrder `debugmap:"visible"`
+	After                 Cursor    `debugmap:"visible"`
+	SkipCaveats           bool      `debugmap:"visible"`
+	SkipExpiration        bool      `debugmap:"visible"`
+	IncludeWriteRevisions bool      `debugmap:"visible"`
+	SQLAssertion          Assertion `debugmap:"visible"`
 }
 
-// ReverseQueryOptions are the options that can affect the results of a reverse query.
+// ReverseQueryOptions are the options that can affect the results of a reverse query. Their
+// limit, sort and cursor follow the same contract as those of QueryOptions.
 type ReverseQueryOptions struct {
 	ResRelation *ResourceRelation `debugmap:"visible"`
 
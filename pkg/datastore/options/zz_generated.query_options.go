@@ -36,6 +36,7 @@ func (q *QueryOptions) ToOption() QueryOptionsOption {
 		to.After = q.After
 		to.SkipCaveats = q.SkipCaveats
 		to.SkipExpiration = q.SkipExpiration
+		to.IncludeWriteRevisions = q.IncludeWriteRevisions
 		to.SQLAssertion = q.SQLAssertion
 	}
 }
@@ -48,6 +49,7 @@ func (q QueryOptions) DebugMap() map[string]any {
 	debugMap["After"] = helpers.DebugValue(q.After, false)
 	debugMap["SkipCaveats"] = helpers.DebugValue(q.SkipCaveats, false)
 	debugMap["SkipExpiration"] = helpers.DebugValue(q.SkipExpiration, false)
+	debugMap["IncludeWriteRevisions"] = helpers.DebugValue(q.IncludeWriteRevisions, false)
 	debugMap["SQLAssertion"] = helpers.DebugValue(q.SQLAssertion, false)
 	return debugMap
 }
@@ -103,6 +105,13 @@ func WithSkipExpiration(skipExpiration bool) QueryOptionsOption {
 	}
 }
 
+// WithIncludeWriteRevisions returns an option that can set IncludeWriteRevisions on a QueryOptions
+func WithIncludeWriteRevisions(includeWriteRevisions bool) QueryOptionsOption {
+	return func(q *QueryOptions) {
+		q.IncludeWriteRevisions = includeWriteRevisions
+	}
+}
+
 // WithSQLAssertion returns an option that can set SQLAssertion on a QueryOptions
 func WithSQLAssertion(sQLAssertion Assertion) QueryOptionsOption {
 	return func(q *QueryOptions) {
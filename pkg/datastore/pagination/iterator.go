@@ -9,7 +9,8 @@ import (
 )
 
 // NewPaginatedIterator creates an implementation of the datastore.Iterator
-// interface that internally paginates over datastore results.
+// interface that internally paginates over datastore results. The options
+// are applied to the query of each page, after the sort, limit and cursor.
 func NewPaginatedIterator(
 	ctx context.Context,
 	reader datastore.Reader,
@@ -17,14 +18,17 @@ func NewPaginatedIterator(
 	pageSize uint64,
 	order options.SortOrder,
 	startCursor options.Cursor,
+	opts ...options.QueryOptionsOption,
 ) (datastore.RelationshipIterator, error) {
-	iter, err := reader.QueryRelationships(
-		ctx,
-		filter,
-		options.WithSort(order),
-		options.WithLimit(&pageSize),
-		options.WithAfter(startCursor),
-	)
+	pageOpts := func(cursor options.Cursor) []options.QueryOptionsOption {
+		return append([]options.QueryOptionsOption{
+			options.WithSort(order),
+			options.WithLimit(&pageSize),
+			options.WithAfter(cursor),
+		}, opts...)
+	}
+
+	iter, err := reader.QueryRelationships(ctx, filter, pageOpts(startCursor)...)
 	if err != nil {
 		return nil, err
 	}
@@ -50,13 +54,7 @@ func NewPaginatedIterator(
 				return
 			}
 
-			iter, err = reader.QueryRelationships(
-				ctx,
-				filter,
-				options.WithSort(order),
-				options.WithLimit(&pageSize),
-				options.WithAfter(cursor),
-			)
+			iter, err = reader.QueryRelationships(ctx, filter, pageOpts(cursor)...)
 			if err != nil {
 				yield(tuple.Relationship{}, err)
 				return
@@ -122,6 +122,14 @@ func (m *mockedReader) QueryRelationships(
 	return potentialRelIter.(datastore.RelationshipIterator), args.Error(1)
 }
 
+func (m *mockedReader) QueryRelationshipsBatch(
+	_ context.Context,
+	_ []datastore.RelationshipsFilter,
+	_ ...options.QueryOptionsOption,
+) (datastore.TaggedRelationshipIterator, error) {
+	panic("not implemented")
+}
+
 func (m *mockedReader) ReverseQueryRelationships(
 	_ context.Context,
 	_ datastore.SubjectsFilter,
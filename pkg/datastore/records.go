@@ -0,0 +1,38 @@
+package datastore
+
+import (
+	"context"
+	"time"
+)
+
+// Record is an opaque value stored by SpiceDB itself in the datastore under a key, such as the
+// state of an operation spanning several transactions.
+type Record struct {
+	// Key is the key of the record.
+	Key string
+
+	// Value is the value of the record.
+	Value []byte
+
+	// ExpiresAt is the time after which the record is garbage collected. If zero, the record
+	// never expires.
+	ExpiresAt time.Time
+}
+
+// Expired returns whether the record has expired at the given time.
+func (r Record) Expired(now time.Time) bool {
+	return !r.ExpiresAt.IsZero() && !now.Before(r.ExpiresAt)
+}
+
+// RecordStorer is an interface for reading and writing records within a read-write transaction.
+type RecordStorer interface {
+	// ReadRecords returns the records whose keys start with the prefix, ordered by key. Records
+	// which have expired but have not yet been garbage collected are returned.
+	ReadRecords(ctx context.Context, keyPrefix string) ([]Record, error)
+
+	// WriteRecords writes the records, replacing any records with the same keys.
+	WriteRecords(ctx context.Context, records ...Record) error
+
+	// DeleteRecords deletes the records with the keys. Keys without a record are ignored.
+	DeleteRecords(ctx context.Context, keys ...string) error
+}
@@ -244,6 +244,54 @@ func CaveatedRelationshipFilterTest(t *testing.T, tester DatastoreTester) {
 	expectRel(req, iter, anotherTpl)
 }
 
+func ExcludedCaveatNamesFilterTest(t *testing.T, tester DatastoreTester) {
+	req := require.New(t)
+	ds, err := tester.New(0*time.Second, veryLargeGCInterval, veryLargeGCWindow, 1)
+	req.NoError(err)
+
+	skipIfNotCaveatStorer(t, ds)
+
+	sds, _ := testfixtures.StandardDatastoreWithSchema(ds, req)
+
+	coreCaveat := createCoreCaveat(t)
+	anotherCoreCaveat := createCoreCaveat(t)
+	ctx := context.Background()
+	_, err = writeCaveats(ctx, ds, coreCaveat, anotherCoreCaveat)
+	req.NoError(err)
+
+	withContext := createTestCaveatedRel(t, "document:first#parent@folder:company#...", coreCaveat.Name)
+	withoutContext := tuple.MustParse("document:second#parent@folder:company#...").WithCaveat(&core.ContextualizedCaveat{
+		CaveatName: coreCaveat.Name,
+	})
+	withAnotherCaveat := tuple.MustParse("document:third#parent@folder:company#...").WithCaveat(&core.ContextualizedCaveat{
+		CaveatName: anotherCoreCaveat.Name,
+	})
+	nonCaveated := tuple.MustParse("document:fourth#parent@folder:company#...")
+	rev, err := common.WriteRelationships(ctx, sds, tuple.UpdateOperationCreate, withContext, withoutContext, withAnotherCaveat, nonCaveated)
+	req.NoError(err)
+
+	// Only the relationship with the excluded caveat and without a written context is excluded.
+	iter, err := ds.SnapshotReader(rev).QueryRelationships(ctx, datastore.RelationshipsFilter{
+		OptionalResourceType:        "document",
+		OptionalResourceRelation:    "parent",
+		OptionalExcludedCaveatNames: []string{coreCaveat.Name},
+	})
+	req.NoError(err)
+
+	found, err := datastore.IteratorToSlice(iter)
+	req.NoError(err)
+
+	foundStrings := make([]string, 0, len(found))
+	for _, rel := range found {
+		foundStrings = append(foundStrings, tuple.MustString(rel))
+	}
+	req.ElementsMatch([]string{
+		tuple.MustString(withContext),
+		tuple.MustString(withAnotherCaveat),
+		tuple.MustString(nonCaveated),
+	}, foundStrings)
+}
+
 func CaveatSnapshotReadsTest(t *testing.T, tester DatastoreTester) {
 	req := require.New(t)
 	ds, err := tester.New(0*time.Second, veryLargeGCInterval, veryLargeGCWindow, 1)
@@ -133,10 +133,12 @@ func AllWithExceptions(t *testing.T, tester DatastoreTester, except Categories,
 	t.Run("TestDeleteWithLimit", runner(tester, DeleteWithLimitTest))
 	t.Run("TestDeleteWithInvalidPrefix", runner(tester, DeleteWithInvalidPrefixTest))
 	t.Run("TestQueryRelationshipsWithVariousFilters", runner(tester, QueryRelationshipsWithVariousFiltersTest))
+	t.Run("TestQueryRelationshipsBatch", runner(tester, QueryRelationshipsBatchTest))
 	t.Run("TestDeleteRelationshipsWithVariousFilters", runner(tester, DeleteRelationshipsWithVariousFiltersTest))
 	t.Run("TestTouchTypedAlreadyExistingWithoutCaveat", runner(tester, TypedTouchAlreadyExistingTest))
 	t.Run("TestTouchTypedAlreadyExistingWithCaveat", runner(tester, TypedTouchAlreadyExistingWithCaveatTest))
 	t.Run("TestRelationshipExpiration", runner(tester, RelationshipExpirationTest))
+	t.Run("TestRelationshipWriteRevisions", runner(tester, RelationshipWriteRevisionsTest))
 	t.Run("TestMixedWriteOperations", runner(tester, MixedWriteOperationsTest))
 
 	t.Run("TestMultipleReadsInRWT", runner(tester, MultipleReadsInRWTTest))
@@ -146,6 +148,7 @@ func AllWithExceptions(t *testing.T, tester DatastoreTester, except Categories,
 	t.Run("TestLimit", runner(tester, LimitTest))
 	t.Run("TestOrderedLimit", runner(tester, OrderedLimitTest))
 	t.Run("TestResume", runner(tester, ResumeTest))
+	t.Run("TestPaginationContract", runner(tester, PaginationContractTest))
 	t.Run("TestReverseQueryCursor", runner(tester, ReverseQueryCursorTest))
 	t.Run("TestReverseQueryFilteredCursor", runner(tester, ReverseQueryFilteredOverMultipleValuesCursorTest))
 
@@ -177,6 +180,7 @@ func AllWithExceptions(t *testing.T, tester DatastoreTester, except Categories,
 	t.Run("TestWriteReadDeleteCaveat", runner(tester, WriteReadDeleteCaveatTest))
 	t.Run("TestWriteCaveatedRelationship", runner(tester, WriteCaveatedRelationshipTest))
 	t.Run("TestCaveatedRelationshipFilter", runner(tester, CaveatedRelationshipFilterTest))
+	t.Run("TestExcludedCaveatNamesFilter", runner(tester, ExcludedCaveatNamesFilterTest))
 	t.Run("TestCaveatSnapshotReads", runner(tester, CaveatSnapshotReadsTest))
 
 	if !except.Watch() {
@@ -192,6 +196,7 @@ func AllWithExceptions(t *testing.T, tester DatastoreTester, except Categories,
 
 	if !except.Watch() && !except.WatchSchema() {
 		t.Run("TestWatchSchema", runner(tester, WatchSchemaTest))
+		t.Run("TestWatchSchemaSnapshot", runner(tester, WatchSchemaSnapshotTest))
 		t.Run("TestWatchAll", runner(tester, WatchAllTest))
 	}
 
@@ -204,6 +209,8 @@ func AllWithExceptions(t *testing.T, tester DatastoreTester, except Categories,
 	t.Run("TestDeleteAllData", runner(tester, DeleteAllDataTest))
 	t.Run("TestRelationshipCounterOverExpired", runner(tester, RelationshipCounterOverExpiredTest))
 	t.Run("TestRegisterRelationshipCountersInParallel", runner(tester, RegisterRelationshipCountersInParallelTest))
+
+	t.Run("TestRecords", runner(tester, RecordsTest))
 }
 
 func OnlyGCTests(t *testing.T, tester DatastoreTester, concurrent bool) {
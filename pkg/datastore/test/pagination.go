@@ -1,8 +1,10 @@
 package test
 
 import (
+	"cmp"
 	"context"
 	"fmt"
+	"slices"
 	"sort"
 	"testing"
 
@@ -360,6 +362,186 @@ func ReverseQueryCursorTest(t *testing.T, tester DatastoreTester) {
 	}
 }
 
+const paginationContractSchema = `
+	definition user {}
+
+	definition group {
+		relation member: user
+		relation manager: user
+	}
+
+	definition document {
+		relation editor: user | group#member | group#manager
+		relation viewer: user | group#member | group#manager
+	}`
+
+// paginationContractRelationships exercise every column of the sort orders: resources with
+// multiple relations and subject objects with multiple subject relations.
+var paginationContractRelationships = []string{
+	"document:doca#editor@user:alice",
+	"document:doca#viewer@user:alice",
+	"document:doca#viewer@user:bob",
+	"document:doca#viewer@group:eng#member",
+	"document:doca#viewer@group:eng#manager",
+	"document:docb#editor@group:eng#member",
+	"document:docb#viewer@group:eng#manager",
+	"document:docb#viewer@group:eng#member",
+	"document:docc#viewer@user:bob",
+	"group:eng#manager@user:carol",
+	"group:eng#member@user:alice",
+}
+
+type contractQuery func(ctx context.Context, reader datastore.Reader, sort options.SortOrder, limit *uint64, cursor options.Cursor) (datastore.RelationshipIterator, error)
+
+// PaginationContractTest tests the limit and cursor contract of options.QueryOptions, for both
+// forward and reverse queries: sorted results are totally ordered, limits cap the results,
+// cursors are exclusive and paging through a query returns each of its results exactly once.
+func PaginationContractTest(t *testing.T, tester DatastoreTester) {
+	rawDS, err := tester.New(0, veryLargeGCInterval, veryLargeGCWindow, 1)
+	require.NoError(t, err)
+
+	rels := lo.Map(paginationContractRelationships, func(item string, _ int) tuple.Relationship {
+		return tuple.MustParse(item)
+	})
+	ds, rev := testfixtures.DatastoreFromSchemaAndTestRelationships(rawDS, paginationContractSchema, rels, require.New(t))
+
+	queries := []struct {
+		name    string
+		query   contractQuery
+		matches func(rel tuple.Relationship) bool
+	}{
+		{
+			"forward documents",
+			func(ctx context.Context, reader datastore.Reader, sort options.SortOrder, limit *uint64, cursor options.Cursor) (datastore.RelationshipIterator, error) {
+				return reader.QueryRelationships(ctx, datastore.RelationshipsFilter{
+					OptionalResourceType: "document",
+				}, options.WithSort(sort), options.WithLimit(limit), options.WithAfter(cursor))
+			},
+			func(rel tuple.Relationship) bool { return rel.Resource.ObjectType == "document" },
+		},
+		{
+			"forward document viewers",
+			func(ctx context.Context, reader datastore.Reader, sort options.SortOrder, limit *uint64, cursor options.Cursor) (datastore.RelationshipIterator, error) {
+				return reader.QueryRelationships(ctx, datastore.RelationshipsFilter{
+					OptionalResourceType:     "document",
+					OptionalResourceRelation: "viewer",
+				}, options.WithSort(sort), options.WithLimit(limit), options.WithAfter(cursor))
+			},
+			func(rel tuple.Relationship) bool {
+				return rel.Resource.ObjectType == "document" && rel.Resource.Relation == "viewer"
+			},
+		},
+		{
+			"reverse groups",
+			func(ctx context.Context, reader datastore.Reader, sort options.SortOrder, limit *uint64, cursor options.Cursor) (datastore.RelationshipIterator, error) {
+				return reader.ReverseQueryRelationships(ctx, datastore.SubjectsFilter{
+					SubjectType: "group",
+				}, options.WithSortForReverse(sort), options.WithLimitForReverse(limit), options.WithAfterForReverse(cursor))
+			},
+			func(rel tuple.Relationship) bool { return rel.Subject.ObjectType == "group" },
+		},
+		{
+			"reverse users",
+			func(ctx context.Context, reader datastore.Reader, sort options.SortOrder, limit *uint64, cursor options.Cursor) (datastore.RelationshipIterator, error) {
+				return reader.ReverseQueryRelationships(ctx, datastore.SubjectsFilter{
+					SubjectType: "user",
+				}, options.WithSortForReverse(sort), options.WithLimitForReverse(limit), options.WithAfterForReverse(cursor))
+			},
+			func(rel tuple.Relationship) bool { return rel.Subject.ObjectType == "user" },
+		},
+	}
+
+	for _, q := range queries {
+		for _, order := range []options.SortOrder{options.ByResource, options.BySubject} {
+			expected := lo.Filter(rels, func(rel tuple.Relationship, _ int) bool { return q.matches(rel) })
+			slices.SortFunc(expected, compareForSortOrder(order))
+
+			t.Run(fmt.Sprintf("%s-%d", q.name, order), func(t *testing.T) {
+				ctx := context.Background()
+
+				foreachTxType(ctx, ds, rev, func(reader datastore.Reader) {
+					readAll := func(limit *uint64, cursor options.Cursor) []tuple.Relationship {
+						it, err := q.query(ctx, reader, order, limit, cursor)
+						require.NoError(t, err)
+
+						found, err := datastore.IteratorToSlice(it)
+						require.NoError(t, err)
+						return found
+					}
+
+					// Sorted results are in the same total order for every datastore.
+					require.Equal(t, relationshipStrings(expected), relationshipStrings(readAll(nil, nil)))
+
+					// A limit of zero returns no relationships.
+					zero := uint64(0)
+					require.Empty(t, readAll(&zero, nil))
+
+					// Paging with any limit returns each relationship exactly once, in order.
+					for pageSize := 1; pageSize <= len(expected)+1; pageSize++ {
+						limit, _ := safecast.ToUint64(pageSize)
+
+						var paged []tuple.Relationship
+						var cursor options.Cursor
+						for {
+							page := readAll(&limit, cursor)
+							require.LessOrEqual(t, len(page), pageSize)
+							paged = append(paged, page...)
+							require.LessOrEqual(t, len(paged), len(expected), "paging returned duplicate relationships")
+
+							if len(page) < pageSize {
+								break
+							}
+							cursor = options.ToCursor(page[len(page)-1])
+						}
+
+						require.Equal(t, relationshipStrings(expected), relationshipStrings(paged), "mismatch paging by %d", pageSize)
+					}
+
+					// Cursors are exclusive.
+					require.Empty(t, readAll(nil, options.ToCursor(expected[len(expected)-1])))
+
+					// Cursors need not be relationships that exist.
+					missing := expected[len(expected)/2]
+					missing.Subject.ObjectID += "x"
+					after := lo.Filter(expected, func(rel tuple.Relationship, _ int) bool {
+						return compareForSortOrder(order)(rel, missing) > 0
+					})
+					require.Equal(t, relationshipStrings(after), relationshipStrings(readAll(nil, options.ToCursor(missing))))
+
+					// Cursors require sorting.
+					_, err := q.query(ctx, reader, options.Unsorted, nil, options.ToCursor(expected[0]))
+					require.ErrorIs(t, err, datastore.ErrCursorsWithoutSorting)
+				})
+			})
+		}
+	}
+}
+
+// compareForSortOrder returns a comparison of relationships by the columns of the sort order.
+func compareForSortOrder(order options.SortOrder) func(lhs, rhs tuple.Relationship) int {
+	return func(lhs, rhs tuple.Relationship) int {
+		resource := cmp.Or(
+			cmp.Compare(lhs.Resource.ObjectType, rhs.Resource.ObjectType),
+			cmp.Compare(lhs.Resource.ObjectID, rhs.Resource.ObjectID),
+			cmp.Compare(lhs.Resource.Relation, rhs.Resource.Relation),
+		)
+		subject := cmp.Or(
+			cmp.Compare(lhs.Subject.ObjectType, rhs.Subject.ObjectType),
+			cmp.Compare(lhs.Subject.ObjectID, rhs.Subject.ObjectID),
+			cmp.Compare(lhs.Subject.Relation, rhs.Subject.Relation),
+		)
+
+		switch order {
+		case options.ByResource:
+			return cmp.Or(resource, subject)
+		case options.BySubject:
+			return cmp.Or(subject, resource)
+		default:
+			panic("request for comparison with no sort order")
+		}
+	}
+}
+
 func foreachTxType(
 	ctx context.Context,
 	ds datastore.Datastore,
@@ -0,0 +1,84 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/pkg/datastore"
+)
+
+var errRollback = errors.New("rolled back")
+
+func RecordsTest(t *testing.T, tester DatastoreTester) {
+	ds, err := tester.New(0, veryLargeGCInterval, veryLargeGCWindow, 1)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	expiresAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+
+	readRecords := func(prefix string) []datastore.Record {
+		var records []datastore.Record
+		_, err := ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+			var err error
+			records, err = rwt.ReadRecords(ctx, prefix)
+			return err
+		})
+		require.NoError(t, err)
+
+		for i := range records {
+			records[i].ExpiresAt = records[i].ExpiresAt.UTC()
+		}
+		return records
+	}
+
+	_, err = ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		return rwt.WriteRecords(ctx,
+			datastore.Record{Key: "test/b", Value: []byte("second")},
+			datastore.Record{Key: "test/a", Value: []byte("first"), ExpiresAt: expiresAt},
+			datastore.Record{Key: "test_c", Value: []byte("unrelated")},
+			datastore.Record{Key: "other", Value: []byte("unrelated")},
+		)
+	})
+	require.NoError(t, err)
+
+	// The prefix matches literally, ordered by key.
+	require.Equal(t, []datastore.Record{
+		{Key: "test/a", Value: []byte("first"), ExpiresAt: expiresAt},
+		{Key: "test/b", Value: []byte("second")},
+	}, readRecords("test/"))
+	require.Len(t, readRecords("test_"), 1)
+	require.Len(t, readRecords(""), 4)
+
+	// Writing a record replaces it.
+	_, err = ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		return rwt.WriteRecords(ctx, datastore.Record{Key: "test/a", Value: []byte("replaced")})
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, []datastore.Record{
+		{Key: "test/a", Value: []byte("replaced")},
+	}, readRecords("test/a"))
+
+	// Deleting records ignores missing keys.
+	_, err = ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		return rwt.DeleteRecords(ctx, "test/a", "test/b", "missing")
+	})
+	require.NoError(t, err)
+
+	require.Empty(t, readRecords("test/"))
+	require.Len(t, readRecords(""), 2)
+
+	// Records written by a failed transaction are rolled back.
+	_, err = ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		if err := rwt.WriteRecords(ctx, datastore.Record{Key: "test/rolledback", Value: []byte("value")}); err != nil {
+			return err
+		}
+		return errRollback
+	})
+	require.ErrorIs(t, err, errRollback)
+	require.Empty(t, readRecords("test/"))
+}
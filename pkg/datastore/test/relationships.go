@@ -1111,6 +1111,87 @@ func RecreateRelationshipsAfterDeleteWithFilter(t *testing.T, tester DatastoreTe
 }
 
 // QueryRelationshipsWithVariousFiltersTest tests various relationship filters for query relationships.
+// QueryRelationshipsBatchTest tests that batched relationship queries return, for each filter,
+// the same relationships as a query of the filter alone.
+func QueryRelationshipsBatchTest(t *testing.T, tester DatastoreTester) {
+	rawDS, err := tester.New(0, veryLargeGCInterval, veryLargeGCWindow, 1)
+	require.NoError(t, err)
+
+	ds, rev := testfixtures.StandardDatastoreWithData(rawDS, require.New(t))
+	ctx := context.Background()
+
+	filters := []datastore.RelationshipsFilter{
+		{OptionalResourceType: testfixtures.DocumentNS.Name},
+		{OptionalResourceType: testfixtures.DocumentNS.Name, OptionalResourceRelation: "viewer"},
+		{OptionalResourceType: testfixtures.FolderNS.Name, OptionalResourceIds: []string{"company", "strategy"}},
+		{OptionalResourceType: testfixtures.DocumentNS.Name, OptionalResourceIds: []string{"unknowndoc"}},
+		{
+			OptionalResourceType: testfixtures.DocumentNS.Name,
+			OptionalSubjectsSelectors: []datastore.SubjectsSelector{
+				{OptionalSubjectType: testfixtures.UserNS.Name, OptionalSubjectIds: []string{"product_manager"}},
+			},
+		},
+	}
+
+	limitTwo := uint64(2)
+	testCases := []struct {
+		name    string
+		options []options.QueryOptionsOption
+		ordered bool
+	}{
+		{"unsorted", nil, false},
+		{"sorted by resource", []options.QueryOptionsOption{options.WithSort(options.ByResource)}, true},
+		{"sorted by resource with limit", []options.QueryOptionsOption{options.WithSort(options.ByResource), options.WithLimit(options.LimitOne)}, true},
+		{"sorted by subject with limit", []options.QueryOptionsOption{options.WithSort(options.BySubject), options.WithLimit(&limitTwo)}, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			foreachTxType(ctx, ds, rev, func(reader datastore.Reader) {
+				it, err := reader.QueryRelationshipsBatch(ctx, filters, tc.options...)
+				require.NoError(t, err)
+
+				batched, err := datastore.TaggedIteratorToSlices(it, len(filters))
+				require.NoError(t, err)
+
+				for index, filter := range filters {
+					it, err := reader.QueryRelationships(ctx, filter, tc.options...)
+					require.NoError(t, err)
+
+					expected, err := datastore.IteratorToSlice(it)
+					require.NoError(t, err)
+
+					if len(expected) == 0 {
+						require.Empty(t, batched[index], "unexpected relationships for filter %d", index)
+						continue
+					}
+
+					if tc.ordered {
+						require.Equal(t, relationshipStrings(expected), relationshipStrings(batched[index]), "mismatch for filter %d", index)
+					} else {
+						require.ElementsMatch(t, relationshipStrings(expected), relationshipStrings(batched[index]), "mismatch for filter %d", index)
+					}
+				}
+			})
+		})
+	}
+
+	t.Run("empty batch", func(t *testing.T) {
+		it, err := ds.SnapshotReader(rev).QueryRelationshipsBatch(ctx, nil)
+		require.NoError(t, err)
+
+		batched, err := datastore.TaggedIteratorToSlices(it, 0)
+		require.NoError(t, err)
+		require.Empty(t, batched)
+	})
+
+	t.Run("cursors are rejected", func(t *testing.T) {
+		cursor := options.ToCursor(tuple.MustParse(testfixtures.StandardRelationships[0]))
+		_, err := ds.SnapshotReader(rev).QueryRelationshipsBatch(ctx, filters, options.WithSort(options.ByResource), options.WithAfter(cursor))
+		require.ErrorIs(t, err, datastore.ErrCursorsInBatch)
+	})
+}
+
 func QueryRelationshipsWithVariousFiltersTest(t *testing.T, tester DatastoreTester) {
 	tcs := []struct {
 		name              string
@@ -2042,6 +2123,50 @@ func ensureReverseRelationshipsStatus(ctx context.Context, require *require.Asse
 	}
 }
 
+// RelationshipWriteRevisionsTest tests that relationships are read with their write revision only
+// when requested, and that it changes when a relationship is deleted and written again.
+func RelationshipWriteRevisionsTest(t *testing.T, tester DatastoreTester) {
+	require := require.New(t)
+
+	rawDS, err := tester.New(0, veryLargeGCInterval, veryLargeGCWindow, 1)
+	require.NoError(err)
+
+	ds, _ := testfixtures.StandardDatastoreWithData(rawDS, require)
+	ctx := context.Background()
+
+	rel := makeTestRel("foo", "tom")
+	readWriteRevision := func(opts ...options.QueryOptionsOption) string {
+		headRev, err := ds.HeadRevision(ctx)
+		require.NoError(err)
+
+		iter, err := ds.SnapshotReader(headRev).QueryRelationships(ctx, datastore.RelationshipsFilter{
+			OptionalResourceType:     rel.Resource.ObjectType,
+			OptionalResourceIds:      []string{rel.Resource.ObjectID},
+			OptionalResourceRelation: rel.Resource.Relation,
+		}, opts...)
+		require.NoError(err)
+
+		found, ok, err := datastore.FirstRelationshipIn(iter)
+		require.NoError(err)
+		require.True(ok)
+		return found.OptionalWriteRevision
+	}
+
+	_, err = common.WriteRelationships(ctx, ds, tuple.UpdateOperationCreate, rel)
+	require.NoError(err)
+
+	require.Empty(readWriteRevision())
+	written := readWriteRevision(options.WithIncludeWriteRevisions(true))
+	require.NotEmpty(written)
+
+	_, err = common.WriteRelationships(ctx, ds, tuple.UpdateOperationDelete, rel)
+	require.NoError(err)
+	_, err = common.WriteRelationships(ctx, ds, tuple.UpdateOperationCreate, rel)
+	require.NoError(err)
+
+	require.NotEqual(written, readWriteRevision(options.WithIncludeWriteRevisions(true)))
+}
+
 func ensureRelationships(ctx context.Context, require *require.Assertions, ds datastore.Datastore, rels ...tuple.Relationship) {
 	ensureRelationshipsStatus(ctx, require, ds, rels, true)
 }
@@ -2086,6 +2211,14 @@ func ensureRelationshipsStatus(ctx context.Context, require *require.Assertions,
 	}
 }
 
+func relationshipStrings(rels []tuple.Relationship) []string {
+	strs := make([]string, 0, len(rels))
+	for _, rel := range rels {
+		strs = append(strs, tuple.MustString(rel))
+	}
+	return strs
+}
+
 func countRels(ctx context.Context, require *require.Assertions, ds datastore.Datastore, resourceType string) int {
 	headRev, err := ds.HeadRevision(ctx)
 	require.NoError(err)
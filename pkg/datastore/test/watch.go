@@ -648,6 +648,57 @@ func WatchSchemaTest(t *testing.T, tester DatastoreTester) {
 	}, changes, errchan, false)
 }
 
+func WatchSchemaSnapshotTest(t *testing.T, tester DatastoreTester) {
+	require := require.New(t)
+
+	ds, err := tester.New(0, veryLargeGCInterval, veryLargeGCWindow, 16)
+	require.NoError(err)
+
+	setupDatastore(ds, require)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	lowestRevision, err := ds.HeadRevision(ctx)
+	require.NoError(err)
+
+	reader := ds.SnapshotReader(lowestRevision)
+	namespaces, err := reader.ListAllNamespaces(ctx)
+	require.NoError(err)
+	caveats, err := reader.ListAllCaveats(ctx)
+	require.NoError(err)
+
+	expectedSnapshot := make([]string, 0, len(namespaces)+len(caveats))
+	for _, namespace := range namespaces {
+		expectedSnapshot = append(expectedSnapshot, "changed:"+namespace.Definition.Name)
+	}
+	for _, caveat := range caveats {
+		expectedSnapshot = append(expectedSnapshot, "changed:"+caveat.Definition.Name)
+	}
+	require.NotEmpty(expectedSnapshot)
+
+	opts := datastore.WatchJustSchema()
+	opts.IncludeSchemaSnapshot = true
+	changes, errchan := ds.Watch(ctx, lowestRevision, opts)
+	require.Zero(len(errchan))
+
+	// The full schema is emitted first, followed by live changes.
+	verifyMixedUpdates(require, [][]string{expectedSnapshot}, changes, errchan, false)
+
+	_, err = ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		return rwt.WriteNamespaces(ctx, &core.NamespaceDefinition{
+			Name: "somenewnamespace",
+		})
+	})
+	require.NoError(err)
+
+	verifyMixedUpdates(require, [][]string{
+		{
+			"changed:somenewnamespace",
+		},
+	}, changes, errchan, false)
+}
+
 func WatchAllTest(t *testing.T, tester DatastoreTester) {
 	require := require.New(t)
 
@@ -0,0 +1,372 @@
+//go:build wasm
+// +build wasm
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"syscall/js"
+
+	"github.com/ccoveille/go-safecast"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/authzed/spicedb/pkg/development"
+	"github.com/authzed/spicedb/pkg/namespace"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	devinterface "github.com/authzed/spicedb/pkg/proto/developer/v1"
+	iv1 "github.com/authzed/spicedb/pkg/proto/impl/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// registerAPI exports the JavaScript-friendly API into the WASM environment, as the functions of
+// the global `spicedb` object. Unlike runSpiceDBDeveloperRequest, they take and return plain
+// JavaScript values rather than JSON-encoded developer messages:
+//
+//	spicedb.compileSchema(schema)
+//	spicedb.formatSchema(schema)
+//	spicedb.check({schema, relationships, resource, subject, caveatContext})
+//	spicedb.validate({schema, relationships, assertions, expectedRelations})
+//
+// Relationships are given as a newline-separated string, as in validation files. Errors in the
+// input are returned in the `errors` field of the result, as DeveloperError messages, and
+// internal errors in its `internalError` field.
+//
+// See the README for an example.
+func registerAPI(global js.Value) {
+	api := js.Global().Get("Object").New()
+	api.Set("compileSchema", js.FuncOf(stringArgFunc(compileSchema)))
+	api.Set("formatSchema", js.FuncOf(stringArgFunc(formatSchema)))
+	api.Set("check", js.FuncOf(objectArgFunc(check)))
+	api.Set("validate", js.FuncOf(objectArgFunc(validate)))
+	global.Set("spicedb", api)
+}
+
+// apiResult is the result of a function of the API. Errors are DeveloperError messages.
+type apiResult struct {
+	Errors        []protoJSON `json:"errors"`
+	InternalError string      `json:"internalError,omitempty"`
+
+	// compileSchema
+	Definitions []definitionInfo `json:"definitions,omitempty"`
+	Caveats     []string         `json:"caveats,omitempty"`
+
+	// formatSchema
+	FormattedSchema string `json:"formattedSchema,omitempty"`
+
+	// check
+	Membership     string     `json:"membership,omitempty"`
+	MissingContext []string   `json:"missingContext,omitempty"`
+	DebugTrace     *protoJSON `json:"debugTrace,omitempty"`
+
+	// validate
+	UpdatedExpectedRelations string      `json:"updatedExpectedRelations,omitempty"`
+	Warnings                 []protoJSON `json:"warnings,omitempty"`
+}
+
+type definitionInfo struct {
+	Name        string   `json:"name"`
+	Relations   []string `json:"relations"`
+	Permissions []string `json:"permissions"`
+}
+
+type checkRequest struct {
+	Schema        string         `json:"schema"`
+	Relationships string         `json:"relationships"`
+	Resource      string         `json:"resource"`
+	Subject       string         `json:"subject"`
+	CaveatContext map[string]any `json:"caveatContext"`
+}
+
+type validateRequest struct {
+	Schema            string `json:"schema"`
+	Relationships     string `json:"relationships"`
+	Assertions        string `json:"assertions"`
+	ExpectedRelations string `json:"expectedRelations"`
+}
+
+// compileSchema compiles and validates the schema, returning its definitions.
+func compileSchema(schema string) (apiResult, error) {
+	devContext, devErrors, err := newAPIDevContext(schema, "")
+	if err != nil || len(devErrors) > 0 {
+		return apiResult{Errors: toProtoJSON(devErrors)}, err
+	}
+	defer devContext.Dispose()
+
+	result := apiResult{
+		Definitions: make([]definitionInfo, 0, len(devContext.CompiledSchema.ObjectDefinitions)),
+		Caveats:     make([]string, 0, len(devContext.CompiledSchema.CaveatDefinitions)),
+	}
+	for _, def := range devContext.CompiledSchema.ObjectDefinitions {
+		info := definitionInfo{Name: def.Name, Relations: []string{}, Permissions: []string{}}
+		for _, relation := range def.Relation {
+			if namespace.GetRelationKind(relation) == iv1.RelationMetadata_PERMISSION {
+				info.Permissions = append(info.Permissions, relation.Name)
+			} else {
+				info.Relations = append(info.Relations, relation.Name)
+			}
+		}
+		result.Definitions = append(result.Definitions, info)
+	}
+	for _, caveat := range devContext.CompiledSchema.CaveatDefinitions {
+		result.Caveats = append(result.Caveats, caveat.Name)
+	}
+	return result, nil
+}
+
+// formatSchema returns the schema in its canonical format.
+func formatSchema(schema string) (apiResult, error) {
+	devContext, devErrors, err := newAPIDevContext(schema, "")
+	if err != nil || len(devErrors) > 0 {
+		return apiResult{Errors: toProtoJSON(devErrors)}, err
+	}
+	defer devContext.Dispose()
+
+	opResult, err := runOperation(devContext, &devinterface.Operation{
+		FormatSchemaParameters: &devinterface.FormatSchemaParameters{},
+	})
+	if err != nil {
+		return apiResult{}, err
+	}
+	return apiResult{Errors: []protoJSON{}, FormattedSchema: opResult.FormatSchemaResult.FormattedSchema}, nil
+}
+
+// check checks whether the subject has the permission or relation on the resource.
+func check(request checkRequest) (apiResult, error) {
+	resource, err := tuple.ParseONR(request.Resource)
+	if err != nil {
+		return apiResult{Errors: toProtoJSON([]*devinterface.DeveloperError{
+			checkInputError(fmt.Errorf("invalid resource: %w", err), request.Resource),
+		})}, nil
+	}
+	subject, err := tuple.ParseSubjectONR(request.Subject)
+	if err != nil {
+		return apiResult{Errors: toProtoJSON([]*devinterface.DeveloperError{
+			checkInputError(fmt.Errorf("invalid subject: %w", err), request.Subject),
+		})}, nil
+	}
+
+	var caveatContext *structpb.Struct
+	if request.CaveatContext != nil {
+		caveatContext, err = structpb.NewStruct(request.CaveatContext)
+		if err != nil {
+			return apiResult{Errors: toProtoJSON([]*devinterface.DeveloperError{
+				checkInputError(fmt.Errorf("invalid caveat context: %w", err), ""),
+			})}, nil
+		}
+	}
+
+	devContext, devErrors, err := newAPIDevContext(request.Schema, request.Relationships)
+	if err != nil || len(devErrors) > 0 {
+		return apiResult{Errors: toProtoJSON(devErrors)}, err
+	}
+	defer devContext.Dispose()
+
+	opResult, err := runOperation(devContext, &devinterface.Operation{
+		CheckParameters: &devinterface.CheckOperationParameters{
+			Resource:      resource.ToCoreONR(),
+			Subject:       subject.ToCoreONR(),
+			CaveatContext: caveatContext,
+		},
+	})
+	if err != nil {
+		return apiResult{}, err
+	}
+
+	checkResult := opResult.CheckResult
+	if checkResult.CheckError != nil {
+		return apiResult{Errors: toProtoJSON([]*devinterface.DeveloperError{checkResult.CheckError})}, nil
+	}
+	return apiResult{
+		Errors:         []protoJSON{},
+		Membership:     checkResult.Membership.String(),
+		MissingContext: checkResult.PartialCaveatInfo.GetMissingRequiredContext(),
+		DebugTrace:     &protoJSON{checkResult.ResolvedDebugInformation},
+	}, nil
+}
+
+// validate runs the assertions and checks the expected relations, returning the errors found,
+// the expected relations as currently computed, and the warnings for the schema.
+func validate(request validateRequest) (apiResult, error) {
+	devContext, devErrors, err := newAPIDevContext(request.Schema, request.Relationships)
+	if err != nil || len(devErrors) > 0 {
+		return apiResult{Errors: toProtoJSON(devErrors)}, err
+	}
+	defer devContext.Dispose()
+
+	operations := []*devinterface.Operation{{
+		SchemaWarningsParameters: &devinterface.SchemaWarningsParameters{},
+	}}
+	if request.Assertions != "" {
+		operations = append(operations, &devinterface.Operation{
+			AssertionsParameters: &devinterface.RunAssertionsParameters{AssertionsYaml: request.Assertions},
+		})
+	}
+	if request.ExpectedRelations != "" {
+		operations = append(operations, &devinterface.Operation{
+			ValidationParameters: &devinterface.RunValidationParameters{ValidationYaml: request.ExpectedRelations},
+		})
+	}
+
+	result := apiResult{Errors: []protoJSON{}, Warnings: []protoJSON{}}
+	for _, operation := range operations {
+		opResult, err := runOperation(devContext, operation)
+		if err != nil {
+			return apiResult{}, err
+		}
+
+		switch {
+		case opResult.SchemaWarningsResult != nil:
+			for _, warning := range opResult.SchemaWarningsResult.Warnings {
+				result.Warnings = append(result.Warnings, protoJSON{warning})
+			}
+
+		case opResult.AssertionsResult != nil:
+			if opResult.AssertionsResult.InputError != nil {
+				result.Errors = append(result.Errors, protoJSON{opResult.AssertionsResult.InputError})
+			}
+			result.Errors = append(result.Errors, toProtoJSON(opResult.AssertionsResult.ValidationErrors)...)
+
+		case opResult.ValidationResult != nil:
+			if opResult.ValidationResult.InputError != nil {
+				result.Errors = append(result.Errors, protoJSON{opResult.ValidationResult.InputError})
+			}
+			result.Errors = append(result.Errors, toProtoJSON(opResult.ValidationResult.ValidationErrors)...)
+			result.UpdatedExpectedRelations = opResult.ValidationResult.UpdatedValidationYaml
+		}
+	}
+	return result, nil
+}
+
+// newAPIDevContext returns a development context for the schema and the newline-separated
+// relationships, or the errors in them.
+func newAPIDevContext(schema string, relationships string) (*development.DevContext, []*devinterface.DeveloperError, error) {
+	parsed, devErrors := parseRelationships(relationships)
+	if len(devErrors) > 0 {
+		return nil, devErrors, nil
+	}
+
+	devContext, devErrs, err := development.NewDevContext(context.Background(), &devinterface.RequestContext{
+		Schema:        schema,
+		Relationships: parsed,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if devErrs != nil && len(devErrs.InputErrors) > 0 {
+		return nil, devErrs.InputErrors, nil
+	}
+	return devContext, nil, nil
+}
+
+func parseRelationships(relationships string) ([]*core.RelationTuple, []*devinterface.DeveloperError) {
+	var parsed []*core.RelationTuple
+	var devErrors []*devinterface.DeveloperError
+	for index, line := range strings.Split(relationships, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if len(trimmed) == 0 || strings.HasPrefix(trimmed, "//") {
+			continue
+		}
+
+		rel, err := tuple.Parse(trimmed)
+		if err != nil {
+			// NOTE: zero is fine here on failure.
+			lineNumber, _ := safecast.ToUint32(index + 1)
+			devErrors = append(devErrors, &devinterface.DeveloperError{
+				Message: fmt.Sprintf("error parsing relationship `%s`: %s", trimmed, err),
+				Kind:    devinterface.DeveloperError_PARSE_ERROR,
+				Source:  devinterface.DeveloperError_RELATIONSHIP,
+				Line:    lineNumber,
+				Context: trimmed,
+			})
+			continue
+		}
+		parsed = append(parsed, rel.ToCoreTuple())
+	}
+	return parsed, devErrors
+}
+
+func checkInputError(err error, context string) *devinterface.DeveloperError {
+	return &devinterface.DeveloperError{
+		Message: err.Error(),
+		Kind:    devinterface.DeveloperError_PARSE_ERROR,
+		Source:  devinterface.DeveloperError_CHECK_WATCH,
+		Context: context,
+	}
+}
+
+// protoJSON marshals a message to JSON as protojson does, for inclusion in a result.
+type protoJSON struct {
+	proto.Message
+}
+
+func (p protoJSON) MarshalJSON() ([]byte, error) {
+	if p.Message == nil || !p.Message.ProtoReflect().IsValid() {
+		return []byte("null"), nil
+	}
+	return protojson.Marshal(p.Message)
+}
+
+func toProtoJSON[T proto.Message](messages []T) []protoJSON {
+	converted := make([]protoJSON, 0, len(messages))
+	for _, message := range messages {
+		converted = append(converted, protoJSON{message})
+	}
+	return converted
+}
+
+// stringArgFunc adapts a function of the API taking a string to a JavaScript function.
+func stringArgFunc(fn func(string) (apiResult, error)) func(js.Value, []js.Value) any {
+	return func(_ js.Value, args []js.Value) any {
+		if len(args) != 1 || args[0].Type() != js.TypeString {
+			return jsResult(apiResult{}, fmt.Errorf("expected a single string argument"))
+		}
+		return jsResult(fn(args[0].String()))
+	}
+}
+
+// objectArgFunc adapts a function of the API taking a request to a JavaScript function taking
+// an object with the fields of the request.
+func objectArgFunc[T any](fn func(T) (apiResult, error)) func(js.Value, []js.Value) any {
+	return func(_ js.Value, args []js.Value) any {
+		if len(args) != 1 || args[0].Type() != js.TypeObject {
+			return jsResult(apiResult{}, fmt.Errorf("expected a single object argument"))
+		}
+
+		var request T
+		encoded := js.Global().Get("JSON").Call("stringify", args[0]).String()
+		if err := json.Unmarshal([]byte(encoded), &request); err != nil {
+			return jsResult(apiResult{}, fmt.Errorf("invalid argument: %w", err))
+		}
+		return jsResult(fn(request))
+	}
+}
+
+// jsResult returns the value returned to JavaScript for the result of a function of the API, which
+// is a JavaScript Error if the result cannot be converted.
+func jsResult(result apiResult, err error) any {
+	value, err := toJSValue(result, err)
+	if err != nil {
+		return js.Global().Get("Error").New(err.Error())
+	}
+	return value
+}
+
+func toJSValue(result apiResult, err error) (js.Value, error) {
+	if err != nil {
+		result = apiResult{InternalError: err.Error()}
+	}
+	if result.Errors == nil {
+		result.Errors = []protoJSON{}
+	}
+
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		return js.Undefined(), fmt.Errorf("failed to marshal result: %w", err)
+	}
+	return js.Global().Get("JSON").Call("parse", string(encoded)), nil
+}
@@ -0,0 +1,135 @@
+//go:build wasm
+// +build wasm
+
+package main
+
+import (
+	"encoding/json"
+	"syscall/js"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	devinterface "github.com/authzed/spicedb/pkg/proto/developer/v1"
+)
+
+const apiTestSchema = `definition user {}
+
+caveat only_on_tuesday(day_of_week string) {
+	day_of_week == 'tuesday'
+}
+
+definition document {
+	relation viewer: user | user with only_on_tuesday
+	permission view = viewer
+}`
+
+func callAPI(t *testing.T, function string, arg any) map[string]any {
+	registerAPI(js.Global())
+	result := js.Global().Get("spicedb").Call(function, js.ValueOf(arg))
+
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal([]byte(js.Global().Get("JSON").Call("stringify", result).String()), &decoded))
+	return decoded
+}
+
+func TestAPICompileSchema(t *testing.T) {
+	result := callAPI(t, "compileSchema", apiTestSchema)
+	require.Empty(t, result["errors"])
+	require.Equal(t, []any{
+		map[string]any{"name": "user", "relations": []any{}, "permissions": []any{}},
+		map[string]any{"name": "document", "relations": []any{"viewer"}, "permissions": []any{"view"}},
+	}, result["definitions"])
+	require.Equal(t, []any{"only_on_tuesday"}, result["caveats"])
+
+	result = callAPI(t, "compileSchema", "definition document {\n\tpermission view = unknown\n}")
+	require.Len(t, result["errors"], 1)
+	devErr := result["errors"].([]any)[0].(map[string]any)
+	require.Contains(t, devErr["message"], "unknown")
+	require.Equal(t, "SCHEMA", devErr["source"])
+
+	result = callAPI(t, "compileSchema", map[string]any{})
+	require.Equal(t, "expected a single string argument", result["internalError"])
+}
+
+func TestAPIFormatSchema(t *testing.T) {
+	result := callAPI(t, "formatSchema", "definition user {}   definition document {\nrelation viewer: user\n}")
+	require.Empty(t, result["errors"])
+	require.Equal(t, "definition user {}\n\ndefinition document {\n\trelation viewer: user\n}", result["formattedSchema"])
+}
+
+func TestAPICheck(t *testing.T) {
+	relationships := "document:first#viewer@user:tom\n// comment\ndocument:second#viewer@user:fred[only_on_tuesday]"
+
+	for _, tc := range []struct {
+		resource           string
+		subject            string
+		caveatContext      map[string]any
+		expectedMembership string
+		expectedMissing    any
+	}{
+		{"document:first#view", "user:tom", nil, "MEMBER", nil},
+		{"document:first#view", "user:fred", nil, "NOT_MEMBER", nil},
+		{"document:second#view", "user:fred", nil, "CAVEATED_MEMBER", []any{"day_of_week"}},
+		{"document:second#view", "user:fred", map[string]any{"day_of_week": "tuesday"}, "MEMBER", nil},
+	} {
+		t.Run(tc.resource+"@"+tc.subject, func(t *testing.T) {
+			request := map[string]any{
+				"schema":        apiTestSchema,
+				"relationships": relationships,
+				"resource":      tc.resource,
+				"subject":       tc.subject,
+			}
+			if tc.caveatContext != nil {
+				request["caveatContext"] = tc.caveatContext
+			}
+
+			result := callAPI(t, "check", request)
+			require.Empty(t, result["errors"])
+			require.Equal(t, tc.expectedMembership, result["membership"])
+			require.Equal(t, tc.expectedMissing, result["missingContext"])
+		})
+	}
+
+	result := callAPI(t, "check", map[string]any{
+		"schema":        apiTestSchema,
+		"relationships": "document:first#viewer@user:tom\ndocument:second#viewer@",
+		"resource":      "document:first#view",
+		"subject":       "user:tom",
+	})
+	require.Len(t, result["errors"], 1)
+	devErr := result["errors"].([]any)[0].(map[string]any)
+	require.Equal(t, "RELATIONSHIP", devErr["source"])
+	require.Equal(t, float64(2), devErr["line"])
+
+	result = callAPI(t, "check", map[string]any{
+		"schema":   apiTestSchema,
+		"resource": "document:first",
+		"subject":  "user:tom",
+	})
+	require.Len(t, result["errors"], 1)
+	require.Contains(t, result["errors"].([]any)[0].(map[string]any)["message"], "invalid resource")
+}
+
+func TestAPIValidate(t *testing.T) {
+	result := callAPI(t, "validate", map[string]any{
+		"schema":            apiTestSchema,
+		"relationships":     "document:first#viewer@user:tom",
+		"assertions":        "assertTrue:\n- document:first#view@user:tom\nassertFalse:\n- document:first#view@user:tom\n",
+		"expectedRelations": "document:first#view:\n- '[user:tom] is <document:first#viewer>'\n",
+	})
+	require.Len(t, result["errors"], 1)
+	devErr := result["errors"].([]any)[0].(map[string]any)
+	require.Equal(t, "ASSERTION_FAILED", devErr["kind"])
+	require.Contains(t, result["updatedExpectedRelations"], "document:first#view")
+	require.Empty(t, result["warnings"])
+}
+
+func TestToJSValueReturnsMarshalErrors(t *testing.T) {
+	// protojson refuses to marshal strings which are not valid UTF-8.
+	_, err := toJSValue(apiResult{Errors: []protoJSON{{&devinterface.DeveloperError{Message: "\xff"}}}}, nil)
+	require.ErrorContains(t, err, "failed to marshal result")
+
+	result := jsResult(apiResult{Errors: []protoJSON{{&devinterface.DeveloperError{Message: "\xff"}}}}, nil)
+	require.True(t, result.(js.Value).InstanceOf(js.Global().Get("Error")))
+}
@@ -11,6 +11,7 @@ import (
 func main() {
 	c := make(chan struct{}, 0)
 	js.Global().Set("runSpiceDBDeveloperRequest", js.FuncOf(runDeveloperRequest))
+	registerAPI(js.Global())
 	fmt.Println("Developer system initialized")
 	<-c
 }
@@ -0,0 +1,141 @@
+package dispatchclient
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"google.golang.org/grpc/metadata"
+
+	v1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+)
+
+// Request is a request made to the dispatch API.
+type Request interface {
+	GetMetadata() *v1.ResolverMeta
+}
+
+// Router selects the dispatch client to which a dispatch request should be forwarded. The method is
+// the full gRPC method name of the request, e.g. v1.DispatchService_DispatchCheck_FullMethodName.
+type Router interface {
+	Route(ctx context.Context, method string, req Request) (v1.DispatchServiceClient, error)
+}
+
+// RouterFunc is a function that implements the Router interface.
+type RouterFunc func(ctx context.Context, method string, req Request) (v1.DispatchServiceClient, error)
+
+// Route implements Router.
+func (rf RouterFunc) Route(ctx context.Context, method string, req Request) (v1.DispatchServiceClient, error) {
+	return rf(ctx, method, req)
+}
+
+// NewRoutingServer returns a dispatch server which forwards each request to the dispatch client
+// selected by the router, returning the client's response(s). Incoming gRPC metadata, other than
+// that used for authentication and transport, is propagated to the selected client.
+func NewRoutingServer(router Router) v1.DispatchServiceServer {
+	return &routingServer{router: router}
+}
+
+type routingServer struct {
+	v1.UnimplementedDispatchServiceServer
+	router Router
+}
+
+func (rs *routingServer) DispatchCheck(ctx context.Context, req *v1.DispatchCheckRequest) (*v1.DispatchCheckResponse, error) {
+	client, err := rs.router.Route(ctx, v1.DispatchService_DispatchCheck_FullMethodName, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return client.DispatchCheck(forwardedContext(ctx), req)
+}
+
+func (rs *routingServer) DispatchExpand(ctx context.Context, req *v1.DispatchExpandRequest) (*v1.DispatchExpandResponse, error) {
+	client, err := rs.router.Route(ctx, v1.DispatchService_DispatchExpand_FullMethodName, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return client.DispatchExpand(forwardedContext(ctx), req)
+}
+
+func (rs *routingServer) DispatchLookupSubjects(req *v1.DispatchLookupSubjectsRequest, stream v1.DispatchService_DispatchLookupSubjectsServer) error {
+	ctx := stream.Context()
+	client, err := rs.router.Route(ctx, v1.DispatchService_DispatchLookupSubjects_FullMethodName, req)
+	if err != nil {
+		return err
+	}
+
+	clientStream, err := client.DispatchLookupSubjects(forwardedContext(ctx), req)
+	if err != nil {
+		return err
+	}
+
+	return forwardStream[v1.DispatchLookupSubjectsResponse](clientStream, stream)
+}
+
+func (rs *routingServer) DispatchLookupResources2(req *v1.DispatchLookupResources2Request, stream v1.DispatchService_DispatchLookupResources2Server) error {
+	ctx := stream.Context()
+	client, err := rs.router.Route(ctx, v1.DispatchService_DispatchLookupResources2_FullMethodName, req)
+	if err != nil {
+		return err
+	}
+
+	clientStream, err := client.DispatchLookupResources2(forwardedContext(ctx), req)
+	if err != nil {
+		return err
+	}
+
+	return forwardStream[v1.DispatchLookupResources2Response](clientStream, stream)
+}
+
+type receiver[T any] interface {
+	Recv() (*T, error)
+}
+
+type sender[T any] interface {
+	Send(*T) error
+}
+
+func forwardStream[T any](from receiver[T], to sender[T]) error {
+	for {
+		resp, err := from.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := to.Send(resp); err != nil {
+			return err
+		}
+	}
+}
+
+// excludedForwardedMetadata are the incoming metadata keys that are not forwarded, as they are
+// either specific to the incoming transport or are set by the outgoing client itself.
+var excludedForwardedMetadata = map[string]struct{}{
+	"authorization":        {},
+	"content-type":         {},
+	"user-agent":           {},
+	"grpc-accept-encoding": {},
+	":authority":           {},
+}
+
+func forwardedContext(ctx context.Context) context.Context {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ctx
+	}
+
+	forwarded := metadata.MD{}
+	for key, values := range md {
+		if _, ok := excludedForwardedMetadata[key]; ok {
+			continue
+		}
+		forwarded[key] = values
+	}
+
+	return metadata.NewOutgoingContext(ctx, forwarded)
+}
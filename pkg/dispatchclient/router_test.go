@@ -0,0 +1,125 @@
+package dispatchclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+
+	humanize "github.com/dustin/go-humanize"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/authzed/spicedb/internal/grpchelpers"
+	corev1 "github.com/authzed/spicedb/pkg/proto/core/v1"
+	v1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+)
+
+type fakeDispatchSvc struct {
+	v1.UnimplementedDispatchServiceServer
+
+	name string
+}
+
+func (fds *fakeDispatchSvc) DispatchCheck(ctx context.Context, _ *v1.DispatchCheckRequest) (*v1.DispatchCheckResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if len(md.Get("tenant")) == 0 {
+		return nil, errors.New("missing forwarded metadata")
+	}
+
+	return &v1.DispatchCheckResponse{
+		Metadata: &v1.ResponseMeta{DispatchCount: 1},
+		ResultsByResourceId: map[string]*v1.ResourceCheckResult{
+			fds.name: {Membership: v1.ResourceCheckResult_MEMBER},
+		},
+	}, nil
+}
+
+func (fds *fakeDispatchSvc) DispatchLookupSubjects(_ *v1.DispatchLookupSubjectsRequest, srv v1.DispatchService_DispatchLookupSubjectsServer) error {
+	for i := 0; i < 3; i++ {
+		if err := srv.Send(&v1.DispatchLookupSubjectsResponse{
+			Metadata: &v1.ResponseMeta{DispatchCount: 1},
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestRoutingServer(t *testing.T) {
+	first := v1.NewDispatchServiceClient(connectionForDispatching(t, &fakeDispatchSvc{name: "first"}))
+	second := v1.NewDispatchServiceClient(connectionForDispatching(t, &fakeDispatchSvc{name: "second"}))
+
+	// Route by the resource type of the request.
+	router := RouterFunc(func(ctx context.Context, method string, req Request) (v1.DispatchServiceClient, error) {
+		if checkReq, ok := req.(*v1.DispatchCheckRequest); ok && checkReq.ResourceRelation.Namespace == "document" {
+			return second, nil
+		}
+		return first, nil
+	})
+
+	client := v1.NewDispatchServiceClient(connectionForDispatching(t, NewRoutingServer(router)))
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "tenant", "sometenant")
+
+	for _, tc := range []struct {
+		namespace string
+		expected  string
+	}{
+		{"document", "second"},
+		{"folder", "first"},
+	} {
+		resp, err := client.DispatchCheck(ctx, &v1.DispatchCheckRequest{
+			ResourceRelation: &corev1.RelationReference{Namespace: tc.namespace, Relation: "view"},
+		})
+		require.NoError(t, err)
+		require.Contains(t, resp.ResultsByResourceId, tc.expected)
+	}
+
+	stream, err := client.DispatchLookupSubjects(ctx, &v1.DispatchLookupSubjectsRequest{})
+	require.NoError(t, err)
+
+	count := 0
+	for {
+		_, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		count++
+	}
+	require.Equal(t, 3, count)
+}
+
+func connectionForDispatching(t *testing.T, svc v1.DispatchServiceServer) *grpc.ClientConn {
+	listener := bufconn.Listen(humanize.MiByte)
+	s := grpc.NewServer()
+
+	v1.RegisterDispatchServiceServer(s, svc)
+
+	go func() {
+		// Ignore any errors
+		_ = s.Serve(listener)
+	}()
+
+	conn, err := grpchelpers.DialAndWait(
+		context.Background(),
+		"",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return listener.Dial()
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		conn.Close()
+		listener.Close()
+		s.Stop()
+	})
+
+	return conn
+}
@@ -0,0 +1,379 @@
+package embedded
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// inProcessConn is a grpc.ClientConnInterface which calls the registered services directly,
+// through the given interceptors, in the calling process. Messages are copied between the client
+// and the services rather than serialized.
+type inProcessConn struct {
+	unaryInterceptors  []grpc.UnaryServerInterceptor
+	streamInterceptors []grpc.StreamServerInterceptor
+
+	unaryMethods  map[string]unaryMethod
+	streamMethods map[string]streamMethod
+
+	// closedCtx is canceled when the connection is closed, to cancel the running streams.
+	closedCtx     context.Context
+	cancelStreams context.CancelFunc
+	streams       sync.WaitGroup
+}
+
+type unaryMethod struct {
+	server any
+	desc   grpc.MethodDesc
+}
+
+type streamMethod struct {
+	server any
+	desc   grpc.StreamDesc
+}
+
+var (
+	_ grpc.ClientConnInterface = (*inProcessConn)(nil)
+	_ grpc.ServiceRegistrar    = (*inProcessConn)(nil)
+)
+
+func newInProcessConn(unaryInterceptors []grpc.UnaryServerInterceptor, streamInterceptors []grpc.StreamServerInterceptor) *inProcessConn {
+	closedCtx, cancelStreams := context.WithCancel(context.Background())
+	return &inProcessConn{
+		unaryInterceptors:  unaryInterceptors,
+		streamInterceptors: streamInterceptors,
+		unaryMethods:       map[string]unaryMethod{},
+		streamMethods:      map[string]streamMethod{},
+		closedCtx:          closedCtx,
+		cancelStreams:      cancelStreams,
+	}
+}
+
+// Close cancels the running streams and waits for their methods to return.
+func (c *inProcessConn) Close() {
+	c.cancelStreams()
+	c.streams.Wait()
+}
+
+// RegisterService registers a service implementation, as done on a grpc.Server.
+func (c *inProcessConn) RegisterService(desc *grpc.ServiceDesc, impl any) {
+	for _, method := range desc.Methods {
+		c.unaryMethods["/"+desc.ServiceName+"/"+method.MethodName] = unaryMethod{impl, method}
+	}
+	for _, stream := range desc.Streams {
+		c.streamMethods["/"+desc.ServiceName+"/"+stream.StreamName] = streamMethod{impl, stream}
+	}
+}
+
+// Invoke calls the unary method with the request, and copies its response into reply.
+func (c *inProcessConn) Invoke(ctx context.Context, method string, args any, reply any, opts ...grpc.CallOption) error {
+	registered, ok := c.unaryMethods[method]
+	if !ok {
+		return status.Errorf(codes.Unimplemented, "unknown method %s", method)
+	}
+
+	ctx, transport := newServerContext(ctx, method)
+	dec := func(in any) error {
+		return copyMessage(in, args)
+	}
+
+	resp, err := registered.desc.Handler(registered.server, ctx, dec, c.chainUnaryInterceptors())
+	for _, opt := range opts {
+		switch opt := opt.(type) {
+		case grpc.HeaderCallOption:
+			*opt.HeaderAddr = transport.getHeader()
+		case grpc.TrailerCallOption:
+			*opt.TrailerAddr = transport.getTrailer()
+		}
+	}
+	if err != nil {
+		return toStatusError(err)
+	}
+	return copyMessage(reply, resp)
+}
+
+func (c *inProcessConn) chainUnaryInterceptors() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		chained := handler
+		for i := len(c.unaryInterceptors) - 1; i >= 0; i-- {
+			interceptor, next := c.unaryInterceptors[i], chained
+			chained = func(ctx context.Context, req any) (any, error) {
+				return interceptor(ctx, req, info, next)
+			}
+		}
+		return chained(ctx, req)
+	}
+}
+
+// NewStream starts the streaming method in a goroutine, connected to the returned client stream.
+// As with a gRPC connection, the stream must be received from until it returns an error or its
+// context must be canceled, for the goroutine to finish.
+func (c *inProcessConn) NewStream(ctx context.Context, _ *grpc.StreamDesc, method string, _ ...grpc.CallOption) (grpc.ClientStream, error) {
+	registered, ok := c.streamMethods[method]
+	if !ok {
+		return nil, status.Errorf(codes.Unimplemented, "unknown method %s", method)
+	}
+
+	clientCtx := ctx
+	ctx, cancel := context.WithCancel(ctx)
+	ctx, transport := newServerContext(ctx, method)
+
+	stream := &inProcessStream{
+		clientCtx: clientCtx,
+		serverCtx: ctx,
+		transport: transport,
+		requests:  make(chan proto.Message),
+		responses: make(chan proto.Message),
+		closeSend: make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+
+	info := &grpc.StreamServerInfo{
+		FullMethod:     method,
+		IsClientStream: registered.desc.ClientStreams,
+		IsServerStream: registered.desc.ServerStreams,
+	}
+	handler := func(srv any, ss grpc.ServerStream) error {
+		return registered.desc.Handler(srv, ss)
+	}
+	for i := len(c.streamInterceptors) - 1; i >= 0; i-- {
+		interceptor, next := c.streamInterceptors[i], handler
+		handler = func(srv any, ss grpc.ServerStream) error {
+			return interceptor(srv, ss, info, next)
+		}
+	}
+
+	stopOnClose := context.AfterFunc(c.closedCtx, cancel)
+	c.streams.Add(1)
+	go func() {
+		defer c.streams.Done()
+		defer stopOnClose()
+		defer cancel()
+		stream.err = handler(registered.server, (*inProcessServerStream)(stream))
+		close(stream.done)
+	}()
+	return stream, nil
+}
+
+// inProcessStream connects a client stream to a streaming method running in a goroutine. It is
+// the client side of the stream, and inProcessServerStream the server side.
+type inProcessStream struct {
+	clientCtx context.Context
+	serverCtx context.Context
+	transport *serverTransportStream
+
+	requests      chan proto.Message
+	responses     chan proto.Message
+	closeSend     chan struct{}
+	closeSendOnce sync.Once
+
+	// done is closed once the method returned err.
+	done chan struct{}
+	err  error
+}
+
+func (s *inProcessStream) Header() (metadata.MD, error) {
+	select {
+	case <-s.transport.headerSent:
+	case <-s.done:
+	case <-s.clientCtx.Done():
+		return nil, toStatusError(s.clientCtx.Err())
+	}
+	return s.transport.getHeader(), nil
+}
+
+func (s *inProcessStream) Trailer() metadata.MD {
+	select {
+	case <-s.done:
+		return s.transport.getTrailer()
+	default:
+		return nil
+	}
+}
+
+func (s *inProcessStream) CloseSend() error {
+	s.closeSendOnce.Do(func() { close(s.closeSend) })
+	return nil
+}
+
+func (s *inProcessStream) Context() context.Context {
+	return s.clientCtx
+}
+
+// SendMsg sends a request to the method. As with gRPC, io.EOF is returned if the method has
+// finished, and its error is returned by RecvMsg.
+func (s *inProcessStream) SendMsg(m any) error {
+	msg, ok := m.(proto.Message)
+	if !ok {
+		return status.Errorf(codes.Internal, "unexpected message type %T", m)
+	}
+
+	select {
+	case s.requests <- proto.Clone(msg):
+		return nil
+	case <-s.done:
+		return io.EOF
+	case <-s.clientCtx.Done():
+		return toStatusError(s.clientCtx.Err())
+	}
+}
+
+// RecvMsg receives a response from the method, or returns io.EOF once it has finished
+// successfully, or its error.
+func (s *inProcessStream) RecvMsg(m any) error {
+	select {
+	case resp := <-s.responses:
+		return copyMessage(m, resp)
+	case <-s.done:
+		if s.err != nil {
+			return toStatusError(s.err)
+		}
+		return io.EOF
+	case <-s.clientCtx.Done():
+		return toStatusError(s.clientCtx.Err())
+	}
+}
+
+type inProcessServerStream inProcessStream
+
+func (s *inProcessServerStream) SetHeader(md metadata.MD) error {
+	return s.transport.SetHeader(md)
+}
+
+func (s *inProcessServerStream) SendHeader(md metadata.MD) error {
+	return s.transport.SendHeader(md)
+}
+
+func (s *inProcessServerStream) SetTrailer(md metadata.MD) {
+	_ = s.transport.SetTrailer(md)
+}
+
+func (s *inProcessServerStream) Context() context.Context {
+	return s.serverCtx
+}
+
+func (s *inProcessServerStream) SendMsg(m any) error {
+	msg, ok := m.(proto.Message)
+	if !ok {
+		return status.Errorf(codes.Internal, "unexpected message type %T", m)
+	}
+
+	_ = s.transport.SendHeader(nil)
+	select {
+	case s.responses <- proto.Clone(msg):
+		return nil
+	case <-s.serverCtx.Done():
+		return toStatusError(s.serverCtx.Err())
+	}
+}
+
+func (s *inProcessServerStream) RecvMsg(m any) error {
+	select {
+	case req := <-s.requests:
+		return copyMessage(m, req)
+	case <-s.closeSend:
+		return io.EOF
+	case <-s.serverCtx.Done():
+		return toStatusError(s.serverCtx.Err())
+	}
+}
+
+// serverTransportStream records the metadata set by the services, so that grpc.SetHeader and
+// similar functions work as they do in a gRPC server.
+type serverTransportStream struct {
+	method string
+
+	lock           sync.Mutex
+	header         metadata.MD
+	trailer        metadata.MD
+	headerSent     chan struct{}
+	headerSentOnce sync.Once
+}
+
+func newServerContext(ctx context.Context, method string) (context.Context, *serverTransportStream) {
+	// Metadata sent by the client is received by the services as incoming metadata.
+	md, _ := metadata.FromOutgoingContext(ctx)
+	ctx = metadata.NewIncomingContext(ctx, md.Copy())
+
+	transport := &serverTransportStream{method: method, headerSent: make(chan struct{})}
+	return grpc.NewContextWithServerTransportStream(ctx, transport), transport
+}
+
+func (t *serverTransportStream) Method() string {
+	return t.method
+}
+
+func (t *serverTransportStream) SetHeader(md metadata.MD) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.header = metadata.Join(t.header, md)
+	return nil
+}
+
+func (t *serverTransportStream) SendHeader(md metadata.MD) error {
+	if err := t.SetHeader(md); err != nil {
+		return err
+	}
+	t.headerSentOnce.Do(func() { close(t.headerSent) })
+	return nil
+}
+
+func (t *serverTransportStream) SetTrailer(md metadata.MD) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.trailer = metadata.Join(t.trailer, md)
+	return nil
+}
+
+func (t *serverTransportStream) getHeader() metadata.MD {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.header.Copy()
+}
+
+func (t *serverTransportStream) getTrailer() metadata.MD {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.trailer.Copy()
+}
+
+func copyMessage(dst any, src any) error {
+	dstMsg, ok := dst.(proto.Message)
+	if !ok {
+		return status.Errorf(codes.Internal, "unexpected message type %T", dst)
+	}
+	srcMsg, ok := src.(proto.Message)
+	if !ok {
+		return status.Errorf(codes.Internal, "unexpected message type %T", src)
+	}
+	if dstMsg.ProtoReflect().Descriptor().FullName() != srcMsg.ProtoReflect().Descriptor().FullName() {
+		return status.Errorf(codes.Internal, "cannot copy %s into %s",
+			srcMsg.ProtoReflect().Descriptor().FullName(), dstMsg.ProtoReflect().Descriptor().FullName())
+	}
+
+	proto.Reset(dstMsg)
+	proto.Merge(dstMsg, srcMsg)
+	return nil
+}
+
+// toStatusError converts context errors to their gRPC status, as returned by a gRPC connection.
+func toStatusError(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	switch {
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	default:
+		return status.Error(codes.Unknown, err.Error())
+	}
+}
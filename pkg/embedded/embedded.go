@@ -0,0 +1,185 @@
+// Package embedded runs SpiceDB within a Go application, exposing the v1 API as direct function
+// calls rather than over the network.
+//
+// The Client implements the same v1 service clients as the authzed-go client, so code written
+// against a remote SpiceDB works unchanged against an embedded one:
+//
+//	client, err := embedded.NewClient(ctx)
+//	if err != nil {
+//		return err
+//	}
+//	defer client.Close()
+//
+//	resp, err := client.CheckPermission(ctx, &v1.CheckPermissionRequest{...})
+//
+// Requests are handled by the same services and validation as a SpiceDB server, but without
+// authentication: every caller in the process is trusted.
+package embedded
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"google.golang.org/grpc"
+
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/internal/dispatch"
+	"github.com/authzed/spicedb/internal/dispatch/graph"
+	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
+	dispatchmw "github.com/authzed/spicedb/internal/middleware/dispatcher"
+	"github.com/authzed/spicedb/internal/middleware/servicespecific"
+	v1svc "github.com/authzed/spicedb/internal/services/v1"
+	"github.com/authzed/spicedb/pkg/datastore"
+	consistencymw "github.com/authzed/spicedb/pkg/middleware/consistency"
+)
+
+const (
+	defaultRevisionQuantization = 5 * time.Second
+	defaultGCWindow             = 24 * time.Hour
+	defaultConcurrencyLimit     = 50
+	defaultDispatchChunkSize    = 100
+	defaultWatchHeartbeat       = 1 * time.Second
+)
+
+// Client is an embedded SpiceDB, with the v1 services called in the process.
+type Client struct {
+	v1.PermissionsServiceClient
+	v1.SchemaServiceClient
+	v1.WatchServiceClient
+	v1.ExperimentalServiceClient
+
+	conn       *inProcessConn
+	ds         datastore.Datastore
+	dispatcher dispatch.Dispatcher
+}
+
+type config struct {
+	ds                           datastore.Datastore
+	concurrencyLimit             uint16
+	expiringRelationshipsEnabled bool
+	watchHeartbeat               time.Duration
+}
+
+// Option configures an embedded SpiceDB.
+type Option func(*config)
+
+// WithDatastore sets the datastore, such as one created with the NewDatastore function of the
+// pkg/cmd/datastore package, instead of the default in-memory datastore. The client takes
+// ownership of the datastore and closes it when closed.
+func WithDatastore(ds datastore.Datastore) Option {
+	return func(c *config) {
+		c.ds = ds
+	}
+}
+
+// WithConcurrencyLimit sets the maximum number of goroutines created by each request to
+// compute its result.
+func WithConcurrencyLimit(limit uint16) Option {
+	return func(c *config) {
+		c.concurrencyLimit = limit
+	}
+}
+
+// WithExpiringRelationshipsEnabled enables writing relationships with an expiration.
+func WithExpiringRelationshipsEnabled(enabled bool) Option {
+	return func(c *config) {
+		c.expiringRelationshipsEnabled = enabled
+	}
+}
+
+// WithWatchHeartbeat sets the interval between heartbeats of the Watch API.
+func WithWatchHeartbeat(heartbeat time.Duration) Option {
+	return func(c *config) {
+		c.watchHeartbeat = heartbeat
+	}
+}
+
+// NewClient returns an embedded SpiceDB, backed by an in-memory datastore unless another is
+// given. It must be closed to release its resources.
+func NewClient(ctx context.Context, opts ...Option) (*Client, error) {
+	c := config{
+		concurrencyLimit: defaultConcurrencyLimit,
+		watchHeartbeat:   defaultWatchHeartbeat,
+	}
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	ds := c.ds
+	if ds == nil {
+		memoryDS, err := memdb.NewMemdbDatastore(0, defaultRevisionQuantization, defaultGCWindow)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create in-memory datastore: %w", err)
+		}
+		ds = memoryDS
+	}
+
+	if startable := datastore.UnwrapAs[datastore.StartableDatastore](ds); startable != nil {
+		if err := startable.Start(ctx); err != nil {
+			return nil, errors.Join(fmt.Errorf("failed to start datastore: %w", err), ds.Close())
+		}
+	}
+
+	dispatcher := graph.NewLocalOnlyDispatcher(c.concurrencyLimit, defaultDispatchChunkSize)
+
+	conn := newInProcessConn(
+		[]grpc.UnaryServerInterceptor{
+			dispatchmw.UnaryServerInterceptor(dispatcher),
+			datastoremw.UnaryServerInterceptor(ds),
+			consistencymw.UnaryServerInterceptor("embedded"),
+			servicespecific.UnaryServerInterceptor,
+		},
+		[]grpc.StreamServerInterceptor{
+			dispatchmw.StreamServerInterceptor(dispatcher),
+			datastoremw.StreamServerInterceptor(ds),
+			consistencymw.StreamServerInterceptor("embedded"),
+			servicespecific.StreamServerInterceptor,
+		},
+	)
+
+	permSysConfig := v1svc.PermissionsServerConfig{
+		MaxPreconditionsCount:           1000,
+		MaxUpdatesPerWrite:              1000,
+		MaximumAPIDepth:                 50,
+		MaxCaveatContextSize:            4096,
+		MaxRelationshipContextSize:      25000,
+		MaxDatastoreReadPageSize:        1000,
+		StreamingAPITimeout:             30 * time.Second,
+		MaxReadRelationshipsLimit:       1000,
+		MaxDeleteRelationshipsLimit:     1000,
+		MaxLookupResourcesLimit:         1000,
+		MaxBulkExportRelationshipsLimit: 10_000,
+		DispatchChunkSize:               defaultDispatchChunkSize,
+		ExpiringRelationshipsEnabled:    c.expiringRelationshipsEnabled,
+	}
+	v1.RegisterPermissionsServiceServer(conn, v1svc.NewPermissionsServer(dispatcher, permSysConfig))
+	v1.RegisterExperimentalServiceServer(conn, v1svc.NewExperimentalServer(dispatcher, permSysConfig))
+	v1.RegisterSchemaServiceServer(conn, v1svc.NewSchemaServer(false, c.expiringRelationshipsEnabled))
+	v1.RegisterWatchServiceServer(conn, v1svc.NewWatchServer(c.watchHeartbeat, nil))
+
+	return &Client{
+		PermissionsServiceClient:  v1.NewPermissionsServiceClient(conn),
+		SchemaServiceClient:       v1.NewSchemaServiceClient(conn),
+		WatchServiceClient:        v1.NewWatchServiceClient(conn),
+		ExperimentalServiceClient: v1.NewExperimentalServiceClient(conn),
+		conn:                      conn,
+		ds:                        ds,
+		dispatcher:                dispatcher,
+	}, nil
+}
+
+// Conn returns the connection to the services of the embedded SpiceDB, to create other clients
+// of them.
+func (c *Client) Conn() grpc.ClientConnInterface {
+	return c.conn
+}
+
+// Close cancels the running streams, then closes the dispatcher and the datastore of the embedded
+// SpiceDB.
+func (c *Client) Close() error {
+	c.conn.Close()
+	return errors.Join(c.dispatcher.Close(), c.ds.Close())
+}
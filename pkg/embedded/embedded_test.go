@@ -0,0 +1,114 @@
+package embedded
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/authzed/spicedb/pkg/testutil"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+const testSchema = `
+definition user {}
+
+definition document {
+	relation viewer: user
+	permission view = viewer
+}`
+
+func TestClient(t *testing.T) {
+	defer goleak.VerifyNone(t, append(testutil.GoLeakIgnores(), goleak.IgnoreCurrent())...)
+
+	ctx := context.Background()
+	client, err := NewClient(ctx)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, client.Close()) }()
+
+	_, err = client.WriteSchema(ctx, &v1.WriteSchemaRequest{Schema: testSchema})
+	require.NoError(t, err)
+
+	// Client streaming.
+	importStream, err := client.ImportBulkRelationships(ctx)
+	require.NoError(t, err)
+	require.NoError(t, importStream.Send(&v1.ImportBulkRelationshipsRequest{
+		Relationships: []*v1.Relationship{
+			tuple.ToV1Relationship(tuple.MustParse("document:first#viewer@user:tom")),
+			tuple.ToV1Relationship(tuple.MustParse("document:second#viewer@user:tom")),
+		},
+	}))
+	importResp, err := importStream.CloseAndRecv()
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), importResp.NumLoaded)
+
+	// Unary.
+	writeResp, err := client.WriteRelationships(ctx, &v1.WriteRelationshipsRequest{
+		Updates: []*v1.RelationshipUpdate{
+			tuple.MustUpdateToV1RelationshipUpdate(tuple.Create(tuple.MustParse("document:third#viewer@user:tom"))),
+		},
+	})
+	require.NoError(t, err)
+
+	consistency := &v1.Consistency{Requirement: &v1.Consistency_AtLeastAsFresh{AtLeastAsFresh: writeResp.WrittenAt}}
+	checkResp, err := client.CheckPermission(ctx, &v1.CheckPermissionRequest{
+		Consistency: consistency,
+		Resource:    &v1.ObjectReference{ObjectType: "document", ObjectId: "third"},
+		Permission:  "view",
+		Subject:     &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: "user", ObjectId: "tom"}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION, checkResp.Permissionship)
+
+	// Server streaming.
+	lookupStream, err := client.LookupResources(ctx, &v1.LookupResourcesRequest{
+		Consistency:        consistency,
+		ResourceObjectType: "document",
+		Permission:         "view",
+		Subject:            &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: "user", ObjectId: "tom"}},
+	})
+	require.NoError(t, err)
+
+	var resourceIDs []string
+	for {
+		resp, err := lookupStream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		resourceIDs = append(resourceIDs, resp.ResourceObjectId)
+	}
+	require.ElementsMatch(t, []string{"first", "second", "third"}, resourceIDs)
+
+	// Requests are validated as by a server.
+	_, err = client.CheckPermission(ctx, &v1.CheckPermissionRequest{
+		Resource:   &v1.ObjectReference{ObjectType: "document", ObjectId: "first"},
+		Permission: "view",
+	})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = client.ReadSchema(ctx, &v1.ReadSchemaRequest{})
+	require.NoError(t, err)
+}
+
+func TestClientCanceledStream(t *testing.T) {
+	defer goleak.VerifyNone(t, append(testutil.GoLeakIgnores(), goleak.IgnoreCurrent())...)
+
+	client, err := NewClient(context.Background())
+	require.NoError(t, err)
+	defer func() { require.NoError(t, client.Close()) }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	watchStream, err := client.Watch(ctx, &v1.WatchRequest{})
+	require.NoError(t, err)
+
+	cancel()
+	_, err = watchStream.Recv()
+	require.Equal(t, codes.Canceled, status.Code(err))
+}
@@ -0,0 +1,341 @@
+// Package errorcodes defines the catalogue of stable, machine-readable codes attached to every
+// error returned by the SpiceDB API.
+//
+// The code of an error is found in the `error_code` metadata of the ErrorInfo details of its gRPC
+// status. Unlike gRPC status codes, which group many unrelated failures, and error reasons,
+// which are not set on every error, the code identifies the exact kind of failure, so that
+// clients can reliably branch on it:
+//
+//	switch code, _ := errorcodes.FromError(err); code {
+//	case errorcodes.PreconditionMustMatchFailed:
+//		...
+//	case errorcodes.RevisionGarbageCollected:
+//		...
+//	}
+//
+// Codes are never renamed or reused once released; new codes may be added at any time, so
+// clients should handle codes they do not know by their category or gRPC status code.
+package errorcodes
+
+import (
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+)
+
+// MetadataKey is the key of the code in the metadata of the ErrorInfo details of an error.
+const MetadataKey = "error_code"
+
+// Code is a stable, machine-readable code identifying the kind of an API error.
+type Code string
+
+// Category is the category of an error code, grouping codes to which clients generally react
+// in the same way.
+type Category string
+
+const (
+	// CategoryRequest is the category of errors in the contents of a request, which will fail
+	// again if retried unchanged.
+	CategoryRequest Category = "request"
+
+	// CategoryLimit is the category of errors for requests exceeding a configured limit.
+	CategoryLimit Category = "limit"
+
+	// CategoryPrecondition is the category of errors for requests whose preconditions on the
+	// stored data or configuration were not met.
+	CategoryPrecondition Category = "precondition"
+
+	// CategorySchema is the category of errors in, or caused by, the schema.
+	CategorySchema Category = "schema"
+
+	// CategoryStaleness is the category of errors for revisions that are invalid, or no longer
+	// available.
+	CategoryStaleness Category = "staleness"
+
+	// CategoryUnavailable is the category of transient errors, for which the request can be
+	// retried.
+	CategoryUnavailable Category = "unavailable"
+
+	// CategoryAuth is the category of authentication and authorization errors.
+	CategoryAuth Category = "auth"
+
+	// CategoryInternal is the category of internal errors of the server.
+	CategoryInternal Category = "internal"
+)
+
+// Request errors.
+const (
+	InvalidArgument                    Code = "INVALID_ARGUMENT"
+	InvalidCursor                      Code = "INVALID_CURSOR"
+	InvalidFilter                      Code = "INVALID_FILTER"
+	InvalidSubjectType                 Code = "INVALID_SUBJECT_TYPE"
+	WildcardNotAllowed                 Code = "WILDCARD_NOT_ALLOWED"
+	DuplicateRelationshipUpdate        Code = "DUPLICATE_RELATIONSHIP_UPDATE"
+	AttemptToRecreateRelationship      Code = "ATTEMPT_TO_RECREATE_RELATIONSHIP"
+	CaveatParameterTypeError           Code = "CAVEAT_PARAMETER_TYPE_ERROR"
+	CaveatEvaluationError              Code = "CAVEAT_EVALUATION_ERROR"
+	EmptyPrecondition                  Code = "EMPTY_PRECONDITION"
+	InvalidExpectedRelationshipVersion Code = "INVALID_EXPECTED_RELATIONSHIP_VERSION"
+	IdempotencyKeyReused               Code = "IDEMPOTENCY_KEY_REUSED"
+	NotFound                           Code = "NOT_FOUND"
+	AlreadyExists                      Code = "ALREADY_EXISTS"
+	Unimplemented                      Code = "UNIMPLEMENTED"
+)
+
+// Limit errors.
+const (
+	ExceedsMaximumLimit                        Code = "EXCEEDS_MAXIMUM_LIMIT"
+	TooManyUpdates                             Code = "TOO_MANY_UPDATES"
+	TooManyPreconditions                       Code = "TOO_MANY_PRECONDITIONS"
+	TooManyChecks                              Code = "TOO_MANY_CHECKS"
+	TooManyRelationshipsForTransactionalDelete Code = "TOO_MANY_RELATIONSHIPS_FOR_TRANSACTIONAL_DELETE"
+	MaxRelationshipContextSize                 Code = "MAX_RELATIONSHIP_CONTEXT_SIZE"
+	TransactionMetadataTooLarge                Code = "TRANSACTION_METADATA_TOO_LARGE"
+	MaximumDepthExceeded                       Code = "MAXIMUM_DEPTH_EXCEEDED"
+	ResourceExhausted                          Code = "RESOURCE_EXHAUSTED"
+	QuotaExceeded                              Code = "QUOTA_EXCEEDED"
+)
+
+// Precondition errors.
+const (
+	PreconditionFailed             Code = "PRECONDITION_FAILED"
+	PreconditionMustMatchFailed    Code = "PRECONDITION_MUST_MATCH_FAILED"
+	PreconditionMustNotMatchFailed Code = "PRECONDITION_MUST_NOT_MATCH_FAILED"
+	RelationshipVersionMismatch    Code = "RELATIONSHIP_VERSION_MISMATCH"
+	CounterAlreadyRegistered       Code = "COUNTER_ALREADY_REGISTERED"
+	CounterNotRegistered           Code = "COUNTER_NOT_REGISTERED"
+	WatchDisabled                  Code = "WATCH_DISABLED"
+	DeletionProtected              Code = "DELETION_PROTECTED"
+)
+
+// Schema errors.
+const (
+	SchemaParseError            Code = "SCHEMA_PARSE_ERROR"
+	SchemaTypeError             Code = "SCHEMA_TYPE_ERROR"
+	SchemaWriteBlocked          Code = "SCHEMA_WRITE_BLOCKED"
+	SchemaWriteUnreferencedData Code = "SCHEMA_WRITE_UNREFERENCED_DATA"
+	UnknownDefinition           Code = "UNKNOWN_DEFINITION"
+	UnknownRelationOrPermission Code = "UNKNOWN_RELATION_OR_PERMISSION"
+	UnknownCaveat               Code = "UNKNOWN_CAVEAT"
+	NotAPermission              Code = "NOT_A_PERMISSION"
+	CannotUpdatePermission      Code = "CANNOT_UPDATE_PERMISSION"
+)
+
+// Staleness errors.
+const (
+	InvalidRevision          Code = "INVALID_REVISION"
+	RevisionGarbageCollected Code = "REVISION_GARBAGE_COLLECTED"
+)
+
+// Unavailable errors.
+const (
+	ServiceReadOnly          Code = "SERVICE_READ_ONLY"
+	ServerShuttingDown       Code = "SERVER_SHUTTING_DOWN"
+	SerializationFailure     Code = "SERIALIZATION_FAILURE"
+	TooManyConcurrentUpdates Code = "TOO_MANY_CONCURRENT_UPDATES"
+	Unavailable              Code = "UNAVAILABLE"
+	Aborted                  Code = "ABORTED"
+	DeadlineExceeded         Code = "DEADLINE_EXCEEDED"
+	Canceled                 Code = "CANCELED"
+)
+
+// Auth errors.
+const (
+	Unauthenticated  Code = "UNAUTHENTICATED"
+	PermissionDenied Code = "PERMISSION_DENIED"
+)
+
+// Internal errors.
+const (
+	Internal                   Code = "INTERNAL"
+	ChunkedWriteRollbackFailed Code = "CHUNKED_WRITE_ROLLBACK_FAILED"
+	Unknown                    Code = "UNKNOWN"
+)
+
+// Entry is the entry of a code in the catalogue.
+type Entry struct {
+	// Code is the code of the entry.
+	Code Code
+
+	// Category is the category of the code.
+	Category Category
+
+	// GRPCCode is the gRPC status code of the errors with the code.
+	GRPCCode codes.Code
+
+	// Reason is the error reason of the V1 API set on the errors with the code, which is
+	// unspecified for the codes without a matching reason.
+	Reason v1.ErrorReason
+
+	// Description describes the errors with the code.
+	Description string
+}
+
+var catalogue = []Entry{
+	{InvalidArgument, CategoryRequest, codes.InvalidArgument, v1.ErrorReason_ERROR_REASON_UNSPECIFIED, "the request is invalid"},
+	{InvalidCursor, CategoryRequest, codes.FailedPrecondition, v1.ErrorReason_ERROR_REASON_INVALID_CURSOR, "the cursor is invalid, or was created by another request"},
+	{InvalidFilter, CategoryRequest, codes.InvalidArgument, v1.ErrorReason_ERROR_REASON_INVALID_FILTER, "the relationship filter is invalid"},
+	{InvalidSubjectType, CategoryRequest, codes.InvalidArgument, v1.ErrorReason_ERROR_REASON_INVALID_SUBJECT_TYPE, "the subject type is not allowed on the relation"},
+	{WildcardNotAllowed, CategoryRequest, codes.InvalidArgument, v1.ErrorReason_ERROR_REASON_WILDCARD_NOT_ALLOWED, "a wildcard subject is not allowed by the request"},
+	{DuplicateRelationshipUpdate, CategoryRequest, codes.InvalidArgument, v1.ErrorReason_ERROR_REASON_UPDATES_ON_SAME_RELATIONSHIP, "a relationship is updated more than once in the request"},
+	{AttemptToRecreateRelationship, CategoryRequest, codes.AlreadyExists, v1.ErrorReason_ERROR_REASON_ATTEMPT_TO_RECREATE_RELATIONSHIP, "a relationship created by the request already exists"},
+	{CaveatParameterTypeError, CategoryRequest, codes.InvalidArgument, v1.ErrorReason_ERROR_REASON_CAVEAT_PARAMETER_TYPE_ERROR, "a caveat parameter has the wrong type"},
+	{CaveatEvaluationError, CategoryRequest, codes.InvalidArgument, v1.ErrorReason_ERROR_REASON_CAVEAT_EVALUATION_ERROR, "a caveat could not be evaluated"},
+	{EmptyPrecondition, CategoryRequest, codes.InvalidArgument, v1.ErrorReason_ERROR_REASON_EMPTY_PRECONDITION, "a precondition of the request is empty"},
+	{InvalidExpectedRelationshipVersion, CategoryRequest, codes.InvalidArgument, v1.ErrorReason_ERROR_REASON_UNSPECIFIED, "an expected relationship version of the write is invalid"},
+	{IdempotencyKeyReused, CategoryRequest, codes.InvalidArgument, v1.ErrorReason_ERROR_REASON_UNSPECIFIED, "the idempotency key was already used for another write"},
+	{NotFound, CategoryRequest, codes.NotFound, v1.ErrorReason_ERROR_REASON_UNSPECIFIED, "the requested entity was not found"},
+	{AlreadyExists, CategoryRequest, codes.AlreadyExists, v1.ErrorReason_ERROR_REASON_UNSPECIFIED, "the entity to create already exists"},
+	{Unimplemented, CategoryRequest, codes.Unimplemented, v1.ErrorReason_ERROR_REASON_UNSPECIFIED, "the operation is not implemented or not enabled"},
+
+	{ExceedsMaximumLimit, CategoryLimit, codes.InvalidArgument, v1.ErrorReason_ERROR_REASON_EXCEEDS_MAXIMUM_ALLOWABLE_LIMIT, "the limit of the request is greater than the maximum allowed"},
+	{TooManyUpdates, CategoryLimit, codes.InvalidArgument, v1.ErrorReason_ERROR_REASON_TOO_MANY_UPDATES_IN_REQUEST, "the write has more updates than allowed"},
+	{TooManyPreconditions, CategoryLimit, codes.InvalidArgument, v1.ErrorReason_ERROR_REASON_TOO_MANY_PRECONDITIONS_IN_REQUEST, "the write has more preconditions than allowed"},
+	{TooManyChecks, CategoryLimit, codes.InvalidArgument, v1.ErrorReason_ERROR_REASON_TOO_MANY_CHECKS_IN_REQUEST, "the bulk check has more checks than allowed"},
+	{TooManyRelationshipsForTransactionalDelete, CategoryLimit, codes.InvalidArgument, v1.ErrorReason_ERROR_REASON_TOO_MANY_RELATIONSHIPS_FOR_TRANSACTIONAL_DELETE, "the delete matches more relationships than can be deleted in a transaction"},
+	{MaxRelationshipContextSize, CategoryLimit, codes.InvalidArgument, v1.ErrorReason_ERROR_REASON_MAX_RELATIONSHIP_CONTEXT_SIZE, "the caveat context of a relationship is larger than allowed"},
+	{TransactionMetadataTooLarge, CategoryLimit, codes.InvalidArgument, v1.ErrorReason_ERROR_REASON_TRANSACTION_METADATA_TOO_LARGE, "the transaction metadata is larger than allowed"},
+	{MaximumDepthExceeded, CategoryLimit, codes.ResourceExhausted, v1.ErrorReason_ERROR_REASON_MAXIMUM_DEPTH_EXCEEDED, "the request exceeded the maximum dispatch depth"},
+	{ResourceExhausted, CategoryLimit, codes.ResourceExhausted, v1.ErrorReason_ERROR_REASON_UNSPECIFIED, "the request exceeded a limit of the server"},
+	{QuotaExceeded, CategoryLimit, codes.ResourceExhausted, v1.ErrorReason_ERROR_REASON_UNSPECIFIED, "the caller exceeded its usage quota until the time given by the RetryInfo"},
+
+	{PreconditionFailed, CategoryPrecondition, codes.FailedPrecondition, v1.ErrorReason_ERROR_REASON_WRITE_OR_DELETE_PRECONDITION_FAILURE, "a precondition of the request was not met"},
+	{PreconditionMustMatchFailed, CategoryPrecondition, codes.FailedPrecondition, v1.ErrorReason_ERROR_REASON_WRITE_OR_DELETE_PRECONDITION_FAILURE, "a precondition required relationships that do not exist"},
+	{PreconditionMustNotMatchFailed, CategoryPrecondition, codes.FailedPrecondition, v1.ErrorReason_ERROR_REASON_WRITE_OR_DELETE_PRECONDITION_FAILURE, "a precondition forbade relationships that exist"},
+	{RelationshipVersionMismatch, CategoryPrecondition, codes.FailedPrecondition, v1.ErrorReason_ERROR_REASON_WRITE_OR_DELETE_PRECONDITION_FAILURE, "a relationship is not at the version expected by the write"},
+	{CounterAlreadyRegistered, CategoryPrecondition, codes.FailedPrecondition, v1.ErrorReason_ERROR_REASON_COUNTER_ALREADY_REGISTERED, "the relationship counter is already registered"},
+	{CounterNotRegistered, CategoryPrecondition, codes.FailedPrecondition, v1.ErrorReason_ERROR_REASON_COUNTER_NOT_REGISTERED, "the relationship counter is not registered"},
+	{WatchDisabled, CategoryPrecondition, codes.FailedPrecondition, v1.ErrorReason_ERROR_REASON_UNSPECIFIED, "watch is disabled on the datastore"},
+	{DeletionProtected, CategoryPrecondition, codes.FailedPrecondition, v1.ErrorReason_ERROR_REASON_UNSPECIFIED, "the delete is denied by a deletion protection rule unless overridden with the admin token"},
+
+	{SchemaParseError, CategorySchema, codes.InvalidArgument, v1.ErrorReason_ERROR_REASON_SCHEMA_PARSE_ERROR, "the schema could not be parsed"},
+	{SchemaTypeError, CategorySchema, codes.FailedPrecondition, v1.ErrorReason_ERROR_REASON_SCHEMA_TYPE_ERROR, "the schema is not valid"},
+	{SchemaWriteBlocked, CategorySchema, codes.InvalidArgument, v1.ErrorReason_ERROR_REASON_SCHEMA_TYPE_ERROR, "existing relationships would no longer conform to the written schema"},
+	{SchemaWriteUnreferencedData, CategorySchema, codes.InvalidArgument, v1.ErrorReason_ERROR_REASON_SCHEMA_TYPE_ERROR, "the written schema would leave existing data unreferenced"},
+	{UnknownDefinition, CategorySchema, codes.FailedPrecondition, v1.ErrorReason_ERROR_REASON_UNKNOWN_DEFINITION, "the definition is not in the schema"},
+	{UnknownRelationOrPermission, CategorySchema, codes.FailedPrecondition, v1.ErrorReason_ERROR_REASON_UNKNOWN_RELATION_OR_PERMISSION, "the relation or permission is not in the schema"},
+	{UnknownCaveat, CategorySchema, codes.FailedPrecondition, v1.ErrorReason_ERROR_REASON_UNKNOWN_CAVEAT, "the caveat is not in the schema"},
+	{NotAPermission, CategorySchema, codes.InvalidArgument, v1.ErrorReason_ERROR_REASON_UNKNOWN_RELATION_OR_PERMISSION, "the relation is not a permission"},
+	{CannotUpdatePermission, CategorySchema, codes.InvalidArgument, v1.ErrorReason_ERROR_REASON_CANNOT_UPDATE_PERMISSION, "relationships cannot be written to a permission"},
+
+	{InvalidRevision, CategoryStaleness, codes.OutOfRange, v1.ErrorReason_ERROR_REASON_UNSPECIFIED, "the revision of the zedtoken is invalid or unknown"},
+	{RevisionGarbageCollected, CategoryStaleness, codes.OutOfRange, v1.ErrorReason_ERROR_REASON_UNSPECIFIED, "the revision is older than the garbage collection window"},
+
+	{ServiceReadOnly, CategoryUnavailable, codes.Unavailable, v1.ErrorReason_ERROR_REASON_SERVICE_READ_ONLY, "the service is in read-only mode"},
+	{ServerShuttingDown, CategoryUnavailable, codes.Unavailable, v1.ErrorReason_ERROR_REASON_UNSPECIFIED, "the server is shutting down"},
+	{SerializationFailure, CategoryUnavailable, codes.Aborted, v1.ErrorReason_ERROR_REASON_SERIALIZATION_FAILURE, "the transaction conflicted with another and can be retried"},
+	{TooManyConcurrentUpdates, CategoryUnavailable, codes.Aborted, v1.ErrorReason_ERROR_REASON_INMEMORY_TOO_MANY_CONCURRENT_UPDATES, "the in-memory datastore received too many concurrent updates"},
+	{Unavailable, CategoryUnavailable, codes.Unavailable, v1.ErrorReason_ERROR_REASON_UNSPECIFIED, "the service is unavailable"},
+	{Aborted, CategoryUnavailable, codes.Aborted, v1.ErrorReason_ERROR_REASON_UNSPECIFIED, "the operation was aborted and can be retried"},
+	{DeadlineExceeded, CategoryUnavailable, codes.DeadlineExceeded, v1.ErrorReason_ERROR_REASON_UNSPECIFIED, "the deadline of the request was exceeded"},
+	{Canceled, CategoryUnavailable, codes.Canceled, v1.ErrorReason_ERROR_REASON_UNSPECIFIED, "the request was canceled"},
+
+	{Unauthenticated, CategoryAuth, codes.Unauthenticated, v1.ErrorReason_ERROR_REASON_UNSPECIFIED, "the request is not authenticated"},
+	{PermissionDenied, CategoryAuth, codes.PermissionDenied, v1.ErrorReason_ERROR_REASON_UNSPECIFIED, "the caller is not allowed to make the request"},
+
+	{Internal, CategoryInternal, codes.Internal, v1.ErrorReason_ERROR_REASON_UNSPECIFIED, "an internal error occurred"},
+	{ChunkedWriteRollbackFailed, CategoryInternal, codes.Internal, v1.ErrorReason_ERROR_REASON_UNSPECIFIED, "a chunked write failed and was left partially applied"},
+	{Unknown, CategoryInternal, codes.Unknown, v1.ErrorReason_ERROR_REASON_UNSPECIFIED, "an unknown error occurred"},
+}
+
+var (
+	entriesByCode = map[Code]Entry{}
+
+	// codesByReason are the codes for errors with a reason but without a code, which are the
+	// first codes of the catalogue with each reason.
+	codesByReason = map[v1.ErrorReason]Code{}
+
+	// codesByGRPCCode are the codes for errors with neither a reason nor a code.
+	codesByGRPCCode = map[codes.Code]Code{
+		codes.Canceled:           Canceled,
+		codes.Unknown:            Unknown,
+		codes.InvalidArgument:    InvalidArgument,
+		codes.DeadlineExceeded:   DeadlineExceeded,
+		codes.NotFound:           NotFound,
+		codes.AlreadyExists:      AlreadyExists,
+		codes.PermissionDenied:   PermissionDenied,
+		codes.ResourceExhausted:  ResourceExhausted,
+		codes.FailedPrecondition: PreconditionFailed,
+		codes.Aborted:            Aborted,
+		codes.OutOfRange:         InvalidRevision,
+		codes.Unimplemented:      Unimplemented,
+		codes.Internal:           Internal,
+		codes.Unavailable:        Unavailable,
+		codes.DataLoss:           Internal,
+		codes.Unauthenticated:    Unauthenticated,
+	}
+)
+
+func init() {
+	for _, entry := range catalogue {
+		entriesByCode[entry.Code] = entry
+		if _, ok := codesByReason[entry.Reason]; !ok && entry.Reason != v1.ErrorReason_ERROR_REASON_UNSPECIFIED {
+			codesByReason[entry.Reason] = entry.Code
+		}
+	}
+}
+
+// All returns the entries of all the codes of the catalogue.
+func All() []Entry {
+	entries := make([]Entry, len(catalogue))
+	copy(entries, catalogue)
+	return entries
+}
+
+// Lookup returns the entry of the given code in the catalogue, if any.
+func Lookup(code Code) (Entry, bool) {
+	entry, ok := entriesByCode[code]
+	return entry, ok
+}
+
+// Category returns the category of the code, or CategoryInternal for codes unknown to the catalogue.
+func (c Code) Category() Category {
+	if entry, ok := entriesByCode[c]; ok {
+		return entry.Category
+	}
+	return CategoryInternal
+}
+
+// Derive returns the code of an error with the given gRPC status code and, if any, ErrorInfo
+// details: the code in its metadata, or otherwise the code of its reason or status code.
+func Derive(grpcCode codes.Code, info *errdetails.ErrorInfo) Code {
+	if info != nil {
+		if code := info.Metadata[MetadataKey]; code != "" {
+			return Code(code)
+		}
+
+		if reason, ok := v1.ErrorReason_value[info.Reason]; ok {
+			if code, ok := codesByReason[v1.ErrorReason(reason)]; ok {
+				return code
+			}
+		}
+	}
+
+	if code, ok := codesByGRPCCode[grpcCode]; ok {
+		return code
+	}
+	return Unknown
+}
+
+// FromError returns the code of the given API error, if it has one.
+func FromError(err error) (Code, bool) {
+	if err == nil {
+		return "", false
+	}
+
+	s, ok := status.FromError(err)
+	if !ok {
+		return "", false
+	}
+
+	for _, detail := range s.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			if code := info.Metadata[MetadataKey]; code != "" {
+				return Code(code), true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,90 @@
+package errorcodes
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+)
+
+func TestCatalogue(t *testing.T) {
+	seen := map[Code]struct{}{}
+	for _, entry := range All() {
+		require.NotEmpty(t, entry.Code)
+		require.NotEmpty(t, entry.Category)
+		require.NotEmpty(t, entry.Description)
+		require.NotContains(t, seen, entry.Code, "duplicate code %s", entry.Code)
+		seen[entry.Code] = struct{}{}
+	}
+
+	// Every status code and every reason has a code.
+	for grpcCode := codes.Canceled; grpcCode <= codes.Unauthenticated; grpcCode++ {
+		require.Contains(t, seen, Derive(grpcCode, nil), grpcCode.String())
+	}
+	for name, reason := range v1.ErrorReason_value {
+		if reason == 0 {
+			continue
+		}
+		code := Derive(codes.Unknown, &errdetails.ErrorInfo{Reason: name})
+		require.NotEqual(t, Unknown, code, name)
+
+		entry, ok := Lookup(code)
+		require.True(t, ok)
+		require.Equal(t, v1.ErrorReason(reason), entry.Reason)
+	}
+}
+
+func TestDerive(t *testing.T) {
+	tcs := []struct {
+		name     string
+		grpcCode codes.Code
+		info     *errdetails.ErrorInfo
+		expected Code
+	}{
+		{"status code only", codes.Unauthenticated, nil, Unauthenticated},
+		{"unknown status code", codes.Code(42), nil, Unknown},
+		{"unspecified reason", codes.FailedPrecondition, &errdetails.ErrorInfo{Reason: "ERROR_REASON_UNSPECIFIED"}, PreconditionFailed},
+		{"reason", codes.FailedPrecondition, &errdetails.ErrorInfo{Reason: "ERROR_REASON_UNKNOWN_CAVEAT"}, UnknownCaveat},
+		{
+			"explicit code",
+			codes.FailedPrecondition,
+			&errdetails.ErrorInfo{
+				Reason:   "ERROR_REASON_WRITE_OR_DELETE_PRECONDITION_FAILURE",
+				Metadata: map[string]string{MetadataKey: string(PreconditionMustNotMatchFailed)},
+			},
+			PreconditionMustNotMatchFailed,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, Derive(tc.grpcCode, tc.info))
+		})
+	}
+}
+
+func TestFromError(t *testing.T) {
+	_, ok := FromError(nil)
+	require.False(t, ok)
+
+	_, ok = FromError(errors.New("some error"))
+	require.False(t, ok)
+
+	_, ok = FromError(status.Error(codes.Internal, "some error"))
+	require.False(t, ok)
+
+	withCode, err := status.New(codes.OutOfRange, "some error").WithDetails(&errdetails.ErrorInfo{
+		Metadata: map[string]string{MetadataKey: string(RevisionGarbageCollected)},
+	})
+	require.NoError(t, err)
+
+	code, ok := FromError(withCode.Err())
+	require.True(t, ok)
+	require.Equal(t, RevisionGarbageCollected, code)
+	require.Equal(t, CategoryStaleness, code.Category())
+}
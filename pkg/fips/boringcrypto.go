@@ -0,0 +1,8 @@
+//go:build boringcrypto
+
+package fips
+
+// Restrict TLS to FIPS-approved settings, as the Go Cryptographic Module does in FIPS 140-3 mode.
+import _ "crypto/tls/fipsonly"
+
+const boringCrypto = true
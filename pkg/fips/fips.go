@@ -0,0 +1,65 @@
+// Package fips reports whether SpiceDB runs in FIPS mode, in which its cryptography is provided by
+// a FIPS 140 validated module, and enforces the policies of that mode.
+//
+// FIPS mode is enabled when the binary is built with GOEXPERIMENT=boringcrypto, which uses the
+// BoringCrypto module, or when it runs with GODEBUG=fips140=on on Go 1.24 or later, which uses the
+// Go Cryptographic Module. In both cases, the standard library restricts TLS to FIPS-approved
+// versions, cipher suites and key exchanges. SpiceDB additionally hashes the tokens which bind
+// cursors and idempotency keys to their requests with SHA-256, and rejects options relying on
+// unapproved cryptography, such as HMAC keys shorter than 112 bits.
+package fips
+
+import (
+	"errors"
+	"fmt"
+)
+
+// minHMACKeyLength is the minimum length of HMAC keys in FIPS mode, in bytes, providing 112 bits
+// of security strength as required by NIST SP 800-131A.
+const minHMACKeyLength = 14
+
+const (
+	// ModeDisabled is the mode of binaries not running in FIPS mode.
+	ModeDisabled = "disabled"
+
+	// ModeBoringCrypto is the mode of binaries built with the BoringCrypto module.
+	ModeBoringCrypto = "boringcrypto"
+
+	// ModeGoFIPS140 is the mode of binaries running with the FIPS 140-3 mode of the Go
+	// Cryptographic Module enabled.
+	ModeGoFIPS140 = "go-fips140"
+)
+
+// Enabled reports whether SpiceDB runs in FIPS mode.
+func Enabled() bool {
+	return Mode() != ModeDisabled
+}
+
+// Mode returns the name of the FIPS mode in which SpiceDB runs.
+func Mode() string {
+	switch {
+	case boringCrypto:
+		return ModeBoringCrypto
+	case goFIPS140Enabled():
+		return ModeGoFIPS140
+	default:
+		return ModeDisabled
+	}
+}
+
+// Require returns an error if SpiceDB does not run in FIPS mode.
+func Require() error {
+	if !Enabled() {
+		return errors.New("FIPS mode is required: run with GODEBUG=fips140=on on a binary built with Go 1.24 or later, or build with GOEXPERIMENT=boringcrypto")
+	}
+	return nil
+}
+
+// CheckHMACKey returns an error if FIPS mode is enabled and the key is too short to be used for
+// HMACs.
+func CheckHMACKey(key []byte) error {
+	if Enabled() && len(key) < minHMACKeyLength {
+		return fmt.Errorf("HMAC keys must be at least %d bytes long in FIPS mode, found %d", minHMACKeyLength, len(key))
+	}
+	return nil
+}
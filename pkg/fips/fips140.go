@@ -0,0 +1,9 @@
+//go:build go1.24
+
+package fips
+
+import "crypto/fips140"
+
+func goFIPS140Enabled() bool {
+	return fips140.Enabled()
+}
@@ -0,0 +1,7 @@
+//go:build !go1.24
+
+package fips
+
+func goFIPS140Enabled() bool {
+	return false
+}
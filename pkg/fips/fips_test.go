@@ -0,0 +1,28 @@
+package fips
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckHMACKey(t *testing.T) {
+	require.NoError(t, CheckHMACKey(make([]byte, minHMACKeyLength)))
+
+	err := CheckHMACKey(make([]byte, minHMACKeyLength-1))
+	if Enabled() {
+		require.ErrorContains(t, err, "HMAC keys must be at least 14 bytes long in FIPS mode")
+	} else {
+		require.NoError(t, err)
+	}
+}
+
+func TestRequire(t *testing.T) {
+	if Enabled() {
+		require.NoError(t, Require())
+		require.NotEqual(t, ModeDisabled, Mode())
+	} else {
+		require.ErrorContains(t, Require(), "FIPS mode is required")
+		require.Equal(t, ModeDisabled, Mode())
+	}
+}
@@ -5,12 +5,14 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 
 	log "github.com/authzed/spicedb/internal/logging"
@@ -18,6 +20,7 @@ import (
 	"github.com/authzed/spicedb/internal/services/shared"
 	"github.com/authzed/spicedb/pkg/cursor"
 	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/requestmeta"
 	"github.com/authzed/spicedb/pkg/zedtoken"
 )
 
@@ -86,6 +89,11 @@ func addRevisionToContextFromConsistency(ctx context.Context, req hasConsistency
 
 	withOptionalCursor, hasOptionalCursor := req.(hasOptionalCursor)
 
+	readAtTime, hasReadAtTime, err := requestedReadAtTime(ctx, req)
+	if err != nil {
+		return err
+	}
+
 	switch {
 	case hasOptionalCursor && withOptionalCursor.GetOptionalCursor() != nil:
 		// Always use the revision encoded in the cursor.
@@ -105,6 +113,24 @@ func addRevisionToContextFromConsistency(ctx context.Context, req hasConsistency
 
 		revision = requestedRev
 
+	case hasReadAtTime:
+		// Read at time: Use the revision of the datastore as of the requested time.
+		if serviceLabel != "" {
+			ConsistencyCounter.WithLabelValues("attime", "request", serviceLabel).Inc()
+		}
+
+		requestedRev, err := revisionAtTime(ctx, ds, readAtTime)
+		if err != nil {
+			return rewriteDatastoreError(ctx, err)
+		}
+
+		err = ds.CheckRevision(ctx, requestedRev)
+		if err != nil {
+			return rewriteDatastoreError(ctx, err)
+		}
+
+		revision = requestedRev
+
 	case consistency == nil || consistency.GetMinimizeLatency():
 		// Minimize Latency: Use the datastore's current revision, whatever it may be.
 		source := "request"
@@ -179,6 +205,46 @@ func addRevisionToContextFromConsistency(ctx context.Context, req hasConsistency
 	return nil
 }
 
+// requestedReadAtTime returns the time at which a ReadRelationships or CheckPermission request
+// asks to read, if any.
+func requestedReadAtTime(ctx context.Context, req any) (time.Time, bool, error) {
+	switch req.(type) {
+	case *v1.ReadRelationshipsRequest, *v1.CheckPermissionRequest:
+	default:
+		return time.Time{}, false, nil
+	}
+
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return time.Time{}, false, nil
+	}
+
+	values := md.Get(string(requestmeta.RequestReadAtTime))
+	if len(values) == 0 {
+		return time.Time{}, false, nil
+	}
+
+	readAtTime, err := time.Parse(time.RFC3339Nano, values[0])
+	if err != nil {
+		return time.Time{}, false, status.Errorf(codes.InvalidArgument, "invalid read time `%s`: expected an RFC 3339 timestamp", values[0])
+	}
+
+	if readAtTime.After(time.Now()) {
+		return time.Time{}, false, status.Errorf(codes.InvalidArgument, "read time `%s` is in the future", values[0])
+	}
+
+	return readAtTime, true, nil
+}
+
+func revisionAtTime(ctx context.Context, ds datastore.Datastore, at time.Time) (datastore.Revision, error) {
+	historical := datastore.UnwrapAs[datastore.HistoricalRevisionDatastore](ds)
+	if historical == nil {
+		return datastore.NoRevision, status.Errorf(codes.Unimplemented, "datastore of type %T does not support reading at a time", ds)
+	}
+
+	return historical.RevisionAtTime(ctx, at)
+}
+
 var bypassServiceWhitelist = map[string]struct{}{
 	"/grpc.reflection.v1alpha.ServerReflection/": {},
 	"/grpc.reflection.v1.ServerReflection/":      {},
@@ -266,8 +332,12 @@ func rewriteDatastoreError(ctx context.Context, err error) error {
 		return err
 	}
 
+	var revisionErr datastore.InvalidRevisionError
 	switch {
-	case errors.As(err, &datastore.InvalidRevisionError{}):
+	case errors.As(err, &revisionErr):
+		if revisionErr.Reason() == datastore.RevisionStale && revisionErr.InvalidRevision() != nil {
+			return NewRevisionGarbageCollectedErr(revisionErr.InvalidRevision(), err)
+		}
 		return status.Errorf(codes.OutOfRange, "invalid revision: %s", err)
 
 	case errors.As(err, &datastore.ReadOnlyError{}):
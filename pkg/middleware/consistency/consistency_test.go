@@ -6,11 +6,14 @@ import (
 	"testing"
 
 	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/authzed/grpcutil"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
 
 	"github.com/authzed/spicedb/internal/datastore/proxy/proxy_test"
 	"github.com/authzed/spicedb/internal/datastore/revisions"
 	"github.com/authzed/spicedb/pkg/cursor"
+	"github.com/authzed/spicedb/pkg/datastore"
 	dispatch "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
 	"github.com/authzed/spicedb/pkg/zedtoken"
 )
@@ -191,3 +194,25 @@ func TestAddRevisionToContextWithCursor(t *testing.T) {
 	require.True(optimized.Equal(rev))
 	ds.AssertExpectations(t)
 }
+
+func TestAddRevisionToContextAtGarbageCollectedSnapshot(t *testing.T) {
+	require := require.New(t)
+
+	ds := &proxy_test.MockDatastore{}
+	ds.On("CheckRevision", zero).Return(datastore.NewInvalidRevisionErr(zero, datastore.RevisionStale)).Times(1)
+	ds.On("RevisionFromString", zero.String()).Return(zero, nil).Once()
+
+	updated := ContextWithHandle(context.Background())
+	err := AddRevisionToContext(updated, &v1.CheckPermissionRequest{
+		Consistency: &v1.Consistency{
+			Requirement: &v1.Consistency_AtExactSnapshot{
+				AtExactSnapshot: zedtoken.MustNewFromRevision(zero),
+			},
+		},
+	}, ds, "somelabel")
+
+	var gcErr RevisionGarbageCollectedError
+	require.ErrorAs(err, &gcErr)
+	grpcutil.RequireStatus(t, codes.OutOfRange, err)
+	ds.AssertExpectations(t)
+}
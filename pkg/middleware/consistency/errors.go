@@ -0,0 +1,49 @@
+package consistency
+
+import (
+	"fmt"
+
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/errorcodes"
+	"github.com/authzed/spicedb/pkg/spiceerrors"
+)
+
+// RevisionGarbageCollectedError occurs when the revision requested for a call, such as by a
+// zedtoken or a time to read at, is older than the GC window of the datastore, and the data at
+// that revision may have been garbage collected.
+type RevisionGarbageCollectedError struct {
+	error
+	revision datastore.Revision
+}
+
+// MarshalZerologObject implements zerolog object marshalling.
+func (err RevisionGarbageCollectedError) MarshalZerologObject(e *zerolog.Event) {
+	e.Err(err.error).Stringer("revision", err.revision)
+}
+
+// GRPCStatus implements retrieving the gRPC status for the error.
+func (err RevisionGarbageCollectedError) GRPCStatus() *status.Status {
+	metadata := map[string]string{"revision_status": "garbage_collected"}
+	if err.revision != datastore.NoRevision {
+		metadata["revision"] = err.revision.String()
+	}
+
+	return spiceerrors.WithCodeAndDetails(
+		err,
+		codes.OutOfRange,
+		spiceerrors.ForCode(errorcodes.RevisionGarbageCollected, metadata),
+	)
+}
+
+// NewRevisionGarbageCollectedErr creates a new error representing that the requested revision has
+// been garbage collected.
+func NewRevisionGarbageCollectedErr(revision datastore.Revision, cause error) RevisionGarbageCollectedError {
+	return RevisionGarbageCollectedError{
+		error:    fmt.Errorf("invalid revision: %w", cause),
+		revision: revision,
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"sync"
 
 	"github.com/cespare/xxhash/v2"
 	middleware "github.com/grpc-ecosystem/go-grpc-middleware/v2"
@@ -20,7 +21,21 @@ type nodeIDHandle struct {
 	nodeID string
 }
 
-var defaultNodeID string
+// defaultNodeID computes, once, the node ID used when none is set in the context.
+var defaultNodeID = sync.OnceValues(func() (string, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return "", err
+	}
+
+	// Hash the hostname to get the final default node ID.
+	hasher := xxhash.New()
+	if _, err := hasher.WriteString(hostname); err != nil {
+		return "", fmt.Errorf("failed to hash hostname: %w", err)
+	}
+
+	return spiceDBPrefix + fmt.Sprintf("%x", hasher.Sum(nil)), nil
+})
 
 // ContextWithHandle adds a placeholder to a context that will later be
 // filled by the Node ID.
@@ -37,26 +52,16 @@ func FromContext(ctx context.Context) (string, error) {
 		}
 	}
 
-	if defaultNodeID == "" {
-		hostname, err := os.Hostname()
-		if err != nil {
-			return "", err
-		}
-
-		// Hash the hostname to get the final default node ID.
-		hasher := xxhash.New()
-		if _, err := hasher.WriteString(hostname); err != nil {
-			return "", fmt.Errorf("failed to hash hostname: %w", err)
-		}
-
-		defaultNodeID = spiceDBPrefix + fmt.Sprintf("%x", hasher.Sum(nil))
+	nodeID, err := defaultNodeID()
+	if err != nil {
+		return "", err
 	}
 
-	if err := setInContext(ctx, defaultNodeID); err != nil {
+	if err := setInContext(ctx, nodeID); err != nil {
 		return "", err
 	}
 
-	return defaultNodeID, nil
+	return nodeID, nil
 }
 
 // setInContext adds a node ID to the given context
@@ -0,0 +1,160 @@
+// Package priority handles the priority classes of requests, which clients set in the
+// requestmeta.RequestPriority header. The class is read from the incoming metadata of the context
+// by the dispatcher, which bounds the concurrent dispatches of batch and background requests, and
+// by the datastores, which map it to their own priorities, and forwarded by the client
+// interceptors to the nodes to which requests are dispatched. Spanner runs the reads and commits
+// of batch and background requests at a medium and low priority, and CockroachDB runs their
+// write transactions at a low priority. Postgres can serve their reads from a pool of their own
+// and bound the statements of their writes with a statement timeout. MySQL and memdb ignore the
+// class.
+package priority
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/authzed/spicedb/pkg/requestmeta"
+)
+
+const metadataKey = string(requestmeta.RequestPriority)
+
+// Class is the priority class of a request.
+type Class uint8
+
+const (
+	// Interactive is the class of requests on which a user is waiting, such as permission checks
+	// made while serving a page. It is the class of requests which do not specify one.
+	Interactive Class = iota
+
+	// Batch is the class of bulk requests whose latency matters less than that of interactive
+	// requests, such as imports or exports of relationships.
+	Batch
+
+	// Background is the class of requests which should only use the capacity left by the other
+	// classes, such as periodic reconciliations.
+	Background
+)
+
+// String returns the name of the class, as set in the request header.
+func (c Class) String() string {
+	switch c {
+	case Interactive:
+		return "interactive"
+	case Batch:
+		return "batch"
+	case Background:
+		return "background"
+	default:
+		return fmt.Sprintf("Class(%d)", uint8(c))
+	}
+}
+
+// Parse returns the class with the given name.
+func Parse(name string) (Class, error) {
+	switch name {
+	case "interactive":
+		return Interactive, nil
+	case "batch":
+		return Batch, nil
+	case "background":
+		return Background, nil
+	default:
+		return Interactive, fmt.Errorf("unknown priority class `%s`: expected one of interactive, batch or background", name)
+	}
+}
+
+func fromContext(ctx context.Context) (Class, bool, error) {
+	values := metadata.ValueFromIncomingContext(ctx, metadataKey)
+	if len(values) == 0 {
+		return Interactive, false, nil
+	}
+
+	class, err := Parse(values[0])
+	return class, true, err
+}
+
+// FromContext returns the priority class found in the incoming metadata of the context, or
+// Interactive if it specifies none or an unknown one.
+func FromContext(ctx context.Context) Class {
+	class, _, err := fromContext(ctx)
+	if err != nil {
+		return Interactive
+	}
+	return class
+}
+
+// ContextWithClass returns the context with the given priority class in its incoming metadata.
+func ContextWithClass(ctx context.Context, class Class) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+	md = md.Copy()
+	md.Set(metadataKey, class.String())
+	return metadata.NewIncomingContext(ctx, md)
+}
+
+// PropagateIfExists copies the priority class from the source context to the target context if
+// the source specifies one. The updated target context is returned.
+func PropagateIfExists(source, target context.Context) context.Context {
+	class, ok, err := fromContext(source)
+	if !ok || err != nil {
+		return target
+	}
+	return ContextWithClass(target, class)
+}
+
+func validate(ctx context.Context) error {
+	if _, _, err := fromContext(ctx); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return nil
+}
+
+// UnaryServerInterceptor returns a new unary server interceptor which rejects requests
+// specifying an unknown priority class.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := validate(ctx); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns a new stream server interceptor which rejects requests
+// specifying an unknown priority class.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := validate(stream.Context()); err != nil {
+			return err
+		}
+		return handler(srv, stream)
+	}
+}
+
+func outgoingContext(ctx context.Context) context.Context {
+	class, ok, err := fromContext(ctx)
+	if !ok || err != nil || class == Interactive {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, metadataKey, class.String())
+}
+
+// UnaryClientInterceptor returns a new unary client interceptor which forwards the priority class
+// of the incoming request to the called server.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(outgoingContext(ctx), method, req, reply, cc, opts...)
+	}
+}
+
+// StreamClientInterceptor returns a new stream client interceptor which forwards the priority
+// class of the incoming request to the called server.
+func StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(outgoingContext(ctx), desc, cc, method, opts...)
+	}
+}
@@ -0,0 +1,81 @@
+package priority
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestParse(t *testing.T) {
+	for _, class := range []Class{Interactive, Batch, Background} {
+		parsed, err := Parse(class.String())
+		require.NoError(t, err)
+		require.Equal(t, class, parsed)
+	}
+
+	_, err := Parse("urgent")
+	require.ErrorContains(t, err, "unknown priority class `urgent`")
+}
+
+func TestFromContext(t *testing.T) {
+	require.Equal(t, Interactive, FromContext(context.Background()))
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(metadataKey, "background"))
+	require.Equal(t, Background, FromContext(ctx))
+
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs(metadataKey, "urgent"))
+	require.Equal(t, Interactive, FromContext(ctx))
+
+	ctx = ContextWithClass(context.Background(), Batch)
+	require.Equal(t, Batch, FromContext(ctx))
+}
+
+func TestPropagateIfExists(t *testing.T) {
+	source := ContextWithClass(context.Background(), Background)
+	require.Equal(t, Background, FromContext(PropagateIfExists(source, context.Background())))
+
+	target := metadata.NewIncomingContext(context.Background(), metadata.Pairs("other", "value"))
+	propagated := PropagateIfExists(context.Background(), target)
+	require.Equal(t, Interactive, FromContext(propagated))
+	require.Equal(t, []string{"value"}, metadata.ValueFromIncomingContext(propagated, "other"))
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	interceptor := UnaryServerInterceptor()
+	handler := func(ctx context.Context, _ any) (any, error) {
+		return FromContext(ctx), nil
+	}
+
+	ctx := ContextWithClass(context.Background(), Batch)
+	resp, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{}, handler)
+	require.NoError(t, err)
+	require.Equal(t, Batch, resp)
+
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs(metadataKey, "urgent"))
+	_, err = interceptor(ctx, nil, &grpc.UnaryServerInfo{}, handler)
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestUnaryClientInterceptor(t *testing.T) {
+	interceptor := UnaryClientInterceptor()
+
+	var outgoing []string
+	invoker := func(ctx context.Context, _ string, _, _ any, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		outgoing = md.Get(metadataKey)
+		return nil
+	}
+
+	ctx := ContextWithClass(context.Background(), Background)
+	require.NoError(t, interceptor(ctx, "/method", nil, nil, nil, invoker))
+	require.Equal(t, []string{"background"}, outgoing)
+
+	ctx = ContextWithClass(context.Background(), Interactive)
+	require.NoError(t, interceptor(ctx, "/method", nil, nil, nil, invoker))
+	require.Empty(t, outgoing)
+}
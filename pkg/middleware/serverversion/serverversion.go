@@ -3,18 +3,20 @@ package serverversion
 import (
 	"context"
 
-	"github.com/authzed/authzed-go/pkg/requestmeta"
+	authzedrequestmeta "github.com/authzed/authzed-go/pkg/requestmeta"
 	"github.com/authzed/authzed-go/pkg/responsemeta"
 	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/metadata"
 
 	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/pkg/fips"
 	"github.com/authzed/spicedb/pkg/releases"
+	"github.com/authzed/spicedb/pkg/requestmeta"
 )
 
-// HandleServerVersion defines a middleware for returning the version of the server
-// when requested via the RequestServerVersion header.
+// HandleServerVersion defines a middleware for returning the version of the server, along with
+// its FIPS mode, when requested via the RequestServerVersion header.
 type HandleServerVersion struct {
 	// IsEnabled is whether the middleware is enabled.
 	IsEnabled bool
@@ -26,7 +28,7 @@ type HandleServerVersion struct {
 func (r *HandleServerVersion) ServerReporter(ctx context.Context, _ interceptors.CallMeta) (interceptors.Reporter, context.Context) {
 	if r.IsEnabled {
 		if md, ok := metadata.FromIncomingContext(ctx); ok {
-			if _, isRequestingVersion := md[string(requestmeta.RequestServerVersion)]; isRequestingVersion {
+			if _, isRequestingVersion := md[string(authzedrequestmeta.RequestServerVersion)]; isRequestingVersion {
 				version, err := r.GetVersion()
 				if err != nil {
 					log.Ctx(ctx).Err(err).Msg("could not load current service version")
@@ -35,6 +37,7 @@ func (r *HandleServerVersion) ServerReporter(ctx context.Context, _ interceptors
 
 				err = responsemeta.SetResponseHeaderMetadata(ctx, map[responsemeta.ResponseMetadataHeaderKey]string{
 					responsemeta.ServerVersion: version,
+					responsemeta.ResponseMetadataHeaderKey(requestmeta.FIPSModeResponseHeaderKey): fips.Mode(),
 				})
 				// if context is cancelled, the stream will be closed, and gRPC will return ErrIllegalHeaderWrite
 				// this prevents logging unnecessary error messages
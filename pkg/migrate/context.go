@@ -8,4 +8,9 @@ const (
 	// BackfillBatchSize represents the number of items that should be backfilled in a
 	// single step of an incremental backfill, and should be of type uint64.
 	BackfillBatchSize MigrationVariable = iota
+
+	// ProgressReporter represents the function to which long running steps of migrations,
+	// such as index builds and backfills, report their progress, and should be of type
+	// ProgressReporterFunc. Progress is logged if it is unset.
+	ProgressReporter
 )
@@ -2,7 +2,9 @@ package migrate
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"strings"
 
 	log "github.com/authzed/spicedb/internal/logging"
@@ -42,6 +44,15 @@ type Driver[C any, T any] interface {
 	Close(ctx context.Context) error
 }
 
+// DryRunDriver is implemented by drivers able to print the statements of migrations, for them to be
+// reviewed before being applied.
+type DryRunDriver[C any, T any] interface {
+	// DryRun returns a driver whose connection handler and transactions write the statements which
+	// would modify the datastore to out, rather than executing them. Statements which only read
+	// from the datastore are still executed, and transactions are never committed.
+	DryRun(out io.Writer) Driver[C, T]
+}
+
 // MigrationFunc is a function that executes in the context of a specific database connection handler.
 type MigrationFunc[C any] func(ctx context.Context, conn C) error
 
@@ -53,6 +64,42 @@ type migration[C any, T any] struct {
 	replaces string
 	up       MigrationFunc[C]
 	upTx     TxMigrationFunc[T]
+	down     MigrationFunc[C]
+	downTx   TxMigrationFunc[T]
+}
+
+func (m migration[C, T]) reversible() bool {
+	return m.down != nil || m.downTx != nil
+}
+
+// step is a migration to run, either up from the revision it replaces to its version, or down from
+// its version to the revision it replaces.
+type step[C any, T any] struct {
+	migration[C, T]
+	reverse bool
+}
+
+func (s step[C, T]) from() string {
+	if s.reverse {
+		return s.version
+	}
+	return s.replaces
+}
+
+func (s step[C, T]) to() string {
+	if s.reverse {
+		return s.replaces
+	}
+	return s.version
+}
+
+// Status describes the migrations applied to a datastore, and those pending for it to be at the
+// head revision.
+type Status struct {
+	CurrentRevision string   `json:"currentRevision"`
+	HeadRevision    string   `json:"headRevision"`
+	Applied         []string `json:"applied"`
+	Pending         []string `json:"pending"`
 }
 
 // Manager is used to manage a self-contained set of migrations. Standard usage
@@ -96,67 +143,174 @@ func (m *Manager[D, C, T]) Register(version, replaces string, up MigrationFunc[C
 	return nil
 }
 
+// RegisterDown makes a registered migration reversible, such that the datastore can be migrated
+// back to the revision it replaces. The down parameters should undo the changes of the up
+// functions of the migration, and run in the reverse order: downTx runs first, in the transaction
+// writing back the replaced revision, followed by down.
+func (m *Manager[D, C, T]) RegisterDown(version string, down MigrationFunc[C], downTx TxMigrationFunc[T]) error {
+	existing, ok := m.migrations[version]
+	if !ok {
+		return fmt.Errorf("unable to find migration for revision: %s", version)
+	}
+
+	if existing.reversible() {
+		return fmt.Errorf("down migration already exists: %s", version)
+	}
+
+	if down == nil && downTx == nil {
+		return fmt.Errorf("down migration of %s must have a function", version)
+	}
+
+	existing.down = down
+	existing.downTx = downTx
+	m.migrations[version] = existing
+	return nil
+}
+
 // Run will actually perform the necessary migrations to bring the backing datastore
-// from its current revision to the specified revision.
+// from its current revision to the specified revision. If the specified revision
+// precedes the current one, the migrations after it are reverted, which requires
+// all of them to be reversible.
 func (m *Manager[D, C, T]) Run(ctx context.Context, driver D, throughRevision string, dryRun RunType) error {
+	toRun, err := m.plan(ctx, driver, throughRevision)
+	if err != nil {
+		return err
+	}
+
+	if !dryRun {
+		return runSteps(ctx, driver, toRun, true)
+	}
+
+	return nil
+}
+
+// PrintRun writes the statements of the migrations which would bring the backing
+// datastore from its current revision to the specified revision to out, without
+// applying them. The driver must implement DryRunDriver.
+func (m *Manager[D, C, T]) PrintRun(ctx context.Context, driver D, throughRevision string, out io.Writer) error {
+	dryRunDriver, ok := any(driver).(DryRunDriver[C, T])
+	if !ok {
+		return errors.New("the datastore driver does not support printing migrations")
+	}
+
+	toRun, err := m.plan(ctx, driver, throughRevision)
+	if err != nil {
+		return err
+	}
+
+	printingDriver := dryRunDriver.DryRun(out)
+	for _, stepToRun := range toRun {
+		if _, err := fmt.Fprintf(out, "-- migrate from %q to %q\n", stepToRun.from(), stepToRun.to()); err != nil {
+			return err
+		}
+
+		// The version is never written, so it cannot be checked between steps.
+		if err := runSteps(ctx, printingDriver, []step[C, T]{stepToRun}, false); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// plan returns the steps to bring the backing datastore from its current revision to the
+// specified revision.
+func (m *Manager[D, C, T]) plan(ctx context.Context, driver D, throughRevision string) ([]step[C, T], error) {
 	requestedRevision := throughRevision
 	starting, err := driver.Version(ctx)
 	if err != nil {
-		return fmt.Errorf("unable to get current revision: %w", err)
+		return nil, fmt.Errorf("unable to get current revision: %w", err)
 	}
 
 	if strings.ToLower(throughRevision) == Head {
 		throughRevision, err = m.HeadRevision()
 		if err != nil {
-			return fmt.Errorf("unable to compute head revision: %w", err)
+			return nil, fmt.Errorf("unable to compute head revision: %w", err)
 		}
 	}
 
-	toRun, err := collectMigrationsInRange(starting, throughRevision, m.migrations)
-	if err != nil {
-		return fmt.Errorf("unable to compute migration list: %w", err)
+	var toRun []step[C, T]
+	toApply, err := collectMigrationsInRange(starting, throughRevision, m.migrations)
+	if err == nil {
+		for _, migrationToApply := range toApply {
+			toRun = append(toRun, step[C, T]{migration: migrationToApply})
+		}
+	} else {
+		// The requested revision may instead precede the current one.
+		if throughRevision == None {
+			return nil, fmt.Errorf("unable to compute migration list: %w", err)
+		}
+
+		toRevert, revertErr := collectMigrationsInRange(throughRevision, starting, m.migrations)
+		if revertErr != nil {
+			return nil, fmt.Errorf("unable to compute migration list: %w", err)
+		}
+
+		for i := len(toRevert) - 1; i >= 0; i-- {
+			if !toRevert[i].reversible() {
+				return nil, fmt.Errorf("unable to migrate down to `%s`: migration `%s` is not reversible", throughRevision, toRevert[i].version)
+			}
+			toRun = append(toRun, step[C, T]{migration: toRevert[i], reverse: true})
+		}
 	}
+
 	if len(toRun) == 0 {
 		log.Ctx(ctx).Info().Str("targetRevision", requestedRevision).Msg("server already at requested revision")
 	}
 
-	if !dryRun {
-		for _, migrationToRun := range toRun {
+	return toRun, nil
+}
+
+func runSteps[C any, T any](ctx context.Context, driver Driver[C, T], toRun []step[C, T], checkVersions bool) error {
+	for _, stepToRun := range toRun {
+		if checkVersions {
 			// Double check that the current version reported is the one we expect
 			currentVersion, err := driver.Version(ctx)
 			if err != nil {
 				return fmt.Errorf("unable to load version from driver: %w", err)
 			}
 
-			if migrationToRun.replaces != currentVersion {
-				return fmt.Errorf("migration attempting to run out of order: %s != %s", currentVersion, migrationToRun.replaces)
+			if stepToRun.from() != currentVersion {
+				return fmt.Errorf("migration attempting to run out of order: %s != %s", currentVersion, stepToRun.from())
 			}
+		}
 
-			log.Ctx(ctx).Info().Str("from", migrationToRun.replaces).Str("to", migrationToRun.version).Msg("migrating")
-			if migrationToRun.up != nil {
-				if err = migrationToRun.up(ctx, driver.Conn()); err != nil {
-					return fmt.Errorf("error executing migration function: %w", err)
-				}
+		log.Ctx(ctx).Info().Str("from", stepToRun.from()).Str("to", stepToRun.to()).Msg("migrating")
+		if !stepToRun.reverse && stepToRun.up != nil {
+			if err := stepToRun.up(ctx, driver.Conn()); err != nil {
+				return fmt.Errorf("error executing migration function: %w", err)
 			}
+		}
 
-			migrationToRun := migrationToRun
-			if err := driver.RunTx(ctx, func(ctx context.Context, tx T) error {
-				if migrationToRun.upTx != nil {
-					if err := migrationToRun.upTx(ctx, tx); err != nil {
-						return err
-					}
+		txFunc := stepToRun.upTx
+		if stepToRun.reverse {
+			txFunc = stepToRun.downTx
+		}
+
+		if err := driver.RunTx(ctx, func(ctx context.Context, tx T) error {
+			if txFunc != nil {
+				if err := txFunc(ctx, tx); err != nil {
+					return err
 				}
-				return driver.WriteVersion(ctx, tx, migrationToRun.version, migrationToRun.replaces)
-			}); err != nil {
-				return fmt.Errorf("error executing migration `%s`: %w", migrationToRun.version, err)
 			}
+			return driver.WriteVersion(ctx, tx, stepToRun.to(), stepToRun.from())
+		}); err != nil {
+			return fmt.Errorf("error executing migration `%s`: %w", stepToRun.version, err)
+		}
+
+		if stepToRun.reverse && stepToRun.down != nil {
+			if err := stepToRun.down(ctx, driver.Conn()); err != nil {
+				return fmt.Errorf("error executing down migration function: %w", err)
+			}
+		}
 
-			currentVersion, err = driver.Version(ctx)
+		if checkVersions {
+			currentVersion, err := driver.Version(ctx)
 			if err != nil {
 				return fmt.Errorf("unable to load version from driver: %w", err)
 			}
-			if migrationToRun.version != currentVersion {
-				return fmt.Errorf("the migration function succeeded, but the driver did not report the expected version: %s", migrationToRun.version)
+			if stepToRun.to() != currentVersion {
+				return fmt.Errorf("the migration function succeeded, but the driver did not report the expected version: %s", stepToRun.to())
 			}
 		}
 	}
@@ -164,6 +318,51 @@ func (m *Manager[D, C, T]) Run(ctx context.Context, driver D, throughRevision st
 	return nil
 }
 
+// Status returns the migrations applied to the backing datastore, and those pending
+// for it to be at the head revision.
+func (m *Manager[D, C, T]) Status(ctx context.Context, driver D) (Status, error) {
+	current, err := driver.Version(ctx)
+	if err != nil {
+		return Status{}, fmt.Errorf("unable to get current revision: %w", err)
+	}
+
+	head, err := m.HeadRevision()
+	if err != nil {
+		return Status{}, fmt.Errorf("unable to compute head revision: %w", err)
+	}
+
+	if _, ok := m.migrations[current]; current != None && !ok {
+		return Status{}, fmt.Errorf("unknown current revision, which may be newer than this version: %s", current)
+	}
+
+	status := Status{
+		CurrentRevision: current,
+		HeadRevision:    head,
+		Applied:         []string{},
+		Pending:         []string{},
+	}
+
+	// Migrations squashed into the first one may not be registered.
+	for revision := current; revision != None; {
+		applied, ok := m.migrations[revision]
+		if !ok {
+			break
+		}
+		status.Applied = append([]string{applied.version}, status.Applied...)
+		revision = applied.replaces
+	}
+
+	pending, err := collectMigrationsInRange(current, head, m.migrations)
+	if err != nil {
+		return Status{}, fmt.Errorf("unable to compute pending migrations: %w", err)
+	}
+	for _, pendingMigration := range pending {
+		status.Pending = append(status.Pending, pendingMigration.version)
+	}
+
+	return status, nil
+}
+
 func (m *Manager[D, C, T]) HeadRevision() (string, error) {
 	candidates := make(map[string]struct{}, len(m.migrations))
 	for candidate := range m.migrations {
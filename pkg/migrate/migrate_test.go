@@ -2,6 +2,9 @@ package migrate
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"strings"
 	"testing"
 	"time"
 
@@ -193,27 +196,178 @@ func TestManagerEnsureVersionIsWritten(t *testing.T) {
 	req.Equal("", writtenVer)
 }
 
+// loggingDriver runs transactions and logs the functions run, writing them to out
+// in a dry run.
+type loggingDriver struct {
+	currentVersion string
+	log            *[]string
+	out            io.Writer
+}
+
+func (ld *loggingDriver) Version(_ context.Context) (string, error) {
+	return ld.currentVersion, nil
+}
+
+func (ld *loggingDriver) WriteVersion(_ context.Context, tx fakeTx, to, from string) error {
+	if ld.out != nil {
+		_, err := fmt.Fprintf(ld.out, "write version %s\n", to)
+		return err
+	}
+	if ld.currentVersion != from {
+		return fmt.Errorf("expected version %s, found %s", from, ld.currentVersion)
+	}
+	ld.currentVersion = to
+	return nil
+}
+
+func (ld *loggingDriver) Conn() fakeConnPool {
+	return fakeConnPool{}
+}
+
+func (ld *loggingDriver) RunTx(ctx context.Context, f TxMigrationFunc[fakeTx]) error {
+	return f(ctx, fakeTx{})
+}
+
+func (*loggingDriver) Close(_ context.Context) error {
+	return nil
+}
+
+func (ld *loggingDriver) DryRun(out io.Writer) Driver[fakeConnPool, fakeTx] {
+	return &loggingDriver{currentVersion: ld.currentVersion, log: ld.log, out: out}
+}
+
+func registerLoggedMigrations(t *testing.T, m *Manager[*loggingDriver, fakeConnPool, fakeTx], log *[]string, versions ...string) {
+	logged := func(name string) (MigrationFunc[fakeConnPool], TxMigrationFunc[fakeTx]) {
+		return func(context.Context, fakeConnPool) error {
+				*log = append(*log, name)
+				return nil
+			}, func(context.Context, fakeTx) error {
+				*log = append(*log, name+" tx")
+				return nil
+			}
+	}
+
+	replaces := None
+	for _, version := range versions {
+		up, upTx := logged("up " + version)
+		require.NoError(t, m.Register(version, replaces, up, upTx))
+		replaces = version
+	}
+}
+
+func TestRunDown(t *testing.T) {
+	var log []string
+	m := NewManager[*loggingDriver, fakeConnPool, fakeTx]()
+	registerLoggedMigrations(t, m, &log, "1", "2", "3")
+
+	for _, version := range []string{"2", "3"} {
+		require.NoError(t, m.RegisterDown(version, func(context.Context, fakeConnPool) error {
+			log = append(log, "down "+version)
+			return nil
+		}, func(context.Context, fakeTx) error {
+			log = append(log, "down "+version+" tx")
+			return nil
+		}))
+	}
+
+	require.ErrorContains(t, m.RegisterDown("3", nil, noTxMigration), "already exists")
+	require.ErrorContains(t, m.RegisterDown("4", nil, noTxMigration), "unable to find migration")
+	require.ErrorContains(t, m.RegisterDown("1", nil, nil), "must have a function")
+
+	drv := &loggingDriver{log: &log}
+	require.NoError(t, m.Run(context.Background(), drv, Head, LiveRun))
+	require.Equal(t, "3", drv.currentVersion)
+
+	log = nil
+	require.NoError(t, m.Run(context.Background(), drv, "1", LiveRun))
+	require.Equal(t, "1", drv.currentVersion)
+	require.Equal(t, []string{"down 3 tx", "down 3", "down 2 tx", "down 2"}, log)
+
+	// Migrating down to an empty datastore is unsupported.
+	require.NoError(t, m.Run(context.Background(), drv, Head, LiveRun))
+	require.ErrorContains(t, m.Run(context.Background(), drv, None, LiveRun), "unable to compute migration list")
+
+	drv.currentVersion = "2"
+	require.NoError(t, m.Run(context.Background(), drv, "1", DryRun))
+	require.Equal(t, "2", drv.currentVersion)
+}
+
+func TestRunDownRequiresReversibleMigrations(t *testing.T) {
+	var log []string
+	m := NewManager[*loggingDriver, fakeConnPool, fakeTx]()
+	registerLoggedMigrations(t, m, &log, "1", "2", "3")
+	require.NoError(t, m.RegisterDown("3", nil, func(context.Context, fakeTx) error { return nil }))
+
+	drv := &loggingDriver{currentVersion: "3", log: &log}
+	require.ErrorContains(t, m.Run(context.Background(), drv, "1", LiveRun), "migration `2` is not reversible")
+	require.Equal(t, "3", drv.currentVersion)
+	require.Empty(t, log)
+}
+
+func TestPrintRun(t *testing.T) {
+	var log []string
+	m := NewManager[*loggingDriver, fakeConnPool, fakeTx]()
+	registerLoggedMigrations(t, m, &log, "1", "2", "3")
+
+	drv := &loggingDriver{currentVersion: "1", log: &log}
+	var out strings.Builder
+	require.NoError(t, m.PrintRun(context.Background(), drv, Head, &out))
+	require.Equal(t, `-- migrate from "1" to "2"
+write version 2
+-- migrate from "2" to "3"
+write version 3
+`, out.String())
+	require.Equal(t, []string{"up 2", "up 2 tx", "up 3", "up 3 tx"}, log)
+	require.Equal(t, "1", drv.currentVersion)
+
+	other := NewManager[Driver[fakeConnPool, fakeTx], fakeConnPool, fakeTx]()
+	require.NoError(t, other.Register("1", None, noNonatomicMigration, noTxMigration))
+	require.ErrorContains(t, other.PrintRun(context.Background(), &fakeDriver{}, Head, &out), "does not support printing")
+}
+
+func TestStatus(t *testing.T) {
+	var log []string
+	m := NewManager[*loggingDriver, fakeConnPool, fakeTx]()
+	registerLoggedMigrations(t, m, &log, "1", "2", "3")
+
+	for _, tc := range []struct {
+		current  string
+		expected Status
+	}{
+		{None, Status{CurrentRevision: None, HeadRevision: "3", Applied: []string{}, Pending: []string{"1", "2", "3"}}},
+		{"2", Status{CurrentRevision: "2", HeadRevision: "3", Applied: []string{"1", "2"}, Pending: []string{"3"}}},
+		{"3", Status{CurrentRevision: "3", HeadRevision: "3", Applied: []string{"1", "2", "3"}, Pending: []string{}}},
+	} {
+		status, err := m.Status(context.Background(), &loggingDriver{currentVersion: tc.current})
+		require.NoError(t, err)
+		require.Equal(t, tc.expected, status)
+	}
+
+	_, err := m.Status(context.Background(), &loggingDriver{currentVersion: "4"})
+	require.ErrorContains(t, err, "unknown current revision")
+}
+
 var noMigrations = map[string]migration[fakeConnPool, fakeTx]{}
 
 var simpleMigrations = map[string]migration[fakeConnPool, fakeTx]{
-	"123": {"123", "", noNonatomicMigration, noTxMigration},
+	"123": {"123", "", noNonatomicMigration, noTxMigration, nil, nil},
 }
 
 var singleHeadedChain = map[string]migration[fakeConnPool, fakeTx]{
-	"123": {"123", "", noNonatomicMigration, noTxMigration},
-	"456": {"456", "123", noNonatomicMigration, noTxMigration},
-	"789": {"789", "456", noNonatomicMigration, noTxMigration},
+	"123": {"123", "", noNonatomicMigration, noTxMigration, nil, nil},
+	"456": {"456", "123", noNonatomicMigration, noTxMigration, nil, nil},
+	"789": {"789", "456", noNonatomicMigration, noTxMigration, nil, nil},
 }
 
 var multiHeadedChain = map[string]migration[fakeConnPool, fakeTx]{
-	"123":  {"123", "", noNonatomicMigration, noTxMigration},
-	"456":  {"456", "123", noNonatomicMigration, noTxMigration},
-	"789a": {"789a", "456", noNonatomicMigration, noTxMigration},
-	"789b": {"789b", "456", noNonatomicMigration, noTxMigration},
+	"123":  {"123", "", noNonatomicMigration, noTxMigration, nil, nil},
+	"456":  {"456", "123", noNonatomicMigration, noTxMigration, nil, nil},
+	"789a": {"789a", "456", noNonatomicMigration, noTxMigration, nil, nil},
+	"789b": {"789b", "456", noNonatomicMigration, noTxMigration, nil, nil},
 }
 
 var missingEarlyMigrations = map[string]migration[fakeConnPool, fakeTx]{
-	"456": {"456", "123", noNonatomicMigration, noTxMigration},
-	"789": {"789", "456", noNonatomicMigration, noTxMigration},
-	"10":  {"10", "789", noNonatomicMigration, noTxMigration},
+	"456": {"456", "123", noNonatomicMigration, noTxMigration, nil, nil},
+	"789": {"789", "456", noNonatomicMigration, noTxMigration, nil, nil},
+	"10":  {"10", "789", noNonatomicMigration, noTxMigration, nil, nil},
 }
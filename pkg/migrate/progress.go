@@ -0,0 +1,98 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	log "github.com/authzed/spicedb/internal/logging"
+)
+
+// DefaultBackfillBatchSize is the number of items backfilled in a single step of an
+// incremental backfill when BackfillBatchSize is not set in the context.
+const DefaultBackfillBatchSize uint64 = 1000
+
+// progressReportInterval is the minimum interval between two reports of the progress
+// of a backfill, such that backfills of huge tables do not report each of their batches.
+var progressReportInterval = 10 * time.Second
+
+// Progress is the progress of a long running step of a migration.
+type Progress struct {
+	// Step describes the step, e.g. the index being built or the column being backfilled.
+	Step string
+
+	// Done is the number of items processed by the step so far.
+	Done uint64
+
+	// Total is the estimated number of items to process, or 0 if unknown. It is an
+	// estimate, which Done may exceed.
+	Total uint64
+
+	// Completed is whether the step has completed.
+	Completed bool
+}
+
+// ProgressReporterFunc receives the progress of long running steps of migrations.
+type ProgressReporterFunc func(ctx context.Context, progress Progress)
+
+// ReportProgress reports the progress of a long running step of a migration to the
+// ProgressReporter of the context, or logs it if the context has none.
+func ReportProgress(ctx context.Context, progress Progress) {
+	if reporter, ok := ctx.Value(ProgressReporter).(ProgressReporterFunc); ok && reporter != nil {
+		reporter(ctx, progress)
+		return
+	}
+
+	event := log.Ctx(ctx).Info().
+		Str("step", progress.Step).
+		Uint64("done", progress.Done).
+		Bool("completed", progress.Completed)
+	if progress.Total > 0 {
+		event = event.Uint64("estimatedTotal", progress.Total).
+			Str("percent", fmt.Sprintf("%.1f", min(100, 100*float64(progress.Done)/float64(progress.Total))))
+	}
+	event.Msg("migration progress")
+}
+
+// BatchFunc processes a single batch of at most batchSize items of a backfill, and
+// returns the number of items it processed.
+type BatchFunc func(ctx context.Context, batchSize uint64) (uint64, error)
+
+// Backfill runs batch, each in their own transaction, until a batch processes no
+// items, reporting the progress of the backfill named step along the way. Batches
+// must only select items which have yet to be backfilled, such that an interrupted
+// backfill resumes where it stopped when its migration is run again. The total is
+// the estimated number of items to backfill, or 0 if unknown.
+//
+// The batch size is read from the BackfillBatchSize of the context, defaulting to
+// DefaultBackfillBatchSize. Backfill returns the number of items processed.
+func Backfill(ctx context.Context, step string, total uint64, batch BatchFunc) (uint64, error) {
+	batchSize, ok := ctx.Value(BackfillBatchSize).(uint64)
+	if !ok || batchSize == 0 {
+		batchSize = DefaultBackfillBatchSize
+	}
+
+	ReportProgress(ctx, Progress{Step: step, Total: total})
+
+	var done uint64
+	lastReported := time.Now()
+	for {
+		processed, err := batch(ctx, batchSize)
+		if err != nil {
+			return done, fmt.Errorf("backfill %s failed after %d items: %w", step, done, err)
+		}
+
+		if processed == 0 {
+			break
+		}
+
+		done += processed
+		if time.Since(lastReported) >= progressReportInterval {
+			ReportProgress(ctx, Progress{Step: step, Done: done, Total: total})
+			lastReported = time.Now()
+		}
+	}
+
+	ReportProgress(ctx, Progress{Step: step, Done: done, Total: total, Completed: true})
+	return done, nil
+}
@@ -0,0 +1,64 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func recordProgress(ctx context.Context, reports *[]Progress) context.Context {
+	return context.WithValue(ctx, ProgressReporter, ProgressReporterFunc(func(_ context.Context, progress Progress) {
+		*reports = append(*reports, progress)
+	}))
+}
+
+func TestBackfill(t *testing.T) {
+	defer func(interval time.Duration) { progressReportInterval = interval }(progressReportInterval)
+	progressReportInterval = 0
+
+	var reports []Progress
+	ctx := recordProgress(context.Background(), &reports)
+	ctx = context.WithValue(ctx, BackfillBatchSize, uint64(4))
+
+	remaining := uint64(10)
+	var batchSizes []uint64
+	done, err := Backfill(ctx, "test backfill", 10, func(_ context.Context, batchSize uint64) (uint64, error) {
+		batchSizes = append(batchSizes, batchSize)
+		processed := min(batchSize, remaining)
+		remaining -= processed
+		return processed, nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, uint64(10), done)
+	require.Equal(t, []uint64{4, 4, 4, 4}, batchSizes)
+	require.Equal(t, []Progress{
+		{Step: "test backfill", Total: 10},
+		{Step: "test backfill", Done: 4, Total: 10},
+		{Step: "test backfill", Done: 8, Total: 10},
+		{Step: "test backfill", Done: 10, Total: 10},
+		{Step: "test backfill", Done: 10, Total: 10, Completed: true},
+	}, reports)
+}
+
+func TestBackfillDefaultsAndErrors(t *testing.T) {
+	var reports []Progress
+	ctx := recordProgress(context.Background(), &reports)
+
+	calls := 0
+	done, err := Backfill(ctx, "failing backfill", 0, func(_ context.Context, batchSize uint64) (uint64, error) {
+		require.Equal(t, DefaultBackfillBatchSize, batchSize)
+		calls++
+		if calls > 2 {
+			return 0, errors.New("connection lost")
+		}
+		return batchSize, nil
+	})
+	require.ErrorContains(t, err, "backfill failing backfill failed after 2000 items: connection lost")
+	require.Equal(t, uint64(2000), done)
+
+	// Batches are only reported once the report interval has elapsed.
+	require.Equal(t, []Progress{{Step: "failing backfill"}}, reports)
+}
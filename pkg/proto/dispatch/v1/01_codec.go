@@ -2,12 +2,16 @@
 // with one that attempts to use protobuf codecs in the following order:
 // - vtprotobuf
 // - google.golang.org/encoding/proto
+//
+// Messages larger than chunkedMarshalThreshold are marshaled into chunks of
+// pooled buffers, rather than into a single buffer.
 
 package dispatchv1
 
 import (
 	"google.golang.org/grpc/encoding"
 	"google.golang.org/grpc/mem"
+	"google.golang.org/protobuf/proto"
 
 	// Guarantee that the built-in proto is called registered before this one
 	// so that it can be replaced.
@@ -40,6 +44,9 @@ func (c *vtprotoCodec) Marshal(v any) (mem.BufferSlice, error) {
 			}
 			return mem.BufferSlice{mem.SliceBuffer(buf[:n])}, nil
 		}
+		if pm, ok := v.(proto.Message); ok && size > chunkedMarshalThreshold {
+			return marshalChunked(pm, marshalChunkSize)
+		}
 		pool := mem.DefaultBufferPool()
 		buf := pool.Get(size)
 		n, err := m.MarshalToSizedBufferVT(*buf)
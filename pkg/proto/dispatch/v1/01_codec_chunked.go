@@ -0,0 +1,167 @@
+package dispatchv1
+
+import (
+	"fmt"
+
+	"google.golang.org/grpc/mem"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const (
+	// chunkedMarshalThreshold is the size above which messages are marshaled into chunks, rather
+	// than into a single buffer of their whole size. Messages this large are large expansion trees
+	// and lookup results, which would otherwise require allocating hundreds of megabytes at once.
+	chunkedMarshalThreshold = 4 << 20
+
+	// marshalChunkSize is the size of the pooled buffers into which large messages are marshaled.
+	marshalChunkSize = 1 << 20
+)
+
+// marshalChunked marshals the message into a slice of pooled buffers of about chunkSize bytes each.
+// The message fields of the message, and the elements of its repeated message fields, are each
+// marshaled separately: those larger than a chunk are recursively split in turn, while the others
+// are packed into the chunks. All other fields are marshaled together, ahead of the message fields.
+// The result is thus a valid encoding of the message, but not the deterministic one.
+func marshalChunked(m proto.Message, chunkSize int) (mem.BufferSlice, error) {
+	w := &chunkWriter{pool: mem.DefaultBufferPool(), chunkSize: chunkSize}
+	if err := w.writeMessage(m); err != nil {
+		w.free()
+		return nil, err
+	}
+
+	w.flush()
+	return w.chunks, nil
+}
+
+// chunkWriter writes a message into chunks taken from a buffer pool.
+type chunkWriter struct {
+	pool      mem.BufferPool
+	chunkSize int
+	chunks    mem.BufferSlice
+	current   *[]byte
+}
+
+// reserve returns n bytes at the end of the current chunk, starting a new chunk if it lacks room.
+func (w *chunkWriter) reserve(n int) []byte {
+	if w.current == nil || cap(*w.current)-len(*w.current) < n {
+		w.flush()
+		w.current = w.pool.Get(max(n, w.chunkSize))
+		*w.current = (*w.current)[:0]
+	}
+
+	start := len(*w.current)
+	*w.current = (*w.current)[:start+n]
+	return (*w.current)[start:]
+}
+
+func (w *chunkWriter) flush() {
+	if w.current == nil {
+		return
+	}
+
+	if len(*w.current) == 0 {
+		w.pool.Put(w.current)
+	} else {
+		w.chunks = append(w.chunks, mem.NewBuffer(w.current, w.pool))
+	}
+	w.current = nil
+}
+
+func (w *chunkWriter) free() {
+	w.flush()
+	w.chunks.Free()
+	w.chunks = nil
+}
+
+// writeMessage writes the fields of the message, splitting its message fields from the others.
+func (w *chunkWriter) writeMessage(m proto.Message) error {
+	msg := m.ProtoReflect()
+	rest := msg.New()
+
+	var split []protoreflect.FieldDescriptor
+	msg.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if fd.Kind() == protoreflect.MessageKind && !fd.IsMap() {
+			split = append(split, fd)
+		} else {
+			rest.Set(fd, v)
+		}
+		return true
+	})
+	rest.SetUnknown(msg.GetUnknown())
+
+	restMessage := rest.Interface()
+	if err := marshalInto(w.reserve(sizeOf(restMessage)), restMessage); err != nil {
+		return err
+	}
+
+	for _, fd := range split {
+		v := msg.Get(fd)
+		if !fd.IsList() {
+			if err := w.writeField(fd.Number(), v.Message().Interface()); err != nil {
+				return err
+			}
+			continue
+		}
+
+		list := v.List()
+		for i := range list.Len() {
+			if err := w.writeField(fd.Number(), list.Get(i).Message().Interface()); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// writeField writes a message field with the given number, splitting the message if it is larger
+// than a chunk.
+func (w *chunkWriter) writeField(num protowire.Number, m proto.Message) error {
+	size := sizeOf(m)
+	headerSize := protowire.SizeTag(num) + protowire.SizeVarint(uint64(size))
+	if size <= w.chunkSize {
+		buf := w.reserve(headerSize + size)
+		appendFieldHeader(buf[:0], num, size)
+		return marshalInto(buf[headerSize:], m)
+	}
+
+	appendFieldHeader(w.reserve(headerSize)[:0], num, size)
+	return w.writeMessage(m)
+}
+
+func appendFieldHeader(b []byte, num protowire.Number, size int) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendVarint(b, uint64(size))
+}
+
+func sizeOf(m proto.Message) int {
+	if vm, ok := m.(vtprotoMessage); ok {
+		return vm.SizeVT()
+	}
+	return proto.Size(m)
+}
+
+// marshalInto marshals the message into the buffer, which must be of the size of the message.
+func marshalInto(buf []byte, m proto.Message) error {
+	if vm, ok := m.(vtprotoMessage); ok {
+		n, err := vm.MarshalToSizedBufferVT(buf)
+		if err != nil {
+			return err
+		}
+		if n != len(buf) {
+			return fmt.Errorf("marshaled %d bytes of %T, expected %d", n, m, len(buf))
+		}
+		return nil
+	}
+
+	marshaled, err := proto.MarshalOptions{}.MarshalAppend(buf[:0], m)
+	if err != nil {
+		return err
+	}
+	if len(marshaled) != len(buf) {
+		return fmt.Errorf("marshaled %d bytes of %T, expected %d", len(marshaled), m, len(buf))
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package dispatchv1
+
+import (
+	"fmt"
+	"testing"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+)
+
+func largeExpandResponse(subjectCount int) *v1.ExpandPermissionTreeResponse {
+	leaf := func(prefix string) *v1.PermissionRelationshipTree {
+		subjects := make([]*v1.SubjectReference, 0, subjectCount)
+		for i := range subjectCount {
+			subjects = append(subjects, &v1.SubjectReference{
+				Object: &v1.ObjectReference{ObjectType: "user", ObjectId: fmt.Sprintf("%s-%d", prefix, i)},
+			})
+		}
+
+		return &v1.PermissionRelationshipTree{
+			TreeType:         &v1.PermissionRelationshipTree_Leaf{Leaf: &v1.DirectSubjectSet{Subjects: subjects}},
+			ExpandedObject:   &v1.ObjectReference{ObjectType: "group", ObjectId: prefix},
+			ExpandedRelation: "member",
+		}
+	}
+
+	return &v1.ExpandPermissionTreeResponse{
+		TreeRoot: &v1.PermissionRelationshipTree{
+			TreeType: &v1.PermissionRelationshipTree_Intermediate{Intermediate: &v1.AlgebraicSubjectSet{
+				Operation: v1.AlgebraicSubjectSet_OPERATION_UNION,
+				Children:  []*v1.PermissionRelationshipTree{leaf("first"), leaf("second"), {}},
+			}},
+			ExpandedObject:   &v1.ObjectReference{ObjectType: "document", ObjectId: "somedoc"},
+			ExpandedRelation: "view",
+		},
+		ExpandedAt: &v1.ZedToken{Token: "sometoken"},
+	}
+}
+
+func TestMarshalChunked(t *testing.T) {
+	caveatContext, err := structpb.NewStruct(map[string]any{"key": "value"})
+	require.NoError(t, err)
+
+	tcs := map[string]proto.Message{
+		"expand response": largeExpandResponse(100),
+		"lookup subjects response": &DispatchLookupSubjectsResponse{
+			FoundSubjectsByResourceId: map[string]*FoundSubjects{
+				"somedoc": {FoundSubjects: []*FoundSubject{{SubjectId: "tom"}, {SubjectId: "sarah"}}},
+			},
+			Metadata: &ResponseMeta{DispatchCount: 3, DepthRequired: 2},
+		},
+		"caveated tuple": &core.RelationTuple{
+			ResourceAndRelation: &core.ObjectAndRelation{Namespace: "document", ObjectId: "somedoc", Relation: "viewer"},
+			Subject:             &core.ObjectAndRelation{Namespace: "user", ObjectId: "tom", Relation: "..."},
+			Caveat:              &core.ContextualizedCaveat{CaveatName: "somecaveat", Context: caveatContext},
+		},
+		"empty": &v1.ExpandPermissionTreeResponse{},
+	}
+
+	for name, msg := range tcs {
+		t.Run(name, func(t *testing.T) {
+			for _, chunkSize := range []int{1, 64, 1024, marshalChunkSize} {
+				chunks, err := marshalChunked(msg, chunkSize)
+				require.NoError(t, err)
+
+				buf := chunks.Materialize()
+				chunks.Free()
+				require.Len(t, buf, proto.Size(msg))
+
+				decoded := msg.ProtoReflect().New().Interface()
+				require.NoError(t, proto.Unmarshal(buf, decoded))
+				require.True(t, proto.Equal(msg, decoded), "mismatch for chunk size %d", chunkSize)
+			}
+		})
+	}
+}
+
+func TestCodecMarshalsLargeMessagesInChunks(t *testing.T) {
+	codec := encoding.GetCodecV2(Name)
+
+	small := largeExpandResponse(10)
+	data, err := codec.Marshal(small)
+	require.NoError(t, err)
+	require.Len(t, data, 1)
+	data.Free()
+
+	large := largeExpandResponse(100_000)
+	require.Greater(t, proto.Size(large), chunkedMarshalThreshold)
+
+	data, err = codec.Marshal(large)
+	require.NoError(t, err)
+	defer data.Free()
+
+	require.Greater(t, len(data), 1)
+	for _, chunk := range data {
+		require.LessOrEqual(t, chunk.Len(), marshalChunkSize)
+	}
+	require.Equal(t, proto.Size(large), data.Len())
+
+	decoded := &v1.ExpandPermissionTreeResponse{}
+	require.NoError(t, codec.Unmarshal(data, decoded))
+	require.True(t, proto.Equal(large, decoded))
+}
+
+func BenchmarkMarshalLargeMessage(b *testing.B) {
+	large := largeExpandResponse(100_000)
+
+	b.Run("single buffer", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			buf := make([]byte, large.SizeVT())
+			if _, err := large.MarshalToSizedBufferVT(buf); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("chunked", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			data, err := marshalChunked(large, marshalChunkSize)
+			if err != nil {
+				b.Fatal(err)
+			}
+			data.Free()
+		}
+	})
+}
@@ -0,0 +1,335 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.2
+// 	protoc        (unknown)
+// source: dispatch/v1/cachewarming.proto
+
+package dispatchv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type DumpHotCacheEntriesResponse_RequestKind int32
+
+const (
+	DumpHotCacheEntriesResponse_REQUEST_KIND_UNSPECIFIED       DumpHotCacheEntriesResponse_RequestKind = 0
+	DumpHotCacheEntriesResponse_REQUEST_KIND_CHECK             DumpHotCacheEntriesResponse_RequestKind = 1
+	DumpHotCacheEntriesResponse_REQUEST_KIND_LOOKUP_RESOURCES2 DumpHotCacheEntriesResponse_RequestKind = 2
+	DumpHotCacheEntriesResponse_REQUEST_KIND_LOOKUP_SUBJECTS   DumpHotCacheEntriesResponse_RequestKind = 3
+)
+
+// Enum value maps for DumpHotCacheEntriesResponse_RequestKind.
+var (
+	DumpHotCacheEntriesResponse_RequestKind_name = map[int32]string{
+		0: "REQUEST_KIND_UNSPECIFIED",
+		1: "REQUEST_KIND_CHECK",
+		2: "REQUEST_KIND_LOOKUP_RESOURCES2",
+		3: "REQUEST_KIND_LOOKUP_SUBJECTS",
+	}
+	DumpHotCacheEntriesResponse_RequestKind_value = map[string]int32{
+		"REQUEST_KIND_UNSPECIFIED":       0,
+		"REQUEST_KIND_CHECK":             1,
+		"REQUEST_KIND_LOOKUP_RESOURCES2": 2,
+		"REQUEST_KIND_LOOKUP_SUBJECTS":   3,
+	}
+)
+
+func (x DumpHotCacheEntriesResponse_RequestKind) Enum() *DumpHotCacheEntriesResponse_RequestKind {
+	p := new(DumpHotCacheEntriesResponse_RequestKind)
+	*p = x
+	return p
+}
+
+func (x DumpHotCacheEntriesResponse_RequestKind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (DumpHotCacheEntriesResponse_RequestKind) Descriptor() protoreflect.EnumDescriptor {
+	return file_dispatch_v1_cachewarming_proto_enumTypes[0].Descriptor()
+}
+
+func (DumpHotCacheEntriesResponse_RequestKind) Type() protoreflect.EnumType {
+	return &file_dispatch_v1_cachewarming_proto_enumTypes[0]
+}
+
+func (x DumpHotCacheEntriesResponse_RequestKind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use DumpHotCacheEntriesResponse_RequestKind.Descriptor instead.
+func (DumpHotCacheEntriesResponse_RequestKind) EnumDescriptor() ([]byte, []int) {
+	return file_dispatch_v1_cachewarming_proto_rawDescGZIP(), []int{1, 0}
+}
+
+type DumpHotCacheEntriesRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// limit is the maximum number of entries returned.
+	Limit uint32 `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`
+}
+
+func (x *DumpHotCacheEntriesRequest) Reset() {
+	*x = DumpHotCacheEntriesRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dispatch_v1_cachewarming_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DumpHotCacheEntriesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DumpHotCacheEntriesRequest) ProtoMessage() {}
+
+func (x *DumpHotCacheEntriesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dispatch_v1_cachewarming_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DumpHotCacheEntriesRequest.ProtoReflect.Descriptor instead.
+func (*DumpHotCacheEntriesRequest) Descriptor() ([]byte, []int) {
+	return file_dispatch_v1_cachewarming_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *DumpHotCacheEntriesRequest) GetLimit() uint32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+// DumpHotCacheEntriesResponse is a single entry of the dispatch cache. As cache keys are specific
+// to each process, the entry carries the request for which the responses are cached, from which the
+// receiving node computes its own key.
+type DumpHotCacheEntriesResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// request_kind is the kind of the dispatch request.
+	RequestKind DumpHotCacheEntriesResponse_RequestKind `protobuf:"varint,1,opt,name=request_kind,json=requestKind,proto3,enum=dispatch.v1.DumpHotCacheEntriesResponse_RequestKind" json:"request_kind,omitempty"`
+	// request is the marshaled dispatch request.
+	Request []byte `protobuf:"bytes,2,opt,name=request,proto3" json:"request,omitempty"`
+	// responses are the marshaled responses cached for the request: a single one for checks, and
+	// every streamed one for lookups.
+	Responses [][]byte `protobuf:"bytes,3,rep,name=responses,proto3" json:"responses,omitempty"`
+	// hit_count is the number of cache hits of the entry on the node.
+	HitCount uint64 `protobuf:"varint,4,opt,name=hit_count,json=hitCount,proto3" json:"hit_count,omitempty"`
+}
+
+func (x *DumpHotCacheEntriesResponse) Reset() {
+	*x = DumpHotCacheEntriesResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dispatch_v1_cachewarming_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DumpHotCacheEntriesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DumpHotCacheEntriesResponse) ProtoMessage() {}
+
+func (x *DumpHotCacheEntriesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_dispatch_v1_cachewarming_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DumpHotCacheEntriesResponse.ProtoReflect.Descriptor instead.
+func (*DumpHotCacheEntriesResponse) Descriptor() ([]byte, []int) {
+	return file_dispatch_v1_cachewarming_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *DumpHotCacheEntriesResponse) GetRequestKind() DumpHotCacheEntriesResponse_RequestKind {
+	if x != nil {
+		return x.RequestKind
+	}
+	return DumpHotCacheEntriesResponse_REQUEST_KIND_UNSPECIFIED
+}
+
+func (x *DumpHotCacheEntriesResponse) GetRequest() []byte {
+	if x != nil {
+		return x.Request
+	}
+	return nil
+}
+
+func (x *DumpHotCacheEntriesResponse) GetResponses() [][]byte {
+	if x != nil {
+		return x.Responses
+	}
+	return nil
+}
+
+func (x *DumpHotCacheEntriesResponse) GetHitCount() uint64 {
+	if x != nil {
+		return x.HitCount
+	}
+	return 0
+}
+
+var File_dispatch_v1_cachewarming_proto protoreflect.FileDescriptor
+
+var file_dispatch_v1_cachewarming_proto_rawDesc = []byte{
+	0x0a, 0x1e, 0x64, 0x69, 0x73, 0x70, 0x61, 0x74, 0x63, 0x68, 0x2f, 0x76, 0x31, 0x2f, 0x63, 0x61,
+	0x63, 0x68, 0x65, 0x77, 0x61, 0x72, 0x6d, 0x69, 0x6e, 0x67, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x12, 0x0b, 0x64, 0x69, 0x73, 0x70, 0x61, 0x74, 0x63, 0x68, 0x2e, 0x76, 0x31, 0x22, 0x32, 0x0a,
+	0x1a, 0x44, 0x75, 0x6d, 0x70, 0x48, 0x6f, 0x74, 0x43, 0x61, 0x63, 0x68, 0x65, 0x45, 0x6e, 0x74,
+	0x72, 0x69, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x6c,
+	0x69, 0x6d, 0x69, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x6c, 0x69, 0x6d, 0x69,
+	0x74, 0x22, 0xd7, 0x02, 0x0a, 0x1b, 0x44, 0x75, 0x6d, 0x70, 0x48, 0x6f, 0x74, 0x43, 0x61, 0x63,
+	0x68, 0x65, 0x45, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x57, 0x0a, 0x0c, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x5f, 0x6b, 0x69, 0x6e,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x34, 0x2e, 0x64, 0x69, 0x73, 0x70, 0x61, 0x74,
+	0x63, 0x68, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x75, 0x6d, 0x70, 0x48, 0x6f, 0x74, 0x43, 0x61, 0x63,
+	0x68, 0x65, 0x45, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x2e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x4b, 0x69, 0x6e, 0x64, 0x52, 0x0b, 0x72,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x4b, 0x69, 0x6e, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x72, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x72, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x09, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x73, 0x12, 0x1b, 0x0a, 0x09, 0x68, 0x69, 0x74, 0x5f, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x68, 0x69, 0x74, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x22,
+	0x89, 0x01, 0x0a, 0x0b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x4b, 0x69, 0x6e, 0x64, 0x12,
+	0x1c, 0x0a, 0x18, 0x52, 0x45, 0x51, 0x55, 0x45, 0x53, 0x54, 0x5f, 0x4b, 0x49, 0x4e, 0x44, 0x5f,
+	0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x16, 0x0a,
+	0x12, 0x52, 0x45, 0x51, 0x55, 0x45, 0x53, 0x54, 0x5f, 0x4b, 0x49, 0x4e, 0x44, 0x5f, 0x43, 0x48,
+	0x45, 0x43, 0x4b, 0x10, 0x01, 0x12, 0x22, 0x0a, 0x1e, 0x52, 0x45, 0x51, 0x55, 0x45, 0x53, 0x54,
+	0x5f, 0x4b, 0x49, 0x4e, 0x44, 0x5f, 0x4c, 0x4f, 0x4f, 0x4b, 0x55, 0x50, 0x5f, 0x52, 0x45, 0x53,
+	0x4f, 0x55, 0x52, 0x43, 0x45, 0x53, 0x32, 0x10, 0x02, 0x12, 0x20, 0x0a, 0x1c, 0x52, 0x45, 0x51,
+	0x55, 0x45, 0x53, 0x54, 0x5f, 0x4b, 0x49, 0x4e, 0x44, 0x5f, 0x4c, 0x4f, 0x4f, 0x4b, 0x55, 0x50,
+	0x5f, 0x53, 0x55, 0x42, 0x4a, 0x45, 0x43, 0x54, 0x53, 0x10, 0x03, 0x32, 0x83, 0x01, 0x0a, 0x13,
+	0x43, 0x61, 0x63, 0x68, 0x65, 0x57, 0x61, 0x72, 0x6d, 0x69, 0x6e, 0x67, 0x53, 0x65, 0x72, 0x76,
+	0x69, 0x63, 0x65, 0x12, 0x6c, 0x0a, 0x13, 0x44, 0x75, 0x6d, 0x70, 0x48, 0x6f, 0x74, 0x43, 0x61,
+	0x63, 0x68, 0x65, 0x45, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x12, 0x27, 0x2e, 0x64, 0x69, 0x73,
+	0x70, 0x61, 0x74, 0x63, 0x68, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x75, 0x6d, 0x70, 0x48, 0x6f, 0x74,
+	0x43, 0x61, 0x63, 0x68, 0x65, 0x45, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x28, 0x2e, 0x64, 0x69, 0x73, 0x70, 0x61, 0x74, 0x63, 0x68, 0x2e, 0x76,
+	0x31, 0x2e, 0x44, 0x75, 0x6d, 0x70, 0x48, 0x6f, 0x74, 0x43, 0x61, 0x63, 0x68, 0x65, 0x45, 0x6e,
+	0x74, 0x72, 0x69, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x30,
+	0x01, 0x42, 0xae, 0x01, 0x0a, 0x0f, 0x63, 0x6f, 0x6d, 0x2e, 0x64, 0x69, 0x73, 0x70, 0x61, 0x74,
+	0x63, 0x68, 0x2e, 0x76, 0x31, 0x42, 0x11, 0x43, 0x61, 0x63, 0x68, 0x65, 0x77, 0x61, 0x72, 0x6d,
+	0x69, 0x6e, 0x67, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x50, 0x01, 0x5a, 0x3b, 0x67, 0x69, 0x74, 0x68,
+	0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x61, 0x75, 0x74, 0x68, 0x7a, 0x65, 0x64, 0x2f, 0x73,
+	0x70, 0x69, 0x63, 0x65, 0x64, 0x62, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x2f, 0x64, 0x69, 0x73, 0x70, 0x61, 0x74, 0x63, 0x68, 0x2f, 0x76, 0x31, 0x3b, 0x64, 0x69, 0x73,
+	0x70, 0x61, 0x74, 0x63, 0x68, 0x76, 0x31, 0xa2, 0x02, 0x03, 0x44, 0x58, 0x58, 0xaa, 0x02, 0x0b,
+	0x44, 0x69, 0x73, 0x70, 0x61, 0x74, 0x63, 0x68, 0x2e, 0x56, 0x31, 0xca, 0x02, 0x0b, 0x44, 0x69,
+	0x73, 0x70, 0x61, 0x74, 0x63, 0x68, 0x5c, 0x56, 0x31, 0xe2, 0x02, 0x17, 0x44, 0x69, 0x73, 0x70,
+	0x61, 0x74, 0x63, 0x68, 0x5c, 0x56, 0x31, 0x5c, 0x47, 0x50, 0x42, 0x4d, 0x65, 0x74, 0x61, 0x64,
+	0x61, 0x74, 0x61, 0xea, 0x02, 0x0c, 0x44, 0x69, 0x73, 0x70, 0x61, 0x74, 0x63, 0x68, 0x3a, 0x3a,
+	0x56, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_dispatch_v1_cachewarming_proto_rawDescOnce sync.Once
+	file_dispatch_v1_cachewarming_proto_rawDescData = file_dispatch_v1_cachewarming_proto_rawDesc
+)
+
+func file_dispatch_v1_cachewarming_proto_rawDescGZIP() []byte {
+	file_dispatch_v1_cachewarming_proto_rawDescOnce.Do(func() {
+		file_dispatch_v1_cachewarming_proto_rawDescData = protoimpl.X.CompressGZIP(file_dispatch_v1_cachewarming_proto_rawDescData)
+	})
+	return file_dispatch_v1_cachewarming_proto_rawDescData
+}
+
+var file_dispatch_v1_cachewarming_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_dispatch_v1_cachewarming_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_dispatch_v1_cachewarming_proto_goTypes = []any{
+	(DumpHotCacheEntriesResponse_RequestKind)(0), // 0: dispatch.v1.DumpHotCacheEntriesResponse.RequestKind
+	(*DumpHotCacheEntriesRequest)(nil),           // 1: dispatch.v1.DumpHotCacheEntriesRequest
+	(*DumpHotCacheEntriesResponse)(nil),          // 2: dispatch.v1.DumpHotCacheEntriesResponse
+}
+var file_dispatch_v1_cachewarming_proto_depIdxs = []int32{
+	0, // 0: dispatch.v1.DumpHotCacheEntriesResponse.request_kind:type_name -> dispatch.v1.DumpHotCacheEntriesResponse.RequestKind
+	1, // 1: dispatch.v1.CacheWarmingService.DumpHotCacheEntries:input_type -> dispatch.v1.DumpHotCacheEntriesRequest
+	2, // 2: dispatch.v1.CacheWarmingService.DumpHotCacheEntries:output_type -> dispatch.v1.DumpHotCacheEntriesResponse
+	2, // [2:3] is the sub-list for method output_type
+	1, // [1:2] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_dispatch_v1_cachewarming_proto_init() }
+func file_dispatch_v1_cachewarming_proto_init() {
+	if File_dispatch_v1_cachewarming_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_dispatch_v1_cachewarming_proto_msgTypes[0].Exporter = func(v any, i int) any {
+			switch v := v.(*DumpHotCacheEntriesRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dispatch_v1_cachewarming_proto_msgTypes[1].Exporter = func(v any, i int) any {
+			switch v := v.(*DumpHotCacheEntriesResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_dispatch_v1_cachewarming_proto_rawDesc,
+			NumEnums:      1,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_dispatch_v1_cachewarming_proto_goTypes,
+		DependencyIndexes: file_dispatch_v1_cachewarming_proto_depIdxs,
+		EnumInfos:         file_dispatch_v1_cachewarming_proto_enumTypes,
+		MessageInfos:      file_dispatch_v1_cachewarming_proto_msgTypes,
+	}.Build()
+	File_dispatch_v1_cachewarming_proto = out.File
+	file_dispatch_v1_cachewarming_proto_rawDesc = nil
+	file_dispatch_v1_cachewarming_proto_goTypes = nil
+	file_dispatch_v1_cachewarming_proto_depIdxs = nil
+}
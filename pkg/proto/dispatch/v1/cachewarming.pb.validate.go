@@ -0,0 +1,250 @@
+// Code generated by protoc-gen-validate. DO NOT EDIT.
+// source: dispatch/v1/cachewarming.proto
+
+package dispatchv1
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// ensure the imports are used
+var (
+	_ = bytes.MinRead
+	_ = errors.New("")
+	_ = fmt.Print
+	_ = utf8.UTFMax
+	_ = (*regexp.Regexp)(nil)
+	_ = (*strings.Reader)(nil)
+	_ = net.IPv4len
+	_ = time.Duration(0)
+	_ = (*url.URL)(nil)
+	_ = (*mail.Address)(nil)
+	_ = anypb.Any{}
+	_ = sort.Sort
+)
+
+// Validate checks the field values on DumpHotCacheEntriesRequest with the rules
+// defined in the proto definition for this message. If any rules are violated,
+// the first error encountered is returned, or nil if there are no violations.
+func (m *DumpHotCacheEntriesRequest) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on DumpHotCacheEntriesRequest with the
+// rules defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// DumpHotCacheEntriesRequestMultiError, or nil if none found.
+func (m *DumpHotCacheEntriesRequest) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *DumpHotCacheEntriesRequest) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Limit
+
+	if len(errors) > 0 {
+		return DumpHotCacheEntriesRequestMultiError(errors)
+	}
+
+	return nil
+}
+
+// DumpHotCacheEntriesRequestMultiError is an error wrapping multiple validation
+// errors returned by DumpHotCacheEntriesRequest.ValidateAll() if the designated
+// constraints aren't met.
+type DumpHotCacheEntriesRequestMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m DumpHotCacheEntriesRequestMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m DumpHotCacheEntriesRequestMultiError) AllErrors() []error { return m }
+
+// DumpHotCacheEntriesRequestValidationError is the validation error returned by
+// DumpHotCacheEntriesRequest.Validate if the designated constraints aren't met.
+type DumpHotCacheEntriesRequestValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e DumpHotCacheEntriesRequestValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e DumpHotCacheEntriesRequestValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e DumpHotCacheEntriesRequestValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e DumpHotCacheEntriesRequestValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e DumpHotCacheEntriesRequestValidationError) ErrorName() string {
+	return "DumpHotCacheEntriesRequestValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e DumpHotCacheEntriesRequestValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sDumpHotCacheEntriesRequest.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = DumpHotCacheEntriesRequestValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = DumpHotCacheEntriesRequestValidationError{}
+
+// Validate checks the field values on DumpHotCacheEntriesResponse with the
+// rules defined in the proto definition for this message. If any rules are
+// violated, the first error encountered is returned, or nil if there are no
+// violations.
+func (m *DumpHotCacheEntriesResponse) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on DumpHotCacheEntriesResponse with the
+// rules defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// DumpHotCacheEntriesResponseMultiError, or nil if none found.
+func (m *DumpHotCacheEntriesResponse) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *DumpHotCacheEntriesResponse) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for RequestKind
+
+	// no validation rules for Request
+
+	// no validation rules for HitCount
+
+	if len(errors) > 0 {
+		return DumpHotCacheEntriesResponseMultiError(errors)
+	}
+
+	return nil
+}
+
+// DumpHotCacheEntriesResponseMultiError is an error wrapping multiple
+// validation errors returned by DumpHotCacheEntriesResponse.ValidateAll() if
+// the designated constraints aren't met.
+type DumpHotCacheEntriesResponseMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m DumpHotCacheEntriesResponseMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m DumpHotCacheEntriesResponseMultiError) AllErrors() []error { return m }
+
+// DumpHotCacheEntriesResponseValidationError is the validation error returned
+// by DumpHotCacheEntriesResponse.Validate if the designated constraints aren't
+// met.
+type DumpHotCacheEntriesResponseValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e DumpHotCacheEntriesResponseValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e DumpHotCacheEntriesResponseValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e DumpHotCacheEntriesResponseValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e DumpHotCacheEntriesResponseValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e DumpHotCacheEntriesResponseValidationError) ErrorName() string {
+	return "DumpHotCacheEntriesResponseValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e DumpHotCacheEntriesResponseValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sDumpHotCacheEntriesResponse.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = DumpHotCacheEntriesResponseValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = DumpHotCacheEntriesResponseValidationError{}
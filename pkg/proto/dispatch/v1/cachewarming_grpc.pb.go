@@ -0,0 +1,140 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: dispatch/v1/cachewarming.proto
+
+package dispatchv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	CacheWarmingService_DumpHotCacheEntries_FullMethodName = "/dispatch.v1.CacheWarmingService/DumpHotCacheEntries"
+)
+
+// CacheWarmingServiceClient is the client API for CacheWarmingService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type CacheWarmingServiceClient interface {
+	// DumpHotCacheEntries streams the entries of the dispatch cache of the node with the most hits,
+	// in descending order of hits.
+	DumpHotCacheEntries(ctx context.Context, in *DumpHotCacheEntriesRequest, opts ...grpc.CallOption) (CacheWarmingService_DumpHotCacheEntriesClient, error)
+}
+
+type cacheWarmingServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCacheWarmingServiceClient(cc grpc.ClientConnInterface) CacheWarmingServiceClient {
+	return &cacheWarmingServiceClient{cc}
+}
+
+func (c *cacheWarmingServiceClient) DumpHotCacheEntries(ctx context.Context, in *DumpHotCacheEntriesRequest, opts ...grpc.CallOption) (CacheWarmingService_DumpHotCacheEntriesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &CacheWarmingService_ServiceDesc.Streams[0], CacheWarmingService_DumpHotCacheEntries_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &cacheWarmingServiceDumpHotCacheEntriesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type CacheWarmingService_DumpHotCacheEntriesClient interface {
+	Recv() (*DumpHotCacheEntriesResponse, error)
+	grpc.ClientStream
+}
+
+type cacheWarmingServiceDumpHotCacheEntriesClient struct {
+	grpc.ClientStream
+}
+
+func (x *cacheWarmingServiceDumpHotCacheEntriesClient) Recv() (*DumpHotCacheEntriesResponse, error) {
+	m := new(DumpHotCacheEntriesResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// CacheWarmingServiceServer is the server API for CacheWarmingService service.
+// All implementations must embed UnimplementedCacheWarmingServiceServer
+// for forward compatibility
+type CacheWarmingServiceServer interface {
+	// DumpHotCacheEntries streams the entries of the dispatch cache of the node with the most hits,
+	// in descending order of hits.
+	DumpHotCacheEntries(*DumpHotCacheEntriesRequest, CacheWarmingService_DumpHotCacheEntriesServer) error
+	mustEmbedUnimplementedCacheWarmingServiceServer()
+}
+
+// UnimplementedCacheWarmingServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedCacheWarmingServiceServer struct {
+}
+
+func (UnimplementedCacheWarmingServiceServer) DumpHotCacheEntries(*DumpHotCacheEntriesRequest, CacheWarmingService_DumpHotCacheEntriesServer) error {
+	return status.Errorf(codes.Unimplemented, "method DumpHotCacheEntries not implemented")
+}
+func (UnimplementedCacheWarmingServiceServer) mustEmbedUnimplementedCacheWarmingServiceServer() {}
+
+// UnsafeCacheWarmingServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to CacheWarmingServiceServer will
+// result in compilation errors.
+type UnsafeCacheWarmingServiceServer interface {
+	mustEmbedUnimplementedCacheWarmingServiceServer()
+}
+
+func RegisterCacheWarmingServiceServer(s grpc.ServiceRegistrar, srv CacheWarmingServiceServer) {
+	s.RegisterService(&CacheWarmingService_ServiceDesc, srv)
+}
+
+func _CacheWarmingService_DumpHotCacheEntries_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(DumpHotCacheEntriesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CacheWarmingServiceServer).DumpHotCacheEntries(m, &cacheWarmingServiceDumpHotCacheEntriesServer{stream})
+}
+
+type CacheWarmingService_DumpHotCacheEntriesServer interface {
+	Send(*DumpHotCacheEntriesResponse) error
+	grpc.ServerStream
+}
+
+type cacheWarmingServiceDumpHotCacheEntriesServer struct {
+	grpc.ServerStream
+}
+
+func (x *cacheWarmingServiceDumpHotCacheEntriesServer) Send(m *DumpHotCacheEntriesResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// CacheWarmingService_ServiceDesc is the grpc.ServiceDesc for CacheWarmingService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var CacheWarmingService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "dispatch.v1.CacheWarmingService",
+	HandlerType: (*CacheWarmingServiceServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "DumpHotCacheEntries",
+			Handler:       _CacheWarmingService_DumpHotCacheEntries_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "dispatch/v1/cachewarming.proto",
+}
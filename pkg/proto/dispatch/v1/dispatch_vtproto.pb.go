@@ -58,6 +58,11 @@ func (m *DispatchCheckRequest) CloneVT() *DispatchCheckRequest {
 		}
 		r.CheckHints = tmpContainer
 	}
+	if rhs := m.ExcludedCaveatNames; rhs != nil {
+		tmpContainer := make([]string, len(rhs))
+		copy(tmpContainer, rhs)
+		r.ExcludedCaveatNames = tmpContainer
+	}
 	if len(m.unknownFields) > 0 {
 		r.unknownFields = make([]byte, len(m.unknownFields))
 		copy(r.unknownFields, m.unknownFields)
@@ -589,6 +594,15 @@ func (this *DispatchCheckRequest) EqualVT(that *DispatchCheckRequest) bool {
 			}
 		}
 	}
+	if len(this.ExcludedCaveatNames) != len(that.ExcludedCaveatNames) {
+		return false
+	}
+	for i, vx := range this.ExcludedCaveatNames {
+		vy := that.ExcludedCaveatNames[i]
+		if vx != vy {
+			return false
+		}
+	}
 	return string(this.unknownFields) == string(that.unknownFields)
 }
 
@@ -1267,6 +1281,15 @@ func (m *DispatchCheckRequest) MarshalToSizedBufferVT(dAtA []byte) (int, error)
 		i -= len(m.unknownFields)
 		copy(dAtA[i:], m.unknownFields)
 	}
+	if len(m.ExcludedCaveatNames) > 0 {
+		for iNdEx := len(m.ExcludedCaveatNames) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.ExcludedCaveatNames[iNdEx])
+			copy(dAtA[i:], m.ExcludedCaveatNames[iNdEx])
+			i = protohelpers.EncodeVarint(dAtA, i, uint64(len(m.ExcludedCaveatNames[iNdEx])))
+			i--
+			dAtA[i] = 0x42
+		}
+	}
 	if len(m.CheckHints) > 0 {
 		for iNdEx := len(m.CheckHints) - 1; iNdEx >= 0; iNdEx-- {
 			size, err := m.CheckHints[iNdEx].MarshalToSizedBufferVT(dAtA[:i])
@@ -2633,6 +2656,12 @@ func (m *DispatchCheckRequest) SizeVT() (n int) {
 			n += 1 + l + protohelpers.SizeOfVarint(uint64(l))
 		}
 	}
+	if len(m.ExcludedCaveatNames) > 0 {
+		for _, s := range m.ExcludedCaveatNames {
+			l = len(s)
+			n += 1 + l + protohelpers.SizeOfVarint(uint64(l))
+		}
+	}
 	n += len(m.unknownFields)
 	return n
 }
@@ -3393,6 +3422,38 @@ func (m *DispatchCheckRequest) UnmarshalVT(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 8:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ExcludedCaveatNames", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return protohelpers.ErrIntOverflow
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return protohelpers.ErrInvalidLength
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return protohelpers.ErrInvalidLength
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ExcludedCaveatNames = append(m.ExcludedCaveatNames, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := protohelpers.Skip(dAtA[iNdEx:])
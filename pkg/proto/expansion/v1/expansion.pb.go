@@ -0,0 +1,358 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.2
+// 	protoc        (unknown)
+// source: expansion/v1/expansion.proto
+
+package expansionv1
+
+import (
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// ExpandPermissionTreeWithLimitsRequest is the request to expand the permission tree of a resource.
+type ExpandPermissionTreeWithLimitsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// consistency is the consistency at which the tree is expanded.
+	Consistency *v1.Consistency `protobuf:"bytes,1,opt,name=consistency,proto3" json:"consistency,omitempty"`
+	// resource is the resource whose permission tree is expanded.
+	Resource *v1.ObjectReference `protobuf:"bytes,2,opt,name=resource,proto3" json:"resource,omitempty"`
+	// permission is the relation or permission expanded.
+	Permission string `protobuf:"bytes,3,opt,name=permission,proto3" json:"permission,omitempty"`
+	// max_depth is the number of levels of subject sets expanded below the tree of the permission.
+	// With a max_depth of 0, the tree is the one returned by ExpandPermissionTree.
+	MaxDepth uint32 `protobuf:"varint,4,opt,name=max_depth,json=maxDepth,proto3" json:"max_depth,omitempty"`
+	// optional_limit is the maximum number of leaf subjects returned. If unspecified, all of them are
+	// returned.
+	OptionalLimit uint32 `protobuf:"varint,5,opt,name=optional_limit,json=optionalLimit,proto3" json:"optional_limit,omitempty"`
+	// optional_cursor is the cursor after which to continue returning leaf subjects, as returned in
+	// after_result_cursor.
+	OptionalCursor *v1.Cursor `protobuf:"bytes,6,opt,name=optional_cursor,json=optionalCursor,proto3" json:"optional_cursor,omitempty"`
+}
+
+func (x *ExpandPermissionTreeWithLimitsRequest) Reset() {
+	*x = ExpandPermissionTreeWithLimitsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_expansion_v1_expansion_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ExpandPermissionTreeWithLimitsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExpandPermissionTreeWithLimitsRequest) ProtoMessage() {}
+
+func (x *ExpandPermissionTreeWithLimitsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_expansion_v1_expansion_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExpandPermissionTreeWithLimitsRequest.ProtoReflect.Descriptor instead.
+func (*ExpandPermissionTreeWithLimitsRequest) Descriptor() ([]byte, []int) {
+	return file_expansion_v1_expansion_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ExpandPermissionTreeWithLimitsRequest) GetConsistency() *v1.Consistency {
+	if x != nil {
+		return x.Consistency
+	}
+	return nil
+}
+
+func (x *ExpandPermissionTreeWithLimitsRequest) GetResource() *v1.ObjectReference {
+	if x != nil {
+		return x.Resource
+	}
+	return nil
+}
+
+func (x *ExpandPermissionTreeWithLimitsRequest) GetPermission() string {
+	if x != nil {
+		return x.Permission
+	}
+	return ""
+}
+
+func (x *ExpandPermissionTreeWithLimitsRequest) GetMaxDepth() uint32 {
+	if x != nil {
+		return x.MaxDepth
+	}
+	return 0
+}
+
+func (x *ExpandPermissionTreeWithLimitsRequest) GetOptionalLimit() uint32 {
+	if x != nil {
+		return x.OptionalLimit
+	}
+	return 0
+}
+
+func (x *ExpandPermissionTreeWithLimitsRequest) GetOptionalCursor() *v1.Cursor {
+	if x != nil {
+		return x.OptionalCursor
+	}
+	return nil
+}
+
+// ExpandPermissionTreeWithLimitsResponse contains a page of the expanded permission tree.
+type ExpandPermissionTreeWithLimitsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// tree_root is the root of the expanded tree. When paging, it contains only the leaf subjects of
+	// the page, along with the intermediate nodes leading to them.
+	TreeRoot *v1.PermissionRelationshipTree `protobuf:"bytes,1,opt,name=tree_root,json=treeRoot,proto3" json:"tree_root,omitempty"`
+	// expanded_at is the revision at which the tree was expanded.
+	ExpandedAt *v1.ZedToken `protobuf:"bytes,2,opt,name=expanded_at,json=expandedAt,proto3" json:"expanded_at,omitempty"`
+	// frozen_subject_sets are the subject sets of the page found in leaves at max_depth, which were
+	// left unexpanded. Each can be expanded by a further request.
+	FrozenSubjectSets []*v1.SubjectReference `protobuf:"bytes,3,rep,name=frozen_subject_sets,json=frozenSubjectSets,proto3" json:"frozen_subject_sets,omitempty"`
+	// after_result_cursor is the cursor from which to request the next page of leaf subjects, set
+	// only if more leaf subjects remain.
+	AfterResultCursor *v1.Cursor `protobuf:"bytes,4,opt,name=after_result_cursor,json=afterResultCursor,proto3" json:"after_result_cursor,omitempty"`
+}
+
+func (x *ExpandPermissionTreeWithLimitsResponse) Reset() {
+	*x = ExpandPermissionTreeWithLimitsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_expansion_v1_expansion_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ExpandPermissionTreeWithLimitsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExpandPermissionTreeWithLimitsResponse) ProtoMessage() {}
+
+func (x *ExpandPermissionTreeWithLimitsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_expansion_v1_expansion_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExpandPermissionTreeWithLimitsResponse.ProtoReflect.Descriptor instead.
+func (*ExpandPermissionTreeWithLimitsResponse) Descriptor() ([]byte, []int) {
+	return file_expansion_v1_expansion_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ExpandPermissionTreeWithLimitsResponse) GetTreeRoot() *v1.PermissionRelationshipTree {
+	if x != nil {
+		return x.TreeRoot
+	}
+	return nil
+}
+
+func (x *ExpandPermissionTreeWithLimitsResponse) GetExpandedAt() *v1.ZedToken {
+	if x != nil {
+		return x.ExpandedAt
+	}
+	return nil
+}
+
+func (x *ExpandPermissionTreeWithLimitsResponse) GetFrozenSubjectSets() []*v1.SubjectReference {
+	if x != nil {
+		return x.FrozenSubjectSets
+	}
+	return nil
+}
+
+func (x *ExpandPermissionTreeWithLimitsResponse) GetAfterResultCursor() *v1.Cursor {
+	if x != nil {
+		return x.AfterResultCursor
+	}
+	return nil
+}
+
+var File_expansion_v1_expansion_proto protoreflect.FileDescriptor
+
+var file_expansion_v1_expansion_proto_rawDesc = []byte{
+	0x0a, 0x1c, 0x65, 0x78, 0x70, 0x61, 0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x2f, 0x76, 0x31, 0x2f, 0x65,
+	0x78, 0x70, 0x61, 0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0c,
+	0x65, 0x78, 0x70, 0x61, 0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x1a, 0x19, 0x61, 0x75,
+	0x74, 0x68, 0x7a, 0x65, 0x64, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x76, 0x31, 0x2f, 0x63, 0x6f, 0x72,
+	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x27, 0x61, 0x75, 0x74, 0x68, 0x7a, 0x65, 0x64,
+	0x2f, 0x61, 0x70, 0x69, 0x2f, 0x76, 0x31, 0x2f, 0x70, 0x65, 0x72, 0x6d, 0x69, 0x73, 0x73, 0x69,
+	0x6f, 0x6e, 0x5f, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x22, 0xc8, 0x02, 0x0a, 0x25, 0x45, 0x78, 0x70, 0x61, 0x6e, 0x64, 0x50, 0x65, 0x72, 0x6d, 0x69,
+	0x73, 0x73, 0x69, 0x6f, 0x6e, 0x54, 0x72, 0x65, 0x65, 0x57, 0x69, 0x74, 0x68, 0x4c, 0x69, 0x6d,
+	0x69, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x3d, 0x0a, 0x0b, 0x63, 0x6f,
+	0x6e, 0x73, 0x69, 0x73, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1b, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x7a, 0x65, 0x64, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x76, 0x31,
+	0x2e, 0x43, 0x6f, 0x6e, 0x73, 0x69, 0x73, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x52, 0x0b, 0x63, 0x6f,
+	0x6e, 0x73, 0x69, 0x73, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x12, 0x3b, 0x0a, 0x08, 0x72, 0x65, 0x73,
+	0x6f, 0x75, 0x72, 0x63, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x61, 0x75,
+	0x74, 0x68, 0x7a, 0x65, 0x64, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x76, 0x31, 0x2e, 0x4f, 0x62, 0x6a,
+	0x65, 0x63, 0x74, 0x52, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x52, 0x08, 0x72, 0x65,
+	0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x12, 0x1e, 0x0a, 0x0a, 0x70, 0x65, 0x72, 0x6d, 0x69, 0x73,
+	0x73, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x70, 0x65, 0x72, 0x6d,
+	0x69, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x1b, 0x0a, 0x09, 0x6d, 0x61, 0x78, 0x5f, 0x64, 0x65,
+	0x70, 0x74, 0x68, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x6d, 0x61, 0x78, 0x44, 0x65,
+	0x70, 0x74, 0x68, 0x12, 0x25, 0x0a, 0x0e, 0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x61, 0x6c, 0x5f,
+	0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0d, 0x6f, 0x70, 0x74,
+	0x69, 0x6f, 0x6e, 0x61, 0x6c, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x12, 0x3f, 0x0a, 0x0f, 0x6f, 0x70,
+	0x74, 0x69, 0x6f, 0x6e, 0x61, 0x6c, 0x5f, 0x63, 0x75, 0x72, 0x73, 0x6f, 0x72, 0x18, 0x06, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x7a, 0x65, 0x64, 0x2e, 0x61, 0x70,
+	0x69, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x75, 0x72, 0x73, 0x6f, 0x72, 0x52, 0x0e, 0x6f, 0x70, 0x74,
+	0x69, 0x6f, 0x6e, 0x61, 0x6c, 0x43, 0x75, 0x72, 0x73, 0x6f, 0x72, 0x22, 0xc6, 0x02, 0x0a, 0x26,
+	0x45, 0x78, 0x70, 0x61, 0x6e, 0x64, 0x50, 0x65, 0x72, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6f, 0x6e,
+	0x54, 0x72, 0x65, 0x65, 0x57, 0x69, 0x74, 0x68, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x73, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x47, 0x0a, 0x09, 0x74, 0x72, 0x65, 0x65, 0x5f, 0x72,
+	0x6f, 0x6f, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x2a, 0x2e, 0x61, 0x75, 0x74, 0x68,
+	0x7a, 0x65, 0x64, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x65, 0x72, 0x6d, 0x69,
+	0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x68, 0x69,
+	0x70, 0x54, 0x72, 0x65, 0x65, 0x52, 0x08, 0x74, 0x72, 0x65, 0x65, 0x52, 0x6f, 0x6f, 0x74, 0x12,
+	0x39, 0x0a, 0x0b, 0x65, 0x78, 0x70, 0x61, 0x6e, 0x64, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x7a, 0x65, 0x64, 0x2e, 0x61,
+	0x70, 0x69, 0x2e, 0x76, 0x31, 0x2e, 0x5a, 0x65, 0x64, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x0a,
+	0x65, 0x78, 0x70, 0x61, 0x6e, 0x64, 0x65, 0x64, 0x41, 0x74, 0x12, 0x50, 0x0a, 0x13, 0x66, 0x72,
+	0x6f, 0x7a, 0x65, 0x6e, 0x5f, 0x73, 0x75, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x5f, 0x73, 0x65, 0x74,
+	0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x7a, 0x65,
+	0x64, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x75, 0x62, 0x6a, 0x65, 0x63, 0x74,
+	0x52, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x52, 0x11, 0x66, 0x72, 0x6f, 0x7a, 0x65,
+	0x6e, 0x53, 0x75, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x53, 0x65, 0x74, 0x73, 0x12, 0x46, 0x0a, 0x13,
+	0x61, 0x66, 0x74, 0x65, 0x72, 0x5f, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x5f, 0x63, 0x75, 0x72,
+	0x73, 0x6f, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x61, 0x75, 0x74, 0x68,
+	0x7a, 0x65, 0x64, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x75, 0x72, 0x73, 0x6f,
+	0x72, 0x52, 0x11, 0x61, 0x66, 0x74, 0x65, 0x72, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x43, 0x75,
+	0x72, 0x73, 0x6f, 0x72, 0x32, 0xa2, 0x01, 0x0a, 0x10, 0x45, 0x78, 0x70, 0x61, 0x6e, 0x73, 0x69,
+	0x6f, 0x6e, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x8d, 0x01, 0x0a, 0x1e, 0x45, 0x78,
+	0x70, 0x61, 0x6e, 0x64, 0x50, 0x65, 0x72, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x54, 0x72,
+	0x65, 0x65, 0x57, 0x69, 0x74, 0x68, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x73, 0x12, 0x33, 0x2e, 0x65,
+	0x78, 0x70, 0x61, 0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x78, 0x70, 0x61,
+	0x6e, 0x64, 0x50, 0x65, 0x72, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x54, 0x72, 0x65, 0x65,
+	0x57, 0x69, 0x74, 0x68, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x34, 0x2e, 0x65, 0x78, 0x70, 0x61, 0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31,
+	0x2e, 0x45, 0x78, 0x70, 0x61, 0x6e, 0x64, 0x50, 0x65, 0x72, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6f,
+	0x6e, 0x54, 0x72, 0x65, 0x65, 0x57, 0x69, 0x74, 0x68, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x73, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x42, 0x3f, 0x5a, 0x3d, 0x67, 0x69, 0x74,
+	0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x61, 0x75, 0x74, 0x68, 0x7a, 0x65, 0x64, 0x2f,
+	0x73, 0x70, 0x69, 0x63, 0x65, 0x64, 0x62, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x2f, 0x65, 0x78, 0x70, 0x61, 0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x2f, 0x76, 0x31, 0x3b, 0x65,
+	0x78, 0x70, 0x61, 0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x76, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x33,
+}
+
+var (
+	file_expansion_v1_expansion_proto_rawDescOnce sync.Once
+	file_expansion_v1_expansion_proto_rawDescData = file_expansion_v1_expansion_proto_rawDesc
+)
+
+func file_expansion_v1_expansion_proto_rawDescGZIP() []byte {
+	file_expansion_v1_expansion_proto_rawDescOnce.Do(func() {
+		file_expansion_v1_expansion_proto_rawDescData = protoimpl.X.CompressGZIP(file_expansion_v1_expansion_proto_rawDescData)
+	})
+	return file_expansion_v1_expansion_proto_rawDescData
+}
+
+var file_expansion_v1_expansion_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_expansion_v1_expansion_proto_goTypes = []any{
+	(*ExpandPermissionTreeWithLimitsRequest)(nil),  // 0: expansion.v1.ExpandPermissionTreeWithLimitsRequest
+	(*ExpandPermissionTreeWithLimitsResponse)(nil), // 1: expansion.v1.ExpandPermissionTreeWithLimitsResponse
+	(*v1.Consistency)(nil),                         // 2: authzed.api.v1.Consistency
+	(*v1.ObjectReference)(nil),                     // 3: authzed.api.v1.ObjectReference
+	(*v1.Cursor)(nil),                              // 4: authzed.api.v1.Cursor
+	(*v1.PermissionRelationshipTree)(nil),          // 5: authzed.api.v1.PermissionRelationshipTree
+	(*v1.ZedToken)(nil),                            // 6: authzed.api.v1.ZedToken
+	(*v1.SubjectReference)(nil),                    // 7: authzed.api.v1.SubjectReference
+}
+var file_expansion_v1_expansion_proto_depIdxs = []int32{
+	2, // 0: expansion.v1.ExpandPermissionTreeWithLimitsRequest.consistency:type_name -> authzed.api.v1.Consistency
+	3, // 1: expansion.v1.ExpandPermissionTreeWithLimitsRequest.resource:type_name -> authzed.api.v1.ObjectReference
+	4, // 2: expansion.v1.ExpandPermissionTreeWithLimitsRequest.optional_cursor:type_name -> authzed.api.v1.Cursor
+	5, // 3: expansion.v1.ExpandPermissionTreeWithLimitsResponse.tree_root:type_name -> authzed.api.v1.PermissionRelationshipTree
+	6, // 4: expansion.v1.ExpandPermissionTreeWithLimitsResponse.expanded_at:type_name -> authzed.api.v1.ZedToken
+	7, // 5: expansion.v1.ExpandPermissionTreeWithLimitsResponse.frozen_subject_sets:type_name -> authzed.api.v1.SubjectReference
+	4, // 6: expansion.v1.ExpandPermissionTreeWithLimitsResponse.after_result_cursor:type_name -> authzed.api.v1.Cursor
+	0, // 7: expansion.v1.ExpansionService.ExpandPermissionTreeWithLimits:input_type -> expansion.v1.ExpandPermissionTreeWithLimitsRequest
+	1, // 8: expansion.v1.ExpansionService.ExpandPermissionTreeWithLimits:output_type -> expansion.v1.ExpandPermissionTreeWithLimitsResponse
+	8, // [8:9] is the sub-list for method output_type
+	7, // [7:8] is the sub-list for method input_type
+	7, // [7:7] is the sub-list for extension type_name
+	7, // [7:7] is the sub-list for extension extendee
+	0, // [0:7] is the sub-list for field type_name
+}
+
+func init() { file_expansion_v1_expansion_proto_init() }
+func file_expansion_v1_expansion_proto_init() {
+	if File_expansion_v1_expansion_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_expansion_v1_expansion_proto_msgTypes[0].Exporter = func(v any, i int) any {
+			switch v := v.(*ExpandPermissionTreeWithLimitsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_expansion_v1_expansion_proto_msgTypes[1].Exporter = func(v any, i int) any {
+			switch v := v.(*ExpandPermissionTreeWithLimitsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_expansion_v1_expansion_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_expansion_v1_expansion_proto_goTypes,
+		DependencyIndexes: file_expansion_v1_expansion_proto_depIdxs,
+		MessageInfos:      file_expansion_v1_expansion_proto_msgTypes,
+	}.Build()
+	File_expansion_v1_expansion_proto = out.File
+	file_expansion_v1_expansion_proto_rawDesc = nil
+	file_expansion_v1_expansion_proto_goTypes = nil
+	file_expansion_v1_expansion_proto_depIdxs = nil
+}
@@ -0,0 +1,460 @@
+// Code generated by protoc-gen-validate. DO NOT EDIT.
+// source: expansion/v1/expansion.proto
+
+package expansionv1
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// ensure the imports are used
+var (
+	_ = bytes.MinRead
+	_ = errors.New("")
+	_ = fmt.Print
+	_ = utf8.UTFMax
+	_ = (*regexp.Regexp)(nil)
+	_ = (*strings.Reader)(nil)
+	_ = net.IPv4len
+	_ = time.Duration(0)
+	_ = (*url.URL)(nil)
+	_ = (*mail.Address)(nil)
+	_ = anypb.Any{}
+	_ = sort.Sort
+)
+
+// Validate checks the field values on ExpandPermissionTreeWithLimitsRequest
+// with the rules defined in the proto definition for this message. If any rules
+// are violated, the first error encountered is returned, or nil if there are no
+// violations.
+func (m *ExpandPermissionTreeWithLimitsRequest) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on ExpandPermissionTreeWithLimitsRequest
+// with the rules defined in the proto definition for this message. If any rules
+// are violated, the result is a list of violation errors wrapped in
+// ExpandPermissionTreeWithLimitsRequestMultiError, or nil if none found.
+func (m *ExpandPermissionTreeWithLimitsRequest) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *ExpandPermissionTreeWithLimitsRequest) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if all {
+		switch v := interface{}(m.GetConsistency()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, ExpandPermissionTreeWithLimitsRequestValidationError{
+					field:  "Consistency",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, ExpandPermissionTreeWithLimitsRequestValidationError{
+					field:  "Consistency",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetConsistency()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return ExpandPermissionTreeWithLimitsRequestValidationError{
+				field:  "Consistency",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if all {
+		switch v := interface{}(m.GetResource()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, ExpandPermissionTreeWithLimitsRequestValidationError{
+					field:  "Resource",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, ExpandPermissionTreeWithLimitsRequestValidationError{
+					field:  "Resource",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetResource()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return ExpandPermissionTreeWithLimitsRequestValidationError{
+				field:  "Resource",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	// no validation rules for Permission
+
+	// no validation rules for MaxDepth
+
+	// no validation rules for OptionalLimit
+
+	if all {
+		switch v := interface{}(m.GetOptionalCursor()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, ExpandPermissionTreeWithLimitsRequestValidationError{
+					field:  "OptionalCursor",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, ExpandPermissionTreeWithLimitsRequestValidationError{
+					field:  "OptionalCursor",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetOptionalCursor()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return ExpandPermissionTreeWithLimitsRequestValidationError{
+				field:  "OptionalCursor",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if len(errors) > 0 {
+		return ExpandPermissionTreeWithLimitsRequestMultiError(errors)
+	}
+
+	return nil
+}
+
+// ExpandPermissionTreeWithLimitsRequestMultiError is an error wrapping multiple
+// validation errors returned by
+// ExpandPermissionTreeWithLimitsRequest.ValidateAll() if the designated
+// constraints aren't met.
+type ExpandPermissionTreeWithLimitsRequestMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m ExpandPermissionTreeWithLimitsRequestMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m ExpandPermissionTreeWithLimitsRequestMultiError) AllErrors() []error { return m }
+
+// ExpandPermissionTreeWithLimitsRequestValidationError is the validation error
+// returned by ExpandPermissionTreeWithLimitsRequest.Validate if the designated
+// constraints aren't met.
+type ExpandPermissionTreeWithLimitsRequestValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e ExpandPermissionTreeWithLimitsRequestValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e ExpandPermissionTreeWithLimitsRequestValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e ExpandPermissionTreeWithLimitsRequestValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e ExpandPermissionTreeWithLimitsRequestValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e ExpandPermissionTreeWithLimitsRequestValidationError) ErrorName() string {
+	return "ExpandPermissionTreeWithLimitsRequestValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e ExpandPermissionTreeWithLimitsRequestValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sExpandPermissionTreeWithLimitsRequest.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = ExpandPermissionTreeWithLimitsRequestValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = ExpandPermissionTreeWithLimitsRequestValidationError{}
+
+// Validate checks the field values on ExpandPermissionTreeWithLimitsResponse
+// with the rules defined in the proto definition for this message. If any rules
+// are violated, the first error encountered is returned, or nil if there are no
+// violations.
+func (m *ExpandPermissionTreeWithLimitsResponse) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on ExpandPermissionTreeWithLimitsResponse
+// with the rules defined in the proto definition for this message. If any rules
+// are violated, the result is a list of violation errors wrapped in
+// ExpandPermissionTreeWithLimitsResponseMultiError, or nil if none found.
+func (m *ExpandPermissionTreeWithLimitsResponse) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *ExpandPermissionTreeWithLimitsResponse) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if all {
+		switch v := interface{}(m.GetTreeRoot()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, ExpandPermissionTreeWithLimitsResponseValidationError{
+					field:  "TreeRoot",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, ExpandPermissionTreeWithLimitsResponseValidationError{
+					field:  "TreeRoot",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetTreeRoot()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return ExpandPermissionTreeWithLimitsResponseValidationError{
+				field:  "TreeRoot",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if all {
+		switch v := interface{}(m.GetExpandedAt()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, ExpandPermissionTreeWithLimitsResponseValidationError{
+					field:  "ExpandedAt",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, ExpandPermissionTreeWithLimitsResponseValidationError{
+					field:  "ExpandedAt",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetExpandedAt()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return ExpandPermissionTreeWithLimitsResponseValidationError{
+				field:  "ExpandedAt",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	for idx, item := range m.GetFrozenSubjectSets() {
+		_, _ = idx, item
+
+		if all {
+			switch v := interface{}(item).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, ExpandPermissionTreeWithLimitsResponseValidationError{
+						field:  fmt.Sprintf("FrozenSubjectSets[%v]", idx),
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, ExpandPermissionTreeWithLimitsResponseValidationError{
+						field:  fmt.Sprintf("FrozenSubjectSets[%v]", idx),
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(item).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return ExpandPermissionTreeWithLimitsResponseValidationError{
+					field:  fmt.Sprintf("FrozenSubjectSets[%v]", idx),
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	}
+
+	if all {
+		switch v := interface{}(m.GetAfterResultCursor()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, ExpandPermissionTreeWithLimitsResponseValidationError{
+					field:  "AfterResultCursor",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, ExpandPermissionTreeWithLimitsResponseValidationError{
+					field:  "AfterResultCursor",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetAfterResultCursor()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return ExpandPermissionTreeWithLimitsResponseValidationError{
+				field:  "AfterResultCursor",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if len(errors) > 0 {
+		return ExpandPermissionTreeWithLimitsResponseMultiError(errors)
+	}
+
+	return nil
+}
+
+// ExpandPermissionTreeWithLimitsResponseMultiError is an error wrapping
+// multiple validation errors returned by
+// ExpandPermissionTreeWithLimitsResponse.ValidateAll() if the designated
+// constraints aren't met.
+type ExpandPermissionTreeWithLimitsResponseMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m ExpandPermissionTreeWithLimitsResponseMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m ExpandPermissionTreeWithLimitsResponseMultiError) AllErrors() []error { return m }
+
+// ExpandPermissionTreeWithLimitsResponseValidationError is the validation error
+// returned by ExpandPermissionTreeWithLimitsResponse.Validate if the designated
+// constraints aren't met.
+type ExpandPermissionTreeWithLimitsResponseValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e ExpandPermissionTreeWithLimitsResponseValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e ExpandPermissionTreeWithLimitsResponseValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e ExpandPermissionTreeWithLimitsResponseValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e ExpandPermissionTreeWithLimitsResponseValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e ExpandPermissionTreeWithLimitsResponseValidationError) ErrorName() string {
+	return "ExpandPermissionTreeWithLimitsResponseValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e ExpandPermissionTreeWithLimitsResponseValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sExpandPermissionTreeWithLimitsResponse.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = ExpandPermissionTreeWithLimitsResponseValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = ExpandPermissionTreeWithLimitsResponseValidationError{}
@@ -0,0 +1,113 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: expansion/v1/expansion.proto
+
+package expansionv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	ExpansionService_ExpandPermissionTreeWithLimits_FullMethodName = "/expansion.v1.ExpansionService/ExpandPermissionTreeWithLimits"
+)
+
+// ExpansionServiceClient is the client API for ExpansionService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ExpansionServiceClient interface {
+	// ExpandPermissionTreeWithLimits expands the permission tree of a resource like
+	// ExpandPermissionTree, further expanding the subject sets found in its leaves up to max_depth.
+	ExpandPermissionTreeWithLimits(ctx context.Context, in *ExpandPermissionTreeWithLimitsRequest, opts ...grpc.CallOption) (*ExpandPermissionTreeWithLimitsResponse, error)
+}
+
+type expansionServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewExpansionServiceClient(cc grpc.ClientConnInterface) ExpansionServiceClient {
+	return &expansionServiceClient{cc}
+}
+
+func (c *expansionServiceClient) ExpandPermissionTreeWithLimits(ctx context.Context, in *ExpandPermissionTreeWithLimitsRequest, opts ...grpc.CallOption) (*ExpandPermissionTreeWithLimitsResponse, error) {
+	out := new(ExpandPermissionTreeWithLimitsResponse)
+	err := c.cc.Invoke(ctx, ExpansionService_ExpandPermissionTreeWithLimits_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ExpansionServiceServer is the server API for ExpansionService service.
+// All implementations must embed UnimplementedExpansionServiceServer
+// for forward compatibility
+type ExpansionServiceServer interface {
+	// ExpandPermissionTreeWithLimits expands the permission tree of a resource like
+	// ExpandPermissionTree, further expanding the subject sets found in its leaves up to max_depth.
+	ExpandPermissionTreeWithLimits(context.Context, *ExpandPermissionTreeWithLimitsRequest) (*ExpandPermissionTreeWithLimitsResponse, error)
+	mustEmbedUnimplementedExpansionServiceServer()
+}
+
+// UnimplementedExpansionServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedExpansionServiceServer struct {
+}
+
+func (UnimplementedExpansionServiceServer) ExpandPermissionTreeWithLimits(context.Context, *ExpandPermissionTreeWithLimitsRequest) (*ExpandPermissionTreeWithLimitsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExpandPermissionTreeWithLimits not implemented")
+}
+func (UnimplementedExpansionServiceServer) mustEmbedUnimplementedExpansionServiceServer() {}
+
+// UnsafeExpansionServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ExpansionServiceServer will
+// result in compilation errors.
+type UnsafeExpansionServiceServer interface {
+	mustEmbedUnimplementedExpansionServiceServer()
+}
+
+func RegisterExpansionServiceServer(s grpc.ServiceRegistrar, srv ExpansionServiceServer) {
+	s.RegisterService(&ExpansionService_ServiceDesc, srv)
+}
+
+func _ExpansionService_ExpandPermissionTreeWithLimits_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExpandPermissionTreeWithLimitsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExpansionServiceServer).ExpandPermissionTreeWithLimits(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ExpansionService_ExpandPermissionTreeWithLimits_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExpansionServiceServer).ExpandPermissionTreeWithLimits(ctx, req.(*ExpandPermissionTreeWithLimitsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ExpansionService_ServiceDesc is the grpc.ServiceDesc for ExpansionService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ExpansionService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "expansion.v1.ExpansionService",
+	HandlerType: (*ExpansionServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ExpandPermissionTreeWithLimits",
+			Handler:    _ExpansionService_ExpandPermissionTreeWithLimits_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "expansion/v1/expansion.proto",
+}
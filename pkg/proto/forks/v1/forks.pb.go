@@ -0,0 +1,612 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.2
+// 	protoc        (unknown)
+// source: forks/v1/forks.proto
+
+package forksv1
+
+import (
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Fork is a fork of the datastore.
+type Fork struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// fork_id is the ID of the fork, to set in the `io.spicedb.fork` header of its requests.
+	ForkId string `protobuf:"bytes,1,opt,name=fork_id,json=forkId,proto3" json:"fork_id,omitempty"`
+	// forked_at is the revision of the datastore at which the fork was created.
+	ForkedAt *v1.ZedToken `protobuf:"bytes,2,opt,name=forked_at,json=forkedAt,proto3" json:"forked_at,omitempty"`
+	// created_at is the time at which the fork was created.
+	CreatedAt *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	// expires_at is the time after which the fork is discarded.
+	ExpiresAt *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+}
+
+func (x *Fork) Reset() {
+	*x = Fork{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_forks_v1_forks_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Fork) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Fork) ProtoMessage() {}
+
+func (x *Fork) ProtoReflect() protoreflect.Message {
+	mi := &file_forks_v1_forks_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Fork.ProtoReflect.Descriptor instead.
+func (*Fork) Descriptor() ([]byte, []int) {
+	return file_forks_v1_forks_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Fork) GetForkId() string {
+	if x != nil {
+		return x.ForkId
+	}
+	return ""
+}
+
+func (x *Fork) GetForkedAt() *v1.ZedToken {
+	if x != nil {
+		return x.ForkedAt
+	}
+	return nil
+}
+
+func (x *Fork) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Fork) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+type CreateForkRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// consistency selects the revision of the datastore to fork.
+	Consistency *v1.Consistency `protobuf:"bytes,1,opt,name=consistency,proto3" json:"consistency,omitempty"`
+	// optional_ttl is the duration after which the fork is discarded. It defaults to, and is
+	// capped at, the maximum configured on the server.
+	OptionalTtl *durationpb.Duration `protobuf:"bytes,2,opt,name=optional_ttl,json=optionalTtl,proto3" json:"optional_ttl,omitempty"`
+}
+
+func (x *CreateForkRequest) Reset() {
+	*x = CreateForkRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_forks_v1_forks_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CreateForkRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateForkRequest) ProtoMessage() {}
+
+func (x *CreateForkRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_forks_v1_forks_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateForkRequest.ProtoReflect.Descriptor instead.
+func (*CreateForkRequest) Descriptor() ([]byte, []int) {
+	return file_forks_v1_forks_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CreateForkRequest) GetConsistency() *v1.Consistency {
+	if x != nil {
+		return x.Consistency
+	}
+	return nil
+}
+
+func (x *CreateForkRequest) GetOptionalTtl() *durationpb.Duration {
+	if x != nil {
+		return x.OptionalTtl
+	}
+	return nil
+}
+
+type CreateForkResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Fork *Fork `protobuf:"bytes,1,opt,name=fork,proto3" json:"fork,omitempty"`
+}
+
+func (x *CreateForkResponse) Reset() {
+	*x = CreateForkResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_forks_v1_forks_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CreateForkResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateForkResponse) ProtoMessage() {}
+
+func (x *CreateForkResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_forks_v1_forks_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateForkResponse.ProtoReflect.Descriptor instead.
+func (*CreateForkResponse) Descriptor() ([]byte, []int) {
+	return file_forks_v1_forks_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *CreateForkResponse) GetFork() *Fork {
+	if x != nil {
+		return x.Fork
+	}
+	return nil
+}
+
+type GetForkRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ForkId string `protobuf:"bytes,1,opt,name=fork_id,json=forkId,proto3" json:"fork_id,omitempty"`
+}
+
+func (x *GetForkRequest) Reset() {
+	*x = GetForkRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_forks_v1_forks_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetForkRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetForkRequest) ProtoMessage() {}
+
+func (x *GetForkRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_forks_v1_forks_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetForkRequest.ProtoReflect.Descriptor instead.
+func (*GetForkRequest) Descriptor() ([]byte, []int) {
+	return file_forks_v1_forks_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetForkRequest) GetForkId() string {
+	if x != nil {
+		return x.ForkId
+	}
+	return ""
+}
+
+type GetForkResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Fork *Fork `protobuf:"bytes,1,opt,name=fork,proto3" json:"fork,omitempty"`
+}
+
+func (x *GetForkResponse) Reset() {
+	*x = GetForkResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_forks_v1_forks_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetForkResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetForkResponse) ProtoMessage() {}
+
+func (x *GetForkResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_forks_v1_forks_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetForkResponse.ProtoReflect.Descriptor instead.
+func (*GetForkResponse) Descriptor() ([]byte, []int) {
+	return file_forks_v1_forks_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetForkResponse) GetFork() *Fork {
+	if x != nil {
+		return x.Fork
+	}
+	return nil
+}
+
+type DeleteForkRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ForkId string `protobuf:"bytes,1,opt,name=fork_id,json=forkId,proto3" json:"fork_id,omitempty"`
+}
+
+func (x *DeleteForkRequest) Reset() {
+	*x = DeleteForkRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_forks_v1_forks_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DeleteForkRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteForkRequest) ProtoMessage() {}
+
+func (x *DeleteForkRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_forks_v1_forks_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteForkRequest.ProtoReflect.Descriptor instead.
+func (*DeleteForkRequest) Descriptor() ([]byte, []int) {
+	return file_forks_v1_forks_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *DeleteForkRequest) GetForkId() string {
+	if x != nil {
+		return x.ForkId
+	}
+	return ""
+}
+
+type DeleteForkResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *DeleteForkResponse) Reset() {
+	*x = DeleteForkResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_forks_v1_forks_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DeleteForkResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteForkResponse) ProtoMessage() {}
+
+func (x *DeleteForkResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_forks_v1_forks_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteForkResponse.ProtoReflect.Descriptor instead.
+func (*DeleteForkResponse) Descriptor() ([]byte, []int) {
+	return file_forks_v1_forks_proto_rawDescGZIP(), []int{6}
+}
+
+var File_forks_v1_forks_proto protoreflect.FileDescriptor
+
+var file_forks_v1_forks_proto_rawDesc = []byte{
+	0x0a, 0x14, 0x66, 0x6f, 0x72, 0x6b, 0x73, 0x2f, 0x76, 0x31, 0x2f, 0x66, 0x6f, 0x72, 0x6b, 0x73,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x08, 0x66, 0x6f, 0x72, 0x6b, 0x73, 0x2e, 0x76, 0x31,
+	0x1a, 0x19, 0x61, 0x75, 0x74, 0x68, 0x7a, 0x65, 0x64, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x76, 0x31,
+	0x2f, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x27, 0x61, 0x75, 0x74,
+	0x68, 0x7a, 0x65, 0x64, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x76, 0x31, 0x2f, 0x70, 0x65, 0x72, 0x6d,
+	0x69, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x5f, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xcc, 0x01, 0x0a, 0x04, 0x46, 0x6f, 0x72, 0x6b, 0x12, 0x17,
+	0x0a, 0x07, 0x66, 0x6f, 0x72, 0x6b, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x06, 0x66, 0x6f, 0x72, 0x6b, 0x49, 0x64, 0x12, 0x35, 0x0a, 0x09, 0x66, 0x6f, 0x72, 0x6b, 0x65,
+	0x64, 0x5f, 0x61, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x61, 0x75, 0x74,
+	0x68, 0x7a, 0x65, 0x64, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x76, 0x31, 0x2e, 0x5a, 0x65, 0x64, 0x54,
+	0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x08, 0x66, 0x6f, 0x72, 0x6b, 0x65, 0x64, 0x41, 0x74, 0x12, 0x39,
+	0x0a, 0x0a, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09,
+	0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x12, 0x39, 0x0a, 0x0a, 0x65, 0x78, 0x70,
+	0x69, 0x72, 0x65, 0x73, 0x5f, 0x61, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
+	0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x65, 0x78, 0x70, 0x69, 0x72,
+	0x65, 0x73, 0x41, 0x74, 0x22, 0x90, 0x01, 0x0a, 0x11, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x46,
+	0x6f, 0x72, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x3d, 0x0a, 0x0b, 0x63, 0x6f,
+	0x6e, 0x73, 0x69, 0x73, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1b, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x7a, 0x65, 0x64, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x76, 0x31,
+	0x2e, 0x43, 0x6f, 0x6e, 0x73, 0x69, 0x73, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x52, 0x0b, 0x63, 0x6f,
+	0x6e, 0x73, 0x69, 0x73, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x12, 0x3c, 0x0a, 0x0c, 0x6f, 0x70, 0x74,
+	0x69, 0x6f, 0x6e, 0x61, 0x6c, 0x5f, 0x74, 0x74, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0b, 0x6f, 0x70, 0x74, 0x69,
+	0x6f, 0x6e, 0x61, 0x6c, 0x54, 0x74, 0x6c, 0x22, 0x38, 0x0a, 0x12, 0x43, 0x72, 0x65, 0x61, 0x74,
+	0x65, 0x46, 0x6f, 0x72, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x22, 0x0a,
+	0x04, 0x66, 0x6f, 0x72, 0x6b, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x66, 0x6f,
+	0x72, 0x6b, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x6f, 0x72, 0x6b, 0x52, 0x04, 0x66, 0x6f, 0x72,
+	0x6b, 0x22, 0x29, 0x0a, 0x0e, 0x47, 0x65, 0x74, 0x46, 0x6f, 0x72, 0x6b, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x17, 0x0a, 0x07, 0x66, 0x6f, 0x72, 0x6b, 0x5f, 0x69, 0x64, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x66, 0x6f, 0x72, 0x6b, 0x49, 0x64, 0x22, 0x35, 0x0a, 0x0f,
+	0x47, 0x65, 0x74, 0x46, 0x6f, 0x72, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x22, 0x0a, 0x04, 0x66, 0x6f, 0x72, 0x6b, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e,
+	0x66, 0x6f, 0x72, 0x6b, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x6f, 0x72, 0x6b, 0x52, 0x04, 0x66,
+	0x6f, 0x72, 0x6b, 0x22, 0x2c, 0x0a, 0x11, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x46, 0x6f, 0x72,
+	0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x17, 0x0a, 0x07, 0x66, 0x6f, 0x72, 0x6b,
+	0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x66, 0x6f, 0x72, 0x6b, 0x49,
+	0x64, 0x22, 0x14, 0x0a, 0x12, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x46, 0x6f, 0x72, 0x6b, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x32, 0xe5, 0x01, 0x0a, 0x0b, 0x46, 0x6f, 0x72, 0x6b,
+	0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x49, 0x0a, 0x0a, 0x43, 0x72, 0x65, 0x61, 0x74,
+	0x65, 0x46, 0x6f, 0x72, 0x6b, 0x12, 0x1b, 0x2e, 0x66, 0x6f, 0x72, 0x6b, 0x73, 0x2e, 0x76, 0x31,
+	0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x46, 0x6f, 0x72, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x1c, 0x2e, 0x66, 0x6f, 0x72, 0x6b, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x72,
+	0x65, 0x61, 0x74, 0x65, 0x46, 0x6f, 0x72, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x22, 0x00, 0x12, 0x40, 0x0a, 0x07, 0x47, 0x65, 0x74, 0x46, 0x6f, 0x72, 0x6b, 0x12, 0x18, 0x2e,
+	0x66, 0x6f, 0x72, 0x6b, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x46, 0x6f, 0x72, 0x6b,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x19, 0x2e, 0x66, 0x6f, 0x72, 0x6b, 0x73, 0x2e,
+	0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x46, 0x6f, 0x72, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x22, 0x00, 0x12, 0x49, 0x0a, 0x0a, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x46, 0x6f,
+	0x72, 0x6b, 0x12, 0x1b, 0x2e, 0x66, 0x6f, 0x72, 0x6b, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65,
+	0x6c, 0x65, 0x74, 0x65, 0x46, 0x6f, 0x72, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x1c, 0x2e, 0x66, 0x6f, 0x72, 0x6b, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74,
+	0x65, 0x46, 0x6f, 0x72, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x42,
+	0x37, 0x5a, 0x35, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x61, 0x75,
+	0x74, 0x68, 0x7a, 0x65, 0x64, 0x2f, 0x73, 0x70, 0x69, 0x63, 0x65, 0x64, 0x62, 0x2f, 0x70, 0x6b,
+	0x67, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x66, 0x6f, 0x72, 0x6b, 0x73, 0x2f, 0x76, 0x31,
+	0x3b, 0x66, 0x6f, 0x72, 0x6b, 0x73, 0x76, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_forks_v1_forks_proto_rawDescOnce sync.Once
+	file_forks_v1_forks_proto_rawDescData = file_forks_v1_forks_proto_rawDesc
+)
+
+func file_forks_v1_forks_proto_rawDescGZIP() []byte {
+	file_forks_v1_forks_proto_rawDescOnce.Do(func() {
+		file_forks_v1_forks_proto_rawDescData = protoimpl.X.CompressGZIP(file_forks_v1_forks_proto_rawDescData)
+	})
+	return file_forks_v1_forks_proto_rawDescData
+}
+
+var file_forks_v1_forks_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_forks_v1_forks_proto_goTypes = []any{
+	(*Fork)(nil),                  // 0: forks.v1.Fork
+	(*CreateForkRequest)(nil),     // 1: forks.v1.CreateForkRequest
+	(*CreateForkResponse)(nil),    // 2: forks.v1.CreateForkResponse
+	(*GetForkRequest)(nil),        // 3: forks.v1.GetForkRequest
+	(*GetForkResponse)(nil),       // 4: forks.v1.GetForkResponse
+	(*DeleteForkRequest)(nil),     // 5: forks.v1.DeleteForkRequest
+	(*DeleteForkResponse)(nil),    // 6: forks.v1.DeleteForkResponse
+	(*v1.ZedToken)(nil),           // 7: authzed.api.v1.ZedToken
+	(*timestamppb.Timestamp)(nil), // 8: google.protobuf.Timestamp
+	(*v1.Consistency)(nil),        // 9: authzed.api.v1.Consistency
+	(*durationpb.Duration)(nil),   // 10: google.protobuf.Duration
+}
+var file_forks_v1_forks_proto_depIdxs = []int32{
+	7,  // 0: forks.v1.Fork.forked_at:type_name -> authzed.api.v1.ZedToken
+	8,  // 1: forks.v1.Fork.created_at:type_name -> google.protobuf.Timestamp
+	8,  // 2: forks.v1.Fork.expires_at:type_name -> google.protobuf.Timestamp
+	9,  // 3: forks.v1.CreateForkRequest.consistency:type_name -> authzed.api.v1.Consistency
+	10, // 4: forks.v1.CreateForkRequest.optional_ttl:type_name -> google.protobuf.Duration
+	0,  // 5: forks.v1.CreateForkResponse.fork:type_name -> forks.v1.Fork
+	0,  // 6: forks.v1.GetForkResponse.fork:type_name -> forks.v1.Fork
+	1,  // 7: forks.v1.ForkService.CreateFork:input_type -> forks.v1.CreateForkRequest
+	3,  // 8: forks.v1.ForkService.GetFork:input_type -> forks.v1.GetForkRequest
+	5,  // 9: forks.v1.ForkService.DeleteFork:input_type -> forks.v1.DeleteForkRequest
+	2,  // 10: forks.v1.ForkService.CreateFork:output_type -> forks.v1.CreateForkResponse
+	4,  // 11: forks.v1.ForkService.GetFork:output_type -> forks.v1.GetForkResponse
+	6,  // 12: forks.v1.ForkService.DeleteFork:output_type -> forks.v1.DeleteForkResponse
+	10, // [10:13] is the sub-list for method output_type
+	7,  // [7:10] is the sub-list for method input_type
+	7,  // [7:7] is the sub-list for extension type_name
+	7,  // [7:7] is the sub-list for extension extendee
+	0,  // [0:7] is the sub-list for field type_name
+}
+
+func init() { file_forks_v1_forks_proto_init() }
+func file_forks_v1_forks_proto_init() {
+	if File_forks_v1_forks_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_forks_v1_forks_proto_msgTypes[0].Exporter = func(v any, i int) any {
+			switch v := v.(*Fork); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_forks_v1_forks_proto_msgTypes[1].Exporter = func(v any, i int) any {
+			switch v := v.(*CreateForkRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_forks_v1_forks_proto_msgTypes[2].Exporter = func(v any, i int) any {
+			switch v := v.(*CreateForkResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_forks_v1_forks_proto_msgTypes[3].Exporter = func(v any, i int) any {
+			switch v := v.(*GetForkRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_forks_v1_forks_proto_msgTypes[4].Exporter = func(v any, i int) any {
+			switch v := v.(*GetForkResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_forks_v1_forks_proto_msgTypes[5].Exporter = func(v any, i int) any {
+			switch v := v.(*DeleteForkRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_forks_v1_forks_proto_msgTypes[6].Exporter = func(v any, i int) any {
+			switch v := v.(*DeleteForkResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_forks_v1_forks_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   7,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_forks_v1_forks_proto_goTypes,
+		DependencyIndexes: file_forks_v1_forks_proto_depIdxs,
+		MessageInfos:      file_forks_v1_forks_proto_msgTypes,
+	}.Build()
+	File_forks_v1_forks_proto = out.File
+	file_forks_v1_forks_proto_rawDesc = nil
+	file_forks_v1_forks_proto_goTypes = nil
+	file_forks_v1_forks_proto_depIdxs = nil
+}
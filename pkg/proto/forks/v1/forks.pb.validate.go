@@ -0,0 +1,951 @@
+// Code generated by protoc-gen-validate. DO NOT EDIT.
+// source: forks/v1/forks.proto
+
+package forksv1
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// ensure the imports are used
+var (
+	_ = bytes.MinRead
+	_ = errors.New("")
+	_ = fmt.Print
+	_ = utf8.UTFMax
+	_ = (*regexp.Regexp)(nil)
+	_ = (*strings.Reader)(nil)
+	_ = net.IPv4len
+	_ = time.Duration(0)
+	_ = (*url.URL)(nil)
+	_ = (*mail.Address)(nil)
+	_ = anypb.Any{}
+	_ = sort.Sort
+)
+
+// Validate checks the field values on Fork with the rules defined in the proto
+// definition for this message. If any rules are violated, the first error
+// encountered is returned, or nil if there are no violations.
+func (m *Fork) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on Fork with the rules defined in the
+// proto definition for this message. If any rules are violated, the result is
+// a list of violation errors wrapped in ForkMultiError, or nil if none found.
+func (m *Fork) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *Fork) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for ForkId
+
+	if all {
+		switch v := interface{}(m.GetForkedAt()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, ForkValidationError{
+					field:  "ForkedAt",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, ForkValidationError{
+					field:  "ForkedAt",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetForkedAt()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return ForkValidationError{
+				field:  "ForkedAt",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if all {
+		switch v := interface{}(m.GetCreatedAt()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, ForkValidationError{
+					field:  "CreatedAt",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, ForkValidationError{
+					field:  "CreatedAt",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetCreatedAt()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return ForkValidationError{
+				field:  "CreatedAt",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if all {
+		switch v := interface{}(m.GetExpiresAt()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, ForkValidationError{
+					field:  "ExpiresAt",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, ForkValidationError{
+					field:  "ExpiresAt",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetExpiresAt()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return ForkValidationError{
+				field:  "ExpiresAt",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if len(errors) > 0 {
+		return ForkMultiError(errors)
+	}
+
+	return nil
+}
+
+// ForkMultiError is an error wrapping multiple validation errors returned by
+// Fork.ValidateAll() if the designated constraints aren't met.
+type ForkMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m ForkMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m ForkMultiError) AllErrors() []error { return m }
+
+// ForkValidationError is the validation error returned by Fork.Validate if the
+// designated constraints aren't met.
+type ForkValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e ForkValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e ForkValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e ForkValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e ForkValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e ForkValidationError) ErrorName() string { return "ForkValidationError" }
+
+// Error satisfies the builtin error interface
+func (e ForkValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sFork.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = ForkValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = ForkValidationError{}
+
+// Validate checks the field values on CreateForkRequest with the rules defined
+// in the proto definition for this message. If any rules are violated, the
+// first error encountered is returned, or nil if there are no violations.
+func (m *CreateForkRequest) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on CreateForkRequest with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// CreateForkRequestMultiError, or nil if none found.
+func (m *CreateForkRequest) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *CreateForkRequest) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if all {
+		switch v := interface{}(m.GetConsistency()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, CreateForkRequestValidationError{
+					field:  "Consistency",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, CreateForkRequestValidationError{
+					field:  "Consistency",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetConsistency()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return CreateForkRequestValidationError{
+				field:  "Consistency",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if all {
+		switch v := interface{}(m.GetOptionalTtl()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, CreateForkRequestValidationError{
+					field:  "OptionalTtl",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, CreateForkRequestValidationError{
+					field:  "OptionalTtl",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetOptionalTtl()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return CreateForkRequestValidationError{
+				field:  "OptionalTtl",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if len(errors) > 0 {
+		return CreateForkRequestMultiError(errors)
+	}
+
+	return nil
+}
+
+// CreateForkRequestMultiError is an error wrapping multiple validation errors
+// returned by CreateForkRequest.ValidateAll() if the designated constraints
+// aren't met.
+type CreateForkRequestMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m CreateForkRequestMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m CreateForkRequestMultiError) AllErrors() []error { return m }
+
+// CreateForkRequestValidationError is the validation error returned by
+// CreateForkRequest.Validate if the designated constraints aren't met.
+type CreateForkRequestValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e CreateForkRequestValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e CreateForkRequestValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e CreateForkRequestValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e CreateForkRequestValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e CreateForkRequestValidationError) ErrorName() string {
+	return "CreateForkRequestValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e CreateForkRequestValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sCreateForkRequest.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = CreateForkRequestValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = CreateForkRequestValidationError{}
+
+// Validate checks the field values on CreateForkResponse with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the first error encountered is returned, or nil if there are no violations.
+func (m *CreateForkResponse) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on CreateForkResponse with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// CreateForkResponseMultiError, or nil if none found.
+func (m *CreateForkResponse) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *CreateForkResponse) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if all {
+		switch v := interface{}(m.GetFork()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, CreateForkResponseValidationError{
+					field:  "Fork",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, CreateForkResponseValidationError{
+					field:  "Fork",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetFork()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return CreateForkResponseValidationError{
+				field:  "Fork",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if len(errors) > 0 {
+		return CreateForkResponseMultiError(errors)
+	}
+
+	return nil
+}
+
+// CreateForkResponseMultiError is an error wrapping multiple validation errors
+// returned by CreateForkResponse.ValidateAll() if the designated constraints
+// aren't met.
+type CreateForkResponseMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m CreateForkResponseMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m CreateForkResponseMultiError) AllErrors() []error { return m }
+
+// CreateForkResponseValidationError is the validation error returned by
+// CreateForkResponse.Validate if the designated constraints aren't met.
+type CreateForkResponseValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e CreateForkResponseValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e CreateForkResponseValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e CreateForkResponseValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e CreateForkResponseValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e CreateForkResponseValidationError) ErrorName() string {
+	return "CreateForkResponseValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e CreateForkResponseValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sCreateForkResponse.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = CreateForkResponseValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = CreateForkResponseValidationError{}
+
+// Validate checks the field values on GetForkRequest with the rules defined in
+// the proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *GetForkRequest) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on GetForkRequest with the rules defined
+// in the proto definition for this message. If any rules are violated, the
+// result is a list of violation errors wrapped in GetForkRequestMultiError,
+// or nil if none found.
+func (m *GetForkRequest) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *GetForkRequest) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for ForkId
+
+	if len(errors) > 0 {
+		return GetForkRequestMultiError(errors)
+	}
+
+	return nil
+}
+
+// GetForkRequestMultiError is an error wrapping multiple validation errors
+// returned by GetForkRequest.ValidateAll() if the designated constraints
+// aren't met.
+type GetForkRequestMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m GetForkRequestMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m GetForkRequestMultiError) AllErrors() []error { return m }
+
+// GetForkRequestValidationError is the validation error returned by
+// GetForkRequest.Validate if the designated constraints aren't met.
+type GetForkRequestValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e GetForkRequestValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e GetForkRequestValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e GetForkRequestValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e GetForkRequestValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e GetForkRequestValidationError) ErrorName() string { return "GetForkRequestValidationError" }
+
+// Error satisfies the builtin error interface
+func (e GetForkRequestValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sGetForkRequest.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = GetForkRequestValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = GetForkRequestValidationError{}
+
+// Validate checks the field values on GetForkResponse with the rules defined
+// in the proto definition for this message. If any rules are violated, the
+// first error encountered is returned, or nil if there are no violations.
+func (m *GetForkResponse) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on GetForkResponse with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// GetForkResponseMultiError, or nil if none found.
+func (m *GetForkResponse) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *GetForkResponse) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if all {
+		switch v := interface{}(m.GetFork()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, GetForkResponseValidationError{
+					field:  "Fork",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, GetForkResponseValidationError{
+					field:  "Fork",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetFork()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return GetForkResponseValidationError{
+				field:  "Fork",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if len(errors) > 0 {
+		return GetForkResponseMultiError(errors)
+	}
+
+	return nil
+}
+
+// GetForkResponseMultiError is an error wrapping multiple validation errors
+// returned by GetForkResponse.ValidateAll() if the designated constraints
+// aren't met.
+type GetForkResponseMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m GetForkResponseMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m GetForkResponseMultiError) AllErrors() []error { return m }
+
+// GetForkResponseValidationError is the validation error returned by
+// GetForkResponse.Validate if the designated constraints aren't met.
+type GetForkResponseValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e GetForkResponseValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e GetForkResponseValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e GetForkResponseValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e GetForkResponseValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e GetForkResponseValidationError) ErrorName() string { return "GetForkResponseValidationError" }
+
+// Error satisfies the builtin error interface
+func (e GetForkResponseValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sGetForkResponse.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = GetForkResponseValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = GetForkResponseValidationError{}
+
+// Validate checks the field values on DeleteForkRequest with the rules defined
+// in the proto definition for this message. If any rules are violated, the
+// first error encountered is returned, or nil if there are no violations.
+func (m *DeleteForkRequest) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on DeleteForkRequest with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// DeleteForkRequestMultiError, or nil if none found.
+func (m *DeleteForkRequest) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *DeleteForkRequest) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for ForkId
+
+	if len(errors) > 0 {
+		return DeleteForkRequestMultiError(errors)
+	}
+
+	return nil
+}
+
+// DeleteForkRequestMultiError is an error wrapping multiple validation errors
+// returned by DeleteForkRequest.ValidateAll() if the designated constraints
+// aren't met.
+type DeleteForkRequestMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m DeleteForkRequestMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m DeleteForkRequestMultiError) AllErrors() []error { return m }
+
+// DeleteForkRequestValidationError is the validation error returned by
+// DeleteForkRequest.Validate if the designated constraints aren't met.
+type DeleteForkRequestValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e DeleteForkRequestValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e DeleteForkRequestValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e DeleteForkRequestValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e DeleteForkRequestValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e DeleteForkRequestValidationError) ErrorName() string {
+	return "DeleteForkRequestValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e DeleteForkRequestValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sDeleteForkRequest.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = DeleteForkRequestValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = DeleteForkRequestValidationError{}
+
+// Validate checks the field values on DeleteForkResponse with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the first error encountered is returned, or nil if there are no violations.
+func (m *DeleteForkResponse) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on DeleteForkResponse with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// DeleteForkResponseMultiError, or nil if none found.
+func (m *DeleteForkResponse) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *DeleteForkResponse) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if len(errors) > 0 {
+		return DeleteForkResponseMultiError(errors)
+	}
+
+	return nil
+}
+
+// DeleteForkResponseMultiError is an error wrapping multiple validation errors
+// returned by DeleteForkResponse.ValidateAll() if the designated constraints
+// aren't met.
+type DeleteForkResponseMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m DeleteForkResponseMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m DeleteForkResponseMultiError) AllErrors() []error { return m }
+
+// DeleteForkResponseValidationError is the validation error returned by
+// DeleteForkResponse.Validate if the designated constraints aren't met.
+type DeleteForkResponseValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e DeleteForkResponseValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e DeleteForkResponseValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e DeleteForkResponseValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e DeleteForkResponseValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e DeleteForkResponseValidationError) ErrorName() string {
+	return "DeleteForkResponseValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e DeleteForkResponseValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sDeleteForkResponse.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = DeleteForkResponseValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = DeleteForkResponseValidationError{}
@@ -0,0 +1,189 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: forks/v1/forks.proto
+
+package forksv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	ForkService_CreateFork_FullMethodName = "/forks.v1.ForkService/CreateFork"
+	ForkService_GetFork_FullMethodName    = "/forks.v1.ForkService/GetFork"
+	ForkService_DeleteFork_FullMethodName = "/forks.v1.ForkService/DeleteFork"
+)
+
+// ForkServiceClient is the client API for ForkService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ForkServiceClient interface {
+	// CreateFork creates a fork of the datastore at the revision selected by the consistency.
+	CreateFork(ctx context.Context, in *CreateForkRequest, opts ...grpc.CallOption) (*CreateForkResponse, error)
+	// GetFork returns a fork.
+	GetFork(ctx context.Context, in *GetForkRequest, opts ...grpc.CallOption) (*GetForkResponse, error)
+	// DeleteFork discards a fork and everything written to it.
+	DeleteFork(ctx context.Context, in *DeleteForkRequest, opts ...grpc.CallOption) (*DeleteForkResponse, error)
+}
+
+type forkServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewForkServiceClient(cc grpc.ClientConnInterface) ForkServiceClient {
+	return &forkServiceClient{cc}
+}
+
+func (c *forkServiceClient) CreateFork(ctx context.Context, in *CreateForkRequest, opts ...grpc.CallOption) (*CreateForkResponse, error) {
+	out := new(CreateForkResponse)
+	err := c.cc.Invoke(ctx, ForkService_CreateFork_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *forkServiceClient) GetFork(ctx context.Context, in *GetForkRequest, opts ...grpc.CallOption) (*GetForkResponse, error) {
+	out := new(GetForkResponse)
+	err := c.cc.Invoke(ctx, ForkService_GetFork_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *forkServiceClient) DeleteFork(ctx context.Context, in *DeleteForkRequest, opts ...grpc.CallOption) (*DeleteForkResponse, error) {
+	out := new(DeleteForkResponse)
+	err := c.cc.Invoke(ctx, ForkService_DeleteFork_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ForkServiceServer is the server API for ForkService service.
+// All implementations must embed UnimplementedForkServiceServer
+// for forward compatibility
+type ForkServiceServer interface {
+	// CreateFork creates a fork of the datastore at the revision selected by the consistency.
+	CreateFork(context.Context, *CreateForkRequest) (*CreateForkResponse, error)
+	// GetFork returns a fork.
+	GetFork(context.Context, *GetForkRequest) (*GetForkResponse, error)
+	// DeleteFork discards a fork and everything written to it.
+	DeleteFork(context.Context, *DeleteForkRequest) (*DeleteForkResponse, error)
+	mustEmbedUnimplementedForkServiceServer()
+}
+
+// UnimplementedForkServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedForkServiceServer struct {
+}
+
+func (UnimplementedForkServiceServer) CreateFork(context.Context, *CreateForkRequest) (*CreateForkResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateFork not implemented")
+}
+func (UnimplementedForkServiceServer) GetFork(context.Context, *GetForkRequest) (*GetForkResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetFork not implemented")
+}
+func (UnimplementedForkServiceServer) DeleteFork(context.Context, *DeleteForkRequest) (*DeleteForkResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteFork not implemented")
+}
+func (UnimplementedForkServiceServer) mustEmbedUnimplementedForkServiceServer() {}
+
+// UnsafeForkServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ForkServiceServer will
+// result in compilation errors.
+type UnsafeForkServiceServer interface {
+	mustEmbedUnimplementedForkServiceServer()
+}
+
+func RegisterForkServiceServer(s grpc.ServiceRegistrar, srv ForkServiceServer) {
+	s.RegisterService(&ForkService_ServiceDesc, srv)
+}
+
+func _ForkService_CreateFork_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateForkRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ForkServiceServer).CreateFork(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ForkService_CreateFork_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ForkServiceServer).CreateFork(ctx, req.(*CreateForkRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ForkService_GetFork_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetForkRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ForkServiceServer).GetFork(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ForkService_GetFork_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ForkServiceServer).GetFork(ctx, req.(*GetForkRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ForkService_DeleteFork_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteForkRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ForkServiceServer).DeleteFork(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ForkService_DeleteFork_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ForkServiceServer).DeleteFork(ctx, req.(*DeleteForkRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ForkService_ServiceDesc is the grpc.ServiceDesc for ForkService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ForkService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "forks.v1.ForkService",
+	HandlerType: (*ForkServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateFork",
+			Handler:    _ForkService_CreateFork_Handler,
+		},
+		{
+			MethodName: "GetFork",
+			Handler:    _ForkService_GetFork_Handler,
+		},
+		{
+			MethodName: "DeleteFork",
+			Handler:    _ForkService_DeleteFork_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "forks/v1/forks.proto",
+}
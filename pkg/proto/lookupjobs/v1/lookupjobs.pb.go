@@ -0,0 +1,1089 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.2
+// 	protoc        (unknown)
+// source: lookupjobs/v1/lookupjobs.proto
+
+package lookupjobsv1
+
+import (
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	status "google.golang.org/genproto/googleapis/rpc/status"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type LookupJob_State int32
+
+const (
+	LookupJob_STATE_UNSPECIFIED LookupJob_State = 0
+	// STATE_RUNNING is the state of a job whose query is running.
+	LookupJob_STATE_RUNNING LookupJob_State = 1
+	// STATE_COMPLETED is the state of a job whose results are all materialized.
+	LookupJob_STATE_COMPLETED LookupJob_State = 2
+	// STATE_FAILED is the state of a job whose query failed, with the error of the job.
+	LookupJob_STATE_FAILED LookupJob_State = 3
+)
+
+// Enum value maps for LookupJob_State.
+var (
+	LookupJob_State_name = map[int32]string{
+		0: "STATE_UNSPECIFIED",
+		1: "STATE_RUNNING",
+		2: "STATE_COMPLETED",
+		3: "STATE_FAILED",
+	}
+	LookupJob_State_value = map[string]int32{
+		"STATE_UNSPECIFIED": 0,
+		"STATE_RUNNING":     1,
+		"STATE_COMPLETED":   2,
+		"STATE_FAILED":      3,
+	}
+)
+
+func (x LookupJob_State) Enum() *LookupJob_State {
+	p := new(LookupJob_State)
+	*p = x
+	return p
+}
+
+func (x LookupJob_State) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (LookupJob_State) Descriptor() protoreflect.EnumDescriptor {
+	return file_lookupjobs_v1_lookupjobs_proto_enumTypes[0].Descriptor()
+}
+
+func (LookupJob_State) Type() protoreflect.EnumType {
+	return &file_lookupjobs_v1_lookupjobs_proto_enumTypes[0]
+}
+
+func (x LookupJob_State) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use LookupJob_State.Descriptor instead.
+func (LookupJob_State) EnumDescriptor() ([]byte, []int) {
+	return file_lookupjobs_v1_lookupjobs_proto_rawDescGZIP(), []int{0, 0}
+}
+
+// LookupJob is the state of a job.
+type LookupJob struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// job_id is the ID of the job.
+	JobId string          `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	State LookupJob_State `protobuf:"varint,2,opt,name=state,proto3,enum=lookupjobs.v1.LookupJob_State" json:"state,omitempty"`
+	// result_count is the number of results materialized so far.
+	ResultCount uint64 `protobuf:"varint,3,opt,name=result_count,json=resultCount,proto3" json:"result_count,omitempty"`
+	// looked_up_at is the revision at which the query runs.
+	LookedUpAt *v1.ZedToken `protobuf:"bytes,4,opt,name=looked_up_at,json=lookedUpAt,proto3" json:"looked_up_at,omitempty"`
+	// submitted_at is the time at which the job was submitted.
+	SubmittedAt *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=submitted_at,json=submittedAt,proto3" json:"submitted_at,omitempty"`
+	// done_at is the time at which the job completed or failed.
+	DoneAt *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=done_at,json=doneAt,proto3" json:"done_at,omitempty"`
+	// expires_at is the time after which a done job and its results are deleted.
+	ExpiresAt *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	// error is the error of a failed job.
+	Error *status.Status `protobuf:"bytes,8,opt,name=error,proto3" json:"error,omitempty"`
+}
+
+func (x *LookupJob) Reset() {
+	*x = LookupJob{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_lookupjobs_v1_lookupjobs_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LookupJob) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LookupJob) ProtoMessage() {}
+
+func (x *LookupJob) ProtoReflect() protoreflect.Message {
+	mi := &file_lookupjobs_v1_lookupjobs_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LookupJob.ProtoReflect.Descriptor instead.
+func (*LookupJob) Descriptor() ([]byte, []int) {
+	return file_lookupjobs_v1_lookupjobs_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *LookupJob) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+func (x *LookupJob) GetState() LookupJob_State {
+	if x != nil {
+		return x.State
+	}
+	return LookupJob_STATE_UNSPECIFIED
+}
+
+func (x *LookupJob) GetResultCount() uint64 {
+	if x != nil {
+		return x.ResultCount
+	}
+	return 0
+}
+
+func (x *LookupJob) GetLookedUpAt() *v1.ZedToken {
+	if x != nil {
+		return x.LookedUpAt
+	}
+	return nil
+}
+
+func (x *LookupJob) GetSubmittedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.SubmittedAt
+	}
+	return nil
+}
+
+func (x *LookupJob) GetDoneAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DoneAt
+	}
+	return nil
+}
+
+func (x *LookupJob) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+func (x *LookupJob) GetError() *status.Status {
+	if x != nil {
+		return x.Error
+	}
+	return nil
+}
+
+// SubmitLookupJobRequest is the request to start a job running a query. The query runs at the
+// revision selected by its consistency, and its optional limit and cursor must be unset.
+type SubmitLookupJobRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Query:
+	//	*SubmitLookupJobRequest_LookupResources
+	//	*SubmitLookupJobRequest_LookupSubjects
+	Query isSubmitLookupJobRequest_Query `protobuf_oneof:"query"`
+}
+
+func (x *SubmitLookupJobRequest) Reset() {
+	*x = SubmitLookupJobRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_lookupjobs_v1_lookupjobs_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SubmitLookupJobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitLookupJobRequest) ProtoMessage() {}
+
+func (x *SubmitLookupJobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_lookupjobs_v1_lookupjobs_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitLookupJobRequest.ProtoReflect.Descriptor instead.
+func (*SubmitLookupJobRequest) Descriptor() ([]byte, []int) {
+	return file_lookupjobs_v1_lookupjobs_proto_rawDescGZIP(), []int{1}
+}
+
+func (m *SubmitLookupJobRequest) GetQuery() isSubmitLookupJobRequest_Query {
+	if m != nil {
+		return m.Query
+	}
+	return nil
+}
+
+func (x *SubmitLookupJobRequest) GetLookupResources() *v1.LookupResourcesRequest {
+	if x, ok := x.GetQuery().(*SubmitLookupJobRequest_LookupResources); ok {
+		return x.LookupResources
+	}
+	return nil
+}
+
+func (x *SubmitLookupJobRequest) GetLookupSubjects() *v1.LookupSubjectsRequest {
+	if x, ok := x.GetQuery().(*SubmitLookupJobRequest_LookupSubjects); ok {
+		return x.LookupSubjects
+	}
+	return nil
+}
+
+type isSubmitLookupJobRequest_Query interface {
+	isSubmitLookupJobRequest_Query()
+}
+
+type SubmitLookupJobRequest_LookupResources struct {
+	LookupResources *v1.LookupResourcesRequest `protobuf:"bytes,1,opt,name=lookup_resources,json=lookupResources,proto3,oneof"`
+}
+
+type SubmitLookupJobRequest_LookupSubjects struct {
+	LookupSubjects *v1.LookupSubjectsRequest `protobuf:"bytes,2,opt,name=lookup_subjects,json=lookupSubjects,proto3,oneof"`
+}
+
+func (*SubmitLookupJobRequest_LookupResources) isSubmitLookupJobRequest_Query() {}
+
+func (*SubmitLookupJobRequest_LookupSubjects) isSubmitLookupJobRequest_Query() {}
+
+type SubmitLookupJobResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Job *LookupJob `protobuf:"bytes,1,opt,name=job,proto3" json:"job,omitempty"`
+}
+
+func (x *SubmitLookupJobResponse) Reset() {
+	*x = SubmitLookupJobResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_lookupjobs_v1_lookupjobs_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SubmitLookupJobResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitLookupJobResponse) ProtoMessage() {}
+
+func (x *SubmitLookupJobResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_lookupjobs_v1_lookupjobs_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitLookupJobResponse.ProtoReflect.Descriptor instead.
+func (*SubmitLookupJobResponse) Descriptor() ([]byte, []int) {
+	return file_lookupjobs_v1_lookupjobs_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *SubmitLookupJobResponse) GetJob() *LookupJob {
+	if x != nil {
+		return x.Job
+	}
+	return nil
+}
+
+type GetLookupJobRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	JobId string `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+}
+
+func (x *GetLookupJobRequest) Reset() {
+	*x = GetLookupJobRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_lookupjobs_v1_lookupjobs_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetLookupJobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLookupJobRequest) ProtoMessage() {}
+
+func (x *GetLookupJobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_lookupjobs_v1_lookupjobs_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLookupJobRequest.ProtoReflect.Descriptor instead.
+func (*GetLookupJobRequest) Descriptor() ([]byte, []int) {
+	return file_lookupjobs_v1_lookupjobs_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetLookupJobRequest) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+type GetLookupJobResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Job *LookupJob `protobuf:"bytes,1,opt,name=job,proto3" json:"job,omitempty"`
+}
+
+func (x *GetLookupJobResponse) Reset() {
+	*x = GetLookupJobResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_lookupjobs_v1_lookupjobs_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetLookupJobResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLookupJobResponse) ProtoMessage() {}
+
+func (x *GetLookupJobResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_lookupjobs_v1_lookupjobs_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLookupJobResponse.ProtoReflect.Descriptor instead.
+func (*GetLookupJobResponse) Descriptor() ([]byte, []int) {
+	return file_lookupjobs_v1_lookupjobs_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetLookupJobResponse) GetJob() *LookupJob {
+	if x != nil {
+		return x.Job
+	}
+	return nil
+}
+
+type WatchLookupJobRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	JobId string `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+}
+
+func (x *WatchLookupJobRequest) Reset() {
+	*x = WatchLookupJobRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_lookupjobs_v1_lookupjobs_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WatchLookupJobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchLookupJobRequest) ProtoMessage() {}
+
+func (x *WatchLookupJobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_lookupjobs_v1_lookupjobs_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchLookupJobRequest.ProtoReflect.Descriptor instead.
+func (*WatchLookupJobRequest) Descriptor() ([]byte, []int) {
+	return file_lookupjobs_v1_lookupjobs_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *WatchLookupJobRequest) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+type WatchLookupJobResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Job *LookupJob `protobuf:"bytes,1,opt,name=job,proto3" json:"job,omitempty"`
+}
+
+func (x *WatchLookupJobResponse) Reset() {
+	*x = WatchLookupJobResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_lookupjobs_v1_lookupjobs_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WatchLookupJobResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchLookupJobResponse) ProtoMessage() {}
+
+func (x *WatchLookupJobResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_lookupjobs_v1_lookupjobs_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchLookupJobResponse.ProtoReflect.Descriptor instead.
+func (*WatchLookupJobResponse) Descriptor() ([]byte, []int) {
+	return file_lookupjobs_v1_lookupjobs_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *WatchLookupJobResponse) GetJob() *LookupJob {
+	if x != nil {
+		return x.Job
+	}
+	return nil
+}
+
+// DownloadLookupJobResultsRequest is the request to download the results of a completed job.
+type DownloadLookupJobResultsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	JobId string `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	// optional_offset is the number of results to skip, such as to resume an interrupted download.
+	OptionalOffset uint64 `protobuf:"varint,2,opt,name=optional_offset,json=optionalOffset,proto3" json:"optional_offset,omitempty"`
+}
+
+func (x *DownloadLookupJobResultsRequest) Reset() {
+	*x = DownloadLookupJobResultsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_lookupjobs_v1_lookupjobs_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DownloadLookupJobResultsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DownloadLookupJobResultsRequest) ProtoMessage() {}
+
+func (x *DownloadLookupJobResultsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_lookupjobs_v1_lookupjobs_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DownloadLookupJobResultsRequest.ProtoReflect.Descriptor instead.
+func (*DownloadLookupJobResultsRequest) Descriptor() ([]byte, []int) {
+	return file_lookupjobs_v1_lookupjobs_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *DownloadLookupJobResultsRequest) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+func (x *DownloadLookupJobResultsRequest) GetOptionalOffset() uint64 {
+	if x != nil {
+		return x.OptionalOffset
+	}
+	return 0
+}
+
+// DownloadLookupJobResultsResponse contains the next results of a job, of the type of its query.
+type DownloadLookupJobResultsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	LookupResourcesResults []*v1.LookupResourcesResponse `protobuf:"bytes,1,rep,name=lookup_resources_results,json=lookupResourcesResults,proto3" json:"lookup_resources_results,omitempty"`
+	LookupSubjectsResults  []*v1.LookupSubjectsResponse  `protobuf:"bytes,2,rep,name=lookup_subjects_results,json=lookupSubjectsResults,proto3" json:"lookup_subjects_results,omitempty"`
+	// next_offset is the offset from which to resume the download after these results.
+	NextOffset uint64 `protobuf:"varint,3,opt,name=next_offset,json=nextOffset,proto3" json:"next_offset,omitempty"`
+}
+
+func (x *DownloadLookupJobResultsResponse) Reset() {
+	*x = DownloadLookupJobResultsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_lookupjobs_v1_lookupjobs_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DownloadLookupJobResultsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DownloadLookupJobResultsResponse) ProtoMessage() {}
+
+func (x *DownloadLookupJobResultsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_lookupjobs_v1_lookupjobs_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DownloadLookupJobResultsResponse.ProtoReflect.Descriptor instead.
+func (*DownloadLookupJobResultsResponse) Descriptor() ([]byte, []int) {
+	return file_lookupjobs_v1_lookupjobs_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *DownloadLookupJobResultsResponse) GetLookupResourcesResults() []*v1.LookupResourcesResponse {
+	if x != nil {
+		return x.LookupResourcesResults
+	}
+	return nil
+}
+
+func (x *DownloadLookupJobResultsResponse) GetLookupSubjectsResults() []*v1.LookupSubjectsResponse {
+	if x != nil {
+		return x.LookupSubjectsResults
+	}
+	return nil
+}
+
+func (x *DownloadLookupJobResultsResponse) GetNextOffset() uint64 {
+	if x != nil {
+		return x.NextOffset
+	}
+	return 0
+}
+
+type DeleteLookupJobRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	JobId string `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+}
+
+func (x *DeleteLookupJobRequest) Reset() {
+	*x = DeleteLookupJobRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_lookupjobs_v1_lookupjobs_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DeleteLookupJobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteLookupJobRequest) ProtoMessage() {}
+
+func (x *DeleteLookupJobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_lookupjobs_v1_lookupjobs_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteLookupJobRequest.ProtoReflect.Descriptor instead.
+func (*DeleteLookupJobRequest) Descriptor() ([]byte, []int) {
+	return file_lookupjobs_v1_lookupjobs_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *DeleteLookupJobRequest) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+type DeleteLookupJobResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *DeleteLookupJobResponse) Reset() {
+	*x = DeleteLookupJobResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_lookupjobs_v1_lookupjobs_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DeleteLookupJobResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteLookupJobResponse) ProtoMessage() {}
+
+func (x *DeleteLookupJobResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_lookupjobs_v1_lookupjobs_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteLookupJobResponse.ProtoReflect.Descriptor instead.
+func (*DeleteLookupJobResponse) Descriptor() ([]byte, []int) {
+	return file_lookupjobs_v1_lookupjobs_proto_rawDescGZIP(), []int{10}
+}
+
+var File_lookupjobs_v1_lookupjobs_proto protoreflect.FileDescriptor
+
+var file_lookupjobs_v1_lookupjobs_proto_rawDesc = []byte{
+	0x0a, 0x1e, 0x6c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x6a, 0x6f, 0x62, 0x73, 0x2f, 0x76, 0x31, 0x2f,
+	0x6c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x6a, 0x6f, 0x62, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x12, 0x0d, 0x6c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x6a, 0x6f, 0x62, 0x73, 0x2e, 0x76, 0x31, 0x1a,
+	0x19, 0x61, 0x75, 0x74, 0x68, 0x7a, 0x65, 0x64, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x76, 0x31, 0x2f,
+	0x63, 0x6f, 0x72, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x27, 0x61, 0x75, 0x74, 0x68,
+	0x7a, 0x65, 0x64, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x76, 0x31, 0x2f, 0x70, 0x65, 0x72, 0x6d, 0x69,
+	0x73, 0x73, 0x69, 0x6f, 0x6e, 0x5f, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x17, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x72, 0x70, 0x63,
+	0x2f, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xea, 0x03,
+	0x0a, 0x09, 0x4c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x4a, 0x6f, 0x62, 0x12, 0x15, 0x0a, 0x06, 0x6a,
+	0x6f, 0x62, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6a, 0x6f, 0x62,
+	0x49, 0x64, 0x12, 0x34, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0e, 0x32, 0x1e, 0x2e, 0x6c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x6a, 0x6f, 0x62, 0x73, 0x2e, 0x76,
+	0x31, 0x2e, 0x4c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x4a, 0x6f, 0x62, 0x2e, 0x53, 0x74, 0x61, 0x74,
+	0x65, 0x52, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x12, 0x21, 0x0a, 0x0c, 0x72, 0x65, 0x73, 0x75,
+	0x6c, 0x74, 0x5f, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0b,
+	0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x3a, 0x0a, 0x0c, 0x6c,
+	0x6f, 0x6f, 0x6b, 0x65, 0x64, 0x5f, 0x75, 0x70, 0x5f, 0x61, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x18, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x7a, 0x65, 0x64, 0x2e, 0x61, 0x70, 0x69, 0x2e,
+	0x76, 0x31, 0x2e, 0x5a, 0x65, 0x64, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x0a, 0x6c, 0x6f, 0x6f,
+	0x6b, 0x65, 0x64, 0x55, 0x70, 0x41, 0x74, 0x12, 0x3d, 0x0a, 0x0c, 0x73, 0x75, 0x62, 0x6d, 0x69,
+	0x74, 0x74, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
+	0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x0b, 0x73, 0x75, 0x62, 0x6d, 0x69,
+	0x74, 0x74, 0x65, 0x64, 0x41, 0x74, 0x12, 0x33, 0x0a, 0x07, 0x64, 0x6f, 0x6e, 0x65, 0x5f, 0x61,
+	0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74,
+	0x61, 0x6d, 0x70, 0x52, 0x06, 0x64, 0x6f, 0x6e, 0x65, 0x41, 0x74, 0x12, 0x39, 0x0a, 0x0a, 0x65,
+	0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x5f, 0x61, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x65, 0x78, 0x70,
+	0x69, 0x72, 0x65, 0x73, 0x41, 0x74, 0x12, 0x28, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18,
+	0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x72,
+	0x70, 0x63, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72,
+	0x22, 0x58, 0x0a, 0x05, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x15, 0x0a, 0x11, 0x53, 0x54, 0x41,
+	0x54, 0x45, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00,
+	0x12, 0x11, 0x0a, 0x0d, 0x53, 0x54, 0x41, 0x54, 0x45, 0x5f, 0x52, 0x55, 0x4e, 0x4e, 0x49, 0x4e,
+	0x47, 0x10, 0x01, 0x12, 0x13, 0x0a, 0x0f, 0x53, 0x54, 0x41, 0x54, 0x45, 0x5f, 0x43, 0x4f, 0x4d,
+	0x50, 0x4c, 0x45, 0x54, 0x45, 0x44, 0x10, 0x02, 0x12, 0x10, 0x0a, 0x0c, 0x53, 0x54, 0x41, 0x54,
+	0x45, 0x5f, 0x46, 0x41, 0x49, 0x4c, 0x45, 0x44, 0x10, 0x03, 0x22, 0xc8, 0x01, 0x0a, 0x16, 0x53,
+	0x75, 0x62, 0x6d, 0x69, 0x74, 0x4c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x4a, 0x6f, 0x62, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x53, 0x0a, 0x10, 0x6c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x5f,
+	0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x26, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x7a, 0x65, 0x64, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x76, 0x31,
+	0x2e, 0x4c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x73,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x48, 0x00, 0x52, 0x0f, 0x6c, 0x6f, 0x6f, 0x6b, 0x75,
+	0x70, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x73, 0x12, 0x50, 0x0a, 0x0f, 0x6c, 0x6f,
+	0x6f, 0x6b, 0x75, 0x70, 0x5f, 0x73, 0x75, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x73, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x25, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x7a, 0x65, 0x64, 0x2e, 0x61, 0x70,
+	0x69, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x53, 0x75, 0x62, 0x6a, 0x65,
+	0x63, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x48, 0x00, 0x52, 0x0e, 0x6c, 0x6f,
+	0x6f, 0x6b, 0x75, 0x70, 0x53, 0x75, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x73, 0x42, 0x07, 0x0a, 0x05,
+	0x71, 0x75, 0x65, 0x72, 0x79, 0x22, 0x45, 0x0a, 0x17, 0x53, 0x75, 0x62, 0x6d, 0x69, 0x74, 0x4c,
+	0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x4a, 0x6f, 0x62, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x2a, 0x0a, 0x03, 0x6a, 0x6f, 0x62, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e,
+	0x6c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x6a, 0x6f, 0x62, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x6f,
+	0x6f, 0x6b, 0x75, 0x70, 0x4a, 0x6f, 0x62, 0x52, 0x03, 0x6a, 0x6f, 0x62, 0x22, 0x2c, 0x0a, 0x13,
+	0x47, 0x65, 0x74, 0x4c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x4a, 0x6f, 0x62, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x15, 0x0a, 0x06, 0x6a, 0x6f, 0x62, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x05, 0x6a, 0x6f, 0x62, 0x49, 0x64, 0x22, 0x42, 0x0a, 0x14, 0x47, 0x65,
+	0x74, 0x4c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x4a, 0x6f, 0x62, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x2a, 0x0a, 0x03, 0x6a, 0x6f, 0x62, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x18, 0x2e, 0x6c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x6a, 0x6f, 0x62, 0x73, 0x2e, 0x76, 0x31, 0x2e,
+	0x4c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x4a, 0x6f, 0x62, 0x52, 0x03, 0x6a, 0x6f, 0x62, 0x22, 0x2e,
+	0x0a, 0x15, 0x57, 0x61, 0x74, 0x63, 0x68, 0x4c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x4a, 0x6f, 0x62,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x15, 0x0a, 0x06, 0x6a, 0x6f, 0x62, 0x5f, 0x69,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6a, 0x6f, 0x62, 0x49, 0x64, 0x22, 0x44,
+	0x0a, 0x16, 0x57, 0x61, 0x74, 0x63, 0x68, 0x4c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x4a, 0x6f, 0x62,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2a, 0x0a, 0x03, 0x6a, 0x6f, 0x62, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x6c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x6a, 0x6f,
+	0x62, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x4a, 0x6f, 0x62, 0x52,
+	0x03, 0x6a, 0x6f, 0x62, 0x22, 0x61, 0x0a, 0x1f, 0x44, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64,
+	0x4c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x4a, 0x6f, 0x62, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x15, 0x0a, 0x06, 0x6a, 0x6f, 0x62, 0x5f, 0x69,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6a, 0x6f, 0x62, 0x49, 0x64, 0x12, 0x27,
+	0x0a, 0x0f, 0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x61, 0x6c, 0x5f, 0x6f, 0x66, 0x66, 0x73, 0x65,
+	0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0e, 0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x61,
+	0x6c, 0x4f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x22, 0x86, 0x02, 0x0a, 0x20, 0x44, 0x6f, 0x77, 0x6e,
+	0x6c, 0x6f, 0x61, 0x64, 0x4c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x4a, 0x6f, 0x62, 0x52, 0x65, 0x73,
+	0x75, 0x6c, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x61, 0x0a, 0x18,
+	0x6c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x5f, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x73,
+	0x5f, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x27,
+	0x2e, 0x61, 0x75, 0x74, 0x68, 0x7a, 0x65, 0x64, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x76, 0x31, 0x2e,
+	0x4c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x73, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x52, 0x16, 0x6c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x52,
+	0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x73, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x12,
+	0x5e, 0x0a, 0x17, 0x6c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x5f, 0x73, 0x75, 0x62, 0x6a, 0x65, 0x63,
+	0x74, 0x73, 0x5f, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x26, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x7a, 0x65, 0x64, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x76,
+	0x31, 0x2e, 0x4c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x53, 0x75, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x73,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x52, 0x15, 0x6c, 0x6f, 0x6f, 0x6b, 0x75, 0x70,
+	0x53, 0x75, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x73, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x12,
+	0x1f, 0x0a, 0x0b, 0x6e, 0x65, 0x78, 0x74, 0x5f, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x04, 0x52, 0x0a, 0x6e, 0x65, 0x78, 0x74, 0x4f, 0x66, 0x66, 0x73, 0x65, 0x74,
+	0x22, 0x2f, 0x0a, 0x16, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x4c, 0x6f, 0x6f, 0x6b, 0x75, 0x70,
+	0x4a, 0x6f, 0x62, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x15, 0x0a, 0x06, 0x6a, 0x6f,
+	0x62, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6a, 0x6f, 0x62, 0x49,
+	0x64, 0x22, 0x19, 0x0a, 0x17, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x4c, 0x6f, 0x6f, 0x6b, 0x75,
+	0x70, 0x4a, 0x6f, 0x62, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x32, 0x9a, 0x04, 0x0a,
+	0x11, 0x4c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x4a, 0x6f, 0x62, 0x73, 0x53, 0x65, 0x72, 0x76, 0x69,
+	0x63, 0x65, 0x12, 0x62, 0x0a, 0x0f, 0x53, 0x75, 0x62, 0x6d, 0x69, 0x74, 0x4c, 0x6f, 0x6f, 0x6b,
+	0x75, 0x70, 0x4a, 0x6f, 0x62, 0x12, 0x25, 0x2e, 0x6c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x6a, 0x6f,
+	0x62, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x75, 0x62, 0x6d, 0x69, 0x74, 0x4c, 0x6f, 0x6f, 0x6b,
+	0x75, 0x70, 0x4a, 0x6f, 0x62, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x26, 0x2e, 0x6c,
+	0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x6a, 0x6f, 0x62, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x75, 0x62,
+	0x6d, 0x69, 0x74, 0x4c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x4a, 0x6f, 0x62, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x59, 0x0a, 0x0c, 0x47, 0x65, 0x74, 0x4c, 0x6f, 0x6f,
+	0x6b, 0x75, 0x70, 0x4a, 0x6f, 0x62, 0x12, 0x22, 0x2e, 0x6c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x6a,
+	0x6f, 0x62, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x4c, 0x6f, 0x6f, 0x6b, 0x75, 0x70,
+	0x4a, 0x6f, 0x62, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x23, 0x2e, 0x6c, 0x6f, 0x6f,
+	0x6b, 0x75, 0x70, 0x6a, 0x6f, 0x62, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x4c, 0x6f,
+	0x6f, 0x6b, 0x75, 0x70, 0x4a, 0x6f, 0x62, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22,
+	0x00, 0x12, 0x61, 0x0a, 0x0e, 0x57, 0x61, 0x74, 0x63, 0x68, 0x4c, 0x6f, 0x6f, 0x6b, 0x75, 0x70,
+	0x4a, 0x6f, 0x62, 0x12, 0x24, 0x2e, 0x6c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x6a, 0x6f, 0x62, 0x73,
+	0x2e, 0x76, 0x31, 0x2e, 0x57, 0x61, 0x74, 0x63, 0x68, 0x4c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x4a,
+	0x6f, 0x62, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x25, 0x2e, 0x6c, 0x6f, 0x6f, 0x6b,
+	0x75, 0x70, 0x6a, 0x6f, 0x62, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x57, 0x61, 0x74, 0x63, 0x68, 0x4c,
+	0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x4a, 0x6f, 0x62, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x22, 0x00, 0x30, 0x01, 0x12, 0x7f, 0x0a, 0x18, 0x44, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64,
+	0x4c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x4a, 0x6f, 0x62, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73,
+	0x12, 0x2e, 0x2e, 0x6c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x6a, 0x6f, 0x62, 0x73, 0x2e, 0x76, 0x31,
+	0x2e, 0x44, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x4c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x4a,
+	0x6f, 0x62, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x2f, 0x2e, 0x6c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x6a, 0x6f, 0x62, 0x73, 0x2e, 0x76, 0x31,
+	0x2e, 0x44, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x4c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x4a,
+	0x6f, 0x62, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x22, 0x00, 0x30, 0x01, 0x12, 0x62, 0x0a, 0x0f, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x4c,
+	0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x4a, 0x6f, 0x62, 0x12, 0x25, 0x2e, 0x6c, 0x6f, 0x6f, 0x6b, 0x75,
+	0x70, 0x6a, 0x6f, 0x62, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x4c,
+	0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x4a, 0x6f, 0x62, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x26, 0x2e, 0x6c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x6a, 0x6f, 0x62, 0x73, 0x2e, 0x76, 0x31, 0x2e,
+	0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x4c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x4a, 0x6f, 0x62, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x42, 0x41, 0x5a, 0x3f, 0x67, 0x69, 0x74,
+	0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x61, 0x75, 0x74, 0x68, 0x7a, 0x65, 0x64, 0x2f,
+	0x73, 0x70, 0x69, 0x63, 0x65, 0x64, 0x62, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x2f, 0x6c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x6a, 0x6f, 0x62, 0x73, 0x2f, 0x76, 0x31, 0x3b,
+	0x6c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x6a, 0x6f, 0x62, 0x73, 0x76, 0x31, 0x62, 0x06, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_lookupjobs_v1_lookupjobs_proto_rawDescOnce sync.Once
+	file_lookupjobs_v1_lookupjobs_proto_rawDescData = file_lookupjobs_v1_lookupjobs_proto_rawDesc
+)
+
+func file_lookupjobs_v1_lookupjobs_proto_rawDescGZIP() []byte {
+	file_lookupjobs_v1_lookupjobs_proto_rawDescOnce.Do(func() {
+		file_lookupjobs_v1_lookupjobs_proto_rawDescData = protoimpl.X.CompressGZIP(file_lookupjobs_v1_lookupjobs_proto_rawDescData)
+	})
+	return file_lookupjobs_v1_lookupjobs_proto_rawDescData
+}
+
+var file_lookupjobs_v1_lookupjobs_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_lookupjobs_v1_lookupjobs_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_lookupjobs_v1_lookupjobs_proto_goTypes = []any{
+	(LookupJob_State)(0),                     // 0: lookupjobs.v1.LookupJob.State
+	(*LookupJob)(nil),                        // 1: lookupjobs.v1.LookupJob
+	(*SubmitLookupJobRequest)(nil),           // 2: lookupjobs.v1.SubmitLookupJobRequest
+	(*SubmitLookupJobResponse)(nil),          // 3: lookupjobs.v1.SubmitLookupJobResponse
+	(*GetLookupJobRequest)(nil),              // 4: lookupjobs.v1.GetLookupJobRequest
+	(*GetLookupJobResponse)(nil),             // 5: lookupjobs.v1.GetLookupJobResponse
+	(*WatchLookupJobRequest)(nil),            // 6: lookupjobs.v1.WatchLookupJobRequest
+	(*WatchLookupJobResponse)(nil),           // 7: lookupjobs.v1.WatchLookupJobResponse
+	(*DownloadLookupJobResultsRequest)(nil),  // 8: lookupjobs.v1.DownloadLookupJobResultsRequest
+	(*DownloadLookupJobResultsResponse)(nil), // 9: lookupjobs.v1.DownloadLookupJobResultsResponse
+	(*DeleteLookupJobRequest)(nil),           // 10: lookupjobs.v1.DeleteLookupJobRequest
+	(*DeleteLookupJobResponse)(nil),          // 11: lookupjobs.v1.DeleteLookupJobResponse
+	(*v1.ZedToken)(nil),                      // 12: authzed.api.v1.ZedToken
+	(*timestamppb.Timestamp)(nil),            // 13: google.protobuf.Timestamp
+	(*status.Status)(nil),                    // 14: google.rpc.Status
+	(*v1.LookupResourcesRequest)(nil),        // 15: authzed.api.v1.LookupResourcesRequest
+	(*v1.LookupSubjectsRequest)(nil),         // 16: authzed.api.v1.LookupSubjectsRequest
+	(*v1.LookupResourcesResponse)(nil),       // 17: authzed.api.v1.LookupResourcesResponse
+	(*v1.LookupSubjectsResponse)(nil),        // 18: authzed.api.v1.LookupSubjectsResponse
+}
+var file_lookupjobs_v1_lookupjobs_proto_depIdxs = []int32{
+	0,  // 0: lookupjobs.v1.LookupJob.state:type_name -> lookupjobs.v1.LookupJob.State
+	12, // 1: lookupjobs.v1.LookupJob.looked_up_at:type_name -> authzed.api.v1.ZedToken
+	13, // 2: lookupjobs.v1.LookupJob.submitted_at:type_name -> google.protobuf.Timestamp
+	13, // 3: lookupjobs.v1.LookupJob.done_at:type_name -> google.protobuf.Timestamp
+	13, // 4: lookupjobs.v1.LookupJob.expires_at:type_name -> google.protobuf.Timestamp
+	14, // 5: lookupjobs.v1.LookupJob.error:type_name -> google.rpc.Status
+	15, // 6: lookupjobs.v1.SubmitLookupJobRequest.lookup_resources:type_name -> authzed.api.v1.LookupResourcesRequest
+	16, // 7: lookupjobs.v1.SubmitLookupJobRequest.lookup_subjects:type_name -> authzed.api.v1.LookupSubjectsRequest
+	1,  // 8: lookupjobs.v1.SubmitLookupJobResponse.job:type_name -> lookupjobs.v1.LookupJob
+	1,  // 9: lookupjobs.v1.GetLookupJobResponse.job:type_name -> lookupjobs.v1.LookupJob
+	1,  // 10: lookupjobs.v1.WatchLookupJobResponse.job:type_name -> lookupjobs.v1.LookupJob
+	17, // 11: lookupjobs.v1.DownloadLookupJobResultsResponse.lookup_resources_results:type_name -> authzed.api.v1.LookupResourcesResponse
+	18, // 12: lookupjobs.v1.DownloadLookupJobResultsResponse.lookup_subjects_results:type_name -> authzed.api.v1.LookupSubjectsResponse
+	2,  // 13: lookupjobs.v1.LookupJobsService.SubmitLookupJob:input_type -> lookupjobs.v1.SubmitLookupJobRequest
+	4,  // 14: lookupjobs.v1.LookupJobsService.GetLookupJob:input_type -> lookupjobs.v1.GetLookupJobRequest
+	6,  // 15: lookupjobs.v1.LookupJobsService.WatchLookupJob:input_type -> lookupjobs.v1.WatchLookupJobRequest
+	8,  // 16: lookupjobs.v1.LookupJobsService.DownloadLookupJobResults:input_type -> lookupjobs.v1.DownloadLookupJobResultsRequest
+	10, // 17: lookupjobs.v1.LookupJobsService.DeleteLookupJob:input_type -> lookupjobs.v1.DeleteLookupJobRequest
+	3,  // 18: lookupjobs.v1.LookupJobsService.SubmitLookupJob:output_type -> lookupjobs.v1.SubmitLookupJobResponse
+	5,  // 19: lookupjobs.v1.LookupJobsService.GetLookupJob:output_type -> lookupjobs.v1.GetLookupJobResponse
+	7,  // 20: lookupjobs.v1.LookupJobsService.WatchLookupJob:output_type -> lookupjobs.v1.WatchLookupJobResponse
+	9,  // 21: lookupjobs.v1.LookupJobsService.DownloadLookupJobResults:output_type -> lookupjobs.v1.DownloadLookupJobResultsResponse
+	11, // 22: lookupjobs.v1.LookupJobsService.DeleteLookupJob:output_type -> lookupjobs.v1.DeleteLookupJobResponse
+	18, // [18:23] is the sub-list for method output_type
+	13, // [13:18] is the sub-list for method input_type
+	13, // [13:13] is the sub-list for extension type_name
+	13, // [13:13] is the sub-list for extension extendee
+	0,  // [0:13] is the sub-list for field type_name
+}
+
+func init() { file_lookupjobs_v1_lookupjobs_proto_init() }
+func file_lookupjobs_v1_lookupjobs_proto_init() {
+	if File_lookupjobs_v1_lookupjobs_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_lookupjobs_v1_lookupjobs_proto_msgTypes[0].Exporter = func(v any, i int) any {
+			switch v := v.(*LookupJob); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_lookupjobs_v1_lookupjobs_proto_msgTypes[1].Exporter = func(v any, i int) any {
+			switch v := v.(*SubmitLookupJobRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_lookupjobs_v1_lookupjobs_proto_msgTypes[2].Exporter = func(v any, i int) any {
+			switch v := v.(*SubmitLookupJobResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_lookupjobs_v1_lookupjobs_proto_msgTypes[3].Exporter = func(v any, i int) any {
+			switch v := v.(*GetLookupJobRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_lookupjobs_v1_lookupjobs_proto_msgTypes[4].Exporter = func(v any, i int) any {
+			switch v := v.(*GetLookupJobResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_lookupjobs_v1_lookupjobs_proto_msgTypes[5].Exporter = func(v any, i int) any {
+			switch v := v.(*WatchLookupJobRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_lookupjobs_v1_lookupjobs_proto_msgTypes[6].Exporter = func(v any, i int) any {
+			switch v := v.(*WatchLookupJobResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_lookupjobs_v1_lookupjobs_proto_msgTypes[7].Exporter = func(v any, i int) any {
+			switch v := v.(*DownloadLookupJobResultsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_lookupjobs_v1_lookupjobs_proto_msgTypes[8].Exporter = func(v any, i int) any {
+			switch v := v.(*DownloadLookupJobResultsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_lookupjobs_v1_lookupjobs_proto_msgTypes[9].Exporter = func(v any, i int) any {
+			switch v := v.(*DeleteLookupJobRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_lookupjobs_v1_lookupjobs_proto_msgTypes[10].Exporter = func(v any, i int) any {
+			switch v := v.(*DeleteLookupJobResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_lookupjobs_v1_lookupjobs_proto_msgTypes[1].OneofWrappers = []any{
+		(*SubmitLookupJobRequest_LookupResources)(nil),
+		(*SubmitLookupJobRequest_LookupSubjects)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_lookupjobs_v1_lookupjobs_proto_rawDesc,
+			NumEnums:      1,
+			NumMessages:   11,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_lookupjobs_v1_lookupjobs_proto_goTypes,
+		DependencyIndexes: file_lookupjobs_v1_lookupjobs_proto_depIdxs,
+		EnumInfos:         file_lookupjobs_v1_lookupjobs_proto_enumTypes,
+		MessageInfos:      file_lookupjobs_v1_lookupjobs_proto_msgTypes,
+	}.Build()
+	File_lookupjobs_v1_lookupjobs_proto = out.File
+	file_lookupjobs_v1_lookupjobs_proto_rawDesc = nil
+	file_lookupjobs_v1_lookupjobs_proto_goTypes = nil
+	file_lookupjobs_v1_lookupjobs_proto_depIdxs = nil
+}
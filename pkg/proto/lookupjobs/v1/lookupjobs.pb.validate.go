@@ -0,0 +1,1564 @@
+// Code generated by protoc-gen-validate. DO NOT EDIT.
+// source: lookupjobs/v1/lookupjobs.proto
+
+package lookupjobsv1
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// ensure the imports are used
+var (
+	_ = bytes.MinRead
+	_ = errors.New("")
+	_ = fmt.Print
+	_ = utf8.UTFMax
+	_ = (*regexp.Regexp)(nil)
+	_ = (*strings.Reader)(nil)
+	_ = net.IPv4len
+	_ = time.Duration(0)
+	_ = (*url.URL)(nil)
+	_ = (*mail.Address)(nil)
+	_ = anypb.Any{}
+	_ = sort.Sort
+)
+
+// Validate checks the field values on LookupJob with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *LookupJob) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on LookupJob with the rules defined in
+// the proto definition for this message. If any rules are violated, the
+// result is a list of violation errors wrapped in LookupJobMultiError, or nil
+// if none found.
+func (m *LookupJob) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *LookupJob) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for JobId
+
+	// no validation rules for State
+
+	// no validation rules for ResultCount
+
+	if all {
+		switch v := interface{}(m.GetLookedUpAt()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, LookupJobValidationError{
+					field:  "LookedUpAt",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, LookupJobValidationError{
+					field:  "LookedUpAt",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetLookedUpAt()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return LookupJobValidationError{
+				field:  "LookedUpAt",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if all {
+		switch v := interface{}(m.GetSubmittedAt()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, LookupJobValidationError{
+					field:  "SubmittedAt",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, LookupJobValidationError{
+					field:  "SubmittedAt",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetSubmittedAt()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return LookupJobValidationError{
+				field:  "SubmittedAt",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if all {
+		switch v := interface{}(m.GetDoneAt()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, LookupJobValidationError{
+					field:  "DoneAt",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, LookupJobValidationError{
+					field:  "DoneAt",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetDoneAt()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return LookupJobValidationError{
+				field:  "DoneAt",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if all {
+		switch v := interface{}(m.GetExpiresAt()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, LookupJobValidationError{
+					field:  "ExpiresAt",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, LookupJobValidationError{
+					field:  "ExpiresAt",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetExpiresAt()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return LookupJobValidationError{
+				field:  "ExpiresAt",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if all {
+		switch v := interface{}(m.GetError()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, LookupJobValidationError{
+					field:  "Error",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, LookupJobValidationError{
+					field:  "Error",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetError()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return LookupJobValidationError{
+				field:  "Error",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if len(errors) > 0 {
+		return LookupJobMultiError(errors)
+	}
+
+	return nil
+}
+
+// LookupJobMultiError is an error wrapping multiple validation errors returned
+// by LookupJob.ValidateAll() if the designated constraints aren't met.
+type LookupJobMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m LookupJobMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m LookupJobMultiError) AllErrors() []error { return m }
+
+// LookupJobValidationError is the validation error returned by
+// LookupJob.Validate if the designated constraints aren't met.
+type LookupJobValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e LookupJobValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e LookupJobValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e LookupJobValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e LookupJobValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e LookupJobValidationError) ErrorName() string { return "LookupJobValidationError" }
+
+// Error satisfies the builtin error interface
+func (e LookupJobValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sLookupJob.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = LookupJobValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = LookupJobValidationError{}
+
+// Validate checks the field values on SubmitLookupJobRequest with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the first error encountered is returned, or nil if there are no violations.
+func (m *SubmitLookupJobRequest) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on SubmitLookupJobRequest with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// SubmitLookupJobRequestMultiError, or nil if none found.
+func (m *SubmitLookupJobRequest) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *SubmitLookupJobRequest) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	switch v := m.Query.(type) {
+	case *SubmitLookupJobRequest_LookupResources:
+		if v == nil {
+			err := SubmitLookupJobRequestValidationError{
+				field:  "Query",
+				reason: "oneof value cannot be a typed-nil",
+			}
+			if !all {
+				return err
+			}
+			errors = append(errors, err)
+		}
+
+		if all {
+			switch v := interface{}(m.GetLookupResources()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, SubmitLookupJobRequestValidationError{
+						field:  "LookupResources",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, SubmitLookupJobRequestValidationError{
+						field:  "LookupResources",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetLookupResources()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return SubmitLookupJobRequestValidationError{
+					field:  "LookupResources",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	case *SubmitLookupJobRequest_LookupSubjects:
+		if v == nil {
+			err := SubmitLookupJobRequestValidationError{
+				field:  "Query",
+				reason: "oneof value cannot be a typed-nil",
+			}
+			if !all {
+				return err
+			}
+			errors = append(errors, err)
+		}
+
+		if all {
+			switch v := interface{}(m.GetLookupSubjects()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, SubmitLookupJobRequestValidationError{
+						field:  "LookupSubjects",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, SubmitLookupJobRequestValidationError{
+						field:  "LookupSubjects",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetLookupSubjects()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return SubmitLookupJobRequestValidationError{
+					field:  "LookupSubjects",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	default:
+		_ = v // ensures v is used
+	}
+
+	if len(errors) > 0 {
+		return SubmitLookupJobRequestMultiError(errors)
+	}
+
+	return nil
+}
+
+// SubmitLookupJobRequestMultiError is an error wrapping multiple validation
+// errors returned by SubmitLookupJobRequest.ValidateAll() if the designated
+// constraints aren't met.
+type SubmitLookupJobRequestMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m SubmitLookupJobRequestMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m SubmitLookupJobRequestMultiError) AllErrors() []error { return m }
+
+// SubmitLookupJobRequestValidationError is the validation error returned by
+// SubmitLookupJobRequest.Validate if the designated constraints aren't met.
+type SubmitLookupJobRequestValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e SubmitLookupJobRequestValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e SubmitLookupJobRequestValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e SubmitLookupJobRequestValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e SubmitLookupJobRequestValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e SubmitLookupJobRequestValidationError) ErrorName() string {
+	return "SubmitLookupJobRequestValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e SubmitLookupJobRequestValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sSubmitLookupJobRequest.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = SubmitLookupJobRequestValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = SubmitLookupJobRequestValidationError{}
+
+// Validate checks the field values on SubmitLookupJobResponse with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the first error encountered is returned, or nil if there are no violations.
+func (m *SubmitLookupJobResponse) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on SubmitLookupJobResponse with the
+// rules defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// SubmitLookupJobResponseMultiError, or nil if none found.
+func (m *SubmitLookupJobResponse) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *SubmitLookupJobResponse) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if all {
+		switch v := interface{}(m.GetJob()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, SubmitLookupJobResponseValidationError{
+					field:  "Job",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, SubmitLookupJobResponseValidationError{
+					field:  "Job",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetJob()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return SubmitLookupJobResponseValidationError{
+				field:  "Job",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if len(errors) > 0 {
+		return SubmitLookupJobResponseMultiError(errors)
+	}
+
+	return nil
+}
+
+// SubmitLookupJobResponseMultiError is an error wrapping multiple validation
+// errors returned by SubmitLookupJobResponse.ValidateAll() if the designated
+// constraints aren't met.
+type SubmitLookupJobResponseMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m SubmitLookupJobResponseMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m SubmitLookupJobResponseMultiError) AllErrors() []error { return m }
+
+// SubmitLookupJobResponseValidationError is the validation error returned by
+// SubmitLookupJobResponse.Validate if the designated constraints aren't met.
+type SubmitLookupJobResponseValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e SubmitLookupJobResponseValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e SubmitLookupJobResponseValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e SubmitLookupJobResponseValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e SubmitLookupJobResponseValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e SubmitLookupJobResponseValidationError) ErrorName() string {
+	return "SubmitLookupJobResponseValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e SubmitLookupJobResponseValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sSubmitLookupJobResponse.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = SubmitLookupJobResponseValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = SubmitLookupJobResponseValidationError{}
+
+// Validate checks the field values on GetLookupJobRequest with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the first error encountered is returned, or nil if there are no violations.
+func (m *GetLookupJobRequest) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on GetLookupJobRequest with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// GetLookupJobRequestMultiError, or nil if none found.
+func (m *GetLookupJobRequest) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *GetLookupJobRequest) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for JobId
+
+	if len(errors) > 0 {
+		return GetLookupJobRequestMultiError(errors)
+	}
+
+	return nil
+}
+
+// GetLookupJobRequestMultiError is an error wrapping multiple validation
+// errors returned by GetLookupJobRequest.ValidateAll() if the designated
+// constraints aren't met.
+type GetLookupJobRequestMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m GetLookupJobRequestMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m GetLookupJobRequestMultiError) AllErrors() []error { return m }
+
+// GetLookupJobRequestValidationError is the validation error returned by
+// GetLookupJobRequest.Validate if the designated constraints aren't met.
+type GetLookupJobRequestValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e GetLookupJobRequestValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e GetLookupJobRequestValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e GetLookupJobRequestValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e GetLookupJobRequestValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e GetLookupJobRequestValidationError) ErrorName() string {
+	return "GetLookupJobRequestValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e GetLookupJobRequestValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sGetLookupJobRequest.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = GetLookupJobRequestValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = GetLookupJobRequestValidationError{}
+
+// Validate checks the field values on GetLookupJobResponse with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the first error encountered is returned, or nil if there are no violations.
+func (m *GetLookupJobResponse) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on GetLookupJobResponse with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// GetLookupJobResponseMultiError, or nil if none found.
+func (m *GetLookupJobResponse) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *GetLookupJobResponse) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if all {
+		switch v := interface{}(m.GetJob()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, GetLookupJobResponseValidationError{
+					field:  "Job",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, GetLookupJobResponseValidationError{
+					field:  "Job",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetJob()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return GetLookupJobResponseValidationError{
+				field:  "Job",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if len(errors) > 0 {
+		return GetLookupJobResponseMultiError(errors)
+	}
+
+	return nil
+}
+
+// GetLookupJobResponseMultiError is an error wrapping multiple validation
+// errors returned by GetLookupJobResponse.ValidateAll() if the designated
+// constraints aren't met.
+type GetLookupJobResponseMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m GetLookupJobResponseMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m GetLookupJobResponseMultiError) AllErrors() []error { return m }
+
+// GetLookupJobResponseValidationError is the validation error returned by
+// GetLookupJobResponse.Validate if the designated constraints aren't met.
+type GetLookupJobResponseValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e GetLookupJobResponseValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e GetLookupJobResponseValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e GetLookupJobResponseValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e GetLookupJobResponseValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e GetLookupJobResponseValidationError) ErrorName() string {
+	return "GetLookupJobResponseValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e GetLookupJobResponseValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sGetLookupJobResponse.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = GetLookupJobResponseValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = GetLookupJobResponseValidationError{}
+
+// Validate checks the field values on WatchLookupJobRequest with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the first error encountered is returned, or nil if there are no violations.
+func (m *WatchLookupJobRequest) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on WatchLookupJobRequest with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// WatchLookupJobRequestMultiError, or nil if none found.
+func (m *WatchLookupJobRequest) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *WatchLookupJobRequest) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for JobId
+
+	if len(errors) > 0 {
+		return WatchLookupJobRequestMultiError(errors)
+	}
+
+	return nil
+}
+
+// WatchLookupJobRequestMultiError is an error wrapping multiple validation
+// errors returned by WatchLookupJobRequest.ValidateAll() if the designated
+// constraints aren't met.
+type WatchLookupJobRequestMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m WatchLookupJobRequestMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m WatchLookupJobRequestMultiError) AllErrors() []error { return m }
+
+// WatchLookupJobRequestValidationError is the validation error returned by
+// WatchLookupJobRequest.Validate if the designated constraints aren't met.
+type WatchLookupJobRequestValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e WatchLookupJobRequestValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e WatchLookupJobRequestValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e WatchLookupJobRequestValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e WatchLookupJobRequestValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e WatchLookupJobRequestValidationError) ErrorName() string {
+	return "WatchLookupJobRequestValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e WatchLookupJobRequestValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sWatchLookupJobRequest.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = WatchLookupJobRequestValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = WatchLookupJobRequestValidationError{}
+
+// Validate checks the field values on WatchLookupJobResponse with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the first error encountered is returned, or nil if there are no violations.
+func (m *WatchLookupJobResponse) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on WatchLookupJobResponse with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// WatchLookupJobResponseMultiError, or nil if none found.
+func (m *WatchLookupJobResponse) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *WatchLookupJobResponse) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if all {
+		switch v := interface{}(m.GetJob()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, WatchLookupJobResponseValidationError{
+					field:  "Job",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, WatchLookupJobResponseValidationError{
+					field:  "Job",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetJob()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return WatchLookupJobResponseValidationError{
+				field:  "Job",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if len(errors) > 0 {
+		return WatchLookupJobResponseMultiError(errors)
+	}
+
+	return nil
+}
+
+// WatchLookupJobResponseMultiError is an error wrapping multiple validation
+// errors returned by WatchLookupJobResponse.ValidateAll() if the designated
+// constraints aren't met.
+type WatchLookupJobResponseMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m WatchLookupJobResponseMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m WatchLookupJobResponseMultiError) AllErrors() []error { return m }
+
+// WatchLookupJobResponseValidationError is the validation error returned by
+// WatchLookupJobResponse.Validate if the designated constraints aren't met.
+type WatchLookupJobResponseValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e WatchLookupJobResponseValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e WatchLookupJobResponseValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e WatchLookupJobResponseValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e WatchLookupJobResponseValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e WatchLookupJobResponseValidationError) ErrorName() string {
+	return "WatchLookupJobResponseValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e WatchLookupJobResponseValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sWatchLookupJobResponse.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = WatchLookupJobResponseValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = WatchLookupJobResponseValidationError{}
+
+// Validate checks the field values on DownloadLookupJobResultsRequest with the
+// rules defined in the proto definition for this message. If any rules are
+// violated, the first error encountered is returned, or nil if there are no violations.
+func (m *DownloadLookupJobResultsRequest) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on DownloadLookupJobResultsRequest with
+// the rules defined in the proto definition for this message. If any rules
+// are violated, the result is a list of violation errors wrapped in
+// DownloadLookupJobResultsRequestMultiError, or nil if none found.
+func (m *DownloadLookupJobResultsRequest) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *DownloadLookupJobResultsRequest) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for JobId
+
+	// no validation rules for OptionalOffset
+
+	if len(errors) > 0 {
+		return DownloadLookupJobResultsRequestMultiError(errors)
+	}
+
+	return nil
+}
+
+// DownloadLookupJobResultsRequestMultiError is an error wrapping multiple
+// validation errors returned by DownloadLookupJobResultsRequest.ValidateAll()
+// if the designated constraints aren't met.
+type DownloadLookupJobResultsRequestMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m DownloadLookupJobResultsRequestMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m DownloadLookupJobResultsRequestMultiError) AllErrors() []error { return m }
+
+// DownloadLookupJobResultsRequestValidationError is the validation error
+// returned by DownloadLookupJobResultsRequest.Validate if the designated
+// constraints aren't met.
+type DownloadLookupJobResultsRequestValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e DownloadLookupJobResultsRequestValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e DownloadLookupJobResultsRequestValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e DownloadLookupJobResultsRequestValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e DownloadLookupJobResultsRequestValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e DownloadLookupJobResultsRequestValidationError) ErrorName() string {
+	return "DownloadLookupJobResultsRequestValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e DownloadLookupJobResultsRequestValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sDownloadLookupJobResultsRequest.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = DownloadLookupJobResultsRequestValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = DownloadLookupJobResultsRequestValidationError{}
+
+// Validate checks the field values on DownloadLookupJobResultsResponse with
+// the rules defined in the proto definition for this message. If any rules
+// are violated, the first error encountered is returned, or nil if there are
+// no violations.
+func (m *DownloadLookupJobResultsResponse) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on DownloadLookupJobResultsResponse with
+// the rules defined in the proto definition for this message. If any rules
+// are violated, the result is a list of violation errors wrapped in
+// DownloadLookupJobResultsResponseMultiError, or nil if none found.
+func (m *DownloadLookupJobResultsResponse) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *DownloadLookupJobResultsResponse) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	for idx, item := range m.GetLookupResourcesResults() {
+		_, _ = idx, item
+
+		if all {
+			switch v := interface{}(item).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, DownloadLookupJobResultsResponseValidationError{
+						field:  fmt.Sprintf("LookupResourcesResults[%v]", idx),
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, DownloadLookupJobResultsResponseValidationError{
+						field:  fmt.Sprintf("LookupResourcesResults[%v]", idx),
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(item).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return DownloadLookupJobResultsResponseValidationError{
+					field:  fmt.Sprintf("LookupResourcesResults[%v]", idx),
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	}
+
+	for idx, item := range m.GetLookupSubjectsResults() {
+		_, _ = idx, item
+
+		if all {
+			switch v := interface{}(item).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, DownloadLookupJobResultsResponseValidationError{
+						field:  fmt.Sprintf("LookupSubjectsResults[%v]", idx),
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, DownloadLookupJobResultsResponseValidationError{
+						field:  fmt.Sprintf("LookupSubjectsResults[%v]", idx),
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(item).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return DownloadLookupJobResultsResponseValidationError{
+					field:  fmt.Sprintf("LookupSubjectsResults[%v]", idx),
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	}
+
+	// no validation rules for NextOffset
+
+	if len(errors) > 0 {
+		return DownloadLookupJobResultsResponseMultiError(errors)
+	}
+
+	return nil
+}
+
+// DownloadLookupJobResultsResponseMultiError is an error wrapping multiple
+// validation errors returned by
+// DownloadLookupJobResultsResponse.ValidateAll() if the designated
+// constraints aren't met.
+type DownloadLookupJobResultsResponseMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m DownloadLookupJobResultsResponseMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m DownloadLookupJobResultsResponseMultiError) AllErrors() []error { return m }
+
+// DownloadLookupJobResultsResponseValidationError is the validation error
+// returned by DownloadLookupJobResultsResponse.Validate if the designated
+// constraints aren't met.
+type DownloadLookupJobResultsResponseValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e DownloadLookupJobResultsResponseValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e DownloadLookupJobResultsResponseValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e DownloadLookupJobResultsResponseValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e DownloadLookupJobResultsResponseValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e DownloadLookupJobResultsResponseValidationError) ErrorName() string {
+	return "DownloadLookupJobResultsResponseValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e DownloadLookupJobResultsResponseValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sDownloadLookupJobResultsResponse.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = DownloadLookupJobResultsResponseValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = DownloadLookupJobResultsResponseValidationError{}
+
+// Validate checks the field values on DeleteLookupJobRequest with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the first error encountered is returned, or nil if there are no violations.
+func (m *DeleteLookupJobRequest) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on DeleteLookupJobRequest with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// DeleteLookupJobRequestMultiError, or nil if none found.
+func (m *DeleteLookupJobRequest) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *DeleteLookupJobRequest) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for JobId
+
+	if len(errors) > 0 {
+		return DeleteLookupJobRequestMultiError(errors)
+	}
+
+	return nil
+}
+
+// DeleteLookupJobRequestMultiError is an error wrapping multiple validation
+// errors returned by DeleteLookupJobRequest.ValidateAll() if the designated
+// constraints aren't met.
+type DeleteLookupJobRequestMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m DeleteLookupJobRequestMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m DeleteLookupJobRequestMultiError) AllErrors() []error { return m }
+
+// DeleteLookupJobRequestValidationError is the validation error returned by
+// DeleteLookupJobRequest.Validate if the designated constraints aren't met.
+type DeleteLookupJobRequestValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e DeleteLookupJobRequestValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e DeleteLookupJobRequestValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e DeleteLookupJobRequestValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e DeleteLookupJobRequestValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e DeleteLookupJobRequestValidationError) ErrorName() string {
+	return "DeleteLookupJobRequestValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e DeleteLookupJobRequestValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sDeleteLookupJobRequest.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = DeleteLookupJobRequestValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = DeleteLookupJobRequestValidationError{}
+
+// Validate checks the field values on DeleteLookupJobResponse with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the first error encountered is returned, or nil if there are no violations.
+func (m *DeleteLookupJobResponse) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on DeleteLookupJobResponse with the
+// rules defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// DeleteLookupJobResponseMultiError, or nil if none found.
+func (m *DeleteLookupJobResponse) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *DeleteLookupJobResponse) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if len(errors) > 0 {
+		return DeleteLookupJobResponseMultiError(errors)
+	}
+
+	return nil
+}
+
+// DeleteLookupJobResponseMultiError is an error wrapping multiple validation
+// errors returned by DeleteLookupJobResponse.ValidateAll() if the designated
+// constraints aren't met.
+type DeleteLookupJobResponseMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m DeleteLookupJobResponseMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m DeleteLookupJobResponseMultiError) AllErrors() []error { return m }
+
+// DeleteLookupJobResponseValidationError is the validation error returned by
+// DeleteLookupJobResponse.Validate if the designated constraints aren't met.
+type DeleteLookupJobResponseValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e DeleteLookupJobResponseValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e DeleteLookupJobResponseValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e DeleteLookupJobResponseValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e DeleteLookupJobResponseValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e DeleteLookupJobResponseValidationError) ErrorName() string {
+	return "DeleteLookupJobResponseValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e DeleteLookupJobResponseValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sDeleteLookupJobResponse.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = DeleteLookupJobResponseValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = DeleteLookupJobResponseValidationError{}
@@ -0,0 +1,324 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: lookupjobs/v1/lookupjobs.proto
+
+package lookupjobsv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	LookupJobsService_SubmitLookupJob_FullMethodName          = "/lookupjobs.v1.LookupJobsService/SubmitLookupJob"
+	LookupJobsService_GetLookupJob_FullMethodName             = "/lookupjobs.v1.LookupJobsService/GetLookupJob"
+	LookupJobsService_WatchLookupJob_FullMethodName           = "/lookupjobs.v1.LookupJobsService/WatchLookupJob"
+	LookupJobsService_DownloadLookupJobResults_FullMethodName = "/lookupjobs.v1.LookupJobsService/DownloadLookupJobResults"
+	LookupJobsService_DeleteLookupJob_FullMethodName          = "/lookupjobs.v1.LookupJobsService/DeleteLookupJob"
+)
+
+// LookupJobsServiceClient is the client API for LookupJobsService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type LookupJobsServiceClient interface {
+	// SubmitLookupJob starts a job running the query, returning its ID.
+	SubmitLookupJob(ctx context.Context, in *SubmitLookupJobRequest, opts ...grpc.CallOption) (*SubmitLookupJobResponse, error)
+	// GetLookupJob returns the current state of a job.
+	GetLookupJob(ctx context.Context, in *GetLookupJobRequest, opts ...grpc.CallOption) (*GetLookupJobResponse, error)
+	// WatchLookupJob streams the state of a job, first its current state and then each change,
+	// ending once the job is done.
+	WatchLookupJob(ctx context.Context, in *WatchLookupJobRequest, opts ...grpc.CallOption) (LookupJobsService_WatchLookupJobClient, error)
+	// DownloadLookupJobResults streams the results of a completed job, in the order they were found.
+	DownloadLookupJobResults(ctx context.Context, in *DownloadLookupJobResultsRequest, opts ...grpc.CallOption) (LookupJobsService_DownloadLookupJobResultsClient, error)
+	// DeleteLookupJob cancels a job if it is running, and deletes it along with its results.
+	DeleteLookupJob(ctx context.Context, in *DeleteLookupJobRequest, opts ...grpc.CallOption) (*DeleteLookupJobResponse, error)
+}
+
+type lookupJobsServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewLookupJobsServiceClient(cc grpc.ClientConnInterface) LookupJobsServiceClient {
+	return &lookupJobsServiceClient{cc}
+}
+
+func (c *lookupJobsServiceClient) SubmitLookupJob(ctx context.Context, in *SubmitLookupJobRequest, opts ...grpc.CallOption) (*SubmitLookupJobResponse, error) {
+	out := new(SubmitLookupJobResponse)
+	err := c.cc.Invoke(ctx, LookupJobsService_SubmitLookupJob_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lookupJobsServiceClient) GetLookupJob(ctx context.Context, in *GetLookupJobRequest, opts ...grpc.CallOption) (*GetLookupJobResponse, error) {
+	out := new(GetLookupJobResponse)
+	err := c.cc.Invoke(ctx, LookupJobsService_GetLookupJob_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lookupJobsServiceClient) WatchLookupJob(ctx context.Context, in *WatchLookupJobRequest, opts ...grpc.CallOption) (LookupJobsService_WatchLookupJobClient, error) {
+	stream, err := c.cc.NewStream(ctx, &LookupJobsService_ServiceDesc.Streams[0], LookupJobsService_WatchLookupJob_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &lookupJobsServiceWatchLookupJobClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type LookupJobsService_WatchLookupJobClient interface {
+	Recv() (*WatchLookupJobResponse, error)
+	grpc.ClientStream
+}
+
+type lookupJobsServiceWatchLookupJobClient struct {
+	grpc.ClientStream
+}
+
+func (x *lookupJobsServiceWatchLookupJobClient) Recv() (*WatchLookupJobResponse, error) {
+	m := new(WatchLookupJobResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *lookupJobsServiceClient) DownloadLookupJobResults(ctx context.Context, in *DownloadLookupJobResultsRequest, opts ...grpc.CallOption) (LookupJobsService_DownloadLookupJobResultsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &LookupJobsService_ServiceDesc.Streams[1], LookupJobsService_DownloadLookupJobResults_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &lookupJobsServiceDownloadLookupJobResultsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type LookupJobsService_DownloadLookupJobResultsClient interface {
+	Recv() (*DownloadLookupJobResultsResponse, error)
+	grpc.ClientStream
+}
+
+type lookupJobsServiceDownloadLookupJobResultsClient struct {
+	grpc.ClientStream
+}
+
+func (x *lookupJobsServiceDownloadLookupJobResultsClient) Recv() (*DownloadLookupJobResultsResponse, error) {
+	m := new(DownloadLookupJobResultsResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *lookupJobsServiceClient) DeleteLookupJob(ctx context.Context, in *DeleteLookupJobRequest, opts ...grpc.CallOption) (*DeleteLookupJobResponse, error) {
+	out := new(DeleteLookupJobResponse)
+	err := c.cc.Invoke(ctx, LookupJobsService_DeleteLookupJob_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// LookupJobsServiceServer is the server API for LookupJobsService service.
+// All implementations must embed UnimplementedLookupJobsServiceServer
+// for forward compatibility
+type LookupJobsServiceServer interface {
+	// SubmitLookupJob starts a job running the query, returning its ID.
+	SubmitLookupJob(context.Context, *SubmitLookupJobRequest) (*SubmitLookupJobResponse, error)
+	// GetLookupJob returns the current state of a job.
+	GetLookupJob(context.Context, *GetLookupJobRequest) (*GetLookupJobResponse, error)
+	// WatchLookupJob streams the state of a job, first its current state and then each change,
+	// ending once the job is done.
+	WatchLookupJob(*WatchLookupJobRequest, LookupJobsService_WatchLookupJobServer) error
+	// DownloadLookupJobResults streams the results of a completed job, in the order they were found.
+	DownloadLookupJobResults(*DownloadLookupJobResultsRequest, LookupJobsService_DownloadLookupJobResultsServer) error
+	// DeleteLookupJob cancels a job if it is running, and deletes it along with its results.
+	DeleteLookupJob(context.Context, *DeleteLookupJobRequest) (*DeleteLookupJobResponse, error)
+	mustEmbedUnimplementedLookupJobsServiceServer()
+}
+
+// UnimplementedLookupJobsServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedLookupJobsServiceServer struct {
+}
+
+func (UnimplementedLookupJobsServiceServer) SubmitLookupJob(context.Context, *SubmitLookupJobRequest) (*SubmitLookupJobResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SubmitLookupJob not implemented")
+}
+func (UnimplementedLookupJobsServiceServer) GetLookupJob(context.Context, *GetLookupJobRequest) (*GetLookupJobResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLookupJob not implemented")
+}
+func (UnimplementedLookupJobsServiceServer) WatchLookupJob(*WatchLookupJobRequest, LookupJobsService_WatchLookupJobServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchLookupJob not implemented")
+}
+func (UnimplementedLookupJobsServiceServer) DownloadLookupJobResults(*DownloadLookupJobResultsRequest, LookupJobsService_DownloadLookupJobResultsServer) error {
+	return status.Errorf(codes.Unimplemented, "method DownloadLookupJobResults not implemented")
+}
+func (UnimplementedLookupJobsServiceServer) DeleteLookupJob(context.Context, *DeleteLookupJobRequest) (*DeleteLookupJobResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteLookupJob not implemented")
+}
+func (UnimplementedLookupJobsServiceServer) mustEmbedUnimplementedLookupJobsServiceServer() {}
+
+// UnsafeLookupJobsServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to LookupJobsServiceServer will
+// result in compilation errors.
+type UnsafeLookupJobsServiceServer interface {
+	mustEmbedUnimplementedLookupJobsServiceServer()
+}
+
+func RegisterLookupJobsServiceServer(s grpc.ServiceRegistrar, srv LookupJobsServiceServer) {
+	s.RegisterService(&LookupJobsService_ServiceDesc, srv)
+}
+
+func _LookupJobsService_SubmitLookupJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubmitLookupJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LookupJobsServiceServer).SubmitLookupJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LookupJobsService_SubmitLookupJob_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LookupJobsServiceServer).SubmitLookupJob(ctx, req.(*SubmitLookupJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LookupJobsService_GetLookupJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetLookupJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LookupJobsServiceServer).GetLookupJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LookupJobsService_GetLookupJob_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LookupJobsServiceServer).GetLookupJob(ctx, req.(*GetLookupJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LookupJobsService_WatchLookupJob_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchLookupJobRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(LookupJobsServiceServer).WatchLookupJob(m, &lookupJobsServiceWatchLookupJobServer{stream})
+}
+
+type LookupJobsService_WatchLookupJobServer interface {
+	Send(*WatchLookupJobResponse) error
+	grpc.ServerStream
+}
+
+type lookupJobsServiceWatchLookupJobServer struct {
+	grpc.ServerStream
+}
+
+func (x *lookupJobsServiceWatchLookupJobServer) Send(m *WatchLookupJobResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _LookupJobsService_DownloadLookupJobResults_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(DownloadLookupJobResultsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(LookupJobsServiceServer).DownloadLookupJobResults(m, &lookupJobsServiceDownloadLookupJobResultsServer{stream})
+}
+
+type LookupJobsService_DownloadLookupJobResultsServer interface {
+	Send(*DownloadLookupJobResultsResponse) error
+	grpc.ServerStream
+}
+
+type lookupJobsServiceDownloadLookupJobResultsServer struct {
+	grpc.ServerStream
+}
+
+func (x *lookupJobsServiceDownloadLookupJobResultsServer) Send(m *DownloadLookupJobResultsResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _LookupJobsService_DeleteLookupJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteLookupJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LookupJobsServiceServer).DeleteLookupJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LookupJobsService_DeleteLookupJob_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LookupJobsServiceServer).DeleteLookupJob(ctx, req.(*DeleteLookupJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// LookupJobsService_ServiceDesc is the grpc.ServiceDesc for LookupJobsService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var LookupJobsService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "lookupjobs.v1.LookupJobsService",
+	HandlerType: (*LookupJobsServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SubmitLookupJob",
+			Handler:    _LookupJobsService_SubmitLookupJob_Handler,
+		},
+		{
+			MethodName: "GetLookupJob",
+			Handler:    _LookupJobsService_GetLookupJob_Handler,
+		},
+		{
+			MethodName: "DeleteLookupJob",
+			Handler:    _LookupJobsService_DeleteLookupJob_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchLookupJob",
+			Handler:       _LookupJobsService_WatchLookupJob_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "DownloadLookupJobResults",
+			Handler:       _LookupJobsService_DownloadLookupJobResults_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "lookupjobs/v1/lookupjobs.proto",
+}
@@ -0,0 +1,482 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.2
+// 	protoc        (unknown)
+// source: lookupwatch/v1/lookupwatch.proto
+
+package lookupwatchv1
+
+import (
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ResourceChange_ChangeType int32
+
+const (
+	ResourceChange_CHANGE_TYPE_UNSPECIFIED ResourceChange_ChangeType = 0
+	// CHANGE_TYPE_ADDED indicates that the resource became accessible, or that its permissionship
+	// changed.
+	ResourceChange_CHANGE_TYPE_ADDED ResourceChange_ChangeType = 1
+	// CHANGE_TYPE_REMOVED indicates that the resource is no longer accessible.
+	ResourceChange_CHANGE_TYPE_REMOVED ResourceChange_ChangeType = 2
+)
+
+// Enum value maps for ResourceChange_ChangeType.
+var (
+	ResourceChange_ChangeType_name = map[int32]string{
+		0: "CHANGE_TYPE_UNSPECIFIED",
+		1: "CHANGE_TYPE_ADDED",
+		2: "CHANGE_TYPE_REMOVED",
+	}
+	ResourceChange_ChangeType_value = map[string]int32{
+		"CHANGE_TYPE_UNSPECIFIED": 0,
+		"CHANGE_TYPE_ADDED":       1,
+		"CHANGE_TYPE_REMOVED":     2,
+	}
+)
+
+func (x ResourceChange_ChangeType) Enum() *ResourceChange_ChangeType {
+	p := new(ResourceChange_ChangeType)
+	*p = x
+	return p
+}
+
+func (x ResourceChange_ChangeType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ResourceChange_ChangeType) Descriptor() protoreflect.EnumDescriptor {
+	return file_lookupwatch_v1_lookupwatch_proto_enumTypes[0].Descriptor()
+}
+
+func (ResourceChange_ChangeType) Type() protoreflect.EnumType {
+	return &file_lookupwatch_v1_lookupwatch_proto_enumTypes[0]
+}
+
+func (x ResourceChange_ChangeType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ResourceChange_ChangeType.Descriptor instead.
+func (ResourceChange_ChangeType) EnumDescriptor() ([]byte, []int) {
+	return file_lookupwatch_v1_lookupwatch_proto_rawDescGZIP(), []int{2, 0}
+}
+
+// WatchLookupResourcesRequest is the request to watch the resources on which a subject has a
+// permission.
+type WatchLookupResourcesRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// resource_object_type is the type of the resources to watch.
+	ResourceObjectType string `protobuf:"bytes,1,opt,name=resource_object_type,json=resourceObjectType,proto3" json:"resource_object_type,omitempty"`
+	// permission is the relation or permission which the subject must have on the resources.
+	Permission string `protobuf:"bytes,2,opt,name=permission,proto3" json:"permission,omitempty"`
+	// subject is the subject whose accessible resources are watched.
+	Subject *v1.SubjectReference `protobuf:"bytes,3,opt,name=subject,proto3" json:"subject,omitempty"`
+	// context is the caveat context used to compute the accessible resources.
+	Context *structpb.Struct `protobuf:"bytes,4,opt,name=context,proto3" json:"context,omitempty"`
+	// optional_start_cursor is the revision, as returned in changes_through, at which to start
+	// watching. If unspecified, the watch starts at the current revision.
+	OptionalStartCursor *v1.ZedToken `protobuf:"bytes,5,opt,name=optional_start_cursor,json=optionalStartCursor,proto3" json:"optional_start_cursor,omitempty"`
+}
+
+func (x *WatchLookupResourcesRequest) Reset() {
+	*x = WatchLookupResourcesRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_lookupwatch_v1_lookupwatch_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WatchLookupResourcesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchLookupResourcesRequest) ProtoMessage() {}
+
+func (x *WatchLookupResourcesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_lookupwatch_v1_lookupwatch_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchLookupResourcesRequest.ProtoReflect.Descriptor instead.
+func (*WatchLookupResourcesRequest) Descriptor() ([]byte, []int) {
+	return file_lookupwatch_v1_lookupwatch_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *WatchLookupResourcesRequest) GetResourceObjectType() string {
+	if x != nil {
+		return x.ResourceObjectType
+	}
+	return ""
+}
+
+func (x *WatchLookupResourcesRequest) GetPermission() string {
+	if x != nil {
+		return x.Permission
+	}
+	return ""
+}
+
+func (x *WatchLookupResourcesRequest) GetSubject() *v1.SubjectReference {
+	if x != nil {
+		return x.Subject
+	}
+	return nil
+}
+
+func (x *WatchLookupResourcesRequest) GetContext() *structpb.Struct {
+	if x != nil {
+		return x.Context
+	}
+	return nil
+}
+
+func (x *WatchLookupResourcesRequest) GetOptionalStartCursor() *v1.ZedToken {
+	if x != nil {
+		return x.OptionalStartCursor
+	}
+	return nil
+}
+
+// WatchLookupResourcesResponse contains the changes to the accessible resources through a revision.
+type WatchLookupResourcesResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// snapshot is true for the responses which start the stream, whose changes add every resource
+	// accessible at the start revision. A view of the resources resuming from a cursor should be
+	// replaced, rather than updated, by the snapshot.
+	Snapshot bool `protobuf:"varint,1,opt,name=snapshot,proto3" json:"snapshot,omitempty"`
+	// changes are the changes to the accessible resources.
+	Changes []*ResourceChange `protobuf:"bytes,2,rep,name=changes,proto3" json:"changes,omitempty"`
+	// changes_through is the revision through which the changes were computed, from which the watch
+	// can be resumed.
+	ChangesThrough *v1.ZedToken `protobuf:"bytes,3,opt,name=changes_through,json=changesThrough,proto3" json:"changes_through,omitempty"`
+}
+
+func (x *WatchLookupResourcesResponse) Reset() {
+	*x = WatchLookupResourcesResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_lookupwatch_v1_lookupwatch_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WatchLookupResourcesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchLookupResourcesResponse) ProtoMessage() {}
+
+func (x *WatchLookupResourcesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_lookupwatch_v1_lookupwatch_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchLookupResourcesResponse.ProtoReflect.Descriptor instead.
+func (*WatchLookupResourcesResponse) Descriptor() ([]byte, []int) {
+	return file_lookupwatch_v1_lookupwatch_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *WatchLookupResourcesResponse) GetSnapshot() bool {
+	if x != nil {
+		return x.Snapshot
+	}
+	return false
+}
+
+func (x *WatchLookupResourcesResponse) GetChanges() []*ResourceChange {
+	if x != nil {
+		return x.Changes
+	}
+	return nil
+}
+
+func (x *WatchLookupResourcesResponse) GetChangesThrough() *v1.ZedToken {
+	if x != nil {
+		return x.ChangesThrough
+	}
+	return nil
+}
+
+// ResourceChange is a change to the accessibility of a single resource.
+type ResourceChange struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// type is the type of the change.
+	Type ResourceChange_ChangeType `protobuf:"varint,1,opt,name=type,proto3,enum=lookupwatch.v1.ResourceChange_ChangeType" json:"type,omitempty"`
+	// resource_object_id is the ID of the resource.
+	ResourceObjectId string `protobuf:"bytes,2,opt,name=resource_object_id,json=resourceObjectId,proto3" json:"resource_object_id,omitempty"`
+	// permissionship is the permissionship of the subject on an added resource.
+	Permissionship v1.LookupPermissionship `protobuf:"varint,3,opt,name=permissionship,proto3,enum=authzed.api.v1.LookupPermissionship" json:"permissionship,omitempty"`
+}
+
+func (x *ResourceChange) Reset() {
+	*x = ResourceChange{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_lookupwatch_v1_lookupwatch_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ResourceChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResourceChange) ProtoMessage() {}
+
+func (x *ResourceChange) ProtoReflect() protoreflect.Message {
+	mi := &file_lookupwatch_v1_lookupwatch_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResourceChange.ProtoReflect.Descriptor instead.
+func (*ResourceChange) Descriptor() ([]byte, []int) {
+	return file_lookupwatch_v1_lookupwatch_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ResourceChange) GetType() ResourceChange_ChangeType {
+	if x != nil {
+		return x.Type
+	}
+	return ResourceChange_CHANGE_TYPE_UNSPECIFIED
+}
+
+func (x *ResourceChange) GetResourceObjectId() string {
+	if x != nil {
+		return x.ResourceObjectId
+	}
+	return ""
+}
+
+func (x *ResourceChange) GetPermissionship() v1.LookupPermissionship {
+	if x != nil {
+		return x.Permissionship
+	}
+	return v1.LookupPermissionship(0)
+}
+
+var File_lookupwatch_v1_lookupwatch_proto protoreflect.FileDescriptor
+
+var file_lookupwatch_v1_lookupwatch_proto_rawDesc = []byte{
+	0x0a, 0x20, 0x6c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x77, 0x61, 0x74, 0x63, 0x68, 0x2f, 0x76, 0x31,
+	0x2f, 0x6c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x77, 0x61, 0x74, 0x63, 0x68, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x12, 0x0e, 0x6c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x77, 0x61, 0x74, 0x63, 0x68, 0x2e,
+	0x76, 0x31, 0x1a, 0x19, 0x61, 0x75, 0x74, 0x68, 0x7a, 0x65, 0x64, 0x2f, 0x61, 0x70, 0x69, 0x2f,
+	0x76, 0x31, 0x2f, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x27, 0x61,
+	0x75, 0x74, 0x68, 0x7a, 0x65, 0x64, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x76, 0x31, 0x2f, 0x70, 0x65,
+	0x72, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x5f, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1c, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x73, 0x74, 0x72, 0x75, 0x63, 0x74, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x22, 0xac, 0x02, 0x0a, 0x1b, 0x57, 0x61, 0x74, 0x63, 0x68, 0x4c, 0x6f,
+	0x6f, 0x6b, 0x75, 0x70, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x73, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x30, 0x0a, 0x14, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65,
+	0x5f, 0x6f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x12, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x4f, 0x62, 0x6a, 0x65,
+	0x63, 0x74, 0x54, 0x79, 0x70, 0x65, 0x12, 0x1e, 0x0a, 0x0a, 0x70, 0x65, 0x72, 0x6d, 0x69, 0x73,
+	0x73, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x70, 0x65, 0x72, 0x6d,
+	0x69, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x3a, 0x0a, 0x07, 0x73, 0x75, 0x62, 0x6a, 0x65, 0x63,
+	0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x7a, 0x65,
+	0x64, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x75, 0x62, 0x6a, 0x65, 0x63, 0x74,
+	0x52, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x52, 0x07, 0x73, 0x75, 0x62, 0x6a, 0x65,
+	0x63, 0x74, 0x12, 0x31, 0x0a, 0x07, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x53, 0x74, 0x72, 0x75, 0x63, 0x74, 0x52, 0x07, 0x63, 0x6f,
+	0x6e, 0x74, 0x65, 0x78, 0x74, 0x12, 0x4c, 0x0a, 0x15, 0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x61,
+	0x6c, 0x5f, 0x73, 0x74, 0x61, 0x72, 0x74, 0x5f, 0x63, 0x75, 0x72, 0x73, 0x6f, 0x72, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x7a, 0x65, 0x64, 0x2e, 0x61,
+	0x70, 0x69, 0x2e, 0x76, 0x31, 0x2e, 0x5a, 0x65, 0x64, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x13,
+	0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x61, 0x6c, 0x53, 0x74, 0x61, 0x72, 0x74, 0x43, 0x75, 0x72,
+	0x73, 0x6f, 0x72, 0x22, 0xb7, 0x01, 0x0a, 0x1c, 0x57, 0x61, 0x74, 0x63, 0x68, 0x4c, 0x6f, 0x6f,
+	0x6b, 0x75, 0x70, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x73, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x73, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74,
+	0x12, 0x38, 0x0a, 0x07, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x1e, 0x2e, 0x6c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x77, 0x61, 0x74, 0x63, 0x68, 0x2e,
+	0x76, 0x31, 0x2e, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x43, 0x68, 0x61, 0x6e, 0x67,
+	0x65, 0x52, 0x07, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x12, 0x41, 0x0a, 0x0f, 0x63, 0x68,
+	0x61, 0x6e, 0x67, 0x65, 0x73, 0x5f, 0x74, 0x68, 0x72, 0x6f, 0x75, 0x67, 0x68, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x7a, 0x65, 0x64, 0x2e, 0x61, 0x70,
+	0x69, 0x2e, 0x76, 0x31, 0x2e, 0x5a, 0x65, 0x64, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x0e, 0x63,
+	0x68, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x54, 0x68, 0x72, 0x6f, 0x75, 0x67, 0x68, 0x22, 0xa6, 0x02,
+	0x0a, 0x0e, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65,
+	0x12, 0x3d, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x29,
+	0x2e, 0x6c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x77, 0x61, 0x74, 0x63, 0x68, 0x2e, 0x76, 0x31, 0x2e,
+	0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x2e, 0x43,
+	0x68, 0x61, 0x6e, 0x67, 0x65, 0x54, 0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12,
+	0x2c, 0x0a, 0x12, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x5f, 0x6f, 0x62, 0x6a, 0x65,
+	0x63, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x10, 0x72, 0x65, 0x73,
+	0x6f, 0x75, 0x72, 0x63, 0x65, 0x4f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x49, 0x64, 0x12, 0x4c, 0x0a,
+	0x0e, 0x70, 0x65, 0x72, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x68, 0x69, 0x70, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x24, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x7a, 0x65, 0x64, 0x2e,
+	0x61, 0x70, 0x69, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x50, 0x65, 0x72,
+	0x6d, 0x69, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x68, 0x69, 0x70, 0x52, 0x0e, 0x70, 0x65, 0x72,
+	0x6d, 0x69, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x68, 0x69, 0x70, 0x22, 0x59, 0x0a, 0x0a, 0x43,
+	0x68, 0x61, 0x6e, 0x67, 0x65, 0x54, 0x79, 0x70, 0x65, 0x12, 0x1b, 0x0a, 0x17, 0x43, 0x48, 0x41,
+	0x4e, 0x47, 0x45, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49,
+	0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x15, 0x0a, 0x11, 0x43, 0x48, 0x41, 0x4e, 0x47, 0x45,
+	0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x41, 0x44, 0x44, 0x45, 0x44, 0x10, 0x01, 0x12, 0x17, 0x0a,
+	0x13, 0x43, 0x48, 0x41, 0x4e, 0x47, 0x45, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x52, 0x45, 0x4d,
+	0x4f, 0x56, 0x45, 0x44, 0x10, 0x02, 0x32, 0x8b, 0x01, 0x0a, 0x12, 0x4c, 0x6f, 0x6f, 0x6b, 0x75,
+	0x70, 0x57, 0x61, 0x74, 0x63, 0x68, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x75, 0x0a,
+	0x14, 0x57, 0x61, 0x74, 0x63, 0x68, 0x4c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x52, 0x65, 0x73, 0x6f,
+	0x75, 0x72, 0x63, 0x65, 0x73, 0x12, 0x2b, 0x2e, 0x6c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x77, 0x61,
+	0x74, 0x63, 0x68, 0x2e, 0x76, 0x31, 0x2e, 0x57, 0x61, 0x74, 0x63, 0x68, 0x4c, 0x6f, 0x6f, 0x6b,
+	0x75, 0x70, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x2c, 0x2e, 0x6c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x77, 0x61, 0x74, 0x63, 0x68,
+	0x2e, 0x76, 0x31, 0x2e, 0x57, 0x61, 0x74, 0x63, 0x68, 0x4c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x52,
+	0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x22, 0x00, 0x30, 0x01, 0x42, 0x43, 0x5a, 0x41, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63,
+	0x6f, 0x6d, 0x2f, 0x61, 0x75, 0x74, 0x68, 0x7a, 0x65, 0x64, 0x2f, 0x73, 0x70, 0x69, 0x63, 0x65,
+	0x64, 0x62, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x6c, 0x6f, 0x6f,
+	0x6b, 0x75, 0x70, 0x77, 0x61, 0x74, 0x63, 0x68, 0x2f, 0x76, 0x31, 0x3b, 0x6c, 0x6f, 0x6f, 0x6b,
+	0x75, 0x70, 0x77, 0x61, 0x74, 0x63, 0x68, 0x76, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x33,
+}
+
+var (
+	file_lookupwatch_v1_lookupwatch_proto_rawDescOnce sync.Once
+	file_lookupwatch_v1_lookupwatch_proto_rawDescData = file_lookupwatch_v1_lookupwatch_proto_rawDesc
+)
+
+func file_lookupwatch_v1_lookupwatch_proto_rawDescGZIP() []byte {
+	file_lookupwatch_v1_lookupwatch_proto_rawDescOnce.Do(func() {
+		file_lookupwatch_v1_lookupwatch_proto_rawDescData = protoimpl.X.CompressGZIP(file_lookupwatch_v1_lookupwatch_proto_rawDescData)
+	})
+	return file_lookupwatch_v1_lookupwatch_proto_rawDescData
+}
+
+var file_lookupwatch_v1_lookupwatch_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_lookupwatch_v1_lookupwatch_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_lookupwatch_v1_lookupwatch_proto_goTypes = []any{
+	(ResourceChange_ChangeType)(0),       // 0: lookupwatch.v1.ResourceChange.ChangeType
+	(*WatchLookupResourcesRequest)(nil),  // 1: lookupwatch.v1.WatchLookupResourcesRequest
+	(*WatchLookupResourcesResponse)(nil), // 2: lookupwatch.v1.WatchLookupResourcesResponse
+	(*ResourceChange)(nil),               // 3: lookupwatch.v1.ResourceChange
+	(*v1.SubjectReference)(nil),          // 4: authzed.api.v1.SubjectReference
+	(*structpb.Struct)(nil),              // 5: google.protobuf.Struct
+	(*v1.ZedToken)(nil),                  // 6: authzed.api.v1.ZedToken
+	(v1.LookupPermissionship)(0),         // 7: authzed.api.v1.LookupPermissionship
+}
+var file_lookupwatch_v1_lookupwatch_proto_depIdxs = []int32{
+	4, // 0: lookupwatch.v1.WatchLookupResourcesRequest.subject:type_name -> authzed.api.v1.SubjectReference
+	5, // 1: lookupwatch.v1.WatchLookupResourcesRequest.context:type_name -> google.protobuf.Struct
+	6, // 2: lookupwatch.v1.WatchLookupResourcesRequest.optional_start_cursor:type_name -> authzed.api.v1.ZedToken
+	3, // 3: lookupwatch.v1.WatchLookupResourcesResponse.changes:type_name -> lookupwatch.v1.ResourceChange
+	6, // 4: lookupwatch.v1.WatchLookupResourcesResponse.changes_through:type_name -> authzed.api.v1.ZedToken
+	0, // 5: lookupwatch.v1.ResourceChange.type:type_name -> lookupwatch.v1.ResourceChange.ChangeType
+	7, // 6: lookupwatch.v1.ResourceChange.permissionship:type_name -> authzed.api.v1.LookupPermissionship
+	1, // 7: lookupwatch.v1.LookupWatchService.WatchLookupResources:input_type -> lookupwatch.v1.WatchLookupResourcesRequest
+	2, // 8: lookupwatch.v1.LookupWatchService.WatchLookupResources:output_type -> lookupwatch.v1.WatchLookupResourcesResponse
+	8, // [8:9] is the sub-list for method output_type
+	7, // [7:8] is the sub-list for method input_type
+	7, // [7:7] is the sub-list for extension type_name
+	7, // [7:7] is the sub-list for extension extendee
+	0, // [0:7] is the sub-list for field type_name
+}
+
+func init() { file_lookupwatch_v1_lookupwatch_proto_init() }
+func file_lookupwatch_v1_lookupwatch_proto_init() {
+	if File_lookupwatch_v1_lookupwatch_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_lookupwatch_v1_lookupwatch_proto_msgTypes[0].Exporter = func(v any, i int) any {
+			switch v := v.(*WatchLookupResourcesRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_lookupwatch_v1_lookupwatch_proto_msgTypes[1].Exporter = func(v any, i int) any {
+			switch v := v.(*WatchLookupResourcesResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_lookupwatch_v1_lookupwatch_proto_msgTypes[2].Exporter = func(v any, i int) any {
+			switch v := v.(*ResourceChange); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_lookupwatch_v1_lookupwatch_proto_rawDesc,
+			NumEnums:      1,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_lookupwatch_v1_lookupwatch_proto_goTypes,
+		DependencyIndexes: file_lookupwatch_v1_lookupwatch_proto_depIdxs,
+		EnumInfos:         file_lookupwatch_v1_lookupwatch_proto_enumTypes,
+		MessageInfos:      file_lookupwatch_v1_lookupwatch_proto_msgTypes,
+	}.Build()
+	File_lookupwatch_v1_lookupwatch_proto = out.File
+	file_lookupwatch_v1_lookupwatch_proto_rawDesc = nil
+	file_lookupwatch_v1_lookupwatch_proto_goTypes = nil
+	file_lookupwatch_v1_lookupwatch_proto_depIdxs = nil
+}
@@ -0,0 +1,510 @@
+// Code generated by protoc-gen-validate. DO NOT EDIT.
+// source: lookupwatch/v1/lookupwatch.proto
+
+package lookupwatchv1
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"google.golang.org/protobuf/types/known/anypb"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+)
+
+// ensure the imports are used
+var (
+	_ = bytes.MinRead
+	_ = errors.New("")
+	_ = fmt.Print
+	_ = utf8.UTFMax
+	_ = (*regexp.Regexp)(nil)
+	_ = (*strings.Reader)(nil)
+	_ = net.IPv4len
+	_ = time.Duration(0)
+	_ = (*url.URL)(nil)
+	_ = (*mail.Address)(nil)
+	_ = anypb.Any{}
+	_ = sort.Sort
+
+	_ = v1.LookupPermissionship(0)
+)
+
+// Validate checks the field values on WatchLookupResourcesRequest with the
+// rules defined in the proto definition for this message. If any rules are
+// violated, the first error encountered is returned, or nil if there are no
+// violations.
+func (m *WatchLookupResourcesRequest) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on WatchLookupResourcesRequest with the
+// rules defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// WatchLookupResourcesRequestMultiError, or nil if none found.
+func (m *WatchLookupResourcesRequest) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *WatchLookupResourcesRequest) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for ResourceObjectType
+
+	// no validation rules for Permission
+
+	if all {
+		switch v := interface{}(m.GetSubject()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, WatchLookupResourcesRequestValidationError{
+					field:  "Subject",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, WatchLookupResourcesRequestValidationError{
+					field:  "Subject",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetSubject()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return WatchLookupResourcesRequestValidationError{
+				field:  "Subject",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if all {
+		switch v := interface{}(m.GetContext()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, WatchLookupResourcesRequestValidationError{
+					field:  "Context",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, WatchLookupResourcesRequestValidationError{
+					field:  "Context",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetContext()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return WatchLookupResourcesRequestValidationError{
+				field:  "Context",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if all {
+		switch v := interface{}(m.GetOptionalStartCursor()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, WatchLookupResourcesRequestValidationError{
+					field:  "OptionalStartCursor",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, WatchLookupResourcesRequestValidationError{
+					field:  "OptionalStartCursor",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetOptionalStartCursor()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return WatchLookupResourcesRequestValidationError{
+				field:  "OptionalStartCursor",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if len(errors) > 0 {
+		return WatchLookupResourcesRequestMultiError(errors)
+	}
+
+	return nil
+}
+
+// WatchLookupResourcesRequestMultiError is an error wrapping multiple
+// validation errors returned by WatchLookupResourcesRequest.ValidateAll() if
+// the designated constraints aren't met.
+type WatchLookupResourcesRequestMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m WatchLookupResourcesRequestMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m WatchLookupResourcesRequestMultiError) AllErrors() []error { return m }
+
+// WatchLookupResourcesRequestValidationError is the validation error returned
+// by WatchLookupResourcesRequest.Validate if the designated constraints aren't
+// met.
+type WatchLookupResourcesRequestValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e WatchLookupResourcesRequestValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e WatchLookupResourcesRequestValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e WatchLookupResourcesRequestValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e WatchLookupResourcesRequestValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e WatchLookupResourcesRequestValidationError) ErrorName() string {
+	return "WatchLookupResourcesRequestValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e WatchLookupResourcesRequestValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sWatchLookupResourcesRequest.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = WatchLookupResourcesRequestValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = WatchLookupResourcesRequestValidationError{}
+
+// Validate checks the field values on WatchLookupResourcesResponse with the
+// rules defined in the proto definition for this message. If any rules are
+// violated, the first error encountered is returned, or nil if there are no
+// violations.
+func (m *WatchLookupResourcesResponse) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on WatchLookupResourcesResponse with the
+// rules defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// WatchLookupResourcesResponseMultiError, or nil if none found.
+func (m *WatchLookupResourcesResponse) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *WatchLookupResourcesResponse) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Snapshot
+
+	for idx, item := range m.GetChanges() {
+		_, _ = idx, item
+
+		if all {
+			switch v := interface{}(item).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, WatchLookupResourcesResponseValidationError{
+						field:  fmt.Sprintf("Changes[%v]", idx),
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, WatchLookupResourcesResponseValidationError{
+						field:  fmt.Sprintf("Changes[%v]", idx),
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(item).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return WatchLookupResourcesResponseValidationError{
+					field:  fmt.Sprintf("Changes[%v]", idx),
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	}
+
+	if all {
+		switch v := interface{}(m.GetChangesThrough()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, WatchLookupResourcesResponseValidationError{
+					field:  "ChangesThrough",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, WatchLookupResourcesResponseValidationError{
+					field:  "ChangesThrough",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetChangesThrough()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return WatchLookupResourcesResponseValidationError{
+				field:  "ChangesThrough",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if len(errors) > 0 {
+		return WatchLookupResourcesResponseMultiError(errors)
+	}
+
+	return nil
+}
+
+// WatchLookupResourcesResponseMultiError is an error wrapping multiple
+// validation errors returned by WatchLookupResourcesResponse.ValidateAll() if
+// the designated constraints aren't met.
+type WatchLookupResourcesResponseMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m WatchLookupResourcesResponseMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m WatchLookupResourcesResponseMultiError) AllErrors() []error { return m }
+
+// WatchLookupResourcesResponseValidationError is the validation error returned
+// by WatchLookupResourcesResponse.Validate if the designated constraints aren't
+// met.
+type WatchLookupResourcesResponseValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e WatchLookupResourcesResponseValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e WatchLookupResourcesResponseValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e WatchLookupResourcesResponseValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e WatchLookupResourcesResponseValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e WatchLookupResourcesResponseValidationError) ErrorName() string {
+	return "WatchLookupResourcesResponseValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e WatchLookupResourcesResponseValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sWatchLookupResourcesResponse.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = WatchLookupResourcesResponseValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = WatchLookupResourcesResponseValidationError{}
+
+// Validate checks the field values on ResourceChange with the rules defined in
+// the proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *ResourceChange) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on ResourceChange with the rules defined
+// in the proto definition for this message. If any rules are violated, the
+// result is a list of violation errors wrapped in ResourceChangeMultiError, or
+// nil if none found.
+func (m *ResourceChange) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *ResourceChange) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Type
+
+	// no validation rules for ResourceObjectId
+
+	// no validation rules for Permissionship
+
+	if len(errors) > 0 {
+		return ResourceChangeMultiError(errors)
+	}
+
+	return nil
+}
+
+// ResourceChangeMultiError is an error wrapping multiple validation errors
+// returned by ResourceChange.ValidateAll() if the designated constraints aren't
+// met.
+type ResourceChangeMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m ResourceChangeMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m ResourceChangeMultiError) AllErrors() []error { return m }
+
+// ResourceChangeValidationError is the validation error returned by
+// ResourceChange.Validate if the designated constraints aren't met.
+type ResourceChangeValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e ResourceChangeValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e ResourceChangeValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e ResourceChangeValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e ResourceChangeValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e ResourceChangeValidationError) ErrorName() string { return "ResourceChangeValidationError" }
+
+// Error satisfies the builtin error interface
+func (e ResourceChangeValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sResourceChange.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = ResourceChangeValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = ResourceChangeValidationError{}
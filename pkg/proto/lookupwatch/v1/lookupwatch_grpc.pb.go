@@ -0,0 +1,140 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: lookupwatch/v1/lookupwatch.proto
+
+package lookupwatchv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	LookupWatchService_WatchLookupResources_FullMethodName = "/lookupwatch.v1.LookupWatchService/WatchLookupResources"
+)
+
+// LookupWatchServiceClient is the client API for LookupWatchService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type LookupWatchServiceClient interface {
+	// WatchLookupResources streams the resources of a type on which a subject has a permission: first
+	// all of them, then the incremental additions and removals as relationships and the schema change.
+	WatchLookupResources(ctx context.Context, in *WatchLookupResourcesRequest, opts ...grpc.CallOption) (LookupWatchService_WatchLookupResourcesClient, error)
+}
+
+type lookupWatchServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewLookupWatchServiceClient(cc grpc.ClientConnInterface) LookupWatchServiceClient {
+	return &lookupWatchServiceClient{cc}
+}
+
+func (c *lookupWatchServiceClient) WatchLookupResources(ctx context.Context, in *WatchLookupResourcesRequest, opts ...grpc.CallOption) (LookupWatchService_WatchLookupResourcesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &LookupWatchService_ServiceDesc.Streams[0], LookupWatchService_WatchLookupResources_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &lookupWatchServiceWatchLookupResourcesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type LookupWatchService_WatchLookupResourcesClient interface {
+	Recv() (*WatchLookupResourcesResponse, error)
+	grpc.ClientStream
+}
+
+type lookupWatchServiceWatchLookupResourcesClient struct {
+	grpc.ClientStream
+}
+
+func (x *lookupWatchServiceWatchLookupResourcesClient) Recv() (*WatchLookupResourcesResponse, error) {
+	m := new(WatchLookupResourcesResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// LookupWatchServiceServer is the server API for LookupWatchService service.
+// All implementations must embed UnimplementedLookupWatchServiceServer
+// for forward compatibility
+type LookupWatchServiceServer interface {
+	// WatchLookupResources streams the resources of a type on which a subject has a permission: first
+	// all of them, then the incremental additions and removals as relationships and the schema change.
+	WatchLookupResources(*WatchLookupResourcesRequest, LookupWatchService_WatchLookupResourcesServer) error
+	mustEmbedUnimplementedLookupWatchServiceServer()
+}
+
+// UnimplementedLookupWatchServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedLookupWatchServiceServer struct {
+}
+
+func (UnimplementedLookupWatchServiceServer) WatchLookupResources(*WatchLookupResourcesRequest, LookupWatchService_WatchLookupResourcesServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchLookupResources not implemented")
+}
+func (UnimplementedLookupWatchServiceServer) mustEmbedUnimplementedLookupWatchServiceServer() {}
+
+// UnsafeLookupWatchServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to LookupWatchServiceServer will
+// result in compilation errors.
+type UnsafeLookupWatchServiceServer interface {
+	mustEmbedUnimplementedLookupWatchServiceServer()
+}
+
+func RegisterLookupWatchServiceServer(s grpc.ServiceRegistrar, srv LookupWatchServiceServer) {
+	s.RegisterService(&LookupWatchService_ServiceDesc, srv)
+}
+
+func _LookupWatchService_WatchLookupResources_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchLookupResourcesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(LookupWatchServiceServer).WatchLookupResources(m, &lookupWatchServiceWatchLookupResourcesServer{stream})
+}
+
+type LookupWatchService_WatchLookupResourcesServer interface {
+	Send(*WatchLookupResourcesResponse) error
+	grpc.ServerStream
+}
+
+type lookupWatchServiceWatchLookupResourcesServer struct {
+	grpc.ServerStream
+}
+
+func (x *lookupWatchServiceWatchLookupResourcesServer) Send(m *WatchLookupResourcesResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// LookupWatchService_ServiceDesc is the grpc.ServiceDesc for LookupWatchService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var LookupWatchService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "lookupwatch.v1.LookupWatchService",
+	HandlerType: (*LookupWatchServiceServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchLookupResources",
+			Handler:       _LookupWatchService_WatchLookupResources_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "lookupwatch/v1/lookupwatch.proto",
+}
@@ -0,0 +1,301 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.2
+// 	protoc        (unknown)
+// source: reconciliation/v1/reconciliation.proto
+
+package reconciliationv1
+
+import (
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// ApplyRelationshipsSnapshotRequest is the request to reconcile the relationships within a scope.
+type ApplyRelationshipsSnapshotRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// relationship_filter is the scope of the snapshot. Relationships matching it but missing from the
+	// snapshot are deleted. The resource type of the filter is required.
+	RelationshipFilter *v1.RelationshipFilter `protobuf:"bytes,1,opt,name=relationship_filter,json=relationshipFilter,proto3" json:"relationship_filter,omitempty"`
+	// relationships are the desired relationships within the scope, each of which must match the
+	// filter.
+	Relationships []*v1.Relationship `protobuf:"bytes,2,rep,name=relationships,proto3" json:"relationships,omitempty"`
+}
+
+func (x *ApplyRelationshipsSnapshotRequest) Reset() {
+	*x = ApplyRelationshipsSnapshotRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_reconciliation_v1_reconciliation_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ApplyRelationshipsSnapshotRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApplyRelationshipsSnapshotRequest) ProtoMessage() {}
+
+func (x *ApplyRelationshipsSnapshotRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_reconciliation_v1_reconciliation_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApplyRelationshipsSnapshotRequest.ProtoReflect.Descriptor instead.
+func (*ApplyRelationshipsSnapshotRequest) Descriptor() ([]byte, []int) {
+	return file_reconciliation_v1_reconciliation_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ApplyRelationshipsSnapshotRequest) GetRelationshipFilter() *v1.RelationshipFilter {
+	if x != nil {
+		return x.RelationshipFilter
+	}
+	return nil
+}
+
+func (x *ApplyRelationshipsSnapshotRequest) GetRelationships() []*v1.Relationship {
+	if x != nil {
+		return x.Relationships
+	}
+	return nil
+}
+
+// ApplyRelationshipsSnapshotResponse is the result of reconciling the relationships within a scope.
+type ApplyRelationshipsSnapshotResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// written_at is the revision at which the relationships within the scope match the snapshot.
+	WrittenAt *v1.ZedToken `protobuf:"bytes,1,opt,name=written_at,json=writtenAt,proto3" json:"written_at,omitempty"`
+	// relationships_created is the number of relationships of the snapshot which did not exist.
+	RelationshipsCreated uint64 `protobuf:"varint,2,opt,name=relationships_created,json=relationshipsCreated,proto3" json:"relationships_created,omitempty"`
+	// relationships_updated is the number of existing relationships whose caveat or expiration was
+	// changed to that of the snapshot.
+	RelationshipsUpdated uint64 `protobuf:"varint,3,opt,name=relationships_updated,json=relationshipsUpdated,proto3" json:"relationships_updated,omitempty"`
+	// relationships_deleted is the number of existing relationships within the scope which were
+	// missing from the snapshot.
+	RelationshipsDeleted uint64 `protobuf:"varint,4,opt,name=relationships_deleted,json=relationshipsDeleted,proto3" json:"relationships_deleted,omitempty"`
+}
+
+func (x *ApplyRelationshipsSnapshotResponse) Reset() {
+	*x = ApplyRelationshipsSnapshotResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_reconciliation_v1_reconciliation_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ApplyRelationshipsSnapshotResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApplyRelationshipsSnapshotResponse) ProtoMessage() {}
+
+func (x *ApplyRelationshipsSnapshotResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_reconciliation_v1_reconciliation_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApplyRelationshipsSnapshotResponse.ProtoReflect.Descriptor instead.
+func (*ApplyRelationshipsSnapshotResponse) Descriptor() ([]byte, []int) {
+	return file_reconciliation_v1_reconciliation_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ApplyRelationshipsSnapshotResponse) GetWrittenAt() *v1.ZedToken {
+	if x != nil {
+		return x.WrittenAt
+	}
+	return nil
+}
+
+func (x *ApplyRelationshipsSnapshotResponse) GetRelationshipsCreated() uint64 {
+	if x != nil {
+		return x.RelationshipsCreated
+	}
+	return 0
+}
+
+func (x *ApplyRelationshipsSnapshotResponse) GetRelationshipsUpdated() uint64 {
+	if x != nil {
+		return x.RelationshipsUpdated
+	}
+	return 0
+}
+
+func (x *ApplyRelationshipsSnapshotResponse) GetRelationshipsDeleted() uint64 {
+	if x != nil {
+		return x.RelationshipsDeleted
+	}
+	return 0
+}
+
+var File_reconciliation_v1_reconciliation_proto protoreflect.FileDescriptor
+
+var file_reconciliation_v1_reconciliation_proto_rawDesc = []byte{
+	0x0a, 0x26, 0x72, 0x65, 0x63, 0x6f, 0x6e, 0x63, 0x69, 0x6c, 0x69, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x2f, 0x76, 0x31, 0x2f, 0x72, 0x65, 0x63, 0x6f, 0x6e, 0x63, 0x69, 0x6c, 0x69, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x11, 0x72, 0x65, 0x63, 0x6f, 0x6e, 0x63,
+	0x69, 0x6c, 0x69, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x1a, 0x19, 0x61, 0x75, 0x74,
+	0x68, 0x7a, 0x65, 0x64, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x76, 0x31, 0x2f, 0x63, 0x6f, 0x72, 0x65,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x27, 0x61, 0x75, 0x74, 0x68, 0x7a, 0x65, 0x64, 0x2f,
+	0x61, 0x70, 0x69, 0x2f, 0x76, 0x31, 0x2f, 0x70, 0x65, 0x72, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6f,
+	0x6e, 0x5f, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22,
+	0xbc, 0x01, 0x0a, 0x21, 0x41, 0x70, 0x70, 0x6c, 0x79, 0x52, 0x65, 0x6c, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x73, 0x68, 0x69, 0x70, 0x73, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x53, 0x0a, 0x13, 0x72, 0x65, 0x6c, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x73, 0x68, 0x69, 0x70, 0x5f, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x22, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x7a, 0x65, 0x64, 0x2e, 0x61, 0x70, 0x69,
+	0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x68, 0x69, 0x70,
+	0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x52, 0x12, 0x72, 0x65, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x73, 0x68, 0x69, 0x70, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x12, 0x42, 0x0a, 0x0d, 0x72, 0x65,
+	0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x68, 0x69, 0x70, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x1c, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x7a, 0x65, 0x64, 0x2e, 0x61, 0x70, 0x69, 0x2e,
+	0x76, 0x31, 0x2e, 0x52, 0x65, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x68, 0x69, 0x70, 0x52,
+	0x0d, 0x72, 0x65, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x68, 0x69, 0x70, 0x73, 0x22, 0xfc,
+	0x01, 0x0a, 0x22, 0x41, 0x70, 0x70, 0x6c, 0x79, 0x52, 0x65, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x73, 0x68, 0x69, 0x70, 0x73, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x37, 0x0a, 0x0a, 0x77, 0x72, 0x69, 0x74, 0x74, 0x65, 0x6e,
+	0x5f, 0x61, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x61, 0x75, 0x74, 0x68,
+	0x7a, 0x65, 0x64, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x76, 0x31, 0x2e, 0x5a, 0x65, 0x64, 0x54, 0x6f,
+	0x6b, 0x65, 0x6e, 0x52, 0x09, 0x77, 0x72, 0x69, 0x74, 0x74, 0x65, 0x6e, 0x41, 0x74, 0x12, 0x33,
+	0x0a, 0x15, 0x72, 0x65, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x68, 0x69, 0x70, 0x73, 0x5f,
+	0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x14, 0x72,
+	0x65, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x68, 0x69, 0x70, 0x73, 0x43, 0x72, 0x65, 0x61,
+	0x74, 0x65, 0x64, 0x12, 0x33, 0x0a, 0x15, 0x72, 0x65, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73,
+	0x68, 0x69, 0x70, 0x73, 0x5f, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x04, 0x52, 0x14, 0x72, 0x65, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x68, 0x69, 0x70,
+	0x73, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x12, 0x33, 0x0a, 0x15, 0x72, 0x65, 0x6c, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x73, 0x68, 0x69, 0x70, 0x73, 0x5f, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65,
+	0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x14, 0x72, 0x65, 0x6c, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x73, 0x68, 0x69, 0x70, 0x73, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x32, 0xa5, 0x01,
+	0x0a, 0x15, 0x52, 0x65, 0x63, 0x6f, 0x6e, 0x63, 0x69, 0x6c, 0x69, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x8b, 0x01, 0x0a, 0x1a, 0x41, 0x70, 0x70, 0x6c,
+	0x79, 0x52, 0x65, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x68, 0x69, 0x70, 0x73, 0x53, 0x6e,
+	0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x12, 0x34, 0x2e, 0x72, 0x65, 0x63, 0x6f, 0x6e, 0x63, 0x69,
+	0x6c, 0x69, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x70, 0x70, 0x6c, 0x79,
+	0x52, 0x65, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x68, 0x69, 0x70, 0x73, 0x53, 0x6e, 0x61,
+	0x70, 0x73, 0x68, 0x6f, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x35, 0x2e, 0x72,
+	0x65, 0x63, 0x6f, 0x6e, 0x63, 0x69, 0x6c, 0x69, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31,
+	0x2e, 0x41, 0x70, 0x70, 0x6c, 0x79, 0x52, 0x65, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x68,
+	0x69, 0x70, 0x73, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x22, 0x00, 0x42, 0x49, 0x5a, 0x47, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e,
+	0x63, 0x6f, 0x6d, 0x2f, 0x61, 0x75, 0x74, 0x68, 0x7a, 0x65, 0x64, 0x2f, 0x73, 0x70, 0x69, 0x63,
+	0x65, 0x64, 0x62, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x72, 0x65,
+	0x63, 0x6f, 0x6e, 0x63, 0x69, 0x6c, 0x69, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2f, 0x76, 0x31, 0x3b,
+	0x72, 0x65, 0x63, 0x6f, 0x6e, 0x63, 0x69, 0x6c, 0x69, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x76, 0x31,
+	0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_reconciliation_v1_reconciliation_proto_rawDescOnce sync.Once
+	file_reconciliation_v1_reconciliation_proto_rawDescData = file_reconciliation_v1_reconciliation_proto_rawDesc
+)
+
+func file_reconciliation_v1_reconciliation_proto_rawDescGZIP() []byte {
+	file_reconciliation_v1_reconciliation_proto_rawDescOnce.Do(func() {
+		file_reconciliation_v1_reconciliation_proto_rawDescData = protoimpl.X.CompressGZIP(file_reconciliation_v1_reconciliation_proto_rawDescData)
+	})
+	return file_reconciliation_v1_reconciliation_proto_rawDescData
+}
+
+var file_reconciliation_v1_reconciliation_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_reconciliation_v1_reconciliation_proto_goTypes = []any{
+	(*ApplyRelationshipsSnapshotRequest)(nil),  // 0: reconciliation.v1.ApplyRelationshipsSnapshotRequest
+	(*ApplyRelationshipsSnapshotResponse)(nil), // 1: reconciliation.v1.ApplyRelationshipsSnapshotResponse
+	(*v1.RelationshipFilter)(nil),              // 2: authzed.api.v1.RelationshipFilter
+	(*v1.Relationship)(nil),                    // 3: authzed.api.v1.Relationship
+	(*v1.ZedToken)(nil),                        // 4: authzed.api.v1.ZedToken
+}
+var file_reconciliation_v1_reconciliation_proto_depIdxs = []int32{
+	2, // 0: reconciliation.v1.ApplyRelationshipsSnapshotRequest.relationship_filter:type_name -> authzed.api.v1.RelationshipFilter
+	3, // 1: reconciliation.v1.ApplyRelationshipsSnapshotRequest.relationships:type_name -> authzed.api.v1.Relationship
+	4, // 2: reconciliation.v1.ApplyRelationshipsSnapshotResponse.written_at:type_name -> authzed.api.v1.ZedToken
+	0, // 3: reconciliation.v1.ReconciliationService.ApplyRelationshipsSnapshot:input_type -> reconciliation.v1.ApplyRelationshipsSnapshotRequest
+	1, // 4: reconciliation.v1.ReconciliationService.ApplyRelationshipsSnapshot:output_type -> reconciliation.v1.ApplyRelationshipsSnapshotResponse
+	4, // [4:5] is the sub-list for method output_type
+	3, // [3:4] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_reconciliation_v1_reconciliation_proto_init() }
+func file_reconciliation_v1_reconciliation_proto_init() {
+	if File_reconciliation_v1_reconciliation_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_reconciliation_v1_reconciliation_proto_msgTypes[0].Exporter = func(v any, i int) any {
+			switch v := v.(*ApplyRelationshipsSnapshotRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_reconciliation_v1_reconciliation_proto_msgTypes[1].Exporter = func(v any, i int) any {
+			switch v := v.(*ApplyRelationshipsSnapshotResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_reconciliation_v1_reconciliation_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_reconciliation_v1_reconciliation_proto_goTypes,
+		DependencyIndexes: file_reconciliation_v1_reconciliation_proto_depIdxs,
+		MessageInfos:      file_reconciliation_v1_reconciliation_proto_msgTypes,
+	}.Build()
+	File_reconciliation_v1_reconciliation_proto = out.File
+	file_reconciliation_v1_reconciliation_proto_rawDesc = nil
+	file_reconciliation_v1_reconciliation_proto_goTypes = nil
+	file_reconciliation_v1_reconciliation_proto_depIdxs = nil
+}
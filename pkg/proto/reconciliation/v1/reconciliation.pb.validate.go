@@ -0,0 +1,343 @@
+// Code generated by protoc-gen-validate. DO NOT EDIT.
+// source: reconciliation/v1/reconciliation.proto
+
+package reconciliationv1
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// ensure the imports are used
+var (
+	_ = bytes.MinRead
+	_ = errors.New("")
+	_ = fmt.Print
+	_ = utf8.UTFMax
+	_ = (*regexp.Regexp)(nil)
+	_ = (*strings.Reader)(nil)
+	_ = net.IPv4len
+	_ = time.Duration(0)
+	_ = (*url.URL)(nil)
+	_ = (*mail.Address)(nil)
+	_ = anypb.Any{}
+	_ = sort.Sort
+)
+
+// Validate checks the field values on ApplyRelationshipsSnapshotRequest with
+// the rules defined in the proto definition for this message. If any rules are
+// violated, the first error encountered is returned, or nil if there are no
+// violations.
+func (m *ApplyRelationshipsSnapshotRequest) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on ApplyRelationshipsSnapshotRequest with
+// the rules defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// ApplyRelationshipsSnapshotRequestMultiError, or nil if none found.
+func (m *ApplyRelationshipsSnapshotRequest) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *ApplyRelationshipsSnapshotRequest) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if all {
+		switch v := interface{}(m.GetRelationshipFilter()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, ApplyRelationshipsSnapshotRequestValidationError{
+					field:  "RelationshipFilter",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, ApplyRelationshipsSnapshotRequestValidationError{
+					field:  "RelationshipFilter",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetRelationshipFilter()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return ApplyRelationshipsSnapshotRequestValidationError{
+				field:  "RelationshipFilter",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	for idx, item := range m.GetRelationships() {
+		_, _ = idx, item
+
+		if all {
+			switch v := interface{}(item).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, ApplyRelationshipsSnapshotRequestValidationError{
+						field:  fmt.Sprintf("Relationships[%v]", idx),
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, ApplyRelationshipsSnapshotRequestValidationError{
+						field:  fmt.Sprintf("Relationships[%v]", idx),
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(item).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return ApplyRelationshipsSnapshotRequestValidationError{
+					field:  fmt.Sprintf("Relationships[%v]", idx),
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	}
+
+	if len(errors) > 0 {
+		return ApplyRelationshipsSnapshotRequestMultiError(errors)
+	}
+
+	return nil
+}
+
+// ApplyRelationshipsSnapshotRequestMultiError is an error wrapping multiple
+// validation errors returned by ApplyRelationshipsSnapshotRequest.ValidateAll()
+// if the designated constraints aren't met.
+type ApplyRelationshipsSnapshotRequestMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m ApplyRelationshipsSnapshotRequestMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m ApplyRelationshipsSnapshotRequestMultiError) AllErrors() []error { return m }
+
+// ApplyRelationshipsSnapshotRequestValidationError is the validation error
+// returned by ApplyRelationshipsSnapshotRequest.Validate if the designated
+// constraints aren't met.
+type ApplyRelationshipsSnapshotRequestValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e ApplyRelationshipsSnapshotRequestValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e ApplyRelationshipsSnapshotRequestValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e ApplyRelationshipsSnapshotRequestValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e ApplyRelationshipsSnapshotRequestValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e ApplyRelationshipsSnapshotRequestValidationError) ErrorName() string {
+	return "ApplyRelationshipsSnapshotRequestValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e ApplyRelationshipsSnapshotRequestValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sApplyRelationshipsSnapshotRequest.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = ApplyRelationshipsSnapshotRequestValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = ApplyRelationshipsSnapshotRequestValidationError{}
+
+// Validate checks the field values on ApplyRelationshipsSnapshotResponse with
+// the rules defined in the proto definition for this message. If any rules are
+// violated, the first error encountered is returned, or nil if there are no
+// violations.
+func (m *ApplyRelationshipsSnapshotResponse) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on ApplyRelationshipsSnapshotResponse
+// with the rules defined in the proto definition for this message. If any rules
+// are violated, the result is a list of violation errors wrapped in
+// ApplyRelationshipsSnapshotResponseMultiError, or nil if none found.
+func (m *ApplyRelationshipsSnapshotResponse) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *ApplyRelationshipsSnapshotResponse) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if all {
+		switch v := interface{}(m.GetWrittenAt()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, ApplyRelationshipsSnapshotResponseValidationError{
+					field:  "WrittenAt",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, ApplyRelationshipsSnapshotResponseValidationError{
+					field:  "WrittenAt",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetWrittenAt()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return ApplyRelationshipsSnapshotResponseValidationError{
+				field:  "WrittenAt",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	// no validation rules for RelationshipsCreated
+
+	// no validation rules for RelationshipsUpdated
+
+	// no validation rules for RelationshipsDeleted
+
+	if len(errors) > 0 {
+		return ApplyRelationshipsSnapshotResponseMultiError(errors)
+	}
+
+	return nil
+}
+
+// ApplyRelationshipsSnapshotResponseMultiError is an error wrapping multiple
+// validation errors returned by
+// ApplyRelationshipsSnapshotResponse.ValidateAll() if the designated
+// constraints aren't met.
+type ApplyRelationshipsSnapshotResponseMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m ApplyRelationshipsSnapshotResponseMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m ApplyRelationshipsSnapshotResponseMultiError) AllErrors() []error { return m }
+
+// ApplyRelationshipsSnapshotResponseValidationError is the validation error
+// returned by ApplyRelationshipsSnapshotResponse.Validate if the designated
+// constraints aren't met.
+type ApplyRelationshipsSnapshotResponseValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e ApplyRelationshipsSnapshotResponseValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e ApplyRelationshipsSnapshotResponseValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e ApplyRelationshipsSnapshotResponseValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e ApplyRelationshipsSnapshotResponseValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e ApplyRelationshipsSnapshotResponseValidationError) ErrorName() string {
+	return "ApplyRelationshipsSnapshotResponseValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e ApplyRelationshipsSnapshotResponseValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sApplyRelationshipsSnapshotResponse.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = ApplyRelationshipsSnapshotResponseValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = ApplyRelationshipsSnapshotResponseValidationError{}
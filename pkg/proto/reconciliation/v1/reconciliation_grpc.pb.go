@@ -0,0 +1,113 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: reconciliation/v1/reconciliation.proto
+
+package reconciliationv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	ReconciliationService_ApplyRelationshipsSnapshot_FullMethodName = "/reconciliation.v1.ReconciliationService/ApplyRelationshipsSnapshot"
+)
+
+// ReconciliationServiceClient is the client API for ReconciliationService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ReconciliationServiceClient interface {
+	// ApplyRelationshipsSnapshot makes the relationships matching the filter exactly those of the
+	// snapshot, creating, updating and deleting the fewest relationships in a single transaction.
+	ApplyRelationshipsSnapshot(ctx context.Context, in *ApplyRelationshipsSnapshotRequest, opts ...grpc.CallOption) (*ApplyRelationshipsSnapshotResponse, error)
+}
+
+type reconciliationServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewReconciliationServiceClient(cc grpc.ClientConnInterface) ReconciliationServiceClient {
+	return &reconciliationServiceClient{cc}
+}
+
+func (c *reconciliationServiceClient) ApplyRelationshipsSnapshot(ctx context.Context, in *ApplyRelationshipsSnapshotRequest, opts ...grpc.CallOption) (*ApplyRelationshipsSnapshotResponse, error) {
+	out := new(ApplyRelationshipsSnapshotResponse)
+	err := c.cc.Invoke(ctx, ReconciliationService_ApplyRelationshipsSnapshot_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ReconciliationServiceServer is the server API for ReconciliationService service.
+// All implementations must embed UnimplementedReconciliationServiceServer
+// for forward compatibility
+type ReconciliationServiceServer interface {
+	// ApplyRelationshipsSnapshot makes the relationships matching the filter exactly those of the
+	// snapshot, creating, updating and deleting the fewest relationships in a single transaction.
+	ApplyRelationshipsSnapshot(context.Context, *ApplyRelationshipsSnapshotRequest) (*ApplyRelationshipsSnapshotResponse, error)
+	mustEmbedUnimplementedReconciliationServiceServer()
+}
+
+// UnimplementedReconciliationServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedReconciliationServiceServer struct {
+}
+
+func (UnimplementedReconciliationServiceServer) ApplyRelationshipsSnapshot(context.Context, *ApplyRelationshipsSnapshotRequest) (*ApplyRelationshipsSnapshotResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ApplyRelationshipsSnapshot not implemented")
+}
+func (UnimplementedReconciliationServiceServer) mustEmbedUnimplementedReconciliationServiceServer() {}
+
+// UnsafeReconciliationServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ReconciliationServiceServer will
+// result in compilation errors.
+type UnsafeReconciliationServiceServer interface {
+	mustEmbedUnimplementedReconciliationServiceServer()
+}
+
+func RegisterReconciliationServiceServer(s grpc.ServiceRegistrar, srv ReconciliationServiceServer) {
+	s.RegisterService(&ReconciliationService_ServiceDesc, srv)
+}
+
+func _ReconciliationService_ApplyRelationshipsSnapshot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ApplyRelationshipsSnapshotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReconciliationServiceServer).ApplyRelationshipsSnapshot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReconciliationService_ApplyRelationshipsSnapshot_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReconciliationServiceServer).ApplyRelationshipsSnapshot(ctx, req.(*ApplyRelationshipsSnapshotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ReconciliationService_ServiceDesc is the grpc.ServiceDesc for ReconciliationService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ReconciliationService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "reconciliation.v1.ReconciliationService",
+	HandlerType: (*ReconciliationServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ApplyRelationshipsSnapshot",
+			Handler:    _ReconciliationService_ApplyRelationshipsSnapshot_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "reconciliation/v1/reconciliation.proto",
+}
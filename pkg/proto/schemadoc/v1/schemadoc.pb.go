@@ -0,0 +1,623 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.2
+// 	protoc        (unknown)
+// source: schemadoc/v1/schemadoc.proto
+
+package schemadocv1
+
+import (
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// ReflectSchemaDocumentationRequest is the request to reflect the documentation of the schema.
+type ReflectSchemaDocumentationRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// consistency is the consistency at which the schema is read.
+	Consistency *v1.Consistency `protobuf:"bytes,1,opt,name=consistency,proto3" json:"consistency,omitempty"`
+	// optional_filters are the filters applied to the reflected schema, as in
+	// ExperimentalReflectSchema.
+	OptionalFilters []*v1.ExpSchemaFilter `protobuf:"bytes,2,rep,name=optional_filters,json=optionalFilters,proto3" json:"optional_filters,omitempty"`
+}
+
+func (x *ReflectSchemaDocumentationRequest) Reset() {
+	*x = ReflectSchemaDocumentationRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_schemadoc_v1_schemadoc_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReflectSchemaDocumentationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReflectSchemaDocumentationRequest) ProtoMessage() {}
+
+func (x *ReflectSchemaDocumentationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_schemadoc_v1_schemadoc_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReflectSchemaDocumentationRequest.ProtoReflect.Descriptor instead.
+func (*ReflectSchemaDocumentationRequest) Descriptor() ([]byte, []int) {
+	return file_schemadoc_v1_schemadoc_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ReflectSchemaDocumentationRequest) GetConsistency() *v1.Consistency {
+	if x != nil {
+		return x.Consistency
+	}
+	return nil
+}
+
+func (x *ReflectSchemaDocumentationRequest) GetOptionalFilters() []*v1.ExpSchemaFilter {
+	if x != nil {
+		return x.OptionalFilters
+	}
+	return nil
+}
+
+// ReflectSchemaDocumentationResponse contains the documentation of the schema.
+type ReflectSchemaDocumentationResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// definitions are the documented object definitions of the schema.
+	Definitions []*DefinitionDocumentation `protobuf:"bytes,1,rep,name=definitions,proto3" json:"definitions,omitempty"`
+	// caveats are the documented caveats of the schema.
+	Caveats []*CaveatDocumentation `protobuf:"bytes,2,rep,name=caveats,proto3" json:"caveats,omitempty"`
+	// read_at is the revision at which the schema was read.
+	ReadAt *v1.ZedToken `protobuf:"bytes,3,opt,name=read_at,json=readAt,proto3" json:"read_at,omitempty"`
+}
+
+func (x *ReflectSchemaDocumentationResponse) Reset() {
+	*x = ReflectSchemaDocumentationResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_schemadoc_v1_schemadoc_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReflectSchemaDocumentationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReflectSchemaDocumentationResponse) ProtoMessage() {}
+
+func (x *ReflectSchemaDocumentationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_schemadoc_v1_schemadoc_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReflectSchemaDocumentationResponse.ProtoReflect.Descriptor instead.
+func (*ReflectSchemaDocumentationResponse) Descriptor() ([]byte, []int) {
+	return file_schemadoc_v1_schemadoc_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ReflectSchemaDocumentationResponse) GetDefinitions() []*DefinitionDocumentation {
+	if x != nil {
+		return x.Definitions
+	}
+	return nil
+}
+
+func (x *ReflectSchemaDocumentationResponse) GetCaveats() []*CaveatDocumentation {
+	if x != nil {
+		return x.Caveats
+	}
+	return nil
+}
+
+func (x *ReflectSchemaDocumentationResponse) GetReadAt() *v1.ZedToken {
+	if x != nil {
+		return x.ReadAt
+	}
+	return nil
+}
+
+// DefinitionDocumentation is the documentation of an object definition.
+type DefinitionDocumentation struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// definition is the reflected object definition: its doc comment, its relations along with their
+	// subject types, and its permissions.
+	Definition *v1.ExpDefinition `protobuf:"bytes,1,opt,name=definition,proto3" json:"definition,omitempty"`
+	// annotations are the annotations of the doc comment of the definition.
+	Annotations []*Annotation `protobuf:"bytes,2,rep,name=annotations,proto3" json:"annotations,omitempty"`
+	// members are the annotations of the relations and permissions of the definition which have any.
+	Members []*MemberDocumentation `protobuf:"bytes,3,rep,name=members,proto3" json:"members,omitempty"`
+}
+
+func (x *DefinitionDocumentation) Reset() {
+	*x = DefinitionDocumentation{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_schemadoc_v1_schemadoc_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DefinitionDocumentation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DefinitionDocumentation) ProtoMessage() {}
+
+func (x *DefinitionDocumentation) ProtoReflect() protoreflect.Message {
+	mi := &file_schemadoc_v1_schemadoc_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DefinitionDocumentation.ProtoReflect.Descriptor instead.
+func (*DefinitionDocumentation) Descriptor() ([]byte, []int) {
+	return file_schemadoc_v1_schemadoc_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *DefinitionDocumentation) GetDefinition() *v1.ExpDefinition {
+	if x != nil {
+		return x.Definition
+	}
+	return nil
+}
+
+func (x *DefinitionDocumentation) GetAnnotations() []*Annotation {
+	if x != nil {
+		return x.Annotations
+	}
+	return nil
+}
+
+func (x *DefinitionDocumentation) GetMembers() []*MemberDocumentation {
+	if x != nil {
+		return x.Members
+	}
+	return nil
+}
+
+// MemberDocumentation holds the annotations of a relation or permission.
+type MemberDocumentation struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// name is the name of the relation or permission.
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// annotations are the annotations of the doc comment of the relation or permission.
+	Annotations []*Annotation `protobuf:"bytes,2,rep,name=annotations,proto3" json:"annotations,omitempty"`
+}
+
+func (x *MemberDocumentation) Reset() {
+	*x = MemberDocumentation{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_schemadoc_v1_schemadoc_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MemberDocumentation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MemberDocumentation) ProtoMessage() {}
+
+func (x *MemberDocumentation) ProtoReflect() protoreflect.Message {
+	mi := &file_schemadoc_v1_schemadoc_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MemberDocumentation.ProtoReflect.Descriptor instead.
+func (*MemberDocumentation) Descriptor() ([]byte, []int) {
+	return file_schemadoc_v1_schemadoc_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *MemberDocumentation) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *MemberDocumentation) GetAnnotations() []*Annotation {
+	if x != nil {
+		return x.Annotations
+	}
+	return nil
+}
+
+// CaveatDocumentation is the documentation of a caveat.
+type CaveatDocumentation struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// caveat is the reflected caveat: its doc comment, expression and the types of its parameters.
+	Caveat *v1.ExpCaveat `protobuf:"bytes,1,opt,name=caveat,proto3" json:"caveat,omitempty"`
+	// annotations are the annotations of the doc comment of the caveat.
+	Annotations []*Annotation `protobuf:"bytes,2,rep,name=annotations,proto3" json:"annotations,omitempty"`
+}
+
+func (x *CaveatDocumentation) Reset() {
+	*x = CaveatDocumentation{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_schemadoc_v1_schemadoc_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CaveatDocumentation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CaveatDocumentation) ProtoMessage() {}
+
+func (x *CaveatDocumentation) ProtoReflect() protoreflect.Message {
+	mi := &file_schemadoc_v1_schemadoc_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CaveatDocumentation.ProtoReflect.Descriptor instead.
+func (*CaveatDocumentation) Descriptor() ([]byte, []int) {
+	return file_schemadoc_v1_schemadoc_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CaveatDocumentation) GetCaveat() *v1.ExpCaveat {
+	if x != nil {
+		return x.Caveat
+	}
+	return nil
+}
+
+func (x *CaveatDocumentation) GetAnnotations() []*Annotation {
+	if x != nil {
+		return x.Annotations
+	}
+	return nil
+}
+
+// Annotation is a user-defined annotation of a doc comment: a line of the form `@key value`, such
+// as `@owner identity-team`.
+type Annotation struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// key is the key of the annotation, without the leading `@`.
+	Key string `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	// value is the rest of the line, if any, with surrounding whitespace trimmed.
+	Value string `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+}
+
+func (x *Annotation) Reset() {
+	*x = Annotation{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_schemadoc_v1_schemadoc_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Annotation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Annotation) ProtoMessage() {}
+
+func (x *Annotation) ProtoReflect() protoreflect.Message {
+	mi := &file_schemadoc_v1_schemadoc_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Annotation.ProtoReflect.Descriptor instead.
+func (*Annotation) Descriptor() ([]byte, []int) {
+	return file_schemadoc_v1_schemadoc_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Annotation) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *Annotation) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+var File_schemadoc_v1_schemadoc_proto protoreflect.FileDescriptor
+
+var file_schemadoc_v1_schemadoc_proto_rawDesc = []byte{
+	0x0a, 0x1c, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x64, 0x6f, 0x63, 0x2f, 0x76, 0x31, 0x2f, 0x73,
+	0x63, 0x68, 0x65, 0x6d, 0x61, 0x64, 0x6f, 0x63, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0c,
+	0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x64, 0x6f, 0x63, 0x2e, 0x76, 0x31, 0x1a, 0x19, 0x61, 0x75,
+	0x74, 0x68, 0x7a, 0x65, 0x64, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x76, 0x31, 0x2f, 0x63, 0x6f, 0x72,
+	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x29, 0x61, 0x75, 0x74, 0x68, 0x7a, 0x65, 0x64,
+	0x2f, 0x61, 0x70, 0x69, 0x2f, 0x76, 0x31, 0x2f, 0x65, 0x78, 0x70, 0x65, 0x72, 0x69, 0x6d, 0x65,
+	0x6e, 0x74, 0x61, 0x6c, 0x5f, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x1a, 0x27, 0x61, 0x75, 0x74, 0x68, 0x7a, 0x65, 0x64, 0x2f, 0x61, 0x70, 0x69, 0x2f,
+	0x76, 0x31, 0x2f, 0x70, 0x65, 0x72, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x5f, 0x73, 0x65,
+	0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xae, 0x01, 0x0a, 0x21,
+	0x52, 0x65, 0x66, 0x6c, 0x65, 0x63, 0x74, 0x53, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x44, 0x6f, 0x63,
+	0x75, 0x6d, 0x65, 0x6e, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x3d, 0x0a, 0x0b, 0x63, 0x6f, 0x6e, 0x73, 0x69, 0x73, 0x74, 0x65, 0x6e, 0x63, 0x79,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x7a, 0x65, 0x64,
+	0x2e, 0x61, 0x70, 0x69, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f, 0x6e, 0x73, 0x69, 0x73, 0x74, 0x65,
+	0x6e, 0x63, 0x79, 0x52, 0x0b, 0x63, 0x6f, 0x6e, 0x73, 0x69, 0x73, 0x74, 0x65, 0x6e, 0x63, 0x79,
+	0x12, 0x4a, 0x0a, 0x10, 0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x61, 0x6c, 0x5f, 0x66, 0x69, 0x6c,
+	0x74, 0x65, 0x72, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x61, 0x75, 0x74,
+	0x68, 0x7a, 0x65, 0x64, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x78, 0x70, 0x53,
+	0x63, 0x68, 0x65, 0x6d, 0x61, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x52, 0x0f, 0x6f, 0x70, 0x74,
+	0x69, 0x6f, 0x6e, 0x61, 0x6c, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x73, 0x22, 0xdd, 0x01, 0x0a,
+	0x22, 0x52, 0x65, 0x66, 0x6c, 0x65, 0x63, 0x74, 0x53, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x44, 0x6f,
+	0x63, 0x75, 0x6d, 0x65, 0x6e, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x47, 0x0a, 0x0b, 0x64, 0x65, 0x66, 0x69, 0x6e, 0x69, 0x74, 0x69, 0x6f,
+	0x6e, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x25, 0x2e, 0x73, 0x63, 0x68, 0x65, 0x6d,
+	0x61, 0x64, 0x6f, 0x63, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x66, 0x69, 0x6e, 0x69, 0x74, 0x69,
+	0x6f, 0x6e, 0x44, 0x6f, 0x63, 0x75, 0x6d, 0x65, 0x6e, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52,
+	0x0b, 0x64, 0x65, 0x66, 0x69, 0x6e, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x3b, 0x0a, 0x07,
+	0x63, 0x61, 0x76, 0x65, 0x61, 0x74, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x21, 0x2e,
+	0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x64, 0x6f, 0x63, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x61, 0x76,
+	0x65, 0x61, 0x74, 0x44, 0x6f, 0x63, 0x75, 0x6d, 0x65, 0x6e, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x52, 0x07, 0x63, 0x61, 0x76, 0x65, 0x61, 0x74, 0x73, 0x12, 0x31, 0x0a, 0x07, 0x72, 0x65, 0x61,
+	0x64, 0x5f, 0x61, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x61, 0x75, 0x74,
+	0x68, 0x7a, 0x65, 0x64, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x76, 0x31, 0x2e, 0x5a, 0x65, 0x64, 0x54,
+	0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x06, 0x72, 0x65, 0x61, 0x64, 0x41, 0x74, 0x22, 0xd1, 0x01, 0x0a,
+	0x17, 0x44, 0x65, 0x66, 0x69, 0x6e, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x44, 0x6f, 0x63, 0x75, 0x6d,
+	0x65, 0x6e, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x3d, 0x0a, 0x0a, 0x64, 0x65, 0x66, 0x69,
+	0x6e, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x61,
+	0x75, 0x74, 0x68, 0x7a, 0x65, 0x64, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x78,
+	0x70, 0x44, 0x65, 0x66, 0x69, 0x6e, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0a, 0x64, 0x65, 0x66,
+	0x69, 0x6e, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x3a, 0x0a, 0x0b, 0x61, 0x6e, 0x6e, 0x6f, 0x74,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x73,
+	0x63, 0x68, 0x65, 0x6d, 0x61, 0x64, 0x6f, 0x63, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x6e, 0x6e, 0x6f,
+	0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0b, 0x61, 0x6e, 0x6e, 0x6f, 0x74, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x73, 0x12, 0x3b, 0x0a, 0x07, 0x6d, 0x65, 0x6d, 0x62, 0x65, 0x72, 0x73, 0x18, 0x03,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x21, 0x2e, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x64, 0x6f, 0x63,
+	0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x65, 0x6d, 0x62, 0x65, 0x72, 0x44, 0x6f, 0x63, 0x75, 0x6d, 0x65,
+	0x6e, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x07, 0x6d, 0x65, 0x6d, 0x62, 0x65, 0x72, 0x73,
+	0x22, 0x65, 0x0a, 0x13, 0x4d, 0x65, 0x6d, 0x62, 0x65, 0x72, 0x44, 0x6f, 0x63, 0x75, 0x6d, 0x65,
+	0x6e, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x3a, 0x0a, 0x0b, 0x61,
+	0x6e, 0x6e, 0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x18, 0x2e, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x64, 0x6f, 0x63, 0x2e, 0x76, 0x31, 0x2e,
+	0x41, 0x6e, 0x6e, 0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0b, 0x61, 0x6e, 0x6e, 0x6f,
+	0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x22, 0x84, 0x01, 0x0a, 0x13, 0x43, 0x61, 0x76, 0x65,
+	0x61, 0x74, 0x44, 0x6f, 0x63, 0x75, 0x6d, 0x65, 0x6e, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12,
+	0x31, 0x0a, 0x06, 0x63, 0x61, 0x76, 0x65, 0x61, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x19, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x7a, 0x65, 0x64, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x76, 0x31,
+	0x2e, 0x45, 0x78, 0x70, 0x43, 0x61, 0x76, 0x65, 0x61, 0x74, 0x52, 0x06, 0x63, 0x61, 0x76, 0x65,
+	0x61, 0x74, 0x12, 0x3a, 0x0a, 0x0b, 0x61, 0x6e, 0x6e, 0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61,
+	0x64, 0x6f, 0x63, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x6e, 0x6e, 0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x52, 0x0b, 0x61, 0x6e, 0x6e, 0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x22, 0x34,
+	0x0a, 0x0a, 0x41, 0x6e, 0x6e, 0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x10, 0x0a, 0x03,
+	0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14,
+	0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76,
+	0x61, 0x6c, 0x75, 0x65, 0x32, 0xa0, 0x01, 0x0a, 0x1a, 0x53, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x44,
+	0x6f, 0x63, 0x75, 0x6d, 0x65, 0x6e, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x53, 0x65, 0x72, 0x76,
+	0x69, 0x63, 0x65, 0x12, 0x81, 0x01, 0x0a, 0x1a, 0x52, 0x65, 0x66, 0x6c, 0x65, 0x63, 0x74, 0x53,
+	0x63, 0x68, 0x65, 0x6d, 0x61, 0x44, 0x6f, 0x63, 0x75, 0x6d, 0x65, 0x6e, 0x74, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x12, 0x2f, 0x2e, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x64, 0x6f, 0x63, 0x2e, 0x76,
+	0x31, 0x2e, 0x52, 0x65, 0x66, 0x6c, 0x65, 0x63, 0x74, 0x53, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x44,
+	0x6f, 0x63, 0x75, 0x6d, 0x65, 0x6e, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x30, 0x2e, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x64, 0x6f, 0x63, 0x2e,
+	0x76, 0x31, 0x2e, 0x52, 0x65, 0x66, 0x6c, 0x65, 0x63, 0x74, 0x53, 0x63, 0x68, 0x65, 0x6d, 0x61,
+	0x44, 0x6f, 0x63, 0x75, 0x6d, 0x65, 0x6e, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x42, 0x3f, 0x5a, 0x3d, 0x67, 0x69, 0x74, 0x68, 0x75,
+	0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x61, 0x75, 0x74, 0x68, 0x7a, 0x65, 0x64, 0x2f, 0x73, 0x70,
+	0x69, 0x63, 0x65, 0x64, 0x62, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f,
+	0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x64, 0x6f, 0x63, 0x2f, 0x76, 0x31, 0x3b, 0x73, 0x63, 0x68,
+	0x65, 0x6d, 0x61, 0x64, 0x6f, 0x63, 0x76, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_schemadoc_v1_schemadoc_proto_rawDescOnce sync.Once
+	file_schemadoc_v1_schemadoc_proto_rawDescData = file_schemadoc_v1_schemadoc_proto_rawDesc
+)
+
+func file_schemadoc_v1_schemadoc_proto_rawDescGZIP() []byte {
+	file_schemadoc_v1_schemadoc_proto_rawDescOnce.Do(func() {
+		file_schemadoc_v1_schemadoc_proto_rawDescData = protoimpl.X.CompressGZIP(file_schemadoc_v1_schemadoc_proto_rawDescData)
+	})
+	return file_schemadoc_v1_schemadoc_proto_rawDescData
+}
+
+var file_schemadoc_v1_schemadoc_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_schemadoc_v1_schemadoc_proto_goTypes = []any{
+	(*ReflectSchemaDocumentationRequest)(nil),  // 0: schemadoc.v1.ReflectSchemaDocumentationRequest
+	(*ReflectSchemaDocumentationResponse)(nil), // 1: schemadoc.v1.ReflectSchemaDocumentationResponse
+	(*DefinitionDocumentation)(nil),            // 2: schemadoc.v1.DefinitionDocumentation
+	(*MemberDocumentation)(nil),                // 3: schemadoc.v1.MemberDocumentation
+	(*CaveatDocumentation)(nil),                // 4: schemadoc.v1.CaveatDocumentation
+	(*Annotation)(nil),                         // 5: schemadoc.v1.Annotation
+	(*v1.Consistency)(nil),                     // 6: authzed.api.v1.Consistency
+	(*v1.ExpSchemaFilter)(nil),                 // 7: authzed.api.v1.ExpSchemaFilter
+	(*v1.ZedToken)(nil),                        // 8: authzed.api.v1.ZedToken
+	(*v1.ExpDefinition)(nil),                   // 9: authzed.api.v1.ExpDefinition
+	(*v1.ExpCaveat)(nil),                       // 10: authzed.api.v1.ExpCaveat
+}
+var file_schemadoc_v1_schemadoc_proto_depIdxs = []int32{
+	6,  // 0: schemadoc.v1.ReflectSchemaDocumentationRequest.consistency:type_name -> authzed.api.v1.Consistency
+	7,  // 1: schemadoc.v1.ReflectSchemaDocumentationRequest.optional_filters:type_name -> authzed.api.v1.ExpSchemaFilter
+	2,  // 2: schemadoc.v1.ReflectSchemaDocumentationResponse.definitions:type_name -> schemadoc.v1.DefinitionDocumentation
+	4,  // 3: schemadoc.v1.ReflectSchemaDocumentationResponse.caveats:type_name -> schemadoc.v1.CaveatDocumentation
+	8,  // 4: schemadoc.v1.ReflectSchemaDocumentationResponse.read_at:type_name -> authzed.api.v1.ZedToken
+	9,  // 5: schemadoc.v1.DefinitionDocumentation.definition:type_name -> authzed.api.v1.ExpDefinition
+	5,  // 6: schemadoc.v1.DefinitionDocumentation.annotations:type_name -> schemadoc.v1.Annotation
+	3,  // 7: schemadoc.v1.DefinitionDocumentation.members:type_name -> schemadoc.v1.MemberDocumentation
+	5,  // 8: schemadoc.v1.MemberDocumentation.annotations:type_name -> schemadoc.v1.Annotation
+	10, // 9: schemadoc.v1.CaveatDocumentation.caveat:type_name -> authzed.api.v1.ExpCaveat
+	5,  // 10: schemadoc.v1.CaveatDocumentation.annotations:type_name -> schemadoc.v1.Annotation
+	0,  // 11: schemadoc.v1.SchemaDocumentationService.ReflectSchemaDocumentation:input_type -> schemadoc.v1.ReflectSchemaDocumentationRequest
+	1,  // 12: schemadoc.v1.SchemaDocumentationService.ReflectSchemaDocumentation:output_type -> schemadoc.v1.ReflectSchemaDocumentationResponse
+	12, // [12:13] is the sub-list for method output_type
+	11, // [11:12] is the sub-list for method input_type
+	11, // [11:11] is the sub-list for extension type_name
+	11, // [11:11] is the sub-list for extension extendee
+	0,  // [0:11] is the sub-list for field type_name
+}
+
+func init() { file_schemadoc_v1_schemadoc_proto_init() }
+func file_schemadoc_v1_schemadoc_proto_init() {
+	if File_schemadoc_v1_schemadoc_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_schemadoc_v1_schemadoc_proto_msgTypes[0].Exporter = func(v any, i int) any {
+			switch v := v.(*ReflectSchemaDocumentationRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_schemadoc_v1_schemadoc_proto_msgTypes[1].Exporter = func(v any, i int) any {
+			switch v := v.(*ReflectSchemaDocumentationResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_schemadoc_v1_schemadoc_proto_msgTypes[2].Exporter = func(v any, i int) any {
+			switch v := v.(*DefinitionDocumentation); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_schemadoc_v1_schemadoc_proto_msgTypes[3].Exporter = func(v any, i int) any {
+			switch v := v.(*MemberDocumentation); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_schemadoc_v1_schemadoc_proto_msgTypes[4].Exporter = func(v any, i int) any {
+			switch v := v.(*CaveatDocumentation); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_schemadoc_v1_schemadoc_proto_msgTypes[5].Exporter = func(v any, i int) any {
+			switch v := v.(*Annotation); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_schemadoc_v1_schemadoc_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   6,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_schemadoc_v1_schemadoc_proto_goTypes,
+		DependencyIndexes: file_schemadoc_v1_schemadoc_proto_depIdxs,
+		MessageInfos:      file_schemadoc_v1_schemadoc_proto_msgTypes,
+	}.Build()
+	File_schemadoc_v1_schemadoc_proto = out.File
+	file_schemadoc_v1_schemadoc_proto_rawDesc = nil
+	file_schemadoc_v1_schemadoc_proto_goTypes = nil
+	file_schemadoc_v1_schemadoc_proto_depIdxs = nil
+}
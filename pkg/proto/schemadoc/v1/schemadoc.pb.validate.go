@@ -0,0 +1,1010 @@
+// Code generated by protoc-gen-validate. DO NOT EDIT.
+// source: schemadoc/v1/schemadoc.proto
+
+package schemadocv1
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// ensure the imports are used
+var (
+	_ = bytes.MinRead
+	_ = errors.New("")
+	_ = fmt.Print
+	_ = utf8.UTFMax
+	_ = (*regexp.Regexp)(nil)
+	_ = (*strings.Reader)(nil)
+	_ = net.IPv4len
+	_ = time.Duration(0)
+	_ = (*url.URL)(nil)
+	_ = (*mail.Address)(nil)
+	_ = anypb.Any{}
+	_ = sort.Sort
+)
+
+// Validate checks the field values on ReflectSchemaDocumentationRequest with
+// the rules defined in the proto definition for this message. If any rules are
+// violated, the first error encountered is returned, or nil if there are no
+// violations.
+func (m *ReflectSchemaDocumentationRequest) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on ReflectSchemaDocumentationRequest with
+// the rules defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// ReflectSchemaDocumentationRequestMultiError, or nil if none found.
+func (m *ReflectSchemaDocumentationRequest) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *ReflectSchemaDocumentationRequest) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if all {
+		switch v := interface{}(m.GetConsistency()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, ReflectSchemaDocumentationRequestValidationError{
+					field:  "Consistency",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, ReflectSchemaDocumentationRequestValidationError{
+					field:  "Consistency",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetConsistency()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return ReflectSchemaDocumentationRequestValidationError{
+				field:  "Consistency",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	for idx, item := range m.GetOptionalFilters() {
+		_, _ = idx, item
+
+		if all {
+			switch v := interface{}(item).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, ReflectSchemaDocumentationRequestValidationError{
+						field:  fmt.Sprintf("OptionalFilters[%v]", idx),
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, ReflectSchemaDocumentationRequestValidationError{
+						field:  fmt.Sprintf("OptionalFilters[%v]", idx),
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(item).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return ReflectSchemaDocumentationRequestValidationError{
+					field:  fmt.Sprintf("OptionalFilters[%v]", idx),
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	}
+
+	if len(errors) > 0 {
+		return ReflectSchemaDocumentationRequestMultiError(errors)
+	}
+
+	return nil
+}
+
+// ReflectSchemaDocumentationRequestMultiError is an error wrapping multiple
+// validation errors returned by ReflectSchemaDocumentationRequest.ValidateAll()
+// if the designated constraints aren't met.
+type ReflectSchemaDocumentationRequestMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m ReflectSchemaDocumentationRequestMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m ReflectSchemaDocumentationRequestMultiError) AllErrors() []error { return m }
+
+// ReflectSchemaDocumentationRequestValidationError is the validation error
+// returned by ReflectSchemaDocumentationRequest.Validate if the designated
+// constraints aren't met.
+type ReflectSchemaDocumentationRequestValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e ReflectSchemaDocumentationRequestValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e ReflectSchemaDocumentationRequestValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e ReflectSchemaDocumentationRequestValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e ReflectSchemaDocumentationRequestValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e ReflectSchemaDocumentationRequestValidationError) ErrorName() string {
+	return "ReflectSchemaDocumentationRequestValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e ReflectSchemaDocumentationRequestValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sReflectSchemaDocumentationRequest.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = ReflectSchemaDocumentationRequestValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = ReflectSchemaDocumentationRequestValidationError{}
+
+// Validate checks the field values on ReflectSchemaDocumentationResponse with
+// the rules defined in the proto definition for this message. If any rules are
+// violated, the first error encountered is returned, or nil if there are no
+// violations.
+func (m *ReflectSchemaDocumentationResponse) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on ReflectSchemaDocumentationResponse
+// with the rules defined in the proto definition for this message. If any rules
+// are violated, the result is a list of violation errors wrapped in
+// ReflectSchemaDocumentationResponseMultiError, or nil if none found.
+func (m *ReflectSchemaDocumentationResponse) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *ReflectSchemaDocumentationResponse) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	for idx, item := range m.GetDefinitions() {
+		_, _ = idx, item
+
+		if all {
+			switch v := interface{}(item).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, ReflectSchemaDocumentationResponseValidationError{
+						field:  fmt.Sprintf("Definitions[%v]", idx),
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, ReflectSchemaDocumentationResponseValidationError{
+						field:  fmt.Sprintf("Definitions[%v]", idx),
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(item).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return ReflectSchemaDocumentationResponseValidationError{
+					field:  fmt.Sprintf("Definitions[%v]", idx),
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	}
+
+	for idx, item := range m.GetCaveats() {
+		_, _ = idx, item
+
+		if all {
+			switch v := interface{}(item).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, ReflectSchemaDocumentationResponseValidationError{
+						field:  fmt.Sprintf("Caveats[%v]", idx),
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, ReflectSchemaDocumentationResponseValidationError{
+						field:  fmt.Sprintf("Caveats[%v]", idx),
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(item).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return ReflectSchemaDocumentationResponseValidationError{
+					field:  fmt.Sprintf("Caveats[%v]", idx),
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	}
+
+	if all {
+		switch v := interface{}(m.GetReadAt()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, ReflectSchemaDocumentationResponseValidationError{
+					field:  "ReadAt",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, ReflectSchemaDocumentationResponseValidationError{
+					field:  "ReadAt",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetReadAt()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return ReflectSchemaDocumentationResponseValidationError{
+				field:  "ReadAt",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if len(errors) > 0 {
+		return ReflectSchemaDocumentationResponseMultiError(errors)
+	}
+
+	return nil
+}
+
+// ReflectSchemaDocumentationResponseMultiError is an error wrapping multiple
+// validation errors returned by
+// ReflectSchemaDocumentationResponse.ValidateAll() if the designated
+// constraints aren't met.
+type ReflectSchemaDocumentationResponseMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m ReflectSchemaDocumentationResponseMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m ReflectSchemaDocumentationResponseMultiError) AllErrors() []error { return m }
+
+// ReflectSchemaDocumentationResponseValidationError is the validation error
+// returned by ReflectSchemaDocumentationResponse.Validate if the designated
+// constraints aren't met.
+type ReflectSchemaDocumentationResponseValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e ReflectSchemaDocumentationResponseValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e ReflectSchemaDocumentationResponseValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e ReflectSchemaDocumentationResponseValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e ReflectSchemaDocumentationResponseValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e ReflectSchemaDocumentationResponseValidationError) ErrorName() string {
+	return "ReflectSchemaDocumentationResponseValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e ReflectSchemaDocumentationResponseValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sReflectSchemaDocumentationResponse.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = ReflectSchemaDocumentationResponseValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = ReflectSchemaDocumentationResponseValidationError{}
+
+// Validate checks the field values on DefinitionDocumentation with the rules
+// defined in the proto definition for this message. If any rules are violated,
+// the first error encountered is returned, or nil if there are no violations.
+func (m *DefinitionDocumentation) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on DefinitionDocumentation with the rules
+// defined in the proto definition for this message. If any rules are violated,
+// the result is a list of violation errors wrapped in
+// DefinitionDocumentationMultiError, or nil if none found.
+func (m *DefinitionDocumentation) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *DefinitionDocumentation) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if all {
+		switch v := interface{}(m.GetDefinition()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, DefinitionDocumentationValidationError{
+					field:  "Definition",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, DefinitionDocumentationValidationError{
+					field:  "Definition",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetDefinition()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return DefinitionDocumentationValidationError{
+				field:  "Definition",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	for idx, item := range m.GetAnnotations() {
+		_, _ = idx, item
+
+		if all {
+			switch v := interface{}(item).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, DefinitionDocumentationValidationError{
+						field:  fmt.Sprintf("Annotations[%v]", idx),
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, DefinitionDocumentationValidationError{
+						field:  fmt.Sprintf("Annotations[%v]", idx),
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(item).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return DefinitionDocumentationValidationError{
+					field:  fmt.Sprintf("Annotations[%v]", idx),
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	}
+
+	for idx, item := range m.GetMembers() {
+		_, _ = idx, item
+
+		if all {
+			switch v := interface{}(item).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, DefinitionDocumentationValidationError{
+						field:  fmt.Sprintf("Members[%v]", idx),
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, DefinitionDocumentationValidationError{
+						field:  fmt.Sprintf("Members[%v]", idx),
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(item).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return DefinitionDocumentationValidationError{
+					field:  fmt.Sprintf("Members[%v]", idx),
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	}
+
+	if len(errors) > 0 {
+		return DefinitionDocumentationMultiError(errors)
+	}
+
+	return nil
+}
+
+// DefinitionDocumentationMultiError is an error wrapping multiple validation
+// errors returned by DefinitionDocumentation.ValidateAll() if the designated
+// constraints aren't met.
+type DefinitionDocumentationMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m DefinitionDocumentationMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m DefinitionDocumentationMultiError) AllErrors() []error { return m }
+
+// DefinitionDocumentationValidationError is the validation error returned by
+// DefinitionDocumentation.Validate if the designated constraints aren't met.
+type DefinitionDocumentationValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e DefinitionDocumentationValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e DefinitionDocumentationValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e DefinitionDocumentationValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e DefinitionDocumentationValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e DefinitionDocumentationValidationError) ErrorName() string {
+	return "DefinitionDocumentationValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e DefinitionDocumentationValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sDefinitionDocumentation.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = DefinitionDocumentationValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = DefinitionDocumentationValidationError{}
+
+// Validate checks the field values on MemberDocumentation with the rules
+// defined in the proto definition for this message. If any rules are violated,
+// the first error encountered is returned, or nil if there are no violations.
+func (m *MemberDocumentation) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on MemberDocumentation with the rules
+// defined in the proto definition for this message. If any rules are violated,
+// the result is a list of violation errors wrapped in
+// MemberDocumentationMultiError, or nil if none found.
+func (m *MemberDocumentation) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *MemberDocumentation) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Name
+
+	for idx, item := range m.GetAnnotations() {
+		_, _ = idx, item
+
+		if all {
+			switch v := interface{}(item).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, MemberDocumentationValidationError{
+						field:  fmt.Sprintf("Annotations[%v]", idx),
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, MemberDocumentationValidationError{
+						field:  fmt.Sprintf("Annotations[%v]", idx),
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(item).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return MemberDocumentationValidationError{
+					field:  fmt.Sprintf("Annotations[%v]", idx),
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	}
+
+	if len(errors) > 0 {
+		return MemberDocumentationMultiError(errors)
+	}
+
+	return nil
+}
+
+// MemberDocumentationMultiError is an error wrapping multiple validation errors
+// returned by MemberDocumentation.ValidateAll() if the designated constraints
+// aren't met.
+type MemberDocumentationMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m MemberDocumentationMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m MemberDocumentationMultiError) AllErrors() []error { return m }
+
+// MemberDocumentationValidationError is the validation error returned by
+// MemberDocumentation.Validate if the designated constraints aren't met.
+type MemberDocumentationValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e MemberDocumentationValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e MemberDocumentationValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e MemberDocumentationValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e MemberDocumentationValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e MemberDocumentationValidationError) ErrorName() string {
+	return "MemberDocumentationValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e MemberDocumentationValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sMemberDocumentation.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = MemberDocumentationValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = MemberDocumentationValidationError{}
+
+// Validate checks the field values on CaveatDocumentation with the rules
+// defined in the proto definition for this message. If any rules are violated,
+// the first error encountered is returned, or nil if there are no violations.
+func (m *CaveatDocumentation) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on CaveatDocumentation with the rules
+// defined in the proto definition for this message. If any rules are violated,
+// the result is a list of violation errors wrapped in
+// CaveatDocumentationMultiError, or nil if none found.
+func (m *CaveatDocumentation) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *CaveatDocumentation) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if all {
+		switch v := interface{}(m.GetCaveat()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, CaveatDocumentationValidationError{
+					field:  "Caveat",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, CaveatDocumentationValidationError{
+					field:  "Caveat",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetCaveat()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return CaveatDocumentationValidationError{
+				field:  "Caveat",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	for idx, item := range m.GetAnnotations() {
+		_, _ = idx, item
+
+		if all {
+			switch v := interface{}(item).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, CaveatDocumentationValidationError{
+						field:  fmt.Sprintf("Annotations[%v]", idx),
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, CaveatDocumentationValidationError{
+						field:  fmt.Sprintf("Annotations[%v]", idx),
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(item).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return CaveatDocumentationValidationError{
+					field:  fmt.Sprintf("Annotations[%v]", idx),
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	}
+
+	if len(errors) > 0 {
+		return CaveatDocumentationMultiError(errors)
+	}
+
+	return nil
+}
+
+// CaveatDocumentationMultiError is an error wrapping multiple validation errors
+// returned by CaveatDocumentation.ValidateAll() if the designated constraints
+// aren't met.
+type CaveatDocumentationMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m CaveatDocumentationMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m CaveatDocumentationMultiError) AllErrors() []error { return m }
+
+// CaveatDocumentationValidationError is the validation error returned by
+// CaveatDocumentation.Validate if the designated constraints aren't met.
+type CaveatDocumentationValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e CaveatDocumentationValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e CaveatDocumentationValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e CaveatDocumentationValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e CaveatDocumentationValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e CaveatDocumentationValidationError) ErrorName() string {
+	return "CaveatDocumentationValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e CaveatDocumentationValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sCaveatDocumentation.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = CaveatDocumentationValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = CaveatDocumentationValidationError{}
+
+// Validate checks the field values on Annotation with the rules defined in the
+// proto definition for this message. If any rules are violated, the first error
+// encountered is returned, or nil if there are no violations.
+func (m *Annotation) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on Annotation with the rules defined in
+// the proto definition for this message. If any rules are violated, the result
+// is a list of violation errors wrapped in AnnotationMultiError, or nil if none
+// found.
+func (m *Annotation) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *Annotation) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Key
+
+	// no validation rules for Value
+
+	if len(errors) > 0 {
+		return AnnotationMultiError(errors)
+	}
+
+	return nil
+}
+
+// AnnotationMultiError is an error wrapping multiple validation errors returned
+// by Annotation.ValidateAll() if the designated constraints aren't met.
+type AnnotationMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m AnnotationMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m AnnotationMultiError) AllErrors() []error { return m }
+
+// AnnotationValidationError is the validation error returned by
+// Annotation.Validate if the designated constraints aren't met.
+type AnnotationValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e AnnotationValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e AnnotationValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e AnnotationValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e AnnotationValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e AnnotationValidationError) ErrorName() string { return "AnnotationValidationError" }
+
+// Error satisfies the builtin error interface
+func (e AnnotationValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sAnnotation.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = AnnotationValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = AnnotationValidationError{}
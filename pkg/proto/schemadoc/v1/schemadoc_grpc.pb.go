@@ -0,0 +1,114 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: schemadoc/v1/schemadoc.proto
+
+package schemadocv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	SchemaDocumentationService_ReflectSchemaDocumentation_FullMethodName = "/schemadoc.v1.SchemaDocumentationService/ReflectSchemaDocumentation"
+)
+
+// SchemaDocumentationServiceClient is the client API for SchemaDocumentationService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type SchemaDocumentationServiceClient interface {
+	// ReflectSchemaDocumentation reflects the schema like ExperimentalReflectSchema, along with the
+	// annotations of its definitions, relations, permissions and caveats.
+	ReflectSchemaDocumentation(ctx context.Context, in *ReflectSchemaDocumentationRequest, opts ...grpc.CallOption) (*ReflectSchemaDocumentationResponse, error)
+}
+
+type schemaDocumentationServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSchemaDocumentationServiceClient(cc grpc.ClientConnInterface) SchemaDocumentationServiceClient {
+	return &schemaDocumentationServiceClient{cc}
+}
+
+func (c *schemaDocumentationServiceClient) ReflectSchemaDocumentation(ctx context.Context, in *ReflectSchemaDocumentationRequest, opts ...grpc.CallOption) (*ReflectSchemaDocumentationResponse, error) {
+	out := new(ReflectSchemaDocumentationResponse)
+	err := c.cc.Invoke(ctx, SchemaDocumentationService_ReflectSchemaDocumentation_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SchemaDocumentationServiceServer is the server API for SchemaDocumentationService service.
+// All implementations must embed UnimplementedSchemaDocumentationServiceServer
+// for forward compatibility
+type SchemaDocumentationServiceServer interface {
+	// ReflectSchemaDocumentation reflects the schema like ExperimentalReflectSchema, along with the
+	// annotations of its definitions, relations, permissions and caveats.
+	ReflectSchemaDocumentation(context.Context, *ReflectSchemaDocumentationRequest) (*ReflectSchemaDocumentationResponse, error)
+	mustEmbedUnimplementedSchemaDocumentationServiceServer()
+}
+
+// UnimplementedSchemaDocumentationServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedSchemaDocumentationServiceServer struct {
+}
+
+func (UnimplementedSchemaDocumentationServiceServer) ReflectSchemaDocumentation(context.Context, *ReflectSchemaDocumentationRequest) (*ReflectSchemaDocumentationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReflectSchemaDocumentation not implemented")
+}
+func (UnimplementedSchemaDocumentationServiceServer) mustEmbedUnimplementedSchemaDocumentationServiceServer() {
+}
+
+// UnsafeSchemaDocumentationServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SchemaDocumentationServiceServer will
+// result in compilation errors.
+type UnsafeSchemaDocumentationServiceServer interface {
+	mustEmbedUnimplementedSchemaDocumentationServiceServer()
+}
+
+func RegisterSchemaDocumentationServiceServer(s grpc.ServiceRegistrar, srv SchemaDocumentationServiceServer) {
+	s.RegisterService(&SchemaDocumentationService_ServiceDesc, srv)
+}
+
+func _SchemaDocumentationService_ReflectSchemaDocumentation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReflectSchemaDocumentationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SchemaDocumentationServiceServer).ReflectSchemaDocumentation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SchemaDocumentationService_ReflectSchemaDocumentation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SchemaDocumentationServiceServer).ReflectSchemaDocumentation(ctx, req.(*ReflectSchemaDocumentationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// SchemaDocumentationService_ServiceDesc is the grpc.ServiceDesc for SchemaDocumentationService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var SchemaDocumentationService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "schemadoc.v1.SchemaDocumentationService",
+	HandlerType: (*SchemaDocumentationServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ReflectSchemaDocumentation",
+			Handler:    _SchemaDocumentationService_ReflectSchemaDocumentation_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "schemadoc/v1/schemadoc.proto",
+}
@@ -0,0 +1,172 @@
+// Package requestmeta defines the request metadata headers supported by SpiceDB in addition to
+// those defined by the requestmeta package of authzed-go, with which they can be set.
+package requestmeta
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/authzed/authzed-go/pkg/requestmeta"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	// RequestStrictWriteValidation, if specified in a WriteRelationships request header, asks
+	// SpiceDB to validate every update against the schema before failing, and to return an error
+	// listing each update which does not conform, with the subject types and caveats allowed by
+	// the schema.
+	// Value: `1`
+	RequestStrictWriteValidation requestmeta.BoolRequestMetadataHeaderKey = "io.spicedb.strictwritevalidation"
+
+	// RequestValidateWriteOnly, if specified in a WriteRelationships request header, asks SpiceDB
+	// to validate the updates and check the preconditions at the current revision without
+	// applying them. The returned WrittenAt is the revision at which the write was validated.
+	// Value: `1`
+	RequestValidateWriteOnly requestmeta.BoolRequestMetadataHeaderKey = "io.spicedb.validatewriteonly"
+
+	// RequestChunkedWrite, if specified in a WriteRelationships request header, allows the
+	// request to hold more updates than fit in a single transaction, up to the maximum configured
+	// for chunked writes. The updates are then applied in multiple transactions, each recording
+	// the prior state of its relationships in a journal from which the transactions already
+	// applied are rolled back should a later one fail. Readers may observe the intermediate
+	// states, and the write is only rolled back by the node serving it.
+	// Value: `1`
+	RequestChunkedWrite requestmeta.BoolRequestMetadataHeaderKey = "io.spicedb.chunkedwrite"
+)
+
+const (
+	// RequestRelationshipVersions, if specified in a ReadRelationships request header, asks
+	// SpiceDB to return the version token of each relationship read, in the
+	// RelationshipVersionsTrailerKey response trailer. So that the trailer remains small, the
+	// request must have a limit of at most 100 relationships, and further relationships are read
+	// from the cursor of the last one. Values which are not true booleans are ignored.
+	// Value: `1`
+	RequestRelationshipVersions requestmeta.BoolRequestMetadataHeaderKey = "io.spicedb.relationshipversions"
+
+	// RelationshipVersionsTrailerKey is the response trailer of ReadRelationships holding the
+	// version token of each relationship read when requested, in the order they were streamed,
+	// separated by commas.
+	RelationshipVersionsTrailerKey = "io.spicedb.relationship-versions"
+
+	// ExpectedRelationshipVersionHeaderKey is the WriteRelationships request header holding the
+	// expected versions of the relationships of its updates, as `<update index>=<version token>`.
+	// The write fails with FailedPrecondition, without applying any update, unless the
+	// relationship of each update with an expected version exists with that version, as returned
+	// by ReadRelationships. Since the version of a relationship covers the revision at which it
+	// was last written, it changes whenever the relationship is written, even back to a prior
+	// state.
+	ExpectedRelationshipVersionHeaderKey = "io.spicedb.expectedrelationshipversion"
+)
+
+const (
+	// RequestSchemaDryRun, if specified in a WriteSchema request header, asks SpiceDB to validate
+	// the schema and analyze the impact of writing it, without writing it. The impact is returned
+	// as JSON in the SchemaImpactResponseHeaderKey response header, and the returned WrittenAt is
+	// the revision at which it was analyzed.
+	// Value: `1`
+	RequestSchemaDryRun requestmeta.BoolRequestMetadataHeaderKey = "io.spicedb.schemadryrun"
+
+	// SchemaImpactResponseHeaderKey is the response header of a dry-run WriteSchema holding the
+	// impact of writing the schema, as JSON: whether it can be written, the existing relationships
+	// it would invalidate, and the permissions whose reachable relations would change.
+	SchemaImpactResponseHeaderKey = "io.spicedb.schema-impact"
+
+	// RequestSchemaBlockingRelationshipCounts, if specified in a WriteSchema request header, asks
+	// SpiceDB, when the schema cannot be written as existing relationships would no longer conform
+	// to it, to count the relationships blocking each change and return the exact counts in the
+	// details of the error.
+	// Value: `1`
+	RequestSchemaBlockingRelationshipCounts requestmeta.BoolRequestMetadataHeaderKey = "io.spicedb.schemablockingrelationshipcounts"
+
+	// RequestSchemaDeleteBlockingRelationships, if specified in a WriteSchema request header, asks
+	// SpiceDB to delete the existing relationships which would no longer conform to the schema,
+	// in the transaction writing it, such that either the relationships are deleted and the
+	// schema is written, or neither is. The number of relationships deleted is returned in the
+	// SchemaDeletedRelationshipsResponseHeaderKey response header.
+	// Value: `1`
+	RequestSchemaDeleteBlockingRelationships requestmeta.BoolRequestMetadataHeaderKey = "io.spicedb.schemadeleteblockingrelationships"
+
+	// SchemaDeletedRelationshipsResponseHeaderKey is the response header of WriteSchema holding
+	// the number of relationships deleted while writing the schema, when requested.
+	SchemaDeletedRelationshipsResponseHeaderKey = "io.spicedb.schema-deleted-relationships"
+)
+
+const (
+	// RequestCheckReason, if specified in a CheckPermission request header, asks SpiceDB to
+	// return the machine-readable reason for the result of the check, as JSON in the
+	// CheckReasonResponseHeaderKey response header: the relationship granting the permission,
+	// directly, via another relation or via a wildcard, or the caveat context fields missing to
+	// evaluate a conditional permission.
+	// Value: `1`
+	RequestCheckReason requestmeta.BoolRequestMetadataHeaderKey = "io.spicedb.checkreason"
+
+	// CheckReasonResponseHeaderKey is the response header of CheckPermission holding the reason
+	// for its result, when requested.
+	CheckReasonResponseHeaderKey = "io.spicedb.check-reason"
+
+	// MissingCaveatContextResponseHeaderKey is the response header of a CheckPermission with a
+	// conditional result holding, as JSON, each caveat which could not be evaluated: its name,
+	// the parameters missing from its context, and the path from the checked resource to the
+	// relation of its relationship.
+	MissingCaveatContextResponseHeaderKey = "io.spicedb.missing-caveat-context"
+)
+
+// RequestZanzibarExpand, if specified in an ExpandPermissionTree request header, asks SpiceDB to
+// return the userset tree with the semantics of the Expand API of the Zanzibar paper: each union,
+// intersection and exclusion of the permission is an intermediate node, and the relations and
+// arrows it references are leaves holding the referenced usersets, as `object#relation`, instead
+// of being expanded in turn. Only the relationships of the expanded relation itself, and of the
+// relations walked by its arrows, are read.
+// Value: `1`
+const RequestZanzibarExpand requestmeta.BoolRequestMetadataHeaderKey = "io.spicedb.zanzibarexpand"
+
+// RequestIdempotencyKey, if specified in a WriteRelationships request header, is the idempotency
+// key of the write, chosen by the client. If the write succeeds, retries of the request with the
+// same key return its revision instead of applying it again, for as long as SpiceDB remembers
+// the key. The key is stored in the transaction metadata of the write.
+// Value: any string, set with the SetRequestHeaders function of authzed-go
+const RequestIdempotencyKey requestmeta.RequestMetadataHeaderKey = "io.spicedb.idempotencykey"
+
+// RequestReadAtTime, if specified in a ReadRelationships or CheckPermission request header, reads
+// the relationships as they were at the given time instead of at the revision selected by the
+// consistency of the request, such as to audit whether a subject had access at some point in the
+// past. The time must be within the GC window of the datastore, or the request fails with an
+// OutOfRange error whose details mark the revision as garbage collected. The revision read at is
+// returned in the ReadAt or CheckedAt of the responses. Continuing a read from a cursor reads at
+// the revision of the cursor.
+// Value: an RFC 3339 timestamp, set with the SetRequestHeaders function of authzed-go
+const RequestReadAtTime requestmeta.RequestMetadataHeaderKey = "io.spicedb.readattime"
+
+// RequestPriority, if specified in a request header, is the priority class of the request:
+// `interactive` (the default), `batch` or `background`. The class is propagated to the nodes to
+// which the request is dispatched, bounds the concurrent dispatches of batch and background
+// requests, and is mapped to the priorities of the datastore, so that bulk jobs do not starve
+// interactive requests. Requests with an unknown class fail with
+// InvalidArgument.
+// Value: a priority class, set with the SetRequestHeaders function of authzed-go
+const RequestPriority requestmeta.RequestMetadataHeaderKey = "io.spicedb.priority"
+
+// RequestOverrideDeletionProtection, if specified in the header of a request deleting relationships,
+// overrides the deletion protection rules configured on the server, such as those denying deletes
+// of too many relationships or of relationships of protected object types. The value must be the
+// admin token configured for deletion protection, or the request fails with PermissionDenied.
+// Value: the deletion protection admin token, set with the SetRequestHeaders function of authzed-go
+const RequestOverrideDeletionProtection requestmeta.RequestMetadataHeaderKey = "io.spicedb.overridedeletionprotection"
+
+// RequestFork, if specified in a request header, serves the request from the datastore fork with
+// the given ID, created with the experimental ForkService, instead of from the datastore itself.
+// Forks are held by the node which created them, to which their requests must be sent. Requests
+// to a fork which does not exist, or to a server on which forks are not enabled, fail.
+// Value: the ID of the fork, set with the SetRequestHeaders function of authzed-go
+const RequestFork requestmeta.RequestMetadataHeaderKey = "io.spicedb.fork"
+
+// FIPSModeResponseHeaderKey is the response header holding the FIPS mode in which the server runs,
+// as named by fips.Mode, returned alongside the server version when it is requested with the
+// RequestServerVersion header of authzed-go.
+const FIPSModeResponseHeaderKey = "io.spicedb.fips-mode"
+
+// WithExpectedRelationshipVersion returns the outgoing context with the expected version of the
+// relationship of the update at the index of a WriteRelationships request.
+func WithExpectedRelationshipVersion(ctx context.Context, updateIndex int, versionToken string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, ExpectedRelationshipVersionHeaderKey, strconv.Itoa(updateIndex)+"="+versionToken)
+}
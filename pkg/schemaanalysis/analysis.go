@@ -0,0 +1,457 @@
+// Package schemaanalysis provides static analysis of the permission graph defined by a schema,
+// detecting unreachable relations, cycles and recursion, and estimating the worst-case dispatch
+// depth of each permission.
+package schemaanalysis
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/authzed/spicedb/pkg/genutil/mapz"
+	"github.com/authzed/spicedb/pkg/graph"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/schemadsl/compiler"
+	"github.com/authzed/spicedb/pkg/schemadsl/input"
+	"github.com/authzed/spicedb/pkg/spiceerrors"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// RelationReference identifies a relation or permission on an object definition.
+type RelationReference struct {
+	// ObjectType is the name of the object definition.
+	ObjectType string `json:"objectType"`
+
+	// Relation is the name of the relation or permission on the object definition.
+	Relation string `json:"relation"`
+}
+
+func (rr RelationReference) String() string {
+	return rr.ObjectType + "#" + rr.Relation
+}
+
+func compareReferences(a, b RelationReference) int {
+	return cmp.Or(cmp.Compare(a.ObjectType, b.ObjectType), cmp.Compare(a.Relation, b.Relation))
+}
+
+// EdgeKind is the kind of an edge in the permission graph.
+type EdgeKind string
+
+const (
+	// EdgeKindComputedUserset is an edge from a permission to a relation or permission on the same
+	// object definition, referenced by name in the permission's expression.
+	EdgeKindComputedUserset EdgeKind = "computed_userset"
+
+	// EdgeKindTupleset is an edge from a permission to the relation on the left side of an arrow.
+	EdgeKindTupleset EdgeKind = "tupleset"
+
+	// EdgeKindArrow is an edge from a permission to a relation or permission on the right side of
+	// an arrow, on an object definition allowed by the left side of the arrow.
+	EdgeKindArrow EdgeKind = "arrow"
+
+	// EdgeKindSubjectRelation is an edge from a relation to a subject relation allowed on it,
+	// such as `group#member`.
+	EdgeKindSubjectRelation EdgeKind = "subject_relation"
+)
+
+// dispatchCost returns the number of dispatches required to walk an edge of the kind.
+func (ek EdgeKind) dispatchCost() int {
+	// Tuplesets are read directly by the dispatch resolving the arrow.
+	if ek == EdgeKindTupleset {
+		return 0
+	}
+	return 1
+}
+
+// CycleKind is the kind of a cycle found in the permission graph.
+type CycleKind string
+
+const (
+	// CycleKindRewrite is a cycle found only via references between relations and permissions
+	// on a single object definition. Such a cycle can never be resolved, regardless of data.
+	CycleKindRewrite CycleKind = "rewrite"
+
+	// CycleKindRecursive is a cycle found via arrows or subject relations, such as a folder
+	// hierarchy. The depth of such recursion is bounded only by the relationships written.
+	CycleKindRecursive CycleKind = "recursive"
+)
+
+// Cycle is a cycle found in the permission graph.
+type Cycle struct {
+	// Kind is the kind of the cycle.
+	Kind CycleKind `json:"kind"`
+
+	// Relations are the relations and permissions found in the cycle, sorted.
+	Relations []RelationReference `json:"relations"`
+}
+
+// PermissionAnalysis is the analysis of a single permission.
+type PermissionAnalysis struct {
+	RelationReference
+
+	// ReachableRelations are the relations and permissions, sorted, which may be walked when
+	// computing the permission.
+	ReachableRelations []RelationReference `json:"reachableRelations"`
+
+	// SubjectTypes are the subject types, sorted, which can be found in the permission, such as
+	// `user`, `user:*` or `group#member`.
+	SubjectTypes []string `json:"subjectTypes"`
+
+	// Unbounded is true if the permission reaches a cycle, in which case the depth of dispatch
+	// required to compute it is bounded only by the relationships written.
+	Unbounded bool `json:"unbounded"`
+
+	// MaxDispatchDepth is the estimated worst-case depth of dispatch required to compute the
+	// permission, including the dispatch of the permission itself. Nil if unbounded.
+	MaxDispatchDepth *int `json:"maxDispatchDepth"`
+}
+
+// Report is the result of analyzing a schema.
+type Report struct {
+	// Permissions is the analysis of each permission, sorted.
+	Permissions []PermissionAnalysis `json:"permissions"`
+
+	// UnreachableRelations are the relations, sorted, which are not referenced by any permission
+	// or relation and can therefore never be walked when computing a permission.
+	UnreachableRelations []RelationReference `json:"unreachableRelations"`
+
+	// Cycles are the cycles found in the permission graph.
+	Cycles []Cycle `json:"cycles"`
+}
+
+// HasFindings returns true if the report contains any unreachable relations or any cycles
+// which can never be resolved.
+func (r *Report) HasFindings() bool {
+	if len(r.UnreachableRelations) > 0 {
+		return true
+	}
+
+	for _, cycle := range r.Cycles {
+		if cycle.Kind == CycleKindRewrite {
+			return true
+		}
+	}
+	return false
+}
+
+// AnalyzeSchema compiles and analyzes the given schema.
+func AnalyzeSchema(schema string) (*Report, error) {
+	compiled, err := compiler.Compile(compiler.InputSchema{
+		Source:       input.Source("schema"),
+		SchemaString: schema,
+	}, compiler.AllowUnprefixedObjectType())
+	if err != nil {
+		return nil, err
+	}
+
+	return Analyze(compiled.ObjectDefinitions)
+}
+
+type edge struct {
+	kind   EdgeKind
+	target RelationReference
+}
+
+type permissionGraph struct {
+	relations map[RelationReference]*core.Relation
+	edges     map[RelationReference][]edge
+}
+
+// Analyze analyzes the permission graph defined by the given object definitions.
+func Analyze(definitions []*core.NamespaceDefinition) (*Report, error) {
+	pg, err := newPermissionGraph(definitions)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make([]RelationReference, 0, len(pg.relations))
+	for ref := range pg.relations {
+		nodes = append(nodes, ref)
+	}
+	slices.SortFunc(nodes, compareReferences)
+
+	report := &Report{
+		Permissions:          []PermissionAnalysis{},
+		UnreachableRelations: pg.unreachableRelations(nodes),
+		Cycles:               []Cycle{},
+	}
+
+	inCycle := mapz.NewSet[RelationReference]()
+	for _, component := range pg.stronglyConnectedComponents(nodes) {
+		if len(component) == 1 && !pg.hasEdgeTo(component[0], component[0]) {
+			continue
+		}
+
+		slices.SortFunc(component, compareReferences)
+		inCycle.Extend(component)
+		report.Cycles = append(report.Cycles, Cycle{
+			Kind:      pg.cycleKind(component),
+			Relations: component,
+		})
+	}
+	slices.SortFunc(report.Cycles, func(a, b Cycle) int {
+		return compareReferences(a.Relations[0], b.Relations[0])
+	})
+
+	depths := map[RelationReference]int{}
+	for _, node := range nodes {
+		if pg.relations[node].UsersetRewrite == nil {
+			continue
+		}
+
+		reachable := pg.reachableFrom(node)
+		analysis := PermissionAnalysis{
+			RelationReference:  node,
+			ReachableRelations: reachable,
+			SubjectTypes:       pg.subjectTypes(reachable),
+		}
+
+		if slices.ContainsFunc(reachable, inCycle.Has) || inCycle.Has(node) {
+			analysis.Unbounded = true
+		} else {
+			depth := pg.maxDispatchDepth(node, depths)
+			analysis.MaxDispatchDepth = &depth
+		}
+
+		report.Permissions = append(report.Permissions, analysis)
+	}
+
+	return report, nil
+}
+
+func newPermissionGraph(definitions []*core.NamespaceDefinition) (*permissionGraph, error) {
+	pg := &permissionGraph{
+		relations: map[RelationReference]*core.Relation{},
+		edges:     map[RelationReference][]edge{},
+	}
+
+	for _, def := range definitions {
+		for _, rel := range def.Relation {
+			pg.relations[RelationReference{def.Name, rel.Name}] = rel
+		}
+	}
+
+	for _, def := range definitions {
+		for _, rel := range def.Relation {
+			current := RelationReference{def.Name, rel.Name}
+
+			for _, allowed := range rel.GetTypeInformation().GetAllowedDirectRelations() {
+				if allowed.GetPublicWildcard() != nil || allowed.GetRelation() == tuple.Ellipsis {
+					continue
+				}
+
+				pg.addEdge(current, EdgeKindSubjectRelation, RelationReference{allowed.Namespace, allowed.GetRelation()})
+			}
+
+			if rel.UsersetRewrite == nil {
+				continue
+			}
+
+			_, err := graph.WalkRewrite(rel.UsersetRewrite, func(childOneof *core.SetOperation_Child) (interface{}, error) {
+				switch child := childOneof.ChildType.(type) {
+				case *core.SetOperation_Child_ComputedUserset:
+					pg.addEdge(current, EdgeKindComputedUserset, RelationReference{def.Name, child.ComputedUserset.Relation})
+
+				case *core.SetOperation_Child_TupleToUserset:
+					pg.addArrowEdges(current, child.TupleToUserset.Tupleset.Relation, child.TupleToUserset.ComputedUserset.Relation)
+
+				case *core.SetOperation_Child_FunctionedTupleToUserset:
+					pg.addArrowEdges(current, child.FunctionedTupleToUserset.Tupleset.Relation, child.FunctionedTupleToUserset.ComputedUserset.Relation)
+
+				case *core.SetOperation_Child_XThis, *core.SetOperation_Child_XNil, *core.SetOperation_Child_UsersetRewrite:
+					// Nothing to do.
+
+				default:
+					return nil, spiceerrors.MustBugf("unknown rewrite child type %T", child)
+				}
+				return nil, nil
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return pg, nil
+}
+
+func (pg *permissionGraph) addArrowEdges(source RelationReference, tuplesetRelationName string, computedRelationName string) {
+	tuplesetRef := RelationReference{source.ObjectType, tuplesetRelationName}
+	pg.addEdge(source, EdgeKindTupleset, tuplesetRef)
+
+	tuplesetRelation, ok := pg.relations[tuplesetRef]
+	if !ok {
+		return
+	}
+
+	for _, allowed := range tuplesetRelation.GetTypeInformation().GetAllowedDirectRelations() {
+		target := RelationReference{allowed.Namespace, computedRelationName}
+		if _, ok := pg.relations[target]; ok {
+			pg.addEdge(source, EdgeKindArrow, target)
+		}
+	}
+}
+
+func (pg *permissionGraph) addEdge(source RelationReference, kind EdgeKind, target RelationReference) {
+	if _, ok := pg.relations[target]; !ok {
+		return
+	}
+
+	for _, existing := range pg.edges[source] {
+		if existing.kind == kind && existing.target == target {
+			return
+		}
+	}
+
+	pg.edges[source] = append(pg.edges[source], edge{kind, target})
+}
+
+func (pg *permissionGraph) hasEdgeTo(source RelationReference, target RelationReference) bool {
+	return slices.ContainsFunc(pg.edges[source], func(e edge) bool {
+		return e.target == target
+	})
+}
+
+// unreachableRelations returns the relations (but not permissions) without any incoming edges.
+func (pg *permissionGraph) unreachableRelations(nodes []RelationReference) []RelationReference {
+	referenced := mapz.NewSet[RelationReference]()
+	for source, edges := range pg.edges {
+		for _, e := range edges {
+			if e.target != source {
+				referenced.Add(e.target)
+			}
+		}
+	}
+
+	unreachable := []RelationReference{}
+	for _, node := range nodes {
+		if pg.relations[node].UsersetRewrite == nil && !referenced.Has(node) {
+			unreachable = append(unreachable, node)
+		}
+	}
+	return unreachable
+}
+
+// cycleKind returns the kind of the cycle formed by the strongly connected component.
+func (pg *permissionGraph) cycleKind(component []RelationReference) CycleKind {
+	for _, source := range component {
+		for _, e := range pg.edges[source] {
+			if e.kind != EdgeKindComputedUserset && slices.Contains(component, e.target) {
+				return CycleKindRecursive
+			}
+		}
+	}
+	return CycleKindRewrite
+}
+
+// reachableFrom returns the relations and permissions, sorted, reachable from the given node,
+// not including the node itself unless it is reachable via a cycle.
+func (pg *permissionGraph) reachableFrom(start RelationReference) []RelationReference {
+	reachable := mapz.NewSet[RelationReference]()
+	toProcess := []RelationReference{start}
+	for len(toProcess) > 0 {
+		current := toProcess[0]
+		toProcess = toProcess[1:]
+
+		for _, e := range pg.edges[current] {
+			if reachable.Add(e.target) {
+				toProcess = append(toProcess, e.target)
+			}
+		}
+	}
+
+	sorted := reachable.AsSlice()
+	slices.SortFunc(sorted, compareReferences)
+	return sorted
+}
+
+// subjectTypes returns the subject types allowed on any of the given relations, sorted.
+func (pg *permissionGraph) subjectTypes(relations []RelationReference) []string {
+	subjectTypes := mapz.NewSet[string]()
+	for _, ref := range relations {
+		for _, allowed := range pg.relations[ref].GetTypeInformation().GetAllowedDirectRelations() {
+			switch {
+			case allowed.GetPublicWildcard() != nil:
+				subjectTypes.Add(tuple.JoinObjectRef(allowed.Namespace, tuple.PublicWildcard))
+
+			case allowed.GetRelation() == tuple.Ellipsis:
+				subjectTypes.Add(allowed.Namespace)
+
+			default:
+				subjectTypes.Add(tuple.JoinRelRef(allowed.Namespace, allowed.GetRelation()))
+			}
+		}
+	}
+
+	sorted := subjectTypes.AsSlice()
+	slices.Sort(sorted)
+	return sorted
+}
+
+// maxDispatchDepth returns the worst-case dispatch depth for the given node, which must not
+// reach any cycle.
+func (pg *permissionGraph) maxDispatchDepth(node RelationReference, depths map[RelationReference]int) int {
+	if depth, ok := depths[node]; ok {
+		return depth
+	}
+
+	depth := 1
+	for _, e := range pg.edges[node] {
+		depth = max(depth, e.kind.dispatchCost()+pg.maxDispatchDepth(e.target, depths))
+	}
+
+	depths[node] = depth
+	return depth
+}
+
+// stronglyConnectedComponents returns the strongly connected components of the graph, computed
+// via Tarjan's algorithm.
+func (pg *permissionGraph) stronglyConnectedComponents(nodes []RelationReference) [][]RelationReference {
+	index := 0
+	indexes := map[RelationReference]int{}
+	lowLinks := map[RelationReference]int{}
+	onStack := mapz.NewSet[RelationReference]()
+	stack := []RelationReference{}
+	components := [][]RelationReference{}
+
+	var visit func(node RelationReference)
+	visit = func(node RelationReference) {
+		indexes[node] = index
+		lowLinks[node] = index
+		index++
+		stack = append(stack, node)
+		onStack.Add(node)
+
+		for _, e := range pg.edges[node] {
+			if _, ok := indexes[e.target]; !ok {
+				visit(e.target)
+				lowLinks[node] = min(lowLinks[node], lowLinks[e.target])
+			} else if onStack.Has(e.target) {
+				lowLinks[node] = min(lowLinks[node], indexes[e.target])
+			}
+		}
+
+		if lowLinks[node] != indexes[node] {
+			return
+		}
+
+		component := []RelationReference{}
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack.Delete(top)
+			component = append(component, top)
+			if top == node {
+				break
+			}
+		}
+		components = append(components, component)
+	}
+
+	for _, node := range nodes {
+		if _, ok := indexes[node]; !ok {
+			visit(node)
+		}
+	}
+
+	return components
+}
@@ -0,0 +1,210 @@
+package schemaanalysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func ref(objectType, relation string) RelationReference {
+	return RelationReference{objectType, relation}
+}
+
+func depth(d int) *int {
+	return &d
+}
+
+func TestAnalyzeSchema(t *testing.T) {
+	tcs := []struct {
+		name                string
+		schema              string
+		expectedPermissions []PermissionAnalysis
+		expectedUnreachable []RelationReference
+		expectedCycles      []Cycle
+		expectedHasFindings bool
+	}{
+		{
+			name: "simple",
+			schema: `definition user {}
+
+			definition document {
+				relation viewer: user
+				relation editor: user | user:*
+				permission view = viewer + editor
+			}`,
+			expectedPermissions: []PermissionAnalysis{
+				{
+					RelationReference:  ref("document", "view"),
+					ReachableRelations: []RelationReference{ref("document", "editor"), ref("document", "viewer")},
+					SubjectTypes:       []string{"user", "user:*"},
+					MaxDispatchDepth:   depth(2),
+				},
+			},
+			expectedUnreachable: []RelationReference{},
+			expectedCycles:      []Cycle{},
+		},
+		{
+			name: "unreachable relation",
+			schema: `definition user {}
+
+			definition document {
+				relation viewer: user
+				relation unused: user
+				permission view = viewer
+			}`,
+			expectedPermissions: []PermissionAnalysis{
+				{
+					RelationReference:  ref("document", "view"),
+					ReachableRelations: []RelationReference{ref("document", "viewer")},
+					SubjectTypes:       []string{"user"},
+					MaxDispatchDepth:   depth(2),
+				},
+			},
+			expectedUnreachable: []RelationReference{ref("document", "unused")},
+			expectedCycles:      []Cycle{},
+			expectedHasFindings: true,
+		},
+		{
+			name: "arrows and subject relations",
+			schema: `definition user {}
+
+			definition group {
+				relation member: user | group#member
+			}
+
+			definition organization {
+				relation admin: user
+			}
+
+			definition document {
+				relation org: organization
+				relation viewer: user | group#member
+				permission view = viewer + org->admin
+			}`,
+			expectedPermissions: []PermissionAnalysis{
+				{
+					RelationReference: ref("document", "view"),
+					ReachableRelations: []RelationReference{
+						ref("document", "org"),
+						ref("document", "viewer"),
+						ref("group", "member"),
+						ref("organization", "admin"),
+					},
+					SubjectTypes: []string{"group#member", "organization", "user"},
+					Unbounded:    true,
+				},
+			},
+			expectedUnreachable: []RelationReference{},
+			expectedCycles: []Cycle{
+				{Kind: CycleKindRecursive, Relations: []RelationReference{ref("group", "member")}},
+			},
+		},
+		{
+			name: "bounded depth via arrow",
+			schema: `definition user {}
+
+			definition organization {
+				relation admin: user
+				permission manage = admin
+			}
+
+			definition document {
+				relation org: organization
+				relation viewer: user
+				permission view = viewer + org->manage
+			}`,
+			expectedPermissions: []PermissionAnalysis{
+				{
+					RelationReference: ref("document", "view"),
+					ReachableRelations: []RelationReference{
+						ref("document", "org"),
+						ref("document", "viewer"),
+						ref("organization", "admin"),
+						ref("organization", "manage"),
+					},
+					SubjectTypes:     []string{"organization", "user"},
+					MaxDispatchDepth: depth(3),
+				},
+				{
+					RelationReference:  ref("organization", "manage"),
+					ReachableRelations: []RelationReference{ref("organization", "admin")},
+					SubjectTypes:       []string{"user"},
+					MaxDispatchDepth:   depth(2),
+				},
+			},
+			expectedUnreachable: []RelationReference{},
+			expectedCycles:      []Cycle{},
+		},
+		{
+			name: "recursive arrow",
+			schema: `definition user {}
+
+			definition folder {
+				relation parent: folder
+				relation viewer: user
+				permission view = viewer + parent->view
+			}`,
+			expectedPermissions: []PermissionAnalysis{
+				{
+					RelationReference: ref("folder", "view"),
+					ReachableRelations: []RelationReference{
+						ref("folder", "parent"),
+						ref("folder", "view"),
+						ref("folder", "viewer"),
+					},
+					SubjectTypes: []string{"folder", "user"},
+					Unbounded:    true,
+				},
+			},
+			expectedUnreachable: []RelationReference{},
+			expectedCycles: []Cycle{
+				{Kind: CycleKindRecursive, Relations: []RelationReference{ref("folder", "view")}},
+			},
+		},
+		{
+			name: "rewrite cycle",
+			schema: `definition user {}
+
+			definition document {
+				relation viewer: user
+				permission view = viewer + edit
+				permission edit = view
+			}`,
+			expectedPermissions: []PermissionAnalysis{
+				{
+					RelationReference:  ref("document", "edit"),
+					ReachableRelations: []RelationReference{ref("document", "edit"), ref("document", "view"), ref("document", "viewer")},
+					SubjectTypes:       []string{"user"},
+					Unbounded:          true,
+				},
+				{
+					RelationReference:  ref("document", "view"),
+					ReachableRelations: []RelationReference{ref("document", "edit"), ref("document", "view"), ref("document", "viewer")},
+					SubjectTypes:       []string{"user"},
+					Unbounded:          true,
+				},
+			},
+			expectedUnreachable: []RelationReference{},
+			expectedCycles: []Cycle{
+				{Kind: CycleKindRewrite, Relations: []RelationReference{ref("document", "edit"), ref("document", "view")}},
+			},
+			expectedHasFindings: true,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			report, err := AnalyzeSchema(tc.schema)
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedPermissions, report.Permissions)
+			require.Equal(t, tc.expectedUnreachable, report.UnreachableRelations)
+			require.Equal(t, tc.expectedCycles, report.Cycles)
+			require.Equal(t, tc.expectedHasFindings, report.HasFindings())
+		})
+	}
+}
+
+func TestAnalyzeSchemaInvalid(t *testing.T) {
+	_, err := AnalyzeSchema(`definition document {`)
+	require.Error(t, err)
+}
@@ -2,6 +2,7 @@ package compiler
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -1132,3 +1133,32 @@ func TestSuperLargeCaveatCompile(t *testing.T) {
 	require.Equal(t, 29, len(compiled.ObjectDefinitions))
 	require.Equal(t, 1, len(compiled.CaveatDefinitions))
 }
+
+func FuzzCompile(f *testing.F) {
+	filenames, err := filepath.Glob("../parser/tests/*.zed")
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	for _, filename := range filenames {
+		b, err := os.ReadFile(filename)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(string(b))
+	}
+
+	f.Fuzz(func(t *testing.T, schema string) {
+		compiled, err := Compile(InputSchema{"fuzz", schema}, AllowUnprefixedObjectType())
+		if err != nil {
+			return
+		}
+
+		for _, def := range compiled.ObjectDefinitions {
+			require.NotEmpty(t, def.Name)
+		}
+		for _, caveatDef := range compiled.CaveatDefinitions {
+			require.NotEmpty(t, caveatDef.Name)
+		}
+	})
+}
@@ -0,0 +1,304 @@
+package generator
+
+import (
+	"sort"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/authzed/spicedb/pkg/genutil/mapz"
+	"github.com/authzed/spicedb/pkg/namespace"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/schemadsl/compiler"
+	"github.com/authzed/spicedb/pkg/schemadsl/input"
+	"github.com/authzed/spicedb/pkg/schemadsl/lexer"
+	"github.com/authzed/spicedb/pkg/spiceerrors"
+)
+
+// FormatSchema parses the schema and returns it in canonical formatting, as generated for compiled
+// schemas. Formatting is stable: formatting a formatted schema returns it unchanged.
+//
+// Unlike GenerateSchema, every comment of the schema is preserved. Comments within a definition,
+// caveat, relation or permission, such as those at the end of its line, are moved before it, and
+// comments at the end of the body of a definition or at the end of the schema are kept there.
+func FormatSchema(schema string) (string, error) {
+	compiled, err := compiler.Compile(compiler.InputSchema{
+		Source:       input.Source("schema"),
+		SchemaString: schema,
+	}, compiler.AllowUnprefixedObjectType())
+	if err != nil {
+		return "", err
+	}
+
+	comments, err := findUnattachedComments(schema)
+	if err != nil {
+		return "", err
+	}
+
+	if len(comments.declarations) != len(compiled.OrderedDefinitions) {
+		return "", spiceerrors.MustBugf("found %d declarations in the schema, but %d were compiled", len(comments.declarations), len(compiled.OrderedDefinitions))
+	}
+
+	generated := make([]string, 0, len(compiled.OrderedDefinitions)+1)
+	flags := mapz.NewSet[string]()
+	for index, definition := range compiled.OrderedDefinitions {
+		declarationComments := comments.declarations[index]
+
+		switch def := definition.(type) {
+		case *core.CaveatDefinition:
+			def = proto.Clone(def).(*core.CaveatDefinition)
+			def.Metadata, err = withMovedComments(def.Metadata, declarationComments.leading, declarationComments.inner)
+			if err != nil {
+				return "", err
+			}
+
+			generatedCaveat, _, err := GenerateCaveatSource(def)
+			if err != nil {
+				return "", err
+			}
+			generated = append(generated, generatedCaveat)
+
+		case *core.NamespaceDefinition:
+			def = proto.Clone(def).(*core.NamespaceDefinition)
+			def.Metadata, err = withMovedComments(def.Metadata, declarationComments.leading, declarationComments.inner)
+			if err != nil {
+				return "", err
+			}
+
+			for relationIndex, relationComments := range declarationComments.statements {
+				if relationIndex >= len(def.Relation) {
+					return "", spiceerrors.MustBugf("found comments in relation %d of definition %s, which has %d relations", relationIndex, def.Name, len(def.Relation))
+				}
+
+				relation := def.Relation[relationIndex]
+				relation.Metadata, err = withMovedComments(relation.Metadata, nil, relationComments)
+				if err != nil {
+					return "", err
+				}
+			}
+
+			generator := &sourceGenerator{
+				indentationLevel: 0,
+				hasNewline:       true,
+				hasBlankline:     true,
+				hasNewScope:      true,
+				flags:            mapz.NewSet[string](),
+			}
+			if err := generator.emitNamespace(def, declarationComments.bodyEnd); err != nil {
+				return "", err
+			}
+			generated = append(generated, generator.buf.String())
+			flags.Merge(generator.flags)
+
+		default:
+			return "", spiceerrors.MustBugf("unknown type of definition %T in FormatSchema", def)
+		}
+	}
+
+	if !flags.IsEmpty() {
+		flagsSlice := flags.AsSlice()
+		sort.Strings(flagsSlice)
+
+		for _, flag := range flagsSlice {
+			generated = append([]string{"use " + flag}, generated...)
+		}
+	}
+
+	if len(comments.schemaEnd) > 0 {
+		generator := &sourceGenerator{
+			indentationLevel: 0,
+			hasNewline:       true,
+			hasBlankline:     true,
+			hasNewScope:      true,
+		}
+		for _, comment := range comments.schemaEnd {
+			generator.appendComment(comment)
+		}
+		generated = append(generated, strings.TrimSuffix(generator.buf.String(), "\n"))
+	}
+
+	formatted := strings.Join(generated, "\n\n") + "\n"
+
+	// Ensure that no comment was dropped or duplicated, as the compiler attaches comments to
+	// declarations by itself.
+	formattedComments, err := findUnattachedComments(formatted)
+	if err != nil {
+		return "", err
+	}
+	if formattedComments.count != comments.count {
+		return "", spiceerrors.MustBugf("formatted schema has %d comments, but the schema has %d", formattedComments.count, comments.count)
+	}
+
+	return formatted, nil
+}
+
+// withMovedComments returns the metadata with the comments which precede the declaration in the
+// source before its doc comments, and the comments found within it after them.
+func withMovedComments(metadata *core.Metadata, preceding []string, within []string) (*core.Metadata, error) {
+	if len(preceding) == 0 && len(within) == 0 {
+		return metadata, nil
+	}
+
+	updated := &core.Metadata{}
+	for _, comment := range preceding {
+		var err error
+		updated, err = namespace.AddComment(updated, comment)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if metadata != nil {
+		updated.MetadataMessage = append(updated.MetadataMessage, metadata.MetadataMessage...)
+	}
+
+	for _, comment := range within {
+		var err error
+		updated, err = namespace.AddComment(updated, comment)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return updated, nil
+}
+
+// schemaComments are the comments of a schema which are not attached to a declaration by the
+// compiler, by the top-level declaration (definition or caveat) they are found in or before.
+type schemaComments struct {
+	declarations []declarationComments
+	schemaEnd    []string
+
+	// count is the number of comments in the schema, including those attached by the compiler.
+	count int
+}
+
+type declarationComments struct {
+	// leading are the comments before the declaration which are not directly before it.
+	leading []string
+
+	// inner are the comments within the declaration but outside of its relations and
+	// permissions, such as within the expression of a caveat or after its closing brace.
+	inner []string
+
+	// statements are the comments within each relation or permission, by index.
+	statements map[int][]string
+
+	// bodyEnd are the comments after the last relation or permission of a definition.
+	bodyEnd []string
+}
+
+var (
+	topLevelKeywords  = map[string]struct{}{"definition": {}, "caveat": {}}
+	statementKeywords = map[string]struct{}{"relation": {}, "permission": {}}
+)
+
+// findUnattachedComments finds the comments of the schema which the parser does not attach to a
+// declaration. The parser attaches the comments before a definition, caveat, relation or
+// permission, with nothing but whitespace between them.
+func findUnattachedComments(schema string) (schemaComments, error) {
+	lex := lexer.NewFlaggableLexler(lexer.Lex(input.Source("schema"), schema))
+	defer lex.Close()
+
+	var (
+		result  schemaComments
+		pending []string // comments since the last significant token
+		orphans []string // unattached comments before the next top-level declaration
+
+		depth       int
+		inHeader    bool // between the keyword of a top-level declaration and its body
+		closed      bool // directly after the body of a top-level declaration
+		isCaveat    bool
+		inStatement bool // within a relation or permission
+		statement   = -1
+	)
+
+	current := func() *declarationComments {
+		return &result.declarations[len(result.declarations)-1]
+	}
+
+	for {
+		token := lex.NextToken()
+		switch token.Kind {
+		case lexer.TokenTypeError:
+			return schemaComments{}, spiceerrors.MustBugf("failed to lex compiled schema: %s", token.Value)
+
+		case lexer.TokenTypeSinglelineComment, lexer.TokenTypeMultilineComment:
+			pending = append(pending, token.Value)
+			result.count++
+			continue
+
+		case lexer.TokenTypeWhitespace, lexer.TokenTypeNewline:
+			continue
+		}
+
+		_, isTopLevelKeyword := topLevelKeywords[token.Value]
+		isTopLevelKeyword = isTopLevelKeyword && token.Kind == lexer.TokenTypeKeyword && depth == 0
+		_, isStatementKeyword := statementKeywords[token.Value]
+		isStatementKeyword = isStatementKeyword && token.Kind == lexer.TokenTypeKeyword && depth == 1 && !isCaveat
+
+		// Place the comments before the token, unless the parser attaches them to it.
+		if len(pending) > 0 && !isTopLevelKeyword && !isStatementKeyword {
+			switch {
+			case closed && token.Kind == lexer.TokenTypeSyntheticSemicolon:
+				// Comments on the line of the end of a declaration belong to it.
+				current().inner = append(current().inner, pending...)
+
+			case len(result.declarations) == 0 || (depth == 0 && !inHeader):
+				orphans = append(orphans, pending...)
+
+			case isCaveat || inHeader:
+				current().inner = append(current().inner, pending...)
+
+			case inStatement:
+				current().statements[statement] = append(current().statements[statement], pending...)
+
+			case token.Kind == lexer.TokenTypeRightBrace && depth == 1:
+				current().bodyEnd = append(current().bodyEnd, pending...)
+
+			case statement >= 0:
+				current().statements[statement] = append(current().statements[statement], pending...)
+
+			default:
+				current().inner = append(current().inner, pending...)
+			}
+		}
+		pending = nil
+		closed = false
+
+		switch {
+		case token.Kind == lexer.TokenTypeEOF:
+			result.schemaEnd = orphans
+			return result, nil
+
+		case isTopLevelKeyword:
+			result.declarations = append(result.declarations, declarationComments{
+				leading:    orphans,
+				statements: map[int][]string{},
+			})
+			orphans = nil
+			inHeader = true
+			isCaveat = token.Value == "caveat"
+			statement = -1
+
+		case isStatementKeyword:
+			statement++
+			inStatement = true
+
+		case token.Kind == lexer.TokenTypeLeftBrace:
+			inHeader = false
+			depth++
+
+		case token.Kind == lexer.TokenTypeRightBrace:
+			depth--
+			if depth <= 1 {
+				inStatement = false
+			}
+			closed = depth == 0
+
+		case token.Kind == lexer.TokenTypeSyntheticSemicolon || token.Kind == lexer.TokenTypeSemicolon:
+			if depth == 1 {
+				inStatement = false
+			}
+		}
+	}
+}
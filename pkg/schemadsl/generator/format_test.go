@@ -0,0 +1,173 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatSchema(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			"canonical formatting",
+			`definition user{}
+definition   document {
+    relation viewer : user|user:*
+  permission view=viewer+nil
+}`,
+			`definition user {}
+
+definition document {
+	relation viewer: user | user:*
+	permission view = viewer + nil
+}
+`,
+		},
+		{
+			"doc comments",
+			`// the user
+definition user {}
+
+/** a document */
+definition document {
+	// who can view
+	relation viewer: user
+}`,
+			`// the user
+definition user {}
+
+/** a document */
+definition document {
+	// who can view
+	relation viewer: user
+}
+`,
+		},
+		{
+			"trailing comments",
+			`definition user {} // trailing user
+
+definition document {
+	relation viewer: user // trailing viewer
+	permission view = viewer /* trailing view */
+}`,
+			`// trailing user
+definition user {}
+
+definition document {
+	// trailing viewer
+	relation viewer: user
+
+	/* trailing view */
+	permission view = viewer
+}
+`,
+		},
+		{
+			"comments within expressions",
+			`definition user {}
+
+definition document {
+	relation viewer: user
+	relation editor: user
+	permission view = viewer + // editors can view too
+		editor
+}`,
+			`definition user {}
+
+definition document {
+	relation viewer: user
+	relation editor: user
+
+	// editors can view too
+	permission view = viewer + editor
+}
+`,
+		},
+		{
+			"comments at the end of bodies and schema",
+			`definition user {
+	// nothing yet
+}
+
+definition document {
+	relation viewer: user
+
+	// more to come
+	// soon
+}
+
+// the end`,
+			`definition user {
+	// nothing yet
+}
+
+definition document {
+	relation viewer: user
+
+	// more to come
+	// soon
+}
+
+// the end
+`,
+		},
+		{
+			"comments in caveats",
+			`caveat somecaveat(someparam int, anotherparam string) {
+	// check the param
+	someparam == 42
+}
+
+definition user {}`,
+			`// check the param
+caveat somecaveat(anotherparam string, someparam int) {
+	someparam == 42
+}
+
+definition user {}
+`,
+		},
+		{
+			"comments before flags",
+			`// the schema
+use expiration
+
+definition user {}
+
+definition document {
+	relation viewer: user with expiration
+}`,
+			`use expiration
+
+// the schema
+definition user {}
+
+definition document {
+	relation viewer: user with expiration
+}
+`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			formatted, err := FormatSchema(tc.input)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, formatted)
+
+			reformatted, err := FormatSchema(formatted)
+			require.NoError(t, err)
+			require.Equal(t, formatted, reformatted, "formatting is not stable")
+		})
+	}
+}
+
+func TestFormatSchemaInvalid(t *testing.T) {
+	_, err := FormatSchema("definition user {")
+	require.Error(t, err)
+}
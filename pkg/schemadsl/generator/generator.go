@@ -100,7 +100,7 @@ func generateDefinitionSource(namespace *core.NamespaceDefinition) (string, []st
 		flags:            mapz.NewSet[string](),
 	}
 
-	err := generator.emitNamespace(namespace)
+	err := generator.emitNamespace(namespace, nil)
 	if err != nil {
 		return "", nil, false, err
 	}
@@ -179,12 +179,13 @@ func (sg *sourceGenerator) emitCaveat(caveat *core.CaveatDefinition) error {
 	return nil
 }
 
-func (sg *sourceGenerator) emitNamespace(namespace *core.NamespaceDefinition) error {
+// emitNamespace emits the namespace definition, with the given comments at the end of its body.
+func (sg *sourceGenerator) emitNamespace(namespace *core.NamespaceDefinition, bodyEndComments []string) error {
 	sg.emitComments(namespace.Metadata)
 	sg.append("definition ")
 	sg.append(namespace.Name)
 
-	if len(namespace.Relation) == 0 {
+	if len(namespace.Relation) == 0 && len(bodyEndComments) == 0 {
 		sg.append(" {}")
 		return nil
 	}
@@ -201,6 +202,13 @@ func (sg *sourceGenerator) emitNamespace(namespace *core.NamespaceDefinition) er
 		}
 	}
 
+	if len(bodyEndComments) > 0 {
+		sg.ensureBlankLineOrNewScope()
+		for _, comment := range bodyEndComments {
+			sg.appendComment(comment)
+		}
+	}
+
 	sg.dedent()
 	sg.append("}")
 	return nil
@@ -297,7 +305,7 @@ func (sg *sourceGenerator) emitRewriteOps(setOp *core.SetOperation, op string) {
 			sg.append(" " + op + " ")
 		}
 
-		sg.mustEmitSetOpChild(child)
+		sg.mustEmitSetOpChild(child, op)
 	}
 }
 
@@ -320,10 +328,12 @@ func (sg *sourceGenerator) isAllUnion(rewrite *core.UsersetRewrite) bool {
 	}
 }
 
-func (sg *sourceGenerator) mustEmitSetOpChild(setOpChild *core.SetOperation_Child) {
+func (sg *sourceGenerator) mustEmitSetOpChild(setOpChild *core.SetOperation_Child, op string) {
 	switch child := setOpChild.ChildType.(type) {
 	case *core.SetOperation_Child_UsersetRewrite:
-		if sg.isAllUnion(child.UsersetRewrite) {
+		// Unions nested in intersections or exclusions keep their parentheses, so that the schema
+		// parses back to the same rewrite.
+		if op == "+" && sg.isAllUnion(child.UsersetRewrite) {
 			sg.mustEmitRewrite(child.UsersetRewrite)
 			break
 		}
@@ -131,6 +131,24 @@ func TestGenerateNamespace(t *testing.T) {
 			),
 			`definition foos/test {
 	permission someperm = (rela - relb - rely->relz) + relc
+}`,
+			true,
+		},
+		{
+			"union nested in exclusion",
+			namespace.Namespace("foos/test",
+				namespace.MustRelation("someperm", namespace.Exclusion(
+					namespace.ComputedUserset("rela"),
+					namespace.Rewrite(
+						namespace.Union(
+							namespace.ComputedUserset("relb"),
+							namespace.ComputedUserset("relc"),
+						),
+					),
+				)),
+			),
+			`definition foos/test {
+	permission someperm = rela - (relb + relc)
 }`,
 			true,
 		},
@@ -236,6 +236,20 @@ var lexerTests = []lexerTest{
 			tEOF,
 		},
 	},
+	{
+		"cel string literal starting with multibyte rune", `"˧hi"`,
+		[]Lexeme{
+			{TokenTypeString, 0, `"˧hi"`, ""},
+			tEOF,
+		},
+	},
+	{
+		"cel string literal with terminators starting with multibyte rune", `"""˧hi"""`,
+		[]Lexeme{
+			{TokenTypeString, 0, `"""˧hi"""`, ""},
+			tEOF,
+		},
+	},
 	{
 		"unterminated cel string literal", "\"hi\nthere\"",
 		[]Lexeme{
@@ -4,6 +4,7 @@ import (
 	"container/list"
 	"fmt"
 	"os"
+	"path/filepath"
 	"sort"
 	"strings"
 	"testing"
@@ -192,3 +193,30 @@ func getParseTree(currentNode *testNode, indentation int) string {
 
 	return parseTree
 }
+
+// addSeedSchemas adds the schemas under the tests directory to the seed corpus of the fuzz test.
+func addSeedSchemas(f *testing.F) {
+	filenames, err := filepath.Glob("tests/*.zed")
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	for _, filename := range filenames {
+		b, err := os.ReadFile(filename)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(string(b))
+	}
+}
+
+func FuzzParse(f *testing.F) {
+	addSeedSchemas(f)
+
+	f.Fuzz(func(t *testing.T, schema string) {
+		root := Parse(createAstNode, input.Source("fuzz"), schema)
+		if root == nil {
+			t.Fatalf("no root node returned for schema %q", schema)
+		}
+	})
+}
@@ -16,6 +16,10 @@ type MetadataKey string
 // The value is expected to be a string containing the proto text of a DebugInformation message.
 const DebugTraceErrorDetailsKey MetadataKey = "debug_trace_proto_text"
 
+// ContentionKeyDetailsKey is the key used to store the contention key of a write which failed due
+// to contention in the error details. Writes with the same contention key contend with each other.
+const ContentionKeyDetailsKey MetadataKey = "contention_key"
+
 // AppendDetailsMetadata appends the key-value pair to the error details metadata.
 // If the error is nil or is not a status error, it is returned as is.
 func AppendDetailsMetadata(err error, key MetadataKey, value string) error {
@@ -2,6 +2,7 @@ package spiceerrors
 
 import (
 	"errors"
+	"time"
 
 	log "github.com/authzed/spicedb/internal/logging"
 
@@ -9,8 +10,11 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/runtime/protoiface"
+	"google.golang.org/protobuf/types/known/durationpb"
 
 	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+
+	"github.com/authzed/spicedb/pkg/errorcodes"
 )
 
 // Domain is the domain used for all errors.
@@ -43,6 +47,23 @@ func ForReason(reason v1.ErrorReason, metadata map[string]string) *errdetails.Er
 	}
 }
 
+// ForCode returns an ErrorInfo block for a specific code of the error catalogue, with the error
+// reason of the code as defined in the V1 API.
+func ForCode(code errorcodes.Code, metadata map[string]string) *errdetails.ErrorInfo {
+	entry, _ := errorcodes.Lookup(code)
+	if metadata == nil {
+		metadata = make(map[string]string, 1)
+	}
+	metadata[errorcodes.MetadataKey] = string(code)
+	return ForReason(entry.Reason, metadata)
+}
+
+// ForRetryDelay returns a RetryInfo block indicating the delay after which the request should be
+// retried.
+func ForRetryDelay(delay time.Duration) *errdetails.RetryInfo {
+	return &errdetails.RetryInfo{RetryDelay: durationpb.New(delay)}
+}
+
 // WithCodeAndReason returns a new error which wraps the existing error with a gRPC code and
 // a reason block.
 func WithCodeAndReason(err error, code codes.Code, reason v1.ErrorReason) error {
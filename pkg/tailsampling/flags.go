@@ -0,0 +1,157 @@
+// Package tailsampling implements the tail-based sampling of traces, in which the decision to
+// export a trace is made once its requests complete, based on their latency and on whether they
+// failed.
+package tailsampling
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jzelinskie/cobrautil/v2"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+
+	log "github.com/authzed/spicedb/internal/logging"
+)
+
+// RegisterFlags adds flags for configuring the tail-based sampling of traces.
+//
+// The following flags are added:
+// - "otel-tail-sampling"
+// - "otel-tail-sampling-latency-threshold"
+// - "otel-tail-sampling-success-ratio"
+// - "otel-tail-sampling-method-policies"
+func RegisterFlags(flags *pflag.FlagSet) {
+	flags.Bool("otel-tail-sampling", false, "decide whether to export traces once their requests complete, always exporting slow and failed requests, instead of using otel-sample-ratio")
+	flags.Duration("otel-tail-sampling-latency-threshold", defaultPolicy.LatencyThreshold, "latency above which the traces of requests are always exported when tail sampling")
+	flags.Float64("otel-tail-sampling-success-ratio", defaultPolicy.SuccessRatio, "ratio of the traces of successful requests under the latency threshold which are exported when tail sampling")
+	flags.StringSlice("otel-tail-sampling-method-policies", nil, "tail sampling policies of individual API methods, as method=threshold:ratio (e.g. `CheckPermission=100ms:0.001`); an empty threshold or ratio uses the default one")
+}
+
+var defaultPolicy = Policy{
+	LatencyThreshold: 500 * time.Millisecond,
+	SuccessRatio:     0.01,
+}
+
+// RunE returns a Cobra RunFunc that, if tail sampling is enabled, replaces the tracer provider
+// configured by cobraotel with one recording every trace and deciding which to export through
+// a tail sampling Processor. It must run after the cobraotel RunFunc.
+//
+// The required flags can be added to a command by using RegisterFlags().
+func RunE() cobrautil.CobraRunFunc {
+	return func(cmd *cobra.Command, args []string) error {
+		if cobrautil.IsBuiltinCommand(cmd) {
+			return nil // No-op for builtins
+		}
+
+		if !cobrautil.MustGetBool(cmd, "otel-tail-sampling") {
+			return nil
+		}
+
+		policies, err := policiesFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+
+		exporter, err := exporterFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+		if exporter == nil {
+			log.Ctx(cmd.Context()).Warn().Msg("tail sampling is enabled without an OpenTelemetry provider; no traces will be exported")
+			return nil
+		}
+
+		res, err := resource.New(
+			context.Background(),
+			resource.WithAttributes(semconv.ServiceNameKey.String(cobrautil.MustGetString(cmd, "otel-service-name"))),
+			resource.WithFromEnv(),
+			resource.WithTelemetrySDK(),
+		)
+		if err != nil {
+			return err
+		}
+
+		// The provider configured by cobraotel is replaced, and shut down so its exporter
+		// releases its connection.
+		if previous, ok := otel.GetTracerProvider().(*sdktrace.TracerProvider); ok {
+			if err := previous.Shutdown(context.Background()); err != nil {
+				return fmt.Errorf("failed to shut down the previous tracer provider: %w", err)
+			}
+		}
+
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(
+			sdktrace.WithSampler(sdktrace.AlwaysSample()),
+			sdktrace.WithSpanProcessor(NewProcessor(sdktrace.NewBatchSpanProcessor(exporter), policies)),
+			sdktrace.WithResource(res),
+		))
+
+		log.Ctx(cmd.Context()).Info().
+			Dur("latencyThreshold", policies.Default.LatencyThreshold).
+			Float64("successRatio", policies.Default.SuccessRatio).
+			Int("methodPolicies", len(policies.Methods)).
+			Msg("configured tail sampling of traces")
+		return nil
+	}
+}
+
+func policiesFromFlags(cmd *cobra.Command) (Policies, error) {
+	defaultPolicy := Policy{
+		LatencyThreshold: cobrautil.MustGetDuration(cmd, "otel-tail-sampling-latency-threshold"),
+		SuccessRatio:     cobrautil.MustGetFloat64(cmd, "otel-tail-sampling-success-ratio"),
+	}
+	if defaultPolicy.LatencyThreshold < 0 {
+		return Policies{}, fmt.Errorf("otel-tail-sampling-latency-threshold must not be negative")
+	}
+	if defaultPolicy.SuccessRatio < 0 || defaultPolicy.SuccessRatio > 1 {
+		return Policies{}, fmt.Errorf("otel-tail-sampling-success-ratio must be between 0 and 1")
+	}
+
+	methods, err := ParseMethodPolicies(cobrautil.MustGetStringSlice(cmd, "otel-tail-sampling-method-policies"), defaultPolicy)
+	if err != nil {
+		return Policies{}, err
+	}
+
+	return Policies{Default: defaultPolicy, Methods: methods}, nil
+}
+
+// exporterFromFlags returns the exporter configured by the cobraotel flags, or nil if the
+// provider is "none".
+func exporterFromFlags(cmd *cobra.Command) (sdktrace.SpanExporter, error) {
+	endpoint := cobrautil.MustGetString(cmd, "otel-endpoint")
+	insecure := cobrautil.MustGetBool(cmd, "otel-insecure")
+
+	switch provider := strings.ToLower(cobrautil.MustGetString(cmd, "otel-provider")); provider {
+	case "none":
+		return nil, nil
+	case "otlphttp":
+		var opts []otlptracehttp.Option
+		if endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpoint(endpoint))
+		}
+		if insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		return otlptrace.New(context.Background(), otlptracehttp.NewClient(opts...))
+	case "otlpgrpc":
+		var opts []otlptracegrpc.Option
+		if endpoint != "" {
+			opts = append(opts, otlptracegrpc.WithEndpoint(endpoint))
+		}
+		if insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		return otlptrace.New(context.Background(), otlptracegrpc.NewClient(opts...))
+	default:
+		return nil, fmt.Errorf("unknown tracing provider: %s", provider)
+	}
+}
@@ -0,0 +1,102 @@
+package tailsampling
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Policy is the sampling policy applied to the traces of an RPC method once they complete.
+type Policy struct {
+	// LatencyThreshold is the duration above which a trace is always exported. A zero threshold
+	// exports every trace.
+	LatencyThreshold time.Duration
+
+	// SuccessRatio is the ratio of the traces completing successfully under the latency
+	// threshold which are exported.
+	SuccessRatio float64
+}
+
+// Policies are the sampling policies of the RPC methods.
+type Policies struct {
+	// Default is the policy of the methods without a policy of their own.
+	Default Policy
+
+	// Methods are the policies of individual methods, keyed either by full method name
+	// (`authzed.api.v1.PermissionsService/CheckPermission`) or by method name (`CheckPermission`).
+	Methods map[string]Policy
+}
+
+// ForMethod returns the policy of the given method, which is either a full gRPC method name or
+// the name of a span.
+func (p Policies) ForMethod(method string) Policy {
+	method = strings.TrimPrefix(method, "/")
+	if policy, ok := p.Methods[method]; ok {
+		return policy
+	}
+
+	if index := strings.LastIndex(method, "/"); index >= 0 {
+		if policy, ok := p.Methods[method[index+1:]]; ok {
+			return policy
+		}
+	}
+
+	return p.Default
+}
+
+// shouldSampleSuccess returns whether a successful trace under the latency threshold is
+// exported. The decision is derived from the trace ID, so that every node sampling the trace
+// reaches the same decision.
+func (p Policy) shouldSampleSuccess(traceID trace.TraceID) bool {
+	result := sdktrace.TraceIDRatioBased(p.SuccessRatio).ShouldSample(sdktrace.SamplingParameters{TraceID: traceID})
+	return result.Decision == sdktrace.RecordAndSample
+}
+
+// ParseMethodPolicies parses method policies of the form `method=threshold:ratio`, such as
+// `CheckPermission=100ms:0.001`. Either the threshold or the ratio may be left empty to use the
+// one of the default policy.
+func ParseMethodPolicies(values []string, defaultPolicy Policy) (map[string]Policy, error) {
+	policies := make(map[string]Policy, len(values))
+	for _, value := range values {
+		method, spec, ok := strings.Cut(value, "=")
+		if !ok || method == "" {
+			return nil, fmt.Errorf("invalid tail sampling policy %q: expected method=threshold:ratio", value)
+		}
+
+		thresholdText, ratioText, ok := strings.Cut(spec, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid tail sampling policy %q: expected method=threshold:ratio", value)
+		}
+
+		policy := defaultPolicy
+		if thresholdText != "" {
+			threshold, err := time.ParseDuration(thresholdText)
+			if err != nil {
+				return nil, fmt.Errorf("invalid latency threshold in tail sampling policy %q: %w", value, err)
+			}
+			if threshold < 0 {
+				return nil, fmt.Errorf("invalid latency threshold in tail sampling policy %q: must not be negative", value)
+			}
+			policy.LatencyThreshold = threshold
+		}
+
+		if ratioText != "" {
+			ratio, err := strconv.ParseFloat(ratioText, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid ratio in tail sampling policy %q: %w", value, err)
+			}
+			if ratio < 0 || ratio > 1 {
+				return nil, fmt.Errorf("invalid ratio in tail sampling policy %q: must be between 0 and 1", value)
+			}
+			policy.SuccessRatio = ratio
+		}
+
+		policies[strings.TrimPrefix(method, "/")] = policy
+	}
+
+	return policies, nil
+}
@@ -0,0 +1,88 @@
+package tailsampling
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMethodPolicies(t *testing.T) {
+	defaultPolicy := Policy{LatencyThreshold: time.Second, SuccessRatio: 0.01}
+
+	tcs := []struct {
+		name          string
+		values        []string
+		expected      map[string]Policy
+		expectedError string
+	}{
+		{
+			name:     "empty",
+			expected: map[string]Policy{},
+		},
+		{
+			name:   "threshold and ratio",
+			values: []string{"CheckPermission=100ms:0.001"},
+			expected: map[string]Policy{
+				"CheckPermission": {LatencyThreshold: 100 * time.Millisecond, SuccessRatio: 0.001},
+			},
+		},
+		{
+			name:   "defaults for empty values",
+			values: []string{"/authzed.api.v1.PermissionsService/LookupResources=5s:", "ReadRelationships=:0.5"},
+			expected: map[string]Policy{
+				"authzed.api.v1.PermissionsService/LookupResources": {LatencyThreshold: 5 * time.Second, SuccessRatio: 0.01},
+				"ReadRelationships": {LatencyThreshold: time.Second, SuccessRatio: 0.5},
+			},
+		},
+		{
+			name:          "missing method",
+			values:        []string{"=1s:0.1"},
+			expectedError: "expected method=threshold:ratio",
+		},
+		{
+			name:          "missing separator",
+			values:        []string{"CheckPermission=1s"},
+			expectedError: "expected method=threshold:ratio",
+		},
+		{
+			name:          "invalid threshold",
+			values:        []string{"CheckPermission=soon:0.1"},
+			expectedError: "invalid latency threshold",
+		},
+		{
+			name:          "ratio out of range",
+			values:        []string{"CheckPermission=1s:2"},
+			expectedError: "must be between 0 and 1",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			policies, err := ParseMethodPolicies(tc.values, defaultPolicy)
+			if tc.expectedError != "" {
+				require.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, policies)
+		})
+	}
+}
+
+func TestPoliciesForMethod(t *testing.T) {
+	policies := Policies{
+		Default: Policy{LatencyThreshold: time.Second},
+		Methods: map[string]Policy{
+			"CheckPermission": {LatencyThreshold: time.Millisecond},
+			"authzed.api.v1.PermissionsService/LookupResources": {LatencyThreshold: time.Minute},
+		},
+	}
+
+	require.Equal(t, time.Millisecond, policies.ForMethod("authzed.api.v1.PermissionsService/CheckPermission").LatencyThreshold)
+	require.Equal(t, time.Millisecond, policies.ForMethod("/authzed.api.v1.PermissionsService/CheckPermission").LatencyThreshold)
+	require.Equal(t, time.Minute, policies.ForMethod("authzed.api.v1.PermissionsService/LookupResources").LatencyThreshold)
+	require.Equal(t, time.Second, policies.ForMethod("authzed.api.v1.PermissionsService/ReadRelationships").LatencyThreshold)
+	require.Equal(t, time.Second, policies.ForMethod("LookupResources").LatencyThreshold)
+}
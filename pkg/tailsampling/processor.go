@@ -0,0 +1,236 @@
+package tailsampling
+
+import (
+	"context"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// DefaultMaxPendingTraces is the default maximum number of traces tracked while waiting for
+	// their spans to end.
+	DefaultMaxPendingTraces = 10_000
+
+	// DefaultMaxSpansPerTrace is the default maximum number of spans buffered for a single trace;
+	// the spans beyond it are dropped.
+	DefaultMaxSpansPerTrace = 1_000
+)
+
+const (
+	decisionError   = "error"
+	decisionLatency = "latency"
+	decisionSampled = "sampled"
+	decisionDropped = "dropped"
+	decisionEvicted = "evicted"
+)
+
+var tracesCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "spicedb",
+	Subsystem: "tracing",
+	Name:      "tail_sampling_traces_total",
+	Help:      "number of traces seen by the tail sampler, by sampling decision",
+}, []string{"decision"})
+
+// Processor is a span processor which buffers the spans of each trace until its local root span
+// ends, and then decides, based on the policy of the RPC method of the root span, whether to
+// pass the spans on to the next processor:
+//
+//   - traces containing an error are always exported
+//   - traces whose root span exceeds the latency threshold are always exported
+//   - the remaining traces are exported at the success ratio of the policy
+//
+// Spans which end after their local root span, such as those of hedged dispatches which outlive
+// the request, follow the decision already made for the trace; their errors do not change it.
+//
+// For the decision to be made on every trace, spans must be recorded and sampled by the tracer
+// provider, i.e. it must use the AlwaysSample sampler. As the nodes of a cluster all sample traces
+// this way, the sampling decision of a caller is not honored: every node decides upon its own part
+// of the trace, consistently for successful traces since their sampling derives from the trace ID.
+type Processor struct {
+	next             sdktrace.SpanProcessor
+	policies         Policies
+	maxPendingTraces int
+	maxSpansPerTrace int
+
+	lock sync.Mutex
+
+	// roots maps the ID of each span of the tracked traces to the ID of its local root span.
+	roots map[trace.SpanID]trace.SpanID
+
+	// traces maps the ID of each local root span to its trace, which is tracked until all of its
+	// spans have ended.
+	traces map[trace.SpanID]*trackedTrace
+}
+
+type trackedTrace struct {
+	// spanIDs are the IDs of all the spans of the trace, including those which ended.
+	spanIDs []trace.SpanID
+
+	// open is the number of spans of the trace which have not ended.
+	open int
+
+	// spans are the spans buffered until the local root span ends.
+	spans   []sdktrace.ReadOnlySpan
+	errored bool
+
+	// decided is true once the local root span ended, and exported is then whether the trace
+	// was exported.
+	decided  bool
+	exported bool
+}
+
+// NewProcessor returns a new tail sampling processor passing the spans of the sampled traces
+// on to the given processor.
+func NewProcessor(next sdktrace.SpanProcessor, policies Policies) *Processor {
+	return &Processor{
+		next:             next,
+		policies:         policies,
+		maxPendingTraces: DefaultMaxPendingTraces,
+		maxSpansPerTrace: DefaultMaxSpansPerTrace,
+		roots:            map[trace.SpanID]trace.SpanID{},
+		traces:           map[trace.SpanID]*trackedTrace{},
+	}
+}
+
+// OnStart implements sdktrace.SpanProcessor.
+func (p *Processor) OnStart(parent context.Context, s sdktrace.ReadWriteSpan) {
+	spanID := s.SpanContext().SpanID()
+	parentSpan := s.Parent()
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if parentSpan.IsValid() && !parentSpan.IsRemote() {
+		root, ok := p.roots[parentSpan.SpanID()]
+		if !ok {
+			// All the spans of the trace already ended, or it was not tracked, so the span is
+			// not tracked either.
+			return
+		}
+
+		tracked := p.traces[root]
+		tracked.spanIDs = append(tracked.spanIDs, spanID)
+		tracked.open++
+		p.roots[spanID] = root
+		return
+	}
+
+	if len(p.traces) >= p.maxPendingTraces {
+		tracesCounter.WithLabelValues(decisionEvicted).Inc()
+		return
+	}
+
+	p.roots[spanID] = spanID
+	p.traces[spanID] = &trackedTrace{spanIDs: []trace.SpanID{spanID}, open: 1}
+}
+
+// OnEnd implements sdktrace.SpanProcessor.
+func (p *Processor) OnEnd(s sdktrace.ReadOnlySpan) {
+	spanID := s.SpanContext().SpanID()
+
+	p.lock.Lock()
+	root, ok := p.roots[spanID]
+	if !ok {
+		p.lock.Unlock()
+		return
+	}
+
+	tracked := p.traces[root]
+	tracked.open--
+
+	var decision string
+	var exported []sdktrace.ReadOnlySpan
+	switch {
+	case tracked.decided:
+		if tracked.exported {
+			exported = []sdktrace.ReadOnlySpan{s}
+		}
+
+	case root == spanID:
+		// The local root span is always kept.
+		tracked.spans = append(tracked.spans, s)
+		tracked.errored = tracked.errored || isError(s)
+
+		decision = p.decide(s, tracked.errored)
+		tracked.decided = true
+		tracked.exported = decision != decisionDropped
+		if tracked.exported {
+			exported = tracked.spans
+		}
+		tracked.spans = nil
+
+	default:
+		if len(tracked.spans) < p.maxSpansPerTrace {
+			tracked.spans = append(tracked.spans, s)
+		}
+		tracked.errored = tracked.errored || isError(s)
+	}
+
+	if tracked.decided && tracked.open == 0 {
+		for _, id := range tracked.spanIDs {
+			delete(p.roots, id)
+		}
+		delete(p.traces, root)
+	}
+	p.lock.Unlock()
+
+	if decision != "" {
+		tracesCounter.WithLabelValues(decision).Inc()
+	}
+
+	for _, span := range exported {
+		p.next.OnEnd(span)
+	}
+}
+
+// decide returns the sampling decision of a trace given its local root span.
+func (p *Processor) decide(root sdktrace.ReadOnlySpan, errored bool) string {
+	if errored {
+		return decisionError
+	}
+
+	policy := p.policies.ForMethod(root.Name())
+	if root.EndTime().Sub(root.StartTime()) >= policy.LatencyThreshold {
+		return decisionLatency
+	}
+
+	if policy.shouldSampleSuccess(root.SpanContext().TraceID()) {
+		return decisionSampled
+	}
+
+	return decisionDropped
+}
+
+// isError returns whether the span recorded an error, either through its status or through the
+// status code of the gRPC call it represents.
+func isError(s sdktrace.ReadOnlySpan) bool {
+	if s.Status().Code == codes.Error {
+		return true
+	}
+
+	for _, attribute := range s.Attributes() {
+		if attribute.Key == semconv.RPCGRPCStatusCodeKey {
+			return attribute.Value.AsInt64() != 0
+		}
+	}
+	return false
+}
+
+// Shutdown implements sdktrace.SpanProcessor. Traces still pending are dropped.
+func (p *Processor) Shutdown(ctx context.Context) error {
+	return p.next.Shutdown(ctx)
+}
+
+// ForceFlush implements sdktrace.SpanProcessor. Only the traces already decided upon are
+// flushed.
+func (p *Processor) ForceFlush(ctx context.Context) error {
+	return p.next.ForceFlush(ctx)
+}
+
+var _ sdktrace.SpanProcessor = (*Processor)(nil)
@@ -0,0 +1,206 @@
+package tailsampling
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const checkPermission = "authzed.api.v1.PermissionsService/CheckPermission"
+
+func newTestTracer(policies Policies) (trace.Tracer, *tracetest.InMemoryExporter) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(sdktrace.AlwaysSample()),
+		sdktrace.WithSpanProcessor(NewProcessor(sdktrace.NewSimpleSpanProcessor(exporter), policies)),
+	)
+	return provider.Tracer("test"), exporter
+}
+
+// runTrace records a trace made of a root span with the given name and duration, and a child span.
+func runTrace(tracer trace.Tracer, name string, duration time.Duration) {
+	start := time.Now()
+	ctx, root := tracer.Start(context.Background(), name, trace.WithTimestamp(start))
+	_, child := tracer.Start(ctx, "child")
+	child.End()
+	root.End(trace.WithTimestamp(start.Add(duration)))
+}
+
+func TestProcessorDecisions(t *testing.T) {
+	policies := Policies{
+		Default: Policy{LatencyThreshold: time.Second, SuccessRatio: 0},
+		Methods: map[string]Policy{
+			"CheckPermission": {LatencyThreshold: 100 * time.Millisecond, SuccessRatio: 0},
+		},
+	}
+
+	tcs := []struct {
+		name     string
+		run      func(tracer trace.Tracer)
+		exported bool
+	}{
+		{
+			name: "fast success is dropped",
+			run: func(tracer trace.Tracer) {
+				runTrace(tracer, checkPermission, 10*time.Millisecond)
+			},
+		},
+		{
+			name: "slow request is exported",
+			run: func(tracer trace.Tracer) {
+				runTrace(tracer, checkPermission, 200*time.Millisecond)
+			},
+			exported: true,
+		},
+		{
+			name: "threshold of the default policy applies to other methods",
+			run: func(tracer trace.Tracer) {
+				runTrace(tracer, "authzed.api.v1.PermissionsService/LookupResources", 200*time.Millisecond)
+			},
+		},
+		{
+			name: "errored root is exported",
+			run: func(tracer trace.Tracer) {
+				start := time.Now()
+				_, root := tracer.Start(context.Background(), checkPermission, trace.WithTimestamp(start))
+				root.SetStatus(codes.Error, "failed")
+				root.End(trace.WithTimestamp(start.Add(time.Millisecond)))
+			},
+			exported: true,
+		},
+		{
+			name: "errored child is exported",
+			run: func(tracer trace.Tracer) {
+				start := time.Now()
+				ctx, root := tracer.Start(context.Background(), checkPermission, trace.WithTimestamp(start))
+				_, child := tracer.Start(ctx, "child")
+				child.SetStatus(codes.Error, "failed")
+				child.End()
+				root.End(trace.WithTimestamp(start.Add(time.Millisecond)))
+			},
+			exported: true,
+		},
+		{
+			name: "non-OK gRPC status is exported",
+			run: func(tracer trace.Tracer) {
+				start := time.Now()
+				_, root := tracer.Start(context.Background(), checkPermission, trace.WithTimestamp(start))
+				root.SetAttributes(semconv.RPCGRPCStatusCodeKey.Int(5))
+				root.End(trace.WithTimestamp(start.Add(time.Millisecond)))
+			},
+			exported: true,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			tracer, exporter := newTestTracer(policies)
+			tc.run(tracer)
+
+			if !tc.exported {
+				require.Empty(t, exporter.GetSpans())
+				return
+			}
+
+			spans := exporter.GetSpans()
+			require.NotEmpty(t, spans)
+			require.Equal(t, checkPermission, spans[len(spans)-1].Name)
+		})
+	}
+}
+
+func TestProcessorSuccessRatio(t *testing.T) {
+	tracer, exporter := newTestTracer(Policies{Default: Policy{LatencyThreshold: time.Hour, SuccessRatio: 1}})
+	runTrace(tracer, checkPermission, time.Millisecond)
+	require.Len(t, exporter.GetSpans(), 2)
+
+	tracer, exporter = newTestTracer(Policies{Default: Policy{LatencyThreshold: time.Hour, SuccessRatio: 0.5}})
+	for range 200 {
+		runTrace(tracer, checkPermission, time.Millisecond)
+	}
+
+	// Each trace is exported whole or not at all.
+	exported := len(exporter.GetSpans())
+	require.Zero(t, exported%2)
+	require.Greater(t, exported, 2*20)
+	require.Less(t, exported, 2*180)
+}
+
+func TestProcessorBoundsPendingTraces(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	processor := NewProcessor(sdktrace.NewSimpleSpanProcessor(exporter), Policies{})
+	processor.maxPendingTraces = 1
+	processor.maxSpansPerTrace = 2
+	tracer := sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(sdktrace.AlwaysSample()),
+		sdktrace.WithSpanProcessor(processor),
+	).Tracer("test")
+
+	ctx, first := tracer.Start(context.Background(), "first")
+	_, second := tracer.Start(context.Background(), "second")
+	for range 3 {
+		_, child := tracer.Start(ctx, "child")
+		child.End()
+	}
+	second.End()
+	first.End()
+
+	// The second trace was not tracked, and the children of the first beyond the limit were dropped.
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 3)
+	require.Equal(t, "first", spans[2].Name)
+	require.Empty(t, processor.roots)
+	require.Empty(t, processor.traces)
+}
+
+func TestProcessorSpansEndingAfterTheirRoot(t *testing.T) {
+	for _, exported := range []bool{true, false} {
+		t.Run(fmt.Sprintf("exported=%v", exported), func(t *testing.T) {
+			exporter := tracetest.NewInMemoryExporter()
+			successRatio := 0.0
+			if exported {
+				successRatio = 1
+			}
+			processor := NewProcessor(sdktrace.NewSimpleSpanProcessor(exporter), Policies{Default: Policy{LatencyThreshold: time.Hour, SuccessRatio: successRatio}})
+			tracer := sdktrace.NewTracerProvider(
+				sdktrace.WithSampler(sdktrace.AlwaysSample()),
+				sdktrace.WithSpanProcessor(processor),
+			).Tracer("test")
+
+			ctx, root := tracer.Start(context.Background(), checkPermission)
+			childCtx, child := tracer.Start(ctx, "child")
+			root.End()
+
+			// Spans started and ended after the root follow the decision made for the trace,
+			// even once their parent ended.
+			_, grandchild := tracer.Start(childCtx, "grandchild")
+			child.End()
+			_, lateGrandchild := tracer.Start(childCtx, "late grandchild")
+			grandchild.End()
+			lateGrandchild.SetStatus(codes.Error, "failed")
+			lateGrandchild.End()
+
+			names := make([]string, 0, 4)
+			for _, span := range exporter.GetSpans() {
+				names = append(names, span.Name)
+			}
+			if exported {
+				require.Equal(t, []string{checkPermission, "child", "grandchild", "late grandchild"}, names)
+			} else {
+				require.Empty(t, names)
+			}
+
+			// The trace is no longer tracked once all of its spans ended.
+			require.Empty(t, processor.roots)
+			require.Empty(t, processor.traces)
+		})
+	}
+}
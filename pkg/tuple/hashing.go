@@ -2,6 +2,8 @@ package tuple
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
 	"fmt"
 	"sort"
 	"time"
@@ -50,6 +52,29 @@ func CanonicalBytes(rel Relationship) ([]byte, error) {
 	return sb.Bytes(), nil
 }
 
+// VersionToken returns a token for the version of a relationship, which changes whenever it is
+// written with another caveat, caveat context or expiration, or deleted and written again. It is
+// computed from the canonical bytes and the write revision of the relationship, which must
+// therefore have been read with its write revision.
+func VersionToken(rel Relationship) (string, error) {
+	if rel.OptionalWriteRevision == "" {
+		return "", fmt.Errorf("relationship %s was read without its write revision", StringWithoutCaveatOrExpiration(rel))
+	}
+
+	canonical, err := CanonicalBytes(rel)
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.New()
+	hash.Write(canonical)
+	hash.Write([]byte("@" + rel.OptionalWriteRevision))
+	return base64.RawURLEncoding.EncodeToString(hash.Sum(nil)[:versionTokenSize]), nil
+}
+
+// versionTokenSize is the number of bytes of the hash of a relationship kept in its version token.
+const versionTokenSize = 12
+
 func writeCanonicalContext(sb *bytes.Buffer, context *structpb.Struct) error {
 	sb.WriteString("{")
 	for i, key := range sortedContextKeys(context.Fields) {
@@ -36,6 +36,40 @@ func TestCanonicalBytes(t *testing.T) {
 	}
 }
 
+func TestVersionToken(t *testing.T) {
+	atRevision := func(rel string, writeRevision string) Relationship {
+		parsed := MustParse(rel)
+		parsed.OptionalWriteRevision = writeRevision
+		return parsed
+	}
+
+	token, err := VersionToken(atRevision(`document:foo#viewer@user:tom[somecaveat:{"someparam":42}]`, "1"))
+	require.NoError(t, err)
+	require.Len(t, token, 16)
+
+	sameToken, err := VersionToken(atRevision(`document:foo#viewer@user:tom[somecaveat:{"someparam":42}]`, "1"))
+	require.NoError(t, err)
+	require.Equal(t, token, sameToken)
+
+	for _, changed := range []Relationship{
+		atRevision(`document:foo#viewer@user:tom[somecaveat:{"someparam":43}]`, "1"),
+		atRevision(`document:foo#viewer@user:tom[somecaveat]`, "1"),
+		atRevision(`document:foo#viewer@user:tom`, "1"),
+		atRevision(`document:foo#viewer@user:tom[expiration:2020-01-01T00:00:00Z]`, "1"),
+		atRevision(`document:foo#viewer@user:sarah[somecaveat:{"someparam":42}]`, "1"),
+		atRevision(`document:foo#viewer@user:tom[somecaveat:{"someparam":42}]`, "2"),
+	} {
+		changedToken, err := VersionToken(changed)
+		require.NoError(t, err)
+		require.NotEqual(t, token, changedToken, MustString(changed))
+	}
+}
+
+func TestVersionTokenRequiresWriteRevision(t *testing.T) {
+	_, err := VersionToken(MustParse(`document:foo#viewer@user:tom`))
+	require.Error(t, err)
+}
+
 func BenchmarkCanonicalBytes(b *testing.B) {
 	for _, tc := range testCases {
 		tc := tc
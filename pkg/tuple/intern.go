@@ -0,0 +1,44 @@
+package tuple
+
+import (
+	"unique"
+
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+)
+
+// InternName returns the canonical copy of an object type, relation or caveat name, held in a
+// table shared by the whole process. As a schema defines few names, but relationships read from
+// datastores and requests received over the network each carry their own copies of them, interning
+// the names where they enter the server lets the relationships, requests and cache entries that
+// hold them share a single backing string. Names no longer referenced are released by the garbage
+// collector.
+func InternName(name string) string {
+	return unique.Make(name).Value()
+}
+
+// InternONR returns the object and relation with its object type and relation interned.
+func InternONR(onr ObjectAndRelation) ObjectAndRelation {
+	onr.ObjectType = InternName(onr.ObjectType)
+	onr.Relation = InternName(onr.Relation)
+	return onr
+}
+
+// InternCoreONR interns the namespace and relation of the core.ObjectAndRelation in place.
+func InternCoreONR(onr *core.ObjectAndRelation) {
+	if onr == nil {
+		return
+	}
+
+	onr.Namespace = InternName(onr.Namespace)
+	onr.Relation = InternName(onr.Relation)
+}
+
+// InternCoreRR interns the namespace and relation of the core.RelationReference in place.
+func InternCoreRR(rr *core.RelationReference) {
+	if rr == nil {
+		return
+	}
+
+	rr.Namespace = InternName(rr.Namespace)
+	rr.Relation = InternName(rr.Relation)
+}
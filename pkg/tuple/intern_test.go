@@ -0,0 +1,59 @@
+package tuple
+
+import (
+	"strings"
+	"testing"
+	"unsafe"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInternName(t *testing.T) {
+	first := strings.Clone("document")
+	second := strings.Clone("document")
+	require.NotSame(t, unsafe.StringData(first), unsafe.StringData(second))
+	require.Same(t, unsafe.StringData(InternName(first)), unsafe.StringData(InternName(second)))
+	require.Equal(t, "", InternName(""))
+
+	onr := InternONR(ONR(strings.Clone("document"), "foo", strings.Clone("viewer")))
+	require.Same(t, unsafe.StringData(InternName("document")), unsafe.StringData(onr.ObjectType))
+	require.Same(t, unsafe.StringData(InternName("viewer")), unsafe.StringData(onr.Relation))
+	require.Equal(t, "foo", onr.ObjectID)
+}
+
+func TestInternCoreMessages(t *testing.T) {
+	coreONR := CoreONR(strings.Clone("document"), "foo", strings.Clone("viewer"))
+	InternCoreONR(coreONR)
+	require.Same(t, unsafe.StringData(InternName("document")), unsafe.StringData(coreONR.Namespace))
+	require.Same(t, unsafe.StringData(InternName("viewer")), unsafe.StringData(coreONR.Relation))
+	require.Equal(t, "foo", coreONR.ObjectId)
+
+	coreRR := CoreRR(strings.Clone("group"), strings.Clone("member"))
+	InternCoreRR(coreRR)
+	require.Same(t, unsafe.StringData(InternName("group")), unsafe.StringData(coreRR.Namespace))
+	require.Same(t, unsafe.StringData(InternName("member")), unsafe.StringData(coreRR.Relation))
+
+	InternCoreONR(nil)
+	InternCoreRR(nil)
+}
+
+func TestFromV1RelationshipInternsNames(t *testing.T) {
+	// The names of relationships received over the network each have their own backing memory.
+	received := func(relString string) *v1.Relationship {
+		rel := MustParseV1Rel(relString)
+		rel.Resource.ObjectType = strings.Clone(rel.Resource.ObjectType)
+		rel.Relation = strings.Clone(rel.Relation)
+		rel.Subject.Object.ObjectType = strings.Clone(rel.Subject.Object.ObjectType)
+		rel.OptionalCaveat.CaveatName = strings.Clone(rel.OptionalCaveat.CaveatName)
+		return rel
+	}
+
+	first := FromV1Relationship(received("document:foo#viewer@user:tom[somecaveat]"))
+	second := FromV1Relationship(received("document:bar#viewer@user:sarah[somecaveat]"))
+
+	require.Same(t, unsafe.StringData(first.Resource.ObjectType), unsafe.StringData(second.Resource.ObjectType))
+	require.Same(t, unsafe.StringData(first.Resource.Relation), unsafe.StringData(second.Resource.Relation))
+	require.Same(t, unsafe.StringData(first.Subject.ObjectType), unsafe.StringData(second.Subject.ObjectType))
+	require.Same(t, unsafe.StringData(first.OptionalCaveat.CaveatName), unsafe.StringData(second.OptionalCaveat.CaveatName))
+}
@@ -6,12 +6,11 @@ import (
 	"maps"
 	"regexp"
 	"slices"
+	"strings"
 	"time"
 
 	"google.golang.org/protobuf/types/known/structpb"
 
-	"github.com/jzelinskie/stringz"
-
 	core "github.com/authzed/spicedb/pkg/proto/core/v1"
 )
 
@@ -23,20 +22,6 @@ const (
 	caveatNameExpr    = "([a-z][a-z0-9_]{1,61}[a-z0-9]/)*[a-z][a-z0-9_]{1,62}[a-z0-9]"
 )
 
-var onrExpr = fmt.Sprintf(
-	`(?P<resourceType>(%s)):(?P<resourceID>%s)#(?P<resourceRel>%s)`,
-	namespaceNameExpr,
-	resourceIDExpr,
-	relationExpr,
-)
-
-var subjectExpr = fmt.Sprintf(
-	`(?P<subjectType>(%s)):(?P<subjectID>%s)(#(?P<subjectRel>%s|\.\.\.))?`,
-	namespaceNameExpr,
-	subjectIDExpr,
-	relationExpr,
-)
-
 var (
 	caveatExpr     = fmt.Sprintf(`\[(?P<caveatName>(%s))(:(?P<caveatContext>(\{(.+)\})))?\]`, caveatNameExpr)
 	expirationExpr = `\[expiration:(?P<expirationDateTime>([\d\-\.:TZ]+))\]`
@@ -47,11 +32,13 @@ var (
 	subjectIDRegex  = regexp.MustCompile(fmt.Sprintf("^%s$", subjectIDExpr))
 )
 
-var parserRegex = regexp.MustCompile(
+// suffixRegex matches the optional caveat and expiration following the subject of a relationship
+// string, which are the only parts of the grammar parsed with a regular expression: the resource
+// and subject are parsed by hand, as Parse is called for every relationship read from files and
+// tests, and is far slower through the regular expression.
+var suffixRegex = regexp.MustCompile(
 	fmt.Sprintf(
-		`^%s@%s(%s)?(%s)?$`,
-		onrExpr,
-		subjectExpr,
+		`^(%s)?(%s)?$`,
 		caveatExpr,
 		expirationExpr,
 	),
@@ -91,94 +78,206 @@ func MustParse(relString string) Relationship {
 }
 
 var (
-	subjectRelIndex         = slices.Index(parserRegex.SubexpNames(), "subjectRel")
-	caveatNameIndex         = slices.Index(parserRegex.SubexpNames(), "caveatName")
-	caveatContextIndex      = slices.Index(parserRegex.SubexpNames(), "caveatContext")
-	resourceIDIndex         = slices.Index(parserRegex.SubexpNames(), "resourceID")
-	subjectIDIndex          = slices.Index(parserRegex.SubexpNames(), "subjectID")
-	resourceTypeIndex       = slices.Index(parserRegex.SubexpNames(), "resourceType")
-	resourceRelIndex        = slices.Index(parserRegex.SubexpNames(), "resourceRel")
-	subjectTypeIndex        = slices.Index(parserRegex.SubexpNames(), "subjectType")
-	expirationDateTimeIndex = slices.Index(parserRegex.SubexpNames(), "expirationDateTime")
+	caveatNameIndex         = slices.Index(suffixRegex.SubexpNames(), "caveatName")
+	caveatContextIndex      = slices.Index(suffixRegex.SubexpNames(), "caveatContext")
+	expirationDateTimeIndex = slices.Index(suffixRegex.SubexpNames(), "expirationDateTime")
 )
 
+// maxIDLength is the maximum length of resource and subject IDs.
+const maxIDLength = 1024
+
 // Parse unmarshals the string form of a Tuple and returns an error on failure,
 //
 // This function treats both missing and Ellipsis relations equally.
 func Parse(relString string) (Relationship, error) {
-	groups := parserRegex.FindStringSubmatch(relString)
-	if len(groups) == 0 {
+	// The caveat and expiration follow the subject, in which brackets cannot appear.
+	prefix, suffix := relString, ""
+	if index := strings.IndexByte(relString, '['); index >= 0 {
+		prefix, suffix = relString[:index], relString[index:]
+	}
+
+	resourceString, subjectString, ok := strings.Cut(prefix, "@")
+	if !ok {
 		return Relationship{}, fmt.Errorf("invalid relationship string")
 	}
 
-	subjectRelation := Ellipsis
-	if len(groups[subjectRelIndex]) > 0 {
-		subjectRelation = stringz.DefaultEmpty(groups[subjectRelIndex], Ellipsis)
+	resource, ok := parseONR(resourceString)
+	if !ok {
+		return Relationship{}, fmt.Errorf("invalid relationship string")
+	}
+
+	subject, ok := parseSubjectONR(subjectString)
+	if !ok {
+		return Relationship{}, fmt.Errorf("invalid relationship string")
 	}
 
-	caveatName := groups[caveatNameIndex]
 	var optionalCaveat *core.ContextualizedCaveat
-	if caveatName != "" {
-		optionalCaveat = &core.ContextualizedCaveat{
-			CaveatName: caveatName,
+	var optionalExpiration *time.Time
+	if suffix != "" {
+		groups := suffixRegex.FindStringSubmatch(suffix)
+		if len(groups) == 0 {
+			return Relationship{}, fmt.Errorf("invalid relationship string")
 		}
 
-		caveatContextString := groups[caveatContextIndex]
-		if len(caveatContextString) > 0 {
-			contextMap := make(map[string]any, 1)
-			err := json.Unmarshal([]byte(caveatContextString), &contextMap)
-			if err != nil {
-				return Relationship{}, fmt.Errorf("invalid caveat context JSON: %w", err)
+		caveatName := groups[caveatNameIndex]
+		if caveatName != "" {
+			optionalCaveat = &core.ContextualizedCaveat{
+				CaveatName: InternName(caveatName),
 			}
 
-			caveatContext, err := structpb.NewStruct(contextMap)
-			if err != nil {
-				return Relationship{}, fmt.Errorf("invalid caveat context: %w", err)
-			}
+			caveatContextString := groups[caveatContextIndex]
+			if len(caveatContextString) > 0 {
+				contextMap := make(map[string]any, 1)
+				err := json.Unmarshal(unsafeStringBytes(caveatContextString), &contextMap)
+				if err != nil {
+					return Relationship{}, fmt.Errorf("invalid caveat context JSON: %w", err)
+				}
 
-			optionalCaveat.Context = caveatContext
-		}
-	}
+				caveatContext, err := structpb.NewStruct(contextMap)
+				if err != nil {
+					return Relationship{}, fmt.Errorf("invalid caveat context: %w", err)
+				}
 
-	expirationTimeStr := groups[expirationDateTimeIndex]
-	var optionalExpiration *time.Time
-	if len(expirationTimeStr) > 0 {
-		expirationTime, err := time.Parse(expirationFormat, expirationTimeStr)
-		if err != nil {
-			return Relationship{}, fmt.Errorf("invalid expiration time: %w", err)
+				optionalCaveat.Context = caveatContext
+			}
 		}
 
-		optionalExpiration = &expirationTime
+		expirationTimeStr := groups[expirationDateTimeIndex]
+		if len(expirationTimeStr) > 0 {
+			expirationTime, err := time.Parse(expirationFormat, expirationTimeStr)
+			if err != nil {
+				return Relationship{}, fmt.Errorf("invalid expiration time: %w", err)
+			}
+
+			optionalExpiration = &expirationTime
+		}
 	}
 
-	resourceID := groups[resourceIDIndex]
-	if err := ValidateResourceID(resourceID); err != nil {
-		return Relationship{}, fmt.Errorf("invalid resource id: %w", err)
+	// The IDs were matched against the grammar while parsed, leaving only their length to check.
+	if len(resource.ObjectID) > maxIDLength {
+		return Relationship{}, fmt.Errorf("invalid resource id: %w", ValidateResourceID(resource.ObjectID))
 	}
 
-	subjectID := groups[subjectIDIndex]
-	if err := ValidateSubjectID(subjectID); err != nil {
-		return Relationship{}, fmt.Errorf("invalid subject id: %w", err)
+	if len(subject.ObjectID) > maxIDLength {
+		return Relationship{}, fmt.Errorf("invalid subject id: %w", ValidateSubjectID(subject.ObjectID))
 	}
 
 	return Relationship{
 		RelationshipReference: RelationshipReference{
-			Resource: ObjectAndRelation{
-				ObjectType: groups[resourceTypeIndex],
-				ObjectID:   resourceID,
-				Relation:   groups[resourceRelIndex],
-			},
-			Subject: ObjectAndRelation{
-				ObjectType: groups[subjectTypeIndex],
-				ObjectID:   subjectID,
-				Relation:   subjectRelation,
-			},
+			Resource: resource,
+			Subject:  subject,
 		},
 		OptionalCaveat:     optionalCaveat,
 		OptionalExpiration: optionalExpiration,
 	}, nil
 }
 
+// parseONR parses the string form of a resource, `type:id#relation`, as matched by onrExpr.
+func parseONR(onr string) (ObjectAndRelation, bool) {
+	objectType, rest, ok := strings.Cut(onr, ":")
+	if !ok || !isNamespaceName(objectType) {
+		return ObjectAndRelation{}, false
+	}
+
+	objectID, relation, ok := strings.Cut(rest, "#")
+	if !ok || !isResourceID(objectID) || !isRelationName(relation) {
+		return ObjectAndRelation{}, false
+	}
+
+	return ObjectAndRelation{
+		ObjectType: InternName(objectType),
+		ObjectID:   objectID,
+		Relation:   InternName(relation),
+	}, true
+}
+
+// parseSubjectONR parses the string form of a subject, `type:id` or `type:id#relation`, as
+// matched by subjectExpr. A missing relation is the ellipsis.
+func parseSubjectONR(onr string) (ObjectAndRelation, bool) {
+	objectType, rest, ok := strings.Cut(onr, ":")
+	if !ok || !isNamespaceName(objectType) {
+		return ObjectAndRelation{}, false
+	}
+
+	objectID, relation, hasRelation := strings.Cut(rest, "#")
+	if objectID != PublicWildcard && !isResourceID(objectID) {
+		return ObjectAndRelation{}, false
+	}
+
+	if !hasRelation {
+		relation = Ellipsis
+	} else if relation != Ellipsis && !isRelationName(relation) {
+		return ObjectAndRelation{}, false
+	}
+
+	return ObjectAndRelation{
+		ObjectType: InternName(objectType),
+		ObjectID:   objectID,
+		Relation:   InternName(relation),
+	}, true
+}
+
+// isNamespaceName returns true if the name matches namespaceNameExpr: name segments separated by
+// slashes, all but the last of which are at most 63 characters long.
+func isNamespaceName(name string) bool {
+	for {
+		segment, rest, ok := strings.Cut(name, "/")
+		if !ok {
+			return isName(segment, 64)
+		}
+		if !isName(segment, 63) {
+			return false
+		}
+		name = rest
+	}
+}
+
+// isRelationName returns true if the name matches relationExpr.
+func isRelationName(name string) bool {
+	return isName(name, 64)
+}
+
+// isName returns true if the name is between 3 and maxLength characters long, starts with a
+// lowercase letter, ends with a lowercase letter or digit and is otherwise made of lowercase
+// letters, digits and underscores.
+func isName(name string, maxLength int) bool {
+	if len(name) < 3 || len(name) > maxLength {
+		return false
+	}
+
+	if name[0] < 'a' || name[0] > 'z' {
+		return false
+	}
+
+	last := name[len(name)-1]
+	if (last < 'a' || last > 'z') && (last < '0' || last > '9') {
+		return false
+	}
+
+	for i := 1; i < len(name)-1; i++ {
+		c := name[i]
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '_' {
+			return false
+		}
+	}
+	return true
+}
+
+// isResourceID returns true if the ID matches resourceIDExpr.
+func isResourceID(id string) bool {
+	if len(id) == 0 {
+		return false
+	}
+
+	for i := 0; i < len(id); i++ {
+		c := id[i]
+		if (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') && (c < '0' || c > '9') && !strings.ContainsRune("/_|-=+", rune(c)) {
+			return false
+		}
+	}
+	return true
+}
+
 // MustWithExpiration adds the given expiration to the relationship. This is for testing only.
 func MustWithExpiration(rel Relationship, expiration time.Time) Relationship {
 	rel.OptionalExpiration = &expiration
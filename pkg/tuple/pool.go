@@ -0,0 +1,74 @@
+package tuple
+
+import (
+	"sync"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+)
+
+var (
+	coreONRPool = sync.Pool{
+		New: func() any {
+			return &core.ObjectAndRelation{}
+		},
+	}
+
+	coreTuplePool = sync.Pool{
+		New: func() any {
+			return &core.RelationTuple{}
+		},
+	}
+)
+
+// AcquireCoreONR returns a core.ObjectAndRelation for the object and relation, taken from a pool.
+// It is meant for short-lived conversions in hot paths, such as comparisons with protos: the
+// returned message must be released with ReleaseCoreONR once no longer used, and must not be
+// retained, such as in a request or response, as it will be reused.
+func AcquireCoreONR(onr ObjectAndRelation) *core.ObjectAndRelation {
+	coreONR := coreONRPool.Get().(*core.ObjectAndRelation)
+	coreONR.Namespace = onr.ObjectType
+	coreONR.ObjectId = onr.ObjectID
+	coreONR.Relation = onr.Relation
+	return coreONR
+}
+
+// ReleaseCoreONR returns a core.ObjectAndRelation acquired with AcquireCoreONR to the pool.
+func ReleaseCoreONR(onr *core.ObjectAndRelation) {
+	if onr == nil {
+		return
+	}
+
+	onr.Reset()
+	coreONRPool.Put(onr)
+}
+
+// AcquireCoreTuple returns a core.RelationTuple for the relationship, taken from a pool along
+// with its resource and subject. The caveat, expiration and integrity of the relationship are
+// referenced rather than copied. As with AcquireCoreONR, the returned message must be released
+// with ReleaseCoreTuple and must not be retained.
+func AcquireCoreTuple(r Relationship) *core.RelationTuple {
+	coreTuple := coreTuplePool.Get().(*core.RelationTuple)
+	coreTuple.ResourceAndRelation = AcquireCoreONR(r.Resource)
+	coreTuple.Subject = AcquireCoreONR(r.Subject)
+	coreTuple.Caveat = r.OptionalCaveat
+	coreTuple.Integrity = r.OptionalIntegrity
+	if r.OptionalExpiration != nil {
+		coreTuple.OptionalExpirationTime = timestamppb.New(*r.OptionalExpiration)
+	}
+	return coreTuple
+}
+
+// ReleaseCoreTuple returns a core.RelationTuple acquired with AcquireCoreTuple, along with its
+// resource and subject, to the pools.
+func ReleaseCoreTuple(rt *core.RelationTuple) {
+	if rt == nil {
+		return
+	}
+
+	ReleaseCoreONR(rt.ResourceAndRelation)
+	ReleaseCoreONR(rt.Subject)
+	rt.Reset()
+	coreTuplePool.Put(rt)
+}
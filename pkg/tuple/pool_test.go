@@ -0,0 +1,85 @@
+package tuple
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+)
+
+func TestAcquireCoreTuple(t *testing.T) {
+	expiration := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	rels := []Relationship{
+		MustParse("document:foo#viewer@user:tom"),
+		MustParse("document:foo#viewer@group:eng#member[somecaveat]"),
+		MustParse("document:foo#viewer@user:*").WithCaveat(&core.ContextualizedCaveat{CaveatName: "othercaveat"}),
+	}
+	withExpiration := MustParse("document:foo#viewer@user:sarah")
+	withExpiration.OptionalExpiration = &expiration
+	rels = append(rels, withExpiration)
+
+	// Tuples acquired after others are released must hold nothing of the released ones.
+	for range 2 {
+		for _, rel := range rels {
+			coreTuple := AcquireCoreTuple(rel)
+			require.True(t, rel.ToCoreTuple().EqualVT(coreTuple), "mismatch for %s", MustString(rel))
+			ReleaseCoreTuple(coreTuple)
+		}
+	}
+
+	onr := ONR("document", "foo", "viewer")
+	coreONR := AcquireCoreONR(onr)
+	require.True(t, onr.ToCoreONR().EqualVT(coreONR))
+	ReleaseCoreONR(coreONR)
+	ReleaseCoreONR(nil)
+	ReleaseCoreTuple(nil)
+}
+
+// coreONRSink makes the converted protos escape to the heap, as they do when passed on rather than
+// only compared.
+var coreONRSink *core.ObjectAndRelation
+
+func BenchmarkToCoreONR(b *testing.B) {
+	onr := ONR("document", "foo", "viewer")
+
+	b.Run("allocated", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			coreONRSink = onr.ToCoreONR()
+		}
+	})
+
+	b.Run("pooled", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			coreONRSink = AcquireCoreONR(onr)
+			ReleaseCoreONR(coreONRSink)
+		}
+	})
+}
+
+func BenchmarkToCoreTuple(b *testing.B) {
+	rel := MustParse("document:foo#viewer@group:eng#member")
+
+	b.Run("allocated", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			if rel.ToCoreTuple().Subject.ObjectId != "eng" {
+				b.Fatal("unexpected subject")
+			}
+		}
+	})
+
+	b.Run("pooled", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			coreTuple := AcquireCoreTuple(rel)
+			if coreTuple.Subject.ObjectId != "eng" {
+				b.Fatal("unexpected subject")
+			}
+			ReleaseCoreTuple(coreTuple)
+		}
+	})
+}
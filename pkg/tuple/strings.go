@@ -4,6 +4,7 @@ import (
 	"sort"
 	"strings"
 	"time"
+	"unsafe"
 
 	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/types/known/structpb"
@@ -58,10 +59,29 @@ func StringCoreONR(onr *core.ObjectAndRelation) string {
 
 // StringONRStrings converts ONR strings to a string.
 func StringONRStrings(namespace, objectID, relation string) string {
-	if relation == Ellipsis {
-		return JoinObjectRef(namespace, objectID)
+	buf := make([]byte, 0, onrStringLength(namespace, objectID, relation))
+	return unsafeBytesString(appendONRStrings(buf, namespace, objectID, relation))
+}
+
+// onrStringLength returns the length of the string form of the ONR strings.
+func onrStringLength(namespace, objectID, relation string) int {
+	length := len(namespace) + 1 + len(objectID)
+	if relation != Ellipsis {
+		length += 1 + len(relation)
+	}
+	return length
+}
+
+// appendONRStrings appends the string form of the ONR strings to the buffer.
+func appendONRStrings(buf []byte, namespace, objectID, relation string) []byte {
+	buf = append(buf, namespace...)
+	buf = append(buf, ':')
+	buf = append(buf, objectID...)
+	if relation != Ellipsis {
+		buf = append(buf, '#')
+		buf = append(buf, relation...)
 	}
-	return JoinRelRef(JoinObjectRef(namespace, objectID), relation)
+	return buf
 }
 
 // StringsONRs converts ONR objects to a string slice, sorted.
@@ -88,25 +108,49 @@ func MustString(rel Relationship) string {
 func String(rel Relationship) (string, error) {
 	spiceerrors.DebugAssert(rel.ValidateNotEmpty, "relationship must not be empty")
 
-	caveatString, err := StringCaveat(rel.OptionalCaveat)
-	if err != nil {
-		return "", err
+	// The relationship is written into a single buffer, sized for all but the caveat context.
+	length := onrStringLength(rel.Resource.ObjectType, rel.Resource.ObjectID, rel.Resource.Relation) + 1 +
+		onrStringLength(rel.Subject.ObjectType, rel.Subject.ObjectID, rel.Subject.Relation)
+	if rel.OptionalCaveat != nil {
+		length += len(rel.OptionalCaveat.CaveatName) + 2
+	}
+	if rel.OptionalExpiration != nil {
+		length += len(expirationPrefix) + len(expirationFormat) + 1
 	}
 
-	expirationString, err := StringExpiration(rel.OptionalExpiration)
+	buf := make([]byte, 0, length)
+	buf = appendONRStrings(buf, rel.Resource.ObjectType, rel.Resource.ObjectID, rel.Resource.Relation)
+	buf = append(buf, '@')
+	buf = appendONRStrings(buf, rel.Subject.ObjectType, rel.Subject.ObjectID, rel.Subject.Relation)
+
+	buf, err := appendCaveat(buf, rel.OptionalCaveat)
 	if err != nil {
 		return "", err
 	}
 
-	return StringONR(rel.Resource) + "@" + StringONR(rel.Subject) + caveatString + expirationString, nil
+	return unsafeBytesString(appendExpiration(buf, rel.OptionalExpiration)), nil
 }
 
+const expirationPrefix = "[expiration:"
+
 func StringExpiration(expiration *time.Time) (string, error) {
 	if expiration == nil {
 		return "", nil
 	}
 
-	return "[expiration:" + expiration.Format(expirationFormat) + "]", nil
+	buf := make([]byte, 0, len(expirationPrefix)+len(expirationFormat)+1)
+	return unsafeBytesString(appendExpiration(buf, expiration)), nil
+}
+
+// appendExpiration appends the string form of the expiration, if any, to the buffer.
+func appendExpiration(buf []byte, expiration *time.Time) []byte {
+	if expiration == nil {
+		return buf
+	}
+
+	buf = append(buf, expirationPrefix...)
+	buf = expiration.AppendFormat(buf, expirationFormat)
+	return append(buf, ']')
 }
 
 // StringWithoutCaveatOrExpiration converts a relationship to a string, without its caveat or expiration included.
@@ -130,16 +174,31 @@ func StringCaveat(caveat *core.ContextualizedCaveat) (string, error) {
 		return "", nil
 	}
 
-	contextString, err := StringCaveatContext(caveat.Context)
+	buf, err := appendCaveat(make([]byte, 0, len(caveat.CaveatName)+2), caveat)
 	if err != nil {
 		return "", err
 	}
+	return unsafeBytesString(buf), nil
+}
 
-	if len(contextString) > 0 {
-		contextString = ":" + contextString
+// appendCaveat appends the string form of the caveat, if any, to the buffer.
+func appendCaveat(buf []byte, caveat *core.ContextualizedCaveat) ([]byte, error) {
+	if caveat == nil || caveat.CaveatName == "" {
+		return buf, nil
 	}
 
-	return "[" + caveat.CaveatName + contextString + "]", nil
+	buf = append(buf, '[')
+	buf = append(buf, caveat.CaveatName...)
+	if caveat.Context != nil && len(caveat.Context.Fields) > 0 {
+		buf = append(buf, ':')
+
+		var err error
+		buf, err = appendCaveatContext(buf, caveat.Context)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return append(buf, ']'), nil
 }
 
 // StringCaveatContext converts the context of a caveat to a string. If the context is nil or empty, returns an empty string.
@@ -148,14 +207,30 @@ func StringCaveatContext(context *structpb.Struct) (string, error) {
 		return "", nil
 	}
 
-	contextBytes, err := protojson.MarshalOptions{
-		Multiline: false,
-		Indent:    "",
-	}.Marshal(context)
+	contextBytes, err := appendCaveatContext(nil, context)
 	if err != nil {
 		return "", err
 	}
-	return string(contextBytes), nil
+	return unsafeBytesString(contextBytes), nil
+}
+
+func appendCaveatContext(buf []byte, context *structpb.Struct) ([]byte, error) {
+	return protojson.MarshalOptions{
+		Multiline: false,
+		Indent:    "",
+	}.MarshalAppend(buf, context)
+}
+
+// unsafeBytesString returns the string sharing the memory of the buffer, which must not be
+// modified afterward, as strings.Builder does.
+func unsafeBytesString(buf []byte) string {
+	return unsafe.String(unsafe.SliceData(buf), len(buf))
+}
+
+// unsafeStringBytes returns the bytes sharing the memory of the string, which must not be
+// modified.
+func unsafeStringBytes(s string) []byte {
+	return unsafe.Slice(unsafe.StringData(s), len(s))
 }
 
 // JoinObject joins the namespace and the objectId together into the standard
@@ -0,0 +1,162 @@
+package tuple
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+	"unsafe"
+
+	"github.com/jzelinskie/stringz"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStringAllocations(t *testing.T) {
+	rel := MustParse("document:foo#viewer@group:eng#member")
+	require.InDelta(t, 1, testing.AllocsPerRun(100, func() {
+		_ = MustString(rel)
+	}), 0)
+	require.InDelta(t, 1, testing.AllocsPerRun(100, func() {
+		_ = StringONR(rel.Subject)
+	}), 0)
+
+	expiration := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	rel.OptionalExpiration = &expiration
+	require.InDelta(t, 1, testing.AllocsPerRun(100, func() {
+		_ = MustString(rel)
+	}), 0)
+}
+
+func TestParseInternsNames(t *testing.T) {
+	first := MustParse("document:foo#viewer@user:tom")
+	second := MustParse("document:bar#viewer@user:sarah")
+
+	// Names are interned, rather than referencing the parsed strings.
+	require.Same(t, unsafe.StringData(first.Resource.ObjectType), unsafe.StringData(second.Resource.ObjectType))
+	require.Same(t, unsafe.StringData(first.Resource.Relation), unsafe.StringData(second.Resource.Relation))
+	require.Same(t, unsafe.StringData(first.Subject.ObjectType), unsafe.StringData(second.Subject.ObjectType))
+}
+
+var stringSink string
+
+func BenchmarkString(b *testing.B) {
+	expiration := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	rels := map[string]Relationship{
+		"plain":      MustParse("document:foo#viewer@group:eng#member"),
+		"caveated":   MustParse(`document:foo#viewer@user:tom[somecaveat:{"key":"value"}]`),
+		"expiration": MustWithExpiration(MustParse("document:foo#viewer@user:tom"), expiration),
+	}
+
+	for name, rel := range rels {
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for range b.N {
+				stringSink = MustString(rel)
+			}
+		})
+	}
+
+	b.Run("onr", func(b *testing.B) {
+		onr := ONR("group", "eng", "member")
+		b.ReportAllocs()
+		for range b.N {
+			stringSink = StringONR(onr)
+		}
+	})
+}
+
+var relationshipSink Relationship
+
+func BenchmarkParse(b *testing.B) {
+	rels := map[string]string{
+		"plain":    "document:foo#viewer@group:eng#member",
+		"caveated": `document:foo#viewer@user:tom[somecaveat:{"key":"value"}]`,
+	}
+
+	for name, rel := range rels {
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for range b.N {
+				relationshipSink = MustParse(rel)
+			}
+		})
+	}
+}
+
+var (
+	referenceONRExpr = fmt.Sprintf(
+		`(?P<resourceType>(%s)):(?P<resourceID>%s)#(?P<resourceRel>%s)`,
+		namespaceNameExpr,
+		resourceIDExpr,
+		relationExpr,
+	)
+
+	referenceSubjectExpr = fmt.Sprintf(
+		`(?P<subjectType>(%s)):(?P<subjectID>%s)(#(?P<subjectRel>%s|\.\.\.))?`,
+		namespaceNameExpr,
+		subjectIDExpr,
+		relationExpr,
+	)
+
+	// referenceParserRegex is the regular expression of the whole grammar, against which the
+	// hand-written parser is checked.
+	referenceParserRegex  = regexp.MustCompile(fmt.Sprintf(`^%s@%s(%s)?(%s)?$`, referenceONRExpr, referenceSubjectExpr, caveatExpr, expirationExpr))
+	referenceONRRegex     = regexp.MustCompile(fmt.Sprintf(`^%s$`, referenceONRExpr))
+	referenceSubjectRegex = regexp.MustCompile(fmt.Sprintf(`^%s$`, referenceSubjectExpr))
+)
+
+func FuzzParse(f *testing.F) {
+	for _, tc := range testCases {
+		f.Add(tc.input)
+	}
+	f.Add("document:foo#viewer@user:*#...")
+	f.Add("org/team/document:foo#viewer@user:tom#member[expiration:2020-01-01T00:00:00Z]")
+	f.Add("document:foo#viewer@user:tom[somecaveat:{\"a\":\"]\"}]")
+
+	f.Fuzz(func(t *testing.T, relString string) {
+		groups := referenceParserRegex.FindStringSubmatch(relString)
+		parsed, err := Parse(relString)
+		if len(groups) == 0 {
+			require.Error(t, err)
+			return
+		}
+
+		names := referenceParserRegex.SubexpNames()
+		group := func(name string) string { return groups[slices.Index(names, name)] }
+		if err != nil {
+			// Only the values of the suffix and the length of the IDs are checked past the grammar.
+			require.True(t, group("caveatContext") != "" || group("expirationDateTime") != "" ||
+				len(group("resourceID")) > maxIDLength || len(group("subjectID")) > maxIDLength, "unexpected error: %v", err)
+			return
+		}
+
+		require.Equal(t, group("resourceType"), parsed.Resource.ObjectType)
+		require.Equal(t, group("resourceID"), parsed.Resource.ObjectID)
+		require.Equal(t, group("resourceRel"), parsed.Resource.Relation)
+		require.Equal(t, group("subjectType"), parsed.Subject.ObjectType)
+		require.Equal(t, group("subjectID"), parsed.Subject.ObjectID)
+		require.Equal(t, stringz.DefaultEmpty(group("subjectRel"), Ellipsis), parsed.Subject.Relation)
+		require.Equal(t, group("caveatName"), parsed.OptionalCaveat.GetCaveatName())
+		require.Equal(t, group("expirationDateTime") != "", parsed.OptionalExpiration != nil)
+
+		resourceONR := relString[:strings.IndexByte(relString, '@')]
+		_, err = ParseONR(resourceONR)
+		require.Equal(t, referenceONRRegex.MatchString(resourceONR), err == nil)
+	})
+}
+
+func FuzzParseSubjectONR(f *testing.F) {
+	for _, subject := range []string{"user:tom", "user:*", "user:tom#...", "group:eng#member", "org/group:eng#member", "user:", "user:tom#", "us:tom", "user:tom#a"} {
+		f.Add(subject)
+	}
+
+	f.Fuzz(func(t *testing.T, subject string) {
+		_, err := ParseSubjectONR(subject)
+		require.Equal(t, referenceSubjectRegex.MatchString(subject), err == nil, "mismatch for %q", subject)
+
+		_, err = ParseONR(subject)
+		require.Equal(t, referenceONRRegex.MatchString(subject), err == nil, "mismatch for %q", subject)
+	})
+}
@@ -74,6 +74,11 @@ type Relationship struct {
 	OptionalExpiration *time.Time
 	OptionalIntegrity  *core.RelationshipIntegrity
 
+	// OptionalWriteRevision identifies the datastore transaction which last wrote the
+	// relationship. It is opaque and only set on relationships read from a datastore with the
+	// IncludeWriteRevisions query option.
+	OptionalWriteRevision string
+
 	RelationshipReference
 }
 
@@ -93,10 +98,10 @@ func (r Relationship) ToCoreTuple() *core.RelationTuple {
 	}
 }
 
-const relStructSize = 120 /* size of the struct itself */
+const relStructSize = 136 /* size of the struct itself */
 
 func (r Relationship) SizeVT() int {
-	size := r.Resource.SizeVT() + r.Subject.SizeVT() + relStructSize
+	size := r.Resource.SizeVT() + r.Subject.SizeVT() + relStructSize + len(r.OptionalWriteRevision)
 	if r.OptionalCaveat != nil {
 		size += r.OptionalCaveat.SizeVT()
 	}
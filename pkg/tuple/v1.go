@@ -179,7 +179,7 @@ func FromV1Relationship(rel *v1.Relationship) Relationship {
 	var caveat *core.ContextualizedCaveat
 	if rel.OptionalCaveat != nil {
 		caveat = &core.ContextualizedCaveat{
-			CaveatName: rel.OptionalCaveat.CaveatName,
+			CaveatName: InternName(rel.OptionalCaveat.CaveatName),
 			Context:    rel.OptionalCaveat.Context,
 		}
 	}
@@ -194,13 +194,13 @@ func FromV1Relationship(rel *v1.Relationship) Relationship {
 		RelationshipReference: RelationshipReference{
 			Resource: ObjectAndRelation{
 				ObjectID:   rel.Resource.ObjectId,
-				ObjectType: rel.Resource.ObjectType,
-				Relation:   rel.Relation,
+				ObjectType: InternName(rel.Resource.ObjectType),
+				Relation:   InternName(rel.Relation),
 			},
 			Subject: ObjectAndRelation{
 				ObjectID:   rel.Subject.Object.ObjectId,
-				ObjectType: rel.Subject.Object.ObjectType,
-				Relation:   stringz.Default(rel.Subject.OptionalRelation, Ellipsis, ""),
+				ObjectType: InternName(rel.Subject.Object.ObjectType),
+				Relation:   InternName(stringz.Default(rel.Subject.OptionalRelation, Ellipsis, "")),
 			},
 		},
 		OptionalCaveat:     caveat,
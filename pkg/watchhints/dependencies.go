@@ -0,0 +1,180 @@
+package watchhints
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/authzed/spicedb/pkg/genutil/mapz"
+	"github.com/authzed/spicedb/pkg/graph"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/spiceerrors"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// PermissionReference identifies a relation or permission on an object type.
+type PermissionReference struct {
+	// ObjectType is the name of the object definition.
+	ObjectType string
+
+	// Permission is the name of the relation or permission on the object definition.
+	Permission string
+}
+
+func (pr PermissionReference) String() string {
+	return pr.ObjectType + "#" + pr.Permission
+}
+
+// DependencyGraph is a graph, computed from a schema, mapping each relation and permission
+// to the relations and permissions whose computed results depend upon it.
+type DependencyGraph struct {
+	dependents map[PermissionReference]*mapz.Set[PermissionReference]
+}
+
+// NewDependencyGraph computes the dependency graph for the given object definitions.
+func NewDependencyGraph(definitions []*core.NamespaceDefinition) (*DependencyGraph, error) {
+	dg := &DependencyGraph{
+		dependents: map[PermissionReference]*mapz.Set[PermissionReference]{},
+	}
+
+	relationsByDefinition := make(map[string]map[string]*core.Relation, len(definitions))
+	for _, def := range definitions {
+		relations := make(map[string]*core.Relation, len(def.Relation))
+		for _, rel := range def.Relation {
+			relations[rel.Name] = rel
+		}
+		relationsByDefinition[def.Name] = relations
+	}
+
+	for _, def := range definitions {
+		for _, rel := range def.Relation {
+			current := PermissionReference{def.Name, rel.Name}
+
+			// A relation depends upon any subject relations allowed on it, as a change to
+			// the membership of the subject relation changes the membership of the relation.
+			for _, allowed := range rel.GetTypeInformation().GetAllowedDirectRelations() {
+				if allowed.GetPublicWildcard() != nil || allowed.GetRelation() == tuple.Ellipsis {
+					continue
+				}
+
+				dg.addDependency(PermissionReference{allowed.Namespace, allowed.GetRelation()}, current)
+			}
+
+			if rel.UsersetRewrite == nil {
+				continue
+			}
+
+			// A permission depends upon every relation or permission referenced by its rewrite,
+			// along with the relations and permissions walked by its arrows.
+			_, err := graph.WalkRewrite(rel.UsersetRewrite, func(childOneof *core.SetOperation_Child) (interface{}, error) {
+				switch child := childOneof.ChildType.(type) {
+				case *core.SetOperation_Child_ComputedUserset:
+					dg.addDependency(PermissionReference{def.Name, child.ComputedUserset.Relation}, current)
+
+				case *core.SetOperation_Child_TupleToUserset:
+					dg.addArrowDependencies(relationsByDefinition, def.Name, child.TupleToUserset.Tupleset.Relation, child.TupleToUserset.ComputedUserset.Relation, current)
+
+				case *core.SetOperation_Child_FunctionedTupleToUserset:
+					dg.addArrowDependencies(relationsByDefinition, def.Name, child.FunctionedTupleToUserset.Tupleset.Relation, child.FunctionedTupleToUserset.ComputedUserset.Relation, current)
+
+				case *core.SetOperation_Child_XThis, *core.SetOperation_Child_XNil, *core.SetOperation_Child_UsersetRewrite:
+					// Nothing to do.
+
+				default:
+					return nil, spiceerrors.MustBugf("unknown rewrite child type %T", child)
+				}
+				return nil, nil
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return dg, nil
+}
+
+func (dg *DependencyGraph) addArrowDependencies(
+	relationsByDefinition map[string]map[string]*core.Relation,
+	definitionName string,
+	tuplesetRelationName string,
+	computedRelationName string,
+	dependent PermissionReference,
+) {
+	dg.addDependency(PermissionReference{definitionName, tuplesetRelationName}, dependent)
+
+	tuplesetRelation, ok := relationsByDefinition[definitionName][tuplesetRelationName]
+	if !ok {
+		return
+	}
+
+	for _, allowed := range tuplesetRelation.GetTypeInformation().GetAllowedDirectRelations() {
+		if _, ok := relationsByDefinition[allowed.Namespace][computedRelationName]; ok {
+			dg.addDependency(PermissionReference{allowed.Namespace, computedRelationName}, dependent)
+		}
+	}
+}
+
+func (dg *DependencyGraph) addDependency(dependency PermissionReference, dependent PermissionReference) {
+	if dependency == dependent {
+		return
+	}
+
+	existing, ok := dg.dependents[dependency]
+	if !ok {
+		existing = mapz.NewSet[PermissionReference]()
+		dg.dependents[dependency] = existing
+	}
+	existing.Add(dependent)
+}
+
+// AffectedBy returns all relations and permissions whose computed results may change when a
+// relationship with the given resource object type and relation is changed, including the
+// relation itself. The results are sorted.
+func (dg *DependencyGraph) AffectedBy(objectType string, relation string) []PermissionReference {
+	start := PermissionReference{objectType, relation}
+	affected := mapz.NewSet(start)
+
+	toProcess := []PermissionReference{start}
+	for len(toProcess) > 0 {
+		current := toProcess[0]
+		toProcess = toProcess[1:]
+
+		dependents, ok := dg.dependents[current]
+		if !ok {
+			continue
+		}
+
+		for _, dependent := range dependents.AsSlice() {
+			if affected.Add(dependent) {
+				toProcess = append(toProcess, dependent)
+			}
+		}
+	}
+
+	return sortedReferences(affected)
+}
+
+// AffectedByChanges returns all relations and permissions whose computed results may change
+// due to the given relationship changes. The results are sorted.
+func (dg *DependencyGraph) AffectedByChanges(changes []tuple.RelationshipUpdate) []PermissionReference {
+	affected := mapz.NewSet[PermissionReference]()
+	encountered := mapz.NewSet[PermissionReference]()
+	for _, change := range changes {
+		resource := change.Relationship.Resource
+		if !encountered.Add(PermissionReference{resource.ObjectType, resource.Relation}) {
+			continue
+		}
+
+		affected.Extend(dg.AffectedBy(resource.ObjectType, resource.Relation))
+	}
+
+	return sortedReferences(affected)
+}
+
+func sortedReferences(refs *mapz.Set[PermissionReference]) []PermissionReference {
+	sorted := refs.AsSlice()
+	slices.SortFunc(sorted, func(a, b PermissionReference) int {
+		return cmp.Or(cmp.Compare(a.ObjectType, b.ObjectType), cmp.Compare(a.Permission, b.Permission))
+	})
+	return sorted
+}
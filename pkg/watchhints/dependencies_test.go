@@ -0,0 +1,105 @@
+package watchhints
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/pkg/schemadsl/compiler"
+	"github.com/authzed/spicedb/pkg/schemadsl/input"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+const testSchema = `
+definition user {}
+
+definition group {
+	relation member: user | group#member
+}
+
+definition folder {
+	relation parent: folder
+	relation viewer: user | group#member
+	permission view = viewer + parent->view
+}
+
+definition document {
+	relation folder: folder
+	relation viewer: user | user:*
+	relation banned: user
+	permission view = (viewer + folder->view) - banned
+	permission view_and_fold = view & folder.any(view)
+	permission nothing = nil
+}
+`
+
+func TestDependencyGraph(t *testing.T) {
+	compiled, err := compiler.Compile(compiler.InputSchema{
+		Source:       input.Source("test"),
+		SchemaString: testSchema,
+	}, compiler.AllowUnprefixedObjectType())
+	require.NoError(t, err)
+
+	dg, err := NewDependencyGraph(compiled.ObjectDefinitions)
+	require.NoError(t, err)
+
+	tcs := []struct {
+		objectType string
+		relation   string
+		expected   []string
+	}{
+		{
+			"document", "viewer",
+			[]string{"document#view", "document#view_and_fold", "document#viewer"},
+		},
+		{
+			"document", "banned",
+			[]string{"document#banned", "document#view", "document#view_and_fold"},
+		},
+		{
+			"document", "folder",
+			[]string{"document#folder", "document#view", "document#view_and_fold"},
+		},
+		{
+			"folder", "viewer",
+			[]string{"document#view", "document#view_and_fold", "folder#view", "folder#viewer"},
+		},
+		{
+			"folder", "parent",
+			[]string{"document#view", "document#view_and_fold", "folder#parent", "folder#view"},
+		},
+		{
+			"group", "member",
+			[]string{"document#view", "document#view_and_fold", "folder#view", "folder#viewer", "group#member"},
+		},
+		{
+			"user", "unknown",
+			[]string{"user#unknown"},
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.objectType+"#"+tc.relation, func(t *testing.T) {
+			affected := dg.AffectedBy(tc.objectType, tc.relation)
+			strs := make([]string, 0, len(affected))
+			for _, ref := range affected {
+				strs = append(strs, ref.String())
+			}
+			require.Equal(t, tc.expected, strs)
+		})
+	}
+
+	affected := dg.AffectedByChanges([]tuple.RelationshipUpdate{
+		tuple.Touch(tuple.MustParse("document:first#banned@user:tom")),
+		tuple.Delete(tuple.MustParse("folder:someFolder#viewer@user:fred")),
+		tuple.Touch(tuple.MustParse("document:second#banned@user:sarah")),
+	})
+	require.Equal(t, []PermissionReference{
+		{"document", "banned"},
+		{"document", "view"},
+		{"document", "view_and_fold"},
+		{"folder", "view"},
+		{"folder", "viewer"},
+	}, affected)
+}
@@ -0,0 +1,136 @@
+// Package watchhints provides a layer over the datastore Watch API which uses the schema to
+// determine the relations and permissions whose computed results may have changed for each
+// set of relationship changes.
+package watchhints
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+)
+
+// RevisionChangesWithHints are the changes at a single revision, enriched with the relations and
+// permissions whose computed results may have changed as a result.
+type RevisionChangesWithHints struct {
+	*datastore.RevisionChanges
+
+	// PermissionHints are the relations and permissions, sorted, whose results may have changed
+	// due to the relationship changes found at the revision. A permission not found in the hints
+	// is guaranteed to be unaffected by the relationship changes.
+	PermissionHints []PermissionReference
+}
+
+// Watch watches the datastore for changes after the given revision, emitting each change along with
+// the relations and permissions whose computed results may have changed, as determined by the schema
+// at the revision of the change.
+//
+// Schema changes are always watched, as they are required to keep the dependency graph up to date,
+// but they are only emitted if requested in the options.
+func Watch(ctx context.Context, ds datastore.Datastore, afterRevision datastore.Revision, opts datastore.WatchOptions) (<-chan *RevisionChangesWithHints, <-chan error) {
+	updates := make(chan *RevisionChangesWithHints)
+	errs := make(chan error, 1)
+
+	dg, err := dependencyGraphAtRevision(ctx, ds, afterRevision)
+	if err != nil {
+		close(updates)
+		errs <- err
+		return updates, errs
+	}
+
+	emitSchema := opts.Content&datastore.WatchSchema != 0
+	opts.Content |= datastore.WatchSchema
+	dsUpdates, dsErrs := ds.Watch(ctx, afterRevision, opts)
+
+	go func() {
+		defer close(updates)
+		defer close(errs)
+
+		for {
+			select {
+			case change, ok := <-dsUpdates:
+				if !ok {
+					return
+				}
+
+				if len(change.ChangedDefinitions) > 0 || len(change.DeletedNamespaces) > 0 {
+					updated, err := dependencyGraphAtRevision(ctx, ds, change.Revision)
+					if err != nil {
+						errs <- err
+						return
+					}
+					dg = updated
+				}
+
+				if !emitSchema {
+					change = withoutSchemaChanges(change)
+					if change == nil {
+						continue
+					}
+				}
+
+				enriched := &RevisionChangesWithHints{RevisionChanges: change}
+				if len(change.RelationshipChanges) > 0 {
+					enriched.PermissionHints = dg.AffectedByChanges(change.RelationshipChanges)
+				}
+
+				select {
+				case updates <- enriched:
+				case <-ctx.Done():
+					errs <- datastore.NewWatchCanceledErr()
+					return
+				}
+
+			case err, ok := <-dsErrs:
+				if ok {
+					errs <- err
+				}
+				return
+
+			case <-ctx.Done():
+				if errors.Is(ctx.Err(), context.Canceled) {
+					errs <- datastore.NewWatchCanceledErr()
+				} else {
+					errs <- ctx.Err()
+				}
+				return
+			}
+		}
+	}()
+
+	return updates, errs
+}
+
+// withoutSchemaChanges returns a copy of the changes without the schema changes, or nil if
+// nothing else changed at the revision.
+func withoutSchemaChanges(change *datastore.RevisionChanges) *datastore.RevisionChanges {
+	if len(change.ChangedDefinitions) == 0 && len(change.DeletedNamespaces) == 0 && len(change.DeletedCaveats) == 0 {
+		return change
+	}
+
+	if len(change.RelationshipChanges) == 0 && !change.IsCheckpoint {
+		return nil
+	}
+
+	withoutSchema := *change
+	withoutSchema.ChangedDefinitions = nil
+	withoutSchema.DeletedNamespaces = nil
+	withoutSchema.DeletedCaveats = nil
+	return &withoutSchema
+}
+
+func dependencyGraphAtRevision(ctx context.Context, ds datastore.Datastore, revision datastore.Revision) (*DependencyGraph, error) {
+	namespaces, err := ds.SnapshotReader(revision).ListAllNamespaces(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load schema for watch hints: %w", err)
+	}
+
+	definitions := make([]*core.NamespaceDefinition, 0, len(namespaces))
+	for _, ns := range namespaces {
+		definitions = append(definitions, ns.Definition)
+	}
+
+	return NewDependencyGraph(definitions)
+}
@@ -0,0 +1,90 @@
+package watchhints
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+func TestWatchEmitsHints(t *testing.T) {
+	rawDS, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+
+	ds, revision := testfixtures.DatastoreFromSchemaAndTestRelationships(rawDS, testSchema, nil, require.New(t))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	updates, errs := Watch(ctx, ds, revision, datastore.WatchJustRelationships())
+
+	_, err = ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		return rwt.WriteRelationships(ctx, []tuple.RelationshipUpdate{
+			tuple.Touch(tuple.MustParse("folder:someFolder#viewer@user:fred")),
+		})
+	})
+	require.NoError(t, err)
+
+	select {
+	case update := <-updates:
+		require.Len(t, update.RelationshipChanges, 1)
+		require.Equal(t, []PermissionReference{
+			{"document", "view"},
+			{"document", "view_and_fold"},
+			{"folder", "view"},
+			{"folder", "viewer"},
+		}, update.PermissionHints)
+
+	case err := <-errs:
+		require.NoError(t, err)
+
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "timed out waiting for watch update")
+	}
+}
+
+func TestWatchOmitsUnrequestedSchemaChanges(t *testing.T) {
+	rawDS, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+
+	ds, revision := testfixtures.DatastoreFromSchemaAndTestRelationships(rawDS, testSchema, nil, require.New(t))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	updates, errs := Watch(ctx, ds, revision, datastore.WatchJustRelationships())
+
+	_, err = ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		folder, _, err := rwt.ReadNamespaceByName(ctx, "folder")
+		if err != nil {
+			return err
+		}
+		return rwt.WriteNamespaces(ctx, folder)
+	})
+	require.NoError(t, err)
+
+	_, err = ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		return rwt.WriteRelationships(ctx, []tuple.RelationshipUpdate{
+			tuple.Touch(tuple.MustParse("folder:someFolder#viewer@user:fred")),
+		})
+	})
+	require.NoError(t, err)
+
+	select {
+	case update := <-updates:
+		require.Empty(t, update.ChangedDefinitions)
+		require.Len(t, update.RelationshipChanges, 1)
+
+	case err := <-errs:
+		require.NoError(t, err)
+
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "timed out waiting for watch update")
+	}
+}
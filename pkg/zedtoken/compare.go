@@ -0,0 +1,92 @@
+package zedtoken
+
+import (
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+
+	"github.com/authzed/spicedb/pkg/datastore"
+)
+
+// Ordering is the ordering of two revisions, or of the revisions of two zedtokens.
+type Ordering int
+
+const (
+	// Concurrent is the ordering of two revisions which are neither equal nor ordered, such as the
+	// revisions of two concurrent transactions in Postgres. Neither revision is guaranteed to
+	// include the changes found at the other.
+	Concurrent Ordering = iota
+
+	// Before is the ordering of a revision which is older than the other: the other revision
+	// includes all of its changes.
+	Before
+
+	// Equal is the ordering of two revisions which include exactly the same changes.
+	Equal
+
+	// After is the ordering of a revision which is newer than the other: it includes all of the
+	// changes found at the other revision.
+	After
+)
+
+// String returns the name of the ordering.
+func (o Ordering) String() string {
+	switch o {
+	case Before:
+		return "before"
+	case Equal:
+		return "equal"
+	case After:
+		return "after"
+	default:
+		return "concurrent"
+	}
+}
+
+// CompareRevisions returns the ordering of the lhs revision relative to the rhs revision. Both
+// revisions must be from the same datastore.
+//
+// Revisions are only partially ordered in some datastores, so callers must handle Concurrent rather
+// than assuming that a revision which is not Before another is After or Equal to it.
+func CompareRevisions(lhs, rhs datastore.Revision) Ordering {
+	switch {
+	case lhs.Equal(rhs):
+		return Equal
+	case lhs.LessThan(rhs):
+		return Before
+	case lhs.GreaterThan(rhs):
+		return After
+	default:
+		return Concurrent
+	}
+}
+
+// Compare returns the ordering of the revision of the lhs zedtoken relative to that of the rhs
+// zedtoken, decoding both with the datastore which issued them.
+//
+// Zedtokens are only comparable when issued by the same datastore: decoding a zedtoken issued by a
+// datastore of a different engine returns an error, while zedtokens issued by different instances
+// of the same engine decode, but compare meaninglessly.
+func Compare(lhs, rhs *v1.ZedToken, ds RevisionDecoder) (Ordering, error) {
+	lhsRevision, err := DecodeRevision(lhs, ds)
+	if err != nil {
+		return Concurrent, err
+	}
+
+	rhsRevision, err := DecodeRevision(rhs, ds)
+	if err != nil {
+		return Concurrent, err
+	}
+
+	return CompareRevisions(lhsRevision, rhsRevision), nil
+}
+
+// AtLeastAsFresh returns whether the revision of the zedtoken includes all of the changes found at
+// the revision of the other zedtoken, such as to decide whether a response computed at the former
+// satisfies a request for at_least_as_fresh the latter. Concurrent revisions are not at least as
+// fresh as each other.
+func AtLeastAsFresh(token, other *v1.ZedToken, ds RevisionDecoder) (bool, error) {
+	ordering, err := Compare(token, other, ds)
+	if err != nil {
+		return false, err
+	}
+	return ordering == Equal || ordering == After, nil
+}
@@ -0,0 +1,74 @@
+package zedtoken
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/revisions"
+	"github.com/authzed/spicedb/pkg/datastore"
+)
+
+// concurrentRevision is a revision which is neither equal to nor ordered with any other revision.
+type concurrentRevision struct{ datastore.Revision }
+
+func (concurrentRevision) Equal(datastore.Revision) bool       { return false }
+func (concurrentRevision) GreaterThan(datastore.Revision) bool { return false }
+func (concurrentRevision) LessThan(datastore.Revision) bool    { return false }
+
+func TestCompareRevisions(t *testing.T) {
+	tcs := []struct {
+		name     string
+		lhs      datastore.Revision
+		rhs      datastore.Revision
+		expected Ordering
+	}{
+		{"equal", revisions.NewForTransactionID(4), revisions.NewForTransactionID(4), Equal},
+		{"before", revisions.NewForTransactionID(4), revisions.NewForTransactionID(8), Before},
+		{"after", revisions.NewForTransactionID(8), revisions.NewForTransactionID(4), After},
+		{"concurrent", concurrentRevision{revisions.NewForTransactionID(4)}, revisions.NewForTransactionID(4), Concurrent},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, CompareRevisions(tc.lhs, tc.rhs))
+			require.Equal(t, tc.name, tc.expected.String())
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	require := require.New(t)
+	decoder := revisions.CommonDecoder{Kind: revisions.TransactionID}
+
+	older := MustNewFromRevision(revisions.NewForTransactionID(4))
+	newer := MustNewFromRevision(revisions.NewForTransactionID(8))
+
+	ordering, err := Compare(older, newer, decoder)
+	require.NoError(err)
+	require.Equal(Before, ordering)
+
+	ordering, err = Compare(newer, older, decoder)
+	require.NoError(err)
+	require.Equal(After, ordering)
+
+	fresh, err := AtLeastAsFresh(newer, older, decoder)
+	require.NoError(err)
+	require.True(fresh)
+
+	fresh, err = AtLeastAsFresh(older, older, decoder)
+	require.NoError(err)
+	require.True(fresh)
+
+	fresh, err = AtLeastAsFresh(older, newer, decoder)
+	require.NoError(err)
+	require.False(fresh)
+
+	// Zedtokens issued by a datastore of another engine cannot be decoded.
+	hlc := MustNewFromRevision(mustHLC("1234.0000000001"))
+	_, err = Compare(older, hlc, decoder)
+	require.Error(err)
+
+	_, err = Compare(nil, older, decoder)
+	require.ErrorIs(err, ErrNilZedToken)
+}
@@ -1,4 +1,10 @@
-// Package zedtoken converts decimal.Decimal to zedtoken and vice versa
+// Package zedtoken encodes datastore revisions into zedtokens, the opaque tokens returned by the
+// API, and decodes and compares them.
+//
+// The encoding is stable: zedtokens encoded by any version of SpiceDB, including legacy zookies,
+// decode in later versions. The revision within a zedtoken is specific to the datastore engine
+// which issued it, so decoding requires that datastore, and zedtokens of different engines cannot
+// be decoded or compared with each other.
 package zedtoken
 
 import (
@@ -77,7 +83,7 @@ func Decode(encoded *v1.ZedToken) (*zedtoken.DecodedZedToken, error) {
 }
 
 // DecodeRevision converts and extracts the revision from a zedtoken or legacy zookie.
-func DecodeRevision(encoded *v1.ZedToken, ds revisionDecoder) (datastore.Revision, error) {
+func DecodeRevision(encoded *v1.ZedToken, ds RevisionDecoder) (datastore.Revision, error) {
 	decoded, err := Decode(encoded)
 	if err != nil {
 		return datastore.NoRevision, err
@@ -103,6 +109,7 @@ func DecodeRevision(encoded *v1.ZedToken, ds revisionDecoder) (datastore.Revisio
 	}
 }
 
-type revisionDecoder interface {
+// RevisionDecoder decodes the revisions of zedtokens, such as a datastore.Datastore.
+type RevisionDecoder interface {
 	RevisionFromString(string) (datastore.Revision, error)
 }